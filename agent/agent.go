@@ -17,6 +17,7 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
+	"github.com/juju/names"
 	"github.com/juju/utils"
 
 	"github.com/juju/juju/mongo"
@@ -48,6 +49,12 @@ const (
 	StorageDir       = "STORAGE_DIR"
 	StorageAddr      = "STORAGE_ADDR"
 	AgentServiceName = "AGENT_SERVICE_NAME"
+
+	// EnvironUUID holds the UUID of the environment the agent
+	// belongs to. It is not set for agents of environments
+	// bootstrapped before environments could be hosted, which
+	// always belong to the state server's own environment.
+	EnvironUUID = "ENVIRON_UUID"
 )
 
 // The Config interface is the sole way that the agent gets access to the
@@ -149,6 +156,10 @@ type ConfigSetterOnly interface {
 	// SetAPIHostPorts sets the API host/port addresses to connect to.
 	SetAPIHostPorts(servers [][]network.HostPort)
 
+	// SetCACert sets the PEM-encoded CA certificates
+	// trusted when connecting to the state and API.
+	SetCACert(caCert string)
+
 	// Migrate takes an existing agent config and applies the given
 	// parameters to change it.
 	//
@@ -449,6 +460,10 @@ func (c *configInternal) SetAPIHostPorts(servers [][]network.HostPort) {
 	c.apiDetails.addresses = addrs
 }
 
+func (c *configInternal) SetCACert(caCert string) {
+	c.caCert = caCert
+}
+
 func (c *configInternal) SetValue(key, value string) {
 	if value == "" {
 		delete(c.values, key)
@@ -622,12 +637,17 @@ func (c *configInternal) APIInfo() *api.Info {
 			addrs = append(addrs, localApiAddr)
 		}
 	}
+	var environTag string
+	if uuid := c.values[EnvironUUID]; uuid != "" {
+		environTag = names.NewEnvironTag(uuid).String()
+	}
 	return &api.Info{
-		Addrs:    addrs,
-		Password: c.apiDetails.password,
-		CACert:   c.caCert,
-		Tag:      c.tag,
-		Nonce:    c.nonce,
+		Addrs:      addrs,
+		Password:   c.apiDetails.password,
+		CACert:     c.caCert,
+		Tag:        c.tag,
+		Nonce:      c.nonce,
+		EnvironTag: environTag,
 	}
 }
 
@@ -567,3 +567,14 @@ func (*suite) TestSetAPIHostPorts(c *gc.C) {
 	c.Assert(err, gc.IsNil)
 	c.Assert(addrs, gc.DeepEquals, []string{"0.1.2.3:123", "0.1.2.5:125"})
 }
+
+func (*suite) TestSetCACert(c *gc.C) {
+	conf, err := agent.NewAgentConfig(attributeParams)
+	c.Assert(err, gc.IsNil)
+	c.Assert(conf.CACert(), gc.Equals, attributeParams.CACert)
+
+	newCACert := "new CA cert\n" + attributeParams.CACert
+	conf.SetCACert(newCACert)
+	c.Assert(conf.CACert(), gc.Equals, newCACert)
+	c.Assert(conf.APIInfo().CACert, gc.Equals, newCACert)
+}
@@ -38,6 +38,55 @@ func ParseCert(certPEM string) (*x509.Certificate, error) {
 	return nil, errors.New("no certificates found")
 }
 
+// ParseCerts parses all the PEM-formatted X509 certificates in the
+// given data, in order. It's used when several CA certificates are
+// trusted at once, as while certificates are being rotated.
+func ParseCerts(certsPEM string) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	certsPEMData := []byte(certsPEM)
+	for len(certsPEMData) > 0 {
+		var certBlock *pem.Block
+		certBlock, certsPEMData = pem.Decode(certsPEMData)
+		if certBlock == nil {
+			break
+		}
+		if certBlock.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(certBlock.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificates found")
+	}
+	return certs, nil
+}
+
+// NewCertPool returns a certificate pool holding all the
+// PEM-formatted X509 certificates in the given data.
+func NewCertPool(certsPEM string) (*x509.CertPool, error) {
+	certs, err := ParseCerts(certsPEM)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	for _, cert := range certs {
+		pool.AddCert(cert)
+	}
+	return pool, nil
+}
+
+// EncodeCert returns the given certificate in PEM format.
+func EncodeCert(cert *x509.Certificate) string {
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: cert.Raw,
+	}))
+}
+
 // ParseCertAndKey parses the given PEM-formatted X509 certificate
 // and RSA private key.
 func ParseCertAndKey(certPEM, keyPEM string) (*x509.Certificate, *rsa.PrivateKey, error) {
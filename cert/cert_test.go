@@ -43,6 +43,34 @@ func (certSuite) TestParseCertificate(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "no certificates found")
 }
 
+func (certSuite) TestParseCerts(c *gc.C) {
+	otherCertPEM, _, err := cert.NewCA("foo", time.Now().AddDate(0, 0, 1))
+	c.Assert(err, gc.IsNil)
+
+	xcerts, err := cert.ParseCerts(otherCertPEM + caKeyPEM + caCertPEM)
+	c.Assert(err, gc.IsNil)
+	c.Assert(xcerts, gc.HasLen, 2)
+	c.Assert(xcerts[0].Subject.CommonName, gc.Equals, `juju-generated CA for environment "foo"`)
+	c.Assert(xcerts[1].Subject.CommonName, gc.Equals, "juju testing")
+	c.Assert(cert.EncodeCert(xcerts[0]), gc.Equals, otherCertPEM)
+
+	xcerts, err = cert.ParseCerts(caKeyPEM)
+	c.Check(xcerts, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "no certificates found")
+}
+
+func (certSuite) TestNewCertPool(c *gc.C) {
+	otherCertPEM, _, err := cert.NewCA("foo", time.Now().AddDate(0, 0, 1))
+	c.Assert(err, gc.IsNil)
+
+	pool, err := cert.NewCertPool(caCertPEM + otherCertPEM)
+	c.Assert(err, gc.IsNil)
+	c.Assert(pool.Subjects(), gc.HasLen, 2)
+
+	_, err = cert.NewCertPool("hello")
+	c.Assert(err, gc.ErrorMatches, "no certificates found")
+}
+
 func (certSuite) TestParseCertAndKey(c *gc.C) {
 	xcert, key, err := cert.ParseCertAndKey(caCertPEM, caKeyPEM)
 	c.Assert(err, gc.IsNil)
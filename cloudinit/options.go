@@ -69,6 +69,13 @@ func (cfg *Config) SetAptMirror(url string) {
 	cfg.set("apt_mirror", url != "", url)
 }
 
+// AptMirror returns the value set by SetAptMirror, or
+// the empty string if no mirror has been set.
+func (cfg *Config) AptMirror() string {
+	mirror, _ := cfg.attrs["apt_mirror"].(string)
+	return mirror
+}
+
 // SetAptPreserveSourcesList sets whether /etc/apt/sources.list
 // is overwritten by the mirror. If true, SetAptMirror above
 // will have no effect.
@@ -121,10 +121,31 @@ func ConfigureScript(cloudcfg *cloudinit.Config) (string, error) {
 //    to always keep old configuration files in the face of change.
 const aptget = "apt-get --option Dpkg::Options::=--force-confold --assume-yes "
 
+// aptSourcesList is the file holding the machine's default apt sources.
+const aptSourcesList = "/etc/apt/sources.list"
+
+// sedReplacementEscaper escapes the characters that are special
+// in the replacement of a sed s command delimited by commas.
+var sedReplacementEscaper = strings.NewReplacer(`\`, `\\`, `&`, `\&`, `,`, `\,`)
+
+// aptMirrorCommand returns a command that, when run, will replace
+// the Ubuntu archive in the default apt sources with the given
+// mirror, as cloud-init does for its apt_mirror option.
+func aptMirrorCommand(mirror string) string {
+	mirror = strings.TrimSuffix(mirror, "/") + "/"
+	expr := `s,https?://([a-z0-9-]+\.)*archive\.ubuntu\.com/ubuntu/?,` +
+		sedReplacementEscaper.Replace(mirror) + `,`
+	return "sed -r -i -e " + utils.ShQuote(expr) + " " + aptSourcesList
+}
+
 // addPackageCommands returns a slice of commands that, when run,
 // will add the required apt repositories and packages.
 func addPackageCommands(cfg *cloudinit.Config) ([]string, error) {
 	var cmds []string
+	if mirror := cfg.AptMirror(); mirror != "" {
+		cmds = append(cmds, cloudinit.LogProgressCmd("Changing apt mirror to %s", mirror))
+		cmds = append(cmds, aptMirrorCommand(mirror))
+	}
 	if len(cfg.AptSources()) > 0 {
 		// Ensure add-apt-repository is available.
 		cmds = append(cmds, cloudinit.LogProgressCmd("Installing add-apt-repository"))
@@ -148,7 +169,7 @@ func addPackageCommands(cfg *cloudinit.Config) ([]string, error) {
 			cmds = append(cmds, `printf '%s\n' `+contents+` > `+path)
 		}
 	}
-	if len(cfg.AptSources()) > 0 || cfg.AptUpdate() {
+	if len(cfg.AptSources()) > 0 || cfg.AptMirror() != "" || cfg.AptUpdate() {
 		cmds = append(cmds, cloudinit.LogProgressCmd("Running apt-get update"))
 		cmds = append(cmds, aptget+"update")
 	}
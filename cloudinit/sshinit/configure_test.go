@@ -162,3 +162,19 @@ func (s *configureSuite) TestAptUpgrade(c *gc.C) {
 	cfg.SetAptUpgrade(true)
 	assertScriptMatches(c, cfg, aptGetUpgradePattern, true)
 }
+
+func (s *configureSuite) TestAptMirror(c *gc.C) {
+	// The archive is replaced in the default apt sources,
+	// and apt-get update run, if AptMirror is set.
+	aptMirrorPattern := "(.|\n)*" + regexp.QuoteMeta(
+		`sed -r -i -e 's,https?://([a-z0-9-]+\.)*archive\.ubuntu\.com/ubuntu/?,http://mirror.example.com/ubuntu/,' /etc/apt/sources.list`,
+	) + "\n(.|\n)*" + aptgetRegexp + "update(.|\n)*"
+	cfg := cloudinit.New()
+	assertScriptMatches(c, cfg, aptMirrorPattern, false)
+	cfg.SetAptMirror("http://mirror.example.com/ubuntu")
+	assertScriptMatches(c, cfg, aptMirrorPattern, true)
+
+	// Characters special to sed are escaped.
+	cfg.SetAptMirror("http://mirror.example.com/a,b&c/")
+	assertScriptMatches(c, cfg, "(.|\n)*"+regexp.QuoteMeta(`,http://mirror.example.com/a\,b\&c/,'`)+"(.|\n)*", true)
+}
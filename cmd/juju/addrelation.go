@@ -18,11 +18,28 @@ type AddRelationCommand struct {
 	Endpoints []string
 }
 
+var jujuAddRelationHelp = `
+Adds a relation between two services. Where a service has more than one
+endpoint that could take part in the relation, the relation name must
+be given too.
+
+A service offered by another environment hosted by the same state
+server may be related to by giving the offer's URL, as printed by
+"juju offer", in place of a service name. The offered service then
+appears in this environment as a remote service of the same name.
+
+Examples:
+
+   juju add-relation wordpress mysql
+   juju add-relation wordpress:db sandbox.mysql:server
+`
+
 func (c *AddRelationCommand) Info() *cmd.Info {
 	return &cmd.Info{
 		Name:    "add-relation",
 		Args:    "<service1>[:<relation name1>] <service2>[:<relation name2>]",
 		Purpose: "add a relation between two services",
+		Doc:     jujuAddRelationHelp,
 	}
 }
 
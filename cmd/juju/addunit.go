@@ -12,18 +12,22 @@ import (
 
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/juju"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/storage"
 )
 
 // UnitCommandBase provides support for commands which deploy units. It handles the parsing
-// and validation of --to and --num-units arguments.
+// and validation of --to, --num-units and --storage arguments.
 type UnitCommandBase struct {
 	ToMachineSpec string
 	NumUnits      int
+	Storage       map[string]storage.Directive
 }
 
 func (c *UnitCommandBase) SetFlags(f *gnuflag.FlagSet) {
 	f.IntVar(&c.NumUnits, "num-units", 1, "")
-	f.StringVar(&c.ToMachineSpec, "to", "", "the machine or container to deploy the unit in, bypasses constraints")
+	f.StringVar(&c.ToMachineSpec, "to", "", "the machine, container or placement directive to deploy the unit to")
+	f.Var(storageFlag{&c.Storage}, "storage", "storage to create for each unit, as <store>=[pool,]size[,count][,kind]")
 }
 
 func (c *UnitCommandBase) Init(args []string) error {
@@ -34,13 +38,20 @@ func (c *UnitCommandBase) Init(args []string) error {
 		if c.NumUnits > 1 {
 			return errors.New("cannot use --num-units > 1 with --to")
 		}
-		if !cmd.IsMachineOrNewContainer(c.ToMachineSpec) {
+		if !isValidToSpec(c.ToMachineSpec) {
 			return fmt.Errorf("invalid --to parameter %q", c.ToMachineSpec)
 		}
 	}
 	return nil
 }
 
+// isValidToSpec reports whether spec is an existing machine or
+// container, a new container, or an environment-specific placement
+// directive for a new machine, such as "zone=us-east-1c".
+func isValidToSpec(spec string) bool {
+	return cmd.IsMachineOrNewContainer(spec) || juju.IsPlacementDirective(spec)
+}
+
 // AddUnitCommand is responsible adding additional units to a service.
 type AddUnitCommand struct {
 	envcmd.EnvCommandBase
@@ -55,13 +66,21 @@ have already been deployed via juju deploy.
 
 By default, services are deployed to newly provisioned machines.  Alternatively,
 service units can be added to a specific existing machine using the --to
-argument.
+argument. The --to argument may also hold a placement directive for the
+new machine, such as an availability zone; the directives available
+depend on the provider.
+
+The storage created for each new unit is the same as for the service's
+existing units, unless overridden with --storage, as described in
+"juju help deploy".
 
 Examples:
  juju add-unit mysql -n 5          (Add 5 mysql units on 5 new machines)
  juju add-unit mysql --to 23       (Add a mysql unit to machine 23)
  juju add-unit mysql --to 24/lxc/3 (Add unit to lxc container 3 on host machine 24)
  juju add-unit mysql --to lxc:25   (Add unit to a new lxc container on host machine 25)
+ juju add-unit mysql --to zone=us-east-1c (Add unit to a new machine in zone us-east-1c)
+ juju add-unit mysql --storage data=20G (Add a unit with a 20GB data volume)
 `
 
 func (c *AddUnitCommand) Info() *cmd.Info {
@@ -100,6 +119,13 @@ func (c *AddUnitCommand) Run(_ *cmd.Context) error {
 	}
 	defer apiclient.Close()
 
-	_, err = apiclient.AddServiceUnits(c.ServiceName, c.NumUnits, c.ToMachineSpec)
+	if len(c.Storage) == 0 {
+		_, err = apiclient.AddServiceUnits(c.ServiceName, c.NumUnits, c.ToMachineSpec)
+		return err
+	}
+	_, err = apiclient.AddServiceUnitsWithStorage(c.ServiceName, c.NumUnits, c.ToMachineSpec, c.Storage)
+	if params.IsCodeNotImplemented(err) {
+		return errors.New("cannot use --storage: not supported by the API server")
+	}
 	return err
 }
@@ -34,6 +34,9 @@ var initAddUnitErrorTests = []struct {
 	}, {
 		args: []string{"some-service-name", "-n", "2", "--to", "123"},
 		err:  `cannot use --num-units > 1 with --to`,
+	}, {
+		args: []string{"some-service-name", "--to", "lxc:zone=a"},
+		err:  `invalid --to parameter "lxc:zone=a"`,
 	},
 }
 
@@ -45,6 +48,13 @@ func (s *AddUnitSuite) TestInitErrors(c *gc.C) {
 	}
 }
 
+func (s *AddUnitSuite) TestInitPlacementDirective(c *gc.C) {
+	addUnitCmd := &AddUnitCommand{}
+	err := testing.InitCommand(envcmd.Wrap(addUnitCmd), []string{"some-service-name", "--to", "zone=us-east-1c"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(addUnitCmd.ToMachineSpec, gc.Equals, "zone=us-east-1c")
+}
+
 func runAddUnit(c *gc.C, args ...string) error {
 	_, err := testing.RunCommand(c, envcmd.Wrap(&AddUnitCommand{}), args...)
 	return err
@@ -71,6 +81,22 @@ func (s *AddUnitSuite) TestAddUnit(c *gc.C) {
 	s.AssertService(c, "some-service-name", curl, 4, 0)
 }
 
+func (s *AddUnitSuite) TestAddUnitWithStorage(c *gc.C) {
+	curl := s.setupService(c)
+
+	err := runAddUnit(c, "some-service-name", "--storage", "data=loop,1G,2")
+	c.Assert(err, gc.IsNil)
+	svc, _ := s.AssertService(c, "some-service-name", curl, 2, 0)
+	units, err := svc.AllUnits()
+	c.Assert(err, gc.IsNil)
+	instances, err := units[1].StorageInstances()
+	c.Assert(err, gc.IsNil)
+	c.Assert(instances, gc.HasLen, 2)
+	for _, si := range instances {
+		c.Assert(si.StorageName(), gc.Equals, "data")
+	}
+}
+
 // assertForceMachine ensures that the result of assigning a unit with --to
 // is as expected.
 func (s *AddUnitSuite) assertForceMachine(c *gc.C, svc *state.Service, expectedNumMachines, unitNum int, machineId string) {
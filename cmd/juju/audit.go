@@ -0,0 +1,170 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/juju"
+	"github.com/juju/juju/state/api/params"
+)
+
+const auditDoc = `
+audit shows the audit trail of the changes made to the environment
+through the API: the services deployed, reconfigured and destroyed, the
+machines added and removed, and so on. Each entry records when the call
+was made, by which user, the call's arguments (with any secrets
+redacted) and whether it failed. Read-only calls, such as status, are
+not recorded.
+
+The oldest entries are discarded once the audit trail grows too large.
+
+Times are given in UTC, as YYYY-MM-DD or in RFC 3339 format.
+
+Examples:
+
+   juju audit --user bob                   (show the changes made by user bob)
+   juju audit --entity wordpress           (show the changes that mention wordpress)
+   juju audit --after 2014-07-01 --before 2014-08-01
+`
+
+// AuditCommand shows the audit trail of the environment.
+type AuditCommand struct {
+	envcmd.EnvCommandBase
+	out    cmd.Output
+	user   string
+	entity string
+	after  timeValue
+	before timeValue
+	limit  int
+}
+
+func (c *AuditCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "audit",
+		Purpose: "show the audit trail of changes made to the environment",
+		Doc:     auditDoc,
+	}
+}
+
+func (c *AuditCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "line", map[string]cmd.Formatter{
+		"line": formatAuditLines,
+		"yaml": cmd.FormatYaml,
+		"json": cmd.FormatJson,
+	})
+	f.StringVar(&c.user, "user", "", "only show changes made by this user")
+	f.StringVar(&c.entity, "entity", "", "only show changes mentioning this service, unit or machine")
+	f.Var(&c.after, "after", "only show changes made at or after this time")
+	f.Var(&c.before, "before", "only show changes made before this time")
+	f.IntVar(&c.limit, "limit", 0, "only show this many of the most recent changes")
+}
+
+func (c *AuditCommand) Init(args []string) error {
+	if c.user != "" && !names.IsUser(c.user) {
+		return fmt.Errorf("invalid user name %q", c.user)
+	}
+	if c.limit < 0 {
+		return fmt.Errorf("invalid limit %d", c.limit)
+	}
+	return cmd.CheckEmpty(args)
+}
+
+// auditAPI holds the methods of the AuditLog API used by
+// the audit command.
+type auditAPI interface {
+	Entries(filter params.AuditLogFilter) ([]params.AuditLogEntry, error)
+	Close() error
+}
+
+var getAuditAPI = func(c *AuditCommand) (auditAPI, error) {
+	return juju.NewAuditLogClient(c.EnvName)
+}
+
+// auditEntry is the serialisation format of an audit entry.
+type auditEntry struct {
+	Time     string   `json:"time" yaml:"time"`
+	User     string   `json:"user" yaml:"user"`
+	Call     string   `json:"call" yaml:"call"`
+	Args     string   `json:"args,omitempty" yaml:"args,omitempty"`
+	Entities []string `json:"entities,omitempty" yaml:"entities,omitempty"`
+	Error    string   `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func (c *AuditCommand) Run(ctx *cmd.Context) error {
+	client, err := getAuditAPI(c)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	filter := params.AuditLogFilter{
+		Entity: c.entity,
+		After:  c.after.Time,
+		Before: c.before.Time,
+		Limit:  c.limit,
+	}
+	if c.user != "" {
+		filter.User = names.NewUserTag(c.user).String()
+	}
+	entries, err := client.Entries(filter)
+	if err != nil {
+		return err
+	}
+	out := make([]auditEntry, len(entries))
+	for i, entry := range entries {
+		out[i] = auditEntry{
+			Time:     entry.Time.UTC().Format(time.RFC3339),
+			User:     strings.TrimPrefix(entry.User, names.UserTagKind+"-"),
+			Call:     entry.Facade + "." + entry.Method,
+			Args:     entry.Args,
+			Entities: entry.Entities,
+			Error:    entry.Error,
+		}
+	}
+	return c.out.Write(ctx, out)
+}
+
+// formatAuditLines formats audit entries one per line.
+func formatAuditLines(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, entry := range value.([]auditEntry) {
+		fmt.Fprintf(&buf, "%s %s %s %s", entry.Time, entry.User, entry.Call, entry.Args)
+		if entry.Error != "" {
+			fmt.Fprintf(&buf, " (error: %s)", entry.Error)
+		}
+		buf.WriteString("\n")
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// timeValue implements gnuflag.Value for a time given
+// as a date or in RFC 3339 format.
+type timeValue struct {
+	time.Time
+}
+
+func (v *timeValue) Set(s string) error {
+	for _, layout := range []string{"2006-01-02", time.RFC3339} {
+		if t, err := time.Parse(layout, s); err == nil {
+			v.Time = t
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid time %q", s)
+}
+
+func (v *timeValue) String() string {
+	if v.IsZero() {
+		return ""
+	}
+	return v.Format(time.RFC3339)
+}
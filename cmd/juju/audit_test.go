@@ -0,0 +1,149 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"errors"
+	"time"
+
+	"github.com/juju/cmd"
+	gc "launchpad.net/gocheck"
+	"launchpad.net/goyaml"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/testing"
+)
+
+type AuditCommandSuite struct {
+	testing.FakeJujuHomeSuite
+	mockAPI *mockAuditAPI
+}
+
+var _ = gc.Suite(&AuditCommandSuite{})
+
+func (s *AuditCommandSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.mockAPI = &mockAuditAPI{
+		entries: []params.AuditLogEntry{{
+			Time:     time.Date(2014, 7, 14, 12, 0, 0, 0, time.UTC),
+			User:     "user-admin",
+			Facade:   "Client",
+			Method:   "ServiceExpose",
+			Args:     `{"ServiceName":"wordpress"}`,
+			Entities: []string{"wordpress"},
+		}, {
+			Time:     time.Date(2014, 7, 14, 12, 1, 0, 0, time.UTC),
+			User:     "user-bob",
+			Facade:   "Client",
+			Method:   "ServiceDestroy",
+			Args:     `{"ServiceName":"mysql"}`,
+			Entities: []string{"mysql"},
+			Error:    `service "mysql" not found`,
+		}},
+	}
+	s.PatchValue(&getAuditAPI, func(c *AuditCommand) (auditAPI, error) {
+		return s.mockAPI, nil
+	})
+}
+
+func newAuditCommand() cmd.Command {
+	return envcmd.Wrap(&AuditCommand{})
+}
+
+func (s *AuditCommandSuite) TestAudit(c *gc.C) {
+	context, err := testing.RunCommand(c, newAuditCommand())
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mockAPI.filter, gc.DeepEquals, params.AuditLogFilter{})
+	c.Assert(testing.Stdout(context), gc.Equals, ""+
+		`2014-07-14T12:00:00Z admin Client.ServiceExpose {"ServiceName":"wordpress"}`+"\n"+
+		`2014-07-14T12:01:00Z bob Client.ServiceDestroy {"ServiceName":"mysql"} (error: service "mysql" not found)`+"\n",
+	)
+	c.Assert(s.mockAPI.closed, gc.Equals, true)
+}
+
+func (s *AuditCommandSuite) TestAuditYAML(c *gc.C) {
+	s.mockAPI.entries = s.mockAPI.entries[:1]
+	context, err := testing.RunCommand(c, newAuditCommand(), "--format", "yaml")
+	c.Assert(err, gc.IsNil)
+	var out []map[string]interface{}
+	err = goyaml.Unmarshal([]byte(testing.Stdout(context)), &out)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, []map[string]interface{}{{
+		"time":     "2014-07-14T12:00:00Z",
+		"user":     "admin",
+		"call":     "Client.ServiceExpose",
+		"args":     `{"ServiceName":"wordpress"}`,
+		"entities": []interface{}{"wordpress"},
+	}})
+}
+
+func (s *AuditCommandSuite) TestAuditFilters(c *gc.C) {
+	_, err := testing.RunCommand(c, newAuditCommand(),
+		"--user", "bob",
+		"--entity", "wordpress/0",
+		"--after", "2014-07-01",
+		"--before", "2014-07-14T12:30:00Z",
+		"--limit", "10",
+	)
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mockAPI.filter, gc.DeepEquals, params.AuditLogFilter{
+		User:   "user-bob",
+		Entity: "wordpress/0",
+		After:  time.Date(2014, 7, 1, 0, 0, 0, 0, time.UTC),
+		Before: time.Date(2014, 7, 14, 12, 30, 0, 0, time.UTC),
+		Limit:  10,
+	})
+}
+
+func (s *AuditCommandSuite) TestAuditError(c *gc.C) {
+	s.mockAPI.err = errors.New("permission denied")
+	_, err := testing.RunCommand(c, newAuditCommand())
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+var auditInitErrorTests = []struct {
+	args []string
+	err  string
+}{{
+	args: []string{"--user", "Bob!"},
+	err:  `invalid user name "Bob!"`,
+}, {
+	args: []string{"--after", "yesterday"},
+	err:  `invalid value "yesterday" for flag --after: invalid time "yesterday"`,
+}, {
+	args: []string{"--limit", "-1"},
+	err:  "invalid limit -1",
+}, {
+	args: []string{"extra"},
+	err:  `unrecognized args: \["extra"\]`,
+}}
+
+func (s *AuditCommandSuite) TestInitErrors(c *gc.C) {
+	for i, test := range auditInitErrorTests {
+		c.Logf("test %d: %v", i, test.args)
+		err := testing.InitCommand(&AuditCommand{}, test.args)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+type mockAuditAPI struct {
+	entries []params.AuditLogEntry
+	filter  params.AuditLogFilter
+	err     error
+	closed  bool
+}
+
+func (m *mockAuditAPI) Entries(filter params.AuditLogFilter) ([]params.AuditLogEntry, error) {
+	m.filter = filter
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.entries, nil
+}
+
+func (m *mockAuditAPI) Close() error {
+	m.closed = true
+	return nil
+}
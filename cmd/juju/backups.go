@@ -0,0 +1,80 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/juju/cmd"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/juju"
+	"github.com/juju/juju/state/api/params"
+)
+
+const backupsCommandDoc = `
+"juju backups" is used to manage backups of the state server.
+
+Backups are made and stored by the state server itself, so no shell
+access to the state server machine is required.
+`
+
+const backupsCommandPurpose = "create, manage and restore backups of the state server"
+
+type BackupsCommand struct {
+	*cmd.SuperCommand
+}
+
+func NewBackupsCommand() cmd.Command {
+	backupscmd := &BackupsCommand{
+		SuperCommand: cmd.NewSuperCommand(cmd.SuperCommandParams{
+			Name:        "backups",
+			Doc:         backupsCommandDoc,
+			UsagePrefix: "juju",
+			Purpose:     backupsCommandPurpose,
+		}),
+	}
+	// Define each subcommand in a separate "backups_FOO.go" source
+	// file and wire in here.
+	backupscmd.Register(envcmd.Wrap(&BackupsCreateCommand{}))
+	backupscmd.Register(envcmd.Wrap(&BackupsListCommand{}))
+	backupscmd.Register(envcmd.Wrap(&BackupsDownloadCommand{}))
+	backupscmd.Register(envcmd.Wrap(&BackupsRemoveCommand{}))
+	backupscmd.Register(envcmd.Wrap(&BackupsRestoreCommand{}))
+	return backupscmd
+}
+
+// backupsAPI holds the methods of the Backups API used by the
+// backups subcommands.
+type backupsAPI interface {
+	Create(notes string) (*params.BackupsMetadataResult, error)
+	Info(id string) (*params.BackupsMetadataResult, error)
+	List() ([]params.BackupsMetadataResult, error)
+	Download(id string) (io.ReadCloser, string, error)
+	Remove(id string) error
+	Restore(id string) error
+	Close() error
+}
+
+var getBackupsAPI = func(envName string) (backupsAPI, error) {
+	return juju.NewBackupsClient(envName)
+}
+
+// dumpBackupMetadata writes a human readable description of the
+// given backup to w.
+func dumpBackupMetadata(w io.Writer, result *params.BackupsMetadataResult) {
+	fmt.Fprintf(w, "backup ID:       %s\n", result.ID)
+	fmt.Fprintf(w, "checksum:        %s\n", result.Checksum)
+	fmt.Fprintf(w, "checksum format: %s\n", result.ChecksumFormat)
+	fmt.Fprintf(w, "size (B):        %d\n", result.Size)
+	fmt.Fprintf(w, "stored:          %t\n", result.Stored)
+	fmt.Fprintf(w, "started:         %v\n", result.Started)
+	fmt.Fprintf(w, "finished:        %v\n", result.Finished)
+	fmt.Fprintf(w, "notes:           %s\n", result.Notes)
+	fmt.Fprintf(w, "environment ID:  %s\n", result.Environment)
+	fmt.Fprintf(w, "machine ID:      %s\n", result.Machine)
+	fmt.Fprintf(w, "created on host: %s\n", result.Hostname)
+	fmt.Fprintf(w, "juju version:    %v\n", result.Version)
+}
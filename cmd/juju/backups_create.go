@@ -0,0 +1,69 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+const backupsCreateDoc = `
+Create a new backup of the state server. The backup archive holds a
+dump of the database along with the state server's configuration,
+certificates and logs, and is stored by the state server. Its metadata
+is printed on success; use --quiet to print only the backup ID.
+
+Examples:
+  juju backups create
+  juju backups create "before upgrading to 1.20"
+`
+
+// BackupsCreateCommand creates a new backup.
+type BackupsCreateCommand struct {
+	envcmd.EnvCommandBase
+	Notes string
+	Quiet bool
+}
+
+func (c *BackupsCreateCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "create",
+		Args:    "[<notes>]",
+		Purpose: "create a backup",
+		Doc:     backupsCreateDoc,
+	}
+}
+
+func (c *BackupsCreateCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.BoolVar(&c.Quiet, "quiet", false, "only print the backup ID")
+}
+
+func (c *BackupsCreateCommand) Init(args []string) error {
+	if len(args) > 0 {
+		c.Notes, args = args[0], args[1:]
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *BackupsCreateCommand) Run(ctx *cmd.Context) error {
+	client, err := getBackupsAPI(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	result, err := client.Create(c.Notes)
+	if err != nil {
+		return err
+	}
+	if c.Quiet {
+		fmt.Fprintln(ctx.Stdout, result.ID)
+		return nil
+	}
+	dumpBackupMetadata(ctx.Stdout, result)
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/state/backups"
+)
+
+const backupsDownloadDoc = `
+Download the archive of a backup to a local file. The archive is
+verified against the checksum recorded by the state server.
+
+Examples:
+  juju backups download 20140714-123000.<env UUID>
+  juju backups download 20140714-123000.<env UUID> --filename backup.tar.gz
+`
+
+// BackupsDownloadCommand downloads the archive of a backup.
+type BackupsDownloadCommand struct {
+	envcmd.EnvCommandBase
+	ID       string
+	Filename string
+}
+
+func (c *BackupsDownloadCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "download",
+		Args:    "<backup ID>",
+		Purpose: "download a backup archive",
+		Doc:     backupsDownloadDoc,
+	}
+}
+
+func (c *BackupsDownloadCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.Filename, "filename", "", "the file to write the archive to (defaults to juju-backup-<backup ID>.tar.gz)")
+}
+
+func (c *BackupsDownloadCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no backup ID specified")
+	}
+	c.ID, args = args[0], args[1:]
+	return cmd.CheckEmpty(args)
+}
+
+func (c *BackupsDownloadCommand) Run(ctx *cmd.Context) error {
+	client, err := getBackupsAPI(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	archive, checksum, err := client.Download(c.ID)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	filename := c.Filename
+	if filename == "" {
+		filename = fmt.Sprintf("juju-backup-%s.tar.gz", c.ID)
+	}
+	path := ctx.AbsPath(filename)
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Annotate(err, "cannot create archive file")
+	}
+	defer f.Close()
+	got, err := backups.Checksum(io.TeeReader(archive, f))
+	if err != nil {
+		return errors.Annotate(err, "cannot download archive")
+	}
+	if err := f.Close(); err != nil {
+		return errors.Annotate(err, "cannot write archive file")
+	}
+	if checksum != "" && got != checksum {
+		os.Remove(path)
+		return fmt.Errorf("downloaded archive is corrupt: checksum mismatch")
+	}
+	fmt.Fprintf(ctx.Stdout, "backup archive written to %s\n", path)
+	return nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+const backupsListDoc = `
+List the backups of the state server, oldest first. With --brief,
+only the backup IDs are printed.
+`
+
+// BackupsListCommand lists the existing backups.
+type BackupsListCommand struct {
+	envcmd.EnvCommandBase
+	Brief bool
+}
+
+func (c *BackupsListCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "list",
+		Purpose: "list the backups",
+		Doc:     backupsListDoc,
+	}
+}
+
+func (c *BackupsListCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.BoolVar(&c.Brief, "brief", false, "only print the backup IDs")
+}
+
+func (c *BackupsListCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+func (c *BackupsListCommand) Run(ctx *cmd.Context) error {
+	client, err := getBackupsAPI(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	list, err := client.List()
+	if err != nil {
+		return err
+	}
+	for i, result := range list {
+		if c.Brief {
+			fmt.Fprintln(ctx.Stdout, result.ID)
+			continue
+		}
+		if i > 0 {
+			fmt.Fprintln(ctx.Stdout)
+		}
+		dumpBackupMetadata(ctx.Stdout, &result)
+	}
+	return nil
+}
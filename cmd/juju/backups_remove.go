@@ -0,0 +1,48 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+const backupsRemoveDoc = `
+Remove a backup and its archive from the state server.
+`
+
+// BackupsRemoveCommand removes a backup.
+type BackupsRemoveCommand struct {
+	envcmd.EnvCommandBase
+	ID string
+}
+
+func (c *BackupsRemoveCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "remove",
+		Args:    "<backup ID>",
+		Purpose: "remove a backup",
+		Doc:     backupsRemoveDoc,
+	}
+}
+
+func (c *BackupsRemoveCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no backup ID specified")
+	}
+	c.ID, args = args[0], args[1:]
+	return cmd.CheckEmpty(args)
+}
+
+func (c *BackupsRemoveCommand) Run(ctx *cmd.Context) error {
+	client, err := getBackupsAPI(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return client.Remove(c.ID)
+}
@@ -0,0 +1,61 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+const backupsRestoreDoc = `
+Restore the state server from a backup. The state server's database,
+configuration and certificates are replaced with those held in the
+backup archive, after it has been verified against its recorded
+checksum. The backup must have been made of the same environment.
+The backups themselves are kept as they are.
+
+In an environment with several state servers, the agents of all but
+the one restored to must be stopped first. The state server's agents
+must be restarted once the restore has completed for the restored
+data to take effect.
+`
+
+// BackupsRestoreCommand restores the state server from a backup.
+type BackupsRestoreCommand struct {
+	envcmd.EnvCommandBase
+	ID string
+}
+
+func (c *BackupsRestoreCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "restore",
+		Args:    "<backup ID>",
+		Purpose: "restore the state server from a backup",
+		Doc:     backupsRestoreDoc,
+	}
+}
+
+func (c *BackupsRestoreCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no backup ID specified")
+	}
+	c.ID, args = args[0], args[1:]
+	return cmd.CheckEmpty(args)
+}
+
+func (c *BackupsRestoreCommand) Run(ctx *cmd.Context) error {
+	client, err := getBackupsAPI(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if err := client.Restore(c.ID); err != nil {
+		return err
+	}
+	fmt.Fprintf(ctx.Stdout, "restored backup %s; restart the state server agents to complete the restore\n", c.ID)
+	return nil
+}
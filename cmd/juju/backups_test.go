@@ -0,0 +1,231 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/juju/cmd"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/backups"
+	"github.com/juju/juju/testing"
+)
+
+type BackupsCommandSuite struct {
+	testing.FakeJujuHomeSuite
+	api *fakeBackupsAPI
+}
+
+var _ = gc.Suite(&BackupsCommandSuite{})
+
+func (s *BackupsCommandSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.api = &fakeBackupsAPI{
+		result: params.BackupsMetadataResult{
+			ID:             "20140714-123000.env-uuid",
+			Checksum:       "checksum",
+			ChecksumFormat: backups.ChecksumFormat,
+			Size:           12,
+			Stored:         true,
+			Started:        time.Date(2014, 7, 14, 12, 30, 0, 0, time.UTC),
+			Finished:       time.Date(2014, 7, 14, 12, 31, 0, 0, time.UTC),
+			Notes:          "some notes",
+			Environment:    "env-uuid",
+			Machine:        "0",
+			Hostname:       "juju-state-server",
+		},
+		archive: "archive data",
+	}
+	s.PatchValue(&getBackupsAPI, func(envName string) (backupsAPI, error) {
+		return s.api, nil
+	})
+}
+
+func (s *BackupsCommandSuite) TestHelp(c *gc.C) {
+	ctx, err := testing.RunCommand(c, NewBackupsCommand(), "--help")
+	c.Assert(err, gc.IsNil)
+	out := testing.Stdout(ctx)
+	for _, name := range []string{"create", "download", "list", "remove", "restore"} {
+		c.Check(out, gc.Matches, "(?s).*\n    "+name+" .*")
+	}
+}
+
+func (s *BackupsCommandSuite) TestCreate(c *gc.C) {
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&BackupsCreateCommand{}), "some notes")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.api.calls, gc.DeepEquals, []string{"Create some notes", "Close"})
+	c.Assert(testing.Stdout(ctx), gc.Matches, "(?s)backup ID:       20140714-123000.env-uuid\n.*notes:           some notes\n.*")
+}
+
+func (s *BackupsCommandSuite) TestCreateQuiet(c *gc.C) {
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&BackupsCreateCommand{}), "--quiet")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.api.calls, gc.DeepEquals, []string{"Create ", "Close"})
+	c.Assert(testing.Stdout(ctx), gc.Equals, "20140714-123000.env-uuid\n")
+}
+
+func (s *BackupsCommandSuite) TestCreateError(c *gc.C) {
+	s.api.err = errors.New("boom")
+	_, err := testing.RunCommand(c, envcmd.Wrap(&BackupsCreateCommand{}))
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *BackupsCommandSuite) TestListBrief(c *gc.C) {
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&BackupsListCommand{}), "--brief")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.api.calls, gc.DeepEquals, []string{"List", "Close"})
+	c.Assert(testing.Stdout(ctx), gc.Equals, "20140714-123000.env-uuid\n20140714-123000.env-uuid\n")
+}
+
+func (s *BackupsCommandSuite) TestList(c *gc.C) {
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&BackupsListCommand{}))
+	c.Assert(err, gc.IsNil)
+	c.Assert(testing.Stdout(ctx), gc.Matches, "(?s)backup ID: .*\n\nbackup ID: .*")
+}
+
+func (s *BackupsCommandSuite) TestDownload(c *gc.C) {
+	checksum, err := backups.Checksum(bytes.NewReader([]byte(s.api.archive)))
+	c.Assert(err, gc.IsNil)
+	s.api.checksum = checksum
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&BackupsDownloadCommand{}), "20140714-123000.env-uuid")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.api.calls, gc.DeepEquals, []string{"Download 20140714-123000.env-uuid", "Close"})
+	path := filepath.Join(ctx.Dir, "juju-backup-20140714-123000.env-uuid.tar.gz")
+	c.Assert(testing.Stdout(ctx), gc.Equals, "backup archive written to "+path+"\n")
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(data), gc.Equals, "archive data")
+}
+
+func (s *BackupsCommandSuite) TestDownloadFilename(c *gc.C) {
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&BackupsDownloadCommand{}), "some-id", "--filename", "backup.tar.gz")
+	c.Assert(err, gc.IsNil)
+	data, err := ioutil.ReadFile(filepath.Join(ctx.Dir, "backup.tar.gz"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(data), gc.Equals, "archive data")
+}
+
+func (s *BackupsCommandSuite) TestDownloadChecksumMismatch(c *gc.C) {
+	s.api.checksum = "bad checksum"
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&BackupsDownloadCommand{}), "some-id")
+	c.Assert(err, gc.ErrorMatches, "downloaded archive is corrupt: checksum mismatch")
+	_, err = ioutil.ReadFile(filepath.Join(ctx.Dir, "juju-backup-some-id.tar.gz"))
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
+
+func (s *BackupsCommandSuite) TestRemove(c *gc.C) {
+	_, err := testing.RunCommand(c, envcmd.Wrap(&BackupsRemoveCommand{}), "some-id")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.api.calls, gc.DeepEquals, []string{"Remove some-id", "Close"})
+}
+
+func (s *BackupsCommandSuite) TestRestore(c *gc.C) {
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&BackupsRestoreCommand{}), "some-id")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.api.calls, gc.DeepEquals, []string{"Restore some-id", "Close"})
+	c.Assert(testing.Stdout(ctx), gc.Matches, "restored backup some-id; restart the state server agents.*\n")
+}
+
+var backupsInitErrorTests = []struct {
+	command cmd.Command
+	args    []string
+	err     string
+}{{
+	command: &BackupsCreateCommand{},
+	args:    []string{"notes", "extra"},
+	err:     `unrecognized args: \["extra"\]`,
+}, {
+	command: &BackupsListCommand{},
+	args:    []string{"extra"},
+	err:     `unrecognized args: \["extra"\]`,
+}, {
+	command: &BackupsDownloadCommand{},
+	err:     "no backup ID specified",
+}, {
+	command: &BackupsRemoveCommand{},
+	err:     "no backup ID specified",
+}, {
+	command: &BackupsRestoreCommand{},
+	err:     "no backup ID specified",
+}, {
+	command: &BackupsRestoreCommand{},
+	args:    []string{"some-id", "extra"},
+	err:     `unrecognized args: \["extra"\]`,
+}}
+
+func (s *BackupsCommandSuite) TestInitErrors(c *gc.C) {
+	for i, test := range backupsInitErrorTests {
+		c.Logf("test %d: %T %v", i, test.command, test.args)
+		err := testing.InitCommand(test.command, test.args)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+type fakeBackupsAPI struct {
+	calls    []string
+	result   params.BackupsMetadataResult
+	archive  string
+	checksum string
+	err      error
+}
+
+func (f *fakeBackupsAPI) Create(notes string) (*params.BackupsMetadataResult, error) {
+	f.calls = append(f.calls, "Create "+notes)
+	if f.err != nil {
+		return nil, f.err
+	}
+	result := f.result
+	result.Notes = notes
+	return &result, nil
+}
+
+func (f *fakeBackupsAPI) Info(id string) (*params.BackupsMetadataResult, error) {
+	f.calls = append(f.calls, "Info "+id)
+	if f.err != nil {
+		return nil, f.err
+	}
+	result := f.result
+	return &result, nil
+}
+
+func (f *fakeBackupsAPI) List() ([]params.BackupsMetadataResult, error) {
+	f.calls = append(f.calls, "List")
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []params.BackupsMetadataResult{f.result, f.result}, nil
+}
+
+func (f *fakeBackupsAPI) Download(id string) (io.ReadCloser, string, error) {
+	f.calls = append(f.calls, "Download "+id)
+	if f.err != nil {
+		return nil, "", f.err
+	}
+	return ioutil.NopCloser(bytes.NewReader([]byte(f.archive))), f.checksum, nil
+}
+
+func (f *fakeBackupsAPI) Remove(id string) error {
+	f.calls = append(f.calls, "Remove "+id)
+	return f.err
+}
+
+func (f *fakeBackupsAPI) Restore(id string) error {
+	f.calls = append(f.calls, "Restore "+id)
+	return f.err
+}
+
+func (f *fakeBackupsAPI) Close() error {
+	f.calls = append(f.calls, "Close")
+	return nil
+}
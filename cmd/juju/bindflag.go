@@ -0,0 +1,51 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/juju/juju/network"
+)
+
+// bindFlag is a gnuflag.Value that accumulates endpoint bindings of
+// the form <endpoint>=<space>. Several bindings may be given in one
+// value, separated by spaces.
+type bindFlag struct {
+	bindings *map[string]string
+}
+
+// Set implements gnuflag.Value.Set.
+func (f bindFlag) Set(s string) error {
+	for _, binding := range strings.Fields(s) {
+		i := strings.Index(binding, "=")
+		if i <= 0 {
+			return fmt.Errorf("expected <endpoint>=<space>, got %q", binding)
+		}
+		endpoint, space := binding[:i], binding[i+1:]
+		if !network.IsValidSpaceName(space) {
+			return fmt.Errorf("%q is not a valid space name", space)
+		}
+		if *f.bindings == nil {
+			*f.bindings = make(map[string]string)
+		}
+		if _, ok := (*f.bindings)[endpoint]; ok {
+			return fmt.Errorf("endpoint %q bound more than once", endpoint)
+		}
+		(*f.bindings)[endpoint] = space
+	}
+	return nil
+}
+
+// String implements gnuflag.Value.String.
+func (f bindFlag) String() string {
+	var strs []string
+	for endpoint, space := range *f.bindings {
+		strs = append(strs, endpoint+"="+space)
+	}
+	sort.Strings(strs)
+	return strings.Join(strs, " ")
+}
@@ -0,0 +1,437 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/juju/charm"
+	"github.com/juju/cmd"
+	"launchpad.net/goyaml"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/state/api"
+)
+
+// bundleData holds the contents of a bundle file, which describes
+// a set of services and the relations between them.
+type bundleData struct {
+	Services  map[string]*bundleService `yaml:"services"`
+	Relations [][]string                `yaml:"relations"`
+}
+
+// bundleService describes a single service in a bundle.
+type bundleService struct {
+	// Charm holds the name or URL of the service's charm.
+	Charm string `yaml:"charm"`
+
+	// NumUnits holds the number of units of the service. If it is
+	// not set, principal services get one unit for each placement
+	// directive, or one unit if there are none.
+	NumUnits *int `yaml:"num_units"`
+
+	// Options holds the service's configuration settings.
+	Options map[string]interface{} `yaml:"options"`
+
+	// Constraints holds the service's constraints.
+	Constraints string `yaml:"constraints"`
+
+	// To holds the placement directives of the service's units, in
+	// the format accepted by deploy --to, e.g. "1" or "lxc:1".
+	To []string `yaml:"to"`
+}
+
+// readBundle reads and validates the bundle file at the given path.
+func readBundle(path string) (*bundleData, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var bundle bundleData
+	if err := goyaml.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("cannot parse bundle %q: %v", path, err)
+	}
+	if err := bundle.validate(); err != nil {
+		return nil, fmt.Errorf("invalid bundle %q: %v", path, err)
+	}
+	return &bundle, nil
+}
+
+func (b *bundleData) validate() error {
+	if len(b.Services) == 0 {
+		return fmt.Errorf("no services specified")
+	}
+	for name, svc := range b.Services {
+		if svc == nil || svc.Charm == "" {
+			return fmt.Errorf("no charm specified for service %q", name)
+		}
+		if svc.NumUnits != nil {
+			if *svc.NumUnits < 0 {
+				return fmt.Errorf("negative number of units specified for service %q", name)
+			}
+			if len(svc.To) > *svc.NumUnits {
+				return fmt.Errorf("too many placement directives for service %q", name)
+			}
+		}
+		for _, spec := range svc.To {
+			if !isValidToSpec(spec) {
+				return fmt.Errorf("invalid placement %q for service %q", spec, name)
+			}
+		}
+		if _, err := constraints.Parse(svc.Constraints); err != nil {
+			return fmt.Errorf("invalid constraints for service %q: %v", name, err)
+		}
+	}
+	for _, endpoints := range b.Relations {
+		if len(endpoints) != 2 {
+			return fmt.Errorf("relation %q does not have two endpoints", endpoints)
+		}
+		for _, ep := range endpoints {
+			service, _ := splitEndpoint(ep)
+			if b.Services[service] == nil {
+				return fmt.Errorf("relation %q refers to unknown service %q", endpoints, service)
+			}
+		}
+	}
+	return nil
+}
+
+// serviceNames returns the names of the bundle's services, sorted.
+func (b *bundleData) serviceNames() []string {
+	var names []string
+	for name := range b.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// splitEndpoint splits an endpoint of the form "service[:relation]"
+// into its service and relation names.
+func splitEndpoint(ep string) (service, relation string) {
+	if i := strings.Index(ep, ":"); i >= 0 {
+		return ep[:i], ep[i+1:]
+	}
+	return ep, ""
+}
+
+// bundleChange holds a single change needed to bring the
+// environment in line with a bundle.
+type bundleChange struct {
+	description string
+	apply       func() error
+}
+
+// bundleDeployer works out and makes the changes needed to
+// bring the environment in line with a bundle.
+type bundleDeployer struct {
+	ctx      *cmd.Context
+	client   *api.Client
+	conf     *config.Config
+	repoPath string
+	status   *api.Status
+
+	// charms maps the URLs of the charms added to the
+	// environment to the revisioned URLs they were added as.
+	charms map[string]*charm.URL
+}
+
+func newBundleDeployer(ctx *cmd.Context, client *api.Client, conf *config.Config, repoPath string) (*bundleDeployer, error) {
+	status, err := client.Status(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &bundleDeployer{
+		ctx:      ctx,
+		client:   client,
+		conf:     conf,
+		repoPath: repoPath,
+		status:   status,
+		charms:   make(map[string]*charm.URL),
+	}, nil
+}
+
+// plan returns the changes needed to deploy the given bundle. Services
+// that already exist are scaled up, reconfigured and constrained as the
+// bundle describes, but are never removed, scaled down or upgraded.
+func (d *bundleDeployer) plan(bundle *bundleData) ([]bundleChange, error) {
+	var changes []bundleChange
+	addedCharms := make(map[string]bool)
+	for _, name := range bundle.serviceNames() {
+		svc := bundle.Services[name]
+		curl, err := resolveCharmURL(svc.Charm, d.client, d.conf)
+		if err != nil {
+			return nil, err
+		}
+		existing, ok := d.status.Services[name]
+		if !ok {
+			if !addedCharms[curl.String()] {
+				changes = append(changes, d.addCharm(curl))
+				addedCharms[curl.String()] = true
+			}
+			changes = append(changes, d.deployService(name, curl, svc)...)
+			continue
+		}
+		if existingURL, err := charm.ParseURL(existing.Charm); err == nil {
+			if *existingURL.WithRevision(-1) != *curl.WithRevision(-1) {
+				d.ctx.Infof("service %q already uses charm %q; not changing it", name, existing.Charm)
+			}
+		}
+		if numUnits, ok := svc.numUnits(); ok {
+			changes = append(changes, d.addUnits(name, svc, len(existing.Units), numUnits)...)
+		}
+		configChange, err := d.setConfig(name, svc)
+		if err != nil {
+			return nil, err
+		}
+		if configChange != nil {
+			changes = append(changes, *configChange)
+		}
+		constraintsChange, err := d.setConstraints(name, svc)
+		if err != nil {
+			return nil, err
+		}
+		if constraintsChange != nil {
+			changes = append(changes, *constraintsChange)
+		}
+	}
+	for _, endpoints := range bundle.Relations {
+		if !d.hasRelation(endpoints) {
+			changes = append(changes, d.addRelation(endpoints))
+		}
+	}
+	return changes, nil
+}
+
+// numUnits returns the number of units the bundle specifies for the
+// service, and whether it specifies any number at all.
+func (svc *bundleService) numUnits() (int, bool) {
+	if svc.NumUnits != nil {
+		return *svc.NumUnits, true
+	}
+	if len(svc.To) > 0 {
+		return len(svc.To), true
+	}
+	return 0, false
+}
+
+// placement returns the placement directive of the service's
+// i'th unit, or an empty string if it has none.
+func (svc *bundleService) placement(i int) string {
+	if i < len(svc.To) {
+		return svc.To[i]
+	}
+	return ""
+}
+
+func (d *bundleDeployer) addCharm(curl *charm.URL) bundleChange {
+	return bundleChange{
+		description: fmt.Sprintf("add charm %s", curl),
+		apply: func() error {
+			repo, err := charm.InferRepository(curl.Reference, d.ctx.AbsPath(d.repoPath))
+			if err != nil {
+				return err
+			}
+			repo = config.SpecializeCharmRepo(repo, d.conf)
+			added, err := addCharmViaAPI(d.client, d.ctx, curl, repo)
+			if err != nil {
+				return err
+			}
+			d.charms[curl.String()] = added
+			return nil
+		},
+	}
+}
+
+// deployService returns the changes that deploy a new service. When
+// its units have placement directives, the service is deployed with
+// a single unit and the rest are added one at a time.
+func (d *bundleDeployer) deployService(name string, curl *charm.URL, svc *bundleService) []bundleChange {
+	numUnits, specified := svc.numUnits()
+	deployUnits := numUnits
+	if len(svc.To) > 0 {
+		deployUnits = 1
+	}
+	description := fmt.Sprintf("deploy service %s using %s", name, curl)
+	if specified {
+		description += fmt.Sprintf(" with %d unit(s)", deployUnits)
+	}
+	if svc.placement(0) != "" {
+		description += fmt.Sprintf(" on %s", svc.placement(0))
+	}
+	deploy := bundleChange{
+		description: description,
+		apply: func() error {
+			curl := curl
+			if added, ok := d.charms[curl.String()]; ok {
+				curl = added
+			}
+			numUnits := deployUnits
+			if !specified {
+				// Subordinate services have no units of their own.
+				info, err := d.client.CharmInfo(curl.String())
+				if err != nil {
+					return err
+				}
+				if !info.Meta.Subordinate {
+					numUnits = 1
+				}
+			}
+			var configYAML []byte
+			if len(svc.Options) > 0 {
+				var err error
+				configYAML, err = goyaml.Marshal(map[string]interface{}{name: svc.Options})
+				if err != nil {
+					return err
+				}
+			}
+			cons, err := constraints.Parse(svc.Constraints)
+			if err != nil {
+				return err
+			}
+			return d.client.ServiceDeploy(
+				curl.String(),
+				name,
+				numUnits,
+				string(configYAML),
+				cons,
+				svc.placement(0),
+			)
+		},
+	}
+	changes := []bundleChange{deploy}
+	if len(svc.To) > 0 {
+		changes = append(changes, d.addUnits(name, svc, 1, numUnits)...)
+	}
+	return changes
+}
+
+// addUnits returns the changes that add the service's units numbered
+// from start up to end. Units without placement directives are
+// added together.
+func (d *bundleDeployer) addUnits(name string, svc *bundleService, start, end int) []bundleChange {
+	var changes []bundleChange
+	unplaced := 0
+	for i := start; i < end; i++ {
+		spec := svc.placement(i)
+		if spec == "" {
+			unplaced++
+			continue
+		}
+		changes = append(changes, bundleChange{
+			description: fmt.Sprintf("add unit to service %s on %s", name, spec),
+			apply: func() error {
+				_, err := d.client.AddServiceUnits(name, 1, spec)
+				return err
+			},
+		})
+	}
+	if unplaced > 0 {
+		changes = append(changes, bundleChange{
+			description: fmt.Sprintf("add %d unit(s) to service %s", unplaced, name),
+			apply: func() error {
+				_, err := d.client.AddServiceUnits(name, unplaced, "")
+				return err
+			},
+		})
+	}
+	return changes
+}
+
+// setConfig returns the change that sets the options of an existing
+// service that differ from the bundle, or nil if none do.
+func (d *bundleDeployer) setConfig(name string, svc *bundleService) (*bundleChange, error) {
+	if len(svc.Options) == 0 {
+		return nil, nil
+	}
+	results, err := d.client.ServiceGet(name)
+	if err != nil {
+		return nil, err
+	}
+	options := make(map[string]string)
+	var settings []string
+	for key, value := range svc.Options {
+		want := fmt.Sprint(value)
+		if info, ok := results.Config[key].(map[string]interface{}); ok {
+			if current, ok := info["value"]; ok && fmt.Sprint(current) == want {
+				continue
+			}
+		}
+		options[key] = want
+		settings = append(settings, key+"="+want)
+	}
+	if len(options) == 0 {
+		return nil, nil
+	}
+	sort.Strings(settings)
+	return &bundleChange{
+		description: fmt.Sprintf("set options of service %s: %s", name, strings.Join(settings, " ")),
+		apply: func() error {
+			return d.client.ServiceSet(name, options)
+		},
+	}, nil
+}
+
+// setConstraints returns the change that sets the constraints of an
+// existing service, or nil if they already match the bundle.
+func (d *bundleDeployer) setConstraints(name string, svc *bundleService) (*bundleChange, error) {
+	if svc.Constraints == "" {
+		return nil, nil
+	}
+	cons, err := constraints.Parse(svc.Constraints)
+	if err != nil {
+		return nil, err
+	}
+	current, err := d.client.GetServiceConstraints(name)
+	if err != nil {
+		return nil, err
+	}
+	if current.String() == cons.String() {
+		return nil, nil
+	}
+	return &bundleChange{
+		description: fmt.Sprintf("set constraints of service %s to %q", name, cons),
+		apply: func() error {
+			return d.client.SetServiceConstraints(name, cons)
+		},
+	}, nil
+}
+
+func (d *bundleDeployer) addRelation(endpoints []string) bundleChange {
+	return bundleChange{
+		description: fmt.Sprintf("add relation %s", strings.Join(endpoints, " ")),
+		apply: func() error {
+			_, err := d.client.AddRelation(endpoints...)
+			return err
+		},
+	}
+}
+
+// hasRelation reports whether the environment already has
+// a relation between the given endpoints.
+func (d *bundleDeployer) hasRelation(endpoints []string) bool {
+	for _, rel := range d.status.Relations {
+		if len(rel.Endpoints) != len(endpoints) {
+			continue
+		}
+		matched := 0
+		for _, ep := range endpoints {
+			service, relation := splitEndpoint(ep)
+			for _, relEp := range rel.Endpoints {
+				if relEp.ServiceName == service && (relation == "" || relEp.Name == relation) {
+					matched++
+					break
+				}
+			}
+		}
+		if matched == len(endpoints) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,223 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/juju/charm"
+	charmtesting "github.com/juju/charm/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type BundleSuite struct {
+	testing.RepoSuite
+}
+
+var _ = gc.Suite(&BundleSuite{})
+
+func (s *BundleSuite) SetUpTest(c *gc.C) {
+	s.RepoSuite.SetUpTest(c)
+	charmtesting.Charms.BundlePath(s.SeriesPath, "dummy")
+	charmtesting.Charms.BundlePath(s.SeriesPath, "logging")
+}
+
+func writeBundle(c *gc.C, content string) string {
+	path := filepath.Join(c.MkDir(), "bundle.yaml")
+	err := ioutil.WriteFile(path, []byte(content), 0644)
+	c.Assert(err, gc.IsNil)
+	return path
+}
+
+func runDeployBundle(c *gc.C, content string, args ...string) (string, string, error) {
+	args = append([]string{writeBundle(c, content)}, args...)
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&DeployCommand{}), args...)
+	if err != nil {
+		return "", "", err
+	}
+	return coretesting.Stdout(ctx), coretesting.Stderr(ctx), nil
+}
+
+const dummyBundle = `
+services:
+  dummy:
+    charm: local:dummy
+    num_units: 2
+    options:
+      username: admin001
+      skill-level: 9000
+    constraints: mem=2G
+  logging:
+    charm: local:logging
+relations:
+  - ["dummy", "logging"]
+`
+
+func (s *BundleSuite) TestDeployBundle(c *gc.C) {
+	_, _, err := runDeployBundle(c, dummyBundle)
+	c.Assert(err, gc.IsNil)
+
+	curl := charm.MustParseURL("local:precise/dummy-1")
+	dummy, _ := s.AssertService(c, "dummy", curl, 2, 1)
+	settings, err := dummy.ConfigSettings()
+	c.Assert(err, gc.IsNil)
+	c.Assert(settings, gc.DeepEquals, charm.Settings{
+		"skill-level": int64(9000),
+		"username":    "admin001",
+	})
+	cons, err := dummy.Constraints()
+	c.Assert(err, gc.IsNil)
+	c.Assert(cons, jc.DeepEquals, constraints.MustParse("mem=2G"))
+
+	logging, err := s.State.Service("logging")
+	c.Assert(err, gc.IsNil)
+	units, err := logging.AllUnits()
+	c.Assert(err, gc.IsNil)
+	c.Assert(units, gc.HasLen, 0)
+	rels, err := logging.Relations()
+	c.Assert(err, gc.IsNil)
+	c.Assert(rels, gc.HasLen, 1)
+}
+
+func (s *BundleSuite) TestDeployBundleTwice(c *gc.C) {
+	_, _, err := runDeployBundle(c, dummyBundle)
+	c.Assert(err, gc.IsNil)
+	_, stderr, err := runDeployBundle(c, dummyBundle)
+	c.Assert(err, gc.IsNil)
+	c.Assert(stderr, gc.Matches, `No changes needed to deploy bundle ".*bundle.yaml".\n`)
+	s.AssertService(c, "dummy", charm.MustParseURL("local:precise/dummy-1"), 2, 1)
+}
+
+func (s *BundleSuite) TestDeployBundleDryRun(c *gc.C) {
+	stdout, _, err := runDeployBundle(c, dummyBundle, "--dry-run")
+	c.Assert(err, gc.IsNil)
+	c.Assert(stdout, gc.Equals, ""+
+		"add charm local:precise/dummy\n"+
+		"deploy service dummy using local:precise/dummy with 2 unit(s)\n"+
+		"add charm local:precise/logging\n"+
+		"deploy service logging using local:precise/logging\n"+
+		"add relation dummy logging\n",
+	)
+	services, err := s.State.AllServices()
+	c.Assert(err, gc.IsNil)
+	c.Assert(services, gc.HasLen, 0)
+}
+
+func (s *BundleSuite) TestDeployBundleChangesExistingService(c *gc.C) {
+	err := runDeploy(c, "local:dummy")
+	c.Assert(err, gc.IsNil)
+
+	stdout, _, err := runDeployBundle(c, `
+services:
+  dummy:
+    charm: local:dummy
+    num_units: 3
+    options:
+      username: bob
+    constraints: mem=4G
+`, "--dry-run")
+	c.Assert(err, gc.IsNil)
+	c.Assert(stdout, gc.Equals, ""+
+		"add 2 unit(s) to service dummy\n"+
+		"set options of service dummy: username=bob\n"+
+		`set constraints of service dummy to "mem=4096M"`+"\n",
+	)
+
+	_, _, err = runDeployBundle(c, `
+services:
+  dummy:
+    charm: local:dummy
+    num_units: 3
+    options:
+      username: bob
+    constraints: mem=4G
+`)
+	c.Assert(err, gc.IsNil)
+	curl := charm.MustParseURL("local:precise/dummy-1")
+	dummy, _ := s.AssertService(c, "dummy", curl, 3, 0)
+	settings, err := dummy.ConfigSettings()
+	c.Assert(err, gc.IsNil)
+	c.Assert(settings, gc.DeepEquals, charm.Settings{"username": "bob"})
+	cons, err := dummy.Constraints()
+	c.Assert(err, gc.IsNil)
+	c.Assert(cons, jc.DeepEquals, constraints.MustParse("mem=4G"))
+}
+
+func (s *BundleSuite) TestDeployBundlePlacement(c *gc.C) {
+	machine, err := s.State.AddMachine("precise", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	_, _, err = runDeployBundle(c, `
+services:
+  dummy:
+    charm: local:dummy
+    num_units: 2
+    to: ["0"]
+`)
+	c.Assert(err, gc.IsNil)
+	units, err := machine.Units()
+	c.Assert(err, gc.IsNil)
+	c.Assert(units, gc.HasLen, 1)
+	c.Assert(units[0].Name(), gc.Equals, "dummy/0")
+
+	unit, err := s.State.Unit("dummy/1")
+	c.Assert(err, gc.IsNil)
+	machineId, err := unit.AssignedMachineId()
+	c.Assert(err, gc.IsNil)
+	c.Assert(machineId, gc.Not(gc.Equals), "0")
+}
+
+var readBundleErrorTests = []struct {
+	about   string
+	content string
+	err     string
+}{{
+	about:   "no services",
+	content: "relations: []",
+	err:     "no services specified",
+}, {
+	about:   "no charm",
+	content: "services: {mysql: {num_units: 1}}",
+	err:     `no charm specified for service "mysql"`,
+}, {
+	about:   "negative units",
+	content: "services: {mysql: {charm: mysql, num_units: -1}}",
+	err:     `negative number of units specified for service "mysql"`,
+}, {
+	about:   "too many placements",
+	content: `services: {mysql: {charm: mysql, num_units: 1, to: ["1", "2"]}}`,
+	err:     `too many placement directives for service "mysql"`,
+}, {
+	about:   "invalid placement",
+	content: `services: {mysql: {charm: mysql, to: ["foo"]}}`,
+	err:     `invalid placement "foo" for service "mysql"`,
+}, {
+	about:   "invalid constraints",
+	content: `services: {mysql: {charm: mysql, constraints: "foo=bar"}}`,
+	err:     `invalid constraints for service "mysql": unknown constraint "foo"`,
+}, {
+	about:   "relation with one endpoint",
+	content: `{services: {mysql: {charm: mysql}}, relations: [["mysql"]]}`,
+	err:     `relation \["mysql"\] does not have two endpoints`,
+}, {
+	about:   "relation with unknown service",
+	content: `{services: {mysql: {charm: mysql}}, relations: [["mysql:db", "wordpress:db"]]}`,
+	err:     `relation \["mysql:db" "wordpress:db"\] refers to unknown service "wordpress"`,
+}}
+
+func (s *BundleSuite) TestReadBundleErrors(c *gc.C) {
+	for i, test := range readBundleErrorTests {
+		c.Logf("test %d: %s", i, test.about)
+		path := writeBundle(c, test.content)
+		_, err := readBundle(path)
+		c.Check(err, gc.ErrorMatches, `invalid bundle ".*": `+test.err)
+	}
+}
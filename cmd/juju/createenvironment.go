@@ -0,0 +1,134 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/environs/configstore"
+	"github.com/juju/juju/juju"
+	"github.com/juju/juju/state/api/params"
+)
+
+const createEnvironmentDoc = `
+create-environment creates a new environment hosted by the state server
+of the current environment, so that no bootstrap node is needed for it.
+The new environment uses the same provider and credentials as the
+current environment; any other configuration values may be given on
+the command line as key=value pairs.
+
+Once created, the new environment may be addressed by name, for example
+with "juju switch" or the -e flag, as the connection details are saved
+locally along with those of the current environment.
+
+Examples:
+
+   juju create-environment sandbox
+   juju create-environment sandbox default-series=trusty
+`
+
+// CreateEnvironmentCommand creates a new environment hosted
+// by the state server of the current environment.
+type CreateEnvironmentCommand struct {
+	envcmd.EnvCommandBase
+	name   string
+	values map[string]interface{}
+}
+
+func (c *CreateEnvironmentCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "create-environment",
+		Args:    "<name> [key=value ...]",
+		Purpose: "create an environment hosted by the current state server",
+		Doc:     createEnvironmentDoc,
+	}
+}
+
+func (c *CreateEnvironmentCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no environment name specified")
+	}
+	c.name, args = args[0], args[1:]
+	c.values = make(map[string]interface{})
+	for i, arg := range args {
+		bits := strings.SplitN(arg, "=", 2)
+		if len(bits) < 2 {
+			return fmt.Errorf(`missing "=" in arg %d: %q`, i+1, arg)
+		}
+		key := bits[0]
+		switch key {
+		case "name":
+			return fmt.Errorf("the environment name must be given as the first argument")
+		case "type", "agent-version":
+			return fmt.Errorf("%s cannot be set for a hosted environment", key)
+		}
+		if _, exists := c.values[key]; exists {
+			return fmt.Errorf("key %q specified more than once", key)
+		}
+		c.values[key] = bits[1]
+	}
+	return nil
+}
+
+// environmentManagerAPI holds the methods of the EnvironmentManager
+// API used by the create-environment and list-environments commands.
+type environmentManagerAPI interface {
+	CreateEnvironment(name string, attrs map[string]interface{}) (params.EnvironmentInfo, error)
+	ListEnvironments() ([]params.EnvironmentInfo, error)
+	Close() error
+}
+
+var getEnvironmentManagerAPI = func(envName string) (environmentManagerAPI, error) {
+	return juju.NewEnvironmentManagerClient(envName)
+}
+
+func (c *CreateEnvironmentCommand) Run(ctx *cmd.Context) (err error) {
+	store, err := configstore.Default()
+	if err != nil {
+		return err
+	}
+	current, err := store.ReadInfo(c.EnvName)
+	if err != nil {
+		return errors.Annotatef(err, "cannot read connection details of environment %q", c.EnvName)
+	}
+	// Creating the local information first ensures that
+	// the new environment's name is not already in use here.
+	info, err := store.CreateInfo(c.name)
+	if err == configstore.ErrEnvironInfoAlreadyExists {
+		return fmt.Errorf("environment %q already exists", c.name)
+	} else if err != nil {
+		return err
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		if err := info.Destroy(); err != nil {
+			logger.Warningf("cannot remove connection details of environment %q: %v", c.name, err)
+		}
+	}()
+	client, err := getEnvironmentManagerAPI(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	created, err := client.CreateEnvironment(c.name, c.values)
+	if err != nil {
+		return err
+	}
+	endpoint := current.APIEndpoint()
+	endpoint.EnvironUUID = created.UUID
+	info.SetAPIEndpoint(endpoint)
+	info.SetAPICredentials(current.APICredentials())
+	if err := info.Write(); err != nil {
+		return errors.Annotatef(err, "environment %q created, but its connection details could not be saved", c.name)
+	}
+	ctx.Infof("created environment %q (%s)", created.Name, created.UUID)
+	return nil
+}
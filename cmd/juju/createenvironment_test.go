@@ -0,0 +1,164 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"errors"
+
+	"github.com/juju/cmd"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/environs/configstore"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/testing"
+)
+
+type CreateEnvironmentSuite struct {
+	testing.FakeJujuHomeSuite
+	mockAPI *mockEnvironmentManagerAPI
+	store   configstore.Storage
+}
+
+var _ = gc.Suite(&CreateEnvironmentSuite{})
+
+func (s *CreateEnvironmentSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.mockAPI = &mockEnvironmentManagerAPI{}
+	s.PatchValue(&getEnvironmentManagerAPI, func(envName string) (environmentManagerAPI, error) {
+		s.mockAPI.envName = envName
+		return s.mockAPI, nil
+	})
+	store, err := configstore.Default()
+	c.Assert(err, gc.IsNil)
+	info, err := store.CreateInfo(testing.SampleEnvName)
+	c.Assert(err, gc.IsNil)
+	info.SetAPIEndpoint(configstore.APIEndpoint{
+		Addresses:   []string{"10.0.0.1:17070"},
+		CACert:      testing.CACert,
+		EnvironUUID: "server-uuid",
+	})
+	info.SetAPICredentials(configstore.APICredentials{
+		User:     "bob",
+		Password: "secret",
+	})
+	err = info.Write()
+	c.Assert(err, gc.IsNil)
+	s.store = store
+}
+
+func newCreateEnvironmentCommand() cmd.Command {
+	return envcmd.Wrap(&CreateEnvironmentCommand{})
+}
+
+func (s *CreateEnvironmentSuite) TestInit(c *gc.C) {
+	for i, test := range []struct {
+		args   []string
+		name   string
+		values map[string]interface{}
+		err    string
+	}{{
+		err: "no environment name specified",
+	}, {
+		args:   []string{"sandbox"},
+		name:   "sandbox",
+		values: map[string]interface{}{},
+	}, {
+		args:   []string{"sandbox", "default-series=trusty", "logging-config=<root>=DEBUG"},
+		name:   "sandbox",
+		values: map[string]interface{}{"default-series": "trusty", "logging-config": "<root>=DEBUG"},
+	}, {
+		args: []string{"sandbox", "default-series"},
+		err:  `missing "=" in arg 1: "default-series"`,
+	}, {
+		args: []string{"sandbox", "a=1", "a=2"},
+		err:  `key "a" specified more than once`,
+	}, {
+		args: []string{"sandbox", "name=other"},
+		err:  "the environment name must be given as the first argument",
+	}, {
+		args: []string{"sandbox", "type=ec2"},
+		err:  "type cannot be set for a hosted environment",
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		command := &CreateEnvironmentCommand{}
+		err := testing.InitCommand(envcmd.Wrap(command), test.args)
+		if test.err != "" {
+			c.Check(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Assert(err, gc.IsNil)
+		c.Check(command.name, gc.Equals, test.name)
+		c.Check(command.values, gc.DeepEquals, test.values)
+	}
+}
+
+func (s *CreateEnvironmentSuite) TestCreateEnvironment(c *gc.C) {
+	context, err := testing.RunCommand(c, newCreateEnvironmentCommand(), "sandbox", "default-series=trusty")
+	c.Assert(err, gc.IsNil)
+	c.Assert(testing.Stderr(context), gc.Equals, `created environment "sandbox" (sandbox-uuid)`+"\n")
+	c.Assert(s.mockAPI.envName, gc.Equals, testing.SampleEnvName)
+	c.Assert(s.mockAPI.created, gc.Equals, "sandbox")
+	c.Assert(s.mockAPI.attrs, gc.DeepEquals, map[string]interface{}{"default-series": "trusty"})
+	c.Assert(s.mockAPI.closed, gc.Equals, true)
+
+	// The new environment is reached through the same API
+	// servers, with the same credentials.
+	info, err := s.store.ReadInfo("sandbox")
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.APIEndpoint(), gc.DeepEquals, configstore.APIEndpoint{
+		Addresses:   []string{"10.0.0.1:17070"},
+		CACert:      testing.CACert,
+		EnvironUUID: "sandbox-uuid",
+	})
+	c.Assert(info.APICredentials(), gc.DeepEquals, configstore.APICredentials{
+		User:     "bob",
+		Password: "secret",
+	})
+}
+
+func (s *CreateEnvironmentSuite) TestCreateEnvironmentAlreadyKnown(c *gc.C) {
+	_, err := testing.RunCommand(c, newCreateEnvironmentCommand(), testing.SampleEnvName)
+	c.Assert(err, gc.ErrorMatches, `environment "erewhemos" already exists`)
+	c.Assert(s.mockAPI.created, gc.Equals, "")
+}
+
+func (s *CreateEnvironmentSuite) TestCreateEnvironmentError(c *gc.C) {
+	s.mockAPI.err = errors.New("permission denied")
+	_, err := testing.RunCommand(c, newCreateEnvironmentCommand(), "sandbox")
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	_, err = s.store.ReadInfo("sandbox")
+	c.Assert(err, gc.ErrorMatches, `environment "sandbox" not found`)
+}
+
+type mockEnvironmentManagerAPI struct {
+	envName string
+	created string
+	attrs   map[string]interface{}
+	envs    []params.EnvironmentInfo
+	err     error
+	closed  bool
+}
+
+func (m *mockEnvironmentManagerAPI) CreateEnvironment(name string, attrs map[string]interface{}) (params.EnvironmentInfo, error) {
+	if m.err != nil {
+		return params.EnvironmentInfo{}, m.err
+	}
+	m.created = name
+	m.attrs = attrs
+	return params.EnvironmentInfo{
+		Name:     name,
+		UUID:     name + "-uuid",
+		OwnerTag: "user-bob",
+	}, nil
+}
+
+func (m *mockEnvironmentManagerAPI) ListEnvironments() ([]params.EnvironmentInfo, error) {
+	return m.envs, m.err
+}
+
+func (m *mockEnvironmentManagerAPI) Close() error {
+	m.closed = true
+	return nil
+}
@@ -6,23 +6,44 @@ package main
 import (
 	"encoding/base64"
 	"fmt"
+	"io/ioutil"
 	"sort"
+	"text/tabwriter"
+	"time"
 
 	"github.com/juju/charm/hooks"
 	"github.com/juju/cmd"
 	"github.com/juju/names"
+	"launchpad.net/gnuflag"
 
+	"github.com/juju/juju/juju"
+	"github.com/juju/juju/state/api/params"
 	unitdebug "github.com/juju/juju/worker/uniter/debug"
+	unithook "github.com/juju/juju/worker/uniter/hook"
 )
 
 // DebugHooksCommand is responsible for launching a ssh shell on a given unit or machine.
 type DebugHooksCommand struct {
 	SSHCommand
 	hooks []string
+
+	record            bool
+	listRecordings    bool
+	downloadRecording string
 }
 
 const debugHooksDoc = `
 Interactively debug a hook remotely on a service unit.
+
+With --record, the shell session in which each hook is debugged is
+recorded, and the recording is stored by the state server when the
+session ends. Recordings may be listed, for all units or for the given
+unit, with --list-recordings. A recording is downloaded with
+--download-recording <id>, which writes the session's transcript and
+timings to <id>.transcript and <id>.timing in the current directory;
+the session may then be replayed with:
+
+    scriptreplay <id>.timing <id>.transcript
 `
 
 func (c *DebugHooksCommand) Info() *cmd.Info {
@@ -34,7 +55,17 @@ func (c *DebugHooksCommand) Info() *cmd.Info {
 	}
 }
 
+func (c *DebugHooksCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.SSHCommand.SetFlags(f)
+	f.BoolVar(&c.record, "record", false, "record the debug sessions")
+	f.BoolVar(&c.listRecordings, "list-recordings", false, "list the recorded debug sessions")
+	f.StringVar(&c.downloadRecording, "download-recording", "", "download the recorded debug session with the given id")
+}
+
 func (c *DebugHooksCommand) Init(args []string) error {
+	if c.listRecordings || c.downloadRecording != "" {
+		return c.initRecordings(args)
+	}
 	if len(args) < 1 {
 		return fmt.Errorf("no unit name specified")
 	}
@@ -54,6 +85,27 @@ func (c *DebugHooksCommand) Init(args []string) error {
 	return nil
 }
 
+// initRecordings checks the arguments given when listing
+// or downloading recordings.
+func (c *DebugHooksCommand) initRecordings(args []string) error {
+	if c.record {
+		return fmt.Errorf("cannot use --record when listing or downloading recordings")
+	}
+	if c.downloadRecording != "" {
+		if c.listRecordings {
+			return fmt.Errorf("cannot use --list-recordings with --download-recording")
+		}
+		return cmd.CheckEmpty(args)
+	}
+	if len(args) > 0 {
+		c.Target, args = args[0], args[1:]
+		if !names.IsUnit(c.Target) {
+			return fmt.Errorf("%q is not a valid unit name", c.Target)
+		}
+	}
+	return cmd.CheckEmpty(args)
+}
+
 func (c *DebugHooksCommand) validateHooks() error {
 	if len(c.hooks) == 0 {
 		return nil
@@ -68,6 +120,12 @@ func (c *DebugHooksCommand) validateHooks() error {
 	for _, hook := range hooks.UnitHooks() {
 		validHooks[string(hook)] = true
 	}
+	validHooks[string(unithook.UpdateStatus)] = true
+	validHooks[string(unithook.CollectMetrics)] = true
+	validHooks[string(unithook.LeaderElected)] = true
+	validHooks[string(unithook.LeaderSettingsChanged)] = true
+	validHooks[string(unithook.StorageAttached)] = true
+	validHooks[string(unithook.StorageDetaching)] = true
 	for _, relation := range relations {
 		for _, hook := range hooks.RelationHooks() {
 			hook := fmt.Sprintf("%s-%s", relation, hook)
@@ -92,6 +150,11 @@ func (c *DebugHooksCommand) validateHooks() error {
 // and connects to it via SSH to execute the debug-hooks
 // script.
 func (c *DebugHooksCommand) Run(ctx *cmd.Context) error {
+	if c.listRecordings {
+		return c.runListRecordings(ctx)
+	} else if c.downloadRecording != "" {
+		return c.runDownloadRecording(ctx)
+	}
 	var err error
 	c.apiClient, err = c.initAPIClient()
 	if err != nil {
@@ -103,9 +166,68 @@ func (c *DebugHooksCommand) Run(ctx *cmd.Context) error {
 		return err
 	}
 	debugctx := unitdebug.NewHooksContext(c.Target)
-	script := base64.StdEncoding.EncodeToString([]byte(unitdebug.ClientScript(debugctx, c.hooks)))
+	script := base64.StdEncoding.EncodeToString([]byte(unitdebug.ClientScript(debugctx, c.hooks, c.record)))
 	innercmd := fmt.Sprintf(`F=$(mktemp); echo %s | base64 -d > $F; . $F`, script)
 	args := []string{fmt.Sprintf("sudo /bin/bash -c '%s'", innercmd)}
 	c.Args = args
 	return c.SSHCommand.Run(ctx)
 }
+
+// debugRecordingsAPI holds the methods of the DebugRecordings API
+// used by the debug-hooks command.
+type debugRecordingsAPI interface {
+	List(unitName string) ([]params.DebugRecording, error)
+	Get(id string) (*params.DebugRecordingData, error)
+	Close() error
+}
+
+var getDebugRecordingsAPI = func(c *DebugHooksCommand) (debugRecordingsAPI, error) {
+	return juju.NewDebugRecordingsClient(c.EnvName)
+}
+
+// runListRecordings lists the recorded debug sessions of the
+// target unit, or of all units if there is no target.
+func (c *DebugHooksCommand) runListRecordings(ctx *cmd.Context) error {
+	client, err := getDebugRecordingsAPI(c)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	recordings, err := client.List(c.Target)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(ctx.Stdout, 0, 1, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tUNIT\tHOOK\tSTARTED\tDURATION\tSIZE")
+	for _, r := range recordings {
+		duration := r.Finished.Sub(r.Started)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\n",
+			r.Id, r.Unit, r.Hook, r.Started.UTC().Format(time.RFC3339), duration-duration%time.Second, r.Size)
+	}
+	return tw.Flush()
+}
+
+// runDownloadRecording writes the transcript and timings of the
+// requested recording to files in the current directory.
+func (c *DebugHooksCommand) runDownloadRecording(ctx *cmd.Context) error {
+	client, err := getDebugRecordingsAPI(c)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	data, err := client.Get(c.downloadRecording)
+	if err != nil {
+		return err
+	}
+	transcriptPath := ctx.AbsPath(data.Recording.Id + ".transcript")
+	if err := ioutil.WriteFile(transcriptPath, data.Transcript, 0600); err != nil {
+		return err
+	}
+	timingPath := ctx.AbsPath(data.Recording.Id + ".timing")
+	if err := ioutil.WriteFile(timingPath, data.Timing, 0600); err != nil {
+		return err
+	}
+	ctx.Infof("debug session for %s hook of %s written to %s and %s",
+		data.Recording.Hook, data.Recording.Unit, transcriptPath, timingPath)
+	return nil
+}
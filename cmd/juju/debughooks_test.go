@@ -4,10 +4,15 @@
 package main
 
 import (
+	"io/ioutil"
+	"path/filepath"
 	"regexp"
+	"time"
 
 	gc "launchpad.net/gocheck"
 
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/state/api/params"
 	coretesting "github.com/juju/juju/testing"
 )
 
@@ -44,6 +49,18 @@ var debugHooksTests = []struct {
 	info:   `"*" mixed with named hooks is equivalent to "*"`,
 	args:   []string{"mysql/0", "*", "relation-get"},
 	result: ".*\n",
+}, {
+	info:   `the update-status hook may be debugged`,
+	args:   []string{"mysql/0", "update-status"},
+	result: ".*\n",
+}, {
+	info:   `the collect-metrics hook may be debugged`,
+	args:   []string{"mysql/0", "collect-metrics"},
+	result: ".*\n",
+}, {
+	info:   `the leadership hooks may be debugged`,
+	args:   []string{"mysql/0", "leader-elected", "leader-settings-changed"},
+	result: ".*\n",
 }, {
 	info:   `multiple named hooks may be specified`,
 	args:   []string{"mysql/0", "start", "stop"},
@@ -93,3 +110,139 @@ func (s *DebugHooksSuite) TestDebugHooksCommand(c *gc.C) {
 		}
 	}
 }
+
+type DebugHooksRecordingsSuite struct {
+	coretesting.FakeJujuHomeSuite
+	mockAPI *mockDebugRecordingsAPI
+}
+
+var _ = gc.Suite(&DebugHooksRecordingsSuite{})
+
+func (s *DebugHooksRecordingsSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	started := time.Date(2014, 7, 14, 12, 0, 0, 0, time.UTC)
+	s.mockAPI = &mockDebugRecordingsAPI{
+		recordings: []params.DebugRecordingData{{
+			Recording: params.DebugRecording{
+				Id:       "0",
+				Unit:     "mysql/0",
+				Hook:     "install",
+				Started:  started,
+				Finished: started.Add(90*time.Second + time.Millisecond),
+				Size:     5,
+			},
+			Transcript: []byte("$ ls\n"),
+			Timing:     []byte("0.1 5\n"),
+		}, {
+			Recording: params.DebugRecording{
+				Id:       "1",
+				Unit:     "wordpress/0",
+				Hook:     "db-relation-changed",
+				Started:  started.Add(time.Hour),
+				Finished: started.Add(time.Hour + time.Minute),
+				Size:     12,
+			},
+		}},
+	}
+	s.PatchValue(&getDebugRecordingsAPI, func(c *DebugHooksCommand) (debugRecordingsAPI, error) {
+		return s.mockAPI, nil
+	})
+}
+
+func (s *DebugHooksRecordingsSuite) TestListRecordings(c *gc.C) {
+	context, err := coretesting.RunCommand(c, envcmd.Wrap(&DebugHooksCommand{}), "--list-recordings")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mockAPI.unitName, gc.Equals, "")
+	c.Assert(coretesting.Stdout(context), gc.Equals, ""+
+		"ID  UNIT         HOOK                 STARTED               DURATION  SIZE\n"+
+		"0   mysql/0      install              2014-07-14T12:00:00Z  1m30s     5\n"+
+		"1   wordpress/0  db-relation-changed  2014-07-14T13:00:00Z  1m0s      12\n")
+	c.Assert(s.mockAPI.closed, gc.Equals, true)
+}
+
+func (s *DebugHooksRecordingsSuite) TestListRecordingsForUnit(c *gc.C) {
+	_, err := coretesting.RunCommand(c, envcmd.Wrap(&DebugHooksCommand{}), "--list-recordings", "mysql/0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mockAPI.unitName, gc.Equals, "mysql/0")
+}
+
+func (s *DebugHooksRecordingsSuite) TestDownloadRecording(c *gc.C) {
+	context := coretesting.Context(c)
+	command := envcmd.Wrap(&DebugHooksCommand{})
+	err := coretesting.InitCommand(command, []string{"--download-recording", "0"})
+	c.Assert(err, gc.IsNil)
+	err = command.Run(context)
+	c.Assert(err, gc.IsNil)
+	transcript, err := ioutil.ReadFile(filepath.Join(context.Dir, "0.transcript"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(transcript), gc.Equals, "$ ls\n")
+	timing, err := ioutil.ReadFile(filepath.Join(context.Dir, "0.timing"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(timing), gc.Equals, "0.1 5\n")
+	c.Assert(s.mockAPI.closed, gc.Equals, true)
+}
+
+func (s *DebugHooksRecordingsSuite) TestDownloadRecordingNotFound(c *gc.C) {
+	_, err := coretesting.RunCommand(c, envcmd.Wrap(&DebugHooksCommand{}), "--download-recording", "42")
+	c.Assert(err, gc.ErrorMatches, `debug recording "42" not found`)
+}
+
+func (s *DebugHooksRecordingsSuite) TestInitErrors(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		args: []string{"--list-recordings", "mysql"},
+		err:  `"mysql" is not a valid unit name`,
+	}, {
+		args: []string{"--list-recordings", "mysql/0", "install"},
+		err:  `unrecognized args: \["install"\]`,
+	}, {
+		args: []string{"--download-recording", "0", "mysql/0"},
+		err:  `unrecognized args: \["mysql/0"\]`,
+	}, {
+		args: []string{"--list-recordings", "--download-recording", "0"},
+		err:  `cannot use --list-recordings with --download-recording`,
+	}, {
+		args: []string{"--record", "--list-recordings"},
+		err:  `cannot use --record when listing or downloading recordings`,
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		err := coretesting.InitCommand(&DebugHooksCommand{}, test.args)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+type mockDebugRecordingsAPI struct {
+	recordings []params.DebugRecordingData
+	unitName   string
+	closed     bool
+}
+
+func (m *mockDebugRecordingsAPI) List(unitName string) ([]params.DebugRecording, error) {
+	m.unitName = unitName
+	var result []params.DebugRecording
+	for _, r := range m.recordings {
+		if unitName == "" || r.Recording.Unit == unitName {
+			result = append(result, r.Recording)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockDebugRecordingsAPI) Get(id string) (*params.DebugRecordingData, error) {
+	for _, r := range m.recordings {
+		if r.Recording.Id == id {
+			return &r, nil
+		}
+	}
+	return nil, &params.Error{
+		Code:    params.CodeNotFound,
+		Message: `debug recording "` + id + `" not found`,
+	}
+}
+
+func (m *mockDebugRecordingsAPI) Close() error {
+	m.closed = true
+	return nil
+}
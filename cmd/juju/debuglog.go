@@ -6,6 +6,7 @@ package main
 import (
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
@@ -21,6 +22,8 @@ type DebugLogCommand struct {
 	envcmd.EnvCommandBase
 
 	level  string
+	since  string
+	until  string
 	params api.DebugLogParams
 }
 
@@ -31,8 +34,22 @@ var DefaultLogLocation = "/var/log/juju/all-machines.log"
 const defaultLineCount = 10
 
 const debuglogDoc = `
-Stream the consolidated debug log file. This file contains the log messages
-from all nodes in the environment.
+Stream the consolidated debug log. This contains the log messages from all
+nodes in the environment, and is the same whichever state server is used.
+
+By default, the last few lines of the log are shown and then new lines
+are shown as they are logged. Use --replay to show the log from the start,
+and --since to only show lines logged since a given time, specified as an
+RFC3339 time (e.g. 2014-10-01T12:00:00Z) or as a duration before now
+(e.g. 30m or 2h). Use --until, specified in the same way, to only show
+lines logged up to a given time; the command exits once they have been
+shown.
+
+Examples:
+
+    juju debug-log --level WARNING --include-module juju.worker
+    juju debug-log --replay --since 2h -i unit-mysql-*
+    juju debug-log --replay --since 2014-10-01T12:00:00Z --until 2014-10-01T13:00:00Z
 `
 
 func (c *DebugLogCommand) Info() *cmd.Info {
@@ -58,6 +75,8 @@ func (c *DebugLogCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.UintVar(&c.params.Backlog, "lines", defaultLineCount, "")
 	f.UintVar(&c.params.Limit, "limit", 0, "show at most this many lines")
 	f.BoolVar(&c.params.Replay, "replay", false, "start filtering from the start")
+	f.StringVar(&c.since, "since", "", "only show log messages logged since this time or duration ago")
+	f.StringVar(&c.until, "until", "", "only show log messages logged until this time or duration ago")
 }
 
 func (c *DebugLogCommand) Init(args []string) error {
@@ -69,9 +88,39 @@ func (c *DebugLogCommand) Init(args []string) error {
 		}
 		c.params.Level = level
 	}
+	now := time.Now()
+	if c.since != "" {
+		since, err := parseLogTime("since", c.since, now)
+		if err != nil {
+			return err
+		}
+		c.params.StartTime = since
+	}
+	if c.until != "" {
+		until, err := parseLogTime("until", c.until, now)
+		if err != nil {
+			return err
+		}
+		if until.Before(c.params.StartTime) {
+			return fmt.Errorf("until value %q is before since value %q", c.until, c.since)
+		}
+		c.params.EndTime = until
+	}
 	return cmd.CheckEmpty(args)
 }
 
+// parseLogTime parses the value of the named --since or --until
+// flag, which is either an RFC3339 time or a duration before now.
+func parseLogTime(name, value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("%s value %q is neither an RFC3339 time nor a positive duration", name, value)
+}
+
 type DebugLogAPI interface {
 	WatchDebugLog(params api.DebugLogParams) (io.ReadCloser, error)
 	Close() error
@@ -8,6 +8,7 @@ import (
 	"io"
 	"io/ioutil"
 	"strings"
+	"time"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
@@ -88,6 +89,33 @@ func (s *DebugLogSuite) TestArgParsing(c *gc.C) {
 				Backlog: 10,
 				Limit:   100,
 			},
+		}, {
+			args: []string{"--replay", "--since", "2014-10-01T12:00:00Z"},
+			expected: api.DebugLogParams{
+				Backlog:   10,
+				Replay:    true,
+				StartTime: time.Date(2014, 10, 1, 12, 0, 0, 0, time.UTC),
+			},
+		}, {
+			args: []string{"--replay", "--since", "2014-10-01T12:00:00Z", "--until", "2014-10-01T13:00:00Z"},
+			expected: api.DebugLogParams{
+				Backlog:   10,
+				Replay:    true,
+				StartTime: time.Date(2014, 10, 1, 12, 0, 0, 0, time.UTC),
+				EndTime:   time.Date(2014, 10, 1, 13, 0, 0, 0, time.UTC),
+			},
+		}, {
+			args:     []string{"--since", "2014-10-01T12:00:00Z", "--until", "2014-10-01T11:00:00Z"},
+			errMatch: `until value "2014-10-01T11:00:00Z" is before since value "2014-10-01T12:00:00Z"`,
+		}, {
+			args:     []string{"--until", "tomorrow"},
+			errMatch: `until value "tomorrow" is neither an RFC3339 time nor a positive duration`,
+		}, {
+			args:     []string{"--since", "yesterday"},
+			errMatch: `since value "yesterday" is neither an RFC3339 time nor a positive duration`,
+		}, {
+			args:     []string{"--since", "-1h"},
+			errMatch: `since value "-1h" is neither an RFC3339 time nor a positive duration`,
 		},
 	} {
 		c.Logf("test %v", i)
@@ -102,6 +130,19 @@ func (s *DebugLogSuite) TestArgParsing(c *gc.C) {
 	}
 }
 
+func (s *DebugLogSuite) TestSinceDuration(c *gc.C) {
+	now := time.Date(2014, 10, 1, 12, 0, 0, 0, time.UTC)
+	since, err := parseLogTime("since", "90m", now)
+	c.Assert(err, gc.IsNil)
+	c.Assert(since, gc.Equals, time.Date(2014, 10, 1, 10, 30, 0, 0, time.UTC))
+
+	command := &DebugLogCommand{}
+	err = testing.InitCommand(envcmd.Wrap(command), []string{"--since", "1h"})
+	c.Assert(err, gc.IsNil)
+	ago := time.Since(command.params.StartTime)
+	c.Assert(ago >= time.Hour && ago < time.Hour+testing.LongWait, jc.IsTrue)
+}
+
 func (s *DebugLogSuite) TestParamsPassed(c *gc.C) {
 	fake := &fakeDebugLogAPI{}
 	s.PatchValue(&getDebugLogAPI, func(envName string) (DebugLogAPI, error) {
@@ -31,8 +31,11 @@ type DeployCommand struct {
 	Config       cmd.FileVar
 	Constraints  constraints.Value
 	Networks     string
+	Bindings     map[string]string
 	BumpRevision bool   // Remove this once the 1.16 support is dropped.
 	RepoPath     string // defaults to JUJU_REPOSITORY
+	BundlePath   string
+	DryRun       bool
 }
 
 const deployDoc = `
@@ -86,6 +89,8 @@ Examples:
    juju deploy mysql --to 23       (deploy to machine 23)
    juju deploy mysql --to 24/lxc/3 (deploy to lxc container 3 on host machine 24)
    juju deploy mysql --to lxc:25   (deploy to a new lxc container on host machine 25)
+   juju deploy mysql --to zone=us-east-1c
+                                   (deploy to a new machine in availability zone us-east-1c)
 
    juju deploy mysql -n 5 --constraints mem=8G
    (deploy 5 instances of mysql with at least 8 GB of RAM each)
@@ -103,6 +108,60 @@ to configure on it. The --networks argument instructs juju to add all the
 networks specified with it to all new machines deployed to host units of
 the service. Not supported on all providers.
 
+Storage for each unit of the service can be requested with the --storage
+argument, which may be repeated. Each takes the name of a store and a
+directive of the form [pool,]size[,count][,kind], where the pool names the
+storage provider (such as "ebs" or "loop"), size is a number with an
+optional M, G, T or P suffix (megabytes by default), count is the number of
+volumes of that size to create, and kind is "block" (the default) for a raw
+block device or "filesystem" for a filesystem created and mounted by Juju.
+The environment's default pool is used if none is named; environments
+without one, such as openstack and maas, must name a pool. Later units
+added with add-unit are given the same storage.
+
+   juju deploy mysql --storage data=ebs,100G --storage logs=10G,2,filesystem
+   (deploy mysql with a 100GB EBS volume and two 10GB filesystems from the
+    default pool)
+
+The relation endpoints of the service can be bound to spaces with the
+--bind argument, which takes space-separated <endpoint>=<space> pairs.
+Units of the service advertise, on the relations using a bound endpoint,
+their address in the space the endpoint is bound to; unit-get
+private-address run in the hooks of those relations also returns it.
+Spaces are created with "juju space create".
+
+   juju deploy mysql --bind "db=internal monitors=admin"
+   (deploy mysql, advertising its addresses in the "internal" space to
+    the services related through the "db" endpoint, and those in the
+    "admin" space through "monitors")
+
+A bundle file, with a name ending in .yaml, describes several services
+and the relations between them, and can be deployed in place of a charm:
+
+   services:
+     wordpress:
+       charm: cs:precise/wordpress
+       num_units: 2
+       options:
+         tuning: optimized
+       constraints: mem=2G
+       to: ["1", "lxc:2"]
+     mysql:
+       charm: cs:precise/mysql
+   relations:
+     - ["wordpress:db", "mysql:server"]
+
+Deploying a bundle compares it with the environment, and only makes the
+changes needed: services that do not exist are deployed, and existing
+services get any missing units, changed options and constraints; no
+services or units are removed. Placement directives apply to units in
+order, in the same form as --to. With --dry-run, the changes are printed
+rather than made.
+
+Examples:
+   juju deploy bundle.yaml
+   juju deploy bundle.yaml --dry-run
+
 See Also:
    juju help constraints
    juju help set-constraints
@@ -112,7 +171,7 @@ See Also:
 func (c *DeployCommand) Info() *cmd.Info {
 	return &cmd.Info{
 		Name:    "deploy",
-		Args:    "<charm name> [<service name>]",
+		Args:    "<charm name> [<service name>] | <bundle file>",
 		Purpose: "deploy a new service",
 		Doc:     deployDoc,
 	}
@@ -126,10 +185,18 @@ func (c *DeployCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.Var(&c.Config, "config", "path to yaml-formatted service config")
 	f.Var(constraints.ConstraintsValue{Target: &c.Constraints}, "constraints", "set service constraints")
 	f.StringVar(&c.Networks, "networks", "", "bind the service to specific networks")
+	f.Var(bindFlag{&c.Bindings}, "bind", "bind relation endpoints to spaces, as <endpoint>=<space>")
 	f.StringVar(&c.RepoPath, "repository", os.Getenv(osenv.JujuRepositoryEnvKey), "local charm repository")
+	f.BoolVar(&c.DryRun, "dry-run", false, "print the changes needed to deploy a bundle without making them")
 }
 
 func (c *DeployCommand) Init(args []string) error {
+	if len(args) > 0 && strings.HasSuffix(args[0], ".yaml") {
+		return c.initBundle(args)
+	}
+	if c.DryRun {
+		return errors.New("--dry-run can only be used when deploying a bundle")
+	}
 	switch len(args) {
 	case 2:
 		if !names.IsService(args[1]) {
@@ -150,6 +217,21 @@ func (c *DeployCommand) Init(args []string) error {
 	return c.UnitCommandBase.Init(args)
 }
 
+// initBundle checks the arguments given when deploying a bundle;
+// the bundle itself specifies everything else.
+func (c *DeployCommand) initBundle(args []string) error {
+	c.BundlePath = args[0]
+	if err := cmd.CheckEmpty(args[1:]); err != nil {
+		return err
+	}
+	if c.ToMachineSpec != "" || c.NumUnits != 1 || c.Config.Path != "" ||
+		c.Networks != "" || !constraints.IsEmpty(&c.Constraints) || len(c.Storage) > 0 ||
+		len(c.Bindings) > 0 {
+		return errors.New("cannot use --to, --num-units, --config, --constraints, --networks, --storage or --bind with a bundle")
+	}
+	return nil
+}
+
 func (c *DeployCommand) Run(ctx *cmd.Context) error {
 	client, err := juju.NewAPIClientFromName(c.EnvName)
 	if err != nil {
@@ -166,6 +248,10 @@ func (c *DeployCommand) Run(ctx *cmd.Context) error {
 		return err
 	}
 
+	if c.BundlePath != "" {
+		return c.deployBundle(ctx, client, conf)
+	}
+
 	curl, err := resolveCharmURL(c.CharmName, client, conf)
 	if err != nil {
 		return err
@@ -212,6 +298,9 @@ func (c *DeployCommand) Run(ctx *cmd.Context) error {
 		if !constraints.IsEmpty(&c.Constraints) {
 			return errors.New("cannot use --constraints with subordinate service")
 		}
+		if len(c.Storage) > 0 {
+			return errors.New("cannot use --storage with subordinate service")
+		}
 		if numUnits == 1 && c.ToMachineSpec == "" {
 			numUnits = 0
 		} else {
@@ -230,6 +319,39 @@ func (c *DeployCommand) Run(ctx *cmd.Context) error {
 			return err
 		}
 	}
+	if len(c.Bindings) > 0 {
+		err = client.ServiceDeployWithBindings(params.ServiceDeploy{
+			ServiceName:      serviceName,
+			CharmUrl:         curl.String(),
+			NumUnits:         numUnits,
+			ConfigYAML:       string(configYAML),
+			Constraints:      c.Constraints,
+			ToMachineSpec:    c.ToMachineSpec,
+			Networks:         requestedNetworks,
+			Storage:          c.Storage,
+			EndpointBindings: c.Bindings,
+		})
+		if params.IsCodeNotImplemented(err) {
+			return errors.New("cannot use --bind: not supported by the API server")
+		}
+		return err
+	}
+	if len(c.Storage) > 0 {
+		err = client.ServiceDeployWithStorage(params.ServiceDeploy{
+			ServiceName:   serviceName,
+			CharmUrl:      curl.String(),
+			NumUnits:      numUnits,
+			ConfigYAML:    string(configYAML),
+			Constraints:   c.Constraints,
+			ToMachineSpec: c.ToMachineSpec,
+			Networks:      requestedNetworks,
+			Storage:       c.Storage,
+		})
+		if params.IsCodeNotImplemented(err) {
+			return errors.New("cannot use --storage: not supported by the API server")
+		}
+		return err
+	}
 	err = client.ServiceDeployWithNetworks(
 		curl.String(),
 		serviceName,
@@ -254,6 +376,38 @@ func (c *DeployCommand) Run(ctx *cmd.Context) error {
 	return err
 }
 
+// deployBundle makes the changes needed to deploy the bundle,
+// or just prints them if --dry-run was given.
+func (c *DeployCommand) deployBundle(ctx *cmd.Context, client *api.Client, conf *config.Config) error {
+	bundle, err := readBundle(ctx.AbsPath(c.BundlePath))
+	if err != nil {
+		return err
+	}
+	deployer, err := newBundleDeployer(ctx, client, conf, c.RepoPath)
+	if err != nil {
+		return err
+	}
+	changes, err := deployer.plan(bundle)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		ctx.Infof("No changes needed to deploy bundle %q.", c.BundlePath)
+		return nil
+	}
+	for _, change := range changes {
+		if c.DryRun {
+			fmt.Fprintln(ctx.Stdout, change.description)
+			continue
+		}
+		ctx.Infof("%s", change.description)
+		if err := change.apply(); err != nil {
+			return fmt.Errorf("cannot %s: %v", change.description, err)
+		}
+	}
+	return nil
+}
+
 // addCharmViaAPI calls the appropriate client API calls to add the
 // given charm URL to state. Also displays the charm URL of the added
 // charm on stdout.
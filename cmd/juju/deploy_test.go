@@ -18,6 +18,7 @@ import (
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/state"
+	"github.com/juju/juju/storage"
 	coretesting "github.com/juju/juju/testing"
 )
 
@@ -60,6 +61,42 @@ var initErrorTests = []struct {
 	}, {
 		args: []string{"craziness", "burble1", "--constraints", "gibber=plop"},
 		err:  `invalid value "gibber=plop" for flag --constraints: unknown constraint "gibber"`,
+	}, {
+		args: []string{"craziness", "--dry-run"},
+		err:  `--dry-run can only be used when deploying a bundle`,
+	}, {
+		args: []string{"bundle.yaml", "burble1"},
+		err:  `unrecognized args: \["burble1"\]`,
+	}, {
+		args: []string{"bundle.yaml", "--to", "1"},
+		err:  `cannot use --to, --num-units, --config, --constraints, --networks, --storage or --bind with a bundle`,
+	}, {
+		args: []string{"bundle.yaml", "--constraints", "mem=2G"},
+		err:  `cannot use --to, --num-units, --config, --constraints, --networks, --storage or --bind with a bundle`,
+	}, {
+		args: []string{"bundle.yaml", "--storage", "data=1G"},
+		err:  `cannot use --to, --num-units, --config, --constraints, --networks, --storage or --bind with a bundle`,
+	}, {
+		args: []string{"craziness", "--storage", "data"},
+		err:  `invalid value "data" for flag --storage: expected <store>=<directive>, got "data"`,
+	}, {
+		args: []string{"craziness", "--storage", "data=lots"},
+		err:  `invalid value "data=lots" for flag --storage: invalid storage directive "lots": .*`,
+	}, {
+		args: []string{"craziness", "--storage", "data=1G", "--storage", "data=2G"},
+		err:  `invalid value "data=2G" for flag --storage: storage "data" specified more than once`,
+	}, {
+		args: []string{"bundle.yaml", "--bind", "db=internal"},
+		err:  `cannot use --to, --num-units, --config, --constraints, --networks, --storage or --bind with a bundle`,
+	}, {
+		args: []string{"craziness", "--bind", "db"},
+		err:  `invalid value "db" for flag --bind: expected <endpoint>=<space>, got "db"`,
+	}, {
+		args: []string{"craziness", "--bind", "db=Internal"},
+		err:  `invalid value "db=Internal" for flag --bind: "Internal" is not a valid space name`,
+	}, {
+		args: []string{"craziness", "--bind", "db=internal db=admin"},
+		err:  `invalid value "db=internal db=admin" for flag --bind: endpoint "db" bound more than once`,
 	},
 }
 
@@ -185,6 +222,45 @@ func (s *DeploySuite) TestNetworks(c *gc.C) {
 	c.Assert(cons, jc.DeepEquals, constraints.MustParse("mem=2G cpu-cores=2 networks=net1,net0,^net3,^net4"))
 }
 
+func (s *DeploySuite) TestStorage(c *gc.C) {
+	charmtesting.Charms.BundlePath(s.SeriesPath, "dummy")
+	err := runDeploy(c, "local:dummy", "--storage", "data=loop,2G", "--storage", "logs=100M,2")
+	c.Assert(err, gc.IsNil)
+	curl := charm.MustParseURL("local:precise/dummy-1")
+	service, _ := s.AssertService(c, "dummy", curl, 1, 0)
+	storageCons, err := service.StorageConstraints()
+	c.Assert(err, gc.IsNil)
+	c.Assert(storageCons, jc.DeepEquals, map[string]storage.Directive{
+		"data": {Pool: "loop", Size: 2048, Count: 1},
+		"logs": {Pool: "loop", Size: 100, Count: 2},
+	})
+}
+
+func (s *DeploySuite) TestBind(c *gc.C) {
+	_, err := s.State.AddSpace("internal", nil)
+	c.Assert(err, gc.IsNil)
+	charmtesting.Charms.BundlePath(s.SeriesPath, "dummy")
+	err = runDeploy(c, "local:dummy", "--bind", "juju-info=internal")
+	c.Assert(err, gc.IsNil)
+	curl := charm.MustParseURL("local:precise/dummy-1")
+	service, _ := s.AssertService(c, "dummy", curl, 1, 0)
+	c.Assert(service.EndpointBindings(), jc.DeepEquals, map[string]string{
+		"juju-info": "internal",
+	})
+}
+
+func (s *DeploySuite) TestBindUnknownSpace(c *gc.C) {
+	charmtesting.Charms.BundlePath(s.SeriesPath, "dummy")
+	err := runDeploy(c, "local:dummy", "--bind", "juju-info=internal")
+	c.Assert(err, gc.ErrorMatches, `cannot set endpoint bindings for service "dummy": space "internal" not found`)
+}
+
+func (s *DeploySuite) TestSubordinateStorage(c *gc.C) {
+	charmtesting.Charms.BundlePath(s.SeriesPath, "logging")
+	err := runDeploy(c, "local:logging", "--storage", "data=1G")
+	c.Assert(err, gc.ErrorMatches, "cannot use --storage with subordinate service")
+}
+
 func (s *DeploySuite) TestSubordinateConstraints(c *gc.C) {
 	charmtesting.Charms.BundlePath(s.SeriesPath, "logging")
 	err := runDeploy(c, "local:logging", "--constraints", "mem=1G")
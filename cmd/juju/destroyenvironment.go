@@ -78,25 +78,22 @@ func (c *DestroyEnvironmentCommand) Run(ctx *cmd.Context) (result error) {
 	environ, err := environs.NewFromName(c.envName, store)
 	if err != nil {
 		if environs.IsEmptyConfig(err) {
+			// Environments hosted by another environment's state
+			// server have no bootstrap configuration of their own;
+			// they are destroyed entirely through the API.
+			if !c.force {
+				if hosted, err := c.destroyHosted(ctx, store); hosted || err != nil {
+					return err
+				}
+			}
 			// Delete the .jenv file and call it done.
 			ctx.Infof("removing empty environment file")
 			return environs.DestroyInfo(c.envName, store)
 		}
 		return err
 	}
-	if !c.assumeYes {
-		fmt.Fprintf(ctx.Stdout, destroyEnvMsg, environ.Name(), environ.Config().Type())
-
-		scanner := bufio.NewScanner(ctx.Stdin)
-		scanner.Scan()
-		err := scanner.Err()
-		if err != nil && err != io.EOF {
-			return fmt.Errorf("Environment destruction aborted: %s", err)
-		}
-		answer := strings.ToLower(scanner.Text())
-		if answer != "y" && answer != "yes" {
-			return errors.New("environment destruction aborted")
-		}
+	if err := c.confirm(ctx, environ.Name(), environ.Config().Type()); err != nil {
+		return err
 	}
 	// If --force is supplied, then don't attempt to use the API.
 	// This is necessary to destroy broken environments, where the
@@ -131,6 +128,63 @@ to be cleaned up.
 	return environs.Destroy(environ, store)
 }
 
+// confirm asks the user to confirm the destruction of the
+// named environment, unless confirmation is not required.
+func (c *DestroyEnvironmentCommand) confirm(ctx *cmd.Context, name, providerType string) error {
+	if c.assumeYes {
+		return nil
+	}
+	fmt.Fprintf(ctx.Stdout, destroyEnvMsg, name, providerType)
+
+	scanner := bufio.NewScanner(ctx.Stdin)
+	scanner.Scan()
+	err := scanner.Err()
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("Environment destruction aborted: %s", err)
+	}
+	answer := strings.ToLower(scanner.Text())
+	if answer != "y" && answer != "yes" {
+		return errors.New("environment destruction aborted")
+	}
+	return nil
+}
+
+// destroyHosted destroys the environment through the API if it is
+// hosted by another environment's state server, and reports whether
+// it was. The state server stops the environment's instances and
+// removes it; the provider is never called directly, as the hosting
+// environment's resources must be left alone.
+func (c *DestroyEnvironmentCommand) destroyHosted(ctx *cmd.Context, store configstore.Storage) (bool, error) {
+	info, err := store.ReadInfo(c.envName)
+	if err != nil {
+		return false, err
+	}
+	if info.APIEndpoint().EnvironUUID == "" {
+		// The connection details of a hosted
+		// environment always name it.
+		return false, nil
+	}
+	apiclient, err := juju.NewAPIClientFromName(c.envName)
+	if err != nil {
+		return false, fmt.Errorf("cannot connect to API: %v", err)
+	}
+	defer apiclient.Close()
+	envInfo, err := apiclient.EnvironmentInfo()
+	if err != nil {
+		return false, err
+	}
+	if envInfo.ServerUUID == "" || envInfo.ServerUUID == envInfo.UUID {
+		return false, nil
+	}
+	if err := c.confirm(ctx, envInfo.Name, envInfo.ProviderType); err != nil {
+		return true, err
+	}
+	if err := apiclient.DestroyEnvironment(); err != nil {
+		return true, fmt.Errorf("destroying environment: %v", err)
+	}
+	return true, environs.DestroyInfo(c.envName, store)
+}
+
 var destroyEnvMsg = `
 WARNING! this command will destroy the %q environment (type: %s)
 This includes all machines, services, data and other resources.
@@ -16,6 +16,7 @@ import (
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/provider/dummy"
+	"github.com/juju/juju/state"
 	coretesting "github.com/juju/juju/testing"
 )
 
@@ -80,6 +81,43 @@ func (s *destroyEnvSuite) TestDestroyEnvironmentCommandEmptyJenv(c *gc.C) {
 	c.Assert(coretesting.Stderr(context), gc.Equals, "removing empty environment file\n")
 }
 
+func (s *destroyEnvSuite) TestDestroyEnvironmentCommandHosted(c *gc.C) {
+	cfg, err := s.State.EnvironConfig()
+	c.Assert(err, gc.IsNil)
+	cfg, err = cfg.Apply(map[string]interface{}{"name": "hosted"})
+	c.Assert(err, gc.IsNil)
+	env, st, err := s.State.NewEnvironment(cfg, "admin")
+	c.Assert(err, gc.IsNil)
+	defer st.Close()
+	info, err := s.ConfigStore.CreateInfo("hosted")
+	c.Assert(err, gc.IsNil)
+	apiInfo := s.APIInfo(c)
+	info.SetAPIEndpoint(configstore.APIEndpoint{
+		Addresses:   apiInfo.Addrs,
+		CACert:      apiInfo.CACert,
+		EnvironUUID: env.UUID(),
+	})
+	info.SetAPICredentials(configstore.APICredentials{
+		User:     "admin",
+		Password: "dummy-secret",
+	})
+	err = info.Write()
+	c.Assert(err, gc.IsNil)
+
+	_, err = coretesting.RunCommand(c, new(DestroyEnvironmentCommand), "hosted", "--yes")
+	c.Assert(err, gc.IsNil)
+
+	// The hosted environment has gone, but the
+	// state server's own environment is untouched.
+	_, err = s.State.HostedEnvironment(env.UUID())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.ConfigStore.ReadInfo("hosted")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	serverEnv, err := s.State.Environment()
+	c.Assert(err, gc.IsNil)
+	c.Assert(serverEnv.Life(), gc.Equals, state.Alive)
+}
+
 func (s *destroyEnvSuite) TestDestroyEnvironmentCommandBroken(c *gc.C) {
 	oldinfo, err := s.ConfigStore.ReadInfo("dummyenv")
 	c.Assert(err, gc.IsNil)
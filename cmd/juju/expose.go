@@ -5,8 +5,12 @@ package main
 
 import (
 	"errors"
+	"fmt"
+	"net"
+	"strings"
 
 	"github.com/juju/cmd"
+	"launchpad.net/gnuflag"
 
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/juju"
@@ -16,12 +20,23 @@ import (
 type ExposeCommand struct {
 	envcmd.EnvCommandBase
 	ServiceName string
+	ToCIDRs     string
+	cidrs       []string
 }
 
 var jujuExposeHelp = `
 Adjusts firewall rules and similar security mechanisms of the provider, to
 allow the service to be accessed on its public address.
 
+By default the service's open ports may be accessed from anywhere. The
+--to-cidrs option restricts access to the given comma-separated list of
+source CIDRs. Not all providers support restricting access in this way;
+on those that do not, the command fails and the service is not exposed.
+
+Examples:
+
+   juju expose wordpress
+   juju expose wordpress --to-cidrs 10.0.0.0/8,192.168.1.0/24
 `
 
 func (c *ExposeCommand) Info() *cmd.Info {
@@ -33,11 +48,24 @@ func (c *ExposeCommand) Info() *cmd.Info {
 	}
 }
 
+func (c *ExposeCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.ToCIDRs, "to-cidrs", "", "only allow access from these comma-separated source CIDRs")
+}
+
 func (c *ExposeCommand) Init(args []string) error {
 	if len(args) == 0 {
 		return errors.New("no service name specified")
 	}
 	c.ServiceName = args[0]
+	if c.ToCIDRs != "" {
+		for _, cidr := range strings.Split(c.ToCIDRs, ",") {
+			cidr = strings.TrimSpace(cidr)
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				return fmt.Errorf("invalid CIDR %q", cidr)
+			}
+			c.cidrs = append(c.cidrs, cidr)
+		}
+	}
 	return cmd.CheckEmpty(args[1:])
 }
 
@@ -49,5 +77,8 @@ func (c *ExposeCommand) Run(_ *cmd.Context) error {
 		return err
 	}
 	defer client.Close()
+	if len(c.cidrs) > 0 {
+		return client.ServiceExposeTo(c.ServiceName, c.cidrs)
+	}
 	return client.ServiceExpose(c.ServiceName)
 }
@@ -45,3 +45,19 @@ func (s *ExposeSuite) TestExpose(c *gc.C) {
 	err = runExpose(c, "nonexistent-service")
 	c.Assert(err, gc.ErrorMatches, `service "nonexistent-service" not found`)
 }
+
+func (s *ExposeSuite) TestExposeToCIDRs(c *gc.C) {
+	charmtesting.Charms.BundlePath(s.SeriesPath, "dummy")
+	err := runDeploy(c, "local:dummy", "some-service-name")
+	c.Assert(err, gc.IsNil)
+
+	err = runExpose(c, "some-service-name", "--to-cidrs", "10.0.0.0/8, 192.168.1.0/24")
+	c.Assert(err, gc.IsNil)
+	s.assertExposed(c, "some-service-name")
+	svc, err := s.State.Service("some-service-name")
+	c.Assert(err, gc.IsNil)
+	c.Assert(svc.ExposedCIDRs(), gc.DeepEquals, []string{"10.0.0.0/8", "192.168.1.0/24"})
+
+	err = runExpose(c, "some-service-name", "--to-cidrs", "10.0.0.1")
+	c.Assert(err, gc.ErrorMatches, `invalid CIDR "10.0.0.1"`)
+}
@@ -0,0 +1,102 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/environs/configstore"
+	"github.com/juju/juju/juju"
+)
+
+const getEnvironmentInfoDoc = `
+Prints an environment file (.jenv) holding the information needed
+to connect to the environment: its API server addresses, CA
+certificate and UUID. The information is fetched from the
+environment's API servers, so it is always up to date.
+
+The file holds no password, so it may be shared freely. It names the
+current user unless --user is given; the recipient supplies their own
+password when importing it with "juju import-environment".
+
+Examples:
+  juju get-environment-info --user bob -o bob.jenv
+  juju import-environment bob.jenv --password <bob's password>
+
+See Also:
+  juju help import-environment
+  juju help user add
+`
+
+// GetEnvironmentInfoCommand prints the connection
+// information for an environment, without any secrets.
+type GetEnvironmentInfoCommand struct {
+	envcmd.EnvCommandBase
+	out  cmd.Output
+	User string
+}
+
+func (c *GetEnvironmentInfoCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "get-environment-info",
+		Purpose: "print the connection information for an environment",
+		Doc:     getEnvironmentInfoDoc,
+	}
+}
+
+func (c *GetEnvironmentInfoCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "yaml", map[string]cmd.Formatter{
+		"yaml": cmd.FormatYaml,
+		"json": cmd.FormatJson,
+	})
+	f.StringVar(&c.User, "user", "", "the user to connect as (defaults to the current user)")
+}
+
+func (c *GetEnvironmentInfoCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+func (c *GetEnvironmentInfoCommand) Run(ctx *cmd.Context) error {
+	user := c.User
+	if user == "" {
+		store, err := configstore.Default()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		info, err := store.ReadInfo(c.EnvName)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		user = info.APICredentials().User
+	}
+	client, err := juju.NewAPIClientFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	connInfo, err := client.ConnectionInfo()
+	if err != nil {
+		return err
+	}
+	endpoint := juju.ConnectionEndpoint(connInfo)
+	if len(endpoint.Addresses) == 0 {
+		// The API servers have not recorded their addresses
+		// yet; fall back to the ones we know about locally.
+		endpoint, err = juju.APIEndpointForEnv(c.EnvName, false)
+		if err != nil {
+			return err
+		}
+		endpoint.CACert = connInfo.CACert
+		endpoint.EnvironUUID = connInfo.EnvironUUID
+	}
+	return c.out.Write(ctx, configstore.EnvironInfoData{
+		User:         user,
+		EnvironUUID:  endpoint.EnvironUUID,
+		StateServers: endpoint.Addresses,
+		CACert:       endpoint.CACert,
+	})
+}
@@ -0,0 +1,71 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	gc "launchpad.net/gocheck"
+	"launchpad.net/goyaml"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/environs/configstore"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/testing"
+)
+
+type GetEnvironmentInfoSuite struct {
+	jujutesting.JujuConnSuite
+}
+
+var _ = gc.Suite(&GetEnvironmentInfoSuite{})
+
+func (s *GetEnvironmentInfoSuite) runGetEnvironmentInfo(c *gc.C, args ...string) configstore.EnvironInfoData {
+	context, err := testing.RunCommand(c, envcmd.Wrap(&GetEnvironmentInfoCommand{}), args...)
+	c.Assert(err, gc.IsNil)
+	var data configstore.EnvironInfoData
+	err = goyaml.Unmarshal([]byte(testing.Stdout(context)), &data)
+	c.Assert(err, gc.IsNil)
+	return data
+}
+
+func (s *GetEnvironmentInfoSuite) TestGetEnvironmentInfo(c *gc.C) {
+	data := s.runGetEnvironmentInfo(c)
+	env, err := s.State.Environment()
+	c.Assert(err, gc.IsNil)
+	apiInfo := s.APIInfo(c)
+	c.Assert(data, gc.DeepEquals, configstore.EnvironInfoData{
+		User:         "admin",
+		EnvironUUID:  env.UUID(),
+		StateServers: apiInfo.Addrs,
+		CACert:       apiInfo.CACert,
+	})
+}
+
+func (s *GetEnvironmentInfoSuite) TestGetEnvironmentInfoUser(c *gc.C) {
+	data := s.runGetEnvironmentInfo(c, "--user", "bob")
+	c.Assert(data.User, gc.Equals, "bob")
+	c.Assert(data.Password, gc.Equals, "")
+	c.Assert(data.Config, gc.HasLen, 0)
+}
+
+func (s *GetEnvironmentInfoSuite) TestGetEnvironmentInfoOutputFile(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "shared.jenv")
+	context, err := testing.RunCommand(c, envcmd.Wrap(&GetEnvironmentInfoCommand{}), "-o", path)
+	c.Assert(err, gc.IsNil)
+	c.Assert(testing.Stdout(context), gc.Equals, "")
+	content, err := ioutil.ReadFile(path)
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(content), gc.Not(gc.Matches), "(?s).*dummy-secret.*")
+	var data configstore.EnvironInfoData
+	err = goyaml.Unmarshal(content, &data)
+	c.Assert(err, gc.IsNil)
+	c.Assert(data.StateServers, gc.DeepEquals, s.APIInfo(c).Addrs)
+}
+
+func (s *GetEnvironmentInfoSuite) TestTooManyArgs(c *gc.C) {
+	_, err := testing.RunCommand(c, envcmd.Wrap(&GetEnvironmentInfoCommand{}), "foo")
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["foo"\]`)
+}
@@ -474,6 +474,11 @@ back to the bootstrap node, and they are accumulated into the all-machines.log
 file.  Each line is prefixed with the source agent tag (also the same as
 the filename without the extension).
 
+The agents also send their log messages to the state servers, which store
+them in the database. This is what the 'debug-log' command shows, so that
+the same messages are seen whichever state server it connects to, and they
+can be filtered by entity, module, level and time on the server.
+
 Juju has a hierarchical logging system internally, and as a user you can
 control how much information is logged out.
 
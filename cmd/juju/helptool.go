@@ -5,11 +5,13 @@ package main
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/juju/charm"
 	"github.com/juju/cmd"
 	"launchpad.net/gnuflag"
 
+	"github.com/juju/juju/state/api/params"
 	"github.com/juju/juju/worker/uniter/jujuc"
 )
 
@@ -26,10 +28,10 @@ func (dummyHookContext) PublicAddress() (string, bool) {
 func (dummyHookContext) PrivateAddress() (string, bool) {
 	return "", false
 }
-func (dummyHookContext) OpenPort(protocol string, port int) error {
+func (dummyHookContext) OpenPorts(protocol string, fromPort, toPort int) error {
 	return nil
 }
-func (dummyHookContext) ClosePort(protocol string, port int) error {
+func (dummyHookContext) ClosePorts(protocol string, fromPort, toPort int) error {
 	return nil
 }
 func (dummyHookContext) ConfigSettings() (charm.Settings, error) {
@@ -52,6 +54,54 @@ func (dummyHookContext) OwnerTag() string {
 	return ""
 }
 
+func (dummyHookContext) ActionParams() (map[string]interface{}, error) {
+	return nil, fmt.Errorf("not running an action")
+}
+
+func (dummyHookContext) UpdateActionResults(keys []string, value string) error {
+	return fmt.Errorf("not running an action")
+}
+
+func (dummyHookContext) SetActionMessage(message string) error {
+	return fmt.Errorf("not running an action")
+}
+
+func (dummyHookContext) SetActionFailed() error {
+	return fmt.Errorf("not running an action")
+}
+
+func (dummyHookContext) WorkloadStatus() (params.WorkloadStatus, string, error) {
+	return params.WorkloadUnknown, "", nil
+}
+
+func (dummyHookContext) SetWorkloadStatus(status params.WorkloadStatus, message string) error {
+	return nil
+}
+
+func (dummyHookContext) IsLeader() (bool, error) {
+	return false, nil
+}
+
+func (dummyHookContext) LeaderSettings() (map[string]string, error) {
+	return map[string]string{}, nil
+}
+
+func (dummyHookContext) WriteLeaderSettings(settings map[string]string) error {
+	return nil
+}
+
+func (dummyHookContext) HookStorageId() (string, bool) {
+	return "", false
+}
+
+func (dummyHookContext) StorageInstances() ([]params.StorageInstance, error) {
+	return nil, nil
+}
+
+func (dummyHookContext) AddMetric(key, value string, created time.Time) error {
+	return fmt.Errorf("metrics disabled")
+}
+
 type HelpToolCommand struct {
 	cmd.CommandBase
 	tool string
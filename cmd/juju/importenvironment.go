@@ -0,0 +1,181 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+	"launchpad.net/gnuflag"
+	"launchpad.net/goyaml"
+
+	"github.com/juju/juju/environs/configstore"
+	"github.com/juju/juju/juju"
+)
+
+const importEnvironmentDoc = `
+Imports an environment file (.jenv), as written by
+"juju get-environment-info" or "juju user add --output", so that the
+environment can be used with the -e flag or "juju switch". The file
+may be given as a local path or an https URL. As the file holds the
+credentials for the environment, it is only fetched from an http URL
+if --insecure is given.
+
+The environment is named after the file, without its .jenv extension,
+unless a name is given. The password is taken from the file unless
+--password is given. Before the environment is saved, its details
+are verified by logging in to it and fetching the current API server
+addresses, so the saved file is always up to date.
+
+Examples:
+  juju import-environment bob.jenv --password <bob's password>
+  juju import-environment https://example.com/shared.jenv staging
+`
+
+// ImportEnvironmentCommand saves an environment file
+// fetched from elsewhere into the local environment store.
+type ImportEnvironmentCommand struct {
+	cmd.CommandBase
+	Source   string
+	Name     string
+	Password string
+	Insecure bool
+}
+
+func (c *ImportEnvironmentCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "import-environment",
+		Args:    "<file|url> [<name>]",
+		Purpose: "import an environment file",
+		Doc:     importEnvironmentDoc,
+	}
+}
+
+func (c *ImportEnvironmentCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.Password, "password", "", "the password to connect with")
+	f.BoolVar(&c.Insecure, "insecure", false, "allow the file to be fetched from an http URL")
+}
+
+func (c *ImportEnvironmentCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no environment file specified")
+	}
+	c.Source, args = args[0], args[1:]
+	if strings.HasPrefix(c.Source, "http://") && !c.Insecure {
+		return fmt.Errorf("refusing to fetch environment file over http; use an https URL or --insecure")
+	}
+	if len(args) > 0 {
+		c.Name, args = args[0], args[1:]
+	} else {
+		c.Name = strings.TrimSuffix(jenvBase(c.Source), ".jenv")
+	}
+	if c.Name == "" {
+		return fmt.Errorf("no environment name specified")
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *ImportEnvironmentCommand) Run(ctx *cmd.Context) (err error) {
+	data, err := c.readSource(ctx)
+	if err != nil {
+		return errors.Annotatef(err, "cannot read environment file")
+	}
+	var jenv configstore.EnvironInfoData
+	if err = goyaml.Unmarshal(data, &jenv); err != nil {
+		return errors.Annotatef(err, "cannot parse environment file")
+	}
+	if len(jenv.StateServers) == 0 {
+		return fmt.Errorf("environment file has no state server addresses")
+	}
+	if jenv.User == "" {
+		return fmt.Errorf("environment file has no user")
+	}
+	creds := configstore.APICredentials{
+		User:     jenv.User,
+		Password: jenv.Password,
+	}
+	if c.Password != "" {
+		creds.Password = c.Password
+	}
+	if creds.Password == "" {
+		return fmt.Errorf("no password specified; use --password")
+	}
+	store, err := configstore.Default()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	info, err := store.CreateInfo(c.Name)
+	if err == configstore.ErrEnvironInfoAlreadyExists {
+		return fmt.Errorf("environment %q already exists", c.Name)
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer func() {
+		// Don't leave an empty environment file
+		// behind if the import fails.
+		if err != nil {
+			info.Destroy()
+		}
+	}()
+	apiStore := juju.NewAPIStore(c.Name, configstore.APIEndpoint{
+		Addresses:   jenv.StateServers,
+		CACert:      jenv.CACert,
+		EnvironUUID: jenv.EnvironUUID,
+	}, creds)
+	apiInfo, err := apiStore.ReadInfo(c.Name)
+	if err != nil {
+		return err
+	}
+	info.SetAPIEndpoint(apiInfo.APIEndpoint())
+	info.SetAPICredentials(apiInfo.APICredentials())
+	if err = info.Write(); err != nil {
+		return errors.Trace(err)
+	}
+	fmt.Fprintf(ctx.Stdout, "environment %q imported to %s\n", c.Name, info.Location())
+	return nil
+}
+
+// readSource returns the contents of the environment
+// file named by the command's source argument.
+func (c *ImportEnvironmentCommand) readSource(ctx *cmd.Context) ([]byte, error) {
+	if !isURL(c.Source) {
+		return ioutil.ReadFile(ctx.AbsPath(c.Source))
+	}
+	resp, err := utils.GetValidatingHTTPClient().Get(c.Source)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot get %q: %s", c.Source, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// isURL reports whether source is an http or https URL.
+func isURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// jenvBase returns the last element of the
+// path of the given environment file source.
+func jenvBase(source string) string {
+	if isURL(source) {
+		u, err := url.Parse(source)
+		if err != nil {
+			return ""
+		}
+		return path.Base(u.Path)
+	}
+	return filepath.Base(source)
+}
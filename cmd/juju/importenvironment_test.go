@@ -0,0 +1,156 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/environs/configstore"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/testing"
+)
+
+type ImportEnvironmentSuite struct {
+	jujutesting.JujuConnSuite
+}
+
+var _ = gc.Suite(&ImportEnvironmentSuite{})
+
+var importEnvironmentInitTests = []struct {
+	args []string
+	name string
+	err  string
+}{{
+	err: "no environment file specified",
+}, {
+	args: []string{"shared.jenv"},
+	name: "shared",
+}, {
+	args: []string{"/some/dir/shared"},
+	name: "shared",
+}, {
+	args: []string{"https://example.com/envs/shared.jenv?x=y"},
+	name: "shared",
+}, {
+	args: []string{"http://example.com/envs/shared.jenv"},
+	err:  "refusing to fetch environment file over http; use an https URL or --insecure",
+}, {
+	args: []string{"--insecure", "http://example.com/envs/shared.jenv"},
+	name: "shared",
+}, {
+	args: []string{"shared.jenv", "other"},
+	name: "other",
+}, {
+	args: []string{"shared.jenv", "other", "extra"},
+	err:  `unrecognized args: \["extra"\]`,
+}}
+
+func (s *ImportEnvironmentSuite) TestInit(c *gc.C) {
+	for i, test := range importEnvironmentInitTests {
+		c.Logf("test %d: %q", i, test.args)
+		command := &ImportEnvironmentCommand{}
+		err := testing.InitCommand(command, test.args)
+		if test.err != "" {
+			c.Check(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Check(err, gc.IsNil)
+		c.Check(command.Name, gc.Equals, test.name)
+	}
+}
+
+// writeJenv writes an environment file for the test
+// environment, holding the given password, and returns
+// its contents and path.
+func (s *ImportEnvironmentSuite) writeJenv(c *gc.C, password string) ([]byte, string) {
+	apiInfo := s.APIInfo(c)
+	content, err := cmd.FormatYaml(configstore.EnvironInfoData{
+		User:         "admin",
+		Password:     password,
+		StateServers: apiInfo.Addrs,
+		CACert:       apiInfo.CACert,
+	})
+	c.Assert(err, gc.IsNil)
+	path := filepath.Join(c.MkDir(), "shared.jenv")
+	err = ioutil.WriteFile(path, content, 0600)
+	c.Assert(err, gc.IsNil)
+	return content, path
+}
+
+func (s *ImportEnvironmentSuite) assertImported(c *gc.C, name, password string) {
+	store, err := configstore.Default()
+	c.Assert(err, gc.IsNil)
+	info, err := store.ReadInfo(name)
+	c.Assert(err, gc.IsNil)
+	env, err := s.State.Environment()
+	c.Assert(err, gc.IsNil)
+	apiInfo := s.APIInfo(c)
+	c.Assert(info.APIEndpoint(), gc.DeepEquals, configstore.APIEndpoint{
+		Addresses:   apiInfo.Addrs,
+		CACert:      apiInfo.CACert,
+		EnvironUUID: env.UUID(),
+	})
+	c.Assert(info.APICredentials(), gc.Equals, configstore.APICredentials{
+		User:     "admin",
+		Password: password,
+	})
+}
+
+func (s *ImportEnvironmentSuite) TestImportFile(c *gc.C) {
+	_, path := s.writeJenv(c, "")
+	context, err := testing.RunCommand(c, &ImportEnvironmentCommand{}, path, "--password", "dummy-secret")
+	c.Assert(err, gc.IsNil)
+	c.Assert(testing.Stdout(context), gc.Matches, `environment "shared" imported to .*shared\.jenv\n`)
+	s.assertImported(c, "shared", "dummy-secret")
+}
+
+func (s *ImportEnvironmentSuite) TestImportURL(c *gc.C) {
+	content, _ := s.writeJenv(c, "dummy-secret")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	}))
+	defer server.Close()
+	_, err := testing.RunCommand(c, &ImportEnvironmentCommand{}, "--insecure", server.URL+"/envs/shared.jenv", "team")
+	c.Assert(err, gc.IsNil)
+	s.assertImported(c, "team", "dummy-secret")
+}
+
+func (s *ImportEnvironmentSuite) TestImportURLNotFound(c *gc.C) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	_, err := testing.RunCommand(c, &ImportEnvironmentCommand{}, "--insecure", server.URL+"/shared.jenv", "--password", "dummy-secret")
+	c.Assert(err, gc.ErrorMatches, `cannot read environment file: cannot get ".*": 404 Not Found`)
+}
+
+func (s *ImportEnvironmentSuite) TestImportNoPassword(c *gc.C) {
+	_, path := s.writeJenv(c, "")
+	_, err := testing.RunCommand(c, &ImportEnvironmentCommand{}, path)
+	c.Assert(err, gc.ErrorMatches, "no password specified; use --password")
+}
+
+func (s *ImportEnvironmentSuite) TestImportBadPassword(c *gc.C) {
+	_, path := s.writeJenv(c, "")
+	_, err := testing.RunCommand(c, &ImportEnvironmentCommand{}, path, "--password", "wrong")
+	c.Assert(err, gc.ErrorMatches, `cannot fetch information for environment "shared": invalid entity name or password`)
+
+	// No environment file is left behind.
+	store, err := configstore.Default()
+	c.Assert(err, gc.IsNil)
+	_, err = store.ReadInfo("shared")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *ImportEnvironmentSuite) TestImportExisting(c *gc.C) {
+	_, path := s.writeJenv(c, "dummy-secret")
+	_, err := testing.RunCommand(c, &ImportEnvironmentCommand{}, path, "dummyenv")
+	c.Assert(err, gc.ErrorMatches, `environment "dummyenv" already exists`)
+}
@@ -4,25 +4,60 @@
 package main
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
 
+	"code.google.com/p/go.crypto/ssh/terminal"
 	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/utils/set"
 	"launchpad.net/gnuflag"
+	"launchpad.net/goyaml"
 
 	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/juju/osenv"
 )
 
 // InitCommand is used to write out a boilerplate environments.yaml file.
 type InitCommand struct {
 	cmd.CommandBase
-	WriteFile bool
-	Show      bool
+	WriteFile   bool
+	Show        bool
+	Interactive bool
 }
 
+const initDoc = `
+With no options, init writes a boilerplate environments.yaml file with
+sample configuration for every provider, to be edited by hand.
+
+With --interactive, init asks for the provider type, name and credentials
+of a single environment, and adds it to environments.yaml, creating the
+file if it does not exist. The other environments in an existing file are
+kept, though its comments are not, and the environment only becomes the
+default if there are no others. An existing environment of the same name
+is only replaced if -f is given. Credentials found on the local machine are
+offered as defaults:
+
+    ec2:       the AWS_* environment variables, or ~/.aws/credentials
+               and ~/.aws/config
+    openstack: the OS_* environment variables, or ~/.novarc
+    maas:      the OAuth key in ~/.juju/maas-oauth
+
+Secret values are not echoed as they are typed. The resulting
+configuration is validated, and the credentials are checked with a
+request to the cloud that changes nothing there, before it is written.
+`
+
 func (c *InitCommand) Info() *cmd.Info {
 	return &cmd.Info{
 		Name:    "init",
 		Purpose: "generate boilerplate configuration for juju environments",
+		Doc:     initDoc,
 		Aliases: []string{"generate-config"},
 	}
 }
@@ -30,6 +65,8 @@ func (c *InitCommand) Info() *cmd.Info {
 func (c *InitCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.BoolVar(&c.WriteFile, "f", false, "force overwriting environments.yaml file even if it exists (ignored if --show flag specified)")
 	f.BoolVar(&c.Show, "show", false, "print the generated configuration data to stdout instead of writing it to a file")
+	f.BoolVar(&c.Interactive, "i", false, "ask for the configuration of a single environment, detecting credentials where possible")
+	f.BoolVar(&c.Interactive, "interactive", false, "")
 }
 
 var errJujuEnvExists = fmt.Errorf(`A juju environment configuration already exists.
@@ -40,19 +77,24 @@ Use -f to overwrite the existing environments.yaml.
 // Run checks to see if there is already an environments.yaml file. In one does not exist already,
 // a boilerplate version is created so that the user can edit it to get started.
 func (c *InitCommand) Run(context *cmd.Context) error {
+	if c.Interactive {
+		return c.runInteractive(context)
+	}
 	out := context.Stdout
+	if !c.Show {
+		_, err := environs.ReadEnvirons("")
+		if err == nil && !c.WriteFile {
+			return errJujuEnvExists
+		}
+		if err != nil && !environs.IsNoEnv(err) {
+			return err
+		}
+	}
 	config := environs.BoilerplateConfig()
 	if c.Show {
 		fmt.Fprint(out, config)
 		return nil
 	}
-	_, err := environs.ReadEnvirons("")
-	if err == nil && !c.WriteFile {
-		return errJujuEnvExists
-	}
-	if err != nil && !environs.IsNoEnv(err) {
-		return err
-	}
 	filename, err := environs.WriteEnvirons("", config)
 	if err != nil {
 		return fmt.Errorf("A boilerplate environment configuration file could not be created: %s", err.Error())
@@ -61,3 +103,228 @@ func (c *InitCommand) Run(context *cmd.Context) error {
 	fmt.Fprint(out, "Edit the file to configure your juju environment and run bootstrap.\n")
 	return nil
 }
+
+// runInteractive asks the user for the configuration of a single
+// environment and adds it to environments.yaml, creating the file if
+// necessary.
+func (c *InitCommand) runInteractive(context *cmd.Context) error {
+	var data []byte
+	checkName := func(string) error { return nil }
+	if !c.Show {
+		// Check the existing file before anything is asked.
+		envs, err := environs.ReadEnvirons("")
+		if err == nil {
+			if data, err = ioutil.ReadFile(osenv.JujuHomePath("environments.yaml")); err != nil {
+				return err
+			}
+			checkName = func(name string) error {
+				if !c.WriteFile && set.NewStrings(envs.Names()...).Contains(name) {
+					return errors.Errorf("environment %q already exists; use -f to replace it", name)
+				}
+				return nil
+			}
+		} else if !environs.IsNoEnv(err) {
+			return err
+		}
+	}
+	p := &prompter{
+		scanner: bufio.NewScanner(context.Stdin),
+		out:     context.Stdout,
+	}
+	if f, ok := context.Stdin.(*os.File); ok && terminal.IsTerminal(int(f.Fd())) {
+		p.readSecret = func() (string, error) {
+			data, err := terminal.ReadPassword(int(f.Fd()))
+			// The newline typed by the user is not echoed either.
+			fmt.Fprintln(context.Stdout)
+			return string(data), err
+		}
+	}
+	name, attrs, err := interactiveConfig(p, checkName)
+	if err != nil {
+		return err
+	}
+	config, err := addEnvironment(data, name, attrs)
+	if err != nil {
+		return err
+	}
+	if c.Show {
+		fmt.Fprint(context.Stdout, config)
+		return nil
+	}
+	filename, err := environs.WriteEnvirons("", config)
+	if err != nil {
+		return fmt.Errorf("An environment configuration file could not be created: %s", err.Error())
+	}
+	fmt.Fprintf(context.Stdout, "An environment configuration file has been written to %s.\n", filename)
+	fmt.Fprint(context.Stdout, "Run juju bootstrap to start the environment.\n")
+	return nil
+}
+
+const interactiveConfigHeader = `
+# This is the Juju config file, generated by juju init --interactive.
+# See https://juju.ubuntu.com/docs for more information.
+
+`[1:]
+
+// checkCredentials checks the credentials in the given configuration
+// with the given checker. It is a variable so that tests need not
+// contact any cloud.
+var checkCredentials = func(checker environs.CredentialChecker, cfg *config.Config) error {
+	return checker.CheckCredentials(cfg)
+}
+
+// interactiveConfig asks for the provider type, name and credentials
+// of a new environment, offering any credentials that the provider
+// can detect as defaults, and returns its name and attributes. The
+// name is checked with checkName as soon as it is given, and the
+// credentials are checked if the provider supports it.
+func interactiveConfig(p *prompter, checkName func(string) error) (string, map[string]interface{}, error) {
+	types := environs.CredentialDetectorTypes()
+	if len(types) == 0 {
+		return "", nil, errors.New("no provider supports interactive configuration")
+	}
+	providerType, err := p.prompt(
+		fmt.Sprintf("Provider type (%s)", strings.Join(types, ", ")),
+		types[0], false,
+	)
+	if err != nil {
+		return "", nil, err
+	}
+	provider, err := environs.Provider(providerType)
+	if err != nil {
+		return "", nil, err
+	}
+	detector, ok := provider.(environs.CredentialDetector)
+	if !ok {
+		return "", nil, errors.Errorf("provider %q does not support interactive configuration", providerType)
+	}
+	name, err := p.prompt("Environment name", providerType, false)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := checkName(name); err != nil {
+		return "", nil, err
+	}
+	detected, err := detector.DetectCredentials()
+	if err != nil {
+		return "", nil, errors.Annotate(err, "cannot detect credentials")
+	}
+	attrs := map[string]interface{}{
+		"type": providerType,
+	}
+	for _, attr := range detector.CredentialAttrs() {
+		value, _ := detected[attr.Name].(string)
+		value, err = p.prompt(attr.Name, value, attr.Secret)
+		if err != nil {
+			return "", nil, err
+		}
+		if value != "" {
+			attrs[attr.Name] = value
+		}
+	}
+
+	// Check the configuration as bootstrap would, without
+	// preparing the environment, as that may create resources
+	// in the cloud.
+	cfgAttrs := map[string]interface{}{"name": name}
+	for attr, value := range attrs {
+		cfgAttrs[attr] = value
+	}
+	cfg, err := config.New(config.UseDefaults, cfgAttrs)
+	if err == nil {
+		cfg, err = provider.Validate(cfg, nil)
+	}
+	if err != nil {
+		return "", nil, errors.Annotatef(err, "invalid configuration for environment %q", name)
+	}
+	if checker, ok := provider.(environs.CredentialChecker); ok {
+		fmt.Fprintln(p.out, "Checking credentials...")
+		if err := checkCredentials(checker, cfg); err != nil {
+			return "", nil, errors.Annotatef(err, "credentials for environment %q not accepted", name)
+		}
+	}
+
+	return name, attrs, nil
+}
+
+// addEnvironment returns the contents of the environments.yaml file
+// with the given contents, if any, with the named environment added
+// or replaced. The environment becomes the default only if there are
+// no others. Other environments are kept, but comments are not.
+func addEnvironment(data []byte, name string, attrs map[string]interface{}) (string, error) {
+	header := ""
+	doc := make(map[string]interface{})
+	if len(data) == 0 {
+		header = interactiveConfigHeader
+	} else if err := goyaml.Unmarshal(data, &doc); err != nil {
+		return "", err
+	}
+	envs, _ := doc["environments"].(map[interface{}]interface{})
+	if envs == nil {
+		envs = make(map[interface{}]interface{})
+	}
+	if defaultName, _ := doc["default"].(string); defaultName == "" {
+		// A single environment is the default without being
+		// named as such, so it must be named to remain so.
+		switch len(envs) {
+		case 0:
+			doc["default"] = name
+		case 1:
+			for existing := range envs {
+				doc["default"] = existing
+			}
+		}
+	}
+	envs[name] = attrs
+	doc["environments"] = envs
+	out, err := goyaml.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+	return header + string(out), nil
+}
+
+// prompter asks the user questions.
+type prompter struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+
+	// readSecret, if not nil, is used to read answers
+	// to secret questions without echoing them.
+	readSecret func() (string, error)
+}
+
+// prompt asks the user the given question and returns the answer,
+// or defaultValue if the answer is empty. If secret is true, the
+// default value is masked and the answer is not echoed, where
+// possible.
+func (p *prompter) prompt(question, defaultValue string, secret bool) (string, error) {
+	switch {
+	case defaultValue == "":
+		fmt.Fprintf(p.out, "%s: ", question)
+	case secret:
+		fmt.Fprintf(p.out, "%s [%s]: ", question, strings.Repeat("*", 8))
+	default:
+		fmt.Fprintf(p.out, "%s [%s]: ", question, defaultValue)
+	}
+	if secret && p.readSecret != nil {
+		answer, err := p.readSecret()
+		if err != nil {
+			return "", err
+		}
+		if answer = strings.TrimSpace(answer); answer != "" {
+			return answer, nil
+		}
+		return defaultValue, nil
+	}
+	if !p.scanner.Scan() {
+		if err := p.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", errors.New("unexpected end of input")
+	}
+	if answer := strings.TrimSpace(p.scanner.Text()); answer != "" {
+		return answer, nil
+	}
+	return defaultValue, nil
+}
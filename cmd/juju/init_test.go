@@ -4,20 +4,28 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/juju/cmd"
 	gitjujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
 	gc "launchpad.net/gocheck"
 
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/testing"
 )
 
 type InitSuite struct {
 	testing.FakeJujuHomeSuite
+	checked  *config.Config
+	checkErr error
 }
 
 var _ = gc.Suite(&InitSuite{})
@@ -100,3 +108,165 @@ func (*InitSuite) TestExistingEnvironmentOverwritten(c *gc.C) {
 	strippedData := strings.Replace(string(data), "\n", "", -1)
 	c.Assert(strippedData, gc.Matches, ".*# This is the Juju config file, which you can use.*")
 }
+
+func (s *InitSuite) setUpInteractive(c *gc.C) {
+	err := os.Remove(gitjujutesting.HomePath(".juju", "environments.yaml"))
+	c.Assert(err, gc.IsNil)
+	s.Home.AddFiles(c, gitjujutesting.TestFile{".ssh/id_rsa.pub", "rsa\n"})
+	for _, name := range []string{
+		"AWS_ACCESS_KEY", "AWS_SECRET_KEY",
+		"EC2_ACCESS_KEY", "EC2_SECRET_KEY",
+		"AWS_PROFILE", "AWS_DEFAULT_REGION",
+	} {
+		s.PatchEnvironment(name, "")
+	}
+	s.PatchEnvironment("AWS_ACCESS_KEY_ID", "key-id")
+	s.PatchEnvironment("AWS_SECRET_ACCESS_KEY", "secret")
+	s.checked, s.checkErr = nil, nil
+	s.PatchValue(&checkCredentials, func(checker environs.CredentialChecker, cfg *config.Config) error {
+		s.checked = cfg
+		return s.checkErr
+	})
+}
+
+// With --interactive, the detected credentials are offered as
+// defaults and a single environment is written.
+func (s *InitSuite) TestInteractive(c *gc.C) {
+	s.setUpInteractive(c)
+	ctx := testing.Context(c)
+	ctx.Stdin = strings.NewReader("ec2\nmyenv\nus-west-2\n\n\n")
+	code := cmd.Main(&InitCommand{}, ctx, []string{"--interactive"})
+	c.Assert(code, gc.Equals, 0, gc.Commentf("stderr: %s", testing.Stderr(ctx)))
+
+	stdout := testing.Stdout(ctx)
+	c.Check(stdout, jc.Contains, "Environment name [ec2]: ")
+	c.Check(stdout, jc.Contains, "access-key [key-id]: ")
+	c.Check(stdout, jc.Contains, "secret-key [********]: ")
+	c.Check(stdout, jc.Contains, "Checking credentials...")
+	c.Check(stdout, jc.Contains, "An environment configuration file has been written")
+
+	c.Assert(s.checked, gc.NotNil)
+	c.Assert(s.checked.Name(), gc.Equals, "myenv")
+	c.Assert(s.checked.UnknownAttrs()["secret-key"], gc.Equals, "secret")
+
+	envs, err := environs.ReadEnvirons("")
+	c.Assert(err, gc.IsNil)
+	c.Assert(envs.Default, gc.Equals, "myenv")
+	c.Assert(envs.Names(), gc.DeepEquals, []string{"myenv"})
+	cfg, err := envs.Config("myenv")
+	c.Assert(err, gc.IsNil)
+	c.Assert(cfg.Type(), gc.Equals, "ec2")
+	attrs := cfg.UnknownAttrs()
+	c.Assert(attrs["region"], gc.Equals, "us-west-2")
+	c.Assert(attrs["access-key"], gc.Equals, "key-id")
+	c.Assert(attrs["secret-key"], gc.Equals, "secret")
+}
+
+// Invalid configuration is reported, and not written.
+func (s *InitSuite) TestInteractiveInvalidConfig(c *gc.C) {
+	s.setUpInteractive(c)
+	ctx := testing.Context(c)
+	ctx.Stdin = strings.NewReader("ec2\nmyenv\nnowhere\n\n\n")
+	code := cmd.Main(&InitCommand{}, ctx, []string{"-i"})
+	c.Check(code, gc.Equals, 1)
+	c.Check(testing.Stderr(ctx), gc.Matches, `error: invalid configuration for environment "myenv": invalid region name "nowhere"\n`)
+	_, err := os.Stat(gitjujutesting.HomePath(".juju", "environments.yaml"))
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
+
+// Credentials rejected by the cloud are reported, and not written.
+func (s *InitSuite) TestInteractiveBadCredentials(c *gc.C) {
+	s.setUpInteractive(c)
+	s.checkErr = fmt.Errorf("AWS was not able to validate the provided access credentials")
+	ctx := testing.Context(c)
+	ctx.Stdin = strings.NewReader("ec2\nmyenv\nus-west-2\n\n\n")
+	code := cmd.Main(&InitCommand{}, ctx, []string{"-i"})
+	c.Check(code, gc.Equals, 1)
+	c.Check(testing.Stderr(ctx), gc.Equals, `error: credentials for environment "myenv" not accepted: AWS was not able to validate the provided access credentials`+"\n")
+	_, err := os.Stat(gitjujutesting.HomePath(".juju", "environments.yaml"))
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
+
+// Secret answers are read without echo where possible.
+func (s *InitSuite) TestPromptReadsSecretWithoutEcho(c *gc.C) {
+	var out bytes.Buffer
+	p := &prompter{
+		scanner: bufio.NewScanner(strings.NewReader("visible\n")),
+		out:     &out,
+		readSecret: func() (string, error) {
+			return "hidden\n", nil
+		},
+	}
+	answer, err := p.prompt("secret-key", "", true)
+	c.Assert(err, gc.IsNil)
+	c.Assert(answer, gc.Equals, "hidden")
+	answer, err = p.prompt("access-key", "", false)
+	c.Assert(err, gc.IsNil)
+	c.Assert(answer, gc.Equals, "visible")
+	c.Assert(out.String(), gc.Equals, "secret-key: access-key: ")
+}
+
+func (s *InitSuite) TestInteractiveUnknownProvider(c *gc.C) {
+	s.setUpInteractive(c)
+	ctx := testing.Context(c)
+	ctx.Stdin = strings.NewReader("dummy\n")
+	code := cmd.Main(&InitCommand{}, ctx, []string{"-i"})
+	c.Check(code, gc.Equals, 1)
+	c.Check(testing.Stderr(ctx), gc.Equals, "error: provider \"dummy\" does not support interactive configuration\n")
+}
+
+// With --interactive, the new environment is added to an
+// existing environments.yaml.
+func (s *InitSuite) TestInteractiveAddsToExistingEnvironments(c *gc.C) {
+	s.setUpInteractive(c)
+	testing.WriteEnvironments(c, existingEnv)
+	ctx := testing.Context(c)
+	ctx.Stdin = strings.NewReader("ec2\nmyenv\nus-west-2\n\n\n")
+	code := cmd.Main(&InitCommand{}, ctx, []string{"-i"})
+	c.Assert(code, gc.Equals, 0, gc.Commentf("stderr: %s", testing.Stderr(ctx)))
+
+	envs, err := environs.ReadEnvirons("")
+	c.Assert(err, gc.IsNil)
+	c.Assert(envs.Default, gc.Equals, "test")
+	names := envs.Names()
+	sort.Strings(names)
+	c.Assert(names, gc.DeepEquals, []string{"myenv", "test"})
+	cfg, err := envs.Config("myenv")
+	c.Assert(err, gc.IsNil)
+	c.Assert(cfg.Type(), gc.Equals, "ec2")
+	cfg, err = envs.Config("test")
+	c.Assert(err, gc.IsNil)
+	c.Assert(cfg.Type(), gc.Equals, "dummy")
+}
+
+// An existing environment is not replaced without -f, and its
+// name is checked before the credentials are asked for.
+func (s *InitSuite) TestInteractiveExistingEnvironmentNotReplaced(c *gc.C) {
+	s.setUpInteractive(c)
+	testing.WriteEnvironments(c, existingEnv)
+	ctx := testing.Context(c)
+	ctx.Stdin = strings.NewReader("ec2\ntest\n")
+	code := cmd.Main(&InitCommand{}, ctx, []string{"-i"})
+	c.Check(code, gc.Equals, 1)
+	c.Check(testing.Stdout(ctx), gc.Not(jc.Contains), "access-key")
+	c.Check(testing.Stderr(ctx), gc.Equals, "error: environment \"test\" already exists; use -f to replace it\n")
+	data, err := ioutil.ReadFile(gitjujutesting.HomePath(".juju", "environments.yaml"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(data), gc.Equals, existingEnv)
+}
+
+func (s *InitSuite) TestInteractiveExistingEnvironmentReplaced(c *gc.C) {
+	s.setUpInteractive(c)
+	testing.WriteEnvironments(c, existingEnv)
+	ctx := testing.Context(c)
+	ctx.Stdin = strings.NewReader("ec2\ntest\nus-west-2\n\n\n")
+	code := cmd.Main(&InitCommand{}, ctx, []string{"-i", "-f"})
+	c.Assert(code, gc.Equals, 0, gc.Commentf("stderr: %s", testing.Stderr(ctx)))
+
+	envs, err := environs.ReadEnvirons("")
+	c.Assert(err, gc.IsNil)
+	c.Assert(envs.Names(), gc.DeepEquals, []string{"test"})
+	cfg, err := envs.Config("test")
+	c.Assert(err, gc.IsNil)
+	c.Assert(cfg.Type(), gc.Equals, "ec2")
+}
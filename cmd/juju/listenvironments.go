@@ -0,0 +1,94 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/juju/cmd"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+const listEnvironmentsDoc = `
+list-environments shows the environments hosted by the state server of
+the current environment, starting with the state server's own
+environment. New environments are added with create-environment.
+`
+
+// ListEnvironmentsCommand shows the environments hosted
+// by the state server of the current environment.
+type ListEnvironmentsCommand struct {
+	envcmd.EnvCommandBase
+	out cmd.Output
+}
+
+func (c *ListEnvironmentsCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "list-environments",
+		Purpose: "list the environments hosted by the current state server",
+		Doc:     listEnvironmentsDoc,
+	}
+}
+
+func (c *ListEnvironmentsCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "tabular", map[string]cmd.Formatter{
+		"tabular": formatEnvironmentsTabular,
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+	})
+}
+
+func (c *ListEnvironmentsCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+// environmentInfo is the serialisation format of an environment.
+type environmentInfo struct {
+	Name  string `json:"name" yaml:"name"`
+	UUID  string `json:"uuid" yaml:"uuid"`
+	Owner string `json:"owner" yaml:"owner"`
+}
+
+func (c *ListEnvironmentsCommand) Run(ctx *cmd.Context) error {
+	client, err := getEnvironmentManagerAPI(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	envs, err := client.ListEnvironments()
+	if err != nil {
+		return err
+	}
+	out := make([]environmentInfo, len(envs))
+	for i, env := range envs {
+		out[i] = environmentInfo{
+			Name:  env.Name,
+			UUID:  env.UUID,
+			Owner: strings.TrimPrefix(env.OwnerTag, names.UserTagKind+"-"),
+		}
+	}
+	return c.out.Write(ctx, out)
+}
+
+// formatEnvironmentsTabular formats environments as a table.
+func formatEnvironmentsTabular(value interface{}) ([]byte, error) {
+	envs, ok := value.([]environmentInfo)
+	if !ok {
+		return nil, fmt.Errorf("expected value of type %T, got %T", envs, value)
+	}
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 1, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tOWNER\tUUID")
+	for _, env := range envs {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", env.Name, env.Owner, env.UUID)
+	}
+	tw.Flush()
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
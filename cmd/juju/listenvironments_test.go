@@ -0,0 +1,84 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"errors"
+
+	"github.com/juju/cmd"
+	gc "launchpad.net/gocheck"
+	"launchpad.net/goyaml"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/testing"
+)
+
+type ListEnvironmentsSuite struct {
+	testing.FakeJujuHomeSuite
+	mockAPI *mockEnvironmentManagerAPI
+}
+
+var _ = gc.Suite(&ListEnvironmentsSuite{})
+
+func (s *ListEnvironmentsSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.mockAPI = &mockEnvironmentManagerAPI{
+		envs: []params.EnvironmentInfo{{
+			Name:     "production",
+			UUID:     "production-uuid",
+			OwnerTag: "user-admin",
+		}, {
+			Name:     "sandbox",
+			UUID:     "sandbox-uuid",
+			OwnerTag: "user-bob",
+		}},
+	}
+	s.PatchValue(&getEnvironmentManagerAPI, func(envName string) (environmentManagerAPI, error) {
+		return s.mockAPI, nil
+	})
+}
+
+func newListEnvironmentsCommand() cmd.Command {
+	return envcmd.Wrap(&ListEnvironmentsCommand{})
+}
+
+func (s *ListEnvironmentsSuite) TestListEnvironments(c *gc.C) {
+	context, err := testing.RunCommand(c, newListEnvironmentsCommand())
+	c.Assert(err, gc.IsNil)
+	c.Assert(testing.Stdout(context), gc.Equals, ""+
+		"NAME        OWNER  UUID\n"+
+		"production  admin  production-uuid\n"+
+		"sandbox     bob    sandbox-uuid\n",
+	)
+	c.Assert(s.mockAPI.closed, gc.Equals, true)
+}
+
+func (s *ListEnvironmentsSuite) TestListEnvironmentsYAML(c *gc.C) {
+	context, err := testing.RunCommand(c, newListEnvironmentsCommand(), "--format", "yaml")
+	c.Assert(err, gc.IsNil)
+	var out []map[string]interface{}
+	err = goyaml.Unmarshal([]byte(testing.Stdout(context)), &out)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, []map[string]interface{}{{
+		"name":  "production",
+		"uuid":  "production-uuid",
+		"owner": "admin",
+	}, {
+		"name":  "sandbox",
+		"uuid":  "sandbox-uuid",
+		"owner": "bob",
+	}})
+}
+
+func (s *ListEnvironmentsSuite) TestListEnvironmentsError(c *gc.C) {
+	s.mockAPI.err = errors.New("permission denied")
+	_, err := testing.RunCommand(c, newListEnvironmentsCommand())
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *ListEnvironmentsSuite) TestInitRejectsArgs(c *gc.C) {
+	err := testing.InitCommand(newListEnvironmentsCommand(), []string{"foo"})
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["foo"\]`)
+}
@@ -93,6 +93,7 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 	r.Register(wrapEnvCommand(&AddMachineCommand{}))
 	r.Register(wrapEnvCommand(&DeployCommand{}))
 	r.Register(wrapEnvCommand(&AddRelationCommand{}))
+	r.Register(wrapEnvCommand(&OfferCommand{}))
 	r.Register(wrapEnvCommand(&AddUnitCommand{}))
 
 	// Destruction commands.
@@ -106,6 +107,7 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 	r.Register(wrapEnvCommand(&StatusCommand{}))
 	r.Register(&SwitchCommand{})
 	r.Register(wrapEnvCommand(&EndpointCommand{}))
+	r.Register(wrapEnvCommand(&AuditCommand{}))
 
 	// Error resolution and debugging commands.
 	r.Register(wrapEnvCommand(&RunCommand{}))
@@ -144,8 +146,26 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 	// Manage users and access
 	r.Register(NewUserCommand())
 
+	// Manage state server backups.
+	r.Register(NewBackupsCommand())
+
 	// Manage state server availability.
 	r.Register(wrapEnvCommand(&EnsureAvailabilityCommand{}))
+
+	// Manage the certificates securing the API.
+	r.Register(wrapEnvCommand(&RotateCertificatesCommand{}))
+
+	// Manage the spaces and subnets of an environment.
+	r.Register(NewSpaceCommand())
+	r.Register(NewSubnetCommand())
+
+	// Manage the environments hosted by a state server.
+	r.Register(wrapEnvCommand(&CreateEnvironmentCommand{}))
+	r.Register(wrapEnvCommand(&ListEnvironmentsCommand{}))
+
+	// Share environments between users.
+	r.Register(wrapEnvCommand(&GetEnvironmentInfoCommand{}))
+	r.Register(&ImportEnvironmentCommand{})
 }
 
 // envCmdWrapper is a struct that wraps an environment command and lets us handle
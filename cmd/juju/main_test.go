@@ -191,9 +191,12 @@ var commandNames = []string{
 	"add-relation",
 	"add-unit",
 	"api-endpoints",
+	"audit",
 	"authorised-keys", // alias for authorized-keys
 	"authorized-keys",
+	"backups",
 	"bootstrap",
+	"create-environment",
 	"debug-hooks",
 	"debug-log",
 	"deploy",
@@ -210,9 +213,13 @@ var commandNames = []string{
 	"get-constraints",
 	"get-env", // alias for get-environment
 	"get-environment",
+	"get-environment-info",
 	"help",
 	"help-tool",
+	"import-environment",
 	"init",
+	"list-environments",
+	"offer",
 	"publish",
 	"remove-machine",  // alias for destroy-machine
 	"remove-relation", // alias for destroy-relation
@@ -220,15 +227,18 @@ var commandNames = []string{
 	"remove-unit",     // alias for destroy-unit
 	"resolved",
 	"retry-provisioning",
+	"rotate-certificates",
 	"run",
 	"scp",
 	"set",
 	"set-constraints",
 	"set-env", // alias for set-environment
 	"set-environment",
+	"space",
 	"ssh",
 	"stat", // alias for status
 	"status",
+	"subnet",
 	"switch",
 	"sync-tools",
 	"terminate-machine", // alias for destroy-machine
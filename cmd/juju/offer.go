@@ -0,0 +1,72 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/juju/cmd"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/juju"
+)
+
+// OfferCommand offers a service for relation to services in other
+// environments hosted by the same state server.
+type OfferCommand struct {
+	envcmd.EnvCommandBase
+	ServiceName string
+	Endpoints   []string
+}
+
+var jujuOfferHelp = `
+Offers the endpoints of a service for relation to services in the other
+environments hosted by the same state server. If no relation names are
+given, all the service's endpoints that can take part in a relation
+between environments are offered; peer and container-scoped relations
+cannot be offered.
+
+The offer's URL, made of the environment and service names, is printed.
+It may be given in place of a service name to add-relation in any
+environment hosted by the state server.
+
+Examples:
+
+   juju offer mysql
+   sandbox.mysql
+
+   juju add-relation -e production wordpress sandbox.mysql
+`
+
+func (c *OfferCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "offer",
+		Args:    "<service> [<relation name> ...]",
+		Purpose: "offer a service to other environments",
+		Doc:     jujuOfferHelp,
+	}
+}
+
+func (c *OfferCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no service name specified")
+	}
+	c.ServiceName, c.Endpoints = args[0], args[1:]
+	return nil
+}
+
+func (c *OfferCommand) Run(ctx *cmd.Context) error {
+	client, err := juju.NewAPIClientFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	url, err := client.ServiceOffer(c.ServiceName, c.Endpoints...)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(ctx.Stdout, url)
+	return nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/cmd/envcmd"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/testing"
+)
+
+type OfferSuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = gc.Suite(&OfferSuite{})
+
+func runOffer(c *gc.C, args ...string) (string, error) {
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&OfferCommand{}), args...)
+	if err != nil {
+		return "", err
+	}
+	return testing.Stdout(ctx), nil
+}
+
+func (s *OfferSuite) TestInit(c *gc.C) {
+	_, err := runOffer(c)
+	c.Assert(err, gc.ErrorMatches, "no service name specified")
+}
+
+func (s *OfferSuite) TestOffer(c *gc.C) {
+	s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	out, err := runOffer(c, "mysql", "server")
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.Equals, "dummyenv.mysql\n")
+	offer, err := s.State.Offer("dummyenv.mysql")
+	c.Assert(err, gc.IsNil)
+	c.Assert(offer.ServiceName(), gc.Equals, "mysql")
+
+	_, err = runOffer(c, "mysql")
+	c.Assert(err, gc.ErrorMatches, `cannot offer service "mysql": offer "dummyenv.mysql" already exists`)
+	_, err = runOffer(c, "wordpress")
+	c.Assert(err, gc.ErrorMatches, `cannot offer service "wordpress": service "wordpress" not found`)
+}
@@ -0,0 +1,137 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/cert"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/environs/configstore"
+	"github.com/juju/juju/juju"
+	"github.com/juju/juju/state/api/params"
+)
+
+const rotateCertificatesDoc = `
+Certificates are rotated in two steps, so that agents keep
+trusting the API servers throughout.
+
+First, run
+
+    juju rotate-certificates --new-ca
+
+to generate a new CA certificate. The agents are told to trust
+it alongside the CAs they already trust. The new CA's private key
+is saved in the environment's connection details.
+
+Connected agents learn about the new CA straight away. Once all
+agents have done so, run
+
+    juju rotate-certificates
+
+to generate a new server certificate signed by the new CA. The
+API servers start using it immediately. The database server on
+each state server is restarted to use it, and the old CA stays
+trusted until all of them have restarted.
+
+Running "juju rotate-certificates" alone replaces the server
+certificate using the current CA, for example when the server
+certificate is about to expire.
+
+The CA private key is kept in the environment's .jenv file, which
+is readable only by its owner. Anyone holding the key can issue
+certificates that the agents trust, so do not share the file;
+use "juju get-environment-info" to give others the connection
+details without it. Only environment administrators may rotate
+certificates.
+`
+
+// RotateCertificatesCommand replaces the certificates
+// used to secure the environment's API.
+type RotateCertificatesCommand struct {
+	envcmd.EnvCommandBase
+	NewCA bool
+}
+
+func (c *RotateCertificatesCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "rotate-certificates",
+		Purpose: "replace the certificates used to secure the API",
+		Doc:     rotateCertificatesDoc,
+	}
+}
+
+func (c *RotateCertificatesCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.BoolVar(&c.NewCA, "new-ca", false, "generate a new CA certificate instead of a new server certificate")
+}
+
+func (c *RotateCertificatesCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+// certificatesAPI holds the client API methods
+// used by the rotate-certificates command.
+type certificatesAPI interface {
+	RotateCertificates(newCA bool, caKey string) (params.RotateCertificatesResult, error)
+	Close() error
+}
+
+var getCertificatesAPI = func(envName string) (certificatesAPI, error) {
+	return juju.NewAPIClientFromName(envName)
+}
+
+func (c *RotateCertificatesCommand) Run(ctx *cmd.Context) error {
+	store, err := configstore.Default()
+	if err != nil {
+		return err
+	}
+	info, err := store.ReadInfo(c.EnvName)
+	if err != nil {
+		return errors.Annotatef(err, "cannot read connection details of environment %q", c.EnvName)
+	}
+	bootstrapConfig := info.BootstrapConfig()
+	var caKey string
+	if !c.NewCA {
+		caKey, _ = bootstrapConfig["ca-private-key"].(string)
+		if caKey == "" {
+			return fmt.Errorf("CA private key not found in connection details of environment %q", c.EnvName)
+		}
+	}
+	client, err := getCertificatesAPI(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	result, err := client.RotateCertificates(c.NewCA, caKey)
+	if err != nil {
+		return err
+	}
+	endpoint := info.APIEndpoint()
+	endpoint.CACert = result.CACert
+	info.SetAPIEndpoint(endpoint)
+	if c.NewCA {
+		// The bootstrap configuration holds a single CA
+		// certificate, matching the CA private key.
+		caCerts, err := cert.ParseCerts(result.CACert)
+		if err != nil {
+			return errors.Annotate(err, "cannot parse CA certificates")
+		}
+		bootstrapConfig["ca-cert"] = cert.EncodeCert(caCerts[0])
+		bootstrapConfig["ca-private-key"] = result.CAPrivateKey
+		info.SetBootstrapConfig(bootstrapConfig)
+	}
+	if err := info.Write(); err != nil {
+		return errors.Annotatef(err, "certificates rotated, but connection details of environment %q could not be saved", c.EnvName)
+	}
+	if c.NewCA {
+		ctx.Infof("new CA certificate generated; run rotate-certificates again once agents trust it")
+	} else {
+		ctx.Infof("new server certificate generated")
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"time"
+
+	"github.com/juju/cmd"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/cert"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/environs/configstore"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/testing"
+)
+
+type RotateCertificatesSuite struct {
+	testing.FakeJujuHomeSuite
+	mockAPI     *mockCertificatesAPI
+	store       configstore.Storage
+	otherCACert string
+	otherCAKey  string
+}
+
+var _ = gc.Suite(&RotateCertificatesSuite{})
+
+func (s *RotateCertificatesSuite) SetUpSuite(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpSuite(c)
+	var err error
+	s.otherCACert, s.otherCAKey, err = cert.NewCA("other", time.Now().AddDate(10, 0, 0))
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *RotateCertificatesSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.mockAPI = &mockCertificatesAPI{}
+	s.PatchValue(&getCertificatesAPI, func(envName string) (certificatesAPI, error) {
+		s.mockAPI.envName = envName
+		return s.mockAPI, nil
+	})
+	store, err := configstore.Default()
+	c.Assert(err, gc.IsNil)
+	info, err := store.CreateInfo(testing.SampleEnvName)
+	c.Assert(err, gc.IsNil)
+	info.SetAPIEndpoint(configstore.APIEndpoint{
+		Addresses:   []string{"10.0.0.1:17070"},
+		CACert:      testing.CACert,
+		EnvironUUID: "env-uuid",
+	})
+	info.SetBootstrapConfig(map[string]interface{}{
+		"name":           testing.SampleEnvName,
+		"ca-cert":        testing.CACert,
+		"ca-private-key": testing.CAKey,
+	})
+	err = info.Write()
+	c.Assert(err, gc.IsNil)
+	s.store = store
+}
+
+func newRotateCertificatesCommand() cmd.Command {
+	return envcmd.Wrap(&RotateCertificatesCommand{})
+}
+
+func (s *RotateCertificatesSuite) TestInit(c *gc.C) {
+	err := testing.InitCommand(newRotateCertificatesCommand(), []string{"foo"})
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["foo"\]`)
+}
+
+func (s *RotateCertificatesSuite) TestRotateServerCertificate(c *gc.C) {
+	s.mockAPI.result = params.RotateCertificatesResult{CACert: s.otherCACert}
+	context, err := testing.RunCommand(c, newRotateCertificatesCommand())
+	c.Assert(err, gc.IsNil)
+	c.Assert(testing.Stderr(context), gc.Equals, "new server certificate generated\n")
+	c.Assert(s.mockAPI.envName, gc.Equals, testing.SampleEnvName)
+	c.Assert(s.mockAPI.newCA, gc.Equals, false)
+	c.Assert(s.mockAPI.caKey, gc.Equals, testing.CAKey)
+	c.Assert(s.mockAPI.closed, gc.Equals, true)
+
+	info, err := s.store.ReadInfo(testing.SampleEnvName)
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.APIEndpoint().CACert, gc.Equals, s.otherCACert)
+	c.Assert(info.BootstrapConfig()["ca-private-key"], gc.Equals, testing.CAKey)
+}
+
+func (s *RotateCertificatesSuite) TestRotateCA(c *gc.C) {
+	s.mockAPI.result = params.RotateCertificatesResult{
+		CACert:       s.otherCACert + testing.CACert,
+		CAPrivateKey: s.otherCAKey,
+	}
+	context, err := testing.RunCommand(c, newRotateCertificatesCommand(), "--new-ca")
+	c.Assert(err, gc.IsNil)
+	c.Assert(testing.Stderr(context), gc.Equals, "new CA certificate generated; run rotate-certificates again once agents trust it\n")
+	c.Assert(s.mockAPI.newCA, gc.Equals, true)
+	c.Assert(s.mockAPI.caKey, gc.Equals, "")
+
+	// The client trusts both CAs; the bootstrap
+	// configuration holds only the new one.
+	info, err := s.store.ReadInfo(testing.SampleEnvName)
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.APIEndpoint().CACert, gc.Equals, s.otherCACert+testing.CACert)
+	c.Assert(info.BootstrapConfig()["ca-cert"], gc.Equals, s.otherCACert)
+	c.Assert(info.BootstrapConfig()["ca-private-key"], gc.Equals, s.otherCAKey)
+}
+
+func (s *RotateCertificatesSuite) TestRotateWithoutCAKey(c *gc.C) {
+	info, err := s.store.ReadInfo(testing.SampleEnvName)
+	c.Assert(err, gc.IsNil)
+	info.SetBootstrapConfig(map[string]interface{}{"name": testing.SampleEnvName})
+	err = info.Write()
+	c.Assert(err, gc.IsNil)
+
+	_, err = testing.RunCommand(c, newRotateCertificatesCommand())
+	c.Assert(err, gc.ErrorMatches, `CA private key not found in connection details of environment "erewhemos"`)
+	c.Assert(s.mockAPI.envName, gc.Equals, "")
+}
+
+func (s *RotateCertificatesSuite) TestRotateError(c *gc.C) {
+	s.mockAPI.err = &params.Error{Message: "permission denied", Code: params.CodeUnauthorized}
+	_, err := testing.RunCommand(c, newRotateCertificatesCommand())
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	c.Assert(s.mockAPI.closed, gc.Equals, true)
+
+	info, err := s.store.ReadInfo(testing.SampleEnvName)
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.APIEndpoint().CACert, gc.Equals, testing.CACert)
+}
+
+type mockCertificatesAPI struct {
+	envName string
+	newCA   bool
+	caKey   string
+	result  params.RotateCertificatesResult
+	err     error
+	closed  bool
+}
+
+func (m *mockCertificatesAPI) RotateCertificates(newCA bool, caKey string) (params.RotateCertificatesResult, error) {
+	m.newCA = newCA
+	m.caKey = caKey
+	return m.result, m.err
+}
+
+func (m *mockCertificatesAPI) Close() error {
+	m.closed = true
+	return nil
+}
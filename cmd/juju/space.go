@@ -0,0 +1,52 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"github.com/juju/cmd"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/juju"
+	"github.com/juju/juju/state/api/params"
+)
+
+type SpaceCommand struct {
+	*cmd.SuperCommand
+}
+
+const spaceCommandDoc = `
+"juju space" is used to manage the spaces of the Juju environment.
+
+A space is a set of subnets, possibly spread across several availability
+zones, which are treated alike for the purposes of connectivity. Machines
+can be required to have an address in a space with the "spaces"
+constraint, and a service's endpoints can be bound to spaces with
+"juju deploy --bind".
+`
+
+const spaceCommandPurpose = "manage network spaces"
+
+func NewSpaceCommand() cmd.Command {
+	spacecmd := &SpaceCommand{
+		SuperCommand: cmd.NewSuperCommand(cmd.SuperCommandParams{
+			Name:        "space",
+			Doc:         spaceCommandDoc,
+			UsagePrefix: "juju",
+			Purpose:     spaceCommandPurpose,
+		}),
+	}
+	spacecmd.Register(envcmd.Wrap(&SpaceCreateCommand{}))
+	spacecmd.Register(envcmd.Wrap(&SpaceListCommand{}))
+	return spacecmd
+}
+
+type spacesAPI interface {
+	CreateSpace(name string, subnetCIDRs []string) error
+	ListSpaces() ([]params.Space, error)
+	Close() error
+}
+
+var getSpacesAPI = func(envName string) (spacesAPI, error) {
+	return juju.NewSpacesClient(envName)
+}
@@ -0,0 +1,78 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/juju/cmd"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/network"
+)
+
+const spaceCreateCommandDoc = `
+Create a new space, made of the given subnets. The subnets must have been
+added with "juju subnet add", and must not be part of any other space.
+A space can be created with no subnets; subnets can then be added to it
+with "juju subnet add".
+
+Space names are made of lower case letters, digits and hyphens, and
+cannot start or end with a hyphen.
+
+Examples:
+  juju space create db 10.0.1.0/24 10.0.2.0/24
+  juju space create dmz
+`
+
+// SpaceCreateCommand creates a new space.
+type SpaceCreateCommand struct {
+	envcmd.EnvCommandBase
+	Name  string
+	CIDRs []string
+}
+
+func (c *SpaceCreateCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "create",
+		Args:    "<name> [<CIDR> ...]",
+		Purpose: "create a new space",
+		Doc:     spaceCreateCommandDoc,
+	}
+}
+
+func (c *SpaceCreateCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no space name specified")
+	}
+	c.Name, c.CIDRs = args[0], args[1:]
+	if !network.IsValidSpaceName(c.Name) {
+		return fmt.Errorf("%q is not a valid space name", c.Name)
+	}
+	for _, cidr := range c.CIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("%q is not a valid CIDR", cidr)
+		}
+	}
+	return nil
+}
+
+func (c *SpaceCreateCommand) Run(ctx *cmd.Context) error {
+	client, err := getSpacesAPI(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if err := client.CreateSpace(c.Name, c.CIDRs); err != nil {
+		return err
+	}
+	if len(c.CIDRs) == 0 {
+		fmt.Fprintf(ctx.Stdout, "created space %q with no subnets\n", c.Name)
+	} else {
+		fmt.Fprintf(ctx.Stdout, "created space %q with subnets %s\n", c.Name, strings.Join(c.CIDRs, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/juju/cmd"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+const spaceListCommandDoc = `
+List the spaces of the environment, along with the CIDRs of their subnets.
+`
+
+// SpaceListCommand lists the spaces of the environment.
+type SpaceListCommand struct {
+	envcmd.EnvCommandBase
+	out cmd.Output
+}
+
+func (c *SpaceListCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "list",
+		Purpose: "list the spaces of the environment",
+		Doc:     spaceListCommandDoc,
+	}
+}
+
+func (c *SpaceListCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "tabular", map[string]cmd.Formatter{
+		"tabular": formatSpacesTabular,
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+	})
+}
+
+func (c *SpaceListCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+// spaceInfo is the serialisation format of a space.
+type spaceInfo struct {
+	Name    string   `json:"name" yaml:"name"`
+	Subnets []string `json:"subnets" yaml:"subnets"`
+}
+
+func (c *SpaceListCommand) Run(ctx *cmd.Context) error {
+	client, err := getSpacesAPI(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	spaces, err := client.ListSpaces()
+	if err != nil {
+		return err
+	}
+	out := make([]spaceInfo, len(spaces))
+	for i, space := range spaces {
+		out[i] = spaceInfo{
+			Name:    space.Name,
+			Subnets: make([]string, len(space.Subnets)),
+		}
+		for j, subnet := range space.Subnets {
+			out[i].Subnets[j] = subnet.CIDR
+		}
+	}
+	return c.out.Write(ctx, out)
+}
+
+// formatSpacesTabular formats spaces as a table.
+func formatSpacesTabular(value interface{}) ([]byte, error) {
+	spaces, ok := value.([]spaceInfo)
+	if !ok {
+		return nil, fmt.Errorf("expected value of type %T, got %T", spaces, value)
+	}
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 1, 2, ' ', 0)
+	fmt.Fprintln(tw, "SPACE\tSUBNETS")
+	for _, space := range spaces {
+		fmt.Fprintf(tw, "%s\t%s\n", space.Name, orDash(strings.Join(space.Subnets, " ")))
+	}
+	tw.Flush()
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// orDash returns s, or "-" if s is empty, so that empty cells are
+// visible in tables.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,144 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/juju/cmd"
+	gc "launchpad.net/gocheck"
+	"launchpad.net/goyaml"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/testing"
+)
+
+type SpaceCommandSuite struct {
+	testing.FakeJujuHomeSuite
+	mockAPI *mockSpacesAPI
+}
+
+var _ = gc.Suite(&SpaceCommandSuite{})
+
+func (s *SpaceCommandSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.mockAPI = &mockSpacesAPI{}
+	s.PatchValue(&getSpacesAPI, func(envName string) (spacesAPI, error) {
+		return s.mockAPI, nil
+	})
+}
+
+func (s *SpaceCommandSuite) TestHelp(c *gc.C) {
+	ctx, err := testing.RunCommand(c, NewSpaceCommand(), "--help")
+	c.Assert(err, gc.IsNil)
+	c.Assert(testing.Stdout(ctx), gc.Matches,
+		"(?s)usage: space <command> .+"+
+			spaceCommandPurpose+".+")
+
+	var namesFound []string
+	commandHelp := strings.SplitAfter(testing.Stdout(ctx), "commands:")[1]
+	commandHelp = strings.TrimSpace(commandHelp)
+	for _, line := range strings.Split(commandHelp, "\n") {
+		namesFound = append(namesFound, strings.TrimSpace(strings.Split(line, " - ")[0]))
+	}
+	c.Assert(namesFound, gc.DeepEquals, []string{"create", "help", "list"})
+}
+
+func newSpaceCreateCommand() cmd.Command {
+	return envcmd.Wrap(&SpaceCreateCommand{})
+}
+
+func newSpaceListCommand() cmd.Command {
+	return envcmd.Wrap(&SpaceListCommand{})
+}
+
+func (s *SpaceCommandSuite) TestCreateInitErrors(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{
+		{nil, "no space name specified"},
+		{[]string{"DB"}, `"DB" is not a valid space name`},
+		{[]string{"db", "10.0.0.0/24", "10.0.1.0"}, `"10.0.1.0" is not a valid CIDR`},
+	} {
+		c.Logf("test %d: %v", i, test.args)
+		_, err := testing.RunCommand(c, newSpaceCreateCommand(), test.args...)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *SpaceCommandSuite) TestCreate(c *gc.C) {
+	ctx, err := testing.RunCommand(c, newSpaceCreateCommand(), "db", "10.0.0.0/24", "10.0.1.0/24")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mockAPI.created, gc.Equals, "db")
+	c.Assert(s.mockAPI.subnetCIDRs, gc.DeepEquals, []string{"10.0.0.0/24", "10.0.1.0/24"})
+	c.Assert(testing.Stdout(ctx), gc.Equals, `created space "db" with subnets 10.0.0.0/24, 10.0.1.0/24`+"\n")
+	c.Assert(s.mockAPI.closed, gc.Equals, true)
+
+	ctx, err = testing.RunCommand(c, newSpaceCreateCommand(), "dmz")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mockAPI.created, gc.Equals, "dmz")
+	c.Assert(testing.Stdout(ctx), gc.Equals, `created space "dmz" with no subnets`+"\n")
+}
+
+func (s *SpaceCommandSuite) TestCreateError(c *gc.C) {
+	s.mockAPI.err = errors.New(`cannot add space "db": space "db" already exists`)
+	_, err := testing.RunCommand(c, newSpaceCreateCommand(), "db")
+	c.Assert(err, gc.ErrorMatches, `cannot add space "db": space "db" already exists`)
+}
+
+func (s *SpaceCommandSuite) TestList(c *gc.C) {
+	s.mockAPI.spaces = []params.Space{{
+		Name:    "db",
+		Subnets: []params.Subnet{{CIDR: "10.0.0.0/24"}, {CIDR: "10.0.1.0/24"}},
+	}, {
+		Name: "dmz",
+	}}
+	ctx, err := testing.RunCommand(c, newSpaceListCommand())
+	c.Assert(err, gc.IsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, ""+
+		"SPACE  SUBNETS\n"+
+		"db     10.0.0.0/24 10.0.1.0/24\n"+
+		"dmz    -\n",
+	)
+	c.Assert(s.mockAPI.closed, gc.Equals, true)
+
+	ctx, err = testing.RunCommand(c, newSpaceListCommand(), "--format", "yaml")
+	c.Assert(err, gc.IsNil)
+	var out []map[string]interface{}
+	err = goyaml.Unmarshal([]byte(testing.Stdout(ctx)), &out)
+	c.Assert(err, gc.IsNil)
+	c.Assert(out, gc.DeepEquals, []map[string]interface{}{{
+		"name":    "db",
+		"subnets": []interface{}{"10.0.0.0/24", "10.0.1.0/24"},
+	}, {
+		"name":    "dmz",
+		"subnets": []interface{}{},
+	}})
+}
+
+type mockSpacesAPI struct {
+	created     string
+	subnetCIDRs []string
+	spaces      []params.Space
+	err         error
+	closed      bool
+}
+
+func (m *mockSpacesAPI) CreateSpace(name string, subnetCIDRs []string) error {
+	m.created = name
+	m.subnetCIDRs = subnetCIDRs
+	return m.err
+}
+
+func (m *mockSpacesAPI) ListSpaces() ([]params.Space, error) {
+	return m.spaces, m.err
+}
+
+func (m *mockSpacesAPI) Close() error {
+	m.closed = true
+	return nil
+}
@@ -6,6 +6,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/juju/cmd"
 	"launchpad.net/gnuflag"
@@ -23,6 +24,7 @@ type StatusCommand struct {
 	envcmd.EnvCommandBase
 	out      cmd.Output
 	patterns []string
+	color    bool
 }
 
 var statusDoc = `
@@ -37,6 +39,11 @@ will be displayed.
 Wildcards ('*') may be specified in service/unit names to match any sequence
 of characters. For example, 'nova-*' will match any service whose name begins
 with 'nova-': 'nova-compute', 'nova-volume', etc.
+
+The tabular format lists machines, services and units in aligned columns,
+and the summary format gives the numbers of machines and units in each
+state, of machines running each series and of units with each port open.
+With --color, those formats highlight agents in the error or down states.
 `
 
 func (c *StatusCommand) Info() *cmd.Info {
@@ -51,9 +58,20 @@ func (c *StatusCommand) Info() *cmd.Info {
 
 func (c *StatusCommand) SetFlags(f *gnuflag.FlagSet) {
 	c.out.AddFlags(f, "yaml", map[string]cmd.Formatter{
-		"yaml": cmd.FormatYaml,
-		"json": cmd.FormatJson,
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+		"tabular": c.formatTabular,
+		"summary": c.formatSummary,
 	})
+	f.BoolVar(&c.color, "color", false, "highlight agents in error or down states in tabular and summary formats")
+}
+
+func (c *StatusCommand) formatTabular(value interface{}) ([]byte, error) {
+	return statusFormatter{color: c.color}.formatTabular(value)
+}
+
+func (c *StatusCommand) formatSummary(value interface{}) ([]byte, error) {
+	return statusFormatter{color: c.color}.formatSummary(value)
 }
 
 func (c *StatusCommand) Init(args []string) error {
@@ -90,10 +108,16 @@ func (c *StatusCommand) Run(ctx *cmd.Context) error {
 }
 
 type formattedStatus struct {
-	Environment string                   `json:"environment"`
-	Machines    map[string]machineStatus `json:"machines"`
-	Services    map[string]serviceStatus `json:"services"`
-	Networks    map[string]networkStatus `json:"networks,omitempty" yaml:",omitempty"`
+	Environment  string                   `json:"environment"`
+	Machines     map[string]machineStatus `json:"machines"`
+	Services     map[string]serviceStatus `json:"services"`
+	Networks     map[string]networkStatus `json:"networks,omitempty" yaml:",omitempty"`
+	Certificates *certificatesStatus      `json:"certificates,omitempty" yaml:",omitempty"`
+}
+
+type certificatesStatus struct {
+	CAExpiry     []string `json:"ca-expiry" yaml:"ca-expiry"`
+	ServerExpiry string   `json:"server-expiry" yaml:"server-expiry"`
 }
 
 type errorStatus struct {
@@ -143,6 +167,7 @@ type serviceStatus struct {
 	Charm         string                `json:"charm" yaml:"charm"`
 	CanUpgradeTo  string                `json:"can-upgrade-to,omitempty" yaml:"can-upgrade-to,omitempty"`
 	Exposed       bool                  `json:"exposed" yaml:"exposed"`
+	ExposedTo     []string              `json:"exposed-to,omitempty" yaml:"exposed-to,omitempty"`
 	Life          string                `json:"life,omitempty" yaml:"life,omitempty"`
 	Relations     map[string][]string   `json:"relations,omitempty" yaml:"relations,omitempty"`
 	Networks      map[string][]string   `json:"networks,omitempty" yaml:"networks,omitempty"`
@@ -173,14 +198,24 @@ type unitStatus struct {
 	Charm          string                `json:"upgrading-from,omitempty" yaml:"upgrading-from,omitempty"`
 	AgentState     params.Status         `json:"agent-state,omitempty" yaml:"agent-state,omitempty"`
 	AgentStateInfo string                `json:"agent-state-info,omitempty" yaml:"agent-state-info,omitempty"`
+	AgentActivity  params.AgentActivity  `json:"agent-activity,omitempty" yaml:"agent-activity,omitempty"`
+	ActivityHook   string                `json:"agent-activity-hook,omitempty" yaml:"agent-activity-hook,omitempty"`
 	AgentVersion   string                `json:"agent-version,omitempty" yaml:"agent-version,omitempty"`
 	Life           string                `json:"life,omitempty" yaml:"life,omitempty"`
+	WorkloadStatus *workloadStatus       `json:"workload-status,omitempty" yaml:"workload-status,omitempty"`
 	Machine        string                `json:"machine,omitempty" yaml:"machine,omitempty"`
 	OpenedPorts    []string              `json:"open-ports,omitempty" yaml:"open-ports,omitempty"`
 	PublicAddress  string                `json:"public-address,omitempty" yaml:"public-address,omitempty"`
 	Subordinates   map[string]unitStatus `json:"subordinates,omitempty" yaml:"subordinates,omitempty"`
 }
 
+// workloadStatus holds the status of a unit's workload, as reported
+// by its charm.
+type workloadStatus struct {
+	Current params.WorkloadStatus `json:"current" yaml:"current"`
+	Message string                `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
 type unitStatusNoMarshal unitStatus
 
 func (s unitStatus) MarshalJSON() ([]byte, error) {
@@ -244,6 +279,19 @@ func formatStatus(status *api.Status) formattedStatus {
 		}
 		out.Networks[k] = formatNetwork(n)
 	}
+	if status.Certificates != nil {
+		out.Certificates = formatCertificates(*status.Certificates)
+	}
+	return out
+}
+
+func formatCertificates(certs api.CertificatesStatus) *certificatesStatus {
+	out := &certificatesStatus{
+		ServerExpiry: certs.ServerExpiry.UTC().Format(time.RFC3339),
+	}
+	for _, expiry := range certs.CAExpiry {
+		out.CAExpiry = append(out.CAExpiry, expiry.UTC().Format(time.RFC3339))
+	}
 	return out
 }
 
@@ -295,6 +343,7 @@ func formatService(service api.ServiceStatus) serviceStatus {
 		Err:           service.Err,
 		Charm:         service.Charm,
 		Exposed:       service.Exposed,
+		ExposedTo:     service.ExposedCIDRs,
 		Life:          service.Life,
 		Relations:     service.Relations,
 		Networks:      make(map[string][]string),
@@ -319,6 +368,8 @@ func formatUnit(unit api.UnitStatus) unitStatus {
 		Err:            unit.Err,
 		AgentState:     unit.AgentState,
 		AgentStateInfo: unit.AgentStateInfo,
+		AgentActivity:  unit.AgentActivity,
+		ActivityHook:   unit.ActivityHook,
 		AgentVersion:   unit.AgentVersion,
 		Life:           unit.Life,
 		Machine:        unit.Machine,
@@ -327,6 +378,15 @@ func formatUnit(unit api.UnitStatus) unitStatus {
 		Charm:          unit.Charm,
 		Subordinates:   make(map[string]unitStatus),
 	}
+	// The workload status is only shown once the charm has
+	// reported it, to avoid cluttering the output for charms
+	// that do not.
+	if unit.WorkloadStatus != "" && unit.WorkloadStatus != params.WorkloadUnknown {
+		out.WorkloadStatus = &workloadStatus{
+			Current: unit.WorkloadStatus,
+			Message: unit.WorkloadStatusInfo,
+		}
+	}
 	for k, m := range unit.Subordinates {
 		out.Subordinates[k] = formatUnit(m)
 	}
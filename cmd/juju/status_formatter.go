@@ -0,0 +1,302 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/juju/juju/state/api/params"
+)
+
+// The escape sequences used to highlight agents
+// in an error or down state when --color is given.
+const (
+	colorError = "\x1b[31m"
+	colorReset = "\x1b[0m"
+)
+
+// statusFormatter formats status in the "tabular" and "summary"
+// formats, optionally highlighting agents that need attention.
+type statusFormatter struct {
+	color bool
+}
+
+func asFormattedStatus(value interface{}) (formattedStatus, error) {
+	status, ok := value.(formattedStatus)
+	if !ok {
+		return formattedStatus{}, fmt.Errorf("expected value of type %T, got %T", status, value)
+	}
+	return status, nil
+}
+
+// agentState returns the state shown for an entity's agent.
+func agentState(err error, state params.Status) string {
+	switch {
+	case err != nil:
+		return string(params.StatusError)
+	case state == "":
+		return "unknown"
+	}
+	return string(state)
+}
+
+// highlight returns the given state, highlighted if
+// color is enabled and the state needs attention.
+func (f statusFormatter) highlight(state string) string {
+	if f.color && (state == string(params.StatusError) || state == string(params.StatusDown)) {
+		return colorError + state + colorReset
+	}
+	return state
+}
+
+// statusTable holds rows of cells to be written in aligned columns.
+type statusTable struct {
+	rows [][]string
+	// stateColumn holds the index of the column
+	// holding agent states, which may be highlighted.
+	stateColumn int
+}
+
+func (t *statusTable) addRow(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// write writes the table to buf. Columns are padded according to the
+// width of their plain text, so highlighting does not break alignment.
+func (t *statusTable) write(buf *bytes.Buffer, f statusFormatter) {
+	var widths []int
+	for _, row := range t.rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+	for r, row := range t.rows {
+		var line bytes.Buffer
+		for i, cell := range row {
+			text := cell
+			if r > 0 && i == t.stateColumn {
+				text = f.highlight(cell)
+			}
+			line.WriteString(text)
+			if i < len(row)-1 {
+				line.WriteString(strings.Repeat(" ", widths[i]-len(cell)+2))
+			}
+		}
+		buf.WriteString(strings.TrimRight(line.String(), " "))
+		buf.WriteString("\n")
+	}
+}
+
+// formatTabular writes the status as separate, aligned tables
+// of machines, services and units.
+func (f statusFormatter) formatTabular(value interface{}) ([]byte, error) {
+	status, err := asFormattedStatus(value)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+
+	buf.WriteString("[Machines]\n")
+	machines := &statusTable{stateColumn: 1}
+	machines.addRow("ID", "STATE", "VERSION", "DNS", "INS-ID", "SERIES", "HARDWARE")
+	for _, m := range sortedMachines(status.Machines) {
+		machines.addRow(
+			m.Id,
+			agentState(m.Err, m.AgentState),
+			m.AgentVersion,
+			m.DNSName,
+			string(m.InstanceId),
+			m.Series,
+			m.Hardware,
+		)
+	}
+	machines.write(&buf, f)
+
+	buf.WriteString("\n[Services]\n")
+	services := &statusTable{stateColumn: -1}
+	services.addRow("NAME", "EXPOSED", "CHARM")
+	for _, name := range sortedServiceNames(status.Services) {
+		svc := status.Services[name]
+		services.addRow(name, strconv.FormatBool(svc.Exposed), svc.Charm)
+	}
+	services.write(&buf, f)
+
+	buf.WriteString("\n[Units]\n")
+	units := &statusTable{stateColumn: 1}
+	units.addRow("ID", "STATE", "VERSION", "MACHINE", "PORTS", "PUBLIC-ADDRESS", "WORKLOAD")
+	var addUnits func(units map[string]unitStatus, indent string)
+	addUnits = func(statuses map[string]unitStatus, indent string) {
+		for _, name := range sortedUnitNames(statuses) {
+			u := statuses[name]
+			workload := ""
+			if u.WorkloadStatus != nil {
+				workload = string(u.WorkloadStatus.Current)
+			}
+			units.addRow(
+				indent+name,
+				agentState(u.Err, u.AgentState),
+				u.AgentVersion,
+				u.Machine,
+				strings.Join(u.OpenedPorts, ","),
+				u.PublicAddress,
+				workload,
+			)
+			addUnits(u.Subordinates, indent+"  ")
+		}
+	}
+	for _, name := range sortedServiceNames(status.Services) {
+		addUnits(status.Services[name].Units, "")
+	}
+	units.write(&buf, f)
+	return buf.Bytes(), nil
+}
+
+// formatSummary writes the numbers of machines and units in each
+// state, of machines running each series, and of units with each
+// port open.
+func (f statusFormatter) formatSummary(value interface{}) ([]byte, error) {
+	status, err := asFormattedStatus(value)
+	if err != nil {
+		return nil, err
+	}
+	machineStates := make(map[string]int)
+	series := make(map[string]int)
+	for _, m := range sortedMachines(status.Machines) {
+		machineStates[agentState(m.Err, m.AgentState)]++
+		if m.Series != "" {
+			series[m.Series]++
+		}
+	}
+	unitStates := make(map[string]int)
+	ports := make(map[string]int)
+	var countUnits func(units map[string]unitStatus)
+	countUnits = func(units map[string]unitStatus) {
+		for _, u := range units {
+			unitStates[agentState(u.Err, u.AgentState)]++
+			for _, port := range u.OpenedPorts {
+				ports[port]++
+			}
+			countUnits(u.Subordinates)
+		}
+	}
+	exposed := 0
+	for _, svc := range status.Services {
+		if svc.Exposed {
+			exposed++
+		}
+		countUnits(svc.Units)
+	}
+
+	var buf bytes.Buffer
+	f.writeCounts(&buf, "Machines", machineStates, true)
+	f.writeCounts(&buf, "Units", unitStates, true)
+	f.writeCounts(&buf, "Services", map[string]int{
+		"exposed":     exposed,
+		"not exposed": len(status.Services) - exposed,
+	}, false)
+	f.writeCounts(&buf, "Series", series, false)
+	f.writeCounts(&buf, "Ports", ports, false)
+	return buf.Bytes(), nil
+}
+
+// writeCounts writes a section of the summary, holding the total
+// and the individual counts, omitting those that are zero.
+func (f statusFormatter) writeCounts(buf *bytes.Buffer, title string, counts map[string]int, states bool) {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	if buf.Len() > 0 {
+		buf.WriteString("\n")
+	}
+	table := &statusTable{stateColumn: -1}
+	if states {
+		table.stateColumn = 0
+	}
+	table.addRow(fmt.Sprintf("[%s]", title), strconv.Itoa(total))
+	var keys []string
+	for key, n := range counts {
+		if n > 0 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		table.addRow(key, strconv.Itoa(counts[key]))
+	}
+	table.write(buf, f)
+}
+
+// sortedMachines returns the given machines and their
+// containers, ordered by id.
+func sortedMachines(machines map[string]machineStatus) []machineStatus {
+	var result []machineStatus
+	for _, m := range machines {
+		result = append(result, m)
+		result = append(result, sortedMachines(m.Containers)...)
+	}
+	sort.Sort(machinesById(result))
+	return result
+}
+
+type machinesById []machineStatus
+
+func (m machinesById) Len() int      { return len(m) }
+func (m machinesById) Swap(i, j int) { m[i], m[j] = m[j], m[i] }
+func (m machinesById) Less(i, j int) bool {
+	return idLess(m[i].Id, m[j].Id)
+}
+
+// idLess reports whether the machine or unit id a sorts before b,
+// comparing numeric parts numerically: "2" sorts before "10", and
+// "mysql/2" before "mysql/10".
+func idLess(a, b string) bool {
+	aParts := strings.Split(a, "/")
+	bParts := strings.Split(b, "/")
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		if aParts[i] == bParts[i] {
+			continue
+		}
+		aNum, aErr := strconv.Atoi(aParts[i])
+		bNum, bErr := strconv.Atoi(bParts[i])
+		if aErr == nil && bErr == nil {
+			return aNum < bNum
+		}
+		return aParts[i] < bParts[i]
+	}
+	return len(aParts) < len(bParts)
+}
+
+func sortedServiceNames(services map[string]serviceStatus) []string {
+	var names []string
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedUnitNames(units map[string]unitStatus) []string {
+	var names []string
+	for name := range units {
+		names = append(names, name)
+	}
+	sort.Sort(idSlice(names))
+	return names
+}
+
+type idSlice []string
+
+func (s idSlice) Len() int           { return len(s) }
+func (s idSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s idSlice) Less(i, j int) bool { return idLess(s[i], s[j]) }
@@ -0,0 +1,164 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"errors"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/testing"
+)
+
+type StatusFormatterSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&StatusFormatterSuite{})
+
+var formatterStatus = formattedStatus{
+	Environment: "dummyenv",
+	Machines: map[string]machineStatus{
+		"0": {
+			Id:           "0",
+			AgentState:   params.StatusStarted,
+			AgentVersion: "1.20.1",
+			DNSName:      "dummyenv-0.dns",
+			InstanceId:   "dummyenv-0",
+			Series:       "trusty",
+			Hardware:     "arch=amd64",
+			Containers: map[string]machineStatus{
+				"0/lxc/0": {
+					Id:         "0/lxc/0",
+					AgentState: params.StatusPending,
+					Series:     "precise",
+				},
+			},
+		},
+		"10": {
+			Id:  "10",
+			Err: errors.New("boom"),
+		},
+		"2": {
+			Id:           "2",
+			AgentState:   params.StatusDown,
+			AgentVersion: "1.20.1",
+			InstanceId:   "dummyenv-2",
+			Series:       "trusty",
+		},
+	},
+	Services: map[string]serviceStatus{
+		"wordpress": {
+			Charm:   "cs:precise/wordpress-3",
+			Exposed: true,
+			Units: map[string]unitStatus{
+				"wordpress/10": {
+					AgentState: params.StatusError,
+					Machine:    "2",
+				},
+				"wordpress/2": {
+					AgentState:     params.StatusStarted,
+					AgentVersion:   "1.20.1",
+					Machine:        "0",
+					OpenedPorts:    []string{"80/tcp", "443/tcp"},
+					PublicAddress:  "dummyenv-0.dns",
+					WorkloadStatus: &workloadStatus{Current: params.WorkloadActive},
+					Subordinates: map[string]unitStatus{
+						"logging/0": {
+							AgentState: params.StatusStarted,
+						},
+					},
+				},
+			},
+		},
+		"logging": {
+			Charm: "cs:precise/logging-1",
+		},
+	},
+}
+
+func (s *StatusFormatterSuite) TestFormatTabular(c *gc.C) {
+	out, err := statusFormatter{}.formatTabular(formatterStatus)
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(out), gc.Equals, ""+
+		"[Machines]\n"+
+		"ID       STATE    VERSION  DNS             INS-ID      SERIES   HARDWARE\n"+
+		"0        started  1.20.1   dummyenv-0.dns  dummyenv-0  trusty   arch=amd64\n"+
+		"0/lxc/0  pending                                       precise\n"+
+		"2        down     1.20.1                   dummyenv-2  trusty\n"+
+		"10       error\n"+
+		"\n"+
+		"[Services]\n"+
+		"NAME       EXPOSED  CHARM\n"+
+		"logging    false    cs:precise/logging-1\n"+
+		"wordpress  true     cs:precise/wordpress-3\n"+
+		"\n"+
+		"[Units]\n"+
+		"ID            STATE    VERSION  MACHINE  PORTS           PUBLIC-ADDRESS  WORKLOAD\n"+
+		"wordpress/2   started  1.20.1   0        80/tcp,443/tcp  dummyenv-0.dns  active\n"+
+		"  logging/0   started\n"+
+		"wordpress/10  error             2\n",
+	)
+}
+
+func (s *StatusFormatterSuite) TestFormatSummary(c *gc.C) {
+	out, err := statusFormatter{}.formatSummary(formatterStatus)
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(out), gc.Equals, ""+
+		"[Machines]  4\n"+
+		"down        1\n"+
+		"error       1\n"+
+		"pending     1\n"+
+		"started     1\n"+
+		"\n"+
+		"[Units]  3\n"+
+		"error    1\n"+
+		"started  2\n"+
+		"\n"+
+		"[Services]   2\n"+
+		"exposed      1\n"+
+		"not exposed  1\n"+
+		"\n"+
+		"[Series]  3\n"+
+		"precise   1\n"+
+		"trusty    2\n"+
+		"\n"+
+		"[Ports]  2\n"+
+		"443/tcp  1\n"+
+		"80/tcp   1\n",
+	)
+}
+
+func (s *StatusFormatterSuite) TestColor(c *gc.C) {
+	status := formattedStatus{
+		Machines: map[string]machineStatus{
+			"0": {Id: "0", AgentState: params.StatusStarted},
+			"1": {Id: "1", AgentState: params.StatusDown},
+		},
+	}
+	out, err := statusFormatter{color: true}.formatTabular(status)
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(out), gc.Equals, ""+
+		"[Machines]\n"+
+		"ID  STATE    VERSION  DNS  INS-ID  SERIES  HARDWARE\n"+
+		"0   started\n"+
+		"1   \x1b[31mdown\x1b[0m\n"+
+		"\n"+
+		"[Services]\n"+
+		"NAME  EXPOSED  CHARM\n"+
+		"\n"+
+		"[Units]\n"+
+		"ID  STATE  VERSION  MACHINE  PORTS  PUBLIC-ADDRESS  WORKLOAD\n",
+	)
+
+	out, err = statusFormatter{color: true}.formatSummary(status)
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(out), gc.Matches, "(?s)\\[Machines\\]  2\n\x1b\\[31mdown\x1b\\[0m        1\nstarted     1\n.*")
+}
+
+func (s *StatusFormatterSuite) TestFormatBadValue(c *gc.C) {
+	_, err := statusFormatter{}.formatTabular("foo")
+	c.Assert(err, gc.ErrorMatches, "expected value of type main.formattedStatus, got string")
+}
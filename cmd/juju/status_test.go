@@ -18,6 +18,7 @@ import (
 	gc "launchpad.net/gocheck"
 	"launchpad.net/goyaml"
 
+	"github.com/juju/juju/cert"
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/instance"
@@ -1569,6 +1570,162 @@ var statusTests = []testCase{
 				},
 			},
 		},
+	), test(
+		"unit with workload status set by its charm",
+		addMachine{machineId: "0", job: state.JobManageEnviron},
+		setAddresses{"0", []network.Address{network.NewAddress("dummyenv-0.dns", network.ScopeUnknown)}},
+		startAliveMachine{"0"},
+		setMachineStatus{"0", params.StatusStarted, ""},
+		addMachine{machineId: "1", job: state.JobHostUnits},
+		setAddresses{"1", []network.Address{network.NewAddress("dummyenv-1.dns", network.ScopeUnknown)}},
+		startAliveMachine{"1"},
+		setMachineStatus{"1", params.StatusStarted, ""},
+		addCharm{"mysql"},
+		addService{name: "mysql", charm: "mysql"},
+		setServiceExposed{"mysql", true},
+		addAliveUnit{"mysql", "1"},
+		setUnitStatus{"mysql/0", params.StatusStarted, ""},
+		setUnitWorkloadStatus{"mysql/0", params.WorkloadMaintenance, "syncing database"},
+
+		expect{
+			"agent and workload status are shown separately",
+			M{
+				"environment": "dummyenv",
+				"machines": M{
+					"0": machine0,
+					"1": machine1,
+				},
+				"services": M{
+					"mysql": M{
+						"charm":   "cs:quantal/mysql-1",
+						"exposed": true,
+						"units": M{
+							"mysql/0": M{
+								"machine":     "1",
+								"agent-state": "started",
+								"workload-status": M{
+									"current": "maintenance",
+									"message": "syncing database",
+								},
+								"public-address": "dummyenv-1.dns",
+							},
+						},
+					},
+				},
+			},
+		},
+	),
+	test(
+		"unit whose agent is running a hook",
+		addMachine{machineId: "0", job: state.JobManageEnviron},
+		setAddresses{"0", []network.Address{network.NewAddress("dummyenv-0.dns", network.ScopeUnknown)}},
+		startAliveMachine{"0"},
+		setMachineStatus{"0", params.StatusStarted, ""},
+		addMachine{machineId: "1", job: state.JobHostUnits},
+		setAddresses{"1", []network.Address{network.NewAddress("dummyenv-1.dns", network.ScopeUnknown)}},
+		startAliveMachine{"1"},
+		setMachineStatus{"1", params.StatusStarted, ""},
+		addCharm{"mysql"},
+		addService{name: "mysql", charm: "mysql"},
+		setServiceExposed{"mysql", true},
+		addAliveUnit{"mysql", "1"},
+		setUnitStatus{"mysql/0", params.StatusStarted, ""},
+		setUnitAgentActivity{"mysql/0", params.ActivityExecuting, "update-status"},
+
+		expect{
+			"the hook being run is shown",
+			M{
+				"environment": "dummyenv",
+				"machines": M{
+					"0": machine0,
+					"1": machine1,
+				},
+				"services": M{
+					"mysql": M{
+						"charm":   "cs:quantal/mysql-1",
+						"exposed": true,
+						"units": M{
+							"mysql/0": M{
+								"machine":             "1",
+								"agent-state":         "started",
+								"agent-activity":      "executing",
+								"agent-activity-hook": "update-status",
+								"public-address":      "dummyenv-1.dns",
+							},
+						},
+					},
+				},
+			},
+		},
+
+		setUnitAgentActivity{"mysql/0", params.ActivityIdle, ""},
+		expect{
+			"an idle agent is shown as such",
+			M{
+				"environment": "dummyenv",
+				"machines": M{
+					"0": machine0,
+					"1": machine1,
+				},
+				"services": M{
+					"mysql": M{
+						"charm":   "cs:quantal/mysql-1",
+						"exposed": true,
+						"units": M{
+							"mysql/0": M{
+								"machine":        "1",
+								"agent-state":    "started",
+								"agent-activity": "idle",
+								"public-address": "dummyenv-1.dns",
+							},
+						},
+					},
+				},
+			},
+		},
+	),
+	test(
+		"service exposed to CIDRs with a unit opening a port range",
+		addMachine{machineId: "0", job: state.JobManageEnviron},
+		setAddresses{"0", []network.Address{network.NewAddress("dummyenv-0.dns", network.ScopeUnknown)}},
+		startAliveMachine{"0"},
+		setMachineStatus{"0", params.StatusStarted, ""},
+		addMachine{machineId: "1", job: state.JobHostUnits},
+		setAddresses{"1", []network.Address{network.NewAddress("dummyenv-1.dns", network.ScopeUnknown)}},
+		startAliveMachine{"1"},
+		setMachineStatus{"1", params.StatusStarted, ""},
+		addCharm{"mysql"},
+		addService{name: "mysql", charm: "mysql"},
+		setServiceExposedTo{"mysql", []string{"10.0.0.0/8"}},
+		addAliveUnit{"mysql", "1"},
+		setUnitStatus{"mysql/0", params.StatusStarted, ""},
+		openUnitPorts{"mysql/0", "tcp", 3306, 3310},
+
+		expect{
+			"the CIDRs and the port range are shown",
+			M{
+				"environment": "dummyenv",
+				"machines": M{
+					"0": machine0,
+					"1": machine1,
+				},
+				"services": M{
+					"mysql": M{
+						"charm":      "cs:quantal/mysql-1",
+						"exposed":    true,
+						"exposed-to": L{"10.0.0.0/8"},
+						"units": M{
+							"mysql/0": M{
+								"machine":        "1",
+								"agent-state":    "started",
+								"open-ports":     L{"3306-3310/tcp"},
+								"public-address": "dummyenv-1.dns",
+							},
+						},
+					},
+				},
+			},
+		},
 	),
 }
 
@@ -1756,6 +1913,18 @@ func (sse setServiceExposed) step(c *gc.C, ctx *context) {
 	}
 }
 
+type setServiceExposedTo struct {
+	name  string
+	cidrs []string
+}
+
+func (sse setServiceExposedTo) step(c *gc.C, ctx *context) {
+	s, err := ctx.st.Service(sse.name)
+	c.Assert(err, gc.IsNil)
+	err = s.SetExposedTo(sse.cidrs)
+	c.Assert(err, gc.IsNil)
+}
+
 type setServiceCharm struct {
 	name  string
 	charm string
@@ -1844,6 +2013,32 @@ func (sus setUnitStatus) step(c *gc.C, ctx *context) {
 	c.Assert(err, gc.IsNil)
 }
 
+type setUnitWorkloadStatus struct {
+	unitName   string
+	status     params.WorkloadStatus
+	statusInfo string
+}
+
+func (sus setUnitWorkloadStatus) step(c *gc.C, ctx *context) {
+	u, err := ctx.st.Unit(sus.unitName)
+	c.Assert(err, gc.IsNil)
+	err = u.SetWorkloadStatus(sus.status, sus.statusInfo)
+	c.Assert(err, gc.IsNil)
+}
+
+type setUnitAgentActivity struct {
+	unitName string
+	activity params.AgentActivity
+	hook     string
+}
+
+func (sua setUnitAgentActivity) step(c *gc.C, ctx *context) {
+	u, err := ctx.st.Unit(sua.unitName)
+	c.Assert(err, gc.IsNil)
+	err = u.SetAgentActivity(sua.activity, sua.hook)
+	c.Assert(err, gc.IsNil)
+}
+
 type setUnitCharmURL struct {
 	unitName string
 	charm    string
@@ -1872,6 +2067,20 @@ func (oup openUnitPort) step(c *gc.C, ctx *context) {
 	c.Assert(err, gc.IsNil)
 }
 
+type openUnitPorts struct {
+	unitName string
+	protocol string
+	fromPort int
+	toPort   int
+}
+
+func (oup openUnitPorts) step(c *gc.C, ctx *context) {
+	u, err := ctx.st.Unit(oup.unitName)
+	c.Assert(err, gc.IsNil)
+	err = u.OpenPorts(oup.protocol, oup.fromPort, oup.toPort)
+	c.Assert(err, gc.IsNil)
+}
+
 type ensureDyingUnit struct {
 	unitName string
 }
@@ -2036,3 +2245,54 @@ func (s *StatusSuite) TestStatusFilterErrors(c *gc.C) {
 	c.Assert(code, gc.Not(gc.Equals), 0)
 	c.Assert(string(stderr), gc.Equals, `error: pattern "[*" contains invalid characters`+"\n")
 }
+
+func (s *StatusSuite) TestStatusCertificates(c *gc.C) {
+	err := s.State.SetStateServingInfo(params.StateServingInfo{
+		APIPort:    1234,
+		StatePort:  4321,
+		Cert:       coretesting.ServerCert,
+		PrivateKey: coretesting.ServerKey,
+	})
+	c.Assert(err, gc.IsNil)
+	code, stdout, stderr := runStatus(c, "--format", "yaml")
+	c.Assert(code, gc.Equals, 0, gc.Commentf("stderr: %s", stderr))
+
+	var status struct {
+		Certificates struct {
+			CAExpiry     []string `yaml:"ca-expiry"`
+			ServerExpiry string   `yaml:"server-expiry"`
+		}
+	}
+	err = goyaml.Unmarshal(stdout, &status)
+	c.Assert(err, gc.IsNil)
+	srvCert, err := cert.ParseCert(coretesting.ServerCert)
+	c.Assert(err, gc.IsNil)
+	c.Assert(status.Certificates.ServerExpiry, gc.Equals, srvCert.NotAfter.UTC().Format(time.RFC3339))
+	c.Assert(status.Certificates.CAExpiry, gc.DeepEquals, []string{
+		coretesting.CACertX509.NotAfter.UTC().Format(time.RFC3339),
+	})
+}
+
+func (s *StatusSuite) TestStatusTabularAndSummary(c *gc.C) {
+	ctx := s.newContext()
+	defer s.resetContext(c, ctx)
+	ctx.run(c, []stepper{
+		addMachine{machineId: "0", job: state.JobManageEnviron},
+		addMachine{machineId: "1", job: state.JobHostUnits},
+		addCharm{"mysql"},
+		addService{name: "mysql", charm: "mysql"},
+		addAliveUnit{"mysql", "1"},
+	})
+
+	code, stdout, stderr := runStatus(c, "--format", "tabular")
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(string(stderr), gc.Equals, "")
+	c.Assert(string(stdout), gc.Matches, `(?s)\[Machines\]\nID +STATE .*\n0 .*`+
+		`\[Services\]\nNAME +EXPOSED +CHARM\nmysql +false +local:quantal/mysql-1\n.*`+
+		`\[Units\]\nID +STATE .*\nmysql/0 .*`)
+
+	code, stdout, stderr = runStatus(c, "--format", "summary")
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(string(stderr), gc.Equals, "")
+	c.Assert(string(stdout), gc.Matches, `(?s)\[Machines\] +2\n.*\[Units\] +1\n.*\[Services\] +1\n.*`)
+}
@@ -0,0 +1,49 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/juju/juju/storage"
+)
+
+// storageFlag is a gnuflag.Value that accumulates storage
+// directives of the form <name>=[pool,]size[,count][,kind].
+type storageFlag struct {
+	stores *map[string]storage.Directive
+}
+
+// Set implements gnuflag.Value.Set.
+func (f storageFlag) Set(s string) error {
+	i := strings.Index(s, "=")
+	if i <= 0 {
+		return fmt.Errorf("expected <store>=<directive>, got %q", s)
+	}
+	name, value := s[:i], s[i+1:]
+	directive, err := storage.ParseDirective(value)
+	if err != nil {
+		return err
+	}
+	if *f.stores == nil {
+		*f.stores = make(map[string]storage.Directive)
+	}
+	if _, ok := (*f.stores)[name]; ok {
+		return fmt.Errorf("storage %q specified more than once", name)
+	}
+	(*f.stores)[name] = directive
+	return nil
+}
+
+// String implements gnuflag.Value.String.
+func (f storageFlag) String() string {
+	var strs []string
+	for name, directive := range *f.stores {
+		strs = append(strs, name+"="+directive.String())
+	}
+	sort.Strings(strs)
+	return strings.Join(strs, " ")
+}
@@ -0,0 +1,48 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"github.com/juju/cmd"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/juju"
+	"github.com/juju/juju/state/api/params"
+)
+
+type SubnetCommand struct {
+	*cmd.SuperCommand
+}
+
+const subnetCommandDoc = `
+"juju subnet" is used to manage the subnets known to the Juju environment.
+
+Subnets must be added before they can be made part of a space.
+`
+
+const subnetCommandPurpose = "manage subnets"
+
+func NewSubnetCommand() cmd.Command {
+	subnetcmd := &SubnetCommand{
+		SuperCommand: cmd.NewSuperCommand(cmd.SuperCommandParams{
+			Name:        "subnet",
+			Doc:         subnetCommandDoc,
+			UsagePrefix: "juju",
+			Purpose:     subnetCommandPurpose,
+		}),
+	}
+	subnetcmd.Register(envcmd.Wrap(&SubnetAddCommand{}))
+	subnetcmd.Register(envcmd.Wrap(&SubnetListCommand{}))
+	return subnetcmd
+}
+
+type subnetsAPI interface {
+	AddSubnet(cidr, providerId, spaceName string) error
+	ListSubnets(spaceName string) ([]params.Subnet, error)
+	Close() error
+}
+
+var getSubnetsAPI = func(envName string) (subnetsAPI, error) {
+	return juju.NewSubnetsClient(envName)
+}
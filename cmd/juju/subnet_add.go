@@ -0,0 +1,85 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/juju/cmd"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/network"
+)
+
+const subnetAddCommandDoc = `
+Add a subnet to the environment, optionally as part of an existing space.
+
+The subnet is identified either by its CIDR or by its provider-specific
+id. When the provider supports it, the subnet's other details, such as
+the availability zones it can be used in, are discovered automatically;
+a subnet unknown to the provider can only be added by CIDR.
+
+Examples:
+  juju subnet add 10.0.1.0/24 db
+  juju subnet add subnet-1f2a3b4c dmz
+  juju subnet add 10.0.3.0/24
+`
+
+// SubnetAddCommand adds a subnet to the environment.
+type SubnetAddCommand struct {
+	envcmd.EnvCommandBase
+	CIDR       string
+	ProviderId string
+	SpaceName  string
+}
+
+func (c *SubnetAddCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "add",
+		Args:    "<CIDR>|<provider-id> [<space>]",
+		Purpose: "add a subnet",
+		Doc:     subnetAddCommandDoc,
+	}
+}
+
+func (c *SubnetAddCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no subnet specified")
+	}
+	if _, _, err := net.ParseCIDR(args[0]); err == nil {
+		c.CIDR = args[0]
+	} else {
+		c.ProviderId = args[0]
+	}
+	args = args[1:]
+	if len(args) > 0 {
+		c.SpaceName, args = args[0], args[1:]
+		if !network.IsValidSpaceName(c.SpaceName) {
+			return fmt.Errorf("%q is not a valid space name", c.SpaceName)
+		}
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *SubnetAddCommand) Run(ctx *cmd.Context) error {
+	client, err := getSubnetsAPI(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if err := client.AddSubnet(c.CIDR, c.ProviderId, c.SpaceName); err != nil {
+		return err
+	}
+	subnet := c.CIDR
+	if subnet == "" {
+		subnet = c.ProviderId
+	}
+	if c.SpaceName == "" {
+		fmt.Fprintf(ctx.Stdout, "added subnet %q\n", subnet)
+	} else {
+		fmt.Fprintf(ctx.Stdout, "added subnet %q to space %q\n", subnet, c.SpaceName)
+	}
+	return nil
+}
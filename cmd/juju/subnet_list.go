@@ -0,0 +1,106 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/juju/cmd"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/network"
+)
+
+const subnetListCommandDoc = `
+List the subnets known to the environment, or only those in the space
+given with --space.
+`
+
+// SubnetListCommand lists the subnets known to the environment.
+type SubnetListCommand struct {
+	envcmd.EnvCommandBase
+	SpaceName string
+	out       cmd.Output
+}
+
+func (c *SubnetListCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "list",
+		Purpose: "list the subnets of the environment",
+		Doc:     subnetListCommandDoc,
+	}
+}
+
+func (c *SubnetListCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.SpaceName, "space", "", "only list the subnets in this space")
+	c.out.AddFlags(f, "tabular", map[string]cmd.Formatter{
+		"tabular": formatSubnetsTabular,
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+	})
+}
+
+func (c *SubnetListCommand) Init(args []string) error {
+	if c.SpaceName != "" && !network.IsValidSpaceName(c.SpaceName) {
+		return fmt.Errorf("%q is not a valid space name", c.SpaceName)
+	}
+	return cmd.CheckEmpty(args)
+}
+
+// subnetInfo is the serialisation format of a subnet.
+type subnetInfo struct {
+	CIDR              string   `json:"cidr" yaml:"cidr"`
+	ProviderId        string   `json:"provider-id,omitempty" yaml:"provider-id,omitempty"`
+	VLANTag           int      `json:"vlan-tag,omitempty" yaml:"vlan-tag,omitempty"`
+	AvailabilityZones []string `json:"zones,omitempty" yaml:"zones,omitempty"`
+	SpaceName         string   `json:"space,omitempty" yaml:"space,omitempty"`
+}
+
+func (c *SubnetListCommand) Run(ctx *cmd.Context) error {
+	client, err := getSubnetsAPI(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	subnets, err := client.ListSubnets(c.SpaceName)
+	if err != nil {
+		return err
+	}
+	out := make([]subnetInfo, len(subnets))
+	for i, subnet := range subnets {
+		out[i] = subnetInfo{
+			CIDR:              subnet.CIDR,
+			ProviderId:        subnet.ProviderId,
+			VLANTag:           subnet.VLANTag,
+			AvailabilityZones: subnet.AvailabilityZones,
+			SpaceName:         subnet.SpaceName,
+		}
+	}
+	return c.out.Write(ctx, out)
+}
+
+// formatSubnetsTabular formats subnets as a table.
+func formatSubnetsTabular(value interface{}) ([]byte, error) {
+	subnets, ok := value.([]subnetInfo)
+	if !ok {
+		return nil, fmt.Errorf("expected value of type %T, got %T", subnets, value)
+	}
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 1, 2, ' ', 0)
+	fmt.Fprintln(tw, "SUBNET\tPROVIDER-ID\tSPACE\tZONES")
+	for _, subnet := range subnets {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n",
+			subnet.CIDR,
+			orDash(subnet.ProviderId),
+			orDash(subnet.SpaceName),
+			orDash(strings.Join(subnet.AvailabilityZones, " ")),
+		)
+	}
+	tw.Flush()
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,150 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/juju/cmd"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/testing"
+)
+
+type SubnetCommandSuite struct {
+	testing.FakeJujuHomeSuite
+	mockAPI *mockSubnetsAPI
+}
+
+var _ = gc.Suite(&SubnetCommandSuite{})
+
+func (s *SubnetCommandSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.mockAPI = &mockSubnetsAPI{}
+	s.PatchValue(&getSubnetsAPI, func(envName string) (subnetsAPI, error) {
+		return s.mockAPI, nil
+	})
+}
+
+func (s *SubnetCommandSuite) TestHelp(c *gc.C) {
+	ctx, err := testing.RunCommand(c, NewSubnetCommand(), "--help")
+	c.Assert(err, gc.IsNil)
+	c.Assert(testing.Stdout(ctx), gc.Matches,
+		"(?s)usage: subnet <command> .+"+
+			subnetCommandPurpose+".+")
+
+	var namesFound []string
+	commandHelp := strings.SplitAfter(testing.Stdout(ctx), "commands:")[1]
+	commandHelp = strings.TrimSpace(commandHelp)
+	for _, line := range strings.Split(commandHelp, "\n") {
+		namesFound = append(namesFound, strings.TrimSpace(strings.Split(line, " - ")[0]))
+	}
+	c.Assert(namesFound, gc.DeepEquals, []string{"add", "help", "list"})
+}
+
+func newSubnetAddCommand() cmd.Command {
+	return envcmd.Wrap(&SubnetAddCommand{})
+}
+
+func newSubnetListCommand() cmd.Command {
+	return envcmd.Wrap(&SubnetListCommand{})
+}
+
+func (s *SubnetCommandSuite) TestAddInitErrors(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{
+		{nil, "no subnet specified"},
+		{[]string{"10.0.0.0/24", "DB"}, `"DB" is not a valid space name`},
+		{[]string{"10.0.0.0/24", "db", "extra"}, `unrecognized args: \["extra"\]`},
+	} {
+		c.Logf("test %d: %v", i, test.args)
+		_, err := testing.RunCommand(c, newSubnetAddCommand(), test.args...)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *SubnetCommandSuite) TestAddByCIDR(c *gc.C) {
+	ctx, err := testing.RunCommand(c, newSubnetAddCommand(), "10.0.0.0/24", "db")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mockAPI.cidr, gc.Equals, "10.0.0.0/24")
+	c.Assert(s.mockAPI.providerId, gc.Equals, "")
+	c.Assert(s.mockAPI.spaceName, gc.Equals, "db")
+	c.Assert(testing.Stdout(ctx), gc.Equals, `added subnet "10.0.0.0/24" to space "db"`+"\n")
+	c.Assert(s.mockAPI.closed, gc.Equals, true)
+}
+
+func (s *SubnetCommandSuite) TestAddByProviderId(c *gc.C) {
+	ctx, err := testing.RunCommand(c, newSubnetAddCommand(), "subnet-1f2a3b4c")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mockAPI.cidr, gc.Equals, "")
+	c.Assert(s.mockAPI.providerId, gc.Equals, "subnet-1f2a3b4c")
+	c.Assert(s.mockAPI.spaceName, gc.Equals, "")
+	c.Assert(testing.Stdout(ctx), gc.Equals, `added subnet "subnet-1f2a3b4c"`+"\n")
+}
+
+func (s *SubnetCommandSuite) TestAddError(c *gc.C) {
+	s.mockAPI.err = errors.New(`subnet with provider id "subnet-42" not found`)
+	_, err := testing.RunCommand(c, newSubnetAddCommand(), "subnet-42")
+	c.Assert(err, gc.ErrorMatches, `subnet with provider id "subnet-42" not found`)
+}
+
+func (s *SubnetCommandSuite) TestList(c *gc.C) {
+	s.mockAPI.subnets = []params.Subnet{{
+		CIDR:              "10.0.0.0/24",
+		ProviderId:        "subnet-0",
+		AvailabilityZones: []string{"zone1", "zone2"},
+		SpaceName:         "db",
+	}, {
+		CIDR: "10.0.1.0/24",
+	}}
+	ctx, err := testing.RunCommand(c, newSubnetListCommand())
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mockAPI.spaceName, gc.Equals, "")
+	c.Assert(testing.Stdout(ctx), gc.Equals, ""+
+		"SUBNET       PROVIDER-ID  SPACE  ZONES\n"+
+		"10.0.0.0/24  subnet-0     db     zone1 zone2\n"+
+		"10.0.1.0/24  -            -      -\n",
+	)
+	c.Assert(s.mockAPI.closed, gc.Equals, true)
+}
+
+func (s *SubnetCommandSuite) TestListSpace(c *gc.C) {
+	_, err := testing.RunCommand(c, newSubnetListCommand(), "--space", "db")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mockAPI.spaceName, gc.Equals, "db")
+
+	_, err = testing.RunCommand(c, newSubnetListCommand(), "--space", "DB")
+	c.Assert(err, gc.ErrorMatches, `"DB" is not a valid space name`)
+}
+
+type mockSubnetsAPI struct {
+	cidr       string
+	providerId string
+	spaceName  string
+	subnets    []params.Subnet
+	err        error
+	closed     bool
+}
+
+func (m *mockSubnetsAPI) AddSubnet(cidr, providerId, spaceName string) error {
+	m.cidr = cidr
+	m.providerId = providerId
+	m.spaceName = spaceName
+	return m.err
+}
+
+func (m *mockSubnetsAPI) ListSubnets(spaceName string) ([]params.Subnet, error) {
+	m.spaceName = spaceName
+	return m.subnets, m.err
+}
+
+func (m *mockSubnetsAPI) Close() error {
+	m.closed = true
+	return nil
+}
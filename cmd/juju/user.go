@@ -32,5 +32,7 @@ func NewUserCommand() cmd.Command {
 	// Define each subcommand in a separate "user_FOO.go" source file
 	// (with tests in user_FOO_test.go) and wire in here.
 	usercmd.Register(envcmd.Wrap(&UserAddCommand{}))
+	usercmd.Register(envcmd.Wrap(&UserGrantCommand{}))
+	usercmd.Register(envcmd.Wrap(&UserRevokeCommand{}))
 	return usercmd
 }
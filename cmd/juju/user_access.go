@@ -0,0 +1,139 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/juju"
+)
+
+const userGrantCommandDoc = `
+Grant a user access to the environment.
+
+A user's access level is one of:
+  read   the user can inspect the environment, but not change it
+  write  the user can also deploy, configure and remove services and machines
+  admin  the user can also manage users and destroy the environment
+
+The user is given exactly the access level requested, so granting read
+access to a user with write access takes write access away. The new
+access level applies to connections the user makes after it is granted.
+
+Examples:
+  juju user grant foobar read   (Let user "foobar" inspect, but not change, the environment)
+  juju user grant foobar admin  (Give user "foobar" full control of the environment)
+`
+
+const userRevokeCommandDoc = `
+Revoke a user's access to the environment.
+
+Revoking an access level leaves the user with the next lower level, so
+revoking admin access leaves the user with write access, and revoking
+write access leaves the user with read access. Revoking read access
+leaves the user with no access to the environment at all.
+
+Examples:
+  juju user revoke foobar write  (Leave user "foobar" with read access)
+  juju user revoke foobar read   (Stop user "foobar" using the environment)
+`
+
+// userAccessCommand holds the arguments common to
+// the user grant and revoke commands.
+type userAccessCommand struct {
+	envcmd.EnvCommandBase
+	User   string
+	Access string
+}
+
+func (c *userAccessCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no username supplied")
+	}
+	if len(args) == 1 {
+		return fmt.Errorf("no access level supplied")
+	}
+	c.User, c.Access = args[0], args[1]
+	if err := checkAccessLevel(c.Access); err != nil {
+		return err
+	}
+	return cmd.CheckEmpty(args[2:])
+}
+
+// checkAccessLevel returns an error if access
+// is not a known access level.
+func checkAccessLevel(access string) error {
+	switch access {
+	case "read", "write", "admin":
+		return nil
+	}
+	return fmt.Errorf(`invalid access level %q; expected "read", "write" or "admin"`, access)
+}
+
+type userAccessAPI interface {
+	GrantAccess(username, access string) error
+	RevokeAccess(username, access string) error
+	Close() error
+}
+
+var getUserAccessAPI = func(c *userAccessCommand) (userAccessAPI, error) {
+	return juju.NewUserManagerClient(c.EnvName)
+}
+
+// UserGrantCommand gives a user access to the environment.
+type UserGrantCommand struct {
+	userAccessCommand
+}
+
+func (c *UserGrantCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "grant",
+		Args:    "<username> read|write|admin",
+		Purpose: "grants a user access to the environment",
+		Doc:     userGrantCommandDoc,
+	}
+}
+
+func (c *UserGrantCommand) Run(ctx *cmd.Context) error {
+	client, err := getUserAccessAPI(&c.userAccessCommand)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if err := client.GrantAccess(c.User, c.Access); err != nil {
+		return err
+	}
+	fmt.Fprintf(ctx.Stdout, "user %q granted %s access\n", c.User, c.Access)
+	return nil
+}
+
+// UserRevokeCommand takes a user's access to the environment away.
+type UserRevokeCommand struct {
+	userAccessCommand
+}
+
+func (c *UserRevokeCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "revoke",
+		Args:    "<username> write|admin",
+		Purpose: "revokes a user's access to the environment",
+		Doc:     userRevokeCommandDoc,
+	}
+}
+
+func (c *UserRevokeCommand) Run(ctx *cmd.Context) error {
+	client, err := getUserAccessAPI(&c.userAccessCommand)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if err := client.RevokeAccess(c.User, c.Access); err != nil {
+		return err
+	}
+	fmt.Fprintf(ctx.Stdout, "%s access revoked from user %q\n", c.Access, c.User)
+	return nil
+}
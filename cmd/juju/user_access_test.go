@@ -0,0 +1,119 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"errors"
+
+	"github.com/juju/cmd"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/testing"
+)
+
+type UserAccessCommandSuite struct {
+	testing.FakeJujuHomeSuite
+	mockAPI *mockUserAccessAPI
+}
+
+var _ = gc.Suite(&UserAccessCommandSuite{})
+
+func (s *UserAccessCommandSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.mockAPI = &mockUserAccessAPI{}
+	s.PatchValue(&getUserAccessAPI, func(c *userAccessCommand) (userAccessAPI, error) {
+		return s.mockAPI, nil
+	})
+}
+
+func (s *UserAccessCommandSuite) TestInit(c *gc.C) {
+	for i, test := range []struct {
+		args        []string
+		user        string
+		access      string
+		errorString string
+	}{{
+		errorString: "no username supplied",
+	}, {
+		args:        []string{"foobar"},
+		errorString: "no access level supplied",
+	}, {
+		args:   []string{"foobar", "read"},
+		user:   "foobar",
+		access: "read",
+	}, {
+		args:   []string{"foobar", "admin"},
+		user:   "foobar",
+		access: "admin",
+	}, {
+		args:        []string{"foobar", "root"},
+		errorString: `invalid access level "root"; expected "read", "write" or "admin"`,
+	}, {
+		args:        []string{"foobar", "write", "extra"},
+		errorString: `unrecognized args: \["extra"\]`,
+	}} {
+		c.Logf("test %d", i)
+		grantCmd := &UserGrantCommand{}
+		err := testing.InitCommand(grantCmd, test.args)
+		if test.errorString == "" {
+			c.Check(err, gc.IsNil)
+			c.Check(grantCmd.User, gc.Equals, test.user)
+			c.Check(grantCmd.Access, gc.Equals, test.access)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.errorString)
+		}
+	}
+}
+
+func (s *UserAccessCommandSuite) TestGrant(c *gc.C) {
+	context, err := testing.RunCommand(c, envcmd.Wrap(&UserGrantCommand{}), "foobar", "read")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mockAPI.calls, gc.DeepEquals, []string{"GrantAccess foobar read"})
+	c.Assert(testing.Stdout(context), gc.Equals, "user \"foobar\" granted read access\n")
+}
+
+func (s *UserAccessCommandSuite) TestRevoke(c *gc.C) {
+	context, err := testing.RunCommand(c, envcmd.Wrap(&UserRevokeCommand{}), "foobar", "write")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mockAPI.calls, gc.DeepEquals, []string{"RevokeAccess foobar write"})
+	c.Assert(testing.Stdout(context), gc.Equals, "write access revoked from user \"foobar\"\n")
+}
+
+func (s *UserAccessCommandSuite) TestErrorResponse(c *gc.C) {
+	s.mockAPI.failMessage = "permission denied"
+	for _, command := range []cmd.Command{
+		envcmd.Wrap(&UserGrantCommand{}),
+		envcmd.Wrap(&UserRevokeCommand{}),
+	} {
+		context, err := testing.RunCommand(c, command, "foobar", "admin")
+		c.Assert(err, gc.ErrorMatches, "permission denied")
+		c.Assert(testing.Stdout(context), gc.Equals, "")
+	}
+}
+
+type mockUserAccessAPI struct {
+	failMessage string
+	calls       []string
+}
+
+func (m *mockUserAccessAPI) call(method, username, access string) error {
+	m.calls = append(m.calls, method+" "+username+" "+access)
+	if m.failMessage == "" {
+		return nil
+	}
+	return errors.New(m.failMessage)
+}
+
+func (m *mockUserAccessAPI) GrantAccess(username, access string) error {
+	return m.call("GrantAccess", username, access)
+}
+
+func (m *mockUserAccessAPI) RevokeAccess(username, access string) error {
+	return m.call("RevokeAccess", username, access)
+}
+
+func (*mockUserAccessAPI) Close() error {
+	return nil
+}
@@ -26,10 +26,16 @@ will be lost when the environent is destroyed.  An environment file
 (.jenv) identifying the new user and the environment can be generated
 using --output.
 
+New users have read access to the environment unless --access is
+given; see "juju help user grant" for the access levels. They have
+no access to any other environment hosted by the state server until
+it is granted there.
+
 Examples:
   juju user add foobar                    (Add user "foobar". A strong password will be generated and printed)
   juju user add foobar --password=mypass  (Add user "foobar" with password "mypass")
   juju user add foobar --output filename  (Add user "foobar" and save environment file to "filename")
+  juju user add foobar --access write     (Add user "foobar", who may change the environment)
 `
 
 type UserAddCommand struct {
@@ -38,6 +44,7 @@ type UserAddCommand struct {
 	DisplayName string
 	Password    string
 	OutPath     string
+	Access      string
 }
 
 func (c *UserAddCommand) Info() *cmd.Info {
@@ -53,6 +60,7 @@ func (c *UserAddCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.StringVar(&c.Password, "password", "", "Password for new user")
 	f.StringVar(&c.OutPath, "o", "", "Output an environment file for new user")
 	f.StringVar(&c.OutPath, "output", "", "")
+	f.StringVar(&c.Access, "access", "read", "Access level of the new user to the environment: read, write or admin")
 }
 
 func (c *UserAddCommand) Init(args []string) error {
@@ -63,11 +71,15 @@ func (c *UserAddCommand) Init(args []string) error {
 	if len(args) > 0 {
 		c.DisplayName, args = args[0], args[1:]
 	}
+	if err := checkAccessLevel(c.Access); err != nil {
+		return err
+	}
 	return cmd.CheckEmpty(args)
 }
 
 type addUserAPI interface {
 	AddUser(username, displayname, password string) error
+	GrantAccess(username, access string) error
 	Close() error
 }
 
@@ -98,6 +110,13 @@ func (c *UserAddCommand) Run(ctx *cmd.Context) error {
 	}
 
 	fmt.Fprintf(ctx.Stdout, "user %q added with password %q\n", user, c.Password)
+	if c.Access != "read" {
+		// Users are added with read access.
+		if err := client.GrantAccess(c.User, c.Access); err != nil {
+			return err
+		}
+		fmt.Fprintf(ctx.Stdout, "user %q granted %s access\n", c.User, c.Access)
+	}
 
 	if c.OutPath != "" {
 		outPath := NormaliseJenvPath(ctx, c.OutPath)
@@ -72,6 +72,17 @@ func (s *UserAddCommandSuite) TestAddUserUsernameAndDisplaynameWithPassword(c *g
 	c.Assert(testing.Stdout(context), gc.Equals, expected+"\n")
 }
 
+func (s *UserAddCommandSuite) TestAddUserWithAccess(c *gc.C) {
+	context, err := testing.RunCommand(c, newUserAddCommand(), "foobar", "--password", "password", "--access", "write")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mockAPI.username, gc.Equals, "foobar")
+	c.Assert(s.mockAPI.access, gc.Equals, "write")
+	expected := `user "foobar" added with password "password"
+user "foobar" granted write access
+`
+	c.Assert(testing.Stdout(context), gc.Equals, expected)
+}
+
 func (s *UserAddCommandSuite) TestAddUserErrorResponse(c *gc.C) {
 	s.mockAPI.failMessage = "failed to create user, chaos ensues"
 	context, err := testing.RunCommand(c, newUserAddCommand(), "foobar")
@@ -88,6 +99,7 @@ func (s *UserAddCommandSuite) TestInit(c *gc.C) {
 		displayname string
 		password    string
 		outPath     string
+		access      string
 		errorString string
 	}{
 		{
@@ -114,6 +126,13 @@ func (s *UserAddCommandSuite) TestInit(c *gc.C) {
 			args:    []string{"foobar", "-o", "somefile"},
 			user:    "foobar",
 			outPath: "somefile",
+		}, {
+			args:   []string{"foobar", "--access", "admin"},
+			user:   "foobar",
+			access: "admin",
+		}, {
+			args:        []string{"foobar", "--access", "superuser"},
+			errorString: `invalid access level "superuser"; expected "read", "write" or "admin"`,
 		},
 	} {
 		c.Logf("test %d", i)
@@ -124,6 +143,10 @@ func (s *UserAddCommandSuite) TestInit(c *gc.C) {
 			c.Check(addUserCmd.DisplayName, gc.Equals, test.displayname)
 			c.Check(addUserCmd.Password, gc.Equals, test.password)
 			c.Check(addUserCmd.OutPath, gc.Equals, test.outPath)
+			if test.access == "" {
+				test.access = "read"
+			}
+			c.Check(addUserCmd.Access, gc.Equals, test.access)
 		} else {
 			c.Check(err, gc.ErrorMatches, test.errorString)
 		}
@@ -177,6 +200,7 @@ type mockAddUserAPI struct {
 	username    string
 	displayname string
 	password    string
+	access      string
 }
 
 func (m *mockAddUserAPI) AddUser(username, displayname, password string) error {
@@ -189,6 +213,11 @@ func (m *mockAddUserAPI) AddUser(username, displayname, password string) error {
 	return errors.New(m.failMessage)
 }
 
+func (m *mockAddUserAPI) GrantAccess(username, access string) error {
+	m.access = access
+	return nil
+}
+
 func (*mockAddUserAPI) Close() error {
 	return nil
 }
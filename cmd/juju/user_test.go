@@ -19,7 +19,9 @@ var _ = gc.Suite(&UserCommandSuite{})
 
 var expectedUserCommmandNames = []string{
 	"add",
+	"grant",
 	"help",
+	"revoke",
 }
 
 func (s *UserCommandSuite) TestHelp(c *gc.C) {
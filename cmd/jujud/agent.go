@@ -12,6 +12,7 @@ import (
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
+	"github.com/juju/loggo"
 	"github.com/juju/utils"
 	"github.com/juju/utils/fslock"
 	"launchpad.net/gnuflag"
@@ -27,6 +28,7 @@ import (
 	"github.com/juju/juju/version"
 	"github.com/juju/juju/worker"
 	"github.com/juju/juju/worker/deployer"
+	"github.com/juju/juju/worker/logsender"
 	"github.com/juju/juju/worker/rsyslog"
 	"github.com/juju/juju/worker/upgrader"
 )
@@ -92,6 +94,13 @@ func (a *AgentConf) SetAPIHostPorts(servers [][]network.HostPort) error {
 	})
 }
 
+// SetCACert satisfies worker/apiaddressupdater/APIAddressSetter.
+func (a *AgentConf) SetCACert(caCert string) error {
+	return a.ChangeConfig(func(c agent.ConfigSetter) {
+		c.SetCACert(caCert)
+	})
+}
+
 func importance(err error) int {
 	switch {
 	case err == nil:
@@ -329,6 +338,28 @@ var newRsyslogConfigWorker = func(st *apirsyslog.State, agentConfig agent.Config
 	return rsyslog.NewRsyslogConfigWorker(st, mode, tag, namespace, addrs)
 }
 
+// bufferedLogSize holds the number of log records an agent
+// buffers while waiting to send them to the API server.
+const bufferedLogSize = 1024
+
+var (
+	agentLogsOnce sync.Once
+	agentLogs     *logsender.BufferedLogWriter
+)
+
+// bufferedAgentLogs returns the writer holding the log records waiting
+// to be sent to the API server by the logsender worker. The writer is
+// registered with loggo the first time it is called.
+func bufferedAgentLogs() *logsender.BufferedLogWriter {
+	agentLogsOnce.Do(func() {
+		agentLogs = logsender.NewBufferedLogWriter(bufferedLogSize)
+		if err := loggo.RegisterWriter("logsender", agentLogs, loggo.TRACE); err != nil {
+			logger.Errorf("cannot register log sender: %v", err)
+		}
+	})
+	return agentLogs
+}
+
 // hookExecutionLock returns an *fslock.Lock suitable for use as a unit
 // hook execution lock. Other workers may also use this lock if they
 // require isolation from hook execution.
@@ -244,6 +244,9 @@ func (s *agentSuite) SetUpSuite(c *gc.C) {
 	s.PatchValue(&ensureMongoServer, func(string, string, params.StateServingInfo) error {
 		return nil
 	})
+	s.PatchValue(&restartMongoServer, func(string, string, params.StateServingInfo) error {
+		return nil
+	})
 }
 
 func (s *agentSuite) TearDownSuite(c *gc.C) {
@@ -24,6 +24,7 @@ import (
 
 	"github.com/juju/juju/agent"
 	"github.com/juju/juju/container/kvm"
+	"github.com/juju/juju/container/lxd"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/mongo"
@@ -43,18 +44,24 @@ import (
 	"github.com/juju/juju/worker/charmrevisionworker"
 	"github.com/juju/juju/worker/cleaner"
 	"github.com/juju/juju/worker/deployer"
+	"github.com/juju/juju/worker/envworkermanager"
 	"github.com/juju/juju/worker/firewaller"
 	"github.com/juju/juju/worker/instancepoller"
 	"github.com/juju/juju/worker/localstorage"
 	workerlogger "github.com/juju/juju/worker/logger"
+	"github.com/juju/juju/worker/logsender"
 	"github.com/juju/juju/worker/machineenvironmentworker"
 	"github.com/juju/juju/worker/machiner"
+	"github.com/juju/juju/worker/metricworker"
 	"github.com/juju/juju/worker/minunitsworker"
+	"github.com/juju/juju/worker/mongocertupdater"
 	"github.com/juju/juju/worker/peergrouper"
 	"github.com/juju/juju/worker/provisioner"
+	"github.com/juju/juju/worker/remoterelations"
 	"github.com/juju/juju/worker/resumer"
 	"github.com/juju/juju/worker/rsyslog"
 	"github.com/juju/juju/worker/singular"
+	"github.com/juju/juju/worker/storageprovisioner"
 	"github.com/juju/juju/worker/terminationworker"
 	"github.com/juju/juju/worker/upgrader"
 )
@@ -76,6 +83,7 @@ var (
 	// The following are defined as variables to
 	// allow the tests to intercept calls to the functions.
 	ensureMongoServer        = mongo.EnsureServer
+	restartMongoServer       = mongo.RestartServer
 	maybeInitiateMongoServer = peergrouper.MaybeInitiateMongoServer
 	ensureMongoAdminUser     = mongo.EnsureAdminUser
 	newSingularRunner        = singular.New
@@ -149,6 +157,7 @@ func (a *MachineAgent) Run(_ *cmd.Context) error {
 	// lines of all logging in the log file.
 	loggo.RemoveWriter("logfile")
 	defer a.tomb.Done()
+	bufferedAgentLogs()
 	logger.Infof("machine agent %v start (%s [%s])", a.Tag(), version.Current, runtime.Compiler)
 	if err := a.ReadConfig(a.Tag()); err != nil {
 		return fmt.Errorf("cannot read agent configuration: %v", err)
@@ -276,6 +285,9 @@ func (a *MachineAgent) APIWorker() (worker.Worker, error) {
 	runner.StartWorker("upgrade-steps", func() (worker.Worker, error) {
 		return a.upgradeWorker(st, entity.Jobs(), agentConfig), nil
 	})
+	runner.StartWorker("logsender", func() (worker.Worker, error) {
+		return logsender.New(bufferedAgentLogs().Logs(), st.Logger(), a.Tag()), nil
+	})
 
 	// All other workers must wait for the upgrade steps to complete
 	// before starting.
@@ -316,6 +328,10 @@ func (a *MachineAgent) APIWorker() (worker.Worker, error) {
 				context := newDeployContext(apiDeployer, agentConfig)
 				return deployer.NewDeployer(apiDeployer, context), nil
 			})
+			a.startWorkerAfterUpgrade(runner, "storageprovisioner", func() (worker.Worker, error) {
+				storageDir := filepath.Join(agentConfig.DataDir(), "storage")
+				return storageprovisioner.NewStorageProvisioner(st.StorageProvisioner(), entity.Tag(), storageDir), nil
+			})
 		case params.JobManageEnviron:
 			a.startWorkerAfterUpgrade(singularRunner, "environ-provisioner", func() (worker.Worker, error) {
 				return provisioner.NewEnvironProvisioner(st.Provisioner(), agentConfig), nil
@@ -330,6 +346,12 @@ func (a *MachineAgent) APIWorker() (worker.Worker, error) {
 			a.startWorkerAfterUpgrade(singularRunner, "charm-revision-updater", func() (worker.Worker, error) {
 				return charmrevisionworker.NewRevisionUpdateWorker(st.CharmRevisionUpdater()), nil
 			})
+			a.startWorkerAfterUpgrade(singularRunner, "remote-relations", func() (worker.Worker, error) {
+				return remoterelations.NewRemoteRelationsWorker(st.RemoteRelations()), nil
+			})
+			a.startWorkerAfterUpgrade(singularRunner, "metric-cleanup-worker", func() (worker.Worker, error) {
+				return metricworker.NewCleanupWorker(st.MetricsManager()), nil
+			})
 		case params.JobManageStateDeprecated:
 			// Legacy environments may set this, but we ignore it.
 		default:
@@ -355,6 +377,16 @@ func (a *MachineAgent) setupContainerSupport(runner worker.Runner, st *api.State
 	if err == nil && supportsKvm {
 		supportedContainers = append(supportedContainers, instance.KVM)
 	}
+	// Nor do we support nested lxd containers.
+	if entity.ContainerType() != instance.LXD {
+		supportsLxd, err := lxd.IsLXDSupported()
+		if err != nil {
+			logger.Warningf("determining lxd support: %v\nno lxd containers possible", err)
+		}
+		if err == nil && supportsLxd {
+			supportedContainers = append(supportedContainers, instance.LXD)
+		}
+	}
 	return a.updateSupportedContainers(runner, st, entity.Tag(), supportedContainers, agentConfig)
 }
 
@@ -418,6 +450,9 @@ func (a *MachineAgent) StateWorker() (worker.Worker, error) {
 	if err := a.ensureMongoServer(agentConfig); err != nil {
 		return nil, err
 	}
+	if err := a.upgradeState(agentConfig); err != nil {
+		return nil, err
+	}
 	st, m, err := openState(agentConfig)
 	if err != nil {
 		return nil, err
@@ -482,6 +517,13 @@ func (a *MachineAgent) StateWorker() (worker.Worker, error) {
 					LogDir:  logDir,
 				})
 			})
+			runner.StartWorker("mongocertupdater", func() (worker.Worker, error) {
+				info, ok := a.CurrentConfig().StateServingInfo()
+				if !ok {
+					return nil, &fatalError{"StateServingInfo not available and we need it"}
+				}
+				return mongocertupdater.NewMongoCertUpdater(st, m.Id(), info.Cert, a.restartMongoServer), nil
+			})
 			a.startWorkerAfterUpgrade(singularRunner, "cleaner", func() (worker.Worker, error) {
 				return cleaner.NewCleaner(st), nil
 			})
@@ -494,6 +536,9 @@ func (a *MachineAgent) StateWorker() (worker.Worker, error) {
 			a.startWorkerAfterUpgrade(singularRunner, "minunitsworker", func() (worker.Worker, error) {
 				return minunitsworker.NewMinUnitsWorker(st), nil
 			})
+			a.startWorkerAfterUpgrade(singularRunner, "envworkermanager", func() (worker.Worker, error) {
+				return envworkermanager.NewEnvWorkerManager(st, a.startEnvWorkers), nil
+			})
 		case state.JobManageStateDeprecated:
 			// Legacy environments may set this, but we ignore it.
 		default:
@@ -503,6 +548,69 @@ func (a *MachineAgent) StateWorker() (worker.Worker, error) {
 	return newCloseWorker(runner, st), nil
 }
 
+// startEnvWorkers starts the workers of an environment hosted by
+// the state server, given a State for the environment. The workers
+// that use the API log in to the environment as this machine agent.
+func (a *MachineAgent) startEnvWorkers(st *state.State) (worker.Worker, error) {
+	agentConfig := a.CurrentConfig()
+	env, err := st.Environment()
+	if err != nil {
+		return nil, err
+	}
+	info := agentConfig.APIInfo()
+	info.EnvironTag = env.Tag()
+	apiSt, err := apiOpen(info, api.DialOpts{})
+	if err != nil {
+		return nil, err
+	}
+	runner := newRunner(connectionIsFatal(apiSt), moreImportant)
+	runner.StartWorker("cleaner", func() (worker.Worker, error) {
+		return cleaner.NewCleaner(st), nil
+	})
+	runner.StartWorker("minunitsworker", func() (worker.Worker, error) {
+		return minunitsworker.NewMinUnitsWorker(st), nil
+	})
+	runner.StartWorker("instancepoller", func() (worker.Worker, error) {
+		return instancepoller.NewWorker(st), nil
+	})
+	runner.StartWorker("environ-provisioner", func() (worker.Worker, error) {
+		return provisioner.NewEnvironProvisioner(apiSt.Provisioner(), agentConfig), nil
+	})
+	runner.StartWorker("firewaller", func() (worker.Worker, error) {
+		return firewaller.NewFirewaller(apiSt.Firewaller())
+	})
+	runner.StartWorker("charm-revision-updater", func() (worker.Worker, error) {
+		return charmrevisionworker.NewRevisionUpdateWorker(apiSt.CharmRevisionUpdater()), nil
+	})
+	runner.StartWorker("remote-relations", func() (worker.Worker, error) {
+		return remoterelations.NewRemoteRelationsWorker(apiSt.RemoteRelations()), nil
+	})
+	runner.StartWorker("metric-cleanup-worker", func() (worker.Worker, error) {
+		return metricworker.NewCleanupWorker(apiSt.MetricsManager()), nil
+	})
+	return newCloseWorker(runner, apiSt), nil
+}
+
+// restartMongoServer records the given state server certificate
+// and key in the agent configuration and restarts mongo so that
+// it serves them.
+func (a *MachineAgent) restartMongoServer(info params.StateServingInfo) error {
+	if err := a.ChangeConfig(func(config agent.ConfigSetter) {
+		servingInfo, _ := config.StateServingInfo()
+		servingInfo.Cert = info.Cert
+		servingInfo.PrivateKey = info.PrivateKey
+		config.SetStateServingInfo(servingInfo)
+	}); err != nil {
+		return err
+	}
+	agentConfig := a.CurrentConfig()
+	servingInfo, ok := agentConfig.StateServingInfo()
+	if !ok {
+		return fmt.Errorf("state worker was started with no state serving info")
+	}
+	return restartMongoServer(agentConfig.DataDir(), agentConfig.Value(agent.Namespace), servingInfo)
+}
+
 // ensureMongoServer ensures that mongo is installed and running,
 // and ready for opening a state connection.
 func (a *MachineAgent) ensureMongoServer(agentConfig agent.Config) error {
@@ -736,6 +844,29 @@ func (a *MachineAgent) upgradeWorker(
 	})
 }
 
+// upgradeState runs the upgrade steps that change the database of
+// the state servers. They run before the state is opened for the
+// state worker, as the state server may not be able to find even
+// its own machine until they have run.
+func (a *MachineAgent) upgradeState(agentConfig agent.Config) error {
+	if agentConfig.UpgradedToVersion() == version.Current.Number {
+		return nil
+	}
+	info, ok := agentConfig.StateInfo()
+	if !ok {
+		return fmt.Errorf("no state info available")
+	}
+	st, err := state.Open(info, mongo.DialOpts{}, environs.NewStatePolicy())
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+	if err := upgrades.PerformStateUpgrade(st, a.Tag()); err != nil {
+		return fmt.Errorf("cannot upgrade state to %v: %v", version.Current, err)
+	}
+	return nil
+}
+
 // runUpgrades runs the upgrade operations for each job type and updates the updatedToVersion on success.
 func (a *MachineAgent) runUpgrades(
 	st *state.State,
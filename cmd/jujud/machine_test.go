@@ -399,7 +399,9 @@ func (s *MachineSuite) TestManageEnviron(c *gc.C) {
 		"cleaner",
 		"environ-provisioner",
 		"firewaller",
+		"metric-cleanup-worker",
 		"minunitsworker",
+		"remote-relations",
 		"resumer",
 	})
 }
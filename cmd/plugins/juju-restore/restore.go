@@ -154,9 +154,13 @@ var updateBootstrapMachineTemplate = mustParseTemplate(`
 	# Remove all state machines but 0, to restore HA
 	mongoEval '
 		db = db.getSiblingDB("juju")
-		db.machines.update({_id: "0"}, {$set: {instanceid: {{.NewInstanceId | printf "%q" }} } })
-		db.instanceData.update({_id: "0"}, {$set: {instanceid: {{.NewInstanceId | printf "%q" }} } })
-		db.machines.remove({_id: {$ne:"0"}, hasvote: true})
+		// Documents are keyed by environment UUID, unless
+		// the backup predates the hosting of environments.
+		uuid = db.stateServers.findOne({_id: "e"})["env-uuid"]
+		prefix = uuid ? uuid + ":" : ""
+		db.machines.update({_id: prefix + "0"}, {$set: {instanceid: {{.NewInstanceId | printf "%q" }} } })
+		db.instanceData.update({_id: prefix + "0"}, {$set: {instanceid: {{.NewInstanceId | printf "%q" }} } })
+		db.machines.remove({_id: {$ne: prefix + "0"}, hasvote: true})
 		db.stateServers.update({"_id":"e"}, {$set:{"machineids" : [0]}})
 		db.stateServers.update({"_id":"e"}, {$set:{"votingmachineids" : [0]}})
 	'
@@ -280,6 +280,18 @@ var parseConstraintsTests = []struct {
 		args:    []string{"networks="},
 	},
 
+	// spaces
+	{
+		summary: "single space",
+		args:    []string{"spaces=db"},
+	}, {
+		summary: "multiple spaces - positive and negative",
+		args:    []string{"spaces=db,^dmz,admin-2"},
+	}, {
+		summary: "no spaces",
+		args:    []string{"spaces="},
+	},
+
 	// instance type
 	{
 		summary: "set instance type",
@@ -389,6 +401,28 @@ func (s *ConstraintsSuite) TestInvalidNetworks(c *gc.C) {
 	}
 }
 
+func (s *ConstraintsSuite) TestIncludeExcludeAndHaveSpaces(c *gc.C) {
+	con := constraints.MustParse("spaces=db,^dmz,admin,^public-2")
+	c.Check(con.IncludeSpaces(), jc.SameContents, []string{"db", "admin"})
+	c.Check(con.ExcludeSpaces(), jc.SameContents, []string{"dmz", "public-2"})
+	c.Check(con.HaveSpaces(), jc.IsTrue)
+	c.Check(con.String(), gc.Equals, "spaces=db,^dmz,admin,^public-2")
+	con = constraints.MustParse("mem=4G")
+	c.Check(con.HaveSpaces(), jc.IsFalse)
+	con = constraints.MustParse("spaces=")
+	c.Check(con.HaveSpaces(), jc.IsFalse)
+}
+
+func (s *ConstraintsSuite) TestInvalidSpaces(c *gc.C) {
+	for _, name := range []string{"DB", "^^db", "db_2", "-db", "net/3"} {
+		con, err := constraints.Parse("spaces=" + name)
+		expectName := strings.TrimPrefix(name, "^")
+		c.Check(err, gc.NotNil)
+		c.Check(err.Error(), gc.Equals, fmt.Sprintf(`bad "spaces" constraint: %q is not a valid space name`, expectName))
+		c.Check(con, jc.DeepEquals, constraints.Value{})
+	}
+}
+
 func (s *ConstraintsSuite) TestIsEmpty(c *gc.C) {
 	con := constraints.Value{}
 	c.Check(&con, jc.Satisfies, constraints.IsEmpty)
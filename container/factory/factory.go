@@ -11,6 +11,7 @@ import (
 	"github.com/juju/juju/container"
 	"github.com/juju/juju/container/kvm"
 	"github.com/juju/juju/container/lxc"
+	"github.com/juju/juju/container/lxd"
 	"github.com/juju/juju/instance"
 )
 
@@ -22,6 +23,8 @@ func NewContainerManager(forType instance.ContainerType, conf container.ManagerC
 		return lxc.NewContainerManager(conf)
 	case instance.KVM:
 		return kvm.NewContainerManager(conf)
+	case instance.LXD:
+		return lxd.NewContainerManager(conf)
 	}
 	return nil, fmt.Errorf("unknown container type: %q", forType)
 }
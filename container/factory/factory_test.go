@@ -28,6 +28,9 @@ func (*factorySuite) TestNewContainerManager(c *gc.C) {
 	}, {
 		containerType: instance.KVM,
 		valid:         true,
+	}, {
+		containerType: instance.LXD,
+		valid:         true,
 	}, {
 		containerType: instance.NONE,
 		valid:         false,
@@ -20,6 +20,7 @@ import (
 	coreCloudinit "github.com/juju/juju/cloudinit"
 	"github.com/juju/juju/container"
 	"github.com/juju/juju/environs/cloudinit"
+	"github.com/juju/juju/environs/config"
 )
 
 const (
@@ -47,12 +48,16 @@ var (
 
 // templateUserData returns a minimal user data necessary for the template.
 // This should have the authorized keys, base packages, the cloud archive if
-// necessary,  initial apt proxy config, and it should do the apt-get
-// update/upgrade initially.
+// necessary,  initial apt proxy config, the apt mirror and package sources,
+// and it should do the apt-get update/upgrade initially, unless upgrade
+// is false.
 func templateUserData(
 	series string,
 	authorizedKeys string,
 	aptProxy proxy.Settings,
+	aptMirror string,
+	packageSources []config.PackageSource,
+	upgrade bool,
 ) ([]byte, error) {
 	config := coreCloudinit.New()
 	config.AddScripts(
@@ -60,7 +65,7 @@ func templateUserData(
 	)
 	config.AddSSHAuthorizedKeys(authorizedKeys)
 	cloudinit.MaybeAddCloudArchiveCloudTools(config, series)
-	cloudinit.AddAptCommands(aptProxy, config)
+	cloudinit.AddAptCommands(aptProxy, aptMirror, packageSources, upgrade, config)
 	config.AddScripts(
 		fmt.Sprintf(
 			"printf '%%s\n' %s > %s",
@@ -96,6 +101,9 @@ func EnsureCloneTemplate(
 	network *container.NetworkConfig,
 	authorizedKeys string,
 	aptProxy proxy.Settings,
+	aptMirror string,
+	packageSources []config.PackageSource,
+	upgrade bool,
 ) (golxc.Container, error) {
 	name := fmt.Sprintf("juju-%s-template", series)
 	containerDirectory, err := container.NewDirectory(name)
@@ -117,7 +125,8 @@ func EnsureCloneTemplate(
 	}
 	logger.Infof("template does not exist, creating")
 
-	userData, err := templateUserData(series, authorizedKeys, aptProxy)
+	userData, err := templateUserData(
+		series, authorizedKeys, aptProxy, aptMirror, packageSources, upgrade)
 	if err != nil {
 		logger.Tracef("failed to create template user data for template: %v", err)
 		return nil, err
@@ -178,6 +178,9 @@ func (manager *containerManager) CreateContainer(
 			network,
 			machineConfig.AuthorizedKeys,
 			machineConfig.AptProxySettings,
+			machineConfig.AptMirror,
+			machineConfig.PackageSources,
+			!machineConfig.DisableOSUpgrade,
 		)
 		if err != nil {
 			return nil, nil, err
@@ -264,7 +264,7 @@ func (s *LxcSuite) createTemplate(c *gc.C) golxc.Container {
 	authorizedKeys := "authorized keys list"
 	aptProxy := proxy.Settings{}
 	template, err := lxc.EnsureCloneTemplate(
-		"ext4", "series", network, authorizedKeys, aptProxy)
+		"ext4", "series", network, authorizedKeys, aptProxy, "", nil, true)
 	c.Assert(err, gc.IsNil)
 	c.Assert(template.Name(), gc.Equals, name)
 	s.AssertEvent(c, <-s.events, mock.Created, name)
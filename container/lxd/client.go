@@ -0,0 +1,325 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package lxd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Container statuses, as reported by the LXD server.
+const (
+	StatusRunning = "Running"
+	StatusStopped = "Stopped"
+)
+
+// Operation status codes.
+const (
+	OperationSuccess   = 200
+	OperationFailure   = 400
+	OperationCancelled = 401
+)
+
+// Container describes an LXD container.
+type Container struct {
+	Name       string            `json:"name"`
+	Status     string            `json:"status"`
+	StatusCode int               `json:"status_code"`
+	Profiles   []string          `json:"profiles"`
+	Config     map[string]string `json:"config"`
+}
+
+// ContainerState holds the run-time state of a container.
+type ContainerState struct {
+	Status     string                      `json:"status"`
+	StatusCode int                         `json:"status_code"`
+	Network    map[string]ContainerNetwork `json:"network"`
+}
+
+// ContainerNetwork describes a network interface of a container.
+type ContainerNetwork struct {
+	Addresses []ContainerAddress `json:"addresses"`
+}
+
+// ContainerAddress describes an address of a container's network
+// interface.
+type ContainerAddress struct {
+	Family  string `json:"family"`
+	Address string `json:"address"`
+	Scope   string `json:"scope"`
+}
+
+// ContainerSource describes what a new container is created from:
+// either an image, identified by its alias, or an existing container
+// which is copied.
+type ContainerSource struct {
+	Type   string `json:"type"`
+	Alias  string `json:"alias,omitempty"`
+	Source string `json:"source,omitempty"`
+}
+
+// ContainerSpec holds the parameters of a new container.
+type ContainerSpec struct {
+	Name     string            `json:"name"`
+	Profiles []string          `json:"profiles,omitempty"`
+	Config   map[string]string `json:"config,omitempty"`
+	Source   ContainerSource   `json:"source"`
+}
+
+// Profile describes an LXD profile: a set of configuration and
+// devices that containers may be started with.
+type Profile struct {
+	Name        string                       `json:"name"`
+	Description string                       `json:"description,omitempty"`
+	Config      map[string]string            `json:"config,omitempty"`
+	Devices     map[string]map[string]string `json:"devices,omitempty"`
+}
+
+// ImageSource describes a remote image to be imported.
+type ImageSource struct {
+	Type     string `json:"type"`
+	Mode     string `json:"mode"`
+	Server   string `json:"server"`
+	Protocol string `json:"protocol"`
+	Alias    string `json:"alias"`
+}
+
+// ImageAlias describes an alias of an image.
+type ImageAlias struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Target      string `json:"target"`
+}
+
+// Operation describes an asynchronous operation of the LXD server.
+type Operation struct {
+	Id         string                 `json:"id"`
+	Status     string                 `json:"status"`
+	StatusCode int                    `json:"status_code"`
+	Metadata   map[string]interface{} `json:"metadata"`
+	Err        string                 `json:"err"`
+}
+
+// response is the envelope of all the LXD server's responses.
+type response struct {
+	Type       string          `json:"type"`
+	Status     string          `json:"status"`
+	StatusCode int             `json:"status_code"`
+	Operation  string          `json:"operation"`
+	ErrorCode  int             `json:"error_code"`
+	Error      string          `json:"error"`
+	Metadata   json.RawMessage `json:"metadata"`
+}
+
+// Error is the error returned by a failed LXD request.
+type Error struct {
+	Code    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("lxd error %d: %s", e.Code, e.Message)
+}
+
+// IsNotFound reports whether err reports that the requested
+// object does not exist.
+func IsNotFound(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e.Code == http.StatusNotFound
+}
+
+// Client talks to an LXD server through its REST API,
+// over the server's unix socket.
+type Client struct {
+	client *http.Client
+}
+
+// NewClient returns a client of the LXD server listening on the
+// unix socket with the given path.
+func NewClient(socketPath string) *Client {
+	transport := &http.Transport{
+		Dial: func(_, _ string) (net.Conn, error) {
+			return net.Dial("unix", socketPath)
+		},
+	}
+	return &Client{
+		client: &http.Client{Transport: transport},
+	}
+}
+
+// call makes a request of the server, encoding in as the body of the
+// request if it is not nil, and decoding the response's metadata into
+// out if it is not nil. The operations started by asynchronous
+// requests are waited for.
+func (c *Client) call(method, path string, in, out interface{}) error {
+	var body bytes.Buffer
+	if in != nil {
+		if err := json.NewEncoder(&body).Encode(in); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, "http://lxd"+path, &body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot connect to LXD: %v", err)
+	}
+	defer resp.Body.Close()
+	var r response
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return fmt.Errorf("cannot decode LXD response: %v", err)
+	}
+	switch r.Type {
+	case "error":
+		return &Error{Code: r.ErrorCode, Message: r.Error}
+	case "async":
+		op, err := c.wait(r.Operation)
+		if err != nil {
+			return err
+		}
+		if out != nil {
+			data, err := json.Marshal(op)
+			if err != nil {
+				return err
+			}
+			return json.Unmarshal(data, out)
+		}
+		return nil
+	}
+	if out != nil && len(r.Metadata) > 0 {
+		return json.Unmarshal(r.Metadata, out)
+	}
+	return nil
+}
+
+// wait waits for the operation with the given path to complete.
+func (c *Client) wait(path string) (*Operation, error) {
+	var op Operation
+	if err := c.call("GET", path+"/wait", nil, &op); err != nil {
+		return nil, err
+	}
+	if op.StatusCode != OperationSuccess {
+		return nil, fmt.Errorf("operation %s: %s", op.Status, op.Err)
+	}
+	return &op, nil
+}
+
+func containerPath(name string) string {
+	return "/1.0/containers/" + url.QueryEscape(name)
+}
+
+// Containers returns the names of all the server's containers.
+func (c *Client) Containers() ([]string, error) {
+	var paths []string
+	if err := c.call("GET", "/1.0/containers", nil, &paths); err != nil {
+		return nil, err
+	}
+	names := make([]string, len(paths))
+	for i, path := range paths {
+		names[i] = path[strings.LastIndex(path, "/")+1:]
+	}
+	return names, nil
+}
+
+// Container returns the container with the given name.
+func (c *Client) Container(name string) (*Container, error) {
+	var container Container
+	if err := c.call("GET", containerPath(name), nil, &container); err != nil {
+		return nil, err
+	}
+	return &container, nil
+}
+
+// ContainerState returns the run-time state of the container
+// with the given name.
+func (c *Client) ContainerState(name string) (*ContainerState, error) {
+	var state ContainerState
+	if err := c.call("GET", containerPath(name)+"/state", nil, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// CreateContainer creates a new container, and waits for it to be
+// created. The container is not started.
+func (c *Client) CreateContainer(spec ContainerSpec) error {
+	return c.call("POST", "/1.0/containers", spec, nil)
+}
+
+// StartContainer starts the container with the given name.
+func (c *Client) StartContainer(name string) error {
+	return c.setState(name, "start")
+}
+
+// StopContainer stops the container with the given name.
+func (c *Client) StopContainer(name string) error {
+	return c.setState(name, "stop")
+}
+
+func (c *Client) setState(name, action string) error {
+	req := struct {
+		Action  string `json:"action"`
+		Timeout int    `json:"timeout"`
+		Force   bool   `json:"force"`
+	}{action, 30, true}
+	return c.call("PUT", containerPath(name)+"/state", req, nil)
+}
+
+// DeleteContainer deletes the stopped container with the given name.
+func (c *Client) DeleteContainer(name string) error {
+	return c.call("DELETE", containerPath(name), nil, nil)
+}
+
+// ImageAlias returns the image alias with the given name.
+func (c *Client) ImageAlias(name string) (*ImageAlias, error) {
+	var alias ImageAlias
+	if err := c.call("GET", "/1.0/images/aliases/"+url.QueryEscape(name), nil, &alias); err != nil {
+		return nil, err
+	}
+	return &alias, nil
+}
+
+// ImportImage imports the image from the given source, and gives
+// it the given alias.
+func (c *Client) ImportImage(source ImageSource, alias string) error {
+	req := struct {
+		Source ImageSource `json:"source"`
+	}{source}
+	var op Operation
+	if err := c.call("POST", "/1.0/images", req, &op); err != nil {
+		return err
+	}
+	fingerprint, _ := op.Metadata["fingerprint"].(string)
+	if fingerprint == "" {
+		return fmt.Errorf("no fingerprint for image imported from %s", source.Server)
+	}
+	return c.call("POST", "/1.0/images/aliases", ImageAlias{
+		Name:   alias,
+		Target: fingerprint,
+	}, nil)
+}
+
+// Profile returns the profile with the given name.
+func (c *Client) Profile(name string) (*Profile, error) {
+	var profile Profile
+	if err := c.call("GET", "/1.0/profiles/"+url.QueryEscape(name), nil, &profile); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// CreateProfile creates the given profile.
+func (c *Client) CreateProfile(profile Profile) error {
+	return c.call("POST", "/1.0/profiles", profile, nil)
+}
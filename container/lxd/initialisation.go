@@ -0,0 +1,88 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package lxd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/juju/utils"
+	"github.com/juju/utils/apt"
+
+	"github.com/juju/juju/container"
+)
+
+var requiredPackages = []string{
+	"lxd",
+}
+
+type containerInitialiser struct {
+	series string
+}
+
+// containerInitialiser implements container.Initialiser.
+var _ container.Initialiser = (*containerInitialiser)(nil)
+
+// NewContainerInitialiser returns an instance used to perform the steps
+// required to allow a host machine to run an LXD container.
+func NewContainerInitialiser(series string) container.Initialiser {
+	return &containerInitialiser{series}
+}
+
+// Initialise is specified on the container.Initialiser interface.
+func (ci *containerInitialiser) Initialise() error {
+	if err := ensureDependencies(); err != nil {
+		return err
+	}
+	if ci.series == "" {
+		return nil
+	}
+	// Import the image for the host's series up front, so that
+	// the first container does not have to wait for it.
+	return ensureImage(NewClient(SocketPath), ci.series)
+}
+
+func ensureDependencies() error {
+	return apt.GetInstall(requiredPackages...)
+}
+
+// IsLXDSupported reports whether the LXD server is running on this
+// machine. It is a variable to allow us to override behaviour in the tests.
+var IsLXDSupported = func() (bool, error) {
+	if _, err := os.Stat(SocketPath); os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if _, err := NewClient(SocketPath).Containers(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+const lxdNeedsUbuntu = `Sorry, LXD support with the local provider is only supported
+on the Ubuntu OS.`
+
+const lxdNotRunning = `The LXD server is not running, or you do not have permission to use it.
+Make sure the lxd package is installed and that you are in the lxd group:
+
+    sudo apt-get install lxd
+    sudo adduser $USER lxd
+`
+
+// VerifyLXDEnabled makes sure that the host OS is Ubuntu, and that the
+// LXD server is installed and can be reached.
+func VerifyLXDEnabled() error {
+	if !utils.IsUbuntu() {
+		return fmt.Errorf(lxdNeedsUbuntu)
+	}
+	supported, err := IsLXDSupported()
+	if err != nil || !supported {
+		if err != nil {
+			logger.Debugf("cannot reach LXD: %v", err)
+		}
+		return fmt.Errorf(lxdNotRunning)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package lxd_test
+
+import (
+	"path/filepath"
+
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/apt"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/container/lxd"
+	lxdtesting "github.com/juju/juju/container/lxd/testing"
+)
+
+type InitialiserSuite struct {
+	lxdtesting.TestSuite
+}
+
+var _ = gc.Suite(&InitialiserSuite{})
+
+func (s *InitialiserSuite) TestInitialise(c *gc.C) {
+	cmdChan := s.HookCommandOutput(&apt.CommandOutput, []byte{}, nil)
+	err := lxd.NewContainerInitialiser("trusty").Initialise()
+	c.Assert(err, gc.IsNil)
+
+	cmd := <-cmdChan
+	c.Assert(cmd.Args, gc.DeepEquals, []string{
+		"apt-get", "--option=Dpkg::Options::=--force-confold",
+		"--option=Dpkg::options::=--force-unsafe-io", "--assume-yes", "--quiet",
+		"install", "lxd",
+	})
+	c.Assert(s.Server.ImportedImages(), gc.DeepEquals, []lxd.ImageSource{{
+		Type:     "image",
+		Mode:     "pull",
+		Server:   lxd.ImageServer,
+		Protocol: "simplestreams",
+		Alias:    "14.04",
+	}})
+	c.Assert(s.Server.ImageAliases(), gc.DeepEquals, []string{"ubuntu-trusty"})
+}
+
+func (s *InitialiserSuite) TestInitialiseExistingImage(c *gc.C) {
+	s.HookCommandOutput(&apt.CommandOutput, []byte{}, nil)
+	s.Server.AddImageAlias("ubuntu-trusty")
+	err := lxd.NewContainerInitialiser("trusty").Initialise()
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.Server.ImportedImages(), gc.HasLen, 0)
+}
+
+func (s *InitialiserSuite) TestInitialiseUnknownSeries(c *gc.C) {
+	s.HookCommandOutput(&apt.CommandOutput, []byte{}, nil)
+	err := lxd.NewContainerInitialiser("fnord").Initialise()
+	c.Assert(err, gc.ErrorMatches, `invalid series "fnord"`)
+	c.Assert(s.Server.ImportedImages(), gc.HasLen, 0)
+}
+
+func (s *InitialiserSuite) TestIsLXDSupported(c *gc.C) {
+	supported, err := lxd.IsLXDSupported()
+	c.Assert(err, gc.IsNil)
+	c.Assert(supported, jc.IsTrue)
+}
+
+func (s *InitialiserSuite) TestIsLXDSupportedNoServer(c *gc.C) {
+	s.PatchValue(&lxd.SocketPath, filepath.Join(c.MkDir(), "unix.socket"))
+	supported, err := lxd.IsLXDSupported()
+	c.Assert(err, gc.IsNil)
+	c.Assert(supported, jc.IsFalse)
+}
@@ -0,0 +1,76 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package lxd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/network"
+)
+
+type lxdInstance struct {
+	client *Client
+	id     string
+}
+
+var _ instance.Instance = (*lxdInstance)(nil)
+
+// Id implements instance.Instance.Id.
+func (lxd *lxdInstance) Id() instance.Id {
+	return instance.Id(lxd.id)
+}
+
+// Status implements instance.Instance.Status.
+func (lxd *lxdInstance) Status() string {
+	c, err := lxd.client.Container(lxd.id)
+	if err != nil {
+		return "unknown"
+	}
+	return strings.ToLower(c.Status)
+}
+
+func (*lxdInstance) Refresh() error {
+	return nil
+}
+
+// Addresses implements instance.Instance.Addresses, returning the
+// global addresses of the container's network interfaces.
+func (lxd *lxdInstance) Addresses() ([]network.Address, error) {
+	state, err := lxd.client.ContainerState(lxd.id)
+	if err != nil {
+		return nil, err
+	}
+	var addresses []network.Address
+	for _, nic := range state.Network {
+		for _, addr := range nic.Addresses {
+			if addr.Scope != "global" {
+				continue
+			}
+			addresses = append(addresses, network.NewAddress(addr.Address, network.ScopeCloudLocal))
+		}
+	}
+	return addresses, nil
+}
+
+// OpenPorts implements instance.Instance.OpenPorts.
+func (lxd *lxdInstance) OpenPorts(machineId string, ports []network.PortRange) error {
+	return fmt.Errorf("not implemented")
+}
+
+// ClosePorts implements instance.Instance.ClosePorts.
+func (lxd *lxdInstance) ClosePorts(machineId string, ports []network.PortRange) error {
+	return fmt.Errorf("not implemented")
+}
+
+// Ports implements instance.Instance.Ports.
+func (lxd *lxdInstance) Ports(machineId string) ([]network.PortRange, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// Add a string representation of the id.
+func (lxd *lxdInstance) String() string {
+	return fmt.Sprintf("lxd:%s", lxd.id)
+}
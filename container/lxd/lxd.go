@@ -0,0 +1,338 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package lxd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/container"
+	"github.com/juju/juju/environs/cloudinit"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/version"
+	"github.com/juju/juju/version/ubuntu"
+)
+
+var logger = loggo.GetLogger("juju.container.lxd")
+
+var (
+	// SocketPath holds the path of the unix socket on which the
+	// LXD server listens. It is a variable so that tests can point
+	// it at a fake server.
+	SocketPath = "/var/lib/lxd/unix.socket"
+
+	// ImageServer holds the simplestreams server from which
+	// images are imported.
+	ImageServer = "https://cloud-images.ubuntu.com/releases"
+
+	DefaultLxdBridge = "lxdbr0"
+)
+
+const (
+	// ConfigUseClone is the manager config key that determines whether
+	// containers are created as copy-on-write clones of a per-series
+	// template container, rather than directly from the series image.
+	// Clones are used unless it is set to "false".
+	ConfigUseClone = "use-clone"
+
+	// userDataKey is the container configuration key holding the
+	// cloud-init user data.
+	userDataKey = "user.user-data"
+)
+
+// imageAlias returns the alias of the local image used to create
+// containers of the given series.
+func imageAlias(series string) string {
+	return "ubuntu-" + series
+}
+
+// templateName returns the name of the stopped container that clones
+// of the given series are copied from.
+func templateName(series string) string {
+	return fmt.Sprintf("juju-%s-lxd-template", series)
+}
+
+// ensureImage imports the image for the given series from ImageServer,
+// unless the server already has an image with the series' alias.
+func ensureImage(client *Client, series string) error {
+	alias := imageAlias(series)
+	if _, err := client.ImageAlias(alias); err == nil {
+		return nil
+	} else if !IsNotFound(err) {
+		return err
+	}
+	seriesVersion, err := ubuntu.SeriesVersion(series)
+	if err != nil {
+		return err
+	}
+	logger.Infof("importing %s image from %s", series, ImageServer)
+	source := ImageSource{
+		Type:     "image",
+		Mode:     "pull",
+		Server:   ImageServer,
+		Protocol: "simplestreams",
+		Alias:    seriesVersion,
+	}
+	if err := client.ImportImage(source, alias); err != nil {
+		return fmt.Errorf("cannot import %s image: %v", series, err)
+	}
+	return nil
+}
+
+// NewContainerManager returns a manager object that can start and stop
+// lxd containers. The containers that are created are namespaced by the
+// name parameter.
+func NewContainerManager(conf container.ManagerConfig) (container.Manager, error) {
+	name := conf.PopValue(container.ConfigName)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	logDir := conf.PopValue(container.ConfigLogDir)
+	if logDir == "" {
+		logDir = agent.DefaultLogDir
+	}
+	useClone := conf.PopValue(ConfigUseClone) != "false"
+	conf.WarnAboutUnused()
+	return &containerManager{
+		name:     name,
+		logdir:   logDir,
+		useClone: useClone,
+		client:   NewClient(SocketPath),
+	}, nil
+}
+
+// containerManager handles all of the business logic at the juju specific
+// level. It makes sure that the images, templates and profiles the
+// containers need are in place, and passes the user-data to the
+// containers it creates.
+type containerManager struct {
+	name     string
+	logdir   string
+	useClone bool
+	client   *Client
+
+	// templateMutex serialises the creation of template containers.
+	templateMutex sync.Mutex
+}
+
+var _ container.Manager = (*containerManager)(nil)
+
+func (manager *containerManager) CreateContainer(
+	machineConfig *cloudinit.MachineConfig,
+	series string,
+	network *container.NetworkConfig) (instance.Instance, *instance.HardwareCharacteristics, error) {
+
+	name := names.NewMachineTag(machineConfig.MachineId).String()
+	if manager.name != "" {
+		name = fmt.Sprintf("%s-%s", manager.name, name)
+	}
+	// The user data is passed to the container through its
+	// configuration, but we keep it on disk too for debugging.
+	directory, err := container.NewDirectory(name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create container directory: %v", err)
+	}
+	logger.Tracef("write cloud-init")
+	userDataFilename, err := container.WriteUserData(machineConfig, directory)
+	if err != nil {
+		return nil, nil, errors.LoggedErrorf(logger, "failed to write user data: %v", err)
+	}
+	userData, err := ioutil.ReadFile(userDataFilename)
+	if err != nil {
+		return nil, nil, errors.LoggedErrorf(logger, "failed to read user data: %v", err)
+	}
+	profiles := []string{"default"}
+	if network != nil {
+		profile, err := manager.ensureNetworkProfile(network)
+		if err != nil {
+			return nil, nil, errors.LoggedErrorf(logger, "failed to create network profile: %v", err)
+		}
+		profiles = append(profiles, profile)
+	}
+	source, err := manager.containerSource(series)
+	if err != nil {
+		return nil, nil, errors.LoggedErrorf(logger, "failed to prepare %s container source: %v", series, err)
+	}
+	config, hardware := parseConstraints(machineConfig.Constraints)
+	config[userDataKey] = string(userData)
+	config["boot.autostart"] = "true"
+
+	logger.Tracef("create the container, constraints: %v", machineConfig.Constraints)
+	spec := ContainerSpec{
+		Name:     name,
+		Profiles: profiles,
+		Config:   config,
+		Source:   source,
+	}
+	if err := manager.client.CreateContainer(spec); err != nil {
+		return nil, nil, errors.LoggedErrorf(logger, "lxd container creation failed: %v", err)
+	}
+	if err := manager.client.StartContainer(name); err != nil {
+		return nil, nil, errors.LoggedErrorf(logger, "lxd container start failed: %v", err)
+	}
+	logger.Tracef("lxd container created")
+	return &lxdInstance{manager.client, name}, hardware, nil
+}
+
+// containerSource returns the source from which new containers of the
+// given series are created: a copy of the series' template container when
+// cloning, and the series' image otherwise.
+func (manager *containerManager) containerSource(series string) (ContainerSource, error) {
+	if !manager.useClone {
+		if err := ensureImage(manager.client, series); err != nil {
+			return ContainerSource{}, err
+		}
+		return ContainerSource{Type: "image", Alias: imageAlias(series)}, nil
+	}
+	manager.templateMutex.Lock()
+	defer manager.templateMutex.Unlock()
+	template := templateName(series)
+	if _, err := manager.client.Container(template); err == nil {
+		return ContainerSource{Type: "copy", Source: template}, nil
+	} else if !IsNotFound(err) {
+		return ContainerSource{}, err
+	}
+	if err := ensureImage(manager.client, series); err != nil {
+		return ContainerSource{}, err
+	}
+	// The template is never started, so copying it is a cheap
+	// copy-on-write snapshot on backing stores that support it.
+	logger.Infof("creating %s template container", series)
+	spec := ContainerSpec{
+		Name:   template,
+		Source: ContainerSource{Type: "image", Alias: imageAlias(series)},
+	}
+	if err := manager.client.CreateContainer(spec); err != nil {
+		return ContainerSource{}, fmt.Errorf("cannot create template: %v", err)
+	}
+	return ContainerSource{Type: "copy", Source: template}, nil
+}
+
+// ensureNetworkProfile ensures that there is a profile attaching a
+// container's eth0 to the given network, and returns its name.
+func (manager *containerManager) ensureNetworkProfile(network *container.NetworkConfig) (string, error) {
+	var nicType string
+	switch network.NetworkType {
+	case container.BridgeNetwork:
+		nicType = "bridged"
+	case container.PhysicalNetwork:
+		nicType = "physical"
+	default:
+		return "", fmt.Errorf("unknown network type %q", network.NetworkType)
+	}
+	device := network.Device
+	if device == "" {
+		device = DefaultLxdBridge
+	}
+	name := fmt.Sprintf("juju-%s-%s", nicType, device)
+	if _, err := manager.client.Profile(name); err == nil {
+		return name, nil
+	} else if !IsNotFound(err) {
+		return "", err
+	}
+	profile := Profile{
+		Name:        name,
+		Description: fmt.Sprintf("eth0 %s to %s", nicType, device),
+		Devices: map[string]map[string]string{
+			"eth0": {
+				"type":    "nic",
+				"nictype": nicType,
+				"parent":  device,
+				"name":    "eth0",
+			},
+		},
+	}
+	if err := manager.client.CreateProfile(profile); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+func (manager *containerManager) DestroyContainer(id instance.Id) error {
+	name := string(id)
+	c, err := manager.client.Container(name)
+	if err != nil {
+		logger.Errorf("failed to get lxd container: %v", err)
+		return err
+	}
+	if c.Status != StatusStopped {
+		if err := manager.client.StopContainer(name); err != nil {
+			logger.Errorf("failed to stop lxd container: %v", err)
+			return err
+		}
+	}
+	if err := manager.client.DeleteContainer(name); err != nil {
+		logger.Errorf("failed to delete lxd container: %v", err)
+		return err
+	}
+	return container.RemoveDirectory(name)
+}
+
+func (manager *containerManager) ListContainers() (result []instance.Instance, err error) {
+	containers, err := manager.client.Containers()
+	if err != nil {
+		logger.Errorf("failed getting all instances: %v", err)
+		return
+	}
+	managerPrefix := fmt.Sprintf("%s-", manager.name)
+	for _, name := range containers {
+		// Filter out those not starting with our name.
+		if !strings.HasPrefix(name, managerPrefix) {
+			continue
+		}
+		c, err := manager.client.Container(name)
+		if err != nil {
+			logger.Errorf("failed getting instance %q: %v", name, err)
+			return nil, err
+		}
+		if c.Status == StatusRunning {
+			result = append(result, &lxdInstance{manager.client, name})
+		}
+	}
+	return
+}
+
+// parseConstraints returns the container configuration limiting the
+// container to the given constraints, and the hardware characteristics
+// of a container so limited. Constraints that LXD cannot apply are
+// logged and ignored.
+func parseConstraints(cons constraints.Value) (map[string]string, *instance.HardwareCharacteristics) {
+	config := make(map[string]string)
+	arch := version.Current.Arch
+	hardware := &instance.HardwareCharacteristics{Arch: &arch}
+	if cons.Mem != nil {
+		mem := *cons.Mem
+		config["limits.memory"] = fmt.Sprintf("%dMB", mem)
+		hardware.Mem = &mem
+	}
+	if cons.CpuCores != nil {
+		cores := *cons.CpuCores
+		config["limits.cpu"] = fmt.Sprint(cores)
+		hardware.CpuCores = &cores
+	}
+	if cons.Arch != nil && *cons.Arch != arch {
+		logger.Infof("arch constraint of %q being ignored as not supported", *cons.Arch)
+	}
+	if cons.RootDisk != nil {
+		logger.Infof("root-disk constraint of %v being ignored as not supported", *cons.RootDisk)
+	}
+	if cons.CpuPower != nil {
+		logger.Infof("cpu-power constraint of %v being ignored as not supported", *cons.CpuPower)
+	}
+	if cons.Container != nil {
+		logger.Infof("container constraint of %q being ignored as not supported", *cons.Container)
+	}
+	if cons.Tags != nil {
+		logger.Infof("tags constraint of %q being ignored as not supported", strings.Join(*cons.Tags, ","))
+	}
+	return config, hardware
+}
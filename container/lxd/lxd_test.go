@@ -0,0 +1,231 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package lxd_test
+
+import (
+	"path/filepath"
+
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/container"
+	"github.com/juju/juju/container/lxd"
+	lxdtesting "github.com/juju/juju/container/lxd/testing"
+	containertesting "github.com/juju/juju/container/testing"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/tools"
+	"github.com/juju/juju/version"
+)
+
+type LXDSuite struct {
+	lxdtesting.TestSuite
+	manager container.Manager
+}
+
+var _ = gc.Suite(&LXDSuite{})
+
+func (s *LXDSuite) SetUpTest(c *gc.C) {
+	s.TestSuite.SetUpTest(c)
+	var err error
+	s.manager, err = lxd.NewContainerManager(container.ManagerConfig{container.ConfigName: "juju"})
+	c.Assert(err, gc.IsNil)
+	// The series used by containertesting.CreateContainer
+	// is not a real one, so its image cannot be imported.
+	s.Server.AddImageAlias("ubuntu-series")
+}
+
+func (*LXDSuite) TestManagerNameNeeded(c *gc.C) {
+	manager, err := lxd.NewContainerManager(container.ManagerConfig{container.ConfigName: ""})
+	c.Assert(err, gc.ErrorMatches, "name is required")
+	c.Assert(manager, gc.IsNil)
+}
+
+func (*LXDSuite) TestManagerWarnsAboutUnknownOption(c *gc.C) {
+	_, err := lxd.NewContainerManager(container.ManagerConfig{
+		container.ConfigName: "BillyBatson",
+		lxd.ConfigUseClone:   "false",
+		"shazam":             "Captain Marvel",
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(c.GetTestLog(), jc.Contains, `WARNING juju.container unused config option: "shazam" -> "Captain Marvel"`)
+	c.Assert(c.GetTestLog(), gc.Not(jc.Contains), `unused config option: "use-clone"`)
+}
+
+func (s *LXDSuite) TestListInitiallyEmpty(c *gc.C) {
+	containers, err := s.manager.ListContainers()
+	c.Assert(err, gc.IsNil)
+	c.Assert(containers, gc.HasLen, 0)
+}
+
+func (s *LXDSuite) TestCreateContainer(c *gc.C) {
+	inst := containertesting.CreateContainer(c, s.manager, "1/lxd/0")
+	name := string(inst.Id())
+	c.Assert(name, gc.Equals, "juju-machine-1-lxd-0")
+	c.Assert(inst.Status(), gc.Equals, "running")
+
+	// The container is a clone of the series' template,
+	// which is created from the series' image and never started.
+	template, source, ok := s.Server.Container("juju-series-lxd-template")
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(template.Status, gc.Equals, lxd.StatusStopped)
+	c.Assert(source, gc.Equals, lxd.ContainerSource{Type: "image", Alias: "ubuntu-series"})
+
+	created, source, ok := s.Server.Container(name)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(created.Status, gc.Equals, lxd.StatusRunning)
+	c.Assert(source, gc.Equals, lxd.ContainerSource{Type: "copy", Source: "juju-series-lxd-template"})
+	c.Assert(created.Config["user.user-data"], jc.HasPrefix, "#cloud-config\n")
+	c.Assert(created.Config["boot.autostart"], gc.Equals, "true")
+
+	// The cloud-init is also kept on disk.
+	cloudInitFilename := filepath.Join(s.ContainerDir, name, "cloud-init")
+	data := containertesting.AssertCloudInit(c, cloudInitFilename)
+	c.Assert(created.Config["user.user-data"], gc.Equals, string(data))
+}
+
+func (s *LXDSuite) TestCreateContainerBridgeProfile(c *gc.C) {
+	inst := containertesting.CreateContainer(c, s.manager, "1/lxd/0")
+	created, _, ok := s.Server.Container(string(inst.Id()))
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(created.Profiles, gc.DeepEquals, []string{"default", "juju-bridged-nic42"})
+
+	profile, ok := s.Server.Profile("juju-bridged-nic42")
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(profile.Devices, gc.DeepEquals, map[string]map[string]string{
+		"eth0": {
+			"type":    "nic",
+			"nictype": "bridged",
+			"parent":  "nic42",
+			"name":    "eth0",
+		},
+	})
+}
+
+func (s *LXDSuite) TestCreateContainerReusesTemplate(c *gc.C) {
+	containertesting.CreateContainer(c, s.manager, "1/lxd/0")
+	containertesting.CreateContainer(c, s.manager, "1/lxd/1")
+	c.Assert(s.Server.Containers(), gc.DeepEquals, []string{
+		"juju-machine-1-lxd-0",
+		"juju-machine-1-lxd-1",
+		"juju-series-lxd-template",
+	})
+}
+
+func (s *LXDSuite) TestCreateContainerWithoutClone(c *gc.C) {
+	manager, err := lxd.NewContainerManager(container.ManagerConfig{
+		container.ConfigName: "juju",
+		lxd.ConfigUseClone:   "false",
+	})
+	c.Assert(err, gc.IsNil)
+	inst := containertesting.CreateContainer(c, manager, "1/lxd/0")
+	_, source, ok := s.Server.Container(string(inst.Id()))
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(source, gc.Equals, lxd.ContainerSource{Type: "image", Alias: "ubuntu-series"})
+	c.Assert(s.Server.Containers(), gc.DeepEquals, []string{"juju-machine-1-lxd-0"})
+}
+
+func (s *LXDSuite) createContainer(c *gc.C, machineId, series, cons string) (instance.Instance, *instance.HardwareCharacteristics) {
+	stateInfo := jujutesting.FakeStateInfo(machineId)
+	apiInfo := jujutesting.FakeAPIInfo(machineId)
+	machineConfig := environs.NewMachineConfig(machineId, "fake-nonce", nil, stateInfo, apiInfo)
+	machineConfig.Tools = &tools.Tools{
+		Version: version.MustParseBinary("2.3.4-foo-bar"),
+		URL:     "http://tools.testing.invalid/2.3.4-foo-bar.tgz",
+	}
+	machineConfig.Constraints = constraints.MustParse(cons)
+	inst, hardware, err := s.manager.CreateContainer(machineConfig, series, nil)
+	c.Assert(err, gc.IsNil)
+	return inst, hardware
+}
+
+func (s *LXDSuite) TestCreateContainerImportsImage(c *gc.C) {
+	inst, _ := s.createContainer(c, "1/lxd/0", "trusty", "")
+	c.Assert(s.Server.ImportedImages(), gc.DeepEquals, []lxd.ImageSource{{
+		Type:     "image",
+		Mode:     "pull",
+		Server:   lxd.ImageServer,
+		Protocol: "simplestreams",
+		Alias:    "14.04",
+	}})
+	c.Assert(s.Server.ImageAliases(), gc.DeepEquals, []string{"ubuntu-series", "ubuntu-trusty"})
+
+	created, _, ok := s.Server.Container(string(inst.Id()))
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(created.Profiles, gc.DeepEquals, []string{"default"})
+}
+
+func (s *LXDSuite) TestCreateContainerConstraints(c *gc.C) {
+	inst, hardware := s.createContainer(c, "1/lxd/0", "series", "mem=1G cpu-cores=2 root-disk=4G")
+	created, _, ok := s.Server.Container(string(inst.Id()))
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(created.Config["limits.memory"], gc.Equals, "1024MB")
+	c.Assert(created.Config["limits.cpu"], gc.Equals, "2")
+	c.Assert(hardware.String(), gc.Equals, "arch="+version.Current.Arch+" cpu-cores=2 mem=1024M")
+}
+
+func (s *LXDSuite) TestCreateContainerNoConstraints(c *gc.C) {
+	inst, hardware := s.createContainer(c, "1/lxd/0", "series", "")
+	created, _, ok := s.Server.Container(string(inst.Id()))
+	c.Assert(ok, jc.IsTrue)
+	_, ok = created.Config["limits.memory"]
+	c.Assert(ok, jc.IsFalse)
+	_, ok = created.Config["limits.cpu"]
+	c.Assert(ok, jc.IsFalse)
+	c.Assert(hardware.String(), gc.Equals, "arch="+version.Current.Arch)
+}
+
+func (s *LXDSuite) TestAddresses(c *gc.C) {
+	inst := containertesting.CreateContainer(c, s.manager, "1/lxd/0")
+	addresses, err := inst.Addresses()
+	c.Assert(err, gc.IsNil)
+	c.Assert(addresses, gc.DeepEquals, []network.Address{
+		network.NewAddress("10.0.8.2", network.ScopeCloudLocal),
+	})
+}
+
+func (s *LXDSuite) TestListMatchesManagerName(c *gc.C) {
+	containertesting.CreateContainer(c, s.manager, "1/lxd/0")
+	containertesting.CreateContainer(c, s.manager, "1/lxd/1")
+	other, err := lxd.NewContainerManager(container.ManagerConfig{container.ConfigName: "other"})
+	c.Assert(err, gc.IsNil)
+	containertesting.CreateContainer(c, other, "1/lxd/2")
+
+	containers, err := s.manager.ListContainers()
+	c.Assert(err, gc.IsNil)
+	c.Assert(containers, gc.HasLen, 2)
+	expectedIds := []instance.Id{"juju-machine-1-lxd-0", "juju-machine-1-lxd-1"}
+	ids := []instance.Id{containers[0].Id(), containers[1].Id()}
+	c.Assert(ids, jc.SameContents, expectedIds)
+}
+
+func (s *LXDSuite) TestDestroyContainer(c *gc.C) {
+	inst := containertesting.CreateContainer(c, s.manager, "1/lxd/0")
+
+	err := s.manager.DestroyContainer(inst.Id())
+	c.Assert(err, gc.IsNil)
+
+	name := string(inst.Id())
+	_, _, ok := s.Server.Container(name)
+	c.Assert(ok, jc.IsFalse)
+	// The template is kept for the next container.
+	c.Assert(s.Server.Containers(), gc.DeepEquals, []string{"juju-series-lxd-template"})
+	// Check that the container dir is no longer in the container dir
+	c.Assert(filepath.Join(s.ContainerDir, name), jc.DoesNotExist)
+	// but instead, in the removed container dir
+	c.Assert(filepath.Join(s.RemovedDir, name), jc.IsDirectory)
+
+	containers, err := s.manager.ListContainers()
+	c.Assert(err, gc.IsNil)
+	c.Assert(containers, gc.HasLen, 0)
+}
+
+func (s *LXDSuite) TestDestroyUnknownContainer(c *gc.C) {
+	err := s.manager.DestroyContainer("juju-machine-1-lxd-0")
+	c.Assert(err, gc.ErrorMatches, `lxd error 404: container "juju-machine-1-lxd-0" not found`)
+	c.Assert(lxd.IsNotFound(err), jc.IsTrue)
+}
@@ -0,0 +1,443 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package lxdtest implements a fake LXD server, listening on a unix
+// socket, for testing the LXD container manager.
+package lxdtest
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/juju/juju/container/lxd"
+)
+
+// Server is a fake LXD server. Operations complete as soon as
+// they are started, and started containers are given an address
+// on the 10.0.8.0/24 network.
+type Server struct {
+	dir      string
+	listener net.Listener
+
+	mu         sync.Mutex
+	containers map[string]*container
+	aliases    map[string]string
+	profiles   map[string]lxd.Profile
+	imported   []lxd.ImageSource
+	operations map[string]lxd.Operation
+	nextId     int
+	nextAddr   int
+}
+
+type container struct {
+	lxd.Container
+	source  lxd.ContainerSource
+	address string
+}
+
+// NewServer starts a fake LXD server listening on a unix socket
+// in a new temporary directory.
+func NewServer() (*Server, error) {
+	dir, err := ioutil.TempDir("", "lxdtest")
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("unix", filepath.Join(dir, "unix.socket"))
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	srv := &Server{
+		dir:        dir,
+		listener:   listener,
+		containers: make(map[string]*container),
+		aliases:    make(map[string]string),
+		profiles: map[string]lxd.Profile{
+			"default": {Name: "default"},
+		},
+		operations: make(map[string]lxd.Operation),
+		nextAddr:   2,
+	}
+	go http.Serve(listener, srv)
+	return srv, nil
+}
+
+// SocketPath returns the path of the server's unix socket.
+func (srv *Server) SocketPath() string {
+	return filepath.Join(srv.dir, "unix.socket")
+}
+
+// Close shuts the server down.
+func (srv *Server) Close() {
+	srv.listener.Close()
+	os.RemoveAll(srv.dir)
+}
+
+// Containers returns the names of the server's containers, sorted.
+func (srv *Server) Containers() []string {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	var names []string
+	for name := range srv.containers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Container returns the container with the given name, and the
+// source it was created from.
+func (srv *Server) Container(name string) (lxd.Container, lxd.ContainerSource, bool) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	c, ok := srv.containers[name]
+	if !ok {
+		return lxd.Container{}, lxd.ContainerSource{}, false
+	}
+	return c.Container, c.source, true
+}
+
+// Profile returns the profile with the given name.
+func (srv *Server) Profile(name string) (lxd.Profile, bool) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	p, ok := srv.profiles[name]
+	return p, ok
+}
+
+// AddImageAlias adds an image with the given alias, as
+// if it had already been imported.
+func (srv *Server) AddImageAlias(alias string) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	srv.aliases[alias] = fingerprint(alias)
+}
+
+// ImageAliases returns the names of the server's image aliases, sorted.
+func (srv *Server) ImageAliases() []string {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	var names []string
+	for name := range srv.aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ImportedImages returns the sources of all images imported
+// by clients of the server.
+func (srv *Server) ImportedImages() []lxd.ImageSource {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return append([]lxd.ImageSource(nil), srv.imported...)
+}
+
+func fingerprint(s string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(s)))
+}
+
+type handlerFunc func(srv *Server, w http.ResponseWriter, req *http.Request, arg string)
+
+var handlers = []struct {
+	prefix  string
+	suffix  string
+	handler handlerFunc
+}{
+	{"/1.0/containers", "", (*Server).serveContainers},
+	{"/1.0/containers/", "/state", (*Server).serveContainerState},
+	{"/1.0/containers/", "", (*Server).serveContainer},
+	{"/1.0/images", "", (*Server).serveImages},
+	{"/1.0/images/aliases", "", (*Server).serveAliases},
+	{"/1.0/images/aliases/", "", (*Server).serveAlias},
+	{"/1.0/profiles", "", (*Server).serveProfiles},
+	{"/1.0/profiles/", "", (*Server).serveProfile},
+	{"/1.0/operations/", "/wait", (*Server).serveWait},
+}
+
+// ServeHTTP implements http.Handler.
+func (srv *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	path := req.URL.Path
+	for _, h := range handlers {
+		if !strings.HasPrefix(path, h.prefix) || !strings.HasSuffix(path, h.suffix) {
+			continue
+		}
+		arg := path[len(h.prefix) : len(path)-len(h.suffix)]
+		if strings.HasSuffix(h.prefix, "/") == (arg == "") || strings.Contains(arg, "/") {
+			continue
+		}
+		h.handler(srv, w, req, arg)
+		return
+	}
+	srv.error(w, http.StatusNotFound, "not found")
+}
+
+func (srv *Server) error(w http.ResponseWriter, code int, format string, args ...interface{}) {
+	srv.write(w, map[string]interface{}{
+		"type":       "error",
+		"error":      fmt.Sprintf(format, args...),
+		"error_code": code,
+	})
+}
+
+func (srv *Server) sync(w http.ResponseWriter, metadata interface{}) {
+	srv.write(w, map[string]interface{}{
+		"type":        "sync",
+		"status":      "Success",
+		"status_code": 200,
+		"metadata":    metadata,
+	})
+}
+
+// async records a successfully completed operation
+// and responds with its path.
+func (srv *Server) async(w http.ResponseWriter, metadata map[string]interface{}) {
+	srv.nextId++
+	id := fmt.Sprint(srv.nextId)
+	srv.operations[id] = lxd.Operation{
+		Id:         id,
+		Status:     "Success",
+		StatusCode: lxd.OperationSuccess,
+		Metadata:   metadata,
+	}
+	srv.write(w, map[string]interface{}{
+		"type":        "async",
+		"status":      "Operation created",
+		"status_code": 100,
+		"operation":   "/1.0/operations/" + id,
+	})
+}
+
+func (srv *Server) write(w http.ResponseWriter, r interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(r)
+}
+
+func (srv *Server) read(w http.ResponseWriter, req *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(req.Body).Decode(v); err != nil {
+		srv.error(w, http.StatusBadRequest, "bad request: %v", err)
+		return false
+	}
+	return true
+}
+
+func (srv *Server) serveContainers(w http.ResponseWriter, req *http.Request, _ string) {
+	switch req.Method {
+	case "GET":
+		paths := []string{}
+		for name := range srv.containers {
+			paths = append(paths, "/1.0/containers/"+name)
+		}
+		sort.Strings(paths)
+		srv.sync(w, paths)
+	case "POST":
+		var spec lxd.ContainerSpec
+		if !srv.read(w, req, &spec) {
+			return
+		}
+		if _, ok := srv.containers[spec.Name]; ok {
+			srv.error(w, http.StatusConflict, "container %q already exists", spec.Name)
+			return
+		}
+		switch spec.Source.Type {
+		case "image":
+			if _, ok := srv.aliases[spec.Source.Alias]; !ok {
+				srv.error(w, http.StatusNotFound, "image %q not found", spec.Source.Alias)
+				return
+			}
+		case "copy":
+			if _, ok := srv.containers[spec.Source.Source]; !ok {
+				srv.error(w, http.StatusNotFound, "container %q not found", spec.Source.Source)
+				return
+			}
+		default:
+			srv.error(w, http.StatusBadRequest, "unknown source type %q", spec.Source.Type)
+			return
+		}
+		for _, profile := range spec.Profiles {
+			if _, ok := srv.profiles[profile]; !ok {
+				srv.error(w, http.StatusNotFound, "profile %q not found", profile)
+				return
+			}
+		}
+		srv.containers[spec.Name] = &container{
+			Container: lxd.Container{
+				Name:       spec.Name,
+				Status:     lxd.StatusStopped,
+				StatusCode: 102,
+				Profiles:   spec.Profiles,
+				Config:     spec.Config,
+			},
+			source: spec.Source,
+		}
+		srv.async(w, nil)
+	default:
+		srv.error(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (srv *Server) serveContainer(w http.ResponseWriter, req *http.Request, name string) {
+	c, ok := srv.containers[name]
+	if !ok {
+		srv.error(w, http.StatusNotFound, "container %q not found", name)
+		return
+	}
+	switch req.Method {
+	case "GET":
+		srv.sync(w, c.Container)
+	case "DELETE":
+		if c.Status != lxd.StatusStopped {
+			srv.error(w, http.StatusBadRequest, "container %q is running", name)
+			return
+		}
+		delete(srv.containers, name)
+		srv.async(w, nil)
+	default:
+		srv.error(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (srv *Server) serveContainerState(w http.ResponseWriter, req *http.Request, name string) {
+	c, ok := srv.containers[name]
+	if !ok {
+		srv.error(w, http.StatusNotFound, "container %q not found", name)
+		return
+	}
+	switch req.Method {
+	case "GET":
+		state := lxd.ContainerState{
+			Status:     c.Status,
+			StatusCode: c.StatusCode,
+		}
+		if c.Status == lxd.StatusRunning {
+			state.Network = map[string]lxd.ContainerNetwork{
+				"lo": {Addresses: []lxd.ContainerAddress{
+					{Family: "inet", Address: "127.0.0.1", Scope: "local"},
+				}},
+				"eth0": {Addresses: []lxd.ContainerAddress{
+					{Family: "inet", Address: c.address, Scope: "global"},
+				}},
+			}
+		}
+		srv.sync(w, state)
+	case "PUT":
+		var action struct {
+			Action string `json:"action"`
+		}
+		if !srv.read(w, req, &action) {
+			return
+		}
+		switch action.Action {
+		case "start":
+			if c.Status == lxd.StatusRunning {
+				srv.error(w, http.StatusBadRequest, "container %q is already running", name)
+				return
+			}
+			c.Status, c.StatusCode = lxd.StatusRunning, 103
+			c.address = fmt.Sprintf("10.0.8.%d", srv.nextAddr)
+			srv.nextAddr++
+		case "stop":
+			if c.Status == lxd.StatusStopped {
+				srv.error(w, http.StatusBadRequest, "container %q is already stopped", name)
+				return
+			}
+			c.Status, c.StatusCode = lxd.StatusStopped, 102
+			c.address = ""
+		default:
+			srv.error(w, http.StatusBadRequest, "unknown action %q", action.Action)
+			return
+		}
+		srv.async(w, nil)
+	default:
+		srv.error(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (srv *Server) serveImages(w http.ResponseWriter, req *http.Request, _ string) {
+	if req.Method != "POST" {
+		srv.error(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var image struct {
+		Source lxd.ImageSource `json:"source"`
+	}
+	if !srv.read(w, req, &image) {
+		return
+	}
+	srv.imported = append(srv.imported, image.Source)
+	srv.async(w, map[string]interface{}{
+		"fingerprint": fingerprint(image.Source.Server + " " + image.Source.Alias),
+	})
+}
+
+func (srv *Server) serveAliases(w http.ResponseWriter, req *http.Request, _ string) {
+	if req.Method != "POST" {
+		srv.error(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var alias lxd.ImageAlias
+	if !srv.read(w, req, &alias) {
+		return
+	}
+	if _, ok := srv.aliases[alias.Name]; ok {
+		srv.error(w, http.StatusConflict, "alias %q already exists", alias.Name)
+		return
+	}
+	srv.aliases[alias.Name] = alias.Target
+	srv.sync(w, nil)
+}
+
+func (srv *Server) serveAlias(w http.ResponseWriter, req *http.Request, name string) {
+	target, ok := srv.aliases[name]
+	if !ok {
+		srv.error(w, http.StatusNotFound, "alias %q not found", name)
+		return
+	}
+	srv.sync(w, lxd.ImageAlias{Name: name, Target: target})
+}
+
+func (srv *Server) serveProfiles(w http.ResponseWriter, req *http.Request, _ string) {
+	if req.Method != "POST" {
+		srv.error(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var profile lxd.Profile
+	if !srv.read(w, req, &profile) {
+		return
+	}
+	if _, ok := srv.profiles[profile.Name]; ok {
+		srv.error(w, http.StatusConflict, "profile %q already exists", profile.Name)
+		return
+	}
+	srv.profiles[profile.Name] = profile
+	srv.sync(w, nil)
+}
+
+func (srv *Server) serveProfile(w http.ResponseWriter, req *http.Request, name string) {
+	profile, ok := srv.profiles[name]
+	if !ok {
+		srv.error(w, http.StatusNotFound, "profile %q not found", name)
+		return
+	}
+	srv.sync(w, profile)
+}
+
+func (srv *Server) serveWait(w http.ResponseWriter, req *http.Request, id string) {
+	op, ok := srv.operations[id]
+	if !ok {
+		srv.error(w, http.StatusNotFound, "operation %q not found", id)
+		return
+	}
+	srv.sync(w, op)
+}
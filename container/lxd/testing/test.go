@@ -0,0 +1,46 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Functions defined in this file should *ONLY* be used for testing.  These
+// functions are exported for testing purposes only, and shouldn't be called
+// from code that isn't in a test file.
+
+package testing
+
+import (
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/container"
+	"github.com/juju/juju/container/lxd"
+	"github.com/juju/juju/container/lxd/lxdtest"
+	"github.com/juju/juju/testing"
+)
+
+// TestSuite points the lxd package at a fake LXD server,
+// started afresh for each test.
+type TestSuite struct {
+	testing.BaseSuite
+	Server       *lxdtest.Server
+	ContainerDir string
+	RemovedDir   string
+}
+
+func (s *TestSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.ContainerDir = c.MkDir()
+	s.PatchValue(&container.ContainerDir, s.ContainerDir)
+	s.RemovedDir = c.MkDir()
+	s.PatchValue(&container.RemovedContainerDir, s.RemovedDir)
+	var err error
+	s.Server, err = lxdtest.NewServer()
+	c.Assert(err, gc.IsNil)
+	s.PatchValue(&lxd.SocketPath, s.Server.SocketPath())
+}
+
+func (s *TestSuite) TearDownTest(c *gc.C) {
+	if s.Server != nil {
+		s.Server.Close()
+		s.Server = nil
+	}
+	s.BaseSuite.TearDownTest(c)
+}
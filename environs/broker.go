@@ -8,6 +8,7 @@ import (
 	"github.com/juju/juju/environs/cloudinit"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/network"
+	"github.com/juju/juju/storage"
 	"github.com/juju/juju/tools"
 )
 
@@ -37,6 +38,18 @@ type StartInstanceParams struct {
 	// this information to distribute instances for
 	// high availability.
 	DistributionGroup func() ([]instance.Id, error)
+
+	// Volumes holds the parameters for volumes that are to be
+	// created along with the instance, and attached to it. They
+	// are only given for storage providers that cannot create
+	// volumes for running instances.
+	Volumes []storage.VolumeParams
+
+	// SubnetsToZones, if not empty, maps the provider ids of the
+	// subnets the instance may be started in, to satisfy a spaces
+	// constraint, to the availability zones those subnets are
+	// usable in.
+	SubnetsToZones map[network.Id][]string
 }
 
 // TODO(wallyworld) - we want this in the environs/instance package but import loops
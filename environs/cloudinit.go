@@ -78,6 +78,9 @@ func PopulateMachineConfig(mcfg *cloudinit.MachineConfig,
 	providerType, authorizedKeys string,
 	sslHostnameVerification bool,
 	proxySettings, aptProxySettings proxy.Settings,
+	aptMirror string,
+	packageSources []config.PackageSource,
+	enableOSUpgrade bool,
 ) error {
 	if authorizedKeys == "" {
 		return fmt.Errorf("environment configuration has no authorized-keys")
@@ -91,6 +94,9 @@ func PopulateMachineConfig(mcfg *cloudinit.MachineConfig,
 	mcfg.DisableSSLHostnameVerification = !sslHostnameVerification
 	mcfg.ProxySettings = proxySettings
 	mcfg.AptProxySettings = aptProxySettings
+	mcfg.AptMirror = aptMirror
+	mcfg.PackageSources = packageSources
+	mcfg.DisableOSUpgrade = !enableOSUpgrade
 	return nil
 }
 
@@ -114,9 +120,17 @@ func FinishMachineConfig(mcfg *cloudinit.MachineConfig, cfg *config.Config, cons
 		cfg.SSLHostnameVerification(),
 		cfg.ProxySettings(),
 		cfg.AptProxySettings(),
+		cfg.AptMirror(),
+		cfg.PackageSources(),
+		cfg.EnableOSUpgrade(),
 	); err != nil {
 		return err
 	}
+	// The agent must know which environment it belongs to, as
+	// the state server may host several.
+	if uuid, ok := cfg.UUID(); ok {
+		mcfg.AgentEnvironment[agent.EnvironUUID] = uuid
+	}
 
 	// The following settings are only appropriate at bootstrap time. At the
 	// moment, the only state server is the bootstrap node, but this
@@ -144,6 +144,18 @@ type MachineConfig struct {
 	// AptProxySettings define the http, https and ftp proxy settings to use
 	// for apt, which may or may not be the same as the normal ProxySettings.
 	AptProxySettings proxy.Settings
+
+	// AptMirror holds the archive mirror to install packages from,
+	// in place of the default Ubuntu archive. It may be empty.
+	AptMirror string
+
+	// PackageSources holds additional apt sources to add before
+	// packages are installed.
+	PackageSources []config.PackageSource
+
+	// DisableOSUpgrade is a flag that specifies whether to skip
+	// upgrading the installed packages when the machine boots.
+	DisableOSUpgrade bool
 }
 
 func base64yaml(m *config.Config) string {
@@ -201,11 +213,27 @@ func ConfigureBasic(cfg *MachineConfig, c *cloudinit.Config) error {
 
 // AddAptCommands update the cloudinit.Config instance with the necessary
 // packages, the request to do the apt-get update/upgrade on boot, and adds
-// the apt proxy settings if there are any.
-func AddAptCommands(proxySettings proxy.Settings, c *cloudinit.Config) {
+// the apt proxy settings, archive mirror and additional package sources
+// if there are any. The apt-get upgrade is skipped if upgrade is false.
+func AddAptCommands(
+	proxySettings proxy.Settings,
+	aptMirror string,
+	packageSources []config.PackageSource,
+	upgrade bool,
+	c *cloudinit.Config,
+) {
+	// Use the configured archive mirror and package sources
+	// when bringing packages up-to-date.
+	if aptMirror != "" {
+		c.SetAptMirror(aptMirror)
+	}
+	for _, source := range packageSources {
+		c.AddAptSource(source.Source, source.Key, nil)
+	}
+
 	// Bring packages up-to-date.
 	c.SetAptUpdate(true)
-	c.SetAptUpgrade(true)
+	c.SetAptUpgrade(upgrade)
 
 	// juju requires git for managing charm directories.
 	c.AddPackage("git")
@@ -249,7 +277,13 @@ func ConfigureJuju(cfg *MachineConfig, c *cloudinit.Config) error {
 	}
 
 	if !cfg.DisablePackageCommands {
-		AddAptCommands(cfg.AptProxySettings, c)
+		AddAptCommands(
+			cfg.AptProxySettings,
+			cfg.AptMirror,
+			cfg.PackageSources,
+			!cfg.DisableOSUpgrade,
+			c,
+		)
 	}
 
 	// Write out the normal proxy settings so that the settings are
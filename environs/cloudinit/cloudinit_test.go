@@ -838,6 +838,62 @@ func (s *cloudinitSuite) TestAptProxyWritten(c *gc.C) {
 	c.Assert(cmds, jc.DeepEquals, []interface{}{expected})
 }
 
+func (s *cloudinitSuite) TestAptMirrorAndPackageSources(c *gc.C) {
+	environConfig := minimalConfig(c)
+	machineCfg := s.createMachineConfig(c, environConfig)
+	cloudcfg := coreCloudinit.New()
+	err := cloudinit.Configure(machineCfg, cloudcfg)
+	c.Assert(err, gc.IsNil)
+	c.Assert(cloudcfg.AptMirror(), gc.Equals, "")
+	c.Assert(cloudcfg.AptSources(), gc.HasLen, 0)
+
+	environConfig, err = environConfig.Apply(map[string]interface{}{
+		"apt-mirror": "http://mirror.example.com/ubuntu",
+		"package-sources": []interface{}{
+			map[string]interface{}{"source": "ppa:juju/stable"},
+			map[string]interface{}{
+				"source": "deb http://example.com/ubuntu trusty main",
+				"key":    "some-key",
+			},
+		},
+	})
+	c.Assert(err, gc.IsNil)
+	machineCfg = s.createMachineConfig(c, environConfig)
+	cloudcfg = coreCloudinit.New()
+	err = cloudinit.Configure(machineCfg, cloudcfg)
+	c.Assert(err, gc.IsNil)
+
+	c.Assert(cloudcfg.AptMirror(), gc.Equals, "http://mirror.example.com/ubuntu")
+	c.Assert(cloudcfg.AptSources(), jc.DeepEquals, []*coreCloudinit.AptSource{
+		{Source: "ppa:juju/stable"},
+		{Source: "deb http://example.com/ubuntu trusty main", Key: "some-key"},
+	})
+	c.Assert(cloudcfg.AptUpdate(), jc.IsTrue)
+}
+
+func (s *cloudinitSuite) TestOSUpgradeDisabled(c *gc.C) {
+	environConfig := minimalConfig(c)
+	machineCfg := s.createMachineConfig(c, environConfig)
+	cloudcfg := coreCloudinit.New()
+	err := cloudinit.Configure(machineCfg, cloudcfg)
+	c.Assert(err, gc.IsNil)
+	c.Assert(cloudcfg.AptUpgrade(), jc.IsTrue)
+
+	environConfig, err = environConfig.Apply(map[string]interface{}{
+		"enable-os-upgrade": false,
+	})
+	c.Assert(err, gc.IsNil)
+	machineCfg = s.createMachineConfig(c, environConfig)
+	cloudcfg = coreCloudinit.New()
+	err = cloudinit.Configure(machineCfg, cloudcfg)
+	c.Assert(err, gc.IsNil)
+
+	// Packages are still updated and installed.
+	c.Assert(cloudcfg.AptUpgrade(), jc.IsFalse)
+	c.Assert(cloudcfg.AptUpdate(), jc.IsTrue)
+	c.Assert(cloudcfg.Packages(), gc.Not(gc.HasLen), 0)
+}
+
 func (s *cloudinitSuite) TestProxyWritten(c *gc.C) {
 	environConfig := minimalConfig(c)
 	environConfig, err := environConfig.Apply(map[string]interface{}{
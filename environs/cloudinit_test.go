@@ -93,6 +93,23 @@ func (s *CloudInitSuite) TestFinishMachineConfigNonDefault(c *gc.C) {
 	})
 }
 
+func (s *CloudInitSuite) TestFinishMachineConfigEnvironUUID(c *gc.C) {
+	attrs := dummySampleConfig().Merge(testing.Attrs{
+		"authorized-keys": "we-are-the-keys",
+		"uuid":            "f47ac10b-58cc-4372-a567-0e02b2c3d479",
+	})
+	cfg, err := config.New(config.NoDefaults, attrs)
+	c.Assert(err, gc.IsNil)
+	mcfg := &cloudinit.MachineConfig{}
+	err = environs.FinishMachineConfig(mcfg, cfg, constraints.Value{})
+	c.Assert(err, gc.IsNil)
+	c.Assert(mcfg.AgentEnvironment, gc.DeepEquals, map[string]string{
+		agent.ProviderType:  "dummy",
+		agent.ContainerType: "",
+		agent.EnvironUUID:   "f47ac10b-58cc-4372-a567-0e02b2c3d479",
+	})
+}
+
 func (s *CloudInitSuite) TestFinishBootstrapConfig(c *gc.C) {
 	attrs := dummySampleConfig().Merge(testing.Attrs{
 		"authorized-keys": "we-are-the-keys",
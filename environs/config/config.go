@@ -59,6 +59,10 @@ const (
 	// refresh addresses from the provider each time.
 	DefaultBootstrapSSHAddressesDelay int = 10
 
+	// DefaultUpdateStatusInterval is the amount of time between
+	// runs of each unit's update-status hook, in seconds.
+	DefaultUpdateStatusInterval int = 300
+
 	// fallbackLtsSeries is the latest LTS series we'll use, if we fail to
 	// obtain this information from the system.
 	fallbackLtsSeries string = "precise"
@@ -343,6 +347,22 @@ func Validate(cfg, old *Config) error {
 			" of key-value pairs, not %q", authToken)
 	}
 
+	if v, ok := cfg.defined["resource-tags"].(string); ok {
+		if _, err := parseResourceTags(v); err != nil {
+			return err
+		}
+	}
+
+	for i, source := range cfg.PackageSources() {
+		if strings.TrimSpace(source.Source) == "" {
+			return fmt.Errorf("empty source in package-sources entry %d", i)
+		}
+	}
+
+	if v, ok := cfg.defined["update-status-interval"].(int); ok && v < 0 {
+		return fmt.Errorf("invalid update-status-interval in environment configuration: %d", v)
+	}
+
 	// Check the immutable config values.  These can't change
 	if old != nil {
 		for _, attr := range immutableAttributes {
@@ -374,6 +394,9 @@ func isEmpty(val interface{}) bool {
 		return val == 0
 	case string:
 		return val == ""
+	case []interface{}:
+		// An empty list is a valid value for list attributes.
+		return false
 	}
 	panic(fmt.Errorf("unexpected type %T in configuration", val))
 }
@@ -596,6 +619,15 @@ func (c *Config) BootstrapSSHOpts() SSHTimeoutOpts {
 	return opts
 }
 
+// UpdateStatusInterval returns how often each unit's
+// update-status hook is run.
+func (c *Config) UpdateStatusInterval() time.Duration {
+	if v, ok := c.defined["update-status-interval"].(int); ok && v != 0 {
+		return time.Duration(v) * time.Second
+	}
+	return time.Duration(DefaultUpdateStatusInterval) * time.Second
+}
+
 // CACert returns the certificate of the CA that signed the state server
 // certificate, in PEM format, and whether the setting is available.
 func (c *Config) CACert() (string, bool) {
@@ -684,6 +716,105 @@ func (c *Config) CharmStoreAuth() (string, bool) {
 	return auth, auth != ""
 }
 
+// UUID returns the uuid for the environment, and whether
+// it has been set. Environments prepared by earlier versions
+// of juju do not have one.
+func (c *Config) UUID() (string, bool) {
+	value, ok := c.defined["uuid"].(string)
+	return value, ok
+}
+
+// ResourceTags returns the user-specified tags to apply to
+// the resources juju creates in the environment's cloud,
+// and whether any were specified.
+func (c *Config) ResourceTags() (map[string]string, bool) {
+	v, ok := c.defined["resource-tags"].(string)
+	if !ok {
+		return nil, false
+	}
+	// The value has been checked by Validate.
+	tags, _ := parseResourceTags(v)
+	return tags, true
+}
+
+// reservedTagPrefix is the prefix of the tags set by juju
+// itself, which may not be specified in resource-tags.
+const reservedTagPrefix = "juju-"
+
+// parseResourceTags parses a space-separated list
+// of key=value pairs, as found in resource-tags.
+func parseResourceTags(s string) (map[string]string, error) {
+	tags := make(map[string]string)
+	for _, pair := range strings.Fields(s) {
+		pos := strings.Index(pair, "=")
+		if pos <= 0 {
+			return nil, fmt.Errorf("invalid resource tag %q, expected key=value", pair)
+		}
+		key, value := pair[:pos], pair[pos+1:]
+		if strings.HasPrefix(key, reservedTagPrefix) {
+			return nil, fmt.Errorf("invalid resource tag %q, keys beginning with %q are reserved", pair, reservedTagPrefix)
+		}
+		tags[key] = value
+	}
+	return tags, nil
+}
+
+// AptMirror returns the archive mirror that machines in the
+// environment should install packages from, or the empty string
+// if they should use the default Ubuntu archive.
+func (c *Config) AptMirror() string {
+	return c.asString("apt-mirror")
+}
+
+// PackageSource describes an additional apt source that is
+// added to machines in the environment.
+type PackageSource struct {
+	// Source is the apt source line, such as
+	// "deb http://example.com/ubuntu trusty main",
+	// or a PPA such as "ppa:juju/stable".
+	Source string `json:"source" yaml:"source"`
+
+	// Key holds the ASCII-armoured public key that the
+	// source's packages are signed with. It may be empty.
+	Key string `json:"key,omitempty" yaml:"key,omitempty"`
+}
+
+// packageSourceChecker checks a single package-sources entry.
+var packageSourceChecker = schema.FieldMap(
+	schema.Fields{
+		"source": schema.String(),
+		"key":    schema.String(),
+	},
+	schema.Defaults{
+		"key": schema.Omit,
+	},
+)
+
+// PackageSources returns the additional apt sources
+// that are added to machines in the environment.
+func (c *Config) PackageSources() []PackageSource {
+	entries, _ := c.defined["package-sources"].([]interface{})
+	var sources []PackageSource
+	for _, entry := range entries {
+		// The entries have been coerced by packageSourceChecker.
+		attrs := entry.(map[string]interface{})
+		key, _ := attrs["key"].(string)
+		sources = append(sources, PackageSource{
+			Source: attrs["source"].(string),
+			Key:    key,
+		})
+	}
+	return sources
+}
+
+// EnableOSUpgrade reports whether machines in the environment
+// should upgrade their installed packages when they first boot.
+// It defaults to true.
+func (c *Config) EnableOSUpgrade() bool {
+	v, ok := c.defined["enable-os-upgrade"].(bool)
+	return !ok || v
+}
+
 // ProvisionerSafeMode reports whether the provisioner should not
 // destroy machines it does not know about.
 func (c *Config) ProvisionerSafeMode() bool {
@@ -797,10 +928,16 @@ var fields = schema.Fields{
 	"bootstrap-timeout":         schema.ForceInt(),
 	"bootstrap-retry-delay":     schema.ForceInt(),
 	"bootstrap-addresses-delay": schema.ForceInt(),
+	"update-status-interval":    schema.ForceInt(),
 	"test-mode":                 schema.Bool(),
 	"proxy-ssh":                 schema.Bool(),
 	"lxc-clone":                 schema.Bool(),
 	"lxc-clone-aufs":            schema.Bool(),
+	"uuid":                      schema.String(),
+	"resource-tags":             schema.String(),
+	"apt-mirror":                schema.String(),
+	"package-sources":           schema.List(packageSourceChecker),
+	"enable-os-upgrade":         schema.Bool(),
 
 	// Deprecated fields, retain for backwards compatibility.
 	"tools-url":     schema.String(),
@@ -827,6 +964,7 @@ var alwaysOptional = schema.Defaults{
 	"bootstrap-timeout":         schema.Omit,
 	"bootstrap-retry-delay":     schema.Omit,
 	"bootstrap-addresses-delay": schema.Omit,
+	"update-status-interval":    schema.Omit,
 	"rsyslog-ca-cert":           schema.Omit,
 	"http-proxy":                schema.Omit,
 	"https-proxy":               schema.Omit,
@@ -836,6 +974,11 @@ var alwaysOptional = schema.Defaults{
 	"apt-https-proxy":           schema.Omit,
 	"apt-ftp-proxy":             schema.Omit,
 	"lxc-clone":                 schema.Omit,
+	"uuid":                      schema.Omit,
+	"resource-tags":             schema.Omit,
+	"apt-mirror":                schema.Omit,
+	"package-sources":           schema.Omit,
+	"enable-os-upgrade":         schema.Omit,
 
 	// Deprecated fields, retain for backwards compatibility.
 	"tools-url":     "",
@@ -928,6 +1071,7 @@ var immutableAttributes = []string{
 	"lxc-clone",
 	"lxc-clone-aufs",
 	"syslog-port",
+	"uuid",
 }
 
 var (
@@ -592,6 +592,23 @@ var configTests = []configTest{
 			"bootstrap-addresses-delay": "illegal",
 		},
 		err: `bootstrap-addresses-delay: expected number, got string\("illegal"\)`,
+	}, {
+		about:       "Explicit update-status interval",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                   "my-type",
+			"name":                   "my-name",
+			"update-status-interval": 60,
+		},
+	}, {
+		about:       "Negative update-status interval",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                   "my-type",
+			"name":                   "my-name",
+			"update-status-interval": -1,
+		},
+		err: `invalid update-status-interval in environment configuration: -1`,
 	}, {
 		about:       "Invalid logging configuration",
 		useDefaults: config.UseDefaults,
@@ -666,6 +683,67 @@ var configTests = []configTest{
 			"name":      "my-name",
 			"test-mode": true,
 		},
+	}, {
+		about:       "Valid resource-tags",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":          "my-type",
+			"name":          "my-name",
+			"resource-tags": "cost-centre=1234 owner=finance",
+		},
+	}, {
+		about:       "Invalid resource-tags",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":          "my-type",
+			"name":          "my-name",
+			"resource-tags": "cost-centre",
+		},
+		err: `invalid resource tag "cost-centre", expected key=value`,
+	}, {
+		about:       "Reserved resource-tags",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":          "my-type",
+			"name":          "my-name",
+			"resource-tags": "juju-env-uuid=foo",
+		},
+		err: `invalid resource tag "juju-env-uuid=foo", keys beginning with "juju-" are reserved`,
+	}, {
+		about:       "Valid package-sources",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type": "my-type",
+			"name": "my-name",
+			"package-sources": []interface{}{
+				map[string]interface{}{"source": "ppa:juju/stable"},
+				map[string]interface{}{
+					"source": "deb http://example.com/ubuntu trusty main",
+					"key":    "some-key",
+				},
+			},
+		},
+	}, {
+		about:       "Invalid package-sources",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":            "my-type",
+			"name":            "my-name",
+			"package-sources": "ppa:juju/stable",
+		},
+		err: `package-sources: expected list, got string\("ppa:juju/stable"\)`,
+	}, {
+		about:       "Empty package-sources source",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type": "my-type",
+			"name": "my-name",
+			"package-sources": []interface{}{
+				map[string]interface{}{"source": "ppa:juju/stable"},
+				map[string]interface{}{"source": " ", "key": "some-key"},
+			},
+		},
+		err: `empty source in package-sources entry 1`,
 	},
 	authTokenConfigTest("token=value, tokensecret=value", true),
 	authTokenConfigTest("token=value, ", true),
@@ -974,6 +1052,12 @@ func (test configTest) check(c *gc.C, home *gitjujutesting.FakeHome) {
 		sshOpts.AddressesDelay,
 		config.DefaultBootstrapSSHAddressesDelay,
 	)
+	test.assertDuration(
+		c,
+		"update-status-interval",
+		cfg.UpdateStatusInterval(),
+		config.DefaultUpdateStatusInterval,
+	)
 
 	if v, ok := test.attrs["image-stream"]; ok {
 		c.Assert(cfg.ImageStream(), gc.Equals, v)
@@ -1171,6 +1255,11 @@ var validationTests = []validationTest{{
 	old:   testing.Attrs{"lxc-clone-aufs": false},
 	new:   testing.Attrs{"lxc-clone-aufs": true},
 	err:   `cannot change lxc-clone-aufs from false to true`,
+}, {
+	about: "Cannot change uuid",
+	old:   testing.Attrs{"uuid": "f47ac10b-58cc-4372-a567-0e02b2c3d479"},
+	new:   testing.Attrs{"uuid": "8ec6ad4b-2b4c-4d49-8f4b-6c0a1b6f3e21"},
+	err:   `cannot change uuid from "f47ac10b-58cc-4372-a567-0e02b2c3d479" to "8ec6ad4b-2b4c-4d49-8f4b-6c0a1b6f3e21"`,
 }}
 
 func (s *ConfigSuite) TestValidateChange(c *gc.C) {
@@ -1267,6 +1356,77 @@ func (s *ConfigSuite) TestLoggingConfigWithUnit(c *gc.C) {
 	c.Assert(config.LoggingConfig(), gc.Equals, "<root>=WARNING;unit=INFO")
 }
 
+func (s *ConfigSuite) TestResourceTags(c *gc.C) {
+	s.addJujuFiles(c)
+	config := newTestConfig(c, nil)
+	tags, ok := config.ResourceTags()
+	c.Assert(ok, jc.IsFalse)
+	c.Assert(tags, gc.IsNil)
+
+	config = newTestConfig(c, testing.Attrs{
+		"resource-tags": "cost-centre=1234  owner=finance empty="})
+	tags, ok = config.ResourceTags()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(tags, gc.DeepEquals, map[string]string{
+		"cost-centre": "1234",
+		"owner":       "finance",
+		"empty":       "",
+	})
+}
+
+func (s *ConfigSuite) TestAptMirror(c *gc.C) {
+	s.addJujuFiles(c)
+	config := newTestConfig(c, nil)
+	c.Assert(config.AptMirror(), gc.Equals, "")
+
+	config = newTestConfig(c, testing.Attrs{"apt-mirror": "http://mirror.example.com/ubuntu"})
+	c.Assert(config.AptMirror(), gc.Equals, "http://mirror.example.com/ubuntu")
+}
+
+func (s *ConfigSuite) TestPackageSources(c *gc.C) {
+	s.addJujuFiles(c)
+	cfg := newTestConfig(c, nil)
+	c.Assert(cfg.PackageSources(), gc.HasLen, 0)
+
+	cfg = newTestConfig(c, testing.Attrs{
+		"package-sources": []interface{}{
+			map[string]interface{}{"source": "ppa:juju/stable"},
+			map[string]interface{}{
+				"source": "deb http://example.com/ubuntu trusty main",
+				"key":    "some-key",
+			},
+		},
+	})
+	c.Assert(cfg.PackageSources(), gc.DeepEquals, []config.PackageSource{
+		{Source: "ppa:juju/stable"},
+		{Source: "deb http://example.com/ubuntu trusty main", Key: "some-key"},
+	})
+}
+
+func (s *ConfigSuite) TestEnableOSUpgrade(c *gc.C) {
+	s.addJujuFiles(c)
+	config := newTestConfig(c, nil)
+	c.Assert(config.EnableOSUpgrade(), jc.IsTrue)
+
+	config = newTestConfig(c, testing.Attrs{"enable-os-upgrade": false})
+	c.Assert(config.EnableOSUpgrade(), jc.IsFalse)
+
+	config = newTestConfig(c, testing.Attrs{"enable-os-upgrade": true})
+	c.Assert(config.EnableOSUpgrade(), jc.IsTrue)
+}
+
+func (s *ConfigSuite) TestUUID(c *gc.C) {
+	s.addJujuFiles(c)
+	config := newTestConfig(c, nil)
+	_, ok := config.UUID()
+	c.Assert(ok, jc.IsFalse)
+
+	config = newTestConfig(c, testing.Attrs{"uuid": "f47ac10b-58cc-4372-a567-0e02b2c3d479"})
+	uuid, ok := config.UUID()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(uuid, gc.Equals, "f47ac10b-58cc-4372-a567-0e02b2c3d479")
+}
+
 func (s *ConfigSuite) TestLoggingConfigFromEnvironment(c *gc.C) {
 	s.addJujuFiles(c)
 	s.PatchEnvironment(osenv.JujuLoggingConfigEnvKey, "<root>=INFO")
@@ -0,0 +1,62 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environs
+
+import (
+	"sort"
+
+	"github.com/juju/juju/environs/config"
+)
+
+// CredentialAttr describes a configuration attribute that holds
+// part of the credentials needed to use a provider's cloud.
+type CredentialAttr struct {
+	// Name is the name of the configuration attribute.
+	Name string
+
+	// Secret reports whether the attribute's value
+	// should not be displayed.
+	Secret bool
+}
+
+// CredentialDetector is implemented by providers that can find
+// the credentials for their cloud on the local machine. It is used
+// by "juju init --interactive" to generate environment configuration.
+type CredentialDetector interface {
+	// CredentialAttrs returns the configuration attributes that
+	// must be specified to use the provider, in the order in which
+	// they should be asked for.
+	CredentialAttrs() []CredentialAttr
+
+	// DetectCredentials returns the values of any of the attributes
+	// returned by CredentialAttrs that can be found on the local
+	// machine, such as in environment variables or well-known files.
+	// Credentials that cannot be found are not an error.
+	DetectCredentials() (map[string]interface{}, error)
+}
+
+// CredentialChecker is implemented by providers that can check that
+// their cloud accepts the credentials in an environment configuration
+// without changing anything in the cloud. It is used by "juju init
+// --interactive" to report bad credentials before bootstrap.
+type CredentialChecker interface {
+	// CheckCredentials makes a read-only request to the cloud using
+	// the credentials in the given configuration, which has been
+	// validated by the provider, and returns an error if the
+	// request fails.
+	CheckCredentials(cfg *config.Config) error
+}
+
+// CredentialDetectorTypes returns the types, in sorted order,
+// of the registered providers that implement CredentialDetector.
+func CredentialDetectorTypes() []string {
+	var types []string
+	for name, p := range providers {
+		if _, ok := p.(CredentialDetector); ok {
+			types = append(types, name)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
@@ -160,20 +160,23 @@ type Environ interface {
 	// same remote environment may become invalid
 	Destroy() error
 
-	// OpenPorts opens the given ports for the whole environment.
+	// OpenPorts opens the given port ranges for the whole environment.
 	// Must only be used if the environment was setup with the
-	// FwGlobal firewall mode.
-	OpenPorts(ports []network.Port) error
+	// FwGlobal firewall mode. A port range with a SourceCIDR is only
+	// opened to that CIDR; providers that cannot restrict access
+	// by source return an error satisfying errors.IsNotSupported.
+	OpenPorts(ports []network.PortRange) error
 
-	// ClosePorts closes the given ports for the whole environment.
+	// ClosePorts closes the given port ranges for the whole environment.
 	// Must only be used if the environment was setup with the
 	// FwGlobal firewall mode.
-	ClosePorts(ports []network.Port) error
+	ClosePorts(ports []network.PortRange) error
 
-	// Ports returns the ports opened for the whole environment.
+	// Ports returns the port ranges opened for the whole environment.
 	// Must only be used if the environment was setup with the
 	// FwGlobal firewall mode.
-	Ports() ([]network.Port, error)
+	// The port ranges are returned as sorted by SortPortRanges.
+	Ports() ([]network.PortRange, error)
 
 	// Provider returns the EnvironProvider that created this Environ.
 	Provider() EnvironProvider
@@ -181,6 +184,17 @@ type Environ interface {
 	state.Prechecker
 }
 
+// SubnetDiscoverer is an Environ that can report the subnets known to
+// the provider, so they can be added to spaces without the user having
+// to spell out their details.
+type SubnetDiscoverer interface {
+	Environ
+
+	// Subnets returns information about all subnets known by the
+	// provider for the environment.
+	Subnets() ([]network.SubnetInfo, error)
+}
+
 // BootstrapContext is an interface that is passed to
 // Environ.Bootstrap, providing a means of obtaining
 // information about and manipulating the context in which
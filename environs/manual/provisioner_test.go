@@ -163,3 +163,29 @@ func (s *provisionerSuite) TestProvisioningScript(c *gc.C) {
 	expectedScript := removeLogFile + shell.DumpFileOnErrorScript("/var/log/cloud-init-output.log") + sshinitScript
 	c.Assert(script, gc.Equals, expectedScript)
 }
+
+func (s *provisionerSuite) TestProvisioningScriptPackages(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"apt-mirror": "http://mirror.example.com/ubuntu",
+		"package-sources": []interface{}{
+			map[string]interface{}{"source": "ppa:juju/stable"},
+		},
+	}, nil, nil)
+	c.Assert(err, gc.IsNil)
+
+	defer fakeSSH{
+		Series:         "precise",
+		Arch:           "amd64",
+		InitUbuntuUser: true,
+	}.install(c).Restore()
+	machineId, err := manual.ProvisionMachine(s.getArgs(c))
+	c.Assert(err, gc.IsNil)
+
+	mcfg, err := client.MachineConfig(s.State, machineId, state.BootstrapNonce, "/var/lib/juju")
+	c.Assert(err, gc.IsNil)
+	script, err := manual.ProvisioningScript(mcfg)
+	c.Assert(err, gc.IsNil)
+	c.Assert(script, jc.Contains, ",http://mirror.example.com/ubuntu/,' /etc/apt/sources.list\n")
+	c.Assert(script, jc.Contains, "add-apt-repository -y 'ppa:juju/stable'\n")
+	c.Assert(script, gc.Not(jc.Contains), sshinit.Aptget+"upgrade")
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/juju/errors"
+	"github.com/juju/utils"
 
 	"github.com/juju/juju/cert"
 	"github.com/juju/juju/environs/config"
@@ -216,9 +217,28 @@ func prepare(ctx BootstrapContext, cfg *config.Config, info configstore.EnvironI
 	if err != nil {
 		return nil, fmt.Errorf("cannot ensure CA certificate: %v", err)
 	}
+	cfg, err = ensureUUID(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate environment UUID: %v", err)
+	}
 	return p.Prepare(ctx, cfg)
 }
 
+// ensureUUID returns a config with a uuid, which is used
+// to identify the environment's resources in its cloud.
+func ensureUUID(cfg *config.Config) (*config.Config, error) {
+	if _, ok := cfg.UUID(); ok {
+		return cfg, nil
+	}
+	uuid, err := utils.NewUUID()
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Apply(map[string]interface{}{
+		"uuid": uuid.String(),
+	})
+}
+
 // ensureAdminSecret returns a config with a non-empty admin-secret.
 func ensureAdminSecret(cfg *config.Config) (*config.Config, error) {
 	if cfg.AdminSecret() != "" {
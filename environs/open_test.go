@@ -204,6 +204,11 @@ func (*OpenSuite) TestPrepare(c *gc.C) {
 	c.Assert(err, gc.IsNil)
 	c.Assert(caCert.Subject.CommonName, gc.Equals, `juju-generated CA for environment "`+testing.SampleEnvName+`"`)
 
+	// Check that a UUID was generated.
+	uuid, ok := env.Config().UUID()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(uuid, gc.Matches, "[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}")
+
 	// Check we can call Prepare again.
 	env, err = environs.Prepare(cfg, ctx, store)
 	c.Assert(err, gc.IsNil)
@@ -0,0 +1,69 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package tags defines the tags that providers apply
+// to the resources they create in the environment's cloud,
+// so that the resources can be attributed to the environment
+// and the machine they were created for.
+package tags
+
+import (
+	"github.com/juju/juju/environs/cloudinit"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/state/api/params"
+)
+
+const (
+	// JujuEnv is the tag holding the UUID of the
+	// environment a resource was created for.
+	JujuEnv = "juju-env-uuid"
+
+	// JujuMachine is the tag holding the id of the
+	// machine an instance was started for.
+	JujuMachine = "juju-machine-id"
+
+	// JujuStateServer is the tag marking instances that
+	// run a state server. Its value is "true" or "false".
+	JujuStateServer = "juju-is-state"
+)
+
+// ResourceTags returns the tags to apply to all resources
+// created for the environment: the environment's resource-tags
+// and, if the environment has a UUID, the JujuEnv tag.
+func ResourceTags(cfg *config.Config) map[string]string {
+	tags, _ := cfg.ResourceTags()
+	if tags == nil {
+		tags = make(map[string]string)
+	}
+	if uuid, ok := cfg.UUID(); ok {
+		tags[JujuEnv] = uuid
+	}
+	return tags
+}
+
+// InstanceTags returns the tags to apply to the instance
+// started for the machine described by mcfg.
+func InstanceTags(cfg *config.Config, mcfg *cloudinit.MachineConfig) map[string]string {
+	tags := ResourceTags(cfg)
+	tags[JujuMachine] = mcfg.MachineId
+	tags[JujuStateServer] = "false"
+	for _, job := range mcfg.Jobs {
+		if job == params.JobManageEnviron {
+			tags[JujuStateServer] = "true"
+			break
+		}
+	}
+	return tags
+}
+
+// BelongsToEnviron reports whether a resource with the given
+// tags was created for the environment, and so may be destroyed
+// by it. Environments without a UUID predate tagging, so all
+// resources are considered theirs.
+func BelongsToEnviron(cfg *config.Config, tags map[string]string) bool {
+	uuid, ok := cfg.UUID()
+	if !ok {
+		return true
+	}
+	return tags[JujuEnv] == uuid
+}
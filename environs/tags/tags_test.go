@@ -0,0 +1,76 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package tags_test
+
+import (
+	stdtesting "testing"
+
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/environs/cloudinit"
+	"github.com/juju/juju/environs/tags"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/testing"
+)
+
+func Test(t *stdtesting.T) {
+	gc.TestingT(t)
+}
+
+type tagsSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&tagsSuite{})
+
+const testUUID = "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+
+func (s *tagsSuite) TestResourceTags(c *gc.C) {
+	cfg := testing.CustomEnvironConfig(c, testing.Attrs{
+		"uuid":          testUUID,
+		"resource-tags": "owner=finance",
+	})
+	c.Assert(tags.ResourceTags(cfg), gc.DeepEquals, map[string]string{
+		"owner":         "finance",
+		"juju-env-uuid": testUUID,
+	})
+}
+
+func (s *tagsSuite) TestResourceTagsWithoutUUID(c *gc.C) {
+	cfg := testing.EnvironConfig(c)
+	c.Assert(tags.ResourceTags(cfg), gc.DeepEquals, map[string]string{})
+}
+
+func (s *tagsSuite) TestInstanceTags(c *gc.C) {
+	cfg := testing.CustomEnvironConfig(c, testing.Attrs{"uuid": testUUID})
+	mcfg := &cloudinit.MachineConfig{
+		MachineId: "1",
+		Jobs:      []params.MachineJob{params.JobHostUnits},
+	}
+	c.Assert(tags.InstanceTags(cfg, mcfg), gc.DeepEquals, map[string]string{
+		"juju-env-uuid":   testUUID,
+		"juju-machine-id": "1",
+		"juju-is-state":   "false",
+	})
+
+	mcfg.MachineId = "0"
+	mcfg.Jobs = []params.MachineJob{params.JobManageEnviron, params.JobHostUnits}
+	c.Assert(tags.InstanceTags(cfg, mcfg), gc.DeepEquals, map[string]string{
+		"juju-env-uuid":   testUUID,
+		"juju-machine-id": "0",
+		"juju-is-state":   "true",
+	})
+}
+
+func (s *tagsSuite) TestBelongsToEnviron(c *gc.C) {
+	cfg := testing.CustomEnvironConfig(c, testing.Attrs{"uuid": testUUID})
+	c.Assert(tags.BelongsToEnviron(cfg, map[string]string{"juju-env-uuid": testUUID}), jc.IsTrue)
+	c.Assert(tags.BelongsToEnviron(cfg, map[string]string{"juju-env-uuid": "other"}), jc.IsFalse)
+	c.Assert(tags.BelongsToEnviron(cfg, nil), jc.IsFalse)
+
+	// Environments without a UUID predate tagging.
+	cfg = testing.EnvironConfig(c)
+	c.Assert(tags.BelongsToEnviron(cfg, nil), jc.IsTrue)
+}
@@ -32,18 +32,21 @@ type Instance interface {
 	// associated with the instance.
 	Addresses() ([]network.Address, error)
 
-	// OpenPorts opens the given ports on the instance, which
-	// should have been started with the given machine id.
-	OpenPorts(machineId string, ports []network.Port) error
+	// OpenPorts opens the given port ranges on the instance, which
+	// should have been started with the given machine id. A port
+	// range with a SourceCIDR is only opened to that CIDR; providers
+	// that cannot restrict access by source return an error
+	// satisfying errors.IsNotSupported.
+	OpenPorts(machineId string, ports []network.PortRange) error
 
-	// ClosePorts closes the given ports on the instance, which
+	// ClosePorts closes the given port ranges on the instance, which
 	// should have been started with the given machine id.
-	ClosePorts(machineId string, ports []network.Port) error
+	ClosePorts(machineId string, ports []network.PortRange) error
 
-	// Ports returns the set of ports open on the instance, which
+	// Ports returns the set of port ranges open on the instance, which
 	// should have been started with the given machine id.
-	// The ports are returned as sorted by SortPorts.
-	Ports(machineId string) ([]network.Port, error)
+	// The port ranges are returned as sorted by SortPortRanges.
+	Ports(machineId string) ([]network.PortRange, error)
 }
 
 // HardwareCharacteristics represents the characteristics of the instance (if known).
@@ -55,6 +58,10 @@ type HardwareCharacteristics struct {
 	CpuCores *uint64   `json:",omitempty" yaml:"cpucores,omitempty"`
 	CpuPower *uint64   `json:",omitempty" yaml:"cpupower,omitempty"`
 	Tags     *[]string `json:",omitempty" yaml:"tags,omitempty"`
+
+	// AvailabilityZone holds the name of the availability
+	// zone the instance was started in.
+	AvailabilityZone *string `json:",omitempty" yaml:"availabilityzone,omitempty"`
 }
 
 func uintStr(i uint64) string {
@@ -84,6 +91,9 @@ func (hc HardwareCharacteristics) String() string {
 	if hc.Tags != nil && len(*hc.Tags) > 0 {
 		strs = append(strs, fmt.Sprintf("tags=%s", strings.Join(*hc.Tags, ",")))
 	}
+	if hc.AvailabilityZone != nil && *hc.AvailabilityZone != "" {
+		strs = append(strs, fmt.Sprintf("availability-zone=%s", *hc.AvailabilityZone))
+	}
 	return strings.Join(strs, " ")
 }
 
@@ -147,6 +157,8 @@ func (hc *HardwareCharacteristics) setRaw(raw string) error {
 		err = hc.setRootDisk(str)
 	case "tags":
 		err = hc.setTags(str)
+	case "availability-zone":
+		err = hc.setAvailabilityZone(str)
 	default:
 		return fmt.Errorf("unknown characteristic %q", name)
 	}
@@ -207,6 +219,16 @@ func (hc *HardwareCharacteristics) setTags(str string) (err error) {
 	return
 }
 
+func (hc *HardwareCharacteristics) setAvailabilityZone(str string) error {
+	if hc.AvailabilityZone != nil {
+		return fmt.Errorf("already set")
+	}
+	if str != "" {
+		hc.AvailabilityZone = &str
+	}
+	return nil
+}
+
 // parseTags returns the tags in the value s
 func parseTags(s string) *[]string {
 	if s == "" {
@@ -217,13 +217,30 @@ var parseHardwareTests = []struct {
 		err:     `bad "root-disk" characteristic: already set`,
 	},
 
+	// "availability-zone" in detail.
+	{
+		summary: "set availability-zone empty",
+		args:    []string{"availability-zone="},
+	}, {
+		summary: "set availability-zone",
+		args:    []string{"availability-zone=us-east-1a"},
+	}, {
+		summary: "double set availability-zone together",
+		args:    []string{"availability-zone=a1 availability-zone=a2"},
+		err:     `bad "availability-zone" characteristic: already set`,
+	}, {
+		summary: "double set availability-zone separately",
+		args:    []string{"availability-zone=a1", "availability-zone=a2"},
+		err:     `bad "availability-zone" characteristic: already set`,
+	},
+
 	// Everything at once.
 	{
 		summary: "kitchen sink together",
 		args:    []string{" root-disk=4G mem=2T  arch=i386  cpu-cores=4096 cpu-power=9001"},
 	}, {
 		summary: "kitchen sink separately",
-		args:    []string{"root-disk=4G", "mem=2T", "cpu-cores=4096", "cpu-power=9001", "arch=armhf"},
+		args:    []string{"root-disk=4G", "mem=2T", "cpu-cores=4096", "cpu-power=9001", "arch=armhf", "availability-zone=a1"},
 	},
 }
 
@@ -17,7 +17,13 @@ import (
 	"github.com/juju/juju/environs/configstore"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state/api"
+	"github.com/juju/juju/state/api/auditlog"
+	"github.com/juju/juju/state/api/backups"
+	"github.com/juju/juju/state/api/debugrecordings"
+	"github.com/juju/juju/state/api/environmentmanager"
 	"github.com/juju/juju/state/api/keymanager"
+	"github.com/juju/juju/state/api/spaces"
+	"github.com/juju/juju/state/api/subnets"
 	"github.com/juju/juju/state/api/usermanager"
 )
 
@@ -108,6 +114,66 @@ func NewUserManagerClient(envName string) (*usermanager.Client, error) {
 	return usermanager.NewClient(st), nil
 }
 
+// NewBackupsClient returns a client for the Backups API facade of
+// the named environment.
+func NewBackupsClient(envName string) (*backups.Client, error) {
+	st, err := newAPIClient(envName)
+	if err != nil {
+		return nil, err
+	}
+	return backups.NewClient(st), nil
+}
+
+// NewAuditLogClient returns a client for the AuditLog API facade of
+// the named environment.
+func NewAuditLogClient(envName string) (*auditlog.Client, error) {
+	st, err := newAPIClient(envName)
+	if err != nil {
+		return nil, err
+	}
+	return auditlog.NewClient(st), nil
+}
+
+// NewDebugRecordingsClient returns a client for the DebugRecordings
+// API facade of the named environment.
+func NewDebugRecordingsClient(envName string) (*debugrecordings.Client, error) {
+	st, err := newAPIClient(envName)
+	if err != nil {
+		return nil, err
+	}
+	return debugrecordings.NewClient(st), nil
+}
+
+// NewEnvironmentManagerClient returns a client for the
+// EnvironmentManager API facade of the named environment.
+func NewEnvironmentManagerClient(envName string) (*environmentmanager.Client, error) {
+	st, err := newAPIClient(envName)
+	if err != nil {
+		return nil, err
+	}
+	return environmentmanager.NewClient(st), nil
+}
+
+// NewSpacesClient returns a client for the Spaces API facade of the
+// named environment.
+func NewSpacesClient(envName string) (*spaces.Client, error) {
+	st, err := newAPIClient(envName)
+	if err != nil {
+		return nil, err
+	}
+	return spaces.NewClient(st), nil
+}
+
+// NewSubnetsClient returns a client for the Subnets API facade of the
+// named environment.
+func NewSubnetsClient(envName string) (*subnets.Client, error) {
+	st, err := newAPIClient(envName)
+	if err != nil {
+		return nil, err
+	}
+	return subnets.NewClient(st), nil
+}
+
 // NewAPIFromName returns an api.State connected to the API Server for
 // the named environment. If envName is "", the default environment will
 // be used.
@@ -381,16 +447,7 @@ func cacheAPIInfo(info configstore.EnvironInfo, apiInfo *api.Info) (err error) {
 // with the provided API server addresses if they have changed. It will also
 // save the environment tag if it is available.
 func cacheChangedAPIInfo(info configstore.EnvironInfo, st apiState) error {
-	var addrs []string
-	for _, serverHostPorts := range st.APIHostPorts() {
-		for _, hostPort := range serverHostPorts {
-			// Only cache addresses that are likely to be usable,
-			// exclude IPv6 for now and localhost style ones.
-			if hostPort.Type != network.IPv6Address && hostPort.Scope != network.ScopeMachineLocal {
-				addrs = append(addrs, hostPort.NetAddr())
-			}
-		}
-	}
+	addrs := usableAPIAddresses(st.APIHostPorts())
 	endpoint := info.APIEndpoint()
 	newEnvironTag := st.EnvironTag()
 	changed := false
@@ -419,6 +476,22 @@ func cacheChangedAPIInfo(info configstore.EnvironInfo, st apiState) error {
 	return nil
 }
 
+// usableAPIAddresses returns the addresses of the given API servers
+// that are likely to be usable from a client machine.
+func usableAPIAddresses(hostPorts [][]network.HostPort) []string {
+	var addrs []string
+	for _, serverHostPorts := range hostPorts {
+		for _, hostPort := range serverHostPorts {
+			// Only cache addresses that are likely to be usable,
+			// exclude IPv6 for now and localhost style ones.
+			if hostPort.Type != network.IPv6Address && hostPort.Scope != network.ScopeMachineLocal {
+				addrs = append(addrs, hostPort.NetAddr())
+			}
+		}
+	}
+	return addrs
+}
+
 // addrsChanged returns true iff the two
 // slices are not equal. Order is important.
 func addrsChanged(a, b []string) bool {
@@ -0,0 +1,129 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package juju
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/environs/configstore"
+	"github.com/juju/juju/state/api"
+	"github.com/juju/juju/state/api/params"
+)
+
+// apiStore implements configstore.Storage by fetching
+// environment information from the environment's API server.
+type apiStore struct {
+	envName  string
+	endpoint configstore.APIEndpoint
+	creds    configstore.APICredentials
+}
+
+// NewAPIStore returns a configstore.Storage that holds the single
+// environment envName. Its information is fetched from the API
+// servers at the given endpoint, logging in with the given
+// credentials, so only the credentials and enough of the endpoint
+// to reach one API server need be known in advance. Changes to the
+// returned information are not persisted.
+func NewAPIStore(envName string, endpoint configstore.APIEndpoint, creds configstore.APICredentials) configstore.Storage {
+	return &apiStore{
+		envName:  envName,
+		endpoint: endpoint,
+		creds:    creds,
+	}
+}
+
+// CreateInfo implements configstore.Storage.CreateInfo.
+func (s *apiStore) CreateInfo(envName string) (configstore.EnvironInfo, error) {
+	return nil, errors.NotSupportedf("creating environment info from the API")
+}
+
+// List implements configstore.Storage.List.
+func (s *apiStore) List() ([]string, error) {
+	return []string{s.envName}, nil
+}
+
+// ReadInfo implements configstore.Storage.ReadInfo.
+func (s *apiStore) ReadInfo(envName string) (configstore.EnvironInfo, error) {
+	if envName != s.envName {
+		return nil, errors.NotFoundf("environment %q", envName)
+	}
+	connInfo, err := s.connectionInfo()
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot fetch information for environment %q", envName)
+	}
+	endpoint := ConnectionEndpoint(connInfo)
+	if len(endpoint.Addresses) == 0 {
+		// The server knows no usable addresses for itself,
+		// so the ones we reached it on are the best we have.
+		endpoint.Addresses = s.endpoint.Addresses
+	}
+	mem := configstore.NewMem()
+	info, err := mem.CreateInfo(envName)
+	if err != nil {
+		return nil, err
+	}
+	info.SetAPIEndpoint(endpoint)
+	info.SetAPICredentials(s.creds)
+	if err := info.Write(); err != nil {
+		return nil, err
+	}
+	// Read the information back so that it
+	// appears initialized, as if read from disk.
+	info, err = mem.ReadInfo(envName)
+	if err != nil {
+		return nil, err
+	}
+	return &apiStoreInfo{
+		EnvironInfo: info,
+		location:    fmt.Sprintf("API servers %v", endpoint.Addresses),
+	}, nil
+}
+
+// connectionInfo logs in to the store's
+// endpoint and returns its connection information.
+func (s *apiStore) connectionInfo() (params.ConnectionInfo, error) {
+	environTag := ""
+	if s.endpoint.EnvironUUID != "" {
+		environTag = names.NewEnvironTag(s.endpoint.EnvironUUID).String()
+	}
+	st, err := api.Open(&api.Info{
+		Addrs:      s.endpoint.Addresses,
+		CACert:     s.endpoint.CACert,
+		Tag:        names.NewUserTag(s.creds.User).String(),
+		Password:   s.creds.Password,
+		EnvironTag: environTag,
+	}, api.DefaultDialOpts())
+	if err != nil {
+		return params.ConnectionInfo{}, err
+	}
+	defer st.Close()
+	return st.Client().ConnectionInfo()
+}
+
+// apiStoreInfo is the configstore.EnvironInfo
+// returned by apiStore.ReadInfo.
+type apiStoreInfo struct {
+	configstore.EnvironInfo
+	location string
+}
+
+// Location implements configstore.EnvironInfo.Location.
+func (info *apiStoreInfo) Location() string {
+	return info.location
+}
+
+// ConnectionEndpoint returns the API endpoint described by the given
+// connection information, as returned by the Client's ConnectionInfo
+// call. Only addresses likely to be usable from a client machine
+// are included.
+func ConnectionEndpoint(info params.ConnectionInfo) configstore.APIEndpoint {
+	return configstore.APIEndpoint{
+		Addresses:   usableAPIAddresses(info.Servers),
+		CACert:      info.CACert,
+		EnvironUUID: info.EnvironUUID,
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package juju_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/environs/configstore"
+	"github.com/juju/juju/juju"
+	"github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/state/api"
+)
+
+type apiStoreSuite struct {
+	testing.JujuConnSuite
+}
+
+var _ = gc.Suite(&apiStoreSuite{})
+
+func (s *apiStoreSuite) newStore(c *gc.C, password string) configstore.Storage {
+	apiInfo := s.APIInfo(c)
+	return juju.NewAPIStore("shared", configstore.APIEndpoint{
+		Addresses: apiInfo.Addrs,
+		CACert:    apiInfo.CACert,
+	}, configstore.APICredentials{
+		User:     "admin",
+		Password: password,
+	})
+}
+
+func (s *apiStoreSuite) TestReadInfo(c *gc.C) {
+	store := s.newStore(c, "dummy-secret")
+	info, err := store.ReadInfo("shared")
+	c.Assert(err, gc.IsNil)
+
+	env, err := s.State.Environment()
+	c.Assert(err, gc.IsNil)
+	apiInfo := s.APIInfo(c)
+	endpoint := info.APIEndpoint()
+	// The dummy environment records no API server addresses,
+	// so the store falls back to the ones it connected to.
+	c.Assert(endpoint.Addresses, gc.DeepEquals, apiInfo.Addrs)
+	c.Assert(endpoint.CACert, gc.Equals, apiInfo.CACert)
+	c.Assert(endpoint.EnvironUUID, gc.Equals, env.UUID())
+	c.Assert(info.APICredentials(), gc.Equals, configstore.APICredentials{
+		User:     "admin",
+		Password: "dummy-secret",
+	})
+	c.Assert(info.BootstrapConfig(), gc.HasLen, 0)
+	c.Assert(info.Initialized(), jc.IsTrue)
+	c.Assert(info.Location(), gc.Matches, `API servers \[.*\]`)
+}
+
+func (s *apiStoreSuite) TestReadInfoUsesServerAddresses(c *gc.C) {
+	hostPorts := [][]network.HostPort{
+		network.AddressesWithPort([]network.Address{{
+			Value: "0.1.2.3",
+			Type:  network.IPv4Address,
+			Scope: network.ScopePublic,
+		}, {
+			Value: "::1",
+			Type:  network.IPv6Address,
+			Scope: network.ScopeMachineLocal,
+		}}, 1234),
+	}
+	err := s.State.SetAPIHostPorts(hostPorts)
+	c.Assert(err, gc.IsNil)
+
+	store := s.newStore(c, "dummy-secret")
+	info, err := store.ReadInfo("shared")
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.APIEndpoint().Addresses, gc.DeepEquals, []string{"0.1.2.3:1234"})
+}
+
+func (s *apiStoreSuite) TestReadInfoBadPassword(c *gc.C) {
+	store := s.newStore(c, "wrong")
+	_, err := store.ReadInfo("shared")
+	c.Assert(err, gc.ErrorMatches, `cannot fetch information for environment "shared": invalid entity name or password`)
+}
+
+func (s *apiStoreSuite) TestReadInfoNotFound(c *gc.C) {
+	store := s.newStore(c, "dummy-secret")
+	_, err := store.ReadInfo("other")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *apiStoreSuite) TestListAndCreateInfo(c *gc.C) {
+	store := s.newStore(c, "dummy-secret")
+	names, err := store.List()
+	c.Assert(err, gc.IsNil)
+	c.Assert(names, gc.DeepEquals, []string{"shared"})
+	_, err = store.CreateInfo("shared")
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *apiStoreSuite) TestNewAPIFromStore(c *gc.C) {
+	store := s.newStore(c, "dummy-secret")
+	apiOpen := func(info *api.Info, opts api.DialOpts) (juju.APIState, error) {
+		return api.Open(info, opts)
+	}
+	st, err := juju.NewAPIFromStore("shared", store, apiOpen)
+	c.Assert(err, gc.IsNil)
+	st.Close()
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/juju/juju/mongo"
 	"github.com/juju/juju/provider/dummy"
 	"github.com/juju/juju/state"
+	"github.com/juju/juju/storage"
 	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/testing/factory"
 )
@@ -579,6 +580,46 @@ func (s *DeployLocalSuite) TestDeployNumUnits(c *gc.C) {
 	s.assertMachines(c, service, constraints.MustParse("mem=2G cpu-cores=2"), "0", "1")
 }
 
+func (s *DeployLocalSuite) TestDeployStorage(c *gc.C) {
+	service, err := juju.DeployService(s.State,
+		juju.DeployServiceParams{
+			ServiceName: "bob",
+			Charm:       s.charm,
+			NumUnits:    1,
+			Storage: map[string]storage.Directive{
+				"data": {Size: 1024, Count: 1},
+			},
+		})
+	c.Assert(err, gc.IsNil)
+	cons, err := service.StorageConstraints()
+	c.Assert(err, gc.IsNil)
+	c.Assert(cons, gc.DeepEquals, map[string]storage.Directive{
+		"data": {Pool: "loop", Size: 1024, Count: 1},
+	})
+	units, err := service.AllUnits()
+	c.Assert(err, gc.IsNil)
+	c.Assert(units, gc.HasLen, 1)
+	instances, err := units[0].StorageInstances()
+	c.Assert(err, gc.IsNil)
+	c.Assert(instances, gc.HasLen, 1)
+	c.Assert(instances[0].Id(), gc.Equals, "data/0")
+}
+
+func (s *DeployLocalSuite) TestDeployEndpointBindings(c *gc.C) {
+	_, err := s.State.AddSpace("internal", nil)
+	c.Assert(err, gc.IsNil)
+	service, err := juju.DeployService(s.State,
+		juju.DeployServiceParams{
+			ServiceName:      "bob",
+			Charm:            s.charm,
+			EndpointBindings: map[string]string{"juju-info": "internal"},
+		})
+	c.Assert(err, gc.IsNil)
+	err = service.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(service.EndpointBindings(), gc.DeepEquals, map[string]string{"juju-info": "internal"})
+}
+
 func (s *DeployLocalSuite) TestDeployWithForceMachineRejectsTooManyUnits(c *gc.C) {
 	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
 	c.Assert(err, gc.IsNil)
@@ -647,6 +688,53 @@ func (s *DeployLocalSuite) TestDeployForceMachineIdWithContainer(c *gc.C) {
 	c.Assert(machineCons, gc.DeepEquals, *unitCons)
 }
 
+func (s *DeployLocalSuite) TestDeployPlacementDirective(c *gc.C) {
+	serviceCons := constraints.MustParse("cpu-cores=2")
+	service, err := juju.DeployService(s.State,
+		juju.DeployServiceParams{
+			ServiceName:   "bob",
+			Charm:         s.charm,
+			Constraints:   serviceCons,
+			NumUnits:      1,
+			ToMachineSpec: "zone=zone1",
+		})
+	c.Assert(err, gc.IsNil)
+	s.assertMachines(c, service, serviceCons, "0")
+	machine, err := s.State.Machine("0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(machine.Placement(), gc.Equals, "zone=zone1")
+}
+
+func (s *DeployLocalSuite) TestDeployInvalidPlacementDirective(c *gc.C) {
+	_, err := juju.DeployService(s.State,
+		juju.DeployServiceParams{
+			ServiceName:   "bob",
+			Charm:         s.charm,
+			NumUnits:      1,
+			ToMachineSpec: "zone=zone3",
+		})
+	c.Assert(err, gc.ErrorMatches, `cannot assign unit "bob/0" to new machine: .*zone=zone3 placement is invalid`)
+}
+
+func (s *DeployLocalSuite) TestIsPlacementDirective(c *gc.C) {
+	for i, test := range []struct {
+		spec   string
+		expect bool
+	}{
+		{"zone=us-east-1c", true},
+		{"zone=", true},
+		{"0", false},
+		{"lxc:0", false},
+		{"0/lxc/1", false},
+		{"=zone", false},
+		{"lxc:zone=a", false},
+		{"", false},
+	} {
+		c.Logf("test %d: %q", i, test.spec)
+		c.Check(juju.IsPlacementDirective(test.spec), gc.Equals, test.expect)
+	}
+}
+
 func (s *DeployLocalSuite) assertCharm(c *gc.C, service *state.Service, expect *charm.URL) {
 	curl, force := service.CharmURL()
 	c.Assert(curl, gc.DeepEquals, expect)
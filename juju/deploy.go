@@ -14,6 +14,7 @@ import (
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/state"
+	"github.com/juju/juju/storage"
 )
 
 // DeployServiceParams contains the arguments required to deploy the referenced
@@ -28,10 +29,17 @@ type DeployServiceParams struct {
 	// ToMachineSpec is either:
 	// - an existing machine/container id eg "1" or "1/lxc/2"
 	// - a new container on an existing machine eg "lxc:1"
+	// - a placement directive for a new machine eg "zone=us-east-1c"
 	// Use string to avoid ambiguity around machine 0.
 	ToMachineSpec string
 	// Networks holds a list of networks to required to start on boot.
 	Networks []string
+	// Storage holds the storage required by each unit, keyed by
+	// store name.
+	Storage map[string]storage.Directive
+	// EndpointBindings maps relation endpoint names to the names
+	// of the spaces they are bound to.
+	EndpointBindings map[string]string
 }
 
 // DeployService takes a charm and various parameters and deploys it.
@@ -50,6 +58,9 @@ func DeployService(st *state.State, args DeployServiceParams) (*state.Service, e
 		if !constraints.IsEmpty(&args.Constraints) {
 			return nil, fmt.Errorf("subordinate service must be deployed without constraints")
 		}
+		if len(args.Storage) > 0 {
+			return nil, fmt.Errorf("subordinate service must be deployed without storage")
+		}
 	}
 	if args.ServiceOwner == "" {
 		args.ServiceOwner = "user-admin"
@@ -83,6 +94,11 @@ func DeployService(st *state.State, args DeployServiceParams) (*state.Service, e
 			return nil, err
 		}
 	}
+	if len(args.EndpointBindings) > 0 {
+		if err := service.SetEndpointBindings(args.EndpointBindings); err != nil {
+			return nil, err
+		}
+	}
 	if args.Charm.Meta().Subordinate {
 		return service, nil
 	}
@@ -91,6 +107,11 @@ func DeployService(st *state.State, args DeployServiceParams) (*state.Service, e
 			return nil, err
 		}
 	}
+	if len(args.Storage) > 0 {
+		if err := service.SetStorageConstraints(args.Storage); err != nil {
+			return nil, err
+		}
+	}
 	if args.NumUnits > 0 {
 		if _, err := AddUnits(st, service, args.NumUnits, args.ToMachineSpec); err != nil {
 			return nil, err
@@ -102,6 +123,13 @@ func DeployService(st *state.State, args DeployServiceParams) (*state.Service, e
 // AddUnits starts n units of the given service and allocates machines
 // to them as necessary.
 func AddUnits(st *state.State, svc *state.Service, n int, machineIdSpec string) ([]*state.Unit, error) {
+	return AddUnitsWithStorage(st, svc, n, machineIdSpec, nil)
+}
+
+// AddUnitsWithStorage starts n units of the given service, with the
+// given storage in place of the storage required by the service,
+// and allocates machines to them as necessary.
+func AddUnitsWithStorage(st *state.State, svc *state.Service, n int, machineIdSpec string, storageCons map[string]storage.Directive) ([]*state.Unit, error) {
 	units := make([]*state.Unit, n)
 	// Hard code for now till we implement a different approach.
 	policy := state.AssignCleanEmpty
@@ -112,11 +140,15 @@ func AddUnits(st *state.State, svc *state.Service, n int, machineIdSpec string)
 	}
 	// TODO what do we do if we fail half-way through this process?
 	for i := 0; i < n; i++ {
-		unit, err := svc.AddUnit()
+		unit, err := svc.AddUnitWithStorage(storageCons)
 		if err != nil {
 			return nil, fmt.Errorf("cannot add unit %d/%d to service %q: %v", i+1, n, svc.Name(), err)
 		}
-		if machineIdSpec != "" {
+		if IsPlacementDirective(machineIdSpec) {
+			if err := assignToNewMachine(st, unit, machineIdSpec, networks); err != nil {
+				return nil, err
+			}
+		} else if machineIdSpec != "" {
 			if n != 1 {
 				return nil, fmt.Errorf("cannot add multiple units of service %q to a single machine", svc.Name())
 			}
@@ -175,3 +207,35 @@ func AddUnits(st *state.State, svc *state.Service, n int, machineIdSpec string)
 	}
 	return units, nil
 }
+
+// IsPlacementDirective reports whether the given machine specification
+// is an environment-specific placement directive, such as
+// "zone=us-east-1c", to be used when provisioning a new machine.
+func IsPlacementDirective(machineIdSpec string) bool {
+	eq := strings.Index(machineIdSpec, "=")
+	return eq > 0 && !strings.ContainsAny(machineIdSpec[:eq], ":/")
+}
+
+// assignToNewMachine assigns the unit to a new machine,
+// to be provisioned according to the placement directive.
+func assignToNewMachine(st *state.State, unit *state.Unit, placement string, networks []string) error {
+	unitCons, err := unit.Constraints()
+	if err != nil {
+		return err
+	}
+	// Create the new machine marked as dirty so that
+	// nothing else will grab it before we assign the unit to it.
+	template := state.MachineTemplate{
+		Series:            unit.Series(),
+		Jobs:              []state.MachineJob{state.JobHostUnits},
+		Dirty:             true,
+		Constraints:       *unitCons,
+		RequestedNetworks: networks,
+		Placement:         placement,
+	}
+	m, err := st.AddOneMachine(template)
+	if err != nil {
+		return fmt.Errorf("cannot assign unit %q to new machine: %v", unit.Name(), err)
+	}
+	return unit.AssignToMachine(m)
+}
@@ -0,0 +1,43 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package testing
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/juju/charm"
+	charmtesting "github.com/juju/charm/testing"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state"
+)
+
+// AddHostedEnvironment creates an environment with the given name,
+// hosted by the suite's state server, and returns a State for it.
+// The State must be closed by the caller.
+func (s *JujuConnSuite) AddHostedEnvironment(c *gc.C, name string) *state.State {
+	serverCfg, err := s.State.EnvironConfig()
+	c.Assert(err, gc.IsNil)
+	cfg, err := serverCfg.Apply(map[string]interface{}{"name": name})
+	c.Assert(err, gc.IsNil)
+	_, st, err := s.State.NewEnvironment(cfg, "admin")
+	c.Assert(err, gc.IsNil)
+	return st
+}
+
+// AddHostedTestingService adds a service with the given name, running
+// the named testing charm, to the environment of the given State.
+func (s *JujuConnSuite) AddHostedTestingService(c *gc.C, st *state.State, name, charmName string) *state.Service {
+	ch := charmtesting.Charms.Dir(charmName)
+	ident := fmt.Sprintf("%s-%d", ch.Meta().Name, ch.Revision())
+	curl := charm.MustParseURL("local:quantal/" + ident)
+	bundleURL, err := url.Parse("http://bundles.testing.invalid/" + ident)
+	c.Assert(err, gc.IsNil)
+	sch, err := st.AddCharm(ch, curl, bundleURL, ident+"-sha256")
+	c.Assert(err, gc.IsNil)
+	service, err := st.AddService(name, "user-admin", sch, nil)
+	c.Assert(err, gc.IsNil)
+	return service
+}
@@ -198,6 +198,25 @@ func EnsureServer(dataDir string, namespace string, info params.StateServingInfo
 	return upstartConfInstall(upstartConf)
 }
 
+// RestartServer writes the certificate and key in the given info for
+// the mongo server to serve, and restarts the server so that it serves
+// them. Unlike EnsureServer, it neither installs mongo nor prepares
+// its database directory, which EnsureServer must already have done.
+func RestartServer(dataDir string, namespace string, info params.StateServingInfo) error {
+	certKey := info.Cert + "\n" + info.PrivateKey
+	if err := utils.AtomicWriteFile(sslKeyPath(dataDir), []byte(certKey), 0600); err != nil {
+		return fmt.Errorf("cannot write SSL key: %v", err)
+	}
+	svc := upstart.NewService(ServiceName(namespace))
+	if err := upstartServiceStop(svc); err != nil {
+		return fmt.Errorf("failed to stop mongo: %v", err)
+	}
+	if err := upstartServiceStart(svc); err != nil {
+		return fmt.Errorf("failed to start mongo: %v", err)
+	}
+	return nil
+}
+
 // ServiceName returns the name of the upstart service config for mongo using
 // the given namespace.
 func ServiceName(namespace string) string {
@@ -175,6 +175,35 @@ func (s *MongoSuite) TestEnsureServer(c *gc.C) {
 	c.Assert(tlog, gc.Matches, start+`using mongod: .*/mongod --version: "db version v2\.4\.9`+tail)
 }
 
+func (s *MongoSuite) TestRestartServer(c *gc.C) {
+	dataDir := c.MkDir()
+	var calls []string
+	s.PatchValue(mongo.UpstartServiceStop, func(svc *upstart.Service) error {
+		calls = append(calls, "stop "+svc.Name)
+		return nil
+	})
+	s.PatchValue(mongo.UpstartServiceStart, func(svc *upstart.Service) error {
+		calls = append(calls, "start "+svc.Name)
+		return nil
+	})
+	// Record any attempt to install mongo.
+	output := mockShellCommand(c, &s.CleanupSuite, "apt-get")
+
+	err := mongo.RestartServer(dataDir, "namespace", testInfo)
+	c.Assert(err, gc.IsNil)
+	c.Assert(calls, gc.DeepEquals, []string{"stop juju-db-namespace", "start juju-db-namespace"})
+	contents, err := ioutil.ReadFile(mongo.SSLKeyPath(dataDir))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(contents), gc.Equals, testInfo.Cert+"\n"+testInfo.PrivateKey)
+
+	// Neither mongo nor its database directory are touched.
+	_, err = os.Stat(output)
+	c.Assert(os.IsNotExist(err), jc.IsTrue)
+	_, err = os.Stat(filepath.Join(dataDir, "db"))
+	c.Assert(os.IsNotExist(err), jc.IsTrue)
+	c.Assert(s.installed, gc.HasLen, 0)
+}
+
 func (s *MongoSuite) TestInstallMongod(c *gc.C) {
 	type installs struct {
 		series string
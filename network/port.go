@@ -8,6 +8,7 @@ import (
 	"net"
 	"sort"
 	"strconv"
+	"strings"
 )
 
 // Port identifies a network port number for a particular protocol.
@@ -63,3 +64,151 @@ func (p portSlice) Less(i, j int) bool {
 func SortPorts(ports []Port) {
 	sort.Sort(portSlice(ports))
 }
+
+// PortRange identifies a range of network ports for a particular
+// protocol. When used as a firewall rule, SourceCIDR restricts the
+// addresses from which the ports may be reached; it is empty when the
+// ports may be reached from anywhere.
+type PortRange struct {
+	Protocol   string
+	FromPort   int
+	ToPort     int
+	SourceCIDR string `bson:",omitempty" json:",omitempty"`
+}
+
+// NewPortRange returns the port range holding the single given port.
+func NewPortRange(p Port) PortRange {
+	return PortRange{
+		Protocol: p.Protocol,
+		FromPort: p.Number,
+		ToPort:   p.Number,
+	}
+}
+
+// Validate returns an error if the port range is not valid.
+func (p PortRange) Validate() error {
+	if p.FromPort < 1 || p.FromPort > 65535 || p.ToPort < 1 || p.ToPort > 65535 {
+		return fmt.Errorf("invalid port range %s: ports must be between 1 and 65535", p)
+	}
+	if p.FromPort > p.ToPort {
+		return fmt.Errorf("invalid port range %s: start port is greater than end port", p)
+	}
+	if p.SourceCIDR != "" {
+		if _, _, err := net.ParseCIDR(p.SourceCIDR); err != nil {
+			return fmt.Errorf("invalid source CIDR %q", p.SourceCIDR)
+		}
+	}
+	return nil
+}
+
+// ConflictsWith reports whether the two port ranges share any ports
+// for the same protocol.
+func (p PortRange) ConflictsWith(other PortRange) bool {
+	if p.Protocol != other.Protocol {
+		return false
+	}
+	return p.FromPort <= other.ToPort && other.FromPort <= p.ToPort
+}
+
+// Ports returns the individual ports in the range.
+func (p PortRange) Ports() []Port {
+	var ports []Port
+	for n := p.FromPort; n <= p.ToPort; n++ {
+		ports = append(ports, Port{Protocol: p.Protocol, Number: n})
+	}
+	return ports
+}
+
+// String implements Stringer. A range holding a single port
+// is formatted as the port alone, so "80/tcp" rather than
+// "80-80/tcp".
+func (p PortRange) String() string {
+	var s string
+	if p.FromPort == p.ToPort {
+		s = fmt.Sprintf("%d/%s", p.FromPort, p.Protocol)
+	} else {
+		s = fmt.Sprintf("%d-%d/%s", p.FromPort, p.ToPort, p.Protocol)
+	}
+	if p.SourceCIDR != "" {
+		s += " from " + p.SourceCIDR
+	}
+	return s
+}
+
+// ParsePortRange parses a port range in the form "<port>[-<port>][/<protocol>]",
+// for example "80", "8000-8080/tcp" or "53/udp". The protocol
+// defaults to tcp.
+func ParsePortRange(s string) (PortRange, error) {
+	p := PortRange{Protocol: "tcp"}
+	portsStr := s
+	if i := strings.Index(s, "/"); i != -1 {
+		portsStr, p.Protocol = s[:i], strings.ToLower(s[i+1:])
+	}
+	fromStr, toStr := portsStr, portsStr
+	if i := strings.Index(portsStr, "-"); i != -1 {
+		fromStr, toStr = portsStr[:i], portsStr[i+1:]
+	}
+	switch p.Protocol {
+	case "tcp", "udp":
+	default:
+		return PortRange{}, fmt.Errorf("invalid protocol %q", p.Protocol)
+	}
+	var err error
+	if p.FromPort, err = strconv.Atoi(fromStr); err != nil {
+		return PortRange{}, fmt.Errorf("invalid port range %q", s)
+	}
+	if p.ToPort, err = strconv.Atoi(toStr); err != nil {
+		return PortRange{}, fmt.Errorf("invalid port range %q", s)
+	}
+	if err := p.Validate(); err != nil {
+		return PortRange{}, err
+	}
+	return p, nil
+}
+
+type portRangeSlice []PortRange
+
+func (p portRangeSlice) Len() int      { return len(p) }
+func (p portRangeSlice) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+func (p portRangeSlice) Less(i, j int) bool {
+	p1 := p[i]
+	p2 := p[j]
+	if p1.Protocol != p2.Protocol {
+		return p1.Protocol < p2.Protocol
+	}
+	if p1.FromPort != p2.FromPort {
+		return p1.FromPort < p2.FromPort
+	}
+	if p1.ToPort != p2.ToPort {
+		return p1.ToPort < p2.ToPort
+	}
+	return p1.SourceCIDR < p2.SourceCIDR
+}
+
+// SortPortRanges sorts the given port ranges, first by protocol,
+// then by start port, end port and source CIDR.
+func SortPortRanges(ports []PortRange) {
+	sort.Sort(portRangeSlice(ports))
+}
+
+// CollapsePorts returns the given ports as port ranges, with
+// consecutive ports for the same protocol collapsed into a single
+// range. The port ranges are sorted by SortPortRanges.
+func CollapsePorts(ports []Port) []PortRange {
+	ports = append([]Port(nil), ports...)
+	SortPorts(ports)
+	var ranges []PortRange
+	for _, p := range ports {
+		if n := len(ranges); n > 0 {
+			last := &ranges[n-1]
+			if last.Protocol == p.Protocol && last.ToPort+1 >= p.Number {
+				if p.Number > last.ToPort {
+					last.ToPort = p.Number
+				}
+				continue
+			}
+		}
+		ranges = append(ranges, NewPortRange(p))
+	}
+	return ranges
+}
@@ -81,3 +81,113 @@ func (*PortSuite) TestAddressesWithPort(c *gc.C) {
 		Port:    999,
 	}})
 }
+
+var parsePortRangeTests = []struct {
+	about  string
+	s      string
+	expect network.PortRange
+	err    string
+}{{
+	about:  "single port, default protocol",
+	s:      "80",
+	expect: network.PortRange{Protocol: "tcp", FromPort: 80, ToPort: 80},
+}, {
+	about:  "range with protocol",
+	s:      "8000-8080/udp",
+	expect: network.PortRange{Protocol: "udp", FromPort: 8000, ToPort: 8080},
+}, {
+	about:  "upper case protocol",
+	s:      "443/TCP",
+	expect: network.PortRange{Protocol: "tcp", FromPort: 443, ToPort: 443},
+}, {
+	about: "bad protocol",
+	s:     "80/sctp",
+	err:   `invalid protocol "sctp"`,
+}, {
+	about: "bad port",
+	s:     "http/tcp",
+	err:   `invalid port range "http/tcp"`,
+}, {
+	about: "reversed range",
+	s:     "90-80/tcp",
+	err:   `invalid port range 90-80/tcp: start port is greater than end port`,
+}, {
+	about: "out of range",
+	s:     "0-100/tcp",
+	err:   `invalid port range 0-100/tcp: ports must be between 1 and 65535`,
+}}
+
+func (*PortSuite) TestParsePortRange(c *gc.C) {
+	for i, test := range parsePortRangeTests {
+		c.Logf("test %d: %s", i, test.about)
+		p, err := network.ParsePortRange(test.s)
+		if test.err != "" {
+			c.Check(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Check(err, gc.IsNil)
+		c.Check(p, gc.Equals, test.expect)
+	}
+}
+
+func (*PortSuite) TestPortRangeString(c *gc.C) {
+	c.Assert(network.PortRange{Protocol: "tcp", FromPort: 80, ToPort: 80}.String(), gc.Equals, "80/tcp")
+	c.Assert(network.PortRange{Protocol: "udp", FromPort: 8000, ToPort: 8080}.String(), gc.Equals, "8000-8080/udp")
+	c.Assert(network.PortRange{Protocol: "tcp", FromPort: 22, ToPort: 22, SourceCIDR: "10.0.0.0/8"}.String(), gc.Equals, "22/tcp from 10.0.0.0/8")
+}
+
+func (*PortSuite) TestPortRangeValidateSourceCIDR(c *gc.C) {
+	p := network.PortRange{Protocol: "tcp", FromPort: 22, ToPort: 22, SourceCIDR: "10.0.0.0/8"}
+	c.Assert(p.Validate(), gc.IsNil)
+	p.SourceCIDR = "10.0.0.0"
+	c.Assert(p.Validate(), gc.ErrorMatches, `invalid source CIDR "10.0.0.0"`)
+}
+
+func (*PortSuite) TestPortRangeConflictsWith(c *gc.C) {
+	p := network.PortRange{Protocol: "tcp", FromPort: 8000, ToPort: 8080}
+	c.Assert(p.ConflictsWith(network.PortRange{Protocol: "tcp", FromPort: 8080, ToPort: 8090}), jc.IsTrue)
+	c.Assert(p.ConflictsWith(network.PortRange{Protocol: "tcp", FromPort: 7000, ToPort: 9000}), jc.IsTrue)
+	c.Assert(p.ConflictsWith(network.PortRange{Protocol: "tcp", FromPort: 8081, ToPort: 8090}), jc.IsFalse)
+	c.Assert(p.ConflictsWith(network.PortRange{Protocol: "udp", FromPort: 8000, ToPort: 8080}), jc.IsFalse)
+}
+
+func (*PortSuite) TestPortRangePorts(c *gc.C) {
+	p := network.PortRange{Protocol: "tcp", FromPort: 80, ToPort: 82}
+	c.Assert(p.Ports(), gc.DeepEquals, []network.Port{{Protocol: "tcp", Number: 80}, {Protocol: "tcp", Number: 81}, {Protocol: "tcp", Number: 82}})
+	c.Assert(network.NewPortRange(network.Port{Protocol: "udp", Number: 53}), gc.Equals, network.PortRange{Protocol: "udp", FromPort: 53, ToPort: 53})
+}
+
+func (*PortSuite) TestSortPortRanges(c *gc.C) {
+	ports := []network.PortRange{
+		{Protocol: "udp", FromPort: 53, ToPort: 53},
+		{Protocol: "tcp", FromPort: 80, ToPort: 90},
+		{Protocol: "tcp", FromPort: 80, ToPort: 80, SourceCIDR: "10.0.0.0/8"},
+		{Protocol: "tcp", FromPort: 80, ToPort: 80},
+		{Protocol: "tcp", FromPort: 22, ToPort: 22},
+	}
+	network.SortPortRanges(ports)
+	c.Assert(ports, gc.DeepEquals, []network.PortRange{
+		{Protocol: "tcp", FromPort: 22, ToPort: 22},
+		{Protocol: "tcp", FromPort: 80, ToPort: 80},
+		{Protocol: "tcp", FromPort: 80, ToPort: 80, SourceCIDR: "10.0.0.0/8"},
+		{Protocol: "tcp", FromPort: 80, ToPort: 90},
+		{Protocol: "udp", FromPort: 53, ToPort: 53},
+	})
+}
+
+func (*PortSuite) TestCollapsePorts(c *gc.C) {
+	ports := []network.Port{
+		{Protocol: "udp", Number: 53},
+		{Protocol: "tcp", Number: 8001},
+		{Protocol: "tcp", Number: 80},
+		{Protocol: "tcp", Number: 8000},
+		{Protocol: "tcp", Number: 8002},
+		{Protocol: "tcp", Number: 8000},
+	}
+	c.Assert(network.CollapsePorts(ports), gc.DeepEquals, []network.PortRange{
+		{Protocol: "tcp", FromPort: 80, ToPort: 80},
+		{Protocol: "tcp", FromPort: 8000, ToPort: 8002},
+		{Protocol: "udp", FromPort: 53, ToPort: 53},
+	})
+	c.Assert(network.CollapsePorts(nil), gc.HasLen, 0)
+}
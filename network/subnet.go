@@ -0,0 +1,52 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package network
+
+import (
+	"net"
+	"regexp"
+)
+
+// SubnetInfo describes a single subnet, as known by the provider.
+// Subnets are the building blocks of spaces: a space is a set of
+// subnets, possibly spread across several availability zones, that
+// are treated alike for the purposes of connectivity.
+type SubnetInfo struct {
+	// CIDR of the subnet, in 123.45.67.89/24 format. It is always
+	// set.
+	CIDR string
+
+	// ProviderId is a provider-specific subnet id. It can be empty
+	// for subnets the provider does not know about.
+	ProviderId Id
+
+	// VLANTag needs to be between 1 and 4094 for VLANs and 0 for
+	// normal subnets. It's defined by IEEE 802.1Q standard.
+	VLANTag int
+
+	// AvailabilityZones lists the availability zones in which the
+	// subnet is usable. It is empty when the provider has no notion
+	// of zones, or the subnet spans them all.
+	AvailabilityZones []string
+}
+
+var validSpaceName = regexp.MustCompile("^[a-z0-9]+(?:-[a-z0-9]+)*$")
+
+// IsValidSpaceName reports whether name is a valid space name: one or
+// more groups of lower case letters and digits, separated by hyphens.
+func IsValidSpaceName(name string) bool {
+	return validSpaceName.MatchString(name)
+}
+
+// SubnetContains reports whether the given address value lies within
+// the subnet with the given CIDR. Malformed values are never
+// contained.
+func SubnetContains(cidr, value string) bool {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(value)
+	return ip != nil && ipNet.Contains(ip)
+}
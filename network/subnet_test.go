@@ -0,0 +1,53 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package network_test
+
+import (
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/network"
+)
+
+type SubnetSuite struct{}
+
+var _ = gc.Suite(&SubnetSuite{})
+
+func (*SubnetSuite) TestIsValidSpaceName(c *gc.C) {
+	for i, test := range []struct {
+		name  string
+		valid bool
+	}{
+		{"db", true},
+		{"dmz-2", true},
+		{"0-public-a", true},
+		{"", false},
+		{"-db", false},
+		{"db-", false},
+		{"db--a", false},
+		{"DB", false},
+		{"db_a", false},
+		{"db.a", false},
+	} {
+		c.Logf("test %d: %q", i, test.name)
+		c.Check(network.IsValidSpaceName(test.name), gc.Equals, test.valid)
+	}
+}
+
+func (*SubnetSuite) TestSubnetContains(c *gc.C) {
+	for i, test := range []struct {
+		cidr     string
+		value    string
+		contains bool
+	}{
+		{"10.0.0.0/24", "10.0.0.1", true},
+		{"10.0.0.0/24", "10.0.1.1", false},
+		{"2001:db8::/32", "2001:db8::1", true},
+		{"2001:db8::/32", "10.0.0.1", false},
+		{"10.0.0.0/24", "example.com", false},
+		{"invalid", "10.0.0.1", false},
+	} {
+		c.Logf("test %d: %s in %s", i, test.value, test.cidr)
+		c.Check(network.SubnetContains(test.cidr, test.value), gc.Equals, test.contains)
+	}
+}
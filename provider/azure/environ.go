@@ -328,7 +328,7 @@ func (env *azureEnviron) SetConfig(cfg *config.Config) error {
 // name it chooses (based on the given prefix), but recognizes that the name
 // may not be available.  If the name is not available, it does not treat that
 // as an error but just returns nil.
-func attemptCreateService(azure *gwacl.ManagementAPI, prefix, affinityGroupName, label string) (*gwacl.CreateHostedService, error) {
+func attemptCreateService(azure *gwacl.ManagementAPI, prefix, affinityGroupName, label string, properties []gwacl.ExtendedProperty) (*gwacl.CreateHostedService, error) {
 	var err error
 	name := gwacl.MakeRandomHostedServiceName(prefix)
 	err = azure.CheckHostedServiceNameAvailability(name)
@@ -341,6 +341,7 @@ func attemptCreateService(azure *gwacl.ManagementAPI, prefix, affinityGroupName,
 	}
 	req := gwacl.NewCreateHostedServiceWithLocation(name, label, "")
 	req.AffinityGroup = affinityGroupName
+	req.ExtendedProperties = properties
 	err = azure.AddHostedService(req)
 	if err != nil {
 		return nil, err
@@ -348,13 +349,14 @@ func attemptCreateService(azure *gwacl.ManagementAPI, prefix, affinityGroupName,
 	return req, nil
 }
 
-// newHostedService creates a hosted service.  It will make up a unique name,
-// starting with the given prefix.
-func newHostedService(azure *gwacl.ManagementAPI, prefix, affinityGroupName, label string) (*gwacl.HostedService, error) {
+// newHostedService creates a hosted service with the given extended
+// properties.  It will make up a unique name, starting with the given
+// prefix.
+func newHostedService(azure *gwacl.ManagementAPI, prefix, affinityGroupName, label string, properties []gwacl.ExtendedProperty) (*gwacl.HostedService, error) {
 	var err error
 	var createdService *gwacl.CreateHostedService
 	for tries := 10; tries > 0 && err == nil && createdService == nil; tries-- {
-		createdService, err = attemptCreateService(azure, prefix, affinityGroupName, label)
+		createdService, err = attemptCreateService(azure, prefix, affinityGroupName, label, properties)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("could not create hosted service: %v", err)
@@ -496,7 +498,8 @@ func (env *azureEnviron) createInstance(azure *gwacl.ManagementAPI, role *gwacl.
 		if stateServer {
 			label = stateServerLabel
 		}
-		service, err = newHostedService(azure, env.getEnvPrefix(), env.getAffinityGroupName(), label)
+		properties := serviceTags(env.Config(), stateServer)
+		service, err = newHostedService(azure, env.getEnvPrefix(), env.getAffinityGroupName(), label, properties)
 	}
 	if err != nil {
 		return nil, err
@@ -796,7 +799,11 @@ func (env *azureEnviron) StopInstances(ids ...instance.Id) error {
 		service, err := context.GetHostedServiceProperties(serviceName, true)
 		if err != nil {
 			return err
-		} else if len(service.Deployments) != 1 {
+		}
+		if !serviceBelongsToEnviron(env.Config(), service.ExtendedProperties) {
+			return fmt.Errorf("cannot stop instances in cloud service %q: not tagged as belonging to environment %q", serviceName, env.Config().Name())
+		}
+		if len(service.Deployments) != 1 {
 			continue
 		}
 		// Filter the instances that have no corresponding role.
@@ -847,6 +854,12 @@ func (env *azureEnviron) destroyAllServices() error {
 	if err != nil {
 		return err
 	}
+	cfg := env.Config()
+	for _, service := range services {
+		if !serviceBelongsToEnviron(cfg, service.ExtendedProperties) {
+			return fmt.Errorf("cannot destroy cloud service %q: not tagged as belonging to environment %q", service.ServiceName, cfg.Name())
+		}
+	}
 	for _, service := range services {
 		if err := context.DeleteHostedService(service.ServiceName); err != nil {
 			return err
@@ -1033,20 +1046,20 @@ func (env *azureEnviron) Destroy() error {
 
 // OpenPorts is specified in the Environ interface. However, Azure does not
 // support the global firewall mode.
-func (env *azureEnviron) OpenPorts(ports []network.Port) error {
+func (env *azureEnviron) OpenPorts(ports []network.PortRange) error {
 	return nil
 }
 
 // ClosePorts is specified in the Environ interface. However, Azure does not
 // support the global firewall mode.
-func (env *azureEnviron) ClosePorts(ports []network.Port) error {
+func (env *azureEnviron) ClosePorts(ports []network.PortRange) error {
 	return nil
 }
 
 // Ports is specified in the Environ interface.
-func (env *azureEnviron) Ports() ([]network.Port, error) {
+func (env *azureEnviron) Ports() ([]network.PortRange, error) {
 	// TODO: implement this.
-	return []network.Port{}, nil
+	return []network.PortRange{}, nil
 }
 
 // Provider is specified in the Environ interface.
@@ -1237,3 +1250,8 @@ func (env *azureEnviron) SupportsUnitPlacement() error {
 	}
 	return nil
 }
+
+// SupportsSourceCIDRs is specified in the state.EnvironCapability interface.
+func (env *azureEnviron) SupportsSourceCIDRs() error {
+	return errors.NotSupportedf("restricting access to source CIDRs")
+}
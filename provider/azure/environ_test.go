@@ -16,6 +16,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/juju/errors"
 	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	gc "launchpad.net/gocheck"
@@ -600,7 +601,7 @@ func (*environSuite) TestAttemptCreateServiceCreatesService(c *gc.C) {
 	azure, err := gwacl.NewManagementAPI("subscription", "", "West US")
 	c.Assert(err, gc.IsNil)
 
-	service, err := attemptCreateService(azure, prefix, affinityGroup, "")
+	service, err := attemptCreateService(azure, prefix, affinityGroup, "", nil)
 	c.Assert(err, gc.IsNil)
 
 	c.Assert(*requests, gc.HasLen, 2)
@@ -612,6 +613,24 @@ func (*environSuite) TestAttemptCreateServiceCreatesService(c *gc.C) {
 	c.Check(service.Location, gc.Equals, "")
 }
 
+func (*environSuite) TestAttemptCreateServiceSetsExtendedProperties(c *gc.C) {
+	responses := []gwacl.DispatcherResponse{
+		gwacl.NewDispatcherResponse(makeAvailabilityResponse(c), http.StatusOK, nil),
+		gwacl.NewDispatcherResponse(nil, http.StatusOK, nil),
+	}
+	requests := gwacl.PatchManagementAPIResponses(responses)
+	azure, err := gwacl.NewManagementAPI("subscription", "", "West US")
+	c.Assert(err, gc.IsNil)
+
+	properties := []gwacl.ExtendedProperty{{Name: "juju_env_uuid", Value: "uuid"}}
+	_, err = attemptCreateService(azure, "service", "affinity-group", "", properties)
+	c.Assert(err, gc.IsNil)
+
+	c.Assert(*requests, gc.HasLen, 2)
+	body := parseCreateServiceRequest(c, (*requests)[1])
+	c.Check(body.ExtendedProperties, gc.DeepEquals, properties)
+}
+
 func (*environSuite) TestAttemptCreateServiceReturnsNilIfNameNotUnique(c *gc.C) {
 	responses := []gwacl.DispatcherResponse{
 		gwacl.NewDispatcherResponse(makeNonAvailabilityResponse(c), http.StatusOK, nil),
@@ -620,7 +639,7 @@ func (*environSuite) TestAttemptCreateServiceReturnsNilIfNameNotUnique(c *gc.C)
 	azure, err := gwacl.NewManagementAPI("subscription", "", "West US")
 	c.Assert(err, gc.IsNil)
 
-	service, err := attemptCreateService(azure, "service", "affinity-group", "")
+	service, err := attemptCreateService(azure, "service", "affinity-group", "", nil)
 	c.Check(err, gc.IsNil)
 	c.Check(service, gc.IsNil)
 }
@@ -634,7 +653,7 @@ func (*environSuite) TestAttemptCreateServicePropagatesOtherFailure(c *gc.C) {
 	azure, err := gwacl.NewManagementAPI("subscription", "", "West US")
 	c.Assert(err, gc.IsNil)
 
-	_, err = attemptCreateService(azure, "service", "affinity-group", "")
+	_, err = attemptCreateService(azure, "service", "affinity-group", "", nil)
 	c.Assert(err, gc.NotNil)
 	c.Check(err, gc.ErrorMatches, ".*Not Found.*")
 }
@@ -655,7 +674,7 @@ func (*environSuite) TestNewHostedServiceCreatesService(c *gc.C) {
 	azure, err := gwacl.NewManagementAPI("subscription", "", "West US")
 	c.Assert(err, gc.IsNil)
 
-	service, err := newHostedService(azure, prefix, affinityGroup, "")
+	service, err := newHostedService(azure, prefix, affinityGroup, "", nil)
 	c.Assert(err, gc.IsNil)
 
 	c.Assert(*requests, gc.HasLen, 3)
@@ -688,7 +707,7 @@ func (*environSuite) TestNewHostedServiceRetriesIfNotUnique(c *gc.C) {
 	azure, err := gwacl.NewManagementAPI("subscription", "", "West US")
 	c.Assert(err, gc.IsNil)
 
-	service, err := newHostedService(azure, "service", "affinity-group", "")
+	service, err := newHostedService(azure, "service", "affinity-group", "", nil)
 	c.Check(err, gc.IsNil)
 
 	c.Assert(*requests, gc.HasLen, 5)
@@ -726,7 +745,7 @@ func (*environSuite) TestNewHostedServiceFailsIfUnableToFindUniqueName(c *gc.C)
 	azure, err := gwacl.NewManagementAPI("subscription", "", "West US")
 	c.Assert(err, gc.IsNil)
 
-	_, err = newHostedService(azure, "service", "affinity-group", "")
+	_, err = newHostedService(azure, "service", "affinity-group", "", nil)
 	c.Assert(err, gc.NotNil)
 	c.Check(err, gc.ErrorMatches, "could not come up with a unique hosted service name.*")
 }
@@ -814,6 +833,25 @@ func (s *environSuite) TestStopInstancesDestroysMachines(c *gc.C) {
 	assertOneRequestMatches(c, *requests, "DELETE", ".*"+service2Name+".*")
 }
 
+func (s *environSuite) TestStopInstancesNotBelongingToEnviron(c *gc.C) {
+	attrs := makeAzureConfigMap(c)
+	attrs["uuid"] = "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+	env := makeEnvironWithConfig(c, attrs)
+	service := makeDeployment(env, env.getEnvPrefix()+"service")
+	role1Name := service.Deployments[0].RoleList[0].RoleName
+	inst1, err := env.getInstance(service, role1Name)
+	c.Assert(err, gc.IsNil)
+
+	responses := buildGetServicePropertiesResponses(c, service)
+	requests := gwacl.PatchManagementAPIResponses(responses)
+	err = env.StopInstances(inst1.Id())
+	c.Assert(err, gc.ErrorMatches, `cannot stop instances in cloud service ".*service": not tagged as belonging to environment ".*"`)
+
+	// Only the service's properties were fetched.
+	c.Check(*requests, gc.HasLen, 1)
+	assertOneRequestMatches(c, *requests, "GET", ".*"+service.ServiceName+".*")
+}
+
 func (s *environSuite) TestStopInstancesServiceSubset(c *gc.C) {
 	env := makeEnviron(c)
 	service := makeDeployment(env, env.getEnvPrefix()+"service")
@@ -1437,6 +1475,12 @@ func (s *environSuite) TestCheckUnitAssignment(c *gc.C) {
 	c.Assert(err, gc.IsNil)
 }
 
+func (s *environSuite) TestSupportsSourceCIDRs(c *gc.C) {
+	env := environs.Environ(makeEnviron(c))
+	err := env.SupportsSourceCIDRs()
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
 type startInstanceSuite struct {
 	baseEnvironSuite
 	env    *azureEnviron
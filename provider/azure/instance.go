@@ -13,7 +13,6 @@ import (
 
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/network"
-	"github.com/juju/juju/worker/firewaller"
 )
 
 const AzureDomainName = "cloudapp.net"
@@ -122,12 +121,31 @@ func (azInstance *azureInstance) ipAddress() string {
 }
 
 // OpenPorts is specified in the Instance interface.
-func (azInstance *azureInstance) OpenPorts(machineId string, ports []network.Port) error {
+func (azInstance *azureInstance) OpenPorts(machineId string, ports []network.PortRange) error {
+	endpointPorts, err := endpointPorts(ports)
+	if err != nil {
+		return err
+	}
 	return azInstance.apiCall(true, func(context *azureManagementContext) error {
-		return azInstance.openEndpoints(context, ports)
+		return azInstance.openEndpoints(context, endpointPorts)
 	})
 }
 
+// endpointPorts returns the individual ports in the given port
+// ranges, as Azure endpoints each map a single port. Azure endpoints
+// cannot be restricted to source CIDRs, so an error is returned
+// if any of the port ranges has one.
+func endpointPorts(ports []network.PortRange) ([]network.Port, error) {
+	var result []network.Port
+	for _, p := range ports {
+		if p.SourceCIDR != "" {
+			return nil, errors.NotSupportedf("opening ports to source CIDR %q", p.SourceCIDR)
+		}
+		result = append(result, p.Ports()...)
+	}
+	return result, nil
+}
+
 // apiCall wraps a call to the azure API to ensure it is properly disposed, optionally locking
 // the environment
 func (azInstance *azureInstance) apiCall(lock bool, f func(*azureManagementContext) error) error {
@@ -185,9 +203,13 @@ func (azInstance *azureInstance) openEndpoints(context *azureManagementContext,
 }
 
 // ClosePorts is specified in the Instance interface.
-func (azInstance *azureInstance) ClosePorts(machineId string, ports []network.Port) error {
+func (azInstance *azureInstance) ClosePorts(machineId string, ports []network.PortRange) error {
+	endpointPorts, err := endpointPorts(ports)
+	if err != nil {
+		return err
+	}
 	return azInstance.apiCall(true, func(context *azureManagementContext) error {
-		return azInstance.closeEndpoints(context, ports)
+		return azInstance.closeEndpoints(context, endpointPorts)
 	})
 }
 
@@ -228,20 +250,36 @@ func convertEndpointsToPorts(endpoints []gwacl.InputEndpoint) []network.Port {
 // convertAndFilterEndpoints converts a slice of gwacl.InputEndpoint into a slice of network.Port
 // and filters out the initial endpoints that every instance should have opened (ssh port, etc.).
 func convertAndFilterEndpoints(endpoints []gwacl.InputEndpoint, env *azureEnviron, stateServer bool) []network.Port {
-	return firewaller.Diff(
+	return diffPorts(
 		convertEndpointsToPorts(endpoints),
 		convertEndpointsToPorts(env.getInitialEndpoints(stateServer)),
 	)
 }
 
-// Ports is specified in the Instance interface.
-func (azInstance *azureInstance) Ports(machineId string) (ports []network.Port, err error) {
+// diffPorts returns all the ports that exist in A but not B.
+func diffPorts(A, B []network.Port) (missing []network.Port) {
+next:
+	for _, a := range A {
+		for _, b := range B {
+			if a == b {
+				continue next
+			}
+		}
+		missing = append(missing, a)
+	}
+	return
+}
+
+// Ports is specified in the Instance interface. As Azure endpoints
+// each map a single port, consecutive ports are reported as a range.
+func (azInstance *azureInstance) Ports(machineId string) (ports []network.PortRange, err error) {
+	var endpointPorts []network.Port
 	err = azInstance.apiCall(false, func(context *azureManagementContext) error {
-		ports, err = azInstance.listPorts(context)
+		endpointPorts, err = azInstance.listPorts(context)
 		return err
 	})
-	if ports != nil {
-		network.SortPorts(ports)
+	if endpointPorts != nil {
+		ports = network.CollapsePorts(endpointPorts)
 	}
 	return ports, err
 }
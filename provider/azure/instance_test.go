@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "launchpad.net/gocheck"
 	"launchpad.net/gwacl"
@@ -171,8 +172,10 @@ func (s *instanceSuite) TestOpenPorts(c *gc.C) {
 
 	responses := preparePortChangeConversation(c, s.role)
 	record := gwacl.PatchManagementAPIResponses(responses)
-	err := s.instance.OpenPorts("machine-id", []network.Port{
-		{"tcp", 79}, {"tcp", 587}, {"udp", 9},
+	err := s.instance.OpenPorts("machine-id", []network.PortRange{
+		{Protocol: "tcp", FromPort: 79, ToPort: 79},
+		{Protocol: "tcp", FromPort: 587, ToPort: 587},
+		{Protocol: "udp", FromPort: 9, ToPort: 9},
 	})
 	c.Assert(err, gc.IsNil)
 
@@ -197,12 +200,48 @@ func (s *instanceSuite) TestOpenPorts(c *gc.C) {
 	)
 }
 
+func (s *instanceSuite) TestOpenPortRange(c *gc.C) {
+	configSetNetwork((*gwacl.Role)(s.role)).InputEndpoints = nil
+
+	responses := preparePortChangeConversation(c, s.role)
+	record := gwacl.PatchManagementAPIResponses(responses)
+	err := s.instance.OpenPorts("machine-id", []network.PortRange{
+		{Protocol: "tcp", FromPort: 8000, ToPort: 8002},
+	})
+	c.Assert(err, gc.IsNil)
+
+	// Each port in the range has its own endpoint.
+	role := &gwacl.PersistentVMRole{}
+	err = role.Deserialize((*record)[1].Payload)
+	c.Assert(err, gc.IsNil)
+	c.Check(
+		*configSetNetwork((*gwacl.Role)(role)).InputEndpoints,
+		gc.DeepEquals,
+		[]gwacl.InputEndpoint{
+			makeInputEndpoint(8000, "tcp"),
+			makeInputEndpoint(8001, "tcp"),
+			makeInputEndpoint(8002, "tcp"),
+		},
+	)
+}
+
+func (s *instanceSuite) TestOpenPortsToSourceCIDRNotSupported(c *gc.C) {
+	record := gwacl.PatchManagementAPIResponses(preparePortChangeConversation(c, s.role))
+	err := s.instance.OpenPorts("machine-id", []network.PortRange{
+		{Protocol: "tcp", FromPort: 80, ToPort: 80, SourceCIDR: "10.0.0.0/8"},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+	c.Check(*record, gc.HasLen, 0)
+}
+
 func (s *instanceSuite) TestOpenPortsFailsWhenUnableToGetRole(c *gc.C) {
 	responses := preparePortChangeConversation(c, s.role)
 	failPortChangeConversationAt(1, responses) // 1st request, GetRole
 	record := gwacl.PatchManagementAPIResponses(responses)
-	err := s.instance.OpenPorts("machine-id", []network.Port{
-		{"tcp", 79}, {"tcp", 587}, {"udp", 9},
+	err := s.instance.OpenPorts("machine-id", []network.PortRange{
+		{Protocol: "tcp", FromPort: 79, ToPort: 79},
+		{Protocol: "tcp", FromPort: 587, ToPort: 587},
+		{Protocol: "udp", FromPort: 9, ToPort: 9},
 	})
 	c.Check(err, gc.ErrorMatches, "GET request failed [(]500: Internal Server Error[)]")
 	c.Check(*record, gc.HasLen, 1)
@@ -212,8 +251,10 @@ func (s *instanceSuite) TestOpenPortsFailsWhenUnableToUpdateRole(c *gc.C) {
 	responses := preparePortChangeConversation(c, s.role)
 	failPortChangeConversationAt(2, responses) // 2nd request, UpdateRole
 	record := gwacl.PatchManagementAPIResponses(responses)
-	err := s.instance.OpenPorts("machine-id", []network.Port{
-		{"tcp", 79}, {"tcp", 587}, {"udp", 9},
+	err := s.instance.OpenPorts("machine-id", []network.PortRange{
+		{Protocol: "tcp", FromPort: 79, ToPort: 79},
+		{Protocol: "tcp", FromPort: 587, ToPort: 587},
+		{Protocol: "udp", FromPort: 9, ToPort: 9},
 	})
 	c.Check(err, gc.ErrorMatches, "PUT request failed [(]500: Internal Server Error[)]")
 	c.Check(*record, gc.HasLen, 2)
@@ -255,7 +296,7 @@ func (s *instanceSuite) TestClosePorts(c *gc.C) {
 		responses := preparePortChangeConversation(c, s.role)
 		record := gwacl.PatchManagementAPIResponses(responses)
 
-		err := s.instance.ClosePorts("machine-id", test.removePorts)
+		err := s.instance.ClosePorts("machine-id", network.CollapsePorts(test.removePorts))
 		c.Assert(err, gc.IsNil)
 		assertPortChangeConversation(c, *record, []expectedRequest{
 			{"GET", ".*/deployments/deployment-one/roles/role-one"}, // GetRole
@@ -281,8 +322,10 @@ func (s *instanceSuite) TestClosePortsFailsWhenUnableToGetRole(c *gc.C) {
 	responses := preparePortChangeConversation(c, s.role)
 	failPortChangeConversationAt(1, responses) // 1st request, GetRole
 	record := gwacl.PatchManagementAPIResponses(responses)
-	err := s.instance.ClosePorts("machine-id", []network.Port{
-		{"tcp", 79}, {"tcp", 587}, {"udp", 9},
+	err := s.instance.ClosePorts("machine-id", []network.PortRange{
+		{Protocol: "tcp", FromPort: 79, ToPort: 79},
+		{Protocol: "tcp", FromPort: 587, ToPort: 587},
+		{Protocol: "udp", FromPort: 9, ToPort: 9},
 	})
 	c.Check(err, gc.ErrorMatches, "GET request failed [(]500: Internal Server Error[)]")
 	c.Check(*record, gc.HasLen, 1)
@@ -292,8 +335,10 @@ func (s *instanceSuite) TestClosePortsFailsWhenUnableToUpdateRole(c *gc.C) {
 	responses := preparePortChangeConversation(c, s.role)
 	failPortChangeConversationAt(2, responses) // 2nd request, UpdateRole
 	record := gwacl.PatchManagementAPIResponses(responses)
-	err := s.instance.ClosePorts("machine-id", []network.Port{
-		{"tcp", 79}, {"tcp", 587}, {"udp", 9},
+	err := s.instance.ClosePorts("machine-id", []network.PortRange{
+		{Protocol: "tcp", FromPort: 79, ToPort: 79},
+		{Protocol: "tcp", FromPort: 587, ToPort: 587},
+		{Protocol: "udp", FromPort: 9, ToPort: 9},
 	})
 	c.Check(err, gc.ErrorMatches, "PUT request failed [(]500: Internal Server Error[)]")
 	c.Check(*record, gc.HasLen, 2)
@@ -374,15 +419,17 @@ func (s *instanceSuite) testPorts(c *gc.C, maskStateServerPorts bool) {
 		{"GET", ".*/deployments/deployment-one/roles/role-one"}, // GetRole
 	})
 
-	expected := []network.Port{
-		{Number: 4456, Protocol: "tcp"},
-		{Number: 1123, Protocol: "udp"},
-		{Number: 2123, Protocol: "udp"},
+	expected := []network.PortRange{
+		{Protocol: "tcp", FromPort: 4456, ToPort: 4456},
+		{Protocol: "udp", FromPort: 1123, ToPort: 1123},
+		{Protocol: "udp", FromPort: 2123, ToPort: 2123},
 	}
 	if !maskStateServerPorts {
-		expected = append(expected, network.Port{Number: s.env.Config().StatePort(), Protocol: "tcp"})
-		expected = append(expected, network.Port{Number: s.env.Config().APIPort(), Protocol: "tcp"})
-		network.SortPorts(expected)
+		statePort := s.env.Config().StatePort()
+		apiPort := s.env.Config().APIPort()
+		expected = append(expected, network.PortRange{Protocol: "tcp", FromPort: statePort, ToPort: statePort})
+		expected = append(expected, network.PortRange{Protocol: "tcp", FromPort: apiPort, ToPort: apiPort})
+		network.SortPortRanges(expected)
 	}
 	c.Check(ports, gc.DeepEquals, expected)
 }
@@ -0,0 +1,62 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package azure
+
+import (
+	"regexp"
+	"sort"
+
+	"launchpad.net/gwacl"
+
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/environs/tags"
+)
+
+// Azure cloud services cannot be tagged, but they can hold extended
+// properties, whose names may only contain letters, digits and
+// underscores.
+var invalidPropertyNameChars = regexp.MustCompile("[^a-zA-Z0-9_]")
+
+// extendedPropertyName returns the name of the extended
+// property that holds the tag with the given key.
+func extendedPropertyName(key string) string {
+	return invalidPropertyNameChars.ReplaceAllString(key, "_")
+}
+
+// serviceTags returns the tags to apply to a new cloud service.
+// A cloud service may hold the instances of several machines,
+// so only the environment's tags and, for state servers' cloud
+// services, the JujuStateServer tag are applied.
+func serviceTags(cfg *config.Config, stateServer bool) []gwacl.ExtendedProperty {
+	resourceTags := tags.ResourceTags(cfg)
+	if stateServer {
+		resourceTags[tags.JujuStateServer] = "true"
+	}
+	keys := make([]string, 0, len(resourceTags))
+	for key := range resourceTags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	properties := make([]gwacl.ExtendedProperty, len(keys))
+	for i, key := range keys {
+		properties[i] = gwacl.ExtendedProperty{
+			Name:  extendedPropertyName(key),
+			Value: resourceTags[key],
+		}
+	}
+	return properties
+}
+
+// serviceBelongsToEnviron reports whether the cloud service with
+// the given extended properties was created for the environment.
+func serviceBelongsToEnviron(cfg *config.Config, properties []gwacl.ExtendedProperty) bool {
+	envProperty := extendedPropertyName(tags.JujuEnv)
+	envTags := make(map[string]string)
+	for _, property := range properties {
+		if property.Name == envProperty {
+			envTags[tags.JujuEnv] = property.Value
+		}
+	}
+	return tags.BelongsToEnviron(cfg, envTags)
+}
@@ -0,0 +1,45 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package azure
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+	"launchpad.net/gwacl"
+
+	"github.com/juju/juju/testing"
+)
+
+type tagsSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&tagsSuite{})
+
+const testUUID = "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+
+func (*tagsSuite) TestServiceTags(c *gc.C) {
+	cfg := testing.CustomEnvironConfig(c, testing.Attrs{
+		"uuid":          testUUID,
+		"resource-tags": "cost-centre=1234",
+	})
+	c.Assert(serviceTags(cfg, false), jc.DeepEquals, []gwacl.ExtendedProperty{
+		{Name: "cost_centre", Value: "1234"},
+		{Name: "juju_env_uuid", Value: testUUID},
+	})
+	c.Assert(serviceTags(cfg, true), jc.DeepEquals, []gwacl.ExtendedProperty{
+		{Name: "cost_centre", Value: "1234"},
+		{Name: "juju_env_uuid", Value: testUUID},
+		{Name: "juju_is_state", Value: "true"},
+	})
+}
+
+func (*tagsSuite) TestServiceBelongsToEnviron(c *gc.C) {
+	cfg := testing.CustomEnvironConfig(c, testing.Attrs{"uuid": testUUID})
+	c.Assert(serviceBelongsToEnviron(cfg, serviceTags(cfg, false)), jc.IsTrue)
+	c.Assert(serviceBelongsToEnviron(cfg, []gwacl.ExtendedProperty{
+		{Name: "juju_env_uuid", Value: "another-environment"},
+	}), jc.IsFalse)
+	c.Assert(serviceBelongsToEnviron(cfg, nil), jc.IsFalse)
+}
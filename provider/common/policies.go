@@ -13,3 +13,14 @@ type SupportsUnitPlacementPolicy struct{}
 func (*SupportsUnitPlacementPolicy) SupportsUnitPlacement() error {
 	return nil
 }
+
+// SupportsSourceCIDRsPolicy provides an
+// implementation of SupportsSourceCIDRs
+// that never returns an error, and is
+// intended for embedding in environs.Environ
+// implementations.
+type SupportsSourceCIDRsPolicy struct{}
+
+func (*SupportsSourceCIDRsPolicy) SupportsSourceCIDRs() error {
+	return nil
+}
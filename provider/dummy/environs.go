@@ -56,6 +56,8 @@ import (
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/api"
 	"github.com/juju/juju/state/apiserver"
+	jujustorage "github.com/juju/juju/storage"
+	storageprovider "github.com/juju/juju/storage/provider"
 	"github.com/juju/juju/testing"
 )
 
@@ -124,17 +126,24 @@ type OpListNetworks struct {
 	Info []network.BasicInfo
 }
 
+type OpSubnets struct {
+	Env  string
+	Info []network.SubnetInfo
+}
+
 type OpStartInstance struct {
-	Env          string
-	MachineId    string
-	MachineNonce string
-	Instance     instance.Instance
-	Constraints  constraints.Value
-	Networks     []string
-	NetworkInfo  []network.Info
-	Info         *state.Info
-	APIInfo      *api.Info
-	Secret       string
+	Env            string
+	MachineId      string
+	MachineNonce   string
+	Instance       instance.Instance
+	Constraints    constraints.Value
+	Networks       []string
+	NetworkInfo    []network.Info
+	SubnetsToZones map[network.Id][]string
+	Volumes        []jujustorage.VolumeParams
+	Info           *state.Info
+	APIInfo        *api.Info
+	Secret         string
 }
 
 type OpStopInstances struct {
@@ -146,14 +155,14 @@ type OpOpenPorts struct {
 	Env        string
 	MachineId  string
 	InstanceId instance.Id
-	Ports      []network.Port
+	Ports      []network.PortRange
 }
 
 type OpClosePorts struct {
 	Env        string
 	MachineId  string
 	InstanceId instance.Id
-	Ports      []network.Port
+	Ports      []network.PortRange
 }
 
 type OpPutFile struct {
@@ -188,7 +197,7 @@ type environState struct {
 	maxId        int // maximum instance id allocated so far.
 	maxAddr      int // maximum allocated address last byte
 	insts        map[instance.Id]*dummyInstance
-	globalPorts  map[network.Port]bool
+	globalPorts  map[network.PortRange]bool
 	bootstrapped bool
 	storageDelay time.Duration
 	storage      *storageServer
@@ -201,6 +210,7 @@ type environState struct {
 // state.
 type environ struct {
 	common.SupportsUnitPlacementPolicy
+	common.SupportsSourceCIDRsPolicy
 
 	name         string
 	ecfgMutex    sync.Mutex
@@ -216,6 +226,7 @@ var discardOperations chan<- Operation
 
 func init() {
 	environs.RegisterProvider("dummy", &providerInstance)
+	jujustorage.RegisterDefaultProvider("dummy", storageprovider.LoopProviderType)
 
 	// Prime the first ops channel, so that naive clients can use
 	// the testing environment by simply importing it.
@@ -300,7 +311,7 @@ func newState(name string, ops chan<- Operation, policy state.Policy) *environSt
 		ops:         ops,
 		statePolicy: policy,
 		insts:       make(map[instance.Id]*dummyInstance),
-		globalPorts: make(map[network.Port]bool),
+		globalPorts: make(map[network.PortRange]bool),
 	}
 	s.storage = newStorageServer(s, "/"+name+"/private")
 	s.listen()
@@ -560,10 +571,11 @@ func (*environ) SupportNetworks() bool {
 
 // PrecheckInstance is specified in the state.Prechecker interface.
 func (*environ) PrecheckInstance(series string, cons constraints.Value, placement string) error {
-	if placement != "" && placement != "valid" {
-		return fmt.Errorf("%s placement is invalid", placement)
+	switch placement {
+	case "", "valid", "zone=zone1", "zone=zone2":
+		return nil
 	}
-	return nil
+	return fmt.Errorf("%s placement is invalid", placement)
 }
 
 // GetImageSources returns a list of sources which are used to search for simplestreams image metadata.
@@ -764,7 +776,7 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (instance.Ins
 	i := &dummyInstance{
 		id:           instance.Id(idString),
 		addresses:    network.NewAddresses(idString + ".dns"),
-		ports:        make(map[network.Port]bool),
+		ports:        make(map[network.PortRange]bool),
 		machineId:    machineId,
 		series:       series,
 		firewallMode: e.Config().FirewallMode(),
@@ -828,16 +840,18 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (instance.Ins
 	estate.insts[i.id] = i
 	estate.maxId++
 	estate.ops <- OpStartInstance{
-		Env:          e.name,
-		MachineId:    machineId,
-		MachineNonce: args.MachineConfig.MachineNonce,
-		Constraints:  args.Constraints,
-		Networks:     args.MachineConfig.Networks,
-		NetworkInfo:  networkInfo,
-		Instance:     i,
-		Info:         args.MachineConfig.StateInfo,
-		APIInfo:      args.MachineConfig.APIInfo,
-		Secret:       e.ecfg().secret(),
+		Env:            e.name,
+		MachineId:      machineId,
+		MachineNonce:   args.MachineConfig.MachineNonce,
+		Constraints:    args.Constraints,
+		Networks:       args.MachineConfig.Networks,
+		NetworkInfo:    networkInfo,
+		SubnetsToZones: args.SubnetsToZones,
+		Volumes:        args.Volumes,
+		Instance:       i,
+		Info:           args.MachineConfig.StateInfo,
+		APIInfo:        args.MachineConfig.APIInfo,
+		Secret:         e.ecfg().secret(),
 	}
 	return i, hc, networkInfo, nil
 }
@@ -949,6 +963,30 @@ func (env *environ) ListNetworks() ([]network.BasicInfo, error) {
 	return netInfo, nil
 }
 
+// Subnets implements environs.SubnetDiscoverer.
+func (env *environ) Subnets() ([]network.SubnetInfo, error) {
+	if err := env.checkBroken("Subnets"); err != nil {
+		return nil, err
+	}
+
+	estate, err := env.state()
+	if err != nil {
+		return nil, err
+	}
+	estate.mu.Lock()
+	defer estate.mu.Unlock()
+
+	subnetInfo := []network.SubnetInfo{
+		{CIDR: "0.10.0.0/24", ProviderId: "dummy-private", AvailabilityZones: []string{"zone1", "zone2"}},
+		{CIDR: "0.20.0.0/24", ProviderId: "dummy-public", AvailabilityZones: []string{"zone1"}},
+	}
+	estate.ops <- OpSubnets{
+		Env:  env.name,
+		Info: subnetInfo,
+	}
+	return subnetInfo, nil
+}
+
 func (e *environ) AllInstances() ([]instance.Instance, error) {
 	defer delay()
 	if err := e.checkBroken("AllInstances"); err != nil {
@@ -967,7 +1005,7 @@ func (e *environ) AllInstances() ([]instance.Instance, error) {
 	return insts, nil
 }
 
-func (e *environ) OpenPorts(ports []network.Port) error {
+func (e *environ) OpenPorts(ports []network.PortRange) error {
 	if mode := e.ecfg().FirewallMode(); mode != config.FwGlobal {
 		return fmt.Errorf("invalid firewall mode %q for opening ports on environment", mode)
 	}
@@ -983,7 +1021,7 @@ func (e *environ) OpenPorts(ports []network.Port) error {
 	return nil
 }
 
-func (e *environ) ClosePorts(ports []network.Port) error {
+func (e *environ) ClosePorts(ports []network.PortRange) error {
 	if mode := e.ecfg().FirewallMode(); mode != config.FwGlobal {
 		return fmt.Errorf("invalid firewall mode %q for closing ports on environment", mode)
 	}
@@ -999,7 +1037,7 @@ func (e *environ) ClosePorts(ports []network.Port) error {
 	return nil
 }
 
-func (e *environ) Ports() (ports []network.Port, err error) {
+func (e *environ) Ports() (ports []network.PortRange, err error) {
 	if mode := e.ecfg().FirewallMode(); mode != config.FwGlobal {
 		return nil, fmt.Errorf("invalid firewall mode %q for retrieving ports from environment", mode)
 	}
@@ -1012,7 +1050,7 @@ func (e *environ) Ports() (ports []network.Port, err error) {
 	for p := range estate.globalPorts {
 		ports = append(ports, p)
 	}
-	network.SortPorts(ports)
+	network.SortPortRanges(ports)
 	return
 }
 
@@ -1022,7 +1060,7 @@ func (*environ) Provider() environs.EnvironProvider {
 
 type dummyInstance struct {
 	state        *environState
-	ports        map[network.Port]bool
+	ports        map[network.PortRange]bool
 	id           instance.Id
 	status       string
 	machineId    string
@@ -1069,7 +1107,7 @@ func (inst *dummyInstance) Addresses() ([]network.Address, error) {
 	return append([]network.Address{}, inst.addresses...), nil
 }
 
-func (inst *dummyInstance) OpenPorts(machineId string, ports []network.Port) error {
+func (inst *dummyInstance) OpenPorts(machineId string, ports []network.PortRange) error {
 	defer delay()
 	logger.Infof("openPorts %s, %#v", machineId, ports)
 	if inst.firewallMode != config.FwInstance {
@@ -1093,7 +1131,7 @@ func (inst *dummyInstance) OpenPorts(machineId string, ports []network.Port) err
 	return nil
 }
 
-func (inst *dummyInstance) ClosePorts(machineId string, ports []network.Port) error {
+func (inst *dummyInstance) ClosePorts(machineId string, ports []network.PortRange) error {
 	defer delay()
 	if inst.firewallMode != config.FwInstance {
 		return fmt.Errorf("invalid firewall mode %q for closing ports on instance",
@@ -1116,7 +1154,7 @@ func (inst *dummyInstance) ClosePorts(machineId string, ports []network.Port) er
 	return nil
 }
 
-func (inst *dummyInstance) Ports(machineId string) (ports []network.Port, err error) {
+func (inst *dummyInstance) Ports(machineId string) (ports []network.PortRange, err error) {
 	defer delay()
 	if inst.firewallMode != config.FwInstance {
 		return nil, fmt.Errorf("invalid firewall mode %q for retrieving ports from instance",
@@ -1130,7 +1168,7 @@ func (inst *dummyInstance) Ports(machineId string) (ports []network.Port, err er
 	for p := range inst.ports {
 		ports = append(ports, p)
 	}
-	network.SortPorts(ports)
+	network.SortPortRanges(ports)
 	return
 }
 
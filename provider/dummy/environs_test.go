@@ -166,3 +166,28 @@ func assertListNetworks(c *gc.C, e environs.Environ, opc chan dummy.Operation, e
 		c.Fatalf("time out wating for operation")
 	}
 }
+
+func (s *suite) TestSubnets(c *gc.C) {
+	e := s.bootstrapTestEnviron(c)
+
+	opc := make(chan dummy.Operation, 200)
+	dummy.Listen(opc)
+
+	expectInfo := []network.SubnetInfo{
+		{CIDR: "0.10.0.0/24", ProviderId: "dummy-private", AvailabilityZones: []string{"zone1", "zone2"}},
+		{CIDR: "0.20.0.0/24", ProviderId: "dummy-public", AvailabilityZones: []string{"zone1"}},
+	}
+	subnetInfo, err := e.(environs.SubnetDiscoverer).Subnets()
+	c.Assert(err, gc.IsNil)
+	c.Assert(subnetInfo, jc.DeepEquals, expectInfo)
+	select {
+	case op := <-opc:
+		subnetsOp, ok := op.(dummy.OpSubnets)
+		if !ok {
+			c.Fatalf("unexpected op: %#v", op)
+		}
+		c.Check(subnetsOp.Info, jc.DeepEquals, expectInfo)
+	case <-time.After(testing.ShortWait):
+		c.Fatalf("time out wating for operation")
+	}
+}
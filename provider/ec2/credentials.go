@@ -0,0 +1,133 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package ec2
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/utils"
+	"launchpad.net/goamz/aws"
+	"launchpad.net/goamz/ec2"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+)
+
+var (
+	_ environs.CredentialDetector = environProvider{}
+	_ environs.CredentialChecker  = environProvider{}
+)
+
+// CredentialAttrs is specified in the environs.CredentialDetector interface.
+func (environProvider) CredentialAttrs() []environs.CredentialAttr {
+	return []environs.CredentialAttr{
+		{Name: "region"},
+		{Name: "access-key"},
+		{Name: "secret-key", Secret: true},
+	}
+}
+
+// DetectCredentials is specified in the environs.CredentialDetector
+// interface. The keys are taken from the AWS_* environment variables
+// if they are set, and otherwise from the profile named by AWS_PROFILE
+// (or the default profile) in ~/.aws/credentials, as used by the AWS
+// command line tools. The region is taken from AWS_DEFAULT_REGION or
+// the profile in ~/.aws/config.
+func (environProvider) DetectCredentials() (map[string]interface{}, error) {
+	attrs := make(map[string]interface{})
+	profile := os.Getenv("AWS_PROFILE")
+	if profile == "" {
+		profile = "default"
+	}
+	if auth, err := aws.EnvAuth(); err == nil {
+		attrs["access-key"] = auth.AccessKey
+		attrs["secret-key"] = auth.SecretKey
+	} else {
+		path := filepath.Join(utils.Home(), ".aws", "credentials")
+		values, err := readAWSProfile(path, profile)
+		if err != nil {
+			return nil, err
+		}
+		if values["aws_access_key_id"] != "" && values["aws_secret_access_key"] != "" {
+			attrs["access-key"] = values["aws_access_key_id"]
+			attrs["secret-key"] = values["aws_secret_access_key"]
+		}
+	}
+	if region := os.Getenv("AWS_DEFAULT_REGION"); region != "" {
+		attrs["region"] = region
+	} else {
+		// Profiles other than the default one are
+		// prefixed with "profile" in the config file.
+		section := profile
+		if profile != "default" {
+			section = "profile " + profile
+		}
+		path := filepath.Join(utils.Home(), ".aws", "config")
+		values, err := readAWSProfile(path, section)
+		if err != nil {
+			return nil, err
+		}
+		if values["region"] != "" {
+			attrs["region"] = values["region"]
+		}
+	}
+	return attrs, nil
+}
+
+// readAWSProfile returns the settings in the given section of the
+// INI-style file at path, as written by the AWS command line tools.
+// It returns no settings if the file does not exist.
+func readAWSProfile(path, section string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	values := make(map[string]string)
+	var inSection bool
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			inSection = strings.TrimSpace(line[1:len(line)-1]) == section
+		case inSection:
+			pos := strings.Index(line, "=")
+			if pos == -1 {
+				continue
+			}
+			key := strings.TrimSpace(line[:pos])
+			values[key] = strings.TrimSpace(line[pos+1:])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read %s: %v", path, err)
+	}
+	return values, nil
+}
+
+// CheckCredentials is specified in the environs.CredentialChecker
+// interface. It lists the security groups with a name no environment
+// uses, which needs valid keys but returns nothing.
+func (p environProvider) CheckCredentials(cfg *config.Config) error {
+	ecfg, err := p.newConfig(cfg)
+	if err != nil {
+		return err
+	}
+	client := ec2.New(aws.Auth{ecfg.accessKey(), ecfg.secretKey()}, aws.Regions[ecfg.region()])
+	filter := ec2.NewFilter()
+	filter.Add("group-name", "juju-credential-check")
+	if _, err := client.SecurityGroups(nil, filter); err != nil {
+		return fmt.Errorf("cannot use credentials in region %q: %v", ecfg.region(), err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package ec2_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/juju/utils"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/testing"
+)
+
+type credentialsSuite struct {
+	testing.BaseSuite
+	provider environs.CredentialDetector
+}
+
+var _ = gc.Suite(&credentialsSuite{})
+
+func (s *credentialsSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	utils.SetHome(c.MkDir())
+	for _, name := range []string{
+		"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY",
+		"AWS_ACCESS_KEY", "AWS_SECRET_KEY",
+		"EC2_ACCESS_KEY", "EC2_SECRET_KEY",
+		"AWS_PROFILE", "AWS_DEFAULT_REGION",
+	} {
+		s.PatchEnvironment(name, "")
+	}
+	provider, err := environs.Provider("ec2")
+	c.Assert(err, gc.IsNil)
+	s.provider = provider.(environs.CredentialDetector)
+}
+
+func (s *credentialsSuite) writeAWSFile(c *gc.C, name, content string) {
+	dir := filepath.Join(utils.Home(), ".aws")
+	err := os.MkdirAll(dir, 0700)
+	c.Assert(err, gc.IsNil)
+	err = ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *credentialsSuite) TestDetectCredentialsNone(c *gc.C) {
+	attrs, err := s.provider.DetectCredentials()
+	c.Assert(err, gc.IsNil)
+	c.Assert(attrs, gc.HasLen, 0)
+}
+
+func (s *credentialsSuite) TestDetectCredentialsFromEnvironment(c *gc.C) {
+	s.PatchEnvironment("AWS_ACCESS_KEY_ID", "key-id")
+	s.PatchEnvironment("AWS_SECRET_ACCESS_KEY", "secret")
+	s.PatchEnvironment("AWS_DEFAULT_REGION", "eu-west-1")
+	s.writeAWSFile(c, "credentials", "[default]\naws_access_key_id = other\naws_secret_access_key = other\n")
+	attrs, err := s.provider.DetectCredentials()
+	c.Assert(err, gc.IsNil)
+	c.Assert(attrs, gc.DeepEquals, map[string]interface{}{
+		"access-key": "key-id",
+		"secret-key": "secret",
+		"region":     "eu-west-1",
+	})
+}
+
+func (s *credentialsSuite) TestDetectCredentialsFromFiles(c *gc.C) {
+	s.writeAWSFile(c, "credentials", `
+# A comment.
+[default]
+aws_access_key_id = default-id
+aws_secret_access_key = default-secret
+
+[dev]
+aws_access_key_id=dev-id
+aws_secret_access_key=dev-secret
+`)
+	s.writeAWSFile(c, "config", `
+[default]
+region = us-west-2
+
+[profile dev]
+region = ap-southeast-1
+`)
+	attrs, err := s.provider.DetectCredentials()
+	c.Assert(err, gc.IsNil)
+	c.Assert(attrs, gc.DeepEquals, map[string]interface{}{
+		"access-key": "default-id",
+		"secret-key": "default-secret",
+		"region":     "us-west-2",
+	})
+
+	s.PatchEnvironment("AWS_PROFILE", "dev")
+	attrs, err = s.provider.DetectCredentials()
+	c.Assert(err, gc.IsNil)
+	c.Assert(attrs, gc.DeepEquals, map[string]interface{}{
+		"access-key": "dev-id",
+		"secret-key": "dev-secret",
+		"region":     "ap-southeast-1",
+	})
+}
+
+func (s *credentialsSuite) TestDetectCredentialsIncompleteProfile(c *gc.C) {
+	s.writeAWSFile(c, "credentials", "[default]\naws_access_key_id = default-id\n")
+	attrs, err := s.provider.DetectCredentials()
+	c.Assert(err, gc.IsNil)
+	c.Assert(attrs, gc.HasLen, 0)
+}
@@ -0,0 +1,112 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package ec2
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"launchpad.net/goamz/ec2"
+
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/storage"
+)
+
+// EBSProviderType is the type of the storage provider
+// that creates Elastic Block Store volumes.
+const EBSProviderType storage.ProviderType = "ebs"
+
+func init() {
+	storage.RegisterProvider(EBSProviderType, ebsProvider{})
+	storage.RegisterDefaultProvider("ec2", EBSProviderType)
+}
+
+// ebsDeviceNames holds the names of the devices to which EBS volumes
+// created along with an instance are mapped. The kernels of the
+// images we use rename the devices from sdX to xvdX.
+var ebsDeviceNames = []string{"f", "g", "h", "i", "j", "k", "l", "m", "n", "o", "p"}
+
+// ebsProvider creates EBS volumes when an instance is started,
+// through the instance's block device mappings. The volumes are
+// deleted when the instance is terminated.
+type ebsProvider struct{}
+
+// VolumeSource is defined on the storage.Provider interface.
+func (ebsProvider) VolumeSource(environConfig *config.Config, storageDir string) (storage.VolumeSource, error) {
+	return ebsVolumeSource{}, nil
+}
+
+// Scope is defined on the storage.Provider interface.
+func (ebsProvider) Scope() storage.Scope {
+	return storage.ScopeEnviron
+}
+
+// Dynamic is defined on the storage.Provider interface.
+func (ebsProvider) Dynamic() bool {
+	return false
+}
+
+type ebsVolumeSource struct{}
+
+var _ storage.VolumeSource = ebsVolumeSource{}
+
+// CreateVolumes is defined on the storage.VolumeSource interface.
+// EBS volumes are created when the instance they are attached to
+// is started, so CreateVolumes only describes volumes created with
+// the block device mappings from ebsBlockDeviceMappings.
+func (ebsVolumeSource) CreateVolumes(params []storage.VolumeParams) ([]storage.Volume, []storage.VolumeAttachment, error) {
+	if len(params) > len(ebsDeviceNames) {
+		return nil, nil, fmt.Errorf("too many EBS volumes: %d requested, %d allowed", len(params), len(ebsDeviceNames))
+	}
+	var volumes []storage.Volume
+	var attachments []storage.VolumeAttachment
+	for i, p := range params {
+		if p.Attachment == nil || p.Attachment.InstanceId == "" {
+			return nil, nil, fmt.Errorf("EBS volume %q must be created along with an instance", p.Name)
+		}
+		volumes = append(volumes, storage.Volume{
+			Name: p.Name,
+			Size: uint64(ebsVolumeSize(p.Size) * 1024),
+		})
+		attachments = append(attachments, storage.VolumeAttachment{
+			Volume:     p.Name,
+			Machine:    p.Attachment.Machine,
+			DeviceName: "xvd" + ebsDeviceNames[i],
+		})
+	}
+	return volumes, attachments, nil
+}
+
+// DestroyVolumes is defined on the storage.VolumeSource interface.
+// EBS volumes are deleted along with the instance they are attached
+// to, and cannot be destroyed before the instance is terminated.
+func (ebsVolumeSource) DestroyVolumes(volumeIds []string) error {
+	return errors.NotSupportedf("destroying EBS volumes before their instance is terminated")
+}
+
+// ebsVolumeSize returns the size in GiB of an EBS
+// volume that holds at least the given size in MiB.
+func ebsVolumeSize(size uint64) int64 {
+	return int64((size + 1023) / 1024)
+}
+
+// ebsBlockDeviceMappings returns the block device mappings
+// that create the given EBS volumes along with an instance.
+func ebsBlockDeviceMappings(params []storage.VolumeParams) ([]ec2.BlockDeviceMapping, error) {
+	if len(params) > len(ebsDeviceNames) {
+		return nil, fmt.Errorf("too many EBS volumes: %d requested, %d allowed", len(params), len(ebsDeviceNames))
+	}
+	var mappings []ec2.BlockDeviceMapping
+	for i, p := range params {
+		if p.Provider != EBSProviderType {
+			return nil, fmt.Errorf("volume %q is not an EBS volume", p.Name)
+		}
+		mappings = append(mappings, ec2.BlockDeviceMapping{
+			DeviceName:          "/dev/sd" + ebsDeviceNames[i],
+			VolumeSize:          ebsVolumeSize(p.Size),
+			DeleteOnTermination: true,
+		})
+	}
+	return mappings, nil
+}
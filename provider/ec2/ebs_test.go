@@ -0,0 +1,101 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package ec2
+
+import (
+	"strconv"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	"launchpad.net/goamz/ec2"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/storage"
+	"github.com/juju/juju/testing"
+)
+
+var _ = gc.Suite(&ebsSuite{})
+
+type ebsSuite struct {
+	testing.BaseSuite
+}
+
+func ebsVolumes(n int) []storage.VolumeParams {
+	var params []storage.VolumeParams
+	for i := 0; i < n; i++ {
+		params = append(params, storage.VolumeParams{
+			Name:     strconv.Itoa(i),
+			Size:     1500,
+			Provider: EBSProviderType,
+			Attachment: &storage.AttachmentParams{
+				Machine:    "1",
+				InstanceId: "i-1234",
+			},
+		})
+	}
+	return params
+}
+
+func (s *ebsSuite) TestProvider(c *gc.C) {
+	p, err := storage.StorageProvider(EBSProviderType)
+	c.Assert(err, gc.IsNil)
+	c.Assert(p.Scope(), gc.Equals, storage.ScopeEnviron)
+	c.Assert(p.Dynamic(), jc.IsFalse)
+	providerType, ok := storage.DefaultProvider("ec2")
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(providerType, gc.Equals, EBSProviderType)
+}
+
+func (s *ebsSuite) TestBlockDeviceMappings(c *gc.C) {
+	mappings, err := ebsBlockDeviceMappings(ebsVolumes(2))
+	c.Assert(err, gc.IsNil)
+	c.Assert(mappings, gc.DeepEquals, []ec2.BlockDeviceMapping{{
+		DeviceName:          "/dev/sdf",
+		VolumeSize:          2,
+		DeleteOnTermination: true,
+	}, {
+		DeviceName:          "/dev/sdg",
+		VolumeSize:          2,
+		DeleteOnTermination: true,
+	}})
+
+	_, err = ebsBlockDeviceMappings(ebsVolumes(12))
+	c.Assert(err, gc.ErrorMatches, "too many EBS volumes: 12 requested, 11 allowed")
+
+	params := ebsVolumes(1)
+	params[0].Provider = "loop"
+	_, err = ebsBlockDeviceMappings(params)
+	c.Assert(err, gc.ErrorMatches, `volume "0" is not an EBS volume`)
+}
+
+func (s *ebsSuite) TestCreateVolumes(c *gc.C) {
+	p, err := storage.StorageProvider(EBSProviderType)
+	c.Assert(err, gc.IsNil)
+	source, err := p.VolumeSource(testing.EnvironConfig(c), "")
+	c.Assert(err, gc.IsNil)
+	volumes, attachments, err := source.CreateVolumes(ebsVolumes(2))
+	c.Assert(err, gc.IsNil)
+	c.Assert(volumes, gc.DeepEquals, []storage.Volume{
+		{Name: "0", Size: 2048},
+		{Name: "1", Size: 2048},
+	})
+	c.Assert(attachments, gc.DeepEquals, []storage.VolumeAttachment{
+		{Volume: "0", Machine: "1", DeviceName: "xvdf"},
+		{Volume: "1", Machine: "1", DeviceName: "xvdg"},
+	})
+
+	params := ebsVolumes(1)
+	params[0].Attachment.InstanceId = ""
+	_, _, err = source.CreateVolumes(params)
+	c.Assert(err, gc.ErrorMatches, `EBS volume "0" must be created along with an instance`)
+}
+
+func (s *ebsSuite) TestDestroyVolumes(c *gc.C) {
+	p, err := storage.StorageProvider(EBSProviderType)
+	c.Assert(err, gc.IsNil)
+	source, err := p.VolumeSource(testing.EnvironConfig(c), "")
+	c.Assert(err, gc.IsNil)
+	err = source.DestroyVolumes([]string{"vol-0"})
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
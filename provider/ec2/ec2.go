@@ -5,6 +5,7 @@ package ec2
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -23,6 +24,7 @@ import (
 	"github.com/juju/juju/environs/instances"
 	"github.com/juju/juju/environs/simplestreams"
 	"github.com/juju/juju/environs/storage"
+	"github.com/juju/juju/environs/tags"
 	envtools "github.com/juju/juju/environs/tools"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/juju/arch"
@@ -51,6 +53,7 @@ var providerInstance environProvider
 
 type environ struct {
 	common.SupportsUnitPlacementPolicy
+	common.SupportsSourceCIDRsPolicy
 
 	name string
 
@@ -77,6 +80,7 @@ var _ imagemetadata.SupportsCustomSources = (*environ)(nil)
 var _ envtools.SupportsCustomSources = (*environ)(nil)
 var _ state.Prechecker = (*environ)(nil)
 var _ state.InstanceDistributor = (*environ)(nil)
+var _ common.ZonedEnviron = (*environ)(nil)
 
 type ec2Instance struct {
 	e *environ
@@ -567,6 +571,20 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (instance.Ins
 	if args.MachineConfig.HasNetworks() {
 		return nil, nil, nil, fmt.Errorf("starting instances with networks is not supported yet.")
 	}
+	// When the machine must be in particular spaces, it's started in
+	// one of their subnets, which may dictate the availability zone.
+	var subnetId network.Id
+	if len(args.SubnetsToZones) > 0 {
+		var err error
+		subnetId, availabilityZone, err = selectSubnet(args.SubnetsToZones, availabilityZone, args.Placement != "")
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	volumeMappings, err := ebsBlockDeviceMappings(args.Volumes)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 	arches := args.Tools.Arches()
 	stor := ebsStorage
 	sources, err := imagemetadata.GetMetadataSources(e)
@@ -608,16 +626,18 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (instance.Ins
 	var instResp *ec2.RunInstancesResp
 
 	device, diskSize := getDiskSize(args.Constraints)
+	blockDeviceMappings := append([]ec2.BlockDeviceMapping{device}, volumeMappings...)
 	for a := shortAttempt.Start(); a.Next(); {
 		instResp, err = e.ec2().RunInstances(&ec2.RunInstances{
 			AvailZone:           availabilityZone,
+			SubnetId:            string(subnetId),
 			ImageId:             spec.Image.Id,
 			MinCount:            1,
 			MaxCount:            1,
 			UserData:            userData,
 			InstanceType:        spec.InstanceType.Name,
 			SecurityGroups:      groups,
-			BlockDeviceMappings: []ec2.BlockDeviceMapping{device},
+			BlockDeviceMappings: blockDeviceMappings,
 		})
 		if err == nil || ec2ErrCode(err) != "InvalidGroup.NotFound" {
 			break
@@ -636,6 +656,16 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (instance.Ins
 	}
 	logger.Infof("started instance %q", inst.Id())
 
+	// An untagged instance would not be recognised as belonging
+	// to the environment, so it could never be stopped by juju.
+	instanceTags := tags.InstanceTags(cfg, args.MachineConfig)
+	if err := e.tagResources(instanceTags, string(inst.Id())); err != nil {
+		if err := e.terminateInstances([]instance.Id{inst.Id()}); err != nil {
+			logger.Errorf("cannot terminate untagged instance %q: %v", inst.Id(), err)
+		}
+		return nil, nil, nil, fmt.Errorf("cannot tag instance: %v", err)
+	}
+
 	hc := instance.HardwareCharacteristics{
 		Arch:     &spec.Image.Arch,
 		Mem:      &spec.InstanceType.Mem,
@@ -644,13 +674,73 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (instance.Ins
 		RootDisk: &diskSize,
 		// Tags currently not supported by EC2
 	}
+	if inst.Instance.AvailZone != "" {
+		hc.AvailabilityZone = &inst.Instance.AvailZone
+	}
 	return inst, &hc, nil, nil
 }
 
 func (e *environ) StopInstances(ids ...instance.Id) error {
+	if err := e.checkInstancesBelongToEnviron(ids); err != nil {
+		return err
+	}
 	return e.terminateInstances(ids)
 }
 
+// tagResources applies the given tags to the resources with the
+// given ids. Tagging is retried in case a newly created resource
+// is not yet visible to the EC2 API.
+func (e *environ) tagResources(resourceTags map[string]string, ids ...string) error {
+	if len(resourceTags) == 0 {
+		return nil
+	}
+	ec2Tags := make([]ec2.Tag, 0, len(resourceTags))
+	for key, value := range resourceTags {
+		ec2Tags = append(ec2Tags, ec2.Tag{Key: key, Value: value})
+	}
+	var err error
+	for a := shortAttempt.Start(); a.Next(); {
+		_, err = e.ec2().CreateTags(ids, ec2Tags)
+		if err == nil || !strings.HasSuffix(ec2ErrCode(err), ".NotFound") {
+			break
+		}
+	}
+	return err
+}
+
+// checkInstancesBelongToEnviron returns an error if any of
+// the given instances lacks the environment's UUID tag, to
+// prevent the environment destroying another's instances.
+// Instances that no longer exist are ignored.
+func (e *environ) checkInstancesBelongToEnviron(ids []instance.Id) error {
+	cfg := e.Config()
+	if _, ok := cfg.UUID(); !ok || len(ids) == 0 {
+		return nil
+	}
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		strs[i] = string(id)
+	}
+	filter := ec2.NewFilter()
+	filter.Add("instance-id", strs...)
+	resp, err := e.ec2().Instances(nil, filter)
+	if err != nil {
+		return err
+	}
+	for _, r := range resp.Reservations {
+		for _, inst := range r.Instances {
+			instTags := make(map[string]string)
+			for _, tag := range inst.Tags {
+				instTags[tag.Key] = tag.Value
+			}
+			if !tags.BelongsToEnviron(cfg, instTags) {
+				return fmt.Errorf("cannot stop instance %q: not tagged as belonging to environment %q", inst.InstanceId, cfg.Name())
+			}
+		}
+	}
+	return nil
+}
+
 // minDiskSize is the minimum/default size (in megabytes) for ec2 root disks.
 const minDiskSize uint64 = 8 * 1024
 
@@ -820,6 +910,52 @@ func (*environ) ListNetworks() ([]network.BasicInfo, error) {
 	return nil, errors.NotImplementedf("ListNetworks")
 }
 
+// Subnets implements environs.SubnetDiscoverer.
+func (e *environ) Subnets() ([]network.SubnetInfo, error) {
+	resp, err := e.ec2().Subnets(nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list subnets: %v", err)
+	}
+	results := make([]network.SubnetInfo, len(resp.Subnets))
+	for i, subnet := range resp.Subnets {
+		results[i] = network.SubnetInfo{
+			CIDR:              subnet.CIDRBlock,
+			ProviderId:        network.Id(subnet.Id),
+			AvailabilityZones: []string{subnet.AvailZone},
+		}
+	}
+	return results, nil
+}
+
+// selectSubnet chooses one of the given subnets for an instance to be
+// started in. A subnet usable in the given availability zone is
+// preferred; if there is none, and the zone was not explicitly asked
+// for, the zone is changed to that of the first subnet. Subnets are
+// considered in order of their ids, so the choice is predictable.
+func selectSubnet(subnetsToZones map[network.Id][]string, zone string, zoneRequired bool) (network.Id, string, error) {
+	ids := make([]string, 0, len(subnetsToZones))
+	for id := range subnetsToZones {
+		ids = append(ids, string(id))
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		for _, subnetZone := range subnetsToZones[network.Id(id)] {
+			if subnetZone == zone {
+				return network.Id(id), zone, nil
+			}
+		}
+	}
+	if zoneRequired {
+		return "", "", fmt.Errorf("no subnets in availability zone %q", zone)
+	}
+	for _, id := range ids {
+		if zones := subnetsToZones[network.Id(id)]; len(zones) > 0 {
+			return network.Id(id), zones[0], nil
+		}
+	}
+	return "", "", fmt.Errorf("no subnets with an availability zone")
+}
+
 func (e *environ) AllInstances() ([]instance.Instance, error) {
 	filter := ec2.NewFilter()
 	filter.Add("instance-state-name", "pending", "running")
@@ -849,24 +985,32 @@ func (e *environ) Destroy() error {
 	return common.Destroy(e)
 }
 
-func portsToIPPerms(ports []network.Port) []ec2.IPPerm {
+// anySource is the source CIDR that allows access from anywhere.
+const anySource = "0.0.0.0/0"
+
+func portsToIPPerms(ports []network.PortRange) []ec2.IPPerm {
 	ipPerms := make([]ec2.IPPerm, len(ports))
 	for i, p := range ports {
+		source := p.SourceCIDR
+		if source == "" {
+			source = anySource
+		}
 		ipPerms[i] = ec2.IPPerm{
 			Protocol:  p.Protocol,
-			FromPort:  p.Number,
-			ToPort:    p.Number,
-			SourceIPs: []string{"0.0.0.0/0"},
+			FromPort:  p.FromPort,
+			ToPort:    p.ToPort,
+			SourceIPs: []string{source},
 		}
 	}
 	return ipPerms
 }
 
-func (e *environ) openPortsInGroup(name string, ports []network.Port) error {
+func (e *environ) openPortsInGroup(name string, ports []network.PortRange) error {
 	if len(ports) == 0 {
 		return nil
 	}
-	// Give permissions for anyone to access the given ports.
+	// Give permissions to access the given ports from their
+	// source CIDRs, or from anywhere if they have none.
 	g, err := e.groupByName(name)
 	if err != nil {
 		return err
@@ -895,11 +1039,11 @@ func (e *environ) openPortsInGroup(name string, ports []network.Port) error {
 	return nil
 }
 
-func (e *environ) closePortsInGroup(name string, ports []network.Port) error {
+func (e *environ) closePortsInGroup(name string, ports []network.PortRange) error {
 	if len(ports) == 0 {
 		return nil
 	}
-	// Revoke permissions for anyone to access the given ports.
+	// Revoke permissions to access the given ports.
 	// Note that ec2 allows the revocation of permissions that aren't
 	// granted, so this is naturally idempotent.
 	g, err := e.groupByName(name)
@@ -913,28 +1057,33 @@ func (e *environ) closePortsInGroup(name string, ports []network.Port) error {
 	return nil
 }
 
-func (e *environ) portsInGroup(name string) (ports []network.Port, err error) {
+func (e *environ) portsInGroup(name string) (ports []network.PortRange, err error) {
 	group, err := e.groupInfoByName(name)
 	if err != nil {
 		return nil, err
 	}
 	for _, p := range group.IPPerms {
-		if len(p.SourceIPs) != 1 {
+		if len(p.SourceIPs) == 0 {
 			logger.Warningf("unexpected IP permission found: %v", p)
 			continue
 		}
-		for i := p.FromPort; i <= p.ToPort; i++ {
-			ports = append(ports, network.Port{
-				Protocol: p.Protocol,
-				Number:   i,
+		for _, source := range p.SourceIPs {
+			if source == anySource {
+				source = ""
+			}
+			ports = append(ports, network.PortRange{
+				Protocol:   p.Protocol,
+				FromPort:   p.FromPort,
+				ToPort:     p.ToPort,
+				SourceCIDR: source,
 			})
 		}
 	}
-	network.SortPorts(ports)
+	network.SortPortRanges(ports)
 	return ports, nil
 }
 
-func (e *environ) OpenPorts(ports []network.Port) error {
+func (e *environ) OpenPorts(ports []network.PortRange) error {
 	if e.Config().FirewallMode() != config.FwGlobal {
 		return fmt.Errorf("invalid firewall mode %q for opening ports on environment",
 			e.Config().FirewallMode())
@@ -946,7 +1095,7 @@ func (e *environ) OpenPorts(ports []network.Port) error {
 	return nil
 }
 
-func (e *environ) ClosePorts(ports []network.Port) error {
+func (e *environ) ClosePorts(ports []network.PortRange) error {
 	if e.Config().FirewallMode() != config.FwGlobal {
 		return fmt.Errorf("invalid firewall mode %q for closing ports on environment",
 			e.Config().FirewallMode())
@@ -958,7 +1107,7 @@ func (e *environ) ClosePorts(ports []network.Port) error {
 	return nil
 }
 
-func (e *environ) Ports() ([]network.Port, error) {
+func (e *environ) Ports() ([]network.PortRange, error) {
 	if e.Config().FirewallMode() != config.FwGlobal {
 		return nil, fmt.Errorf("invalid firewall mode %q for retrieving ports from environment",
 			e.Config().FirewallMode())
@@ -1017,7 +1166,7 @@ func (e *environ) jujuGroupName() string {
 	return "juju-" + e.name
 }
 
-func (inst *ec2Instance) OpenPorts(machineId string, ports []network.Port) error {
+func (inst *ec2Instance) OpenPorts(machineId string, ports []network.PortRange) error {
 	if inst.e.Config().FirewallMode() != config.FwInstance {
 		return fmt.Errorf("invalid firewall mode %q for opening ports on instance",
 			inst.e.Config().FirewallMode())
@@ -1030,7 +1179,7 @@ func (inst *ec2Instance) OpenPorts(machineId string, ports []network.Port) error
 	return nil
 }
 
-func (inst *ec2Instance) ClosePorts(machineId string, ports []network.Port) error {
+func (inst *ec2Instance) ClosePorts(machineId string, ports []network.PortRange) error {
 	if inst.e.Config().FirewallMode() != config.FwInstance {
 		return fmt.Errorf("invalid firewall mode %q for closing ports on instance",
 			inst.e.Config().FirewallMode())
@@ -1043,7 +1192,7 @@ func (inst *ec2Instance) ClosePorts(machineId string, ports []network.Port) erro
 	return nil
 }
 
-func (inst *ec2Instance) Ports(machineId string) ([]network.Port, error) {
+func (inst *ec2Instance) Ports(machineId string) ([]network.PortRange, error) {
 	if inst.e.Config().FirewallMode() != config.FwInstance {
 		return nil, fmt.Errorf("invalid firewall mode %q for retrieving ports from instance",
 			inst.e.Config().FirewallMode())
@@ -1130,6 +1279,9 @@ func (e *environ) ensureGroup(name string, perms []ec2.IPPerm) (g ec2.SecurityGr
 	var have permSet
 	if err == nil {
 		g = resp.SecurityGroup
+		if err := e.tagResources(tags.ResourceTags(e.Config()), g.Id); err != nil {
+			return zeroGroup, fmt.Errorf("cannot tag security group: %v", err)
+		}
 	} else {
 		resp, err := ec2inst.SecurityGroups(ec2.SecurityGroupNames(name), nil)
 		if err != nil {
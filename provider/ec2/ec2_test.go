@@ -8,6 +8,7 @@ import (
 	gc "launchpad.net/gocheck"
 
 	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/network"
 )
 
 type Suite struct{}
@@ -61,3 +62,34 @@ func (*Suite) TestRootDisk(c *gc.C) {
 func pInt(i uint64) *uint64 {
 	return &i
 }
+
+func (*Suite) TestSelectSubnet(c *gc.C) {
+	subnetsToZones := map[network.Id][]string{
+		"subnet-b": {"zone1"},
+		"subnet-a": {"zone2"},
+		"subnet-c": {"zone1", "zone3"},
+	}
+	for i, test := range []struct {
+		zone         string
+		zoneRequired bool
+		subnetId     network.Id
+		chosenZone   string
+		err          string
+	}{
+		{zone: "zone1", subnetId: "subnet-b", chosenZone: "zone1"},
+		{zone: "zone3", zoneRequired: true, subnetId: "subnet-c", chosenZone: "zone3"},
+		{zone: "zone4", subnetId: "subnet-a", chosenZone: "zone2"},
+		{zone: "", subnetId: "subnet-a", chosenZone: "zone2"},
+		{zone: "zone4", zoneRequired: true, err: `no subnets in availability zone "zone4"`},
+	} {
+		c.Logf("test %d: zone %q, required %v", i, test.zone, test.zoneRequired)
+		subnetId, zone, err := selectSubnet(subnetsToZones, test.zone, test.zoneRequired)
+		if test.err != "" {
+			c.Check(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Check(err, gc.IsNil)
+		c.Check(subnetId, gc.Equals, test.subnetId)
+		c.Check(zone, gc.Equals, test.chosenZone)
+	}
+}
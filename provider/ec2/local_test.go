@@ -335,6 +335,66 @@ func (t *localServerSuite) TestStartInstanceHardwareCharacteristics(c *gc.C) {
 	c.Assert(*hc.CpuPower, gc.Equals, uint64(100))
 }
 
+func (t *localServerSuite) TestStartInstanceTags(c *gc.C) {
+	t.PatchValue(&t.TestConfig, t.TestConfig.Merge(coretesting.Attrs{
+		"resource-tags": "owner=finance",
+	}))
+	env := t.Prepare(c)
+	envtesting.UploadFakeTools(c, env.Storage())
+	err := bootstrap.Bootstrap(coretesting.Context(c), env, environs.BootstrapParams{})
+	c.Assert(err, gc.IsNil)
+	inst, _ := testing.AssertStartInstance(c, env, "1")
+
+	uuid, ok := env.Config().UUID()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(t.instanceTags(c, env, inst.Id()), jc.DeepEquals, map[string]string{
+		"owner":           "finance",
+		"juju-env-uuid":   uuid,
+		"juju-machine-id": "1",
+		"juju-is-state":   "false",
+	})
+
+	bootstrapState, err := bootstrap.LoadState(env.Storage())
+	c.Assert(err, gc.IsNil)
+	c.Assert(t.instanceTags(c, env, bootstrapState.StateInstances[0]), jc.DeepEquals, map[string]string{
+		"owner":           "finance",
+		"juju-env-uuid":   uuid,
+		"juju-machine-id": "0",
+		"juju-is-state":   "true",
+	})
+}
+
+func (t *localServerSuite) TestStopInstancesNotBelongingToEnviron(c *gc.C) {
+	env := t.Prepare(c)
+	envtesting.UploadFakeTools(c, env.Storage())
+	err := bootstrap.Bootstrap(coretesting.Context(c), env, environs.BootstrapParams{})
+	c.Assert(err, gc.IsNil)
+	inst, _ := testing.AssertStartInstance(c, env, "1")
+	_, err = ec2.EnvironEC2(env).CreateTags(
+		[]string{string(inst.Id())},
+		[]amzec2.Tag{{Key: "juju-env-uuid", Value: "another-environment"}},
+	)
+	c.Assert(err, gc.IsNil)
+
+	err = env.StopInstances(inst.Id())
+	c.Assert(err, gc.ErrorMatches, `cannot stop instance ".*": not tagged as belonging to environment "sample"`)
+	insts, err := env.Instances([]instance.Id{inst.Id()})
+	c.Assert(err, gc.IsNil)
+	c.Assert(insts[0].Status(), gc.Not(gc.Equals), "terminated")
+}
+
+func (t *localServerSuite) instanceTags(c *gc.C, env environs.Environ, id instance.Id) map[string]string {
+	resp, err := ec2.EnvironEC2(env).Instances([]string{string(id)}, nil)
+	c.Assert(err, gc.IsNil)
+	c.Assert(resp.Reservations, gc.HasLen, 1)
+	c.Assert(resp.Reservations[0].Instances, gc.HasLen, 1)
+	tags := make(map[string]string)
+	for _, tag := range resp.Reservations[0].Instances[0].Tags {
+		tags[tag.Key] = tag.Value
+	}
+	return tags
+}
+
 func (t *localServerSuite) TestStartInstanceAvailZone(c *gc.C) {
 	inst, err := t.testStartInstanceAvailZone(c, "test-available")
 	c.Assert(err, gc.IsNil)
@@ -608,6 +668,15 @@ func (t *localServerSuite) TestPrecheckInstanceAvailZoneUnknown(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, `invalid availability zone "test-unknown"`)
 }
 
+func (t *localServerSuite) TestCheckCredentials(c *gc.C) {
+	cfg, err := config.New(config.NoDefaults, localConfigAttrs)
+	c.Assert(err, gc.IsNil)
+	provider, err := environs.Provider("ec2")
+	c.Assert(err, gc.IsNil)
+	err = provider.(environs.CredentialChecker).CheckCredentials(cfg)
+	c.Assert(err, gc.IsNil)
+}
+
 func (t *localServerSuite) TestValidateImageMetadata(c *gc.C) {
 	env := t.Prepare(c)
 	params, err := env.(simplestreams.MetadataValidator).MetadataLookupParams("test")
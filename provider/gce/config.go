@@ -0,0 +1,176 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package gce
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+
+	"github.com/juju/schema"
+	"github.com/juju/utils"
+
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/provider/gce/google"
+)
+
+var configFields = schema.Fields{
+	"project-id":     schema.String(),
+	"region":         schema.String(),
+	"control-bucket": schema.String(),
+	"auth-file":      schema.String(),
+	"client-id":      schema.String(),
+	"client-email":   schema.String(),
+	"private-key":    schema.String(),
+}
+
+var configDefaults = schema.Defaults{
+	"region":       "us-central1",
+	"auth-file":    schema.Omit,
+	"client-id":    "",
+	"client-email": "",
+	"private-key":  "",
+}
+
+var configSecretFields = []string{
+	"client-id",
+	"client-email",
+	"private-key",
+}
+
+var configImmutableFields = []string{
+	"project-id",
+	"region",
+	"control-bucket",
+}
+
+// validEnvironName matches the environment names that can be used
+// in the names of GCE resources. The length is limited so that the
+// names of instances and firewall rules fit in 63 characters.
+var validEnvironName = regexp.MustCompile(`^[a-z]([-a-z0-9]{0,28}[a-z0-9])?$`)
+
+type environConfig struct {
+	*config.Config
+	attrs map[string]interface{}
+}
+
+func (c *environConfig) projectId() string {
+	return c.attrs["project-id"].(string)
+}
+
+func (c *environConfig) region() string {
+	return c.attrs["region"].(string)
+}
+
+func (c *environConfig) controlBucket() string {
+	return c.attrs["control-bucket"].(string)
+}
+
+func (c *environConfig) authFile() string {
+	path, _ := c.attrs["auth-file"].(string)
+	return path
+}
+
+func (c *environConfig) clientId() string {
+	return c.attrs["client-id"].(string)
+}
+
+func (c *environConfig) clientEmail() string {
+	return c.attrs["client-email"].(string)
+}
+
+func (c *environConfig) privateKey() string {
+	return c.attrs["private-key"].(string)
+}
+
+// credentials returns the service account credentials used to
+// access the Google APIs.
+func (c *environConfig) credentials() *google.Credentials {
+	return &google.Credentials{
+		ClientId:    c.clientId(),
+		ClientEmail: c.clientEmail(),
+		PrivateKey:  []byte(c.privateKey()),
+	}
+}
+
+func (p environProvider) newConfig(cfg *config.Config) (*environConfig, error) {
+	valid, err := p.Validate(cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &environConfig{valid, valid.UnknownAttrs()}, nil
+}
+
+func (p environProvider) Validate(cfg, old *config.Config) (valid *config.Config, err error) {
+	// Check for valid changes for the base config values.
+	if err := config.Validate(cfg, old); err != nil {
+		return nil, err
+	}
+	if !validEnvironName.MatchString(cfg.Name()) {
+		return nil, fmt.Errorf("environment name %q is not valid on GCE: "+
+			"it must be at most 30 lower case letters, digits and hyphens, starting with a letter", cfg.Name())
+	}
+	validated, err := cfg.ValidateUnknownAttrs(configFields, configDefaults)
+	if err != nil {
+		return nil, err
+	}
+	ecfg := &environConfig{cfg, validated}
+	if ecfg.projectId() == "" {
+		return nil, fmt.Errorf("project-id: must not be empty")
+	}
+	if ecfg.controlBucket() == "" {
+		return nil, fmt.Errorf("control-bucket: must not be empty")
+	}
+	if ecfg.clientEmail() == "" || ecfg.privateKey() == "" {
+		if err := readAuthFile(ecfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if old != nil {
+		attrs := old.UnknownAttrs()
+		for _, field := range configImmutableFields {
+			if value, _ := attrs[field].(string); ecfg.attrs[field] != value {
+				return nil, fmt.Errorf("cannot change %s from %q to %q", field, value, ecfg.attrs[field])
+			}
+		}
+	}
+
+	// Apply the coerced unknown values back into the config.
+	return cfg.Apply(ecfg.attrs)
+}
+
+// readAuthFile fills in the credentials missing from ecfg with
+// those in the service account's JSON key file named by the
+// auth-file attribute.
+func readAuthFile(ecfg *environConfig) error {
+	if ecfg.authFile() == "" {
+		return fmt.Errorf("environment has no auth-file, or client-email and private-key")
+	}
+	path, err := utils.NormalizePath(ecfg.authFile())
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read auth-file: %v", err)
+	}
+	creds, err := google.ParseJSONKey(data)
+	if err != nil {
+		return fmt.Errorf("invalid auth-file %q: %v", path, err)
+	}
+	if creds.ClientEmail == "" || len(creds.PrivateKey) == 0 {
+		return fmt.Errorf("invalid auth-file %q: no client email or private key", path)
+	}
+	if ecfg.clientId() == "" {
+		ecfg.attrs["client-id"] = creds.ClientId
+	}
+	if ecfg.clientEmail() == "" {
+		ecfg.attrs["client-email"] = creds.ClientEmail
+	}
+	if ecfg.privateKey() == "" {
+		ecfg.attrs["private-key"] = string(creds.PrivateKey)
+	}
+	return nil
+}
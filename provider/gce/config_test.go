@@ -0,0 +1,170 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package gce_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/environs/configstore"
+	"github.com/juju/juju/provider/gce"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type ConfigSuite struct {
+	coretesting.FakeJujuHomeSuite
+}
+
+var _ = gc.Suite(&ConfigSuite{})
+
+func validAttrs() coretesting.Attrs {
+	return coretesting.FakeConfig().Merge(coretesting.Attrs{
+		"name":           "sample",
+		"type":           "gce",
+		"project-id":     "test-project",
+		"control-bucket": "test-bucket",
+		"client-email":   "juju@test-project.example.com",
+		"private-key":    testPrivateKey,
+	})
+}
+
+func newConfig(c *gc.C, attrs coretesting.Attrs) *config.Config {
+	cfg, err := config.New(config.UseDefaults, attrs)
+	c.Assert(err, gc.IsNil)
+	return cfg
+}
+
+var newConfigTests = []struct {
+	info   string
+	insert coretesting.Attrs
+	remove []string
+	expect coretesting.Attrs
+	err    string
+}{{
+	info:   "region defaults to us-central1",
+	expect: coretesting.Attrs{"region": "us-central1"},
+}, {
+	info:   "region is untouched if present",
+	insert: coretesting.Attrs{"region": "europe-west1"},
+	expect: coretesting.Attrs{"region": "europe-west1"},
+}, {
+	info:   "project-id is required",
+	remove: []string{"project-id"},
+	err:    ".*project-id: expected string, got nothing",
+}, {
+	info:   "project-id cannot be empty",
+	insert: coretesting.Attrs{"project-id": ""},
+	err:    "project-id: must not be empty",
+}, {
+	info:   "control-bucket cannot be empty",
+	insert: coretesting.Attrs{"control-bucket": ""},
+	err:    "control-bucket: must not be empty",
+}, {
+	info:   "credentials are required",
+	remove: []string{"private-key"},
+	err:    "environment has no auth-file, or client-email and private-key",
+}, {
+	info:   "invalid private key",
+	insert: coretesting.Attrs{"private-key": "not a key"},
+	err:    "cannot connect to GCE: .*",
+}, {
+	info:   "environment name must be usable in GCE names",
+	insert: coretesting.Attrs{"name": "Sample_env"},
+	err:    `environment name "Sample_env" is not valid on GCE: .*`,
+}, {
+	info:   "unknown field is not touched",
+	insert: coretesting.Attrs{"unknown-field": 12345},
+	expect: coretesting.Attrs{"unknown-field": 12345},
+}}
+
+func (s *ConfigSuite) TestNewEnvironConfig(c *gc.C) {
+	for i, test := range newConfigTests {
+		c.Logf("test %d: %s", i, test.info)
+		attrs := validAttrs().Merge(test.insert).Delete(test.remove...)
+		environ, err := environs.New(newConfig(c, attrs))
+		if test.err == "" {
+			c.Check(err, gc.IsNil)
+			if err != nil {
+				continue
+			}
+			attrs := environ.Config().AllAttrs()
+			for field, value := range test.expect {
+				c.Check(attrs[field], gc.Equals, value)
+			}
+		} else {
+			c.Check(environ, gc.IsNil)
+			c.Check(err, gc.ErrorMatches, test.err)
+		}
+	}
+}
+
+func (s *ConfigSuite) TestAuthFile(c *gc.C) {
+	data, err := json.Marshal(map[string]string{
+		"private_key_id": "abcdef",
+		"private_key":    testPrivateKey,
+		"client_email":   "juju@test-project.example.com",
+		"client_id":      "1234.example.com",
+		"type":           "service_account",
+	})
+	c.Assert(err, gc.IsNil)
+	path := filepath.Join(c.MkDir(), "key.json")
+	err = ioutil.WriteFile(path, data, 0600)
+	c.Assert(err, gc.IsNil)
+
+	attrs := validAttrs().Delete("client-email", "private-key").Merge(coretesting.Attrs{
+		"auth-file": path,
+	})
+	environ, err := environs.New(newConfig(c, attrs))
+	c.Assert(err, gc.IsNil)
+	all := environ.Config().AllAttrs()
+	c.Check(all["client-id"], gc.Equals, "1234.example.com")
+	c.Check(all["client-email"], gc.Equals, "juju@test-project.example.com")
+	c.Check(all["private-key"], gc.Equals, testPrivateKey)
+
+	attrs["auth-file"] = filepath.Join(c.MkDir(), "missing.json")
+	_, err = environs.New(newConfig(c, attrs))
+	c.Assert(err, gc.ErrorMatches, "cannot read auth-file: .*")
+}
+
+func (s *ConfigSuite) TestValidateChange(c *gc.C) {
+	baseConfig := newConfig(c, validAttrs())
+	for _, field := range []string{"project-id", "region", "control-bucket"} {
+		c.Logf("changing %s", field)
+		testConfig := newConfig(c, validAttrs().Merge(coretesting.Attrs{field: "changed"}))
+		_, err := gce.Provider.Validate(testConfig, baseConfig)
+		c.Check(err, gc.ErrorMatches, `cannot change `+field+` from ".*" to "changed"`)
+	}
+	testConfig := newConfig(c, validAttrs().Merge(coretesting.Attrs{"client-email": "other@example.com"}))
+	validated, err := gce.Provider.Validate(testConfig, baseConfig)
+	c.Assert(err, gc.IsNil)
+	c.Check(validated.AllAttrs()["client-email"], gc.Equals, "other@example.com")
+}
+
+func (s *ConfigSuite) TestPrepareSetsControlBucket(c *gc.C) {
+	attrs := validAttrs().Delete("control-bucket")
+	env, err := environs.Prepare(newConfig(c, attrs), coretesting.Context(c), configstore.NewMem())
+	c.Assert(err, gc.IsNil)
+	c.Assert(gce.ControlBucketName(env), gc.Matches, "juju-[0-9a-f]{32}")
+}
+
+func (s *ConfigSuite) TestSecretAttrs(c *gc.C) {
+	secrets, err := gce.Provider.SecretAttrs(newConfig(c, validAttrs()))
+	c.Assert(err, gc.IsNil)
+	c.Assert(secrets, jc.DeepEquals, map[string]string{
+		"client-id":    "",
+		"client-email": "juju@test-project.example.com",
+		"private-key":  testPrivateKey,
+	})
+}
+
+func (s *ConfigSuite) TestBoilerplateConfig(c *gc.C) {
+	boilerplate := gce.Provider.BoilerplateConfig()
+	c.Assert(boilerplate, gc.Matches, "(?s)# https://juju.ubuntu.com/docs/config-gce.html\ngce:\n    type: gce\n.*")
+}
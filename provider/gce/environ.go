@@ -0,0 +1,259 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package gce
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/environs/imagemetadata"
+	"github.com/juju/juju/environs/simplestreams"
+	"github.com/juju/juju/environs/storage"
+	envtools "github.com/juju/juju/environs/tools"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/juju/arch"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/provider/common"
+	"github.com/juju/juju/provider/gce/google"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api"
+)
+
+// imageEndpoint is the endpoint under which GCE images are
+// described in simplestreams.
+const imageEndpoint = "https://www.googleapis.com"
+
+// endpoints holds the URLs of the Google APIs used by the provider.
+var endpoints = google.DefaultEndpoints
+
+type environ struct {
+	common.SupportsUnitPlacementPolicy
+	common.SupportsSourceCIDRsPolicy
+
+	name string
+
+	// archMutex gates access to supportedArchitectures
+	archMutex sync.Mutex
+	// supportedArchitectures caches the architectures
+	// for which images can be instantiated.
+	supportedArchitectures []string
+
+	// ecfgMutex protects the *Unlocked fields below.
+	ecfgMutex       sync.Mutex
+	ecfgUnlocked    *environConfig
+	connUnlocked    *google.Connection
+	storageUnlocked storage.Storage
+
+	zonesMutex sync.Mutex
+	zones      []common.AvailabilityZone
+}
+
+var _ environs.Environ = (*environ)(nil)
+var _ simplestreams.HasRegion = (*environ)(nil)
+var _ imagemetadata.SupportsCustomSources = (*environ)(nil)
+var _ envtools.SupportsCustomSources = (*environ)(nil)
+var _ state.Prechecker = (*environ)(nil)
+var _ state.InstanceDistributor = (*environ)(nil)
+var _ common.ZonedEnviron = (*environ)(nil)
+
+func (e *environ) Name() string {
+	return e.name
+}
+
+func (*environ) Provider() environs.EnvironProvider {
+	return providerInstance
+}
+
+func (e *environ) Config() *config.Config {
+	return e.ecfg().Config
+}
+
+func (e *environ) SetConfig(cfg *config.Config) error {
+	ecfg, err := providerInstance.newConfig(cfg)
+	if err != nil {
+		return err
+	}
+	conn, err := google.NewConnection(ecfg.credentials(), ecfg.projectId(), endpoints)
+	if err != nil {
+		return fmt.Errorf("cannot connect to GCE: %v", err)
+	}
+	e.ecfgMutex.Lock()
+	defer e.ecfgMutex.Unlock()
+	e.ecfgUnlocked = ecfg
+	e.connUnlocked = conn
+	// create new storage instances, existing instances continue
+	// to reference their existing configuration.
+	e.storageUnlocked = &gceStorage{
+		conn:   conn,
+		bucket: ecfg.controlBucket(),
+	}
+	return nil
+}
+
+func (e *environ) ecfg() *environConfig {
+	e.ecfgMutex.Lock()
+	ecfg := e.ecfgUnlocked
+	e.ecfgMutex.Unlock()
+	return ecfg
+}
+
+func (e *environ) conn() *google.Connection {
+	e.ecfgMutex.Lock()
+	conn := e.connUnlocked
+	e.ecfgMutex.Unlock()
+	return conn
+}
+
+func (e *environ) Storage() storage.Storage {
+	e.ecfgMutex.Lock()
+	stor := e.storageUnlocked
+	e.ecfgMutex.Unlock()
+	return stor
+}
+
+func (e *environ) Bootstrap(ctx environs.BootstrapContext, args environs.BootstrapParams) error {
+	return common.Bootstrap(ctx, e, args)
+}
+
+func (e *environ) StateInfo() (*state.Info, *api.Info, error) {
+	return common.StateInfo(e)
+}
+
+// Destroy shuts down all the environment's instances, removes its
+// storage and then the firewall rules made for it.
+func (e *environ) Destroy() error {
+	if err := common.Destroy(e); err != nil {
+		return err
+	}
+	return e.removeFirewalls()
+}
+
+// SupportedArchitectures is specified on the EnvironCapability interface.
+func (e *environ) SupportedArchitectures() ([]string, error) {
+	e.archMutex.Lock()
+	defer e.archMutex.Unlock()
+	if e.supportedArchitectures != nil {
+		return e.supportedArchitectures, nil
+	}
+	// Create a filter to get all images from our region and for the correct stream.
+	cloudSpec, err := e.Region()
+	if err != nil {
+		return nil, err
+	}
+	imageConstraint := imagemetadata.NewImageConstraint(simplestreams.LookupParams{
+		CloudSpec: cloudSpec,
+		Stream:    e.Config().ImageStream(),
+	})
+	e.supportedArchitectures, err = common.SupportedArchitectures(e, imageConstraint)
+	return e.supportedArchitectures, err
+}
+
+// SupportNetworks is specified on the EnvironCapability interface.
+func (e *environ) SupportNetworks() bool {
+	return false
+}
+
+var unsupportedConstraints = []string{
+	constraints.Tags,
+}
+
+// ConstraintsValidator is defined on the Environs interface.
+func (e *environ) ConstraintsValidator() (constraints.Validator, error) {
+	validator := constraints.NewValidator()
+	validator.RegisterConflicts(
+		[]string{constraints.InstanceType},
+		[]string{constraints.Mem, constraints.CpuCores, constraints.CpuPower})
+	validator.RegisterUnsupported(unsupportedConstraints)
+	supportedArches, err := e.SupportedArchitectures()
+	if err != nil {
+		return nil, err
+	}
+	validator.RegisterVocabulary(constraints.Arch, supportedArches)
+	instTypeNames := make([]string, len(allInstanceTypes))
+	for i, itype := range allInstanceTypes {
+		instTypeNames[i] = itype.Name
+	}
+	validator.RegisterVocabulary(constraints.InstanceType, instTypeNames)
+	return validator, nil
+}
+
+// PrecheckInstance is defined on the state.Prechecker interface.
+func (e *environ) PrecheckInstance(series string, cons constraints.Value, placement string) error {
+	if placement != "" {
+		if _, err := e.parsePlacement(placement); err != nil {
+			return err
+		}
+	}
+	if !cons.HasInstanceType() {
+		return nil
+	}
+	// Constraint has an instance-type constraint so let's see if it is valid.
+	for _, itype := range allInstanceTypes {
+		if itype.Name != *cons.InstanceType {
+			continue
+		}
+		if cons.Arch == nil || *cons.Arch == arch.AMD64 {
+			return nil
+		}
+		return fmt.Errorf("invalid GCE instance type %q and arch %q specified", *cons.InstanceType, *cons.Arch)
+	}
+	return fmt.Errorf("invalid GCE instance type %q specified", *cons.InstanceType)
+}
+
+// AllocateAddress requests a new address to be allocated for the
+// given instance on the given network. This is not implemented by the
+// GCE provider yet.
+func (*environ) AllocateAddress(_ instance.Id, _ network.Id) (network.Address, error) {
+	return network.Address{}, errors.NotImplementedf("AllocateAddress")
+}
+
+// ListNetworks returns basic information about all networks known
+// by the provider for the environment. They may be unknown to juju
+// yet (i.e. when called initially or when a new network was created).
+// This is not implemented by the GCE provider yet.
+func (*environ) ListNetworks() ([]network.BasicInfo, error) {
+	return nil, errors.NotImplementedf("ListNetworks")
+}
+
+// MetadataLookupParams returns parameters which are used to query simplestreams metadata.
+func (e *environ) MetadataLookupParams(region string) (*simplestreams.MetadataLookupParams, error) {
+	if region == "" {
+		region = e.ecfg().region()
+	}
+	return &simplestreams.MetadataLookupParams{
+		Series:        config.PreferredSeries(e.ecfg()),
+		Region:        region,
+		Endpoint:      imageEndpoint,
+		Architectures: []string{arch.AMD64},
+	}, nil
+}
+
+// Region is specified in the HasRegion interface.
+func (e *environ) Region() (simplestreams.CloudSpec, error) {
+	return simplestreams.CloudSpec{
+		Region:   e.ecfg().region(),
+		Endpoint: imageEndpoint,
+	}, nil
+}
+
+// GetImageSources returns a list of sources which are used to search for simplestreams image metadata.
+func (e *environ) GetImageSources() ([]simplestreams.DataSource, error) {
+	// Add the simplestreams source off the control bucket.
+	sources := []simplestreams.DataSource{
+		storage.NewStorageSimpleStreamsDataSource("cloud storage", e.Storage(), storage.BaseImagesPath)}
+	return sources, nil
+}
+
+// GetToolsSources returns a list of sources which are used to search for simplestreams tools metadata.
+func (e *environ) GetToolsSources() ([]simplestreams.DataSource, error) {
+	// Add the simplestreams source off the control bucket.
+	sources := []simplestreams.DataSource{
+		storage.NewStorageSimpleStreamsDataSource("cloud storage", e.Storage(), storage.BaseToolsPath)}
+	return sources, nil
+}
@@ -0,0 +1,248 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package gce
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/provider/gce/google"
+)
+
+// anySource is the source CIDR that allows access from anywhere.
+const anySource = "0.0.0.0/0"
+
+// envTag returns the tag given to all the environment's instances.
+// It also prefixes the names of all the environment's firewall rules.
+func (e *environ) envTag() string {
+	return "juju-" + e.name
+}
+
+// machineTag returns the tag given to the instance of the given
+// machine. Firewall rules opening the machine's ports target it.
+func (e *environ) machineTag(machineId string) string {
+	return fmt.Sprintf("%s-%s", e.envTag(), machineId)
+}
+
+// globalPrefix is the prefix of the names of the firewall rules
+// opening ports on all the environment's instances.
+func (e *environ) globalPrefix() string {
+	return e.envTag() + "-global-"
+}
+
+// ensureBaseFirewalls creates the firewall rules that every instance
+// of the environment needs: access to ssh and the state server from
+// anywhere, and unrestricted access between the instances themselves.
+func (e *environ) ensureBaseFirewalls() error {
+	cfg := e.Config()
+	envTag := e.envTag()
+	rules := []*google.Firewall{{
+		Name:    envTag,
+		Network: defaultNetwork,
+		Allowed: []google.FirewallAllowed{{
+			IPProtocol: "tcp",
+			Ports: []string{
+				"22",
+				strconv.Itoa(cfg.StatePort()),
+				strconv.Itoa(cfg.APIPort()),
+			},
+		}},
+		SourceRanges: []string{anySource},
+		TargetTags:   []string{envTag},
+	}, {
+		Name:    envTag + "-internal",
+		Network: defaultNetwork,
+		Allowed: []google.FirewallAllowed{
+			{IPProtocol: "tcp", Ports: []string{"0-65535"}},
+			{IPProtocol: "udp", Ports: []string{"0-65535"}},
+			{IPProtocol: "icmp"},
+		},
+		SourceTags: []string{envTag},
+		TargetTags: []string{envTag},
+	}}
+	for _, rule := range rules {
+		if err := e.conn().AddFirewall(rule); err != nil && !google.IsConflict(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeFirewalls removes all the environment's firewall rules.
+func (e *environ) removeFirewalls() error {
+	rules, err := e.conn().Firewalls()
+	if err != nil {
+		return err
+	}
+	// Match the names of the rules exactly, so that the rules of
+	// other environments whose names start with this one's are kept.
+	envRule := regexp.MustCompile("^" + regexp.QuoteMeta(e.envTag()) +
+		"(-internal|-global-[0-9a-f]{10}|-[0-9]+-[0-9a-f]{10})?$")
+	for _, rule := range rules {
+		if !envRule.MatchString(rule.Name) {
+			continue
+		}
+		if err := e.conn().RemoveFirewall(rule.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ruleHashLen is the length of the hash ending the name of a rule
+// opening a port range.
+const ruleHashLen = 10
+
+// isPortRule reports whether the rule with the given name opens
+// a port range, and was named by portRule with the given prefix.
+func isPortRule(name, prefix string) bool {
+	if !strings.HasPrefix(name, prefix) || len(name) != len(prefix)+ruleHashLen {
+		return false
+	}
+	_, err := hex.DecodeString(name[len(prefix):])
+	return err == nil
+}
+
+// portRule returns the firewall rule, named with the given prefix,
+// that opens the given port range to the instances with the given
+// target tag. The name ends with a hash of the range, so that
+// the rule for a range can be found again to close it.
+func portRule(prefix, target string, ports network.PortRange) (*google.Firewall, error) {
+	source := ports.SourceCIDR
+	if source == "" {
+		source = anySource
+	}
+	switch ports.Protocol {
+	case "tcp", "udp":
+	default:
+		return nil, errors.NotSupportedf("opening ports with protocol %q", ports.Protocol)
+	}
+	key := fmt.Sprintf("%s/%d/%d/%s", ports.Protocol, ports.FromPort, ports.ToPort, source)
+	return &google.Firewall{
+		Name:    fmt.Sprintf("%s%x", prefix, sha1.Sum([]byte(key)))[:len(prefix)+ruleHashLen],
+		Network: defaultNetwork,
+		Allowed: []google.FirewallAllowed{{
+			IPProtocol: ports.Protocol,
+			Ports:      []string{fmt.Sprintf("%d-%d", ports.FromPort, ports.ToPort)},
+		}},
+		SourceRanges: []string{source},
+		TargetTags:   []string{target},
+	}, nil
+}
+
+// ruleRanges returns the port ranges opened by the given rule.
+func ruleRanges(rule *google.Firewall) ([]network.PortRange, error) {
+	source := ""
+	if len(rule.SourceRanges) > 0 && rule.SourceRanges[0] != anySource {
+		source = rule.SourceRanges[0]
+	}
+	var ranges []network.PortRange
+	for _, allowed := range rule.Allowed {
+		for _, p := range allowed.Ports {
+			r := network.PortRange{
+				Protocol:   allowed.IPProtocol,
+				SourceCIDR: source,
+			}
+			from, to := p, p
+			if pos := strings.IndexRune(p, '-'); pos != -1 {
+				from, to = p[:pos], p[pos+1:]
+			}
+			var err error
+			if r.FromPort, err = strconv.Atoi(from); err != nil {
+				return nil, fmt.Errorf("invalid port %q in firewall rule %q", p, rule.Name)
+			}
+			if r.ToPort, err = strconv.Atoi(to); err != nil {
+				return nil, fmt.Errorf("invalid port %q in firewall rule %q", p, rule.Name)
+			}
+			ranges = append(ranges, r)
+		}
+	}
+	return ranges, nil
+}
+
+func (e *environ) openPortsWithPrefix(prefix, target string, ports []network.PortRange) error {
+	for _, p := range ports {
+		rule, err := portRule(prefix, target, p)
+		if err != nil {
+			return err
+		}
+		if err := e.conn().AddFirewall(rule); err != nil && !google.IsConflict(err) {
+			return fmt.Errorf("cannot open ports: %v", err)
+		}
+	}
+	return nil
+}
+
+func (e *environ) closePortsWithPrefix(prefix, target string, ports []network.PortRange) error {
+	for _, p := range ports {
+		rule, err := portRule(prefix, target, p)
+		if err != nil {
+			return err
+		}
+		if err := e.conn().RemoveFirewall(rule.Name); err != nil {
+			return fmt.Errorf("cannot close ports: %v", err)
+		}
+	}
+	return nil
+}
+
+func (e *environ) portsWithPrefix(prefix string) ([]network.PortRange, error) {
+	rules, err := e.conn().Firewalls()
+	if err != nil {
+		return nil, err
+	}
+	var ports []network.PortRange
+	for i := range rules {
+		if !isPortRule(rules[i].Name, prefix) {
+			continue
+		}
+		ranges, err := ruleRanges(&rules[i])
+		if err != nil {
+			return nil, err
+		}
+		ports = append(ports, ranges...)
+	}
+	network.SortPortRanges(ports)
+	return ports, nil
+}
+
+func (e *environ) OpenPorts(ports []network.PortRange) error {
+	if e.Config().FirewallMode() != config.FwGlobal {
+		return fmt.Errorf("invalid firewall mode %q for opening ports on environment",
+			e.Config().FirewallMode())
+	}
+	if err := e.openPortsWithPrefix(e.globalPrefix(), e.envTag(), ports); err != nil {
+		return err
+	}
+	logger.Infof("opened ports in global firewall: %v", ports)
+	return nil
+}
+
+func (e *environ) ClosePorts(ports []network.PortRange) error {
+	if e.Config().FirewallMode() != config.FwGlobal {
+		return fmt.Errorf("invalid firewall mode %q for closing ports on environment",
+			e.Config().FirewallMode())
+	}
+	if err := e.closePortsWithPrefix(e.globalPrefix(), e.envTag(), ports); err != nil {
+		return err
+	}
+	logger.Infof("closed ports in global firewall: %v", ports)
+	return nil
+}
+
+func (e *environ) Ports() ([]network.PortRange, error) {
+	if e.Config().FirewallMode() != config.FwGlobal {
+		return nil, fmt.Errorf("invalid firewall mode %q for retrieving ports from environment",
+			e.Config().FirewallMode())
+	}
+	return e.portsWithPrefix(e.globalPrefix())
+}
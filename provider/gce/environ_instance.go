@@ -0,0 +1,382 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package gce
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/imagemetadata"
+	"github.com/juju/juju/environs/instances"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/provider/common"
+	"github.com/juju/juju/provider/gce/google"
+	"github.com/juju/juju/tools"
+)
+
+// defaultNetwork is the network that instances are started in.
+const defaultNetwork = "global/networks/default"
+
+// imageProject is the project holding the Ubuntu images, used when
+// the image ids given by simplestreams are not already qualified
+// with a project.
+const imageProject = "ubuntu-os-cloud"
+
+// userDataKey is the metadata key holding the cloud-init user data.
+const userDataKey = "user-data"
+
+type gceAvailabilityZone struct {
+	google.Zone
+}
+
+func (z *gceAvailabilityZone) Name() string {
+	return z.Zone.Name
+}
+
+func (z *gceAvailabilityZone) Available() bool {
+	return z.Zone.Status == google.ZoneUp
+}
+
+// AvailabilityZones returns a slice of availability zones
+// for the configured region.
+func (e *environ) AvailabilityZones() ([]common.AvailabilityZone, error) {
+	e.zonesMutex.Lock()
+	defer e.zonesMutex.Unlock()
+	if e.zones == nil {
+		zones, err := e.conn().Zones()
+		if err != nil {
+			return nil, err
+		}
+		region := e.ecfg().region()
+		var regionZones []common.AvailabilityZone
+		for _, z := range zones {
+			if z.RegionName() == region {
+				regionZones = append(regionZones, &gceAvailabilityZone{z})
+			}
+		}
+		e.zones = regionZones
+	}
+	return e.zones, nil
+}
+
+// InstanceAvailabilityZoneNames returns the availability zone names for each
+// of the specified instances.
+func (e *environ) InstanceAvailabilityZoneNames(ids []instance.Id) ([]string, error) {
+	instances, err := e.Instances(ids)
+	if err != nil && err != environs.ErrPartialInstances {
+		return nil, err
+	}
+	zones := make([]string, len(instances))
+	for i, inst := range instances {
+		if inst == nil {
+			continue
+		}
+		zones[i] = inst.(*gceInstance).zone
+	}
+	return zones, err
+}
+
+type gcePlacement struct {
+	zone google.Zone
+}
+
+func (e *environ) parsePlacement(placement string) (*gcePlacement, error) {
+	pos := strings.IndexRune(placement, '=')
+	if pos == -1 {
+		return nil, fmt.Errorf("unknown placement directive: %v", placement)
+	}
+	switch key, value := placement[:pos], placement[pos+1:]; key {
+	case "zone":
+		zones, err := e.AvailabilityZones()
+		if err != nil {
+			return nil, err
+		}
+		for _, z := range zones {
+			if z.Name() == value {
+				return &gcePlacement{z.(*gceAvailabilityZone).Zone}, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid availability zone %q", value)
+	}
+	return nil, fmt.Errorf("unknown placement directive: %v", placement)
+}
+
+// DistributeInstances implements the state.InstanceDistributor policy.
+func (e *environ) DistributeInstances(candidates, distributionGroup []instance.Id) ([]instance.Id, error) {
+	return common.DistributeInstances(e, candidates, distributionGroup)
+}
+
+var bestAvailabilityZoneAllocations = common.BestAvailabilityZoneAllocations
+
+// StartInstance is specified in the InstanceBroker interface.
+func (e *environ) StartInstance(args environs.StartInstanceParams) (instance.Instance, *instance.HardwareCharacteristics, []network.Info, error) {
+	var zone string
+	if args.Placement != "" {
+		placement, err := e.parsePlacement(args.Placement)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if placement.zone.Status != google.ZoneUp {
+			return nil, nil, nil, fmt.Errorf("availability zone %q is %s", placement.zone.Name, placement.zone.Status)
+		}
+		zone = placement.zone.Name
+	}
+
+	// If no availability zone is specified, then automatically spread across
+	// the known zones for optimal spread across the instance distribution
+	// group.
+	if zone == "" {
+		var group []instance.Id
+		var err error
+		if args.DistributionGroup != nil {
+			group, err = args.DistributionGroup()
+			if err != nil {
+				return nil, nil, nil, err
+			}
+		}
+		bestAvailabilityZones, err := bestAvailabilityZoneAllocations(e, group)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		for zone = range bestAvailabilityZones {
+			break
+		}
+		if zone == "" {
+			return nil, nil, nil, fmt.Errorf("no availability zones in region %q", e.ecfg().region())
+		}
+	}
+
+	if args.MachineConfig.HasNetworks() {
+		return nil, nil, nil, fmt.Errorf("starting instances with networks is not supported yet.")
+	}
+	if len(args.Volumes) > 0 {
+		return nil, nil, nil, errors.NotSupportedf("starting instances with volumes")
+	}
+	arches := args.Tools.Arches()
+	sources, err := imagemetadata.GetMetadataSources(e)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	series := args.Tools.OneSeries()
+	spec, err := findInstanceSpec(sources, e.Config().ImageStream(), &instances.InstanceConstraint{
+		Region:      e.ecfg().region(),
+		Series:      series,
+		Arches:      arches,
+		Constraints: args.Constraints,
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	tools, err := args.Tools.Match(tools.Filter{Arch: spec.Image.Arch})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("chosen architecture %v not present in %v", spec.Image.Arch, arches)
+	}
+
+	args.MachineConfig.Tools = tools[0]
+	if err := environs.FinishMachineConfig(args.MachineConfig, e.Config(), args.Constraints); err != nil {
+		return nil, nil, nil, err
+	}
+	userData, err := environs.ComposeUserData(args.MachineConfig, nil)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot make user data: %v", err)
+	}
+	// Instance metadata values are plain strings.
+	userData, err = utils.Gunzip(userData)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot make user data: %v", err)
+	}
+	logger.Debugf("gce user data; %d bytes", len(userData))
+
+	if err := e.ensureBaseFirewalls(); err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot set up firewall rules: %v", err)
+	}
+	machineId := args.MachineConfig.MachineId
+	name, err := e.newInstanceName(machineId)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	diskSize := getDiskSize(args.Constraints)
+	raw, err := e.conn().AddInstance(zone, &google.Instance{
+		Name:        name,
+		MachineType: fmt.Sprintf("zones/%s/machineTypes/%s", zone, spec.InstanceType.Name),
+		Disks: []google.AttachedDisk{{
+			Type:       "PERSISTENT",
+			Mode:       "READ_WRITE",
+			Boot:       true,
+			AutoDelete: true,
+			InitializeParams: &google.DiskInitializeParams{
+				SourceImage: imageURL(spec.Image.Id),
+				DiskSizeGb:  int64(diskSize / 1024),
+			},
+		}},
+		NetworkInterfaces: []google.NetworkInterface{{
+			Network: defaultNetwork,
+			AccessConfigs: []google.AccessConfig{{
+				Name: "External NAT",
+				Type: "ONE_TO_ONE_NAT",
+			}},
+		}},
+		Metadata: &google.Metadata{
+			Items: []google.MetadataItem{{Key: userDataKey, Value: string(userData)}},
+		},
+		Tags: &google.Tags{
+			Items: []string{e.envTag(), e.machineTag(machineId)},
+		},
+	})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot run instances: %v", err)
+	}
+	inst := newInstance(e, raw)
+	logger.Infof("started instance %q in zone %q", inst.Id(), zone)
+
+	hc := instance.HardwareCharacteristics{
+		Arch:             &spec.Image.Arch,
+		Mem:              &spec.InstanceType.Mem,
+		CpuCores:         &spec.InstanceType.CpuCores,
+		CpuPower:         spec.InstanceType.CpuPower,
+		RootDisk:         &diskSize,
+		AvailabilityZone: &zone,
+		// Tags currently not supported by GCE
+	}
+	return inst, &hc, nil, nil
+}
+
+// newInstanceName returns a name for a new instance of the given
+// machine. A random suffix keeps the names of the instances of
+// successive environments with the same name distinct.
+func (e *environ) newInstanceName(machineId string) (string, error) {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("cannot generate instance name: %v", err)
+	}
+	return fmt.Sprintf("%s-%x", e.machineTag(machineId), buf), nil
+}
+
+// imageURL returns the source image URL for the given simplestreams
+// image id.
+func imageURL(id string) string {
+	if strings.Contains(id, "/") {
+		return id
+	}
+	return fmt.Sprintf("projects/%s/global/images/%s", imageProject, id)
+}
+
+// minDiskSize is the minimum/default size (in megabytes) for GCE root disks.
+const minDiskSize uint64 = 10 * 1024
+
+// getDiskSize translates a RootDisk constraint (or lack thereof) into
+// the size in megabytes of the root disk to create.
+func getDiskSize(cons constraints.Value) uint64 {
+	diskSize := minDiskSize
+	if cons.RootDisk != nil {
+		if *cons.RootDisk >= minDiskSize {
+			diskSize = *cons.RootDisk
+		} else {
+			logger.Infof("Ignoring root-disk constraint of %dM because it is smaller than the GCE image size of %dM",
+				*cons.RootDisk, minDiskSize)
+		}
+	}
+	// GCE's disk size is in gigabytes, root-disk is in megabytes,
+	// so round up to the nearest gigabyte.
+	return (diskSize + 1023) / 1024 * 1024
+}
+
+func (e *environ) StopInstances(ids ...instance.Id) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	insts, err := e.Instances(ids)
+	if err != nil && err != environs.ErrPartialInstances {
+		if err == environs.ErrNoInstances {
+			return nil
+		}
+		return err
+	}
+	var firstErr error
+	for _, inst := range insts {
+		if inst == nil {
+			continue
+		}
+		gi := inst.(*gceInstance)
+		if err := e.conn().RemoveInstance(gi.zone, gi.name); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// liveInstances returns all the environment's instances that have
+// not been stopped, in all the zones of its region.
+func (e *environ) liveInstances() ([]*gceInstance, error) {
+	zones, err := e.AvailabilityZones()
+	if err != nil {
+		return nil, err
+	}
+	envTag := e.envTag()
+	var insts []*gceInstance
+	for _, z := range zones {
+		raw, err := e.conn().Instances(z.Name())
+		if err != nil {
+			return nil, err
+		}
+		for i := range raw {
+			if !raw[i].HasTag(envTag) {
+				continue
+			}
+			switch raw[i].Status {
+			case google.StatusProvisioning, google.StatusStaging, google.StatusRunning:
+				insts = append(insts, newInstance(e, &raw[i]))
+			}
+		}
+	}
+	return insts, nil
+}
+
+func (e *environ) Instances(ids []instance.Id) ([]instance.Instance, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	live, err := e.liveInstances()
+	if err != nil {
+		return nil, err
+	}
+	byId := make(map[instance.Id]*gceInstance)
+	for _, inst := range live {
+		byId[inst.Id()] = inst
+	}
+	insts := make([]instance.Instance, len(ids))
+	n := 0
+	for i, id := range ids {
+		if inst, ok := byId[id]; ok {
+			insts[i] = inst
+			n++
+		}
+	}
+	if n == 0 {
+		return nil, environs.ErrNoInstances
+	}
+	if n < len(ids) {
+		return insts, environs.ErrPartialInstances
+	}
+	return insts, nil
+}
+
+func (e *environ) AllInstances() ([]instance.Instance, error) {
+	live, err := e.liveInstances()
+	if err != nil {
+		return nil, err
+	}
+	insts := make([]instance.Instance, len(live))
+	for i, inst := range live {
+		insts[i] = inst
+	}
+	return insts, nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package gce
+
+import (
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/imagemetadata"
+	"github.com/juju/juju/environs/jujutest"
+	"github.com/juju/juju/instance"
+)
+
+var Provider environs.EnvironProvider = providerInstance
+
+var (
+	Endpoints                       = &endpoints
+	BestAvailabilityZoneAllocations = &bestAvailabilityZoneAllocations
+	StorageAttempt                  = &storageAttempt
+)
+
+func ControlBucketName(e environs.Environ) string {
+	return e.(*environ).ecfg().controlBucket()
+}
+
+func EnvironTag(e environs.Environ) string {
+	return e.(*environ).envTag()
+}
+
+func MachineTag(e environs.Environ, machineId string) string {
+	return e.(*environ).machineTag(machineId)
+}
+
+func InstanceZone(inst instance.Instance) string {
+	return inst.(*gceInstance).zone
+}
+
+var testRoundTripper = &jujutest.ProxyRoundTripper{}
+
+func init() {
+	// Prepare mock http transport for overriding images output in tests.
+	testRoundTripper.RegisterForScheme("test")
+}
+
+var origImagesUrl = imagemetadata.DefaultBaseURL
+
+// UseTestImageData causes the given content to be served
+// when the gce provider asks for image data.
+func UseTestImageData(files map[string]string) {
+	if files != nil {
+		testRoundTripper.Sub = jujutest.NewCannedRoundTripper(files, nil)
+		imagemetadata.DefaultBaseURL = "test:"
+		signedImageDataOnly = false
+	} else {
+		signedImageDataOnly = true
+		testRoundTripper.Sub = nil
+		imagemetadata.DefaultBaseURL = origImagesUrl
+	}
+}
+
+var TestImagesData = map[string]string{
+	"/streams/v1/index.json": `
+        {
+         "index": {
+          "com.ubuntu.cloud:released:gce": {
+           "updated": "Wed, 01 Oct 2014 13:31:26 +0000",
+           "clouds": [
+            {
+             "region": "us-central1",
+             "endpoint": "https://www.googleapis.com"
+            }
+           ],
+           "cloudname": "gce",
+           "datatype": "image-ids",
+           "format": "products:1.0",
+           "products": [
+            "com.ubuntu.cloud:server:12.04:amd64",
+            "com.ubuntu.cloud:server:14.04:amd64"
+           ],
+           "path": "streams/v1/com.ubuntu.cloud:released:gce.json"
+          }
+         },
+         "updated": "Wed, 01 Oct 2014 13:31:26 +0000",
+         "format": "index:1.0"
+        }
+`,
+	"/streams/v1/com.ubuntu.cloud:released:gce.json": `
+{
+ "content_id": "com.ubuntu.cloud:released:gce",
+ "products": {
+   "com.ubuntu.cloud:server:12.04:amd64": {
+     "release": "precise",
+     "version": "12.04",
+     "arch": "amd64",
+     "versions": {
+       "20141001": {
+         "items": {
+           "uc1": {
+             "region": "us-central1",
+             "id": "ubuntu-1204-precise-v20141001"
+           },
+           "ew1": {
+             "region": "europe-west1",
+             "id": "ubuntu-1204-precise-v20141001"
+           }
+         },
+         "pubname": "ubuntu-precise-12.04-amd64-server-20141001",
+         "label": "release"
+       }
+     }
+   },
+   "com.ubuntu.cloud:server:14.04:amd64": {
+     "release": "trusty",
+     "version": "14.04",
+     "arch": "amd64",
+     "versions": {
+       "20141001": {
+         "items": {
+           "uc1": {
+             "region": "us-central1",
+             "id": "ubuntu-1404-trusty-v20141001"
+           },
+           "ew1": {
+             "region": "europe-west1",
+             "id": "ubuntu-1404-trusty-v20141001"
+           }
+         },
+         "pubname": "ubuntu-trusty-14.04-amd64-server-20141001",
+         "label": "release"
+       }
+     }
+   }
+ },
+ "format": "products:1.0"
+}
+`,
+}
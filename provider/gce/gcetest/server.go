@@ -0,0 +1,449 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package gcetest implements a fake of the parts of the Google
+// Compute Engine and Google Cloud Storage REST APIs used by the GCE
+// provider, running within the test process itself.
+package gcetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/juju/juju/provider/gce/google"
+)
+
+// AccessToken is the access token issued by the server.
+const AccessToken = "gcetest-access-token"
+
+// Server is a fake Google API server.
+type Server struct {
+	srv     *httptest.Server
+	project string
+
+	mu         sync.Mutex
+	zones      []google.Zone
+	instances  map[string]*google.Instance
+	firewalls  map[string]*google.Firewall
+	buckets    map[string]map[string][]byte
+	operations int
+	addresses  int
+}
+
+// NewServer starts and returns a new server holding the resources
+// of the project with the given id. The project initially has no
+// zones; see SetZones.
+func NewServer(project string) *Server {
+	s := &Server{
+		project:   project,
+		instances: make(map[string]*google.Instance),
+		firewalls: make(map[string]*google.Firewall),
+		buckets:   make(map[string]map[string][]byte),
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// URL returns the URL of the server.
+func (s *Server) URL() string {
+	return s.srv.URL
+}
+
+// Endpoints returns the endpoints that address the server.
+func (s *Server) Endpoints() google.Endpoints {
+	return google.Endpoints{
+		Compute: s.srv.URL + "/compute/v1/",
+		Storage: s.srv.URL + "/storage/v1/",
+		Objects: s.srv.URL + "/objects/",
+		Token:   s.srv.URL + "/token",
+	}
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// RegionURL returns the URL of the region with the given name.
+func (s *Server) RegionURL(region string) string {
+	return s.projectURL("regions/" + region)
+}
+
+// SetZones sets the zones of the project.
+func (s *Server) SetZones(zones ...google.Zone) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.zones = append([]google.Zone(nil), zones...)
+}
+
+// Instance returns the instance with the given name, or nil if
+// there is none.
+func (s *Server) Instance(name string) *google.Instance {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, inst := range s.instances {
+		if inst.Name == name {
+			copy := *inst
+			return &copy
+		}
+	}
+	return nil
+}
+
+// Firewall returns the firewall rule with the given name, or nil if
+// there is none.
+func (s *Server) Firewall(name string) *google.Firewall {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if fw, ok := s.firewalls[name]; ok {
+		copy := *fw
+		return &copy
+	}
+	return nil
+}
+
+// FirewallNames returns the sorted names of the firewall rules.
+func (s *Server) FirewallNames() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var names []string
+	for name := range s.firewalls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AddFirewall adds the given firewall rule, as if made by another
+// client of the project.
+func (s *Server) AddFirewall(fw google.Firewall) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.firewalls[fw.Name] = &fw
+}
+
+func (s *Server) projectURL(path string) string {
+	return s.srv.URL + "/compute/v1/projects/" + s.project + "/" + path
+}
+
+// httpError is an error reported to the client with the given
+// HTTP status code.
+type httpError struct {
+	code    int
+	message string
+}
+
+func (e *httpError) Error() string {
+	return e.message
+}
+
+func errorf(code int, format string, args ...interface{}) error {
+	return &httpError{code, fmt.Sprintf(format, args...)}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	result, err := s.handle(req)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if e, ok := err.(*httpError); ok {
+			code = e.code
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": map[string]interface{}{
+				"code":    code,
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+	switch result := result.(type) {
+	case nil:
+	case []byte:
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(result)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+func (s *Server) handle(req *http.Request) (interface{}, error) {
+	path := req.URL.Path
+	if path == "/token" {
+		return s.serveToken(req)
+	}
+	if strings.HasPrefix(path, "/objects/") && req.Method == "GET" && req.URL.Query().Get("Signature") != "" {
+		// Signed URLs are used without authentication.
+		return s.serveObject(req, strings.TrimPrefix(path, "/objects/"))
+	}
+	if req.Header.Get("Authorization") != "Bearer "+AccessToken {
+		return nil, errorf(http.StatusUnauthorized, "invalid credentials")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case strings.HasPrefix(path, "/compute/v1/projects/"+s.project+"/"):
+		return s.serveCompute(req, strings.TrimPrefix(path, "/compute/v1/projects/"+s.project+"/"))
+	case strings.HasPrefix(path, "/storage/v1/"):
+		return s.serveStorage(req, strings.TrimPrefix(path, "/storage/v1/"))
+	case strings.HasPrefix(path, "/objects/"):
+		return s.serveObjectLocked(req, strings.TrimPrefix(path, "/objects/"))
+	}
+	return nil, errorf(http.StatusNotFound, "unknown path %q", path)
+}
+
+func (s *Server) serveToken(req *http.Request) (interface{}, error) {
+	if req.Method != "POST" {
+		return nil, errorf(http.StatusMethodNotAllowed, "method %s not allowed", req.Method)
+	}
+	if req.FormValue("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+		return nil, errorf(http.StatusBadRequest, "invalid grant type %q", req.FormValue("grant_type"))
+	}
+	if parts := strings.Split(req.FormValue("assertion"), "."); len(parts) != 3 {
+		return nil, errorf(http.StatusBadRequest, "invalid assertion")
+	}
+	return map[string]interface{}{
+		"access_token": AccessToken,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+	}, nil
+}
+
+// operation returns a completed operation.
+func (s *Server) operation(scope string) *google.Operation {
+	s.operations++
+	name := fmt.Sprintf("operation-%d", s.operations)
+	return &google.Operation{
+		Name:     name,
+		Status:   "DONE",
+		SelfLink: s.projectURL(scope + "/operations/" + name),
+	}
+}
+
+func (s *Server) serveCompute(req *http.Request, path string) (interface{}, error) {
+	parts := strings.Split(path, "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "zones" && req.Method == "GET":
+		return map[string]interface{}{"items": s.zones}, nil
+	case len(parts) >= 3 && parts[0] == "zones" && parts[2] == "instances":
+		zone := parts[1]
+		if !s.hasZone(zone) {
+			return nil, errorf(http.StatusNotFound, "zone %q not found", zone)
+		}
+		return s.serveInstances(req, zone, parts[3:])
+	case len(parts) == 4 && parts[0] == "zones" && parts[2] == "operations" && req.Method == "GET":
+		return &google.Operation{
+			Name:     parts[3],
+			Status:   "DONE",
+			SelfLink: s.projectURL(path),
+		}, nil
+	case len(parts) >= 2 && parts[0] == "global" && parts[1] == "firewalls":
+		return s.serveFirewalls(req, parts[2:])
+	case len(parts) == 3 && parts[0] == "global" && parts[1] == "operations" && req.Method == "GET":
+		return &google.Operation{
+			Name:     parts[2],
+			Status:   "DONE",
+			SelfLink: s.projectURL(path),
+		}, nil
+	}
+	return nil, errorf(http.StatusNotFound, "unknown path %q", path)
+}
+
+func (s *Server) hasZone(name string) bool {
+	for _, zone := range s.zones {
+		if zone.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) serveInstances(req *http.Request, zone string, parts []string) (interface{}, error) {
+	switch {
+	case len(parts) == 0 && req.Method == "GET":
+		var names []string
+		for key := range s.instances {
+			if strings.HasPrefix(key, zone+"/") {
+				names = append(names, key)
+			}
+		}
+		sort.Strings(names)
+		items := make([]google.Instance, len(names))
+		for i, key := range names {
+			items[i] = *s.instances[key]
+		}
+		return map[string]interface{}{"items": items}, nil
+	case len(parts) == 0 && req.Method == "POST":
+		var inst google.Instance
+		if err := json.NewDecoder(req.Body).Decode(&inst); err != nil {
+			return nil, errorf(http.StatusBadRequest, "invalid instance: %v", err)
+		}
+		if inst.Name == "" || inst.MachineType == "" {
+			return nil, errorf(http.StatusBadRequest, "instance has no name or machine type")
+		}
+		key := zone + "/" + inst.Name
+		if _, ok := s.instances[key]; ok {
+			return nil, errorf(http.StatusConflict, "instance %q already exists", inst.Name)
+		}
+		inst.Zone = s.projectURL("zones/" + zone)
+		inst.Status = google.StatusRunning
+		for i := range inst.NetworkInterfaces {
+			s.addresses++
+			iface := &inst.NetworkInterfaces[i]
+			iface.NetworkIP = fmt.Sprintf("10.240.%d.%d", s.addresses/256, s.addresses%256)
+			for j := range iface.AccessConfigs {
+				iface.AccessConfigs[j].NatIP = fmt.Sprintf("203.0.%d.%d", s.addresses/256, s.addresses%256)
+			}
+		}
+		s.instances[key] = &inst
+		return s.operation("zones/" + zone), nil
+	case len(parts) == 1 && req.Method == "GET":
+		inst, ok := s.instances[zone+"/"+parts[0]]
+		if !ok {
+			return nil, errorf(http.StatusNotFound, "instance %q not found", parts[0])
+		}
+		return inst, nil
+	case len(parts) == 1 && req.Method == "DELETE":
+		key := zone + "/" + parts[0]
+		if _, ok := s.instances[key]; !ok {
+			return nil, errorf(http.StatusNotFound, "instance %q not found", parts[0])
+		}
+		delete(s.instances, key)
+		return s.operation("zones/" + zone), nil
+	}
+	return nil, errorf(http.StatusMethodNotAllowed, "method %s not allowed", req.Method)
+}
+
+func (s *Server) serveFirewalls(req *http.Request, parts []string) (interface{}, error) {
+	switch {
+	case len(parts) == 0 && req.Method == "GET":
+		var names []string
+		for name := range s.firewalls {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		items := make([]google.Firewall, len(names))
+		for i, name := range names {
+			items[i] = *s.firewalls[name]
+		}
+		return map[string]interface{}{"items": items}, nil
+	case len(parts) == 0 && req.Method == "POST":
+		var fw google.Firewall
+		if err := json.NewDecoder(req.Body).Decode(&fw); err != nil {
+			return nil, errorf(http.StatusBadRequest, "invalid firewall: %v", err)
+		}
+		if fw.Name == "" || len(fw.Allowed) == 0 {
+			return nil, errorf(http.StatusBadRequest, "firewall has no name or allowed traffic")
+		}
+		if _, ok := s.firewalls[fw.Name]; ok {
+			return nil, errorf(http.StatusConflict, "firewall %q already exists", fw.Name)
+		}
+		s.firewalls[fw.Name] = &fw
+		return s.operation("global"), nil
+	case len(parts) == 1 && req.Method == "DELETE":
+		if _, ok := s.firewalls[parts[0]]; !ok {
+			return nil, errorf(http.StatusNotFound, "firewall %q not found", parts[0])
+		}
+		delete(s.firewalls, parts[0])
+		return s.operation("global"), nil
+	}
+	return nil, errorf(http.StatusMethodNotAllowed, "method %s not allowed", req.Method)
+}
+
+func (s *Server) serveStorage(req *http.Request, path string) (interface{}, error) {
+	parts := strings.Split(path, "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "b" && req.Method == "POST":
+		if project := req.URL.Query().Get("project"); project != s.project {
+			return nil, errorf(http.StatusBadRequest, "unknown project %q", project)
+		}
+		var bucket struct {
+			Name string `json:"name"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&bucket); err != nil || bucket.Name == "" {
+			return nil, errorf(http.StatusBadRequest, "invalid bucket")
+		}
+		if _, ok := s.buckets[bucket.Name]; ok {
+			return nil, errorf(http.StatusConflict, "bucket %q already exists", bucket.Name)
+		}
+		s.buckets[bucket.Name] = make(map[string][]byte)
+		return bucket, nil
+	case len(parts) == 2 && parts[0] == "b" && req.Method == "DELETE":
+		objects, ok := s.buckets[parts[1]]
+		if !ok {
+			return nil, errorf(http.StatusNotFound, "bucket %q not found", parts[1])
+		}
+		if len(objects) > 0 {
+			return nil, errorf(http.StatusConflict, "bucket %q is not empty", parts[1])
+		}
+		delete(s.buckets, parts[1])
+		return nil, nil
+	case len(parts) == 3 && parts[0] == "b" && parts[2] == "o" && req.Method == "GET":
+		objects, ok := s.buckets[parts[1]]
+		if !ok {
+			return nil, errorf(http.StatusNotFound, "bucket %q not found", parts[1])
+		}
+		prefix := req.URL.Query().Get("prefix")
+		var names []string
+		for name := range objects {
+			if strings.HasPrefix(name, prefix) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		items := make([]map[string]string, len(names))
+		for i, name := range names {
+			items[i] = map[string]string{"name": name}
+		}
+		return map[string]interface{}{"items": items}, nil
+	}
+	return nil, errorf(http.StatusNotFound, "unknown path %q", path)
+}
+
+func (s *Server) serveObject(req *http.Request, path string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.serveObjectLocked(req, path)
+}
+
+func (s *Server) serveObjectLocked(req *http.Request, path string) (interface{}, error) {
+	parts := strings.SplitN(path, "/", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, errorf(http.StatusBadRequest, "invalid object path %q", path)
+	}
+	bucket, name := parts[0], parts[1]
+	objects, ok := s.buckets[bucket]
+	if !ok {
+		return nil, errorf(http.StatusNotFound, "bucket %q not found", bucket)
+	}
+	switch req.Method {
+	case "GET":
+		data, ok := objects[name]
+		if !ok {
+			return nil, errorf(http.StatusNotFound, "object %q not found", name)
+		}
+		return data, nil
+	case "PUT":
+		data, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		objects[name] = data
+		return nil, nil
+	case "DELETE":
+		if _, ok := objects[name]; !ok {
+			return nil, errorf(http.StatusNotFound, "object %q not found", name)
+		}
+		delete(objects, name)
+		return nil, nil
+	}
+	return nil, errorf(http.StatusMethodNotAllowed, "method %s not allowed", req.Method)
+}
@@ -0,0 +1,197 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package google
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// jwtGrantType is the OAuth2 grant type used to exchange a
+	// signed JSON Web Token for an access token.
+	jwtGrantType = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+
+	// authScopes holds the OAuth2 scopes requested for the
+	// access tokens.
+	authScopes = "https://www.googleapis.com/auth/compute " +
+		"https://www.googleapis.com/auth/devstorage.full_control"
+
+	// tokenLifetime is the lifetime requested for access tokens.
+	tokenLifetime = time.Hour
+
+	// tokenExpiryMargin is how long before its expiry an access
+	// token is replaced.
+	tokenExpiryMargin = time.Minute
+)
+
+// Credentials holds the credentials of a service account.
+type Credentials struct {
+	// ClientId is the client id of the service account.
+	ClientId string
+
+	// ClientEmail is the email address of the service account.
+	ClientEmail string
+
+	// PrivateKey holds the PEM encoded private key of the
+	// service account.
+	PrivateKey []byte
+}
+
+// ParseJSONKey returns the credentials held in the contents of the
+// JSON key file generated for a service account.
+func ParseJSONKey(data []byte) (*Credentials, error) {
+	var key struct {
+		ClientId    string `json:"client_id"`
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+	}
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("cannot parse JSON key: %v", err)
+	}
+	return &Credentials{
+		ClientId:    key.ClientId,
+		ClientEmail: key.ClientEmail,
+		PrivateKey:  []byte(key.PrivateKey),
+	}, nil
+}
+
+// parsePrivateKey returns the RSA private key in the given PEM data.
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in private key")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse private key: %v", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// authenticator obtains access tokens for a service account, caching
+// them until they expire.
+type authenticator struct {
+	email    string
+	key      *rsa.PrivateKey
+	tokenURL string
+	client   *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiry      time.Time
+}
+
+func newAuthenticator(creds *Credentials, tokenURL string, client *http.Client) (*authenticator, error) {
+	if creds.ClientEmail == "" {
+		return nil, fmt.Errorf("no client email given")
+	}
+	key, err := parsePrivateKey(creds.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &authenticator{
+		email:    creds.ClientEmail,
+		key:      key,
+		tokenURL: tokenURL,
+		client:   client,
+	}, nil
+}
+
+// token returns a valid access token, requesting a new one if needed.
+func (a *authenticator) token() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := time.Now()
+	if a.accessToken != "" && now.Before(a.expiry) {
+		return a.accessToken, nil
+	}
+	assertion, err := a.assertion(now)
+	if err != nil {
+		return "", err
+	}
+	resp, err := a.client.PostForm(a.tokenURL, url.Values{
+		"grant_type": {jwtGrantType},
+		"assertion":  {assertion},
+	})
+	if err != nil {
+		return "", fmt.Errorf("cannot get access token: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("cannot get access token: %v", newError(resp))
+	}
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("cannot get access token: %v", err)
+	}
+	if result.AccessToken == "" {
+		return "", fmt.Errorf("cannot get access token: no token returned")
+	}
+	a.accessToken = result.AccessToken
+	a.expiry = now.Add(time.Duration(result.ExpiresIn)*time.Second - tokenExpiryMargin)
+	return a.accessToken, nil
+}
+
+// assertion returns the signed JSON Web Token exchanged for an
+// access token.
+func (a *authenticator) assertion(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+	})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":   a.email,
+		"scope": authScopes,
+		"aud":   a.tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(tokenLifetime).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := encodeSegment(header) + "." + encodeSegment(claims)
+	signature, err := a.sign([]byte(unsigned))
+	if err != nil {
+		return "", fmt.Errorf("cannot sign token request: %v", err)
+	}
+	return unsigned + "." + encodeSegment(signature), nil
+}
+
+// sign returns the RSA SHA-256 signature of data made with the
+// service account's private key.
+func (a *authenticator) sign(data []byte) ([]byte, error) {
+	hash := sha256.Sum256(data)
+	return rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, hash[:])
+}
+
+// encodeSegment encodes data as a JSON Web Token segment.
+func encodeSegment(data []byte) string {
+	return strings.TrimRight(base64.URLEncoding.EncodeToString(data), "=")
+}
@@ -0,0 +1,316 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package google
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/juju/utils"
+)
+
+// Zone statuses.
+const (
+	ZoneUp   = "UP"
+	ZoneDown = "DOWN"
+)
+
+// Instance statuses.
+const (
+	StatusProvisioning = "PROVISIONING"
+	StatusStaging      = "STAGING"
+	StatusRunning      = "RUNNING"
+	StatusStopping     = "STOPPING"
+	StatusTerminated   = "TERMINATED"
+)
+
+// operationDone is the status of a completed operation.
+const operationDone = "DONE"
+
+// Zone describes a Compute Engine zone.
+type Zone struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+
+	// Region holds the URL of the region the zone is in.
+	Region string `json:"region"`
+}
+
+// RegionName returns the name of the region the zone is in.
+func (z *Zone) RegionName() string {
+	return lastPathElement(z.Region)
+}
+
+// Instance describes a Compute Engine instance.
+type Instance struct {
+	Name              string             `json:"name"`
+	Zone              string             `json:"zone,omitempty"`
+	Status            string             `json:"status,omitempty"`
+	MachineType       string             `json:"machineType"`
+	Disks             []AttachedDisk     `json:"disks,omitempty"`
+	NetworkInterfaces []NetworkInterface `json:"networkInterfaces,omitempty"`
+	Metadata          *Metadata          `json:"metadata,omitempty"`
+	Tags              *Tags              `json:"tags,omitempty"`
+}
+
+// ZoneName returns the name of the zone the instance is in.
+func (inst *Instance) ZoneName() string {
+	return lastPathElement(inst.Zone)
+}
+
+// HasTag reports whether the instance is tagged with the given tag.
+func (inst *Instance) HasTag(tag string) bool {
+	if inst.Tags == nil {
+		return false
+	}
+	for _, t := range inst.Tags.Items {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// AttachedDisk describes a disk attached to an instance.
+type AttachedDisk struct {
+	Type             string                `json:"type"`
+	Mode             string                `json:"mode"`
+	Boot             bool                  `json:"boot,omitempty"`
+	AutoDelete       bool                  `json:"autoDelete,omitempty"`
+	InitializeParams *DiskInitializeParams `json:"initializeParams,omitempty"`
+}
+
+// DiskInitializeParams holds the parameters of a disk created along
+// with an instance.
+type DiskInitializeParams struct {
+	SourceImage string `json:"sourceImage"`
+	DiskSizeGb  int64  `json:"diskSizeGb,string,omitempty"`
+}
+
+// NetworkInterface describes a network interface of an instance.
+type NetworkInterface struct {
+	Network       string         `json:"network"`
+	NetworkIP     string         `json:"networkIP,omitempty"`
+	AccessConfigs []AccessConfig `json:"accessConfigs,omitempty"`
+}
+
+// AccessConfig describes how a network interface of an instance
+// is reached from outside its network.
+type AccessConfig struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	NatIP string `json:"natIP,omitempty"`
+}
+
+// Metadata holds the metadata of an instance.
+type Metadata struct {
+	Items []MetadataItem `json:"items,omitempty"`
+}
+
+// MetadataItem holds a metadata key and its value.
+type MetadataItem struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Tags holds the tags of an instance.
+type Tags struct {
+	Items []string `json:"items,omitempty"`
+}
+
+// Firewall describes a firewall rule.
+type Firewall struct {
+	Name    string            `json:"name"`
+	Network string            `json:"network"`
+	Allowed []FirewallAllowed `json:"allowed"`
+
+	// SourceRanges and SourceTags restrict the traffic allowed
+	// to that from the given CIDRs and from instances with the
+	// given tags.
+	SourceRanges []string `json:"sourceRanges,omitempty"`
+	SourceTags   []string `json:"sourceTags,omitempty"`
+
+	// TargetTags restricts the instances the rule applies to
+	// to those with the given tags.
+	TargetTags []string `json:"targetTags,omitempty"`
+}
+
+// FirewallAllowed describes the traffic allowed by a firewall rule.
+type FirewallAllowed struct {
+	IPProtocol string   `json:"IPProtocol"`
+	Ports      []string `json:"ports,omitempty"`
+}
+
+// Operation describes an asynchronous operation.
+type Operation struct {
+	Name     string          `json:"name"`
+	Status   string          `json:"status"`
+	SelfLink string          `json:"selfLink"`
+	Error    *OperationError `json:"error,omitempty"`
+}
+
+// OperationError holds the errors that made an operation fail.
+type OperationError struct {
+	Errors []OperationErrorItem `json:"errors"`
+}
+
+// OperationErrorItem describes an error that made an operation fail.
+type OperationErrorItem struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// operationAttempt is the strategy used to wait for operations
+// to complete.
+var operationAttempt = utils.AttemptStrategy{
+	Total: 5 * time.Minute,
+	Delay: 2 * time.Second,
+}
+
+// waitOperation waits for the given operation to complete, returning
+// an error if it fails.
+func (c *Connection) waitOperation(op *Operation) error {
+	for a := operationAttempt.Start(); op.Status != operationDone; {
+		if !a.Next() {
+			return fmt.Errorf("timed out waiting for operation %q", op.Name)
+		}
+		var next Operation
+		if err := c.call("GET", op.SelfLink, nil, &next); err != nil {
+			return err
+		}
+		op = &next
+	}
+	if op.Error != nil && len(op.Error.Errors) > 0 {
+		e := op.Error.Errors[0]
+		return fmt.Errorf("operation %q failed: %s: %s", op.Name, e.Code, e.Message)
+	}
+	return nil
+}
+
+// Zones returns the zones available to the project.
+func (c *Connection) Zones() ([]Zone, error) {
+	var zones []Zone
+	query := make(url.Values)
+	for {
+		var page struct {
+			Items         []Zone `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := c.call("GET", c.computeURL("zones", query), nil, &page); err != nil {
+			return nil, err
+		}
+		zones = append(zones, page.Items...)
+		if page.NextPageToken == "" {
+			return zones, nil
+		}
+		query.Set("pageToken", page.NextPageToken)
+	}
+}
+
+// Instances returns the instances in the given zone.
+func (c *Connection) Instances(zone string) ([]Instance, error) {
+	var instances []Instance
+	query := make(url.Values)
+	for {
+		var page struct {
+			Items         []Instance `json:"items"`
+			NextPageToken string     `json:"nextPageToken"`
+		}
+		if err := c.call("GET", c.computeURL("zones/"+zone+"/instances", query), nil, &page); err != nil {
+			return nil, err
+		}
+		instances = append(instances, page.Items...)
+		if page.NextPageToken == "" {
+			return instances, nil
+		}
+		query.Set("pageToken", page.NextPageToken)
+	}
+}
+
+// Instance returns the instance with the given name in the given zone.
+func (c *Connection) Instance(zone, name string) (*Instance, error) {
+	var inst Instance
+	if err := c.call("GET", c.computeURL("zones/"+zone+"/instances/"+name, nil), nil, &inst); err != nil {
+		return nil, err
+	}
+	return &inst, nil
+}
+
+// AddInstance creates the given instance in the given zone, waits
+// for it to be created, and returns it as created.
+func (c *Connection) AddInstance(zone string, inst *Instance) (*Instance, error) {
+	var op Operation
+	if err := c.call("POST", c.computeURL("zones/"+zone+"/instances", nil), inst, &op); err != nil {
+		return nil, err
+	}
+	if err := c.waitOperation(&op); err != nil {
+		return nil, err
+	}
+	return c.Instance(zone, inst.Name)
+}
+
+// RemoveInstance deletes the instance with the given name in the
+// given zone, and waits for it to be deleted. It does not return
+// an error if the instance does not exist.
+func (c *Connection) RemoveInstance(zone, name string) error {
+	var op Operation
+	err := c.call("DELETE", c.computeURL("zones/"+zone+"/instances/"+name, nil), nil, &op)
+	if IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return c.waitOperation(&op)
+}
+
+// Firewalls returns the firewall rules of the project.
+func (c *Connection) Firewalls() ([]Firewall, error) {
+	var firewalls []Firewall
+	query := make(url.Values)
+	for {
+		var page struct {
+			Items         []Firewall `json:"items"`
+			NextPageToken string     `json:"nextPageToken"`
+		}
+		if err := c.call("GET", c.computeURL("global/firewalls", query), nil, &page); err != nil {
+			return nil, err
+		}
+		firewalls = append(firewalls, page.Items...)
+		if page.NextPageToken == "" {
+			return firewalls, nil
+		}
+		query.Set("pageToken", page.NextPageToken)
+	}
+}
+
+// AddFirewall creates the given firewall rule, and waits for it to
+// be created.
+func (c *Connection) AddFirewall(fw *Firewall) error {
+	var op Operation
+	if err := c.call("POST", c.computeURL("global/firewalls", nil), fw, &op); err != nil {
+		return err
+	}
+	return c.waitOperation(&op)
+}
+
+// RemoveFirewall deletes the firewall rule with the given name, and
+// waits for it to be deleted. It does not return an error if the
+// rule does not exist.
+func (c *Connection) RemoveFirewall(name string) error {
+	var op Operation
+	err := c.call("DELETE", c.computeURL("global/firewalls/"+name, nil), nil, &op)
+	if IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return c.waitOperation(&op)
+}
+
+// lastPathElement returns the last element of the given URL path.
+func lastPathElement(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
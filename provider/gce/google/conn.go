@@ -0,0 +1,189 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package google implements a client for the parts of the Google
+// Compute Engine and Google Cloud Storage REST APIs used by the GCE
+// provider.
+package google
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Endpoints holds the base URLs of the Google APIs.
+type Endpoints struct {
+	// Compute is the base URL of the Compute Engine API.
+	Compute string
+
+	// Storage is the base URL of the Cloud Storage JSON API,
+	// used to manage buckets and list objects.
+	Storage string
+
+	// Objects is the base URL from which Cloud Storage
+	// objects are read and written.
+	Objects string
+
+	// Token is the URL of the OAuth2 token endpoint.
+	Token string
+}
+
+// DefaultEndpoints holds the URLs of the public Google APIs.
+var DefaultEndpoints = Endpoints{
+	Compute: "https://www.googleapis.com/compute/v1/",
+	Storage: "https://www.googleapis.com/storage/v1/",
+	Objects: "https://storage.googleapis.com/",
+	Token:   "https://accounts.google.com/o/oauth2/token",
+}
+
+// Connection provides access to the Google APIs on behalf of a
+// service account, within a single project.
+type Connection struct {
+	projectId string
+	endpoints Endpoints
+	client    *http.Client
+	auth      *authenticator
+}
+
+// NewConnection returns a connection to the Google APIs at the given
+// endpoints, authenticating with the given service account
+// credentials. No request is made until the connection is used.
+func NewConnection(creds *Credentials, projectId string, endpoints Endpoints) (*Connection, error) {
+	if projectId == "" {
+		return nil, fmt.Errorf("no project id given")
+	}
+	client := http.DefaultClient
+	auth, err := newAuthenticator(creds, endpoints.Token, client)
+	if err != nil {
+		return nil, err
+	}
+	return &Connection{
+		projectId: projectId,
+		endpoints: endpoints,
+		client:    client,
+		auth:      auth,
+	}, nil
+}
+
+// ProjectId returns the id of the project the connection works in.
+func (c *Connection) ProjectId() string {
+	return c.projectId
+}
+
+// computeURL returns the Compute Engine API URL of the project
+// resource at the given path.
+func (c *Connection) computeURL(path string, query url.Values) string {
+	u := c.endpoints.Compute + "projects/" + c.projectId + "/" + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+// send makes an authenticated request to the given URL, returning
+// the response if it succeeds. Unsuccessful responses are turned
+// into an *Error.
+func (c *Connection) send(method, url string, body io.Reader, length int64, contentType string) (*http.Response, error) {
+	token, err := c.auth.token()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = length
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		return nil, newError(resp)
+	}
+	return resp, nil
+}
+
+// call makes an authenticated request to the given URL, sending in
+// as the JSON encoded request body, if not nil, and decoding the
+// response body into out, if not nil.
+func (c *Connection) call(method, url string, in, out interface{}) error {
+	var body io.Reader
+	var length int64
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+		length = int64(len(data))
+	}
+	resp, err := c.send(method, url, body, length, "application/json")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// Error holds an error returned by a Google API.
+type Error struct {
+	// Code holds the HTTP status code of the response.
+	Code int
+
+	// Message holds the message describing the error.
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("google API error %d: %s", e.Code, e.Message)
+}
+
+func newError(resp *http.Response) error {
+	e := &Error{Code: resp.StatusCode}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		e.Message = http.StatusText(resp.StatusCode)
+		return e
+	}
+	var body struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(data, &body) == nil && body.Error.Message != "" {
+		e.Message = body.Error.Message
+	} else if msg := strings.TrimSpace(string(data)); msg != "" {
+		e.Message = msg
+	} else {
+		e.Message = http.StatusText(resp.StatusCode)
+	}
+	return e
+}
+
+// IsNotFound reports whether err is an *Error reporting that the
+// resource requested does not exist.
+func IsNotFound(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e.Code == http.StatusNotFound
+}
+
+// IsConflict reports whether err is an *Error reporting that the
+// resource to be created already exists.
+func IsConflict(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e.Code == http.StatusConflict
+}
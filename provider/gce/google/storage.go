@@ -0,0 +1,125 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package google
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// storageURL returns the Cloud Storage JSON API URL of the resource
+// at the given path.
+func (c *Connection) storageURL(path string, query url.Values) string {
+	u := c.endpoints.Storage + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+// objectPath returns the path of the given object, relative to the
+// Objects endpoint.
+func objectPath(bucket, name string) string {
+	parts := strings.Split(name, "/")
+	for i, part := range parts {
+		parts[i] = strings.Replace(url.QueryEscape(part), "+", "%20", -1)
+	}
+	return url.QueryEscape(bucket) + "/" + strings.Join(parts, "/")
+}
+
+// AddBucket creates the bucket with the given name in the project.
+// The returned error satisfies IsConflict if the bucket exists.
+func (c *Connection) AddBucket(bucket string) error {
+	query := url.Values{"project": {c.projectId}}
+	return c.call("POST", c.storageURL("b", query), map[string]string{"name": bucket}, nil)
+}
+
+// RemoveBucket deletes the bucket with the given name, which must
+// be empty.
+func (c *Connection) RemoveBucket(bucket string) error {
+	return c.call("DELETE", c.storageURL("b/"+url.QueryEscape(bucket), nil), nil, nil)
+}
+
+// Objects returns the names of the objects in the given bucket
+// whose names start with the given prefix, in alphabetical order.
+func (c *Connection) Objects(bucket, prefix string) ([]string, error) {
+	var names []string
+	query := url.Values{"prefix": {prefix}}
+	for {
+		var page struct {
+			Items []struct {
+				Name string `json:"name"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		path := "b/" + url.QueryEscape(bucket) + "/o"
+		if err := c.call("GET", c.storageURL(path, query), nil, &page); err != nil {
+			return nil, err
+		}
+		for _, item := range page.Items {
+			names = append(names, item.Name)
+		}
+		if page.NextPageToken == "" {
+			return names, nil
+		}
+		query.Set("pageToken", page.NextPageToken)
+	}
+}
+
+// PutObject writes length bytes read from r to the object with the
+// given name in the given bucket.
+func (c *Connection) PutObject(bucket, name string, r io.Reader, length int64) error {
+	u := c.endpoints.Objects + objectPath(bucket, name)
+	resp, err := c.send("PUT", u, r, length, "application/octet-stream")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Object returns a reader of the contents of the object with the
+// given name in the given bucket. The returned error satisfies
+// IsNotFound if the object does not exist.
+func (c *Connection) Object(bucket, name string) (io.ReadCloser, error) {
+	resp, err := c.send("GET", c.endpoints.Objects+objectPath(bucket, name), nil, 0, "")
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// RemoveObject deletes the object with the given name in the given
+// bucket. The returned error satisfies IsNotFound if the object does
+// not exist.
+func (c *Connection) RemoveObject(bucket, name string) error {
+	resp, err := c.send("DELETE", c.endpoints.Objects+objectPath(bucket, name), nil, 0, "")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// SignedObjectURL returns a URL from which the object with the given
+// name in the given bucket can be read, without authentication, until
+// the given expiry time.
+func (c *Connection) SignedObjectURL(bucket, name string, expires time.Time) (string, error) {
+	path := objectPath(bucket, name)
+	stringToSign := fmt.Sprintf("GET\n\n\n%d\n/%s", expires.Unix(), path)
+	signature, err := c.auth.sign([]byte(stringToSign))
+	if err != nil {
+		return "", fmt.Errorf("cannot sign URL: %v", err)
+	}
+	query := url.Values{
+		"GoogleAccessId": {c.auth.email},
+		"Expires":        {fmt.Sprint(expires.Unix())},
+		"Signature":      {base64.StdEncoding.EncodeToString(signature)},
+	}
+	return c.endpoints.Objects + path + "?" + query.Encode(), nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package gce
+
+import (
+	"github.com/juju/juju/environs/imagemetadata"
+	"github.com/juju/juju/environs/instances"
+	"github.com/juju/juju/environs/simplestreams"
+)
+
+// signedImageDataOnly is defined here to allow tests to override the content.
+// If true, only inline PGP signed image metadata will be used.
+var signedImageDataOnly = true
+
+// defaultCpuPower is larger than the f1-micro's cpuPower, and no larger
+// than any other instance type's cpuPower. It prevents the f1-micro from
+// being chosen unless the user has clearly indicated that they are
+// willing to accept poor performance.
+const defaultCpuPower = 100
+
+// findInstanceSpec returns an InstanceSpec satisfying the supplied instanceConstraint.
+func findInstanceSpec(
+	sources []simplestreams.DataSource, stream string, ic *instances.InstanceConstraint) (*instances.InstanceSpec, error) {
+
+	if ic.Constraints.CpuPower == nil {
+		ic.Constraints.CpuPower = instances.CpuPower(defaultCpuPower)
+	}
+	imageConstraint := imagemetadata.NewImageConstraint(simplestreams.LookupParams{
+		CloudSpec: simplestreams.CloudSpec{ic.Region, imageEndpoint},
+		Series:    []string{ic.Series},
+		Arches:    ic.Arches,
+		Stream:    stream,
+	})
+	matchingImages, _, err := imagemetadata.Fetch(
+		sources, simplestreams.DefaultIndexPath, imageConstraint, signedImageDataOnly)
+	if err != nil {
+		return nil, err
+	}
+	if len(matchingImages) == 0 {
+		logger.Warningf("no matching image meta data for constraints: %v", ic)
+	}
+	images := instances.ImageMetadataToImages(matchingImages)
+	return instances.FindInstanceSpec(images, ic, allInstanceTypes)
+}
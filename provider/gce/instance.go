@@ -0,0 +1,120 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package gce
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/provider/gce/google"
+)
+
+type gceInstance struct {
+	e    *environ
+	name string
+	zone string
+
+	mu  sync.Mutex
+	raw *google.Instance
+}
+
+var _ instance.Instance = (*gceInstance)(nil)
+
+func newInstance(e *environ, raw *google.Instance) *gceInstance {
+	return &gceInstance{
+		e:    e,
+		name: raw.Name,
+		zone: raw.ZoneName(),
+		raw:  raw,
+	}
+}
+
+func (inst *gceInstance) String() string {
+	return string(inst.Id())
+}
+
+func (inst *gceInstance) getInstance() *google.Instance {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+	return inst.raw
+}
+
+func (inst *gceInstance) Id() instance.Id {
+	return instance.Id(inst.name)
+}
+
+func (inst *gceInstance) Status() string {
+	return inst.getInstance().Status
+}
+
+// Refresh implements instance.Refresh(), requerying the
+// Instance details over the GCE API.
+func (inst *gceInstance) Refresh() error {
+	raw, err := inst.e.conn().Instance(inst.zone, inst.name)
+	if err != nil {
+		return err
+	}
+	inst.mu.Lock()
+	inst.raw = raw
+	inst.mu.Unlock()
+	return nil
+}
+
+func (inst *gceInstance) Addresses() ([]network.Address, error) {
+	var addresses []network.Address
+	for _, iface := range inst.getInstance().NetworkInterfaces {
+		if iface.NetworkIP != "" {
+			addresses = append(addresses, network.NewAddress(iface.NetworkIP, network.ScopeCloudLocal))
+		}
+		for _, access := range iface.AccessConfigs {
+			if access.NatIP != "" {
+				addresses = append(addresses, network.NewAddress(access.NatIP, network.ScopePublic))
+			}
+		}
+	}
+	return addresses, nil
+}
+
+// machinePrefix is the prefix of the names of the firewall rules
+// opening ports on the instance of the given machine.
+func (inst *gceInstance) machinePrefix(machineId string) string {
+	return inst.e.machineTag(machineId) + "-"
+}
+
+func (inst *gceInstance) OpenPorts(machineId string, ports []network.PortRange) error {
+	if inst.e.Config().FirewallMode() != config.FwInstance {
+		return fmt.Errorf("invalid firewall mode %q for opening ports on instance",
+			inst.e.Config().FirewallMode())
+	}
+	err := inst.e.openPortsWithPrefix(inst.machinePrefix(machineId), inst.e.machineTag(machineId), ports)
+	if err != nil {
+		return err
+	}
+	logger.Infof("opened ports on instance %s: %v", inst.Id(), ports)
+	return nil
+}
+
+func (inst *gceInstance) ClosePorts(machineId string, ports []network.PortRange) error {
+	if inst.e.Config().FirewallMode() != config.FwInstance {
+		return fmt.Errorf("invalid firewall mode %q for closing ports on instance",
+			inst.e.Config().FirewallMode())
+	}
+	err := inst.e.closePortsWithPrefix(inst.machinePrefix(machineId), inst.e.machineTag(machineId), ports)
+	if err != nil {
+		return err
+	}
+	logger.Infof("closed ports on instance %s: %v", inst.Id(), ports)
+	return nil
+}
+
+func (inst *gceInstance) Ports(machineId string) ([]network.PortRange, error) {
+	if inst.e.Config().FirewallMode() != config.FwInstance {
+		return nil, fmt.Errorf("invalid firewall mode %q for retrieving ports from instance",
+			inst.e.Config().FirewallMode())
+	}
+	return inst.e.portsWithPrefix(inst.machinePrefix(machineId))
+}
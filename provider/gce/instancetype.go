@@ -0,0 +1,130 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package gce
+
+import (
+	"github.com/juju/juju/environs/instances"
+	"github.com/juju/juju/juju/arch"
+)
+
+// All GCE machine types run amd64 images only.
+var amd64 = []string{arch.AMD64}
+
+// allInstanceTypes holds the relevant attributes of every known GCE
+// machine type. CpuPower is expressed in hundredths of a Google
+// Compute Engine Unit; Cost is the hourly price in USD/1000.
+var allInstanceTypes = []instances.InstanceType{
+	{ // Shared core.
+		Name:     "f1-micro",
+		Arches:   amd64,
+		CpuCores: 1,
+		CpuPower: instances.CpuPower(20),
+		Mem:      614,
+		Cost:     13,
+	}, {
+		Name:     "g1-small",
+		Arches:   amd64,
+		CpuCores: 1,
+		CpuPower: instances.CpuPower(138),
+		Mem:      1740,
+		Cost:     35,
+	},
+
+	{ // Standard.
+		Name:     "n1-standard-1",
+		Arches:   amd64,
+		CpuCores: 1,
+		CpuPower: instances.CpuPower(275),
+		Mem:      3840,
+		Cost:     70,
+	}, {
+		Name:     "n1-standard-2",
+		Arches:   amd64,
+		CpuCores: 2,
+		CpuPower: instances.CpuPower(550),
+		Mem:      7680,
+		Cost:     140,
+	}, {
+		Name:     "n1-standard-4",
+		Arches:   amd64,
+		CpuCores: 4,
+		CpuPower: instances.CpuPower(1100),
+		Mem:      15360,
+		Cost:     280,
+	}, {
+		Name:     "n1-standard-8",
+		Arches:   amd64,
+		CpuCores: 8,
+		CpuPower: instances.CpuPower(2200),
+		Mem:      30720,
+		Cost:     560,
+	}, {
+		Name:     "n1-standard-16",
+		Arches:   amd64,
+		CpuCores: 16,
+		CpuPower: instances.CpuPower(4400),
+		Mem:      61440,
+		Cost:     1120,
+	},
+
+	{ // High memory.
+		Name:     "n1-highmem-2",
+		Arches:   amd64,
+		CpuCores: 2,
+		CpuPower: instances.CpuPower(550),
+		Mem:      13312,
+		Cost:     164,
+	}, {
+		Name:     "n1-highmem-4",
+		Arches:   amd64,
+		CpuCores: 4,
+		CpuPower: instances.CpuPower(1100),
+		Mem:      26624,
+		Cost:     328,
+	}, {
+		Name:     "n1-highmem-8",
+		Arches:   amd64,
+		CpuCores: 8,
+		CpuPower: instances.CpuPower(2200),
+		Mem:      53248,
+		Cost:     656,
+	}, {
+		Name:     "n1-highmem-16",
+		Arches:   amd64,
+		CpuCores: 16,
+		CpuPower: instances.CpuPower(4400),
+		Mem:      106496,
+		Cost:     1312,
+	},
+
+	{ // High CPU.
+		Name:     "n1-highcpu-2",
+		Arches:   amd64,
+		CpuCores: 2,
+		CpuPower: instances.CpuPower(550),
+		Mem:      1843,
+		Cost:     88,
+	}, {
+		Name:     "n1-highcpu-4",
+		Arches:   amd64,
+		CpuCores: 4,
+		CpuPower: instances.CpuPower(1100),
+		Mem:      3686,
+		Cost:     176,
+	}, {
+		Name:     "n1-highcpu-8",
+		Arches:   amd64,
+		CpuCores: 8,
+		CpuPower: instances.CpuPower(2200),
+		Mem:      7373,
+		Cost:     352,
+	}, {
+		Name:     "n1-highcpu-16",
+		Arches:   amd64,
+		CpuCores: 16,
+		CpuPower: instances.CpuPower(4400),
+		Mem:      14746,
+		Cost:     704,
+	},
+}
@@ -0,0 +1,394 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package gce_test
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+	"launchpad.net/goyaml"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/bootstrap"
+	"github.com/juju/juju/environs/jujutest"
+	envtesting "github.com/juju/juju/environs/testing"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/juju/arch"
+	"github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/provider/common"
+	"github.com/juju/juju/provider/gce"
+	"github.com/juju/juju/provider/gce/gcetest"
+	"github.com/juju/juju/provider/gce/google"
+	"github.com/juju/juju/storage"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/version"
+)
+
+var localConfigAttrs = coretesting.FakeConfig().Merge(coretesting.Attrs{
+	"name":           "sample",
+	"type":           "gce",
+	"project-id":     "test-project",
+	"control-bucket": "test-bucket",
+	"client-email":   "juju@test-project.example.com",
+	"private-key":    testPrivateKey,
+	"agent-version":  version.Current.Number.String(),
+})
+
+func registerLocalTests() {
+	gc.Suite(&localServerSuite{})
+}
+
+// localServerSuite contains tests that run against a fake GCE server
+// running within the test process itself. It starts a new gcetest
+// server for each test.
+type localServerSuite struct {
+	coretesting.BaseSuite
+	jujutest.Tests
+	srv *gcetest.Server
+}
+
+func (t *localServerSuite) SetUpSuite(c *gc.C) {
+	t.BaseSuite.SetUpSuite(c)
+	gce.UseTestImageData(gce.TestImagesData)
+	t.AddSuiteCleanup(func(*gc.C) { gce.UseTestImageData(nil) })
+	restoreTimeouts := envtesting.PatchAttemptStrategies(gce.StorageAttempt)
+	t.AddSuiteCleanup(func(*gc.C) { restoreTimeouts() })
+	restoreFinishBootstrap := envtesting.DisableFinishBootstrap()
+	t.AddSuiteCleanup(func(*gc.C) { restoreFinishBootstrap() })
+}
+
+func (t *localServerSuite) SetUpTest(c *gc.C) {
+	t.BaseSuite.SetUpTest(c)
+	t.srv = gcetest.NewServer("test-project")
+	t.srv.SetZones(
+		google.Zone{Name: "us-central1-a", Status: google.ZoneUp, Region: t.srv.RegionURL("us-central1")},
+		google.Zone{Name: "us-central1-b", Status: google.ZoneUp, Region: t.srv.RegionURL("us-central1")},
+		google.Zone{Name: "us-central1-f", Status: google.ZoneDown, Region: t.srv.RegionURL("us-central1")},
+		google.Zone{Name: "europe-west1-a", Status: google.ZoneUp, Region: t.srv.RegionURL("europe-west1")},
+	)
+	t.PatchValue(gce.Endpoints, t.srv.Endpoints())
+	t.TestConfig = localConfigAttrs
+	t.Tests.SetUpTest(c)
+	t.PatchValue(&version.Current, version.Binary{
+		Number: version.Current.Number,
+		Series: coretesting.FakeDefaultSeries,
+		Arch:   arch.AMD64,
+	})
+}
+
+func (t *localServerSuite) TearDownTest(c *gc.C) {
+	t.Tests.TearDownTest(c)
+	t.srv.Close()
+	t.BaseSuite.TearDownTest(c)
+}
+
+// prepareWithTools prepares an environment with the given extra
+// attributes and uploads fake tools to its storage.
+func (t *localServerSuite) prepareWithTools(c *gc.C, attrs coretesting.Attrs) environs.Environ {
+	t.TestConfig = localConfigAttrs.Merge(attrs)
+	env := t.Prepare(c)
+	envtesting.UploadFakeTools(c, env.Storage())
+	return env
+}
+
+func (t *localServerSuite) TestBootstrapInstanceUserDataAndTags(c *gc.C) {
+	env := t.prepareWithTools(c, nil)
+	err := bootstrap.Bootstrap(coretesting.Context(c), env, environs.BootstrapParams{})
+	c.Assert(err, gc.IsNil)
+
+	bootstrapState, err := bootstrap.LoadState(env.Storage())
+	c.Assert(err, gc.IsNil)
+	c.Assert(bootstrapState.StateInstances, gc.HasLen, 1)
+	raw := t.srv.Instance(string(bootstrapState.StateInstances[0]))
+	c.Assert(raw, gc.NotNil)
+
+	c.Check(raw.Tags.Items, jc.SameContents, []string{"juju-sample", "juju-sample-0"})
+	c.Check(strings.HasPrefix(raw.Name, "juju-sample-0-"), jc.IsTrue)
+	c.Check(raw.Disks[0].InitializeParams.SourceImage, gc.Equals,
+		"projects/ubuntu-os-cloud/global/images/ubuntu-1204-precise-v20141001")
+
+	c.Assert(raw.Metadata.Items, gc.HasLen, 1)
+	c.Assert(raw.Metadata.Items[0].Key, gc.Equals, "user-data")
+	var userData map[interface{}]interface{}
+	err = goyaml.Unmarshal([]byte(raw.Metadata.Items[0].Value), &userData)
+	c.Assert(err, gc.IsNil)
+	c.Check(userData["runcmd"], gc.NotNil)
+
+	// The base firewall rules are in place.
+	c.Check(t.srv.FirewallNames(), jc.SameContents, []string{"juju-sample", "juju-sample-internal"})
+	rule := t.srv.Firewall("juju-sample")
+	c.Check(rule.SourceRanges, gc.DeepEquals, []string{"0.0.0.0/0"})
+	c.Check(rule.TargetTags, gc.DeepEquals, []string{"juju-sample"})
+	c.Check(rule.Allowed[0].Ports, jc.SameContents, []string{
+		"22",
+		strconv.Itoa(env.Config().StatePort()),
+		strconv.Itoa(env.Config().APIPort()),
+	})
+}
+
+func (t *localServerSuite) TestDestroy(c *gc.C) {
+	env := t.prepareWithTools(c, nil)
+	err := bootstrap.Bootstrap(coretesting.Context(c), env, environs.BootstrapParams{})
+	c.Assert(err, gc.IsNil)
+	// A rule of another environment whose name starts with this
+	// environment's name is left alone.
+	t.srv.AddFirewall(google.Firewall{Name: "juju-sample-other-internal"})
+
+	err = env.Destroy()
+	c.Assert(err, gc.IsNil)
+	insts, err := env.AllInstances()
+	c.Assert(err, gc.IsNil)
+	c.Assert(insts, gc.HasLen, 0)
+	c.Assert(t.srv.FirewallNames(), gc.DeepEquals, []string{"juju-sample-other-internal"})
+}
+
+func (t *localServerSuite) TestInstanceAddresses(c *gc.C) {
+	env := t.prepareWithTools(c, nil)
+	inst, _ := testing.AssertStartInstance(c, env, "1")
+	addrs, err := inst.Addresses()
+	c.Assert(err, gc.IsNil)
+	c.Assert(addrs, gc.HasLen, 2)
+	c.Check(addrs[0].Scope, gc.Equals, network.ScopeCloudLocal)
+	c.Check(addrs[1].Scope, gc.Equals, network.ScopePublic)
+}
+
+func (t *localServerSuite) TestStartInstanceHardwareCharacteristics(c *gc.C) {
+	env := t.prepareWithTools(c, nil)
+	inst, hc := testing.AssertStartInstanceWithConstraints(c, env, "1", constraints.MustParse("mem=7G root-disk=20000M"))
+	c.Check(*hc.Arch, gc.Equals, "amd64")
+	c.Check(*hc.Mem, gc.Equals, uint64(7680))
+	c.Check(*hc.CpuCores, gc.Equals, uint64(2))
+	c.Check(*hc.RootDisk, gc.Equals, uint64(20*1024))
+	raw := t.srv.Instance(string(inst.Id()))
+	c.Check(raw.MachineType, gc.Matches, ".*/machineTypes/n1-standard-2")
+	c.Check(raw.Disks[0].InitializeParams.DiskSizeGb, gc.Equals, int64(20))
+}
+
+func (t *localServerSuite) TestStartInstanceInstanceType(c *gc.C) {
+	env := t.prepareWithTools(c, nil)
+	inst, hc := testing.AssertStartInstanceWithConstraints(c, env, "1", constraints.MustParse("instance-type=n1-highcpu-4"))
+	c.Check(*hc.CpuCores, gc.Equals, uint64(4))
+	c.Check(*hc.RootDisk, gc.Equals, uint64(10*1024))
+	raw := t.srv.Instance(string(inst.Id()))
+	c.Check(raw.MachineType, gc.Matches, "zones/us-central1-[ab]/machineTypes/n1-highcpu-4")
+}
+
+func (t *localServerSuite) TestStartInstanceVolumesNotSupported(c *gc.C) {
+	env := t.prepareWithTools(c, nil)
+	params := environs.StartInstanceParams{
+		Volumes: []storage.VolumeParams{{Name: "0", Size: 1024}},
+	}
+	_, _, _, err := testing.StartInstanceWithParams(env, "1", params, nil)
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (t *localServerSuite) TestStartInstanceAvailZone(c *gc.C) {
+	inst, err := t.testStartInstanceAvailZone(c, "us-central1-b")
+	c.Assert(err, gc.IsNil)
+	c.Assert(gce.InstanceZone(inst), gc.Equals, "us-central1-b")
+}
+
+func (t *localServerSuite) TestStartInstanceAvailZoneDown(c *gc.C) {
+	_, err := t.testStartInstanceAvailZone(c, "us-central1-f")
+	c.Assert(err, gc.ErrorMatches, `availability zone "us-central1-f" is DOWN`)
+}
+
+func (t *localServerSuite) TestStartInstanceAvailZoneOtherRegion(c *gc.C) {
+	_, err := t.testStartInstanceAvailZone(c, "europe-west1-a")
+	c.Assert(err, gc.ErrorMatches, `invalid availability zone "europe-west1-a"`)
+}
+
+func (t *localServerSuite) testStartInstanceAvailZone(c *gc.C, zone string) (instance.Instance, error) {
+	env := t.prepareWithTools(c, nil)
+	params := environs.StartInstanceParams{Placement: "zone=" + zone}
+	inst, _, _, err := testing.StartInstanceWithParams(env, "1", params, nil)
+	return inst, err
+}
+
+func (t *localServerSuite) TestAvailabilityZones(c *gc.C) {
+	env := t.Prepare(c).(common.ZonedEnviron)
+	zones, err := env.AvailabilityZones()
+	c.Assert(err, gc.IsNil)
+	c.Assert(zones, gc.HasLen, 3)
+	for i, name := range []string{"us-central1-a", "us-central1-b", "us-central1-f"} {
+		c.Check(zones[i].Name(), gc.Equals, name)
+		c.Check(zones[i].Available(), gc.Equals, name != "us-central1-f")
+	}
+}
+
+func (t *localServerSuite) TestStartInstanceDistributesZones(c *gc.C) {
+	env := t.prepareWithTools(c, nil)
+	inst0, _ := testing.AssertStartInstance(c, env, "1")
+	inst1, _ := testing.AssertStartInstance(c, env, "2")
+	zones := []string{gce.InstanceZone(inst0), gce.InstanceZone(inst1)}
+	c.Assert(zones, jc.SameContents, []string{"us-central1-a", "us-central1-b"})
+
+	zoneNames, err := env.(common.ZonedEnviron).InstanceAvailabilityZoneNames(
+		[]instance.Id{inst0.Id(), inst1.Id()})
+	c.Assert(err, gc.IsNil)
+	c.Assert(zoneNames, gc.DeepEquals, zones)
+}
+
+func (t *localServerSuite) TestStartInstanceDistributionGroup(c *gc.C) {
+	var group []instance.Id
+	t.PatchValue(gce.BestAvailabilityZoneAllocations, func(env common.ZonedEnviron, g []instance.Id) (map[string][]instance.Id, error) {
+		group = g
+		return map[string][]instance.Id{"us-central1-b": nil}, nil
+	})
+	env := t.prepareWithTools(c, nil)
+	params := environs.StartInstanceParams{
+		DistributionGroup: func() ([]instance.Id, error) {
+			return []instance.Id{"inst-0"}, nil
+		},
+	}
+	inst, _, _, err := testing.StartInstanceWithParams(env, "1", params, nil)
+	c.Assert(err, gc.IsNil)
+	c.Assert(group, gc.DeepEquals, []instance.Id{"inst-0"})
+	c.Assert(gce.InstanceZone(inst), gc.Equals, "us-central1-b")
+}
+
+func (t *localServerSuite) TestGlobalPorts(c *gc.C) {
+	env := t.prepareWithTools(c, coretesting.Attrs{"firewall-mode": "global"})
+	inst, _ := testing.AssertStartInstance(c, env, "1")
+
+	ports, err := env.Ports()
+	c.Assert(err, gc.IsNil)
+	c.Assert(ports, gc.HasLen, 0)
+
+	err = env.OpenPorts([]network.PortRange{
+		{Protocol: "tcp", FromPort: 80, ToPort: 80},
+		{Protocol: "udp", FromPort: 100, ToPort: 110, SourceCIDR: "10.0.0.0/8"},
+	})
+	c.Assert(err, gc.IsNil)
+	// Opening a port twice is harmless.
+	err = env.OpenPorts([]network.PortRange{{Protocol: "tcp", FromPort: 80, ToPort: 80}})
+	c.Assert(err, gc.IsNil)
+
+	ports, err = env.Ports()
+	c.Assert(err, gc.IsNil)
+	c.Assert(ports, gc.DeepEquals, []network.PortRange{
+		{Protocol: "tcp", FromPort: 80, ToPort: 80},
+		{Protocol: "udp", FromPort: 100, ToPort: 110, SourceCIDR: "10.0.0.0/8"},
+	})
+	for _, name := range t.srv.FirewallNames() {
+		if strings.HasPrefix(name, "juju-sample-global-") {
+			c.Check(t.srv.Firewall(name).TargetTags, gc.DeepEquals, []string{"juju-sample"})
+		}
+	}
+
+	err = env.ClosePorts([]network.PortRange{{Protocol: "tcp", FromPort: 80, ToPort: 80}})
+	c.Assert(err, gc.IsNil)
+	ports, err = env.Ports()
+	c.Assert(err, gc.IsNil)
+	c.Assert(ports, gc.DeepEquals, []network.PortRange{
+		{Protocol: "udp", FromPort: 100, ToPort: 110, SourceCIDR: "10.0.0.0/8"},
+	})
+
+	err = env.OpenPorts([]network.PortRange{{Protocol: "icmp"}})
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+
+	// Instance ports are refused in global mode.
+	err = inst.OpenPorts("1", []network.PortRange{{Protocol: "tcp", FromPort: 80, ToPort: 80}})
+	c.Assert(err, gc.ErrorMatches, `invalid firewall mode "global" for opening ports on instance`)
+	_, err = inst.Ports("1")
+	c.Assert(err, gc.ErrorMatches, `invalid firewall mode "global" for retrieving ports from instance`)
+}
+
+func (t *localServerSuite) TestInstancePorts(c *gc.C) {
+	env := t.prepareWithTools(c, nil)
+	inst1, _ := testing.AssertStartInstance(c, env, "1")
+	inst10, _ := testing.AssertStartInstance(c, env, "10")
+
+	err := inst1.OpenPorts("1", []network.PortRange{
+		{Protocol: "tcp", FromPort: 8080, ToPort: 8081},
+		{Protocol: "tcp", FromPort: 22, ToPort: 22},
+	})
+	c.Assert(err, gc.IsNil)
+	err = inst10.OpenPorts("10", []network.PortRange{{Protocol: "udp", FromPort: 53, ToPort: 53}})
+	c.Assert(err, gc.IsNil)
+
+	ports, err := inst1.Ports("1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(ports, gc.DeepEquals, []network.PortRange{
+		{Protocol: "tcp", FromPort: 22, ToPort: 22},
+		{Protocol: "tcp", FromPort: 8080, ToPort: 8081},
+	})
+	ports, err = inst10.Ports("10")
+	c.Assert(err, gc.IsNil)
+	c.Assert(ports, gc.DeepEquals, []network.PortRange{{Protocol: "udp", FromPort: 53, ToPort: 53}})
+	for _, name := range t.srv.FirewallNames() {
+		if strings.HasPrefix(name, "juju-sample-10-") {
+			c.Check(t.srv.Firewall(name).TargetTags, gc.DeepEquals, []string{"juju-sample-10"})
+		}
+	}
+
+	err = inst1.ClosePorts("1", []network.PortRange{{Protocol: "tcp", FromPort: 22, ToPort: 22}})
+	c.Assert(err, gc.IsNil)
+	ports, err = inst1.Ports("1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(ports, gc.DeepEquals, []network.PortRange{{Protocol: "tcp", FromPort: 8080, ToPort: 8081}})
+
+	// Environment ports are refused in instance mode.
+	err = env.OpenPorts([]network.PortRange{{Protocol: "tcp", FromPort: 80, ToPort: 80}})
+	c.Assert(err, gc.ErrorMatches, `invalid firewall mode "instance" for opening ports on environment`)
+	_, err = env.Ports()
+	c.Assert(err, gc.ErrorMatches, `invalid firewall mode "instance" for retrieving ports from environment`)
+}
+
+func (t *localServerSuite) TestSupportedArchitectures(c *gc.C) {
+	env := t.Prepare(c)
+	a, err := env.SupportedArchitectures()
+	c.Assert(err, gc.IsNil)
+	c.Assert(a, jc.SameContents, []string{"amd64"})
+}
+
+func (t *localServerSuite) TestSupportNetworks(c *gc.C) {
+	env := t.Prepare(c)
+	c.Assert(env.SupportNetworks(), jc.IsFalse)
+}
+
+func (t *localServerSuite) TestConstraintsValidator(c *gc.C) {
+	env := t.Prepare(c)
+	validator, err := env.ConstraintsValidator()
+	c.Assert(err, gc.IsNil)
+	cons := constraints.MustParse("arch=amd64 tags=foo")
+	unsupported, err := validator.Validate(cons)
+	c.Assert(err, gc.IsNil)
+	c.Assert(unsupported, gc.DeepEquals, []string{"tags"})
+
+	_, err = validator.Validate(constraints.MustParse("instance-type=n1-standard-1 mem=4G"))
+	c.Assert(err, gc.ErrorMatches, `ambiguous constraints: "instance-type" overlaps with "mem"`)
+	_, err = validator.Validate(constraints.MustParse("instance-type=m1.small"))
+	c.Assert(err, gc.ErrorMatches, `invalid constraint value: instance-type=m1.small\nvalid values are:.*`)
+}
+
+func (t *localServerSuite) TestPrecheckInstance(c *gc.C) {
+	env := t.Prepare(c)
+	prechecker, ok := env.(interface {
+		PrecheckInstance(string, constraints.Value, string) error
+	})
+	c.Assert(ok, jc.IsTrue)
+	err := prechecker.PrecheckInstance("precise", constraints.MustParse("instance-type=g1-small"), "zone=us-central1-a")
+	c.Assert(err, gc.IsNil)
+	err = prechecker.PrecheckInstance("precise", constraints.MustParse("instance-type=m1.small"), "")
+	c.Assert(err, gc.ErrorMatches, `invalid GCE instance type "m1.small" specified`)
+	err = prechecker.PrecheckInstance("precise", constraints.MustParse("instance-type=g1-small arch=i386"), "")
+	c.Assert(err, gc.ErrorMatches, `invalid GCE instance type "g1-small" and arch "i386" specified`)
+	err = prechecker.PrecheckInstance("precise", constraints.Value{}, "zone=us-central1-z")
+	c.Assert(err, gc.ErrorMatches, `invalid availability zone "us-central1-z"`)
+	err = prechecker.PrecheckInstance("precise", constraints.Value{}, "host=foo")
+	c.Assert(err, gc.ErrorMatches, `unknown placement directive: host=foo`)
+}
+
+func (t *localServerSuite) TestStorageURL(c *gc.C) {
+	env := t.Prepare(c)
+	url, err := env.Storage().URL("tools/foo")
+	c.Assert(err, gc.IsNil)
+	c.Assert(url, gc.Matches, t.srv.URL()+"/objects/"+gce.ControlBucketName(env)+`/tools/foo\?Expires=.*&GoogleAccessId=.*&Signature=.*`)
+}
@@ -0,0 +1,122 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package gce implements the Google Compute Engine provider.
+//
+// Instances are started in the zones of the configured region, and
+// spread across them; the environment's files are kept in a Google
+// Cloud Storage bucket. Instances are tagged with the environment's
+// name, and the firewall rules that open their ports target those
+// tags.
+package gce
+
+import (
+	"fmt"
+
+	"github.com/juju/loggo"
+	"github.com/juju/utils"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/environs/simplestreams"
+	"github.com/juju/juju/juju/arch"
+)
+
+var logger = loggo.GetLogger("juju.provider.gce")
+
+func init() {
+	environs.RegisterProvider("gce", environProvider{})
+}
+
+type environProvider struct{}
+
+var providerInstance environProvider
+
+var _ environs.EnvironProvider = providerInstance
+
+func (p environProvider) BoilerplateConfig() string {
+	return `
+# https://juju.ubuntu.com/docs/config-gce.html
+gce:
+    type: gce
+
+    # project-id is the id of the Google Cloud project in which
+    # instances are started. It must be set.
+    #
+    # project-id: <project id>
+
+    # region is the region in which instances are started. It
+    # defaults to us-central1.
+    #
+    # region: us-central1
+
+    # auth-file is the path of the JSON key file generated for the
+    # service account juju uses to access the project. Alternatively,
+    # client-id, client-email and private-key may be set to the values
+    # held in that file.
+    #
+    # auth-file: ~/.config/gcloud/juju.json
+    # client-id: <secret>
+    # client-email: <secret>
+    # private-key: <secret>
+
+    # image-stream chooses a simplestreams stream to select OS images
+    # from, for example daily or released images (or any other stream
+    # available on simplestreams).
+    #
+    # image-stream: "released"
+
+`[1:]
+}
+
+func (p environProvider) Open(cfg *config.Config) (environs.Environ, error) {
+	logger.Infof("opening environment %q", cfg.Name())
+	e := new(environ)
+	e.name = cfg.Name()
+	if err := e.SetConfig(cfg); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (p environProvider) Prepare(ctx environs.BootstrapContext, cfg *config.Config) (environs.Environ, error) {
+	attrs := cfg.UnknownAttrs()
+	if _, ok := attrs["control-bucket"]; !ok {
+		uuid, err := utils.NewUUID()
+		if err != nil {
+			return nil, err
+		}
+		// Bucket names are global to Cloud Storage.
+		attrs["control-bucket"] = fmt.Sprintf("juju-%x", uuid.Raw())
+	}
+	cfg, err := cfg.Apply(attrs)
+	if err != nil {
+		return nil, err
+	}
+	return p.Open(cfg)
+}
+
+// MetadataLookupParams returns parameters which are used to query image metadata to
+// find matching image information.
+func (p environProvider) MetadataLookupParams(region string) (*simplestreams.MetadataLookupParams, error) {
+	if region == "" {
+		return nil, fmt.Errorf("region must be specified")
+	}
+	return &simplestreams.MetadataLookupParams{
+		Region:        region,
+		Endpoint:      imageEndpoint,
+		Architectures: []string{arch.AMD64},
+	}, nil
+}
+
+func (environProvider) SecretAttrs(cfg *config.Config) (map[string]string, error) {
+	ecfg, err := providerInstance.newConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	secretAttrs := make(map[string]string)
+	for _, field := range configSecretFields {
+		secretAttrs[field] = ecfg.attrs[field].(string)
+	}
+	return secretAttrs, nil
+}
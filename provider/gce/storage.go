@@ -0,0 +1,143 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package gce
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+
+	"github.com/juju/juju/environs/storage"
+	"github.com/juju/juju/provider/gce/google"
+)
+
+// gceStorage implements storage.Storage on a Google Cloud Storage
+// bucket.
+type gceStorage struct {
+	sync.Mutex
+	madeBucket bool
+	conn       *google.Connection
+	bucket     string
+}
+
+var _ storage.Storage = (*gceStorage)(nil)
+
+// makeBucket makes the environent's control bucket, the
+// place where bootstrap information and deployed charms
+// are stored. To avoid two round trips on every PUT operation,
+// we do this only once for each environ.
+func (s *gceStorage) makeBucket() error {
+	s.Lock()
+	defer s.Unlock()
+	if s.madeBucket {
+		return nil
+	}
+	if err := s.conn.AddBucket(s.bucket); err != nil && !google.IsConflict(err) {
+		return err
+	}
+	s.madeBucket = true
+	return nil
+}
+
+func (s *gceStorage) Put(file string, r io.Reader, length int64) error {
+	if err := s.makeBucket(); err != nil {
+		return fmt.Errorf("cannot make control bucket: %v", err)
+	}
+	if err := s.conn.PutObject(s.bucket, file, r, length); err != nil {
+		return fmt.Errorf("cannot write file %q to control bucket: %v", file, err)
+	}
+	return nil
+}
+
+func (s *gceStorage) Get(file string) (io.ReadCloser, error) {
+	r, err := s.conn.Object(s.bucket, file)
+	if google.IsNotFound(err) {
+		return nil, errors.NewNotFound(err, "")
+	}
+	return r, err
+}
+
+func (s *gceStorage) URL(name string) (string, error) {
+	// 10 years should be good enough.
+	return s.conn.SignedObjectURL(s.bucket, name, time.Now().AddDate(10, 0, 0))
+}
+
+var storageAttempt = utils.AttemptStrategy{
+	Total: 5 * time.Second,
+	Delay: 200 * time.Millisecond,
+}
+
+// DefaultConsistencyStrategy is specified in the StorageReader interface.
+func (s *gceStorage) DefaultConsistencyStrategy() utils.AttemptStrategy {
+	return storageAttempt
+}
+
+// ShouldRetry is specified in the StorageReader interface.
+func (s *gceStorage) ShouldRetry(err error) bool {
+	if err == nil {
+		return false
+	}
+	switch err {
+	case io.ErrUnexpectedEOF, io.EOF:
+		return true
+	}
+	switch e := err.(type) {
+	case *net.DNSError:
+		return true
+	case *net.OpError:
+		switch e.Op {
+		case "read", "write":
+			return true
+		}
+	case *google.Error:
+		return e.Code >= 500
+	}
+	return false
+}
+
+func (s *gceStorage) Remove(file string) error {
+	err := s.conn.RemoveObject(s.bucket, file)
+	// If we can't delete the object because the bucket doesn't
+	// exist, then we don't care.
+	if google.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (s *gceStorage) List(prefix string) ([]string, error) {
+	names, err := s.conn.Objects(s.bucket, prefix)
+	// If the bucket is not found, it's not an error
+	// because it's only created when the first
+	// file is put.
+	if google.IsNotFound(err) {
+		return nil, nil
+	}
+	return names, err
+}
+
+func (s *gceStorage) RemoveAll() error {
+	names, err := storage.List(s, "")
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := s.Remove(name); err != nil {
+			return fmt.Errorf("cannot delete all provider state: %v", err)
+		}
+	}
+	s.Lock()
+	defer s.Unlock()
+	s.madeBucket = false
+	err = s.conn.RemoveBucket(s.bucket)
+	if google.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
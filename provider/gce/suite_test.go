@@ -0,0 +1,34 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package gce_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	gc "launchpad.net/gocheck"
+)
+
+func TestGCE(t *testing.T) {
+	registerLocalTests()
+	gc.TestingT(t)
+}
+
+// testPrivateKey holds a PEM encoded RSA key for the service
+// account used by the tests.
+var testPrivateKey = generatePrivateKey()
+
+func generatePrivateKey() string {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		panic(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}))
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/juju/errors"
+
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/config"
@@ -123,6 +125,11 @@ func (e *joyentEnviron) SupportNetworks() bool {
 	return false
 }
 
+// SupportsSourceCIDRs is specified on the EnvironCapability interface.
+func (e *joyentEnviron) SupportsSourceCIDRs() error {
+	return errors.NotSupportedf("restricting access to source CIDRs")
+}
+
 func (env *joyentEnviron) SetConfig(cfg *config.Config) error {
 	env.lock.Lock()
 	defer env.lock.Unlock()
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/joyent/gosdc/cloudapi"
+	"github.com/juju/errors"
 
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/network"
@@ -34,8 +35,23 @@ func ruleExists(rules []cloudapi.FirewallRule, rule string) (bool, string) {
 	return false, ""
 }
 
-// Helper method to get port from the given firewall rules
-func getPorts(env *joyentEnviron, rules []cloudapi.FirewallRule) []network.Port {
+// Helper method to get the individual ports in the given port ranges, as
+// each firewall rule opens a single port. Firewall rules are not
+// restricted to source CIDRs, so an error is returned if any of the
+// port ranges has one.
+func rulePorts(ports []network.PortRange) ([]network.Port, error) {
+	var result []network.Port
+	for _, p := range ports {
+		if p.SourceCIDR != "" {
+			return nil, errors.NotSupportedf("opening ports to source CIDR %q", p.SourceCIDR)
+		}
+		result = append(result, p.Ports()...)
+	}
+	return result, nil
+}
+
+// Helper method to get port ranges from the given firewall rules
+func getPorts(env *joyentEnviron, rules []cloudapi.FirewallRule) []network.PortRange {
 	ports := []network.Port{}
 	for _, r := range rules {
 		rule := r.Rule
@@ -47,21 +63,25 @@ func getPorts(env *joyentEnviron, rules []cloudapi.FirewallRule) []network.Port
 		}
 	}
 
-	network.SortPorts(ports)
-	return ports
+	return network.CollapsePorts(ports)
 }
 
-func (env *joyentEnviron) OpenPorts(ports []network.Port) error {
+func (env *joyentEnviron) OpenPorts(ports []network.PortRange) error {
 	if env.Config().FirewallMode() != config.FwGlobal {
 		return fmt.Errorf("invalid firewall mode %q for opening ports on environment", env.Config().FirewallMode())
 	}
 
+	singlePorts, err := rulePorts(ports)
+	if err != nil {
+		return err
+	}
+
 	fwRules, err := env.compute.cloudapi.ListFirewallRules()
 	if err != nil {
 		return fmt.Errorf("cannot get firewall rules: %v", err)
 	}
 
-	for _, p := range ports {
+	for _, p := range singlePorts {
 		rule := createFirewallRuleAll(env, p)
 		if e, id := ruleExists(fwRules, rule); e {
 			_, err := env.compute.cloudapi.EnableFirewallRule(id)
@@ -84,17 +104,22 @@ func (env *joyentEnviron) OpenPorts(ports []network.Port) error {
 	return nil
 }
 
-func (env *joyentEnviron) ClosePorts(ports []network.Port) error {
+func (env *joyentEnviron) ClosePorts(ports []network.PortRange) error {
 	if env.Config().FirewallMode() != config.FwGlobal {
 		return fmt.Errorf("invalid firewall mode %q for closing ports on environment", env.Config().FirewallMode())
 	}
 
+	singlePorts, err := rulePorts(ports)
+	if err != nil {
+		return err
+	}
+
 	fwRules, err := env.compute.cloudapi.ListFirewallRules()
 	if err != nil {
 		return fmt.Errorf("cannot get firewall rules: %v", err)
 	}
 
-	for _, p := range ports {
+	for _, p := range singlePorts {
 		rule := createFirewallRuleAll(env, p)
 		if e, id := ruleExists(fwRules, rule); e {
 			_, err := env.compute.cloudapi.DisableFirewallRule(id)
@@ -117,7 +142,7 @@ func (env *joyentEnviron) ClosePorts(ports []network.Port) error {
 	return nil
 }
 
-func (env *joyentEnviron) Ports() ([]network.Port, error) {
+func (env *joyentEnviron) Ports() ([]network.PortRange, error) {
 	if env.Config().FirewallMode() != config.FwGlobal {
 		return nil, fmt.Errorf("invalid firewall mode %q for retrieving ports from environment", env.Config().FirewallMode())
 	}
@@ -22,18 +22,23 @@ func createFirewallRuleVm(env *joyentEnviron, machineId string, port network.Por
 	return fmt.Sprintf(firewallRuleVm, env.Name(), machineId, strings.ToLower(port.Protocol), port.Number)
 }
 
-func (inst *joyentInstance) OpenPorts(machineId string, ports []network.Port) error {
+func (inst *joyentInstance) OpenPorts(machineId string, ports []network.PortRange) error {
 	if inst.env.Config().FirewallMode() != config.FwInstance {
 		return fmt.Errorf("invalid firewall mode %q for opening ports on instance", inst.env.Config().FirewallMode())
 	}
 
+	singlePorts, err := rulePorts(ports)
+	if err != nil {
+		return err
+	}
+
 	fwRules, err := inst.env.compute.cloudapi.ListFirewallRules()
 	if err != nil {
 		return fmt.Errorf("cannot get firewall rules: %v", err)
 	}
 
 	machineId = string(inst.Id())
-	for _, p := range ports {
+	for _, p := range singlePorts {
 		rule := createFirewallRuleVm(inst.env, machineId, p)
 		if e, id := ruleExists(fwRules, rule); e {
 			_, err := inst.env.compute.cloudapi.EnableFirewallRule(id)
@@ -56,18 +61,23 @@ func (inst *joyentInstance) OpenPorts(machineId string, ports []network.Port) er
 	return nil
 }
 
-func (inst *joyentInstance) ClosePorts(machineId string, ports []network.Port) error {
+func (inst *joyentInstance) ClosePorts(machineId string, ports []network.PortRange) error {
 	if inst.env.Config().FirewallMode() != config.FwInstance {
 		return fmt.Errorf("invalid firewall mode %q for closing ports on instance", inst.env.Config().FirewallMode())
 	}
 
+	singlePorts, err := rulePorts(ports)
+	if err != nil {
+		return err
+	}
+
 	fwRules, err := inst.env.compute.cloudapi.ListFirewallRules()
 	if err != nil {
 		return fmt.Errorf("cannot get firewall rules: %v", err)
 	}
 
 	machineId = string(inst.Id())
-	for _, p := range ports {
+	for _, p := range singlePorts {
 		rule := createFirewallRuleVm(inst.env, machineId, p)
 		if e, id := ruleExists(fwRules, rule); e {
 			_, err := inst.env.compute.cloudapi.DisableFirewallRule(id)
@@ -90,7 +100,7 @@ func (inst *joyentInstance) ClosePorts(machineId string, ports []network.Port) e
 	return nil
 }
 
-func (inst *joyentInstance) Ports(machineId string) ([]network.Port, error) {
+func (inst *joyentInstance) Ports(machineId string) ([]network.PortRange, error) {
 	if inst.env.Config().FirewallMode() != config.FwInstance {
 		return nil, fmt.Errorf("invalid firewall mode %q for retrieving ports from instance", inst.env.Config().FirewallMode())
 	}
@@ -56,6 +56,23 @@ func (s *configSuite) TestDefaultNetworkBridge(c *gc.C) {
 	c.Assert(unknownAttrs["network-bridge"], gc.Equals, "lxcbr0")
 }
 
+func (s *configSuite) TestDefaultNetworkBridgeLXD(c *gc.C) {
+	config := localConfig(c, map[string]interface{}{
+		"container": "lxd",
+	})
+	unknownAttrs := config.UnknownAttrs()
+	c.Assert(unknownAttrs["network-bridge"], gc.Equals, "lxdbr0")
+}
+
+func (s *configSuite) TestSetNetworkBridgeLXD(c *gc.C) {
+	config := localConfig(c, map[string]interface{}{
+		"container":      "lxd",
+		"network-bridge": "br0",
+	})
+	unknownAttrs := config.UnknownAttrs()
+	c.Assert(unknownAttrs["network-bridge"], gc.Equals, "br0")
+}
+
 func (s *configSuite) TestSetNetworkBridge(c *gc.C) {
 	config := localConfig(c, map[string]interface{}{
 		"network-bridge": "br0",
@@ -59,6 +59,7 @@ var _ envtools.SupportsCustomSources = (*localEnviron)(nil)
 
 type localEnviron struct {
 	common.SupportsUnitPlacementPolicy
+	common.SupportsSourceCIDRsPolicy
 
 	localMutex       sync.Mutex
 	config           *environConfig
@@ -512,17 +513,17 @@ func (env *localEnviron) Destroy() error {
 }
 
 // OpenPorts is specified in the Environ interface.
-func (env *localEnviron) OpenPorts(ports []network.Port) error {
+func (env *localEnviron) OpenPorts(ports []network.PortRange) error {
 	return fmt.Errorf("open ports not implemented")
 }
 
 // ClosePorts is specified in the Environ interface.
-func (env *localEnviron) ClosePorts(ports []network.Port) error {
+func (env *localEnviron) ClosePorts(ports []network.PortRange) error {
 	return fmt.Errorf("close ports not implemented")
 }
 
 // Ports is specified in the Environ interface.
-func (env *localEnviron) Ports() ([]network.Port, error) {
+func (env *localEnviron) Ports() ([]network.PortRange, error) {
 	return nil, nil
 }
 
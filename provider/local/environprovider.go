@@ -15,6 +15,7 @@ import (
 	"github.com/juju/utils/apt"
 	"github.com/juju/utils/proxy"
 
+	"github.com/juju/juju/container/lxd"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/instance"
@@ -183,6 +184,11 @@ func (provider environProvider) Validate(cfg, old *config.Config) (valid *config
 		return nil, fmt.Errorf("failed to validate unknown attrs: %v", err)
 	}
 	localConfig := newEnvironConfig(cfg, validated)
+	// LXD containers are bridged to LXD's own bridge unless
+	// another is specified.
+	if _, ok := cfg.UnknownAttrs()["network-bridge"]; !ok && localConfig.container() == instance.LXD {
+		localConfig.attrs["network-bridge"] = lxd.DefaultLxdBridge
+	}
 	// Before potentially creating directories, make sure that the
 	// root directory has not changed.
 	containerType := localConfig.container()
@@ -211,8 +217,8 @@ func (provider environProvider) Validate(cfg, old *config.Config) (valid *config
 				localConfig.storagePort())
 		}
 	}
-	// Currently only supported containers are "lxc" and "kvm".
-	if containerType != instance.LXC && containerType != instance.KVM {
+	// Currently only supported containers are "lxc", "kvm" and "lxd".
+	if containerType != instance.LXC && containerType != instance.KVM && containerType != instance.LXD {
 		return nil, fmt.Errorf("unsupported container type: %q", containerType)
 	}
 	dir, err := utils.NormalizePath(localConfig.rootDir())
@@ -250,10 +256,16 @@ local:
 
     # network-bridge holds the name of the LXC network bridge to use.
     # Override if the default LXC network bridge is different.
+    # When container is lxd, the default is lxdbr0.
     #
     #
     # network-bridge: lxcbr0
 
+    # container holds the type of container the local provider
+    # creates machines in: lxc (the default), kvm or lxd.
+    #
+    # container: lxc
+
     # The default series to deploy the state-server and charms on.
     # Make sure to uncomment the following option and set the value to
     # precise or trusty as desired.
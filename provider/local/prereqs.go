@@ -13,6 +13,7 @@ import (
 	"github.com/juju/utils/apt"
 
 	"github.com/juju/juju/container/kvm"
+	"github.com/juju/juju/container/lxd"
 	"github.com/juju/juju/instance"
 )
 
@@ -69,6 +70,8 @@ var VerifyPrerequisites = func(containerType instance.ContainerType) error {
 		return verifyLxc()
 	case instance.KVM:
 		return kvm.VerifyKVMEnabled()
+	case instance.LXD:
+		return lxd.VerifyLXDEnabled()
 	}
 	return fmt.Errorf("Unknown container type specified in the config.")
 }
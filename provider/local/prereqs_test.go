@@ -11,6 +11,7 @@ import (
 	"github.com/juju/utils/apt"
 	gc "launchpad.net/gocheck"
 
+	"github.com/juju/juju/container/lxd"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/mongo"
 	"github.com/juju/juju/testing"
@@ -94,6 +95,24 @@ func (s *prereqsSuite) TestLxcPrereq(c *gc.C) {
 	c.Assert(err, gc.IsNil)
 }
 
+func (s *prereqsSuite) TestLxdPrereq(c *gc.C) {
+	supported := false
+	s.PatchValue(&lxd.IsLXDSupported, func() (bool, error) {
+		return supported, nil
+	})
+	err := VerifyPrerequisites(instance.LXD)
+	c.Assert(err, gc.ErrorMatches, "(.|\n)*The LXD server is not running(.|\n)*")
+	c.Assert(err, gc.ErrorMatches, "(.|\n)*apt-get install lxd(.|\n)*")
+
+	supported = true
+	err = VerifyPrerequisites(instance.LXD)
+	c.Assert(err, gc.IsNil)
+
+	os.Setenv("JUJUTEST_LSB_RELEASE_ID", "NotUbuntu")
+	err = VerifyPrerequisites(instance.LXD)
+	c.Assert(err, gc.ErrorMatches, "Sorry, LXD support with the local provider is only supported\non the Ubuntu OS.")
+}
+
 func (s *prereqsSuite) TestJujuLocalPrereq(c *gc.C) {
 	err := os.Remove(filepath.Join(s.tmpdir, "dpkg-query"))
 	c.Assert(err, gc.IsNil)
@@ -0,0 +1,159 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package maas
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/juju/errors"
+	"launchpad.net/gomaasapi"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/provider/common"
+	"github.com/juju/juju/state"
+)
+
+var _ common.ZonedEnviron = (*maasEnviron)(nil)
+var _ state.InstanceDistributor = (*maasEnviron)(nil)
+
+type maasAvailabilityZone struct {
+	name string
+}
+
+func (z maasAvailabilityZone) Name() string {
+	return z.name
+}
+
+func (z maasAvailabilityZone) Available() bool {
+	// MAAS does not report the availability of zones.
+	return true
+}
+
+// AvailabilityZones returns a slice of availability zones
+// known to the MAAS server.
+func (environ *maasEnviron) AvailabilityZones() ([]common.AvailabilityZone, error) {
+	environ.availabilityZonesMutex.Lock()
+	defer environ.availabilityZonesMutex.Unlock()
+	if environ.availabilityZones != nil {
+		return environ.availabilityZones, nil
+	}
+	zonesObject, err := environ.getMAASClient().GetSubObject("zones").CallGet("", nil)
+	if err, ok := err.(*gomaasapi.ServerError); ok && err.StatusCode == http.StatusNotFound {
+		return nil, errors.NotImplementedf("availability zones on this MAAS server")
+	}
+	if err != nil {
+		return nil, err
+	}
+	zoneArray, err := zonesObject.GetArray()
+	if err != nil {
+		return nil, err
+	}
+	zones := make([]common.AvailabilityZone, len(zoneArray))
+	for i, obj := range zoneArray {
+		zoneObject, err := obj.GetMAASObject()
+		if err != nil {
+			return nil, err
+		}
+		name, err := zoneObject.GetField("name")
+		if err != nil {
+			return nil, err
+		}
+		zones[i] = maasAvailabilityZone{name}
+	}
+	environ.availabilityZones = zones
+	return zones, nil
+}
+
+// InstanceAvailabilityZoneNames returns the availability zone names for each
+// of the specified instances.
+func (environ *maasEnviron) InstanceAvailabilityZoneNames(ids []instance.Id) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	instances, err := environ.Instances(ids)
+	if err != nil && err != environs.ErrPartialInstances {
+		return nil, err
+	}
+	zones := make([]string, len(instances))
+	for i, inst := range instances {
+		if inst == nil {
+			continue
+		}
+		zones[i] = inst.(*maasInstance).zone()
+	}
+	return zones, err
+}
+
+// DistributeInstances implements the state.InstanceDistributor policy.
+func (environ *maasEnviron) DistributeInstances(candidates, distributionGroup []instance.Id) ([]instance.Id, error) {
+	eligible, err := common.DistributeInstances(environ, candidates, distributionGroup)
+	if errors.IsNotImplemented(err) {
+		// Without zones, every candidate is as good as any other.
+		return candidates, nil
+	}
+	return eligible, err
+}
+
+var bestAvailabilityZoneAllocations = common.BestAvailabilityZoneAllocations
+
+// maasPlacement holds a parsed placement directive. A directive
+// is either "zone=<name>", or the name of the node to acquire.
+type maasPlacement struct {
+	nodeName string
+	zoneName string
+}
+
+func (environ *maasEnviron) parsePlacement(placement string) (*maasPlacement, error) {
+	pos := strings.IndexRune(placement, '=')
+	if pos == -1 {
+		// If there's no '=' delimiter, assume it's a node name.
+		return &maasPlacement{nodeName: placement}, nil
+	}
+	switch key, value := placement[:pos], placement[pos+1:]; key {
+	case "zone":
+		zones, err := environ.AvailabilityZones()
+		if err != nil {
+			return nil, err
+		}
+		for _, z := range zones {
+			if z.Name() == value {
+				return &maasPlacement{zoneName: value}, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid availability zone %q", value)
+	}
+	return nil, fmt.Errorf("unknown placement directive: %v", placement)
+}
+
+// selectZone returns the name of the availability zone to acquire
+// a node in, spreading the given distribution group across zones.
+// It returns an empty string if the MAAS server has no zones.
+func (environ *maasEnviron) selectZone(distributionGroup func() ([]instance.Id, error)) (string, error) {
+	var group []instance.Id
+	if distributionGroup != nil {
+		var err error
+		group, err = distributionGroup()
+		if err != nil {
+			return "", err
+		}
+	}
+	bestAvailabilityZones, err := bestAvailabilityZoneAllocations(environ, group)
+	if errors.IsNotImplemented(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	var best string
+	for zone := range bestAvailabilityZones {
+		// Choose the same zone each time for the
+		// same allocations, for predictability.
+		if best == "" || zone < best {
+			best = zone
+		}
+	}
+	return best, nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package maas
+
+import (
+	"github.com/juju/errors"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/provider/common"
+)
+
+type availabilityZonesSuite struct {
+	providerSuite
+}
+
+var _ = gc.Suite(&availabilityZonesSuite{})
+
+func (s *availabilityZonesSuite) makeZonedEnviron(zones ...string) *maasEnviron {
+	env := s.makeEnviron()
+	env.availabilityZones = make([]common.AvailabilityZone, len(zones))
+	for i, name := range zones {
+		env.availabilityZones[i] = maasAvailabilityZone{name}
+	}
+	return env
+}
+
+func (s *availabilityZonesSuite) TestInstanceAvailabilityZoneNames(c *gc.C) {
+	env := s.makeEnviron()
+	s.testMAASObject.TestServer.NewNode(`{"system_id": "node0", "hostname": "host0", "zone": {"name": "zone1"}}`)
+	s.testMAASObject.TestServer.NewNode(`{"system_id": "node1", "hostname": "host1"}`)
+	instances, err := env.AllInstances()
+	c.Assert(err, gc.IsNil)
+	c.Assert(instances, gc.HasLen, 2)
+	ids := []instance.Id{instances[0].Id(), instances[1].Id()}
+
+	zones, err := env.InstanceAvailabilityZoneNames(ids)
+	c.Assert(err, gc.IsNil)
+	zoneByHost := make(map[string]string)
+	for i, inst := range instances {
+		hostname, err := inst.(*maasInstance).hostname()
+		c.Assert(err, gc.IsNil)
+		zoneByHost[hostname] = zones[i]
+	}
+	c.Assert(zoneByHost, gc.DeepEquals, map[string]string{"host0": "zone1", "host1": ""})
+
+	zones, err = env.InstanceAvailabilityZoneNames(nil)
+	c.Assert(err, gc.IsNil)
+	c.Assert(zones, gc.HasLen, 0)
+}
+
+func (s *availabilityZonesSuite) TestPrecheckInstancePlacement(c *gc.C) {
+	env := s.makeZonedEnviron("zone1", "zone2")
+	for i, test := range []struct {
+		placement string
+		err       string
+	}{{
+		placement: "zone=zone1",
+	}, {
+		placement: "host0",
+	}, {
+		placement: "zone=zone3",
+		err:       `invalid availability zone "zone3"`,
+	}, {
+		placement: "rack=1",
+		err:       "unknown placement directive: rack=1",
+	}} {
+		c.Logf("test %d: %s", i, test.placement)
+		err := env.PrecheckInstance("precise", constraints.Value{}, test.placement)
+		if test.err == "" {
+			c.Check(err, gc.IsNil)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.err)
+		}
+	}
+}
+
+func (s *availabilityZonesSuite) TestParsePlacement(c *gc.C) {
+	env := s.makeZonedEnviron("zone1")
+	placement, err := env.parsePlacement("zone=zone1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(*placement, gc.Equals, maasPlacement{zoneName: "zone1"})
+	placement, err = env.parsePlacement("host0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(*placement, gc.Equals, maasPlacement{nodeName: "host0"})
+}
+
+func (s *availabilityZonesSuite) TestSelectZone(c *gc.C) {
+	env := s.makeZonedEnviron("zone1", "zone2")
+	group := []instance.Id{"inst0"}
+	s.PatchValue(&bestAvailabilityZoneAllocations, func(e common.ZonedEnviron, g []instance.Id) (map[string][]instance.Id, error) {
+		c.Assert(g, gc.DeepEquals, group)
+		return map[string][]instance.Id{"zone2": nil, "zone1": nil}, nil
+	})
+	zone, err := env.selectZone(func() ([]instance.Id, error) { return group, nil })
+	c.Assert(err, gc.IsNil)
+	c.Assert(zone, gc.Equals, "zone1")
+}
+
+func (s *availabilityZonesSuite) TestSelectZoneWithoutZones(c *gc.C) {
+	env := s.makeEnviron()
+	s.PatchValue(&bestAvailabilityZoneAllocations, func(common.ZonedEnviron, []instance.Id) (map[string][]instance.Id, error) {
+		return nil, errors.NotImplementedf("availability zones on this MAAS server")
+	})
+	zone, err := env.selectZone(nil)
+	c.Assert(err, gc.IsNil)
+	c.Assert(zone, gc.Equals, "")
+}
@@ -0,0 +1,64 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package maas
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/juju/osenv"
+)
+
+var (
+	_ environs.CredentialDetector = maasEnvironProvider{}
+	_ environs.CredentialChecker  = maasEnvironProvider{}
+)
+
+// CredentialAttrs is specified in the environs.CredentialDetector interface.
+func (maasEnvironProvider) CredentialAttrs() []environs.CredentialAttr {
+	return []environs.CredentialAttr{
+		{Name: "maas-server"},
+		{Name: "maas-oauth", Secret: true},
+	}
+}
+
+// oauthKeyFile is the name of the file in the juju home
+// directory from which the MAAS OAuth key is read.
+const oauthKeyFile = "maas-oauth"
+
+// DetectCredentials is specified in the environs.CredentialDetector
+// interface. The MAAS OAuth key, as shown on the MAAS user's
+// preferences page, is read from ~/.juju/maas-oauth.
+func (maasEnvironProvider) DetectCredentials() (map[string]interface{}, error) {
+	attrs := make(map[string]interface{})
+	data, err := ioutil.ReadFile(osenv.JujuHomePath(oauthKeyFile))
+	if os.IsNotExist(err) {
+		return attrs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if key := strings.TrimSpace(string(data)); key != "" {
+		attrs["maas-oauth"] = key
+	}
+	return attrs, nil
+}
+
+// CheckCredentials is specified in the environs.CredentialChecker
+// interface. It lists the nodes allocated to the environment,
+// of which there are none before bootstrap.
+func (maasEnvironProvider) CheckCredentials(cfg *config.Config) error {
+	env, err := NewEnviron(cfg)
+	if err != nil {
+		return err
+	}
+	if _, err := env.instances(nil); err != nil {
+		return fmt.Errorf("cannot list nodes on %q: %v", env.ecfg().maasServer(), err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package maas
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/juju/osenv"
+)
+
+type credentialsSuite struct {
+	providerSuite
+}
+
+var _ = gc.Suite(&credentialsSuite{})
+
+func (suite *credentialsSuite) TestDetectCredentials(c *gc.C) {
+	testJujuHome := c.MkDir()
+	defer osenv.SetJujuHome(osenv.SetJujuHome(testJujuHome))
+
+	attrs, err := providerInstance.DetectCredentials()
+	c.Assert(err, gc.IsNil)
+	c.Check(attrs, gc.HasLen, 0)
+
+	err = ioutil.WriteFile(filepath.Join(testJujuHome, "maas-oauth"), []byte("aa:bb:cc\n"), 0600)
+	c.Assert(err, gc.IsNil)
+	attrs, err = providerInstance.DetectCredentials()
+	c.Assert(err, gc.IsNil)
+	c.Check(attrs, gc.DeepEquals, map[string]interface{}{"maas-oauth": "aa:bb:cc"})
+}
+
+func (suite *credentialsSuite) TestCheckCredentials(c *gc.C) {
+	env := suite.makeEnviron()
+	err := providerInstance.CheckCredentials(env.Config())
+	c.Assert(err, gc.IsNil)
+}
+
+func (suite *credentialsSuite) TestCheckCredentialsRejected(c *gc.C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Authorization Required", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	cfg, err := suite.makeEnviron().Config().Apply(map[string]interface{}{
+		"maas-server": server.URL,
+	})
+	c.Assert(err, gc.IsNil)
+	err = providerInstance.CheckCredentials(cfg)
+	c.Assert(err, gc.ErrorMatches, `cannot list nodes on ".*": .*`)
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/juju/juju/provider/common"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/api"
+	jujustorage "github.com/juju/juju/storage"
 	"github.com/juju/juju/tools"
 )
 
@@ -53,6 +54,7 @@ var shortAttempt = utils.AttemptStrategy{
 
 type maasEnviron struct {
 	common.SupportsUnitPlacementPolicy
+	common.SupportsSourceCIDRsPolicy
 
 	name string
 
@@ -62,6 +64,12 @@ type maasEnviron struct {
 	// for which images can be instantiated.
 	supportedArchitectures []string
 
+	// availabilityZonesMutex gates access to availabilityZones
+	availabilityZonesMutex sync.Mutex
+	// availabilityZones caches the availability zones
+	// known to the MAAS server.
+	availabilityZones []common.AvailabilityZone
+
 	// ecfgMutex protects the *Unlocked fields below.
 	ecfgMutex sync.Mutex
 
@@ -171,8 +179,13 @@ func (env *maasEnviron) SupportNetworks() bool {
 	return caps.Contains(capNetworksManagement)
 }
 
+// PrecheckInstance is defined on the state.Prechecker interface.
 func (env *maasEnviron) PrecheckInstance(series string, cons constraints.Value, placement string) error {
-	// We treat all placement directives as maas-name.
+	if placement != "" {
+		if _, err := env.parsePlacement(placement); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -275,14 +288,19 @@ func addNetworks(params url.Values, includeNetworks, excludeNetworks []string) {
 	}
 }
 
-// acquireNode allocates a node from the MAAS.
-func (environ *maasEnviron) acquireNode(nodeName string, cons constraints.Value, includeNetworks, excludeNetworks []string, possibleTools tools.List) (gomaasapi.MAASObject, *tools.Tools, error) {
+// acquireNode allocates a node from the MAAS, with
+// a disk for each of the given volumes.
+func (environ *maasEnviron) acquireNode(nodeName, zoneName string, cons constraints.Value, includeNetworks, excludeNetworks []string, volumes []jujustorage.VolumeParams, possibleTools tools.List) (gomaasapi.MAASObject, *tools.Tools, error) {
 	acquireParams := convertConstraints(cons)
 	addNetworks(acquireParams, includeNetworks, excludeNetworks)
+	addStorage(acquireParams, volumes)
 	acquireParams.Add("agent_name", environ.ecfg().maasAgentName())
 	if nodeName != "" {
 		acquireParams.Add("name", nodeName)
 	}
+	if zoneName != "" {
+		acquireParams.Add("zone", zoneName)
+	}
 	var result gomaasapi.JSONObject
 	var err error
 	for a := shortAttempt.Start(); a.Next(); {
@@ -422,15 +440,36 @@ func (environ *maasEnviron) StartInstance(args environs.StartInstanceParams) (
 ) {
 	var inst *maasInstance
 	var err error
-	nodeName := args.Placement
+	var nodeName, zoneName string
+	if args.Placement != "" {
+		placement, err := environ.parsePlacement(args.Placement)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		nodeName, zoneName = placement.nodeName, placement.zoneName
+	}
+	// If neither a node nor an availability zone is specified,
+	// then automatically spread across the known zones for optimal
+	// spread across the instance distribution group.
+	if nodeName == "" && zoneName == "" {
+		zoneName, err = environ.selectZone(args.DistributionGroup)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
 	requestedNetworks := args.MachineConfig.Networks
 	includeNetworks := append(args.Constraints.IncludeNetworks(), requestedNetworks...)
 	excludeNetworks := args.Constraints.ExcludeNetworks()
+	if subnet := firstSubnet(args.SubnetsToZones); subnet != "" {
+		includeNetworks = append(includeNetworks, subnet)
+	}
 	node, tools, err := environ.acquireNode(
 		nodeName,
+		zoneName,
 		args.Constraints,
 		includeNetworks,
 		excludeNetworks,
+		args.Volumes,
 		args.Tools)
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("cannot run instances: %v", err)
@@ -481,7 +520,25 @@ func (environ *maasEnviron) StartInstance(args environs.StartInstanceParams) (
 	}
 	logger.Debugf("started instance %q", inst.Id())
 	// TODO(bug 1193998) - return instance hardware characteristics as well
-	return inst, nil, networkInfo, nil
+	var hc *instance.HardwareCharacteristics
+	if zone := inst.zone(); zone != "" {
+		hc = &instance.HardwareCharacteristics{AvailabilityZone: &zone}
+	}
+	return inst, hc, networkInfo, nil
+}
+
+// firstSubnet returns the provider id of the first of the given
+// subnets, in sorted order. MAAS can only be asked for a node connected
+// to all of a set of networks, not to any one of them, so a node that
+// must be in a space is acquired in a single subnet of that space.
+func firstSubnet(subnetsToZones map[network.Id][]string) string {
+	var first string
+	for id := range subnetsToZones {
+		if first == "" || string(id) < first {
+			first = string(id)
+		}
+	}
+	return first
 }
 
 // newCloudinitConfig creates a cloudinit.Config structure
@@ -654,6 +711,47 @@ func (*maasEnviron) ListNetworks() ([]network.BasicInfo, error) {
 	return nil, errors.NotImplementedf("ListNetworks")
 }
 
+// Subnets implements environs.SubnetDiscoverer. MAAS networks are
+// reported as subnets, using the network name as provider id. MAAS
+// has no notion of subnets being tied to availability zones.
+func (environ *maasEnviron) Subnets() ([]network.SubnetInfo, error) {
+	networks, err := environ.getNetworks(nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list subnets: %v", err)
+	}
+	results := make([]network.SubnetInfo, len(networks))
+	for i, netw := range networks {
+		cidr, err := networkCIDR(netw.IP, netw.Mask)
+		if err != nil {
+			return nil, fmt.Errorf("network %q: %v", netw.Name, err)
+		}
+		results[i] = network.SubnetInfo{
+			CIDR:       cidr,
+			ProviderId: network.Id(netw.Name),
+			VLANTag:    netw.VLANTag,
+		}
+	}
+	return results, nil
+}
+
+// networkCIDR returns the canonical CIDR of the network with the
+// given address and netmask, both in dotted decimal notation.
+func networkCIDR(ip, mask string) (string, error) {
+	netIP := net.ParseIP(ip).To4()
+	if netIP == nil {
+		return "", fmt.Errorf("invalid IP address %q", ip)
+	}
+	netMask := net.ParseIP(mask).To4()
+	if netMask == nil {
+		return "", fmt.Errorf("invalid netmask %q", mask)
+	}
+	ipNet := net.IPNet{
+		IP:   netIP.Mask(net.IPMask(netMask)),
+		Mask: net.IPMask(netMask),
+	}
+	return ipNet.String(), nil
+}
+
 // AllInstances returns all the instance.Instance in this provider.
 func (environ *maasEnviron) AllInstances() ([]instance.Instance, error) {
 	return environ.instances(nil)
@@ -671,19 +769,19 @@ func (environ *maasEnviron) Destroy() error {
 }
 
 // MAAS does not do firewalling so these port methods do nothing.
-func (*maasEnviron) OpenPorts([]network.Port) error {
+func (*maasEnviron) OpenPorts([]network.PortRange) error {
 	logger.Debugf("unimplemented OpenPorts() called")
 	return nil
 }
 
-func (*maasEnviron) ClosePorts([]network.Port) error {
+func (*maasEnviron) ClosePorts([]network.PortRange) error {
 	logger.Debugf("unimplemented ClosePorts() called")
 	return nil
 }
 
-func (*maasEnviron) Ports() ([]network.Port, error) {
+func (*maasEnviron) Ports() ([]network.PortRange, error) {
 	logger.Debugf("unimplemented Ports() called")
-	return []network.Port{}, nil
+	return []network.PortRange{}, nil
 }
 
 func (*maasEnviron) Provider() environs.EnvironProvider {
@@ -717,12 +815,17 @@ type networkDetails struct {
 func (environ *maasEnviron) getInstanceNetworks(inst instance.Instance) ([]networkDetails, error) {
 	maasInst := inst.(*maasInstance)
 	maasObj := maasInst.maasObject
-	client := environ.getMAASClient().GetSubObject("networks")
 	nodeId, err := maasObj.GetField("system_id")
 	if err != nil {
 		return nil, err
 	}
-	params := url.Values{"node": {nodeId}}
+	return environ.getNetworks(url.Values{"node": {nodeId}})
+}
+
+// getNetworks returns the MAAS networks matching the given query
+// parameters; all networks when params is nil.
+func (environ *maasEnviron) getNetworks(params url.Values) ([]networkDetails, error) {
+	client := environ.getMAASClient().GetSubObject("networks")
 	json, err := client.CallGet("", params)
 	if err != nil {
 		return nil, err
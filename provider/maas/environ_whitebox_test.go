@@ -32,6 +32,7 @@ import (
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/network"
+	jujustorage "github.com/juju/juju/storage"
 	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/tools"
 	"github.com/juju/juju/version"
@@ -276,7 +277,7 @@ func (suite *environSuite) TestAcquireNode(c *gc.C) {
 	env := suite.makeEnviron()
 	suite.testMAASObject.TestServer.NewNode(`{"system_id": "node0", "hostname": "host0"}`)
 
-	_, _, err := env.acquireNode("", constraints.Value{}, nil, nil, tools.List{fakeTools})
+	_, _, err := env.acquireNode("", "", constraints.Value{}, nil, nil, nil, tools.List{fakeTools})
 
 	c.Check(err, gc.IsNil)
 	operations := suite.testMAASObject.TestServer.NodeOperations()
@@ -296,7 +297,7 @@ func (suite *environSuite) TestAcquireNodeByName(c *gc.C) {
 	env := suite.makeEnviron()
 	suite.testMAASObject.TestServer.NewNode(`{"system_id": "node0", "hostname": "host0"}`)
 
-	_, _, err := env.acquireNode("host0", constraints.Value{}, nil, nil, tools.List{fakeTools})
+	_, _, err := env.acquireNode("host0", "", constraints.Value{}, nil, nil, nil, tools.List{fakeTools})
 
 	c.Check(err, gc.IsNil)
 	operations := suite.testMAASObject.TestServer.NodeOperations()
@@ -310,6 +311,21 @@ func (suite *environSuite) TestAcquireNodeByName(c *gc.C) {
 	c.Assert(nodeName, gc.Equals, "host0")
 }
 
+func (suite *environSuite) TestAcquireNodeInZone(c *gc.C) {
+	stor := NewStorage(suite.makeEnviron())
+	fakeTools := envtesting.MustUploadFakeToolsVersions(stor, version.Current)[0]
+	env := suite.makeEnviron()
+	suite.testMAASObject.TestServer.NewNode(`{"system_id": "node0", "hostname": "host0"}`)
+
+	_, _, err := env.acquireNode("", "zone1", constraints.Value{}, nil, nil, nil, tools.List{fakeTools})
+
+	c.Check(err, gc.IsNil)
+	values := suite.testMAASObject.TestServer.NodeOperationRequestValues()["node0"][0]
+	c.Assert(values.Get("zone"), gc.Equals, "zone1")
+	_, found := values["name"]
+	c.Assert(found, jc.IsFalse)
+}
+
 func (suite *environSuite) TestAcquireNodeTakesConstraintsIntoAccount(c *gc.C) {
 	stor := NewStorage(suite.makeEnviron())
 	fakeTools := envtesting.MustUploadFakeToolsVersions(stor, version.Current)[0]
@@ -317,7 +333,7 @@ func (suite *environSuite) TestAcquireNodeTakesConstraintsIntoAccount(c *gc.C) {
 	suite.testMAASObject.TestServer.NewNode(`{"system_id": "node0", "hostname": "host0"}`)
 	constraints := constraints.Value{Arch: stringp("arm"), Mem: uint64p(1024)}
 
-	_, _, err := env.acquireNode("", constraints, nil, nil, tools.List{fakeTools})
+	_, _, err := env.acquireNode("", "", constraints, nil, nil, nil, tools.List{fakeTools})
 
 	c.Check(err, gc.IsNil)
 	requestValues := suite.testMAASObject.TestServer.NodeOperationRequestValues()
@@ -333,7 +349,7 @@ func (suite *environSuite) TestAcquireNodePassedAgentName(c *gc.C) {
 	env := suite.makeEnviron()
 	suite.testMAASObject.TestServer.NewNode(`{"system_id": "node0", "hostname": "host0"}`)
 
-	_, _, err := env.acquireNode("", constraints.Value{}, nil, nil, tools.List{fakeTools})
+	_, _, err := env.acquireNode("", "", constraints.Value{}, nil, nil, nil, tools.List{fakeTools})
 
 	c.Check(err, gc.IsNil)
 	requestValues := suite.testMAASObject.TestServer.NodeOperationRequestValues()
@@ -342,6 +358,25 @@ func (suite *environSuite) TestAcquireNodePassedAgentName(c *gc.C) {
 	c.Assert(nodeRequestValues[0].Get("agent_name"), gc.Equals, exampleAgentName)
 }
 
+func (suite *environSuite) TestAcquireNodeStorage(c *gc.C) {
+	stor := NewStorage(suite.makeEnviron())
+	fakeTools := envtesting.MustUploadFakeToolsVersions(stor, version.Current)[0]
+	env := suite.makeEnviron()
+	suite.testMAASObject.TestServer.NewNode(`{"system_id": "node0", "hostname": "host0"}`)
+	volumes := []jujustorage.VolumeParams{
+		{Name: "0", Size: 1024},
+		{Name: "1", Size: 10240},
+	}
+
+	_, _, err := env.acquireNode("", "", constraints.Value{}, nil, nil, volumes, tools.List{fakeTools})
+
+	c.Check(err, gc.IsNil)
+	requestValues := suite.testMAASObject.TestServer.NodeOperationRequestValues()
+	nodeRequestValues, found := requestValues["node0"]
+	c.Assert(found, gc.Equals, true)
+	c.Assert(nodeRequestValues[0].Get("storage"), gc.Equals, "root:0,volume-0:2,volume-1:11")
+}
+
 var testValues = []struct {
 	constraints    constraints.Value
 	expectedResult url.Values
@@ -666,6 +701,30 @@ func (suite *environSuite) TestGetInstanceNetworks(c *gc.C) {
 	})
 }
 
+func (*environSuite) TestNetworkCIDR(c *gc.C) {
+	cidr, err := networkCIDR("192.168.123.1", "255.255.255.0")
+	c.Assert(err, gc.IsNil)
+	c.Check(cidr, gc.Equals, "192.168.123.0/24")
+
+	cidr, err = networkCIDR("10.1.2.3", "255.255.0.0")
+	c.Assert(err, gc.IsNil)
+	c.Check(cidr, gc.Equals, "10.1.0.0/16")
+
+	_, err = networkCIDR("invalid", "255.255.255.0")
+	c.Check(err, gc.ErrorMatches, `invalid IP address "invalid"`)
+	_, err = networkCIDR("10.1.2.3", "")
+	c.Check(err, gc.ErrorMatches, `invalid netmask ""`)
+}
+
+func (*environSuite) TestFirstSubnet(c *gc.C) {
+	c.Check(firstSubnet(nil), gc.Equals, "")
+	c.Check(firstSubnet(map[network.Id][]string{
+		"net-b": nil,
+		"net-a": nil,
+		"net-c": nil,
+	}), gc.Equals, "net-a")
+}
+
 // A typical lshw XML dump with lots of things left out.
 const lshwXMLTestExtractInterfaces = `
 <?xml version="1.0" standalone="yes" ?>
@@ -116,18 +116,36 @@ func (mi *maasInstance) hostname() (string, error) {
 	return mi.getMaasObject().GetField("hostname")
 }
 
+// zone returns the name of the availability zone the instance
+// is in, or an empty string if the MAAS server has no zones.
+func (mi *maasInstance) zone() string {
+	obj, ok := mi.getMaasObject().GetMap()["zone"]
+	if !ok {
+		return ""
+	}
+	zone, err := obj.GetMap()
+	if err != nil {
+		return ""
+	}
+	name, err := zone["name"].GetString()
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
 // MAAS does not do firewalling so these port methods do nothing.
-func (mi *maasInstance) OpenPorts(machineId string, ports []network.Port) error {
+func (mi *maasInstance) OpenPorts(machineId string, ports []network.PortRange) error {
 	logger.Debugf("unimplemented OpenPorts() called")
 	return nil
 }
 
-func (mi *maasInstance) ClosePorts(machineId string, ports []network.Port) error {
+func (mi *maasInstance) ClosePorts(machineId string, ports []network.PortRange) error {
 	logger.Debugf("unimplemented ClosePorts() called")
 	return nil
 }
 
-func (mi *maasInstance) Ports(machineId string) ([]network.Port, error) {
+func (mi *maasInstance) Ports(machineId string) ([]network.PortRange, error) {
 	logger.Debugf("unimplemented Ports() called")
-	return []network.Port{}, nil
+	return []network.PortRange{}, nil
 }
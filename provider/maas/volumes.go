@@ -0,0 +1,203 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package maas
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+	"launchpad.net/gomaasapi"
+
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/storage"
+)
+
+// MAASProviderType is the type of the storage provider
+// that uses disks of nodes allocated from MAAS.
+const MAASProviderType storage.ProviderType = "maas"
+
+func init() {
+	storage.RegisterProvider(MAASProviderType, maasStorageProvider{})
+	storage.RegisterDefaultProvider("maas", MAASProviderType)
+}
+
+// maasStorageProvider provides the disks of a node as volumes. Nodes
+// are acquired with disks large enough for the requested volumes, so
+// volumes cannot be created or destroyed once a node has been
+// acquired.
+type maasStorageProvider struct{}
+
+// VolumeSource is defined on the storage.Provider interface.
+func (maasStorageProvider) VolumeSource(environConfig *config.Config, storageDir string) (storage.VolumeSource, error) {
+	env, err := NewEnviron(environConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &maasVolumeSource{env}, nil
+}
+
+// Scope is defined on the storage.Provider interface.
+func (maasStorageProvider) Scope() storage.Scope {
+	return storage.ScopeEnviron
+}
+
+// Dynamic is defined on the storage.Provider interface.
+func (maasStorageProvider) Dynamic() bool {
+	return false
+}
+
+// addStorage converts the parameters of volumes into a storage
+// constraint, suitable to pass to MAAS when acquiring a node, that
+// requires a disk for each volume. The first disk of the constraint
+// is the node's root disk, which is not used for volumes.
+func addStorage(params url.Values, volumes []storage.VolumeParams) {
+	if len(volumes) == 0 {
+		return
+	}
+	disks := []string{"root:0"}
+	for _, v := range volumes {
+		disks = append(disks, fmt.Sprintf("volume-%s:%d", v.Name, maasDiskSize(v.Size)))
+	}
+	params.Add("storage", strings.Join(disks, ","))
+}
+
+// maasDiskSize returns the size in GB, as understood by MAAS,
+// of a disk that holds at least the given size in MiB.
+func maasDiskSize(size uint64) uint64 {
+	const gb = 1000 * 1000 * 1000
+	return (size*1024*1024 + gb - 1) / gb
+}
+
+type maasVolumeSource struct {
+	env *maasEnviron
+}
+
+var _ storage.VolumeSource = (*maasVolumeSource)(nil)
+
+// CreateVolumes is defined on the storage.VolumeSource interface.
+// The disks of a node are allocated when the node is acquired, so
+// CreateVolumes only describes the disks of the nodes the volumes
+// are attached to. Each volume is given the smallest unused disk,
+// other than the node's first disk, that is large enough to hold it.
+func (s *maasVolumeSource) CreateVolumes(params []storage.VolumeParams) ([]storage.Volume, []storage.VolumeAttachment, error) {
+	var volumes []storage.Volume
+	var attachments []storage.VolumeAttachment
+	disksByInstance := make(map[instance.Id][]maasDisk)
+	for _, p := range params {
+		if p.Attachment == nil || p.Attachment.InstanceId == "" {
+			return nil, nil, fmt.Errorf("MAAS volume %q must be created along with a node", p.Name)
+		}
+		instId := p.Attachment.InstanceId
+		disks, ok := disksByInstance[instId]
+		if !ok {
+			var err error
+			if disks, err = s.nodeDisks(instId); err != nil {
+				return nil, nil, err
+			}
+		}
+		i := sort.Search(len(disks), func(i int) bool {
+			return disks[i].size >= p.Size
+		})
+		if i == len(disks) {
+			return nil, nil, fmt.Errorf("node %q has no unused disk for volume %q", instId, p.Name)
+		}
+		disk := disks[i]
+		disksByInstance[instId] = append(disks[:i], disks[i+1:]...)
+		volumes = append(volumes, storage.Volume{
+			Name:     p.Name,
+			VolumeId: disk.id,
+			Size:     disk.size,
+		})
+		attachments = append(attachments, storage.VolumeAttachment{
+			Volume:     p.Name,
+			Machine:    p.Attachment.Machine,
+			DeviceName: disk.name,
+		})
+	}
+	return volumes, attachments, nil
+}
+
+// DestroyVolumes is defined on the storage.VolumeSource interface.
+// The disks of a node remain allocated until the node is released.
+func (s *maasVolumeSource) DestroyVolumes(volumeIds []string) error {
+	return errors.NotSupportedf("destroying MAAS disks before their node is released")
+}
+
+// maasDisk describes a physical disk of a node.
+type maasDisk struct {
+	id   string
+	name string
+	// size is the size of the disk in MiB.
+	size uint64
+}
+
+// nodeDisks returns the disks of the node with the given instance id
+// that may be used for volumes, ordered by size. The node's first
+// disk, which holds its root filesystem, is omitted.
+func (s *maasVolumeSource) nodeDisks(instId instance.Id) ([]maasDisk, error) {
+	instances, err := s.env.Instances([]instance.Id{instId})
+	if err != nil {
+		return nil, err
+	}
+	node := instances[0].(*maasInstance).getMaasObject()
+	devicesObj, ok := node.GetMap()["physicalblockdevice_set"]
+	if !ok || devicesObj.IsNil() {
+		return nil, fmt.Errorf("node %q does not describe its disks; MAAS 1.8 or later is required for storage", instId)
+	}
+	devices, err := devicesObj.GetArray()
+	if err != nil {
+		return nil, err
+	}
+	var disks []maasDisk
+	for i, deviceObj := range devices {
+		if i == 0 {
+			continue
+		}
+		disk, err := parseDisk(deviceObj)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse disk of node %q: %v", instId, err)
+		}
+		disks = append(disks, disk)
+	}
+	sort.Sort(bySize(disks))
+	return disks, nil
+}
+
+func parseDisk(obj gomaasapi.JSONObject) (maasDisk, error) {
+	device, err := obj.GetMap()
+	if err != nil {
+		return maasDisk{}, err
+	}
+	name, err := device["name"].GetString()
+	if err != nil {
+		return maasDisk{}, err
+	}
+	size, err := device["size"].GetFloat64()
+	if err != nil {
+		return maasDisk{}, err
+	}
+	// The id path identifies the disk uniquely, but
+	// not all disks have one.
+	id := name
+	if idPath, ok := device["id_path"]; ok && !idPath.IsNil() {
+		if id, err = idPath.GetString(); err != nil {
+			return maasDisk{}, err
+		}
+	}
+	return maasDisk{
+		id:   id,
+		name: name,
+		size: uint64(size) / (1024 * 1024),
+	}, nil
+}
+
+type bySize []maasDisk
+
+func (b bySize) Len() int           { return len(b) }
+func (b bySize) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b bySize) Less(i, j int) bool { return b[i].size < b[j].size }
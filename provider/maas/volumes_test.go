@@ -0,0 +1,123 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package maas
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/storage"
+)
+
+type volumeSuite struct {
+	providerSuite
+}
+
+var _ = gc.Suite(&volumeSuite{})
+
+// nodeWithDisks describes a node with a 20GB root disk and
+// three further disks, the first of which has no id path.
+const nodeWithDisks = `{
+	"system_id": "node0",
+	"hostname": "host0",
+	"physicalblockdevice_set": [
+		{"name": "sda", "size": 20000000000, "id_path": "/dev/disk/by-id/root"},
+		{"name": "sdb", "size": 50000000000},
+		{"name": "sdc", "size": 2000000000, "id_path": "/dev/disk/by-id/small"},
+		{"name": "sdd", "size": 10000000000, "id_path": "/dev/disk/by-id/medium"}
+	]
+}`
+
+func (s *volumeSuite) addNode(jsonText string) instance.Id {
+	node := s.testMAASObject.TestServer.NewNode(jsonText)
+	resourceURI, _ := node.GetField("resource_uri")
+	return instance.Id(resourceURI)
+}
+
+func (s *volumeSuite) volumeSource(c *gc.C) storage.VolumeSource {
+	p, err := storage.StorageProvider(MAASProviderType)
+	c.Assert(err, gc.IsNil)
+	source, err := p.VolumeSource(s.makeEnviron().Config(), "")
+	c.Assert(err, gc.IsNil)
+	return source
+}
+
+func volumeParams(name string, size uint64, instId instance.Id) storage.VolumeParams {
+	return storage.VolumeParams{
+		Name:     name,
+		Size:     size,
+		Provider: MAASProviderType,
+		Attachment: &storage.AttachmentParams{
+			Machine:    "1",
+			InstanceId: instId,
+		},
+	}
+}
+
+func (s *volumeSuite) TestProvider(c *gc.C) {
+	p, err := storage.StorageProvider(MAASProviderType)
+	c.Assert(err, gc.IsNil)
+	c.Assert(p.Scope(), gc.Equals, storage.ScopeEnviron)
+	c.Assert(p.Dynamic(), jc.IsFalse)
+	providerType, ok := storage.DefaultProvider("maas")
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(providerType, gc.Equals, MAASProviderType)
+}
+
+func (s *volumeSuite) TestMAASDiskSize(c *gc.C) {
+	c.Assert(maasDiskSize(0), gc.Equals, uint64(0))
+	c.Assert(maasDiskSize(1), gc.Equals, uint64(1))
+	c.Assert(maasDiskSize(953), gc.Equals, uint64(1))
+	c.Assert(maasDiskSize(954), gc.Equals, uint64(2))
+}
+
+func (s *volumeSuite) TestCreateVolumes(c *gc.C) {
+	instId := s.addNode(nodeWithDisks)
+	volumes, attachments, err := s.volumeSource(c).CreateVolumes([]storage.VolumeParams{
+		volumeParams("0", 5000, instId),
+		volumeParams("1", 1000, instId),
+		volumeParams("2", 5000, instId),
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(volumes, gc.DeepEquals, []storage.Volume{
+		{Name: "0", VolumeId: "/dev/disk/by-id/medium", Size: 9536},
+		{Name: "1", VolumeId: "/dev/disk/by-id/small", Size: 1907},
+		{Name: "2", VolumeId: "sdb", Size: 47683},
+	})
+	c.Assert(attachments, gc.DeepEquals, []storage.VolumeAttachment{
+		{Volume: "0", Machine: "1", DeviceName: "sdd"},
+		{Volume: "1", Machine: "1", DeviceName: "sdc"},
+		{Volume: "2", Machine: "1", DeviceName: "sdb"},
+	})
+}
+
+func (s *volumeSuite) TestCreateVolumesNoUnusedDisk(c *gc.C) {
+	instId := s.addNode(nodeWithDisks)
+	_, _, err := s.volumeSource(c).CreateVolumes([]storage.VolumeParams{
+		volumeParams("0", 100000, instId),
+	})
+	c.Assert(err, gc.ErrorMatches, `node ".*" has no unused disk for volume "0"`)
+}
+
+func (s *volumeSuite) TestCreateVolumesNodeWithoutDisks(c *gc.C) {
+	instId := s.addNode(`{"system_id": "node0", "hostname": "host0"}`)
+	_, _, err := s.volumeSource(c).CreateVolumes([]storage.VolumeParams{
+		volumeParams("0", 1000, instId),
+	})
+	c.Assert(err, gc.ErrorMatches, `node ".*" does not describe its disks; MAAS 1.8 or later is required for storage`)
+}
+
+func (s *volumeSuite) TestCreateVolumesWithoutInstance(c *gc.C) {
+	_, _, err := s.volumeSource(c).CreateVolumes([]storage.VolumeParams{
+		volumeParams("0", 1000, ""),
+	})
+	c.Assert(err, gc.ErrorMatches, `MAAS volume "0" must be created along with a node`)
+}
+
+func (s *volumeSuite) TestDestroyVolumes(c *gc.C) {
+	err := s.volumeSource(c).DestroyVolumes([]string{"sdb"})
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
@@ -52,6 +52,7 @@ var logger = loggo.GetLogger("juju.provider.manual")
 
 type manualEnviron struct {
 	common.SupportsUnitPlacementPolicy
+	common.SupportsSourceCIDRsPolicy
 
 	cfg                 *environConfig
 	cfgmutex            sync.Mutex
@@ -294,16 +295,16 @@ func (e *manualEnviron) ConstraintsValidator() (constraints.Validator, error) {
 	return validator, nil
 }
 
-func (e *manualEnviron) OpenPorts(ports []network.Port) error {
+func (e *manualEnviron) OpenPorts(ports []network.PortRange) error {
 	return nil
 }
 
-func (e *manualEnviron) ClosePorts(ports []network.Port) error {
+func (e *manualEnviron) ClosePorts(ports []network.PortRange) error {
 	return nil
 }
 
-func (e *manualEnviron) Ports() ([]network.Port, error) {
-	return []network.Port{}, nil
+func (e *manualEnviron) Ports() ([]network.PortRange, error) {
+	return []network.PortRange{}, nil
 }
 
 func (*manualEnviron) Provider() environs.EnvironProvider {
@@ -0,0 +1,273 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package openstack
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+	"launchpad.net/goose/client"
+	gooseerrors "launchpad.net/goose/errors"
+	goosehttp "launchpad.net/goose/http"
+
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/storage"
+)
+
+// CinderProviderType is the type of the storage provider
+// that creates OpenStack Cinder volumes.
+const CinderProviderType storage.ProviderType = "cinder"
+
+func init() {
+	storage.RegisterProvider(CinderProviderType, cinderProvider{})
+	storage.RegisterDefaultProvider("openstack", CinderProviderType)
+}
+
+// volumeAttempt is used to poll for changes to the status of
+// Cinder volumes, which may take a while to be created.
+var volumeAttempt = utils.AttemptStrategy{
+	Total: 5 * time.Minute,
+	Delay: 2 * time.Second,
+}
+
+// cinderProvider creates Cinder volumes and attaches them to running
+// instances through the Nova volume attachments extension.
+type cinderProvider struct{}
+
+// VolumeSource is defined on the storage.Provider interface.
+func (cinderProvider) VolumeSource(environConfig *config.Config, storageDir string) (storage.VolumeSource, error) {
+	env, err := providerInstance.Open(environConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &cinderVolumeSource{env.(*environ).client}, nil
+}
+
+// Scope is defined on the storage.Provider interface.
+func (cinderProvider) Scope() storage.Scope {
+	return storage.ScopeEnviron
+}
+
+// Dynamic is defined on the storage.Provider interface.
+func (cinderProvider) Dynamic() bool {
+	return true
+}
+
+// requestSender sends requests to OpenStack services. It is
+// implemented by the goose client, which does not yet support
+// the block storage API.
+type requestSender interface {
+	SendRequest(method, svcType, apiCall string, requestData *goosehttp.RequestData) error
+}
+
+// cinderVolume is the block storage API's representation of a volume.
+type cinderVolume struct {
+	Id          string             `json:"id,omitempty"`
+	DisplayName string             `json:"display_name,omitempty"`
+	Size        int                `json:"size"`
+	Status      string             `json:"status,omitempty"`
+	Attachments []cinderAttachment `json:"attachments,omitempty"`
+}
+
+// cinderAttachment is the block storage API's representation
+// of the attachment of a volume to a server.
+type cinderAttachment struct {
+	ServerId string `json:"server_id"`
+}
+
+// novaVolumeAttachment is the compute API's representation
+// of the attachment of a volume to a server.
+type novaVolumeAttachment struct {
+	VolumeId string `json:"volumeId"`
+	ServerId string `json:"serverId,omitempty"`
+	Device   string `json:"device,omitempty"`
+}
+
+type cinderVolumeSource struct {
+	client requestSender
+}
+
+var _ storage.VolumeSource = (*cinderVolumeSource)(nil)
+
+// CreateVolumes is defined on the storage.VolumeSource interface.
+// Each volume is created, and attached to the instance of the machine
+// it is to be attached to once it is available. If any volume cannot
+// be created or attached, the volumes already created are destroyed.
+func (s *cinderVolumeSource) CreateVolumes(params []storage.VolumeParams) (_ []storage.Volume, _ []storage.VolumeAttachment, err error) {
+	for _, p := range params {
+		if p.Attachment == nil || p.Attachment.InstanceId == "" {
+			return nil, nil, fmt.Errorf("cinder volume %q must be attached to an instance", p.Name)
+		}
+	}
+	var volumes []storage.Volume
+	var attachments []storage.VolumeAttachment
+	defer func() {
+		if err == nil || len(volumes) == 0 {
+			return
+		}
+		volumeIds := make([]string, len(volumes))
+		for i, volume := range volumes {
+			volumeIds[i] = volume.VolumeId
+		}
+		if err := s.DestroyVolumes(volumeIds); err != nil {
+			logger.Errorf("cannot destroy volumes %v: %v", volumeIds, err)
+		}
+	}()
+	for _, p := range params {
+		volume, err := s.createVolume(p)
+		if err != nil {
+			return nil, nil, errors.Annotatef(err, "cannot create volume %q", p.Name)
+		}
+		volumes = append(volumes, volume)
+		deviceName, err := s.attachVolume(volume.VolumeId, p.Attachment.InstanceId)
+		if err != nil {
+			return nil, nil, errors.Annotatef(err, "cannot attach volume %q", p.Name)
+		}
+		attachments = append(attachments, storage.VolumeAttachment{
+			Volume:     p.Name,
+			Machine:    p.Attachment.Machine,
+			DeviceName: deviceName,
+		})
+	}
+	return volumes, attachments, nil
+}
+
+// createVolume creates a volume with the given parameters, and
+// waits for it to become available.
+func (s *cinderVolumeSource) createVolume(p storage.VolumeParams) (storage.Volume, error) {
+	var req, resp struct {
+		Volume cinderVolume `json:"volume"`
+	}
+	req.Volume = cinderVolume{
+		DisplayName: "juju-volume-" + p.Name,
+		Size:        cinderVolumeSize(p.Size),
+	}
+	err := s.client.SendRequest(client.POST, "volume", "volumes", &goosehttp.RequestData{
+		ReqValue:       &req,
+		RespValue:      &resp,
+		ExpectedStatus: []int{http.StatusOK, http.StatusAccepted},
+	})
+	if err != nil {
+		return storage.Volume{}, err
+	}
+	volume, err := s.waitVolumeStatus(resp.Volume.Id, "available")
+	if err != nil {
+		return storage.Volume{}, err
+	}
+	return storage.Volume{
+		Name:     p.Name,
+		VolumeId: volume.Id,
+		Size:     uint64(volume.Size * 1024),
+	}, nil
+}
+
+// attachVolume attaches the volume with the given id to the
+// instance, and returns the name of the block device it is
+// attached as.
+func (s *cinderVolumeSource) attachVolume(volumeId string, instId instance.Id) (string, error) {
+	var req, resp struct {
+		VolumeAttachment novaVolumeAttachment `json:"volumeAttachment"`
+	}
+	req.VolumeAttachment = novaVolumeAttachment{VolumeId: volumeId}
+	apiCall := fmt.Sprintf("servers/%s/os-volume_attachments", instId)
+	err := s.client.SendRequest(client.POST, "compute", apiCall, &goosehttp.RequestData{
+		ReqValue:       &req,
+		RespValue:      &resp,
+		ExpectedStatus: []int{http.StatusOK},
+	})
+	if err != nil {
+		return "", err
+	}
+	if resp.VolumeAttachment.Device == "" {
+		return "", fmt.Errorf("no device name reported for volume %q", volumeId)
+	}
+	return strings.TrimPrefix(resp.VolumeAttachment.Device, "/dev/"), nil
+}
+
+// DestroyVolumes is defined on the storage.VolumeSource interface.
+// Volumes are detached from any servers they are attached to, and
+// deleted once they are available. Volumes that have already been
+// deleted are ignored.
+func (s *cinderVolumeSource) DestroyVolumes(volumeIds []string) error {
+	for _, volumeId := range volumeIds {
+		if err := s.destroyVolume(volumeId); err != nil {
+			return errors.Annotatef(err, "cannot destroy volume %q", volumeId)
+		}
+	}
+	return nil
+}
+
+func (s *cinderVolumeSource) destroyVolume(volumeId string) error {
+	volume, err := s.volume(volumeId)
+	if gooseerrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if len(volume.Attachments) > 0 {
+		for _, attachment := range volume.Attachments {
+			apiCall := fmt.Sprintf("servers/%s/os-volume_attachments/%s", attachment.ServerId, volumeId)
+			err := s.client.SendRequest(client.DELETE, "compute", apiCall, &goosehttp.RequestData{
+				ExpectedStatus: []int{http.StatusAccepted},
+			})
+			if err != nil && !gooseerrors.IsNotFound(err) {
+				return err
+			}
+		}
+		if _, err := s.waitVolumeStatus(volumeId, "available"); gooseerrors.IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	err = s.client.SendRequest(client.DELETE, "volume", "volumes/"+volumeId, &goosehttp.RequestData{
+		ExpectedStatus: []int{http.StatusAccepted},
+	})
+	if err != nil && !gooseerrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// volume returns the volume with the given id.
+func (s *cinderVolumeSource) volume(volumeId string) (cinderVolume, error) {
+	var resp struct {
+		Volume cinderVolume `json:"volume"`
+	}
+	err := s.client.SendRequest(client.GET, "volume", "volumes/"+volumeId, &goosehttp.RequestData{
+		RespValue: &resp,
+	})
+	return resp.Volume, err
+}
+
+// waitVolumeStatus waits for the volume with the given id to have
+// the given status, and returns its details.
+func (s *cinderVolumeSource) waitVolumeStatus(volumeId, status string) (cinderVolume, error) {
+	var volume cinderVolume
+	var err error
+	for a := volumeAttempt.Start(); a.Next(); {
+		volume, err = s.volume(volumeId)
+		if err != nil {
+			return cinderVolume{}, err
+		}
+		if volume.Status == status {
+			return volume, nil
+		}
+		if volume.Status == "error" {
+			return cinderVolume{}, fmt.Errorf("volume %q has status %q", volumeId, volume.Status)
+		}
+	}
+	return cinderVolume{}, fmt.Errorf("timed out waiting for volume %q to become %s: status is %q", volumeId, status, volume.Status)
+}
+
+// cinderVolumeSize returns the size in GiB of a Cinder
+// volume that holds at least the given size in MiB.
+func cinderVolumeSize(size uint64) int {
+	return int((size + 1023) / 1024)
+}
@@ -0,0 +1,194 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package openstack
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	gc "launchpad.net/gocheck"
+	gooseerrors "launchpad.net/goose/errors"
+	goosehttp "launchpad.net/goose/http"
+
+	"github.com/juju/juju/storage"
+	"github.com/juju/juju/testing"
+)
+
+type cinderSuite struct {
+	testing.BaseSuite
+	sender *fakeSender
+	source *cinderVolumeSource
+}
+
+var _ = gc.Suite(&cinderSuite{})
+
+func (s *cinderSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.PatchValue(&volumeAttempt, utils.AttemptStrategy{
+		Total: 100 * time.Millisecond,
+		Delay: time.Millisecond,
+	})
+	s.sender = &fakeSender{volumes: make(map[string]*cinderVolume)}
+	s.source = &cinderVolumeSource{s.sender}
+}
+
+// fakeSender records the requests sent to it, and
+// simulates the block storage and compute APIs.
+type fakeSender struct {
+	calls     []string
+	volumes   map[string]*cinderVolume
+	attachErr error
+}
+
+func (f *fakeSender) SendRequest(method, svcType, apiCall string, requestData *goosehttp.RequestData) error {
+	f.calls = append(f.calls, fmt.Sprintf("%s %s %s", method, svcType, apiCall))
+	parts := strings.Split(apiCall, "/")
+	switch {
+	case method == "POST" && apiCall == "volumes":
+		req := requestData.ReqValue.(*struct {
+			Volume cinderVolume `json:"volume"`
+		})
+		resp := requestData.RespValue.(*struct {
+			Volume cinderVolume `json:"volume"`
+		})
+		volume := req.Volume
+		volume.Id = fmt.Sprintf("vol-%d", len(f.volumes))
+		volume.Status = "available"
+		f.volumes[volume.Id] = &volume
+		resp.Volume = cinderVolume{Id: volume.Id, Size: volume.Size, Status: "creating"}
+	case method == "GET" && parts[0] == "volumes":
+		volume, ok := f.volumes[parts[1]]
+		if !ok {
+			return gooseerrors.NewNotFoundf(nil, "", "volume %q", parts[1])
+		}
+		resp := requestData.RespValue.(*struct {
+			Volume cinderVolume `json:"volume"`
+		})
+		resp.Volume = *volume
+	case method == "DELETE" && parts[0] == "volumes":
+		if _, ok := f.volumes[parts[1]]; !ok {
+			return gooseerrors.NewNotFoundf(nil, "", "volume %q", parts[1])
+		}
+		delete(f.volumes, parts[1])
+	case method == "POST" && len(parts) == 3:
+		if f.attachErr != nil {
+			return f.attachErr
+		}
+		req := requestData.ReqValue.(*struct {
+			VolumeAttachment novaVolumeAttachment `json:"volumeAttachment"`
+		})
+		resp := requestData.RespValue.(*struct {
+			VolumeAttachment novaVolumeAttachment `json:"volumeAttachment"`
+		})
+		volume := f.volumes[req.VolumeAttachment.VolumeId]
+		volume.Status = "in-use"
+		volume.Attachments = []cinderAttachment{{ServerId: parts[1]}}
+		resp.VolumeAttachment = novaVolumeAttachment{
+			VolumeId: volume.Id,
+			ServerId: parts[1],
+			Device:   fmt.Sprintf("/dev/vd%c", 'b'+len(f.volumes)-1),
+		}
+	case method == "DELETE" && len(parts) == 4:
+		volume := f.volumes[parts[3]]
+		volume.Status = "available"
+		volume.Attachments = nil
+	default:
+		return fmt.Errorf("unexpected request %s %s %s", method, svcType, apiCall)
+	}
+	return nil
+}
+
+func cinderVolumes(n int) []storage.VolumeParams {
+	var params []storage.VolumeParams
+	for i := 0; i < n; i++ {
+		params = append(params, storage.VolumeParams{
+			Name:     fmt.Sprint(i),
+			Size:     1500,
+			Provider: CinderProviderType,
+			Attachment: &storage.AttachmentParams{
+				Machine:    "1",
+				InstanceId: "inst-1",
+			},
+		})
+	}
+	return params
+}
+
+func (s *cinderSuite) TestProvider(c *gc.C) {
+	p, err := storage.StorageProvider(CinderProviderType)
+	c.Assert(err, gc.IsNil)
+	c.Assert(p.Scope(), gc.Equals, storage.ScopeEnviron)
+	c.Assert(p.Dynamic(), jc.IsTrue)
+	providerType, ok := storage.DefaultProvider("openstack")
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(providerType, gc.Equals, CinderProviderType)
+}
+
+func (s *cinderSuite) TestCreateVolumes(c *gc.C) {
+	volumes, attachments, err := s.source.CreateVolumes(cinderVolumes(2))
+	c.Assert(err, gc.IsNil)
+	c.Assert(volumes, gc.DeepEquals, []storage.Volume{
+		{Name: "0", VolumeId: "vol-0", Size: 2048},
+		{Name: "1", VolumeId: "vol-1", Size: 2048},
+	})
+	c.Assert(attachments, gc.DeepEquals, []storage.VolumeAttachment{
+		{Volume: "0", Machine: "1", DeviceName: "vdb"},
+		{Volume: "1", Machine: "1", DeviceName: "vdc"},
+	})
+	c.Assert(s.sender.volumes["vol-0"].DisplayName, gc.Equals, "juju-volume-0")
+	c.Assert(s.sender.volumes["vol-0"].Size, gc.Equals, 2)
+	c.Assert(s.sender.calls, gc.DeepEquals, []string{
+		"POST volume volumes",
+		"GET volume volumes/vol-0",
+		"POST compute servers/inst-1/os-volume_attachments",
+		"POST volume volumes",
+		"GET volume volumes/vol-1",
+		"POST compute servers/inst-1/os-volume_attachments",
+	})
+}
+
+func (s *cinderSuite) TestCreateVolumesWithoutInstance(c *gc.C) {
+	params := cinderVolumes(1)
+	params[0].Attachment.InstanceId = ""
+	_, _, err := s.source.CreateVolumes(params)
+	c.Assert(err, gc.ErrorMatches, `cinder volume "0" must be attached to an instance`)
+	c.Assert(s.sender.calls, gc.HasLen, 0)
+}
+
+func (s *cinderSuite) TestCreateVolumesDestroysVolumesOnError(c *gc.C) {
+	s.sender.attachErr = fmt.Errorf("no more devices")
+	_, _, err := s.source.CreateVolumes(cinderVolumes(2))
+	c.Assert(err, gc.ErrorMatches, `cannot attach volume "0": no more devices`)
+	c.Assert(s.sender.volumes, gc.HasLen, 0)
+}
+
+func (s *cinderSuite) TestDestroyVolumes(c *gc.C) {
+	_, _, err := s.source.CreateVolumes(cinderVolumes(1))
+	c.Assert(err, gc.IsNil)
+	s.sender.calls = nil
+
+	err = s.source.DestroyVolumes([]string{"vol-0", "vol-42"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.sender.volumes, gc.HasLen, 0)
+	c.Assert(s.sender.calls, gc.DeepEquals, []string{
+		"GET volume volumes/vol-0",
+		"DELETE compute servers/inst-1/os-volume_attachments/vol-0",
+		"GET volume volumes/vol-0",
+		"DELETE volume volumes/vol-0",
+		"GET volume volumes/vol-42",
+	})
+}
+
+func (s *cinderSuite) TestWaitVolumeStatusError(c *gc.C) {
+	s.sender.volumes["vol-0"] = &cinderVolume{Id: "vol-0", Status: "error"}
+	_, err := s.source.waitVolumeStatus("vol-0", "available")
+	c.Assert(err, gc.ErrorMatches, `volume "vol-0" has status "error"`)
+
+	s.sender.volumes["vol-0"].Status = "creating"
+	_, err = s.source.waitVolumeStatus("vol-0", "available")
+	c.Assert(err, gc.ErrorMatches, `timed out waiting for volume "vol-0" to become available: status is "creating"`)
+}
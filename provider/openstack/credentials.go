@@ -0,0 +1,119 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package openstack
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/utils"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+)
+
+var (
+	_ environs.CredentialDetector = environProvider{}
+	_ environs.CredentialChecker  = environProvider{}
+)
+
+// CredentialAttrs is specified in the environs.CredentialDetector interface.
+func (environProvider) CredentialAttrs() []environs.CredentialAttr {
+	return []environs.CredentialAttr{
+		{Name: "auth-url"},
+		{Name: "region"},
+		{Name: "tenant-name"},
+		{Name: "username"},
+		{Name: "password", Secret: true},
+	}
+}
+
+// credentialVars holds the environment variables that hold the
+// value of each credentials attribute, in order of preference.
+// These are the variables that identity.CredentialsFromEnv uses.
+var credentialVars = []struct {
+	attr string
+	vars []string
+}{
+	{"auth-url", []string{"OS_AUTH_URL"}},
+	{"region", []string{"OS_REGION_NAME", "NOVA_REGION"}},
+	{"tenant-name", []string{"OS_TENANT_NAME", "NOVA_PROJECT_ID"}},
+	{"username", []string{"OS_USERNAME", "NOVA_USERNAME"}},
+	{"password", []string{"OS_PASSWORD", "NOVA_PASSWORD"}},
+}
+
+// DetectCredentials is specified in the environs.CredentialDetector
+// interface. The credentials are taken from the OS_* (or NOVA_*)
+// environment variables, or if they are not set, from the variables
+// exported by ~/.novarc.
+func (environProvider) DetectCredentials() (map[string]interface{}, error) {
+	vars, err := readNovarc(filepath.Join(utils.Home(), ".novarc"))
+	if err != nil {
+		return nil, err
+	}
+	attrs := make(map[string]interface{})
+	for _, cv := range credentialVars {
+		for _, name := range cv.vars {
+			value := os.Getenv(name)
+			if value == "" {
+				value = vars[name]
+			}
+			if value != "" {
+				attrs[cv.attr] = value
+				break
+			}
+		}
+	}
+	return attrs, nil
+}
+
+// readNovarc returns the variables set in the novarc shell
+// script at path. It returns no variables if the file does
+// not exist.
+func readNovarc(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	vars := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		pos := strings.Index(line, "=")
+		if pos <= 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		value := line[pos+1:]
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		vars[line[:pos]] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read %s: %v", path, err)
+	}
+	return vars, nil
+}
+
+// CheckCredentials is specified in the environs.CredentialChecker
+// interface. It requests a token from the identity service.
+func (p environProvider) CheckCredentials(cfg *config.Config) error {
+	ecfg, err := p.newConfig(cfg)
+	if err != nil {
+		return err
+	}
+	client := (&environ{}).authClient(ecfg, AuthMode(ecfg.authMode()))
+	if err := client.Authenticate(); err != nil {
+		return fmt.Errorf("cannot authenticate with %q: %v", ecfg.authURL(), err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package openstack_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/juju/utils"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/testing"
+)
+
+type credentialsSuite struct {
+	testing.BaseSuite
+	provider environs.CredentialDetector
+}
+
+var _ = gc.Suite(&credentialsSuite{})
+
+func (s *credentialsSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	utils.SetHome(c.MkDir())
+	for _, name := range []string{
+		"OS_AUTH_URL",
+		"OS_REGION_NAME", "NOVA_REGION",
+		"OS_TENANT_NAME", "NOVA_PROJECT_ID",
+		"OS_USERNAME", "NOVA_USERNAME",
+		"OS_PASSWORD", "NOVA_PASSWORD",
+	} {
+		s.PatchEnvironment(name, "")
+	}
+	provider, err := environs.Provider("openstack")
+	c.Assert(err, gc.IsNil)
+	s.provider = provider.(environs.CredentialDetector)
+}
+
+func (s *credentialsSuite) TestDetectCredentialsNone(c *gc.C) {
+	attrs, err := s.provider.DetectCredentials()
+	c.Assert(err, gc.IsNil)
+	c.Assert(attrs, gc.HasLen, 0)
+}
+
+func (s *credentialsSuite) TestDetectCredentialsFromEnvironment(c *gc.C) {
+	s.PatchEnvironment("OS_AUTH_URL", "https://keystone.example.com:5000/v2.0/")
+	s.PatchEnvironment("OS_REGION_NAME", "region-a")
+	s.PatchEnvironment("NOVA_PROJECT_ID", "tenant")
+	s.PatchEnvironment("OS_USERNAME", "user")
+	s.PatchEnvironment("OS_PASSWORD", "secret")
+	attrs, err := s.provider.DetectCredentials()
+	c.Assert(err, gc.IsNil)
+	c.Assert(attrs, gc.DeepEquals, map[string]interface{}{
+		"auth-url":    "https://keystone.example.com:5000/v2.0/",
+		"region":      "region-a",
+		"tenant-name": "tenant",
+		"username":    "user",
+		"password":    "secret",
+	})
+}
+
+func (s *credentialsSuite) TestDetectCredentialsFromNovarc(c *gc.C) {
+	novarc := `
+# Sourced by the shell.
+export OS_AUTH_URL=https://keystone.example.com:5000/v2.0/
+export OS_REGION_NAME="region-a"
+export OS_TENANT_NAME='tenant'
+OS_USERNAME=user
+export OS_PASSWORD=secret
+`
+	err := ioutil.WriteFile(filepath.Join(utils.Home(), ".novarc"), []byte(novarc), 0600)
+	c.Assert(err, gc.IsNil)
+	// The environment takes precedence.
+	s.PatchEnvironment("OS_USERNAME", "other-user")
+	attrs, err := s.provider.DetectCredentials()
+	c.Assert(err, gc.IsNil)
+	c.Assert(attrs, gc.DeepEquals, map[string]interface{}{
+		"auth-url":    "https://keystone.example.com:5000/v2.0/",
+		"region":      "region-a",
+		"tenant-name": "tenant",
+		"username":    "other-user",
+		"password":    "secret",
+	})
+}
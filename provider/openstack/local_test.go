@@ -218,6 +218,21 @@ func (s *localServerSuite) TearDownTest(c *gc.C) {
 	s.BaseSuite.TearDownTest(c)
 }
 
+func (s *localServerSuite) TestCheckCredentials(c *gc.C) {
+	cfg, err := config.New(config.NoDefaults, s.TestConfig)
+	c.Assert(err, gc.IsNil)
+	provider, err := environs.Provider("openstack")
+	c.Assert(err, gc.IsNil)
+	checker := provider.(environs.CredentialChecker)
+	err = checker.CheckCredentials(cfg)
+	c.Assert(err, gc.IsNil)
+
+	cfg, err = cfg.Apply(map[string]interface{}{"password": "wrong"})
+	c.Assert(err, gc.IsNil)
+	err = checker.CheckCredentials(cfg)
+	c.Assert(err, gc.ErrorMatches, `cannot authenticate with ".*": .*`)
+}
+
 // If the bootstrap node is configured to require a public IP address,
 // bootstrapping fails if an address cannot be allocated.
 func (s *localServerSuite) TestBootstrapFailsWhenPublicIPError(c *gc.C) {
@@ -381,6 +396,48 @@ func (s *localServerSuite) TestStopInstance(c *gc.C) {
 	assertSecurityGroups(c, env, []string{"default", fmt.Sprintf("juju-%v", env.Name())})
 }
 
+func (s *localServerSuite) TestStartInstanceTags(c *gc.C) {
+	cfg, err := config.New(config.NoDefaults, s.TestConfig.Merge(coretesting.Attrs{
+		"uuid":          "f47ac10b-58cc-4372-a567-0e02b2c3d479",
+		"resource-tags": "owner=finance",
+	}))
+	c.Assert(err, gc.IsNil)
+	env, err := environs.New(cfg)
+	c.Assert(err, gc.IsNil)
+	inst, _ := testing.AssertStartInstance(c, env, "100")
+	c.Assert(openstack.InstanceServerDetail(inst).Metadata, gc.DeepEquals, map[string]string{
+		"owner":           "finance",
+		"juju-env-uuid":   "f47ac10b-58cc-4372-a567-0e02b2c3d479",
+		"juju-machine-id": "100",
+		"juju-is-state":   "false",
+	})
+}
+
+func (s *localServerSuite) TestStopInstanceNotBelongingToEnviron(c *gc.C) {
+	cfg, err := config.New(config.NoDefaults, s.TestConfig.Merge(coretesting.Attrs{
+		"uuid": "f47ac10b-58cc-4372-a567-0e02b2c3d479",
+	}))
+	c.Assert(err, gc.IsNil)
+	env, err := environs.New(cfg)
+	c.Assert(err, gc.IsNil)
+	inst, _ := testing.AssertStartInstance(c, env, "100")
+
+	otherCfg, err := config.New(config.NoDefaults, s.TestConfig.Merge(coretesting.Attrs{
+		"uuid": "8ec6ad4b-2b4c-4d49-8f4b-6c0a1b6f3e21",
+	}))
+	c.Assert(err, gc.IsNil)
+	otherEnv, err := environs.New(otherCfg)
+	c.Assert(err, gc.IsNil)
+	err = otherEnv.StopInstances(inst.Id())
+	c.Assert(err, gc.ErrorMatches, `cannot stop instance ".*": not tagged as belonging to environment ".*"`)
+
+	insts, err := env.Instances([]instance.Id{inst.Id()})
+	c.Assert(err, gc.IsNil)
+	c.Assert(insts, gc.HasLen, 1)
+	err = env.StopInstances(inst.Id())
+	c.Assert(err, gc.IsNil)
+}
+
 // Due to bug #1300755 it can happen that the security group intended for
 // an instance is also used as the common security group of another
 // environment. If this is the case, the attempt to delete the instance's
@@ -31,6 +31,7 @@ import (
 	"github.com/juju/juju/environs/instances"
 	"github.com/juju/juju/environs/simplestreams"
 	"github.com/juju/juju/environs/storage"
+	"github.com/juju/juju/environs/tags"
 	envtools "github.com/juju/juju/environs/tools"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/juju/arch"
@@ -278,6 +279,7 @@ func retryGet(uri string) (data []byte, err error) {
 
 type environ struct {
 	common.SupportsUnitPlacementPolicy
+	common.SupportsSourceCIDRsPolicy
 
 	name string
 
@@ -311,6 +313,7 @@ var _ envtools.SupportsCustomSources = (*environ)(nil)
 var _ simplestreams.HasRegion = (*environ)(nil)
 var _ state.Prechecker = (*environ)(nil)
 var _ state.InstanceDistributor = (*environ)(nil)
+var _ common.ZonedEnviron = (*environ)(nil)
 
 type openstackInstance struct {
 	e        *environ
@@ -370,6 +373,9 @@ func (inst *openstackInstance) hardwareCharacteristics() *instance.HardwareChara
 		hc.CpuPower = inst.instType.CpuPower
 		// tags not currently supported on openstack
 	}
+	if zone := inst.getServerDetail().AvailabilityZone; zone != "" {
+		hc.AvailabilityZone = &zone
+	}
 	return hc
 }
 
@@ -430,7 +436,7 @@ func convertNovaAddresses(addresses map[string][]nova.IPAddress) []network.Addre
 
 // TODO: following 30 lines nearly verbatim from environs/ec2
 
-func (inst *openstackInstance) OpenPorts(machineId string, ports []network.Port) error {
+func (inst *openstackInstance) OpenPorts(machineId string, ports []network.PortRange) error {
 	if inst.e.Config().FirewallMode() != config.FwInstance {
 		return fmt.Errorf("invalid firewall mode %q for opening ports on instance",
 			inst.e.Config().FirewallMode())
@@ -443,7 +449,7 @@ func (inst *openstackInstance) OpenPorts(machineId string, ports []network.Port)
 	return nil
 }
 
-func (inst *openstackInstance) ClosePorts(machineId string, ports []network.Port) error {
+func (inst *openstackInstance) ClosePorts(machineId string, ports []network.PortRange) error {
 	if inst.e.Config().FirewallMode() != config.FwInstance {
 		return fmt.Errorf("invalid firewall mode %q for closing ports on instance",
 			inst.e.Config().FirewallMode())
@@ -456,7 +462,7 @@ func (inst *openstackInstance) ClosePorts(machineId string, ports []network.Port
 	return nil
 }
 
-func (inst *openstackInstance) Ports(machineId string) ([]network.Port, error) {
+func (inst *openstackInstance) Ports(machineId string) ([]network.PortRange, error) {
 	if inst.e.Config().FirewallMode() != config.FwInstance {
 		return nil, fmt.Errorf("invalid firewall mode %q for retrieving ports from instance",
 			inst.e.Config().FirewallMode())
@@ -972,6 +978,7 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (instance.Ins
 		SecurityGroupNames: groupNames,
 		Networks:           networks,
 		AvailabilityZone:   availabilityZone,
+		Metadata:           tags.InstanceTags(cfg, args.MachineConfig),
 	}
 	var server *nova.Entity
 	for a := shortAttempt.Start(); a.Next(); {
@@ -1004,17 +1011,39 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (instance.Ins
 		}
 		logger.Infof("assigned public IP %s to %q", publicIP.IP, inst.Id())
 	}
-	return inst, inst.hardwareCharacteristics(), nil, nil
+	hc := inst.hardwareCharacteristics()
+	if hc.AvailabilityZone == nil && availabilityZone != "" {
+		hc.AvailabilityZone = &availabilityZone
+	}
+	return inst, hc, nil, nil
 }
 
 func (e *environ) StopInstances(ids ...instance.Id) error {
-	// If in instance firewall mode, gather the security group names.
-	var securityGroupNames []string
-	if e.Config().FirewallMode() == config.FwInstance {
-		instances, err := e.Instances(ids)
+	cfg := e.Config()
+	_, checkTags := cfg.UUID()
+	fwInstance := cfg.FirewallMode() == config.FwInstance
+	var instances []instance.Instance
+	if checkTags || fwInstance {
+		var err error
+		instances, err = e.Instances(ids)
 		if err == environs.ErrNoInstances {
 			return nil
+		} else if checkTags && err != nil && err != environs.ErrPartialInstances {
+			return err
+		}
+	}
+	// Refuse to stop instances created for another environment.
+	for _, inst := range instances {
+		if inst == nil {
+			continue
 		}
+		if !tags.BelongsToEnviron(cfg, inst.(*openstackInstance).getServerDetail().Metadata) {
+			return fmt.Errorf("cannot stop instance %q: not tagged as belonging to environment %q", inst.Id(), cfg.Name())
+		}
+	}
+	// If in instance firewall mode, gather the security group names.
+	var securityGroupNames []string
+	if fwInstance {
 		securityGroupNames = make([]string, 0, len(ids))
 		for _, inst := range instances {
 			if inst == nil {
@@ -1189,7 +1218,19 @@ func (e *environ) machinesFilter() *nova.Filter {
 	return filter
 }
 
-func (e *environ) openPortsInGroup(name string, ports []network.Port) error {
+// anySource is the source CIDR that allows access from anywhere.
+const anySource = "0.0.0.0/0"
+
+// sourceCIDR returns the CIDR from which the given port range
+// may be accessed.
+func sourceCIDR(port network.PortRange) string {
+	if port.SourceCIDR == "" {
+		return anySource
+	}
+	return port.SourceCIDR
+}
+
+func (e *environ) openPortsInGroup(name string, ports []network.PortRange) error {
 	novaclient := e.nova()
 	group, err := novaclient.SecurityGroupByName(name)
 	if err != nil {
@@ -1198,10 +1239,10 @@ func (e *environ) openPortsInGroup(name string, ports []network.Port) error {
 	for _, port := range ports {
 		_, err := novaclient.CreateSecurityGroupRule(nova.RuleInfo{
 			ParentGroupId: group.Id,
-			FromPort:      port.Number,
-			ToPort:        port.Number,
+			FromPort:      port.FromPort,
+			ToPort:        port.ToPort,
 			IPProtocol:    port.Protocol,
-			Cidr:          "0.0.0.0/0",
+			Cidr:          sourceCIDR(port),
 		})
 		if err != nil {
 			// TODO: if err is not rule already exists, raise?
@@ -1211,7 +1252,7 @@ func (e *environ) openPortsInGroup(name string, ports []network.Port) error {
 	return nil
 }
 
-func (e *environ) closePortsInGroup(name string, ports []network.Port) error {
+func (e *environ) closePortsInGroup(name string, ports []network.PortRange) error {
 	if len(ports) == 0 {
 		return nil
 	}
@@ -1224,8 +1265,9 @@ func (e *environ) closePortsInGroup(name string, ports []network.Port) error {
 	for _, port := range ports {
 		for _, p := range (*group).Rules {
 			if p.IPProtocol == nil || *p.IPProtocol != port.Protocol ||
-				p.FromPort == nil || *p.FromPort != port.Number ||
-				p.ToPort == nil || *p.ToPort != port.Number {
+				p.FromPort == nil || *p.FromPort != port.FromPort ||
+				p.ToPort == nil || *p.ToPort != port.ToPort ||
+				p.IPRange["cidr"] != sourceCIDR(port) {
 				continue
 			}
 			err := novaclient.DeleteSecurityGroupRule(p.Id)
@@ -1238,26 +1280,30 @@ func (e *environ) closePortsInGroup(name string, ports []network.Port) error {
 	return nil
 }
 
-func (e *environ) portsInGroup(name string) (ports []network.Port, err error) {
+func (e *environ) portsInGroup(name string) (ports []network.PortRange, err error) {
 	group, err := e.nova().SecurityGroupByName(name)
 	if err != nil {
 		return nil, err
 	}
 	for _, p := range (*group).Rules {
-		for i := *p.FromPort; i <= *p.ToPort; i++ {
-			ports = append(ports, network.Port{
-				Protocol: *p.IPProtocol,
-				Number:   i,
-			})
+		source := p.IPRange["cidr"]
+		if source == anySource {
+			source = ""
 		}
+		ports = append(ports, network.PortRange{
+			Protocol:   *p.IPProtocol,
+			FromPort:   *p.FromPort,
+			ToPort:     *p.ToPort,
+			SourceCIDR: source,
+		})
 	}
-	network.SortPorts(ports)
+	network.SortPortRanges(ports)
 	return ports, nil
 }
 
 // TODO: following 30 lines nearly verbatim from environs/ec2
 
-func (e *environ) OpenPorts(ports []network.Port) error {
+func (e *environ) OpenPorts(ports []network.PortRange) error {
 	if e.Config().FirewallMode() != config.FwGlobal {
 		return fmt.Errorf("invalid firewall mode %q for opening ports on environment",
 			e.Config().FirewallMode())
@@ -1269,7 +1315,7 @@ func (e *environ) OpenPorts(ports []network.Port) error {
 	return nil
 }
 
-func (e *environ) ClosePorts(ports []network.Port) error {
+func (e *environ) ClosePorts(ports []network.PortRange) error {
 	if e.Config().FirewallMode() != config.FwGlobal {
 		return fmt.Errorf("invalid firewall mode %q for closing ports on environment",
 			e.Config().FirewallMode())
@@ -1281,7 +1327,7 @@ func (e *environ) ClosePorts(ports []network.Port) error {
 	return nil
 }
 
-func (e *environ) Ports() ([]network.Port, error) {
+func (e *environ) Ports() ([]network.PortRange, error) {
 	if e.Config().FirewallMode() != config.FwGlobal {
 		return nil, fmt.Errorf("invalid firewall mode %q for retrieving ports from environment",
 			e.Config().FirewallMode())
@@ -1387,7 +1433,8 @@ func (e *environ) ensureGroup(name string, rules []nova.RuleInfo) (nova.Security
 		// groups (especially if they were set up under Python)
 		return *group, nil
 	}
-	// Doesn't exist, so try and create it.
+	// Doesn't exist, so try and create it. Nova security groups
+	// cannot carry metadata, so unlike instances they are not tagged.
 	group, err = novaClient.CreateSecurityGroup(name, "juju group")
 	if err != nil {
 		if !gooseerrors.IsDuplicateValue(err) {
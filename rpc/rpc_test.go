@@ -841,6 +841,38 @@ func (*rpcSuite) TestRootIsKilled(c *gc.C) {
 	c.Assert(root.killed, gc.Equals, true)
 }
 
+type AuthorizerRoot struct {
+	Root
+}
+
+func (r *AuthorizerRoot) AuthorizeRequest(req rpc.Request) error {
+	if req.Action == "Call0r1" {
+		return nil
+	}
+	return &codedError{"not allowed", "unauthorized"}
+}
+
+func (*rpcSuite) TestRootAuthorizesRequests(c *gc.C) {
+	root := &AuthorizerRoot{}
+	root.simple = map[string]*SimpleMethods{
+		"a99": {root: &root.Root, id: "a99"},
+	}
+	client, srvDone, _, _ := newRPCClientServer(c, root, nil, false)
+	defer closeClient(c, client, srvDone)
+
+	var r stringVal
+	err := client.Call(rpc.Request{"SimpleMethods", "a99", "Call0r1"}, nil, &r)
+	c.Assert(err, gc.IsNil)
+	c.Assert(r.Val, gc.Equals, "Call0r1 ret")
+
+	err = client.Call(rpc.Request{"SimpleMethods", "a99", "Call0r0"}, nil, nil)
+	c.Assert(err, gc.DeepEquals, &rpc.RequestError{
+		Message: "not allowed",
+		Code:    "unauthorized",
+	})
+	c.Assert(root.calls, gc.HasLen, 1)
+}
+
 func (*rpcSuite) TestBidirectional(c *gc.C) {
 	srvRoot := &Root{}
 	client, srvDone, _, _ := newRPCClientServer(c, srvRoot, nil, true)
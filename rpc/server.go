@@ -311,6 +311,15 @@ type Killer interface {
 	Kill()
 }
 
+// RequestAuthorizer represents a root value that decides which
+// requests it will serve. If the root passed to Serve implements
+// RequestAuthorizer, AuthorizeRequest is called for each request
+// before its method is invoked; if it returns an error, the error
+// is returned to the client and the method is not called.
+type RequestAuthorizer interface {
+	AuthorizeRequest(req Request) error
+}
+
 // input reads messages from the connection and handles them
 // appropriately.
 func (conn *Conn) input() {
@@ -472,6 +481,11 @@ func (conn *Conn) bindRequest(hdr *Header) (boundRequest, error) {
 		}
 		return boundRequest{}, err
 	}
+	if authorizer, ok := rootValue.GoValue().Interface().(RequestAuthorizer); ok {
+		if err := authorizer.AuthorizeRequest(hdr.Request); err != nil {
+			return boundRequest{}, transformErrors(err)
+		}
+	}
 	return boundRequest{
 		MethodCaller:    caller,
 		transformErrors: transformErrors,
@@ -89,22 +89,39 @@ func (a *Action) Payload() map[string]interface{} {
 	return a.doc.Payload
 }
 
+// UnitName returns the name of the unit the action is queued for.
+func (a *Action) UnitName() string {
+	return unitNameFromGlobalKey(getActionIdPrefix(a.doc.Id))
+}
+
 // Complete removes action from the pending queue and creates an ActionResult
 // to capture the output and end state of the action.
 func (a *Action) Complete(output string) error {
-	return a.removeAndLog(ActionCompleted, output)
+	return a.removeAndLog(ActionCompleted, nil, output)
 }
 
 // Fail removes an Action from the queue, and creates an ActionResult that
 // will capture the reason for the failure.
 func (a *Action) Fail(reason string) error {
-	return a.removeAndLog(ActionFailed, reason)
+	return a.removeAndLog(ActionFailed, nil, reason)
+}
+
+// Finish removes an Action from the queue, and creates an ActionResult
+// holding the final status, the results set by the action itself, and
+// a descriptive message.
+func (a *Action) Finish(status ActionStatus, results map[string]interface{}, message string) error {
+	switch status {
+	case ActionCompleted, ActionFailed:
+	default:
+		return errors.Errorf("unexpected action status %q", status)
+	}
+	return a.removeAndLog(status, results, message)
 }
 
 // removeAndLog takes the action off of the pending queue, and creates an
 // actionresult to capture the outcome of the action.
-func (a *Action) removeAndLog(finalStatus ActionStatus, output string) error {
-	result, err := newActionResultDoc(a, finalStatus, output)
+func (a *Action) removeAndLog(finalStatus ActionStatus, results map[string]interface{}, output string) error {
+	result, err := newActionResultDoc(a, finalStatus, results, output)
 	if err != nil {
 		return err
 	}
@@ -4,10 +4,12 @@
 package state_test
 
 import (
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "launchpad.net/gocheck"
 
 	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
 )
 
 type ActionSuite struct {
@@ -220,6 +222,71 @@ func (s *ActionSuite) TestComplete(c *gc.C) {
 	c.Assert(len(actions), gc.Equals, 0)
 }
 
+func (s *ActionSuite) TestFinish(c *gc.C) {
+	id, err := s.unit.AddAction("snapshot", map[string]interface{}{"outfile": "foo.bz2"})
+	c.Assert(err, gc.IsNil)
+	action, err := s.State.Action(id)
+	c.Assert(err, gc.IsNil)
+	c.Assert(action.UnitName(), gc.Equals, s.unit.Name())
+
+	err = action.Finish(state.ActionStatus("bogus"), nil, "")
+	c.Assert(err, gc.ErrorMatches, `unexpected action status "bogus"`)
+
+	results := map[string]interface{}{"size": "42"}
+	err = action.Finish(state.ActionCompleted, results, "all done")
+	c.Assert(err, gc.IsNil)
+
+	actionResults, err := s.State.ActionResultsForAction(id)
+	c.Assert(err, gc.IsNil)
+	c.Assert(actionResults, gc.HasLen, 1)
+	result := actionResults[0]
+	c.Assert(result.ActionId(), gc.Equals, id)
+	c.Assert(result.UnitName(), gc.Equals, s.unit.Name())
+	c.Assert(result.ActionName(), gc.Equals, "snapshot")
+	c.Assert(result.Status(), gc.Equals, state.ActionCompleted)
+	c.Assert(result.Results(), jc.DeepEquals, results)
+	c.Assert(result.Output(), gc.Equals, "all done")
+
+	_, err = s.State.Action(id)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *ActionSuite) TestWatchActions(c *gc.C) {
+	id0, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, gc.IsNil)
+
+	w := s.unit.WatchActions()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewStringsWatcherC(c, s.State, w)
+	wc.AssertChange(id0)
+	wc.AssertNoChange()
+
+	// Queue two more actions; both are reported.
+	id1, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, gc.IsNil)
+	id2, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, gc.IsNil)
+	wc.AssertChange(id1, id2)
+	wc.AssertNoChange()
+
+	// Actions queued for another unit are not reported.
+	other, err := s.service.AddUnit()
+	c.Assert(err, gc.IsNil)
+	_, err = other.AddAction("snapshot", nil)
+	c.Assert(err, gc.IsNil)
+	wc.AssertNoChange()
+
+	// Finishing an action is not reported.
+	action, err := s.State.Action(id0)
+	c.Assert(err, gc.IsNil)
+	err = action.Complete("")
+	c.Assert(err, gc.IsNil)
+	wc.AssertNoChange()
+
+	statetesting.AssertStop(c, w)
+	wc.AssertClosed()
+}
+
 func (s *ActionSuite) TestGetActionIdPrefix(c *gc.C) {
 	getPrefixTest(c, state.GetActionIdPrefix, state.ActionMarker)
 }
@@ -40,6 +40,10 @@ type actionResultDoc struct {
 	// ActionCompleted for an action that successfully completed.
 	Status ActionStatus
 
+	// Results holds the key/value pairs set by the action while it
+	// was running.
+	Results map[string]interface{}
+
 	// Output captures any text emitted by the action.
 	Output string
 }
@@ -82,7 +86,7 @@ func newActionResultId(st *State, globalKey string) (string, error) {
 }
 
 // newActionResultDoc builds a new doc
-func newActionResultDoc(action *Action, status ActionStatus, output string) (*actionResultDoc, error) {
+func newActionResultDoc(action *Action, status ActionStatus, results map[string]interface{}, output string) (*actionResultDoc, error) {
 	id, err := newActionResultId(action.st, action.Id())
 	if err != nil {
 		return nil, err
@@ -92,6 +96,7 @@ func newActionResultDoc(action *Action, status ActionStatus, output string) (*ac
 		ActionName: action.Name(),
 		Payload:    action.Payload(),
 		Status:     status,
+		Results:    results,
 		Output:     output,
 	}, nil
 }
@@ -117,6 +122,16 @@ func (a *ActionResult) Id() string {
 	return a.doc.Id
 }
 
+// ActionId returns the id of the Action that produced this ActionResult.
+func (a *ActionResult) ActionId() string {
+	return getActionResultIdPrefix(a.doc.Id)
+}
+
+// UnitName returns the name of the unit the Action was run on.
+func (a *ActionResult) UnitName() string {
+	return unitNameFromGlobalKey(getActionIdPrefix(a.ActionId()))
+}
+
 // ActionName returns the name of the Action.
 func (a *ActionResult) ActionName() string {
 	return a.doc.ActionName
@@ -133,6 +148,12 @@ func (a *ActionResult) Status() ActionStatus {
 	return a.doc.Status
 }
 
+// Results returns the key/value pairs set by the action while it was
+// running.
+func (a *ActionResult) Results() map[string]interface{} {
+	return a.doc.Results
+}
+
 // Output returns the text caputured from the action as it was executed.
 func (a *ActionResult) Output() string {
 	return a.doc.Output
@@ -9,6 +9,7 @@ import (
 	"labix.org/v2/mgo/bson"
 	"labix.org/v2/mgo/txn"
 
+	"github.com/juju/juju/cert"
 	"github.com/juju/juju/network"
 )
 
@@ -81,8 +82,13 @@ func (st *State) APIAddressesFromMachines() ([]string, error) {
 
 const apiHostPortsKey = "apiHostPorts"
 
+// apiHostPortsDoc holds the addresses of the API servers and, once
+// they've been rotated, the certificates of the CAs trusted to sign
+// the API servers' certificates. The two are kept together so that
+// agents watching for API address changes learn about new CAs too.
 type apiHostPortsDoc struct {
 	APIHostPorts [][]hostPort
+	CACert       string `bson:"cacert,omitempty"`
 }
 
 // SetAPIHostPorts sets the addresses of the API server
@@ -108,6 +114,26 @@ func (st *State) SetAPIHostPorts(hps [][]network.HostPort) error {
 	return nil
 }
 
+// SetCACert records the certificates, in PEM format, of the CAs trusted
+// to sign the state server certificate. More than one CA is trusted
+// while certificates are rotated, the most recently generated first.
+func (st *State) SetCACert(caCertsPEM string) error {
+	if _, err := cert.ParseCerts(caCertsPEM); err != nil {
+		return fmt.Errorf("cannot set CA certificates: %v", err)
+	}
+	ops := []txn.Op{{
+		C:  st.stateServers.Name,
+		Id: apiHostPortsKey,
+		Update: bson.D{{"$set", bson.D{
+			{"cacert", caCertsPEM},
+		}}},
+	}}
+	if err := st.runTransaction(ops); err != nil {
+		return fmt.Errorf("cannot set CA certificates: %v", err)
+	}
+	return nil
+}
+
 // APIHostPorts returns the API addresses as set by SetAPIHostPorts.
 func (st *State) APIHostPorts() ([][]network.HostPort, error) {
 	var doc apiHostPortsDoc
@@ -8,6 +8,7 @@ import (
 	"crypto/x509"
 	"fmt"
 	"io"
+	"net/http"
 	"strings"
 	"time"
 
@@ -122,12 +123,10 @@ func Open(info *Info, opts DialOpts) (*State, error) {
 	if len(info.Addrs) == 0 {
 		return nil, fmt.Errorf("no API addresses to connect to")
 	}
-	pool := x509.NewCertPool()
-	xcert, err := cert.ParseCert(info.CACert)
+	pool, err := cert.NewCertPool(info.CACert)
 	if err != nil {
 		return nil, err
 	}
-	pool.AddCert(xcert)
 
 	environUUID := ""
 	if info.EnvironTag != "" {
@@ -301,6 +300,20 @@ func (s *State) Addr() string {
 	return s.addr
 }
 
+// SendHTTPRequest sends an HTTP request with the given method to the
+// given path on the API server, authenticated with the credentials
+// used to log in.
+func (s *State) SendHTTPRequest(method, path string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, s.serverRoot+"/"+strings.TrimPrefix(path, "/"), body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create HTTP request: %v", err)
+	}
+	req.SetBasicAuth(s.tag, s.password)
+	// See the comment in Client.AddLocalCharm for why we
+	// cannot use a validating client here.
+	return utils.GetNonValidatingHTTPClient().Do(req)
+}
+
 // EnvironTag returns the Environment Tag describing the environment we are
 // connected to.
 func (s *State) EnvironTag() string {
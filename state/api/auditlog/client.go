@@ -0,0 +1,35 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package auditlog
+
+import (
+	"github.com/juju/juju/state/api"
+	"github.com/juju/juju/state/api/params"
+)
+
+// Client provides access to the AuditLog API facade.
+type Client struct {
+	st *api.State
+}
+
+// NewClient returns a new AuditLog client using the given API
+// connection.
+func NewClient(st *api.State) *Client {
+	return &Client{st}
+}
+
+// Close closes the underlying API connection.
+func (c *Client) Close() error {
+	return c.st.Close()
+}
+
+// Entries returns the audit entries matching the given filter,
+// oldest first.
+func (c *Client) Entries(filter params.AuditLogFilter) ([]params.AuditLogEntry, error) {
+	var result params.AuditLogResults
+	if err := c.st.Call("AuditLog", "", "Entries", filter, &result); err != nil {
+		return nil, err
+	}
+	return result.Entries, nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package auditlog_test
+
+import (
+	gc "launchpad.net/gocheck"
+
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state/api/auditlog"
+	"github.com/juju/juju/state/api/params"
+)
+
+type auditLogSuite struct {
+	jujutesting.JujuConnSuite
+
+	client *auditlog.Client
+}
+
+var _ = gc.Suite(&auditLogSuite{})
+
+func (s *auditLogSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.client = auditlog.NewClient(s.APIState)
+}
+
+func (s *auditLogSuite) TestEntries(c *gc.C) {
+	s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	err := s.APIState.Client().ServiceExpose("wordpress")
+	c.Assert(err, gc.IsNil)
+	err = s.APIState.Client().ServiceUnexpose("wordpress")
+	c.Assert(err, gc.IsNil)
+
+	entries, err := s.client.Entries(params.AuditLogFilter{Entity: "wordpress"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(entries, gc.HasLen, 2)
+	c.Assert(entries[0].Method, gc.Equals, "ServiceExpose")
+	c.Assert(entries[1].Method, gc.Equals, "ServiceUnexpose")
+	c.Assert(entries[1].User, gc.Equals, "user-admin")
+
+	entries, err = s.client.Entries(params.AuditLogFilter{User: "user-bob"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(entries, gc.HasLen, 0)
+}
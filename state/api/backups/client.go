@@ -0,0 +1,107 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/juju/juju/state/api"
+	"github.com/juju/juju/state/api/params"
+)
+
+// Client provides access to the Backups API facade.
+type Client struct {
+	st *api.State
+}
+
+// NewClient returns a new Backups client using the given API
+// connection.
+func NewClient(st *api.State) *Client {
+	return &Client{st}
+}
+
+func (c *Client) call(method string, params, result interface{}) error {
+	return c.st.Call("Backups", "", method, params, result)
+}
+
+// Close closes the underlying API connection.
+func (c *Client) Close() error {
+	return c.st.Close()
+}
+
+// Create makes a new backup of the state server and returns its
+// metadata.
+func (c *Client) Create(notes string) (*params.BackupsMetadataResult, error) {
+	var result params.BackupsMetadataResult
+	args := params.BackupsCreateArgs{Notes: notes}
+	if err := c.call("Create", args, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Info returns the metadata of the backup with the given id.
+func (c *Client) Info(id string) (*params.BackupsMetadataResult, error) {
+	var result params.BackupsMetadataResult
+	args := params.BackupsInfoArgs{ID: id}
+	if err := c.call("Info", args, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// List returns the metadata of every backup.
+func (c *Client) List() ([]params.BackupsMetadataResult, error) {
+	var result params.BackupsListResult
+	if err := c.call("List", nil, &result); err != nil {
+		return nil, err
+	}
+	return result.List, nil
+}
+
+// Remove deletes the backup with the given id.
+func (c *Client) Remove(id string) error {
+	return c.call("Remove", params.BackupsRemoveArgs{ID: id}, nil)
+}
+
+// Restore restores the state server from the backup with the given
+// id.
+func (c *Client) Restore(id string) error {
+	return c.call("Restore", params.BackupsRestoreArgs{ID: id}, nil)
+}
+
+// Download returns a reader for the archive of the backup with the
+// given id, and the checksum reported by the server. The caller must
+// close the reader.
+func (c *Client) Download(id string) (io.ReadCloser, string, error) {
+	resp, err := c.st.SendHTTPRequest("GET", "/backups?id="+url.QueryEscape(id), nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot download backup: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, "", fmt.Errorf("cannot read backup download response: %v", err)
+		}
+		var result params.ErrorResult
+		if err := json.Unmarshal(body, &result); err != nil {
+			return nil, "", fmt.Errorf("cannot unmarshal backup download response: %v", err)
+		}
+		if result.Error != nil {
+			return nil, "", result.Error
+		}
+		return nil, "", fmt.Errorf("cannot download backup: %s", resp.Status)
+	}
+	var checksum string
+	if digest := resp.Header.Get("Digest"); len(digest) > len("SHA=") {
+		checksum = digest[len("SHA="):]
+	}
+	return resp.Body, checksum, nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/backups"
+	"github.com/juju/juju/state/api/params"
+)
+
+type backupsSuite struct {
+	jujutesting.JujuConnSuite
+
+	client *backups.Client
+}
+
+var _ = gc.Suite(&backupsSuite{})
+
+func (s *backupsSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.client = backups.NewClient(s.APIState)
+}
+
+func (s *backupsSuite) addBackup(c *gc.C, data string) *state.BackupMetadata {
+	meta, err := s.State.AddBackupMetadata(state.BackupMetadataParams{
+		Started:     time.Date(2014, 7, 14, 12, 30, 0, 0, time.UTC),
+		Checksum:    "checksum",
+		Size:        int64(len(data)),
+		Notes:       "some notes",
+		Environment: "env-uuid",
+	})
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.BackupStorage().Put(meta.ArchivePath(), bytes.NewReader([]byte(data)), int64(len(data)))
+	c.Assert(err, gc.IsNil)
+	err = s.State.SetBackupStored(meta.Id())
+	c.Assert(err, gc.IsNil)
+	return meta
+}
+
+func (s *backupsSuite) TestInfo(c *gc.C) {
+	meta := s.addBackup(c, "archive data")
+	info, err := s.client.Info(meta.Id())
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.ID, gc.Equals, meta.Id())
+	c.Assert(info.Notes, gc.Equals, "some notes")
+	c.Assert(info.Stored, jc.IsTrue)
+
+	_, err = s.client.Info("foo")
+	c.Assert(err, gc.ErrorMatches, `backup "foo" not found`)
+	c.Assert(err, jc.Satisfies, params.IsCodeNotFound)
+}
+
+func (s *backupsSuite) TestList(c *gc.C) {
+	list, err := s.client.List()
+	c.Assert(err, gc.IsNil)
+	c.Assert(list, gc.HasLen, 0)
+
+	meta := s.addBackup(c, "archive data")
+	list, err = s.client.List()
+	c.Assert(err, gc.IsNil)
+	c.Assert(list, gc.HasLen, 1)
+	c.Assert(list[0].ID, gc.Equals, meta.Id())
+}
+
+func (s *backupsSuite) TestRemove(c *gc.C) {
+	meta := s.addBackup(c, "archive data")
+	err := s.client.Remove(meta.Id())
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.BackupMetadata(meta.Id())
+	c.Assert(err, gc.ErrorMatches, `backup ".*" not found`)
+}
+
+func (s *backupsSuite) TestDownload(c *gc.C) {
+	meta := s.addBackup(c, "archive data")
+	r, checksum, err := s.client.Download(meta.Id())
+	c.Assert(err, gc.IsNil)
+	defer r.Close()
+	c.Assert(checksum, gc.Equals, "checksum")
+	data, err := ioutil.ReadAll(r)
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(data), gc.Equals, "archive data")
+
+	_, _, err = s.client.Download("foo")
+	c.Assert(err, gc.ErrorMatches, `backup "foo" not found`)
+}
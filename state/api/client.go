@@ -25,6 +25,7 @@ import (
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/storage"
 	"github.com/juju/juju/tools"
 	"github.com/juju/juju/version"
 )
@@ -86,6 +87,7 @@ type ServiceStatus struct {
 	Err           error
 	Charm         string
 	Exposed       bool
+	ExposedCIDRs  []string
 	Life          string
 	Relations     map[string][]string
 	Networks      NetworksSpecification
@@ -105,6 +107,16 @@ type UnitStatus struct {
 	Life           string
 	Err            error
 
+	// AgentActivity and ActivityHook hold what the unit's agent
+	// is doing and, if it is running a hook, which one.
+	AgentActivity params.AgentActivity
+	ActivityHook  string
+
+	// WorkloadStatus and WorkloadStatusInfo hold the status
+	// of the unit's workload, as reported by its charm.
+	WorkloadStatus     params.WorkloadStatus
+	WorkloadStatusInfo string
+
 	Machine       string
 	OpenedPorts   []string
 	PublicAddress string
@@ -148,6 +160,16 @@ type Status struct {
 	Services        map[string]ServiceStatus
 	Networks        map[string]NetworkStatus
 	Relations       []RelationStatus
+	Certificates    *CertificatesStatus
+}
+
+// CertificatesStatus holds when the certificates
+// securing the API expire.
+type CertificatesStatus struct {
+	// CAExpiry holds the expiry time of each trusted CA
+	// certificate, in the order the CAs are trusted.
+	CAExpiry     []time.Time
+	ServerExpiry time.Time
 }
 
 // Status returns the status of the juju environment.
@@ -272,6 +294,18 @@ func (c *Client) DestroyRelation(endpoints ...string) error {
 	return c.call("DestroyRelation", params, nil)
 }
 
+// ServiceOffer offers the specified endpoints of a service to the other
+// environments hosted by the state server, and returns the URL by which
+// they may relate to it.
+func (c *Client) ServiceOffer(service string, endpoints ...string) (string, error) {
+	var result params.ServiceOfferResult
+	params := params.ServiceOffer{ServiceName: service, Endpoints: endpoints}
+	if err := c.call("ServiceOffer", params, &result); err != nil {
+		return "", err
+	}
+	return result.URL, nil
+}
+
 // ServiceCharmRelations returns the service's charms relation names.
 func (c *Client) ServiceCharmRelations(service string) ([]string, error) {
 	var results params.ServiceCharmRelationsResults
@@ -332,6 +366,14 @@ func (c *Client) ServiceExpose(service string) error {
 	return c.call("ServiceExpose", params, nil)
 }
 
+// ServiceExposeTo changes the juju-managed firewall to expose any ports
+// that were also explicitly marked by units as open, but only to the
+// given source CIDRs.
+func (c *Client) ServiceExposeTo(service string, cidrs []string) error {
+	params := params.ServiceExposeTo{ServiceName: service, CIDRs: cidrs}
+	return c.call("ServiceExposeTo", params, nil)
+}
+
 // ServiceUnexpose changes the juju-managed firewall to unexpose any ports that
 // were also explicitly marked by units as open.
 func (c *Client) ServiceUnexpose(service string) error {
@@ -370,6 +412,20 @@ func (c *Client) ServiceDeploy(charmURL string, serviceName string, numUnits int
 	return c.call("ServiceDeploy", params, nil)
 }
 
+// ServiceDeployWithStorage works exactly like ServiceDeployWithNetworks,
+// but also allows the specification of the storage to create for each
+// unit of the service, with args.Storage.
+func (c *Client) ServiceDeployWithStorage(args params.ServiceDeploy) error {
+	return c.call("ServiceDeployWithStorage", args, nil)
+}
+
+// ServiceDeployWithBindings works exactly like ServiceDeployWithStorage,
+// but also allows binding the relation endpoints of the service to
+// spaces, with args.EndpointBindings.
+func (c *Client) ServiceDeployWithBindings(args params.ServiceDeploy) error {
+	return c.call("ServiceDeployWithBindings", args, nil)
+}
+
 // ServiceUpdate updates the service attributes, including charm URL,
 // minimum number of units, settings and constraints.
 // TODO(frankban) deprecate redundant API calls that this supercedes.
@@ -411,6 +467,20 @@ func (c *Client) AddServiceUnits(service string, numUnits int, machineSpec strin
 	return results.Units, err
 }
 
+// AddServiceUnitsWithStorage adds a given number of units to a service,
+// creating the specified storage for each of them.
+func (c *Client) AddServiceUnitsWithStorage(service string, numUnits int, machineSpec string, storageCons map[string]storage.Directive) ([]string, error) {
+	args := params.AddServiceUnits{
+		ServiceName:   service,
+		NumUnits:      numUnits,
+		ToMachineSpec: machineSpec,
+		Storage:       storageCons,
+	}
+	results := new(params.AddServiceUnitsResults)
+	err := c.call("AddServiceUnitsWithStorage", args, results)
+	return results.Units, err
+}
+
 // DestroyServiceUnits decreases the number of units dedicated to a service.
 func (c *Client) DestroyServiceUnits(unitNames ...string) error {
 	params := params.DestroyServiceUnits{unitNames}
@@ -480,6 +550,10 @@ type EnvironmentInfo struct {
 	ProviderType  string
 	Name          string
 	UUID          string
+	// ServerUUID holds the UUID of the environment of the state
+	// server hosting the environment. It is the same as UUID if
+	// the environment is not hosted.
+	ServerUUID string
 }
 
 // EnvironmentInfo returns details about the Juju environment.
@@ -554,6 +628,18 @@ func (c *Client) SetEnvironAgentVersion(version version.Number) error {
 	return c.call("SetEnvironAgentVersion", args, nil)
 }
 
+// RotateCertificates replaces the certificates used to secure the
+// API. If newCA is true, a new CA certificate is generated and trusted
+// alongside the current one, and its private key is returned in the
+// result. Otherwise a new server certificate is signed using the
+// trusted CA matching caKey.
+func (c *Client) RotateCertificates(newCA bool, caKey string) (params.RotateCertificatesResult, error) {
+	var result params.RotateCertificatesResult
+	args := params.RotateCertificates{NewCA: newCA, CAPrivateKey: caKey}
+	err := c.call("RotateCertificates", args, &result)
+	return result, err
+}
+
 // FindTools returns a List containing all tools matching the specified parameters.
 func (c *Client) FindTools(majorVersion, minorVersion int,
 	series, arch string) (result params.FindToolsResults, err error) {
@@ -585,6 +671,53 @@ func (c *Client) Run(run params.RunParams) ([]params.RunResult, error) {
 	return results.Results, err
 }
 
+// EnqueueAction queues the named action, with the given parameters,
+// for execution by the unit with the given tag. It returns the id
+// assigned to the queued action.
+func (c *Client) EnqueueAction(unitTag, name string, actionParams map[string]interface{}) (string, error) {
+	var results params.ActionResults
+	args := params.Actions{
+		Actions: []params.Action{{Unit: unitTag, Name: name, Params: actionParams}},
+	}
+	if err := c.call("EnqueueAction", args, &results); err != nil {
+		return "", err
+	}
+	if n := len(results.Results); n != 1 {
+		return "", fmt.Errorf("expected 1 result, got %d", n)
+	}
+	if err := results.Results[0].Error; err != nil {
+		return "", err
+	}
+	return results.Results[0].Action.Id, nil
+}
+
+// ListActions returns the actions still queued for the unit with the
+// given tag.
+func (c *Client) ListActions(unitTag string) ([]params.ActionResult, error) {
+	return c.unitActions("ListActions", unitTag)
+}
+
+// ActionResults returns the outcome of every action that has finished
+// running on the unit with the given tag.
+func (c *Client) ActionResults(unitTag string) ([]params.ActionResult, error) {
+	return c.unitActions("ActionResults", unitTag)
+}
+
+func (c *Client) unitActions(method, unitTag string) ([]params.ActionResult, error) {
+	var results params.ActionsByUnits
+	args := params.Entities{Entities: []params.Entity{{Tag: unitTag}}}
+	if err := c.call(method, args, &results); err != nil {
+		return nil, err
+	}
+	if n := len(results.Results); n != 1 {
+		return nil, fmt.Errorf("expected 1 result, got %d", n)
+	}
+	if err := results.Results[0].Error; err != nil {
+		return nil, err
+	}
+	return results.Results[0].Actions, nil
+}
+
 // DestroyEnvironment puts the environment into a "dying" state,
 // and removes all non-manager machine instances. DestroyEnvironment
 // will fail if there are any manually-provisioned non-manager machines
@@ -770,6 +903,14 @@ func (c *Client) APIHostPorts() ([][]network.HostPort, error) {
 	return result.Servers, nil
 }
 
+// ConnectionInfo returns the information needed to connect to the
+// environment's API servers. It holds no secrets.
+func (c *Client) ConnectionInfo() (params.ConnectionInfo, error) {
+	var result params.ConnectionInfo
+	err := c.call("ConnectionInfo", nil, &result)
+	return result, err
+}
+
 // EnsureAvailability ensures the availability of Juju state servers.
 func (c *Client) EnsureAvailability(numStateServers int, cons constraints.Value, series string) error {
 	args := params.EnsureAvailability{
@@ -824,9 +965,17 @@ type DebugLogParams struct {
 	Backlog uint
 	// Level specifies the minimum logging level to be sent back in the response.
 	Level loggo.Level
-	// Replay tells the server to start at the start of the log file rather
+	// Replay tells the server to start at the start of the log rather
 	// than the end. If replay is true, backlog is ignored.
 	Replay bool
+	// StartTime, if set, tells the server to only send log lines logged
+	// at or after this time. Together with Replay, it replays the log
+	// from the given time.
+	StartTime time.Time
+	// EndTime, if set, tells the server to only send log lines logged
+	// at or before this time, and to close the connection once they
+	// have been sent.
+	EndTime time.Time
 }
 
 // WatchDebugLog returns a ReadCloser that the caller can read the log
@@ -859,6 +1008,12 @@ func (c *Client) WatchDebugLog(args DebugLogParams) (io.ReadCloser, error) {
 	if args.Level != loggo.UNSPECIFIED {
 		attrs.Set("level", fmt.Sprint(args.Level))
 	}
+	if !args.StartTime.IsZero() {
+		attrs.Set("startTime", args.StartTime.UTC().Format(time.RFC3339))
+	}
+	if !args.EndTime.IsZero() {
+		attrs.Set("endTime", args.EndTime.UTC().Format(time.RFC3339))
+	}
 	attrs["includeEntity"] = args.IncludeEntity
 	attrs["includeModule"] = args.IncludeModule
 	attrs["excludeEntity"] = args.ExcludeEntity
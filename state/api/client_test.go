@@ -14,6 +14,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"code.google.com/p/go.net/websocket"
 	"github.com/juju/charm"
@@ -94,11 +95,22 @@ func (s *clientSuite) TestAddLocalCharm(c *gc.C) {
 }
 
 func (s *clientSuite) TestWatchDebugLogConnected(c *gc.C) {
-	// Shows both the unmarshalling of a real error, and
-	// that the api server is connected.
+	// Shows that the api server is connected.
 	client := s.APIState.Client()
 	reader, err := client.WatchDebugLog(api.DebugLogParams{})
-	c.Assert(err, gc.ErrorMatches, "cannot open log file: .*")
+	c.Assert(err, gc.IsNil)
+	c.Assert(reader, gc.NotNil)
+	c.Assert(reader.Close(), gc.IsNil)
+}
+
+func (s *clientSuite) TestWatchDebugLogError(c *gc.C) {
+	// Shows the unmarshalling of a real error.
+	client := s.APIState.Client()
+	reader, err := client.WatchDebugLog(api.DebugLogParams{
+		IncludeModule: []string{"juju"},
+		Level:         loggo.Level(42),
+	})
+	c.Assert(err, gc.ErrorMatches, `level value .* is not one of .*`)
 	c.Assert(reader, gc.IsNil)
 }
 
@@ -156,6 +168,8 @@ func (s *clientSuite) TestParamsEncoded(c *gc.C) {
 		Backlog:       200,
 		Level:         loggo.ERROR,
 		Replay:        true,
+		StartTime:     time.Date(2014, 10, 1, 12, 0, 0, 0, time.UTC),
+		EndTime:       time.Date(2014, 10, 1, 13, 0, 0, 0, time.UTC),
 	}
 
 	client := s.APIState.Client()
@@ -175,6 +189,8 @@ func (s *clientSuite) TestParamsEncoded(c *gc.C) {
 		"backlog":       {"200"},
 		"level":         {"ERROR"},
 		"replay":        {"true"},
+		"startTime":     {"2014-10-01T12:00:00Z"},
+		"endTime":       {"2014-10-01T13:00:00Z"},
 	})
 }
 
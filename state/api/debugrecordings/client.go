@@ -0,0 +1,51 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package debugrecordings
+
+import (
+	"github.com/juju/juju/state/api"
+	"github.com/juju/juju/state/api/params"
+)
+
+// Client provides access to the DebugRecordings API facade.
+type Client struct {
+	st *api.State
+}
+
+// NewClient returns a new DebugRecordings client using the given
+// API connection.
+func NewClient(st *api.State) *Client {
+	return &Client{st}
+}
+
+func (c *Client) call(method string, params, result interface{}) error {
+	return c.st.Call("DebugRecordings", "", method, params, result)
+}
+
+// Close closes the underlying API connection.
+func (c *Client) Close() error {
+	return c.st.Close()
+}
+
+// List returns the debug-hooks session recordings of the unit with
+// the given name, or of all units if the name is empty, oldest first.
+func (c *Client) List(unitName string) ([]params.DebugRecording, error) {
+	var result params.DebugRecordingsListResult
+	args := params.DebugRecordingsFilter{Unit: unitName}
+	if err := c.call("List", args, &result); err != nil {
+		return nil, err
+	}
+	return result.Recordings, nil
+}
+
+// Get returns the debug-hooks session recording with the given id,
+// along with its transcript and timings.
+func (c *Client) Get(id string) (*params.DebugRecordingData, error) {
+	var result params.DebugRecordingData
+	args := params.DebugRecordingsGetArgs{Id: id}
+	if err := c.call("Get", args, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package debugrecordings_test
+
+import (
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/debugrecordings"
+)
+
+type debugRecordingsSuite struct {
+	jujutesting.JujuConnSuite
+
+	client *debugrecordings.Client
+}
+
+var _ = gc.Suite(&debugRecordingsSuite{})
+
+func (s *debugRecordingsSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.client = debugrecordings.NewClient(s.APIState)
+}
+
+func (s *debugRecordingsSuite) TestListAndGet(c *gc.C) {
+	started := time.Date(2014, 7, 14, 12, 0, 0, 0, time.UTC)
+	for _, unitName := range []string{"mysql/0", "wordpress/0"} {
+		_, err := s.State.AddDebugRecording(state.DebugRecordingParams{
+			Unit:     unitName,
+			Hook:     "install",
+			Started:  started,
+			Finished: started.Add(time.Minute),
+		}, []byte("$ ls\n"), []byte("0.1 5\n"))
+		c.Assert(err, gc.IsNil)
+	}
+
+	recordings, err := s.client.List("")
+	c.Assert(err, gc.IsNil)
+	c.Assert(recordings, gc.HasLen, 2)
+	recordings, err = s.client.List("wordpress/0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(recordings, gc.HasLen, 1)
+	c.Assert(recordings[0].Id, gc.Equals, "1")
+	c.Assert(recordings[0].Unit, gc.Equals, "wordpress/0")
+
+	data, err := s.client.Get("1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(data.Recording.Hook, gc.Equals, "install")
+	c.Assert(string(data.Transcript), gc.Equals, "$ ls\n")
+	c.Assert(string(data.Timing), gc.Equals, "0.1 5\n")
+
+	_, err = s.client.Get("2")
+	c.Assert(err, gc.ErrorMatches, `debug recording "2" not found`)
+}
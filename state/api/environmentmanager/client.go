@@ -0,0 +1,50 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environmentmanager
+
+import (
+	"github.com/juju/juju/state/api"
+	"github.com/juju/juju/state/api/params"
+)
+
+// Client provides access to the EnvironmentManager API facade.
+type Client struct {
+	st *api.State
+}
+
+// NewClient returns a new EnvironmentManager client using the
+// given API connection.
+func NewClient(st *api.State) *Client {
+	return &Client{st}
+}
+
+// Close closes the underlying API connection.
+func (c *Client) Close() error {
+	return c.st.Close()
+}
+
+// CreateEnvironment creates a new environment hosted by the
+// state server, with the given name. The given attributes override
+// those of the state server's own environment.
+func (c *Client) CreateEnvironment(name string, attrs map[string]interface{}) (params.EnvironmentInfo, error) {
+	var result params.EnvironmentInfo
+	args := params.EnvironmentCreateArgs{
+		Name:   name,
+		Config: attrs,
+	}
+	if err := c.st.Call("EnvironmentManager", "", "CreateEnvironment", args, &result); err != nil {
+		return params.EnvironmentInfo{}, err
+	}
+	return result, nil
+}
+
+// ListEnvironments returns the state server's own environment,
+// followed by the environments it hosts.
+func (c *Client) ListEnvironments() ([]params.EnvironmentInfo, error) {
+	var result params.EnvironmentInfoList
+	if err := c.st.Call("EnvironmentManager", "", "ListEnvironments", nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Environments, nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environmentmanager_test
+
+import (
+	gc "launchpad.net/gocheck"
+
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state/api/environmentmanager"
+)
+
+type environmentManagerSuite struct {
+	jujutesting.JujuConnSuite
+
+	client *environmentmanager.Client
+}
+
+var _ = gc.Suite(&environmentManagerSuite{})
+
+func (s *environmentManagerSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.client = environmentmanager.NewClient(s.APIState)
+}
+
+func (s *environmentManagerSuite) TestCreateEnvironment(c *gc.C) {
+	info, err := s.client.CreateEnvironment("sandbox", map[string]interface{}{
+		"default-series": "trusty",
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.Name, gc.Equals, "sandbox")
+	c.Assert(info.UUID, gc.HasLen, 36)
+	c.Assert(info.OwnerTag, gc.Equals, "user-admin")
+
+	st, err := s.State.ForEnviron(info.UUID)
+	c.Assert(err, gc.IsNil)
+	defer st.Close()
+	cfg, err := st.EnvironConfig()
+	c.Assert(err, gc.IsNil)
+	c.Assert(cfg.Name(), gc.Equals, "sandbox")
+	series, _ := cfg.DefaultSeries()
+	c.Assert(series, gc.Equals, "trusty")
+}
+
+func (s *environmentManagerSuite) TestListEnvironments(c *gc.C) {
+	created, err := s.client.CreateEnvironment("sandbox", nil)
+	c.Assert(err, gc.IsNil)
+
+	envs, err := s.client.ListEnvironments()
+	c.Assert(err, gc.IsNil)
+	c.Assert(envs, gc.HasLen, 2)
+	serverEnv, err := s.State.Environment()
+	c.Assert(err, gc.IsNil)
+	c.Assert(envs[0].Name, gc.Equals, serverEnv.Name())
+	c.Assert(envs[0].UUID, gc.Equals, serverEnv.UUID())
+	c.Assert(envs[1], gc.DeepEquals, created)
+}
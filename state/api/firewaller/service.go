@@ -89,3 +89,25 @@ func (s *Service) IsExposed() (bool, error) {
 	}
 	return result.Result, nil
 }
+
+// ExposedCIDRs returns the source CIDRs from which the ports of the
+// exposed service may be accessed. If it returns no CIDRs, the ports
+// may be accessed from anywhere.
+func (s *Service) ExposedCIDRs() ([]string, error) {
+	var results params.StringsResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: s.tag}},
+	}
+	err := s.st.call("GetExposedCIDRs", args, &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return result.Result, nil
+}
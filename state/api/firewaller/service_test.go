@@ -89,3 +89,16 @@ func (s *serviceSuite) TestIsExposed(c *gc.C) {
 	c.Assert(err, gc.IsNil)
 	c.Assert(isExposed, jc.IsFalse)
 }
+
+func (s *serviceSuite) TestExposedCIDRs(c *gc.C) {
+	cidrs, err := s.apiService.ExposedCIDRs()
+	c.Assert(err, gc.IsNil)
+	c.Assert(cidrs, gc.HasLen, 0)
+
+	err = s.service.SetExposedTo([]string{"10.0.0.0/8", "192.168.0.0/16"})
+	c.Assert(err, gc.IsNil)
+
+	cidrs, err = s.apiService.ExposedCIDRs()
+	c.Assert(err, gc.IsNil)
+	c.Assert(cidrs, gc.DeepEquals, []string{"10.0.0.0/8", "192.168.0.0/16"})
+}
@@ -104,6 +104,29 @@ func (u *Unit) OpenedPorts() ([]network.Port, error) {
 	return result.Ports, nil
 }
 
+// OpenedPortRanges returns the list of port ranges opened by this unit.
+//
+// NOTE: This differs from state.Unit.OpenedPortRanges() by returning
+// an error as well, because it needs to make an API call.
+func (u *Unit) OpenedPortRanges() ([]network.PortRange, error) {
+	var results params.PortRangesResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag}},
+	}
+	err := u.st.call("OpenedPortRanges", args, &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return result.PortRanges, nil
+}
+
 // AssignedMachine returns the tag of this unit's assigned machine (if
 // any), or a CodeNotAssigned error.
 func (u *Unit) AssignedMachine() (string, error) {
@@ -111,6 +111,20 @@ func (s *unitSuite) TestOpenedPorts(c *gc.C) {
 	c.Assert(ports, jc.DeepEquals, []network.Port{{"bar", 4321}, {"foo", 1234}})
 }
 
+func (s *unitSuite) TestOpenedPortRanges(c *gc.C) {
+	ports, err := s.apiUnit.OpenedPortRanges()
+	c.Assert(err, gc.IsNil)
+	c.Assert(ports, gc.HasLen, 0)
+
+	err = s.units[0].OpenPorts("tcp", 8000, 8080)
+	c.Assert(err, gc.IsNil)
+	ports, err = s.apiUnit.OpenedPortRanges()
+	c.Assert(err, gc.IsNil)
+	c.Assert(ports, jc.DeepEquals, []network.PortRange{
+		{Protocol: "tcp", FromPort: 8000, ToPort: 8080},
+	})
+}
+
 func (s *unitSuite) TestService(c *gc.C) {
 	service, err := s.apiUnit.Service()
 	c.Assert(err, gc.IsNil)
@@ -73,3 +73,23 @@ func (st *State) WatchLoggingConfig(agentTag string) (watcher.NotifyWatcher, err
 	w := watcher.NewNotifyWatcher(st.caller, result)
 	return w, nil
 }
+
+// WriteLogs sends the given log records to be stored in state. All the
+// records must have been logged by the agent using the API connection.
+func (st *State) WriteLogs(records []params.LogRecord) error {
+	var results params.ErrorResults
+	args := params.LogRecords{Records: records}
+	err := st.call("WriteLogs", args, &results)
+	if err != nil {
+		return err
+	}
+	if len(results.Results) != len(records) {
+		return fmt.Errorf("expected %d results, got %d", len(records), len(results.Results))
+	}
+	for _, result := range results.Results {
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
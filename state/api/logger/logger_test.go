@@ -4,13 +4,18 @@
 package logger_test
 
 import (
+	"time"
+
+	"github.com/juju/loggo"
 	gc "launchpad.net/gocheck"
 
 	jujutesting "github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/api"
 	"github.com/juju/juju/state/api/logger"
+	"github.com/juju/juju/state/api/params"
 	"github.com/juju/juju/state/testing"
+	coretesting "github.com/juju/juju/testing"
 )
 
 type loggerSuite struct {
@@ -76,3 +81,36 @@ func (s *loggerSuite) TestWatchLoggingConfig(c *gc.C) {
 	testing.AssertStop(c, watcher)
 	wc.AssertClosed()
 }
+
+func (s *loggerSuite) TestWriteLogs(c *gc.C) {
+	err := s.logger.WriteLogs([]params.LogRecord{{
+		Entity:   s.rawMachine.Tag(),
+		Time:     time.Now(),
+		Module:   "juju.worker.machiner",
+		Location: "machiner.go:54",
+		Level:    loggo.INFO,
+		Message:  "machine is alive",
+	}})
+	c.Assert(err, gc.IsNil)
+
+	t := s.BackingState.NewLogTailer(state.LogTailerParams{})
+	defer t.Stop()
+	select {
+	case record := <-t.Logs():
+		c.Assert(record.Entity, gc.Equals, s.rawMachine.Tag())
+		c.Assert(record.Module, gc.Equals, "juju.worker.machiner")
+		c.Assert(record.Message, gc.Equals, "machine is alive")
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for log record")
+	}
+}
+
+func (s *loggerSuite) TestWriteLogsWrongMachine(c *gc.C) {
+	err := s.logger.WriteLogs([]params.LogRecord{{
+		Entity:  "machine-42",
+		Module:  "juju.worker.machiner",
+		Level:   loggo.INFO,
+		Message: "not mine",
+	}})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
@@ -0,0 +1,59 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package metricsmanager
+
+import (
+	"github.com/juju/juju/state/api/base"
+	"github.com/juju/juju/state/api/params"
+)
+
+// State provides access to the MetricsManager API facade.
+type State struct {
+	caller base.Caller
+}
+
+// NewState returns a version of the state that provides access to
+// the metrics reported by units.
+func NewState(caller base.Caller) *State {
+	return &State{caller}
+}
+
+func (st *State) call(method string, params, result interface{}) error {
+	return st.caller.Call("MetricsManager", "", method, params, result)
+}
+
+// MetricBatches returns all the metric batches in the environment,
+// oldest first.
+func (st *State) MetricBatches() ([]params.MetricBatch, error) {
+	var result params.MetricBatchesResult
+	if err := st.call("MetricBatches", nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Batches, nil
+}
+
+// ExportMetricBatches returns the metric batches that have not yet
+// been exported, oldest first. Once they have been delivered, the
+// batches should be acknowledged with AcknowledgeMetricBatches;
+// until then they are exported again by later calls.
+func (st *State) ExportMetricBatches() ([]params.MetricBatch, error) {
+	var result params.MetricBatchesResult
+	if err := st.call("ExportMetricBatches", nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Batches, nil
+}
+
+// AcknowledgeMetricBatches marks the metric batches with the given
+// UUIDs as exported.
+func (st *State) AcknowledgeMetricBatches(uuids []string) error {
+	args := params.MetricBatchUUIDs{UUIDs: uuids}
+	return st.call("AcknowledgeMetricBatches", args, nil)
+}
+
+// CleanupOldMetrics removes the exported metric batches that are old
+// enough to be expired.
+func (st *State) CleanupOldMetrics() error {
+	return st.call("CleanupOldMetrics", nil, nil)
+}
@@ -0,0 +1,98 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package metricsmanager_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	gc "launchpad.net/gocheck"
+
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/metricsmanager"
+)
+
+type metricsManagerSuite struct {
+	jujutesting.JujuConnSuite
+
+	client  *metricsmanager.State
+	manager *metricsmanager.State
+	unit    *state.Unit
+}
+
+var _ = gc.Suite(&metricsManagerSuite{})
+
+func (s *metricsManagerSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.client = metricsmanager.NewState(s.APIState)
+
+	machine, err := s.State.AddMachine("quantal", state.JobManageEnviron)
+	c.Assert(err, gc.IsNil)
+	password, err := utils.RandomPassword()
+	c.Assert(err, gc.IsNil)
+	err = machine.SetPassword(password)
+	c.Assert(err, gc.IsNil)
+	err = machine.SetProvisioned("i-manager", "fake_nonce", nil)
+	c.Assert(err, gc.IsNil)
+	st := s.OpenAPIAsMachine(c, machine.Tag(), password, "fake_nonce")
+	s.manager = st.MetricsManager()
+
+	ch := s.AddTestingCharm(c, "wordpress")
+	svc := s.AddTestingService(c, "wordpress", ch)
+	s.unit, err = svc.AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = s.unit.SetCharmURL(ch.URL())
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *metricsManagerSuite) TestExportMetricBatches(c *gc.C) {
+	now := time.Now()
+	batch, err := s.unit.AddMetrics(now, []state.Metric{{Key: "pings", Value: "5", Time: now}})
+	c.Assert(err, gc.IsNil)
+
+	batches, err := s.client.MetricBatches()
+	c.Assert(err, gc.IsNil)
+	c.Assert(batches, gc.HasLen, 1)
+	c.Assert(batches[0].UUID, gc.Equals, batch.UUID())
+	c.Assert(batches[0].Sent, jc.IsFalse)
+
+	batches, err = s.client.ExportMetricBatches()
+	c.Assert(err, gc.IsNil)
+	c.Assert(batches, gc.HasLen, 1)
+	c.Assert(batches[0].UUID, gc.Equals, batch.UUID())
+	c.Assert(batches[0].Unit, gc.Equals, "wordpress/0")
+	c.Assert(batches[0].Metrics, gc.HasLen, 1)
+	c.Assert(batches[0].Metrics[0].Key, gc.Equals, "pings")
+
+	// Batches are exported until they are acknowledged.
+	batches, err = s.client.ExportMetricBatches()
+	c.Assert(err, gc.IsNil)
+	c.Assert(batches, gc.HasLen, 1)
+	err = s.client.AcknowledgeMetricBatches([]string{batch.UUID()})
+	c.Assert(err, gc.IsNil)
+	batches, err = s.client.ExportMetricBatches()
+	c.Assert(err, gc.IsNil)
+	c.Assert(batches, gc.HasLen, 0)
+}
+
+func (s *metricsManagerSuite) TestCleanupOldMetrics(c *gc.C) {
+	created := time.Now().Add(-2 * state.MetricsCleanupAge)
+	batch, err := s.unit.AddMetrics(created, []state.Metric{{Key: "pings", Value: "5", Time: created}})
+	c.Assert(err, gc.IsNil)
+	err = batch.SetSent()
+	c.Assert(err, gc.IsNil)
+
+	err = s.client.CleanupOldMetrics()
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	_, err = s.manager.MetricBatches()
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+
+	err = s.manager.CleanupOldMetrics()
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.MetricBatch(batch.UUID())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
@@ -33,20 +33,22 @@ func (e Error) GoString() string {
 
 // The Code constants hold error codes for some kinds of error.
 const (
-	CodeNotFound            = "not found"
-	CodeUnauthorized        = "unauthorized access"
-	CodeCannotEnterScope    = "cannot enter scope"
-	CodeCannotEnterScopeYet = "cannot enter scope yet"
-	CodeExcessiveContention = "excessive contention"
-	CodeUnitHasSubordinates = "unit has subordinates"
-	CodeNotAssigned         = "not assigned"
-	CodeStopped             = "stopped"
-	CodeHasAssignedUnits    = "machine has assigned units"
-	CodeNotProvisioned      = "not provisioned"
-	CodeNoAddressSet        = "no address set"
-	CodeTryAgain            = "try again"
-	CodeNotImplemented      = rpc.CodeNotImplemented
-	CodeAlreadyExists       = "already exists"
+	CodeNotFound              = "not found"
+	CodeUnauthorized          = "unauthorized access"
+	CodeCannotEnterScope      = "cannot enter scope"
+	CodeCannotEnterScopeYet   = "cannot enter scope yet"
+	CodeExcessiveContention   = "excessive contention"
+	CodeUnitHasSubordinates   = "unit has subordinates"
+	CodeNotAssigned           = "not assigned"
+	CodeStopped               = "stopped"
+	CodeHasAssignedUnits      = "machine has assigned units"
+	CodeNotProvisioned        = "not provisioned"
+	CodeNoAddressSet          = "no address set"
+	CodeTryAgain              = "try again"
+	CodeNotImplemented        = rpc.CodeNotImplemented
+	CodeAlreadyExists         = "already exists"
+	CodeLeadershipClaimDenied = "leadership claim denied"
+	CodeNotLeader             = "not leader"
 )
 
 // ErrCode returns the error code associated with
@@ -140,3 +142,11 @@ func IsCodeNotImplemented(err error) bool {
 func IsCodeAlreadyExists(err error) bool {
 	return ErrCode(err) == CodeAlreadyExists
 }
+
+func IsCodeLeadershipClaimDenied(err error) bool {
+	return ErrCode(err) == CodeLeadershipClaimDenied
+}
+
+func IsCodeNotLeader(err error) bool {
+	return ErrCode(err) == CodeNotLeader
+}
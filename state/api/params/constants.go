@@ -39,6 +39,22 @@ const (
 	ResolvedNoHooks    ResolvedMode = "no-hooks"
 )
 
+// ActionStatus describes the progress of an action.
+type ActionStatus string
+
+const (
+	// ActionPending indicates that the action is queued and has
+	// not yet finished running.
+	ActionPending ActionStatus = "pending"
+
+	// ActionCompleted indicates that the action ran to completion.
+	ActionCompleted ActionStatus = "complete"
+
+	// ActionFailed indicates that the action failed, or could not
+	// be run.
+	ActionFailed ActionStatus = "fail"
+)
+
 // Status represents the status of an entity.
 // It could be a unit, machine or its agent.
 type Status string
@@ -82,3 +98,56 @@ func (status Status) Valid() bool {
 	}
 	return true
 }
+
+// AgentActivity describes what a unit's agent is doing. It is
+// reported alongside the agent's status, which remains "started"
+// throughout.
+type AgentActivity string
+
+const (
+	// The agent is waiting for something to do.
+	ActivityIdle AgentActivity = "idle"
+
+	// The agent is running a hook.
+	ActivityExecuting AgentActivity = "executing"
+)
+
+// WorkloadStatus represents the status of the workload of a unit, as
+// reported by its charm. It is distinct from the status of the unit's
+// agent.
+type WorkloadStatus string
+
+const (
+	// The charm has not reported the status of the workload.
+	WorkloadUnknown WorkloadStatus = "unknown"
+
+	// The charm is performing work to set up or reconfigure the
+	// workload.
+	WorkloadMaintenance WorkloadStatus = "maintenance"
+
+	// The workload cannot proceed without intervention by the
+	// user, such as the addition of a relation.
+	WorkloadBlocked WorkloadStatus = "blocked"
+
+	// The workload is waiting on something outside its control,
+	// such as another service.
+	WorkloadWaiting WorkloadStatus = "waiting"
+
+	// The workload is ready and providing its service.
+	WorkloadActive WorkloadStatus = "active"
+)
+
+// Valid returns true if status has a known value.
+func (status WorkloadStatus) Valid() bool {
+	switch status {
+	case
+		WorkloadUnknown,
+		WorkloadMaintenance,
+		WorkloadBlocked,
+		WorkloadWaiting,
+		WorkloadActive:
+	default:
+		return false
+	}
+	return true
+}
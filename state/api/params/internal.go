@@ -6,11 +6,13 @@ package params
 import (
 	"time"
 
+	"github.com/juju/loggo"
 	"github.com/juju/utils/exec"
 
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/network"
+	"github.com/juju/juju/storage"
 	"github.com/juju/juju/tools"
 	"github.com/juju/juju/version"
 )
@@ -63,6 +65,19 @@ type PortsResults struct {
 	Results []PortsResult
 }
 
+// PortRangesResults holds the bulk operation result of an API call
+// that returns a slice of network.PortRange.
+type PortRangesResults struct {
+	Results []PortRangesResult
+}
+
+// PortRangesResult holds the result of an API call that returns
+// a slice of network.PortRange or an error.
+type PortRangesResult struct {
+	Error      *Error
+	PortRanges []network.PortRange
+}
+
 // PortsResult holds the result of an API call that returns a slice
 // of network.Port or an error.
 type PortsResult struct {
@@ -260,6 +275,21 @@ type EntitiesPorts struct {
 	Entities []EntityPort
 }
 
+// EntityPortRange holds an entity's tag, a protocol and
+// a range of ports.
+type EntityPortRange struct {
+	Tag      string
+	Protocol string
+	FromPort int
+	ToPort   int
+}
+
+// EntitiesPortRanges holds the parameters for making an OpenPorts
+// or ClosePorts call on some entities.
+type EntitiesPortRanges struct {
+	Entities []EntityPortRange
+}
+
 // EntityCharmURL holds an entity's tag and a charm URL.
 type EntityCharmURL struct {
 	Tag      string
@@ -416,6 +446,47 @@ type StatusResults struct {
 	Results []StatusResult
 }
 
+// EntityWorkloadStatus holds a unit tag and the status
+// of its workload.
+type EntityWorkloadStatus struct {
+	Tag    string
+	Status WorkloadStatus
+	Info   string
+}
+
+// SetWorkloadStatus holds the parameters for making a
+// SetWorkloadStatus call.
+type SetWorkloadStatus struct {
+	Entities []EntityWorkloadStatus
+}
+
+// WorkloadStatusResult holds the status of a unit's
+// workload, or an error.
+type WorkloadStatusResult struct {
+	Error  *Error
+	Status WorkloadStatus
+	Info   string
+}
+
+// WorkloadStatusResults holds multiple workload status results.
+type WorkloadStatusResults struct {
+	Results []WorkloadStatusResult
+}
+
+// EntityAgentActivity holds a unit tag, what the unit's agent is
+// doing and, if it is running a hook, which one.
+type EntityAgentActivity struct {
+	Tag      string
+	Activity AgentActivity
+	Hook     string
+}
+
+// SetAgentActivity holds the parameters for making a
+// SetAgentActivity call.
+type SetAgentActivity struct {
+	Entities []EntityAgentActivity
+}
+
 // MachineAddresses holds an machine tag and addresses.
 type MachineAddresses struct {
 	Tag       string
@@ -617,6 +688,11 @@ type ProvisioningInfo struct {
 	Series      string
 	Placement   string
 	Networks    []string
+	Volumes     []storage.VolumeParams
+	// SubnetsToZones maps the provider ids of the subnets in the
+	// spaces required by the machine's constraints to the
+	// availability zones they are usable in.
+	SubnetsToZones map[string][]string
 }
 
 // ProvisioningInfoResult holds machine provisioning info or an error.
@@ -629,3 +705,263 @@ type ProvisioningInfoResult struct {
 type ProvisioningInfoResults struct {
 	Results []ProvisioningInfoResult
 }
+
+// LogRecord holds a single log message written by an agent.
+type LogRecord struct {
+	// Entity holds the tag of the agent that logged the message.
+	Entity   string
+	Time     time.Time
+	Module   string
+	Location string
+	Level    loggo.Level
+	Message  string
+}
+
+// LogRecords holds the log messages sent by an agent
+// to be stored in state.
+type LogRecords struct {
+	Records []LogRecord
+}
+
+// ClaimLeadership holds the arguments for claiming, or extending,
+// the leadership of a unit's service on behalf of the unit.
+type ClaimLeadership struct {
+	UnitTag string
+	// DurationSeconds holds the time for which
+	// the unit will remain leader.
+	DurationSeconds float64
+}
+
+// ClaimLeadershipParams holds the arguments for
+// making a Leadership.ClaimLeadership call.
+type ClaimLeadershipParams struct {
+	Params []ClaimLeadership
+}
+
+// MergeLeaderSettings holds the settings a unit writes as leader
+// of its service. Settings with empty values are removed.
+type MergeLeaderSettings struct {
+	UnitTag  string
+	Settings map[string]string
+}
+
+// MergeLeaderSettingsParams holds the arguments for
+// making a Leadership.MergeLeaderSettings call.
+type MergeLeaderSettingsParams struct {
+	Params []MergeLeaderSettings
+}
+
+// LeaderSettingsResult holds the settings written
+// by the leader of a service, or an error.
+type LeaderSettingsResult struct {
+	Settings map[string]string
+	Error    *Error
+}
+
+// LeaderSettingsResults holds the results of
+// a Leadership.LeaderSettings call.
+type LeaderSettingsResults struct {
+	Results []LeaderSettingsResult
+}
+
+// StorageInstance describes a storage instance owned by a unit.
+type StorageInstance struct {
+	Id       string
+	Name     string
+	Kind     string
+	Location string
+}
+
+// StorageInstancesResult holds the storage instances
+// owned by a unit, or an error.
+type StorageInstancesResult struct {
+	Result []StorageInstance
+	Error  *Error
+}
+
+// StorageInstancesResults holds the results of
+// a Storage.StorageInstances call.
+type StorageInstancesResults struct {
+	Results []StorageInstancesResult
+}
+
+// VolumeAttachmentId identifies the attachment
+// of a volume to a machine.
+type VolumeAttachmentId struct {
+	MachineTag string
+	Volume     string
+}
+
+// VolumeAttachmentIds holds the arguments for making StorageProvisioner
+// VolumeAttachments and RemoveVolumes calls.
+type VolumeAttachmentIds struct {
+	Ids []VolumeAttachmentId
+}
+
+// VolumeAttachment describes the attachment of a volume to
+// a machine. VolumeId and DeviceName are only set once the
+// volume has been provisioned. If Kind is storage.KindFilesystem,
+// a filesystem is to be created on the volume and mounted;
+// MountPoint is set once it has been.
+type VolumeAttachment struct {
+	Params      storage.VolumeParams
+	Life        Life
+	Kind        storage.Kind
+	Provisioned bool
+	VolumeId    string
+	DeviceName  string
+	MountPoint  string
+}
+
+// VolumeAttachmentResult holds a volume attachment or an error.
+type VolumeAttachmentResult struct {
+	Result VolumeAttachment
+	Error  *Error
+}
+
+// VolumeAttachmentResults holds the results of
+// a StorageProvisioner.VolumeAttachments call.
+type VolumeAttachmentResults struct {
+	Results []VolumeAttachmentResult
+}
+
+// VolumeNames holds the arguments for making Provisioner
+// Volumes and RemoveVolumes calls.
+type VolumeNames struct {
+	Names []string
+}
+
+// Volume describes a volume and whether it is still attached to
+// a machine. VolumeId is only set once the volume has been
+// provisioned.
+type Volume struct {
+	Params      storage.VolumeParams
+	Life        Life
+	Provisioned bool
+	VolumeId    string
+	Attached    bool
+}
+
+// VolumeResult holds a volume or an error.
+type VolumeResult struct {
+	Result Volume
+	Error  *Error
+}
+
+// VolumeResults holds the results of a Provisioner.Volumes call.
+type VolumeResults struct {
+	Results []VolumeResult
+}
+
+// VolumeInfo holds the details of a provisioned
+// volume and its attachment to a machine.
+type VolumeInfo struct {
+	MachineTag string
+	Volume     storage.Volume
+	Attachment storage.VolumeAttachment
+}
+
+// VolumesInfo holds the arguments for making a SetVolumeInfo call.
+type VolumesInfo struct {
+	Volumes []VolumeInfo
+}
+
+// FilesystemInfo holds the mount point of the filesystem
+// created on a volume attached to a machine.
+type FilesystemInfo struct {
+	MachineTag string
+	Volume     string
+	MountPoint string
+}
+
+// FilesystemsInfo holds the arguments for making a
+// SetFilesystemInfo call.
+type FilesystemsInfo struct {
+	Filesystems []FilesystemInfo
+}
+
+// RemoteRelation describes a relation between a local service and a
+// remote service, within the environment with the given UUID.
+type RemoteRelation struct {
+	EnvUUID       string
+	Key           string
+	Life          Life
+	RemoteService string
+	SourceEnvUUID string
+	// Consumer is true when the remote service was added to
+	// consume an offer, rather than to stand for a service
+	// consuming one.
+	Consumer bool
+}
+
+// RemoteRelationsResult holds the result of a
+// RemoteRelations.RemoteRelations call.
+type RemoteRelationsResult struct {
+	Relations []RemoteRelation
+}
+
+// RemoteRelationId identifies a relation by its key, within the
+// environment with the given UUID.
+type RemoteRelationId struct {
+	EnvUUID string
+	Key     string
+}
+
+// RemoteRelationIds holds the arguments for API calls
+// expecting multiple relations.
+type RemoteRelationIds struct {
+	Relations []RemoteRelationId
+}
+
+// RemoteRelationUnit holds the name of a unit in the scope of a
+// relation and its settings within it.
+type RemoteRelationUnit struct {
+	Unit     string
+	Settings RelationSettings
+}
+
+// RemoteRelationUnitsResult holds the units in the scope of a
+// relation, divided into the units of its local service and those of
+// its remote service, or an error.
+type RemoteRelationUnitsResult struct {
+	Error       *Error
+	Life        Life
+	LocalUnits  []RemoteRelationUnit
+	RemoteUnits []RemoteRelationUnit
+}
+
+// RemoteRelationUnitsResults holds the result of a
+// RemoteRelations.RelationUnits call.
+type RemoteRelationUnitsResults struct {
+	Results []RemoteRelationUnitsResult
+}
+
+// RemoteRelationUnitChange holds a unit of a remote service
+// entering or leaving the scope of a relation, within the
+// environment with the given UUID.
+type RemoteRelationUnitChange struct {
+	EnvUUID  string
+	Key      string
+	Unit     string
+	Settings RelationSettings
+}
+
+// RemoteRelationUnitChanges holds the arguments for making
+// RemoteRelations.EnterScope and LeaveScope calls.
+type RemoteRelationUnitChanges struct {
+	Changes []RemoteRelationUnitChange
+}
+
+// MetricBatchParam holds a unit tag and a batch of metrics
+// reported by the unit.
+type MetricBatchParam struct {
+	Tag     string
+	Created time.Time
+	Metrics []Metric
+}
+
+// MetricBatchParams holds the parameters for making an
+// AddMetricBatches call.
+type MetricBatchParams struct {
+	Batches []MetricBatchParam
+}
@@ -13,8 +13,10 @@ import (
 	"github.com/juju/utils/proxy"
 
 	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/network"
+	"github.com/juju/juju/storage"
 	"github.com/juju/juju/utils/ssh"
 	"github.com/juju/juju/version"
 )
@@ -87,6 +89,19 @@ type DestroyRelation struct {
 	Endpoints []string
 }
 
+// ServiceOffer holds the parameters for making the ServiceOffer call.
+// If no endpoints are specified, all the service's endpoints that can
+// be related to from other environments are offered.
+type ServiceOffer struct {
+	ServiceName string
+	Endpoints   []string
+}
+
+// ServiceOfferResult holds the result of a ServiceOffer call.
+type ServiceOfferResult struct {
+	URL string
+}
+
 // AddMachineParams encapsulates the parameters used to create a new machine.
 type AddMachineParams struct {
 	// The following fields hold attributes that will be given to the
@@ -158,6 +173,10 @@ type ServiceDeploy struct {
 	Constraints   constraints.Value
 	ToMachineSpec string
 	Networks      []string
+	Storage       map[string]storage.Directive
+	// EndpointBindings maps relation endpoint names to the names
+	// of the spaces they are bound to.
+	EndpointBindings map[string]string
 }
 
 // ServiceUpdate holds the parameters for making the ServiceUpdate call.
@@ -183,6 +202,13 @@ type ServiceExpose struct {
 	ServiceName string
 }
 
+// ServiceExposeTo holds the parameters for making the
+// ServiceExposeTo call.
+type ServiceExposeTo struct {
+	ServiceName string
+	CIDRs       []string
+}
+
 // ServiceSet holds the parameters for a ServiceSet
 // command. Options contains the configuration data.
 type ServiceSet struct {
@@ -279,6 +305,7 @@ type AddServiceUnits struct {
 	ServiceName   string
 	NumUnits      int
 	ToMachineSpec string
+	Storage       map[string]storage.Directive
 }
 
 // DestroyServiceUnits holds parameters for the DestroyUnits call.
@@ -398,6 +425,19 @@ type ModifyUser struct {
 	Password    string
 }
 
+// ModifyUserAccesses holds the parameters for making UserManager
+// GrantAccess or RevokeAccess calls.
+type ModifyUserAccesses struct {
+	Changes []ModifyUserAccess
+}
+
+// ModifyUserAccess holds the tag of a user and the access level
+// (read, write or admin) to grant or revoke.
+type ModifyUserAccess struct {
+	Tag    string
+	Access string
+}
+
 // MarshalJSON implements json.Marshaler.
 func (d *Delta) MarshalJSON() ([]byte, error) {
 	b, err := json.Marshal(d.Entity)
@@ -539,14 +579,15 @@ func (i *MachineInfo) EntityId() EntityId {
 }
 
 type ServiceInfo struct {
-	Name        string `bson:"_id"`
-	Exposed     bool
-	CharmURL    string
-	OwnerTag    string
-	Life        Life
-	MinUnits    int
-	Constraints constraints.Value
-	Config      map[string]interface{}
+	Name         string `bson:"_id"`
+	Exposed      bool
+	ExposedCIDRs []string
+	CharmURL     string
+	OwnerTag     string
+	Life         Life
+	MinUnits     int
+	Constraints  constraints.Value
+	Config       map[string]interface{}
 }
 
 func (i *ServiceInfo) EntityId() EntityId {
@@ -564,10 +605,26 @@ type UnitInfo struct {
 	PublicAddress  string
 	PrivateAddress string
 	MachineId      string
-	Ports          []network.Port
-	Status         Status
-	StatusInfo     string
-	StatusData     StatusData
+
+	// PortRanges holds the port ranges opened by the unit. Ports
+	// holds those of them that hold a single port, for clients
+	// that do not know about port ranges.
+	Ports      []network.Port
+	PortRanges []network.PortRange
+
+	Status     Status
+	StatusInfo string
+	StatusData StatusData
+
+	// AgentActivity and ActivityHook hold what the unit's agent
+	// is doing and, if it is running a hook, which one.
+	AgentActivity AgentActivity
+	ActivityHook  string
+
+	// WorkloadStatus and WorkloadStatusInfo hold the status
+	// of the unit's workload, as reported by its charm.
+	WorkloadStatus     WorkloadStatus
+	WorkloadStatusInfo string
 }
 
 func (i *UnitInfo) EntityId() EntityId {
@@ -627,6 +684,13 @@ type ContainerConfig struct {
 	SSLHostnameVerification bool
 	Proxy                   proxy.Settings
 	AptProxy                proxy.Settings
+	AptMirror               string
+	PackageSources          []config.PackageSource
+
+	// DisableOSUpgrade is set rather than an "enable" flag so
+	// that servers that do not know about it upgrade containers,
+	// as before.
+	DisableOSUpgrade bool
 }
 
 // ProvisioningScriptParams contains the parameters for the
@@ -674,6 +738,32 @@ type SetEnvironAgentVersion struct {
 	Version version.Number
 }
 
+// RotateCertificates contains the arguments for the
+// RotateCertificates client API call.
+type RotateCertificates struct {
+	// NewCA specifies that a new CA certificate should be generated
+	// and trusted alongside the current one. The state server
+	// certificate is left unchanged.
+	NewCA bool
+
+	// CAPrivateKey holds the private key of the CA that will sign
+	// the new state server certificate. It is ignored when NewCA
+	// is set.
+	CAPrivateKey string
+}
+
+// RotateCertificatesResult holds the result of a RotateCertificates
+// client API call.
+type RotateCertificatesResult struct {
+	// CACert holds the PEM-encoded CA certificates now trusted
+	// by the environment.
+	CACert string
+
+	// CAPrivateKey holds the private key of a newly generated CA.
+	// It is only set when a new CA was requested.
+	CAPrivateKey string
+}
+
 // DeployerConnectionValues containers the result of deployer.ConnectionInfo
 // API call.
 type DeployerConnectionValues struct {
@@ -727,6 +817,19 @@ type APIHostPortsResult struct {
 	Servers [][]network.HostPort
 }
 
+// ConnectionInfo holds the information needed to connect to
+// an environment's API servers, as returned by the Client's
+// ConnectionInfo call. It holds no secrets, so that it may be
+// shared with any user of the environment.
+type ConnectionInfo struct {
+	EnvironName string
+	EnvironUUID string
+	CACert      string
+
+	// Servers holds the addresses of each API server.
+	Servers [][]network.HostPort
+}
+
 // LoginResult holds the result of a Login call.
 type LoginResult struct {
 	Servers        [][]network.HostPort
@@ -750,6 +853,7 @@ type UserInfo struct {
 	CreatedBy      string    `json:created-by`
 	DateCreated    time.Time `json:date-created`
 	LastConnection time.Time `json:last-connection`
+	Access         string    `json:"access"`
 }
 
 // UserInfoResult holds the result of a UserInfo call.
@@ -761,3 +865,267 @@ type UserInfoResult struct {
 type UserInfoResults struct {
 	Results []UserInfoResult
 }
+
+// Action describes an action to be run, or that has been run, on a
+// unit. Id is assigned by the server when the action is queued.
+type Action struct {
+	Id     string
+	Unit   string
+	Name   string
+	Params map[string]interface{}
+}
+
+// Actions holds a list of actions.
+type Actions struct {
+	Actions []Action
+}
+
+// ActionResult holds an action and its current status. For actions
+// that have finished running, Results holds the values set by the
+// action and Message any descriptive text or failure reason.
+type ActionResult struct {
+	Action  Action
+	Status  ActionStatus
+	Results map[string]interface{}
+	Message string
+	Error   *Error
+}
+
+// ActionResults holds the results of a bulk action operation.
+type ActionResults struct {
+	Results []ActionResult
+}
+
+// ActionsByUnit holds the actions associated with a single unit.
+type ActionsByUnit struct {
+	Unit    string
+	Actions []ActionResult
+	Error   *Error
+}
+
+// ActionsByUnits holds the results of the ListActions and
+// ActionResults calls.
+type ActionsByUnits struct {
+	Results []ActionsByUnit
+}
+
+// BackupsCreateArgs holds the arguments to the Backups.Create call.
+type BackupsCreateArgs struct {
+	Notes string
+}
+
+// BackupsInfoArgs holds the arguments to the Backups.Info call.
+type BackupsInfoArgs struct {
+	ID string
+}
+
+// BackupsRemoveArgs holds the arguments to the Backups.Remove call.
+type BackupsRemoveArgs struct {
+	ID string
+}
+
+// BackupsRestoreArgs holds the arguments to the Backups.Restore call.
+type BackupsRestoreArgs struct {
+	ID string
+}
+
+// BackupsMetadataResult describes a single backup.
+type BackupsMetadataResult struct {
+	ID             string
+	Checksum       string
+	ChecksumFormat string
+	Size           int64
+	Stored         bool
+	Started        time.Time
+	Finished       time.Time
+	Notes          string
+	Environment    string
+	Machine        string
+	Hostname       string
+	Version        version.Number
+}
+
+// BackupsListResult holds the result of the Backups.List call.
+type BackupsListResult struct {
+	List []BackupsMetadataResult
+}
+
+// AuditLogFilter holds the arguments to the AuditLog.Entries call.
+// Empty fields match all entries. If Limit is greater than zero, at
+// most that many of the most recent matching entries are returned.
+type AuditLogFilter struct {
+	User   string
+	Entity string
+	After  time.Time
+	Before time.Time
+	Limit  int
+}
+
+// AuditLogEntry describes a single audited API call.
+type AuditLogEntry struct {
+	Time     time.Time
+	User     string
+	Facade   string
+	Method   string
+	Args     string
+	Entities []string
+	Error    string
+}
+
+// AuditLogResults holds the result of the AuditLog.Entries call.
+type AuditLogResults struct {
+	Entries []AuditLogEntry
+}
+
+// DebugRecording describes a recorded debug-hooks session.
+type DebugRecording struct {
+	Id       string
+	Unit     string
+	Hook     string
+	Started  time.Time
+	Finished time.Time
+	Size     int64
+}
+
+// MaxDebugRecordingSize is the largest size in bytes of the
+// transcript, and of the timings, of a debug-hooks recording
+// that the state server will store.
+const MaxDebugRecordingSize = 16 * 1024 * 1024
+
+// DebugRecordingData holds a recorded debug-hooks session along
+// with its transcript, and the transcript's timings as written by
+// script(1). Neither may be larger than MaxDebugRecordingSize.
+type DebugRecordingData struct {
+	Recording  DebugRecording
+	Transcript []byte
+	Timing     []byte
+}
+
+// DebugRecordingsAddArgs holds the arguments to the
+// DebugRecordings.Add call. The ids of the recordings are
+// ignored.
+type DebugRecordingsAddArgs struct {
+	Recordings []DebugRecordingData
+}
+
+// DebugRecordingsFilter holds the arguments to the
+// DebugRecordings.List call. An empty Unit matches all units.
+type DebugRecordingsFilter struct {
+	Unit string
+}
+
+// DebugRecordingsListResult holds the result of the
+// DebugRecordings.List call.
+type DebugRecordingsListResult struct {
+	Recordings []DebugRecording
+}
+
+// DebugRecordingsGetArgs holds the arguments to the
+// DebugRecordings.Get call.
+type DebugRecordingsGetArgs struct {
+	Id string
+}
+
+// EnvironmentCreateArgs holds the arguments to the
+// EnvironmentManager.CreateEnvironment call. Config holds
+// attributes that override those of the state server's own
+// environment, from which the new environment inherits its
+// provider credentials.
+type EnvironmentCreateArgs struct {
+	Name   string
+	Config map[string]interface{}
+}
+
+// EnvironmentInfo describes an environment hosted
+// by a state server.
+type EnvironmentInfo struct {
+	Name     string
+	UUID     string
+	OwnerTag string
+}
+
+// EnvironmentInfoList holds the result of the
+// EnvironmentManager.ListEnvironments call.
+type EnvironmentInfoList struct {
+	Environments []EnvironmentInfo
+}
+
+// Metric holds a single value reported by a unit's
+// collect-metrics hook.
+type Metric struct {
+	Key   string
+	Value string
+	Time  time.Time
+}
+
+// MetricBatch holds the metrics reported by a unit in a single run
+// of its collect-metrics hook.
+type MetricBatch struct {
+	UUID     string
+	Unit     string
+	CharmURL string
+	Sent     bool
+	Created  time.Time
+	Metrics  []Metric
+}
+
+// MetricBatchesResult holds the result of the
+// MetricsManager.MetricBatches and MetricsManager.ExportMetricBatches
+// calls.
+type MetricBatchesResult struct {
+	Batches []MetricBatch
+}
+
+// MetricBatchUUIDs holds the UUIDs of metric batches, as passed to
+// MetricsManager.AcknowledgeMetricBatches.
+type MetricBatchUUIDs struct {
+	UUIDs []string
+}
+
+// Subnet describes a single subnet known to juju.
+type Subnet struct {
+	CIDR              string
+	ProviderId        string
+	VLANTag           int
+	AvailabilityZones []string
+	SpaceName         string
+}
+
+// Space describes a space and the subnets it is made of.
+type Space struct {
+	Name    string
+	Subnets []Subnet
+}
+
+// CreateSpaceArgs holds the arguments of the Spaces.CreateSpace call.
+type CreateSpaceArgs struct {
+	Name        string
+	SubnetCIDRs []string
+}
+
+// ListSpacesResults holds the result of the Spaces.ListSpaces call.
+type ListSpacesResults struct {
+	Spaces []Space
+}
+
+// AddSubnetArgs holds the arguments of the Subnets.AddSubnet call.
+// Exactly one of SubnetCIDR and SubnetProviderId must be set; the
+// subnet's other details are discovered from the provider when it
+// supports it.
+type AddSubnetArgs struct {
+	SubnetCIDR       string
+	SubnetProviderId string
+	SpaceName        string
+}
+
+// ListSubnetsArgs holds the arguments of the Subnets.ListSubnets
+// call. When SpaceName is set, only the subnets in that space are
+// listed.
+type ListSubnetsArgs struct {
+	SpaceName string
+}
+
+// ListSubnetsResults holds the result of the Subnets.ListSubnets call.
+type ListSubnetsResults struct {
+	Subnets []Subnet
+}
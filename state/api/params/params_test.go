@@ -66,7 +66,7 @@ var marshalTestCases = []struct {
 			},
 		},
 	},
-	json: `["service","change",{"CharmURL": "cs:quantal/name","Name":"Benji","Exposed":true,"Life":"dying","OwnerTag":"test-owner","MinUnits":42,"Constraints":{"arch":"armhf", "mem": 1024},"Config": {"hello":"goodbye","foo":false}}]`,
+	json: `["service","change",{"CharmURL": "cs:quantal/name","Name":"Benji","Exposed":true,"ExposedCIDRs":null,"Life":"dying","OwnerTag":"test-owner","MinUnits":42,"Constraints":{"arch":"armhf", "mem": 1024},"Config": {"hello":"goodbye","foo":false}}]`,
 }, {
 	about: "UnitInfo Delta",
 	value: params.Delta{
@@ -85,9 +85,15 @@ var marshalTestCases = []struct {
 			MachineId:      "1",
 			Status:         "error",
 			StatusInfo:     "foo",
+
+			AgentActivity: "executing",
+			ActivityHook:  "config-changed",
+
+			WorkloadStatus:     "blocked",
+			WorkloadStatusInfo: "need a database",
 		},
 	},
-	json: `["unit", "change", {"CharmURL": "cs:~user/precise/wordpress-42", "MachineId": "1", "Series": "precise", "Name": "Benji", "PublicAddress": "testing.invalid", "Service": "Shazam", "PrivateAddress": "10.0.0.1", "Ports": [{"Protocol": "http", "Number": 80}], "Status": "error", "StatusInfo": "foo","StatusData":null}]`,
+	json: `["unit", "change", {"CharmURL": "cs:~user/precise/wordpress-42", "MachineId": "1", "Series": "precise", "Name": "Benji", "PublicAddress": "testing.invalid", "Service": "Shazam", "PrivateAddress": "10.0.0.1", "Ports": [{"Protocol": "http", "Number": 80}], "PortRanges": null, "Status": "error", "StatusInfo": "foo","StatusData":null, "AgentActivity": "executing", "ActivityHook": "config-changed", "WorkloadStatus": "blocked", "WorkloadStatusInfo": "need a database"}]`,
 }, {
 	about: "RelationInfo Delta",
 	value: params.Delta{
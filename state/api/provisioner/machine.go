@@ -11,6 +11,7 @@ import (
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/state/api/params"
 	"github.com/juju/juju/state/api/watcher"
+	"github.com/juju/juju/storage"
 )
 
 // Machine represents a juju machine as seen by the provisioner worker.
@@ -206,6 +207,40 @@ func (m *Machine) SetInstanceInfo(
 	return result.OneError()
 }
 
+// SetVolumeInfo records the provider details of volumes created
+// for this machine, and of their attachments to it. Each volume
+// must be followed by its attachment at the same index.
+func (m *Machine) SetVolumeInfo(volumes []storage.Volume, attachments []storage.VolumeAttachment) error {
+	if len(volumes) != len(attachments) {
+		return fmt.Errorf("expected %d volume attachments, got %d", len(volumes), len(attachments))
+	}
+	if len(volumes) == 0 {
+		return nil
+	}
+	info := make([]params.VolumeInfo, len(volumes))
+	for i, volume := range volumes {
+		info[i] = params.VolumeInfo{
+			MachineTag: m.tag,
+			Volume:     volume,
+			Attachment: attachments[i],
+		}
+	}
+	var results params.ErrorResults
+	args := params.VolumesInfo{Volumes: info}
+	if err := m.st.call("SetVolumeInfo", args, &results); err != nil {
+		return err
+	}
+	if len(results.Results) != len(info) {
+		return fmt.Errorf("expected %d results, got %d", len(info), len(results.Results))
+	}
+	for _, result := range results.Results {
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
 // InstanceId returns the provider specific instance id for the
 // machine or an CodeNotProvisioned error, if not set.
 func (m *Machine) InstanceId() (instance.Id, error) {
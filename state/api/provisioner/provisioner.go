@@ -151,3 +151,45 @@ func (st *State) MachinesWithTransientErrors() ([]*Machine, []params.StatusResul
 	}
 	return machines, results.Results, nil
 }
+
+// WatchVolumes returns a StringsWatcher that notifies of changes
+// to the lifecycles of the volumes in the current environment.
+func (st *State) WatchVolumes() (watcher.StringsWatcher, error) {
+	var result params.StringsWatchResult
+	err := st.call("WatchVolumes", nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	if err := result.Error; err != nil {
+		return nil, result.Error
+	}
+	w := watcher.NewStringsWatcher(st.caller, result)
+	return w, nil
+}
+
+// Volumes returns the details of the named volumes.
+func (st *State) Volumes(names []string) ([]params.VolumeResult, error) {
+	var results params.VolumeResults
+	args := params.VolumeNames{Names: names}
+	if err := st.call("Volumes", args, &results); err != nil {
+		return nil, err
+	}
+	if len(results.Results) != len(names) {
+		return nil, fmt.Errorf("expected %d results, got %d", len(names), len(results.Results))
+	}
+	return results.Results, nil
+}
+
+// RemoveVolumes removes the named volumes, which must no
+// longer be alive.
+func (st *State) RemoveVolumes(names []string) ([]params.ErrorResult, error) {
+	var results params.ErrorResults
+	args := params.VolumeNames{Names: names}
+	if err := st.call("RemoveVolumes", args, &results); err != nil {
+		return nil, err
+	}
+	if len(results.Results) != len(names) {
+		return nil, fmt.Errorf("expected %d results, got %d", len(names), len(results.Results))
+	}
+	return results.Results, nil
+}
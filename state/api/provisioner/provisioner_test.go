@@ -24,6 +24,8 @@ import (
 	"github.com/juju/juju/state/api/provisioner"
 	apitesting "github.com/juju/juju/state/api/testing"
 	statetesting "github.com/juju/juju/state/testing"
+	"github.com/juju/juju/storage"
+	_ "github.com/juju/juju/storage/provider"
 	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/tools"
 	"github.com/juju/juju/version"
@@ -524,6 +526,49 @@ func (s *provisionerSuite) TestWatchEnvironMachines(c *gc.C) {
 	wc.AssertClosed()
 }
 
+func (s *provisionerSuite) TestVolumes(c *gc.C) {
+	w, err := s.provisioner.WatchVolumes()
+	c.Assert(err, gc.IsNil)
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewStringsWatcherC(c, s.BackingState, w)
+
+	// Initial event.
+	wc.AssertChange()
+
+	service := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	unit, err := service.AddUnitWithStorage(map[string]storage.Directive{
+		"data": {Pool: "loop", Size: 1024, Count: 1},
+	})
+	c.Assert(err, gc.IsNil)
+	wc.AssertChange("0")
+
+	results, err := s.provisioner.Volumes([]string{"0"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(results, gc.DeepEquals, []params.VolumeResult{{
+		Result: params.Volume{
+			Params: storage.VolumeParams{Name: "0", Size: 1024, Provider: "loop"},
+			Life:   params.Alive,
+		},
+	}})
+
+	err = unit.EnsureDead()
+	c.Assert(err, gc.IsNil)
+	err = unit.Remove()
+	c.Assert(err, gc.IsNil)
+	err = s.State.Cleanup()
+	c.Assert(err, gc.IsNil)
+	wc.AssertChange("0")
+
+	errResults, err := s.provisioner.RemoveVolumes([]string{"0"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(errResults, gc.DeepEquals, []params.ErrorResult{{nil}})
+	wc.AssertChange("0")
+	wc.AssertNoChange()
+
+	statetesting.AssertStop(c, w)
+	wc.AssertClosed()
+}
+
 func (s *provisionerSuite) TestStateAddresses(c *gc.C) {
 	err := s.machine.SetAddresses(network.NewAddress("0.1.2.3", network.ScopeUnknown))
 	c.Assert(err, gc.IsNil)
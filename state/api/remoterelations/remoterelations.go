@@ -0,0 +1,113 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package remoterelations
+
+import (
+	"fmt"
+
+	"github.com/juju/juju/state/api/base"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/api/watcher"
+)
+
+const remoteRelationsFacade = "RemoteRelations"
+
+// State provides access to the remoterelations worker's view of the
+// relations with remote services in all the environments hosted by
+// the state server.
+type State struct {
+	caller base.Caller
+}
+
+// NewState creates a new client-side RemoteRelations facade.
+func NewState(caller base.Caller) *State {
+	return &State{caller}
+}
+
+// RemoteRelations returns the relations with remote services in
+// all the environments hosted by the state server.
+func (st *State) RemoteRelations() ([]params.RemoteRelation, error) {
+	var result params.RemoteRelationsResult
+	err := st.caller.Call(remoteRelationsFacade, "", "RemoteRelations", nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result.Relations, nil
+}
+
+// WatchRemoteRelations returns a NotifyWatcher that notifies of
+// changes to the relations with remote services, and to their
+// units, in all the environments hosted by the state server.
+func (st *State) WatchRemoteRelations() (watcher.NotifyWatcher, error) {
+	var result params.NotifyWatchResult
+	err := st.caller.Call(remoteRelationsFacade, "", "WatchRemoteRelations", nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	w := watcher.NewNotifyWatcher(st.caller, result)
+	return w, nil
+}
+
+// RelationUnits returns the units that have joined the identified
+// relation, along with their settings in it.
+func (st *State) RelationUnits(id params.RemoteRelationId) (params.RemoteRelationUnitsResult, error) {
+	var results params.RemoteRelationUnitsResults
+	args := params.RemoteRelationIds{Relations: []params.RemoteRelationId{id}}
+	err := st.caller.Call(remoteRelationsFacade, "", "RelationUnits", args, &results)
+	if err != nil {
+		return params.RemoteRelationUnitsResult{}, err
+	}
+	if len(results.Results) != 1 {
+		return params.RemoteRelationUnitsResult{}, fmt.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return params.RemoteRelationUnitsResult{}, result.Error
+	}
+	return result, nil
+}
+
+// MirrorRelation ensures that the environment offering the remote
+// service of the identified relation has a matching relation.
+func (st *State) MirrorRelation(id params.RemoteRelationId) error {
+	return st.relationCall("MirrorRelations", id)
+}
+
+// DestroyRelation destroys the identified relation.
+func (st *State) DestroyRelation(id params.RemoteRelationId) error {
+	return st.relationCall("DestroyRelations", id)
+}
+
+func (st *State) relationCall(method string, id params.RemoteRelationId) error {
+	var results params.ErrorResults
+	args := params.RemoteRelationIds{Relations: []params.RemoteRelationId{id}}
+	if err := st.caller.Call(remoteRelationsFacade, "", method, args, &results); err != nil {
+		return err
+	}
+	return results.OneError()
+}
+
+// EnterScope ensures that the given remote unit has entered the
+// scope of its relation, with the given settings.
+func (st *State) EnterScope(change params.RemoteRelationUnitChange) error {
+	return st.scopeCall("EnterScope", change)
+}
+
+// LeaveScope ensures that the given remote unit has left the scope
+// of its relation.
+func (st *State) LeaveScope(change params.RemoteRelationUnitChange) error {
+	return st.scopeCall("LeaveScope", change)
+}
+
+func (st *State) scopeCall(method string, change params.RemoteRelationUnitChange) error {
+	var results params.ErrorResults
+	args := params.RemoteRelationUnitChanges{Changes: []params.RemoteRelationUnitChange{change}}
+	if err := st.caller.Call(remoteRelationsFacade, "", method, args, &results); err != nil {
+		return err
+	}
+	return results.OneError()
+}
@@ -0,0 +1,119 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package remoterelations_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	gc "launchpad.net/gocheck"
+
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/api/remoterelations"
+	statetesting "github.com/juju/juju/state/testing"
+)
+
+type remoteRelationsSuite struct {
+	jujutesting.JujuConnSuite
+
+	remoteRelations *remoterelations.State
+}
+
+var _ = gc.Suite(&remoteRelationsSuite{})
+
+func (s *remoteRelationsSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+
+	machine, err := s.State.AddMachine("quantal", state.JobManageEnviron)
+	c.Assert(err, gc.IsNil)
+	password, err := utils.RandomPassword()
+	c.Assert(err, gc.IsNil)
+	err = machine.SetPassword(password)
+	c.Assert(err, gc.IsNil)
+	err = machine.SetProvisioned("i-manager", "fake_nonce", nil)
+	c.Assert(err, gc.IsNil)
+	st := s.OpenAPIAsMachine(c, machine.Tag(), password, "fake_nonce")
+	s.remoteRelations = st.RemoteRelations()
+	c.Assert(s.remoteRelations, gc.NotNil)
+}
+
+func (s *remoteRelationsSuite) TestRelateToOffer(c *gc.C) {
+	hosted := s.AddHostedEnvironment(c, "sandbox")
+	defer hosted.Close()
+	s.AddHostedTestingService(c, hosted, "mysql", "mysql")
+	offer, err := hosted.AddOffer("mysql")
+	c.Assert(err, gc.IsNil)
+	s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	_, err = s.APIState.Client().AddRelation("wordpress", offer.URL())
+	c.Assert(err, gc.IsNil)
+
+	relations, err := s.remoteRelations.RemoteRelations()
+	c.Assert(err, gc.IsNil)
+	c.Assert(relations, gc.HasLen, 1)
+	rel := relations[0]
+	c.Assert(rel.Key, gc.Equals, "wordpress:db mysql:server")
+	c.Assert(rel.SourceEnvUUID, gc.Equals, offer.EnvUUID())
+	c.Assert(rel.Consumer, gc.Equals, true)
+
+	localId := params.RemoteRelationId{EnvUUID: rel.EnvUUID, Key: rel.Key}
+	remoteId := params.RemoteRelationId{EnvUUID: rel.SourceEnvUUID, Key: rel.Key}
+	_, err = s.remoteRelations.RelationUnits(remoteId)
+	c.Assert(err, jc.Satisfies, params.IsCodeNotFound)
+	err = s.remoteRelations.MirrorRelation(localId)
+	c.Assert(err, gc.IsNil)
+
+	err = s.remoteRelations.EnterScope(params.RemoteRelationUnitChange{
+		EnvUUID:  rel.SourceEnvUUID,
+		Key:      rel.Key,
+		Unit:     "wordpress/0",
+		Settings: params.RelationSettings{"foo": "bar"},
+	})
+	c.Assert(err, gc.IsNil)
+	result, err := s.remoteRelations.RelationUnits(remoteId)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Life, gc.Equals, params.Alive)
+	c.Assert(result.RemoteUnits, gc.DeepEquals, []params.RemoteRelationUnit{{
+		Unit:     "wordpress/0",
+		Settings: params.RelationSettings{"foo": "bar"},
+	}})
+
+	err = s.remoteRelations.LeaveScope(params.RemoteRelationUnitChange{
+		EnvUUID: rel.SourceEnvUUID,
+		Key:     rel.Key,
+		Unit:    "wordpress/0",
+	})
+	c.Assert(err, gc.IsNil)
+	err = s.remoteRelations.DestroyRelation(remoteId)
+	c.Assert(err, gc.IsNil)
+	_, err = hosted.KeyRelation(rel.Key)
+	c.Assert(err, gc.ErrorMatches, `relation "wordpress:db mysql:server" not found`)
+}
+
+func (s *remoteRelationsSuite) TestWatchRemoteRelations(c *gc.C) {
+	w, err := s.remoteRelations.WatchRemoteRelations()
+	c.Assert(err, gc.IsNil)
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.BackingState, w)
+
+	// Initial event.
+	wc.AssertOneChange()
+
+	// Adding a hosted environment is reported.
+	hosted := s.AddHostedEnvironment(c, "sandbox")
+	defer hosted.Close()
+	wc.AssertOneChange()
+
+	// So is relating to an offer.
+	s.AddHostedTestingService(c, hosted, "mysql", "mysql")
+	offer, err := hosted.AddOffer("mysql")
+	c.Assert(err, gc.IsNil)
+	s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	_, err = s.APIState.Client().AddRelation("wordpress", offer.URL())
+	c.Assert(err, gc.IsNil)
+	wc.AssertOneChange()
+
+	statetesting.AssertStop(c, w)
+	wc.AssertClosed()
+}
@@ -0,0 +1,45 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package spaces
+
+import (
+	"github.com/juju/juju/state/api"
+	"github.com/juju/juju/state/api/params"
+)
+
+// Client provides access to the Spaces API facade.
+type Client struct {
+	st *api.State
+}
+
+// NewClient returns a new Spaces client using the given API
+// connection.
+func NewClient(st *api.State) *Client {
+	return &Client{st}
+}
+
+// Close closes the underlying API connection.
+func (c *Client) Close() error {
+	return c.st.Close()
+}
+
+// CreateSpace creates a new space with the given name, made of the
+// subnets with the given CIDRs.
+func (c *Client) CreateSpace(name string, subnetCIDRs []string) error {
+	args := params.CreateSpaceArgs{
+		Name:        name,
+		SubnetCIDRs: subnetCIDRs,
+	}
+	return c.st.Call("Spaces", "", "CreateSpace", args, nil)
+}
+
+// ListSpaces returns all the spaces in the environment, along with
+// their subnets.
+func (c *Client) ListSpaces() ([]params.Space, error) {
+	var result params.ListSpacesResults
+	if err := c.st.Call("Spaces", "", "ListSpaces", nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Spaces, nil
+}
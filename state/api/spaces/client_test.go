@@ -0,0 +1,49 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package spaces_test
+
+import (
+	gc "launchpad.net/gocheck"
+
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/spaces"
+)
+
+type spacesSuite struct {
+	jujutesting.JujuConnSuite
+
+	client *spaces.Client
+}
+
+var _ = gc.Suite(&spacesSuite{})
+
+func (s *spacesSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.client = spaces.NewClient(s.APIState)
+}
+
+func (s *spacesSuite) TestCreateAndListSpaces(c *gc.C) {
+	_, err := s.State.AddSubnet(state.SubnetInfo{CIDR: "10.0.0.0/24"})
+	c.Assert(err, gc.IsNil)
+
+	err = s.client.CreateSpace("db", []string{"10.0.0.0/24"})
+	c.Assert(err, gc.IsNil)
+	err = s.client.CreateSpace("dmz", nil)
+	c.Assert(err, gc.IsNil)
+
+	spaces, err := s.client.ListSpaces()
+	c.Assert(err, gc.IsNil)
+	c.Assert(spaces, gc.HasLen, 2)
+	c.Assert(spaces[0].Name, gc.Equals, "db")
+	c.Assert(spaces[0].Subnets, gc.HasLen, 1)
+	c.Assert(spaces[0].Subnets[0].CIDR, gc.Equals, "10.0.0.0/24")
+	c.Assert(spaces[1].Name, gc.Equals, "dmz")
+	c.Assert(spaces[1].Subnets, gc.HasLen, 0)
+}
+
+func (s *spacesSuite) TestCreateSpaceError(c *gc.C) {
+	err := s.client.CreateSpace("db", []string{"10.0.0.0/24"})
+	c.Assert(err, gc.ErrorMatches, `cannot add space "db": subnet "10.0.0.0/24" not found`)
+}
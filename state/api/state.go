@@ -16,10 +16,13 @@ import (
 	"github.com/juju/juju/state/api/keyupdater"
 	apilogger "github.com/juju/juju/state/api/logger"
 	"github.com/juju/juju/state/api/machiner"
+	"github.com/juju/juju/state/api/metricsmanager"
 	"github.com/juju/juju/state/api/networker"
 	"github.com/juju/juju/state/api/params"
 	"github.com/juju/juju/state/api/provisioner"
+	"github.com/juju/juju/state/api/remoterelations"
 	"github.com/juju/juju/state/api/rsyslog"
+	"github.com/juju/juju/state/api/storageprovisioner"
 	"github.com/juju/juju/state/api/uniter"
 	"github.com/juju/juju/state/api/upgrader"
 )
@@ -112,6 +115,12 @@ func (st *State) Networker() *networker.State {
 	return networker.NewState(st)
 }
 
+// StorageProvisioner returns a version of the state that provides
+// functionality required by the storage provisioner worker.
+func (st *State) StorageProvisioner() *storageprovisioner.State {
+	return storageprovisioner.NewState(st)
+}
+
 // Provisioner returns a version of the state that provides functionality
 // required by the provisioner worker.
 func (st *State) Provisioner() *provisioner.State {
@@ -166,6 +175,16 @@ func (st *State) CharmRevisionUpdater() *charmrevisionupdater.State {
 	return charmrevisionupdater.NewState(st)
 }
 
+// MetricsManager returns access to the MetricsManager API
+func (st *State) MetricsManager() *metricsmanager.State {
+	return metricsmanager.NewState(st)
+}
+
+// RemoteRelations returns access to the RemoteRelations API
+func (st *State) RemoteRelations() *remoterelations.State {
+	return remoterelations.NewState(st)
+}
+
 // Rsyslog returns access to the Rsyslog API
 func (st *State) Rsyslog() *rsyslog.State {
 	return rsyslog.NewState(st)
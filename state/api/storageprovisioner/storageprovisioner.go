@@ -0,0 +1,118 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storageprovisioner
+
+import (
+	"fmt"
+
+	"github.com/juju/juju/state/api/base"
+	"github.com/juju/juju/state/api/common"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/api/watcher"
+)
+
+const storageProvisionerFacade = "StorageProvisioner"
+
+// State provides access to a storage provisioner worker's view of the state.
+type State struct {
+	*common.EnvironWatcher
+
+	caller base.Caller
+}
+
+func (st *State) call(method string, params, result interface{}) error {
+	return st.caller.Call(storageProvisionerFacade, "", method, params, result)
+}
+
+// NewState creates a new client-side StorageProvisioner facade.
+func NewState(caller base.Caller) *State {
+	return &State{
+		EnvironWatcher: common.NewEnvironWatcher(storageProvisionerFacade, caller),
+		caller:         caller,
+	}
+}
+
+// WatchVolumeAttachments returns a StringsWatcher that notifies of
+// changes to the lifecycles of the volume attachments of the machine
+// with the given tag. The ids reported are of the form
+// "<machine id>:<volume name>".
+func (st *State) WatchVolumeAttachments(machineTag string) (watcher.StringsWatcher, error) {
+	var results params.StringsWatchResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: machineTag}},
+	}
+	if err := st.call("WatchVolumeAttachments", args, &results); err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return watcher.NewStringsWatcher(st.caller, result), nil
+}
+
+func attachmentIds(machineTag string, volumes []string) params.VolumeAttachmentIds {
+	ids := make([]params.VolumeAttachmentId, len(volumes))
+	for i, volume := range volumes {
+		ids[i] = params.VolumeAttachmentId{MachineTag: machineTag, Volume: volume}
+	}
+	return params.VolumeAttachmentIds{Ids: ids}
+}
+
+// VolumeAttachments returns the details of the attachments of the
+// named volumes to the machine with the given tag.
+func (st *State) VolumeAttachments(machineTag string, volumes []string) ([]params.VolumeAttachmentResult, error) {
+	var results params.VolumeAttachmentResults
+	if err := st.call("VolumeAttachments", attachmentIds(machineTag, volumes), &results); err != nil {
+		return nil, err
+	}
+	if len(results.Results) != len(volumes) {
+		return nil, fmt.Errorf("expected %d results, got %d", len(volumes), len(results.Results))
+	}
+	return results.Results, nil
+}
+
+// SetVolumeInfo records the provider details of provisioned
+// volumes and their attachments.
+func (st *State) SetVolumeInfo(info []params.VolumeInfo) ([]params.ErrorResult, error) {
+	var results params.ErrorResults
+	args := params.VolumesInfo{Volumes: info}
+	if err := st.call("SetVolumeInfo", args, &results); err != nil {
+		return nil, err
+	}
+	if len(results.Results) != len(info) {
+		return nil, fmt.Errorf("expected %d results, got %d", len(info), len(results.Results))
+	}
+	return results.Results, nil
+}
+
+// SetFilesystemInfo records the mount points of filesystems
+// created on provisioned volumes.
+func (st *State) SetFilesystemInfo(info []params.FilesystemInfo) ([]params.ErrorResult, error) {
+	var results params.ErrorResults
+	args := params.FilesystemsInfo{Filesystems: info}
+	if err := st.call("SetFilesystemInfo", args, &results); err != nil {
+		return nil, err
+	}
+	if len(results.Results) != len(info) {
+		return nil, fmt.Errorf("expected %d results, got %d", len(info), len(results.Results))
+	}
+	return results.Results, nil
+}
+
+// RemoveVolumes removes the named volumes, which must no longer
+// be alive, and their attachments to the machine with the given tag.
+func (st *State) RemoveVolumes(machineTag string, volumes []string) ([]params.ErrorResult, error) {
+	var results params.ErrorResults
+	if err := st.call("RemoveVolumes", attachmentIds(machineTag, volumes), &results); err != nil {
+		return nil, err
+	}
+	if len(results.Results) != len(volumes) {
+		return nil, fmt.Errorf("expected %d results, got %d", len(volumes), len(results.Results))
+	}
+	return results.Results, nil
+}
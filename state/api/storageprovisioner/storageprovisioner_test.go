@@ -0,0 +1,117 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storageprovisioner_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/api/storageprovisioner"
+	statetesting "github.com/juju/juju/state/testing"
+	"github.com/juju/juju/storage"
+)
+
+type storageProvisionerSuite struct {
+	testing.JujuConnSuite
+
+	st          *api.State
+	machine     *state.Machine
+	unit        *state.Unit
+	provisioner *storageprovisioner.State
+}
+
+var _ = gc.Suite(&storageProvisionerSuite{})
+
+func (s *storageProvisionerSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.st, s.machine = s.OpenAPIAsNewMachine(c, state.JobHostUnits)
+	mysql := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	var err error
+	s.unit, err = mysql.AddUnitWithStorage(map[string]storage.Directive{
+		"data": {Pool: "loop", Size: 1024, Count: 1},
+	})
+	c.Assert(err, gc.IsNil)
+	err = s.unit.AssignToMachine(s.machine)
+	c.Assert(err, gc.IsNil)
+	s.provisioner = s.st.StorageProvisioner()
+	c.Assert(s.provisioner, gc.NotNil)
+}
+
+func (s *storageProvisionerSuite) TestWatchVolumeAttachments(c *gc.C) {
+	w, err := s.provisioner.WatchVolumeAttachments(s.machine.Tag())
+	c.Assert(err, gc.IsNil)
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewStringsWatcherC(c, s.BackingState, w)
+
+	// Initial event.
+	wc.AssertChange(s.machine.Id() + ":0")
+	wc.AssertNoChange()
+
+	err = s.unit.UnassignFromMachine()
+	c.Assert(err, gc.IsNil)
+	wc.AssertChange(s.machine.Id() + ":0")
+	wc.AssertNoChange()
+}
+
+func (s *storageProvisionerSuite) TestProvisionVolume(c *gc.C) {
+	results, err := s.provisioner.VolumeAttachments(s.machine.Tag(), []string{"0"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(results, gc.HasLen, 1)
+	c.Assert(results[0].Error, gc.IsNil)
+	attachment := results[0].Result
+	c.Assert(attachment.Params.Name, gc.Equals, "0")
+	c.Assert(attachment.Params.Provider, gc.Equals, storage.ProviderType("loop"))
+	c.Assert(attachment.Life, gc.Equals, params.Alive)
+	c.Assert(attachment.Provisioned, jc.IsFalse)
+
+	errResults, err := s.provisioner.SetVolumeInfo([]params.VolumeInfo{{
+		MachineTag: s.machine.Tag(),
+		Volume:     storage.Volume{Name: "0", VolumeId: "volume-0", Size: 1024},
+		Attachment: storage.VolumeAttachment{Volume: "0", Machine: s.machine.Id(), DeviceName: "loop0"},
+	}})
+	c.Assert(err, gc.IsNil)
+	c.Assert(errResults, gc.DeepEquals, []params.ErrorResult{{nil}})
+
+	results, err = s.provisioner.VolumeAttachments(s.machine.Tag(), []string{"0"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(results[0].Result.Provisioned, jc.IsTrue)
+	c.Assert(results[0].Result.VolumeId, gc.Equals, "volume-0")
+	c.Assert(results[0].Result.DeviceName, gc.Equals, "loop0")
+}
+
+func (s *storageProvisionerSuite) TestSetFilesystemInfo(c *gc.C) {
+	err := s.State.SetVolumeAttachmentInfo(s.machine.Id(), "0", state.VolumeAttachmentInfo{DeviceName: "loop0"})
+	c.Assert(err, gc.IsNil)
+	errResults, err := s.provisioner.SetFilesystemInfo([]params.FilesystemInfo{{
+		MachineTag: s.machine.Tag(),
+		Volume:     "0",
+		MountPoint: "/srv/data",
+	}})
+	c.Assert(err, gc.IsNil)
+	c.Assert(errResults, gc.DeepEquals, []params.ErrorResult{{nil}})
+
+	results, err := s.provisioner.VolumeAttachments(s.machine.Tag(), []string{"0"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(results[0].Result.MountPoint, gc.Equals, "/srv/data")
+}
+
+func (s *storageProvisionerSuite) TestRemoveVolumes(c *gc.C) {
+	err := s.unit.EnsureDead()
+	c.Assert(err, gc.IsNil)
+	err = s.unit.Remove()
+	c.Assert(err, gc.IsNil)
+	err = s.State.Cleanup()
+	c.Assert(err, gc.IsNil)
+
+	results, err := s.provisioner.RemoveVolumes(s.machine.Tag(), []string{"0"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(results, gc.DeepEquals, []params.ErrorResult{{nil}})
+	_, err = s.State.Volume("0")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
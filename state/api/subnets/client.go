@@ -0,0 +1,48 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package subnets
+
+import (
+	"github.com/juju/juju/state/api"
+	"github.com/juju/juju/state/api/params"
+)
+
+// Client provides access to the Subnets API facade.
+type Client struct {
+	st *api.State
+}
+
+// NewClient returns a new Subnets client using the given API
+// connection.
+func NewClient(st *api.State) *Client {
+	return &Client{st}
+}
+
+// Close closes the underlying API connection.
+func (c *Client) Close() error {
+	return c.st.Close()
+}
+
+// AddSubnet adds the subnet with the given CIDR or provider id, only
+// one of which may be non-empty, to the given space. The space name
+// may be empty.
+func (c *Client) AddSubnet(cidr, providerId, spaceName string) error {
+	args := params.AddSubnetArgs{
+		SubnetCIDR:       cidr,
+		SubnetProviderId: providerId,
+		SpaceName:        spaceName,
+	}
+	return c.st.Call("Subnets", "", "AddSubnet", args, nil)
+}
+
+// ListSubnets returns the subnets in the environment, or only those in
+// the given space if spaceName is not empty.
+func (c *Client) ListSubnets(spaceName string) ([]params.Subnet, error) {
+	var result params.ListSubnetsResults
+	args := params.ListSubnetsArgs{SpaceName: spaceName}
+	if err := c.st.Call("Subnets", "", "ListSubnets", args, &result); err != nil {
+		return nil, err
+	}
+	return result.Subnets, nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package subnets_test
+
+import (
+	gc "launchpad.net/gocheck"
+
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/api/subnets"
+)
+
+type subnetsSuite struct {
+	jujutesting.JujuConnSuite
+
+	client *subnets.Client
+}
+
+var _ = gc.Suite(&subnetsSuite{})
+
+func (s *subnetsSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.client = subnets.NewClient(s.APIState)
+}
+
+func (s *subnetsSuite) TestAddAndListSubnets(c *gc.C) {
+	_, err := s.State.AddSpace("db", nil)
+	c.Assert(err, gc.IsNil)
+
+	err = s.client.AddSubnet("", "dummy-public", "db")
+	c.Assert(err, gc.IsNil)
+	err = s.client.AddSubnet("10.0.0.0/24", "", "")
+	c.Assert(err, gc.IsNil)
+
+	all, err := s.client.ListSubnets("")
+	c.Assert(err, gc.IsNil)
+	c.Assert(all, gc.DeepEquals, []params.Subnet{{
+		CIDR:              "0.20.0.0/24",
+		ProviderId:        "dummy-public",
+		AvailabilityZones: []string{"zone1"},
+		SpaceName:         "db",
+	}, {
+		CIDR: "10.0.0.0/24",
+	}})
+
+	inDB, err := s.client.ListSubnets("db")
+	c.Assert(err, gc.IsNil)
+	c.Assert(inDB, gc.DeepEquals, all[:1])
+}
+
+func (s *subnetsSuite) TestAddSubnetError(c *gc.C) {
+	err := s.client.AddSubnet("10.0.0.0/24", "", "missing")
+	c.Assert(err, gc.ErrorMatches, `cannot add subnet "10.0.0.0/24": space "missing" not found`)
+}
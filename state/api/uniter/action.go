@@ -0,0 +1,89 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter
+
+import (
+	"fmt"
+
+	"github.com/juju/juju/state/api/params"
+)
+
+// Action represents a single action queued for a unit, as seen by
+// the uniter.
+type Action struct {
+	st     *State
+	id     string
+	name   string
+	params map[string]interface{}
+}
+
+// Action returns the queued action with the given id.
+func (st *State) Action(id string) (*Action, error) {
+	var results params.ActionResults
+	args := params.Actions{
+		Actions: []params.Action{{Id: id}},
+	}
+	err := st.call("Actions", args, &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &Action{
+		st:     st,
+		id:     result.Action.Id,
+		name:   result.Action.Name,
+		params: result.Action.Params,
+	}, nil
+}
+
+// Id returns the id of the action.
+func (a *Action) Id() string {
+	return a.id
+}
+
+// Name returns the name of the action, which identifies the
+// executable to run under the charm's actions directory.
+func (a *Action) Name() string {
+	return a.name
+}
+
+// Params returns the parameters the action was queued with.
+func (a *Action) Params() map[string]interface{} {
+	return a.params
+}
+
+// Complete removes the action from the unit's queue and records that
+// it ran successfully, with the given results and message.
+func (a *Action) Complete(results map[string]interface{}, message string) error {
+	return a.finish(params.ActionCompleted, results, message)
+}
+
+// Fail removes the action from the unit's queue and records that it
+// failed, with the given results and reason.
+func (a *Action) Fail(results map[string]interface{}, reason string) error {
+	return a.finish(params.ActionFailed, results, reason)
+}
+
+func (a *Action) finish(status params.ActionStatus, results map[string]interface{}, message string) error {
+	var result params.ErrorResults
+	args := params.ActionResults{
+		Results: []params.ActionResult{{
+			Action:  params.Action{Id: a.id},
+			Status:  status,
+			Results: results,
+			Message: message,
+		}},
+	}
+	err := a.st.call("FinishActions", args, &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
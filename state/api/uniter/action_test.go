@@ -0,0 +1,102 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/api/uniter"
+	statetesting "github.com/juju/juju/state/testing"
+)
+
+type actionSuite struct {
+	uniterSuite
+
+	apiUnit *uniter.Unit
+}
+
+var _ = gc.Suite(&actionSuite{})
+
+func (s *actionSuite) SetUpTest(c *gc.C) {
+	s.uniterSuite.SetUpTest(c)
+
+	var err error
+	s.apiUnit, err = s.uniter.Unit(s.wordpressUnit.Tag())
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *actionSuite) TestWatchActions(c *gc.C) {
+	w, err := s.apiUnit.WatchActions()
+	c.Assert(err, gc.IsNil)
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewStringsWatcherC(c, s.BackingState, w)
+
+	// Initial event.
+	wc.AssertChange()
+	wc.AssertNoChange()
+
+	id, err := s.wordpressUnit.AddAction("snapshot", nil)
+	c.Assert(err, gc.IsNil)
+	wc.AssertChange(id)
+	wc.AssertNoChange()
+
+	statetesting.AssertStop(c, w)
+	wc.AssertClosed()
+}
+
+func (s *actionSuite) TestAction(c *gc.C) {
+	actionParams := map[string]interface{}{"outfile": "foo.bz2"}
+	id, err := s.wordpressUnit.AddAction("snapshot", actionParams)
+	c.Assert(err, gc.IsNil)
+
+	action, err := s.uniter.Action(id)
+	c.Assert(err, gc.IsNil)
+	c.Assert(action.Id(), gc.Equals, id)
+	c.Assert(action.Name(), gc.Equals, "snapshot")
+	c.Assert(action.Params(), jc.DeepEquals, actionParams)
+
+	_, err = s.uniter.Action("u#mysql/0#a#0")
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	c.Assert(err, jc.Satisfies, params.IsCodeUnauthorized)
+}
+
+func (s *actionSuite) TestComplete(c *gc.C) {
+	id, err := s.wordpressUnit.AddAction("snapshot", nil)
+	c.Assert(err, gc.IsNil)
+	action, err := s.uniter.Action(id)
+	c.Assert(err, gc.IsNil)
+
+	err = action.Complete(map[string]interface{}{"size": "10"}, "done")
+	c.Assert(err, gc.IsNil)
+
+	results, err := s.State.ActionResultsForAction(id)
+	c.Assert(err, gc.IsNil)
+	c.Assert(results, gc.HasLen, 1)
+	c.Assert(results[0].Status(), gc.Equals, state.ActionCompleted)
+	c.Assert(results[0].Results(), jc.DeepEquals, map[string]interface{}{"size": "10"})
+	c.Assert(results[0].Output(), gc.Equals, "done")
+
+	// The action is no longer queued, so it cannot be finished again.
+	err = action.Fail(nil, "again")
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *actionSuite) TestFail(c *gc.C) {
+	id, err := s.wordpressUnit.AddAction("snapshot", nil)
+	c.Assert(err, gc.IsNil)
+	action, err := s.uniter.Action(id)
+	c.Assert(err, gc.IsNil)
+
+	err = action.Fail(nil, "no space left")
+	c.Assert(err, gc.IsNil)
+
+	results, err := s.State.ActionResultsForAction(id)
+	c.Assert(err, gc.IsNil)
+	c.Assert(results, gc.HasLen, 1)
+	c.Assert(results[0].Status(), gc.Equals, state.ActionFailed)
+	c.Assert(results[0].Output(), gc.Equals, "no space left")
+}
@@ -0,0 +1,40 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/juju/state/api/params"
+)
+
+// AddDebugRecording stores the recording of a debug-hooks session in
+// which the given hook was run on the unit, with the session's
+// transcript and its timings as written by script(1).
+func (u *Unit) AddDebugRecording(hookName string, started, finished time.Time, transcript, timing []byte) error {
+	var results params.ErrorResults
+	args := params.DebugRecordingsAddArgs{
+		Recordings: []params.DebugRecordingData{{
+			Recording: params.DebugRecording{
+				Unit:     u.Name(),
+				Hook:     hookName,
+				Started:  started,
+				Finished: finished,
+			},
+			Transcript: transcript,
+			Timing:     timing,
+		}},
+	}
+	if err := u.st.caller.Call("DebugRecordings", "", "Add", args, &results); err != nil {
+		return err
+	}
+	if len(results.Results) != 1 {
+		return fmt.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	if result := results.Results[0]; result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
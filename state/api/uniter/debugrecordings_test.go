@@ -0,0 +1,43 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter_test
+
+import (
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state/api/uniter"
+)
+
+type debugRecordingsSuite struct {
+	uniterSuite
+
+	apiUnit *uniter.Unit
+}
+
+var _ = gc.Suite(&debugRecordingsSuite{})
+
+func (s *debugRecordingsSuite) SetUpTest(c *gc.C) {
+	s.uniterSuite.SetUpTest(c)
+
+	var err error
+	s.apiUnit, err = s.uniter.Unit(s.wordpressUnit.Tag())
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *debugRecordingsSuite) TestAddDebugRecording(c *gc.C) {
+	started := time.Date(2014, 7, 14, 12, 0, 0, 0, time.UTC)
+	err := s.apiUnit.AddDebugRecording("install", started, started.Add(time.Minute), []byte("$ ls\n"), []byte("0.1 5\n"))
+	c.Assert(err, gc.IsNil)
+
+	recordings, err := s.State.DebugRecordings(s.wordpressUnit.Name())
+	c.Assert(err, gc.IsNil)
+	c.Assert(recordings, gc.HasLen, 1)
+	c.Assert(recordings[0].Hook(), gc.Equals, "install")
+	transcript, timing, err := s.State.DebugRecordingData(recordings[0])
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(transcript), gc.Equals, "$ ls\n")
+	c.Assert(string(timing), gc.Equals, "0.1 5\n")
+}
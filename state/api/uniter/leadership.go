@@ -0,0 +1,116 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/api/watcher"
+)
+
+// Leadership is handled by its own facade, which
+// is only used by the uniter.
+const leadershipFacade = "Leadership"
+
+func (st *State) callLeadership(method string, params, results interface{}) error {
+	return st.caller.Call(leadershipFacade, "", method, params, results)
+}
+
+// ClaimLeadership makes the unit the leader of its service for the
+// given duration, or extends its leadership if it is already leader.
+// If another unit is leader, the returned error satisfies
+// params.IsCodeLeadershipClaimDenied.
+func (u *Unit) ClaimLeadership(duration time.Duration) error {
+	var results params.ErrorResults
+	args := params.ClaimLeadershipParams{
+		Params: []params.ClaimLeadership{{
+			UnitTag:         u.tag,
+			DurationSeconds: duration.Seconds(),
+		}},
+	}
+	if err := u.st.callLeadership("ClaimLeadership", args, &results); err != nil {
+		return err
+	}
+	return results.OneError()
+}
+
+// IsLeader returns whether the unit currently holds the
+// leadership of its service. It does not claim leadership.
+func (u *Unit) IsLeader() (bool, error) {
+	var results params.BoolResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag}},
+	}
+	if err := u.st.callLeadership("IsLeader", args, &results); err != nil {
+		return false, err
+	}
+	if len(results.Results) != 1 {
+		return false, fmt.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.Result, nil
+}
+
+// LeaderSettings returns the settings written by the
+// leader of the unit's service.
+func (u *Unit) LeaderSettings() (map[string]string, error) {
+	var results params.LeaderSettingsResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag}},
+	}
+	if err := u.st.callLeadership("LeaderSettings", args, &results); err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return result.Settings, nil
+}
+
+// MergeLeaderSettings merges the given settings into those shared
+// with the other units of the service; settings with empty values
+// are removed. Only the leader may write the settings; for any other
+// unit, the returned error satisfies params.IsCodeNotLeader.
+func (u *Unit) MergeLeaderSettings(settings map[string]string) error {
+	var results params.ErrorResults
+	args := params.MergeLeaderSettingsParams{
+		Params: []params.MergeLeaderSettings{{
+			UnitTag:  u.tag,
+			Settings: settings,
+		}},
+	}
+	if err := u.st.callLeadership("MergeLeaderSettings", args, &results); err != nil {
+		return err
+	}
+	return results.OneError()
+}
+
+// WatchLeaderSettings returns a watcher that notifies when the
+// settings written by the leader of the unit's service change.
+func (u *Unit) WatchLeaderSettings() (watcher.NotifyWatcher, error) {
+	var results params.NotifyWatchResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag}},
+	}
+	if err := u.st.callLeadership("WatchLeaderSettings", args, &results); err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return watcher.NewNotifyWatcher(u.st.caller, result), nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/api/uniter"
+	statetesting "github.com/juju/juju/state/testing"
+)
+
+type leadershipSuite struct {
+	uniterSuite
+
+	apiUnit *uniter.Unit
+}
+
+var _ = gc.Suite(&leadershipSuite{})
+
+func (s *leadershipSuite) SetUpTest(c *gc.C) {
+	s.uniterSuite.SetUpTest(c)
+
+	var err error
+	s.apiUnit, err = s.uniter.Unit(s.wordpressUnit.Tag())
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *leadershipSuite) TestClaimLeadership(c *gc.C) {
+	err := s.apiUnit.ClaimLeadership(time.Minute)
+	c.Assert(err, gc.IsNil)
+	leader, err := s.wordpressService.Leader()
+	c.Assert(err, gc.IsNil)
+	c.Assert(leader, gc.Equals, s.wordpressUnit.Name())
+}
+
+func (s *leadershipSuite) TestClaimLeadershipDenied(c *gc.C) {
+	err := s.wordpressService.ClaimLeadership("wordpress/1", time.Minute)
+	c.Assert(err, gc.IsNil)
+	err = s.apiUnit.ClaimLeadership(time.Minute)
+	c.Assert(err, gc.ErrorMatches, "leadership claim denied")
+	c.Assert(err, jc.Satisfies, params.IsCodeLeadershipClaimDenied)
+}
+
+func (s *leadershipSuite) TestIsLeader(c *gc.C) {
+	isLeader, err := s.apiUnit.IsLeader()
+	c.Assert(err, gc.IsNil)
+	c.Assert(isLeader, jc.IsFalse)
+
+	err = s.wordpressService.ClaimLeadership(s.wordpressUnit.Name(), time.Minute)
+	c.Assert(err, gc.IsNil)
+	isLeader, err = s.apiUnit.IsLeader()
+	c.Assert(err, gc.IsNil)
+	c.Assert(isLeader, jc.IsTrue)
+}
+
+func (s *leadershipSuite) TestLeaderSettings(c *gc.C) {
+	settings, err := s.apiUnit.LeaderSettings()
+	c.Assert(err, gc.IsNil)
+	c.Assert(settings, gc.HasLen, 0)
+
+	err = s.apiUnit.MergeLeaderSettings(map[string]string{"foo": "bar"})
+	c.Assert(err, gc.ErrorMatches, "unit is not the service leader")
+	c.Assert(err, jc.Satisfies, params.IsCodeNotLeader)
+
+	err = s.apiUnit.ClaimLeadership(time.Minute)
+	c.Assert(err, gc.IsNil)
+	err = s.apiUnit.MergeLeaderSettings(map[string]string{"foo": "bar"})
+	c.Assert(err, gc.IsNil)
+
+	settings, err = s.apiUnit.LeaderSettings()
+	c.Assert(err, gc.IsNil)
+	c.Assert(settings, jc.DeepEquals, map[string]string{"foo": "bar"})
+}
+
+func (s *leadershipSuite) TestWatchLeaderSettings(c *gc.C) {
+	w, err := s.apiUnit.WatchLeaderSettings()
+	c.Assert(err, gc.IsNil)
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.BackingState, w)
+
+	// Initial event.
+	wc.AssertOneChange()
+
+	err = s.wordpressService.ClaimLeadership(s.wordpressUnit.Name(), time.Minute)
+	c.Assert(err, gc.IsNil)
+	wc.AssertNoChange()
+	err = s.wordpressService.MergeLeaderSettings(s.wordpressUnit.Name(), map[string]string{"foo": "bar"})
+	c.Assert(err, gc.IsNil)
+	wc.AssertOneChange()
+
+	statetesting.AssertStop(c, w)
+	wc.AssertClosed()
+}
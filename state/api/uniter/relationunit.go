@@ -39,14 +39,37 @@ func (ru *RelationUnit) Endpoint() Endpoint {
 	return ru.endpoint
 }
 
-// PrivateAddress returns the private address of the unit and whether
-// it is valid.
+// PrivateAddress returns the private address the unit advertises in
+// the relation: its address in the space the relation endpoint is
+// bound to, if any, and its private address otherwise.
 //
 // NOTE: This differs from state.RelationUnit.PrivateAddress() by
 // returning an error instead of a bool, because it needs to make an
 // API call.
 func (ru *RelationUnit) PrivateAddress() (string, error) {
-	return ru.unit.PrivateAddress()
+	var results params.StringResults
+	args := params.RelationUnits{
+		RelationUnits: []params.RelationUnit{{
+			Relation: ru.relation.tag,
+			Unit:     ru.unit.tag,
+		}},
+	}
+	err := ru.st.call("RelationPrivateAddress", args, &results)
+	if params.IsCodeNotImplemented(err) {
+		// Older API servers know nothing of endpoint bindings.
+		return ru.unit.PrivateAddress()
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(results.Results) != 1 {
+		return "", fmt.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return "", result.Error
+	}
+	return result.Result, nil
 }
 
 // EnterScope ensures that the unit has entered its scope in the relation.
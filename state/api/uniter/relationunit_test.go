@@ -103,6 +103,25 @@ func (s *relationUnitSuite) TestPrivateAddress(c *gc.C) {
 	c.Assert(address, gc.Equals, "1.2.3.4")
 }
 
+func (s *relationUnitSuite) TestPrivateAddressWithEndpointBinding(c *gc.C) {
+	_, apiRelUnit := s.getRelationUnits(c)
+	err := s.wordpressMachine.SetAddresses(
+		network.NewAddress("1.2.3.4", network.ScopeCloudLocal),
+		network.NewAddress("10.0.1.4", network.ScopeCloudLocal),
+	)
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.AddSubnet(state.SubnetInfo{CIDR: "10.0.1.0/24"})
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.AddSpace("db", []string{"10.0.1.0/24"})
+	c.Assert(err, gc.IsNil)
+	err = s.wordpressService.SetEndpointBindings(map[string]string{"db": "db"})
+	c.Assert(err, gc.IsNil)
+
+	address, err := apiRelUnit.PrivateAddress()
+	c.Assert(err, gc.IsNil)
+	c.Assert(address, gc.Equals, "10.0.1.4")
+}
+
 func (s *relationUnitSuite) TestEnterScopeSuccessfully(c *gc.C) {
 	// NOTE: This test is not as exhaustive as the ones in state.
 	// Here, we just check the success case, while the two error
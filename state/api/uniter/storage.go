@@ -0,0 +1,61 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter
+
+import (
+	"fmt"
+
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/api/watcher"
+)
+
+// Storage is handled by its own facade, which
+// is only used by the uniter.
+const storageFacade = "Storage"
+
+func (st *State) callStorage(method string, params, results interface{}) error {
+	return st.caller.Call(storageFacade, "", method, params, results)
+}
+
+// StorageInstances returns the storage instances owned by the unit.
+// The location of an instance is empty until its volume has been
+// attached to the unit's machine, or, for a filesystem, until the
+// filesystem has been mounted there.
+func (u *Unit) StorageInstances() ([]params.StorageInstance, error) {
+	var results params.StorageInstancesResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag}},
+	}
+	if err := u.st.callStorage("StorageInstances", args, &results); err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return result.Result, nil
+}
+
+// WatchStorage returns a watcher that notifies when the
+// unit's storage instances are attached or detached.
+func (u *Unit) WatchStorage() (watcher.NotifyWatcher, error) {
+	var results params.NotifyWatchResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag}},
+	}
+	if err := u.st.callStorage("WatchStorage", args, &results); err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return watcher.NewNotifyWatcher(u.st.caller, result), nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter_test
+
+import (
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state/api/uniter"
+	statetesting "github.com/juju/juju/state/testing"
+)
+
+type storageSuite struct {
+	uniterSuite
+
+	apiUnit *uniter.Unit
+}
+
+var _ = gc.Suite(&storageSuite{})
+
+func (s *storageSuite) SetUpTest(c *gc.C) {
+	s.uniterSuite.SetUpTest(c)
+
+	var err error
+	s.apiUnit, err = s.uniter.Unit(s.wordpressUnit.Tag())
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *storageSuite) TestStorageInstances(c *gc.C) {
+	instances, err := s.apiUnit.StorageInstances()
+	c.Assert(err, gc.IsNil)
+	c.Assert(instances, gc.HasLen, 0)
+}
+
+func (s *storageSuite) TestWatchStorage(c *gc.C) {
+	w, err := s.apiUnit.WatchStorage()
+	c.Assert(err, gc.IsNil)
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.BackingState, w)
+
+	// Initial event.
+	wc.AssertOneChange()
+	wc.AssertNoChange()
+}
@@ -6,6 +6,7 @@ package uniter
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/juju/charm"
 	"github.com/juju/names"
@@ -70,6 +71,74 @@ func (u *Unit) SetStatus(status params.Status, info string, data params.StatusDa
 	return result.OneError()
 }
 
+// WorkloadStatus returns the status of the unit's workload, as
+// last set by its charm.
+func (u *Unit) WorkloadStatus() (params.WorkloadStatus, string, error) {
+	var results params.WorkloadStatusResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag}},
+	}
+	err := u.st.call("WorkloadStatus", args, &results)
+	if err != nil {
+		return "", "", err
+	}
+	if len(results.Results) != 1 {
+		return "", "", fmt.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return "", "", result.Error
+	}
+	return result.Status, result.Info, nil
+}
+
+// SetWorkloadStatus sets the status of the unit's workload.
+func (u *Unit) SetWorkloadStatus(status params.WorkloadStatus, info string) error {
+	var result params.ErrorResults
+	args := params.SetWorkloadStatus{
+		Entities: []params.EntityWorkloadStatus{
+			{Tag: u.tag, Status: status, Info: info},
+		},
+	}
+	err := u.st.call("SetWorkloadStatus", args, &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
+
+// SetAgentActivity records what the unit's agent is doing and, if it
+// is running a hook, which one.
+func (u *Unit) SetAgentActivity(activity params.AgentActivity, hook string) error {
+	var result params.ErrorResults
+	args := params.SetAgentActivity{
+		Entities: []params.EntityAgentActivity{
+			{Tag: u.tag, Activity: activity, Hook: hook},
+		},
+	}
+	err := u.st.call("SetAgentActivity", args, &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
+
+// AddMetrics records a batch of metrics reported by the
+// unit, collected at the given time.
+func (u *Unit) AddMetrics(created time.Time, metrics []params.Metric) error {
+	var result params.ErrorResults
+	args := params.MetricBatchParams{
+		Batches: []params.MetricBatchParam{
+			{Tag: u.tag, Created: created, Metrics: metrics},
+		},
+	}
+	err := u.st.call("AddMetricBatches", args, &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
+
 // EnsureDead sets the unit lifecycle to Dead if it is Alive or
 // Dying. It does nothing otherwise.
 func (u *Unit) EnsureDead() error {
@@ -344,6 +413,35 @@ func (u *Unit) ClosePort(protocol string, number int) error {
 	return result.OneError()
 }
 
+// OpenPorts sets the policy of the range of ports from fromPort
+// to toPort with the given protocol to be opened.
+func (u *Unit) OpenPorts(protocol string, fromPort, toPort int) error {
+	return u.changePortRanges("OpenPorts", protocol, fromPort, toPort)
+}
+
+// ClosePorts sets the policy of the range of ports from fromPort
+// to toPort with the given protocol to be closed.
+func (u *Unit) ClosePorts(protocol string, fromPort, toPort int) error {
+	return u.changePortRanges("ClosePorts", protocol, fromPort, toPort)
+}
+
+func (u *Unit) changePortRanges(method, protocol string, fromPort, toPort int) error {
+	var result params.ErrorResults
+	args := params.EntitiesPortRanges{
+		Entities: []params.EntityPortRange{{
+			Tag:      u.tag,
+			Protocol: protocol,
+			FromPort: fromPort,
+			ToPort:   toPort,
+		}},
+	}
+	err := u.st.call(method, args, &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
+
 var ErrNoCharmURLSet = errors.New("unit has no charm url set")
 
 // CharmURL returns the charm URL this unit is currently using.
@@ -432,6 +530,28 @@ func (u *Unit) WatchConfigSettings() (watcher.NotifyWatcher, error) {
 	return w, nil
 }
 
+// WatchActions returns a StringsWatcher that notifies of the ids of
+// actions queued for the unit.
+func (u *Unit) WatchActions() (watcher.StringsWatcher, error) {
+	var results params.StringsWatchResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag}},
+	}
+	err := u.st.call("WatchActions", args, &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	w := watcher.NewStringsWatcher(u.st.caller, result)
+	return w, nil
+}
+
 // JoinedRelations returns the tags of the relations the unit has joined.
 func (u *Unit) JoinedRelations() ([]string, error) {
 	var results params.StringsResults
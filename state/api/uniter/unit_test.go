@@ -5,6 +5,7 @@ package uniter_test
 
 import (
 	"sort"
+	"time"
 
 	"github.com/juju/charm"
 	"github.com/juju/errors"
@@ -64,6 +65,50 @@ func (s *unitSuite) TestSetStatus(c *gc.C) {
 	c.Assert(data, gc.HasLen, 0)
 }
 
+func (s *unitSuite) TestWorkloadStatus(c *gc.C) {
+	status, info, err := s.apiUnit.WorkloadStatus()
+	c.Assert(err, gc.IsNil)
+	c.Assert(status, gc.Equals, params.WorkloadUnknown)
+	c.Assert(info, gc.Equals, "")
+
+	err = s.apiUnit.SetWorkloadStatus(params.WorkloadWaiting, "waiting for mysql")
+	c.Assert(err, gc.IsNil)
+
+	status, info, err = s.wordpressUnit.WorkloadStatus()
+	c.Assert(err, gc.IsNil)
+	c.Assert(status, gc.Equals, params.WorkloadWaiting)
+	c.Assert(info, gc.Equals, "waiting for mysql")
+
+	status, info, err = s.apiUnit.WorkloadStatus()
+	c.Assert(err, gc.IsNil)
+	c.Assert(status, gc.Equals, params.WorkloadWaiting)
+	c.Assert(info, gc.Equals, "waiting for mysql")
+}
+
+func (s *unitSuite) TestSetAgentActivity(c *gc.C) {
+	err := s.apiUnit.SetAgentActivity(params.ActivityExecuting, "config-changed")
+	c.Assert(err, gc.IsNil)
+
+	activity, hook, err := s.wordpressUnit.AgentActivity()
+	c.Assert(err, gc.IsNil)
+	c.Assert(activity, gc.Equals, params.ActivityExecuting)
+	c.Assert(hook, gc.Equals, "config-changed")
+}
+
+func (s *unitSuite) TestAddMetrics(c *gc.C) {
+	err := s.wordpressUnit.SetCharmURL(s.wordpressCharm.URL())
+	c.Assert(err, gc.IsNil)
+	now := time.Now()
+	err = s.apiUnit.AddMetrics(now, []params.Metric{{Key: "pings", Value: "5", Time: now}})
+	c.Assert(err, gc.IsNil)
+
+	batches, err := s.State.MetricBatches()
+	c.Assert(err, gc.IsNil)
+	c.Assert(batches, gc.HasLen, 1)
+	c.Assert(batches[0].Unit(), gc.Equals, "wordpress/0")
+	c.Assert(batches[0].Metrics()[0].Value, gc.Equals, "5")
+}
+
 func (s *unitSuite) TestEnsureDead(c *gc.C) {
 	c.Assert(s.wordpressUnit.Life(), gc.Equals, state.Alive)
 
@@ -261,6 +306,25 @@ func (s *unitSuite) TestOpenClosePort(c *gc.C) {
 	c.Assert(ports, gc.HasLen, 0)
 }
 
+func (s *unitSuite) TestOpenClosePorts(c *gc.C) {
+	err := s.apiUnit.OpenPorts("tcp", 8000, 8080)
+	c.Assert(err, gc.IsNil)
+	err = s.apiUnit.OpenPorts("tcp", 8080, 8090)
+	c.Assert(err, gc.ErrorMatches, `cannot open ports 8080-8090/tcp for unit "wordpress/0": ports conflict with already opened ports 8000-8080/tcp`)
+
+	err = s.wordpressUnit.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.wordpressUnit.OpenedPortRanges(), gc.DeepEquals, []network.PortRange{
+		{Protocol: "tcp", FromPort: 8000, ToPort: 8080},
+	})
+
+	err = s.apiUnit.ClosePorts("tcp", 8000, 8080)
+	c.Assert(err, gc.IsNil)
+	err = s.wordpressUnit.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.wordpressUnit.OpenedPortRanges(), gc.HasLen, 0)
+}
+
 func (s *unitSuite) TestGetSetCharmURL(c *gc.C) {
 	// No charm URL set yet.
 	curl, ok := s.wordpressUnit.CharmURL()
@@ -55,3 +55,33 @@ func (c *Client) RemoveUser(tag string) error {
 	}
 	return results.OneError()
 }
+
+// GrantAccess gives the user the given access level (read,
+// write or admin) to the environment.
+func (c *Client) GrantAccess(username, access string) error {
+	return c.changeAccess("GrantAccess", username, access)
+}
+
+// RevokeAccess takes the given access level to the
+// environment away from the user.
+func (c *Client) RevokeAccess(username, access string) error {
+	return c.changeAccess("RevokeAccess", username, access)
+}
+
+func (c *Client) changeAccess(method, username, access string) error {
+	if !names.IsUser(username) {
+		return fmt.Errorf("invalid user name %q", username)
+	}
+	args := params.ModifyUserAccesses{
+		Changes: []params.ModifyUserAccess{{
+			Tag:    names.NewUserTag(username).String(),
+			Access: access,
+		}},
+	}
+	results := new(params.ErrorResults)
+	err := c.call(method, args, results)
+	if err != nil {
+		return err
+	}
+	return results.OneError()
+}
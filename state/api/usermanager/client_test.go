@@ -72,3 +72,25 @@ func (s *usermanagerSuite) TestCantRemoveAdminUser(c *gc.C) {
 	err := s.usermanager.RemoveUser(state.AdminUser)
 	c.Assert(err, gc.ErrorMatches, "Failed to remove user: Can't deactivate admin user")
 }
+
+func (s *usermanagerSuite) TestGrantAndRevokeAccess(c *gc.C) {
+	err := s.usermanager.AddUser("foobar", "Foo Bar", "password")
+	c.Assert(err, gc.IsNil)
+
+	access, err := s.State.UserAccess("foobar")
+	c.Assert(err, gc.IsNil)
+	c.Assert(access, gc.Equals, state.ReadAccess)
+
+	err = s.usermanager.GrantAccess("foobar", "admin")
+	c.Assert(err, gc.IsNil)
+	err = s.usermanager.RevokeAccess("foobar", "admin")
+	c.Assert(err, gc.IsNil)
+	access, err = s.State.UserAccess("foobar")
+	c.Assert(err, gc.IsNil)
+	c.Assert(access, gc.Equals, state.WriteAccess)
+}
+
+func (s *usermanagerSuite) TestCantChangeAccessOfAdminUser(c *gc.C) {
+	err := s.usermanager.RevokeAccess(state.AdminUser, "admin")
+	c.Assert(err, gc.ErrorMatches, `cannot change access of environment administrator "admin"`)
+}
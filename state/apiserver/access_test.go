@@ -0,0 +1,131 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api"
+	"github.com/juju/juju/state/api/environmentmanager"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/api/usermanager"
+	"github.com/juju/juju/testing/factory"
+)
+
+type accessSuite struct {
+	testing.JujuConnSuite
+}
+
+var _ = gc.Suite(&accessSuite{})
+
+func (s *accessSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+}
+
+func (s *accessSuite) openAPIWithAccess(c *gc.C, access state.Access) *api.State {
+	userFactory := factory.NewFactory(s.State, c)
+	user := userFactory.MakeUser(factory.UserParams{Password: "password"})
+	err := s.State.SetUserAccess(user.Name(), access)
+	c.Assert(err, gc.IsNil)
+	st := s.OpenAPIAs(c, user.Tag(), "password")
+	s.AddCleanup(func(_ *gc.C) { st.Close() })
+	return st
+}
+
+func assertPermissionDenied(c *gc.C, err error) {
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	c.Assert(params.IsCodeUnauthorized(err), jc.IsTrue)
+}
+
+func (s *accessSuite) TestReadAccess(c *gc.C) {
+	client := s.openAPIWithAccess(c, state.ReadAccess).Client()
+
+	_, err := client.Status(nil)
+	c.Assert(err, gc.IsNil)
+	_, err = client.ServiceGet("wordpress")
+	c.Assert(err, gc.IsNil)
+	_, err = client.ConnectionInfo()
+	c.Assert(err, gc.IsNil)
+	watcher, err := client.WatchAll()
+	c.Assert(err, gc.IsNil)
+	c.Assert(watcher.Stop(), gc.IsNil)
+
+	err = client.ServiceDeploy("local:quantal/wordpress-3", "blog", 1, "", constraints.Value{}, "")
+	assertPermissionDenied(c, err)
+	err = client.ServiceExpose("wordpress")
+	assertPermissionDenied(c, err)
+	err = client.DestroyEnvironment()
+	assertPermissionDenied(c, err)
+}
+
+func (s *accessSuite) TestDefaultAccessIsRead(c *gc.C) {
+	userFactory := factory.NewFactory(s.State, c)
+	user := userFactory.MakeUser(factory.UserParams{Password: "password"})
+	st := s.OpenAPIAs(c, user.Tag(), "password")
+	defer st.Close()
+
+	_, err := st.Client().Status(nil)
+	c.Assert(err, gc.IsNil)
+	err = st.Client().ServiceExpose("wordpress")
+	assertPermissionDenied(c, err)
+}
+
+func (s *accessSuite) TestReadAccessProvisioningScript(c *gc.C) {
+	client := s.openAPIWithAccess(c, state.ReadAccess).Client()
+	_, err := client.ProvisioningScript(params.ProvisioningScriptParams{MachineId: "0"})
+	assertPermissionDenied(c, err)
+}
+
+func (s *accessSuite) TestEnvironmentGetHidesSecrets(c *gc.C) {
+	for _, access := range []state.Access{state.ReadAccess, state.WriteAccess} {
+		c.Logf("access %q", access)
+		attrs, err := s.openAPIWithAccess(c, access).Client().EnvironmentGet()
+		c.Assert(err, gc.IsNil)
+		c.Assert(attrs["name"], gc.Equals, "dummyenv")
+		for _, name := range []string{"admin-secret", "ca-private-key", "secret"} {
+			_, ok := attrs[name]
+			c.Check(ok, jc.IsFalse, gc.Commentf("attribute %q", name))
+		}
+	}
+
+	attrs, err := s.openAPIWithAccess(c, state.AdminAccess).Client().EnvironmentGet()
+	c.Assert(err, gc.IsNil)
+	c.Assert(attrs["secret"], gc.Equals, "pork")
+}
+
+func (s *accessSuite) TestReadAccessEnvironmentManager(c *gc.C) {
+	client := environmentmanager.NewClient(s.openAPIWithAccess(c, state.ReadAccess))
+
+	_, err := client.ListEnvironments()
+	c.Assert(err, gc.IsNil)
+	_, err = client.CreateEnvironment("sandbox", nil)
+	assertPermissionDenied(c, err)
+}
+
+func (s *accessSuite) TestWriteAccess(c *gc.C) {
+	st := s.openAPIWithAccess(c, state.WriteAccess)
+
+	err := st.Client().ServiceExpose("wordpress")
+	c.Assert(err, gc.IsNil)
+	err = st.Client().DestroyEnvironment()
+	assertPermissionDenied(c, err)
+	err = usermanager.NewClient(st).AddUser("foobar", "Foo Bar", "password")
+	assertPermissionDenied(c, err)
+	_, err = st.Client().RotateCertificates(true, "")
+	assertPermissionDenied(c, err)
+}
+
+func (s *accessSuite) TestAdminAccess(c *gc.C) {
+	st := s.openAPIWithAccess(c, state.AdminAccess)
+
+	err := st.Client().ServiceExpose("wordpress")
+	c.Assert(err, gc.IsNil)
+	err = usermanager.NewClient(st).AddUser("foobar", "Foo Bar", "password")
+	c.Assert(err, gc.IsNil)
+}
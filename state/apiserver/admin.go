@@ -19,9 +19,10 @@ import (
 	"github.com/juju/juju/state/presence"
 )
 
-func newStateServer(srv *Server, rpcConn *rpc.Conn, reqNotifier *requestNotifier, limiter utils.Limiter) *initialRoot {
+func newStateServer(srv *Server, st *state.State, rpcConn *rpc.Conn, reqNotifier *requestNotifier, limiter utils.Limiter) *initialRoot {
 	r := &initialRoot{
 		srv:     srv,
+		state:   st,
 		rpcConn: rpcConn,
 	}
 	r.admin = &srvAdmin{
@@ -40,6 +41,10 @@ type initialRoot struct {
 	srv     *Server
 	rpcConn *rpc.Conn
 
+	// state holds the State of the environment
+	// that the client has connected to.
+	state *state.State
+
 	admin *srvAdmin
 }
 
@@ -94,7 +99,21 @@ func (a *srvAdmin) Login(c params.Creds) (params.LoginResult, error) {
 		}
 		defer a.limiter.Release()
 	}
-	entity, err := doCheckCreds(a.root.srv.state, c)
+	// Users are shared by all the environments hosted by the
+	// state server, so they are always checked against the state
+	// server's own environment. Agents belong to a single
+	// environment.
+	credsState := a.root.state
+	if kind, err := names.TagKind(c.AuthTag); err == nil && kind == names.UserTagKind {
+		credsState = a.root.srv.state
+	}
+	entity, err := doCheckCreds(credsState, c)
+	if err == common.ErrBadCreds && a.root.state.IsHosted() {
+		// The workers of the hosted environments are run by the
+		// state server's own machines, so those may log into
+		// any of them.
+		entity, err = checkStateServerCreds(a.root.srv.state, c)
+	}
 	if err != nil {
 		return params.LoginResult{}, err
 	}
@@ -104,7 +123,20 @@ func (a *srvAdmin) Login(c params.Creds) (params.LoginResult, error) {
 	// We have authenticated the user; now choose an appropriate API
 	// to serve to them.
 	// TODO: consider switching the new root based on who is logging in
-	newRoot := newSrvRoot(a.root, entity)
+	var access state.Access
+	if user, ok := entity.(*state.User); ok {
+		// Users are shared between environments, but
+		// their access is recorded by each environment.
+		access, err = a.root.state.UserAccess(user.Name())
+		if errors.IsNotFound(err) {
+			// The user has not been granted
+			// access to the environment.
+			return params.LoginResult{}, common.ErrPerm
+		} else if err != nil {
+			return params.LoginResult{}, err
+		}
+	}
+	newRoot := newSrvRoot(a.root, entity, access)
 	if err := a.startPingerIfAgent(newRoot, entity); err != nil {
 		return params.LoginResult{}, err
 	}
@@ -116,7 +148,7 @@ func (a *srvAdmin) Login(c params.Creds) (params.LoginResult, error) {
 	}
 	logger.Debugf("hostPorts: %v", hostPorts)
 
-	environ, err := a.root.srv.state.Environment()
+	environ, err := a.root.state.Environment()
 	if err != nil {
 		return params.LoginResult{}, err
 	}
@@ -155,6 +187,23 @@ func checkCreds(st *state.State, c params.Creds) (taggedAuthenticator, error) {
 	return entity, nil
 }
 
+// checkStateServerCreds is like checkCreds, but only
+// authenticates the machines that manage the environment
+// of the given State.
+func checkStateServerCreds(st *state.State, c params.Creds) (taggedAuthenticator, error) {
+	if kind, err := names.TagKind(c.AuthTag); err != nil || kind != names.MachineTagKind {
+		return nil, common.ErrBadCreds
+	}
+	entity, err := doCheckCreds(st, c)
+	if err != nil {
+		return nil, err
+	}
+	if machine, ok := entity.(*state.Machine); !ok || !machine.IsManager() {
+		return nil, common.ErrBadCreds
+	}
+	return entity, nil
+}
+
 func getAndUpdateLastConnectionForEntity(entity taggedAuthenticator) *time.Time {
 	if user, ok := entity.(*state.User); ok {
 		result := user.LastConnection()
@@ -4,6 +4,7 @@
 package apiserver
 
 import (
+	"bytes"
 	"crypto/tls"
 	"fmt"
 	"net"
@@ -14,6 +15,7 @@ import (
 
 	"code.google.com/p/go.net/websocket"
 	"github.com/bmizerany/pat"
+	"github.com/juju/errors"
 	"github.com/juju/loggo"
 	"github.com/juju/utils"
 	"launchpad.net/tomb"
@@ -42,6 +44,11 @@ type Server struct {
 	logDir      string
 	limiter     utils.Limiter
 	validator   LoginValidator
+
+	// hostedStates holds a State for each hosted
+	// environment that has been connected to.
+	mu           sync.Mutex
+	hostedStates map[string]*state.State
 }
 
 // LoginValidator functions are used to decide whether login requests
@@ -73,22 +80,109 @@ func NewServer(s *state.State, cfg ServerConfig) (*Server, error) {
 		return nil, err
 	}
 	srv := &Server{
-		state:     s,
-		addr:      lis.Addr(),
-		dataDir:   cfg.DataDir,
-		logDir:    cfg.LogDir,
-		limiter:   utils.NewLimiter(loginRateLimit),
-		validator: cfg.Validator,
+		state:        s,
+		addr:         lis.Addr(),
+		dataDir:      cfg.DataDir,
+		logDir:       cfg.LogDir,
+		limiter:      utils.NewLimiter(loginRateLimit),
+		validator:    cfg.Validator,
+		hostedStates: make(map[string]*state.State),
 	}
 	// TODO(rog) check that *srvRoot is a valid type for using
 	// as an RPC server.
-	lis = tls.NewListener(lis, &tls.Config{
-		Certificates: []tls.Certificate{tlsCert},
-	})
-	go srv.run(lis)
+	go srv.run(newChangeCertListener(lis, tlsCert))
 	return srv, nil
 }
 
+// changeCertListener wraps a listener so that accepted connections
+// are served over TLS, with a certificate that can be changed while
+// the listener is in use.
+type changeCertListener struct {
+	net.Listener
+
+	mu     sync.Mutex
+	config *tls.Config
+}
+
+func newChangeCertListener(lis net.Listener, cert tls.Certificate) *changeCertListener {
+	cl := &changeCertListener{Listener: lis}
+	cl.setCertificate(cert)
+	return cl
+}
+
+// Accept implements net.Listener.Accept.
+func (cl *changeCertListener) Accept() (net.Conn, error) {
+	conn, err := cl.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	cl.mu.Lock()
+	config := cl.config
+	cl.mu.Unlock()
+	return tls.Server(conn, config), nil
+}
+
+// setCertificate sets the certificate presented to new connections,
+// and reports whether it is different from the previous one.
+func (cl *changeCertListener) setCertificate(cert tls.Certificate) bool {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	if cl.config != nil && bytes.Equal(cl.config.Certificates[0].Certificate[0], cert.Certificate[0]) {
+		return false
+	}
+	cl.config = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+	}
+	return true
+}
+
+// watchCertificate changes the certificate presented by the server
+// whenever the state server certificate recorded in the state changes,
+// so that certificates can be rotated without restarting the server.
+func (srv *Server) watchCertificate(lis *changeCertListener) {
+	w := srv.state.WatchStateServingInfo()
+	defer func() {
+		if err := w.Stop(); err != nil {
+			logger.Errorf("error stopping state serving info watcher: %v", err)
+		}
+	}()
+	for {
+		select {
+		case <-srv.tomb.Dying():
+			return
+		case _, ok := <-w.Changes():
+			if !ok {
+				logger.Errorf("state serving info watcher died: %v", w.Err())
+				return
+			}
+			if err := srv.updateCertificate(lis); err != nil {
+				logger.Errorf("cannot update server certificate: %v", err)
+			}
+		}
+	}
+}
+
+// updateCertificate makes the listener present the certificate
+// currently recorded in the state.
+func (srv *Server) updateCertificate(lis *changeCertListener) error {
+	info, err := srv.state.StateServingInfo()
+	if errors.IsNotFound(err) || err == nil && info.Cert == "" {
+		// Nothing to change to: the certificate the server was
+		// started with is the only one known.
+		return nil
+	} else if err != nil {
+		return err
+	}
+	tlsCert, err := tls.X509KeyPair([]byte(info.Cert), []byte(info.PrivateKey))
+	if err != nil {
+		return err
+	}
+	if lis.setCertificate(tlsCert) {
+		logger.Infof("server certificate changed")
+	}
+	return nil
+}
+
 // Dead returns a channel that signals when the server has exited.
 func (srv *Server) Dead() <-chan struct{} {
 	return srv.tomb.Dead()
@@ -114,18 +208,25 @@ func (srv *Server) Wait() error {
 type requestNotifier struct {
 	id    int64
 	start time.Time
+	st    *state.State
 
 	mu   sync.Mutex
 	tag_ string
+
+	// audits holds the audit entries of requests
+	// that are in progress, keyed by request id.
+	audits map[uint64]*state.AuditEntry
 }
 
 var globalCounter int64
 
-func newRequestNotifier() *requestNotifier {
+func newRequestNotifier(st *state.State) *requestNotifier {
 	return &requestNotifier{
-		id:    atomic.AddInt64(&globalCounter, 1),
-		tag_:  "<unknown>",
-		start: time.Now(),
+		id:     atomic.AddInt64(&globalCounter, 1),
+		tag_:   "<unknown>",
+		start:  time.Now(),
+		st:     st,
+		audits: make(map[uint64]*state.AuditEntry),
 	}
 }
 
@@ -146,15 +247,34 @@ func (n *requestNotifier) ServerRequest(hdr *rpc.Header, body interface{}) {
 	if hdr.Request.Type == "Pinger" && hdr.Request.Action == "Ping" {
 		return
 	}
-	// TODO(rog) 2013-10-11 remove secrets from some requests.
-	logger.Debugf("<- [%X] %s %s", n.id, n.tag(), jsoncodec.DumpRequest(hdr, body))
+	tag := n.tag()
+	if isAudited(hdr.Request, tag) {
+		entry := newAuditEntry(n.st, tag, hdr.Request, body)
+		n.mu.Lock()
+		n.audits[hdr.RequestId] = entry
+		n.mu.Unlock()
+	}
+	if logger.EffectiveLogLevel() <= loggo.DEBUG {
+		// TODO(rog) 2013-10-11 remove secrets from some requests.
+		logger.Debugf("<- [%X] %s %s", n.id, tag, jsoncodec.DumpRequest(hdr, body))
+	}
 }
 
 func (n *requestNotifier) ServerReply(req rpc.Request, hdr *rpc.Header, body interface{}, timeSpent time.Duration) {
 	if req.Type == "Pinger" && req.Action == "Ping" {
 		return
 	}
-	logger.Debugf("-> [%X] %s %s %s %s[%q].%s", n.id, n.tag(), timeSpent, jsoncodec.DumpRequest(hdr, body), req.Type, req.Id, req.Action)
+	n.mu.Lock()
+	entry := n.audits[hdr.RequestId]
+	delete(n.audits, hdr.RequestId)
+	n.mu.Unlock()
+	if entry != nil {
+		entry.Error = hdr.Error
+		recordAuditEntry(n.st, entry)
+	}
+	if logger.EffectiveLogLevel() <= loggo.DEBUG {
+		logger.Debugf("-> [%X] %s %s %s %s[%q].%s", n.id, n.tag(), timeSpent, jsoncodec.DumpRequest(hdr, body), req.Type, req.Id, req.Action)
+	}
 }
 
 func (n *requestNotifier) join(req *http.Request) {
@@ -180,8 +300,9 @@ func handleAll(mux *pat.PatternServeMux, pattern string, handler http.Handler) {
 	mux.Options(pattern, handler)
 }
 
-func (srv *Server) run(lis net.Listener) {
+func (srv *Server) run(lis *changeCertListener) {
 	defer srv.tomb.Done()
+	defer srv.closeHostedStates()
 	defer srv.wg.Wait() // wait for any outstanding requests to complete.
 	srv.wg.Add(1)
 	go func() {
@@ -190,6 +311,11 @@ func (srv *Server) run(lis net.Listener) {
 		srv.wg.Done()
 	}()
 	srv.wg.Add(1)
+	go func() {
+		srv.watchCertificate(lis)
+		srv.wg.Done()
+	}()
+	srv.wg.Add(1)
 	go func() {
 		err := srv.mongoPinger()
 		srv.tomb.Kill(err)
@@ -201,13 +327,11 @@ func (srv *Server) run(lis net.Listener) {
 	mux := pat.New()
 	// For backwards compatibility we register all the old paths
 	handleAll(mux, "/environment/:envuuid/log",
-		&debugLogHandler{
-			httpHandler: httpHandler{state: srv.state},
-			logDir:      srv.logDir},
+		&debugLogHandler{srv.httpHandler()},
 	)
 	handleAll(mux, "/environment/:envuuid/charms",
 		&charmsHandler{
-			httpHandler: httpHandler{state: srv.state},
+			httpHandler: srv.httpHandler(),
 			dataDir:     srv.dataDir},
 	)
 	// TODO: We can switch from handleAll to mux.Post/Get/etc for entries
@@ -215,22 +339,26 @@ func (srv *Server) run(lis net.Listener) {
 	// tests currently assert that errors come back as application/json and
 	// pat only does "text/plain" responses.
 	handleAll(mux, "/environment/:envuuid/tools",
-		&toolsHandler{httpHandler{state: srv.state}},
+		&toolsHandler{srv.httpHandler()},
+	)
+	handleAll(mux, "/environment/:envuuid/backups",
+		&backupsHandler{srv.httpHandler()},
 	)
 	handleAll(mux, "/environment/:envuuid/api", http.HandlerFunc(srv.apiHandler))
 	// For backwards compatibility we register all the old paths
 	handleAll(mux, "/log",
-		&debugLogHandler{
-			httpHandler: httpHandler{state: srv.state},
-			logDir:      srv.logDir},
+		&debugLogHandler{srv.httpHandler()},
 	)
 	handleAll(mux, "/charms",
 		&charmsHandler{
-			httpHandler: httpHandler{state: srv.state},
+			httpHandler: srv.httpHandler(),
 			dataDir:     srv.dataDir},
 	)
 	handleAll(mux, "/tools",
-		&toolsHandler{httpHandler{state: srv.state}},
+		&toolsHandler{srv.httpHandler()},
+	)
+	handleAll(mux, "/backups",
+		&backupsHandler{srv.httpHandler()},
 	)
 	handleAll(mux, "/", http.HandlerFunc(srv.apiHandler))
 	// The error from http.Serve is not interesting.
@@ -238,7 +366,7 @@ func (srv *Server) run(lis net.Listener) {
 }
 
 func (srv *Server) apiHandler(w http.ResponseWriter, req *http.Request) {
-	reqNotifier := newRequestNotifier()
+	reqNotifier := newRequestNotifier(srv.state)
 	reqNotifier.join(req)
 	defer reqNotifier.leave()
 	wsServer := websocket.Server{
@@ -267,7 +395,10 @@ func (srv *Server) Addr() string {
 	return srv.addr.String()
 }
 
-func (srv *Server) validateEnvironUUID(envUUID string) error {
+// stateForEnviron returns the State for the environment with the
+// given UUID, which may be the state server's own environment or
+// any environment that it hosts.
+func (srv *Server) stateForEnviron(envUUID string) (*state.State, error) {
 	if envUUID == "" {
 		// We allow the environUUID to be empty for 2 cases
 		// 1) Compatibility with older clients
@@ -276,19 +407,53 @@ func (srv *Server) validateEnvironUUID(envUUID string) error {
 		//    threaded that information all the way back to the 'juju
 		//    bootstrap' process to be able to cache the value until
 		//    after we've connected one time.
-		return nil
+		return srv.state, nil
 	}
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
 	if srv.environUUID == "" {
 		env, err := srv.state.Environment()
 		if err != nil {
-			return err
+			return nil, err
 		}
 		srv.environUUID = env.UUID()
 	}
-	if envUUID != srv.environUUID {
-		return common.UnknownEnvironmentError(envUUID)
+	if envUUID == srv.environUUID {
+		return srv.state, nil
+	}
+	if st, ok := srv.hostedStates[envUUID]; ok {
+		return st, nil
+	}
+	st, err := srv.state.ForEnviron(envUUID)
+	if errors.IsNotFound(err) {
+		return nil, common.UnknownEnvironmentError(envUUID)
+	} else if err != nil {
+		return nil, err
+	}
+	srv.hostedStates[envUUID] = st
+	return st, nil
+}
+
+// httpHandler returns the httpHandler embedded by the
+// handlers of the server's HTTP endpoints.
+func (srv *Server) httpHandler() httpHandler {
+	return httpHandler{
+		state:           srv.state,
+		stateForEnviron: srv.stateForEnviron,
+	}
+}
+
+// closeHostedStates closes the States of all the hosted
+// environments that have been connected to.
+func (srv *Server) closeHostedStates() {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	for envUUID, st := range srv.hostedStates {
+		if err := st.Close(); err != nil {
+			logger.Errorf("error closing state for environment %q: %v", envUUID, err)
+		}
+		delete(srv.hostedStates, envUUID)
 	}
-	return nil
 }
 
 func (srv *Server) serveConn(wsConn *websocket.Conn, reqNotifier *requestNotifier, envUUID string) error {
@@ -296,18 +461,14 @@ func (srv *Server) serveConn(wsConn *websocket.Conn, reqNotifier *requestNotifie
 	if loggo.GetLogger("juju.rpc.jsoncodec").EffectiveLogLevel() <= loggo.TRACE {
 		codec.SetLogging(true)
 	}
-	var notifier rpc.RequestNotifier
-	if logger.EffectiveLogLevel() <= loggo.DEBUG {
-		// Incur request monitoring overhead only if we
-		// know we'll need it.
-		notifier = reqNotifier
-	}
-	conn := rpc.NewConn(codec, notifier)
-	err := srv.validateEnvironUUID(envUUID)
+	// The request notifier is always needed, as
+	// it records the audit trail.
+	conn := rpc.NewConn(codec, reqNotifier)
+	st, err := srv.stateForEnviron(envUUID)
 	if err != nil {
 		conn.Serve(&errRoot{err}, serverError)
 	} else {
-		conn.Serve(newStateServer(srv, conn, reqNotifier, srv.limiter), serverError)
+		conn.Serve(newStateServer(srv, st, conn, reqNotifier, srv.limiter), serverError)
 	}
 	conn.Start()
 	select {
@@ -0,0 +1,157 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/juju/names"
+	"github.com/juju/utils/set"
+
+	"github.com/juju/juju/audit"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/rpc"
+	"github.com/juju/juju/state"
+)
+
+// entityArgs holds the names of the request arguments
+// that identify entities in the environment.
+var entityArgs = set.NewStrings(
+	"Endpoints",
+	"MachineNames",
+	"ServiceName",
+	"Tag",
+	"UnitName",
+	"UnitNames",
+)
+
+// redacted replaces the values of secret arguments in audit entries.
+const redacted = "<redacted>"
+
+// isAudited reports whether the given request made by
+// the entity with the given tag should be audited.
+func isAudited(req rpc.Request, tag string) bool {
+	if req.Type != "Client" || readOnlyClientMethods.Contains(req.Action) {
+		return false
+	}
+	_, err := names.ParseTag(tag, names.UserTagKind)
+	return err == nil
+}
+
+// newAuditEntry returns an audit entry recording the given request
+// made by the user with the given tag.
+func newAuditEntry(st *state.State, tag string, req rpc.Request, body interface{}) *state.AuditEntry {
+	entry := &state.AuditEntry{
+		Time:   time.Now(),
+		User:   tag,
+		Facade: req.Type,
+		Method: req.Action,
+	}
+	if body == nil {
+		return entry
+	}
+	// Round trip the arguments through JSON so that we see
+	// them as the client sent them.
+	data, err := json.Marshal(body)
+	if err != nil {
+		logger.Warningf("cannot marshal arguments for audit: %v", err)
+		return entry
+	}
+	var args interface{}
+	if err := json.Unmarshal(data, &args); err != nil {
+		logger.Warningf("cannot unmarshal arguments for audit: %v", err)
+		return entry
+	}
+	entities := set.NewStrings()
+	args = redactArgs(args, secretAttrs(st), entities)
+	if data, err = json.Marshal(args); err == nil {
+		entry.Args = string(data)
+	}
+	entry.Entities = entities.SortedValues()
+	return entry
+}
+
+// secretAttrs returns the names of the secret environment
+// attributes, as reported by the environment's provider.
+func secretAttrs(st *state.State) set.Strings {
+	secrets := set.NewStrings("admin-secret")
+	cfg, err := st.EnvironConfig()
+	if err != nil {
+		logger.Warningf("cannot get environment config for audit: %v", err)
+		return secrets
+	}
+	provider, err := environs.Provider(cfg.Type())
+	if err != nil {
+		logger.Warningf("cannot get environment provider for audit: %v", err)
+		return secrets
+	}
+	attrs, err := provider.SecretAttrs(cfg)
+	if err != nil {
+		logger.Warningf("cannot get secret attributes for audit: %v", err)
+		return secrets
+	}
+	for name := range attrs {
+		secrets.Add(name)
+	}
+	return secrets
+}
+
+// redactArgs returns args with the values of any secrets or
+// passwords replaced, and adds any entities mentioned in args
+// to entities.
+func redactArgs(args interface{}, secrets, entities set.Strings) interface{} {
+	switch args := args.(type) {
+	case map[string]interface{}:
+		for name, value := range args {
+			if secrets.Contains(name) || strings.Contains(strings.ToLower(name), "password") {
+				args[name] = redacted
+				continue
+			}
+			if entityArgs.Contains(name) {
+				addEntities(value, entities)
+			}
+			args[name] = redactArgs(value, secrets, entities)
+		}
+	case []interface{}:
+		for i, value := range args {
+			args[i] = redactArgs(value, secrets, entities)
+		}
+	}
+	return args
+}
+
+func addEntities(value interface{}, entities set.Strings) {
+	switch value := value.(type) {
+	case string:
+		if value != "" {
+			entities.Add(value)
+		}
+	case []interface{}:
+		for _, v := range value {
+			addEntities(v, entities)
+		}
+	}
+}
+
+// auditTagger implements audit.Tagger for a tag.
+type auditTagger string
+
+func (t auditTagger) Tag() string {
+	return string(t)
+}
+
+// recordAuditEntry records the given entry in the state and
+// in the audit log.
+func recordAuditEntry(st *state.State, entry *state.AuditEntry) {
+	if entry.Error != "" {
+		audit.Audit(auditTagger(entry.User), "%s.%s %s failed: %s", entry.Facade, entry.Method, entry.Args, entry.Error)
+	} else {
+		audit.Audit(auditTagger(entry.User), "%s.%s %s", entry.Facade, entry.Method, entry.Args)
+	}
+	if err := st.AddAuditEntry(*entry); err != nil {
+		logger.Errorf("cannot record audit entry: %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+)
+
+type auditSuite struct {
+	testing.JujuConnSuite
+}
+
+var _ = gc.Suite(&auditSuite{})
+
+func (s *auditSuite) auditEntries(c *gc.C) []state.AuditEntry {
+	entries, err := s.State.AuditEntries(state.AuditFilter{}, 0)
+	c.Assert(err, gc.IsNil)
+	return entries
+}
+
+func (s *auditSuite) TestMutatingClientCallAudited(c *gc.C) {
+	s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	err := s.APIState.Client().ServiceExpose("wordpress")
+	c.Assert(err, gc.IsNil)
+
+	entries := s.auditEntries(c)
+	c.Assert(entries, gc.HasLen, 1)
+	entry := entries[0]
+	c.Assert(entry.Time.IsZero(), gc.Equals, false)
+	c.Assert(entry.User, gc.Equals, "user-admin")
+	c.Assert(entry.Facade, gc.Equals, "Client")
+	c.Assert(entry.Method, gc.Equals, "ServiceExpose")
+	c.Assert(entry.Args, gc.Equals, `{"ServiceName":"wordpress"}`)
+	c.Assert(entry.Entities, gc.DeepEquals, []string{"wordpress"})
+	c.Assert(entry.Error, gc.Equals, "")
+}
+
+func (s *auditSuite) TestFailedCallAudited(c *gc.C) {
+	err := s.APIState.Client().DestroyServiceUnits("wordpress/0", "mysql/1")
+	c.Assert(err, gc.NotNil)
+
+	entries := s.auditEntries(c)
+	c.Assert(entries, gc.HasLen, 1)
+	c.Assert(entries[0].Method, gc.Equals, "DestroyServiceUnits")
+	c.Assert(entries[0].Entities, gc.DeepEquals, []string{"mysql/1", "wordpress/0"})
+	c.Assert(entries[0].Error, gc.Not(gc.Equals), "")
+}
+
+func (s *auditSuite) TestReadOnlyClientCallNotAudited(c *gc.C) {
+	_, err := s.APIState.Client().Status(nil)
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.auditEntries(c), gc.HasLen, 0)
+}
+
+func (s *auditSuite) TestSecretsRedacted(c *gc.C) {
+	// The dummy provider reports "secret" as a secret attribute.
+	// Whether or not the call succeeds, its arguments are audited.
+	s.APIState.Client().EnvironmentSet(map[string]interface{}{
+		"secret":         "pork",
+		"admin-secret":   "sausage",
+		"default-series": "trusty",
+	})
+
+	entries := s.auditEntries(c)
+	c.Assert(entries, gc.HasLen, 1)
+	c.Assert(entries[0].Method, gc.Equals, "EnvironmentSet")
+	c.Assert(entries[0].Args, gc.Equals,
+		`{"Config":{"admin-secret":"<redacted>","default-series":"trusty","secret":"<redacted>"}}`)
+}
@@ -0,0 +1,58 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package auditlog
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/common"
+)
+
+// AuditLogAPI implements the API end point used to query the audit
+// trail of calls made to the API server.
+type AuditLogAPI struct {
+	st *state.State
+}
+
+// NewAuditLogAPI returns a new AuditLogAPI.
+func NewAuditLogAPI(st *state.State, authorizer common.Authorizer) (*AuditLogAPI, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	return &AuditLogAPI{st: st}, nil
+}
+
+// Entries returns the audit entries matching the given filter,
+// oldest first.
+func (api *AuditLogAPI) Entries(args params.AuditLogFilter) (params.AuditLogResults, error) {
+	var result params.AuditLogResults
+	if args.Limit < 0 {
+		return result, errors.Errorf("invalid limit %d", args.Limit)
+	}
+	filter := state.AuditFilter{
+		User:   args.User,
+		Entity: args.Entity,
+		After:  args.After,
+		Before: args.Before,
+	}
+	entries, err := api.st.AuditEntries(filter, args.Limit)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	result.Entries = make([]params.AuditLogEntry, len(entries))
+	for i, entry := range entries {
+		result.Entries[i] = params.AuditLogEntry{
+			Time:     entry.Time,
+			User:     entry.User,
+			Facade:   entry.Facade,
+			Method:   entry.Method,
+			Args:     entry.Args,
+			Entities: entry.Entities,
+			Error:    entry.Error,
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package auditlog_test
+
+import (
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/auditlog"
+	apiservertesting "github.com/juju/juju/state/apiserver/testing"
+)
+
+type auditLogSuite struct {
+	jujutesting.JujuConnSuite
+
+	api        *auditlog.AuditLogAPI
+	authorizer apiservertesting.FakeAuthorizer
+}
+
+var _ = gc.Suite(&auditLogSuite{})
+
+func (s *auditLogSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.authorizer = apiservertesting.FakeAuthorizer{
+		Tag:      "user-admin",
+		LoggedIn: true,
+		Client:   true,
+	}
+	var err error
+	s.api, err = auditlog.NewAuditLogAPI(s.State, s.authorizer)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *auditLogSuite) TestNewAuditLogAPIRefusesNonClient(c *gc.C) {
+	anAuthoriser := s.authorizer
+	anAuthoriser.Client = false
+	api, err := auditlog.NewAuditLogAPI(s.State, anAuthoriser)
+	c.Assert(api, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *auditLogSuite) TestEntries(c *gc.C) {
+	start := time.Date(2014, 7, 14, 12, 0, 0, 0, time.UTC)
+	for i, user := range []string{"user-admin", "user-bob", "user-admin"} {
+		err := s.State.AddAuditEntry(state.AuditEntry{
+			Time:     start.Add(time.Duration(i) * time.Minute),
+			User:     user,
+			Facade:   "Client",
+			Method:   "ServiceExpose",
+			Args:     `{"ServiceName":"wordpress"}`,
+			Entities: []string{"wordpress"},
+		})
+		c.Assert(err, gc.IsNil)
+	}
+
+	result, err := s.api.Entries(params.AuditLogFilter{User: "user-admin"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Entries, gc.HasLen, 2)
+	entry := result.Entries[1]
+	c.Assert(entry.Time.Equal(start.Add(2*time.Minute)), gc.Equals, true)
+	entry.Time = time.Time{}
+	c.Assert(entry, gc.DeepEquals, params.AuditLogEntry{
+		User:     "user-admin",
+		Facade:   "Client",
+		Method:   "ServiceExpose",
+		Args:     `{"ServiceName":"wordpress"}`,
+		Entities: []string{"wordpress"},
+	})
+
+	result, err = s.api.Entries(params.AuditLogFilter{Entity: "wordpress", Limit: 1})
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Entries, gc.HasLen, 1)
+	c.Assert(result.Entries[0].Time.Equal(start.Add(2*time.Minute)), gc.Equals, true)
+
+	result, err = s.api.Entries(params.AuditLogFilter{After: start.Add(3 * time.Minute)})
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Entries, gc.HasLen, 0)
+}
+
+func (s *auditLogSuite) TestEntriesInvalidLimit(c *gc.C) {
+	_, err := s.api.Entries(params.AuditLogFilter{Limit: -1})
+	c.Assert(err, gc.ErrorMatches, "invalid limit -1")
+}
@@ -0,0 +1,103 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/common"
+)
+
+// backupsHandler handles backup archive downloads through HTTPS in
+// the API server.
+type backupsHandler struct {
+	httpHandler
+}
+
+func (h *backupsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, err := h.authenticate(r)
+	if err != nil {
+		h.authError(w, h)
+		return
+	}
+	st, err := h.environState(r)
+	if err != nil {
+		h.sendError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	// Backups hold the secrets of the state server.
+	if err := h.checkAccess(st, user, state.AdminAccess); err != nil {
+		h.sendError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	// Serve the request from the environment it names.
+	envHandler := *h
+	envHandler.state = st
+	h = &envHandler
+
+	switch r.Method {
+	case "GET":
+		// Download the archive of the backup given by the "id" query.
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			h.sendError(w, http.StatusBadRequest, "expected id query argument")
+			return
+		}
+		if err := h.sendArchive(w, id); err != nil {
+			status := http.StatusInternalServerError
+			if errors.IsNotFound(err) {
+				status = http.StatusNotFound
+			}
+			h.sendError(w, status, err.Error())
+		}
+	default:
+		h.sendError(w, http.StatusMethodNotAllowed, fmt.Sprintf("unsupported method: %q", r.Method))
+	}
+}
+
+// sendArchive writes the archive of the backup with the given id to w.
+func (h *backupsHandler) sendArchive(w http.ResponseWriter, id string) error {
+	meta, err := h.state.BackupMetadata(id)
+	if err != nil {
+		return err
+	}
+	if !meta.Stored() {
+		return errors.NotFoundf("archive for backup %q", id)
+	}
+	archive, err := h.state.BackupStorage().Get(meta.ArchivePath())
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+	w.Header().Set("Content-Type", "application/x-tar-gz")
+	w.Header().Set("Content-Length", strconv.FormatInt(meta.Size(), 10))
+	w.Header().Set("Digest", "SHA="+meta.Checksum())
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, archive); err != nil {
+		logger.Errorf("error sending backup %q: %v", id, err)
+	}
+	return nil
+}
+
+// sendError sends a JSON-encoded error response.
+func (h *backupsHandler) sendError(w http.ResponseWriter, statusCode int, message string) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	body, err := json.Marshal(&params.ErrorResult{
+		Error: common.ServerError(fmt.Errorf(message)),
+	})
+	if err != nil {
+		return err
+	}
+	w.Write(body)
+	return nil
+}
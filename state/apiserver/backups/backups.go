@@ -0,0 +1,258 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/common"
+	"github.com/juju/juju/state/backups"
+	"github.com/juju/juju/version"
+)
+
+var logger = loggo.GetLogger("juju.state.apiserver.backups")
+
+// These are overridden in tests.
+var (
+	createBackup  = backups.Create
+	restoreBackup = backups.Restore
+)
+
+// BackupsAPI implements the API end point used to create, list and
+// restore backups of the state server.
+type BackupsAPI struct {
+	st    *state.State
+	paths backups.Paths
+}
+
+// NewBackupsAPI returns a new BackupsAPI, backing up the data found
+// at the given paths.
+func NewBackupsAPI(st *state.State, authorizer common.Authorizer, paths backups.Paths) (*BackupsAPI, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	return &BackupsAPI{
+		st:    st,
+		paths: paths,
+	}, nil
+}
+
+// dbInfo returns the information needed to connect to the database
+// as the API server does.
+func (api *BackupsAPI) dbInfo() backups.DBInfo {
+	info := api.st.MongoConnectionInfo()
+	var address string
+	if len(info.Addrs) > 0 {
+		address = info.Addrs[0]
+	}
+	return backups.DBInfo{
+		Address:  address,
+		Username: info.Tag,
+		Password: info.Password,
+	}
+}
+
+// machineId returns the id of the state server machine the API
+// server is running on, if known.
+func (api *BackupsAPI) machineId() string {
+	tag, err := names.ParseTag(api.st.MongoConnectionInfo().Tag, names.MachineTagKind)
+	if err != nil {
+		return ""
+	}
+	return tag.Id()
+}
+
+// Create makes a new backup of the state server, stores its archive
+// in the backup storage and returns its metadata.
+func (api *BackupsAPI) Create(args params.BackupsCreateArgs) (params.BackupsMetadataResult, error) {
+	var result params.BackupsMetadataResult
+	env, err := api.st.Environment()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		logger.Warningf("cannot get host name: %v", err)
+	}
+	meta := backups.Metadata{
+		Started:     time.Now().UTC(),
+		Notes:       args.Notes,
+		Environment: env.UUID(),
+		Machine:     api.machineId(),
+		Hostname:    hostname,
+		Version:     version.Current.Number,
+	}
+	archive, err := createBackup(api.paths, api.dbInfo(), meta)
+	if err != nil {
+		return result, errors.Annotate(err, "cannot create backup")
+	}
+	defer archive.Close()
+
+	stored, err := api.st.AddBackupMetadata(state.BackupMetadataParams{
+		Started:        meta.Started,
+		Finished:       archive.Finished,
+		Checksum:       archive.Checksum,
+		ChecksumFormat: backups.ChecksumFormat,
+		Size:           archive.Size,
+		Notes:          meta.Notes,
+		Environment:    meta.Environment,
+		Machine:        meta.Machine,
+		Hostname:       meta.Hostname,
+		Version:        meta.Version,
+	})
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	if _, err := api.st.BackupStorage().Put(stored.ArchivePath(), archive.File, archive.Size); err != nil {
+		return result, errors.Annotate(err, "cannot store backup archive")
+	}
+	if err := api.st.SetBackupStored(stored.Id()); err != nil {
+		return result, errors.Trace(err)
+	}
+	stored, err = api.st.BackupMetadata(stored.Id())
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	return metadataResult(stored), nil
+}
+
+// Info returns the metadata of the requested backup.
+func (api *BackupsAPI) Info(args params.BackupsInfoArgs) (params.BackupsMetadataResult, error) {
+	meta, err := api.st.BackupMetadata(args.ID)
+	if err != nil {
+		return params.BackupsMetadataResult{}, err
+	}
+	return metadataResult(meta), nil
+}
+
+// List returns the metadata of every backup.
+func (api *BackupsAPI) List() (params.BackupsListResult, error) {
+	var result params.BackupsListResult
+	all, err := api.st.AllBackupMetadata()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	result.List = make([]params.BackupsMetadataResult, len(all))
+	for i, meta := range all {
+		result.List[i] = metadataResult(meta)
+	}
+	return result, nil
+}
+
+// Remove deletes the requested backup and its archive.
+func (api *BackupsAPI) Remove(args params.BackupsRemoveArgs) error {
+	return api.st.RemoveBackup(args.ID)
+}
+
+// Restore replaces the state server's files and database with those
+// held in the requested backup, after verifying the archive against
+// its recorded checksum. The agents of the other state servers must
+// be stopped first. The backups themselves are not restored, and the
+// state server's agents must be restarted for the restored data to
+// take effect.
+func (api *BackupsAPI) Restore(args params.BackupsRestoreArgs) error {
+	meta, err := api.st.BackupMetadata(args.ID)
+	if err != nil {
+		return err
+	}
+	if !meta.Stored() {
+		return errors.Errorf("backup %q has no stored archive", args.ID)
+	}
+	env, err := api.st.Environment()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := api.verifyArchive(meta); err != nil {
+		return errors.Trace(err)
+	}
+	if err := api.checkOtherStateServersStopped(); err != nil {
+		return errors.Trace(err)
+	}
+	r, err := api.st.BackupStorage().Get(meta.ArchivePath())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer r.Close()
+	logger.Infof("restoring backup %q", meta.Id())
+	if err := restoreBackup(r, api.paths, api.dbInfo(), env.UUID()); err != nil {
+		return errors.Annotatef(err, "cannot restore backup %q", meta.Id())
+	}
+	return api.st.ResetBackupTransactions()
+}
+
+// checkOtherStateServersStopped returns an error if the agent of any
+// state server machine other than the API server's is running, as it
+// would change the database while it is being restored.
+func (api *BackupsAPI) checkOtherStateServersStopped() error {
+	info, err := api.st.StateServerInfo()
+	if err != nil {
+		return err
+	}
+	var running []string
+	for _, id := range info.MachineIds {
+		if id == api.machineId() {
+			continue
+		}
+		m, err := api.st.Machine(id)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		alive, err := m.AgentAlive()
+		if err != nil {
+			return err
+		}
+		if alive {
+			running = append(running, id)
+		}
+	}
+	if len(running) > 0 {
+		return errors.Errorf("cannot restore while other state servers are running: stop the agents of machines %s first", strings.Join(running, ", "))
+	}
+	return nil
+}
+
+// verifyArchive checks that the stored archive of the given backup
+// matches its recorded checksum.
+func (api *BackupsAPI) verifyArchive(meta *state.BackupMetadata) error {
+	r, err := api.st.BackupStorage().Get(meta.ArchivePath())
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	checksum, err := backups.Checksum(r)
+	if err != nil {
+		return err
+	}
+	if checksum != meta.Checksum() {
+		return errors.Errorf("backup %q archive is corrupt: checksum mismatch", meta.Id())
+	}
+	return nil
+}
+
+func metadataResult(meta *state.BackupMetadata) params.BackupsMetadataResult {
+	return params.BackupsMetadataResult{
+		ID:             meta.Id(),
+		Checksum:       meta.Checksum(),
+		ChecksumFormat: meta.ChecksumFormat(),
+		Size:           meta.Size(),
+		Stored:         meta.Stored(),
+		Started:        meta.Started(),
+		Finished:       meta.Finished(),
+		Notes:          meta.Notes(),
+		Environment:    meta.Environment(),
+		Machine:        meta.Machine(),
+		Hostname:       meta.Hostname(),
+		Version:        meta.Version(),
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/backups"
+	apiservertesting "github.com/juju/juju/state/apiserver/testing"
+	statebackups "github.com/juju/juju/state/backups"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/version"
+)
+
+type backupsSuite struct {
+	jujutesting.JujuConnSuite
+
+	api        *backups.BackupsAPI
+	authorizer apiservertesting.FakeAuthorizer
+	paths      statebackups.Paths
+	data       string
+	restored   string
+}
+
+var _ = gc.Suite(&backupsSuite{})
+
+func (s *backupsSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.authorizer = apiservertesting.FakeAuthorizer{
+		Tag:      "user-admin",
+		LoggedIn: true,
+		Client:   true,
+	}
+	s.paths = statebackups.Paths{DataDir: c.MkDir(), LogDir: c.MkDir()}
+	var err error
+	s.api, err = backups.NewBackupsAPI(s.State, s.authorizer, s.paths)
+	c.Assert(err, gc.IsNil)
+
+	s.data = "archive data"
+	s.restored = ""
+	s.PatchValue(backups.CreateBackup, s.createBackup)
+	s.PatchValue(backups.RestoreBackup, s.restoreBackup)
+}
+
+func (s *backupsSuite) createBackup(paths statebackups.Paths, dbInfo statebackups.DBInfo, meta statebackups.Metadata) (*statebackups.Archive, error) {
+	if paths != s.paths {
+		return nil, errors.Errorf("unexpected paths %#v", paths)
+	}
+	f, err := ioutil.TempFile("", "backups-test")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.WriteString(s.data); err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		return nil, err
+	}
+	checksum, err := statebackups.Checksum(bytes.NewReader([]byte(s.data)))
+	if err != nil {
+		return nil, err
+	}
+	return &statebackups.Archive{
+		File:     f,
+		Size:     int64(len(s.data)),
+		Checksum: checksum,
+		Finished: meta.Started.Add(time.Second),
+	}, nil
+}
+
+func (s *backupsSuite) restoreBackup(r io.Reader, paths statebackups.Paths, dbInfo statebackups.DBInfo, envUUID string) error {
+	data, err := ioutil.ReadAll(r)
+	s.restored = string(data)
+	return err
+}
+
+func (s *backupsSuite) TestNewBackupsAPIRefusesNonClient(c *gc.C) {
+	authorizer := s.authorizer
+	authorizer.Client = false
+	api, err := backups.NewBackupsAPI(s.State, authorizer, s.paths)
+	c.Assert(api, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *backupsSuite) TestCreate(c *gc.C) {
+	result, err := s.api.Create(params.BackupsCreateArgs{Notes: "before upgrade"})
+	c.Assert(err, gc.IsNil)
+
+	env, err := s.State.Environment()
+	c.Assert(err, gc.IsNil)
+	hostname, err := os.Hostname()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.ID, gc.Not(gc.Equals), "")
+	c.Assert(result.Notes, gc.Equals, "before upgrade")
+	c.Assert(result.Environment, gc.Equals, env.UUID())
+	c.Assert(result.Hostname, gc.Equals, hostname)
+	c.Assert(result.Version, gc.Equals, version.Current.Number)
+	c.Assert(result.Size, gc.Equals, int64(len(s.data)))
+	c.Assert(result.ChecksumFormat, gc.Equals, statebackups.ChecksumFormat)
+	c.Assert(result.Stored, jc.IsTrue)
+
+	meta, err := s.State.BackupMetadata(result.ID)
+	c.Assert(err, gc.IsNil)
+	r, err := s.State.BackupStorage().Get(meta.ArchivePath())
+	c.Assert(err, gc.IsNil)
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(data), gc.Equals, s.data)
+}
+
+func (s *backupsSuite) TestCreateError(c *gc.C) {
+	s.PatchValue(backups.CreateBackup, func(statebackups.Paths, statebackups.DBInfo, statebackups.Metadata) (*statebackups.Archive, error) {
+		return nil, errors.New("mongodump failed")
+	})
+	_, err := s.api.Create(params.BackupsCreateArgs{})
+	c.Assert(err, gc.ErrorMatches, "cannot create backup: mongodump failed")
+
+	result, err := s.api.List()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.List, gc.HasLen, 0)
+}
+
+func (s *backupsSuite) TestInfoListAndRemove(c *gc.C) {
+	created, err := s.api.Create(params.BackupsCreateArgs{})
+	c.Assert(err, gc.IsNil)
+
+	info, err := s.api.Info(params.BackupsInfoArgs{ID: created.ID})
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.ID, gc.Equals, created.ID)
+	c.Assert(info.Checksum, gc.Equals, created.Checksum)
+
+	result, err := s.api.List()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.List, gc.HasLen, 1)
+	c.Assert(result.List[0].ID, gc.Equals, created.ID)
+
+	err = s.api.Remove(params.BackupsRemoveArgs{ID: created.ID})
+	c.Assert(err, gc.IsNil)
+	_, err = s.api.Info(params.BackupsInfoArgs{ID: created.ID})
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	result, err = s.api.List()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.List, gc.HasLen, 0)
+}
+
+func (s *backupsSuite) TestRestore(c *gc.C) {
+	created, err := s.api.Create(params.BackupsCreateArgs{})
+	c.Assert(err, gc.IsNil)
+
+	err = s.api.Restore(params.BackupsRestoreArgs{ID: created.ID})
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.restored, gc.Equals, s.data)
+}
+
+func (s *backupsSuite) TestRestoreCorruptArchive(c *gc.C) {
+	created, err := s.api.Create(params.BackupsCreateArgs{})
+	c.Assert(err, gc.IsNil)
+	meta, err := s.State.BackupMetadata(created.ID)
+	c.Assert(err, gc.IsNil)
+	stor := s.State.BackupStorage()
+	err = stor.Remove(meta.ArchivePath())
+	c.Assert(err, gc.IsNil)
+	corrupt := "corrupt data"
+	_, err = stor.Put(meta.ArchivePath(), bytes.NewReader([]byte(corrupt)), int64(len(corrupt)))
+	c.Assert(err, gc.IsNil)
+
+	err = s.api.Restore(params.BackupsRestoreArgs{ID: created.ID})
+	c.Assert(err, gc.ErrorMatches, `backup ".*" archive is corrupt: checksum mismatch`)
+	c.Assert(s.restored, gc.Equals, "")
+}
+
+func (s *backupsSuite) TestRestoreUnknownBackup(c *gc.C) {
+	err := s.api.Restore(params.BackupsRestoreArgs{ID: "foo"})
+	c.Assert(err, gc.ErrorMatches, `backup "foo" not found`)
+}
+
+func (s *backupsSuite) TestRestoreRefusedWhileOtherStateServersRun(c *gc.C) {
+	created, err := s.api.Create(params.BackupsCreateArgs{})
+	c.Assert(err, gc.IsNil)
+	m, err := s.State.AddMachine("quantal", state.JobManageEnviron)
+	c.Assert(err, gc.IsNil)
+	pinger, err := m.SetAgentAlive()
+	c.Assert(err, gc.IsNil)
+	s.State.StartSync()
+	err = m.WaitAgentAlive(coretesting.LongWait)
+	c.Assert(err, gc.IsNil)
+
+	err = s.api.Restore(params.BackupsRestoreArgs{ID: created.ID})
+	c.Assert(err, gc.ErrorMatches, "cannot restore while other state servers are running: stop the agents of machines 0 first")
+	c.Assert(s.restored, gc.Equals, "")
+
+	err = pinger.Kill()
+	c.Assert(err, gc.IsNil)
+	s.State.StartSync()
+	err = s.api.Restore(params.BackupsRestoreArgs{ID: created.ID})
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.restored, gc.Equals, s.data)
+
+	// The backup metadata is still usable once restored.
+	err = s.api.Remove(params.BackupsRemoveArgs{ID: created.ID})
+	c.Assert(err, gc.IsNil)
+}
@@ -0,0 +1,9 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups
+
+var (
+	CreateBackup  = &createBackup
+	RestoreBackup = &restoreBackup
+)
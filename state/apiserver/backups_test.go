@@ -0,0 +1,106 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+)
+
+type backupsSuite struct {
+	authHttpSuite
+}
+
+var _ = gc.Suite(&backupsSuite{})
+
+func (s *backupsSuite) backupsURI(c *gc.C, query string) string {
+	uri := s.baseURL(c)
+	uri.Path = "/backups"
+	uri.RawQuery = query
+	return uri.String()
+}
+
+func (s *backupsSuite) assertErrorResponse(c *gc.C, resp *http.Response, expCode int, expError string) {
+	body := assertResponse(c, resp, expCode, "application/json")
+	var result params.ErrorResult
+	err := json.Unmarshal(body, &result)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Error, gc.NotNil)
+	c.Check(result.Error, gc.ErrorMatches, expError)
+}
+
+func (s *backupsSuite) addBackup(c *gc.C, data string, stored bool) *state.BackupMetadata {
+	meta, err := s.State.AddBackupMetadata(state.BackupMetadataParams{
+		Started:     time.Date(2014, 7, 14, 12, 30, 0, 0, time.UTC),
+		Checksum:    "checksum",
+		Size:        int64(len(data)),
+		Environment: "env-uuid",
+	})
+	c.Assert(err, gc.IsNil)
+	if stored {
+		_, err = s.State.BackupStorage().Put(meta.ArchivePath(), bytes.NewReader([]byte(data)), int64(len(data)))
+		c.Assert(err, gc.IsNil)
+		err = s.State.SetBackupStored(meta.Id())
+		c.Assert(err, gc.IsNil)
+	}
+	return meta
+}
+
+func (s *backupsSuite) TestRequiresAuth(c *gc.C) {
+	resp, err := s.sendRequest(c, "", "", "GET", s.backupsURI(c, "id=foo"), "", nil)
+	c.Assert(err, gc.IsNil)
+	s.assertErrorResponse(c, resp, http.StatusUnauthorized, "unauthorized")
+}
+
+func (s *backupsSuite) TestRequiresAdminAccess(c *gc.C) {
+	meta := s.addBackup(c, "archive data", true)
+	for _, access := range []state.Access{state.ReadAccess, state.WriteAccess} {
+		c.Logf("access %q", access)
+		tag := s.userWithAccess(c, access)
+		resp, err := s.sendRequest(c, tag, s.password, "GET", s.backupsURI(c, "id="+meta.Id()), "", nil)
+		c.Assert(err, gc.IsNil)
+		s.assertErrorResponse(c, resp, http.StatusForbidden, "permission denied")
+	}
+}
+
+func (s *backupsSuite) TestRequiresGET(c *gc.C) {
+	resp, err := s.authRequest(c, "POST", s.backupsURI(c, "id=foo"), "", nil)
+	c.Assert(err, gc.IsNil)
+	s.assertErrorResponse(c, resp, http.StatusMethodNotAllowed, `unsupported method: "POST"`)
+}
+
+func (s *backupsSuite) TestRequiresId(c *gc.C) {
+	resp, err := s.authRequest(c, "GET", s.backupsURI(c, ""), "", nil)
+	c.Assert(err, gc.IsNil)
+	s.assertErrorResponse(c, resp, http.StatusBadRequest, "expected id query argument")
+}
+
+func (s *backupsSuite) TestUnknownBackup(c *gc.C) {
+	resp, err := s.authRequest(c, "GET", s.backupsURI(c, "id=foo"), "", nil)
+	c.Assert(err, gc.IsNil)
+	s.assertErrorResponse(c, resp, http.StatusNotFound, `backup "foo" not found`)
+}
+
+func (s *backupsSuite) TestBackupNotStored(c *gc.C) {
+	meta := s.addBackup(c, "archive data", false)
+	resp, err := s.authRequest(c, "GET", s.backupsURI(c, "id="+meta.Id()), "", nil)
+	c.Assert(err, gc.IsNil)
+	s.assertErrorResponse(c, resp, http.StatusNotFound, `archive for backup ".*" not found`)
+}
+
+func (s *backupsSuite) TestDownload(c *gc.C) {
+	meta := s.addBackup(c, "archive data", true)
+	resp, err := s.authRequest(c, "GET", s.backupsURI(c, "id="+meta.Id()), "", nil)
+	c.Assert(err, gc.IsNil)
+	c.Check(resp.Header.Get("Digest"), gc.Equals, "SHA=checksum")
+	body := assertResponse(c, resp, http.StatusOK, "application/x-tar-gz")
+	c.Assert(string(body), gc.Equals, "archive data")
+}
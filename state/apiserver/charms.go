@@ -26,6 +26,7 @@ import (
 	ziputil "github.com/juju/utils/zip"
 
 	"github.com/juju/juju/environs"
+	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/api/params"
 )
 
@@ -40,14 +41,29 @@ type charmsHandler struct {
 type bundleContentSenderFunc func(w http.ResponseWriter, r *http.Request, bundle *charm.Bundle)
 
 func (h *charmsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	if err := h.authenticate(r); err != nil {
+	user, err := h.authenticate(r)
+	if err != nil {
 		h.authError(w, h)
 		return
 	}
-	if err := h.validateEnvironUUID(r); err != nil {
+	st, err := h.environState(r)
+	if err != nil {
 		h.sendError(w, http.StatusNotFound, err.Error())
 		return
 	}
+	// Uploading charms changes the environment.
+	access := state.ReadAccess
+	if r.Method == "POST" {
+		access = state.WriteAccess
+	}
+	if err := h.checkAccess(st, user, access); err != nil {
+		h.sendError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	// Serve the request from the environment it names.
+	envHandler := *h
+	envHandler.state = st
+	h = &envHandler
 
 	switch r.Method {
 	case "POST":
@@ -400,7 +416,12 @@ func (h *charmsHandler) processGet(r *http.Request) (string, string, error) {
 
 	// Prepare the bundle directories.
 	name := charm.Quote(curl)
-	charmArchivePath := filepath.Join(h.dataDir, "charm-get-cache", name+".zip")
+	cacheDir := filepath.Join(h.dataDir, "charm-get-cache")
+	if h.state.IsHosted() {
+		// Local charm URLs are only unique within an environment.
+		cacheDir = filepath.Join(cacheDir, h.getEnvironUUID(r))
+	}
+	charmArchivePath := filepath.Join(cacheDir, name+".zip")
 
 	// Check if the charm archive is already in the cache.
 	if _, err := os.Stat(charmArchivePath); os.IsNotExist(err) {
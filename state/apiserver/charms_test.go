@@ -38,8 +38,16 @@ type authHttpSuite struct {
 func (s *authHttpSuite) SetUpTest(c *gc.C) {
 	s.JujuConnSuite.SetUpTest(c)
 	s.password = "password"
+	s.userTag = s.userWithAccess(c, state.AdminAccess)
+}
+
+// userWithAccess adds a user with the suite's password and the given
+// access to the environment, and returns the user's tag.
+func (s *authHttpSuite) userWithAccess(c *gc.C, access state.Access) string {
 	user := s.Factory.MakeUser(factory.UserParams{Password: s.password})
-	s.userTag = user.Tag()
+	err := s.State.SetUserAccess(user.Name(), access)
+	c.Assert(err, gc.IsNil)
+	return user.Tag()
 }
 
 func (s *authHttpSuite) sendRequest(c *gc.C, tag, password, method, uri, contentType string, body io.Reader) (*http.Response, error) {
@@ -136,6 +144,18 @@ func (s *charmsSuite) TestAuthRequiresUser(c *gc.C) {
 	s.assertErrorResponse(c, resp, http.StatusBadRequest, "expected url=CharmURL query argument")
 }
 
+func (s *charmsSuite) TestUploadRequiresWriteAccess(c *gc.C) {
+	tag := s.userWithAccess(c, state.ReadAccess)
+	resp, err := s.sendRequest(c, tag, s.password, "POST", s.charmsURI(c, "?series=quantal"), "", nil)
+	c.Assert(err, gc.IsNil)
+	s.assertErrorResponse(c, resp, http.StatusForbidden, "permission denied")
+
+	// Users with read access can still get charms.
+	resp, err = s.sendRequest(c, tag, s.password, "GET", s.charmsURI(c, ""), "", nil)
+	c.Assert(err, gc.IsNil)
+	s.assertErrorResponse(c, resp, http.StatusBadRequest, "expected url=CharmURL query argument")
+}
+
 func (s *charmsSuite) TestUploadRequiresSeries(c *gc.C) {
 	resp, err := s.authRequest(c, "POST", s.charmsURI(c, ""), "", nil)
 	c.Assert(err, gc.IsNil)
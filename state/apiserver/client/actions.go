@@ -0,0 +1,170 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/binary132/gojsonschema"
+	"github.com/juju/charm"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/common"
+)
+
+// EnqueueAction queues the given actions for execution by their units,
+// after validating each action's parameters against the actions schema
+// of the unit's charm. The returned results hold the id assigned to
+// each queued action.
+func (c *Client) EnqueueAction(args params.Actions) (params.ActionResults, error) {
+	result := params.ActionResults{
+		Results: make([]params.ActionResult, len(args.Actions)),
+	}
+	for i, arg := range args.Actions {
+		id, err := c.enqueueOneAction(arg)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		action := arg
+		action.Id = id
+		result.Results[i].Action = action
+		result.Results[i].Status = params.ActionPending
+	}
+	return result, nil
+}
+
+func (c *Client) enqueueOneAction(arg params.Action) (string, error) {
+	unit, err := c.unitFromTag(arg.Unit)
+	if err != nil {
+		return "", err
+	}
+	service, err := unit.Service()
+	if err != nil {
+		return "", err
+	}
+	ch, _, err := service.Charm()
+	if err != nil {
+		return "", err
+	}
+	if err := validateActionParams(ch.Actions(), arg.Name, arg.Params); err != nil {
+		return "", err
+	}
+	return unit.AddAction(arg.Name, arg.Params)
+}
+
+// ListActions returns the actions still queued for each given unit.
+func (c *Client) ListActions(args params.Entities) (params.ActionsByUnits, error) {
+	result := params.ActionsByUnits{
+		Results: make([]params.ActionsByUnit, len(args.Entities)),
+	}
+	for i, entity := range args.Entities {
+		result.Results[i].Unit = entity.Tag
+		unit, err := c.unitFromTag(entity.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		actions, err := c.api.state.UnitActions(unit.Name())
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		pending := make([]params.ActionResult, len(actions))
+		for j, action := range actions {
+			pending[j] = params.ActionResult{
+				Action: params.Action{
+					Id:     action.Id(),
+					Unit:   entity.Tag,
+					Name:   action.Name(),
+					Params: action.Payload(),
+				},
+				Status: params.ActionPending,
+			}
+		}
+		result.Results[i].Actions = pending
+	}
+	return result, nil
+}
+
+// ActionResults returns the outcome of every action that has finished
+// running on each given unit.
+func (c *Client) ActionResults(args params.Entities) (params.ActionsByUnits, error) {
+	result := params.ActionsByUnits{
+		Results: make([]params.ActionsByUnit, len(args.Entities)),
+	}
+	for i, entity := range args.Entities {
+		result.Results[i].Unit = entity.Tag
+		unit, err := c.unitFromTag(entity.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		actionResults, err := c.api.state.ActionResultsForUnit(unit.Name())
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		finished := make([]params.ActionResult, len(actionResults))
+		for j, ar := range actionResults {
+			finished[j] = params.ActionResult{
+				Action: params.Action{
+					Id:     ar.ActionId(),
+					Unit:   entity.Tag,
+					Name:   ar.ActionName(),
+					Params: ar.Payload(),
+				},
+				Status:  params.ActionStatus(ar.Status()),
+				Results: ar.Results(),
+				Message: ar.Output(),
+			}
+		}
+		result.Results[i].Actions = finished
+	}
+	return result, nil
+}
+
+func (c *Client) unitFromTag(tag string) (*state.Unit, error) {
+	t, err := names.ParseTag(tag, names.UnitTagKind)
+	if err != nil {
+		return nil, err
+	}
+	return c.api.state.Unit(t.Id())
+}
+
+// validateActionParams checks that the named action is defined by the
+// given charm actions, and that params conforms to its schema.
+func validateActionParams(actions *charm.Actions, name string, params map[string]interface{}) error {
+	if actions == nil {
+		return fmt.Errorf("charm does not define any actions")
+	}
+	spec, ok := actions.ActionSpecs[name]
+	if !ok {
+		return fmt.Errorf("action %q not defined by charm", name)
+	}
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	properties := spec.Params
+	if properties == nil {
+		properties = map[string]interface{}{}
+	}
+	schema, err := gojsonschema.NewJsonSchemaDocument(map[string]interface{}{
+		"title":      name,
+		"type":       "object",
+		"properties": properties,
+	})
+	if err != nil {
+		return fmt.Errorf("invalid schema for action %q: %v", name, err)
+	}
+	result := schema.Validate(params)
+	if !result.IsValid() {
+		return fmt.Errorf("invalid parameters for action %q: %s",
+			name, strings.Join(result.GetErrorMessages(), "; "))
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+)
+
+type actionsSuite struct {
+	baseSuite
+	unit *state.Unit
+}
+
+var _ = gc.Suite(&actionsSuite{})
+
+func (s *actionsSuite) SetUpTest(c *gc.C) {
+	s.baseSuite.SetUpTest(c)
+	service := s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
+	var err error
+	s.unit, err = service.AddUnit()
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *actionsSuite) TestEnqueueAction(c *gc.C) {
+	client := s.APIState.Client()
+	actionParams := map[string]interface{}{"outfile": "out.bz2"}
+	id, err := client.EnqueueAction(s.unit.Tag(), "snapshot", actionParams)
+	c.Assert(err, gc.IsNil)
+
+	action, err := s.State.Action(id)
+	c.Assert(err, gc.IsNil)
+	c.Assert(action.Name(), gc.Equals, "snapshot")
+	c.Assert(action.UnitName(), gc.Equals, s.unit.Name())
+	c.Assert(action.Payload(), jc.DeepEquals, actionParams)
+}
+
+var enqueueActionErrorTests = []struct {
+	about  string
+	unit   string
+	name   string
+	params map[string]interface{}
+	err    string
+}{{
+	about: "unknown unit",
+	unit:  "unit-dummy-42",
+	name:  "snapshot",
+	err:   `unit "dummy/42" not found`,
+}, {
+	about: "invalid tag",
+	unit:  "machine-0",
+	name:  "snapshot",
+	err:   `"machine-0" is not a valid unit tag`,
+}, {
+	about: "undefined action",
+	unit:  "unit-dummy-0",
+	name:  "no-such-action",
+	err:   `action "no-such-action" not defined by charm`,
+}, {
+	about:  "bad parameter type",
+	unit:   "unit-dummy-0",
+	name:   "snapshot",
+	params: map[string]interface{}{"outfile": 5.0},
+	err:    `invalid parameters for action "snapshot": .*`,
+}}
+
+func (s *actionsSuite) TestEnqueueActionErrors(c *gc.C) {
+	client := s.APIState.Client()
+	for i, t := range enqueueActionErrorTests {
+		c.Logf("test %d: %s", i, t.about)
+		_, err := client.EnqueueAction(t.unit, t.name, t.params)
+		c.Check(err, gc.ErrorMatches, t.err)
+	}
+	actions, err := s.State.UnitActions(s.unit.Name())
+	c.Assert(err, gc.IsNil)
+	c.Assert(actions, gc.HasLen, 0)
+}
+
+func (s *actionsSuite) TestListActionsAndResults(c *gc.C) {
+	client := s.APIState.Client()
+	id0, err := client.EnqueueAction(s.unit.Tag(), "snapshot", nil)
+	c.Assert(err, gc.IsNil)
+	id1, err := client.EnqueueAction(s.unit.Tag(), "snapshot", map[string]interface{}{"outfile": "x"})
+	c.Assert(err, gc.IsNil)
+
+	pending, err := client.ListActions(s.unit.Tag())
+	c.Assert(err, gc.IsNil)
+	c.Assert(pending, gc.HasLen, 2)
+	for i, id := range []string{id0, id1} {
+		c.Check(pending[i].Action.Id, gc.Equals, id)
+		c.Check(pending[i].Action.Unit, gc.Equals, s.unit.Tag())
+		c.Check(pending[i].Status, gc.Equals, params.ActionPending)
+	}
+
+	action, err := s.State.Action(id0)
+	c.Assert(err, gc.IsNil)
+	err = action.Finish(state.ActionCompleted, map[string]interface{}{"size": "10"}, "ok")
+	c.Assert(err, gc.IsNil)
+
+	pending, err = client.ListActions(s.unit.Tag())
+	c.Assert(err, gc.IsNil)
+	c.Assert(pending, gc.HasLen, 1)
+	c.Assert(pending[0].Action.Id, gc.Equals, id1)
+
+	finished, err := client.ActionResults(s.unit.Tag())
+	c.Assert(err, gc.IsNil)
+	c.Assert(finished, jc.DeepEquals, []params.ActionResult{{
+		Action: params.Action{
+			Id:   id0,
+			Unit: s.unit.Tag(),
+			Name: "snapshot",
+		},
+		Status:  params.ActionCompleted,
+		Results: map[string]interface{}{"size": "10"},
+		Message: "ok",
+	}})
+}
@@ -335,6 +335,10 @@ func (s *baseSuite) setUpScenario(c *gc.C) (entities []string) {
 
 	u = s.Factory.MakeUser(factory.UserParams{Username: "other"})
 	setDefaultPassword(c, u)
+	// Access levels are tested elsewhere; give the user
+	// the same access to the environment as the admin.
+	err = s.State.SetUserAccess(u.Name(), state.AdminAccess)
+	c.Assert(err, gc.IsNil)
 	add(u)
 
 	m, err := s.State.AddMachine("quantal", state.JobManageEnviron)
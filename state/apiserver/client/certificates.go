@@ -0,0 +1,118 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/cert"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/common"
+)
+
+// certificateLifetime holds how long generated CA and
+// state server certificates remain valid.
+const certificateLifetime = 10 * 365 * 24 * time.Hour
+
+// RotateCertificates replaces the certificates used to secure
+// connections to the API.
+//
+// If args.NewCA is set, a new CA certificate is generated and trusted
+// alongside the CAs already trusted. The new CA certificate and its
+// private key are returned, so the client can use them in a
+// subsequent call once agents have learned about the new CA.
+//
+// Otherwise a new state server certificate is generated, signed by
+// the trusted CA matching args.CAPrivateKey. API servers start using
+// the new certificate without restarting, but mongo must restart on
+// every state server to pick it up, so CAs replaced by the signing CA
+// stay trusted until then; see state.RetireOldCACerts.
+//
+// Only environment administrators may rotate certificates, because
+// anyone holding a CA private key can issue certificates that agents
+// trust.
+func (c *Client) RotateCertificates(args params.RotateCertificates) (params.RotateCertificatesResult, error) {
+	var result params.RotateCertificatesResult
+	// Rotating certificates hands out the CA private key.
+	canReadSecrets, err := c.canReadSecrets()
+	if err != nil {
+		return result, err
+	}
+	if !canReadSecrets {
+		return result, common.ErrPerm
+	}
+	caCerts, err := cert.ParseCerts(c.api.state.CACert())
+	if err != nil {
+		return result, errors.Annotate(err, "cannot parse CA certificates")
+	}
+	if args.NewCA {
+		return c.rotateCA(caCerts)
+	}
+	info, err := c.api.state.StateServingInfo()
+	if err != nil {
+		return result, errors.Annotate(err, "cannot get state serving info")
+	}
+	if args.CAPrivateKey == "" {
+		return result, fmt.Errorf("CA private key not specified")
+	}
+	caCertPEM, err := matchCA(caCerts, args.CAPrivateKey)
+	if err != nil {
+		return result, err
+	}
+	certPEM, keyPEM, err := cert.NewServer(caCertPEM, args.CAPrivateKey, time.Now().UTC().Add(certificateLifetime), nil)
+	if err != nil {
+		return result, errors.Annotate(err, "cannot generate server certificate")
+	}
+	info.Cert = certPEM
+	info.PrivateKey = keyPEM
+	if err := c.api.state.SetStateServingInfo(info); err != nil {
+		return result, errors.Annotate(err, "cannot set state serving info")
+	}
+	if err := c.api.state.RetireOldCACerts(); err != nil {
+		return result, errors.Annotate(err, "cannot retire old CA certificates")
+	}
+	result.CACert = c.api.state.CACert()
+	return result, nil
+}
+
+// rotateCA generates a new CA certificate and records it as trusted
+// ahead of the CAs already trusted.
+func (c *Client) rotateCA(caCerts []*x509.Certificate) (params.RotateCertificatesResult, error) {
+	var result params.RotateCertificatesResult
+	cfg, err := c.api.state.EnvironConfig()
+	if err != nil {
+		return result, err
+	}
+	caCertPEM, caKeyPEM, err := cert.NewCA(cfg.Name(), time.Now().UTC().Add(certificateLifetime))
+	if err != nil {
+		return result, errors.Annotate(err, "cannot generate CA certificate")
+	}
+	bundle := caCertPEM
+	for _, caCert := range caCerts {
+		bundle += cert.EncodeCert(caCert)
+	}
+	if err := c.api.state.SetCACert(bundle); err != nil {
+		return result, err
+	}
+	result.CACert = bundle
+	result.CAPrivateKey = caKeyPEM
+	return result, nil
+}
+
+// matchCA returns the PEM encoding of the CA certificate
+// in caCerts that matches the given private key.
+func matchCA(caCerts []*x509.Certificate, caKeyPEM string) (string, error) {
+	for _, caCert := range caCerts {
+		caCertPEM := cert.EncodeCert(caCert)
+		if _, err := tls.X509KeyPair([]byte(caCertPEM), []byte(caKeyPEM)); err == nil {
+			return caCertPEM, nil
+		}
+	}
+	return "", fmt.Errorf("CA private key does not match any trusted CA certificate")
+}
@@ -0,0 +1,132 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client_test
+
+import (
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/cert"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type certificatesSuite struct {
+	baseSuite
+}
+
+var _ = gc.Suite(&certificatesSuite{})
+
+func (s *certificatesSuite) SetUpTest(c *gc.C) {
+	s.baseSuite.SetUpTest(c)
+	err := s.State.SetStateServingInfo(params.StateServingInfo{
+		APIPort:    1234,
+		StatePort:  4321,
+		Cert:       coretesting.ServerCert,
+		PrivateKey: coretesting.ServerKey,
+	})
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *certificatesSuite) TestRotateServerCertificate(c *gc.C) {
+	result, err := s.APIState.Client().RotateCertificates(false, coretesting.CAKey)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.CACert, gc.Equals, coretesting.CACert)
+	c.Assert(result.CAPrivateKey, gc.Equals, "")
+
+	info, err := s.State.StateServingInfo()
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.Cert, gc.Not(gc.Equals), coretesting.ServerCert)
+	c.Assert(info.APIPort, gc.Equals, 1234)
+	err = cert.Verify(info.Cert, coretesting.CACert, time.Now())
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *certificatesSuite) TestRotateServerCertificateWrongKey(c *gc.C) {
+	_, otherKey, err := cert.NewCA("other", time.Now().AddDate(1, 0, 0))
+	c.Assert(err, gc.IsNil)
+	_, err = s.APIState.Client().RotateCertificates(false, otherKey)
+	c.Assert(err, gc.ErrorMatches, "CA private key does not match any trusted CA certificate")
+	_, err = s.APIState.Client().RotateCertificates(false, "")
+	c.Assert(err, gc.ErrorMatches, "CA private key not specified")
+
+	info, err := s.State.StateServingInfo()
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.Cert, gc.Equals, coretesting.ServerCert)
+}
+
+func (s *certificatesSuite) TestRotateCA(c *gc.C) {
+	result, err := s.APIState.Client().RotateCertificates(true, "")
+	c.Assert(err, gc.IsNil)
+	caCerts, err := cert.ParseCerts(result.CACert)
+	c.Assert(err, gc.IsNil)
+	c.Assert(caCerts, gc.HasLen, 2)
+	c.Assert(cert.EncodeCert(caCerts[1]), gc.Equals, coretesting.CACert)
+	c.Assert(s.State.CACert(), gc.Equals, result.CACert)
+
+	// The server certificate is unchanged until
+	// it is rotated using the new CA's key.
+	info, err := s.State.StateServingInfo()
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.Cert, gc.Equals, coretesting.ServerCert)
+
+	newCACert := cert.EncodeCert(caCerts[0])
+	rotated, err := s.APIState.Client().RotateCertificates(false, result.CAPrivateKey)
+	c.Assert(err, gc.IsNil)
+	c.Assert(rotated.CACert, gc.Equals, newCACert)
+	c.Assert(s.State.CACert(), gc.Equals, newCACert)
+	info, err = s.State.StateServingInfo()
+	c.Assert(err, gc.IsNil)
+	err = cert.Verify(info.Cert, newCACert, time.Now())
+	c.Assert(err, gc.IsNil)
+
+	// The CA that no longer signs the server certificate
+	// was dropped, so rotating the CA again trusts only
+	// the new CA and the current one.
+	result, err = s.APIState.Client().RotateCertificates(true, "")
+	c.Assert(err, gc.IsNil)
+	caCerts, err = cert.ParseCerts(result.CACert)
+	c.Assert(err, gc.IsNil)
+	c.Assert(caCerts, gc.HasLen, 2)
+	c.Assert(cert.EncodeCert(caCerts[1]), gc.Equals, newCACert)
+}
+
+func (s *certificatesSuite) TestRotateKeepsOldCAUntilMongoRestarted(c *gc.C) {
+	m, err := s.State.AddMachine("quantal", state.JobManageEnviron)
+	c.Assert(err, gc.IsNil)
+	err = s.State.SetMongoCert(m.Id(), coretesting.ServerCert)
+	c.Assert(err, gc.IsNil)
+
+	result, err := s.APIState.Client().RotateCertificates(true, "")
+	c.Assert(err, gc.IsNil)
+	rotated, err := s.APIState.Client().RotateCertificates(false, result.CAPrivateKey)
+	c.Assert(err, gc.IsNil)
+
+	// Mongo still presents the old certificate,
+	// so the old CA remains trusted.
+	c.Assert(rotated.CACert, gc.Equals, result.CACert)
+	c.Assert(s.State.CACert(), gc.Equals, result.CACert)
+
+	caCerts, err := cert.ParseCerts(result.CACert)
+	c.Assert(err, gc.IsNil)
+	newCACert := cert.EncodeCert(caCerts[0])
+	info, err := s.State.StateServingInfo()
+	c.Assert(err, gc.IsNil)
+	err = s.State.SetMongoCert(m.Id(), info.Cert)
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.State.CACert(), gc.Equals, newCACert)
+}
+
+func (s *certificatesSuite) TestStatusReportsExpiry(c *gc.C) {
+	status, err := s.APIState.Client().Status(nil)
+	c.Assert(err, gc.IsNil)
+	c.Assert(status.Certificates, gc.NotNil)
+	srvCert, err := cert.ParseCert(coretesting.ServerCert)
+	c.Assert(err, gc.IsNil)
+	c.Assert(status.Certificates.ServerExpiry.Equal(srvCert.NotAfter), gc.Equals, true)
+	c.Assert(status.Certificates.CAExpiry, gc.HasLen, 1)
+	c.Assert(status.Certificates.CAExpiry[0].Equal(coretesting.CACertX509.NotAfter), gc.Equals, true)
+}
@@ -223,6 +223,17 @@ func (c *Client) ServiceExpose(args params.ServiceExpose) error {
 	return svc.SetExposed()
 }
 
+// ServiceExposeTo changes the juju-managed firewall to expose any ports
+// that were also explicitly marked by units as open, but only to the
+// given source CIDRs.
+func (c *Client) ServiceExposeTo(args params.ServiceExposeTo) error {
+	svc, err := c.api.state.Service(args.ServiceName)
+	if err != nil {
+		return err
+	}
+	return svc.SetExposedTo(args.CIDRs)
+}
+
 // ServiceUnexpose changes the juju-managed firewall to unexpose any ports that
 // were also explicitly marked by units as open.
 func (c *Client) ServiceUnexpose(args params.ServiceUnexpose) error {
@@ -297,14 +308,16 @@ func (c *Client) ServiceDeploy(args params.ServiceDeploy) error {
 
 	_, err = juju.DeployService(c.api.state,
 		juju.DeployServiceParams{
-			ServiceName:    args.ServiceName,
-			ServiceOwner:   c.api.auth.GetAuthTag(),
-			Charm:          ch,
-			NumUnits:       args.NumUnits,
-			ConfigSettings: settings,
-			Constraints:    args.Constraints,
-			ToMachineSpec:  args.ToMachineSpec,
-			Networks:       requestedNetworks,
+			ServiceName:      args.ServiceName,
+			ServiceOwner:     c.api.auth.GetAuthTag(),
+			Charm:            ch,
+			NumUnits:         args.NumUnits,
+			ConfigSettings:   settings,
+			Constraints:      args.Constraints,
+			ToMachineSpec:    args.ToMachineSpec,
+			Networks:         requestedNetworks,
+			Storage:          args.Storage,
+			EndpointBindings: args.EndpointBindings,
 		})
 	return err
 }
@@ -317,6 +330,20 @@ func (c *Client) ServiceDeployWithNetworks(args params.ServiceDeploy) error {
 	return c.ServiceDeploy(args)
 }
 
+// ServiceDeployWithStorage works exactly like ServiceDeploy, but
+// allows specifying the storage to create for each unit of the
+// service with args.Storage.
+func (c *Client) ServiceDeployWithStorage(args params.ServiceDeploy) error {
+	return c.ServiceDeploy(args)
+}
+
+// ServiceDeployWithBindings works exactly like ServiceDeploy, but
+// allows binding the relation endpoints of the service to spaces
+// with args.EndpointBindings.
+func (c *Client) ServiceDeployWithBindings(args params.ServiceDeploy) error {
+	return c.ServiceDeploy(args)
+}
+
 // ServiceUpdate updates the service attributes, including charm URL,
 // minimum number of units, settings and constraints.
 // All parameters in params.ServiceUpdate except the service name are optional.
@@ -463,7 +490,7 @@ func addServiceUnits(state *state.State, args params.AddServiceUnits) ([]*state.
 	if args.NumUnits > 1 && args.ToMachineSpec != "" {
 		return nil, fmt.Errorf("cannot use NumUnits with ToMachineSpec")
 	}
-	return juju.AddUnits(state, service, args.NumUnits, args.ToMachineSpec)
+	return juju.AddUnitsWithStorage(state, service, args.NumUnits, args.ToMachineSpec, args.Storage)
 }
 
 // AddServiceUnits adds a given number of units to a service.
@@ -479,6 +506,13 @@ func (c *Client) AddServiceUnits(args params.AddServiceUnits) (params.AddService
 	return params.AddServiceUnitsResults{Units: unitNames}, nil
 }
 
+// AddServiceUnitsWithStorage works exactly like AddServiceUnits, but
+// allows specifying the storage to create for each new unit with
+// args.Storage.
+func (c *Client) AddServiceUnitsWithStorage(args params.AddServiceUnits) (params.AddServiceUnitsResults, error) {
+	return c.AddServiceUnits(args)
+}
+
 // DestroyServiceUnits removes a given set of service units.
 func (c *Client) DestroyServiceUnits(args params.DestroyServiceUnits) error {
 	var errs []string
@@ -546,12 +580,23 @@ func (c *Client) SetEnvironmentConstraints(args params.SetConstraints) error {
 
 // AddRelation adds a relation between the specified endpoints and returns the relation info.
 func (c *Client) AddRelation(args params.AddRelation) (params.AddRelationResults, error) {
-	inEps, err := c.api.state.InferEndpoints(args.Endpoints)
+	var user string
+	if u, ok := c.api.auth.GetAuthEntity().(*state.User); ok {
+		user = u.Name()
+	}
+	endpoints, added, err := consumeOffers(c.api.state, user, args.Endpoints)
+	if err != nil {
+		destroyUnused(added)
+		return params.AddRelationResults{}, err
+	}
+	inEps, err := c.api.state.InferEndpoints(endpoints)
 	if err != nil {
+		destroyUnused(added)
 		return params.AddRelationResults{}, err
 	}
 	rel, err := c.api.state.AddRelation(inEps...)
 	if err != nil {
+		destroyUnused(added)
 		return params.AddRelationResults{}, err
 	}
 	outEps := make(map[string]charm.Relation)
@@ -758,6 +803,7 @@ func (c *Client) EnvironmentInfo() (api.EnvironmentInfo, error) {
 		ProviderType:  conf.Type(),
 		Name:          conf.Name(),
 		UUID:          env.UUID(),
+		ServerUUID:    env.ServerUUID(),
 	}
 	return info, nil
 }
@@ -844,10 +890,57 @@ func (c *Client) EnvironmentGet() (params.EnvironmentGetResults, error) {
 	if err != nil {
 		return result, err
 	}
-	result.Config = config.AllAttrs()
+	attrs := config.AllAttrs()
+	canReadSecrets, err := c.canReadSecrets()
+	if err != nil {
+		return result, err
+	}
+	if !canReadSecrets {
+		secrets, err := environSecretAttrs(config)
+		if err != nil {
+			return result, err
+		}
+		for _, name := range secrets {
+			delete(attrs, name)
+		}
+	}
+	result.Config = attrs
 	return result, nil
 }
 
+// canReadSecrets reports whether the logged in
+// user may see the secrets in the environment configuration.
+// Only users with admin access to the environment may.
+func (c *Client) canReadSecrets() (bool, error) {
+	user, ok := c.api.auth.GetAuthEntity().(*state.User)
+	if !ok {
+		return false, nil
+	}
+	access, err := c.api.state.UserAccess(user.Name())
+	if err != nil {
+		return false, err
+	}
+	return access.Allows(state.AdminAccess), nil
+}
+
+// environSecretAttrs returns the names of the attributes
+// of the given environment configuration that hold secrets.
+func environSecretAttrs(cfg *config.Config) ([]string, error) {
+	secrets := []string{"admin-secret", "ca-private-key"}
+	provider, err := environs.Provider(cfg.Type())
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := provider.SecretAttrs(cfg)
+	if err != nil {
+		return nil, err
+	}
+	for name := range attrs {
+		secrets = append(secrets, name)
+	}
+	return secrets, nil
+}
+
 // EnvironmentSet implements the server-side part of the
 // set-environment CLI command.
 func (c *Client) EnvironmentSet(args params.EnvironmentSet) error {
@@ -1066,6 +1159,33 @@ func (c *Client) APIHostPorts() (result params.APIHostPortsResult, err error) {
 	return result, nil
 }
 
+// ConnectionInfo returns the information needed to connect to the
+// environment's API servers. Unlike EnvironmentGet, it returns no
+// secrets, so it is used to share environments between users.
+func (c *Client) ConnectionInfo() (params.ConnectionInfo, error) {
+	st := c.api.state
+	conf, err := st.EnvironConfig()
+	if err != nil {
+		return params.ConnectionInfo{}, err
+	}
+	env, err := st.Environment()
+	if err != nil {
+		return params.ConnectionInfo{}, err
+	}
+	servers, err := st.APIHostPorts()
+	if err != nil {
+		return params.ConnectionInfo{}, err
+	}
+	// The CA certificates recorded in the state include any added
+	// by rotate-certificates, unlike those in the environment config.
+	return params.ConnectionInfo{
+		EnvironName: conf.Name(),
+		EnvironUUID: env.UUID(),
+		CACert:      st.CACert(),
+		Servers:     servers,
+	}, nil
+}
+
 // EnsureAvailability ensures the availability of Juju state servers.
 func (c *Client) EnsureAvailability(args params.EnsureAvailability) error {
 	series := args.Series
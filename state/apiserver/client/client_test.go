@@ -18,6 +18,7 @@ import (
 	"github.com/juju/utils"
 	gc "launchpad.net/gocheck"
 
+	"github.com/juju/juju/cert"
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/config"
@@ -32,6 +33,7 @@ import (
 	"github.com/juju/juju/state/api/params"
 	"github.com/juju/juju/state/apiserver/client"
 	"github.com/juju/juju/state/presence"
+	"github.com/juju/juju/storage"
 	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/testing/factory"
 	"github.com/juju/juju/version"
@@ -211,6 +213,20 @@ func (s *clientSuite) TestClientAddServiceUnits(c *gc.C) {
 	c.Assert(assignedMachine, gc.Equals, "0")
 }
 
+func (s *clientSuite) TestClientAddServiceUnitsWithStorage(c *gc.C) {
+	s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
+	units, err := s.APIState.Client().AddServiceUnitsWithStorage("dummy", 1, "", map[string]storage.Directive{
+		"data": {Size: 1024, Count: 2},
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(units, gc.DeepEquals, []string{"dummy/0"})
+	unit, err := s.BackingState.Unit("dummy/0")
+	c.Assert(err, gc.IsNil)
+	instances, err := unit.StorageInstances()
+	c.Assert(err, gc.IsNil)
+	c.Assert(instances, gc.HasLen, 2)
+}
+
 var clientCharmInfoTests = []struct {
 	about string
 	url   string
@@ -268,6 +284,7 @@ func (s *clientSuite) TestClientEnvironmentInfo(c *gc.C) {
 	c.Assert(info.ProviderType, gc.Equals, conf.Type())
 	c.Assert(info.Name, gc.Equals, conf.Name())
 	c.Assert(info.UUID, gc.Equals, env.UUID())
+	c.Assert(info.ServerUUID, gc.Equals, env.UUID())
 }
 
 var clientAnnotationsTests = []struct {
@@ -407,6 +424,21 @@ func (s *clientSuite) TestClientServiceExpose(c *gc.C) {
 	}
 }
 
+func (s *clientSuite) TestClientServiceExposeTo(c *gc.C) {
+	s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	err := s.APIState.Client().ServiceExposeTo("wordpress", []string{"10.0.0.0/8"})
+	c.Assert(err, gc.IsNil)
+	service, err := s.State.Service("wordpress")
+	c.Assert(err, gc.IsNil)
+	c.Assert(service.IsExposed(), gc.Equals, true)
+	c.Assert(service.ExposedCIDRs(), gc.DeepEquals, []string{"10.0.0.0/8"})
+
+	err = s.APIState.Client().ServiceExposeTo("wordpress", []string{"10.0.0.1"})
+	c.Assert(err, gc.ErrorMatches, `cannot expose service "wordpress": invalid CIDR "10.0.0.1"`)
+	err = s.APIState.Client().ServiceExposeTo("unknown-service", []string{"10.0.0.0/8"})
+	c.Assert(err, gc.ErrorMatches, `service "unknown-service" not found`)
+}
+
 var serviceUnexposeTests = []struct {
 	about    string
 	service  string
@@ -711,6 +743,45 @@ func (s *clientSuite) TestClientServiceDeployWithNetworks(c *gc.C) {
 	c.Assert(serviceCons, gc.DeepEquals, cons)
 }
 
+func (s *clientSuite) TestClientServiceDeployWithStorage(c *gc.C) {
+	store, restore := makeMockCharmStore()
+	defer restore()
+	curl, bundle := addCharm(c, store, "dummy")
+	expect := map[string]storage.Directive{
+		"data": {Pool: "loop", Size: 1024, Count: 1},
+	}
+	err := s.APIState.Client().ServiceDeployWithStorage(params.ServiceDeploy{
+		ServiceName: "service",
+		CharmUrl:    curl.String(),
+		NumUnits:    1,
+		Storage:     expect,
+	})
+	c.Assert(err, gc.IsNil)
+	service := s.assertPrincipalDeployed(c, "service", curl, false, bundle, constraints.Value{})
+	storageCons, err := service.StorageConstraints()
+	c.Assert(err, gc.IsNil)
+	c.Assert(storageCons, gc.DeepEquals, expect)
+	_, err = s.State.StorageInstance("data/0")
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *clientSuite) TestClientServiceDeployWithBindings(c *gc.C) {
+	store, restore := makeMockCharmStore()
+	defer restore()
+	curl, bundle := addCharm(c, store, "dummy")
+	_, err := s.State.AddSpace("internal", nil)
+	c.Assert(err, gc.IsNil)
+	err = s.APIState.Client().ServiceDeployWithBindings(params.ServiceDeploy{
+		ServiceName:      "service",
+		CharmUrl:         curl.String(),
+		NumUnits:         1,
+		EndpointBindings: map[string]string{"juju-info": "internal"},
+	})
+	c.Assert(err, gc.IsNil)
+	service := s.assertPrincipalDeployed(c, "service", curl, false, bundle, constraints.Value{})
+	c.Assert(service.EndpointBindings(), gc.DeepEquals, map[string]string{"juju-info": "internal"})
+}
+
 func (s *clientSuite) assertPrincipalDeployed(c *gc.C, serviceName string, curl *charm.URL, forced bool, bundle charm.Charm, cons constraints.Value) *state.Service {
 	service, err := s.State.Service(serviceName)
 	c.Assert(err, gc.IsNil)
@@ -839,9 +910,11 @@ func (s *clientSuite) TestClientServiceDeployServiceOwner(c *gc.C) {
 	curl, _ := addCharm(c, store, "dummy")
 
 	user := s.Factory.MakeUser(factory.UserParams{Password: "password"})
+	err := s.State.SetUserAccess(user.Name(), state.WriteAccess)
+	c.Assert(err, gc.IsNil)
 	s.APIState = s.OpenAPIAs(c, user.Tag(), "password")
 
-	err := s.APIState.Client().ServiceDeploy(
+	err = s.APIState.Client().ServiceDeploy(
 		curl.String(), "service", 3, "", constraints.Value{}, "",
 	)
 	c.Assert(err, gc.IsNil)
@@ -2338,6 +2411,40 @@ func (s *clientSuite) TestAPIHostPorts(c *gc.C) {
 	c.Assert(apiHostPorts, gc.DeepEquals, stateAPIHostPorts)
 }
 
+func (s *clientSuite) TestConnectionInfo(c *gc.C) {
+	hostPorts := [][]network.HostPort{
+		network.AddressesWithPort(network.NewAddresses("server-1", "10.0.0.1"), 17070),
+	}
+	err := s.State.SetAPIHostPorts(hostPorts)
+	c.Assert(err, gc.IsNil)
+
+	info, err := s.APIState.Client().ConnectionInfo()
+	c.Assert(err, gc.IsNil)
+	conf, err := s.State.EnvironConfig()
+	c.Assert(err, gc.IsNil)
+	env, err := s.State.Environment()
+	c.Assert(err, gc.IsNil)
+	caCert, _ := conf.CACert()
+	c.Assert(info, gc.DeepEquals, params.ConnectionInfo{
+		EnvironName: conf.Name(),
+		EnvironUUID: env.UUID(),
+		CACert:      caCert,
+		Servers:     hostPorts,
+	})
+}
+
+func (s *clientSuite) TestConnectionInfoRotatedCACert(c *gc.C) {
+	newCACert, _, err := cert.NewCA("new", time.Now().AddDate(1, 0, 0))
+	c.Assert(err, gc.IsNil)
+	bundle := newCACert + coretesting.CACert
+	err = s.State.SetCACert(bundle)
+	c.Assert(err, gc.IsNil)
+
+	info, err := s.APIState.Client().ConnectionInfo()
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.CACert, gc.Equals, bundle)
+}
+
 func (s *clientSuite) TestClientAgentVersion(c *gc.C) {
 	current := version.MustParse("1.2.0")
 	s.PatchValue(&version.Current.Number, current)
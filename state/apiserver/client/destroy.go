@@ -66,6 +66,13 @@ func (c *Client) DestroyEnvironment() error {
 		return err
 	}
 
+	// A hosted environment has no state servers or other
+	// resources of its own, so nothing is left for the CLI to
+	// destroy; its instances are gone, so it can be removed.
+	if c.api.state.IsHosted() {
+		return env.Remove()
+	}
+
 	// Return to the caller. If it's the CLI, it will finish up
 	// by calling the provider's Destroy method, which will
 	// destroy the state servers, any straggler instances, and
@@ -0,0 +1,116 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/common"
+)
+
+// ServiceOffer offers endpoints of a service to the other environments
+// hosted by the state server.
+func (c *Client) ServiceOffer(args params.ServiceOffer) (params.ServiceOfferResult, error) {
+	offer, err := c.api.state.AddOffer(args.ServiceName, args.Endpoints...)
+	if err != nil {
+		return params.ServiceOfferResult{}, err
+	}
+	return params.ServiceOfferResult{URL: offer.URL()}, nil
+}
+
+// consumeOffers returns the given endpoint names, with any that name an
+// offer (as <environment name>.<service name>[:<relation name>])
+// replaced by names of remote services standing for the offered
+// services. The remote services are added as necessary; those added
+// are also returned, so they can be destroyed if they end up unused.
+// The named user must have access to the environments of the offers.
+func consumeOffers(st *state.State, user string, names []string) ([]string, []*state.RemoteService, error) {
+	var env *state.Environment
+	var added []*state.RemoteService
+	result := make([]string, len(names))
+	for i, name := range names {
+		url, relName := name, ""
+		if j := strings.Index(name, ":"); j != -1 {
+			url, relName = name[:j], name[j:]
+		}
+		if !strings.Contains(url, ".") {
+			result[i] = name
+			continue
+		}
+		offer, err := st.Offer(url)
+		if err != nil {
+			return nil, added, err
+		}
+		if env == nil {
+			if env, err = st.Environment(); err != nil {
+				return nil, added, err
+			}
+		}
+		if offer.EnvUUID() != env.UUID() {
+			if err := checkOfferAccess(st, user, offer); err != nil {
+				return nil, added, err
+			}
+			svc, err := consumeOffer(st, offer)
+			if err != nil {
+				return nil, added, err
+			}
+			if svc != nil {
+				added = append(added, svc)
+			}
+		}
+		result[i] = offer.ServiceName() + relName
+	}
+	return result, added, nil
+}
+
+// checkOfferAccess returns an error unless the named user has at least
+// read access to the environment of the given offer.
+func checkOfferAccess(st *state.State, user string, offer *state.Offer) error {
+	offerSt, err := st.ForEnviron(offer.EnvUUID())
+	if err != nil {
+		return err
+	}
+	defer offerSt.Close()
+	access, err := offerSt.UserAccess(user)
+	if errors.IsNotFound(err) {
+		return common.ErrPerm
+	} else if err != nil {
+		return err
+	}
+	if !access.Allows(state.ReadAccess) {
+		return common.ErrPerm
+	}
+	return nil
+}
+
+// consumeOffer ensures that the environment has a remote service
+// standing for the service of the given offer. If the remote service
+// had to be added, it is returned.
+func consumeOffer(st *state.State, offer *state.Offer) (*state.RemoteService, error) {
+	svc, err := st.RemoteService(offer.ServiceName())
+	if errors.IsNotFound(err) {
+		return st.AddRemoteService(offer.ServiceName(), offer.URL(), offer.EnvUUID(), offer.Endpoints())
+	} else if err != nil {
+		return nil, err
+	}
+	if svc.OfferURL() != offer.URL() {
+		return nil, fmt.Errorf("cannot consume offer %q: service %q already exists", offer.URL(), svc.Name())
+	}
+	return nil, nil
+}
+
+// destroyUnused destroys the given remote services, which were added
+// for a relation that could not be made.
+func destroyUnused(added []*state.RemoteService) {
+	for _, svc := range added {
+		if err := svc.Destroy(); err != nil {
+			logger.Warningf("cannot destroy unused remote service %q: %v", svc, err)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+type offersSuite struct {
+	baseSuite
+}
+
+var _ = gc.Suite(&offersSuite{})
+
+func (s *offersSuite) TestServiceOffer(c *gc.C) {
+	s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	url, err := s.APIState.Client().ServiceOffer("mysql", "server")
+	c.Assert(err, gc.IsNil)
+	c.Assert(url, gc.Equals, "dummyenv.mysql")
+	offer, err := s.State.Offer(url)
+	c.Assert(err, gc.IsNil)
+	c.Assert(offer.ServiceName(), gc.Equals, "mysql")
+
+	_, err = s.APIState.Client().ServiceOffer("wordpress")
+	c.Assert(err, gc.ErrorMatches, `cannot offer service "wordpress": service "wordpress" not found`)
+}
+
+func (s *offersSuite) TestAddRelationToOffer(c *gc.C) {
+	st := s.AddHostedEnvironment(c, "sandbox")
+	defer st.Close()
+	s.AddHostedTestingService(c, st, "mysql", "mysql")
+	_, err := st.AddOffer("mysql")
+	c.Assert(err, gc.IsNil)
+	s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+
+	res, err := s.APIState.Client().AddRelation("wordpress", "sandbox.mysql:server")
+	c.Assert(err, gc.IsNil)
+	c.Assert(res.Endpoints["mysql"].Name, gc.Equals, "server")
+	c.Assert(res.Endpoints["wordpress"].Name, gc.Equals, "db")
+
+	remote, err := s.State.RemoteService("mysql")
+	c.Assert(err, gc.IsNil)
+	c.Assert(remote.OfferURL(), gc.Equals, "sandbox.mysql")
+	env, err := st.Environment()
+	c.Assert(err, gc.IsNil)
+	c.Assert(remote.SourceEnvUUID(), gc.Equals, env.UUID())
+	rels, err := s.State.AllRelations()
+	c.Assert(err, gc.IsNil)
+	c.Assert(rels, gc.HasLen, 1)
+	c.Assert(rels[0].String(), gc.Equals, "wordpress:db mysql:server")
+
+	// The remote service is removed along with the relation,
+	// and added again when relating to the offer again.
+	err = rels[0].Destroy()
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.RemoteService("mysql")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.APIState.Client().AddRelation("sandbox.mysql", "wordpress")
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.RemoteService("mysql")
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *offersSuite) TestAddRelationToOfferFailureRemovesRemoteService(c *gc.C) {
+	st := s.AddHostedEnvironment(c, "sandbox")
+	defer st.Close()
+	s.AddHostedTestingService(c, st, "mysql", "mysql")
+	_, err := st.AddOffer("mysql")
+	c.Assert(err, gc.IsNil)
+	s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+
+	_, err = s.APIState.Client().AddRelation("wordpress:nonexistent", "sandbox.mysql")
+	c.Assert(err, gc.ErrorMatches, `.*"nonexistent".*`)
+	remotes, err := s.State.AllRemoteServices()
+	c.Assert(err, gc.IsNil)
+	c.Assert(remotes, gc.HasLen, 0)
+}
+
+func (s *offersSuite) TestAddRelationToOwnOffer(c *gc.C) {
+	s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	_, err := s.State.AddOffer("mysql")
+	c.Assert(err, gc.IsNil)
+	_, err = s.APIState.Client().AddRelation("wordpress", "dummyenv.mysql")
+	c.Assert(err, gc.IsNil)
+	remotes, err := s.State.AllRemoteServices()
+	c.Assert(err, gc.IsNil)
+	c.Assert(remotes, gc.HasLen, 0)
+}
+
+func (s *offersSuite) TestAddRelationToOfferErrors(c *gc.C) {
+	s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	_, err := s.APIState.Client().AddRelation("wordpress", "sandbox.mysql")
+	c.Assert(err, gc.ErrorMatches, `offer "sandbox.mysql" not found`)
+
+	st := s.AddHostedEnvironment(c, "sandbox")
+	defer st.Close()
+	s.AddHostedTestingService(c, st, "wordpress", "wordpress")
+	_, err = st.AddOffer("wordpress")
+	c.Assert(err, gc.IsNil)
+	s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	_, err = s.APIState.Client().AddRelation("mysql", "sandbox.wordpress")
+	c.Assert(err, gc.ErrorMatches, `cannot add remote service "wordpress": service already exists`)
+	remotes, err := s.State.AllRemoteServices()
+	c.Assert(err, gc.IsNil)
+	c.Assert(remotes, gc.HasLen, 0)
+}
+
+func (s *offersSuite) TestAddRelationToOfferRequiresAccess(c *gc.C) {
+	st := s.AddHostedEnvironment(c, "sandbox")
+	defer st.Close()
+	s.AddHostedTestingService(c, st, "mysql", "mysql")
+	_, err := st.AddOffer("mysql")
+	c.Assert(err, gc.IsNil)
+	s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	user := s.Factory.MakeUser(factory.UserParams{Password: "password"})
+	err = s.State.SetUserAccess(user.Name(), state.WriteAccess)
+	c.Assert(err, gc.IsNil)
+	s.APIState = s.OpenAPIAs(c, user.Tag(), "password")
+
+	// The user has no access to the environment of the offer.
+	_, err = s.APIState.Client().AddRelation("wordpress", "sandbox.mysql")
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	remotes, err := s.State.AllRemoteServices()
+	c.Assert(err, gc.IsNil)
+	c.Assert(remotes, gc.HasLen, 0)
+
+	err = st.SetUserAccess(user.Name(), state.ReadAccess)
+	c.Assert(err, gc.IsNil)
+	_, err = s.APIState.Client().AddRelation("wordpress", "sandbox.mysql")
+	c.Assert(err, gc.IsNil)
+}
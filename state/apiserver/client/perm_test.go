@@ -60,6 +60,10 @@ var operationPermTests = []struct {
 	about: "Client.ServiceExpose",
 	op:    opClientServiceExpose,
 	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.ServiceExposeTo",
+	op:    opClientServiceExposeTo,
+	allow: []string{"user-admin", "user-other"},
 }, {
 	about: "Client.ServiceUnexpose",
 	op:    opClientServiceUnexpose,
@@ -72,6 +76,14 @@ var operationPermTests = []struct {
 	about: "Client.ServiceDeployWithNetworks",
 	op:    opClientServiceDeployWithNetworks,
 	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.ServiceDeployWithStorage",
+	op:    opClientServiceDeployWithStorage,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.ServiceDeployWithBindings",
+	op:    opClientServiceDeployWithBindings,
+	allow: []string{"user-admin", "user-other"},
 }, {
 	about: "Client.ServiceUpdate",
 	op:    opClientServiceUpdate,
@@ -92,6 +104,10 @@ var operationPermTests = []struct {
 	about: "Client.AddServiceUnits",
 	op:    opClientAddServiceUnits,
 	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.AddServiceUnitsWithStorage",
+	op:    opClientAddServiceUnitsWithStorage,
+	allow: []string{"user-admin", "user-other"},
 }, {
 	about: "Client.DestroyServiceUnits",
 	op:    opClientDestroyServiceUnits,
@@ -124,6 +140,10 @@ var operationPermTests = []struct {
 	about: "Client.SetEnvironAgentVersion",
 	op:    opClientSetEnvironAgentVersion,
 	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.RotateCertificates",
+	op:    opClientRotateCertificates,
+	allow: []string{"user-admin", "user-other"},
 }, {
 	about: "Client.WatchAll",
 	op:    opClientWatchAll,
@@ -270,6 +290,18 @@ func opClientServiceExpose(c *gc.C, st *api.State, mst *state.State) (func(), er
 	}, nil
 }
 
+func opClientServiceExposeTo(c *gc.C, st *api.State, mst *state.State) (func(), error) {
+	err := st.Client().ServiceExposeTo("wordpress", []string{"10.0.0.0/8"})
+	if err != nil {
+		return func() {}, err
+	}
+	return func() {
+		svc, err := mst.Service("wordpress")
+		c.Assert(err, gc.IsNil)
+		svc.ClearExposed()
+	}, nil
+}
+
 func opClientServiceUnexpose(c *gc.C, st *api.State, mst *state.State) (func(), error) {
 	err := st.Client().ServiceUnexpose("wordpress")
 	if err != nil {
@@ -333,6 +365,30 @@ func opClientServiceDeployWithNetworks(c *gc.C, st *api.State, mst *state.State)
 	return func() {}, err
 }
 
+func opClientServiceDeployWithStorage(c *gc.C, st *api.State, mst *state.State) (func(), error) {
+	err := st.Client().ServiceDeployWithStorage(params.ServiceDeploy{
+		ServiceName: "x",
+		CharmUrl:    "mad:bad/url-1",
+		NumUnits:    1,
+	})
+	if err.Error() == `charm URL has invalid schema: "mad:bad/url-1"` {
+		err = nil
+	}
+	return func() {}, err
+}
+
+func opClientServiceDeployWithBindings(c *gc.C, st *api.State, mst *state.State) (func(), error) {
+	err := st.Client().ServiceDeployWithBindings(params.ServiceDeploy{
+		ServiceName: "x",
+		CharmUrl:    "mad:bad/url-1",
+		NumUnits:    1,
+	})
+	if err.Error() == `charm URL has invalid schema: "mad:bad/url-1"` {
+		err = nil
+	}
+	return func() {}, err
+}
+
 func opClientServiceUpdate(c *gc.C, st *api.State, mst *state.State) (func(), error) {
 	args := params.ServiceUpdate{
 		ServiceName:     "no-such-charm",
@@ -364,6 +420,14 @@ func opClientAddServiceUnits(c *gc.C, st *api.State, mst *state.State) (func(),
 	return func() {}, err
 }
 
+func opClientAddServiceUnitsWithStorage(c *gc.C, st *api.State, mst *state.State) (func(), error) {
+	_, err := st.Client().AddServiceUnitsWithStorage("nosuch", 1, "", nil)
+	if params.IsCodeNotFound(err) {
+		err = nil
+	}
+	return func() {}, err
+}
+
 func opClientDestroyServiceUnits(c *gc.C, st *api.State, mst *state.State) (func(), error) {
 	err := st.Client().DestroyServiceUnits("wordpress/99")
 	if err != nil && strings.HasPrefix(err.Error(), "no units were destroyed") {
@@ -442,6 +506,14 @@ func opClientSetEnvironAgentVersion(c *gc.C, st *api.State, mst *state.State) (f
 	}, nil
 }
 
+func opClientRotateCertificates(c *gc.C, st *api.State, mst *state.State) (func(), error) {
+	_, err := st.Client().RotateCertificates(false, "")
+	if err != nil && err.Error() == "CA private key not specified" {
+		err = nil
+	}
+	return func() {}, err
+}
+
 func opClientWatchAll(c *gc.C, st *api.State, mst *state.State) (func(), error) {
 	watcher, err := st.Client().WatchAll()
 	if err == nil {
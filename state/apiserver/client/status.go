@@ -13,6 +13,7 @@ import (
 	"github.com/juju/errors"
 	"github.com/juju/utils/set"
 
+	"github.com/juju/juju/cert"
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/juju"
 	"github.com/juju/juju/network"
@@ -56,6 +57,10 @@ func (c *Client) FullStatus(args params.StatusParams) (api.Status, error) {
 	if context.networks, err = fetchNetworks(conn.State); err != nil {
 		return noStatus, err
 	}
+	certificates, err := fetchCertificates(conn.State)
+	if err != nil {
+		return noStatus, err
+	}
 
 	return api.Status{
 		EnvironmentName: conn.Environ.Name(),
@@ -63,6 +68,7 @@ func (c *Client) FullStatus(args params.StatusParams) (api.Status, error) {
 		Services:        context.processServices(),
 		Networks:        context.processNetworks(),
 		Relations:       context.processRelations(),
+		Certificates:    certificates,
 	}, nil
 }
 
@@ -317,6 +323,33 @@ func fetchNetworks(st *state.State) (map[string]*state.Network, error) {
 	return out, nil
 }
 
+// fetchCertificates returns when the trusted CA certificates and
+// the state server certificate expire. It returns nil if the state
+// server certificate is not recorded in the state.
+func fetchCertificates(st *state.State) (*api.CertificatesStatus, error) {
+	info, err := st.StateServingInfo()
+	if errors.IsNotFound(err) || err == nil && info.Cert == "" {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	srvCert, err := cert.ParseCert(info.Cert)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot parse server certificate")
+	}
+	caCerts, err := cert.ParseCerts(st.CACert())
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot parse CA certificates")
+	}
+	out := &api.CertificatesStatus{
+		ServerExpiry: srvCert.NotAfter,
+	}
+	for _, caCert := range caCerts {
+		out.CAExpiry = append(out.CAExpiry, caCert.NotAfter)
+	}
+	return out, nil
+}
+
 func (context *statusContext) processMachines() map[string]api.MachineStatus {
 	machinesMap := make(map[string]api.MachineStatus)
 	for id, machines := range context.machines {
@@ -486,6 +519,7 @@ func (context *statusContext) processService(service *state.Service) (status api
 	serviceCharmURL, _ := service.CharmURL()
 	status.Charm = serviceCharmURL.String()
 	status.Exposed = service.IsExposed()
+	status.ExposedCIDRs = service.ExposedCIDRs()
 	status.Life = processLife(service)
 
 	latestCharm, ok := context.latestCharms[*serviceCharmURL.WithRevision(-1)]
@@ -538,7 +572,7 @@ func (context *statusContext) processUnits(units map[string]*state.Unit, service
 
 func (context *statusContext) processUnit(unit *state.Unit, serviceCharm string) (status api.UnitStatus) {
 	status.PublicAddress, _ = unit.PublicAddress()
-	for _, port := range unit.OpenedPorts() {
+	for _, port := range unit.OpenedPortRanges() {
 		status.OpenedPorts = append(status.OpenedPorts, port.String())
 	}
 	if unit.IsPrincipal() {
@@ -552,6 +586,8 @@ func (context *statusContext) processUnit(unit *state.Unit, serviceCharm string)
 	status.AgentVersion = status.Agent.Version
 	status.Life = status.Agent.Life
 	status.Err = status.Agent.Err
+	status.AgentActivity, status.ActivityHook, _ = unit.AgentActivity()
+	status.WorkloadStatus, status.WorkloadStatusInfo, _ = unit.WorkloadStatus()
 	if subUnits := unit.SubordinateNames(); len(subUnits) > 0 {
 		status.Subordinates = make(map[string]api.UnitStatus)
 		for _, name := range subUnits {
@@ -74,17 +74,19 @@ var (
 )
 
 var singletonErrorCodes = map[error]string{
-	state.ErrCannotEnterScopeYet: params.CodeCannotEnterScopeYet,
-	state.ErrCannotEnterScope:    params.CodeCannotEnterScope,
-	state.ErrExcessiveContention: params.CodeExcessiveContention,
-	state.ErrUnitHasSubordinates: params.CodeUnitHasSubordinates,
-	ErrBadId:                     params.CodeNotFound,
-	ErrBadCreds:                  params.CodeUnauthorized,
-	ErrPerm:                      params.CodeUnauthorized,
-	ErrNotLoggedIn:               params.CodeUnauthorized,
-	ErrUnknownWatcher:            params.CodeNotFound,
-	ErrStoppedWatcher:            params.CodeStopped,
-	ErrTryAgain:                  params.CodeTryAgain,
+	state.ErrCannotEnterScopeYet:   params.CodeCannotEnterScopeYet,
+	state.ErrCannotEnterScope:      params.CodeCannotEnterScope,
+	state.ErrExcessiveContention:   params.CodeExcessiveContention,
+	state.ErrUnitHasSubordinates:   params.CodeUnitHasSubordinates,
+	state.ErrLeadershipClaimDenied: params.CodeLeadershipClaimDenied,
+	state.ErrNotLeader:             params.CodeNotLeader,
+	ErrBadId:                       params.CodeNotFound,
+	ErrBadCreds:                    params.CodeUnauthorized,
+	ErrPerm:                        params.CodeUnauthorized,
+	ErrNotLoggedIn:                 params.CodeUnauthorized,
+	ErrUnknownWatcher:              params.CodeNotFound,
+	ErrStoppedWatcher:              params.CodeStopped,
+	ErrTryAgain:                    params.CodeTryAgain,
 }
 
 func singletonCode(err error) (string, bool) {
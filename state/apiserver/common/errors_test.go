@@ -50,6 +50,14 @@ var errorTransformTests = []struct {
 	err:        state.ErrUnitHasSubordinates,
 	code:       params.CodeUnitHasSubordinates,
 	helperFunc: params.IsCodeUnitHasSubordinates,
+}, {
+	err:        state.ErrLeadershipClaimDenied,
+	code:       params.CodeLeadershipClaimDenied,
+	helperFunc: params.IsCodeLeadershipClaimDenied,
+}, {
+	err:        state.ErrNotLeader,
+	code:       params.CodeNotLeader,
+	helperFunc: params.IsCodeNotLeader,
 }, {
 	err:        common.ErrBadId,
 	code:       params.CodeNotFound,
@@ -0,0 +1,25 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common
+
+import (
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+)
+
+// SubnetsParams returns the API representation of the given subnets,
+// for use by the facades that report subnets and spaces.
+func SubnetsParams(subnets []*state.Subnet) []params.Subnet {
+	result := make([]params.Subnet, len(subnets))
+	for i, subnet := range subnets {
+		result[i] = params.Subnet{
+			CIDR:              subnet.CIDR(),
+			ProviderId:        string(subnet.ProviderId()),
+			VLANTag:           subnet.VLANTag(),
+			AvailabilityZones: subnet.AvailabilityZones(),
+			SpaceName:         subnet.SpaceName(),
+		}
+	}
+	return result
+}
@@ -0,0 +1,79 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common
+
+import (
+	"fmt"
+
+	"github.com/juju/names"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+)
+
+// VolumeInfoSetter implements a common SetVolumeInfo method for use
+// by the facades that provision volumes.
+type VolumeInfoSetter struct {
+	st           *state.State
+	getCanModify GetAuthFunc
+}
+
+// NewVolumeInfoSetter returns a new VolumeInfoSetter. The GetAuthFunc
+// will be used on each invocation of SetVolumeInfo to determine
+// which machines' volumes may be modified.
+func NewVolumeInfoSetter(st *state.State, getCanModify GetAuthFunc) *VolumeInfoSetter {
+	return &VolumeInfoSetter{
+		st:           st,
+		getCanModify: getCanModify,
+	}
+}
+
+func (s *VolumeInfoSetter) setVolumeInfo(arg params.VolumeInfo) error {
+	tag, err := names.ParseTag(arg.MachineTag, names.MachineTagKind)
+	if err != nil {
+		return ErrPerm
+	}
+	machineId := tag.Id()
+	if arg.Attachment.Volume != arg.Volume.Name || arg.Attachment.Machine != machineId {
+		return fmt.Errorf("attachment does not match volume %q on machine %q", arg.Volume.Name, machineId)
+	}
+	// Volumes may only be provisioned through
+	// the machines they are attached to.
+	if _, err := s.st.VolumeAttachment(machineId, arg.Volume.Name); err != nil {
+		return err
+	}
+	err = s.st.SetVolumeInfo(arg.Volume.Name, state.VolumeInfo{
+		VolumeId: arg.Volume.VolumeId,
+		Size:     arg.Volume.Size,
+	})
+	if err != nil {
+		return err
+	}
+	return s.st.SetVolumeAttachmentInfo(machineId, arg.Volume.Name, state.VolumeAttachmentInfo{
+		DeviceName: arg.Attachment.DeviceName,
+	})
+}
+
+// SetVolumeInfo records the provider details of each given
+// volume, and of its attachment to the given machine.
+func (s *VolumeInfoSetter) SetVolumeInfo(args params.VolumesInfo) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Volumes)),
+	}
+	if len(args.Volumes) == 0 {
+		return result, nil
+	}
+	canModify, err := s.getCanModify()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, arg := range args.Volumes {
+		err := ErrPerm
+		if canModify(arg.MachineTag) {
+			err = s.setVolumeInfo(arg)
+		}
+		result.Results[i].Error = ServerError(err)
+	}
+	return result, nil
+}
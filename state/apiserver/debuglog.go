@@ -7,30 +7,28 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
-	"os"
-	"path/filepath"
 	"strconv"
-	"strings"
+	"time"
 
 	"code.google.com/p/go.net/websocket"
 	"github.com/juju/loggo"
-	"github.com/juju/utils/tailer"
-	"launchpad.net/tomb"
 
+	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/api/params"
 )
 
 // debugLogHandler takes requests to watch the debug log.
 type debugLogHandler struct {
 	httpHandler
-	logDir string
 }
 
-var maxLinesReached = fmt.Errorf("max lines reached")
-
 // ServeHTTP will serve up connections as a websocket.
+// The log records are read from the logs collection in state, which
+// holds the records sent by all the agents in the environment, so the
+// same records are seen whichever API server handles the request.
 // Args for the HTTP request are as follows:
 //   includeEntity -> []string - lists entity tags to include in the response
 //      - tags may finish with a '*' to match a prefix e.g.: unit-mysql-*, machine-2
@@ -45,61 +43,55 @@ var maxLinesReached = fmt.Errorf("max lines reached")
 //      - go back this many lines from the end before starting to filter
 //      - has no meaning if 'replay' is true
 //   level -> string one of [TRACE, DEBUG, INFO, WARNING, ERROR]
-//   replay -> string - one of [true, false], if true, start the file from the start
+//   replay -> string - one of [true, false], if true, start the log from the start
+//   startTime -> string - an RFC3339 time; only lines logged at or after
+//      this time are sent
+//   endTime -> string - an RFC3339 time; only lines logged at or before
+//      this time are sent, and the socket is closed once they have been
 func (h *debugLogHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	server := websocket.Server{
 		Handler: func(socket *websocket.Conn) {
+			defer socket.Close()
 			logger.Infof("debug log handler starting")
-			if err := h.authenticate(req); err != nil {
+			user, err := h.authenticate(req)
+			if err != nil {
 				h.sendError(socket, fmt.Errorf("auth failed: %v", err))
-				socket.Close()
 				return
 			}
-			if err := h.validateEnvironUUID(req); err != nil {
+			st, err := h.environState(req)
+			if err != nil {
 				h.sendError(socket, err)
-				socket.Close()
 				return
 			}
-			stream, err := newLogStream(req.URL.Query())
-			if err != nil {
+			if err := h.checkAccess(st, user, state.ReadAccess); err != nil {
 				h.sendError(socket, err)
-				socket.Close()
 				return
 			}
-			// Open log file.
-			logLocation := filepath.Join(h.logDir, "all-machines.log")
-			logFile, err := os.Open(logLocation)
+			stream, err := newLogStream(req.URL.Query())
 			if err != nil {
-				h.sendError(socket, fmt.Errorf("cannot open log file: %v", err))
-				socket.Close()
-				return
-			}
-			defer logFile.Close()
-			if err := stream.positionLogFile(logFile); err != nil {
-				h.sendError(socket, fmt.Errorf("cannot position log file: %v", err))
-				socket.Close()
+				h.sendError(socket, err)
 				return
 			}
+			tailer := st.NewLogTailer(stream.tailerParams(time.Now()))
+			defer tailer.Stop()
 
 			// If we get to here, no more errors to report, so we report a nil
 			// error.  This way the first line of the socket is always a json
 			// formatted simple error.
 			if err := h.sendError(socket, nil); err != nil {
 				logger.Errorf("could not send good log stream start")
-				socket.Close()
 				return
 			}
 
-			stream.start(logFile, socket)
+			// The client never sends anything, so reading only
+			// tells us when it has closed the connection.
+			closed := make(chan struct{})
 			go func() {
-				defer stream.tomb.Done()
-				defer socket.Close()
-				stream.tomb.Kill(stream.loop())
+				io.Copy(ioutil.Discard, socket)
+				close(closed)
 			}()
-			if err := stream.tomb.Wait(); err != nil {
-				if err != maxLinesReached {
-					logger.Errorf("debug-log handler error: %v", err)
-				}
+			if err := stream.send(tailer, socket, closed); err != nil {
+				logger.Errorf("debug-log handler error: %v", err)
 			}
 		}}
 	server.ServeHTTP(w, req)
@@ -143,6 +135,27 @@ func newLogStream(queryMap url.Values) (*logStream, error) {
 		}
 	}
 
+	var startTime time.Time
+	if value := queryMap.Get("startTime"); value != "" {
+		var err error
+		startTime, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("startTime value %q is not a valid RFC3339 time", value)
+		}
+	}
+
+	var endTime time.Time
+	if value := queryMap.Get("endTime"); value != "" {
+		var err error
+		endTime, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("endTime value %q is not a valid RFC3339 time", value)
+		}
+		if !startTime.IsZero() && endTime.Before(startTime) {
+			return nil, fmt.Errorf("endTime %q is before startTime %q", value, queryMap.Get("startTime"))
+		}
+	}
+
 	return &logStream{
 		includeEntity: queryMap["includeEntity"],
 		includeModule: queryMap["includeModule"],
@@ -152,6 +165,8 @@ func newLogStream(queryMap url.Values) (*logStream, error) {
 		fromTheStart:  fromTheStart,
 		backlog:       backlog,
 		filterLevel:   level,
+		startTime:     startTime,
+		endTime:       endTime,
 	}, nil
 }
 
@@ -172,44 +187,9 @@ func (h *debugLogHandler) sendError(w io.Writer, err error) error {
 	return err
 }
 
-type logLine struct {
-	line   string
-	agent  string
-	level  loggo.Level
-	module string
-}
-
-func parseLogLine(line string) *logLine {
-	const (
-		agentField  = 0
-		levelField  = 3
-		moduleField = 4
-	)
-	fields := strings.Fields(line)
-	result := &logLine{
-		line: line,
-	}
-	if len(fields) > agentField {
-		agent := fields[agentField]
-		if strings.HasSuffix(agent, ":") {
-			result.agent = agent[:len(agent)-1]
-		}
-	}
-	if len(fields) > moduleField {
-		if level, valid := loggo.ParseLevel(fields[levelField]); valid {
-			result.level = level
-			result.module = fields[moduleField]
-		}
-	}
-
-	return result
-}
-
-// logStream runs the tailer to read a log file and stream
-// it via a web socket.
+// logStream sends the log records matching its filters
+// down a web socket.
 type logStream struct {
-	tomb          tomb.Tomb
-	logTailer     *tailer.Tailer
 	filterLevel   loggo.Level
 	includeEntity []string
 	includeModule []string
@@ -217,109 +197,68 @@ type logStream struct {
 	excludeModule []string
 	backlog       uint
 	maxLines      uint
-	lineCount     uint
 	fromTheStart  bool
+	startTime     time.Time
+	endTime       time.Time
 }
 
-// positionLogFile will update the internal read position of the logFile to be
-// at the end of the file or somewhere in the middle if backlog has been specified.
-func (stream *logStream) positionLogFile(logFile io.ReadSeeker) error {
-	// Seek to the end, or lines back from the end if we need to.
-	if !stream.fromTheStart {
-		return tailer.SeekLastLines(logFile, stream.backlog, stream.filterLine)
-	}
-	return nil
-}
-
-// start the tailer listening to the logFile, and sending the matching
-// lines to the writer.
-func (stream *logStream) start(logFile io.ReadSeeker, writer io.Writer) {
-	stream.logTailer = tailer.NewTailer(logFile, writer, stream.countedFilterLine)
-}
-
-// loop starts the tailer with the log file and the web socket.
-func (stream *logStream) loop() error {
-	select {
-	case <-stream.logTailer.Dead():
-		return stream.logTailer.Err()
-	case <-stream.tomb.Dying():
-		stream.logTailer.Stop()
+// tailerParams returns the parameters for the log tailer
+// that reads the records for the stream. Unless the stream
+// replays the log or asks for a backlog or start time, only
+// records logged after now are returned.
+func (stream *logStream) tailerParams(now time.Time) state.LogTailerParams {
+	args := state.LogTailerParams{
+		StartTime:     stream.startTime,
+		EndTime:       stream.endTime,
+		MinLevel:      stream.filterLevel,
+		IncludeEntity: stream.includeEntity,
+		IncludeModule: stream.includeModule,
+		ExcludeEntity: stream.excludeEntity,
+		ExcludeModule: stream.excludeModule,
 	}
-	return nil
-}
-
-// filterLine checks the received line for one of the confgured tags.
-func (stream *logStream) filterLine(line []byte) bool {
-	log := parseLogLine(string(line))
-	return stream.checkIncludeEntity(log) &&
-		stream.checkIncludeModule(log) &&
-		!stream.exclude(log) &&
-		stream.checkLevel(log)
-}
-
-// countedFilterLine checks the received line for one of the confgured tags,
-// and also checks to make sure the stream doesn't send more than the
-// specified number of lines.
-func (stream *logStream) countedFilterLine(line []byte) bool {
-	result := stream.filterLine(line)
-	if result && stream.maxLines > 0 {
-		stream.lineCount++
-		result = stream.lineCount <= stream.maxLines
-		if stream.lineCount == stream.maxLines {
-			stream.tomb.Kill(maxLinesReached)
+	if !stream.fromTheStart {
+		if stream.backlog > 0 {
+			args.InitialLines = int(stream.backlog)
+		} else if args.StartTime.IsZero() {
+			args.StartTime = now
 		}
 	}
-	return result
+	return args
 }
 
-func (stream *logStream) checkIncludeEntity(line *logLine) bool {
-	if len(stream.includeEntity) == 0 {
-		return true
-	}
-	for _, value := range stream.includeEntity {
-		// special handling, if ends with '*', check prefix
-		if strings.HasSuffix(value, "*") {
-			if strings.HasPrefix(line.agent, value[:len(value)-1]) {
-				return true
+// send writes the records from the tailer to w, one line each,
+// until the maximum number of lines is reached, the tailer stops
+// or the closed channel is closed.
+func (stream *logStream) send(tailer *state.LogTailer, w io.Writer, closed <-chan struct{}) error {
+	var lineCount uint
+	for {
+		select {
+		case <-closed:
+			return nil
+		case record, ok := <-tailer.Logs():
+			if !ok {
+				return tailer.Err()
 			}
-		} else if line.agent == value {
-			return true
-		}
-	}
-	return false
-}
-
-func (stream *logStream) checkIncludeModule(line *logLine) bool {
-	if len(stream.includeModule) == 0 {
-		return true
-	}
-	for _, value := range stream.includeModule {
-		if strings.HasPrefix(line.module, value) {
-			return true
-		}
-	}
-	return false
-}
-
-func (stream *logStream) exclude(line *logLine) bool {
-	for _, value := range stream.excludeEntity {
-		// special handling, if ends with '*', check prefix
-		if strings.HasSuffix(value, "*") {
-			if strings.HasPrefix(line.agent, value[:len(value)-1]) {
-				return true
+			if _, err := io.WriteString(w, formatLogRecord(record)); err != nil {
+				return err
+			}
+			lineCount++
+			if stream.maxLines > 0 && lineCount >= stream.maxLines {
+				return nil
 			}
-		} else if line.agent == value {
-			return true
-		}
-	}
-	for _, value := range stream.excludeModule {
-		if strings.HasPrefix(line.module, value) {
-			return true
 		}
 	}
-	return false
 }
 
-func (stream *logStream) checkLevel(line *logLine) bool {
-	return line.level >= stream.filterLevel
+// formatLogRecord formats the record as a line
+// of the consolidated log file.
+func formatLogRecord(record *state.LogRecord) string {
+	return fmt.Sprintf("%s: %s %s %s %s %s\n",
+		record.Entity,
+		record.Time.In(time.UTC).Format("2006-01-02 15:04:05"),
+		record.Level,
+		record.Module,
+		record.Location,
+		record.Message,
+	)
 }
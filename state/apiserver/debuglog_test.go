@@ -12,23 +12,24 @@ import (
 	"io"
 	"net/http"
 	"net/url"
-	"os"
-	"path/filepath"
 	"strings"
+	"time"
 
 	"code.google.com/p/go.net/websocket"
+	"github.com/juju/loggo"
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/utils"
 	gc "launchpad.net/gocheck"
 
+	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/api/params"
 	"github.com/juju/juju/testing"
+	"github.com/juju/juju/testing/factory"
 )
 
 type debugLogSuite struct {
 	authHttpSuite
-	logFile *os.File
-	last    int
+	last int
 }
 
 var _ = gc.Suite(&debugLogSuite{})
@@ -56,9 +57,16 @@ func (s *debugLogSuite) TestNoAuth(c *gc.C) {
 	s.assertWebsocketClosed(c, reader)
 }
 
-func (s *debugLogSuite) TestNoLogfile(c *gc.C) {
-	reader := s.openWebsocket(c, nil)
-	s.assertErrorResponse(c, reader, "cannot open log file: .*: no such file or directory")
+func (s *debugLogSuite) TestRequiresAccess(c *gc.C) {
+	user := s.Factory.MakeUser(factory.UserParams{Password: s.password})
+	err := s.State.RevokeUserAccess(user.Name(), state.ReadAccess)
+	c.Assert(err, gc.IsNil)
+	conn, err := s.dialWebsocketInternal(c, nil, utils.BasicAuthHeader(user.Tag(), s.password))
+	c.Assert(err, gc.IsNil)
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	s.assertErrorResponse(c, reader, "permission denied")
 	s.assertWebsocketClosed(c, reader)
 }
 
@@ -68,6 +76,15 @@ func (s *debugLogSuite) TestBadParams(c *gc.C) {
 	s.assertWebsocketClosed(c, reader)
 }
 
+func (s *debugLogSuite) TestBadEndTime(c *gc.C) {
+	reader := s.openWebsocket(c, url.Values{
+		"startTime": {"2014-10-01T12:00:00Z"},
+		"endTime":   {"2014-10-01T11:00:00Z"},
+	})
+	s.assertErrorResponse(c, reader, `endTime "2014-10-01T11:00:00Z" is before startTime "2014-10-01T12:00:00Z"`)
+	s.assertWebsocketClosed(c, reader)
+}
+
 func (s *debugLogSuite) assertLogReader(c *gc.C, reader *bufio.Reader) {
 	s.assertLogFollowing(c, reader)
 	s.writeLogLines(c, logLineCount)
@@ -77,7 +94,6 @@ func (s *debugLogSuite) assertLogReader(c *gc.C, reader *bufio.Reader) {
 }
 
 func (s *debugLogSuite) TestServesLog(c *gc.C) {
-	s.ensureLogFile(c)
 	reader := s.openWebsocket(c, nil)
 	s.assertLogReader(c, reader)
 }
@@ -85,7 +101,6 @@ func (s *debugLogSuite) TestServesLog(c *gc.C) {
 func (s *debugLogSuite) TestReadFromTopLevelPath(c *gc.C) {
 	// Backwards compatibility check, that we can read the log file at
 	// https://host:port/log
-	s.ensureLogFile(c)
 	reader := s.openWebsocketCustomPath(c, "/log")
 	s.assertLogReader(c, reader)
 }
@@ -94,14 +109,12 @@ func (s *debugLogSuite) TestReadFromEnvUUIDPath(c *gc.C) {
 	// Check that we can read the log at https://host:port/ENVUUID/log
 	environ, err := s.State.Environment()
 	c.Assert(err, gc.IsNil)
-	s.ensureLogFile(c)
 	reader := s.openWebsocketCustomPath(c, fmt.Sprintf("/environment/%s/log", environ.UUID()))
 	s.assertLogReader(c, reader)
 }
 
 func (s *debugLogSuite) TestReadRejectsWrongEnvUUIDPath(c *gc.C) {
-	// Check that we cannot upload charms to https://host:port/BADENVUUID/charms
-	s.ensureLogFile(c)
+	// Check that we cannot read the log at https://host:port/BADENVUUID/log
 	reader := s.openWebsocketCustomPath(c, "/environment/dead-beef-123456/log")
 	s.assertErrorResponse(c, reader, `unknown environment: "dead-beef-123456"`)
 	s.assertWebsocketClosed(c, reader)
@@ -164,9 +177,29 @@ func (s *debugLogSuite) TestBacklogWithMaxLines(c *gc.C) {
 	s.assertWebsocketClosed(c, reader)
 }
 
-func (s *debugLogSuite) TestFilter(c *gc.C) {
-	s.ensureLogFile(c)
+func (s *debugLogSuite) TestEndTime(c *gc.C) {
+	// Records' times are stored to millisecond precision,
+	// so leave a gap either side of the end time.
+	s.writeLogLines(c, 10)
+	time.Sleep(10 * time.Millisecond)
+	endTime := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	s.writeLogLines(c, 10)
 
+	reader := s.openWebsocket(c, url.Values{
+		"replay":  {"true"},
+		"endTime": {endTime.UTC().Format(time.RFC3339Nano)},
+	})
+	s.assertLogFollowing(c, reader)
+
+	// Only the lines logged before the end time are sent,
+	// and then the socket is closed.
+	linesRead := s.readLogLines(c, reader, 10)
+	c.Assert(linesRead, jc.DeepEquals, logLines[:10])
+	s.assertWebsocketClosed(c, reader)
+}
+
+func (s *debugLogSuite) TestFilter(c *gc.C) {
 	reader := s.openWebsocket(c, url.Values{
 		"includeEntity": {"machine-0", "unit-ubuntu-0"},
 		"includeModule": {"juju.cmd"},
@@ -184,8 +217,9 @@ func (s *debugLogSuite) readLogLines(c *gc.C, reader *bufio.Reader, count int) (
 	for len(linesRead) < count {
 		line, err := reader.ReadString('\n')
 		c.Assert(err, gc.IsNil)
-		// Trim off the trailing \n
-		linesRead = append(linesRead, line[:len(line)-1])
+		// Trim off the trailing \n, and the time, which
+		// is set when the line is written.
+		linesRead = append(linesRead, stripTime(line[:len(line)-1]))
 	}
 	return linesRead
 }
@@ -207,27 +241,42 @@ func (s *debugLogSuite) openWebsocketCustomPath(c *gc.C, path string) *bufio.Rea
 	return bufio.NewReader(conn)
 }
 
-func (s *debugLogSuite) ensureLogFile(c *gc.C) {
-	if s.logFile != nil {
-		return
-	}
-	path := filepath.Join(s.LogDir, "all-machines.log")
-	var err error
-	s.logFile, err = os.Create(path)
-	c.Assert(err, gc.IsNil)
-	s.AddCleanup(func(c *gc.C) {
-		s.logFile.Close()
-		s.logFile = nil
-		s.last = 0
-	})
+func (s *debugLogSuite) SetUpTest(c *gc.C) {
+	s.authHttpSuite.SetUpTest(c)
+	s.last = 0
 }
 
+// writeLogLines adds the next count log lines to state,
+// as records logged now.
 func (s *debugLogSuite) writeLogLines(c *gc.C, count int) {
-	s.ensureLogFile(c)
+	var records []state.LogRecord
 	for i := 0; i < count && s.last < logLineCount; i++ {
-		s.logFile.WriteString(logLines[s.last] + "\n")
+		// Each line holds "<entity>: <level> <module> <location> <message>".
+		fields := strings.SplitN(logLines[s.last], " ", 5)
+		c.Assert(fields, gc.HasLen, 5)
+		level, ok := loggo.ParseLevel(fields[1])
+		c.Assert(ok, jc.IsTrue)
+		records = append(records, state.LogRecord{
+			Time:     time.Now(),
+			Entity:   strings.TrimSuffix(fields[0], ":"),
+			Level:    level,
+			Module:   fields[2],
+			Location: fields[3],
+			Message:  fields[4],
+		})
 		s.last++
 	}
+	err := s.State.AddLogs(records)
+	c.Assert(err, gc.IsNil)
+}
+
+// stripTime removes the date and time from a log line.
+func stripTime(line string) string {
+	fields := strings.SplitN(line, " ", 4)
+	if len(fields) < 4 {
+		return line
+	}
+	return fields[0] + " " + fields[3]
 }
 
 func (s *debugLogSuite) dialWebsocketInternal(c *gc.C, queryParams url.Values, header http.Header) (*websocket.Conn, error) {
@@ -289,7 +338,7 @@ func (s *debugLogSuite) getErrorResult(c *gc.C, reader *bufio.Reader) params.Err
 }
 
 var (
-	logLines = strings.Split(`
+	rawLogLines = strings.Split(`
 machine-0: 2014-03-24 22:34:25 INFO juju.cmd supercommand.go:297 running juju-1.17.7.1-trusty-amd64 [gc]
 machine-0: 2014-03-24 22:34:25 INFO juju.cmd.jujud machine.go:127 machine agent machine-0 start (1.17.7.1-trusty-amd64 [gc])
 machine-0: 2014-03-24 22:34:25 DEBUG juju.agent agent.go:384 read agent config, format "1.18"
@@ -345,5 +394,14 @@ unit-ubuntu-0: 2014-03-24 22:36:28 DEBUG juju.worker.logger logger.go:60 logger
 unit-ubuntu-0: 2014-03-24 22:36:28 INFO juju runner.go:262 worker: start "rsyslog"
 unit-ubuntu-0: 2014-03-24 22:36:28 DEBUG juju.worker.rsyslog worker.go:76 starting rsyslog worker mode 1 for "unit-ubuntu-0" "tim-local"
 `[1:], "\n")
+	logLines     = stripTimes(rawLogLines)
 	logLineCount = len(logLines)
 )
+
+func stripTimes(lines []string) []string {
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		result[i] = stripTime(line)
+	}
+	return result
+}
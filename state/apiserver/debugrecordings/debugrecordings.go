@@ -0,0 +1,112 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package debugrecordings
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/common"
+)
+
+// DebugRecordingsAPI implements the API end point used by unit
+// agents to store the recordings of debug-hooks sessions, and by
+// clients to list and download them.
+type DebugRecordingsAPI struct {
+	st         *state.State
+	authorizer common.Authorizer
+}
+
+// NewDebugRecordingsAPI returns a new DebugRecordingsAPI.
+func NewDebugRecordingsAPI(st *state.State, authorizer common.Authorizer) (*DebugRecordingsAPI, error) {
+	if !authorizer.AuthUnitAgent() && !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	return &DebugRecordingsAPI{
+		st:         st,
+		authorizer: authorizer,
+	}, nil
+}
+
+func (api *DebugRecordingsAPI) add(arg params.DebugRecordingData) error {
+	unitName := arg.Recording.Unit
+	if !names.IsUnit(unitName) || !api.authorizer.AuthOwner(names.NewUnitTag(unitName).String()) {
+		return common.ErrPerm
+	}
+	_, err := api.st.AddDebugRecording(state.DebugRecordingParams{
+		Unit:     unitName,
+		Hook:     arg.Recording.Hook,
+		Started:  arg.Recording.Started,
+		Finished: arg.Recording.Finished,
+	}, arg.Transcript, arg.Timing)
+	return err
+}
+
+// Add stores the given debug-hooks session recordings. Only the
+// agent of the unit that was debugged may store its recordings.
+func (api *DebugRecordingsAPI) Add(args params.DebugRecordingsAddArgs) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Recordings)),
+	}
+	if !api.authorizer.AuthUnitAgent() {
+		return params.ErrorResults{}, common.ErrPerm
+	}
+	for i, arg := range args.Recordings {
+		result.Results[i].Error = common.ServerError(api.add(arg))
+	}
+	return result, nil
+}
+
+// List returns the debug-hooks session recordings matching
+// the given filter, oldest first.
+func (api *DebugRecordingsAPI) List(args params.DebugRecordingsFilter) (params.DebugRecordingsListResult, error) {
+	var result params.DebugRecordingsListResult
+	if !api.authorizer.AuthClient() {
+		return result, common.ErrPerm
+	}
+	recordings, err := api.st.DebugRecordings(args.Unit)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	result.Recordings = make([]params.DebugRecording, len(recordings))
+	for i, recording := range recordings {
+		result.Recordings[i] = recordingResult(recording)
+	}
+	return result, nil
+}
+
+// Get returns the requested debug-hooks session recording,
+// along with its transcript and timings.
+func (api *DebugRecordingsAPI) Get(args params.DebugRecordingsGetArgs) (params.DebugRecordingData, error) {
+	var result params.DebugRecordingData
+	if !api.authorizer.AuthClient() {
+		return result, common.ErrPerm
+	}
+	recording, err := api.st.DebugRecording(args.Id)
+	if err != nil {
+		return result, err
+	}
+	transcript, timing, err := api.st.DebugRecordingData(recording)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	return params.DebugRecordingData{
+		Recording:  recordingResult(recording),
+		Transcript: transcript,
+		Timing:     timing,
+	}, nil
+}
+
+func recordingResult(recording *state.DebugRecording) params.DebugRecording {
+	return params.DebugRecording{
+		Id:       recording.Id(),
+		Unit:     recording.Unit(),
+		Hook:     recording.Hook(),
+		Started:  recording.Started(),
+		Finished: recording.Finished(),
+		Size:     recording.Size(),
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package debugrecordings_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/debugrecordings"
+	apiservertesting "github.com/juju/juju/state/apiserver/testing"
+)
+
+type debugRecordingsSuite struct {
+	jujutesting.JujuConnSuite
+
+	client     *debugrecordings.DebugRecordingsAPI
+	unitAgent  *debugrecordings.DebugRecordingsAPI
+	authorizer apiservertesting.FakeAuthorizer
+}
+
+var _ = gc.Suite(&debugRecordingsSuite{})
+
+func (s *debugRecordingsSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	var err error
+	s.client, err = debugrecordings.NewDebugRecordingsAPI(s.State, apiservertesting.FakeAuthorizer{
+		Tag:      "user-admin",
+		LoggedIn: true,
+		Client:   true,
+	})
+	c.Assert(err, gc.IsNil)
+	s.unitAgent, err = debugrecordings.NewDebugRecordingsAPI(s.State, apiservertesting.FakeAuthorizer{
+		Tag:       "unit-mysql-0",
+		LoggedIn:  true,
+		UnitAgent: true,
+	})
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *debugRecordingsSuite) TestNewDebugRecordingsAPIRefusesMachineAgent(c *gc.C) {
+	api, err := debugrecordings.NewDebugRecordingsAPI(s.State, apiservertesting.FakeAuthorizer{
+		Tag:          "machine-0",
+		LoggedIn:     true,
+		MachineAgent: true,
+	})
+	c.Assert(api, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *debugRecordingsSuite) recording(unitName string, started time.Time) params.DebugRecordingData {
+	return params.DebugRecordingData{
+		Recording: params.DebugRecording{
+			Unit:     unitName,
+			Hook:     "config-changed",
+			Started:  started,
+			Finished: started.Add(time.Minute),
+		},
+		Transcript: []byte("$ config-get\n"),
+		Timing:     []byte("0.1 13\n"),
+	}
+}
+
+func (s *debugRecordingsSuite) TestAddListGet(c *gc.C) {
+	started := time.Date(2014, 7, 14, 12, 0, 0, 0, time.UTC)
+	results, err := s.unitAgent.Add(params.DebugRecordingsAddArgs{
+		Recordings: []params.DebugRecordingData{
+			s.recording("mysql/0", started),
+			s.recording("mysql/1", started),
+			s.recording("mysql", started),
+		},
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(results, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{nil},
+			{apiservertesting.ErrUnauthorized},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	list, err := s.client.List(params.DebugRecordingsFilter{Unit: "mysql/0"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(list.Recordings, gc.HasLen, 1)
+	recording := list.Recordings[0]
+	c.Assert(recording.Id, gc.Equals, "0")
+	c.Assert(recording.Unit, gc.Equals, "mysql/0")
+	c.Assert(recording.Hook, gc.Equals, "config-changed")
+	c.Assert(recording.Started.Equal(started), jc.IsTrue)
+	c.Assert(recording.Size, gc.Equals, int64(13))
+
+	list, err = s.client.List(params.DebugRecordingsFilter{Unit: "wordpress/0"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(list.Recordings, gc.HasLen, 0)
+
+	data, err := s.client.Get(params.DebugRecordingsGetArgs{Id: "0"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(data.Recording.Unit, gc.Equals, "mysql/0")
+	c.Assert(string(data.Transcript), gc.Equals, "$ config-get\n")
+	c.Assert(string(data.Timing), gc.Equals, "0.1 13\n")
+
+	_, err = s.client.Get(params.DebugRecordingsGetArgs{Id: "1"})
+	c.Assert(err, gc.ErrorMatches, `debug recording "1" not found`)
+}
+
+func (s *debugRecordingsSuite) TestPermissions(c *gc.C) {
+	_, err := s.client.Add(params.DebugRecordingsAddArgs{
+		Recordings: []params.DebugRecordingData{s.recording("mysql/0", time.Now())},
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	_, err = s.unitAgent.List(params.DebugRecordingsFilter{})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	_, err = s.unitAgent.Get(params.DebugRecordingsGetArgs{Id: "0"})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
@@ -0,0 +1,105 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environmentmanager
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/common"
+)
+
+var logger = loggo.GetLogger("juju.state.apiserver.environmentmanager")
+
+// EnvironmentManagerAPI implements the API end point used to create
+// and list the environments hosted by a state server.
+type EnvironmentManagerAPI struct {
+	st         *state.State
+	authorizer common.Authorizer
+}
+
+// NewEnvironmentManagerAPI returns a new EnvironmentManagerAPI. The
+// given State must be that of the state server's own environment.
+func NewEnvironmentManagerAPI(st *state.State, authorizer common.Authorizer) (*EnvironmentManagerAPI, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	return &EnvironmentManagerAPI{
+		st:         st,
+		authorizer: authorizer,
+	}, nil
+}
+
+// CreateEnvironment creates a new environment hosted by the state
+// server, owned by the user making the call. The new environment's
+// configuration is that of the state server's own environment,
+// including its provider credentials, with the given name and
+// configuration attributes applied on top.
+func (api *EnvironmentManagerAPI) CreateEnvironment(args params.EnvironmentCreateArgs) (params.EnvironmentInfo, error) {
+	var result params.EnvironmentInfo
+	ownerTag := api.authorizer.GetAuthTag()
+	owner, err := names.ParseTag(ownerTag, names.UserTagKind)
+	if err != nil {
+		return result, common.ErrPerm
+	}
+	if args.Name == "" {
+		return result, errors.New("no environment name specified")
+	}
+	serverCfg, err := api.st.EnvironConfig()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	attrs := make(map[string]interface{})
+	for key, value := range args.Config {
+		attrs[key] = value
+	}
+	attrs["name"] = args.Name
+	cfg, err := serverCfg.Apply(attrs)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	env, st, err := api.st.NewEnvironment(cfg, owner.Id())
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	if err := st.Close(); err != nil {
+		logger.Errorf("error closing state for environment %q: %v", env.Name(), err)
+	}
+	logger.Infof("created environment %q (%s) for %s", env.Name(), env.UUID(), ownerTag)
+	return params.EnvironmentInfo{
+		Name:     env.Name(),
+		UUID:     env.UUID(),
+		OwnerTag: ownerTag,
+	}, nil
+}
+
+// ListEnvironments returns the state server's own environment,
+// followed by the environments it hosts.
+func (api *EnvironmentManagerAPI) ListEnvironments() (params.EnvironmentInfoList, error) {
+	var result params.EnvironmentInfoList
+	serverEnv, err := api.st.Environment()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	hosted, err := api.st.HostedEnvironments()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	result.Environments = append(result.Environments, params.EnvironmentInfo{
+		Name:     serverEnv.Name(),
+		UUID:     serverEnv.UUID(),
+		OwnerTag: names.NewUserTag(state.AdminUser).String(),
+	})
+	for _, env := range hosted {
+		result.Environments = append(result.Environments, params.EnvironmentInfo{
+			Name:     env.Name(),
+			UUID:     env.UUID(),
+			OwnerTag: names.NewUserTag(env.Owner()).String(),
+		})
+	}
+	return result, nil
+}
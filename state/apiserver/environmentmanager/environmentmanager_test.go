@@ -0,0 +1,106 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environmentmanager_test
+
+import (
+	gc "launchpad.net/gocheck"
+
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/environmentmanager"
+	apiservertesting "github.com/juju/juju/state/apiserver/testing"
+)
+
+type environmentManagerSuite struct {
+	jujutesting.JujuConnSuite
+
+	api        *environmentmanager.EnvironmentManagerAPI
+	authorizer apiservertesting.FakeAuthorizer
+}
+
+var _ = gc.Suite(&environmentManagerSuite{})
+
+func (s *environmentManagerSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.authorizer = apiservertesting.FakeAuthorizer{
+		Tag:      "user-bob",
+		LoggedIn: true,
+		Client:   true,
+	}
+	var err error
+	s.api, err = environmentmanager.NewEnvironmentManagerAPI(s.State, s.authorizer)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *environmentManagerSuite) TestNewEnvironmentManagerAPIRefusesNonClient(c *gc.C) {
+	anAuthoriser := s.authorizer
+	anAuthoriser.Client = false
+	api, err := environmentmanager.NewEnvironmentManagerAPI(s.State, anAuthoriser)
+	c.Assert(api, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *environmentManagerSuite) TestCreateEnvironment(c *gc.C) {
+	serverCfg, err := s.State.EnvironConfig()
+	c.Assert(err, gc.IsNil)
+
+	info, err := s.api.CreateEnvironment(params.EnvironmentCreateArgs{
+		Name:   "sandbox",
+		Config: map[string]interface{}{"default-series": "trusty"},
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.Name, gc.Equals, "sandbox")
+	c.Assert(info.OwnerTag, gc.Equals, "user-bob")
+
+	hosted, err := s.State.HostedEnvironment(info.UUID)
+	c.Assert(err, gc.IsNil)
+	c.Assert(hosted.Owner(), gc.Equals, "bob")
+
+	st, err := s.State.ForEnviron(info.UUID)
+	c.Assert(err, gc.IsNil)
+	defer st.Close()
+	cfg, err := st.EnvironConfig()
+	c.Assert(err, gc.IsNil)
+	c.Assert(cfg.Name(), gc.Equals, "sandbox")
+	series, _ := cfg.DefaultSeries()
+	c.Assert(series, gc.Equals, "trusty")
+	// The provider credentials are those of the state server.
+	c.Assert(cfg.Type(), gc.Equals, serverCfg.Type())
+	c.Assert(cfg.AuthorizedKeys(), gc.Equals, serverCfg.AuthorizedKeys())
+}
+
+func (s *environmentManagerSuite) TestCreateEnvironmentNoName(c *gc.C) {
+	_, err := s.api.CreateEnvironment(params.EnvironmentCreateArgs{})
+	c.Assert(err, gc.ErrorMatches, "no environment name specified")
+}
+
+func (s *environmentManagerSuite) TestCreateEnvironmentRefusesAgent(c *gc.C) {
+	s.authorizer.Tag = "machine-0"
+	api, err := environmentmanager.NewEnvironmentManagerAPI(s.State, s.authorizer)
+	c.Assert(err, gc.IsNil)
+	_, err = api.CreateEnvironment(params.EnvironmentCreateArgs{Name: "sandbox"})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *environmentManagerSuite) TestCreateEnvironmentDuplicateName(c *gc.C) {
+	_, err := s.api.CreateEnvironment(params.EnvironmentCreateArgs{Name: "sandbox"})
+	c.Assert(err, gc.IsNil)
+	_, err = s.api.CreateEnvironment(params.EnvironmentCreateArgs{Name: "sandbox"})
+	c.Assert(err, gc.ErrorMatches, `cannot create environment "sandbox": environment already exists`)
+}
+
+func (s *environmentManagerSuite) TestListEnvironments(c *gc.C) {
+	created, err := s.api.CreateEnvironment(params.EnvironmentCreateArgs{Name: "sandbox"})
+	c.Assert(err, gc.IsNil)
+
+	result, err := s.api.ListEnvironments()
+	c.Assert(err, gc.IsNil)
+	serverEnv, err := s.State.Environment()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Environments, gc.DeepEquals, []params.EnvironmentInfo{{
+		Name:     serverEnv.Name(),
+		UUID:     serverEnv.UUID(),
+		OwnerTag: "user-admin",
+	}, created})
+}
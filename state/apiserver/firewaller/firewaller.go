@@ -114,6 +114,27 @@ func (f *FirewallerAPI) OpenedPorts(args params.Entities) (params.PortsResults,
 	return result, nil
 }
 
+// OpenedPortRanges returns the list of opened port ranges for each
+// given unit.
+func (f *FirewallerAPI) OpenedPortRanges(args params.Entities) (params.PortRangesResults, error) {
+	result := params.PortRangesResults{
+		Results: make([]params.PortRangesResult, len(args.Entities)),
+	}
+	canAccess, err := f.accessUnit()
+	if err != nil {
+		return params.PortRangesResults{}, err
+	}
+	for i, entity := range args.Entities {
+		var unit *state.Unit
+		unit, err = f.getUnit(canAccess, entity.Tag)
+		if err == nil {
+			result.Results[i].PortRanges = unit.OpenedPortRanges()
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
 // GetExposed returns the exposed flag value for each given service.
 func (f *FirewallerAPI) GetExposed(args params.Entities) (params.BoolResults, error) {
 	result := params.BoolResults{
@@ -134,6 +155,27 @@ func (f *FirewallerAPI) GetExposed(args params.Entities) (params.BoolResults, er
 	return result, nil
 }
 
+// GetExposedCIDRs returns the source CIDRs to which each given
+// service is exposed.
+func (f *FirewallerAPI) GetExposedCIDRs(args params.Entities) (params.StringsResults, error) {
+	result := params.StringsResults{
+		Results: make([]params.StringsResult, len(args.Entities)),
+	}
+	canAccess, err := f.accessService()
+	if err != nil {
+		return params.StringsResults{}, err
+	}
+	for i, entity := range args.Entities {
+		var service *state.Service
+		service, err = f.getService(canAccess, entity.Tag)
+		if err == nil {
+			result.Results[i].Result = service.ExposedCIDRs()
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
 // GetAssignedMachine returns the assigned machine tag (if any) for
 // each given unit.
 func (f *FirewallerAPI) GetAssignedMachine(args params.Entities) (params.StringResults, error) {
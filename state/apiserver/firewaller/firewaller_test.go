@@ -325,6 +325,28 @@ func (s *firewallerSuite) TestGetExposed(c *gc.C) {
 	})
 }
 
+func (s *firewallerSuite) TestGetExposedCIDRs(c *gc.C) {
+	err := s.service.SetExposedTo([]string{"10.0.0.0/8"})
+	c.Assert(err, gc.IsNil)
+
+	args := addFakeEntities(params.Entities{Entities: []params.Entity{
+		{Tag: s.service.Tag()},
+	}})
+	result, err := s.firewaller.GetExposedCIDRs(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, jc.DeepEquals, params.StringsResults{
+		Results: []params.StringsResult{
+			{Result: []string{"10.0.0.0/8"}},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.NotFoundError(`service "bar"`)},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+}
+
 func (s *firewallerSuite) TestOpenedPorts(c *gc.C) {
 	// Open some ports on two of the units.
 	err := s.units[0].OpenPort("foo", 1234)
@@ -371,6 +393,35 @@ func (s *firewallerSuite) TestOpenedPorts(c *gc.C) {
 	})
 }
 
+func (s *firewallerSuite) TestOpenedPortRanges(c *gc.C) {
+	err := s.units[0].OpenPorts("tcp", 8000, 8080)
+	c.Assert(err, gc.IsNil)
+	err = s.units[0].OpenPort("udp", 53)
+	c.Assert(err, gc.IsNil)
+
+	args := addFakeEntities(params.Entities{Entities: []params.Entity{
+		{Tag: s.units[0].Tag()},
+		{Tag: s.units[1].Tag()},
+	}})
+	result, err := s.firewaller.OpenedPortRanges(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, jc.DeepEquals, params.PortRangesResults{
+		Results: []params.PortRangesResult{
+			{PortRanges: []network.PortRange{
+				{Protocol: "tcp", FromPort: 8000, ToPort: 8080},
+				{Protocol: "udp", FromPort: 53, ToPort: 53},
+			}},
+			{PortRanges: []network.PortRange{}},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.NotFoundError(`unit "foo/0"`)},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+}
+
 func (s *firewallerSuite) TestGetAssignedMachine(c *gc.C) {
 	// Unassign a unit first.
 	err := s.units[2].UnassignFromMachine()
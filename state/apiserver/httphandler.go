@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/juju/errors"
 	"github.com/juju/names"
 
 	"github.com/juju/juju/state"
@@ -24,62 +25,82 @@ type errorSender interface {
 // httpHandler handles http requests through HTTPS in the API server.
 type httpHandler struct {
 	state *state.State
+
+	// stateForEnviron returns the State for the environment
+	// with the given UUID.
+	stateForEnviron func(envUUID string) (*state.State, error)
 }
 
 // authenticate parses HTTP basic authentication and authorizes the
 // request by looking up the provided tag and password against state.
-func (h *httpHandler) authenticate(r *http.Request) error {
+// It returns the authenticated user.
+func (h *httpHandler) authenticate(r *http.Request) (*state.User, error) {
 	parts := strings.Fields(r.Header.Get("Authorization"))
 	if len(parts) != 2 || parts[0] != "Basic" {
 		// Invalid header format or no header provided.
-		return fmt.Errorf("invalid request format")
+		return nil, fmt.Errorf("invalid request format")
 	}
 	// Challenge is a base64-encoded "tag:pass" string.
 	// See RFC 2617, Section 2.
 	challenge, err := base64.StdEncoding.DecodeString(parts[1])
 	if err != nil {
-		return fmt.Errorf("invalid request format")
+		return nil, fmt.Errorf("invalid request format")
 	}
 	tagPass := strings.SplitN(string(challenge), ":", 2)
 	if len(tagPass) != 2 {
-		return fmt.Errorf("invalid request format")
+		return nil, fmt.Errorf("invalid request format")
 	}
 	// Only allow users, not agents.
 	if _, err := names.ParseTag(tagPass[0], names.UserTagKind); err != nil {
-		return common.ErrBadCreds
+		return nil, common.ErrBadCreds
 	}
 	// Ensure the credentials are correct.
-	_, err = checkCreds(h.state, params.Creds{
+	entity, err := checkCreds(h.state, params.Creds{
 		AuthTag:  tagPass[0],
 		Password: tagPass[1],
 	})
-	return err
+	if err != nil {
+		return nil, err
+	}
+	user, ok := entity.(*state.User)
+	if !ok {
+		return nil, common.ErrBadCreds
+	}
+	return user, nil
+}
+
+// checkAccess returns common.ErrPerm unless the given user has at
+// least the given access to the environment of st.
+func (h *httpHandler) checkAccess(st *state.State, user *state.User, access state.Access) error {
+	current, err := st.UserAccess(user.Name())
+	if errors.IsNotFound(err) {
+		return common.ErrPerm
+	} else if err != nil {
+		return err
+	}
+	if !current.Allows(access) {
+		return common.ErrPerm
+	}
+	return nil
 }
 
 func (h *httpHandler) getEnvironUUID(r *http.Request) string {
 	return r.URL.Query().Get(":envuuid")
 }
 
-func (h *httpHandler) validateEnvironUUID(r *http.Request) error {
-	// Note: this is only true until we have support for multiple
-	// environments. For now, there is only one, so we make sure that is
-	// the one being addressed.
+// environState returns the State for the environment named in the
+// request's URL, which may be the state server's own environment or
+// any environment that it hosts. The state server's own environment
+// is used if the URL names none.
+func (h *httpHandler) environState(r *http.Request) (*state.State, error) {
 	envUUID := h.getEnvironUUID(r)
 	logger.Tracef("got a request for env %q", envUUID)
-	if envUUID == "" {
-		return nil
-	}
-	env, err := h.state.Environment()
+	st, err := h.stateForEnviron(envUUID)
 	if err != nil {
-		logger.Infof("error looking up environment: %v", err)
-		return err
-	}
-	if env.UUID() != envUUID {
-		logger.Infof("environment uuid mismatch: %v != %v",
-			envUUID, env.UUID())
-		return common.UnknownEnvironmentError(envUUID)
+		logger.Infof("error looking up environment %q: %v", envUUID, err)
+		return nil, err
 	}
-	return nil
+	return st, nil
 }
 
 // authError sends an unauthorized error.
@@ -0,0 +1,158 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package leadership
+
+import (
+	"time"
+
+	"github.com/juju/names"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/common"
+	"github.com/juju/juju/state/watcher"
+)
+
+// maxLeaseDuration holds the longest time for which
+// a unit may claim the leadership of its service.
+const maxLeaseDuration = 5 * time.Minute
+
+// Leadership defines the methods on the leadership API end point.
+type Leadership interface {
+	ClaimLeadership(args params.ClaimLeadershipParams) params.ErrorResults
+	IsLeader(args params.Entities) params.BoolResults
+	LeaderSettings(args params.Entities) params.LeaderSettingsResults
+	MergeLeaderSettings(args params.MergeLeaderSettingsParams) params.ErrorResults
+	WatchLeaderSettings(args params.Entities) params.NotifyWatchResults
+}
+
+// LeadershipAPI implements the Leadership interface and is the
+// concrete implementation of the api end point. Units use it to
+// elect a leader for their service, and to share settings
+// written by that leader.
+type LeadershipAPI struct {
+	state      *state.State
+	resources  *common.Resources
+	authorizer common.Authorizer
+}
+
+var _ Leadership = (*LeadershipAPI)(nil)
+
+// NewLeadershipAPI creates a new server-side leadership API end point.
+func NewLeadershipAPI(
+	st *state.State,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*LeadershipAPI, error) {
+	if !authorizer.AuthUnitAgent() {
+		return nil, common.ErrPerm
+	}
+	return &LeadershipAPI{state: st, resources: resources, authorizer: authorizer}, nil
+}
+
+// service returns the service of the unit with the given
+// tag, if the unit is the authenticated entity.
+func (api *LeadershipAPI) service(unitTag string) (*state.Service, string, error) {
+	if !api.authorizer.AuthOwner(unitTag) {
+		return nil, "", common.ErrPerm
+	}
+	tag, err := names.ParseTag(unitTag, names.UnitTagKind)
+	if err != nil {
+		return nil, "", common.ErrPerm
+	}
+	unit, err := api.state.Unit(tag.Id())
+	if err != nil {
+		return nil, "", err
+	}
+	service, err := unit.Service()
+	if err != nil {
+		return nil, "", err
+	}
+	return service, unit.Name(), nil
+}
+
+// ClaimLeadership makes each given unit the leader of its service
+// for the requested time, unless another unit is already leader.
+func (api *LeadershipAPI) ClaimLeadership(args params.ClaimLeadershipParams) params.ErrorResults {
+	results := make([]params.ErrorResult, len(args.Params))
+	for i, arg := range args.Params {
+		service, unitName, err := api.service(arg.UnitTag)
+		if err == nil {
+			duration := time.Duration(arg.DurationSeconds * float64(time.Second))
+			if duration > maxLeaseDuration {
+				duration = maxLeaseDuration
+			}
+			err = service.ClaimLeadership(unitName, duration)
+		}
+		results[i].Error = common.ServerError(err)
+	}
+	return params.ErrorResults{Results: results}
+}
+
+// IsLeader reports whether each given unit currently holds the
+// leadership lease of its service. Unlike ClaimLeadership, it never
+// changes which unit is leader.
+func (api *LeadershipAPI) IsLeader(args params.Entities) params.BoolResults {
+	results := make([]params.BoolResult, len(args.Entities))
+	for i, entity := range args.Entities {
+		service, unitName, err := api.service(entity.Tag)
+		if err == nil {
+			var leader string
+			leader, err = service.Leader()
+			results[i].Result = leader == unitName
+		}
+		results[i].Error = common.ServerError(err)
+	}
+	return params.BoolResults{Results: results}
+}
+
+// LeaderSettings returns the settings written by the
+// leader of each given unit's service.
+func (api *LeadershipAPI) LeaderSettings(args params.Entities) params.LeaderSettingsResults {
+	results := make([]params.LeaderSettingsResult, len(args.Entities))
+	for i, entity := range args.Entities {
+		service, _, err := api.service(entity.Tag)
+		if err == nil {
+			results[i].Settings, err = service.LeaderSettings()
+		}
+		results[i].Error = common.ServerError(err)
+	}
+	return params.LeaderSettingsResults{Results: results}
+}
+
+// MergeLeaderSettings writes the given settings on behalf of
+// each unit, which must be the leader of its service.
+func (api *LeadershipAPI) MergeLeaderSettings(args params.MergeLeaderSettingsParams) params.ErrorResults {
+	results := make([]params.ErrorResult, len(args.Params))
+	for i, arg := range args.Params {
+		service, unitName, err := api.service(arg.UnitTag)
+		if err == nil {
+			err = service.MergeLeaderSettings(unitName, arg.Settings)
+		}
+		results[i].Error = common.ServerError(err)
+	}
+	return params.ErrorResults{Results: results}
+}
+
+// WatchLeaderSettings starts a watcher for changes to the
+// settings written by the leader of each given unit's service.
+func (api *LeadershipAPI) WatchLeaderSettings(args params.Entities) params.NotifyWatchResults {
+	results := make([]params.NotifyWatchResult, len(args.Entities))
+	for i, entity := range args.Entities {
+		service, _, err := api.service(entity.Tag)
+		if err == nil {
+			watch := service.WatchLeaderSettings()
+			// Consume the initial event. Technically, API calls to Watch
+			// 'transmit' the initial event in the Watch response. But
+			// NotifyWatchers have no state to transmit.
+			if _, ok := <-watch.Changes(); ok {
+				results[i].NotifyWatcherId = api.resources.Register(watch)
+			} else {
+				err = watcher.MustErr(watch)
+			}
+		}
+		results[i].Error = common.ServerError(err)
+	}
+	return params.NotifyWatchResults{Results: results}
+}
@@ -0,0 +1,187 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package leadership_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/common"
+	"github.com/juju/juju/state/apiserver/leadership"
+	apiservertesting "github.com/juju/juju/state/apiserver/testing"
+	statetesting "github.com/juju/juju/state/testing"
+)
+
+type leadershipSuite struct {
+	jujutesting.JujuConnSuite
+
+	mysql      *state.Service
+	mysql0     *state.Unit
+	mysql1     *state.Unit
+	leadership *leadership.LeadershipAPI
+	resources  *common.Resources
+	authorizer apiservertesting.FakeAuthorizer
+}
+
+var _ = gc.Suite(&leadershipSuite{})
+
+func (s *leadershipSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.resources = common.NewResources()
+	s.AddCleanup(func(_ *gc.C) { s.resources.StopAll() })
+
+	var err error
+	s.mysql = s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	s.mysql0, err = s.mysql.AddUnit()
+	c.Assert(err, gc.IsNil)
+	s.mysql1, err = s.mysql.AddUnit()
+	c.Assert(err, gc.IsNil)
+
+	s.authorizer = apiservertesting.FakeAuthorizer{
+		Tag:       s.mysql0.Tag(),
+		LoggedIn:  true,
+		UnitAgent: true,
+		Entity:    s.mysql0,
+	}
+	s.leadership, err = leadership.NewLeadershipAPI(s.State, s.resources, s.authorizer)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *leadershipSuite) TestNewLeadershipAPIRefusesNonUnitAgent(c *gc.C) {
+	anAuthorizer := s.authorizer
+	anAuthorizer.UnitAgent = false
+	anAuthorizer.MachineAgent = true
+	endPoint, err := leadership.NewLeadershipAPI(s.State, s.resources, anAuthorizer)
+	c.Assert(endPoint, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *leadershipSuite) TestClaimLeadership(c *gc.C) {
+	err := s.mysql.ClaimLeadership("mysql/1", time.Minute)
+	c.Assert(err, gc.IsNil)
+
+	args := params.ClaimLeadershipParams{
+		Params: []params.ClaimLeadership{
+			{UnitTag: s.mysql0.Tag(), DurationSeconds: 30},
+			{UnitTag: s.mysql1.Tag(), DurationSeconds: 30},
+			{UnitTag: "service-mysql", DurationSeconds: 30},
+		},
+	}
+	results := s.leadership.ClaimLeadership(args)
+	c.Assert(results, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{Error: &params.Error{
+				Message: "leadership claim denied",
+				Code:    params.CodeLeadershipClaimDenied,
+			}},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+}
+
+func (s *leadershipSuite) TestIsLeader(c *gc.C) {
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: s.mysql0.Tag()}, {Tag: s.mysql1.Tag()}},
+	}
+	results := s.leadership.IsLeader(args)
+	c.Assert(results, jc.DeepEquals, params.BoolResults{
+		Results: []params.BoolResult{
+			{Result: false},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	// Asking does not claim the leadership.
+	leader, err := s.mysql.Leader()
+	c.Assert(err, gc.IsNil)
+	c.Assert(leader, gc.Equals, "")
+
+	err = s.mysql.ClaimLeadership("mysql/0", time.Minute)
+	c.Assert(err, gc.IsNil)
+	results = s.leadership.IsLeader(args)
+	c.Assert(results.Results[0], jc.DeepEquals, params.BoolResult{Result: true})
+}
+
+func (s *leadershipSuite) TestClaimAndMergeLeaderSettings(c *gc.C) {
+	claim := params.ClaimLeadershipParams{
+		Params: []params.ClaimLeadership{{UnitTag: s.mysql0.Tag(), DurationSeconds: 30}},
+	}
+	results := s.leadership.ClaimLeadership(claim)
+	c.Assert(results.OneError(), gc.IsNil)
+	leader, err := s.mysql.Leader()
+	c.Assert(err, gc.IsNil)
+	c.Assert(leader, gc.Equals, "mysql/0")
+
+	merge := params.MergeLeaderSettingsParams{
+		Params: []params.MergeLeaderSettings{{
+			UnitTag:  s.mysql0.Tag(),
+			Settings: map[string]string{"master": "10.0.0.1"},
+		}, {
+			UnitTag:  s.mysql1.Tag(),
+			Settings: map[string]string{"master": "10.0.0.2"},
+		}},
+	}
+	results = s.leadership.MergeLeaderSettings(merge)
+	c.Assert(results.Results, gc.HasLen, 2)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	c.Assert(results.Results[1].Error, jc.DeepEquals, apiservertesting.ErrUnauthorized)
+
+	settings := s.leadership.LeaderSettings(params.Entities{
+		Entities: []params.Entity{{Tag: s.mysql0.Tag()}, {Tag: s.mysql1.Tag()}},
+	})
+	c.Assert(settings, jc.DeepEquals, params.LeaderSettingsResults{
+		Results: []params.LeaderSettingsResult{
+			{Settings: map[string]string{"master": "10.0.0.1"}},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+}
+
+func (s *leadershipSuite) TestMergeLeaderSettingsNotLeader(c *gc.C) {
+	merge := params.MergeLeaderSettingsParams{
+		Params: []params.MergeLeaderSettings{{
+			UnitTag:  s.mysql0.Tag(),
+			Settings: map[string]string{"master": "10.0.0.1"},
+		}},
+	}
+	results := s.leadership.MergeLeaderSettings(merge)
+	c.Assert(results.OneError(), gc.ErrorMatches, "unit is not the service leader")
+	c.Assert(params.IsCodeNotLeader(results.OneError()), jc.IsTrue)
+}
+
+func (s *leadershipSuite) TestWatchLeaderSettings(c *gc.C) {
+	c.Assert(s.resources.Count(), gc.Equals, 0)
+
+	results := s.leadership.WatchLeaderSettings(params.Entities{
+		Entities: []params.Entity{{Tag: s.mysql0.Tag()}, {Tag: s.mysql1.Tag()}},
+	})
+	c.Assert(results, gc.DeepEquals, params.NotifyWatchResults{
+		Results: []params.NotifyWatchResult{
+			{NotifyWatcherId: "1"},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	// Verify the resource was registered and stop when done
+	c.Assert(s.resources.Count(), gc.Equals, 1)
+	resource := s.resources.Get("1")
+	defer statetesting.AssertStop(c, resource)
+
+	// Check that the Watch has consumed the initial event ("returned" in
+	// the Watch call)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, resource.(state.NotifyWatcher))
+	wc.AssertNoChange()
+
+	err := s.mysql.ClaimLeadership("mysql/0", time.Minute)
+	c.Assert(err, gc.IsNil)
+	err = s.mysql.MergeLeaderSettings("mysql/0", map[string]string{"master": "10.0.0.1"})
+	c.Assert(err, gc.IsNil)
+	wc.AssertOneChange()
+}
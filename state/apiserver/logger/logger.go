@@ -21,6 +21,7 @@ var logger = loggo.GetLogger("juju.api.logger")
 type Logger interface {
 	WatchLoggingConfig(args params.Entities) params.NotifyWatchResults
 	LoggingConfig(args params.Entities) params.StringResults
+	WriteLogs(args params.LogRecords) params.ErrorResults
 }
 
 // LoggerAPI implements the Logger interface and is the concrete
@@ -91,3 +92,33 @@ func (api *LoggerAPI) LoggingConfig(arg params.Entities) params.StringResults {
 	}
 	return params.StringResults{Results: results}
 }
+
+// WriteLogs stores the log records sent by the agents, so that they can
+// be retrieved with debug-log from any API server. Agents may only write
+// records logged by themselves.
+func (api *LoggerAPI) WriteLogs(args params.LogRecords) params.ErrorResults {
+	results := make([]params.ErrorResult, len(args.Records))
+	var records []state.LogRecord
+	var indexes []int
+	for i, arg := range args.Records {
+		if !api.authorizer.AuthOwner(arg.Entity) {
+			results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		records = append(records, state.LogRecord{
+			Time:     arg.Time,
+			Entity:   arg.Entity,
+			Module:   arg.Module,
+			Location: arg.Location,
+			Level:    arg.Level,
+			Message:  arg.Message,
+		})
+		indexes = append(indexes, i)
+	}
+	if err := api.state.AddLogs(records); err != nil {
+		for _, i := range indexes {
+			results[i].Error = common.ServerError(err)
+		}
+	}
+	return params.ErrorResults{Results: results}
+}
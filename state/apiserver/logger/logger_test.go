@@ -4,6 +4,9 @@
 package logger_test
 
 import (
+	"time"
+
+	"github.com/juju/loggo"
 	gc "launchpad.net/gocheck"
 
 	jujutesting "github.com/juju/juju/juju/testing"
@@ -13,6 +16,7 @@ import (
 	"github.com/juju/juju/state/apiserver/logger"
 	apiservertesting "github.com/juju/juju/state/apiserver/testing"
 	statetesting "github.com/juju/juju/state/testing"
+	coretesting "github.com/juju/juju/testing"
 )
 
 type loggerSuite struct {
@@ -145,3 +149,49 @@ func (s *loggerSuite) TestLoggingConfigForAgent(c *gc.C) {
 	c.Assert(result.Error, gc.IsNil)
 	c.Assert(result.Result, gc.Equals, newLoggingConfig)
 }
+
+func (s *loggerSuite) TestWriteLogs(c *gc.C) {
+	start := time.Date(2014, 10, 1, 12, 0, 0, 0, time.UTC)
+	args := params.LogRecords{
+		Records: []params.LogRecord{{
+			Entity:   s.rawMachine.Tag(),
+			Time:     start,
+			Module:   "juju.worker.machiner",
+			Location: "machiner.go:54",
+			Level:    loggo.INFO,
+			Message:  "machine is alive",
+		}, {
+			Entity:  "machine-12354",
+			Time:    start,
+			Module:  "juju.worker.machiner",
+			Level:   loggo.INFO,
+			Message: "not mine",
+		}},
+	}
+	results := s.logger.WriteLogs(args)
+	c.Assert(results, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{Error: nil},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	t := s.State.NewLogTailer(state.LogTailerParams{})
+	defer t.Stop()
+	select {
+	case record := <-t.Logs():
+		c.Assert(record.Entity, gc.Equals, s.rawMachine.Tag())
+		c.Assert(record.Time.Equal(start), gc.Equals, true)
+		c.Assert(record.Module, gc.Equals, "juju.worker.machiner")
+		c.Assert(record.Location, gc.Equals, "machiner.go:54")
+		c.Assert(record.Level, gc.Equals, loggo.INFO)
+		c.Assert(record.Message, gc.Equals, "machine is alive")
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for log record")
+	}
+	select {
+	case record := <-t.Logs():
+		c.Fatalf("unexpected log record %#v", record)
+	case <-time.After(coretesting.ShortWait):
+	}
+}
@@ -4,6 +4,7 @@
 package apiserver_test
 
 import (
+	"fmt"
 	"net"
 	"strconv"
 	"sync"
@@ -15,11 +16,13 @@ import (
 	"github.com/juju/utils"
 	gc "launchpad.net/gocheck"
 
+	"github.com/juju/juju/instance"
 	jujutesting "github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/api"
 	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/api/usermanager"
 	"github.com/juju/juju/state/apiserver"
 	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/testing/factory"
@@ -495,6 +498,143 @@ func (s *loginSuite) TestLoginReportsEnvironTag(c *gc.C) {
 	c.Assert(result.EnvironTag, gc.Equals, env.Tag())
 }
 
+func (s *loginSuite) TestLoginToHostedEnvironment(c *gc.C) {
+	cfg, err := s.State.EnvironConfig()
+	c.Assert(err, gc.IsNil)
+	cfg, err = cfg.Apply(map[string]interface{}{"name": "sandbox"})
+	c.Assert(err, gc.IsNil)
+	env, hostedSt, err := s.State.NewEnvironment(cfg, "admin")
+	c.Assert(err, gc.IsNil)
+	defer hostedSt.Close()
+
+	info, cleanup := s.setupServer(c)
+	defer cleanup()
+	info.EnvironTag = env.Tag()
+	info.Tag = "user-admin"
+	info.Password = "dummy-secret"
+	st, err := api.Open(info, fastDialOpts)
+	c.Assert(err, gc.IsNil)
+	defer st.Close()
+	c.Assert(st.EnvironTag(), gc.Equals, env.Tag())
+
+	// Requests are served by the hosted environment.
+	_, err = st.Client().AddMachines([]params.AddMachineParams{{
+		Series: "quantal",
+		Jobs:   []params.MachineJob{params.JobHostUnits},
+	}})
+	c.Assert(err, gc.IsNil)
+	machines, err := hostedSt.AllMachines()
+	c.Assert(err, gc.IsNil)
+	c.Assert(machines, gc.HasLen, 1)
+	machines, err = s.State.AllMachines()
+	c.Assert(err, gc.IsNil)
+	c.Assert(machines, gc.HasLen, 0)
+}
+
+func (s *loginSuite) TestUserLoginToHostedEnvironmentNeedsAccess(c *gc.C) {
+	cfg, err := s.State.EnvironConfig()
+	c.Assert(err, gc.IsNil)
+	cfg, err = cfg.Apply(map[string]interface{}{"name": "sandbox"})
+	c.Assert(err, gc.IsNil)
+	env, hostedSt, err := s.State.NewEnvironment(cfg, "admin")
+	c.Assert(err, gc.IsNil)
+	defer hostedSt.Close()
+	user := factory.NewFactory(s.State, c).MakeUser(factory.UserParams{Password: "password"})
+
+	info, cleanup := s.setupServer(c)
+	defer cleanup()
+	info.EnvironTag = env.Tag()
+	info.Tag = user.Tag()
+	info.Password = "password"
+	_, err = api.Open(info, fastDialOpts)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+
+	err = hostedSt.SetUserAccess(user.Name(), state.ReadAccess)
+	c.Assert(err, gc.IsNil)
+	st, err := api.Open(info, fastDialOpts)
+	c.Assert(err, gc.IsNil)
+	st.Close()
+}
+
+func (s *loginSuite) TestUserAddedInHostedEnvironment(c *gc.C) {
+	cfg, err := s.State.EnvironConfig()
+	c.Assert(err, gc.IsNil)
+	cfg, err = cfg.Apply(map[string]interface{}{"name": "sandbox"})
+	c.Assert(err, gc.IsNil)
+	env, hostedSt, err := s.State.NewEnvironment(cfg, "admin")
+	c.Assert(err, gc.IsNil)
+	defer hostedSt.Close()
+
+	info, cleanup := s.setupServer(c)
+	defer cleanup()
+	info.EnvironTag = env.Tag()
+	info.Tag = "user-admin"
+	info.Password = "dummy-secret"
+	st, err := api.Open(info, fastDialOpts)
+	c.Assert(err, gc.IsNil)
+	defer st.Close()
+	err = usermanager.NewClient(st).AddUser("foobar", "Foo Bar", "password")
+	c.Assert(err, gc.IsNil)
+
+	// The new user has access to the hosted
+	// environment, but to no other.
+	access, err := hostedSt.UserAccess("foobar")
+	c.Assert(err, gc.IsNil)
+	c.Assert(access, gc.Equals, state.ReadAccess)
+	_, err = s.State.UserAccess("foobar")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *loginSuite) TestStateServerMachineLogsInToHostedEnvironment(c *gc.C) {
+	cfg, err := s.State.EnvironConfig()
+	c.Assert(err, gc.IsNil)
+	cfg, err = cfg.Apply(map[string]interface{}{"name": "sandbox"})
+	c.Assert(err, gc.IsNil)
+	env, hostedSt, err := s.State.NewEnvironment(cfg, "admin")
+	c.Assert(err, gc.IsNil)
+	defer hostedSt.Close()
+	serverInfo, cleanup := s.setupServer(c)
+	defer cleanup()
+
+	for i, job := range []state.MachineJob{state.JobManageEnviron, state.JobHostUnits} {
+		c.Logf("test %d: %v", i, job)
+		machine, err := s.State.AddMachine("quantal", job)
+		c.Assert(err, gc.IsNil)
+		err = machine.SetProvisioned(instance.Id(fmt.Sprintf("inst-%d", i)), "fake_nonce", nil)
+		c.Assert(err, gc.IsNil)
+		password, err := utils.RandomPassword()
+		c.Assert(err, gc.IsNil)
+		err = machine.SetPassword(password)
+		c.Assert(err, gc.IsNil)
+
+		info := *serverInfo
+		info.EnvironTag = env.Tag()
+		info.Tag = machine.Tag()
+		info.Password = password
+		info.Nonce = "fake_nonce"
+		st, err := api.Open(&info, fastDialOpts)
+		if job != state.JobManageEnviron {
+			// Only the machines that manage the state server's
+			// environment run the workers of hosted environments.
+			c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+			continue
+		}
+		c.Assert(err, gc.IsNil)
+		c.Assert(st.EnvironTag(), gc.Equals, env.Tag())
+		st.Close()
+	}
+}
+
+func (s *loginSuite) TestLoginToUnknownEnvironment(c *gc.C) {
+	info, cleanup := s.setupServer(c)
+	defer cleanup()
+	info.EnvironTag = "environment-deadbeef-0bad-400d-8000-4b1d0d06f00d"
+	info.Tag = "user-admin"
+	info.Password = "dummy-secret"
+	_, err := api.Open(info, fastDialOpts)
+	c.Assert(err, gc.ErrorMatches, `unknown environment: "deadbeef-0bad-400d-8000-4b1d0d06f00d"`)
+}
+
 func (s *loginSuite) TestLoginValidationSuccess(c *gc.C) {
 	validator := func(_ params.Creds) error {
 		return nil
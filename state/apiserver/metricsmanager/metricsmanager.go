@@ -0,0 +1,99 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package metricsmanager
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/common"
+)
+
+// MetricsManagerAPI implements the API end point used by clients to
+// list and export the metrics reported by units, and by the state
+// server's agent to clean up the metrics that have been exported.
+type MetricsManagerAPI struct {
+	st         *state.State
+	authorizer common.Authorizer
+}
+
+// NewMetricsManagerAPI returns a new MetricsManagerAPI.
+func NewMetricsManagerAPI(st *state.State, authorizer common.Authorizer) (*MetricsManagerAPI, error) {
+	if !authorizer.AuthClient() && !authorizer.AuthEnvironManager() {
+		return nil, common.ErrPerm
+	}
+	return &MetricsManagerAPI{
+		st:         st,
+		authorizer: authorizer,
+	}, nil
+}
+
+// MetricBatches returns all the metric batches in the environment,
+// oldest first.
+func (api *MetricsManagerAPI) MetricBatches() (params.MetricBatchesResult, error) {
+	if !api.authorizer.AuthClient() {
+		return params.MetricBatchesResult{}, common.ErrPerm
+	}
+	batches, err := api.st.MetricBatches()
+	if err != nil {
+		return params.MetricBatchesResult{}, errors.Trace(err)
+	}
+	return batchesResult(batches), nil
+}
+
+// ExportMetricBatches returns the metric batches that have not yet
+// been exported, oldest first. The batches are returned again by
+// later calls until they are acknowledged with AcknowledgeMetricBatches,
+// so that batches are not lost if they cannot be delivered.
+func (api *MetricsManagerAPI) ExportMetricBatches() (params.MetricBatchesResult, error) {
+	if !api.authorizer.AuthClient() {
+		return params.MetricBatchesResult{}, common.ErrPerm
+	}
+	batches, err := api.st.UnsentMetricBatches()
+	if err != nil {
+		return params.MetricBatchesResult{}, errors.Trace(err)
+	}
+	return batchesResult(batches), nil
+}
+
+// AcknowledgeMetricBatches marks the given exported metric batches as
+// sent, once they have been delivered, so that they are not exported
+// again and are eventually cleaned up.
+func (api *MetricsManagerAPI) AcknowledgeMetricBatches(args params.MetricBatchUUIDs) error {
+	if !api.authorizer.AuthClient() {
+		return common.ErrPerm
+	}
+	return api.st.SetMetricBatchesSent(args.UUIDs)
+}
+
+// CleanupOldMetrics removes the exported metric batches that are
+// old enough to be expired.
+func (api *MetricsManagerAPI) CleanupOldMetrics() error {
+	if !api.authorizer.AuthEnvironManager() {
+		return common.ErrPerm
+	}
+	return api.st.CleanupOldMetrics()
+}
+
+func batchesResult(batches []*state.MetricBatch) params.MetricBatchesResult {
+	result := params.MetricBatchesResult{
+		Batches: make([]params.MetricBatch, len(batches)),
+	}
+	for i, batch := range batches {
+		metrics := batch.Metrics()
+		result.Batches[i] = params.MetricBatch{
+			UUID:     batch.UUID(),
+			Unit:     batch.Unit(),
+			CharmURL: batch.CharmURL(),
+			Sent:     batch.Sent(),
+			Created:  batch.Created(),
+			Metrics:  make([]params.Metric, len(metrics)),
+		}
+		for j, m := range metrics {
+			result.Batches[i].Metrics[j] = params.Metric{Key: m.Key, Value: m.Value, Time: m.Time}
+		}
+	}
+	return result
+}
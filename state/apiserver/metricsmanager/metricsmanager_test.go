@@ -0,0 +1,128 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package metricsmanager_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/common"
+	"github.com/juju/juju/state/apiserver/metricsmanager"
+	apiservertesting "github.com/juju/juju/state/apiserver/testing"
+)
+
+type metricsManagerSuite struct {
+	jujutesting.JujuConnSuite
+
+	client  *metricsmanager.MetricsManagerAPI
+	manager *metricsmanager.MetricsManagerAPI
+	unit    *state.Unit
+}
+
+var _ = gc.Suite(&metricsManagerSuite{})
+
+func (s *metricsManagerSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	var err error
+	s.client, err = metricsmanager.NewMetricsManagerAPI(s.State, apiservertesting.FakeAuthorizer{
+		Tag:      "user-admin",
+		LoggedIn: true,
+		Client:   true,
+	})
+	c.Assert(err, gc.IsNil)
+	s.manager, err = metricsmanager.NewMetricsManagerAPI(s.State, apiservertesting.FakeAuthorizer{
+		Tag:            "machine-0",
+		LoggedIn:       true,
+		EnvironManager: true,
+	})
+	c.Assert(err, gc.IsNil)
+
+	ch := s.AddTestingCharm(c, "wordpress")
+	svc := s.AddTestingService(c, "wordpress", ch)
+	s.unit, err = svc.AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = s.unit.SetCharmURL(ch.URL())
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *metricsManagerSuite) addMetrics(c *gc.C, created time.Time) *state.MetricBatch {
+	batch, err := s.unit.AddMetrics(created, []state.Metric{{Key: "pings", Value: "5", Time: created}})
+	c.Assert(err, gc.IsNil)
+	return batch
+}
+
+func (s *metricsManagerSuite) TestNewMetricsManagerAPIRefusesUnitAgent(c *gc.C) {
+	api, err := metricsmanager.NewMetricsManagerAPI(s.State, apiservertesting.FakeAuthorizer{
+		Tag:       "unit-wordpress-0",
+		LoggedIn:  true,
+		UnitAgent: true,
+	})
+	c.Assert(api, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *metricsManagerSuite) TestMetricBatches(c *gc.C) {
+	created := time.Date(2014, 10, 1, 12, 0, 0, 0, time.UTC)
+	batch := s.addMetrics(c, created)
+
+	result, err := s.client.MetricBatches()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Batches, gc.HasLen, 1)
+	c.Assert(result.Batches[0].UUID, gc.Equals, batch.UUID())
+	c.Assert(result.Batches[0].Unit, gc.Equals, "wordpress/0")
+	c.Assert(result.Batches[0].Sent, jc.IsFalse)
+	c.Assert(result.Batches[0].Created.Equal(created), jc.IsTrue)
+	c.Assert(result.Batches[0].Metrics, gc.HasLen, 1)
+	c.Assert(result.Batches[0].Metrics[0].Key, gc.Equals, "pings")
+	c.Assert(result.Batches[0].Metrics[0].Value, gc.Equals, "5")
+
+	_, err = s.manager.MetricBatches()
+	c.Assert(err, gc.Equals, common.ErrPerm)
+}
+
+func (s *metricsManagerSuite) TestExportMetricBatches(c *gc.C) {
+	batch := s.addMetrics(c, time.Now())
+
+	result, err := s.client.ExportMetricBatches()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Batches, gc.HasLen, 1)
+	c.Assert(result.Batches[0].UUID, gc.Equals, batch.UUID())
+	c.Assert(result.Batches[0].Sent, jc.IsFalse)
+
+	// Batches that have not been acknowledged are exported again.
+	result, err = s.client.ExportMetricBatches()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Batches, gc.HasLen, 1)
+
+	err = s.client.AcknowledgeMetricBatches(params.MetricBatchUUIDs{UUIDs: []string{batch.UUID()}})
+	c.Assert(err, gc.IsNil)
+	result, err = s.client.ExportMetricBatches()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Batches, gc.HasLen, 0)
+
+	batch, err = s.State.MetricBatch(batch.UUID())
+	c.Assert(err, gc.IsNil)
+	c.Assert(batch.Sent(), jc.IsTrue)
+
+	err = s.manager.AcknowledgeMetricBatches(params.MetricBatchUUIDs{UUIDs: []string{batch.UUID()}})
+	c.Assert(err, gc.Equals, common.ErrPerm)
+}
+
+func (s *metricsManagerSuite) TestCleanupOldMetrics(c *gc.C) {
+	old := s.addMetrics(c, time.Now().Add(-2*state.MetricsCleanupAge))
+	err := s.client.AcknowledgeMetricBatches(params.MetricBatchUUIDs{UUIDs: []string{old.UUID()}})
+	c.Assert(err, gc.IsNil)
+
+	err = s.client.CleanupOldMetrics()
+	c.Assert(err, gc.Equals, common.ErrPerm)
+	err = s.manager.CleanupOldMetrics()
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.MetricBatch(old.UUID())
+	c.Assert(err, gc.ErrorMatches, `metric batch ".*" not found`)
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/juju/juju/state/api/params"
 	"github.com/juju/juju/state/apiserver/common"
 	"github.com/juju/juju/state/watcher"
+	"github.com/juju/juju/storage"
 )
 
 // ProvisionerAPI provides access to the Provisioner API facade.
@@ -31,6 +32,7 @@ type ProvisionerAPI struct {
 	*common.EnvironWatcher
 	*common.EnvironMachinesWatcher
 	*common.InstanceIdGetter
+	*common.VolumeInfoSetter
 
 	st                  *state.State
 	resources           *common.Resources
@@ -89,6 +91,7 @@ func NewProvisionerAPI(
 		EnvironWatcher:         common.NewEnvironWatcher(st, resources, getCanWatch, getCanReadSecrets),
 		EnvironMachinesWatcher: common.NewEnvironMachinesWatcher(st, resources, getCanReadSecrets),
 		InstanceIdGetter:       common.NewInstanceIdGetter(st, getAuthFunc),
+		VolumeInfoSetter:       common.NewVolumeInfoSetter(st, getAuthFunc),
 		st:                     st,
 		resources:              resources,
 		authorizer:             authorizer,
@@ -228,6 +231,9 @@ func (p *ProvisionerAPI) ContainerConfig() (params.ContainerConfig, error) {
 	result.SSLHostnameVerification = config.SSLHostnameVerification()
 	result.Proxy = config.ProxySettings()
 	result.AptProxy = config.AptProxySettings()
+	result.AptMirror = config.AptMirror()
+	result.PackageSources = config.PackageSources()
+	result.DisableOSUpgrade = !config.EnableOSUpgrade()
 	return result, nil
 }
 
@@ -322,14 +328,14 @@ func (p *ProvisionerAPI) ProvisioningInfo(args params.Entities) (params.Provisio
 	for i, entity := range args.Entities {
 		machine, err := p.getMachine(canAccess, entity.Tag)
 		if err == nil {
-			result.Results[i].Result, err = getProvisioningInfo(machine)
+			result.Results[i].Result, err = getProvisioningInfo(p.st, machine)
 		}
 		result.Results[i].Error = common.ServerError(err)
 	}
 	return result, nil
 }
 
-func getProvisioningInfo(m *state.Machine) (*params.ProvisioningInfo, error) {
+func getProvisioningInfo(st *state.State, m *state.Machine) (*params.ProvisioningInfo, error) {
 	cons, err := m.Constraints()
 	if err != nil {
 		return nil, err
@@ -344,14 +350,96 @@ func getProvisioningInfo(m *state.Machine) (*params.ProvisioningInfo, error) {
 	if err != nil {
 		return nil, err
 	}
+	volumes, err := machineVolumeParams(st, m)
+	if err != nil {
+		return nil, err
+	}
+	subnetsToZones, err := machineSubnetsToZones(st, cons)
+	if err != nil {
+		return nil, err
+	}
 	return &params.ProvisioningInfo{
-		Constraints: cons,
-		Series:      m.Series(),
-		Placement:   m.Placement(),
-		Networks:    networks,
+		Constraints:    cons,
+		Series:         m.Series(),
+		Placement:      m.Placement(),
+		Networks:       networks,
+		Volumes:        volumes,
+		SubnetsToZones: subnetsToZones,
 	}, nil
 }
 
+// machineSubnetsToZones returns a map from the provider ids of the
+// subnets in the spaces included by the given constraints to the
+// availability zones they are usable in. Subnets the provider does
+// not know about are left out.
+func machineSubnetsToZones(st *state.State, cons constraints.Value) (map[string][]string, error) {
+	spaces := cons.IncludeSpaces()
+	if len(spaces) == 0 {
+		return nil, nil
+	}
+	subnetsToZones := make(map[string][]string)
+	for _, name := range spaces {
+		space, err := st.Space(name)
+		if err != nil {
+			return nil, err
+		}
+		subnets, err := space.Subnets()
+		if err != nil {
+			return nil, err
+		}
+		for _, subnet := range subnets {
+			if subnet.ProviderId() == "" {
+				continue
+			}
+			subnetsToZones[string(subnet.ProviderId())] = subnet.AvailabilityZones()
+		}
+	}
+	if len(subnetsToZones) == 0 {
+		return nil, fmt.Errorf("no provider subnets in spaces %v", spaces)
+	}
+	return subnetsToZones, nil
+}
+
+// machineVolumeParams returns the parameters of the unprovisioned
+// volumes attached to the machine that are managed by the
+// environment provisioner.
+func machineVolumeParams(st *state.State, m *state.Machine) ([]storage.VolumeParams, error) {
+	if m.ContainerType() != "" {
+		// Environ-scoped volumes cannot be attached to containers.
+		return nil, nil
+	}
+	attachments, err := m.VolumeAttachments()
+	if err != nil {
+		return nil, err
+	}
+	var allParams []storage.VolumeParams
+	for _, attachment := range attachments {
+		if attachment.Life() != state.Alive {
+			continue
+		}
+		volume, err := st.Volume(attachment.Volume())
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := volume.Info(); ok {
+			continue
+		}
+		volumeParams := volume.Params()
+		provider, err := storage.StorageProvider(volumeParams.Provider)
+		if err != nil {
+			return nil, err
+		}
+		if provider.Scope() != storage.ScopeEnviron {
+			continue
+		}
+		volumeParams.Attachment = &storage.AttachmentParams{
+			Machine: m.Id(),
+		}
+		allParams = append(allParams, volumeParams)
+	}
+	return allParams, nil
+}
+
 // DistributionGroup returns, for each given machine entity,
 // a slice of instance.Ids that belong to the same distribution
 // group as that machine. This information may be used to
@@ -595,3 +683,90 @@ func (p *ProvisionerAPI) WatchMachineErrorRetry() (params.NotifyWatchResult, err
 	}
 	return result, nil
 }
+
+// WatchVolumes returns a StringsWatcher that notifies of changes to
+// the lifecycles of the volumes in the environment.
+func (p *ProvisionerAPI) WatchVolumes() (params.StringsWatchResult, error) {
+	result := params.StringsWatchResult{}
+	canWatch, err := p.getCanWatchMachines()
+	if err != nil {
+		return params.StringsWatchResult{}, err
+	}
+	if !canWatch("") {
+		return result, common.ErrPerm
+	}
+	watch := p.st.WatchVolumes()
+	// Consume the initial event and forward it to the result.
+	if changes, ok := <-watch.Changes(); ok {
+		result.StringsWatcherId = p.resources.Register(watch)
+		result.Changes = changes
+	} else {
+		return result, watcher.MustErr(watch)
+	}
+	return result, nil
+}
+
+func (p *ProvisionerAPI) volume(name string) (params.Volume, error) {
+	nothing := params.Volume{}
+	volume, err := p.st.Volume(name)
+	if err != nil {
+		return nothing, err
+	}
+	attachments, err := volume.Attachments()
+	if err != nil {
+		return nothing, err
+	}
+	result := params.Volume{
+		Params:   volume.Params(),
+		Life:     params.Life(volume.Life().String()),
+		Attached: len(attachments) > 0,
+	}
+	if info, ok := volume.Info(); ok {
+		result.Provisioned = true
+		result.VolumeId = info.VolumeId
+	}
+	return result, nil
+}
+
+// Volumes returns the details of each given volume, for the
+// environment provisioner to destroy volumes that are no longer
+// required.
+func (p *ProvisionerAPI) Volumes(args params.VolumeNames) (params.VolumeResults, error) {
+	result := params.VolumeResults{
+		Results: make([]params.VolumeResult, len(args.Names)),
+	}
+	canManage, err := p.getCanWatchMachines()
+	if err != nil {
+		return result, err
+	}
+	for i, name := range args.Names {
+		var err error
+		if canManage("") {
+			result.Results[i].Result, err = p.volume(name)
+		} else {
+			err = common.ErrPerm
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// RemoveVolumes removes each given volume, which must be dying
+// or dead, along with any remaining attachments.
+func (p *ProvisionerAPI) RemoveVolumes(args params.VolumeNames) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Names)),
+	}
+	canManage, err := p.getCanWatchMachines()
+	if err != nil {
+		return result, err
+	}
+	for i, name := range args.Names {
+		err := common.ErrPerm
+		if canManage("") {
+			err = p.st.RemoveVolume(name)
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/container"
+	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/network"
@@ -25,6 +26,8 @@ import (
 	"github.com/juju/juju/state/apiserver/provisioner"
 	apiservertesting "github.com/juju/juju/state/apiserver/testing"
 	statetesting "github.com/juju/juju/state/testing"
+	"github.com/juju/juju/storage"
+	_ "github.com/juju/juju/storage/provider"
 	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/version"
 )
@@ -768,6 +771,196 @@ func (s *withoutStateServerSuite) TestProvisioningInfo(c *gc.C) {
 	})
 }
 
+func (s *withoutStateServerSuite) TestProvisioningInfoSpaces(c *gc.C) {
+	for _, info := range []state.SubnetInfo{
+		{CIDR: "10.0.0.0/24", ProviderId: "subnet-0", AvailabilityZones: []string{"zone1"}},
+		{CIDR: "10.0.1.0/24", ProviderId: "subnet-1", AvailabilityZones: []string{"zone2"}},
+		{CIDR: "10.0.2.0/24"},
+		{CIDR: "10.0.3.0/24", ProviderId: "subnet-3"},
+	} {
+		_, err := s.State.AddSubnet(info)
+		c.Assert(err, gc.IsNil)
+	}
+	_, err := s.State.AddSpace("db", []string{"10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/24"})
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.AddSpace("dmz", []string{"10.0.3.0/24"})
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.AddSpace("empty", nil)
+	c.Assert(err, gc.IsNil)
+
+	addMachine := func(cons string) *state.Machine {
+		m, err := s.State.AddOneMachine(state.MachineTemplate{
+			Series:      "quantal",
+			Jobs:        []state.MachineJob{state.JobHostUnits},
+			Constraints: constraints.MustParse(cons),
+		})
+		c.Assert(err, gc.IsNil)
+		return m
+	}
+	inDB := addMachine("spaces=db,^dmz")
+	inEmpty := addMachine("spaces=empty")
+
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: inDB.Tag()},
+		{Tag: inEmpty.Tag()},
+	}}
+	result, err := s.provisioner.ProvisioningInfo(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Results, gc.HasLen, 2)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+	c.Assert(result.Results[0].Result.SubnetsToZones, gc.DeepEquals, map[string][]string{
+		"subnet-0": {"zone1"},
+		"subnet-1": {"zone2"},
+	})
+	c.Assert(result.Results[1].Error, gc.ErrorMatches, `no provider subnets in spaces \[empty\]`)
+}
+
+// staticStorageProvider is an environ-scoped storage provider whose
+// volumes are created along with the instances they attach to.
+type staticStorageProvider struct {
+	storage.Provider
+}
+
+func (staticStorageProvider) Scope() storage.Scope {
+	return storage.ScopeEnviron
+}
+
+func (staticStorageProvider) Dynamic() bool {
+	return false
+}
+
+func init() {
+	storage.RegisterProvider("static", staticStorageProvider{})
+}
+
+func (s *withoutStateServerSuite) TestProvisioningInfoVolumes(c *gc.C) {
+	service := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	unit, err := service.AddUnitWithStorage(map[string]storage.Directive{
+		"cache": {Pool: "loop", Size: 512, Count: 1},
+		"data":  {Pool: "static", Size: 1024, Count: 1},
+	})
+	c.Assert(err, gc.IsNil)
+	err = unit.AssignToMachine(s.machines[1])
+	c.Assert(err, gc.IsNil)
+
+	args := params.Entities{Entities: []params.Entity{{Tag: s.machines[1].Tag()}}}
+	result, err := s.provisioner.ProvisioningInfo(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Results, gc.HasLen, 1)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+	// Only the environ-scoped volume is created by the provisioner.
+	c.Assert(result.Results[0].Result.Volumes, gc.DeepEquals, []storage.VolumeParams{{
+		Name:       "1",
+		Size:       1024,
+		Provider:   "static",
+		Attachment: &storage.AttachmentParams{Machine: s.machines[1].Id()},
+	}})
+
+	// Once provisioned, the volume is no longer reported.
+	setResults, err := s.provisioner.SetVolumeInfo(params.VolumesInfo{
+		Volumes: []params.VolumeInfo{{
+			MachineTag: s.machines[1].Tag(),
+			Volume:     storage.Volume{Name: "1", VolumeId: "vol-1", Size: 1024},
+			Attachment: storage.VolumeAttachment{Volume: "1", Machine: s.machines[1].Id(), DeviceName: "xvdf"},
+		}, {
+			MachineTag: s.machines[0].Tag(),
+			Volume:     storage.Volume{Name: "1", VolumeId: "vol-1", Size: 1024},
+			Attachment: storage.VolumeAttachment{Volume: "1", Machine: s.machines[0].Id(), DeviceName: "xvdf"},
+		}, {
+			MachineTag: "unit-mysql-0",
+		}},
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(setResults, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{nil},
+			{apiservertesting.NotFoundError(`volume "1" on machine "0"`)},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+	volume, err := s.State.Volume("1")
+	c.Assert(err, gc.IsNil)
+	info, ok := volume.Info()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(info, gc.Equals, state.VolumeInfo{VolumeId: "vol-1", Size: 1024})
+
+	result, err = s.provisioner.ProvisioningInfo(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Results[0].Result.Volumes, gc.HasLen, 0)
+}
+
+func (s *withoutStateServerSuite) TestVolumes(c *gc.C) {
+	service := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	unit, err := service.AddUnitWithStorage(map[string]storage.Directive{
+		"data": {Pool: "static", Size: 1024, Count: 1},
+	})
+	c.Assert(err, gc.IsNil)
+	err = unit.AssignToMachine(s.machines[1])
+	c.Assert(err, gc.IsNil)
+	err = s.State.SetVolumeInfo("0", state.VolumeInfo{VolumeId: "vol-0", Size: 1024})
+	c.Assert(err, gc.IsNil)
+
+	args := params.VolumeNames{Names: []string{"0", "42"}}
+	result, err := s.provisioner.Volumes(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.VolumeResults{
+		Results: []params.VolumeResult{
+			{Result: params.Volume{
+				Params:      storage.VolumeParams{Name: "0", Size: 1024, Provider: "static"},
+				Life:        params.Alive,
+				Provisioned: true,
+				VolumeId:    "vol-0",
+				Attached:    true,
+			}},
+			{Error: apiservertesting.NotFoundError(`volume "42"`)},
+		},
+	})
+
+	removeResults, err := s.provisioner.RemoveVolumes(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(removeResults.Results, gc.HasLen, 2)
+	c.Assert(removeResults.Results[0].Error, gc.ErrorMatches, `cannot remove volume "0": volume is alive`)
+	c.Assert(removeResults.Results[1].Error, gc.IsNil)
+
+	err = unit.EnsureDead()
+	c.Assert(err, gc.IsNil)
+	err = unit.Remove()
+	c.Assert(err, gc.IsNil)
+	err = s.State.Cleanup()
+	c.Assert(err, gc.IsNil)
+	removeResults, err = s.provisioner.RemoveVolumes(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(removeResults, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{{nil}, {nil}},
+	})
+	_, err = s.State.Volume("0")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *withoutStateServerSuite) TestVolumesPermissions(c *gc.C) {
+	anAuthorizer := s.authorizer
+	anAuthorizer.MachineAgent = true
+	anAuthorizer.EnvironManager = false
+	anAuthorizer.Tag = s.machines[0].Tag()
+	aProvisioner, err := provisioner.NewProvisionerAPI(s.State, s.resources, anAuthorizer)
+	c.Assert(err, gc.IsNil)
+
+	args := params.VolumeNames{Names: []string{"0"}}
+	result, err := aProvisioner.Volumes(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.VolumeResults{
+		Results: []params.VolumeResult{{Error: apiservertesting.ErrUnauthorized}},
+	})
+	removeResults, err := aProvisioner.RemoveVolumes(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(removeResults, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{{apiservertesting.ErrUnauthorized}},
+	})
+	watchResult, err := aProvisioner.WatchVolumes()
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	c.Assert(watchResult, gc.DeepEquals, params.StringsWatchResult{})
+}
+
 func (s *withoutStateServerSuite) TestProvisioningInfoPermissions(c *gc.C) {
 	// Login as a machine agent for machine 0.
 	anAuthorizer := s.authorizer
@@ -1165,6 +1358,34 @@ func (s *withoutStateServerSuite) TestContainerConfig(c *gc.C) {
 	c.Check(results.SSLHostnameVerification, jc.IsTrue)
 	c.Check(results.Proxy, gc.DeepEquals, expectedProxy)
 	c.Check(results.AptProxy, gc.DeepEquals, expectedProxy)
+	c.Check(results.AptMirror, gc.Equals, "")
+	c.Check(results.PackageSources, gc.HasLen, 0)
+	c.Check(results.DisableOSUpgrade, jc.IsFalse)
+}
+
+func (s *withoutStateServerSuite) TestContainerConfigPackages(c *gc.C) {
+	attrs := map[string]interface{}{
+		"apt-mirror": "http://mirror.example.com/ubuntu",
+		"package-sources": []interface{}{
+			map[string]interface{}{"source": "ppa:juju/stable"},
+			map[string]interface{}{
+				"source": "deb http://example.com/ubuntu trusty main",
+				"key":    "some-key",
+			},
+		},
+		"enable-os-upgrade": false,
+	}
+	err := s.State.UpdateEnvironConfig(attrs, nil, nil)
+	c.Assert(err, gc.IsNil)
+
+	results, err := s.provisioner.ContainerConfig()
+	c.Check(err, gc.IsNil)
+	c.Check(results.AptMirror, gc.Equals, "http://mirror.example.com/ubuntu")
+	c.Check(results.PackageSources, gc.DeepEquals, []config.PackageSource{
+		{Source: "ppa:juju/stable"},
+		{Source: "deb http://example.com/ubuntu trusty main", Key: "some-key"},
+	})
+	c.Check(results.DisableOSUpgrade, jc.IsTrue)
 }
 
 func (s *withoutStateServerSuite) TestToolsRefusesWrongAgent(c *gc.C) {
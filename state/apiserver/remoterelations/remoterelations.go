@@ -0,0 +1,405 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package remoterelations
+
+import (
+	"fmt"
+
+	"github.com/juju/charm"
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/common"
+	"github.com/juju/juju/state/watcher"
+)
+
+var logger = loggo.GetLogger("juju.state.apiserver.remoterelations")
+
+// StateForEnvironFunc returns a State for the environment with the
+// given UUID, hosted by the state server. The returned State is
+// owned by the API server and must not be closed.
+type StateForEnvironFunc func(uuid string) (*state.State, error)
+
+// RemoteRelationsAPI implements the API end point used to keep the
+// relations between local and remote services in step across all the
+// environments hosted by a state server.
+type RemoteRelationsAPI struct {
+	st              *state.State
+	stateForEnviron StateForEnvironFunc
+	resources       *common.Resources
+	authorizer      common.Authorizer
+}
+
+// NewRemoteRelationsAPI returns a new RemoteRelationsAPI. The given
+// State must be that of the state server's own environment.
+func NewRemoteRelationsAPI(
+	st *state.State,
+	stateForEnviron StateForEnvironFunc,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*RemoteRelationsAPI, error) {
+	if !authorizer.AuthEnvironManager() {
+		return nil, common.ErrPerm
+	}
+	return &RemoteRelationsAPI{
+		st:              st,
+		stateForEnviron: stateForEnviron,
+		resources:       resources,
+		authorizer:      authorizer,
+	}, nil
+}
+
+// WatchRemoteRelations starts a NotifyWatcher that notifies of
+// changes to the relations with remote services in all the
+// environments hosted by the state server, to the units in
+// their scopes, and to those units' settings.
+func (api *RemoteRelationsAPI) WatchRemoteRelations() (params.NotifyWatchResult, error) {
+	watch := newRemoteRelationsWatcher(api.st, api.stateForEnviron)
+	// Consume the initial event. Technically, API calls to Watch
+	// 'transmit' the initial event in the Watch response. But
+	// NotifyWatchers have no state to transmit.
+	if _, ok := <-watch.Changes(); ok {
+		return params.NotifyWatchResult{
+			NotifyWatcherId: api.resources.Register(watch),
+		}, nil
+	}
+	return params.NotifyWatchResult{}, watcher.MustErr(watch)
+}
+
+// environUUIDs returns the UUIDs of the state server's own environment
+// and of all the environments it hosts.
+func (api *RemoteRelationsAPI) environUUIDs() ([]string, error) {
+	env, err := api.st.Environment()
+	if err != nil {
+		return nil, err
+	}
+	hosted, err := api.st.HostedEnvironments()
+	if err != nil {
+		return nil, err
+	}
+	uuids := []string{env.UUID()}
+	for _, env := range hosted {
+		uuids = append(uuids, env.UUID())
+	}
+	return uuids, nil
+}
+
+// RemoteRelations returns the relations with remote services in all
+// the environments hosted by the state server.
+func (api *RemoteRelationsAPI) RemoteRelations() (params.RemoteRelationsResult, error) {
+	var result params.RemoteRelationsResult
+	uuids, err := api.environUUIDs()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	for _, uuid := range uuids {
+		st, err := api.stateForEnviron(uuid)
+		if err != nil {
+			return result, errors.Trace(err)
+		}
+		remoteServices, err := st.AllRemoteServices()
+		if err != nil {
+			return result, errors.Trace(err)
+		}
+		if len(remoteServices) == 0 {
+			continue
+		}
+		remotes := make(map[string]*state.RemoteService)
+		for _, svc := range remoteServices {
+			remotes[svc.Name()] = svc
+		}
+		relations, err := st.AllRelations()
+		if err != nil {
+			return result, errors.Trace(err)
+		}
+		for _, rel := range relations {
+			for _, ep := range rel.Endpoints() {
+				svc, ok := remotes[ep.ServiceName]
+				if !ok {
+					continue
+				}
+				result.Relations = append(result.Relations, params.RemoteRelation{
+					EnvUUID:       uuid,
+					Key:           rel.String(),
+					Life:          params.Life(rel.Life().String()),
+					RemoteService: svc.Name(),
+					SourceEnvUUID: svc.SourceEnvUUID(),
+					Consumer:      svc.OfferURL() != "",
+				})
+			}
+		}
+	}
+	return result, nil
+}
+
+// relation returns the identified relation, along with the State
+// of its environment.
+func (api *RemoteRelationsAPI) relation(envUUID, key string) (*state.State, *state.Relation, error) {
+	st, err := api.stateForEnviron(envUUID)
+	if err != nil {
+		return nil, nil, err
+	}
+	rel, err := st.KeyRelation(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return st, rel, nil
+}
+
+// RelationUnits returns the units that have joined each of the given
+// relations, along with their settings in it.
+func (api *RemoteRelationsAPI) RelationUnits(args params.RemoteRelationIds) (params.RemoteRelationUnitsResults, error) {
+	results := params.RemoteRelationUnitsResults{
+		Results: make([]params.RemoteRelationUnitsResult, len(args.Relations)),
+	}
+	for i, id := range args.Relations {
+		result, err := api.relationUnits(id)
+		if err != nil {
+			result.Error = common.ServerError(err)
+		}
+		results.Results[i] = result
+	}
+	return results, nil
+}
+
+func (api *RemoteRelationsAPI) relationUnits(id params.RemoteRelationId) (params.RemoteRelationUnitsResult, error) {
+	var result params.RemoteRelationUnitsResult
+	st, rel, err := api.relation(id.EnvUUID, id.Key)
+	if err != nil {
+		return result, err
+	}
+	result.Life = params.Life(rel.Life().String())
+	remotes := make(map[string]bool)
+	for _, ep := range rel.Endpoints() {
+		if _, err := st.RemoteService(ep.ServiceName); err == nil {
+			remotes[ep.ServiceName] = true
+		} else if !errors.IsNotFound(err) {
+			return result, err
+		}
+	}
+	unitNames, err := rel.JoinedUnits()
+	if err != nil {
+		return result, err
+	}
+	for _, unitName := range unitNames {
+		isRemote := remotes[names.UnitService(unitName)]
+		var settings map[string]interface{}
+		if isRemote {
+			rru, err := rel.RemoteUnit(unitName)
+			if err != nil {
+				return result, err
+			}
+			node, err := rru.Settings()
+			if err != nil {
+				return result, err
+			}
+			settings = node.Map()
+		} else {
+			unit, err := st.Unit(unitName)
+			if errors.IsNotFound(err) {
+				// The unit is on its way out of the relation.
+				continue
+			} else if err != nil {
+				return result, err
+			}
+			ru, err := rel.Unit(unit)
+			if err != nil {
+				return result, err
+			}
+			if settings, err = ru.ReadSettings(unitName); err != nil {
+				return result, err
+			}
+		}
+		converted, err := convertRelationSettings(settings)
+		if err != nil {
+			return result, err
+		}
+		unit := params.RemoteRelationUnit{Unit: unitName, Settings: converted}
+		if isRemote {
+			result.RemoteUnits = append(result.RemoteUnits, unit)
+		} else {
+			result.LocalUnits = append(result.LocalUnits, unit)
+		}
+	}
+	return result, nil
+}
+
+// MirrorRelations ensures that the environment offering the remote
+// service of each of the given relations has a matching relation,
+// with a remote service standing for the consuming service.
+func (api *RemoteRelationsAPI) MirrorRelations(args params.RemoteRelationIds) (params.ErrorResults, error) {
+	results := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Relations)),
+	}
+	for i, id := range args.Relations {
+		err := api.mirrorRelation(id)
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
+
+func (api *RemoteRelationsAPI) mirrorRelation(id params.RemoteRelationId) (err error) {
+	st, rel, err := api.relation(id.EnvUUID, id.Key)
+	if err != nil {
+		return err
+	}
+	var localEp, remoteEp state.Endpoint
+	var remoteSvc *state.RemoteService
+	for _, ep := range rel.Endpoints() {
+		svc, err := st.RemoteService(ep.ServiceName)
+		if errors.IsNotFound(err) {
+			localEp = ep
+			continue
+		} else if err != nil {
+			return err
+		}
+		remoteSvc, remoteEp = svc, ep
+	}
+	if remoteSvc == nil || remoteSvc.OfferURL() == "" {
+		return fmt.Errorf("relation %q does not consume an offer", rel)
+	}
+	sourceSt, err := api.stateForEnviron(remoteSvc.SourceEnvUUID())
+	if err != nil {
+		return err
+	}
+	if _, err := sourceSt.KeyRelation(rel.String()); err == nil {
+		return nil
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+	// The offering environment sees the consuming service as a
+	// remote service of its own.
+	mirror, err := sourceSt.RemoteService(localEp.ServiceName)
+	if errors.IsNotFound(err) {
+		if mirror, err = addMirrorService(st, sourceSt, localEp.ServiceName, id.EnvUUID); err != nil {
+			return err
+		}
+		defer func() {
+			if err == nil {
+				return
+			}
+			// The mirror service was only added for the relation.
+			if err := mirror.Destroy(); err != nil {
+				logger.Warningf("cannot destroy unused remote service %q: %v", mirror, err)
+			}
+		}()
+	} else if err != nil {
+		return err
+	}
+	if mirror.SourceEnvUUID() != id.EnvUUID {
+		return fmt.Errorf("cannot mirror relation %q: service %q already exists", rel, mirror.Name())
+	}
+	mirrorEp, err := mirror.Endpoint(localEp.Name)
+	if err != nil {
+		return err
+	}
+	offered, err := sourceSt.Service(remoteSvc.Name())
+	if err != nil {
+		return err
+	}
+	offeredEp, err := offered.Endpoint(remoteEp.Name)
+	if err != nil {
+		return err
+	}
+	if _, err := sourceSt.AddRelation(offeredEp, mirrorEp); err != nil {
+		return err
+	}
+	logger.Infof("mirrored relation %q in environment %s", rel, remoteSvc.SourceEnvUUID())
+	return nil
+}
+
+// addMirrorService adds to the environment of sourceSt a remote
+// service standing for the named service in the environment of st,
+// with all its endpoints that may be related to remotely.
+func addMirrorService(st, sourceSt *state.State, serviceName, envUUID string) (*state.RemoteService, error) {
+	svc, err := st.Service(serviceName)
+	if err != nil {
+		return nil, err
+	}
+	eps, err := svc.Endpoints()
+	if err != nil {
+		return nil, err
+	}
+	var relations []charm.Relation
+	for _, ep := range eps {
+		if ep.Role != charm.RolePeer && ep.Scope == charm.ScopeGlobal && !ep.IsImplicit() {
+			relations = append(relations, ep.Relation)
+		}
+	}
+	return sourceSt.AddRemoteService(serviceName, "", envUUID, relations)
+}
+
+// DestroyRelations destroys each of the given relations.
+func (api *RemoteRelationsAPI) DestroyRelations(args params.RemoteRelationIds) (params.ErrorResults, error) {
+	results := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Relations)),
+	}
+	for i, id := range args.Relations {
+		_, rel, err := api.relation(id.EnvUUID, id.Key)
+		if err == nil {
+			err = rel.Destroy()
+		}
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
+
+// EnterScope ensures that each of the given remote units has entered
+// the scope of its relation, with the given settings.
+func (api *RemoteRelationsAPI) EnterScope(args params.RemoteRelationUnitChanges) (params.ErrorResults, error) {
+	return api.changeScope(args, func(rru *state.RemoteRelationUnit, settings params.RelationSettings) error {
+		values := make(map[string]interface{})
+		for k, v := range settings {
+			values[k] = v
+		}
+		return rru.EnterScope(values)
+	})
+}
+
+// LeaveScope ensures that each of the given remote units has left the
+// scope of its relation.
+func (api *RemoteRelationsAPI) LeaveScope(args params.RemoteRelationUnitChanges) (params.ErrorResults, error) {
+	return api.changeScope(args, func(rru *state.RemoteRelationUnit, _ params.RelationSettings) error {
+		return rru.LeaveScope()
+	})
+}
+
+func (api *RemoteRelationsAPI) changeScope(
+	args params.RemoteRelationUnitChanges,
+	change func(*state.RemoteRelationUnit, params.RelationSettings) error,
+) (params.ErrorResults, error) {
+	results := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Changes)),
+	}
+	for i, arg := range args.Changes {
+		err := common.ErrPerm
+		if names.IsUnit(arg.Unit) {
+			var rel *state.Relation
+			var rru *state.RemoteRelationUnit
+			if _, rel, err = api.relation(arg.EnvUUID, arg.Key); err == nil {
+				if rru, err = rel.RemoteUnit(arg.Unit); err == nil {
+					err = change(rru, arg.Settings)
+				}
+			}
+		}
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
+
+func convertRelationSettings(settings map[string]interface{}) (params.RelationSettings, error) {
+	result := make(params.RelationSettings)
+	for k, v := range settings {
+		// All relation settings should be strings.
+		sval, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected relation setting %q: expected string, got %T", k, v)
+		}
+		result[k] = sval
+	}
+	return result, nil
+}
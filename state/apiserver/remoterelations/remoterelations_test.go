@@ -0,0 +1,311 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package remoterelations_test
+
+import (
+	"sync"
+
+	gc "launchpad.net/gocheck"
+
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/common"
+	"github.com/juju/juju/state/apiserver/remoterelations"
+	apiservertesting "github.com/juju/juju/state/apiserver/testing"
+	statetesting "github.com/juju/juju/state/testing"
+)
+
+type remoteRelationsSuite struct {
+	jujutesting.JujuConnSuite
+
+	mu         sync.Mutex
+	states     map[string]*state.State
+	resources  *common.Resources
+	authorizer apiservertesting.FakeAuthorizer
+	api        *remoterelations.RemoteRelationsAPI
+
+	hosted      *state.State
+	serverUUID  string
+	hostedUUID  string
+	offerURL    string
+	relationKey string
+}
+
+var _ = gc.Suite(&remoteRelationsSuite{})
+
+func (s *remoteRelationsSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.states = make(map[string]*state.State)
+	s.AddCleanup(func(*gc.C) {
+		for _, st := range s.states {
+			st.Close()
+		}
+	})
+	s.resources = common.NewResources()
+	s.AddCleanup(func(*gc.C) { s.resources.StopAll() })
+	s.authorizer = apiservertesting.FakeAuthorizer{
+		LoggedIn:       true,
+		EnvironManager: true,
+	}
+	var err error
+	s.api, err = remoterelations.NewRemoteRelationsAPI(s.State, s.stateForEnviron, s.resources, s.authorizer)
+	c.Assert(err, gc.IsNil)
+
+	// The hosted environment offers mysql, which wordpress in the
+	// state server's environment consumes.
+	s.hosted = s.AddHostedEnvironment(c, "sandbox")
+	s.AddCleanup(func(*gc.C) { s.hosted.Close() })
+	s.AddHostedTestingService(c, s.hosted, "mysql", "mysql")
+	offer, err := s.hosted.AddOffer("mysql")
+	c.Assert(err, gc.IsNil)
+	s.hostedUUID = offer.EnvUUID()
+	s.offerURL = offer.URL()
+	env, err := s.State.Environment()
+	c.Assert(err, gc.IsNil)
+	s.serverUUID = env.UUID()
+
+	s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	_, err = s.State.AddRemoteService("mysql", offer.URL(), s.hostedUUID, offer.Endpoints())
+	c.Assert(err, gc.IsNil)
+	eps, err := s.State.InferEndpoints([]string{"wordpress", "mysql"})
+	c.Assert(err, gc.IsNil)
+	rel, err := s.State.AddRelation(eps...)
+	c.Assert(err, gc.IsNil)
+	s.relationKey = rel.String()
+}
+
+func (s *remoteRelationsSuite) stateForEnviron(uuid string) (*state.State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st, ok := s.states[uuid]; ok {
+		return st, nil
+	}
+	st, err := s.State.ForEnviron(uuid)
+	if err != nil {
+		return nil, err
+	}
+	s.states[uuid] = st
+	return st, nil
+}
+
+func (s *remoteRelationsSuite) TestNewRemoteRelationsAPIRequiresEnvironManager(c *gc.C) {
+	anAuthorizer := s.authorizer
+	anAuthorizer.EnvironManager = false
+	api, err := remoterelations.NewRemoteRelationsAPI(s.State, s.stateForEnviron, s.resources, anAuthorizer)
+	c.Assert(err, gc.Equals, common.ErrPerm)
+	c.Assert(api, gc.IsNil)
+}
+
+func (s *remoteRelationsSuite) TestRemoteRelations(c *gc.C) {
+	result, err := s.api.RemoteRelations()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Relations, gc.DeepEquals, []params.RemoteRelation{{
+		EnvUUID:       s.serverUUID,
+		Key:           s.relationKey,
+		Life:          params.Alive,
+		RemoteService: "mysql",
+		SourceEnvUUID: s.hostedUUID,
+		Consumer:      true,
+	}})
+}
+
+func (s *remoteRelationsSuite) TestWatchRemoteRelations(c *gc.C) {
+	result, err := s.api.WatchRemoteRelations()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.NotifyWatchResult{NotifyWatcherId: "1"})
+
+	// Verify the resource was registered and stop when done.
+	c.Assert(s.resources.Count(), gc.Equals, 1)
+	resource := s.resources.Get("1")
+	defer statetesting.AssertStop(c, resource)
+
+	// Check that the Watch has consumed the initial event.
+	wc := statetesting.NewNotifyWatcherC(c, s.State, resource.(state.NotifyWatcher))
+	wc.AssertNoChange()
+
+	// Changes to relations in the state server's environment
+	// are reported.
+	rel, err := s.State.KeyRelation(s.relationKey)
+	c.Assert(err, gc.IsNil)
+	rru, err := rel.RemoteUnit("mysql/0")
+	c.Assert(err, gc.IsNil)
+	err = rru.EnterScope(nil)
+	c.Assert(err, gc.IsNil)
+	s.syncAll()
+	wc.AssertOneChange()
+
+	// So are changes to those in hosted environments.
+	results, err := s.api.MirrorRelations(params.RemoteRelationIds{
+		Relations: []params.RemoteRelationId{{EnvUUID: s.serverUUID, Key: s.relationKey}},
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	s.syncAll()
+	wc.AssertOneChange()
+
+	// New hosted environments are reported, and their relations
+	// watched from then on.
+	other := s.AddHostedEnvironment(c, "other")
+	defer other.Close()
+	s.syncAll()
+	wc.AssertOneChange()
+	offer, err := s.hosted.Offer(s.offerURL)
+	c.Assert(err, gc.IsNil)
+	s.AddHostedTestingService(c, other, "wordpress", "wordpress")
+	_, err = other.AddRemoteService("mysql", offer.URL(), s.hostedUUID, offer.Endpoints())
+	c.Assert(err, gc.IsNil)
+	s.syncAll()
+	wc.AssertNoChange()
+	eps, err := other.InferEndpoints([]string{"wordpress", "mysql"})
+	c.Assert(err, gc.IsNil)
+	_, err = other.AddRelation(eps...)
+	c.Assert(err, gc.IsNil)
+	s.syncAll()
+	wc.AssertOneChange()
+}
+
+// syncAll syncs the watchers of all the environments' States.
+func (s *remoteRelationsSuite) syncAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, st := range s.states {
+		st.StartSync()
+	}
+	s.State.StartSync()
+}
+
+func (s *remoteRelationsSuite) TestMirrorRelations(c *gc.C) {
+	serverId := params.RemoteRelationId{EnvUUID: s.serverUUID, Key: s.relationKey}
+	hostedId := params.RemoteRelationId{EnvUUID: s.hostedUUID, Key: s.relationKey}
+	results, err := s.api.MirrorRelations(params.RemoteRelationIds{
+		Relations: []params.RemoteRelationId{serverId, serverId, hostedId},
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(results.Results, gc.HasLen, 3)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	c.Assert(results.Results[1].Error, gc.IsNil)
+	// The mirrored relation consumes no offer of its own.
+	c.Assert(results.Results[2].Error, gc.ErrorMatches, `relation "wordpress:db mysql:server" does not consume an offer`)
+
+	mirror, err := s.hosted.RemoteService("wordpress")
+	c.Assert(err, gc.IsNil)
+	c.Assert(mirror.OfferURL(), gc.Equals, "")
+	c.Assert(mirror.SourceEnvUUID(), gc.Equals, s.serverUUID)
+	_, err = s.hosted.KeyRelation(s.relationKey)
+	c.Assert(err, gc.IsNil)
+
+	// The mirrored relation shows up from the other side too.
+	result, err := s.api.RemoteRelations()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Relations, gc.HasLen, 2)
+	c.Assert(result.Relations[1], gc.DeepEquals, params.RemoteRelation{
+		EnvUUID:       s.hostedUUID,
+		Key:           s.relationKey,
+		Life:          params.Alive,
+		RemoteService: "wordpress",
+		SourceEnvUUID: s.serverUUID,
+	})
+}
+
+func (s *remoteRelationsSuite) TestMirrorRelationsNotConsumer(c *gc.C) {
+	s.AddTestingService(c, "mysql2", s.AddTestingCharm(c, "mysql"))
+	s.AddTestingService(c, "wordpress2", s.AddTestingCharm(c, "wordpress"))
+	eps, err := s.State.InferEndpoints([]string{"wordpress2", "mysql2"})
+	c.Assert(err, gc.IsNil)
+	rel, err := s.State.AddRelation(eps...)
+	c.Assert(err, gc.IsNil)
+	results, err := s.api.MirrorRelations(params.RemoteRelationIds{
+		Relations: []params.RemoteRelationId{
+			{EnvUUID: s.serverUUID, Key: rel.String()},
+			{EnvUUID: s.serverUUID, Key: "foo:bar baz:qux"},
+		},
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(results.Results, gc.HasLen, 2)
+	c.Assert(results.Results[0].Error, gc.ErrorMatches, `relation "wordpress2:db mysql2:server" does not consume an offer`)
+	c.Assert(results.Results[1].Error, gc.DeepEquals, &params.Error{
+		Message: `relation "foo:bar baz:qux" not found`,
+		Code:    params.CodeNotFound,
+	})
+}
+
+func (s *remoteRelationsSuite) TestRelationUnitsAndScope(c *gc.C) {
+	serverId := params.RemoteRelationId{EnvUUID: s.serverUUID, Key: s.relationKey}
+	wordpress, err := s.State.Service("wordpress")
+	c.Assert(err, gc.IsNil)
+	unit, err := wordpress.AddUnit()
+	c.Assert(err, gc.IsNil)
+	rel, err := s.State.KeyRelation(s.relationKey)
+	c.Assert(err, gc.IsNil)
+	ru, err := rel.Unit(unit)
+	c.Assert(err, gc.IsNil)
+	err = ru.EnterScope(map[string]interface{}{"foo": "bar"})
+	c.Assert(err, gc.IsNil)
+
+	results, err := s.api.EnterScope(params.RemoteRelationUnitChanges{
+		Changes: []params.RemoteRelationUnitChange{{
+			EnvUUID:  s.serverUUID,
+			Key:      s.relationKey,
+			Unit:     "mysql/0",
+			Settings: params.RelationSettings{"host": "10.0.0.1"},
+		}, {
+			EnvUUID: s.serverUUID,
+			Key:     s.relationKey,
+			Unit:    "wordpress/0",
+		}, {
+			EnvUUID: s.serverUUID,
+			Key:     s.relationKey,
+			Unit:    "invalid",
+		}},
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(results.Results, gc.HasLen, 3)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	c.Assert(results.Results[1].Error, gc.ErrorMatches, `service "wordpress" is not a remote service`)
+	c.Assert(results.Results[2].Error, gc.DeepEquals, apiservertesting.ErrUnauthorized)
+
+	unitsResults, err := s.api.RelationUnits(params.RemoteRelationIds{
+		Relations: []params.RemoteRelationId{serverId},
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(unitsResults, gc.DeepEquals, params.RemoteRelationUnitsResults{
+		Results: []params.RemoteRelationUnitsResult{{
+			Life: params.Alive,
+			LocalUnits: []params.RemoteRelationUnit{{
+				Unit:     "wordpress/0",
+				Settings: params.RelationSettings{"foo": "bar"},
+			}},
+			RemoteUnits: []params.RemoteRelationUnit{{
+				Unit:     "mysql/0",
+				Settings: params.RelationSettings{"host": "10.0.0.1"},
+			}},
+		}},
+	})
+
+	results, err = s.api.LeaveScope(params.RemoteRelationUnitChanges{
+		Changes: []params.RemoteRelationUnitChange{{
+			EnvUUID: s.serverUUID,
+			Key:     s.relationKey,
+			Unit:    "mysql/0",
+		}},
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	unitsResults, err = s.api.RelationUnits(params.RemoteRelationIds{
+		Relations: []params.RemoteRelationId{serverId},
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(unitsResults.Results[0].RemoteUnits, gc.HasLen, 0)
+}
+
+func (s *remoteRelationsSuite) TestDestroyRelations(c *gc.C) {
+	results, err := s.api.DestroyRelations(params.RemoteRelationIds{
+		Relations: []params.RemoteRelationId{{EnvUUID: s.serverUUID, Key: s.relationKey}},
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	_, err = s.State.KeyRelation(s.relationKey)
+	c.Assert(err, gc.ErrorMatches, `relation "wordpress:db mysql:server" not found`)
+}
@@ -0,0 +1,173 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package remoterelations
+
+import (
+	"github.com/juju/errors"
+	"launchpad.net/tomb"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/watcher"
+)
+
+// remoteRelationsWatcher notifies of changes to the relations with
+// remote services in the state server's environment and in all the
+// environments it hosts, as reported by State.WatchRemoteRelations
+// in each of them.
+type remoteRelationsWatcher struct {
+	tomb            tomb.Tomb
+	st              *state.State
+	stateForEnviron StateForEnvironFunc
+	watchers        map[string]state.NotifyWatcher
+	changes         chan struct{}
+	out             chan struct{}
+}
+
+var _ state.NotifyWatcher = (*remoteRelationsWatcher)(nil)
+
+func newRemoteRelationsWatcher(st *state.State, stateForEnviron StateForEnvironFunc) *remoteRelationsWatcher {
+	w := &remoteRelationsWatcher{
+		st:              st,
+		stateForEnviron: stateForEnviron,
+		watchers:        make(map[string]state.NotifyWatcher),
+		changes:         make(chan struct{}),
+		out:             make(chan struct{}),
+	}
+	go func() {
+		defer w.tomb.Done()
+		defer close(w.out)
+		defer w.stopWatchers()
+		w.tomb.Kill(w.loop())
+	}()
+	return w
+}
+
+// Changes returns the event channel for w.
+func (w *remoteRelationsWatcher) Changes() <-chan struct{} {
+	return w.out
+}
+
+// Kill is defined on the state.Watcher interface.
+func (w *remoteRelationsWatcher) Kill() {
+	w.tomb.Kill(nil)
+}
+
+// Wait is defined on the state.Watcher interface.
+func (w *remoteRelationsWatcher) Wait() error {
+	return w.tomb.Wait()
+}
+
+// Stop is defined on the state.Watcher interface.
+func (w *remoteRelationsWatcher) Stop() error {
+	w.tomb.Kill(nil)
+	return w.tomb.Wait()
+}
+
+// Err is defined on the state.Watcher interface.
+func (w *remoteRelationsWatcher) Err() error {
+	return w.tomb.Err()
+}
+
+func (w *remoteRelationsWatcher) loop() error {
+	envw := w.st.WatchHostedEnvironments()
+	defer watcher.Stop(envw, &w.tomb)
+	env, err := w.st.Environment()
+	if err != nil {
+		return err
+	}
+	if err := w.watchEnviron(env.UUID()); err != nil {
+		return err
+	}
+	// The initial event is only sent once the relations of all
+	// the existing environments are being watched.
+	var out chan struct{}
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case uuids, ok := <-envw.Changes():
+			if !ok {
+				return watcher.MustErr(envw)
+			}
+			for _, uuid := range uuids {
+				if err := w.updateEnviron(uuid); err != nil {
+					return err
+				}
+			}
+			out = w.out
+		case <-w.changes:
+			out = w.out
+		case out <- struct{}{}:
+			out = nil
+		}
+	}
+}
+
+// updateEnviron starts watching the relations of the hosted
+// environment with the given UUID, or stops watching them once
+// the environment has been removed.
+func (w *remoteRelationsWatcher) updateEnviron(uuid string) error {
+	if _, err := w.st.HostedEnvironment(uuid); errors.IsNotFound(err) {
+		if ew, ok := w.watchers[uuid]; ok {
+			delete(w.watchers, uuid)
+			return ew.Stop()
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return w.watchEnviron(uuid)
+}
+
+// watchEnviron starts watching the relations of the environment
+// with the given UUID, unless they are already being watched.
+func (w *remoteRelationsWatcher) watchEnviron(uuid string) error {
+	if _, ok := w.watchers[uuid]; ok {
+		return nil
+	}
+	st, err := w.stateForEnviron(uuid)
+	if err != nil {
+		return err
+	}
+	ew := st.WatchRemoteRelations()
+	// Consume the initial event; the environment's relations are
+	// covered by the next event sent by w.
+	if _, ok := <-ew.Changes(); !ok {
+		return watcher.MustErr(ew)
+	}
+	w.watchers[uuid] = ew
+	go w.forward(ew)
+	return nil
+}
+
+// forward passes on the events of the environment watcher ew
+// until it is stopped, killing w if ew fails.
+func (w *remoteRelationsWatcher) forward(ew state.NotifyWatcher) {
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return
+		case _, ok := <-ew.Changes():
+			if !ok {
+				if err := ew.Err(); err != nil {
+					w.tomb.Kill(err)
+				}
+				return
+			}
+			select {
+			case w.changes <- struct{}{}:
+			case <-w.tomb.Dying():
+				return
+			}
+		}
+	}
+}
+
+func (w *remoteRelationsWatcher) stopWatchers() {
+	for _, ew := range w.watchers {
+		if err := ew.Stop(); err != nil {
+			logger.Errorf("cannot stop remote relations watcher: %v", err)
+		}
+	}
+}
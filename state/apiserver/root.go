@@ -8,27 +8,40 @@ import (
 	"time"
 
 	"github.com/juju/names"
+	"github.com/juju/utils/set"
 	"launchpad.net/tomb"
 
 	"github.com/juju/juju/rpc"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/apiserver/agent"
+	"github.com/juju/juju/state/apiserver/auditlog"
+	"github.com/juju/juju/state/apiserver/backups"
 	"github.com/juju/juju/state/apiserver/charmrevisionupdater"
 	"github.com/juju/juju/state/apiserver/client"
 	"github.com/juju/juju/state/apiserver/common"
+	"github.com/juju/juju/state/apiserver/debugrecordings"
 	"github.com/juju/juju/state/apiserver/deployer"
 	"github.com/juju/juju/state/apiserver/environment"
+	"github.com/juju/juju/state/apiserver/environmentmanager"
 	"github.com/juju/juju/state/apiserver/firewaller"
 	"github.com/juju/juju/state/apiserver/keymanager"
 	"github.com/juju/juju/state/apiserver/keyupdater"
+	"github.com/juju/juju/state/apiserver/leadership"
 	loggerapi "github.com/juju/juju/state/apiserver/logger"
 	"github.com/juju/juju/state/apiserver/machine"
+	"github.com/juju/juju/state/apiserver/metricsmanager"
 	"github.com/juju/juju/state/apiserver/networker"
 	"github.com/juju/juju/state/apiserver/provisioner"
+	"github.com/juju/juju/state/apiserver/remoterelations"
 	"github.com/juju/juju/state/apiserver/rsyslog"
+	"github.com/juju/juju/state/apiserver/spaces"
+	"github.com/juju/juju/state/apiserver/storage"
+	"github.com/juju/juju/state/apiserver/storageprovisioner"
+	"github.com/juju/juju/state/apiserver/subnets"
 	"github.com/juju/juju/state/apiserver/uniter"
 	"github.com/juju/juju/state/apiserver/upgrader"
 	"github.com/juju/juju/state/apiserver/usermanager"
+	statebackups "github.com/juju/juju/state/backups"
 	"github.com/juju/juju/state/multiwatcher"
 )
 
@@ -58,24 +71,31 @@ var (
 type srvRoot struct {
 	clientAPI
 	srv       *Server
+	state     *state.State
 	rpcConn   *rpc.Conn
 	resources *common.Resources
 
 	entity taggedAuthenticator
+
+	// access holds the access level to the environment of the
+	// logged in user. It is empty for agents.
+	access state.Access
 }
 
 // newSrvRoot creates the client's connection representation
 // and starts a ping timeout for the monitoring of this
 // connection.
-func newSrvRoot(root *initialRoot, entity taggedAuthenticator) *srvRoot {
+func newSrvRoot(root *initialRoot, entity taggedAuthenticator, access state.Access) *srvRoot {
 	r := &srvRoot{
 		srv:       root.srv,
+		state:     root.state,
 		rpcConn:   root.rpcConn,
 		resources: common.NewResources(),
 		entity:    entity,
+		access:    access,
 	}
 	r.resources.RegisterNamed("dataDir", common.StringResource(r.srv.dataDir))
-	r.clientAPI.API = client.NewAPI(r.srv.state, r.resources, r)
+	r.clientAPI.API = client.NewAPI(r.state, r.resources, r)
 	return r
 }
 
@@ -105,6 +125,93 @@ func (r *srvRoot) requireClient() error {
 	return nil
 }
 
+// readOnlyClientMethods holds the Client methods that do not
+// change the environment. Users with read access may call them,
+// and they are not audited.
+var readOnlyClientMethods = set.NewStrings(
+	"APIHostPorts",
+	"ActionResults",
+	"AgentVersion",
+	"CharmInfo",
+	"ConnectionInfo",
+	"EnvironmentGet",
+	"EnvironmentInfo",
+	"FindTools",
+	"FullStatus",
+	"GetAnnotations",
+	"GetEnvironmentConstraints",
+	"GetServiceConstraints",
+	"ListActions",
+	"PrivateAddress",
+	"PublicAddress",
+	"ResolveCharms",
+	"ServiceCharmRelations",
+	"ServiceGet",
+	"ServiceGetCharmURL",
+	"Status",
+	"WatchAll",
+)
+
+// readOnlyFacadeMethods holds the methods, other than those of
+// the Client facade, that users with read access may call.
+var readOnlyFacadeMethods = map[string]set.Strings{
+	"AllWatcher":         set.NewStrings("Next", "Stop"),
+	"EnvironmentManager": set.NewStrings("ListEnvironments"),
+	"KeyManager":         set.NewStrings("ListKeys"),
+	"Pinger":             set.NewStrings("Ping"),
+	"Spaces":             set.NewStrings("ListSpaces"),
+	"Subnets":            set.NewStrings("ListSubnets"),
+	"UserManager":        set.NewStrings("UserInfo"),
+}
+
+// adminFacades holds the facades that only users
+// with admin access may use.
+var adminFacades = set.NewStrings(
+	"AuditLog",
+	"Backups",
+	"DebugRecordings",
+	"MetricsManager",
+	"UserManager",
+)
+
+// adminClientMethods holds the Client methods that only
+// users with admin access may call.
+var adminClientMethods = set.NewStrings(
+	"DestroyEnvironment",
+	"SetEnvironAgentVersion",
+	"RotateCertificates",
+)
+
+// requiredAccess returns the access level a user needs
+// to make the given request.
+func requiredAccess(req rpc.Request) state.Access {
+	switch {
+	case req.Type == "Client" && readOnlyClientMethods.Contains(req.Action):
+		return state.ReadAccess
+	case readOnlyFacadeMethods[req.Type].Contains(req.Action):
+		return state.ReadAccess
+	case req.Type == "Client" && adminClientMethods.Contains(req.Action):
+		return state.AdminAccess
+	case adminFacades.Contains(req.Type):
+		return state.AdminAccess
+	}
+	return state.WriteAccess
+}
+
+// AuthorizeRequest implements rpc.RequestAuthorizer. It refuses
+// requests that need more access to the environment than the
+// logged in user has. Agents are not restricted here; the facades
+// themselves check what agents may do.
+func (r *srvRoot) AuthorizeRequest(req rpc.Request) error {
+	if _, ok := r.entity.(*state.User); !ok {
+		return nil
+	}
+	if !r.access.Allows(requiredAccess(req)) {
+		return common.ErrPerm
+	}
+	return nil
+}
+
 // KeyManager returns an object that provides access to the KeyManager API
 // facade. The id argument is reserved for future use and currently
 // needs to be empty.
@@ -112,7 +219,7 @@ func (r *srvRoot) KeyManager(id string) (*keymanager.KeyManagerAPI, error) {
 	if id != "" {
 		return nil, common.ErrBadId
 	}
-	return keymanager.NewKeyManagerAPI(r.srv.state, r.resources, r)
+	return keymanager.NewKeyManagerAPI(r.state, r.resources, r)
 }
 
 // UserManager returns an object that provides access to the UserManager API
@@ -122,7 +229,94 @@ func (r *srvRoot) UserManager(id string) (*usermanager.UserManagerAPI, error) {
 	if id != "" {
 		return nil, common.ErrBadId
 	}
-	return usermanager.NewUserManagerAPI(r.srv.state, r)
+	// Users are shared by all the environments hosted by the state
+	// server, but access is granted to each environment separately,
+	// so users are managed through the environment logged into.
+	return usermanager.NewUserManagerAPI(r.state, r)
+}
+
+// Backups returns an object that provides access to the Backups API
+// facade. The id argument is reserved for future use and currently
+// needs to be empty.
+func (r *srvRoot) Backups(id string) (*backups.BackupsAPI, error) {
+	if id != "" {
+		return nil, common.ErrBadId
+	}
+	paths := statebackups.Paths{
+		DataDir: r.srv.dataDir,
+		LogDir:  r.srv.logDir,
+	}
+	return backups.NewBackupsAPI(r.srv.state, r, paths)
+}
+
+// AuditLog returns an object that provides access to the AuditLog API
+// facade. The id argument is reserved for future use and currently
+// needs to be empty.
+func (r *srvRoot) AuditLog(id string) (*auditlog.AuditLogAPI, error) {
+	if id != "" {
+		return nil, common.ErrBadId
+	}
+	return auditlog.NewAuditLogAPI(r.srv.state, r)
+}
+
+// EnvironmentManager returns an object that provides access to the
+// EnvironmentManager API facade. The id argument is reserved for
+// future use and currently needs to be empty.
+func (r *srvRoot) EnvironmentManager(id string) (*environmentmanager.EnvironmentManagerAPI, error) {
+	if id != "" {
+		return nil, common.ErrBadId
+	}
+	return environmentmanager.NewEnvironmentManagerAPI(r.srv.state, r)
+}
+
+// RemoteRelations returns an object that provides access to the
+// RemoteRelations API facade. The id argument is reserved for future
+// use and currently needs to be empty.
+func (r *srvRoot) RemoteRelations(id string) (*remoterelations.RemoteRelationsAPI, error) {
+	if id != "" {
+		return nil, common.ErrBadId
+	}
+	return remoterelations.NewRemoteRelationsAPI(r.srv.state, r.srv.stateForEnviron, r.resources, r)
+}
+
+// MetricsManager returns an object that provides access to the
+// MetricsManager API facade. The id argument is reserved for future
+// use and currently needs to be empty.
+func (r *srvRoot) MetricsManager(id string) (*metricsmanager.MetricsManagerAPI, error) {
+	if id != "" {
+		return nil, common.ErrBadId
+	}
+	return metricsmanager.NewMetricsManagerAPI(r.state, r)
+}
+
+// Spaces returns an object that provides access to the Spaces API
+// facade. The id argument is reserved for future use and currently
+// needs to be empty.
+func (r *srvRoot) Spaces(id string) (*spaces.SpacesAPI, error) {
+	if id != "" {
+		return nil, common.ErrBadId
+	}
+	return spaces.NewSpacesAPI(r.state, r)
+}
+
+// Subnets returns an object that provides access to the Subnets API
+// facade. The id argument is reserved for future use and currently
+// needs to be empty.
+func (r *srvRoot) Subnets(id string) (*subnets.SubnetsAPI, error) {
+	if id != "" {
+		return nil, common.ErrBadId
+	}
+	return subnets.NewSubnetsAPI(r.state, r)
+}
+
+// DebugRecordings returns an object that provides access to the
+// DebugRecordings API facade. The id argument is reserved for future
+// use and currently needs to be empty.
+func (r *srvRoot) DebugRecordings(id string) (*debugrecordings.DebugRecordingsAPI, error) {
+	if id != "" {
+		return nil, common.ErrBadId
+	}
+	return debugrecordings.NewDebugRecordingsAPI(r.state, r)
 }
 
 // Machiner returns an object that provides access to the Machiner API
@@ -133,7 +327,7 @@ func (r *srvRoot) Machiner(id string) (*machine.MachinerAPI, error) {
 		// Safeguard id for possible future use.
 		return nil, common.ErrBadId
 	}
-	return machine.NewMachinerAPI(r.srv.state, r.resources, r)
+	return machine.NewMachinerAPI(r.state, r.resources, r)
 }
 
 // Networker returns an object that provides access to the
@@ -144,7 +338,7 @@ func (r *srvRoot) Networker(id string) (*networker.NetworkerAPI, error) {
 		// Safeguard id for possible future use.
 		return nil, common.ErrBadId
 	}
-	return networker.NewNetworkerAPI(r.srv.state, r.resources, r)
+	return networker.NewNetworkerAPI(r.state, r.resources, r)
 }
 
 // Provisioner returns an object that provides access to the
@@ -155,7 +349,7 @@ func (r *srvRoot) Provisioner(id string) (*provisioner.ProvisionerAPI, error) {
 		// Safeguard id for possible future use.
 		return nil, common.ErrBadId
 	}
-	return provisioner.NewProvisionerAPI(r.srv.state, r.resources, r)
+	return provisioner.NewProvisionerAPI(r.state, r.resources, r)
 }
 
 // Uniter returns an object that provides access to the Uniter API
@@ -166,7 +360,7 @@ func (r *srvRoot) Uniter(id string) (*uniter.UniterAPI, error) {
 		// Safeguard id for possible future use.
 		return nil, common.ErrBadId
 	}
-	return uniter.NewUniterAPI(r.srv.state, r.resources, r)
+	return uniter.NewUniterAPI(r.state, r.resources, r)
 }
 
 // Firewaller returns an object that provides access to the Firewaller
@@ -177,7 +371,7 @@ func (r *srvRoot) Firewaller(id string) (*firewaller.FirewallerAPI, error) {
 		// Safeguard id for possible future use.
 		return nil, common.ErrBadId
 	}
-	return firewaller.NewFirewallerAPI(r.srv.state, r.resources, r)
+	return firewaller.NewFirewallerAPI(r.state, r.resources, r)
 }
 
 // Agent returns an object that provides access to the
@@ -187,7 +381,7 @@ func (r *srvRoot) Agent(id string) (*agent.API, error) {
 	if id != "" {
 		return nil, common.ErrBadId
 	}
-	return agent.NewAPI(r.srv.state, r)
+	return agent.NewAPI(r.state, r)
 }
 
 // Deployer returns an object that provides access to the Deployer API facade.
@@ -197,7 +391,7 @@ func (r *srvRoot) Deployer(id string) (*deployer.DeployerAPI, error) {
 		// TODO(dimitern): There is no direct test for this
 		return nil, common.ErrBadId
 	}
-	return deployer.NewDeployerAPI(r.srv.state, r.resources, r)
+	return deployer.NewDeployerAPI(r.state, r.resources, r)
 }
 
 // Environment returns an object that provides access to the Environment API
@@ -208,7 +402,7 @@ func (r *srvRoot) Environment(id string) (*environment.EnvironmentAPI, error) {
 		// Safeguard id for possible future use.
 		return nil, common.ErrBadId
 	}
-	return environment.NewEnvironmentAPI(r.srv.state, r.resources, r)
+	return environment.NewEnvironmentAPI(r.state, r.resources, r)
 }
 
 // Rsyslog returns an object that provides access to the Rsyslog API
@@ -219,7 +413,7 @@ func (r *srvRoot) Rsyslog(id string) (*rsyslog.RsyslogAPI, error) {
 		// Safeguard id for possible future use.
 		return nil, common.ErrBadId
 	}
-	return rsyslog.NewRsyslogAPI(r.srv.state, r.resources, r)
+	return rsyslog.NewRsyslogAPI(r.state, r.resources, r)
 }
 
 // Logger returns an object that provides access to the Logger API facade.
@@ -229,7 +423,35 @@ func (r *srvRoot) Logger(id string) (*loggerapi.LoggerAPI, error) {
 		// TODO: There is no direct test for this
 		return nil, common.ErrBadId
 	}
-	return loggerapi.NewLoggerAPI(r.srv.state, r.resources, r)
+	return loggerapi.NewLoggerAPI(r.state, r.resources, r)
+}
+
+// Leadership returns an object that provides access to the Leadership
+// API facade. The id argument is reserved for future use and must be empty.
+func (r *srvRoot) Leadership(id string) (*leadership.LeadershipAPI, error) {
+	if id != "" {
+		return nil, common.ErrBadId
+	}
+	return leadership.NewLeadershipAPI(r.state, r.resources, r)
+}
+
+// Storage returns an object that provides access to the Storage
+// API facade. The id argument is reserved for future use and must be empty.
+func (r *srvRoot) Storage(id string) (*storage.StorageAPI, error) {
+	if id != "" {
+		return nil, common.ErrBadId
+	}
+	return storage.NewStorageAPI(r.state, r.resources, r)
+}
+
+// StorageProvisioner returns an object that provides access to the
+// StorageProvisioner API facade. The id argument is reserved for
+// future use and must be empty.
+func (r *srvRoot) StorageProvisioner(id string) (*storageprovisioner.StorageProvisionerAPI, error) {
+	if id != "" {
+		return nil, common.ErrBadId
+	}
+	return storageprovisioner.NewStorageProvisionerAPI(r.state, r.resources, r)
 }
 
 // Upgrader returns an object that provides access to the Upgrader API facade.
@@ -249,9 +471,9 @@ func (r *srvRoot) Upgrader(id string) (upgrader.Upgrader, error) {
 	}
 	switch tag.(type) {
 	case names.MachineTag:
-		return upgrader.NewUpgraderAPI(r.srv.state, r.resources, r)
+		return upgrader.NewUpgraderAPI(r.state, r.resources, r)
 	case names.UnitTag:
-		return upgrader.NewUnitUpgraderAPI(r.srv.state, r.resources, r)
+		return upgrader.NewUnitUpgraderAPI(r.state, r.resources, r)
 	}
 	// Not a machine or unit.
 	return nil, common.ErrPerm
@@ -264,7 +486,7 @@ func (r *srvRoot) KeyUpdater(id string) (*keyupdater.KeyUpdaterAPI, error) {
 		// TODO: There is no direct test for this
 		return nil, common.ErrBadId
 	}
-	return keyupdater.NewKeyUpdaterAPI(r.srv.state, r.resources, r)
+	return keyupdater.NewKeyUpdaterAPI(r.state, r.resources, r)
 }
 
 // CharmRevisionUpdater returns an object that provides access to the CharmRevisionUpdater API facade.
@@ -274,7 +496,7 @@ func (r *srvRoot) CharmRevisionUpdater(id string) (*charmrevisionupdater.CharmRe
 		// TODO: There is no direct test for this
 		return nil, common.ErrBadId
 	}
-	return charmrevisionupdater.NewCharmRevisionUpdaterAPI(r.srv.state, r.resources, r)
+	return charmrevisionupdater.NewCharmRevisionUpdaterAPI(r.state, r.resources, r)
 }
 
 // NotifyWatcher returns an object that provides
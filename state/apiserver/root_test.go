@@ -26,6 +26,7 @@ var allowedDiscardedMethods = []string{
 	"AuthMachineAgent",
 	"AuthOwner",
 	"AuthUnitAgent",
+	"AuthorizeRequest",
 	"GetAuthEntity",
 	"GetAuthTag",
 }
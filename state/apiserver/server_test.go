@@ -90,6 +90,50 @@ func (s *serverSuite) TestStop(c *gc.C) {
 	c.Assert(err, gc.IsNil)
 }
 
+func (s *serverSuite) TestServerCertificateChanges(c *gc.C) {
+	srv, err := apiserver.NewServer(s.State, apiserver.ServerConfig{
+		Addr: "localhost:0",
+		Cert: []byte(coretesting.ServerCert),
+		Key:  []byte(coretesting.ServerKey),
+	})
+	c.Assert(err, gc.IsNil)
+	defer srv.Stop()
+
+	peerCert := func() *x509.Certificate {
+		conn, err := tls.Dial("tcp", srv.Addr(), &tls.Config{
+			InsecureSkipVerify: true,
+		})
+		c.Assert(err, gc.IsNil)
+		defer conn.Close()
+		c.Assert(conn.Handshake(), gc.IsNil)
+		return conn.ConnectionState().PeerCertificates[0]
+	}
+	origCert, err := cert.ParseCert(coretesting.ServerCert)
+	c.Assert(err, gc.IsNil)
+	c.Assert(peerCert().Equal(origCert), jc.IsTrue)
+
+	newCertPEM, newKeyPEM, err := cert.NewServer(
+		coretesting.CACert, coretesting.CAKey, time.Now().AddDate(1, 0, 0), nil,
+	)
+	c.Assert(err, gc.IsNil)
+	newCert, err := cert.ParseCert(newCertPEM)
+	c.Assert(err, gc.IsNil)
+	err = s.State.SetStateServingInfo(params.StateServingInfo{
+		APIPort:    1234,
+		StatePort:  4321,
+		Cert:       newCertPEM,
+		PrivateKey: newKeyPEM,
+	})
+	c.Assert(err, gc.IsNil)
+
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		if peerCert().Equal(newCert) {
+			return
+		}
+	}
+	c.Fatalf("server certificate was not changed")
+}
+
 func (s *serverSuite) TestOpenAsMachineErrors(c *gc.C) {
 	assertNotProvisioned := func(err error) {
 		c.Assert(err, gc.NotNil)
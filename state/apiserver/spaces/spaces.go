@@ -0,0 +1,60 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package spaces
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/common"
+)
+
+// SpacesAPI implements the API end point used by clients to create
+// and list the spaces of an environment.
+type SpacesAPI struct {
+	st         *state.State
+	authorizer common.Authorizer
+}
+
+// NewSpacesAPI returns a new SpacesAPI.
+func NewSpacesAPI(st *state.State, authorizer common.Authorizer) (*SpacesAPI, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	return &SpacesAPI{
+		st:         st,
+		authorizer: authorizer,
+	}, nil
+}
+
+// CreateSpace creates a new space made of the given subnets, which
+// must already be known and not be part of any other space.
+func (api *SpacesAPI) CreateSpace(args params.CreateSpaceArgs) error {
+	_, err := api.st.AddSpace(args.Name, args.SubnetCIDRs)
+	return err
+}
+
+// ListSpaces returns all the spaces in the environment, along with
+// their subnets, sorted by name.
+func (api *SpacesAPI) ListSpaces() (params.ListSpacesResults, error) {
+	spaces, err := api.st.AllSpaces()
+	if err != nil {
+		return params.ListSpacesResults{}, errors.Trace(err)
+	}
+	result := params.ListSpacesResults{
+		Spaces: make([]params.Space, len(spaces)),
+	}
+	for i, space := range spaces {
+		subnets, err := space.Subnets()
+		if err != nil {
+			return params.ListSpacesResults{}, errors.Trace(err)
+		}
+		result.Spaces[i] = params.Space{
+			Name:    space.Name(),
+			Subnets: common.SubnetsParams(subnets),
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package spaces_test
+
+import (
+	gc "launchpad.net/gocheck"
+
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/spaces"
+	apiservertesting "github.com/juju/juju/state/apiserver/testing"
+)
+
+type spacesSuite struct {
+	jujutesting.JujuConnSuite
+
+	api *spaces.SpacesAPI
+}
+
+var _ = gc.Suite(&spacesSuite{})
+
+func (s *spacesSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	var err error
+	s.api, err = spaces.NewSpacesAPI(s.State, apiservertesting.FakeAuthorizer{
+		Tag:      "user-admin",
+		LoggedIn: true,
+		Client:   true,
+	})
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *spacesSuite) TestNewSpacesAPIRefusesNonClient(c *gc.C) {
+	api, err := spaces.NewSpacesAPI(s.State, apiservertesting.FakeAuthorizer{
+		Tag:       "unit-mysql-0",
+		LoggedIn:  true,
+		UnitAgent: true,
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	c.Assert(api, gc.IsNil)
+}
+
+func (s *spacesSuite) TestCreateSpace(c *gc.C) {
+	_, err := s.State.AddSubnet(state.SubnetInfo{CIDR: "10.0.0.0/24"})
+	c.Assert(err, gc.IsNil)
+
+	err = s.api.CreateSpace(params.CreateSpaceArgs{
+		Name:        "db",
+		SubnetCIDRs: []string{"10.0.0.0/24"},
+	})
+	c.Assert(err, gc.IsNil)
+	subnet, err := s.State.Subnet("10.0.0.0/24")
+	c.Assert(err, gc.IsNil)
+	c.Assert(subnet.SpaceName(), gc.Equals, "db")
+
+	err = s.api.CreateSpace(params.CreateSpaceArgs{Name: "db"})
+	c.Assert(err, gc.ErrorMatches, `cannot add space "db": space "db" already exists`)
+	err = s.api.CreateSpace(params.CreateSpaceArgs{
+		Name:        "dmz",
+		SubnetCIDRs: []string{"10.0.1.0/24"},
+	})
+	c.Assert(err, gc.ErrorMatches, `cannot add space "dmz": subnet "10.0.1.0/24" not found`)
+}
+
+func (s *spacesSuite) TestListSpaces(c *gc.C) {
+	result, err := s.api.ListSpaces()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Spaces, gc.HasLen, 0)
+
+	_, err = s.State.AddSubnet(state.SubnetInfo{
+		CIDR:              "10.0.0.0/24",
+		ProviderId:        "subnet-0",
+		AvailabilityZones: []string{"zone1"},
+	})
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.AddSpace("dmz", nil)
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.AddSpace("db", []string{"10.0.0.0/24"})
+	c.Assert(err, gc.IsNil)
+
+	result, err = s.api.ListSpaces()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.ListSpacesResults{
+		Spaces: []params.Space{{
+			Name: "db",
+			Subnets: []params.Subnet{{
+				CIDR:              "10.0.0.0/24",
+				ProviderId:        "subnet-0",
+				AvailabilityZones: []string{"zone1"},
+				SpaceName:         "db",
+			}},
+		}, {
+			Name:    "dmz",
+			Subnets: []params.Subnet{},
+		}},
+	})
+}
@@ -0,0 +1,137 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/common"
+	"github.com/juju/juju/state/watcher"
+	jujustorage "github.com/juju/juju/storage"
+)
+
+// Storage defines the methods on the storage API end point.
+type Storage interface {
+	StorageInstances(args params.Entities) params.StorageInstancesResults
+	WatchStorage(args params.Entities) params.NotifyWatchResults
+}
+
+// StorageAPI implements the Storage interface and is the concrete
+// implementation of the api end point. Units use it to find the
+// storage instances they own, and where those are attached.
+type StorageAPI struct {
+	state      *state.State
+	resources  *common.Resources
+	authorizer common.Authorizer
+}
+
+var _ Storage = (*StorageAPI)(nil)
+
+// NewStorageAPI creates a new server-side storage API end point.
+func NewStorageAPI(
+	st *state.State,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*StorageAPI, error) {
+	if !authorizer.AuthUnitAgent() {
+		return nil, common.ErrPerm
+	}
+	return &StorageAPI{state: st, resources: resources, authorizer: authorizer}, nil
+}
+
+// unit returns the unit with the given tag,
+// if it is the authenticated entity.
+func (api *StorageAPI) unit(unitTag string) (*state.Unit, error) {
+	if !api.authorizer.AuthOwner(unitTag) {
+		return nil, common.ErrPerm
+	}
+	tag, err := names.ParseTag(unitTag, names.UnitTagKind)
+	if err != nil {
+		return nil, common.ErrPerm
+	}
+	return api.state.Unit(tag.Id())
+}
+
+// storageInstances returns the storage instances owned by the unit.
+// The location of a block device is only set once its volume has been
+// attached to the unit's machine, and that of a filesystem once it
+// has been mounted there.
+func (api *StorageAPI) storageInstances(unit *state.Unit) ([]params.StorageInstance, error) {
+	instances, err := unit.StorageInstances()
+	if err != nil {
+		return nil, err
+	}
+	machineId, err := unit.AssignedMachineId()
+	if state.IsNotAssigned(err) {
+		machineId = ""
+	} else if err != nil {
+		return nil, err
+	}
+	result := make([]params.StorageInstance, len(instances))
+	for i, instance := range instances {
+		result[i] = params.StorageInstance{
+			Id:   instance.Id(),
+			Name: instance.StorageName(),
+			Kind: string(instance.Kind()),
+		}
+		if machineId == "" {
+			continue
+		}
+		attachment, err := api.state.VolumeAttachment(machineId, instance.VolumeName())
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		info, ok := attachment.Info()
+		if !ok || attachment.Life() != state.Alive {
+			continue
+		}
+		if instance.Kind() == jujustorage.KindFilesystem {
+			result[i].Location = info.MountPoint
+		} else {
+			result[i].Location = "/dev/" + info.DeviceName
+		}
+	}
+	return result, nil
+}
+
+// StorageInstances returns the storage instances
+// owned by each given unit.
+func (api *StorageAPI) StorageInstances(args params.Entities) params.StorageInstancesResults {
+	results := make([]params.StorageInstancesResult, len(args.Entities))
+	for i, entity := range args.Entities {
+		unit, err := api.unit(entity.Tag)
+		if err == nil {
+			results[i].Result, err = api.storageInstances(unit)
+		}
+		results[i].Error = common.ServerError(err)
+	}
+	return params.StorageInstancesResults{Results: results}
+}
+
+// WatchStorage starts a watcher for changes to the
+// storage instances of each given unit.
+func (api *StorageAPI) WatchStorage(args params.Entities) params.NotifyWatchResults {
+	results := make([]params.NotifyWatchResult, len(args.Entities))
+	for i, entity := range args.Entities {
+		unit, err := api.unit(entity.Tag)
+		if err == nil {
+			watch := unit.WatchStorage()
+			// Consume the initial event. Technically, API calls to Watch
+			// 'transmit' the initial event in the Watch response. But
+			// NotifyWatchers have no state to transmit.
+			if _, ok := <-watch.Changes(); ok {
+				results[i].NotifyWatcherId = api.resources.Register(watch)
+			} else {
+				err = watcher.MustErr(watch)
+			}
+		}
+		results[i].Error = common.ServerError(err)
+	}
+	return params.NotifyWatchResults{Results: results}
+}
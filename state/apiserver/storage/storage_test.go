@@ -0,0 +1,144 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage_test
+
+import (
+	gc "launchpad.net/gocheck"
+
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/common"
+	"github.com/juju/juju/state/apiserver/storage"
+	apiservertesting "github.com/juju/juju/state/apiserver/testing"
+	statetesting "github.com/juju/juju/state/testing"
+	jujustorage "github.com/juju/juju/storage"
+)
+
+type storageSuite struct {
+	jujutesting.JujuConnSuite
+
+	machine    *state.Machine
+	mysql0     *state.Unit
+	storage    *storage.StorageAPI
+	resources  *common.Resources
+	authorizer apiservertesting.FakeAuthorizer
+}
+
+var _ = gc.Suite(&storageSuite{})
+
+func (s *storageSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.resources = common.NewResources()
+	s.AddCleanup(func(_ *gc.C) { s.resources.StopAll() })
+
+	mysql := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	var err error
+	s.mysql0, err = mysql.AddUnitWithStorage(map[string]jujustorage.Directive{
+		"data": {Pool: "loop", Size: 1024, Count: 2},
+		"logs": {Pool: "loop", Size: 512, Count: 1, Kind: jujustorage.KindFilesystem},
+	})
+	c.Assert(err, gc.IsNil)
+	s.machine, err = s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+
+	s.authorizer = apiservertesting.FakeAuthorizer{
+		Tag:       s.mysql0.Tag(),
+		LoggedIn:  true,
+		UnitAgent: true,
+		Entity:    s.mysql0,
+	}
+	s.storage, err = storage.NewStorageAPI(s.State, s.resources, s.authorizer)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *storageSuite) TestNewStorageAPIRefusesNonUnitAgent(c *gc.C) {
+	anAuthorizer := s.authorizer
+	anAuthorizer.UnitAgent = false
+	anAuthorizer.MachineAgent = true
+	endPoint, err := storage.NewStorageAPI(s.State, s.resources, anAuthorizer)
+	c.Assert(endPoint, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *storageSuite) TestStorageInstances(c *gc.C) {
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: s.mysql0.Tag()},
+		{Tag: "unit-mysql-1"},
+		{Tag: "machine-0"},
+	}}
+	results := s.storage.StorageInstances(args)
+	c.Assert(results, gc.DeepEquals, params.StorageInstancesResults{
+		Results: []params.StorageInstancesResult{
+			{Result: []params.StorageInstance{
+				{Id: "data/0", Name: "data", Kind: "block"},
+				{Id: "data/1", Name: "data", Kind: "block"},
+				{Id: "logs/0", Name: "logs", Kind: "filesystem"},
+			}},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	err := s.mysql0.AssignToMachine(s.machine)
+	c.Assert(err, gc.IsNil)
+	err = s.State.SetVolumeAttachmentInfo(s.machine.Id(), "1", state.VolumeAttachmentInfo{DeviceName: "loop1"})
+	c.Assert(err, gc.IsNil)
+	// A filesystem has no location until it is mounted.
+	err = s.State.SetVolumeAttachmentInfo(s.machine.Id(), "2", state.VolumeAttachmentInfo{DeviceName: "loop2"})
+	c.Assert(err, gc.IsNil)
+	results = s.storage.StorageInstances(params.Entities{Entities: []params.Entity{{Tag: s.mysql0.Tag()}}})
+	c.Assert(results, gc.DeepEquals, params.StorageInstancesResults{
+		Results: []params.StorageInstancesResult{
+			{Result: []params.StorageInstance{
+				{Id: "data/0", Name: "data", Kind: "block"},
+				{Id: "data/1", Name: "data", Kind: "block", Location: "/dev/loop1"},
+				{Id: "logs/0", Name: "logs", Kind: "filesystem"},
+			}},
+		},
+	})
+
+	err = s.State.SetVolumeAttachmentMountPoint(s.machine.Id(), "2", "/var/lib/juju/storage/mount/2")
+	c.Assert(err, gc.IsNil)
+	results = s.storage.StorageInstances(params.Entities{Entities: []params.Entity{{Tag: s.mysql0.Tag()}}})
+	c.Assert(results, gc.DeepEquals, params.StorageInstancesResults{
+		Results: []params.StorageInstancesResult{
+			{Result: []params.StorageInstance{
+				{Id: "data/0", Name: "data", Kind: "block"},
+				{Id: "data/1", Name: "data", Kind: "block", Location: "/dev/loop1"},
+				{Id: "logs/0", Name: "logs", Kind: "filesystem", Location: "/var/lib/juju/storage/mount/2"},
+			}},
+		},
+	})
+}
+
+func (s *storageSuite) TestWatchStorage(c *gc.C) {
+	c.Assert(s.resources.Count(), gc.Equals, 0)
+
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: s.mysql0.Tag()},
+		{Tag: "unit-mysql-1"},
+	}}
+	results := s.storage.WatchStorage(args)
+	c.Assert(results, gc.DeepEquals, params.NotifyWatchResults{
+		Results: []params.NotifyWatchResult{
+			{NotifyWatcherId: "1"},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	// Verify the resource was registered and stop when done.
+	c.Assert(s.resources.Count(), gc.Equals, 1)
+	resource := s.resources.Get("1")
+	defer statetesting.AssertStop(c, resource)
+
+	// Check that the Watch has consumed the initial event ("returned"
+	// in the Watch call).
+	wc := statetesting.NewNotifyWatcherC(c, s.State, resource.(state.NotifyWatcher))
+	wc.AssertNoChange()
+
+	err := s.mysql0.AssignToMachine(s.machine)
+	c.Assert(err, gc.IsNil)
+	wc.AssertOneChange()
+}
@@ -0,0 +1,233 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storageprovisioner
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/common"
+	"github.com/juju/juju/state/watcher"
+	"github.com/juju/juju/storage"
+)
+
+// StorageProvisionerAPI provides access to the StorageProvisioner API
+// facade. Machine agents use it to create and destroy the volumes
+// attached to their machines.
+type StorageProvisionerAPI struct {
+	*common.EnvironWatcher
+	*common.VolumeInfoSetter
+
+	st          *state.State
+	resources   *common.Resources
+	authorizer  common.Authorizer
+	getAuthFunc common.GetAuthFunc
+}
+
+// NewStorageProvisionerAPI creates a new server-side
+// StorageProvisionerAPI facade.
+func NewStorageProvisionerAPI(
+	st *state.State,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*StorageProvisionerAPI, error) {
+	if !authorizer.AuthMachineAgent() {
+		return nil, common.ErrPerm
+	}
+	getAuthFunc := func() (common.AuthFunc, error) {
+		authEntityTag := authorizer.GetAuthTag()
+		return func(tag string) bool {
+			// A machine agent can only access its own machine.
+			return tag == authEntityTag
+		}, nil
+	}
+	// Machine agents can watch the environment,
+	// but not read its secrets.
+	getCanWatch := common.AuthAlways(true)
+	getCanReadSecrets := common.AuthAlways(false)
+	return &StorageProvisionerAPI{
+		EnvironWatcher:   common.NewEnvironWatcher(st, resources, getCanWatch, getCanReadSecrets),
+		VolumeInfoSetter: common.NewVolumeInfoSetter(st, getAuthFunc),
+		st:               st,
+		resources:        resources,
+		authorizer:       authorizer,
+		getAuthFunc:      getAuthFunc,
+	}, nil
+}
+
+func (s *StorageProvisionerAPI) getMachine(canAccess common.AuthFunc, tag string) (*state.Machine, error) {
+	if !canAccess(tag) {
+		return nil, common.ErrPerm
+	}
+	t, err := names.ParseTag(tag, names.MachineTagKind)
+	if err != nil {
+		return nil, common.ErrPerm
+	}
+	return s.st.Machine(t.Id())
+}
+
+func (s *StorageProvisionerAPI) watchVolumeAttachments(canAccess common.AuthFunc, tag string) (params.StringsWatchResult, error) {
+	nothing := params.StringsWatchResult{}
+	machine, err := s.getMachine(canAccess, tag)
+	if err != nil {
+		return nothing, err
+	}
+	watch := machine.WatchVolumeAttachments()
+	// Consume the initial event and forward it to the result.
+	if changes, ok := <-watch.Changes(); ok {
+		return params.StringsWatchResult{
+			StringsWatcherId: s.resources.Register(watch),
+			Changes:          changes,
+		}, nil
+	}
+	return nothing, watcher.MustErr(watch)
+}
+
+// WatchVolumeAttachments starts a StringsWatcher to watch the volume
+// attachments of each given machine. The ids reported are of the
+// form "<machine id>:<volume name>".
+func (s *StorageProvisionerAPI) WatchVolumeAttachments(args params.Entities) (params.StringsWatchResults, error) {
+	result := params.StringsWatchResults{
+		Results: make([]params.StringsWatchResult, len(args.Entities)),
+	}
+	canAccess, err := s.getAuthFunc()
+	if err != nil {
+		return result, err
+	}
+	for i, entity := range args.Entities {
+		watcherResult, err := s.watchVolumeAttachments(canAccess, entity.Tag)
+		result.Results[i] = watcherResult
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+func (s *StorageProvisionerAPI) volumeAttachment(canAccess common.AuthFunc, id params.VolumeAttachmentId) (params.VolumeAttachment, error) {
+	nothing := params.VolumeAttachment{}
+	machine, err := s.getMachine(canAccess, id.MachineTag)
+	if err != nil {
+		return nothing, err
+	}
+	attachment, err := s.st.VolumeAttachment(machine.Id(), id.Volume)
+	if err != nil {
+		return nothing, err
+	}
+	volume, err := s.st.Volume(id.Volume)
+	if err != nil {
+		return nothing, err
+	}
+	instanceId, err := machine.InstanceId()
+	if err != nil && !state.IsNotProvisionedError(err) {
+		return nothing, err
+	}
+	result := params.VolumeAttachment{
+		Params: volume.Params(),
+		Life:   params.Life(attachment.Life().String()),
+	}
+	result.Params.Attachment = &storage.AttachmentParams{
+		Machine:    machine.Id(),
+		InstanceId: instanceId,
+	}
+	// The storage instance is removed before the volume backing
+	// it, in which case the kind is left unset; the volume is no
+	// longer alive, and any filesystem on it only needs removing.
+	instance, err := s.st.StorageInstance(volume.StorageInstance())
+	if err == nil {
+		result.Kind = instance.Kind()
+	} else if !errors.IsNotFound(err) {
+		return nothing, err
+	}
+	volumeInfo, ok := volume.Info()
+	if !ok {
+		return result, nil
+	}
+	if attachmentInfo, ok := attachment.Info(); ok {
+		result.Provisioned = true
+		result.VolumeId = volumeInfo.VolumeId
+		result.DeviceName = attachmentInfo.DeviceName
+		result.MountPoint = attachmentInfo.MountPoint
+	}
+	return result, nil
+}
+
+// VolumeAttachments returns the details of each
+// given volume attachment.
+func (s *StorageProvisionerAPI) VolumeAttachments(args params.VolumeAttachmentIds) (params.VolumeAttachmentResults, error) {
+	result := params.VolumeAttachmentResults{
+		Results: make([]params.VolumeAttachmentResult, len(args.Ids)),
+	}
+	canAccess, err := s.getAuthFunc()
+	if err != nil {
+		return result, err
+	}
+	for i, id := range args.Ids {
+		attachment, err := s.volumeAttachment(canAccess, id)
+		result.Results[i].Result = attachment
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+func (s *StorageProvisionerAPI) removeVolume(canAccess common.AuthFunc, id params.VolumeAttachmentId) error {
+	machine, err := s.getMachine(canAccess, id.MachineTag)
+	if err != nil {
+		return err
+	}
+	// Machines may only remove the volumes attached to them.
+	_, err = s.st.VolumeAttachment(machine.Id(), id.Volume)
+	if errors.IsNotFound(err) {
+		if _, err := s.st.Volume(id.Volume); errors.IsNotFound(err) {
+			// The volume has already been removed.
+			return nil
+		}
+		return common.ErrPerm
+	} else if err != nil {
+		return err
+	}
+	return s.st.RemoveVolume(id.Volume)
+}
+
+// RemoveVolumes removes each given volume, which must be dying
+// or dead, along with its attachment to the given machine.
+func (s *StorageProvisionerAPI) RemoveVolumes(args params.VolumeAttachmentIds) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Ids)),
+	}
+	canAccess, err := s.getAuthFunc()
+	if err != nil {
+		return result, err
+	}
+	for i, id := range args.Ids {
+		err := s.removeVolume(canAccess, id)
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+func (s *StorageProvisionerAPI) setFilesystemInfo(canAccess common.AuthFunc, arg params.FilesystemInfo) error {
+	machine, err := s.getMachine(canAccess, arg.MachineTag)
+	if err != nil {
+		return err
+	}
+	return s.st.SetVolumeAttachmentMountPoint(machine.Id(), arg.Volume, arg.MountPoint)
+}
+
+// SetFilesystemInfo records where the filesystem created on
+// each given volume is mounted on the machine it is attached to.
+func (s *StorageProvisionerAPI) SetFilesystemInfo(args params.FilesystemsInfo) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Filesystems)),
+	}
+	canAccess, err := s.getAuthFunc()
+	if err != nil {
+		return result, err
+	}
+	for i, arg := range args.Filesystems {
+		err := s.setFilesystemInfo(canAccess, arg)
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
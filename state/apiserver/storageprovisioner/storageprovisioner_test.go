@@ -0,0 +1,222 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storageprovisioner_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/common"
+	"github.com/juju/juju/state/apiserver/storageprovisioner"
+	apiservertesting "github.com/juju/juju/state/apiserver/testing"
+	statetesting "github.com/juju/juju/state/testing"
+	"github.com/juju/juju/storage"
+)
+
+type storageProvisionerSuite struct {
+	jujutesting.JujuConnSuite
+
+	machine     *state.Machine
+	unit        *state.Unit
+	provisioner *storageprovisioner.StorageProvisionerAPI
+	resources   *common.Resources
+	authorizer  apiservertesting.FakeAuthorizer
+}
+
+var _ = gc.Suite(&storageProvisionerSuite{})
+
+func (s *storageProvisionerSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.resources = common.NewResources()
+	s.AddCleanup(func(_ *gc.C) { s.resources.StopAll() })
+
+	mysql := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	var err error
+	s.unit, err = mysql.AddUnitWithStorage(map[string]storage.Directive{
+		"data": {Pool: "loop", Size: 1024, Count: 1},
+	})
+	c.Assert(err, gc.IsNil)
+	s.machine, err = s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	err = s.unit.AssignToMachine(s.machine)
+	c.Assert(err, gc.IsNil)
+
+	s.authorizer = apiservertesting.FakeAuthorizer{
+		Tag:          s.machine.Tag(),
+		LoggedIn:     true,
+		MachineAgent: true,
+		Entity:       s.machine,
+	}
+	s.provisioner, err = storageprovisioner.NewStorageProvisionerAPI(s.State, s.resources, s.authorizer)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *storageProvisionerSuite) TestNewStorageProvisionerAPIRefusesNonMachineAgent(c *gc.C) {
+	anAuthorizer := s.authorizer
+	anAuthorizer.MachineAgent = false
+	anAuthorizer.UnitAgent = true
+	endPoint, err := storageprovisioner.NewStorageProvisionerAPI(s.State, s.resources, anAuthorizer)
+	c.Assert(endPoint, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *storageProvisionerSuite) TestWatchVolumeAttachments(c *gc.C) {
+	c.Assert(s.resources.Count(), gc.Equals, 0)
+
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: s.machine.Tag()},
+		{Tag: "machine-42"},
+		{Tag: s.unit.Tag()},
+	}}
+	result, err := s.provisioner.WatchVolumeAttachments(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.StringsWatchResults{
+		Results: []params.StringsWatchResult{
+			{StringsWatcherId: "1", Changes: []string{s.machine.Id() + ":0"}},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	// Verify the resource was registered and stop when done.
+	c.Assert(s.resources.Count(), gc.Equals, 1)
+	resource := s.resources.Get("1")
+	defer statetesting.AssertStop(c, resource)
+
+	// Check that the Watch has consumed the initial event ("returned"
+	// in the Watch call).
+	wc := statetesting.NewStringsWatcherC(c, s.State, resource.(state.StringsWatcher))
+	wc.AssertNoChange()
+}
+
+func (s *storageProvisionerSuite) TestVolumeAttachments(c *gc.C) {
+	args := params.VolumeAttachmentIds{Ids: []params.VolumeAttachmentId{
+		{MachineTag: s.machine.Tag(), Volume: "0"},
+		{MachineTag: s.machine.Tag(), Volume: "42"},
+		{MachineTag: "machine-42", Volume: "0"},
+	}}
+	expectParams := storage.VolumeParams{
+		Name:       "0",
+		Size:       1024,
+		Provider:   "loop",
+		Attachment: &storage.AttachmentParams{Machine: s.machine.Id()},
+	}
+	result, err := s.provisioner.VolumeAttachments(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.VolumeAttachmentResults{
+		Results: []params.VolumeAttachmentResult{
+			{Result: params.VolumeAttachment{Params: expectParams, Life: params.Alive, Kind: storage.KindBlock}},
+			{Error: apiservertesting.NotFoundError(`volume "42" on machine "` + s.machine.Id() + `"`)},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	setResult, err := s.provisioner.SetVolumeInfo(params.VolumesInfo{
+		Volumes: []params.VolumeInfo{{
+			MachineTag: s.machine.Tag(),
+			Volume:     storage.Volume{Name: "0", VolumeId: "volume-0", Size: 1024},
+			Attachment: storage.VolumeAttachment{Volume: "0", Machine: s.machine.Id(), DeviceName: "loop0"},
+		}},
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(setResult, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{{nil}},
+	})
+	result, err = s.provisioner.VolumeAttachments(params.VolumeAttachmentIds{
+		Ids: args.Ids[:1],
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.VolumeAttachmentResults{
+		Results: []params.VolumeAttachmentResult{{
+			Result: params.VolumeAttachment{
+				Params:      expectParams,
+				Life:        params.Alive,
+				Kind:        storage.KindBlock,
+				Provisioned: true,
+				VolumeId:    "volume-0",
+				DeviceName:  "loop0",
+			},
+		}},
+	})
+}
+
+func (s *storageProvisionerSuite) TestSetFilesystemInfo(c *gc.C) {
+	mysql, err := s.State.Service("mysql")
+	c.Assert(err, gc.IsNil)
+	unit, err := mysql.AddUnitWithStorage(map[string]storage.Directive{
+		"data": {Pool: "loop", Size: 1024, Count: 1, Kind: storage.KindFilesystem},
+	})
+	c.Assert(err, gc.IsNil)
+	err = unit.AssignToMachine(s.machine)
+	c.Assert(err, gc.IsNil)
+	id := params.VolumeAttachmentId{MachineTag: s.machine.Tag(), Volume: "1"}
+	result, err := s.provisioner.VolumeAttachments(params.VolumeAttachmentIds{
+		Ids: []params.VolumeAttachmentId{id},
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Results, gc.HasLen, 1)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+	c.Assert(result.Results[0].Result.Kind, gc.Equals, storage.KindFilesystem)
+
+	args := params.FilesystemsInfo{Filesystems: []params.FilesystemInfo{
+		{MachineTag: s.machine.Tag(), Volume: "1", MountPoint: "/srv/data"},
+		{MachineTag: "machine-42", Volume: "1", MountPoint: "/srv/data"},
+	}}
+	setResult, err := s.provisioner.SetFilesystemInfo(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(setResult.Results, gc.HasLen, 2)
+	c.Assert(setResult.Results[0].Error, gc.ErrorMatches, `cannot set mount point for volume "1" on machine "[0-9]+": volume not attached`)
+	c.Assert(setResult.Results[1].Error, gc.DeepEquals, apiservertesting.ErrUnauthorized)
+
+	err = s.State.SetVolumeAttachmentInfo(s.machine.Id(), "1", state.VolumeAttachmentInfo{DeviceName: "loop1"})
+	c.Assert(err, gc.IsNil)
+	setResult, err = s.provisioner.SetFilesystemInfo(params.FilesystemsInfo{
+		Filesystems: args.Filesystems[:1],
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(setResult, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{{nil}},
+	})
+	attachment, err := s.State.VolumeAttachment(s.machine.Id(), "1")
+	c.Assert(err, gc.IsNil)
+	info, ok := attachment.Info()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(info, gc.Equals, state.VolumeAttachmentInfo{DeviceName: "loop1", MountPoint: "/srv/data"})
+}
+
+func (s *storageProvisionerSuite) TestRemoveVolumes(c *gc.C) {
+	other, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	args := params.VolumeAttachmentIds{Ids: []params.VolumeAttachmentId{
+		{MachineTag: s.machine.Tag(), Volume: "0"},
+		{MachineTag: other.Tag(), Volume: "0"},
+	}}
+	result, err := s.provisioner.RemoveVolumes(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{&params.Error{Message: `cannot remove volume "0": volume is alive`}},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	err = s.unit.EnsureDead()
+	c.Assert(err, gc.IsNil)
+	err = s.unit.Remove()
+	c.Assert(err, gc.IsNil)
+	err = s.State.Cleanup()
+	c.Assert(err, gc.IsNil)
+
+	result, err = s.provisioner.RemoveVolumes(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{{nil}, {nil}},
+	})
+	_, err = s.State.Volume("0")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
@@ -0,0 +1,118 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package subnets
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/common"
+)
+
+var logger = loggo.GetLogger("juju.state.apiserver.subnets")
+
+// SubnetsAPI implements the API end point used by clients to add
+// subnets to an environment and list them.
+type SubnetsAPI struct {
+	st         *state.State
+	authorizer common.Authorizer
+}
+
+// NewSubnetsAPI returns a new SubnetsAPI.
+func NewSubnetsAPI(st *state.State, authorizer common.Authorizer) (*SubnetsAPI, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	return &SubnetsAPI{
+		st:         st,
+		authorizer: authorizer,
+	}, nil
+}
+
+// AddSubnet adds a subnet, identified by either its CIDR or its
+// provider id, optionally to an existing space. The subnet's details
+// are taken from the provider when it can discover subnets; otherwise
+// only a CIDR can be used to add it.
+func (api *SubnetsAPI) AddSubnet(args params.AddSubnetArgs) error {
+	if (args.SubnetCIDR == "") == (args.SubnetProviderId == "") {
+		return fmt.Errorf("either CIDR or provider id must be specified")
+	}
+	info := state.SubnetInfo{
+		CIDR:      args.SubnetCIDR,
+		SpaceName: args.SpaceName,
+	}
+	discovered, err := api.discoverSubnet(args.SubnetCIDR, network.Id(args.SubnetProviderId))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if discovered != nil {
+		info.CIDR = discovered.CIDR
+		info.ProviderId = discovered.ProviderId
+		info.VLANTag = discovered.VLANTag
+		info.AvailabilityZones = discovered.AvailabilityZones
+	} else if args.SubnetCIDR == "" {
+		return errors.NotFoundf("subnet with provider id %q", args.SubnetProviderId)
+	}
+	_, err = api.st.AddSubnet(info)
+	return err
+}
+
+// discoverSubnet asks the provider for the subnet with the given CIDR
+// or provider id. It returns nil if the provider does not know the
+// subnet, or cannot discover subnets at all.
+func (api *SubnetsAPI) discoverSubnet(cidr string, providerId network.Id) (*network.SubnetInfo, error) {
+	envConfig, err := api.st.EnvironConfig()
+	if err != nil {
+		return nil, err
+	}
+	env, err := environs.New(envConfig)
+	if err != nil {
+		return nil, err
+	}
+	discoverer, ok := env.(environs.SubnetDiscoverer)
+	if !ok {
+		logger.Debugf("provider %q cannot discover subnets", envConfig.Type())
+		return nil, nil
+	}
+	subnets, err := discoverer.Subnets()
+	if errors.IsNotImplemented(err) || errors.IsNotSupported(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, subnet := range subnets {
+		if cidr != "" && subnet.CIDR == cidr || providerId != "" && subnet.ProviderId == providerId {
+			return &subnet, nil
+		}
+	}
+	return nil, nil
+}
+
+// ListSubnets returns the subnets in the environment, sorted by CIDR,
+// optionally only those in the given space.
+func (api *SubnetsAPI) ListSubnets(args params.ListSubnetsArgs) (params.ListSubnetsResults, error) {
+	var subnets []*state.Subnet
+	var err error
+	if args.SpaceName == "" {
+		subnets, err = api.st.AllSubnets()
+	} else {
+		var space *state.Space
+		space, err = api.st.Space(args.SpaceName)
+		if err == nil {
+			subnets, err = space.Subnets()
+		}
+	}
+	if err != nil {
+		return params.ListSubnetsResults{}, err
+	}
+	return params.ListSubnetsResults{
+		Subnets: common.SubnetsParams(subnets),
+	}, nil
+}
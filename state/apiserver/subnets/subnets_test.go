@@ -0,0 +1,122 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package subnets_test
+
+import (
+	gc "launchpad.net/gocheck"
+
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/apiserver/subnets"
+	apiservertesting "github.com/juju/juju/state/apiserver/testing"
+)
+
+type subnetsSuite struct {
+	jujutesting.JujuConnSuite
+
+	api *subnets.SubnetsAPI
+}
+
+var _ = gc.Suite(&subnetsSuite{})
+
+func (s *subnetsSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	var err error
+	s.api, err = subnets.NewSubnetsAPI(s.State, apiservertesting.FakeAuthorizer{
+		Tag:      "user-admin",
+		LoggedIn: true,
+		Client:   true,
+	})
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *subnetsSuite) TestNewSubnetsAPIRefusesNonClient(c *gc.C) {
+	api, err := subnets.NewSubnetsAPI(s.State, apiservertesting.FakeAuthorizer{
+		Tag:       "unit-mysql-0",
+		LoggedIn:  true,
+		UnitAgent: true,
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	c.Assert(api, gc.IsNil)
+}
+
+func (s *subnetsSuite) TestAddSubnetDiscoveredByProviderId(c *gc.C) {
+	_, err := s.State.AddSpace("db", nil)
+	c.Assert(err, gc.IsNil)
+
+	err = s.api.AddSubnet(params.AddSubnetArgs{
+		SubnetProviderId: "dummy-private",
+		SpaceName:        "db",
+	})
+	c.Assert(err, gc.IsNil)
+	subnet, err := s.State.Subnet("0.10.0.0/24")
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(subnet.ProviderId()), gc.Equals, "dummy-private")
+	c.Assert(subnet.AvailabilityZones(), gc.DeepEquals, []string{"zone1", "zone2"})
+	c.Assert(subnet.SpaceName(), gc.Equals, "db")
+}
+
+func (s *subnetsSuite) TestAddSubnetDiscoveredByCIDR(c *gc.C) {
+	err := s.api.AddSubnet(params.AddSubnetArgs{SubnetCIDR: "0.20.0.0/24"})
+	c.Assert(err, gc.IsNil)
+	subnet, err := s.State.Subnet("0.20.0.0/24")
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(subnet.ProviderId()), gc.Equals, "dummy-public")
+	c.Assert(subnet.SpaceName(), gc.Equals, "")
+}
+
+func (s *subnetsSuite) TestAddSubnetUnknownToProvider(c *gc.C) {
+	err := s.api.AddSubnet(params.AddSubnetArgs{SubnetCIDR: "10.0.0.0/24"})
+	c.Assert(err, gc.IsNil)
+	subnet, err := s.State.Subnet("10.0.0.0/24")
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(subnet.ProviderId()), gc.Equals, "")
+
+	err = s.api.AddSubnet(params.AddSubnetArgs{SubnetProviderId: "subnet-42"})
+	c.Assert(err, gc.ErrorMatches, `subnet with provider id "subnet-42" not found`)
+}
+
+func (s *subnetsSuite) TestAddSubnetErrors(c *gc.C) {
+	err := s.api.AddSubnet(params.AddSubnetArgs{})
+	c.Assert(err, gc.ErrorMatches, "either CIDR or provider id must be specified")
+	err = s.api.AddSubnet(params.AddSubnetArgs{
+		SubnetCIDR:       "10.0.0.0/24",
+		SubnetProviderId: "subnet-0",
+	})
+	c.Assert(err, gc.ErrorMatches, "either CIDR or provider id must be specified")
+	err = s.api.AddSubnet(params.AddSubnetArgs{
+		SubnetCIDR: "10.0.0.0/24",
+		SpaceName:  "missing",
+	})
+	c.Assert(err, gc.ErrorMatches, `cannot add subnet "10.0.0.0/24": space "missing" not found`)
+}
+
+func (s *subnetsSuite) TestListSubnets(c *gc.C) {
+	for _, info := range []state.SubnetInfo{
+		{CIDR: "10.0.1.0/24"},
+		{CIDR: "10.0.0.0/24", ProviderId: "subnet-0", VLANTag: 42},
+	} {
+		_, err := s.State.AddSubnet(info)
+		c.Assert(err, gc.IsNil)
+	}
+	_, err := s.State.AddSpace("db", []string{"10.0.1.0/24"})
+	c.Assert(err, gc.IsNil)
+
+	result, err := s.api.ListSubnets(params.ListSubnetsArgs{})
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Subnets, gc.DeepEquals, []params.Subnet{
+		{CIDR: "10.0.0.0/24", ProviderId: "subnet-0", VLANTag: 42},
+		{CIDR: "10.0.1.0/24", SpaceName: "db"},
+	})
+
+	result, err = s.api.ListSubnets(params.ListSubnetsArgs{SpaceName: "db"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Subnets, gc.DeepEquals, []params.Subnet{
+		{CIDR: "10.0.1.0/24", SpaceName: "db"},
+	})
+
+	_, err = s.api.ListSubnets(params.ListSubnetsArgs{SpaceName: "missing"})
+	c.Assert(err, gc.ErrorMatches, `space "missing" not found`)
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/juju/juju/environs/filestorage"
 	"github.com/juju/juju/environs/sync"
 	envtools "github.com/juju/juju/environs/tools"
+	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/api/params"
 	"github.com/juju/juju/state/apiserver/common"
 	"github.com/juju/juju/tools"
@@ -30,14 +31,25 @@ type toolsHandler struct {
 }
 
 func (h *toolsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	if err := h.authenticate(r); err != nil {
+	user, err := h.authenticate(r)
+	if err != nil {
 		h.authError(w, h)
 		return
 	}
-	if err := h.validateEnvironUUID(r); err != nil {
+	st, err := h.environState(r)
+	if err != nil {
 		h.sendError(w, http.StatusNotFound, err.Error())
 		return
 	}
+	// Uploading tools changes the environment.
+	if err := h.checkAccess(st, user, state.WriteAccess); err != nil {
+		h.sendError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	// Serve the request from the environment it names.
+	envHandler := *h
+	envHandler.state = st
+	h = &envHandler
 
 	switch r.Method {
 	case "POST":
@@ -74,6 +74,13 @@ func (s *toolsSuite) TestAuthRequiresUser(c *gc.C) {
 	s.assertErrorResponse(c, resp, http.StatusBadRequest, "expected binaryVersion argument")
 }
 
+func (s *toolsSuite) TestUploadRequiresWriteAccess(c *gc.C) {
+	tag := s.userWithAccess(c, state.ReadAccess)
+	resp, err := s.sendRequest(c, tag, s.password, "POST", s.toolsURI(c, ""), "", nil)
+	c.Assert(err, gc.IsNil)
+	s.assertErrorResponse(c, resp, http.StatusForbidden, "permission denied")
+}
+
 func (s *toolsSuite) TestUploadRequiresVersion(c *gc.C) {
 	resp, err := s.authRequest(c, "POST", s.toolsURI(c, ""), "", nil)
 	c.Assert(err, gc.IsNil)
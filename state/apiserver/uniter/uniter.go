@@ -190,6 +190,106 @@ func (u *UniterAPI) ClearResolved(args params.Entities) (params.ErrorResults, er
 	return result, nil
 }
 
+// WorkloadStatus returns the status of the workload of each given
+// unit.
+func (u *UniterAPI) WorkloadStatus(args params.Entities) (params.WorkloadStatusResults, error) {
+	result := params.WorkloadStatusResults{
+		Results: make([]params.WorkloadStatusResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.WorkloadStatusResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				r := &result.Results[i]
+				r.Status, r.Info, err = unit.WorkloadStatus()
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// SetWorkloadStatus sets the status of the workload of each given
+// unit.
+func (u *UniterAPI) SetWorkloadStatus(args params.SetWorkloadStatus) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				err = unit.SetWorkloadStatus(entity.Status, entity.Info)
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// SetAgentActivity records what the agent of each given unit is doing.
+func (u *UniterAPI) SetAgentActivity(args params.SetAgentActivity) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				err = unit.SetAgentActivity(entity.Activity, entity.Hook)
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// AddMetricBatches records the batches of metrics reported
+// by each given unit.
+func (u *UniterAPI) AddMetricBatches(args params.MetricBatchParams) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Batches)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, batch := range args.Batches {
+		err := common.ErrPerm
+		if canAccess(batch.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(batch.Tag)
+			if err == nil {
+				metrics := make([]state.Metric, len(batch.Metrics))
+				for j, m := range batch.Metrics {
+					metrics[j] = state.Metric{Key: m.Key, Value: m.Value, Time: m.Time}
+				}
+				_, err = unit.AddMetrics(batch.Created, metrics)
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
 // GetPrincipal returns the result of calling PrincipalName() and
 // converting it to a tag, on each given unit.
 func (u *UniterAPI) GetPrincipal(args params.Entities) (params.StringBoolResults, error) {
@@ -410,6 +510,43 @@ func (u *UniterAPI) ClosePort(args params.EntitiesPorts) (params.ErrorResults, e
 	return result, nil
 }
 
+// OpenPorts sets the policy of the range of ports with the given
+// protocol to be opened, for all given units.
+func (u *UniterAPI) OpenPorts(args params.EntitiesPortRanges) (params.ErrorResults, error) {
+	return u.changePortRanges(args, (*state.Unit).OpenPorts)
+}
+
+// ClosePorts sets the policy of the range of ports with the given
+// protocol to be closed, for all given units.
+func (u *UniterAPI) ClosePorts(args params.EntitiesPortRanges) (params.ErrorResults, error) {
+	return u.changePortRanges(args, (*state.Unit).ClosePorts)
+}
+
+func (u *UniterAPI) changePortRanges(
+	args params.EntitiesPortRanges,
+	change func(unit *state.Unit, protocol string, fromPort, toPort int) error,
+) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				err = change(unit, entity.Protocol, entity.FromPort, entity.ToPort)
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
 func (u *UniterAPI) watchOneUnitConfigSettings(tag string) (string, error) {
 	unit, err := u.getUnit(tag)
 	if err != nil {
@@ -797,6 +934,34 @@ func (u *UniterAPI) EnterScope(args params.RelationUnits) (params.ErrorResults,
 	return result, nil
 }
 
+// RelationPrivateAddress returns, for each given relation/unit pair,
+// the private address the unit advertises in the relation. It is the
+// unit's address in the space the relation endpoint is bound to, if
+// any, and its private address otherwise. See also
+// state.RelationUnit.PrivateAddress().
+func (u *UniterAPI) RelationPrivateAddress(args params.RelationUnits) (params.StringResults, error) {
+	result := params.StringResults{
+		Results: make([]params.StringResult, len(args.RelationUnits)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.StringResults{}, err
+	}
+	for i, arg := range args.RelationUnits {
+		relUnit, err := u.getRelationUnit(canAccess, arg.Relation, arg.Unit)
+		if err == nil {
+			address, ok := relUnit.PrivateAddress()
+			if ok {
+				result.Results[i].Result = address
+			} else {
+				err = common.NoAddressSetError(arg.Unit, "private")
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
 // LeaveScope signals each unit has left its scope in the relation,
 // for all of the given relation/unit pairs. See also
 // state.RelationUnit.LeaveScope().
@@ -971,6 +1136,108 @@ func (u *UniterAPI) WatchRelationUnits(args params.RelationUnits) (params.Relati
 	return result, nil
 }
 
+func (u *UniterAPI) watchOneUnitActions(tag string) (params.StringsWatchResult, error) {
+	nothing := params.StringsWatchResult{}
+	unit, err := u.getUnit(tag)
+	if err != nil {
+		return nothing, err
+	}
+	watch := unit.WatchActions()
+	// Consume the initial event and forward it to the result.
+	if changes, ok := <-watch.Changes(); ok {
+		return params.StringsWatchResult{
+			StringsWatcherId: u.resources.Register(watch),
+			Changes:          changes,
+		}, nil
+	}
+	return nothing, watcher.MustErr(watch)
+}
+
+// WatchActions returns a StringsWatcher, for each given unit, that
+// notifies of the ids of actions queued for that unit.
+func (u *UniterAPI) WatchActions(args params.Entities) (params.StringsWatchResults, error) {
+	result := params.StringsWatchResults{
+		Results: make([]params.StringsWatchResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.StringsWatchResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			result.Results[i], err = u.watchOneUnitActions(entity.Tag)
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// getAction returns the queued action with the given id, if it
+// belongs to a unit the caller can access.
+func (u *UniterAPI) getAction(canAccess common.AuthFunc, id string) (*state.Action, error) {
+	if !state.IsAction(id) {
+		return nil, common.ErrPerm
+	}
+	action, err := u.st.Action(id)
+	if errors.IsNotFound(err) {
+		return nil, common.ErrPerm
+	} else if err != nil {
+		return nil, err
+	}
+	if !canAccess(names.NewUnitTag(action.UnitName()).String()) {
+		return nil, common.ErrPerm
+	}
+	return action, nil
+}
+
+// Actions returns the name and parameters of each given queued action.
+func (u *UniterAPI) Actions(args params.Actions) (params.ActionResults, error) {
+	result := params.ActionResults{
+		Results: make([]params.ActionResult, len(args.Actions)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ActionResults{}, err
+	}
+	for i, arg := range args.Actions {
+		action, err := u.getAction(canAccess, arg.Id)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		result.Results[i].Action = params.Action{
+			Id:     action.Id(),
+			Unit:   names.NewUnitTag(action.UnitName()).String(),
+			Name:   action.Name(),
+			Params: action.Payload(),
+		}
+		result.Results[i].Status = params.ActionPending
+	}
+	return result, nil
+}
+
+// FinishActions removes each given action from its unit's queue, and
+// records its final status, results and message.
+func (u *UniterAPI) FinishActions(args params.ActionResults) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Results)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, arg := range args.Results {
+		action, err := u.getAction(canAccess, arg.Action.Id)
+		if err == nil {
+			status := state.ActionStatus(arg.Status)
+			err = action.Finish(status, arg.Results, arg.Message)
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
 // TODO(dimitern) bug #1270795 2014-01-20
 // Add a doc comment here and use u.accessService()
 // below in the body to check for permissions.
@@ -5,6 +5,7 @@ package uniter_test
 
 import (
 	stdtesting "testing"
+	"time"
 
 	"github.com/juju/charm"
 	"github.com/juju/errors"
@@ -425,6 +426,114 @@ func (s *uniterSuite) TestClearResolved(c *gc.C) {
 	c.Assert(mode, gc.Equals, state.ResolvedNone)
 }
 
+func (s *uniterSuite) TestWorkloadStatus(c *gc.C) {
+	err := s.wordpressUnit.SetWorkloadStatus(params.WorkloadBlocked, "need a database")
+	c.Assert(err, gc.IsNil)
+
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: "unit-mysql-0"},
+		{Tag: "unit-wordpress-0"},
+		{Tag: "unit-foo-42"},
+	}}
+	result, err := s.uniter.WorkloadStatus(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.WorkloadStatusResults{
+		Results: []params.WorkloadStatusResult{
+			{Error: apiservertesting.ErrUnauthorized},
+			{Status: params.WorkloadBlocked, Info: "need a database"},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+}
+
+func (s *uniterSuite) TestSetWorkloadStatus(c *gc.C) {
+	args := params.SetWorkloadStatus{Entities: []params.EntityWorkloadStatus{
+		{Tag: "unit-mysql-0", Status: params.WorkloadActive},
+		{Tag: "unit-wordpress-0", Status: params.WorkloadActive, Info: "ready"},
+		{Tag: "unit-wordpress-0", Status: params.WorkloadUnknown},
+		{Tag: "unit-foo-42", Status: params.WorkloadActive},
+	}}
+	result, err := s.uniter.SetWorkloadStatus(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{apiservertesting.ErrUnauthorized},
+			{nil},
+			{&params.Error{Message: `cannot set workload status "unknown"`}},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	// Verify the workload status of wordpressUnit has changed,
+	// and that of mysqlUnit has not.
+	status, info, err := s.wordpressUnit.WorkloadStatus()
+	c.Assert(err, gc.IsNil)
+	c.Assert(status, gc.Equals, params.WorkloadActive)
+	c.Assert(info, gc.Equals, "ready")
+	status, _, err = s.mysqlUnit.WorkloadStatus()
+	c.Assert(err, gc.IsNil)
+	c.Assert(status, gc.Equals, params.WorkloadUnknown)
+}
+
+func (s *uniterSuite) TestSetAgentActivity(c *gc.C) {
+	args := params.SetAgentActivity{Entities: []params.EntityAgentActivity{
+		{Tag: "unit-mysql-0", Activity: params.ActivityIdle},
+		{Tag: "unit-wordpress-0", Activity: params.ActivityExecuting, Hook: "install"},
+		{Tag: "unit-wordpress-0", Activity: params.ActivityExecuting},
+		{Tag: "unit-foo-42", Activity: params.ActivityIdle},
+	}}
+	result, err := s.uniter.SetAgentActivity(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{apiservertesting.ErrUnauthorized},
+			{nil},
+			{&params.Error{Message: `cannot set agent activity "executing" without hook`}},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	// Verify the agent activity of wordpressUnit has changed,
+	// and that of mysqlUnit has not.
+	activity, hook, err := s.wordpressUnit.AgentActivity()
+	c.Assert(err, gc.IsNil)
+	c.Assert(activity, gc.Equals, params.ActivityExecuting)
+	c.Assert(hook, gc.Equals, "install")
+	activity, _, err = s.mysqlUnit.AgentActivity()
+	c.Assert(err, gc.IsNil)
+	c.Assert(activity, gc.Equals, params.AgentActivity(""))
+}
+
+func (s *uniterSuite) TestAddMetricBatches(c *gc.C) {
+	err := s.wordpressUnit.SetCharmURL(s.wpCharm.URL())
+	c.Assert(err, gc.IsNil)
+	now := time.Now()
+	metrics := []params.Metric{{Key: "pings", Value: "5", Time: now}}
+	args := params.MetricBatchParams{Batches: []params.MetricBatchParam{
+		{Tag: "unit-mysql-0", Created: now, Metrics: metrics},
+		{Tag: "unit-wordpress-0", Created: now, Metrics: metrics},
+		{Tag: "unit-wordpress-0", Created: now},
+		{Tag: "unit-foo-42", Created: now, Metrics: metrics},
+	}}
+	result, err := s.uniter.AddMetricBatches(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{apiservertesting.ErrUnauthorized},
+			{nil},
+			{&params.Error{Message: `cannot add metrics for unit "wordpress/0": no metrics given`}},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	batches, err := s.State.MetricBatches()
+	c.Assert(err, gc.IsNil)
+	c.Assert(batches, gc.HasLen, 1)
+	c.Assert(batches[0].Unit(), gc.Equals, "wordpress/0")
+	c.Assert(batches[0].CharmURL(), gc.Equals, s.wpCharm.URL().String())
+	c.Assert(batches[0].Metrics(), gc.HasLen, 1)
+}
+
 func (s *uniterSuite) TestGetPrincipal(c *gc.C) {
 	// Add a subordinate to wordpressUnit.
 	_, _, subordinate := s.addRelatedService(c, "wordpress", "logging", s.wordpressUnit)
@@ -702,6 +811,41 @@ func (s *uniterSuite) TestClosePort(c *gc.C) {
 	c.Assert(openedPorts, gc.HasLen, 0)
 }
 
+func (s *uniterSuite) TestOpenClosePorts(c *gc.C) {
+	args := params.EntitiesPortRanges{Entities: []params.EntityPortRange{
+		{Tag: "unit-mysql-0", Protocol: "tcp", FromPort: 1000, ToPort: 2000},
+		{Tag: "unit-wordpress-0", Protocol: "tcp", FromPort: 8000, ToPort: 8080},
+		{Tag: "unit-foo-42", Protocol: "tcp", FromPort: 42, ToPort: 43},
+	}}
+	result, err := s.uniter.OpenPorts(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{apiservertesting.ErrUnauthorized},
+			{nil},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+	err = s.wordpressUnit.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.wordpressUnit.OpenedPortRanges(), gc.DeepEquals, []network.PortRange{
+		{Protocol: "tcp", FromPort: 8000, ToPort: 8080},
+	})
+
+	result, err = s.uniter.ClosePorts(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{apiservertesting.ErrUnauthorized},
+			{nil},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+	err = s.wordpressUnit.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.wordpressUnit.OpenedPortRanges(), gc.HasLen, 0)
+}
+
 func (s *uniterSuite) TestWatchConfigSettings(c *gc.C) {
 	err := s.wordpressUnit.SetCharmURL(s.wpCharm.URL())
 	c.Assert(err, gc.IsNil)
@@ -769,6 +913,85 @@ func (s *uniterSuite) TestWatchServiceRelations(c *gc.C) {
 	s.assertOneStringsWatcher(c, result, err)
 }
 
+func (s *uniterSuite) TestWatchActions(c *gc.C) {
+	c.Assert(s.resources.Count(), gc.Equals, 0)
+
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: "unit-mysql-0"},
+		{Tag: "unit-wordpress-0"},
+		{Tag: "unit-foo-42"},
+	}}
+	result, err := s.uniter.WatchActions(args)
+	s.assertOneStringsWatcher(c, result, err)
+}
+
+func (s *uniterSuite) TestActions(c *gc.C) {
+	wpId, err := s.wordpressUnit.AddAction("snapshot", map[string]interface{}{"outfile": "foo"})
+	c.Assert(err, gc.IsNil)
+	mysqlId, err := s.mysqlUnit.AddAction("snapshot", nil)
+	c.Assert(err, gc.IsNil)
+
+	args := params.Actions{Actions: []params.Action{
+		{Id: mysqlId},
+		{Id: wpId},
+		{Id: "u#wordpress/0#a#42"},
+		{Id: "invalid"},
+	}}
+	result, err := s.uniter.Actions(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.ActionResults{
+		Results: []params.ActionResult{
+			{Error: apiservertesting.ErrUnauthorized},
+			{
+				Action: params.Action{
+					Id:     wpId,
+					Unit:   "unit-wordpress-0",
+					Name:   "snapshot",
+					Params: map[string]interface{}{"outfile": "foo"},
+				},
+				Status: params.ActionPending,
+			},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+}
+
+func (s *uniterSuite) TestFinishActions(c *gc.C) {
+	wpId, err := s.wordpressUnit.AddAction("snapshot", nil)
+	c.Assert(err, gc.IsNil)
+	mysqlId, err := s.mysqlUnit.AddAction("snapshot", nil)
+	c.Assert(err, gc.IsNil)
+
+	args := params.ActionResults{Results: []params.ActionResult{{
+		Action: params.Action{Id: mysqlId},
+		Status: params.ActionCompleted,
+	}, {
+		Action:  params.Action{Id: wpId},
+		Status:  params.ActionFailed,
+		Results: map[string]interface{}{"partial": "yes"},
+		Message: "disk full",
+	}}}
+	result, err := s.uniter.FinishActions(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{apiservertesting.ErrUnauthorized},
+			{nil},
+		},
+	})
+
+	pending, err := s.State.UnitActions("mysql/0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(pending, gc.HasLen, 1)
+	finished, err := s.State.ActionResultsForAction(wpId)
+	c.Assert(err, gc.IsNil)
+	c.Assert(finished, gc.HasLen, 1)
+	c.Assert(finished[0].Status(), gc.Equals, state.ActionFailed)
+	c.Assert(finished[0].Results(), gc.DeepEquals, map[string]interface{}{"partial": "yes"})
+	c.Assert(finished[0].Output(), gc.Equals, "disk full")
+}
+
 func (s *uniterSuite) TestCharmArchiveURL(c *gc.C) {
 	dummyCharm := s.AddTestingCharm(c, "dummy")
 
@@ -999,6 +1222,41 @@ func (s *uniterSuite) TestEnterScope(c *gc.C) {
 	})
 }
 
+func (s *uniterSuite) TestRelationPrivateAddress(c *gc.C) {
+	err := s.machine0.SetAddresses(
+		network.NewAddress("1.2.3.4", network.ScopeCloudLocal),
+		network.NewAddress("10.0.1.4", network.ScopeCloudLocal),
+	)
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.AddSubnet(state.SubnetInfo{CIDR: "10.0.1.0/24"})
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.AddSpace("db", []string{"10.0.1.0/24"})
+	c.Assert(err, gc.IsNil)
+
+	rel := s.addRelation(c, "wordpress", "mysql")
+	args := params.RelationUnits{RelationUnits: []params.RelationUnit{
+		{Relation: rel.Tag(), Unit: "unit-wordpress-0"},
+		{Relation: rel.Tag(), Unit: "unit-mysql-0"},
+		{Relation: "relation-42", Unit: "unit-wordpress-0"},
+	}}
+	result, err := s.uniter.RelationPrivateAddress(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.StringResults{
+		Results: []params.StringResult{
+			{Result: "1.2.3.4"},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	// Binding the endpoint to a space changes the address.
+	err = s.wordpress.SetEndpointBindings(map[string]string{"db": "db"})
+	c.Assert(err, gc.IsNil)
+	result, err = s.uniter.RelationPrivateAddress(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Results[0], gc.DeepEquals, params.StringResult{Result: "10.0.1.4"})
+}
+
 func (s *uniterSuite) TestLeaveScope(c *gc.C) {
 	rel := s.addRelation(c, "wordpress", "mysql")
 	relUnit, err := rel.Unit(s.wordpressUnit)
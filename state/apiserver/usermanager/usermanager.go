@@ -21,6 +21,8 @@ var logger = loggo.GetLogger("juju.state.apiserver.usermanager")
 type UserManager interface {
 	AddUser(arg params.ModifyUsers) (params.ErrorResults, error)
 	RemoveUser(arg params.Entities) (params.ErrorResults, error)
+	GrantAccess(arg params.ModifyUserAccesses) (params.ErrorResults, error)
+	RevokeAccess(arg params.ModifyUserAccesses) (params.ErrorResults, error)
 }
 
 // UserManagerAPI implements the user manager interface and is the concrete
@@ -120,6 +122,50 @@ func (api *UserManagerAPI) RemoveUser(args params.Entities) (params.ErrorResults
 	return result, nil
 }
 
+// GrantAccess gives users the requested access level
+// to the environment.
+func (api *UserManagerAPI) GrantAccess(args params.ModifyUserAccesses) (params.ErrorResults, error) {
+	return api.changeAccess(args, api.state.SetUserAccess)
+}
+
+// RevokeAccess takes the requested access level to the
+// environment away from users.
+func (api *UserManagerAPI) RevokeAccess(args params.ModifyUserAccesses) (params.ErrorResults, error) {
+	return api.changeAccess(args, api.state.RevokeUserAccess)
+}
+
+func (api *UserManagerAPI) changeAccess(args params.ModifyUserAccesses, change func(string, state.Access) error) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Changes)),
+	}
+	if len(args.Changes) == 0 {
+		return result, nil
+	}
+	canWrite, err := api.getCanWrite()
+	if err != nil {
+		return result, err
+	}
+	for i, arg := range args.Changes {
+		if !canWrite(arg.Tag) {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		tag, err := names.ParseTag(arg.Tag, names.UserTagKind)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		if _, err := api.state.User(tag.Id()); err != nil {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		if err := change(tag.Id(), state.Access(arg.Access)); err != nil {
+			result.Results[i].Error = common.ServerError(err)
+		}
+	}
+	return result, nil
+}
+
 // UserInfo returns information on a user.
 func (api *UserManagerAPI) UserInfo(args params.Entities) (params.UserInfoResults, error) {
 	results := params.UserInfoResults{
@@ -143,6 +189,10 @@ func (api *UserManagerAPI) UserInfo(args params.Entities) (params.UserInfoResult
 		username := tag.Id()
 
 		user, err := api.state.User(username)
+		var access state.Access
+		if err == nil {
+			access, err = api.state.UserAccess(username)
+		}
 		var result params.UserInfoResult
 		if err != nil {
 			if errors.IsNotFound(err) {
@@ -157,6 +207,7 @@ func (api *UserManagerAPI) UserInfo(args params.Entities) (params.UserInfoResult
 				CreatedBy:      user.CreatedBy(),
 				DateCreated:    user.DateCreated(),
 				LastConnection: user.LastConnection(),
+				Access:         string(access),
 			}
 			result.Result = &info
 		}
@@ -148,6 +148,7 @@ func (s *userManagerSuite) TestUserInfoUsersExist(c *gc.C) {
 					CreatedBy:      "admin",
 					DateCreated:    time.Time{},
 					LastConnection: time.Time{},
+					Access:         "read",
 				},
 			}, {
 				Result: &params.UserInfo{
@@ -156,6 +157,7 @@ func (s *userManagerSuite) TestUserInfoUsersExist(c *gc.C) {
 					CreatedBy:      "admin",
 					DateCreated:    time.Time{},
 					LastConnection: time.Time{},
+					Access:         "read",
 				},
 			}},
 	}
@@ -188,6 +190,7 @@ func (s *userManagerSuite) TestUserInfoUserExists(c *gc.C) {
 					CreatedBy:      "admin",
 					DateCreated:    time.Time{},
 					LastConnection: time.Time{},
+					Access:         "read",
 				},
 			},
 		},
@@ -261,6 +264,49 @@ func (s *userManagerSuite) TestUserInfoNotATagFails(c *gc.C) {
 	c.Assert(results, gc.DeepEquals, expected)
 }
 
+func (s *userManagerSuite) TestGrantAccess(c *gc.C) {
+	userFactory := factory.NewFactory(s.State, c)
+	userFactory.MakeUser(factory.UserParams{Username: "foobar"})
+
+	args := params.ModifyUserAccesses{
+		Changes: []params.ModifyUserAccess{
+			{Tag: "user-foobar", Access: "write"},
+			{Tag: "user-foobar", Access: "superuser"},
+			{Tag: "user-nobody", Access: "read"},
+			{Tag: "machine-0", Access: "read"},
+		}}
+	result, err := s.usermanager.GrantAccess(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Results, gc.HasLen, 4)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+	c.Assert(result.Results[1].Error, gc.ErrorMatches, `cannot set access of user "foobar": access level "superuser" not valid`)
+	c.Assert(result.Results[2].Error, gc.ErrorMatches, "permission denied")
+	c.Assert(result.Results[3].Error, gc.ErrorMatches, `"machine-0" is not a valid user tag`)
+
+	access, err := s.State.UserAccess("foobar")
+	c.Assert(err, gc.IsNil)
+	c.Assert(access, gc.Equals, state.WriteAccess)
+}
+
+func (s *userManagerSuite) TestRevokeAccess(c *gc.C) {
+	userFactory := factory.NewFactory(s.State, c)
+	userFactory.MakeUser(factory.UserParams{Username: "foobar"})
+	err := s.State.SetUserAccess("foobar", state.WriteAccess)
+	c.Assert(err, gc.IsNil)
+
+	args := params.ModifyUserAccesses{
+		Changes: []params.ModifyUserAccess{{Tag: "user-foobar", Access: "write"}},
+	}
+	result, err := s.usermanager.RevokeAccess(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Results, gc.HasLen, 1)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+
+	access, err := s.State.UserAccess("foobar")
+	c.Assert(err, gc.IsNil)
+	c.Assert(access, gc.Equals, state.ReadAccess)
+}
+
 func (s *userManagerSuite) TestAgentUnauthorized(c *gc.C) {
 
 	machine1, err := s.State.AddMachine("quantal", state.JobManageEnviron)
@@ -14,6 +14,7 @@ import (
 
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/instance"
+	"github.com/juju/juju/network"
 	"github.com/juju/juju/state"
 )
 
@@ -949,6 +950,46 @@ func (s *assignCleanSuite) TestAssignUsingConstraintsToMachine(c *gc.C) {
 	}
 }
 
+func (s *assignCleanSuite) TestAssignUsingSpacesConstraint(c *gc.C) {
+	for _, cidr := range []string{"10.0.0.0/24", "10.0.1.0/24"} {
+		_, err := s.State.AddSubnet(state.SubnetInfo{CIDR: cidr})
+		c.Assert(err, gc.IsNil)
+	}
+	_, err := s.State.AddSpace("db", []string{"10.0.0.0/24"})
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.AddSpace("dmz", []string{"10.0.1.0/24"})
+	c.Assert(err, gc.IsNil)
+
+	addMachine := func(instId instance.Id, addr string) *state.Machine {
+		m, err := s.State.AddMachine("quantal", state.JobHostUnits)
+		c.Assert(err, gc.IsNil)
+		if instId != "" {
+			err = m.SetProvisioned(instId, "fake_nonce", nil)
+			c.Assert(err, gc.IsNil)
+			err = m.SetAddresses(network.NewAddress(addr, network.ScopeCloudLocal))
+			c.Assert(err, gc.IsNil)
+		}
+		return m
+	}
+	addMachine("", "")
+	addMachine("inst-dmz", "10.0.1.5")
+	inDB := addMachine("inst-db", "10.0.0.5")
+
+	err = s.wordpress.SetConstraints(constraints.MustParse("spaces=db,^dmz"))
+	c.Assert(err, gc.IsNil)
+	unit, err := s.wordpress.AddUnit()
+	c.Assert(err, gc.IsNil)
+	m, err := s.assignUnit(unit)
+	c.Assert(err, gc.IsNil)
+	c.Assert(m.Id(), gc.Equals, inDB.Id())
+
+	unit, err = s.wordpress.AddUnit()
+	c.Assert(err, gc.IsNil)
+	m, err = s.assignUnit(unit)
+	c.Assert(m, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, eligibleMachinesInUse)
+}
+
 func (s *assignCleanSuite) TestAssignUnitWithRemovedService(c *gc.C) {
 	_, err := s.State.AddMachine("quantal", state.JobManageEnviron) // bootstrap machine
 	c.Assert(err, gc.IsNil)
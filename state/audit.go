@@ -0,0 +1,105 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"labix.org/v2/mgo/bson"
+)
+
+// AuditEntry records a single API call made by a user. Audit entries
+// are stored in a capped collection, so the oldest entries are
+// discarded once the collection is full.
+type AuditEntry struct {
+	// Time holds the time at which the call was made.
+	Time time.Time `bson:"time"`
+
+	// User holds the tag of the user that made the call.
+	User string `bson:"user"`
+
+	// Facade and Method identify the call.
+	Facade string `bson:"facade"`
+	Method string `bson:"method"`
+
+	// Args holds the JSON encoded arguments of the call,
+	// with any secrets redacted.
+	Args string `bson:"args"`
+
+	// Entities holds the names or tags of the entities
+	// mentioned in the arguments of the call.
+	Entities []string `bson:"entities,omitempty"`
+
+	// Error holds the error returned by the call, if any.
+	Error string `bson:"error,omitempty"`
+}
+
+// AuditFilter selects audit entries. The zero value
+// matches all entries.
+type AuditFilter struct {
+	// User, if set, matches entries made by the user with this tag.
+	User string
+
+	// Entity, if set, matches entries mentioning this entity.
+	Entity string
+
+	// After and Before, if set, match entries made at
+	// or after, and before, the given times.
+	After  time.Time
+	Before time.Time
+}
+
+// AddAuditEntry records the given audit entry.
+func (st *State) AddAuditEntry(entry AuditEntry) error {
+	if entry.User == "" {
+		return errors.New("audit entry has no user")
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	// Mongo only stores times to millisecond precision, in UTC.
+	entry.Time = entry.Time.UTC().Round(time.Millisecond)
+	if err := st.audit.Insert(&entry); err != nil {
+		return errors.Annotate(err, "cannot add audit entry")
+	}
+	return nil
+}
+
+// AuditEntries returns the audit entries matching the given filter,
+// oldest first. If limit is greater than zero, at most that many of
+// the most recent matching entries are returned.
+func (st *State) AuditEntries(filter AuditFilter, limit int) ([]AuditEntry, error) {
+	sel := bson.D{}
+	if filter.User != "" {
+		sel = append(sel, bson.DocElem{"user", filter.User})
+	}
+	if filter.Entity != "" {
+		sel = append(sel, bson.DocElem{"entities", filter.Entity})
+	}
+	timeSel := bson.D{}
+	if !filter.After.IsZero() {
+		timeSel = append(timeSel, bson.DocElem{"$gte", filter.After.UTC()})
+	}
+	if !filter.Before.IsZero() {
+		timeSel = append(timeSel, bson.DocElem{"$lt", filter.Before.UTC()})
+	}
+	if len(timeSel) > 0 {
+		sel = append(sel, bson.DocElem{"time", timeSel})
+	}
+	// Entries in a capped collection are held in insertion
+	// order, so we query in reverse to find the most recent.
+	query := st.audit.Find(sel).Sort("-$natural")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	var entries []AuditEntry
+	if err := query.All(&entries); err != nil {
+		return nil, errors.Annotate(err, "cannot get audit entries")
+	}
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
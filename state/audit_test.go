@@ -0,0 +1,128 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state"
+)
+
+type AuditSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&AuditSuite{})
+
+var auditStart = time.Date(2014, 7, 14, 12, 0, 0, 0, time.UTC)
+
+func (s *AuditSuite) addEntries(c *gc.C) {
+	for i, entry := range []state.AuditEntry{{
+		User:     "user-admin",
+		Method:   "ServiceDeploy",
+		Args:     `{"ServiceName":"wordpress"}`,
+		Entities: []string{"wordpress"},
+	}, {
+		User:     "user-bob",
+		Method:   "AddServiceUnits",
+		Args:     `{"ServiceName":"wordpress","NumUnits":2}`,
+		Entities: []string{"wordpress"},
+	}, {
+		User:     "user-admin",
+		Method:   "DestroyMachines",
+		Args:     `{"MachineNames":["1"]}`,
+		Entities: []string{"1"},
+		Error:    `machine 1 has unit "wordpress/0" assigned`,
+	}} {
+		entry.Time = auditStart.Add(time.Duration(i) * time.Minute)
+		entry.Facade = "Client"
+		err := s.State.AddAuditEntry(entry)
+		c.Assert(err, gc.IsNil)
+	}
+}
+
+func auditMethods(entries []state.AuditEntry) []string {
+	var methods []string
+	for _, entry := range entries {
+		methods = append(methods, entry.Method)
+	}
+	return methods
+}
+
+func (s *AuditSuite) TestAddAuditEntry(c *gc.C) {
+	s.addEntries(c)
+	entries, err := s.State.AuditEntries(state.AuditFilter{}, 0)
+	c.Assert(err, gc.IsNil)
+	c.Assert(entries, gc.HasLen, 3)
+	entry := entries[2]
+	c.Assert(entry.Time.Equal(auditStart.Add(2*time.Minute)), jc.IsTrue)
+	c.Assert(entry.User, gc.Equals, "user-admin")
+	c.Assert(entry.Facade, gc.Equals, "Client")
+	c.Assert(entry.Method, gc.Equals, "DestroyMachines")
+	c.Assert(entry.Args, gc.Equals, `{"MachineNames":["1"]}`)
+	c.Assert(entry.Entities, gc.DeepEquals, []string{"1"})
+	c.Assert(entry.Error, gc.Equals, `machine 1 has unit "wordpress/0" assigned`)
+}
+
+func (s *AuditSuite) TestAddAuditEntryNoUser(c *gc.C) {
+	err := s.State.AddAuditEntry(state.AuditEntry{Facade: "Client", Method: "ServiceDeploy"})
+	c.Assert(err, gc.ErrorMatches, "audit entry has no user")
+}
+
+var auditFilterTests = []struct {
+	about   string
+	filter  state.AuditFilter
+	limit   int
+	methods []string
+}{{
+	about:   "all entries",
+	methods: []string{"ServiceDeploy", "AddServiceUnits", "DestroyMachines"},
+}, {
+	about:   "by user",
+	filter:  state.AuditFilter{User: "user-admin"},
+	methods: []string{"ServiceDeploy", "DestroyMachines"},
+}, {
+	about:   "by entity",
+	filter:  state.AuditFilter{Entity: "wordpress"},
+	methods: []string{"ServiceDeploy", "AddServiceUnits"},
+}, {
+	about:   "by user and entity",
+	filter:  state.AuditFilter{User: "user-bob", Entity: "wordpress"},
+	methods: []string{"AddServiceUnits"},
+}, {
+	about:   "after",
+	filter:  state.AuditFilter{After: auditStart.Add(time.Minute)},
+	methods: []string{"AddServiceUnits", "DestroyMachines"},
+}, {
+	about:   "before",
+	filter:  state.AuditFilter{Before: auditStart.Add(time.Minute)},
+	methods: []string{"ServiceDeploy"},
+}, {
+	about: "time range",
+	filter: state.AuditFilter{
+		After:  auditStart.Add(30 * time.Second),
+		Before: auditStart.Add(90 * time.Second),
+	},
+	methods: []string{"AddServiceUnits"},
+}, {
+	about:   "limit returns the most recent",
+	limit:   2,
+	methods: []string{"AddServiceUnits", "DestroyMachines"},
+}, {
+	about:  "no match",
+	filter: state.AuditFilter{User: "user-nobody"},
+}}
+
+func (s *AuditSuite) TestAuditEntriesFilter(c *gc.C) {
+	s.addEntries(c)
+	for i, test := range auditFilterTests {
+		c.Logf("test %d: %s", i, test.about)
+		entries, err := s.State.AuditEntries(test.filter, test.limit)
+		c.Assert(err, gc.IsNil)
+		c.Check(auditMethods(entries), gc.DeepEquals, test.methods)
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/bson"
+	"labix.org/v2/mgo/txn"
+
+	"github.com/juju/juju/state/storage"
+	"github.com/juju/juju/version"
+)
+
+// backupsNamespace is the GridFS namespace in which backup archives
+// are stored.
+const backupsNamespace = "backups"
+
+// BackupMetadata describes a backup archive of a state server.
+type BackupMetadata struct {
+	doc backupMetadataDoc
+}
+
+// backupMetadataDoc is the mongo representation of BackupMetadata.
+type backupMetadataDoc struct {
+	Id             string `bson:"_id"`
+	Started        time.Time
+	Finished       time.Time
+	Checksum       string
+	ChecksumFormat string
+	Size           int64
+	Stored         bool
+	Notes          string
+	Environment    string
+	Machine        string
+	Hostname       string
+	Version        version.Number
+}
+
+// BackupMetadataParams holds the values used to record a new backup.
+type BackupMetadataParams struct {
+	Started        time.Time
+	Finished       time.Time
+	Checksum       string
+	ChecksumFormat string
+	Size           int64
+	Notes          string
+	Environment    string
+	Machine        string
+	Hostname       string
+	Version        version.Number
+}
+
+// Id returns the unique identifier of the backup.
+func (m *BackupMetadata) Id() string {
+	return m.doc.Id
+}
+
+// Started returns the time at which the backup was started.
+func (m *BackupMetadata) Started() time.Time {
+	return m.doc.Started
+}
+
+// Finished returns the time at which the backup archive was completed.
+func (m *BackupMetadata) Finished() time.Time {
+	return m.doc.Finished
+}
+
+// Checksum returns the checksum of the backup archive.
+func (m *BackupMetadata) Checksum() string {
+	return m.doc.Checksum
+}
+
+// ChecksumFormat describes how the checksum was computed.
+func (m *BackupMetadata) ChecksumFormat() string {
+	return m.doc.ChecksumFormat
+}
+
+// Size returns the size of the backup archive in bytes.
+func (m *BackupMetadata) Size() int64 {
+	return m.doc.Size
+}
+
+// Stored reports whether the backup archive has been saved in the
+// backup storage.
+func (m *BackupMetadata) Stored() bool {
+	return m.doc.Stored
+}
+
+// Notes returns the notes given when the backup was requested.
+func (m *BackupMetadata) Notes() string {
+	return m.doc.Notes
+}
+
+// Environment returns the UUID of the environment that was backed up.
+func (m *BackupMetadata) Environment() string {
+	return m.doc.Environment
+}
+
+// Machine returns the id of the state server machine that was
+// backed up.
+func (m *BackupMetadata) Machine() string {
+	return m.doc.Machine
+}
+
+// Hostname returns the host name of the machine that was backed up.
+func (m *BackupMetadata) Hostname() string {
+	return m.doc.Hostname
+}
+
+// Version returns the version of juju that made the backup.
+func (m *BackupMetadata) Version() version.Number {
+	return m.doc.Version
+}
+
+// ArchivePath returns the path of the backup archive within the
+// backup storage.
+func (m *BackupMetadata) ArchivePath() string {
+	return backupArchivePath(m.doc.Id)
+}
+
+func backupArchivePath(id string) string {
+	return id + ".tar.gz"
+}
+
+// newBackupId returns the id to use for a backup started at the given
+// time of the given environment.
+func newBackupId(started time.Time, envUUID string) string {
+	return started.UTC().Format("20060102-150405") + "." + envUUID
+}
+
+// BackupStorage returns the storage holding backup archives.
+func (st *State) BackupStorage() storage.ResourceStorage {
+	return storage.NewGridFS(backupsNamespace, st.db.Session)
+}
+
+// AddBackupMetadata records the metadata of a new backup and returns
+// it. The backup is not marked as stored until SetBackupStored is
+// called.
+func (st *State) AddBackupMetadata(p BackupMetadataParams) (*BackupMetadata, error) {
+	if p.Started.IsZero() {
+		return nil, errors.New("backup start time not set")
+	}
+	if p.Environment == "" {
+		return nil, errors.New("backup environment not set")
+	}
+	doc := backupMetadataDoc{
+		Id:             newBackupId(p.Started, p.Environment),
+		Started:        p.Started,
+		Finished:       p.Finished,
+		Checksum:       p.Checksum,
+		ChecksumFormat: p.ChecksumFormat,
+		Size:           p.Size,
+		Notes:          p.Notes,
+		Environment:    p.Environment,
+		Machine:        p.Machine,
+		Hostname:       p.Hostname,
+		Version:        p.Version,
+	}
+	ops := []txn.Op{{
+		C:      st.backups.Name,
+		Id:     doc.Id,
+		Assert: txn.DocMissing,
+		Insert: &doc,
+	}}
+	if err := st.runTransaction(ops); err == txn.ErrAborted {
+		return nil, errors.AlreadyExistsf("backup %q", doc.Id)
+	} else if err != nil {
+		return nil, errors.Annotate(err, "cannot add backup metadata")
+	}
+	return &BackupMetadata{doc}, nil
+}
+
+// SetBackupStored records that the archive for the backup with the
+// given id has been saved in the backup storage.
+func (st *State) SetBackupStored(id string) error {
+	ops := []txn.Op{{
+		C:      st.backups.Name,
+		Id:     id,
+		Assert: txn.DocExists,
+		Update: bson.D{{"$set", bson.D{{"stored", true}}}},
+	}}
+	if err := st.runTransaction(ops); err == txn.ErrAborted {
+		return errors.NotFoundf("backup %q", id)
+	} else if err != nil {
+		return errors.Annotatef(err, "cannot mark backup %q as stored", id)
+	}
+	return nil
+}
+
+// BackupMetadata returns the metadata of the backup with the given id.
+func (st *State) BackupMetadata(id string) (*BackupMetadata, error) {
+	var doc backupMetadataDoc
+	err := st.backups.FindId(id).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("backup %q", id)
+	} else if err != nil {
+		return nil, errors.Annotatef(err, "cannot get backup %q", id)
+	}
+	return &BackupMetadata{doc}, nil
+}
+
+// AllBackupMetadata returns the metadata of every recorded backup,
+// oldest first.
+func (st *State) AllBackupMetadata() ([]*BackupMetadata, error) {
+	var docs []backupMetadataDoc
+	if err := st.backups.Find(nil).Sort("started").All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get backups")
+	}
+	result := make([]*BackupMetadata, len(docs))
+	for i, doc := range docs {
+		result[i] = &BackupMetadata{doc}
+	}
+	return result, nil
+}
+
+// RemoveBackup removes the backup with the given id, along with its
+// archive if it was stored.
+func (st *State) RemoveBackup(id string) error {
+	meta, err := st.BackupMetadata(id)
+	if err != nil {
+		return err
+	}
+	if meta.Stored() {
+		if err := st.BackupStorage().Remove(meta.ArchivePath()); err != nil {
+			return errors.Annotatef(err, "cannot remove archive for backup %q", id)
+		}
+	}
+	ops := []txn.Op{{
+		C:      st.backups.Name,
+		Id:     id,
+		Remove: true,
+	}}
+	if err := st.runTransaction(ops); err != nil {
+		return errors.Annotatef(err, "cannot remove backup %q", id)
+	}
+	return nil
+}
+
+// ResetBackupTransactions clears the transaction queues of the backup
+// metadata documents. They are needed after the database has been
+// restored from a backup: the backup metadata is kept, but the
+// transactions its documents refer to are lost.
+func (st *State) ResetBackupTransactions() error {
+	_, err := st.backups.UpdateAll(nil, bson.D{{"$set", bson.D{{"txn-queue", []string{}}}}})
+	if err != nil {
+		return errors.Annotate(err, "cannot reset backup transactions")
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The backups package implements the creation and restoration of
+// state server backup archives.
+//
+// A backup archive is a gzipped tarball holding a single top level
+// directory, juju-backup, which contains:
+//
+//	metadata.json   the Metadata describing the backup
+//	root.tar        the state server's configuration files, logs
+//	                and certificates, with their absolute paths
+//	dump/           the output of mongodump
+package backups
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/version"
+)
+
+var logger = loggo.GetLogger("juju.state.backups")
+
+// ChecksumFormat describes how archive checksums are computed.
+const ChecksumFormat = "SHA-1, base64 encoded"
+
+const (
+	archiveRoot      = "juju-backup"
+	metadataFilename = "metadata.json"
+	filesFilename    = "root.tar"
+	dumpDirname      = "dump"
+)
+
+// Paths holds the locations on the state server of the data that is
+// backed up.
+type Paths struct {
+	// DataDir is the juju data directory, usually /var/lib/juju.
+	DataDir string
+
+	// LogDir is the juju log directory, usually /var/log/juju.
+	LogDir string
+}
+
+// Metadata describes the origin of a backup archive. It is stored
+// in the archive so that the archive can be verified on restore.
+type Metadata struct {
+	Started     time.Time
+	Notes       string
+	Environment string
+	Machine     string
+	Hostname    string
+	Version     version.Number
+}
+
+// Archive holds a newly created backup archive.
+type Archive struct {
+	// File holds the archive data. It is a temporary file which is
+	// removed when the Archive is closed.
+	File *os.File
+
+	// Size holds the size of the archive in bytes.
+	Size int64
+
+	// Checksum holds the checksum of the archive,
+	// computed as described by ChecksumFormat.
+	Checksum string
+
+	// Finished holds the time at which the archive was completed.
+	Finished time.Time
+}
+
+// Close removes the archive's data.
+func (a *Archive) Close() error {
+	err := a.File.Close()
+	if removeErr := os.Remove(a.File.Name()); err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// Create builds a backup archive of the state server described by
+// paths and dbInfo, recording meta within it. The database is dumped
+// while it is running, so the archive is consistent with the point
+// in time at which the dump completed.
+func Create(paths Paths, dbInfo DBInfo, meta Metadata) (_ *Archive, err error) {
+	files, err := backupFiles(paths)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot list files to back up")
+	}
+	tempDir, err := ioutil.TempDir("", "juju-backup")
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot create temporary directory")
+	}
+	defer os.RemoveAll(tempDir)
+
+	contentDir := filepath.Join(tempDir, archiveRoot)
+	if err := os.Mkdir(contentDir, 0700); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := writeMetadata(filepath.Join(contentDir, metadataFilename), meta); err != nil {
+		return nil, errors.Annotate(err, "cannot write backup metadata")
+	}
+	if err := writeFilesTar(filepath.Join(contentDir, filesFilename), files); err != nil {
+		return nil, errors.Annotate(err, "cannot archive state server files")
+	}
+	if err := dumpDatabase(dbInfo, filepath.Join(contentDir, dumpDirname)); err != nil {
+		return nil, errors.Annotate(err, "cannot dump database")
+	}
+
+	f, err := ioutil.TempFile("", "juju-backup-archive")
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot create archive file")
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+	hash := sha1.New()
+	if err := writeArchive(io.MultiWriter(f, hash), tempDir, archiveRoot); err != nil {
+		return nil, errors.Annotate(err, "cannot write archive")
+	}
+	size, err := f.Seek(0, 1)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &Archive{
+		File:     f,
+		Size:     size,
+		Checksum: base64.StdEncoding.EncodeToString(hash.Sum(nil)),
+		Finished: time.Now().UTC(),
+	}, nil
+}
+
+// Checksum returns the checksum of the data read from r, computed as
+// described by ChecksumFormat.
+func Checksum(r io.Reader) (string, error) {
+	hash := sha1.New()
+	if _, err := io.Copy(hash, r); err != nil {
+		return "", errors.Trace(err)
+	}
+	return base64.StdEncoding.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Restore restores the state server described by paths and dbInfo
+// from the backup archive read from r. If envUUID is not empty, the
+// archive must have been made from that environment. The state
+// server's agents must be restarted for the restored data to take
+// effect.
+func Restore(r io.Reader, paths Paths, dbInfo DBInfo, envUUID string) error {
+	tempDir, err := ioutil.TempDir("", "juju-restore")
+	if err != nil {
+		return errors.Annotate(err, "cannot create temporary directory")
+	}
+	defer os.RemoveAll(tempDir)
+
+	if err := extractArchive(r, tempDir); err != nil {
+		return errors.Annotate(err, "cannot extract archive")
+	}
+	contentDir := filepath.Join(tempDir, archiveRoot)
+	meta, err := readMetadata(filepath.Join(contentDir, metadataFilename))
+	if err != nil {
+		return errors.Annotate(err, "cannot read backup metadata")
+	}
+	if envUUID != "" && meta.Environment != envUUID {
+		return errors.Errorf("backup is of environment %q, not %q", meta.Environment, envUUID)
+	}
+	logger.Infof("restoring backup of environment %q made at %v", meta.Environment, meta.Started)
+	if err := extractFilesTar(filepath.Join(contentDir, filesFilename), rootDir); err != nil {
+		return errors.Annotate(err, "cannot restore state server files")
+	}
+	if err := restoreDatabase(dbInfo, filepath.Join(contentDir, dumpDirname)); err != nil {
+		return errors.Annotate(err, "cannot restore database")
+	}
+	return nil
+}
+
+func writeMetadata(path string, meta Metadata) error {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+func readMetadata(path string) (*Metadata, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var meta Metadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// writeArchive writes a gzipped tarball of the directory dir, which
+// must be within baseDir, to w. Paths in the tarball are relative to
+// baseDir.
+func writeArchive(w io.Writer, baseDir, dir string) error {
+	gzw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gzw)
+	err := filepath.Walk(filepath.Join(baseDir, dir), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(baseDir, path)
+		if err != nil {
+			return err
+		}
+		return addToTar(tw, path, name, info)
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gzw.Close()
+}
+
+// extractArchive extracts the gzipped tarball read from r into dir.
+func extractArchive(r io.Reader, dir string) error {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gzr.Close()
+	return extractTar(tar.NewReader(gzr), dir)
+}
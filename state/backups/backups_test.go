@@ -0,0 +1,301 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	"labix.org/v2/mgo/bson"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state/backups"
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/version"
+)
+
+type backupsSuite struct {
+	testing.BaseSuite
+
+	root     string
+	paths    backups.Paths
+	dbInfo   backups.DBInfo
+	commands [][]string
+	stdins   []string
+	restored string
+	// restoredFiles and replayed hold the files of the juju
+	// database, and the namespaces of the oplog entries,
+	// found in the dump given to mongorestore.
+	restoredFiles []string
+	replayed      []string
+}
+
+var _ = gc.Suite(&backupsSuite{})
+
+func (s *backupsSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.root = c.MkDir()
+	s.PatchValue(backups.RootDir, s.root)
+	s.PatchValue(backups.InitDir, filepath.Join(s.root, "etc", "init"))
+	s.PatchValue(backups.RsyslogDir, filepath.Join(s.root, "etc", "rsyslog.d"))
+	s.PatchValue(backups.SSHDir, filepath.Join(s.root, "home", "ubuntu", ".ssh"))
+	s.paths = backups.Paths{
+		DataDir: filepath.Join(s.root, "var", "lib", "juju"),
+		LogDir:  filepath.Join(s.root, "var", "log", "juju"),
+	}
+	s.dbInfo = backups.DBInfo{
+		Address:  "localhost:37017",
+		Username: "machine-0",
+		Password: "secret",
+	}
+	s.commands = nil
+	s.stdins = nil
+	s.restored = ""
+	s.restoredFiles = nil
+	s.replayed = nil
+	s.PatchValue(backups.RunCommand, s.runCommand)
+
+	for path, content := range map[string]string{
+		"etc/init/juju-db.conf":                    "juju-db",
+		"etc/init/jujud-machine-0.conf":            "jujud",
+		"etc/init/jujud-unit-wordpress-0.conf":     "unit",
+		"var/lib/juju/agents/machine-0/agent.conf": "agent config",
+		"var/lib/juju/server.pem":                  "cert",
+		"var/lib/juju/system-identity":             "identity",
+		"var/log/juju/machine-0.log":               "log",
+		"var/log/juju/unit-wordpress-0.log":        "unit log",
+	} {
+		s.writeFile(c, path, content)
+	}
+}
+
+func (s *backupsSuite) writeFile(c *gc.C, path, content string) {
+	path = filepath.Join(s.root, path)
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	c.Assert(err, gc.IsNil)
+	err = ioutil.WriteFile(path, []byte(content), 0644)
+	c.Assert(err, gc.IsNil)
+}
+
+// oplogEntries holds the operations recorded in the
+// oplog of the dumps written by the fake mongodump.
+var oplogEntries = []bson.D{
+	{{"op", "i"}, {"ns", "juju.machines"}},
+	{{"op", "i"}, {"ns", "juju.backupsmetadata"}},
+	{{"op", "u"}, {"ns", "juju.units"}},
+	{{"op", "i"}, {"ns", "juju.backups.chunks"}},
+}
+
+// runCommand fakes the mongo tools, writing a dump on mongodump and
+// reading it back on mongorestore.
+func (s *backupsSuite) runCommand(stdin io.Reader, name string, args ...string) error {
+	s.commands = append(s.commands, append([]string{filepath.Base(name)}, args...))
+	input, err := ioutil.ReadAll(stdin)
+	if err != nil {
+		return err
+	}
+	s.stdins = append(s.stdins, string(input))
+	dir := args[len(args)-1]
+	switch filepath.Base(name) {
+	case "mongodump":
+		return writeDump(dir)
+	case "mongorestore":
+		data, err := ioutil.ReadFile(filepath.Join(dir, "juju", "machines.bson"))
+		if err != nil {
+			return err
+		}
+		s.restored = string(data)
+		infos, err := ioutil.ReadDir(filepath.Join(dir, "juju"))
+		if err != nil {
+			return err
+		}
+		for _, info := range infos {
+			s.restoredFiles = append(s.restoredFiles, info.Name())
+		}
+		oplog, err := ioutil.ReadFile(filepath.Join(dir, "oplog.bson"))
+		if err != nil {
+			return err
+		}
+		for len(oplog) > 0 {
+			size := binary.LittleEndian.Uint32(oplog)
+			var entry struct {
+				Namespace string `bson:"ns"`
+			}
+			if err := bson.Unmarshal(oplog[:size], &entry); err != nil {
+				return err
+			}
+			s.replayed = append(s.replayed, entry.Namespace)
+			oplog = oplog[size:]
+		}
+	}
+	return nil
+}
+
+// writeDump writes a fake dump of the database, with an
+// oplog, to dir.
+func writeDump(dir string) error {
+	if err := os.MkdirAll(filepath.Join(dir, "juju"), 0755); err != nil {
+		return err
+	}
+	for name, content := range map[string]string{
+		"juju/machines.bson":                 "db data",
+		"juju/machines.metadata.json":        "{}",
+		"juju/backupsmetadata.bson":          "backups",
+		"juju/backupsmetadata.metadata.json": "{}",
+		"juju/backups.files.bson":            "backup files",
+		"juju/backups.chunks.bson":           "backup chunks",
+		"juju/backups.chunks.metadata.json":  "{}",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	var oplog []byte
+	for _, entry := range oplogEntries {
+		data, err := bson.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		oplog = append(oplog, data...)
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "oplog.bson"), oplog, 0644)
+}
+
+func (s *backupsSuite) metadata() backups.Metadata {
+	return backups.Metadata{
+		Started:     time.Date(2014, 7, 14, 12, 30, 0, 0, time.UTC),
+		Environment: "env-uuid",
+		Machine:     "0",
+		Hostname:    "juju-state-server",
+		Version:     version.Current.Number,
+	}
+}
+
+func (s *backupsSuite) patchMongoTools(c *gc.C) {
+	// Ensure the mongo tools are found regardless of what
+	// is installed on the test machine.
+	binDir := c.MkDir()
+	for _, name := range []string{"mongodump", "mongorestore"} {
+		err := ioutil.WriteFile(filepath.Join(binDir, name), nil, 0755)
+		c.Assert(err, gc.IsNil)
+	}
+	s.PatchEnvironment("PATH", binDir)
+}
+
+func (s *backupsSuite) create(c *gc.C) *backups.Archive {
+	s.patchMongoTools(c)
+	archive, err := backups.Create(s.paths, s.dbInfo, s.metadata())
+	c.Assert(err, gc.IsNil)
+	return archive
+}
+
+func (s *backupsSuite) TestCreate(c *gc.C) {
+	archive := s.create(c)
+	defer archive.Close()
+
+	data, err := ioutil.ReadAll(archive.File)
+	c.Assert(err, gc.IsNil)
+	c.Assert(int64(len(data)), gc.Equals, archive.Size)
+	checksum, err := backups.Checksum(bytes.NewReader(data))
+	c.Assert(err, gc.IsNil)
+	c.Assert(archive.Checksum, gc.Equals, checksum)
+	c.Assert(archive.Finished.IsZero(), jc.IsFalse)
+
+	c.Assert(s.commands, gc.HasLen, 1)
+	c.Assert(s.commands[0][:11], gc.DeepEquals, []string{
+		"mongodump",
+		"--ssl",
+		"--authenticationDatabase", "admin",
+		"--host", "localhost:37017",
+		"--username", "machine-0",
+		"--password",
+		"--oplog",
+		"--out",
+	})
+	// The password is given on standard input, not the command line.
+	c.Assert(s.stdins, gc.DeepEquals, []string{"secret\n"})
+
+	// Closing the archive removes its data.
+	name := archive.File.Name()
+	err = archive.Close()
+	c.Assert(err, gc.IsNil)
+	_, err = os.Stat(name)
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
+
+func (s *backupsSuite) TestCreateNoFiles(c *gc.C) {
+	s.patchMongoTools(c)
+	paths := backups.Paths{DataDir: c.MkDir(), LogDir: c.MkDir()}
+	s.PatchValue(backups.InitDir, c.MkDir())
+	_, err := backups.Create(paths, s.dbInfo, s.metadata())
+	c.Assert(err, gc.ErrorMatches, "cannot list files to back up: no state server files found")
+}
+
+func (s *backupsSuite) TestRestore(c *gc.C) {
+	archive := s.create(c)
+	defer archive.Close()
+
+	// Restore into a fresh root.
+	newRoot := c.MkDir()
+	s.PatchValue(backups.RootDir, newRoot)
+	err := backups.Restore(archive.File, s.paths, s.dbInfo, "env-uuid")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.restored, gc.Equals, "db data")
+	c.Assert(s.commands[1][:10], gc.DeepEquals, []string{
+		"mongorestore",
+		"--ssl",
+		"--authenticationDatabase", "admin",
+		"--host", "localhost:37017",
+		"--username", "machine-0",
+		"--password",
+		"--drop",
+	})
+	c.Assert(s.stdins[1], gc.Equals, "secret\n")
+
+	// The backups collections are neither dropped nor restored,
+	// and the operations on them are not replayed.
+	c.Assert(s.restoredFiles, gc.DeepEquals, []string{"machines.bson", "machines.metadata.json"})
+	c.Assert(s.replayed, gc.DeepEquals, []string{"juju.machines", "juju.units"})
+
+	for path, content := range map[string]string{
+		"etc/init/juju-db.conf":                    "juju-db",
+		"etc/init/jujud-machine-0.conf":            "jujud",
+		"var/lib/juju/agents/machine-0/agent.conf": "agent config",
+		"var/lib/juju/server.pem":                  "cert",
+		"var/lib/juju/system-identity":             "identity",
+		"var/log/juju/machine-0.log":               "log",
+	} {
+		data, err := ioutil.ReadFile(filepath.Join(newRoot, path))
+		c.Check(err, gc.IsNil)
+		c.Check(string(data), gc.Equals, content)
+	}
+	// Unit agents are not backed up.
+	for _, path := range []string{
+		"etc/init/jujud-unit-wordpress-0.conf",
+		"var/log/juju/unit-wordpress-0.log",
+	} {
+		_, err := os.Stat(filepath.Join(newRoot, path))
+		c.Check(err, jc.Satisfies, os.IsNotExist)
+	}
+}
+
+func (s *backupsSuite) TestRestoreWrongEnvironment(c *gc.C) {
+	archive := s.create(c)
+	defer archive.Close()
+
+	err := backups.Restore(archive.File, s.paths, s.dbInfo, "other-uuid")
+	c.Assert(err, gc.ErrorMatches, `backup is of environment "env-uuid", not "other-uuid"`)
+	c.Assert(s.restored, gc.Equals, "")
+}
+
+func (s *backupsSuite) TestRestoreInvalidArchive(c *gc.C) {
+	err := backups.Restore(bytes.NewReader([]byte("not an archive")), s.paths, s.dbInfo, "")
+	c.Assert(err, gc.ErrorMatches, "cannot extract archive: .*")
+}
@@ -0,0 +1,155 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"labix.org/v2/mgo/bson"
+)
+
+// DBInfo holds the information needed to connect to the state
+// server's database.
+type DBInfo struct {
+	// Address is the host:port of the mongo server.
+	Address string
+
+	// Username and Password are the credentials used to connect.
+	Username string
+	Password string
+}
+
+// jujuMongoBinDir holds the juju-bundled mongo tools, which are
+// preferred over any found in $PATH.
+const jujuMongoBinDir = "/usr/lib/juju/bin"
+
+// runCommand runs the given command with the given standard input,
+// returning an error that includes its output if it fails. It is
+// overridden in tests.
+var runCommand = func(stdin io.Reader, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = stdin
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s failed: %v (%s)", filepath.Base(name), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// mongoTool returns the path of the named mongo tool.
+func mongoTool(name string) (string, error) {
+	path := filepath.Join(jujuMongoBinDir, name)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	return exec.LookPath(name)
+}
+
+// connectArgs returns the mongo tool arguments needed to connect
+// to the database described by info. The password is not given on
+// the command line, where any user of the machine could see it;
+// the tools prompt for it, and read it from passwordInput.
+func connectArgs(info DBInfo) []string {
+	return []string{
+		"--ssl",
+		"--authenticationDatabase", "admin",
+		"--host", info.Address,
+		"--username", info.Username,
+		"--password",
+	}
+}
+
+// passwordInput returns the standard input for a mongo tool
+// connecting to the database described by info.
+func passwordInput(info DBInfo) io.Reader {
+	return strings.NewReader(info.Password + "\n")
+}
+
+// dumpDatabase writes a dump of every database, including the oplog
+// entries made while dumping, to dir.
+func dumpDatabase(info DBInfo, dir string) error {
+	mongodump, err := mongoTool("mongodump")
+	if err != nil {
+		return err
+	}
+	args := append(connectArgs(info), "--oplog", "--out", dir)
+	return runCommand(passwordInput(info), mongodump, args...)
+}
+
+// backupsCollections holds the names of the collections in the juju
+// database that hold the backups themselves. They are left out of a
+// restore, so that restoring a backup does not lose the backups made
+// since, or the one being restored.
+var backupsCollections = []string{
+	"backupsmetadata",
+	"backups.files",
+	"backups.chunks",
+}
+
+// restoreDatabase replaces the contents of the database, other than
+// the backups collections, with the dump in dir.
+func restoreDatabase(info DBInfo, dir string) error {
+	mongorestore, err := mongoTool("mongorestore")
+	if err != nil {
+		return err
+	}
+	if err := excludeBackups(dir); err != nil {
+		return fmt.Errorf("cannot exclude backups from restore: %v", err)
+	}
+	args := append(connectArgs(info), "--drop", "--oplogReplay", dir)
+	return runCommand(passwordInput(info), mongorestore, args...)
+}
+
+// excludeBackups removes the backups collections from the dump in
+// dir, so that mongorestore neither drops nor restores them, and
+// removes the operations on them from the dump's oplog, so that they
+// are not replayed.
+func excludeBackups(dir string) error {
+	excluded := make(map[string]bool)
+	for _, name := range backupsCollections {
+		for _, suffix := range []string{".bson", ".metadata.json"} {
+			err := os.Remove(filepath.Join(dir, "juju", name+suffix))
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		excluded["juju."+name] = true
+	}
+	oplogPath := filepath.Join(dir, "oplog.bson")
+	data, err := ioutil.ReadFile(oplogPath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	var kept bytes.Buffer
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return fmt.Errorf("truncated oplog")
+		}
+		size := int(binary.LittleEndian.Uint32(data))
+		if size < 5 || size > len(data) {
+			return fmt.Errorf("truncated oplog")
+		}
+		var entry struct {
+			Namespace string `bson:"ns"`
+		}
+		if err := bson.Unmarshal(data[:size], &entry); err != nil {
+			return fmt.Errorf("cannot parse oplog: %v", err)
+		}
+		if !excluded[entry.Namespace] {
+			kept.Write(data[:size])
+		}
+		data = data[size:]
+	}
+	return ioutil.WriteFile(oplogPath, kept.Bytes(), 0600)
+}
@@ -0,0 +1,12 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups
+
+var (
+	RootDir    = &rootDir
+	InitDir    = &initDir
+	RsyslogDir = &rsyslogDir
+	SSHDir     = &sshDir
+	RunCommand = &runCommand
+)
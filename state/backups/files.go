@@ -0,0 +1,182 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// These are overridden in tests.
+var (
+	rootDir    = "/"
+	initDir    = "/etc/init"
+	rsyslogDir = "/etc/rsyslog.d"
+	sshDir     = "/home/ubuntu/.ssh"
+)
+
+// backupFiles returns the absolute paths of the state server files
+// and directories that are backed up.
+func backupFiles(paths Paths) ([]string, error) {
+	patterns := []string{
+		filepath.Join(initDir, "juju-db.conf"),
+		filepath.Join(initDir, "jujud-machine-*.conf"),
+		filepath.Join(paths.DataDir, "agents", "machine-*"),
+		filepath.Join(paths.DataDir, "tools"),
+		filepath.Join(paths.DataDir, "server.pem"),
+		filepath.Join(paths.DataDir, "system-identity"),
+		filepath.Join(paths.DataDir, "nonce.txt"),
+		filepath.Join(paths.DataDir, "shared-secret"),
+		filepath.Join(sshDir, "authorized_keys"),
+		filepath.Join(rsyslogDir, "*juju.conf"),
+		filepath.Join(paths.LogDir, "all-machines.log"),
+		filepath.Join(paths.LogDir, "machine-*.log"),
+	}
+	var files []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no state server files found")
+	}
+	return files, nil
+}
+
+// writeFilesTar writes a tarball of the given files and directories
+// to the file at path. Entries are named by their absolute paths,
+// without the leading separator, so that they can be restored in
+// place.
+func writeFilesTar(path string, files []string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	tw := tar.NewWriter(f)
+	for _, file := range files {
+		err := filepath.Walk(file, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			name, err := filepath.Rel(rootDir, path)
+			if err != nil {
+				return err
+			}
+			return addToTar(tw, path, name, info)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// extractFilesTar extracts the tarball at path into dir.
+func extractFilesTar(path, dir string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return extractTar(tar.NewReader(f), dir)
+}
+
+// addToTar adds the file or directory at path to tw, naming it name.
+// Symbolic links are stored as links.
+func addToTar(tw *tar.Writer, path, name string, info os.FileInfo) error {
+	var link string
+	if info.Mode()&os.ModeSymlink != 0 {
+		var err error
+		if link, err = os.Readlink(path); err != nil {
+			return err
+		}
+	}
+	hdr, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	hdr.Name = filepath.ToSlash(name)
+	if info.IsDir() {
+		hdr.Name += "/"
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// extractTar writes the contents of tr into dir, preserving file
+// modes. Entries that would be written outside dir are rejected.
+func extractTar(tr *tar.Reader, dir string) error {
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.Clean(filepath.FromSlash(hdr.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("invalid archive entry %q", hdr.Name)
+		}
+		path := filepath.Join(dir, name)
+		mode := os.FileMode(hdr.Mode).Perm()
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, mode); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			os.Remove(path)
+			if err := os.Symlink(hdr.Linkname, path); err != nil {
+				return err
+			}
+		case tar.TypeReg, tar.TypeRegA:
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			if err := writeFile(path, mode, tr); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported archive entry type %q for %q", hdr.Typeflag, hdr.Name)
+		}
+	}
+}
+
+func writeFile(path string, mode os.FileMode, r io.Reader) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, r); err != nil {
+		return err
+	}
+	return f.Close()
+}
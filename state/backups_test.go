@@ -0,0 +1,122 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/version"
+)
+
+type BackupsSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&BackupsSuite{})
+
+func (s *BackupsSuite) metadataParams() state.BackupMetadataParams {
+	started := time.Date(2014, 7, 14, 12, 30, 0, 0, time.UTC)
+	return state.BackupMetadataParams{
+		Started:        started,
+		Finished:       started.Add(time.Minute),
+		Checksum:       "checksum",
+		ChecksumFormat: "SHA-1, base64 encoded",
+		Size:           10,
+		Notes:          "before upgrade",
+		Environment:    "env-uuid",
+		Machine:        "0",
+		Hostname:       "juju-state-server",
+		Version:        version.MustParse("1.20.1"),
+	}
+}
+
+func (s *BackupsSuite) TestAddBackupMetadata(c *gc.C) {
+	p := s.metadataParams()
+	meta, err := s.State.AddBackupMetadata(p)
+	c.Assert(err, gc.IsNil)
+	c.Assert(meta.Id(), gc.Equals, "20140714-123000.env-uuid")
+	c.Assert(meta.ArchivePath(), gc.Equals, "20140714-123000.env-uuid.tar.gz")
+	c.Assert(meta.Stored(), jc.IsFalse)
+
+	meta, err = s.State.BackupMetadata(meta.Id())
+	c.Assert(err, gc.IsNil)
+	c.Assert(meta.Started().Equal(p.Started), jc.IsTrue)
+	c.Assert(meta.Finished().Equal(p.Finished), jc.IsTrue)
+	c.Assert(meta.Checksum(), gc.Equals, p.Checksum)
+	c.Assert(meta.ChecksumFormat(), gc.Equals, p.ChecksumFormat)
+	c.Assert(meta.Size(), gc.Equals, p.Size)
+	c.Assert(meta.Notes(), gc.Equals, p.Notes)
+	c.Assert(meta.Environment(), gc.Equals, p.Environment)
+	c.Assert(meta.Machine(), gc.Equals, p.Machine)
+	c.Assert(meta.Hostname(), gc.Equals, p.Hostname)
+	c.Assert(meta.Version(), gc.Equals, p.Version)
+
+	_, err = s.State.AddBackupMetadata(p)
+	c.Assert(err, jc.Satisfies, errors.IsAlreadyExists)
+}
+
+func (s *BackupsSuite) TestAddBackupMetadataInvalid(c *gc.C) {
+	p := s.metadataParams()
+	p.Started = time.Time{}
+	_, err := s.State.AddBackupMetadata(p)
+	c.Assert(err, gc.ErrorMatches, "backup start time not set")
+
+	p = s.metadataParams()
+	p.Environment = ""
+	_, err = s.State.AddBackupMetadata(p)
+	c.Assert(err, gc.ErrorMatches, "backup environment not set")
+}
+
+func (s *BackupsSuite) TestAllBackupMetadata(c *gc.C) {
+	p := s.metadataParams()
+	later := p
+	later.Started = p.Started.Add(time.Hour)
+	meta1, err := s.State.AddBackupMetadata(later)
+	c.Assert(err, gc.IsNil)
+	meta0, err := s.State.AddBackupMetadata(p)
+	c.Assert(err, gc.IsNil)
+
+	all, err := s.State.AllBackupMetadata()
+	c.Assert(err, gc.IsNil)
+	c.Assert(all, gc.HasLen, 2)
+	c.Assert(all[0].Id(), gc.Equals, meta0.Id())
+	c.Assert(all[1].Id(), gc.Equals, meta1.Id())
+}
+
+func (s *BackupsSuite) TestStoreAndRemoveBackup(c *gc.C) {
+	meta, err := s.State.AddBackupMetadata(s.metadataParams())
+	c.Assert(err, gc.IsNil)
+	data := []byte("archive data")
+	_, err = s.State.BackupStorage().Put(meta.ArchivePath(), bytes.NewReader(data), int64(len(data)))
+	c.Assert(err, gc.IsNil)
+	err = s.State.SetBackupStored(meta.Id())
+	c.Assert(err, gc.IsNil)
+
+	meta, err = s.State.BackupMetadata(meta.Id())
+	c.Assert(err, gc.IsNil)
+	c.Assert(meta.Stored(), jc.IsTrue)
+	r, err := s.State.BackupStorage().Get(meta.ArchivePath())
+	c.Assert(err, gc.IsNil)
+	stored, err := ioutil.ReadAll(r)
+	r.Close()
+	c.Assert(err, gc.IsNil)
+	c.Assert(stored, gc.DeepEquals, data)
+
+	err = s.State.RemoveBackup(meta.Id())
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.BackupMetadata(meta.Id())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.State.BackupStorage().Get(meta.ArchivePath())
+	c.Assert(err, gc.NotNil)
+
+	err = s.State.SetBackupStored(meta.Id())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
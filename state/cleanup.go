@@ -196,7 +196,7 @@ func (st *State) cleanupRemovedUnit(name string) error {
 			return err
 		}
 	}
-	return nil
+	return st.destroyUnitStorage(name)
 }
 
 // cleanupForceDestroyedMachine systematically destroys and removes all entities
@@ -5,7 +5,9 @@ package state
 
 import (
 	charmtesting "github.com/juju/charm/testing"
+	"github.com/juju/errors"
 	gitjujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
 	"labix.org/v2/mgo/bson"
 	"labix.org/v2/mgo/txn"
 	gc "launchpad.net/gocheck"
@@ -103,3 +105,59 @@ func (s *compatSuite) TestGetMachineWithoutRequestedNetworksIsOK(c *gc.C) {
 	c.Assert(err, gc.IsNil)
 	c.Assert(networks, gc.HasLen, 0)
 }
+
+func (s *compatSuite) TestScopeLegacyDocuments(c *gc.C) {
+	machine, err := s.state.AddMachine("quantal", JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	// Before state servers hosted several environments, documents
+	// were keyed by their local ids and had no environment UUID,
+	// and neither did the state servers document. We make the
+	// machine's document look like that here.
+	machines := s.state.db.C("machines")
+	var doc bson.D
+	err = machines.FindId(s.state.docID(machine.Id())).One(&doc)
+	c.Assert(err, gc.IsNil)
+	legacy := bson.D{{"_id", machine.Id()}}
+	for _, field := range doc {
+		if field.Name != "_id" && field.Name != envUUIDField {
+			legacy = append(legacy, field)
+		}
+	}
+	err = machines.Insert(legacy)
+	c.Assert(err, gc.IsNil)
+	err = machines.RemoveId(s.state.docID(machine.Id()))
+	c.Assert(err, gc.IsNil)
+	err = s.state.db.C("stateServers").UpdateId(environGlobalKey, bson.D{{"$unset", bson.D{{envUUIDField, 1}}}})
+	c.Assert(err, gc.IsNil)
+
+	// Opening the state leaves the documents alone, so
+	// the machine cannot be found until they are scoped.
+	st, err := Open(TestingStateInfo(), TestingDialOpts(), Policy(nil))
+	c.Assert(err, gc.IsNil)
+	defer st.Close()
+	c.Assert(st.IsHosted(), gc.Equals, false)
+	_, err = st.Machine(machine.Id())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	var info stateServersDoc
+	err = st.db.C("stateServers").FindId(environGlobalKey).One(&info)
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.EnvUUID, gc.Equals, "")
+
+	err = st.ScopeLegacyDocuments()
+	c.Assert(err, gc.IsNil)
+	m, err := st.Machine(machine.Id())
+	c.Assert(err, gc.IsNil)
+	c.Assert(m.Series(), gc.Equals, "quantal")
+	n, err := machines.FindId(machine.Id()).Count()
+	c.Assert(err, gc.IsNil)
+	c.Assert(n, gc.Equals, 0)
+
+	var scopedInfo stateServersDoc
+	err = st.db.C("stateServers").FindId(environGlobalKey).One(&scopedInfo)
+	c.Assert(err, gc.IsNil)
+	c.Assert(scopedInfo.EnvUUID, gc.Equals, s.env.UUID())
+
+	// Once the documents have been scoped, it does nothing.
+	err = st.ScopeLegacyDocuments()
+	c.Assert(err, gc.IsNil)
+}
@@ -7,7 +7,6 @@ import (
 	stdtesting "testing"
 
 	gitjujutesting "github.com/juju/testing"
-	"labix.org/v2/mgo"
 	gc "launchpad.net/gocheck"
 
 	"github.com/juju/juju/state"
@@ -26,13 +25,13 @@ func TestPackage(t *stdtesting.T) {
 type ConnSuite struct {
 	gitjujutesting.MgoSuite
 	testing.BaseSuite
-	annotations  *mgo.Collection
-	charms       *mgo.Collection
-	machines     *mgo.Collection
-	relations    *mgo.Collection
-	services     *mgo.Collection
-	units        *mgo.Collection
-	stateServers *mgo.Collection
+	annotations  state.Collection
+	charms       state.Collection
+	machines     state.Collection
+	relations    state.Collection
+	services     state.Collection
+	units        state.Collection
+	stateServers state.Collection
 	State        *state.State
 	policy       statetesting.MockPolicy
 	factory      *factory.Factory
@@ -53,13 +52,13 @@ func (cs *ConnSuite) SetUpTest(c *gc.C) {
 	cs.MgoSuite.SetUpTest(c)
 	cs.policy = statetesting.MockPolicy{}
 	cs.State = state.TestingInitialize(c, nil, &cs.policy)
-	cs.annotations = cs.MgoSuite.Session.DB("juju").C("annotations")
-	cs.charms = cs.MgoSuite.Session.DB("juju").C("charms")
-	cs.machines = cs.MgoSuite.Session.DB("juju").C("machines")
-	cs.relations = cs.MgoSuite.Session.DB("juju").C("relations")
-	cs.services = cs.MgoSuite.Session.DB("juju").C("services")
-	cs.units = cs.MgoSuite.Session.DB("juju").C("units")
-	cs.stateServers = cs.MgoSuite.Session.DB("juju").C("stateServers")
+	cs.annotations = state.GetCollection(cs.State, "annotations")
+	cs.charms = state.GetCollection(cs.State, "charms")
+	cs.machines = state.GetCollection(cs.State, "machines")
+	cs.relations = state.GetCollection(cs.State, "relations")
+	cs.services = state.GetCollection(cs.State, "services")
+	cs.units = state.GetCollection(cs.State, "units")
+	cs.stateServers = state.GetCollection(cs.State, "stateServers")
 	cs.State.AddAdminUser("pass")
 	cs.factory = factory.NewFactory(cs.State, c)
 }
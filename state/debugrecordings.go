@@ -0,0 +1,212 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/bson"
+	"labix.org/v2/mgo/txn"
+
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/storage"
+)
+
+// debugRecordingsNamespace is the GridFS namespace in which the
+// transcripts of debug-hooks sessions are stored.
+const debugRecordingsNamespace = "debugrecordings"
+
+// DebugRecording describes the recording of a debug-hooks session
+// in which a hook was run interactively on a unit.
+type DebugRecording struct {
+	doc debugRecordingDoc
+}
+
+// debugRecordingDoc is the mongo representation of DebugRecording.
+type debugRecordingDoc struct {
+	Id             string `bson:"_id"`
+	EnvUUID        string `bson:"env-uuid"`
+	Unit           string
+	Hook           string
+	Started        time.Time
+	Finished       time.Time
+	TranscriptSize int64
+	TimingSize     int64
+}
+
+// DebugRecordingParams holds the values used to record a new
+// debug-hooks session.
+type DebugRecordingParams struct {
+	Unit     string
+	Hook     string
+	Started  time.Time
+	Finished time.Time
+}
+
+// Id returns the unique identifier of the recording.
+func (r *DebugRecording) Id() string {
+	return r.doc.Id
+}
+
+// Unit returns the name of the unit that was debugged.
+func (r *DebugRecording) Unit() string {
+	return r.doc.Unit
+}
+
+// Hook returns the name of the hook that was run in the session.
+func (r *DebugRecording) Hook() string {
+	return r.doc.Hook
+}
+
+// Started returns the time at which the session started.
+func (r *DebugRecording) Started() time.Time {
+	return r.doc.Started
+}
+
+// Finished returns the time at which the session ended.
+func (r *DebugRecording) Finished() time.Time {
+	return r.doc.Finished
+}
+
+// Size returns the size of the session transcript in bytes.
+func (r *DebugRecording) Size() int64 {
+	return r.doc.TranscriptSize
+}
+
+// TranscriptPath returns the path of the session transcript within
+// the debug recording storage. The storage is shared by all the
+// environments of a state server, so the path includes the UUID
+// of the recording's environment.
+func (r *DebugRecording) TranscriptPath() string {
+	return path.Join(r.doc.EnvUUID, r.doc.Id+".transcript")
+}
+
+// TimingPath returns the path within the debug recording storage of
+// the timings of the session transcript, in the format written by
+// script(1) and read by scriptreplay(1).
+func (r *DebugRecording) TimingPath() string {
+	return path.Join(r.doc.EnvUUID, r.doc.Id+".timing")
+}
+
+// DebugRecordingStorage returns the storage holding the transcripts
+// of debug-hooks sessions.
+func (st *State) DebugRecordingStorage() storage.ResourceStorage {
+	return storage.NewGridFS(debugRecordingsNamespace, st.db.Session)
+}
+
+// AddDebugRecording stores the transcript and timings of a recorded
+// debug-hooks session, and returns the recording. Neither may be
+// larger than params.MaxDebugRecordingSize.
+func (st *State) AddDebugRecording(p DebugRecordingParams, transcript, timing []byte) (_ *DebugRecording, err error) {
+	defer errors.Maskf(&err, "cannot add debug recording for unit %q", p.Unit)
+	if !names.IsUnit(p.Unit) {
+		return nil, fmt.Errorf("invalid unit name")
+	}
+	if p.Hook == "" {
+		return nil, fmt.Errorf("hook name not set")
+	}
+	if p.Started.IsZero() {
+		return nil, fmt.Errorf("start time not set")
+	}
+	if len(transcript) > params.MaxDebugRecordingSize {
+		return nil, fmt.Errorf("transcript larger than %d bytes", params.MaxDebugRecordingSize)
+	}
+	if len(timing) > params.MaxDebugRecordingSize {
+		return nil, fmt.Errorf("timing larger than %d bytes", params.MaxDebugRecordingSize)
+	}
+	seq, err := st.sequence("debugrecording")
+	if err != nil {
+		return nil, err
+	}
+	recording := &DebugRecording{debugRecordingDoc{
+		Id:             strconv.Itoa(seq),
+		EnvUUID:        st.envUUID,
+		Unit:           p.Unit,
+		Hook:           p.Hook,
+		Started:        p.Started.UTC(),
+		Finished:       p.Finished.UTC(),
+		TranscriptSize: int64(len(transcript)),
+		TimingSize:     int64(len(timing)),
+	}}
+	// The data is stored before the recording is, so that
+	// every recording listed can be downloaded.
+	store := st.DebugRecordingStorage()
+	if _, err := store.Put(recording.TranscriptPath(), bytes.NewReader(transcript), int64(len(transcript))); err != nil {
+		return nil, err
+	}
+	if _, err := store.Put(recording.TimingPath(), bytes.NewReader(timing), int64(len(timing))); err != nil {
+		return nil, err
+	}
+	ops := []txn.Op{{
+		C:      st.debugRecordings.Name,
+		Id:     recording.doc.Id,
+		Assert: txn.DocMissing,
+		Insert: &recording.doc,
+	}}
+	if err := st.runTransaction(ops); err != nil {
+		return nil, err
+	}
+	return recording, nil
+}
+
+// DebugRecording returns the debug-hooks recording with the given id.
+func (st *State) DebugRecording(id string) (*DebugRecording, error) {
+	var doc debugRecordingDoc
+	err := st.debugRecordings.FindId(id).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("debug recording %q", id)
+	} else if err != nil {
+		return nil, errors.Annotatef(err, "cannot get debug recording %q", id)
+	}
+	return &DebugRecording{doc}, nil
+}
+
+// DebugRecordings returns the debug-hooks recordings of the unit with
+// the given name, or of all units if the name is empty, oldest first.
+func (st *State) DebugRecordings(unitName string) ([]*DebugRecording, error) {
+	var query bson.D
+	if unitName != "" {
+		query = bson.D{{"unit", unitName}}
+	}
+	var docs []debugRecordingDoc
+	if err := st.debugRecordings.Find(query).Sort("started").All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get debug recordings")
+	}
+	result := make([]*DebugRecording, len(docs))
+	for i, doc := range docs {
+		result[i] = &DebugRecording{doc}
+	}
+	return result, nil
+}
+
+// DebugRecordingData returns the transcript and timings of the
+// given recording.
+func (st *State) DebugRecordingData(r *DebugRecording) (transcript, timing []byte, err error) {
+	defer errors.Maskf(&err, "cannot get data for debug recording %q", r.Id())
+	store := st.DebugRecordingStorage()
+	if transcript, err = readResource(store, r.TranscriptPath()); err != nil {
+		return nil, nil, err
+	}
+	if timing, err = readResource(store, r.TimingPath()); err != nil {
+		return nil, nil, err
+	}
+	return transcript, timing, nil
+}
+
+func readResource(store storage.ResourceStorage, path string) ([]byte, error) {
+	r, err := store.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
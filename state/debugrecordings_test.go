@@ -0,0 +1,104 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+)
+
+type DebugRecordingsSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&DebugRecordingsSuite{})
+
+func (s *DebugRecordingsSuite) recordingParams(unitName, hookName string, started time.Time) state.DebugRecordingParams {
+	return state.DebugRecordingParams{
+		Unit:     unitName,
+		Hook:     hookName,
+		Started:  started,
+		Finished: started.Add(time.Minute),
+	}
+}
+
+func (s *DebugRecordingsSuite) TestAddDebugRecording(c *gc.C) {
+	started := time.Date(2014, 7, 14, 12, 30, 0, 0, time.UTC)
+	p := s.recordingParams("wordpress/0", "install", started)
+	recording, err := s.State.AddDebugRecording(p, []byte("$ ls\n"), []byte("0.5 5\n"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(recording.Id(), gc.Equals, "0")
+
+	recording, err = s.State.DebugRecording(recording.Id())
+	c.Assert(err, gc.IsNil)
+	c.Assert(recording.Unit(), gc.Equals, "wordpress/0")
+	c.Assert(recording.Hook(), gc.Equals, "install")
+	c.Assert(recording.Started().Equal(p.Started), jc.IsTrue)
+	c.Assert(recording.Finished().Equal(p.Finished), jc.IsTrue)
+	c.Assert(recording.Size(), gc.Equals, int64(5))
+
+	transcript, timing, err := s.State.DebugRecordingData(recording)
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(transcript), gc.Equals, "$ ls\n")
+	c.Assert(string(timing), gc.Equals, "0.5 5\n")
+}
+
+func (s *DebugRecordingsSuite) TestAddDebugRecordingInvalid(c *gc.C) {
+	started := time.Now()
+	_, err := s.State.AddDebugRecording(s.recordingParams("wordpress", "install", started), nil, nil)
+	c.Assert(err, gc.ErrorMatches, `cannot add debug recording for unit "wordpress": invalid unit name`)
+	_, err = s.State.AddDebugRecording(s.recordingParams("wordpress/0", "", started), nil, nil)
+	c.Assert(err, gc.ErrorMatches, `cannot add debug recording for unit "wordpress/0": hook name not set`)
+	_, err = s.State.AddDebugRecording(s.recordingParams("wordpress/0", "install", time.Time{}), nil, nil)
+	c.Assert(err, gc.ErrorMatches, `cannot add debug recording for unit "wordpress/0": start time not set`)
+}
+
+func (s *DebugRecordingsSuite) TestAddDebugRecordingTooLarge(c *gc.C) {
+	p := s.recordingParams("wordpress/0", "install", time.Now())
+	large := make([]byte, params.MaxDebugRecordingSize+1)
+	_, err := s.State.AddDebugRecording(p, large, nil)
+	c.Assert(err, gc.ErrorMatches, `cannot add debug recording for unit "wordpress/0": transcript larger than 16777216 bytes`)
+	_, err = s.State.AddDebugRecording(p, nil, large)
+	c.Assert(err, gc.ErrorMatches, `cannot add debug recording for unit "wordpress/0": timing larger than 16777216 bytes`)
+	recordings, err := s.State.DebugRecordings("wordpress/0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(recordings, gc.HasLen, 0)
+}
+
+func (s *DebugRecordingsSuite) TestDebugRecordingNotFound(c *gc.C) {
+	_, err := s.State.DebugRecording("42")
+	c.Assert(err, gc.ErrorMatches, `debug recording "42" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *DebugRecordingsSuite) TestDebugRecordings(c *gc.C) {
+	started := time.Date(2014, 7, 14, 12, 30, 0, 0, time.UTC)
+	for i, unitName := range []string{"mysql/0", "wordpress/0", "mysql/0"} {
+		p := s.recordingParams(unitName, "config-changed", started.Add(time.Duration(-i)*time.Hour))
+		_, err := s.State.AddDebugRecording(p, nil, nil)
+		c.Assert(err, gc.IsNil)
+	}
+	ids := func(recordings []*state.DebugRecording) []string {
+		var ids []string
+		for _, r := range recordings {
+			ids = append(ids, r.Id())
+		}
+		return ids
+	}
+	all, err := s.State.DebugRecordings("")
+	c.Assert(err, gc.IsNil)
+	c.Assert(ids(all), gc.DeepEquals, []string{"2", "1", "0"})
+	mysql, err := s.State.DebugRecordings("mysql/0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(ids(mysql), gc.DeepEquals, []string{"2", "0"})
+	none, err := s.State.DebugRecordings("mysql/1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(none, gc.HasLen, 0)
+}
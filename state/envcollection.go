@@ -0,0 +1,631 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"encoding/binary"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/set"
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/bson"
+	"labix.org/v2/mgo/txn"
+
+	"github.com/juju/juju/state/watcher"
+)
+
+// envUUIDField holds the name of the field recording the UUID of
+// the environment a document belongs to.
+const envUUIDField = "env-uuid"
+
+// envScopedCollections holds the names of the collections whose
+// documents each belong to a single environment. All the
+// environments hosted by a state server share these collections:
+// every document records the UUID of its environment in its
+// env-uuid field and, when its _id is a string, has an _id
+// prefixed by the UUID, so that the same key can be used by
+// several environments.
+//
+// The prefix is added and removed by stateCollection and
+// runTransaction, so that the rest of the package deals only in
+// the keys local to an environment. Watchers see the prefixed
+// ids and must use docID and localID to convert them. Agent
+// presence keys are prefixed in the same way, since all the
+// environments also share the presence collection.
+var envScopedCollections = set.NewStrings(
+	"actionresults",
+	"actions",
+	"annotations",
+	"audit",
+	"charms",
+	"cleanups",
+	"constraints",
+	"containerRefs",
+	"debugrecordings",
+	"envusers",
+	"instanceData",
+	"leases",
+	"machines",
+	"metrics",
+	"minunits",
+	"networkinterfaces",
+	"networks",
+	"relations",
+	"relationscopes",
+	"remoteservices",
+	"requestednetworks",
+	"sequence",
+	"services",
+	"settings",
+	"settingsrefs",
+	"spaces",
+	"statuses",
+	"storageconstraints",
+	"storageinstances",
+	"subnets",
+	"units",
+	"volumeattachments",
+	"volumes",
+	"workloadstatuses",
+)
+
+// docID returns the _id of the document with the given
+// environment-local key in an environment-scoped collection.
+func (st *State) docID(localID string) string {
+	return st.envUUID + ":" + localID
+}
+
+// localID returns the environment-local key of the document
+// with the given _id in an environment-scoped collection.
+func (st *State) localID(docID string) string {
+	return strings.TrimPrefix(docID, st.envUUID+":")
+}
+
+// isForEnv reports whether the given document _id, as seen by a
+// watcher of an environment-scoped collection, belongs to a
+// document of the State's environment. Ids that are not strings
+// are not prefixed, so they cannot be told apart and are all
+// reported as belonging to the environment.
+func (st *State) isForEnv(id interface{}) bool {
+	docID, ok := id.(string)
+	return !ok || strings.HasPrefix(docID, st.envUUID+":")
+}
+
+// watchID returns the id by which the txn watcher knows the
+// document with the given key in the named collection.
+func (st *State) watchID(coll string, key interface{}) interface{} {
+	if id, ok := stringID(key); ok && envScopedCollections.Contains(coll) {
+		return st.docID(id)
+	}
+	return key
+}
+
+// changeKey returns the key, local to the State's environment,
+// of the document whose id is reported by a txn watcher of the
+// named collection.
+func (st *State) changeKey(coll string, id interface{}) interface{} {
+	if docID, ok := id.(string); ok && envScopedCollections.Contains(coll) {
+		return st.localID(docID)
+	}
+	return id
+}
+
+// watchFilter returns a filter for a txn watcher of the named
+// collection that passes only changes to the documents of the
+// State's environment that are also passed by filter, if it is
+// not nil. The filter is called with the documents' local keys.
+func (st *State) watchFilter(coll string, filter func(interface{}) bool) func(interface{}) bool {
+	if !envScopedCollections.Contains(coll) {
+		return filter
+	}
+	return func(id interface{}) bool {
+		if !st.isForEnv(id) {
+			return false
+		}
+		return filter == nil || filter(st.changeKey(coll, id))
+	}
+}
+
+// watchDoc is like watcher.Watcher.Watch, but takes the
+// environment-local key of a document.
+func (st *State) watchDoc(coll string, key interface{}, revno int64, ch chan<- watcher.Change) {
+	st.watcher.Watch(coll, st.watchID(coll, key), revno, ch)
+}
+
+// unwatchDoc is like watcher.Watcher.Unwatch, but takes the
+// environment-local key of a document.
+func (st *State) unwatchDoc(coll string, key interface{}, ch chan<- watcher.Change) {
+	st.watcher.Unwatch(coll, st.watchID(coll, key), ch)
+}
+
+// watchCollection is like watcher.Watcher.WatchCollectionWithFilter,
+// but reports only changes to the documents of the State's
+// environment, and calls filter with their local keys. The
+// changes sent on ch still hold the ids of the documents, which
+// must be converted with changeKey.
+func (st *State) watchCollection(coll string, ch chan<- watcher.Change, filter func(interface{}) bool) {
+	st.watcher.WatchCollectionWithFilter(coll, ch, st.watchFilter(coll, filter))
+}
+
+// stateCollection wraps a collection used by a State. Queries
+// on an environment-scoped collection see only the documents of
+// the State's environment, and the _ids of the documents read
+// are returned without their environment prefix. Queries on
+// other collections are passed through unchanged.
+//
+// Only the methods defined here are scoped; the other methods of
+// the embedded collection, such as Upsert, must not be used on an
+// environment-scoped collection.
+type stateCollection struct {
+	*mgo.Collection
+	st     *State
+	scoped bool
+}
+
+// newStateCollection returns the named collection in db, as used by st.
+func newStateCollection(st *State, db *mgo.Database, name string) *stateCollection {
+	return &stateCollection{
+		Collection: db.C(name),
+		st:         st,
+		scoped:     envScopedCollections.Contains(name),
+	}
+}
+
+// Find is like mgo.Collection.Find.
+func (c *stateCollection) Find(query interface{}) *stateQuery {
+	return &stateQuery{
+		Query: c.Collection.Find(c.scopeQuery(query)),
+		coll:  c,
+	}
+}
+
+// FindId is like mgo.Collection.FindId.
+func (c *stateCollection) FindId(id interface{}) *stateQuery {
+	if !c.scoped {
+		return &stateQuery{Query: c.Collection.FindId(id), coll: c}
+	}
+	return c.Find(bson.D{{"_id", id}})
+}
+
+// Count is like mgo.Collection.Count.
+func (c *stateCollection) Count() (int, error) {
+	if !c.scoped {
+		return c.Collection.Count()
+	}
+	return c.Find(nil).Count()
+}
+
+// Insert is like mgo.Collection.Insert.
+func (c *stateCollection) Insert(docs ...interface{}) error {
+	if !c.scoped {
+		return c.Collection.Insert(docs...)
+	}
+	scoped := make([]interface{}, len(docs))
+	for i, doc := range docs {
+		var err error
+		if scoped[i], err = c.st.scopeDoc(nil, doc); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return c.Collection.Insert(scoped...)
+}
+
+// Update is like mgo.Collection.Update.
+func (c *stateCollection) Update(selector, update interface{}) error {
+	return c.Collection.Update(c.scopeQuery(selector), update)
+}
+
+// UpdateId is like mgo.Collection.UpdateId.
+func (c *stateCollection) UpdateId(id, update interface{}) error {
+	if !c.scoped {
+		return c.Collection.UpdateId(id, update)
+	}
+	return c.Update(bson.D{{"_id", id}}, update)
+}
+
+// UpdateAll is like mgo.Collection.UpdateAll.
+func (c *stateCollection) UpdateAll(selector, update interface{}) (*mgo.ChangeInfo, error) {
+	return c.Collection.UpdateAll(c.scopeQuery(selector), update)
+}
+
+// Remove is like mgo.Collection.Remove.
+func (c *stateCollection) Remove(selector interface{}) error {
+	return c.Collection.Remove(c.scopeQuery(selector))
+}
+
+// RemoveId is like mgo.Collection.RemoveId.
+func (c *stateCollection) RemoveId(id interface{}) error {
+	if !c.scoped {
+		return c.Collection.RemoveId(id)
+	}
+	return c.Remove(bson.D{{"_id", id}})
+}
+
+// RemoveAll is like mgo.Collection.RemoveAll.
+func (c *stateCollection) RemoveAll(selector interface{}) (*mgo.ChangeInfo, error) {
+	return c.Collection.RemoveAll(c.scopeQuery(selector))
+}
+
+// scopeQuery returns the given query restricted to the documents
+// of the State's environment, with any conditions on _id changed
+// to match the prefixed ids.
+func (c *stateCollection) scopeQuery(query interface{}) interface{} {
+	if !c.scoped {
+		return query
+	}
+	envCond := bson.DocElem{envUUIDField, c.st.envUUID}
+	switch query := query.(type) {
+	case nil:
+		return bson.D{envCond}
+	case bson.D:
+		return append(c.st.scopeQueryDoc(query), envCond)
+	case bson.M:
+		scoped := c.st.scopeQueryMap(query)
+		scoped[envUUIDField] = c.st.envUUID
+		return scoped
+	}
+	return bson.D{{"$and", []interface{}{query, bson.D{envCond}}}}
+}
+
+// scopeQueryDoc returns a copy of the given query document with
+// any conditions on _id changed to match prefixed ids.
+func (st *State) scopeQueryDoc(query bson.D) bson.D {
+	scoped := make(bson.D, len(query), len(query)+1)
+	for i, elem := range query {
+		scoped[i] = bson.DocElem{elem.Name, st.scopeQueryElem(elem.Name, elem.Value)}
+	}
+	return scoped
+}
+
+// scopeQueryMap is the bson.M equivalent of scopeQueryDoc.
+func (st *State) scopeQueryMap(query bson.M) bson.M {
+	scoped := make(bson.M, len(query)+1)
+	for name, value := range query {
+		scoped[name] = st.scopeQueryElem(name, value)
+	}
+	return scoped
+}
+
+// scopeQueryElem returns the value of the named query element
+// changed to match prefixed ids where necessary.
+func (st *State) scopeQueryElem(name string, value interface{}) interface{} {
+	switch name {
+	case "_id":
+		return st.scopeIdCond(value)
+	case "$and", "$or", "$nor":
+		return st.scopeSubQueries(value)
+	}
+	return value
+}
+
+// scopeSubQueries changes the queries in the value of an $and,
+// $or or $nor element to match prefixed ids.
+func (st *State) scopeSubQueries(value interface{}) interface{} {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice {
+		return value
+	}
+	scoped := make([]interface{}, v.Len())
+	for i := range scoped {
+		switch query := v.Index(i).Interface().(type) {
+		case bson.D:
+			scoped[i] = st.scopeQueryDoc(query)
+		case bson.M:
+			scoped[i] = st.scopeQueryMap(query)
+		default:
+			scoped[i] = query
+		}
+	}
+	return scoped
+}
+
+// scopeIdCond changes a condition on _id to match prefixed ids.
+// Conditions on ids that are not strings are left alone.
+func (st *State) scopeIdCond(cond interface{}) interface{} {
+	switch cond := cond.(type) {
+	case bson.D:
+		scoped := make(bson.D, len(cond))
+		for i, elem := range cond {
+			scoped[i] = bson.DocElem{elem.Name, st.scopeIdOperand(elem.Name, elem.Value)}
+		}
+		return scoped
+	case bson.M:
+		scoped := make(bson.M, len(cond))
+		for op, operand := range cond {
+			scoped[op] = st.scopeIdOperand(op, operand)
+		}
+		return scoped
+	}
+	return st.scopeIdValue(cond)
+}
+
+// scopeIdOperand changes the operand of the given query operator
+// in a condition on _id to match prefixed ids.
+func (st *State) scopeIdOperand(op string, operand interface{}) interface{} {
+	switch op {
+	case "$regex":
+		if pattern, ok := operand.(string); ok {
+			return st.scopeIdRegex(pattern)
+		}
+	case "$in", "$nin":
+		v := reflect.ValueOf(operand)
+		if v.Kind() != reflect.Slice {
+			return operand
+		}
+		scoped := make([]interface{}, v.Len())
+		for i := range scoped {
+			scoped[i] = st.scopeIdValue(v.Index(i).Interface())
+		}
+		return scoped
+	case "$ne", "$gt", "$gte", "$lt", "$lte":
+		return st.scopeIdValue(operand)
+	}
+	return operand
+}
+
+// scopeIdValue returns the prefixed id for the given value,
+// if it is stored as a string.
+func (st *State) scopeIdValue(value interface{}) interface{} {
+	if id, ok := stringID(value); ok {
+		return st.docID(id)
+	}
+	return value
+}
+
+// scopeIdRegex changes a regular expression matching local ids
+// to match the corresponding prefixed ids.
+func (st *State) scopeIdRegex(pattern string) string {
+	prefix := "^" + regexp.QuoteMeta(st.docID(""))
+	if strings.HasPrefix(pattern, "^") {
+		return prefix + pattern[1:]
+	}
+	return prefix + ".*(?:" + pattern + ")"
+}
+
+// stringID returns the string that the given document id is
+// stored as, if it is stored as a string. Ids such as charm URLs
+// are held in values that are stored as strings.
+func stringID(id interface{}) (string, bool) {
+	switch id := id.(type) {
+	case string:
+		return id, true
+	case bson.Getter:
+		if v, err := id.GetBSON(); err == nil {
+			s, ok := v.(string)
+			return s, ok
+		}
+	}
+	return "", false
+}
+
+// decode unmarshals into result the given document read from
+// the collection, after removing the environment prefix from its
+// _id. The document must be decoded without its prefix, as ids
+// such as charm URLs cannot otherwise be parsed.
+func (c *stateCollection) decode(doc bson.RawD, result interface{}) error {
+	for i, elem := range doc {
+		if elem.Name != "_id" || elem.Value.Kind != bsonString {
+			continue
+		}
+		var id string
+		if err := elem.Value.Unmarshal(&id); err != nil {
+			return err
+		}
+		doc[i].Value = rawString(c.st.localID(id))
+	}
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(data, result)
+}
+
+// bsonString is the kind of a BSON string value.
+const bsonString = 0x02
+
+// rawString returns the BSON encoding of s.
+func rawString(s string) bson.Raw {
+	data := make([]byte, 4, 4+len(s)+1)
+	binary.LittleEndian.PutUint32(data, uint32(len(s)+1))
+	data = append(data, s...)
+	return bson.Raw{Kind: bsonString, Data: append(data, 0)}
+}
+
+// stateQuery wraps a query on a stateCollection, removing the
+// environment prefix from the _ids of the documents it returns.
+type stateQuery struct {
+	*mgo.Query
+	coll *stateCollection
+}
+
+// One is like mgo.Query.One.
+func (q *stateQuery) One(result interface{}) error {
+	if !q.coll.scoped {
+		return q.Query.One(result)
+	}
+	var doc bson.RawD
+	if err := q.Query.One(&doc); err != nil {
+		return err
+	}
+	return q.coll.decode(doc, result)
+}
+
+// All is like mgo.Query.All.
+func (q *stateQuery) All(result interface{}) error {
+	if !q.coll.scoped {
+		return q.Query.All(result)
+	}
+	resultv := reflect.ValueOf(result)
+	if resultv.Kind() != reflect.Ptr || resultv.Elem().Kind() != reflect.Slice {
+		panic("result argument must be a slice address")
+	}
+	slicev := resultv.Elem()
+	slicev = slicev.Slice(0, slicev.Cap())
+	elemt := slicev.Type().Elem()
+	iter := q.Iter()
+	i := 0
+	for ; ; i++ {
+		if slicev.Len() == i {
+			elemp := reflect.New(elemt)
+			if !iter.Next(elemp.Interface()) {
+				break
+			}
+			slicev = reflect.Append(slicev, elemp.Elem())
+			slicev = slicev.Slice(0, slicev.Cap())
+		} else if !iter.Next(slicev.Index(i).Addr().Interface()) {
+			break
+		}
+	}
+	resultv.Elem().Set(slicev.Slice(0, i))
+	return iter.Close()
+}
+
+// Apply is like mgo.Query.Apply.
+func (q *stateQuery) Apply(change mgo.Change, result interface{}) (*mgo.ChangeInfo, error) {
+	if !q.coll.scoped || result == nil {
+		return q.Query.Apply(change, result)
+	}
+	var doc bson.RawD
+	info, err := q.Query.Apply(change, &doc)
+	if err != nil {
+		return info, err
+	}
+	return info, q.coll.decode(doc, result)
+}
+
+// Sort is like mgo.Query.Sort.
+func (q *stateQuery) Sort(fields ...string) *stateQuery {
+	q.Query.Sort(fields...)
+	return q
+}
+
+// Select is like mgo.Query.Select.
+func (q *stateQuery) Select(selector interface{}) *stateQuery {
+	q.Query.Select(selector)
+	return q
+}
+
+// Limit is like mgo.Query.Limit.
+func (q *stateQuery) Limit(n int) *stateQuery {
+	q.Query.Limit(n)
+	return q
+}
+
+// Iter is like mgo.Query.Iter.
+func (q *stateQuery) Iter() *stateIter {
+	return &stateIter{Iter: q.Query.Iter(), coll: q.coll}
+}
+
+// Tail is like mgo.Query.Tail.
+func (q *stateQuery) Tail(timeout time.Duration) *stateIter {
+	return &stateIter{Iter: q.Query.Tail(timeout), coll: q.coll}
+}
+
+// stateIter wraps an iterator over the results of a stateQuery,
+// removing the environment prefix from the _ids of the documents
+// it returns.
+type stateIter struct {
+	*mgo.Iter
+	coll *stateCollection
+	err  error
+}
+
+// Next is like mgo.Iter.Next.
+func (it *stateIter) Next(result interface{}) bool {
+	if !it.coll.scoped {
+		return it.Iter.Next(result)
+	}
+	if it.err != nil {
+		return false
+	}
+	var doc bson.RawD
+	if !it.Iter.Next(&doc) {
+		return false
+	}
+	if err := it.coll.decode(doc, result); err != nil {
+		it.err = err
+		return false
+	}
+	return true
+}
+
+// Err is like mgo.Iter.Err.
+func (it *stateIter) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.Iter.Err()
+}
+
+// Close is like mgo.Iter.Close.
+func (it *stateIter) Close() error {
+	err := it.Iter.Close()
+	if it.err != nil {
+		return it.err
+	}
+	return err
+}
+
+// scopeOps returns the given transaction operations with the ids
+// of the documents in environment-scoped collections prefixed by
+// the environment UUID, and with the environment UUID recorded
+// in any documents they insert.
+func (st *State) scopeOps(ops []txn.Op) ([]txn.Op, error) {
+	scoped := make([]txn.Op, len(ops))
+	for i, op := range ops {
+		scoped[i] = op
+		if !envScopedCollections.Contains(op.C) {
+			continue
+		}
+		if id, ok := stringID(op.Id); ok {
+			scoped[i].Id = st.docID(id)
+		}
+		if op.Insert != nil {
+			doc, err := st.scopeDoc(scoped[i].Id, op.Insert)
+			if err != nil {
+				return nil, errors.Annotatef(err, "cannot insert document into %q", op.C)
+			}
+			scoped[i].Insert = doc
+		}
+	}
+	return scoped, nil
+}
+
+// scopeDoc returns a copy of the given document to be inserted
+// into an environment-scoped collection, recording the State's
+// environment UUID. The copy's _id is set to the given id if it
+// is not nil; otherwise a string _id is prefixed by the UUID.
+func (st *State) scopeDoc(id, doc interface{}) (bson.D, error) {
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var fields bson.D
+	if err := bson.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	scoped := make(bson.D, 0, len(fields)+2)
+	if id != nil {
+		scoped = append(scoped, bson.DocElem{"_id", id})
+	}
+	for _, field := range fields {
+		switch field.Name {
+		case envUUIDField:
+			continue
+		case "_id":
+			if id != nil {
+				continue
+			}
+			if local, ok := field.Value.(string); ok {
+				field.Value = st.docID(local)
+			}
+		}
+		scoped = append(scoped, field)
+	}
+	return append(scoped, bson.DocElem{envUUIDField, st.envUUID}), nil
+}
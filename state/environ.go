@@ -27,12 +27,15 @@ type environmentDoc struct {
 	UUID string `bson:"_id"`
 	Name string
 	Life Life
+	// ServerUUID holds the UUID of the environment of the
+	// state server hosting the environment.
+	ServerUUID string `bson:"server-uuid"`
 }
 
 // Environment returns the environment entity.
 func (st *State) Environment() (*Environment, error) {
 	env := &Environment{st: st}
-	if err := env.refresh(st.environments.Find(nil)); err != nil {
+	if err := env.refresh(st.environments.FindId(st.envUUID)); err != nil {
 		return nil, err
 	}
 	env.annotator = annotator{
@@ -60,6 +63,18 @@ func (e *Environment) Name() string {
 	return e.doc.Name
 }
 
+// ServerUUID returns the UUID of the environment of the
+// state server hosting the environment. It is the
+// environment's own UUID if the environment is not hosted.
+func (e *Environment) ServerUUID() string {
+	if e.doc.ServerUUID == "" {
+		// Environments created before environments could be
+		// hosted are always the state server's own.
+		return e.doc.UUID
+	}
+	return e.doc.ServerUUID
+}
+
 // Life returns whether the environment is Alive, Dying or Dead.
 func (e *Environment) Life() Life {
 	return e.doc.Life
@@ -74,7 +89,7 @@ func (e *Environment) Refresh() error {
 	return e.refresh(e.st.environments.FindId(e.UUID()))
 }
 
-func (e *Environment) refresh(query *mgo.Query) error {
+func (e *Environment) refresh(query *stateQuery) error {
 	err := query.One(&e.doc)
 	if err == mgo.ErrNotFound {
 		return errors.NotFoundf("environment")
@@ -119,9 +134,10 @@ func (e *Environment) Destroy() error {
 }
 
 // createEnvironmentOp returns the operation needed to create
-// an environment document with the given name and UUID.
-func createEnvironmentOp(st *State, name, uuid string) txn.Op {
-	doc := &environmentDoc{uuid, name, Alive}
+// an environment document with the given name and UUID, hosted
+// by the state server whose environment has the UUID serverUUID.
+func createEnvironmentOp(st *State, name, uuid, serverUUID string) txn.Op {
+	doc := &environmentDoc{uuid, name, Alive, serverUUID}
 	return txn.Op{
 		C:      st.environments.Name,
 		Id:     uuid,
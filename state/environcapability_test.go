@@ -23,6 +23,7 @@ var _ = gc.Suite(&EnvironCapabilitySuite{})
 
 type mockEnvironCapability struct {
 	supportsUnitPlacementError error
+	supportsSourceCIDRsError   error
 }
 
 func (p *mockEnvironCapability) SupportedArchitectures() ([]string, error) {
@@ -37,6 +38,10 @@ func (p *mockEnvironCapability) SupportsUnitPlacement() error {
 	return p.supportsUnitPlacementError
 }
 
+func (p *mockEnvironCapability) SupportsSourceCIDRs() error {
+	return p.supportsSourceCIDRsError
+}
+
 func (s *EnvironCapabilitySuite) SetUpTest(c *gc.C) {
 	s.ConnSuite.SetUpTest(c)
 	s.capability = mockEnvironCapability{}
@@ -130,3 +135,17 @@ func (s *EnvironCapabilitySuite) TestSupportsUnitPlacementNoPolicy(c *gc.C) {
 	_, err := s.addOneMachine(c)
 	c.Assert(err, gc.IsNil)
 }
+
+func (s *EnvironCapabilitySuite) TestSupportsSourceCIDRsServiceExpose(c *gc.C) {
+	service := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	s.capability.supportsSourceCIDRsError = errors.NotSupportedf("restricting access to source CIDRs")
+	err := service.SetExposedTo([]string{"10.0.0.0/8"})
+	c.Assert(err, gc.ErrorMatches, `cannot expose service "wordpress": restricting access to source CIDRs not supported`)
+	c.Assert(service.IsExposed(), gc.Equals, false)
+
+	// Exposing the service to anywhere does not need the capability.
+	err = service.SetExposedTo(nil)
+	c.Assert(err, gc.IsNil)
+	err = service.SetExposed()
+	c.Assert(err, gc.IsNil)
+}
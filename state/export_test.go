@@ -234,6 +234,8 @@ func GetPasswordHash(e Authenticator) string {
 
 func init() {
 	logSize = logSizeTests
+	auditLogSize = logSizeTests
+	agentLogSize = logSizeTests
 }
 
 // MinUnitsRevno returns the Revno of the minUnits document
@@ -282,3 +284,27 @@ const ActionMarker string = actionMarker
 func GetActionIdPrefix(actionId string) string {
 	return getActionIdPrefix(actionId)
 }
+
+// RemoveWorkloadStatus removes the workload status document of the
+// given unit, as if it had been created by an earlier version.
+func RemoveWorkloadStatus(st *State, u *Unit) error {
+	return st.workloadStatuses.RemoveId(u.globalKey())
+}
+
+// Collection is a collection used by a State. The documents of
+// an environment-scoped collection are seen only by the States
+// of their own environment, and their ids are environment-local.
+type Collection struct {
+	*stateCollection
+}
+
+// GetCollection returns the named collection as used by st.
+func GetCollection(st *State, name string) Collection {
+	return Collection{newStateCollection(st, st.db, name)}
+}
+
+// DocID returns the _id of the document with the given
+// environment-local key in an environment-scoped collection.
+func DocID(st *State, localID string) string {
+	return st.docID(localID)
+}
@@ -0,0 +1,241 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"github.com/juju/utils"
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/bson"
+	"labix.org/v2/mgo/txn"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/environs/config"
+)
+
+// HostedEnvironment describes an environment that is hosted by
+// the state server alongside the state server's own environment.
+// The documents of all the environments are kept in the same
+// database, each scoped by the UUID of its environment.
+type HostedEnvironment struct {
+	doc hostedEnvironmentDoc
+}
+
+// hostedEnvironmentDoc records a hosted environment. It is keyed
+// by name, so that two environments hosted by the same state
+// server cannot share a name.
+type hostedEnvironmentDoc struct {
+	Name  string `bson:"_id"`
+	UUID  string `bson:"uuid"`
+	Owner string `bson:"owner"`
+}
+
+// Name returns the name of the hosted environment.
+func (e *HostedEnvironment) Name() string {
+	return e.doc.Name
+}
+
+// UUID returns the universally unique identifier of the
+// hosted environment.
+func (e *HostedEnvironment) UUID() string {
+	return e.doc.UUID
+}
+
+// Tag returns the tag of the hosted environment.
+func (e *HostedEnvironment) Tag() string {
+	return names.NewEnvironTag(e.doc.UUID).String()
+}
+
+// Owner returns the name of the user that created the
+// hosted environment.
+func (e *HostedEnvironment) Owner() string {
+	return e.doc.Owner
+}
+
+// IsHosted reports whether the State is for an environment hosted
+// by the state server, rather than for the state server's own
+// environment.
+func (st *State) IsHosted() bool {
+	return st.envUUID != st.serverUUID
+}
+
+// HostedEnvironments returns all the environments hosted by
+// the state server, ordered by name.
+func (st *State) HostedEnvironments() ([]*HostedEnvironment, error) {
+	var docs []hostedEnvironmentDoc
+	if err := st.hostedEnvs.Find(nil).Sort("_id").All(&docs); err != nil {
+		return nil, fmt.Errorf("cannot get hosted environments: %v", err)
+	}
+	envs := make([]*HostedEnvironment, len(docs))
+	for i, doc := range docs {
+		envs[i] = &HostedEnvironment{doc: doc}
+	}
+	return envs, nil
+}
+
+// HostedEnvironment returns the hosted environment with the given UUID.
+func (st *State) HostedEnvironment(uuid string) (*HostedEnvironment, error) {
+	env := &HostedEnvironment{}
+	err := st.hostedEnvs.Find(bson.D{{"uuid", uuid}}).One(&env.doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("environment %q", uuid)
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot get environment %q: %v", uuid, err)
+	}
+	return env, nil
+}
+
+// WatchHostedEnvironments returns a StringsWatcher that notifies of
+// changes to the lifecycles of the environments hosted by the state
+// server. The watcher reports the UUIDs of the environments.
+func (st *State) WatchHostedEnvironments() StringsWatcher {
+	members := bson.D{{"_id", bson.D{{"$ne", st.serverUUID}}}}
+	filter := func(id interface{}) bool {
+		return id.(string) != st.serverUUID
+	}
+	return newLifecycleWatcher(st, st.environments, members, filter)
+}
+
+// ForEnviron returns a new State for the environment with the given
+// UUID, which must be either the state server's own environment or
+// one of the environments it hosts. The returned State has its own
+// connection to mongo and must be closed independently.
+func (st *State) ForEnviron(uuid string) (*State, error) {
+	if uuid != st.serverUUID {
+		if _, err := st.HostedEnvironment(uuid); err != nil {
+			return nil, err
+		}
+	}
+	session := st.db.Session.Copy()
+	newSt, err := openEnvironState(st.info, st.policy, session, uuid, st.serverUUID)
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+	return newSt, nil
+}
+
+// NewEnvironment creates a new environment with the given
+// configuration, hosted by the state server and owned by the
+// named user. It returns the new environment along with a State
+// for it, which must be closed independently.
+//
+// NewEnvironment may only be called on the state server's own
+// environment.
+func (st *State) NewEnvironment(cfg *config.Config, owner string) (_ *Environment, _ *State, err error) {
+	defer errors.Maskf(&err, "cannot create environment %q", cfg.Name())
+	if st.IsHosted() {
+		return nil, nil, fmt.Errorf("environments can only be created by the state server")
+	}
+	if !names.IsUser(owner) {
+		return nil, nil, fmt.Errorf("invalid owner name %q", owner)
+	}
+	if err := checkEnvironConfig(cfg); err != nil {
+		return nil, nil, err
+	}
+	if cfg, err = st.validate(cfg, nil); err != nil {
+		return nil, nil, err
+	}
+	serverEnv, err := st.Environment()
+	if err != nil {
+		return nil, nil, err
+	}
+	if serverEnv.Name() == cfg.Name() {
+		return nil, nil, fmt.Errorf("environment already exists")
+	}
+	uuid, err := utils.NewUUID()
+	if err != nil {
+		return nil, nil, fmt.Errorf("environment UUID cannot be created: %v", err)
+	}
+	// The configuration may have been derived from the state
+	// server's own, so it must be given the new environment's UUID.
+	cfg, err = cfg.Apply(map[string]interface{}{"uuid": uuid.String()})
+	if err != nil {
+		return nil, nil, err
+	}
+	session := st.db.Session.Copy()
+	newSt, err := openEnvironState(st.info, st.policy, session, uuid.String(), st.serverUUID)
+	if err != nil {
+		session.Close()
+		return nil, nil, err
+	}
+	defer func() {
+		if err != nil {
+			newSt.Close()
+		}
+	}()
+	// The environment's documents are created in the same
+	// transaction that registers it, so that the state server
+	// never finds an environment that is half created.
+	ops := []txn.Op{
+		createConstraintsOp(newSt, environGlobalKey, constraints.Value{}),
+		createSettingsOp(newSt, environGlobalKey, cfg.AllAttrs()),
+		createEnvironmentOp(newSt, cfg.Name(), uuid.String(), st.serverUUID),
+		{
+			C:      st.hostedEnvs.Name,
+			Id:     cfg.Name(),
+			Assert: txn.DocMissing,
+			Insert: &hostedEnvironmentDoc{
+				Name:  cfg.Name(),
+				UUID:  uuid.String(),
+				Owner: owner,
+			},
+		},
+	}
+	if err := newSt.runTransaction(ops); err == txn.ErrAborted {
+		return nil, nil, fmt.Errorf("environment already exists")
+	} else if err != nil {
+		return nil, nil, err
+	}
+	env, err := newSt.Environment()
+	if err != nil {
+		return nil, nil, err
+	}
+	return env, newSt, nil
+}
+
+// Remove removes the hosted environment, along with all of its
+// documents. The environment must be Dying; its instances must
+// already have been stopped, as they are no longer known to juju
+// once it has been removed. The state server's own environment
+// cannot be removed.
+func (e *Environment) Remove() (err error) {
+	defer errors.Maskf(&err, "cannot remove environment %q", e.Name())
+	if !e.st.IsHosted() || e.UUID() != e.st.envUUID {
+		return fmt.Errorf("only hosted environments can be removed")
+	}
+	hosted, err := e.st.HostedEnvironment(e.UUID())
+	if err != nil {
+		return err
+	}
+	ops := []txn.Op{{
+		C:      e.st.environments.Name,
+		Id:     e.UUID(),
+		Assert: bson.D{{"life", Dying}},
+		Remove: true,
+	}, {
+		C:      e.st.hostedEnvs.Name,
+		Id:     hosted.Name(),
+		Assert: txn.DocExists,
+		Remove: true,
+	}}
+	if err := e.st.runTransaction(ops); err == txn.ErrAborted {
+		return fmt.Errorf("environment is not dying")
+	} else if err != nil {
+		return err
+	}
+	// The environment's instances have been stopped, and its
+	// workers stop once it is gone, so the remaining documents
+	// are removed without the cost of a transaction.
+	for _, name := range envScopedCollections.SortedValues() {
+		sel := bson.D{{envUUIDField, e.UUID()}}
+		if _, err := e.st.db.C(name).RemoveAll(sel); err != nil {
+			return fmt.Errorf("cannot remove %s: %v", name, err)
+		}
+	}
+	return nil
+}
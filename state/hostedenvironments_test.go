@@ -0,0 +1,293 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+	"github.com/juju/juju/testing"
+)
+
+type HostedEnvironmentsSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&HostedEnvironmentsSuite{})
+
+func (s *HostedEnvironmentsSuite) newConfig(c *gc.C, name string) *config.Config {
+	return testing.CustomEnvironConfig(c, testing.Attrs{"name": name})
+}
+
+func (s *HostedEnvironmentsSuite) TestNewEnvironment(c *gc.C) {
+	env, st, err := s.State.NewEnvironment(s.newConfig(c, "sandbox"), "bob")
+	c.Assert(err, gc.IsNil)
+	defer st.Close()
+	c.Assert(env.Name(), gc.Equals, "sandbox")
+	c.Assert(env.UUID(), gc.HasLen, 36)
+	c.Assert(env.Life(), gc.Equals, state.Alive)
+	c.Assert(st.IsHosted(), jc.IsTrue)
+	c.Assert(s.State.IsHosted(), jc.IsFalse)
+
+	cfg, err := st.EnvironConfig()
+	c.Assert(err, gc.IsNil)
+	c.Assert(cfg.Name(), gc.Equals, "sandbox")
+	uuid, ok := cfg.UUID()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(uuid, gc.Equals, env.UUID())
+	cons, err := st.EnvironConstraints()
+	c.Assert(err, gc.IsNil)
+	c.Assert(cons, gc.DeepEquals, constraints.Value{})
+
+	serverEnv, err := s.State.Environment()
+	c.Assert(err, gc.IsNil)
+	c.Assert(serverEnv.Name(), gc.Equals, "testenv")
+	c.Assert(serverEnv.UUID(), gc.Not(gc.Equals), env.UUID())
+
+	hosted, err := s.State.HostedEnvironment(env.UUID())
+	c.Assert(err, gc.IsNil)
+	c.Assert(hosted.Name(), gc.Equals, "sandbox")
+	c.Assert(hosted.UUID(), gc.Equals, env.UUID())
+	c.Assert(hosted.Tag(), gc.Equals, env.Tag())
+	c.Assert(hosted.Owner(), gc.Equals, "bob")
+}
+
+func (s *HostedEnvironmentsSuite) TestNewEnvironmentSeparatesDocuments(c *gc.C) {
+	_, st, err := s.State.NewEnvironment(s.newConfig(c, "sandbox"), "bob")
+	c.Assert(err, gc.IsNil)
+	defer st.Close()
+
+	m, err := st.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	c.Assert(m.Id(), gc.Equals, "0")
+
+	_, err = s.State.Machine("0")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	m, err = s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	c.Assert(m.Id(), gc.Equals, "0")
+
+	machines, err := st.AllMachines()
+	c.Assert(err, gc.IsNil)
+	c.Assert(machines, gc.HasLen, 1)
+}
+
+func (s *HostedEnvironmentsSuite) TestNewEnvironmentReusesKeys(c *gc.C) {
+	_, st, err := s.State.NewEnvironment(s.newConfig(c, "sandbox"), "bob")
+	c.Assert(err, gc.IsNil)
+	defer st.Close()
+
+	// Charms, services and units are keyed by the same names
+	// in each environment without conflict.
+	for _, envSt := range []*state.State{s.State, st} {
+		ch := state.AddTestingCharm(c, envSt, "wordpress")
+		svc, err := envSt.AddService("wordpress", "user-admin", ch, nil)
+		c.Assert(err, gc.IsNil)
+		unit, err := svc.AddUnit()
+		c.Assert(err, gc.IsNil)
+		c.Assert(unit.Name(), gc.Equals, "wordpress/0")
+	}
+	unit, err := st.Unit("wordpress/0")
+	c.Assert(err, gc.IsNil)
+	err = unit.Destroy()
+	c.Assert(err, gc.IsNil)
+	unit, err = s.State.Unit("wordpress/0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(unit.Life(), gc.Equals, state.Alive)
+}
+
+func (s *HostedEnvironmentsSuite) TestWatchersSeeOwnEnvironment(c *gc.C) {
+	_, st, err := s.State.NewEnvironment(s.newConfig(c, "sandbox"), "bob")
+	c.Assert(err, gc.IsNil)
+	defer st.Close()
+
+	w := s.State.WatchEnvironMachines()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewStringsWatcherC(c, s.State, w)
+	wc.AssertChange()
+	wc.AssertNoChange()
+
+	_, err = st.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	wc.AssertNoChange()
+	_, err = s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	wc.AssertChange("0")
+	wc.AssertNoChange()
+}
+
+func (s *HostedEnvironmentsSuite) TestNewEnvironmentSharesStateServers(c *gc.C) {
+	hostPorts := [][]network.HostPort{{{
+		Address: network.NewAddress("0.1.2.3", network.ScopeUnknown),
+		Port:    1234,
+	}}}
+	err := s.State.SetAPIHostPorts(hostPorts)
+	c.Assert(err, gc.IsNil)
+
+	_, st, err := s.State.NewEnvironment(s.newConfig(c, "sandbox"), "bob")
+	c.Assert(err, gc.IsNil)
+	defer st.Close()
+	got, err := st.APIHostPorts()
+	c.Assert(err, gc.IsNil)
+	c.Assert(got, gc.DeepEquals, hostPorts)
+}
+
+func (s *HostedEnvironmentsSuite) TestNewEnvironmentSharesUsers(c *gc.C) {
+	_, err := s.State.AddUser("mary", "Mary", "secret", "admin")
+	c.Assert(err, gc.IsNil)
+	_, st, err := s.State.NewEnvironment(s.newConfig(c, "sandbox"), "bob")
+	c.Assert(err, gc.IsNil)
+	defer st.Close()
+
+	user, err := st.User("mary")
+	c.Assert(err, gc.IsNil)
+	c.Assert(user.DisplayName(), gc.Equals, "Mary")
+	svc, err := st.AddService("wordpress", "user-mary", state.AddTestingCharm(c, st, "wordpress"), nil)
+	c.Assert(err, gc.IsNil)
+	c.Assert(svc.GetOwnerTag(), gc.Equals, "user-mary")
+	_, err = st.AddService("mysql", "user-nobody", state.AddTestingCharm(c, st, "mysql"), nil)
+	c.Assert(err, gc.ErrorMatches, `cannot add service "mysql": user nobody doesn't exist`)
+}
+
+func (s *HostedEnvironmentsSuite) TestNewEnvironmentDuplicateName(c *gc.C) {
+	_, st, err := s.State.NewEnvironment(s.newConfig(c, "sandbox"), "bob")
+	c.Assert(err, gc.IsNil)
+	st.Close()
+
+	_, _, err = s.State.NewEnvironment(s.newConfig(c, "sandbox"), "mary")
+	c.Assert(err, gc.ErrorMatches, `cannot create environment "sandbox": environment already exists`)
+	_, _, err = s.State.NewEnvironment(s.newConfig(c, "testenv"), "mary")
+	c.Assert(err, gc.ErrorMatches, `cannot create environment "testenv": environment already exists`)
+
+	envs, err := s.State.HostedEnvironments()
+	c.Assert(err, gc.IsNil)
+	c.Assert(envs, gc.HasLen, 1)
+}
+
+func (s *HostedEnvironmentsSuite) TestNewEnvironmentInvalidOwner(c *gc.C) {
+	_, _, err := s.State.NewEnvironment(s.newConfig(c, "sandbox"), "#bad")
+	c.Assert(err, gc.ErrorMatches, `cannot create environment "sandbox": invalid owner name "#bad"`)
+}
+
+func (s *HostedEnvironmentsSuite) TestNewEnvironmentFromHostedEnvironment(c *gc.C) {
+	_, st, err := s.State.NewEnvironment(s.newConfig(c, "sandbox"), "bob")
+	c.Assert(err, gc.IsNil)
+	defer st.Close()
+	_, _, err = st.NewEnvironment(s.newConfig(c, "nested"), "bob")
+	c.Assert(err, gc.ErrorMatches, `cannot create environment "nested": environments can only be created by the state server`)
+}
+
+func (s *HostedEnvironmentsSuite) TestHostedEnvironments(c *gc.C) {
+	envs, err := s.State.HostedEnvironments()
+	c.Assert(err, gc.IsNil)
+	c.Assert(envs, gc.HasLen, 0)
+
+	for _, name := range []string{"zebra", "alpha"} {
+		_, st, err := s.State.NewEnvironment(s.newConfig(c, name), "bob")
+		c.Assert(err, gc.IsNil)
+		st.Close()
+	}
+	envs, err = s.State.HostedEnvironments()
+	c.Assert(err, gc.IsNil)
+	c.Assert(envs, gc.HasLen, 2)
+	c.Assert(envs[0].Name(), gc.Equals, "alpha")
+	c.Assert(envs[1].Name(), gc.Equals, "zebra")
+}
+
+func (s *HostedEnvironmentsSuite) TestForEnviron(c *gc.C) {
+	env, st, err := s.State.NewEnvironment(s.newConfig(c, "sandbox"), "bob")
+	c.Assert(err, gc.IsNil)
+	defer st.Close()
+	_, err = st.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+
+	hostedSt, err := s.State.ForEnviron(env.UUID())
+	c.Assert(err, gc.IsNil)
+	defer hostedSt.Close()
+	c.Assert(hostedSt.IsHosted(), jc.IsTrue)
+	got, err := hostedSt.Environment()
+	c.Assert(err, gc.IsNil)
+	c.Assert(got.UUID(), gc.Equals, env.UUID())
+	_, err = hostedSt.Machine("0")
+	c.Assert(err, gc.IsNil)
+
+	serverEnv, err := s.State.Environment()
+	c.Assert(err, gc.IsNil)
+	serverSt, err := hostedSt.ForEnviron(serverEnv.UUID())
+	c.Assert(err, gc.IsNil)
+	defer serverSt.Close()
+	c.Assert(serverSt.IsHosted(), jc.IsFalse)
+	got, err = serverSt.Environment()
+	c.Assert(err, gc.IsNil)
+	c.Assert(got.UUID(), gc.Equals, serverEnv.UUID())
+}
+
+func (s *HostedEnvironmentsSuite) TestForEnvironNotFound(c *gc.C) {
+	_, err := s.State.ForEnviron("deadbeef-0bad-400d-8000-4b1d0d06f00d")
+	c.Assert(err, gc.ErrorMatches, `environment "deadbeef-0bad-400d-8000-4b1d0d06f00d" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *HostedEnvironmentsSuite) TestWatchHostedEnvironments(c *gc.C) {
+	w := s.State.WatchHostedEnvironments()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewStringsWatcherC(c, s.State, w)
+	wc.AssertChange()
+	wc.AssertNoChange()
+
+	env, st, err := s.State.NewEnvironment(s.newConfig(c, "sandbox"), "bob")
+	c.Assert(err, gc.IsNil)
+	defer st.Close()
+	wc.AssertChange(env.UUID())
+	wc.AssertNoChange()
+
+	err = env.Destroy()
+	c.Assert(err, gc.IsNil)
+	wc.AssertChange(env.UUID())
+	wc.AssertNoChange()
+
+	err = env.Remove()
+	c.Assert(err, gc.IsNil)
+	wc.AssertChange(env.UUID())
+	wc.AssertNoChange()
+}
+
+func (s *HostedEnvironmentsSuite) TestRemoveEnvironment(c *gc.C) {
+	env, st, err := s.State.NewEnvironment(s.newConfig(c, "sandbox"), "bob")
+	c.Assert(err, gc.IsNil)
+	defer st.Close()
+	_, err = st.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+
+	err = env.Remove()
+	c.Assert(err, gc.ErrorMatches, `cannot remove environment "sandbox": environment is not dying`)
+
+	err = env.Destroy()
+	c.Assert(err, gc.IsNil)
+	err = env.Remove()
+	c.Assert(err, gc.IsNil)
+
+	_, err = s.State.HostedEnvironment(env.UUID())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.State.ForEnviron(env.UUID())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = st.Machine("0")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	// The state server's own environment is untouched.
+	_, err = s.State.Machine("0")
+	c.Assert(err, gc.IsNil)
+	serverEnv, err := s.State.Environment()
+	c.Assert(err, gc.IsNil)
+	err = serverEnv.Remove()
+	c.Assert(err, gc.ErrorMatches, `cannot remove environment "testenv": only hosted environments can be removed`)
+}
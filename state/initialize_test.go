@@ -85,6 +85,18 @@ func (s *InitializeSuite) TestInitialize(c *gc.C) {
 	c.Assert(info, jc.DeepEquals, &state.StateServerInfo{})
 }
 
+func (s *InitializeSuite) TestInitializeWithUUID(c *gc.C) {
+	const uuid = "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+	cfg := testing.CustomEnvironConfig(c, testing.Attrs{"uuid": uuid})
+	st := state.TestingInitialize(c, cfg, state.Policy(nil))
+	st.Close()
+
+	s.openState(c)
+	env, err := s.State.Environment()
+	c.Assert(err, gc.IsNil)
+	c.Assert(env.UUID(), gc.Equals, uuid)
+}
+
 func (s *InitializeSuite) TestDoubleInitializeConfig(c *gc.C) {
 	cfg := testing.EnvironConfig(c)
 	initial := cfg.AllAttrs()
@@ -0,0 +1,203 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/bson"
+	"labix.org/v2/mgo/txn"
+)
+
+// ErrLeadershipClaimDenied is returned by ClaimLeadership when
+// another unit holds an unexpired leadership lease.
+var ErrLeadershipClaimDenied = errors.New("leadership claim denied")
+
+// ErrNotLeader is returned by MergeLeaderSettings when the
+// unit does not hold the service's leadership lease.
+var ErrNotLeader = errors.New("unit is not the service leader")
+
+// leaseDoc records which unit of a service is its leader, and
+// until when. The leader must claim the lease again before it
+// expires to remain leader; once it has expired, any unit of
+// the service may claim it.
+type leaseDoc struct {
+	Service string    `bson:"_id"`
+	Leader  string    `bson:"leader"`
+	Expiry  time.Time `bson:"expiry"`
+}
+
+// leaderSettingsKey returns the key of the settings
+// written by the leader of the named service.
+func leaderSettingsKey(serviceName string) string {
+	return serviceGlobalKey(serviceName) + "#leader"
+}
+
+// ClaimLeadership makes the named unit the leader of the service
+// for the given duration, if no other unit currently holds the
+// leadership lease. The current leader calls it periodically to
+// extend its lease. If another unit is leader, it returns
+// ErrLeadershipClaimDenied.
+func (s *Service) ClaimLeadership(unitName string, duration time.Duration) error {
+	if duration <= 0 {
+		return errors.Errorf("invalid leadership lease duration %v", duration)
+	}
+	for i := 0; i < 3; i++ {
+		// Mongo only stores times to millisecond precision.
+		now := time.Now().UTC().Round(time.Millisecond)
+		var doc leaseDoc
+		err := s.st.leases.FindId(s.doc.Name).One(&doc)
+		leaseOp := txn.Op{
+			C:  s.st.leases.Name,
+			Id: s.doc.Name,
+		}
+		switch {
+		case err == mgo.ErrNotFound:
+			leaseOp.Assert = txn.DocMissing
+			leaseOp.Insert = &leaseDoc{
+				Service: s.doc.Name,
+				Leader:  unitName,
+				Expiry:  now.Add(duration),
+			}
+		case err != nil:
+			return fmt.Errorf("cannot claim leadership of service %q: %v", s, err)
+		case doc.Leader != unitName && doc.Expiry.After(now):
+			return ErrLeadershipClaimDenied
+		default:
+			leaseOp.Assert = bson.D{{"leader", doc.Leader}, {"expiry", doc.Expiry}}
+			leaseOp.Update = bson.D{{"$set", bson.D{
+				{"leader", unitName},
+				{"expiry", now.Add(duration)},
+			}}}
+		}
+		ops := []txn.Op{{
+			C:      s.st.services.Name,
+			Id:     s.doc.Name,
+			Assert: txn.DocExists,
+		}, leaseOp}
+		err = s.st.runTransaction(ops)
+		if err == nil {
+			return nil
+		}
+		if err != txn.ErrAborted {
+			return fmt.Errorf("cannot claim leadership of service %q: %v", s, err)
+		}
+		if err := s.Refresh(); err != nil {
+			return err
+		}
+	}
+	return ErrExcessiveContention
+}
+
+// Leader returns the name of the unit that leads the service,
+// or an empty string if no unit holds an unexpired lease.
+func (s *Service) Leader() (string, error) {
+	var doc leaseDoc
+	err := s.st.leases.FindId(s.doc.Name).One(&doc)
+	if err == mgo.ErrNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot get leader of service %q: %v", s, err)
+	}
+	if !doc.Expiry.After(time.Now()) {
+		return "", nil
+	}
+	return doc.Leader, nil
+}
+
+// LeaderSettings returns the settings written by
+// the service's leader.
+func (s *Service) LeaderSettings() (map[string]string, error) {
+	settings, err := readSettings(s.st, leaderSettingsKey(s.doc.Name))
+	if errors.IsNotFound(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]string)
+	for key, value := range settings.Map() {
+		if value, ok := value.(string); ok {
+			result[key] = value
+		}
+	}
+	return result, nil
+}
+
+// MergeLeaderSettings merges the given settings into the service's
+// leader settings; settings with empty values are removed. Only the
+// named unit may change the settings, and only while it is leader;
+// otherwise ErrNotLeader is returned.
+func (s *Service) MergeLeaderSettings(unitName string, settings map[string]string) error {
+	key := leaderSettingsKey(s.doc.Name)
+	for i := 0; i < 3; i++ {
+		values := make(map[string]interface{})
+		current, err := readSettings(s.st, key)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		if err == nil {
+			values = current.Map()
+		}
+		for k, v := range settings {
+			if v == "" {
+				delete(values, k)
+			} else {
+				values[k] = v
+			}
+		}
+		var settingsOp txn.Op
+		if current == nil {
+			settingsOp = createSettingsOp(s.st, key, values)
+		} else if settingsOp, _, err = replaceSettingsOp(s.st, key, values); err != nil {
+			return err
+		}
+		ops := []txn.Op{{
+			C:  s.st.leases.Name,
+			Id: s.doc.Name,
+			Assert: bson.D{
+				{"leader", unitName},
+				{"expiry", bson.D{{"$gt", time.Now()}}},
+			},
+		}, settingsOp}
+		err = s.st.runTransaction(ops)
+		if err == nil {
+			return nil
+		}
+		if err != txn.ErrAborted {
+			return fmt.Errorf("cannot write leader settings of service %q: %v", s, err)
+		}
+		if leader, err := s.Leader(); err != nil {
+			return err
+		} else if leader != unitName {
+			return ErrNotLeader
+		}
+		// The settings were changed concurrently; try again.
+	}
+	return ErrExcessiveContention
+}
+
+// WatchLeaderSettings returns a watcher that notifies when the
+// settings written by the service's leader change.
+func (s *Service) WatchLeaderSettings() NotifyWatcher {
+	return newEntityWatcher(s.st, s.st.settings, leaderSettingsKey(s.doc.Name))
+}
+
+// removeLeadershipOps returns the operations that
+// remove the leadership records of the named service.
+func removeLeadershipOps(st *State, serviceName string) []txn.Op {
+	return []txn.Op{{
+		C:      st.leases.Name,
+		Id:     serviceName,
+		Remove: true,
+	}, {
+		C:      st.settings.Name,
+		Id:     leaderSettingsKey(serviceName),
+		Remove: true,
+	}}
+}
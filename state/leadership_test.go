@@ -0,0 +1,137 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/testing"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type LeadershipSuite struct {
+	ConnSuite
+	mysql *state.Service
+}
+
+var _ = gc.Suite(&LeadershipSuite{})
+
+func (s *LeadershipSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.mysql = s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+}
+
+func (s *LeadershipSuite) assertLeader(c *gc.C, expected string) {
+	leader, err := s.mysql.Leader()
+	c.Assert(err, gc.IsNil)
+	c.Assert(leader, gc.Equals, expected)
+}
+
+func (s *LeadershipSuite) TestClaimLeadership(c *gc.C) {
+	s.assertLeader(c, "")
+
+	err := s.mysql.ClaimLeadership("mysql/0", time.Minute)
+	c.Assert(err, gc.IsNil)
+	s.assertLeader(c, "mysql/0")
+
+	// The leader can extend its lease, but no other unit can claim it.
+	err = s.mysql.ClaimLeadership("mysql/0", time.Minute)
+	c.Assert(err, gc.IsNil)
+	err = s.mysql.ClaimLeadership("mysql/1", time.Minute)
+	c.Assert(err, gc.Equals, state.ErrLeadershipClaimDenied)
+	s.assertLeader(c, "mysql/0")
+}
+
+func (s *LeadershipSuite) TestClaimExpiredLeadership(c *gc.C) {
+	err := s.mysql.ClaimLeadership("mysql/0", coretesting.ShortWait)
+	c.Assert(err, gc.IsNil)
+	time.Sleep(2 * coretesting.ShortWait)
+	s.assertLeader(c, "")
+
+	err = s.mysql.ClaimLeadership("mysql/1", time.Minute)
+	c.Assert(err, gc.IsNil)
+	s.assertLeader(c, "mysql/1")
+}
+
+func (s *LeadershipSuite) TestClaimLeadershipInvalidDuration(c *gc.C) {
+	err := s.mysql.ClaimLeadership("mysql/0", 0)
+	c.Assert(err, gc.ErrorMatches, "invalid leadership lease duration 0")
+}
+
+func (s *LeadershipSuite) TestClaimLeadershipServiceRemoved(c *gc.C) {
+	err := s.mysql.Destroy()
+	c.Assert(err, gc.IsNil)
+	err = s.mysql.ClaimLeadership("mysql/0", time.Minute)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *LeadershipSuite) TestMergeLeaderSettings(c *gc.C) {
+	settings, err := s.mysql.LeaderSettings()
+	c.Assert(err, gc.IsNil)
+	c.Assert(settings, gc.HasLen, 0)
+
+	err = s.mysql.ClaimLeadership("mysql/0", time.Minute)
+	c.Assert(err, gc.IsNil)
+	err = s.mysql.MergeLeaderSettings("mysql/0", map[string]string{
+		"master":     "10.0.0.1",
+		"cluster.id": "one",
+	})
+	c.Assert(err, gc.IsNil)
+	err = s.mysql.MergeLeaderSettings("mysql/0", map[string]string{
+		"master": "",
+		"token":  "secret",
+	})
+	c.Assert(err, gc.IsNil)
+
+	settings, err = s.mysql.LeaderSettings()
+	c.Assert(err, gc.IsNil)
+	c.Assert(settings, jc.DeepEquals, map[string]string{
+		"cluster.id": "one",
+		"token":      "secret",
+	})
+}
+
+func (s *LeadershipSuite) TestMergeLeaderSettingsNotLeader(c *gc.C) {
+	err := s.mysql.MergeLeaderSettings("mysql/0", map[string]string{"foo": "bar"})
+	c.Assert(err, gc.Equals, state.ErrNotLeader)
+
+	err = s.mysql.ClaimLeadership("mysql/0", time.Minute)
+	c.Assert(err, gc.IsNil)
+	err = s.mysql.MergeLeaderSettings("mysql/1", map[string]string{"foo": "bar"})
+	c.Assert(err, gc.Equals, state.ErrNotLeader)
+
+	settings, err := s.mysql.LeaderSettings()
+	c.Assert(err, gc.IsNil)
+	c.Assert(settings, gc.HasLen, 0)
+}
+
+func (s *LeadershipSuite) TestWatchLeaderSettings(c *gc.C) {
+	w := s.mysql.WatchLeaderSettings()
+	defer testing.AssertStop(c, w)
+
+	// Initial event.
+	wc := testing.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	// Claiming leadership does not change the settings.
+	err := s.mysql.ClaimLeadership("mysql/0", time.Minute)
+	c.Assert(err, gc.IsNil)
+	wc.AssertNoChange()
+
+	err = s.mysql.MergeLeaderSettings("mysql/0", map[string]string{"foo": "bar"})
+	c.Assert(err, gc.IsNil)
+	wc.AssertOneChange()
+
+	err = s.mysql.MergeLeaderSettings("mysql/0", map[string]string{"foo": "baz"})
+	c.Assert(err, gc.IsNil)
+	wc.AssertOneChange()
+
+	testing.AssertStop(c, w)
+	wc.AssertClosed()
+}
@@ -4,7 +4,6 @@
 package state
 
 import (
-	"labix.org/v2/mgo"
 	"labix.org/v2/mgo/bson"
 
 	"github.com/juju/juju/state/api/params"
@@ -50,12 +49,12 @@ type AgentLiving interface {
 	Remove() error
 }
 
-func isAlive(coll *mgo.Collection, id interface{}) (bool, error) {
+func isAlive(coll *stateCollection, id interface{}) (bool, error) {
 	n, err := coll.Find(bson.D{{"_id", id}, {"life", Alive}}).Count()
 	return n == 1, err
 }
 
-func isNotDead(coll *mgo.Collection, id interface{}) (bool, error) {
+func isNotDead(coll *stateCollection, id interface{}) (bool, error) {
 	n, err := coll.Find(bson.D{{"_id", id}, {"life", bson.D{{"$ne", Dead}}}}).Count()
 	return n == 1, err
 }
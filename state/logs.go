@@ -0,0 +1,332 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/bson"
+	"launchpad.net/tomb"
+)
+
+// LogRecord holds a single log message written by an agent. Log
+// records are stored in a capped collection, so the oldest records
+// are discarded once the collection is full.
+type LogRecord struct {
+	Id bson.ObjectId `bson:"_id"`
+
+	// Time holds the time at which the message was logged.
+	Time time.Time `bson:"time"`
+
+	// Entity holds the tag of the agent that logged the message.
+	Entity string `bson:"entity"`
+
+	// Module and Location hold the name of the logging module
+	// and the source location ("file.go:line") of the message.
+	Module   string `bson:"module"`
+	Location string `bson:"location"`
+
+	// Level holds the severity of the message.
+	Level loggo.Level `bson:"level"`
+
+	// Message holds the message itself.
+	Message string `bson:"message"`
+
+	// EnvUUID holds the UUID of the environment of the agent
+	// that logged the message. It is set by AddLogs.
+	EnvUUID string `bson:"env-uuid"`
+}
+
+// AddLogs records the given log records.
+func (st *State) AddLogs(records []LogRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, len(records))
+	for i, record := range records {
+		if record.Entity == "" {
+			return errors.New("log record has no entity")
+		}
+		record.Id = bson.NewObjectId()
+		record.EnvUUID = st.envUUID
+		if record.Time.IsZero() {
+			record.Time = time.Now()
+		}
+		// Mongo only stores times to millisecond precision, in UTC.
+		record.Time = record.Time.UTC().Round(time.Millisecond)
+		docs[i] = record
+	}
+	if err := st.logs.Insert(docs...); err != nil {
+		return errors.Annotate(err, "cannot add log records")
+	}
+	return nil
+}
+
+// LogTailerParams specifies the log records a LogTailer returns.
+// The zero value returns all records, starting with the oldest.
+type LogTailerParams struct {
+	// StartTime, if set, causes only records logged at or after
+	// that time to be returned.
+	StartTime time.Time
+
+	// EndTime, if set, causes only records logged at or before
+	// that time to be returned. The tailer stops once it has
+	// returned all such records and the end time has passed.
+	EndTime time.Time
+
+	// InitialLines, if greater than zero, causes the tailer to start
+	// with (approximately) that many of the most recent matching
+	// records, rather than the oldest.
+	InitialLines int
+
+	// MinLevel, if set, causes only records at or above that
+	// level to be returned.
+	MinLevel loggo.Level
+
+	// IncludeEntity and ExcludeEntity list the tags of entities whose
+	// records should be included or excluded. Tags may end with a '*'
+	// to match a prefix, e.g. unit-mysql-*. If IncludeEntity is empty,
+	// all entities are included.
+	IncludeEntity []string
+	ExcludeEntity []string
+
+	// IncludeModule and ExcludeModule list the logging modules whose
+	// records should be included or excluded. Submodules are matched
+	// too. If IncludeModule is empty, all modules are included.
+	IncludeModule []string
+	ExcludeModule []string
+}
+
+// tailTimeout holds the time a LogTailer waits for new records
+// before checking whether it has been stopped.
+var tailTimeout = time.Second
+
+// LogTailer follows the log records stored in state, returning
+// the records matching its parameters as they are added.
+type LogTailer struct {
+	tomb    tomb.Tomb
+	logs    *mgo.Collection
+	envUUID string
+	params  LogTailerParams
+	out     chan *LogRecord
+}
+
+// NewLogTailer returns a LogTailer that returns the log records of
+// the State's environment matching the given parameters, oldest
+// first, until it is stopped
+// or, if the parameters have an end time, all the records up to
+// that time have been returned.
+func (st *State) NewLogTailer(params LogTailerParams) *LogTailer {
+	t := &LogTailer{
+		logs:    st.logs.Collection,
+		envUUID: st.envUUID,
+		params:  params,
+		out:     make(chan *LogRecord),
+	}
+	go func() {
+		defer t.tomb.Done()
+		defer close(t.out)
+		t.tomb.Kill(t.loop())
+	}()
+	return t
+}
+
+// Logs returns a channel on which the tailer sends the matching
+// records. The channel is closed when the tailer stops.
+func (t *LogTailer) Logs() <-chan *LogRecord {
+	return t.out
+}
+
+// Stop stops the tailer and returns any error encountered
+// while it was running.
+func (t *LogTailer) Stop() error {
+	t.tomb.Kill(nil)
+	return t.tomb.Wait()
+}
+
+// Err returns the reason the tailer stopped, or
+// tomb.ErrStillAlive if it is still running.
+func (t *LogTailer) Err() error {
+	return t.tomb.Err()
+}
+
+func (t *LogTailer) loop() error {
+	// A tailable cursor blocks its session while waiting for
+	// records, so we use a session of our own.
+	session := t.logs.Database.Session.Copy()
+	defer session.Close()
+	logs := t.logs.With(session)
+
+	filter := t.filter()
+	startTime, initial, err := t.startTime(logs, filter)
+	if err != nil {
+		return errors.Annotate(err, "cannot find initial log records")
+	}
+	initialTime := startTime
+	endTime := t.params.EndTime.UTC()
+	iter := logs.Find(withTime(filter, "$gte", startTime, endTime)).Tail(tailTimeout)
+	defer func() { iter.Close() }()
+	var record LogRecord
+	sent := false
+	for {
+		for iter.Next(&record) {
+			if initial != nil && record.Time.Equal(initialTime) && !initial[record.Id] {
+				// An older record logged in the same
+				// millisecond as the first initial one.
+				continue
+			}
+			out := record
+			select {
+			case <-t.tomb.Dying():
+				return tomb.ErrDying
+			case t.out <- &out:
+			}
+			startTime = record.Time
+			sent = true
+		}
+		if err := iter.Err(); err != nil {
+			return errors.Annotate(err, "cannot read log records")
+		}
+		if t.finished(endTime) {
+			return nil
+		}
+		if iter.Timeout() {
+			select {
+			case <-t.tomb.Dying():
+				return tomb.ErrDying
+			default:
+				continue
+			}
+		}
+		// The cursor has died, usually because no records matched
+		// when it was created. Wait a little and query again for
+		// records logged after the last one returned.
+		select {
+		case <-t.tomb.Dying():
+			return tomb.ErrDying
+		case <-time.After(tailTimeout):
+		}
+		iter.Close()
+		op := "$gte"
+		if sent {
+			op = "$gt"
+		}
+		iter = logs.Find(withTime(filter, op, startTime, endTime)).Tail(tailTimeout)
+	}
+}
+
+// finished reports whether the tailer has returned all the records
+// it ever will, because they must be logged at or before endTime
+// and that time has passed.
+func (t *LogTailer) finished(endTime time.Time) bool {
+	return !endTime.IsZero() && time.Now().After(endTime)
+}
+
+// startTime returns the time from which the tailer should start
+// returning records. When the tailer starts with the most recent
+// records, it also returns the ids of those logged at that time, so
+// that older records logged at the same time can be skipped.
+func (t *LogTailer) startTime(logs *mgo.Collection, filter bson.D) (time.Time, map[bson.ObjectId]bool, error) {
+	// Records' times are stored to millisecond precision, so we
+	// truncate the start time to make sure no records are missed.
+	startTime := t.params.StartTime.UTC().Truncate(time.Millisecond)
+	if t.params.InitialLines <= 0 {
+		return startTime, nil, nil
+	}
+	// Records in a capped collection are held in insertion
+	// order, so we query in reverse to find the most recent.
+	var records []LogRecord
+	err := logs.Find(withTime(filter, "$gte", startTime, t.params.EndTime.UTC())).
+		Sort("-$natural").
+		Limit(t.params.InitialLines).
+		Select(bson.D{{"time", 1}}).
+		All(&records)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	if len(records) == 0 {
+		return startTime, nil, nil
+	}
+	startTime = records[len(records)-1].Time
+	initial := make(map[bson.ObjectId]bool)
+	for _, record := range records {
+		if record.Time.Equal(startTime) {
+			initial[record.Id] = true
+		}
+	}
+	return startTime, initial, nil
+}
+
+// filter returns the query selecting the records of the tailer's
+// environment that match its level, entity and module parameters.
+func (t *LogTailer) filter() bson.D {
+	sel := bson.D{{envUUIDField, t.envUUID}}
+	if t.params.MinLevel > loggo.UNSPECIFIED {
+		sel = append(sel, bson.DocElem{"level", bson.D{{"$gte", t.params.MinLevel}}})
+	}
+	if matches := matchValues(t.params.IncludeEntity, t.params.ExcludeEntity, entityPattern); len(matches) > 0 {
+		sel = append(sel, bson.DocElem{"entity", matches})
+	}
+	if matches := matchValues(t.params.IncludeModule, t.params.ExcludeModule, modulePattern); len(matches) > 0 {
+		sel = append(sel, bson.DocElem{"module", matches})
+	}
+	return sel
+}
+
+// withTime returns a copy of the given query that also compares the
+// records' time with start using op, and selects only records logged
+// at or before end. Zero times are not compared.
+func withTime(sel bson.D, op string, start, end time.Time) bson.D {
+	timeSel := bson.D{}
+	if !start.IsZero() {
+		timeSel = append(timeSel, bson.DocElem{op, start})
+	}
+	if !end.IsZero() {
+		timeSel = append(timeSel, bson.DocElem{"$lte", end})
+	}
+	if len(timeSel) == 0 {
+		return sel
+	}
+	result := make(bson.D, len(sel), len(sel)+1)
+	copy(result, sel)
+	return append(result, bson.DocElem{"time", timeSel})
+}
+
+// matchValues returns a query clause matching any of the include
+// patterns, if there are any, and none of the exclude patterns.
+func matchValues(include, exclude []string, pattern func(string) interface{}) bson.D {
+	patterns := func(values []string) []interface{} {
+		result := make([]interface{}, len(values))
+		for i, value := range values {
+			result[i] = pattern(value)
+		}
+		return result
+	}
+	matches := bson.D{}
+	if len(include) > 0 {
+		matches = append(matches, bson.DocElem{"$in", patterns(include)})
+	}
+	if len(exclude) > 0 {
+		matches = append(matches, bson.DocElem{"$nin", patterns(exclude)})
+	}
+	return matches
+}
+
+// entityPattern matches an entity tag exactly, or by prefix
+// when the tag ends with a '*'.
+func entityPattern(tag string) interface{} {
+	if n := len(tag); n > 0 && tag[n-1] == '*' {
+		return bson.RegEx{Pattern: "^" + regexp.QuoteMeta(tag[:n-1])}
+	}
+	return tag
+}
+
+// modulePattern matches a logging module and its submodules.
+func modulePattern(module string) interface{} {
+	return bson.RegEx{Pattern: "^" + regexp.QuoteMeta(module)}
+}
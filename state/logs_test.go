@@ -0,0 +1,237 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	"github.com/juju/loggo"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type LogsSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&LogsSuite{})
+
+var logsStart = time.Date(2014, 10, 1, 12, 0, 0, 0, time.UTC)
+
+var logRecords = []state.LogRecord{{
+	Entity:   "machine-0",
+	Module:   "juju.cmd.jujud",
+	Location: "machine.go:152",
+	Level:    loggo.INFO,
+	Message:  "machine agent machine-0 start",
+}, {
+	Entity:   "machine-0",
+	Module:   "juju.worker.firewaller",
+	Location: "firewaller.go:485",
+	Level:    loggo.DEBUG,
+	Message:  "opened ports [80/tcp] on \"machine-1\"",
+}, {
+	Entity:   "machine-1",
+	Module:   "juju.worker.machiner",
+	Location: "machiner.go:54",
+	Level:    loggo.WARNING,
+	Message:  "machine is dying",
+}, {
+	Entity:   "unit-mysql-0",
+	Module:   "unit.mysql/0.install",
+	Location: "server.go:254",
+	Level:    loggo.INFO,
+	Message:  "installing mysql-server",
+}, {
+	Entity:   "unit-mysql-1",
+	Module:   "juju.worker.uniter",
+	Location: "uniter.go:482",
+	Level:    loggo.ERROR,
+	Message:  "hook failed",
+}}
+
+func (s *LogsSuite) addLogs(c *gc.C) {
+	records := make([]state.LogRecord, len(logRecords))
+	for i, record := range logRecords {
+		record.Time = logsStart.Add(time.Duration(i) * time.Minute)
+		records[i] = record
+	}
+	err := s.State.AddLogs(records)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *LogsSuite) TestAddLogs(c *gc.C) {
+	s.addLogs(c)
+	t := s.State.NewLogTailer(state.LogTailerParams{})
+	defer func() { c.Assert(t.Stop(), gc.IsNil) }()
+	records := s.readLogs(c, t, len(logRecords))
+	record := records[2]
+	c.Assert(record.Id.Valid(), jc.IsTrue)
+	c.Assert(record.Time.Equal(logsStart.Add(2*time.Minute)), jc.IsTrue)
+	c.Assert(record.Entity, gc.Equals, "machine-1")
+	c.Assert(record.Module, gc.Equals, "juju.worker.machiner")
+	c.Assert(record.Location, gc.Equals, "machiner.go:54")
+	c.Assert(record.Level, gc.Equals, loggo.WARNING)
+	c.Assert(record.Message, gc.Equals, "machine is dying")
+}
+
+func (s *LogsSuite) TestAddLogsNoEntity(c *gc.C) {
+	err := s.State.AddLogs([]state.LogRecord{{Module: "juju", Message: "hello"}})
+	c.Assert(err, gc.ErrorMatches, "log record has no entity")
+}
+
+var logTailerTests = []struct {
+	about    string
+	params   state.LogTailerParams
+	messages []string
+}{{
+	about: "all records",
+	messages: []string{
+		"machine agent machine-0 start",
+		"opened ports [80/tcp] on \"machine-1\"",
+		"machine is dying",
+		"installing mysql-server",
+		"hook failed",
+	},
+}, {
+	about:    "start time",
+	params:   state.LogTailerParams{StartTime: logsStart.Add(3 * time.Minute)},
+	messages: []string{"installing mysql-server", "hook failed"},
+}, {
+	about:    "initial lines",
+	params:   state.LogTailerParams{InitialLines: 2},
+	messages: []string{"installing mysql-server", "hook failed"},
+}, {
+	about:    "minimum level",
+	params:   state.LogTailerParams{MinLevel: loggo.WARNING},
+	messages: []string{"machine is dying", "hook failed"},
+}, {
+	about: "include entity",
+	params: state.LogTailerParams{
+		IncludeEntity: []string{"machine-1", "unit-mysql-*"},
+	},
+	messages: []string{"machine is dying", "installing mysql-server", "hook failed"},
+}, {
+	about: "exclude entity",
+	params: state.LogTailerParams{
+		ExcludeEntity: []string{"machine-*", "unit-mysql-1"},
+	},
+	messages: []string{"installing mysql-server"},
+}, {
+	about: "include and exclude module",
+	params: state.LogTailerParams{
+		IncludeModule: []string{"juju.worker"},
+		ExcludeModule: []string{"juju.worker.uniter"},
+	},
+	messages: []string{"opened ports [80/tcp] on \"machine-1\"", "machine is dying"},
+}, {
+	about: "initial lines with filter",
+	params: state.LogTailerParams{
+		InitialLines:  1,
+		IncludeEntity: []string{"machine-0"},
+	},
+	messages: []string{"opened ports [80/tcp] on \"machine-1\""},
+}}
+
+func (s *LogsSuite) TestLogTailer(c *gc.C) {
+	s.addLogs(c)
+	for i, test := range logTailerTests {
+		c.Logf("test %d: %s", i, test.about)
+		t := s.State.NewLogTailer(test.params)
+		records := s.readLogs(c, t, len(test.messages))
+		var messages []string
+		for _, record := range records {
+			messages = append(messages, record.Message)
+		}
+		c.Check(messages, jc.DeepEquals, test.messages)
+		s.assertNoMoreLogs(c, t)
+		c.Check(t.Stop(), gc.IsNil)
+	}
+}
+
+func (s *LogsSuite) TestLogTailerEndTime(c *gc.C) {
+	s.addLogs(c)
+	t := s.State.NewLogTailer(state.LogTailerParams{
+		StartTime: logsStart.Add(time.Minute),
+		EndTime:   logsStart.Add(3 * time.Minute),
+	})
+	records := s.readLogs(c, t, 3)
+	c.Assert(records[0].Message, gc.Equals, "opened ports [80/tcp] on \"machine-1\"")
+	c.Assert(records[2].Message, gc.Equals, "installing mysql-server")
+
+	// The end time has passed, so the tailer stops by itself.
+	select {
+	case record, ok := <-t.Logs():
+		c.Assert(ok, jc.IsFalse, gc.Commentf("unexpected log record %#v", record))
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for the tailer to stop")
+	}
+	c.Assert(t.Stop(), gc.IsNil)
+}
+
+func (s *LogsSuite) TestLogTailerEndTimeInitialLines(c *gc.C) {
+	s.addLogs(c)
+	t := s.State.NewLogTailer(state.LogTailerParams{
+		InitialLines: 1,
+		EndTime:      logsStart.Add(2 * time.Minute),
+	})
+	defer t.Stop()
+	records := s.readLogs(c, t, 1)
+	c.Assert(records[0].Message, gc.Equals, "machine is dying")
+}
+
+func (s *LogsSuite) TestLogTailerFollows(c *gc.C) {
+	t := s.State.NewLogTailer(state.LogTailerParams{
+		IncludeEntity: []string{"unit-mysql-*"},
+	})
+	defer func() { c.Assert(t.Stop(), gc.IsNil) }()
+	s.assertNoMoreLogs(c, t)
+
+	s.addLogs(c)
+	records := s.readLogs(c, t, 2)
+	c.Assert(records[0].Message, gc.Equals, "installing mysql-server")
+	c.Assert(records[1].Message, gc.Equals, "hook failed")
+
+	err := s.State.AddLogs([]state.LogRecord{{
+		Entity:  "unit-mysql-0",
+		Module:  "unit.mysql/0.start",
+		Level:   loggo.INFO,
+		Message: "starting mysql",
+	}, {
+		Entity:  "machine-0",
+		Module:  "juju.worker",
+		Level:   loggo.INFO,
+		Message: "ignored",
+	}})
+	c.Assert(err, gc.IsNil)
+	records = s.readLogs(c, t, 1)
+	c.Assert(records[0].Message, gc.Equals, "starting mysql")
+	s.assertNoMoreLogs(c, t)
+}
+
+func (s *LogsSuite) readLogs(c *gc.C, t *state.LogTailer, count int) []*state.LogRecord {
+	var records []*state.LogRecord
+	timeout := time.After(coretesting.LongWait)
+	for len(records) < count {
+		select {
+		case record, ok := <-t.Logs():
+			c.Assert(ok, jc.IsTrue)
+			records = append(records, record)
+		case <-timeout:
+			c.Fatalf("timed out waiting for log records; got %d of %d", len(records), count)
+		}
+	}
+	return records
+}
+
+func (s *LogsSuite) assertNoMoreLogs(c *gc.C, t *state.LogTailer) {
+	select {
+	case record := <-t.Logs():
+		c.Fatalf("unexpected log record %#v", record)
+	case <-time.After(coretesting.ShortWait):
+	}
+}
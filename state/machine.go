@@ -174,6 +174,7 @@ type instanceData struct {
 	CpuCores   *uint64     `bson:"cpucores,omitempty"`
 	CpuPower   *uint64     `bson:"cpupower,omitempty"`
 	Tags       *[]string   `bson:"tags,omitempty"`
+	AvailZone  *string     `bson:"availzone,omitempty"`
 }
 
 func hardwareCharacteristics(instData instanceData) *instance.HardwareCharacteristics {
@@ -184,6 +185,8 @@ func hardwareCharacteristics(instData instanceData) *instance.HardwareCharacteri
 		CpuCores: instData.CpuCores,
 		CpuPower: instData.CpuPower,
 		Tags:     instData.Tags,
+
+		AvailabilityZone: instData.AvailZone,
 	}
 }
 
@@ -629,6 +632,11 @@ func (m *Machine) Remove() (err error) {
 		return err
 	}
 	ops = append(ops, ifacesOps...)
+	volumesOps, err := removeMachineVolumesOps(m.st, m.Id())
+	if err != nil {
+		return err
+	}
+	ops = append(ops, volumesOps...)
 	ops = append(ops, removeContainerRefOps(m.st, m.Id())...)
 	// The only abort conditions in play indicate that the machine has already
 	// been removed.
@@ -653,15 +661,15 @@ func (m *Machine) Refresh() error {
 
 // AgentAlive returns whether the respective remote agent is alive.
 func (m *Machine) AgentAlive() (bool, error) {
-	return m.st.pwatcher.Alive(m.globalKey())
+	return m.st.pwatcher.Alive(m.st.docID(m.globalKey()))
 }
 
 // WaitAgentAlive blocks until the respective agent is alive.
 func (m *Machine) WaitAgentAlive(timeout time.Duration) (err error) {
 	defer errors.Maskf(&err, "waiting for agent of machine %v", m)
 	ch := make(chan presence.Change)
-	m.st.pwatcher.Watch(m.globalKey(), ch)
-	defer m.st.pwatcher.Unwatch(m.globalKey(), ch)
+	m.st.pwatcher.Watch(m.st.docID(m.globalKey()), ch)
+	defer m.st.pwatcher.Unwatch(m.st.docID(m.globalKey()), ch)
 	for i := 0; i < 2; i++ {
 		select {
 		case change := <-ch:
@@ -680,7 +688,7 @@ func (m *Machine) WaitAgentAlive(timeout time.Duration) (err error) {
 // SetAgentAlive signals that the agent for machine m is alive.
 // It returns the started pinger.
 func (m *Machine) SetAgentAlive() (*presence.Pinger, error) {
-	p := presence.NewPinger(m.st.presence, m.globalKey())
+	p := presence.NewPinger(m.st.presence, m.st.docID(m.globalKey()))
 	err := p.Start()
 	if err != nil {
 		return nil, err
@@ -801,6 +809,7 @@ func (m *Machine) SetProvisioned(id instance.Id, nonce string, characteristics *
 		CpuCores:   characteristics.CpuCores,
 		CpuPower:   characteristics.CpuPower,
 		Tags:       characteristics.Tags,
+		AvailZone:  characteristics.AvailabilityZone,
 	}
 	// SCHEMACHANGE
 	// TODO(wallyworld) - do not check instanceId on machineDoc after schema is upgraded
@@ -634,9 +634,11 @@ func (s *MachineSuite) TestMachineSetProvisionedUpdatesCharacteristics(c *gc.C)
 	c.Assert(err, jc.Satisfies, errors.IsNotFound)
 	arch := "amd64"
 	mem := uint64(4096)
+	zone := "zone1"
 	expected := &instance.HardwareCharacteristics{
-		Arch: &arch,
-		Mem:  &mem,
+		Arch:             &arch,
+		Mem:              &mem,
+		AvailabilityZone: &zone,
 	}
 	err = s.machine.SetProvisioned("umbrella/0", "fake_nonce", expected)
 	c.Assert(err, gc.IsNil)
@@ -11,6 +11,7 @@ import (
 	"github.com/juju/errors"
 	"labix.org/v2/mgo"
 
+	"github.com/juju/juju/network"
 	"github.com/juju/juju/state/api/params"
 	"github.com/juju/juju/state/multiwatcher"
 	"github.com/juju/juju/state/watcher"
@@ -86,12 +87,16 @@ func (m *backingMachine) mongoId() interface{} {
 type backingUnit unitDoc
 
 func (u *backingUnit) updated(st *State, store *multiwatcher.Store, id interface{}) error {
+	portRanges := openedPortRanges((*unitDoc)(u))
 	info := &params.UnitInfo{
 		Name:      u.Name,
 		Service:   u.Service,
 		Series:    u.Series,
 		MachineId: u.MachineId,
-		Ports:     u.Ports,
+		Ports:     singlePorts(portRanges),
+	}
+	if len(portRanges) > 0 {
+		info.PortRanges = portRanges
 	}
 	if u.CharmURL != nil {
 		info.CharmURL = u.CharmURL.String()
@@ -106,11 +111,23 @@ func (u *backingUnit) updated(st *State, store *multiwatcher.Store, id interface
 		}
 		info.Status = sdoc.Status
 		info.StatusInfo = sdoc.StatusInfo
+		info.AgentActivity = sdoc.AgentActivity
+		info.ActivityHook = sdoc.ActivityHook
+		wdoc, err := getWorkloadStatus(st, unitGlobalKey(u.Name))
+		if err != nil {
+			return err
+		}
+		info.WorkloadStatus = wdoc.Status
+		info.WorkloadStatusInfo = wdoc.StatusInfo
 	} else {
 		// The entry already exists, so preserve the current status.
 		oldInfo := oldInfo.(*params.UnitInfo)
 		info.Status = oldInfo.Status
 		info.StatusInfo = oldInfo.StatusInfo
+		info.AgentActivity = oldInfo.AgentActivity
+		info.ActivityHook = oldInfo.ActivityHook
+		info.WorkloadStatus = oldInfo.WorkloadStatus
+		info.WorkloadStatusInfo = oldInfo.WorkloadStatusInfo
 	}
 	publicAddress, privateAddress, err := getUnitAddresses(st, u.Name)
 	if err != nil {
@@ -135,6 +152,20 @@ func getUnitAddresses(st *State, unitName string) (publicAddress, privateAddress
 	return publicAddress, privateAddress, nil
 }
 
+// singlePorts returns the ports of the given port ranges that hold
+// a single port, for clients that do not know about port ranges.
+// Wider ranges are only reported as ranges, rather than as a port
+// for every number they hold.
+func singlePorts(portRanges []network.PortRange) []network.Port {
+	ports := []network.Port{}
+	for _, p := range portRanges {
+		if p.FromPort == p.ToPort {
+			ports = append(ports, network.Port{Protocol: p.Protocol, Number: p.FromPort})
+		}
+	}
+	return ports
+}
+
 func (svc *backingUnit) removed(st *State, store *multiwatcher.Store, id interface{}) error {
 	store.Remove(params.EntityId{
 		Kind: "unit",
@@ -152,12 +183,13 @@ type backingService serviceDoc
 func (svc *backingService) updated(st *State, store *multiwatcher.Store, id interface{}) error {
 
 	info := &params.ServiceInfo{
-		Name:     svc.Name,
-		Exposed:  svc.Exposed,
-		CharmURL: svc.CharmURL.String(),
-		OwnerTag: svc.fixOwnerTag(),
-		Life:     params.Life(svc.Life.String()),
-		MinUnits: svc.MinUnits,
+		Name:         svc.Name,
+		Exposed:      svc.Exposed,
+		ExposedCIDRs: svc.ExposedCIDRs,
+		CharmURL:     svc.CharmURL.String(),
+		OwnerTag:     svc.fixOwnerTag(),
+		Life:         params.Life(svc.Life.String()),
+		MinUnits:     svc.MinUnits,
 	}
 	oldInfo := store.Get(info.EntityId())
 	needConfig := false
@@ -291,6 +323,8 @@ func (s *backingStatus) updated(st *State, store *multiwatcher.Store, id interfa
 		newInfo.Status = s.Status
 		newInfo.StatusInfo = s.StatusInfo
 		newInfo.StatusData = s.StatusData
+		newInfo.AgentActivity = s.AgentActivity
+		newInfo.ActivityHook = s.ActivityHook
 		info0 = &newInfo
 	case *params.MachineInfo:
 		newInfo := *info
@@ -315,6 +349,40 @@ func (a *backingStatus) mongoId() interface{} {
 	panic("cannot find mongo id from status document")
 }
 
+type backingWorkloadStatus workloadStatusDoc
+
+func (s *backingWorkloadStatus) updated(st *State, store *multiwatcher.Store, id interface{}) error {
+	parentId, ok := backingEntityIdForGlobalKey(id.(string))
+	if !ok {
+		return nil
+	}
+	info0 := store.Get(parentId)
+	switch info := info0.(type) {
+	case nil:
+		// The parent info doesn't exist. Ignore the status until it does.
+		return nil
+	case *params.UnitInfo:
+		newInfo := *info
+		newInfo.WorkloadStatus = s.Status
+		newInfo.WorkloadStatusInfo = s.StatusInfo
+		info0 = &newInfo
+	default:
+		panic(fmt.Errorf("workload status for unexpected entity with id %q; type %T", id, info))
+	}
+	store.Update(info0)
+	return nil
+}
+
+func (s *backingWorkloadStatus) removed(st *State, store *multiwatcher.Store, id interface{}) error {
+	// If the status is removed, the parent will follow not long after,
+	// so do nothing.
+	return nil
+}
+
+func (a *backingWorkloadStatus) mongoId() interface{} {
+	panic("cannot find mongo id from workload status document")
+}
+
 type backingConstraints constraintsDoc
 
 func (s *backingConstraints) updated(st *State, store *multiwatcher.Store, id interface{}) error {
@@ -450,6 +518,7 @@ var (
 	_ backingEntityDoc = (*backingRelation)(nil)
 	_ backingEntityDoc = (*backingAnnotation)(nil)
 	_ backingEntityDoc = (*backingStatus)(nil)
+	_ backingEntityDoc = (*backingWorkloadStatus)(nil)
 	_ backingEntityDoc = (*backingConstraints)(nil)
 	_ backingEntityDoc = (*backingSettings)(nil)
 )
@@ -459,7 +528,7 @@ var (
 // type of value we use to store entity information
 // for that collection.
 type allWatcherStateCollection struct {
-	*mgo.Collection
+	*stateCollection
 
 	// infoType stores the type of the info type
 	// that we use for this collection.
@@ -476,32 +545,36 @@ func newAllWatcherStateBacking(st *State) multiwatcher.Backing {
 		collectionByName: make(map[string]allWatcherStateCollection),
 	}
 	collections := []allWatcherStateCollection{{
-		Collection: st.machines,
-		infoType:   reflect.TypeOf(backingMachine{}),
+		stateCollection: st.machines,
+		infoType:        reflect.TypeOf(backingMachine{}),
+	}, {
+		stateCollection: st.units,
+		infoType:        reflect.TypeOf(backingUnit{}),
 	}, {
-		Collection: st.units,
-		infoType:   reflect.TypeOf(backingUnit{}),
+		stateCollection: st.services,
+		infoType:        reflect.TypeOf(backingService{}),
 	}, {
-		Collection: st.services,
-		infoType:   reflect.TypeOf(backingService{}),
+		stateCollection: st.relations,
+		infoType:        reflect.TypeOf(backingRelation{}),
 	}, {
-		Collection: st.relations,
-		infoType:   reflect.TypeOf(backingRelation{}),
+		stateCollection: st.annotations,
+		infoType:        reflect.TypeOf(backingAnnotation{}),
 	}, {
-		Collection: st.annotations,
-		infoType:   reflect.TypeOf(backingAnnotation{}),
+		stateCollection: st.statuses,
+		infoType:        reflect.TypeOf(backingStatus{}),
+		subsidiary:      true,
 	}, {
-		Collection: st.statuses,
-		infoType:   reflect.TypeOf(backingStatus{}),
-		subsidiary: true,
+		stateCollection: st.workloadStatuses,
+		infoType:        reflect.TypeOf(backingWorkloadStatus{}),
+		subsidiary:      true,
 	}, {
-		Collection: st.constraints,
-		infoType:   reflect.TypeOf(backingConstraints{}),
-		subsidiary: true,
+		stateCollection: st.constraints,
+		infoType:        reflect.TypeOf(backingConstraints{}),
+		subsidiary:      true,
 	}, {
-		Collection: st.settings,
-		infoType:   reflect.TypeOf(backingSettings{}),
-		subsidiary: true,
+		stateCollection: st.settings,
+		infoType:        reflect.TypeOf(backingSettings{}),
+		subsidiary:      true,
 	}}
 	// Populate the collection maps from the above set of collections.
 	for _, c := range collections {
@@ -521,7 +594,7 @@ func newAllWatcherStateBacking(st *State) multiwatcher.Backing {
 // Watch watches all the collections.
 func (b *allWatcherStateBacking) Watch(in chan<- watcher.Change) {
 	for _, c := range b.collectionByName {
-		b.st.watcher.WatchCollection(c.Name, in)
+		b.st.watchCollection(c.Name, in, nil)
 	}
 }
 
@@ -564,12 +637,13 @@ func (b *allWatcherStateBacking) Changed(all *multiwatcher.Store, change watcher
 	// than simply fetching each entity in turn.
 	// TODO(rog) avoid fetching documents that we have no interest
 	// in, such as settings changes to entities we don't care about.
-	err := c.FindId(change.Id).One(doc)
+	id := b.st.changeKey(change.C, change.Id)
+	err := c.FindId(id).One(doc)
 	if err == mgo.ErrNotFound {
-		return doc.removed(b.st, all, change.Id)
+		return doc.removed(b.st, all, id)
 	}
 	if err != nil {
 		return err
 	}
-	return doc.updated(b.st, all, change.Id)
+	return doc.updated(b.st, all, id)
 }
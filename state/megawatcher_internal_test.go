@@ -13,7 +13,6 @@ import (
 	"github.com/juju/charm"
 	"github.com/juju/names"
 	gitjujutesting "github.com/juju/testing"
-	"labix.org/v2/mgo"
 	gc "launchpad.net/gocheck"
 
 	"github.com/juju/juju/constraints"
@@ -156,6 +155,8 @@ func (s *storeManagerStateSuite) setUpScenario(c *gc.C) (entities entityInfoSlic
 			MachineId: m.Id(),
 			Ports:     []network.Port{},
 			Status:    params.StatusPending,
+
+			WorkloadStatus: params.WorkloadUnknown,
 		})
 		pairs := map[string]string{"name": fmt.Sprintf("bar %d", i)}
 		err = wu.SetAnnotations(pairs)
@@ -209,6 +210,8 @@ func (s *storeManagerStateSuite) setUpScenario(c *gc.C) (entities entityInfoSlic
 			Series:  "quantal",
 			Ports:   []network.Port{},
 			Status:  params.StatusPending,
+
+			WorkloadStatus: params.WorkloadUnknown,
 		})
 	}
 	return
@@ -380,8 +383,11 @@ var allWatcherChangedTests = []struct {
 				Series:     "quantal",
 				MachineId:  "0",
 				Ports:      []network.Port{{"tcp", 12345}},
+				PortRanges: []network.PortRange{{Protocol: "tcp", FromPort: 12345, ToPort: 12345}},
 				Status:     params.StatusError,
 				StatusInfo: "failure",
+
+				WorkloadStatus: params.WorkloadUnknown,
 			},
 		},
 	}, {
@@ -408,10 +414,40 @@ var allWatcherChangedTests = []struct {
 				Service:    "wordpress",
 				Series:     "quantal",
 				Ports:      []network.Port{{"udp", 17070}},
+				PortRanges: []network.PortRange{{Protocol: "udp", FromPort: 17070, ToPort: 17070}},
 				Status:     params.StatusError,
 				StatusInfo: "another failure",
 			},
 		},
+	}, {
+		about: "unit port ranges are not expanded into ports",
+		setUp: func(c *gc.C, st *State) {
+			wordpress := AddTestingService(c, st, "wordpress", AddTestingCharm(c, st, "wordpress"))
+			u, err := wordpress.AddUnit()
+			c.Assert(err, gc.IsNil)
+			err = u.OpenPort("tcp", 80)
+			c.Assert(err, gc.IsNil)
+			err = u.OpenPorts("tcp", 1000, 2000)
+			c.Assert(err, gc.IsNil)
+		},
+		change: watcher.Change{
+			C:  "units",
+			Id: "wordpress/0",
+		},
+		expectContents: []params.EntityInfo{
+			&params.UnitInfo{
+				Name:    "wordpress/0",
+				Service: "wordpress",
+				Series:  "quantal",
+				Ports:   []network.Port{{"tcp", 80}},
+				PortRanges: []network.PortRange{
+					{Protocol: "tcp", FromPort: 80, ToPort: 80},
+					{Protocol: "tcp", FromPort: 1000, ToPort: 2000},
+				},
+				Status:         params.StatusPending,
+				WorkloadStatus: params.WorkloadUnknown,
+			},
+		},
 	}, {
 		about: "unit addresses are read from the assigned machine for recent Juju releases",
 		setUp: func(c *gc.C, st *State) {
@@ -444,8 +480,10 @@ var allWatcherChangedTests = []struct {
 				PrivateAddress: "private",
 				MachineId:      "0",
 				Ports:          []network.Port{{"tcp", 12345}},
+				PortRanges:     []network.PortRange{{Protocol: "tcp", FromPort: 12345, ToPort: 12345}},
 				Status:         params.StatusError,
 				StatusInfo:     "failure",
+				WorkloadStatus: params.WorkloadUnknown,
 			},
 		},
 	},
@@ -737,6 +775,70 @@ var allWatcherChangedTests = []struct {
 				},
 			},
 		},
+	}, {
+		about: "agent activity is changed if the unit exists in the store",
+		add: []params.EntityInfo{&params.UnitInfo{
+			Name:          "wordpress/0",
+			Status:        params.StatusStarted,
+			AgentActivity: params.ActivityIdle,
+		}},
+		setUp: func(c *gc.C, st *State) {
+			wordpress := AddTestingService(c, st, "wordpress", AddTestingCharm(c, st, "wordpress"))
+			u, err := wordpress.AddUnit()
+			c.Assert(err, gc.IsNil)
+			err = u.SetStatus(params.StatusStarted, "", nil)
+			c.Assert(err, gc.IsNil)
+			err = u.SetAgentActivity(params.ActivityExecuting, "config-changed")
+			c.Assert(err, gc.IsNil)
+		},
+		change: watcher.Change{
+			C:  "statuses",
+			Id: "u#wordpress/0",
+		},
+		expectContents: []params.EntityInfo{
+			&params.UnitInfo{
+				Name:          "wordpress/0",
+				Status:        params.StatusStarted,
+				StatusData:    params.StatusData{},
+				AgentActivity: params.ActivityExecuting,
+				ActivityHook:  "config-changed",
+			},
+		},
+	},
+	// Unit workload status changes
+	{
+		about: "no unit in state for workload status -> do nothing",
+		setUp: func(c *gc.C, st *State) {},
+		change: watcher.Change{
+			C:  "workloadstatuses",
+			Id: "u#wordpress/0",
+		},
+	}, {
+		about: "workload status is changed if the unit exists in the store",
+		add: []params.EntityInfo{&params.UnitInfo{
+			Name:           "wordpress/0",
+			Status:         params.StatusStarted,
+			WorkloadStatus: params.WorkloadUnknown,
+		}},
+		setUp: func(c *gc.C, st *State) {
+			wordpress := AddTestingService(c, st, "wordpress", AddTestingCharm(c, st, "wordpress"))
+			u, err := wordpress.AddUnit()
+			c.Assert(err, gc.IsNil)
+			err = u.SetWorkloadStatus(params.WorkloadBlocked, "need a database")
+			c.Assert(err, gc.IsNil)
+		},
+		change: watcher.Change{
+			C:  "workloadstatuses",
+			Id: "u#wordpress/0",
+		},
+		expectContents: []params.EntityInfo{
+			&params.UnitInfo{
+				Name:               "wordpress/0",
+				Status:             params.StatusStarted,
+				WorkloadStatus:     params.WorkloadBlocked,
+				WorkloadStatusInfo: "need a database",
+			},
+		},
 	},
 	// Machine status changes
 	{
@@ -949,7 +1051,7 @@ func setServiceConfigAttr(c *gc.C, svc *Service, attr string, val interface{}) {
 }
 
 func (s *storeManagerStateSuite) TestChanged(c *gc.C) {
-	collections := map[string]*mgo.Collection{
+	collections := map[string]*stateCollection{
 		"machines":    s.State.machines,
 		"units":       s.State.units,
 		"services":    s.State.services,
@@ -0,0 +1,229 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/bson"
+	"labix.org/v2/mgo/txn"
+)
+
+// MetricsCleanupAge is how long metric batches are kept after they
+// were collected, once they have been sent.
+var MetricsCleanupAge = 24 * time.Hour
+
+// Metric represents a single value reported by a charm's
+// collect-metrics hook.
+type Metric struct {
+	Key   string
+	Value string
+	Time  time.Time
+}
+
+// MetricBatch represents the metrics reported by a unit in a single
+// run of its collect-metrics hook.
+type MetricBatch struct {
+	st  *State
+	doc metricBatchDoc
+}
+
+// metricBatchDoc is the mongo representation of MetricBatch.
+type metricBatchDoc struct {
+	UUID     string `bson:"_id"`
+	Unit     string
+	CharmURL string
+	Sent     bool
+	Created  time.Time
+	Metrics  []Metric
+}
+
+// UUID returns the unique identifier of the batch.
+func (m *MetricBatch) UUID() string {
+	return m.doc.UUID
+}
+
+// Unit returns the name of the unit that reported the batch.
+func (m *MetricBatch) Unit() string {
+	return m.doc.Unit
+}
+
+// CharmURL returns the URL of the charm the unit was running when
+// the batch was reported.
+func (m *MetricBatch) CharmURL() string {
+	return m.doc.CharmURL
+}
+
+// Sent returns whether the batch has been sent on, by being exported
+// from the environment.
+func (m *MetricBatch) Sent() bool {
+	return m.doc.Sent
+}
+
+// Created returns the time at which the batch was collected.
+func (m *MetricBatch) Created() time.Time {
+	return m.doc.Created
+}
+
+// Metrics returns the metrics in the batch.
+func (m *MetricBatch) Metrics() []Metric {
+	result := make([]Metric, len(m.doc.Metrics))
+	copy(result, m.doc.Metrics)
+	return result
+}
+
+// SetSent records that the batch has been sent on. Sent batches are
+// removed by CleanupOldMetrics once they are old enough.
+func (m *MetricBatch) SetSent() error {
+	if err := m.st.SetMetricBatchesSent([]string{m.doc.UUID}); err != nil {
+		return err
+	}
+	m.doc.Sent = true
+	return nil
+}
+
+// SetMetricBatchesSent records that the metric batches with the given
+// UUIDs have been sent on. The batches are marked in a single
+// transaction, so either all of them are marked or none are.
+func (st *State) SetMetricBatchesSent(uuids []string) error {
+	if len(uuids) == 0 {
+		return nil
+	}
+	ops := make([]txn.Op, len(uuids))
+	for i, uuid := range uuids {
+		ops[i] = txn.Op{
+			C:      st.metrics.Name,
+			Id:     uuid,
+			Assert: txn.DocExists,
+			Update: bson.D{{"$set", bson.D{{"sent", true}}}},
+		}
+	}
+	err := st.runTransaction(ops)
+	if err == txn.ErrAborted {
+		for _, uuid := range uuids {
+			if _, err := st.MetricBatch(uuid); err != nil {
+				return err
+			}
+		}
+		return ErrExcessiveContention
+	} else if err != nil {
+		return errors.Annotate(err, "cannot mark metric batches as sent")
+	}
+	return nil
+}
+
+// AddMetrics records a batch of metrics reported by the unit, with
+// the time at which they were collected.
+func (u *Unit) AddMetrics(created time.Time, metrics []Metric) (_ *MetricBatch, err error) {
+	defer errors.Maskf(&err, "cannot add metrics for unit %q", u)
+	if len(metrics) == 0 {
+		return nil, fmt.Errorf("no metrics given")
+	}
+	for _, m := range metrics {
+		if m.Key == "" {
+			return nil, fmt.Errorf("metric has no key")
+		}
+		if _, err := strconv.ParseFloat(m.Value, 64); err != nil {
+			return nil, fmt.Errorf("invalid value %q for metric %q", m.Value, m.Key)
+		}
+		if m.Time.IsZero() {
+			return nil, fmt.Errorf("metric %q has no time", m.Key)
+		}
+	}
+	if u.doc.CharmURL == nil {
+		return nil, fmt.Errorf("unit has no charm")
+	}
+	uuid, err := utils.NewUUID()
+	if err != nil {
+		return nil, err
+	}
+	batch := &MetricBatch{st: u.st, doc: metricBatchDoc{
+		UUID:     uuid.String(),
+		Unit:     u.doc.Name,
+		CharmURL: u.doc.CharmURL.String(),
+		Created:  created.UTC(),
+		Metrics:  make([]Metric, len(metrics)),
+	}}
+	for i, m := range metrics {
+		m.Time = m.Time.UTC()
+		batch.doc.Metrics[i] = m
+	}
+	ops := []txn.Op{{
+		C:      u.st.units.Name,
+		Id:     u.doc.Name,
+		Assert: notDeadDoc,
+	}, {
+		C:      u.st.metrics.Name,
+		Id:     batch.doc.UUID,
+		Assert: txn.DocMissing,
+		Insert: &batch.doc,
+	}}
+	if err := u.st.runTransaction(ops); err == txn.ErrAborted {
+		return nil, errDead
+	} else if err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// MetricBatch returns the metric batch with the given UUID.
+func (st *State) MetricBatch(uuid string) (*MetricBatch, error) {
+	var doc metricBatchDoc
+	err := st.metrics.FindId(uuid).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("metric batch %q", uuid)
+	} else if err != nil {
+		return nil, errors.Annotatef(err, "cannot get metric batch %q", uuid)
+	}
+	return &MetricBatch{st: st, doc: doc}, nil
+}
+
+// MetricBatches returns all the metric batches in the environment,
+// oldest first.
+func (st *State) MetricBatches() ([]*MetricBatch, error) {
+	return st.metricBatches(nil)
+}
+
+// UnsentMetricBatches returns the metric batches that have not yet
+// been sent on, oldest first.
+func (st *State) UnsentMetricBatches() ([]*MetricBatch, error) {
+	return st.metricBatches(bson.D{{"sent", false}})
+}
+
+func (st *State) metricBatches(query bson.D) ([]*MetricBatch, error) {
+	var docs []metricBatchDoc
+	if err := st.metrics.Find(query).Sort("created", "_id").All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get metric batches")
+	}
+	result := make([]*MetricBatch, len(docs))
+	for i, doc := range docs {
+		result[i] = &MetricBatch{st: st, doc: doc}
+	}
+	return result, nil
+}
+
+// CleanupOldMetrics removes the metric batches that have been sent on
+// and were collected longer than MetricsCleanupAge ago. It should be
+// called periodically by at least one element of the system.
+func (st *State) CleanupOldMetrics() error {
+	// Sent batches are not otherwise referenced in the system, and
+	// are not under watch, and are therefore safe to delete directly.
+	sel := bson.D{
+		{"sent", true},
+		{"created", bson.D{{"$lte", time.Now().Add(-MetricsCleanupAge).UTC()}}},
+	}
+	info, err := st.metrics.RemoveAll(sel)
+	if err != nil {
+		return fmt.Errorf("cannot remove old metric batches: %v", err)
+	}
+	if info.Removed > 0 {
+		logger.Debugf("removed %d old metric batches", info.Removed)
+	}
+	return nil
+}
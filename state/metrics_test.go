@@ -0,0 +1,158 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state"
+)
+
+type MetricsSuite struct {
+	ConnSuite
+	unit *state.Unit
+}
+
+var _ = gc.Suite(&MetricsSuite{})
+
+func (s *MetricsSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	ch := s.AddTestingCharm(c, "wordpress")
+	svc := s.AddTestingService(c, "wordpress", ch)
+	var err error
+	s.unit, err = svc.AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = s.unit.SetCharmURL(ch.URL())
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *MetricsSuite) TestAddMetrics(c *gc.C) {
+	now := time.Date(2014, 10, 1, 12, 30, 0, 0, time.UTC)
+	metrics := []state.Metric{{Key: "pings", Value: "5", Time: now}}
+	batch, err := s.unit.AddMetrics(now, metrics)
+	c.Assert(err, gc.IsNil)
+	c.Assert(batch.Unit(), gc.Equals, "wordpress/0")
+	c.Assert(batch.CharmURL(), gc.Equals, "local:quantal/quantal-wordpress-3")
+	c.Assert(batch.Sent(), jc.IsFalse)
+
+	batch, err = s.State.MetricBatch(batch.UUID())
+	c.Assert(err, gc.IsNil)
+	c.Assert(batch.Unit(), gc.Equals, "wordpress/0")
+	c.Assert(batch.Created().Equal(now), jc.IsTrue)
+	c.Assert(batch.Metrics(), gc.HasLen, 1)
+	c.Assert(batch.Metrics()[0].Key, gc.Equals, "pings")
+	c.Assert(batch.Metrics()[0].Value, gc.Equals, "5")
+
+	_, err = s.State.MetricBatch("nonsense")
+	c.Assert(err, gc.ErrorMatches, `metric batch "nonsense" not found`)
+}
+
+func (s *MetricsSuite) TestAddMetricsInvalid(c *gc.C) {
+	now := time.Now()
+	for i, test := range []struct {
+		metrics []state.Metric
+		err     string
+	}{{
+		err: "no metrics given",
+	}, {
+		metrics: []state.Metric{{Value: "5", Time: now}},
+		err:     "metric has no key",
+	}, {
+		metrics: []state.Metric{{Key: "pings", Value: "lots", Time: now}},
+		err:     `invalid value "lots" for metric "pings"`,
+	}, {
+		metrics: []state.Metric{{Key: "pings", Value: "5"}},
+		err:     `metric "pings" has no time`,
+	}} {
+		c.Logf("test %d", i)
+		_, err := s.unit.AddMetrics(now, test.metrics)
+		c.Assert(err, gc.ErrorMatches, `cannot add metrics for unit "wordpress/0": `+test.err)
+	}
+}
+
+func (s *MetricsSuite) TestAddMetricsDeadUnit(c *gc.C) {
+	err := s.unit.EnsureDead()
+	c.Assert(err, gc.IsNil)
+	now := time.Now()
+	_, err = s.unit.AddMetrics(now, []state.Metric{{Key: "pings", Value: "5", Time: now}})
+	c.Assert(err, gc.ErrorMatches, `cannot add metrics for unit "wordpress/0": not found or dead`)
+}
+
+func (s *MetricsSuite) TestMetricBatches(c *gc.C) {
+	now := time.Now()
+	metrics := []state.Metric{{Key: "pings", Value: "5", Time: now}}
+	older, err := s.unit.AddMetrics(now.Add(-time.Minute), metrics)
+	c.Assert(err, gc.IsNil)
+	newer, err := s.unit.AddMetrics(now, metrics)
+	c.Assert(err, gc.IsNil)
+
+	batches, err := s.State.MetricBatches()
+	c.Assert(err, gc.IsNil)
+	c.Assert(batches, gc.HasLen, 2)
+	c.Assert(batches[0].UUID(), gc.Equals, older.UUID())
+	c.Assert(batches[1].UUID(), gc.Equals, newer.UUID())
+
+	err = older.SetSent()
+	c.Assert(err, gc.IsNil)
+	c.Assert(older.Sent(), jc.IsTrue)
+	batches, err = s.State.UnsentMetricBatches()
+	c.Assert(err, gc.IsNil)
+	c.Assert(batches, gc.HasLen, 1)
+	c.Assert(batches[0].UUID(), gc.Equals, newer.UUID())
+}
+
+func (s *MetricsSuite) TestSetMetricBatchesSent(c *gc.C) {
+	now := time.Now()
+	metrics := []state.Metric{{Key: "pings", Value: "5", Time: now}}
+	first, err := s.unit.AddMetrics(now, metrics)
+	c.Assert(err, gc.IsNil)
+	second, err := s.unit.AddMetrics(now, metrics)
+	c.Assert(err, gc.IsNil)
+
+	// No batch is marked if any of them does not exist.
+	err = s.State.SetMetricBatchesSent([]string{first.UUID(), "no-such-batch"})
+	c.Assert(err, gc.ErrorMatches, `metric batch "no-such-batch" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	batches, err := s.State.UnsentMetricBatches()
+	c.Assert(err, gc.IsNil)
+	c.Assert(batches, gc.HasLen, 2)
+
+	err = s.State.SetMetricBatchesSent([]string{first.UUID(), second.UUID()})
+	c.Assert(err, gc.IsNil)
+	batches, err = s.State.UnsentMetricBatches()
+	c.Assert(err, gc.IsNil)
+	c.Assert(batches, gc.HasLen, 0)
+}
+
+func (s *MetricsSuite) TestCleanupOldMetrics(c *gc.C) {
+	now := time.Now()
+	metrics := []state.Metric{{Key: "pings", Value: "5", Time: now}}
+	oldTime := now.Add(-2 * state.MetricsCleanupAge)
+	oldSent, err := s.unit.AddMetrics(oldTime, metrics)
+	c.Assert(err, gc.IsNil)
+	err = oldSent.SetSent()
+	c.Assert(err, gc.IsNil)
+	oldUnsent, err := s.unit.AddMetrics(oldTime, metrics)
+	c.Assert(err, gc.IsNil)
+	newSent, err := s.unit.AddMetrics(now, metrics)
+	c.Assert(err, gc.IsNil)
+	err = newSent.SetSent()
+	c.Assert(err, gc.IsNil)
+
+	err = s.State.CleanupOldMetrics()
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.MetricBatch(oldSent.UUID())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.State.MetricBatch(oldUnsent.UUID())
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.MetricBatch(newSent.UUID())
+	c.Assert(err, gc.IsNil)
+
+	err = oldSent.SetSent()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
@@ -0,0 +1,113 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/bson"
+	"labix.org/v2/mgo/txn"
+
+	"github.com/juju/juju/cert"
+)
+
+const mongoCertsKey = "mongoCerts"
+
+// mongoCertsDoc records the certificate that the mongo server on
+// each state server machine was last started with, keyed by machine
+// id. Mongo only reads its certificate when it starts, so after the
+// state server certificate is rotated the CA that signed the old
+// certificate must stay trusted until every mongo has restarted.
+type mongoCertsDoc struct {
+	Certs map[string]string `bson:"certs"`
+}
+
+// SetMongoCert records that the mongo server on the state server
+// machine with the given id has been started with the given
+// certificate, in PEM format. Once every state server's mongo uses
+// the current state server certificate, CAs that no longer sign it
+// stop being trusted.
+func (st *State) SetMongoCert(machineId, certPEM string) error {
+	for i := 0; i < 3; i++ {
+		op := txn.Op{
+			C:  st.stateServers.Name,
+			Id: mongoCertsKey,
+		}
+		var doc mongoCertsDoc
+		err := st.stateServers.FindId(mongoCertsKey).One(&doc)
+		if err == mgo.ErrNotFound {
+			op.Assert = txn.DocMissing
+			op.Insert = &mongoCertsDoc{Certs: map[string]string{machineId: certPEM}}
+		} else if err != nil {
+			return fmt.Errorf("cannot set mongo certificate of machine %q: %v", machineId, err)
+		} else {
+			op.Assert = txn.DocExists
+			op.Update = bson.D{{"$set", bson.D{{"certs." + machineId, certPEM}}}}
+		}
+		err = st.runTransaction([]txn.Op{op})
+		if err == txn.ErrAborted {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("cannot set mongo certificate of machine %q: %v", machineId, err)
+		}
+		return st.RetireOldCACerts()
+	}
+	return ErrExcessiveContention
+}
+
+// RetireOldCACerts stops trusting the CAs that were replaced by the
+// CA that signed the current state server certificate, as long as
+// the mongo server on every state server machine has been started
+// with that certificate. Until then it does nothing, so that agents
+// can still verify the certificates the mongo servers present.
+func (st *State) RetireOldCACerts() error {
+	info, err := st.StateServingInfo()
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cannot get state serving info: %v", err)
+	}
+	serverInfo, err := st.StateServerInfo()
+	if err != nil {
+		return err
+	}
+	var doc mongoCertsDoc
+	err = st.stateServers.FindId(mongoCertsKey).One(&doc)
+	if err != nil && err != mgo.ErrNotFound {
+		return fmt.Errorf("cannot get mongo certificates: %v", err)
+	}
+	for _, id := range serverInfo.MachineIds {
+		if doc.Certs[id] != info.Cert {
+			logger.Debugf("mongo on machine %q does not yet use the current certificate", id)
+			return nil
+		}
+	}
+	srvCert, err := cert.ParseCert(info.Cert)
+	if err != nil {
+		return fmt.Errorf("cannot parse state server certificate: %v", err)
+	}
+	caCerts, err := cert.ParseCerts(st.CACert())
+	if err != nil {
+		return fmt.Errorf("cannot parse CA certificates: %v", err)
+	}
+	// The CA certificates are ordered newest first, so those
+	// after the one that signed the server certificate are
+	// the ones it replaced.
+	var bundle string
+	for i, caCert := range caCerts {
+		bundle += cert.EncodeCert(caCert)
+		if srvCert.CheckSignatureFrom(caCert) != nil {
+			continue
+		}
+		if i == len(caCerts)-1 {
+			return nil
+		}
+		logger.Infof("all state servers use the current certificate; no longer trusting %d old CA certificates", len(caCerts)-i-1)
+		return st.SetCACert(bundle)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/cert"
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/testing"
+)
+
+type MongoCertSuite struct {
+	ConnSuite
+	newCACert  string
+	newCert    string
+	newCertKey string
+}
+
+var _ = gc.Suite(&MongoCertSuite{})
+
+func (s *MongoCertSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	caCert, caKey, err := cert.NewCA("new", time.Now().AddDate(1, 0, 0))
+	c.Assert(err, gc.IsNil)
+	s.newCACert = caCert
+	s.newCert, s.newCertKey, err = cert.NewServer(caCert, caKey, time.Now().AddDate(1, 0, 0), nil)
+	c.Assert(err, gc.IsNil)
+
+	err = s.State.SetCACert(caCert + testing.CACert)
+	c.Assert(err, gc.IsNil)
+	err = s.State.SetStateServingInfo(params.StateServingInfo{
+		APIPort:    1234,
+		StatePort:  4321,
+		Cert:       s.newCert,
+		PrivateKey: s.newCertKey,
+	})
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *MongoCertSuite) TestRetireOldCACertsWaitsForMongo(c *gc.C) {
+	err := s.State.EnsureAvailability(3, constraints.Value{}, "quantal")
+	c.Assert(err, gc.IsNil)
+	info, err := s.State.StateServerInfo()
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.MachineIds, gc.HasLen, 3)
+
+	last := len(info.MachineIds) - 1
+	for _, id := range info.MachineIds[:last] {
+		err = s.State.SetMongoCert(id, s.newCert)
+		c.Assert(err, gc.IsNil)
+	}
+	err = s.State.SetMongoCert(info.MachineIds[last], testing.ServerCert)
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.State.CACert(), gc.Equals, s.newCACert+testing.CACert)
+
+	err = s.State.SetMongoCert(info.MachineIds[last], s.newCert)
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.State.CACert(), gc.Equals, s.newCACert)
+}
+
+func (s *MongoCertSuite) TestRetireOldCACertsKeepsSigningCA(c *gc.C) {
+	err := s.State.SetStateServingInfo(params.StateServingInfo{
+		APIPort:    1234,
+		StatePort:  4321,
+		Cert:       testing.ServerCert,
+		PrivateKey: testing.ServerKey,
+	})
+	c.Assert(err, gc.IsNil)
+	err = s.State.RetireOldCACerts()
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.State.CACert(), gc.Equals, s.newCACert+testing.CACert)
+}
@@ -0,0 +1,173 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+
+	"github.com/juju/charm"
+	"github.com/juju/errors"
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/bson"
+)
+
+// Offer describes service endpoints that have been published by an
+// environment, so that services in the other environments hosted by
+// the same state server may relate to them.
+type Offer struct {
+	doc offerDoc
+}
+
+// offerDoc records an offer in the state server's database, where it
+// is visible to every environment. It is keyed by the offer's URL.
+type offerDoc struct {
+	URL         string           `bson:"_id"`
+	EnvUUID     string           `bson:"envuuid"`
+	EnvName     string           `bson:"envname"`
+	ServiceName string           `bson:"servicename"`
+	Endpoints   []charm.Relation `bson:"endpoints"`
+}
+
+// offerURL returns the URL under which the named service of the
+// named environment is offered.
+func offerURL(envName, serviceName string) string {
+	return envName + "." + serviceName
+}
+
+// URL returns the URL of the offer, of the form
+// <environment name>.<service name>.
+func (o *Offer) URL() string {
+	return o.doc.URL
+}
+
+// EnvUUID returns the UUID of the environment making the offer.
+func (o *Offer) EnvUUID() string {
+	return o.doc.EnvUUID
+}
+
+// EnvName returns the name of the environment making the offer.
+func (o *Offer) EnvName() string {
+	return o.doc.EnvName
+}
+
+// ServiceName returns the name of the offered service.
+func (o *Offer) ServiceName() string {
+	return o.doc.ServiceName
+}
+
+// Endpoints returns the offered endpoints.
+func (o *Offer) Endpoints() []charm.Relation {
+	return o.doc.Endpoints
+}
+
+// AddOffer publishes the named endpoints of the given service to the
+// other environments hosted by the state server. If no endpoints are
+// named, all the service's endpoints that can be related to remotely
+// are offered; peer relations, container-scoped relations and the
+// implicit juju-info relation never are.
+func (st *State) AddOffer(serviceName string, endpointNames ...string) (_ *Offer, err error) {
+	defer errors.Maskf(&err, "cannot offer service %q", serviceName)
+	svc, err := st.Service(serviceName)
+	if err != nil {
+		return nil, err
+	}
+	if svc.Life() != Alive {
+		return nil, fmt.Errorf("service is not alive")
+	}
+	var eps []Endpoint
+	if len(endpointNames) == 0 {
+		all, err := svc.Endpoints()
+		if err != nil {
+			return nil, err
+		}
+		for _, ep := range all {
+			if ep.Role != charm.RolePeer && ep.Scope == charm.ScopeGlobal && !ep.IsImplicit() {
+				eps = append(eps, ep)
+			}
+		}
+		if len(eps) == 0 {
+			return nil, fmt.Errorf("service has no endpoints that can be offered")
+		}
+	} else {
+		for _, name := range endpointNames {
+			ep, err := svc.Endpoint(name)
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case ep.Role == charm.RolePeer:
+				return nil, fmt.Errorf("cannot offer peer relation %q", name)
+			case ep.Scope != charm.ScopeGlobal:
+				return nil, fmt.Errorf("cannot offer container-scoped relation %q", name)
+			}
+			eps = append(eps, ep)
+		}
+	}
+	env, err := st.Environment()
+	if err != nil {
+		return nil, err
+	}
+	doc := offerDoc{
+		URL:         offerURL(env.Name(), serviceName),
+		EnvUUID:     env.UUID(),
+		EnvName:     env.Name(),
+		ServiceName: serviceName,
+	}
+	for _, ep := range eps {
+		doc.Endpoints = append(doc.Endpoints, ep.Relation)
+	}
+	// The offers collection lives in the state server's database,
+	// which cannot take part in the transactions of a hosted
+	// environment; a plain insert is enough, as an offer refers to
+	// nothing but itself.
+	if err := st.offers.Insert(&doc); mgo.IsDup(err) {
+		return nil, fmt.Errorf("offer %q already exists", doc.URL)
+	} else if err != nil {
+		return nil, err
+	}
+	return &Offer{doc: doc}, nil
+}
+
+// Offer returns the offer with the given URL.
+func (st *State) Offer(url string) (*Offer, error) {
+	o := &Offer{}
+	err := st.offers.FindId(url).One(&o.doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("offer %q", url)
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot get offer %q: %v", url, err)
+	}
+	return o, nil
+}
+
+// Offers returns all the offers made by the environments hosted by
+// the state server, ordered by URL.
+func (st *State) Offers() ([]*Offer, error) {
+	var docs []offerDoc
+	if err := st.offers.Find(nil).Sort("_id").All(&docs); err != nil {
+		return nil, fmt.Errorf("cannot get offers: %v", err)
+	}
+	offers := make([]*Offer, len(docs))
+	for i, doc := range docs {
+		offers[i] = &Offer{doc: doc}
+	}
+	return offers, nil
+}
+
+// RemoveOffer withdraws the offer with the given URL, which must have
+// been made by the State's environment. Relations already established
+// with the offered service are not affected.
+func (st *State) RemoveOffer(url string) error {
+	env, err := st.Environment()
+	if err != nil {
+		return err
+	}
+	sel := bson.D{{"_id", url}, {"envuuid", env.UUID()}}
+	if err := st.offers.Remove(sel); err == mgo.ErrNotFound {
+		return errors.NotFoundf("offer %q", url)
+	} else if err != nil {
+		return fmt.Errorf("cannot remove offer %q: %v", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/charm"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing"
+)
+
+type OffersSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&OffersSuite{})
+
+func (s *OffersSuite) TestAddOffer(c *gc.C) {
+	s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	offer, err := s.State.AddOffer("mysql", "server")
+	c.Assert(err, gc.IsNil)
+	env, err := s.State.Environment()
+	c.Assert(err, gc.IsNil)
+	c.Assert(offer.URL(), gc.Equals, "testenv.mysql")
+	c.Assert(offer.EnvUUID(), gc.Equals, env.UUID())
+	c.Assert(offer.EnvName(), gc.Equals, "testenv")
+	c.Assert(offer.ServiceName(), gc.Equals, "mysql")
+	c.Assert(offer.Endpoints(), gc.DeepEquals, []charm.Relation{{
+		Name:      "server",
+		Role:      charm.RoleProvider,
+		Interface: "mysql",
+		Scope:     charm.ScopeGlobal,
+	}})
+
+	got, err := s.State.Offer("testenv.mysql")
+	c.Assert(err, gc.IsNil)
+	c.Assert(got, gc.DeepEquals, offer)
+}
+
+func (s *OffersSuite) TestAddOfferAllEndpoints(c *gc.C) {
+	s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	offer, err := s.State.AddOffer("wordpress")
+	c.Assert(err, gc.IsNil)
+	var names []string
+	for _, ep := range offer.Endpoints() {
+		c.Assert(ep.Role, gc.Not(gc.Equals), charm.RolePeer)
+		c.Assert(ep.Scope, gc.Equals, charm.ScopeGlobal)
+		names = append(names, ep.Name)
+	}
+	c.Assert(names, jc.SameContents, []string{"db", "url", "cache"})
+}
+
+func (s *OffersSuite) TestAddOfferErrors(c *gc.C) {
+	s.AddTestingService(c, "riak", s.AddTestingCharm(c, "riak"))
+	s.AddTestingService(c, "logging", s.AddTestingCharm(c, "logging"))
+
+	_, err := s.State.AddOffer("mysql")
+	c.Assert(err, gc.ErrorMatches, `cannot offer service "mysql": service "mysql" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.State.AddOffer("riak", "ring")
+	c.Assert(err, gc.ErrorMatches, `cannot offer service "riak": cannot offer peer relation "ring"`)
+	_, err = s.State.AddOffer("riak", "nope")
+	c.Assert(err, gc.ErrorMatches, `cannot offer service "riak": service "riak" has no "nope" relation`)
+	_, err = s.State.AddOffer("logging", "info")
+	c.Assert(err, gc.ErrorMatches, `cannot offer service "logging": cannot offer container-scoped relation "info"`)
+}
+
+func (s *OffersSuite) TestAddOfferDuplicate(c *gc.C) {
+	s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	_, err := s.State.AddOffer("mysql")
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.AddOffer("mysql", "server")
+	c.Assert(err, gc.ErrorMatches, `cannot offer service "mysql": offer "testenv.mysql" already exists`)
+}
+
+func (s *OffersSuite) TestOffersSharedBetweenEnvironments(c *gc.C) {
+	cfg := testing.CustomEnvironConfig(c, testing.Attrs{"name": "sandbox"})
+	_, st, err := s.State.NewEnvironment(cfg, "bob")
+	c.Assert(err, gc.IsNil)
+	defer st.Close()
+
+	s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	_, err = s.State.AddOffer("mysql")
+	c.Assert(err, gc.IsNil)
+	svc, err := st.AddService("mysql", "user-admin", state.AddTestingCharm(c, st, "mysql"), nil)
+	c.Assert(err, gc.IsNil)
+	_, err = st.AddOffer(svc.Name())
+	c.Assert(err, gc.IsNil)
+
+	for _, st := range []*state.State{s.State, st} {
+		offers, err := st.Offers()
+		c.Assert(err, gc.IsNil)
+		c.Assert(offers, gc.HasLen, 2)
+		c.Assert(offers[0].URL(), gc.Equals, "sandbox.mysql")
+		c.Assert(offers[1].URL(), gc.Equals, "testenv.mysql")
+	}
+
+	// An environment can only withdraw its own offers.
+	err = st.RemoveOffer("testenv.mysql")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	err = s.State.RemoveOffer("testenv.mysql")
+	c.Assert(err, gc.IsNil)
+	_, err = st.Offer("testenv.mysql")
+	c.Assert(err, gc.ErrorMatches, `offer "testenv.mysql" not found`)
+	_, err = st.Offer("sandbox.mysql")
+	c.Assert(err, gc.IsNil)
+}
@@ -99,18 +99,29 @@ func Initialize(info *Info, cfg *config.Config, opts mongo.DialOpts, policy Poli
 	if err := checkEnvironConfig(cfg); err != nil {
 		return nil, err
 	}
-	uuid, err := utils.NewUUID()
-	if err != nil {
-		return nil, fmt.Errorf("environment UUID cannot be created: %v", err)
+	// The environment keeps the UUID it was prepared with, if
+	// any, so that its resources in the cloud are tagged with
+	// the same UUID as the environment in state.
+	uuid, ok := cfg.UUID()
+	if !ok {
+		newUUID, err := utils.NewUUID()
+		if err != nil {
+			return nil, fmt.Errorf("environment UUID cannot be created: %v", err)
+		}
+		uuid = newUUID.String()
 	}
+	// The State was opened before the environment existed,
+	// so it must be told which environment it is for.
+	st.envUUID = uuid
+	st.serverUUID = uuid
 	ops := []txn.Op{
 		createConstraintsOp(st, environGlobalKey, constraints.Value{}),
 		createSettingsOp(st, environGlobalKey, cfg.AllAttrs()),
-		createEnvironmentOp(st, cfg.Name(), uuid.String()),
+		createEnvironmentOp(st, cfg.Name(), uuid, uuid),
 		{
 			C:      st.stateServers.Name,
 			Id:     environGlobalKey,
-			Insert: &stateServersDoc{},
+			Insert: &stateServersDoc{EnvUUID: uuid},
 		}, {
 			C:      st.stateServers.Name,
 			Id:     apiHostPortsKey,
@@ -145,6 +156,8 @@ var indexes = []struct {
 	{"networkinterfaces", []string{"macaddress", "networkname"}, true},
 	{"networkinterfaces", []string{"networkname"}, false},
 	{"networkinterfaces", []string{"machineid"}, false},
+	{"subnets", []string{"spacename"}, false},
+	{"metrics", []string{"sent", "created"}, false},
 }
 
 // The capped collection used for transaction logs defaults to 10MB.
@@ -155,6 +168,14 @@ var (
 	logSizeTests = 1000000
 )
 
+// The capped collection used for the audit trail defaults to 100MB.
+// It is likewise tweaked in export_test.go to 1MB.
+var auditLogSize = 100000000
+
+// The capped collection holding agent log records defaults to 1GB.
+// It is likewise tweaked in export_test.go to 1MB.
+var agentLogSize = 1000000000
+
 func maybeUnauthorized(err error, msg string) error {
 	if err == nil {
 		return nil
@@ -202,35 +223,105 @@ func newState(session *mgo.Session, info *Info, policy Policy) (*State, error) {
 		}
 	}
 
+	uuid, legacy, err := serverEnvironUUID(db)
+	if err != nil {
+		return nil, maybeUnauthorized(err, "cannot open state")
+	}
+	st, err := openEnvironState(info, policy, session, uuid, uuid)
+	if err != nil {
+		return nil, err
+	}
+	auditInfo := mgo.CollectionInfo{Capped: true, MaxBytes: auditLogSize}
+	err = st.audit.Create(&auditInfo)
+	if err != nil && err.Error() != "collection already exists" {
+		return nil, maybeUnauthorized(err, "cannot create audit collection")
+	}
+	logsInfo := mgo.CollectionInfo{Capped: true, MaxBytes: agentLogSize}
+	err = st.logs.Create(&logsInfo)
+	if err != nil && err.Error() != "collection already exists" {
+		return nil, maybeUnauthorized(err, "cannot create logs collection")
+	}
+
+	// The documents of an older state server are found only once
+	// they have been scoped by the environment's UUID, which is done
+	// by an upgrade step; until then the state servers document
+	// must not record the UUID, or they would never be scoped.
+	if !legacy {
+		// TODO(rog) delete this when we can assume there are no
+		// pre-1.18 environments running.
+		if err := st.createStateServersDoc(); err != nil {
+			return nil, fmt.Errorf("cannot create state servers document: %v", err)
+		}
+	}
+	if err := st.createAPIAddressesDoc(); err != nil {
+		return nil, fmt.Errorf("cannot create API addresses document: %v", err)
+	}
+	if err := st.createStateServingInfoDoc(); err != nil {
+		return nil, fmt.Errorf("cannot create state serving info document: %v", err)
+	}
+	return st, nil
+}
+
+// openEnvironState returns a State for the environment with the
+// given UUID, hosted by the state server whose own environment has
+// the UUID serverUUID. The documents of all the environments are
+// held in the juju database, and agent presence is recorded in the
+// presence database.
+func openEnvironState(info *Info, policy Policy, session *mgo.Session, envUUID, serverUUID string) (*State, error) {
+	db := session.DB("juju")
 	st := &State{
-		info:              info,
-		policy:            policy,
-		db:                db,
-		environments:      db.C("environments"),
-		charms:            db.C("charms"),
-		machines:          db.C("machines"),
-		containerRefs:     db.C("containerRefs"),
-		instanceData:      db.C("instanceData"),
-		relations:         db.C("relations"),
-		relationScopes:    db.C("relationscopes"),
-		services:          db.C("services"),
-		requestedNetworks: db.C("requestednetworks"),
-		networks:          db.C("networks"),
-		networkInterfaces: db.C("networkinterfaces"),
-		minUnits:          db.C("minunits"),
-		settings:          db.C("settings"),
-		settingsrefs:      db.C("settingsrefs"),
-		constraints:       db.C("constraints"),
-		units:             db.C("units"),
-		actions:           db.C("actions"),
-		actionresults:     db.C("actionresults"),
-		users:             db.C("users"),
-		presence:          pdb.C("presence"),
-		cleanups:          db.C("cleanups"),
-		annotations:       db.C("annotations"),
-		statuses:          db.C("statuses"),
-		stateServers:      db.C("stateServers"),
+		info:       info,
+		policy:     policy,
+		db:         db,
+		presence:   session.DB("presence").C("presence"),
+		envUUID:    envUUID,
+		serverUUID: serverUUID,
 	}
+	coll := func(name string) *stateCollection {
+		return newStateCollection(st, db, name)
+	}
+	st.environments = coll("environments")
+	st.hostedEnvs = coll("hostedenvironments")
+	st.charms = coll("charms")
+	st.machines = coll("machines")
+	st.containerRefs = coll("containerRefs")
+	st.instanceData = coll("instanceData")
+	st.relations = coll("relations")
+	st.relationScopes = coll("relationscopes")
+	st.services = coll("services")
+	st.remoteServices = coll("remoteservices")
+	st.offers = coll("offers")
+	st.requestedNetworks = coll("requestednetworks")
+	st.networks = coll("networks")
+	st.networkInterfaces = coll("networkinterfaces")
+	st.subnets = coll("subnets")
+	st.spaces = coll("spaces")
+	st.minUnits = coll("minunits")
+	st.settings = coll("settings")
+	st.settingsrefs = coll("settingsrefs")
+	st.constraints = coll("constraints")
+	st.units = coll("units")
+	st.actions = coll("actions")
+	st.actionresults = coll("actionresults")
+	st.users = coll("users")
+	st.envUsers = coll("envusers")
+	st.cleanups = coll("cleanups")
+	st.annotations = coll("annotations")
+	st.statuses = coll("statuses")
+	st.workloadStatuses = coll("workloadstatuses")
+	st.stateServers = coll("stateServers")
+	st.backups = coll("backupsmetadata")
+	st.audit = coll("audit")
+	st.logs = coll("logs")
+	st.leases = coll("leases")
+	st.storageInstances = coll("storageinstances")
+	st.volumes = coll("volumes")
+	st.volumeAttachments = coll("volumeattachments")
+	st.storageCons = coll("storageconstraints")
+	st.debugRecordings = coll("debugrecordings")
+	st.metrics = coll("metrics")
+	st.sequences = coll("sequence")
+
 	log := db.C("txns.log")
 	logInfo := mgo.CollectionInfo{Capped: true, MaxBytes: logSize}
 	// The lack of error code for this error was reported upstream:
@@ -242,28 +333,142 @@ func newState(session *mgo.Session, info *Info, policy Policy) (*State, error) {
 	st.runner = txn.NewRunner(db.C("txns"))
 	st.runner.ChangeLog(db.C("txns.log"))
 	st.watcher = watcher.New(db.C("txns.log"))
-	st.pwatcher = presence.NewWatcher(pdb.C("presence"))
+	st.pwatcher = presence.NewWatcher(st.presence)
 	for _, item := range indexes {
-		index := mgo.Index{Key: item.key, Unique: item.unique}
+		key := item.key
+		if envScopedCollections.Contains(item.collection) {
+			// Keys need only be unique within an environment.
+			key = append([]string{envUUIDField}, key...)
+		}
+		index := mgo.Index{Key: key, Unique: item.unique}
 		if err := db.C(item.collection).EnsureIndex(index); err != nil {
 			return nil, fmt.Errorf("cannot create database index: %v", err)
 		}
 	}
 	st.transactionHooks = make(chan ([]transactionHook), 1)
 	st.transactionHooks <- nil
+	return st, nil
+}
 
-	// TODO(rog) delete this when we can assume there are no
-	// pre-1.18 environments running.
+// serverEnvironUUID returns the UUID of the state server's own
+// environment, as recorded in db, or the empty string if the state
+// has not yet been initialized. It also reports whether the
+// environment's documents predate the hosting of several
+// environments, and so are not yet scoped by its UUID.
+func serverEnvironUUID(db *mgo.Database) (uuid string, legacy bool, err error) {
+	var doc stateServersDoc
+	err = db.C("stateServers").FindId(environGlobalKey).One(&doc)
+	if err != nil && err != mgo.ErrNotFound {
+		return "", false, fmt.Errorf("cannot get state servers document: %v", err)
+	}
+	if doc.EnvUUID != "" {
+		return doc.EnvUUID, false, nil
+	}
+	// The state servers document of an older state server does
+	// not record the environment's UUID, but its environment is
+	// the only one there is.
+	var envDoc environmentDoc
+	err = db.C("environments").Find(nil).One(&envDoc)
+	if err == mgo.ErrNotFound {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, fmt.Errorf("cannot get environment: %v", err)
+	}
+	return envDoc.UUID, true, nil
+}
+
+// ScopeLegacyDocuments scopes the documents of a state server that
+// predates the hosting of several environments by the UUID of its
+// environment, which is the State's. Documents with string ids are
+// replaced by copies with prefixed ids; others just have the UUID
+// recorded. Until this is done, the State sees none of the
+// environment's documents. The state servers document records the
+// UUID last, so that the documents are scoped again should this be
+// interrupted; once it does, ScopeLegacyDocuments does nothing.
+func (st *State) ScopeLegacyDocuments() error {
+	_, legacy, err := serverEnvironUUID(st.db)
+	if err != nil || !legacy {
+		return err
+	}
+	logger.Infof("scoping documents by environment %s", st.envUUID)
+	// Every transaction must be complete before documents
+	// are replaced, as pending ones refer to the old ids.
+	if err := st.ResumeTransactions(); err != nil {
+		return err
+	}
+	for _, name := range envScopedCollections.SortedValues() {
+		coll := st.db.C(name)
+		unscoped := bson.D{{envUUIDField, bson.D{{"$exists", false}}}}
+		iter := coll.Find(unscoped).Iter()
+		var doc bson.RawD
+		for iter.Next(&doc) {
+			if err := st.scopeLegacyDocument(coll, doc); err != nil {
+				iter.Close()
+				return fmt.Errorf("cannot scope document in %q: %v", name, err)
+			}
+			doc = nil
+		}
+		if err := iter.Close(); err != nil {
+			return err
+		}
+	}
+	// The indexes created before documents were scoped would
+	// keep keys unique across all environments; they are
+	// created again when the state is next opened.
+	for _, item := range indexes {
+		if envScopedCollections.Contains(item.collection) {
+			// The index may never have been created.
+			st.db.C(item.collection).DropIndex(item.key...)
+		}
+	}
+	if err := st.db.C("environments").UpdateId(st.envUUID, bson.D{{"$set", bson.D{
+		{"server-uuid", st.serverUUID},
+	}}}); err != nil {
+		return err
+	}
+	// The state servers document of a pre-1.18 state server
+	// does not exist yet; it is created with the UUID, now
+	// that the state server machines can be found.
 	if err := st.createStateServersDoc(); err != nil {
-		return nil, fmt.Errorf("cannot create state servers document: %v", err)
+		return fmt.Errorf("cannot create state servers document: %v", err)
 	}
-	if err := st.createAPIAddressesDoc(); err != nil {
-		return nil, fmt.Errorf("cannot create API addresses document: %v", err)
+	return st.db.C("stateServers").UpdateId(environGlobalKey, bson.D{{"$set", bson.D{
+		{envUUIDField, st.serverUUID},
+	}}})
+}
+
+// scopeLegacyDocument scopes the given unscoped document in coll.
+func (st *State) scopeLegacyDocument(coll *mgo.Collection, doc bson.RawD) error {
+	var id interface{}
+	for _, field := range doc {
+		if field.Name == "_id" {
+			if err := field.Value.Unmarshal(&id); err != nil {
+				return err
+			}
+		}
 	}
-	if err := st.createStateServingInfoDoc(); err != nil {
-		return nil, fmt.Errorf("cannot create state serving info document: %v", err)
+	localID, ok := id.(string)
+	if !ok {
+		return coll.UpdateId(id, bson.D{{"$set", bson.D{{envUUIDField, st.envUUID}}}})
 	}
-	return st, nil
+	scoped := bson.RawD{{"_id", rawString(st.docID(localID))}}
+	for _, field := range doc {
+		// The transaction queue refers only to
+		// completed transactions, so it is dropped.
+		if field.Name != "_id" && field.Name != "txn-queue" {
+			scoped = append(scoped, field)
+		}
+	}
+	scoped = append(scoped, bson.RawDocElem{envUUIDField, rawString(st.envUUID)})
+	// The copy may have been inserted already, by an
+	// earlier attempt that was interrupted.
+	if err := coll.Insert(scoped); err != nil && !mgo.IsDup(err) {
+		return err
+	}
+	if err := coll.RemoveId(id); err != nil && err != mgo.ErrNotFound {
+		return err
+	}
+	return nil
 }
 
 // createStateServersDoc creates the state servers document
@@ -291,7 +496,7 @@ func (st *State) createStateServersDoc() error {
 	if err != nil {
 		return err
 	}
-	var doc stateServersDoc
+	doc := stateServersDoc{EnvUUID: st.serverUUID}
 	for _, m := range machineDocs {
 		doc.MachineIds = append(doc.MachineIds, m.Id)
 	}
@@ -346,8 +551,19 @@ func (st *State) createStateServingInfoDoc() error {
 	return onAbort(st.runTransaction(ops), nil)
 }
 
-// CACert returns the certificate used to validate the state connection.
+// CACert returns the certificates used to validate the state and API
+// connections. Once SetCACert has been called, these are the CA
+// certificates it recorded; until then, it's the certificate the State
+// was opened with.
 func (st *State) CACert() string {
+	var doc apiHostPortsDoc
+	err := st.stateServers.FindId(apiHostPortsKey).One(&doc)
+	if err != nil && err != mgo.ErrNotFound {
+		logger.Warningf("cannot get CA certificates: %v", err)
+	}
+	if doc.CACert != "" {
+		return doc.CACert
+	}
 	return st.info.CACert
 }
 
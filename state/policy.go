@@ -79,6 +79,11 @@ type EnvironCapability interface {
 	// does not support unit placement, then machines may not be created
 	// without units, and units cannot be placed explcitly.
 	SupportsUnitPlacement() error
+
+	// SupportsSourceCIDRs returns an error which, if non-nil, indicates
+	// that the environment cannot restrict access to the open ports of
+	// an exposed service to source CIDRs.
+	SupportsSourceCIDRs() error
 }
 
 // precheckInstance calls the state's assigned policy, if non-nil, to obtain
@@ -192,6 +197,29 @@ func (st *State) supportsUnitPlacement() error {
 	return capability.SupportsUnitPlacement()
 }
 
+// supportsSourceCIDRs calls the state's assigned policy, if non-nil,
+// to obtain an EnvironCapability, and calls SupportsSourceCIDRs if a
+// non-nil EnvironCapability is returned.
+func (st *State) supportsSourceCIDRs() error {
+	if st.policy == nil {
+		return nil
+	}
+	cfg, err := st.EnvironConfig()
+	if err != nil {
+		return err
+	}
+	capability, err := st.policy.EnvironCapability(cfg)
+	if errors.IsNotImplemented(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if capability == nil {
+		return fmt.Errorf("policy returned nil EnvironCapability without an error")
+	}
+	return capability.SupportsSourceCIDRs()
+}
+
 // InstanceDistributor is a policy interface that is provided
 // to State to perform distribution of units across instances
 // for high availability.
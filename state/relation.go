@@ -142,7 +142,7 @@ func (r *Relation) destroyOps(ignoreService string) (ops []txn.Op, isRemove bool
 		return nil, false, errAlreadyDying
 	}
 	if r.doc.UnitCount == 0 {
-		removeOps, err := r.removeOps(ignoreService, nil)
+		removeOps, err := r.removeOps(ignoreService, "")
 		if err != nil {
 			return nil, false, err
 		}
@@ -158,16 +158,16 @@ func (r *Relation) destroyOps(ignoreService string) (ops []txn.Op, isRemove bool
 
 // removeOps returns the operations necessary to remove the relation. If
 // ignoreService is not empty, no operations affecting that service will be
-// included; if departingUnit is not nil, this implies that the relation's
+// included; if departingUnit is not empty, this implies that the relation's
 // services may be Dying and otherwise unreferenced, and may thus require
 // removal themselves.
-func (r *Relation) removeOps(ignoreService string, departingUnit *Unit) ([]txn.Op, error) {
+func (r *Relation) removeOps(ignoreService string, departingUnit string) ([]txn.Op, error) {
 	relOp := txn.Op{
 		C:      r.st.relations.Name,
 		Id:     r.doc.Key,
 		Remove: true,
 	}
-	if departingUnit != nil {
+	if departingUnit != "" {
 		relOp.Assert = bson.D{{"life", Dying}, {"unitcount", 1}}
 	} else {
 		relOp.Assert = bson.D{{"life", Alive}, {"unitcount", 0}}
@@ -179,12 +179,29 @@ func (r *Relation) removeOps(ignoreService string, departingUnit *Unit) ([]txn.O
 		}
 		var asserts bson.D
 		hasRelation := bson.D{{"relationcount", bson.D{{"$gt", 0}}}}
-		if departingUnit == nil {
+		if remote, err := r.st.RemoteService(ep.ServiceName); err == nil {
+			// Remote services exist only to take part in relations,
+			// so they are removed along with their last relation.
+			if remote.doc.RelationCount == 1 {
+				ops = append(ops, remote.removeOp(bson.D{{"relationcount", 1}}))
+			} else {
+				ops = append(ops, txn.Op{
+					C:      r.st.remoteServices.Name,
+					Id:     ep.ServiceName,
+					Assert: bson.D{{"relationcount", bson.D{{"$gt", 1}}}},
+					Update: bson.D{{"$inc", bson.D{{"relationcount", -1}}}},
+				})
+			}
+			continue
+		} else if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		if departingUnit == "" {
 			// We're constructing a destroy operation, either of the relation
 			// or one of its services, and can therefore be assured that both
 			// services are Alive.
 			asserts = append(hasRelation, isAliveDoc...)
-		} else if ep.ServiceName == departingUnit.ServiceName() {
+		} else if ep.ServiceName == names.UnitService(departingUnit) {
 			// This service must have at least one unit -- the one that's
 			// departing the relation -- so it cannot be ready for removal.
 			cannotDieYet := bson.D{{"unitcount", bson.D{{"$gt", 0}}}}
@@ -14,6 +14,8 @@ import (
 	"labix.org/v2/mgo"
 	"labix.org/v2/mgo/bson"
 	"labix.org/v2/mgo/txn"
+
+	"github.com/juju/juju/network"
 )
 
 // RelationUnit holds information about a single unit in a relation, and
@@ -38,8 +40,32 @@ func (ru *RelationUnit) Endpoint() Endpoint {
 }
 
 // PrivateAddress returns the private address of the unit and whether it is valid.
+// If the unit's service binds the relation endpoint to a space, and the unit
+// has an address in that space, that address is returned instead.
 func (ru *RelationUnit) PrivateAddress() (string, bool) {
-	return ru.unit.PrivateAddress()
+	svc, err := ru.unit.Service()
+	if err != nil {
+		unitLogger.Errorf("unit %v cannot get service: %v", ru.unit, err)
+		return ru.unit.PrivateAddress()
+	}
+	space, ok := svc.EndpointBindings()[ru.endpoint.Name]
+	if !ok {
+		return ru.unit.PrivateAddress()
+	}
+	addresses, err := ru.st.spaceAddresses(space, ru.unit.addressesOfMachine())
+	if err != nil {
+		unitLogger.Errorf("unit %v cannot get addresses in space %q: %v", ru.unit, space, err)
+		return ru.unit.PrivateAddress()
+	}
+	if len(addresses) == 0 {
+		unitLogger.Warningf("unit %v has no address in space %q", ru.unit, space)
+		return ru.unit.PrivateAddress()
+	}
+	privateAddress := network.SelectInternalAddress(addresses, false)
+	if privateAddress == "" {
+		privateAddress = addresses[0].Value
+	}
+	return privateAddress, true
 }
 
 // ErrCannotEnterScope indicates that a relation unit failed to enter its scope
@@ -208,7 +234,7 @@ func (ru *RelationUnit) subordinateOps() ([]txn.Op, string, error) {
 		if err != nil {
 			return nil, "", err
 		}
-		_, ops, err := service.addUnitOps(unitName, nil)
+		_, ops, err := service.addUnitOps(unitName, nil, nil)
 		return ops, "", err
 	} else if err != nil {
 		return nil, "", err
@@ -253,6 +279,13 @@ func (ru *RelationUnit) LeaveScope() error {
 	if err != nil {
 		return err
 	}
+	return ru.relation.leaveScope(key, ru.unit.Name())
+}
+
+// leaveScope removes the scope document with the given key, belonging
+// to the named unit, on behalf of both RelationUnit and
+// RemoteRelationUnit.
+func (r *Relation) leaveScope(key, unitName string) error {
 	// The logic below is involved because we remove a dying relation
 	// with the last unit that leaves a scope in it. It handles three
 	// possible cases:
@@ -275,48 +308,48 @@ func (ru *RelationUnit) LeaveScope() error {
 	// to have a Dying relation with a smaller-than-real unit count, because
 	// Destroy changes the Life attribute in memory (units could join before
 	// the database is actually changed).
-	desc := fmt.Sprintf("unit %q in relation %q", ru.unit, ru.relation)
+	desc := fmt.Sprintf("unit %q in relation %q", unitName, r)
 	for attempt := 0; attempt < 3; attempt++ {
-		count, err := ru.st.relationScopes.FindId(key).Count()
+		count, err := r.st.relationScopes.FindId(key).Count()
 		if err != nil {
 			return fmt.Errorf("cannot examine scope for %s: %v", desc, err)
 		} else if count == 0 {
 			return nil
 		}
 		ops := []txn.Op{{
-			C:      ru.st.relationScopes.Name,
+			C:      r.st.relationScopes.Name,
 			Id:     key,
 			Assert: txn.DocExists,
 			Remove: true,
 		}}
-		if ru.relation.doc.Life == Alive {
+		if r.doc.Life == Alive {
 			ops = append(ops, txn.Op{
-				C:      ru.st.relations.Name,
-				Id:     ru.relation.doc.Key,
+				C:      r.st.relations.Name,
+				Id:     r.doc.Key,
 				Assert: bson.D{{"life", Alive}},
 				Update: bson.D{{"$inc", bson.D{{"unitcount", -1}}}},
 			})
-		} else if ru.relation.doc.UnitCount > 1 {
+		} else if r.doc.UnitCount > 1 {
 			ops = append(ops, txn.Op{
-				C:      ru.st.relations.Name,
-				Id:     ru.relation.doc.Key,
+				C:      r.st.relations.Name,
+				Id:     r.doc.Key,
 				Assert: bson.D{{"unitcount", bson.D{{"$gt", 1}}}},
 				Update: bson.D{{"$inc", bson.D{{"unitcount", -1}}}},
 			})
 		} else {
-			relOps, err := ru.relation.removeOps("", ru.unit)
+			relOps, err := r.removeOps("", unitName)
 			if err != nil {
 				return err
 			}
 			ops = append(ops, relOps...)
 		}
-		if err = ru.st.runTransaction(ops); err != txn.ErrAborted {
+		if err = r.st.runTransaction(ops); err != txn.ErrAborted {
 			if err != nil {
 				return fmt.Errorf("cannot leave scope for %s: %v", desc, err)
 			}
 			return err
 		}
-		if err := ru.relation.Refresh(); errors.IsNotFound(err) {
+		if err := r.Refresh(); errors.IsNotFound(err) {
 			return nil
 		} else if err != nil {
 			return err
@@ -844,6 +844,37 @@ func addRU(c *gc.C, svc *state.Service, rel *state.Relation, principal *state.Un
 	return u, ru
 }
 
+func (s *RelationUnitSuite) TestPrivateAddressWithEndpointBinding(c *gc.C) {
+	prr := NewProReqRelation(c, &s.ConnSuite, charm.ScopeGlobal)
+	for _, cidr := range []string{"10.0.0.0/24", "10.0.1.0/24"} {
+		_, err := s.State.AddSubnet(state.SubnetInfo{CIDR: cidr})
+		c.Assert(err, gc.IsNil)
+	}
+	_, err := s.State.AddSpace("db", []string{"10.0.1.0/24"})
+	c.Assert(err, gc.IsNil)
+	err = prr.pu0.AssignToNewMachine()
+	c.Assert(err, gc.IsNil)
+	mId, err := prr.pu0.AssignedMachineId()
+	c.Assert(err, gc.IsNil)
+	machine, err := s.State.Machine(mId)
+	c.Assert(err, gc.IsNil)
+	err = machine.SetAddresses(
+		network.NewAddress("10.0.0.5", network.ScopeCloudLocal),
+		network.NewAddress("10.0.1.5", network.ScopeCloudLocal),
+	)
+	c.Assert(err, gc.IsNil)
+
+	address, ok := prr.pru0.PrivateAddress()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(address, gc.Equals, "10.0.0.5")
+
+	err = prr.psvc.SetEndpointBindings(map[string]string{"server": "db"})
+	c.Assert(err, gc.IsNil)
+	address, ok = prr.pru0.PrivateAddress()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(address, gc.Equals, "10.0.1.5")
+}
+
 type WatchScopeSuite struct {
 	ConnSuite
 }
@@ -0,0 +1,199 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"labix.org/v2/mgo/bson"
+	"labix.org/v2/mgo/txn"
+)
+
+// RemoteRelationUnit holds information about a single unit of a remote
+// service in a relation. Remote units have no presence in the
+// environment other than their membership of the relation's scope and
+// their settings within it, which are maintained on their behalf as
+// the remote environment reports changes to them.
+type RemoteRelationUnit struct {
+	st       *State
+	relation *Relation
+	unitName string
+	endpoint Endpoint
+	key      string
+}
+
+// RemoteUnit returns a RemoteRelationUnit for the named unit of a
+// remote service taking part in the relation.
+func (r *Relation) RemoteUnit(unitName string) (*RemoteRelationUnit, error) {
+	if !names.IsUnit(unitName) {
+		return nil, fmt.Errorf("%q is not a valid unit name", unitName)
+	}
+	serviceName := names.UnitService(unitName)
+	ep, err := r.Endpoint(serviceName)
+	if err != nil {
+		return nil, err
+	}
+	if isRemote, err := r.st.isRemoteService(serviceName); err != nil {
+		return nil, err
+	} else if !isRemote {
+		return nil, fmt.Errorf("service %q is not a remote service", serviceName)
+	}
+	// Remote services only take part in globally scoped relations,
+	// so the key has no container part.
+	key := fmt.Sprintf("r#%d#%s#%s", r.doc.Id, ep.Role, unitName)
+	return &RemoteRelationUnit{
+		st:       r.st,
+		relation: r,
+		unitName: unitName,
+		endpoint: ep,
+		key:      key,
+	}, nil
+}
+
+// Relation returns the relation associated with the unit.
+func (ru *RemoteRelationUnit) Relation() *Relation {
+	return ru.relation
+}
+
+// UnitName returns the name of the remote unit.
+func (ru *RemoteRelationUnit) UnitName() string {
+	return ru.unitName
+}
+
+// Endpoint returns the relation endpoint that defines the unit's
+// participation in the relation.
+func (ru *RemoteRelationUnit) Endpoint() Endpoint {
+	return ru.endpoint
+}
+
+// EnterScope ensures that the remote unit has entered its scope in the
+// relation, with the supplied settings. If the unit is already in
+// scope, its settings are brought up to date with those supplied;
+// watchers only see a change when the settings actually differ.
+func (ru *RemoteRelationUnit) EnterScope(settings map[string]interface{}) error {
+	if inScope, err := ru.InScope(); err != nil {
+		return err
+	} else if inScope {
+		return ru.replaceSettings(settings)
+	}
+	serviceName, relationKey := ru.endpoint.ServiceName, ru.relation.doc.Key
+	ops := []txn.Op{{
+		C:      ru.st.remoteServices.Name,
+		Id:     serviceName,
+		Assert: isAliveDoc,
+	}, {
+		C:      ru.st.relations.Name,
+		Id:     relationKey,
+		Assert: isAliveDoc,
+		Update: bson.D{{"$inc", bson.D{{"unitcount", 1}}}},
+	}}
+	// The settings document is left behind when a unit leaves scope, so
+	// that it remains readable for as long as the relation exists; a
+	// unit reentering scope overwrites it.
+	settingsChanged := func() (bool, error) { return false, nil }
+	if count, err := ru.st.settings.FindId(ru.key).Count(); err != nil {
+		return err
+	} else if count == 0 {
+		ops = append(ops, createSettingsOp(ru.st, ru.key, settings))
+	} else {
+		var rop txn.Op
+		rop, settingsChanged, err = replaceSettingsOp(ru.st, ru.key, settings)
+		if err != nil {
+			return err
+		}
+		ops = append(ops, rop)
+	}
+	ops = append(ops, txn.Op{
+		C:      ru.st.relationScopes.Name,
+		Id:     ru.key,
+		Assert: txn.DocMissing,
+		Insert: relationScopeDoc{Key: ru.key},
+	})
+	if err := ru.st.runTransaction(ops); err != txn.ErrAborted {
+		return err
+	}
+	if inScope, err := ru.InScope(); err != nil {
+		return err
+	} else if inScope {
+		return ru.replaceSettings(settings)
+	}
+	if alive, err := isAlive(ru.st.remoteServices, serviceName); err != nil {
+		return err
+	} else if !alive {
+		return ErrCannotEnterScope
+	}
+	if alive, err := isAlive(ru.st.relations, relationKey); err != nil {
+		return err
+	} else if !alive {
+		return ErrCannotEnterScope
+	}
+	prefix := fmt.Sprintf("cannot enter scope for remote unit %q in relation %q: ", ru.unitName, ru.relation)
+	if changed, err := settingsChanged(); err != nil {
+		return err
+	} else if changed {
+		return fmt.Errorf(prefix + "concurrent settings change detected")
+	}
+	return fmt.Errorf(prefix + "inconsistent state in EnterScope")
+}
+
+// replaceSettings replaces the unit's settings with those supplied.
+func (ru *RemoteRelationUnit) replaceSettings(settings map[string]interface{}) error {
+	node, err := ru.Settings()
+	if err != nil {
+		return err
+	}
+	for key := range node.Map() {
+		if _, ok := settings[key]; !ok {
+			node.Delete(key)
+		}
+	}
+	node.Update(settings)
+	_, err = node.Write()
+	return err
+}
+
+// LeaveScope signals that the remote unit has left its scope in the
+// relation. If the relation is dying when its last member unit leaves,
+// it is removed immediately. It is not an error to leave a scope that
+// the unit is not, or never was, a member of.
+func (ru *RemoteRelationUnit) LeaveScope() error {
+	return ru.relation.leaveScope(ru.key, ru.unitName)
+}
+
+// InScope returns whether the remote unit has entered scope and not
+// left it.
+func (ru *RemoteRelationUnit) InScope() (bool, error) {
+	count, err := ru.st.relationScopes.FindId(ru.key).Count()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Settings returns a Settings which allows access to the remote unit's
+// settings within the relation.
+func (ru *RemoteRelationUnit) Settings() (*Settings, error) {
+	return readSettings(ru.st, ru.key)
+}
+
+// JoinedUnits returns the names of the units, local or remote, that
+// have entered the relation's scope and have not prepared to leave it.
+func (r *Relation) JoinedUnits() (_ []string, err error) {
+	defer errors.Maskf(&err, "cannot get units of relation %q", r)
+	sel := bson.D{
+		{"_id", bson.D{{"$regex", fmt.Sprintf("^r#%d#", r.doc.Id)}}},
+		{"departing", bson.D{{"$ne", true}}},
+	}
+	var docs []relationScopeDoc
+	if err := r.st.relationScopes.Find(sel).All(&docs); err != nil {
+		return nil, err
+	}
+	unitNames := make([]string, len(docs))
+	for i, doc := range docs {
+		unitNames[i] = doc.unitName()
+	}
+	return unitNames, nil
+}
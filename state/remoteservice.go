@@ -0,0 +1,312 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/juju/charm"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/bson"
+	"labix.org/v2/mgo/txn"
+)
+
+// RemoteService represents, within an environment, a service that is
+// deployed in another environment hosted by the same state server.
+// Local services may relate to a remote service exactly as they
+// would to any other; the units of the remote service enter and leave
+// the relation's scope by way of RemoteRelationUnit, as the remote
+// environment reports them.
+//
+// A remote service is added to the environment consuming an offer
+// when it first relates to it, and to the offering environment to
+// stand for the consuming service. It is removed along with its
+// last relation.
+type RemoteService struct {
+	st  *State
+	doc remoteServiceDoc
+}
+
+// remoteServiceDoc represents the internal state of a remote service
+// in MongoDB. Its name shares a namespace with that of local services.
+type remoteServiceDoc struct {
+	Name          string `bson:"_id"`
+	OfferURL      string
+	SourceEnvUUID string
+	Endpoints     []charm.Relation
+	Life          Life
+	RelationCount int
+}
+
+func newRemoteService(st *State, doc *remoteServiceDoc) *RemoteService {
+	return &RemoteService{
+		st:  st,
+		doc: *doc,
+	}
+}
+
+// Name returns the name of the remote service, which is the
+// name of the service in its own environment.
+func (s *RemoteService) Name() string {
+	return s.doc.Name
+}
+
+func (s *RemoteService) String() string {
+	return s.doc.Name
+}
+
+// OfferURL returns the URL of the offer through which the remote
+// service was consumed. It is empty when the remote service stands
+// for a service that consumes an offer of this environment.
+func (s *RemoteService) OfferURL() string {
+	return s.doc.OfferURL
+}
+
+// SourceEnvUUID returns the UUID of the environment in which
+// the service is deployed.
+func (s *RemoteService) SourceEnvUUID() string {
+	return s.doc.SourceEnvUUID
+}
+
+// Life returns whether the remote service is Alive, Dying or Dead.
+func (s *RemoteService) Life() Life {
+	return s.doc.Life
+}
+
+// Endpoints returns the remote service's endpoints that may
+// take part in relations.
+func (s *RemoteService) Endpoints() ([]Endpoint, error) {
+	var eps []Endpoint
+	for _, rel := range s.doc.Endpoints {
+		eps = append(eps, Endpoint{
+			ServiceName: s.doc.Name,
+			Relation:    rel,
+		})
+	}
+	sort.Sort(epSlice(eps))
+	return eps, nil
+}
+
+// Endpoint returns the relation endpoint with the supplied name, if it exists.
+func (s *RemoteService) Endpoint(relationName string) (Endpoint, error) {
+	for _, rel := range s.doc.Endpoints {
+		if rel.Name == relationName {
+			return Endpoint{
+				ServiceName: s.doc.Name,
+				Relation:    rel,
+			}, nil
+		}
+	}
+	return Endpoint{}, fmt.Errorf("remote service %q has no %q relation", s, relationName)
+}
+
+// Destroy ensures that the remote service and its relations will be
+// removed at some point. A remote service that takes part in no
+// relations is removed immediately; otherwise it is removed along
+// with its last relation.
+func (s *RemoteService) Destroy() (err error) {
+	defer errors.Maskf(&err, "cannot destroy remote service %q", s)
+	defer func() {
+		if err == nil {
+			// This is a white lie; the document might actually be removed.
+			s.doc.Life = Dying
+		}
+	}()
+	svc := &RemoteService{st: s.st, doc: s.doc}
+	for i := 0; i < 5; i++ {
+		switch ops, err := svc.destroyOps(); err {
+		case errRefresh:
+		case errAlreadyDying:
+			return nil
+		case nil:
+			if err := svc.st.runTransaction(ops); err != txn.ErrAborted {
+				return err
+			}
+		default:
+			return err
+		}
+		if err := svc.Refresh(); errors.IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	return ErrExcessiveContention
+}
+
+// destroyOps returns the operations required to destroy the remote
+// service. If it returns errRefresh, the remote service should be
+// refreshed and the destruction operations recalculated.
+func (s *RemoteService) destroyOps() ([]txn.Op, error) {
+	if s.doc.Life == Dying {
+		return nil, errAlreadyDying
+	}
+	rels, err := serviceRelations(s.st, s.doc.Name)
+	if err != nil {
+		return nil, err
+	}
+	if len(rels) != s.doc.RelationCount {
+		// The relations obtained may still be wrong, but that will
+		// be caught by the asserts on relationcount below.
+		return nil, errRefresh
+	}
+	var ops []txn.Op
+	removeCount := 0
+	for _, rel := range rels {
+		relOps, isRemove, err := rel.destroyOps(s.doc.Name)
+		if err == errAlreadyDying {
+			relOps = []txn.Op{{
+				C:      s.st.relations.Name,
+				Id:     rel.doc.Key,
+				Assert: bson.D{{"life", Dying}},
+			}}
+		} else if err != nil {
+			return nil, err
+		}
+		if isRemove {
+			removeCount++
+		}
+		ops = append(ops, relOps...)
+	}
+	// If all the remote service's relations will be removed,
+	// it can be removed too.
+	if s.doc.RelationCount == removeCount {
+		hasLastRefs := bson.D{{"life", Alive}, {"relationcount", removeCount}}
+		return append(ops, s.removeOp(hasLastRefs)), nil
+	}
+	// Otherwise it will be removed along with its last relation.
+	update := bson.D{{"$set", bson.D{{"life", Dying}}}}
+	if removeCount != 0 {
+		decref := bson.D{{"$inc", bson.D{{"relationcount", -removeCount}}}}
+		update = append(update, decref...)
+	}
+	return append(ops, txn.Op{
+		C:      s.st.remoteServices.Name,
+		Id:     s.doc.Name,
+		Assert: bson.D{{"life", Alive}, {"relationcount", s.doc.RelationCount}},
+		Update: update,
+	}), nil
+}
+
+// removeOp returns the operation required to remove the remote
+// service. Supplied asserts will be included in the operation.
+func (s *RemoteService) removeOp(asserts bson.D) txn.Op {
+	return txn.Op{
+		C:      s.st.remoteServices.Name,
+		Id:     s.doc.Name,
+		Assert: asserts,
+		Remove: true,
+	}
+}
+
+// Refresh refreshes the contents of the remote service from the
+// underlying state.
+func (s *RemoteService) Refresh() error {
+	err := s.st.remoteServices.FindId(s.doc.Name).One(&s.doc)
+	if err == mgo.ErrNotFound {
+		return errors.NotFoundf("remote service %q", s)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot refresh remote service %q: %v", s, err)
+	}
+	return nil
+}
+
+// AddRemoteService adds a remote service with the given name, standing
+// for the service deployed in the environment with the given UUID. The
+// offer URL is empty unless the remote service is being added to
+// consume an offer.
+func (st *State) AddRemoteService(name, offerURL, sourceEnvUUID string, endpoints []charm.Relation) (_ *RemoteService, err error) {
+	defer errors.Maskf(&err, "cannot add remote service %q", name)
+	if !names.IsService(name) {
+		return nil, fmt.Errorf("invalid name")
+	}
+	if sourceEnvUUID == "" {
+		return nil, fmt.Errorf("no source environment specified")
+	}
+	for _, ep := range endpoints {
+		if ep.Role == charm.RolePeer || ep.Scope != charm.ScopeGlobal {
+			return nil, fmt.Errorf("endpoint %q cannot be related to remotely", ep.Name)
+		}
+	}
+	env, err := st.Environment()
+	if err != nil {
+		return nil, err
+	} else if env.Life() != Alive {
+		return nil, fmt.Errorf("environment is no longer alive")
+	}
+	doc := &remoteServiceDoc{
+		Name:          name,
+		OfferURL:      offerURL,
+		SourceEnvUUID: sourceEnvUUID,
+		Endpoints:     endpoints,
+		Life:          Alive,
+	}
+	ops := []txn.Op{
+		env.assertAliveOp(),
+		{
+			C:      st.services.Name,
+			Id:     name,
+			Assert: txn.DocMissing,
+		},
+		{
+			C:      st.remoteServices.Name,
+			Id:     name,
+			Assert: txn.DocMissing,
+			Insert: doc,
+		},
+	}
+	if err := st.runTransaction(ops); err == txn.ErrAborted {
+		if err := env.Refresh(); (err == nil && env.Life() != Alive) || errors.IsNotFound(err) {
+			return nil, fmt.Errorf("environment is no longer alive")
+		} else if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("service already exists")
+	} else if err != nil {
+		return nil, err
+	}
+	return newRemoteService(st, doc), nil
+}
+
+// RemoteService returns a remote service by name.
+func (st *State) RemoteService(name string) (*RemoteService, error) {
+	if !names.IsService(name) {
+		return nil, fmt.Errorf("%q is not a valid service name", name)
+	}
+	doc := &remoteServiceDoc{}
+	err := st.remoteServices.FindId(name).One(doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("remote service %q", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot get remote service %q: %v", name, err)
+	}
+	return newRemoteService(st, doc), nil
+}
+
+// AllRemoteServices returns all the remote services in the environment.
+func (st *State) AllRemoteServices() ([]*RemoteService, error) {
+	var docs []remoteServiceDoc
+	if err := st.remoteServices.Find(bson.D{}).All(&docs); err != nil {
+		return nil, fmt.Errorf("cannot get all remote services: %v", err)
+	}
+	services := make([]*RemoteService, len(docs))
+	for i := range docs {
+		services[i] = newRemoteService(st, &docs[i])
+	}
+	return services, nil
+}
+
+// isRemoteService returns whether the named service is a remote service.
+func (st *State) isRemoteService(name string) (bool, error) {
+	count, err := st.remoteServices.FindId(name).Count()
+	if err != nil {
+		return false, fmt.Errorf("cannot get remote service %q: %v", name, err)
+	}
+	return count > 0, nil
+}
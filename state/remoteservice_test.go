@@ -0,0 +1,344 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"sort"
+	"time"
+
+	"github.com/juju/charm"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/testing"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type RemoteServiceSuite struct {
+	ConnSuite
+	wordpress *state.Service
+}
+
+var _ = gc.Suite(&RemoteServiceSuite{})
+
+const remoteEnvUUID = "deadbeef-0bad-400d-8000-4b1d0d06f00d"
+
+var mysqlEndpoints = []charm.Relation{{
+	Name:      "server",
+	Role:      charm.RoleProvider,
+	Interface: "mysql",
+	Scope:     charm.ScopeGlobal,
+}}
+
+func (s *RemoteServiceSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.wordpress = s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+}
+
+func (s *RemoteServiceSuite) addRelation(c *gc.C) *state.Relation {
+	_, err := s.State.AddRemoteService("mysql", "sandbox.mysql", remoteEnvUUID, mysqlEndpoints)
+	c.Assert(err, gc.IsNil)
+	eps, err := s.State.InferEndpoints([]string{"wordpress", "mysql"})
+	c.Assert(err, gc.IsNil)
+	rel, err := s.State.AddRelation(eps...)
+	c.Assert(err, gc.IsNil)
+	return rel
+}
+
+func (s *RemoteServiceSuite) TestAddRemoteService(c *gc.C) {
+	svc, err := s.State.AddRemoteService("mysql", "sandbox.mysql", remoteEnvUUID, mysqlEndpoints)
+	c.Assert(err, gc.IsNil)
+	c.Assert(svc.Name(), gc.Equals, "mysql")
+	c.Assert(svc.OfferURL(), gc.Equals, "sandbox.mysql")
+	c.Assert(svc.SourceEnvUUID(), gc.Equals, remoteEnvUUID)
+	c.Assert(svc.Life(), gc.Equals, state.Alive)
+	ep, err := svc.Endpoint("server")
+	c.Assert(err, gc.IsNil)
+	c.Assert(ep, gc.DeepEquals, state.Endpoint{ServiceName: "mysql", Relation: mysqlEndpoints[0]})
+	_, err = svc.Endpoint("db")
+	c.Assert(err, gc.ErrorMatches, `remote service "mysql" has no "db" relation`)
+
+	got, err := s.State.RemoteService("mysql")
+	c.Assert(err, gc.IsNil)
+	c.Assert(got.OfferURL(), gc.Equals, "sandbox.mysql")
+	all, err := s.State.AllRemoteServices()
+	c.Assert(err, gc.IsNil)
+	c.Assert(all, gc.HasLen, 1)
+	c.Assert(all[0].Name(), gc.Equals, "mysql")
+
+	_, err = s.State.RemoteService("wordpress")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *RemoteServiceSuite) TestAddRemoteServiceErrors(c *gc.C) {
+	_, err := s.State.AddRemoteService("my.sql", "", remoteEnvUUID, mysqlEndpoints)
+	c.Assert(err, gc.ErrorMatches, `cannot add remote service "my.sql": invalid name`)
+	_, err = s.State.AddRemoteService("mysql", "", "", mysqlEndpoints)
+	c.Assert(err, gc.ErrorMatches, `cannot add remote service "mysql": no source environment specified`)
+	peers := []charm.Relation{{Name: "ring", Role: charm.RolePeer, Interface: "riak", Scope: charm.ScopeGlobal}}
+	_, err = s.State.AddRemoteService("riak", "", remoteEnvUUID, peers)
+	c.Assert(err, gc.ErrorMatches, `cannot add remote service "riak": endpoint "ring" cannot be related to remotely`)
+}
+
+func (s *RemoteServiceSuite) TestServiceNamesShared(c *gc.C) {
+	_, err := s.State.AddRemoteService("wordpress", "", remoteEnvUUID, mysqlEndpoints)
+	c.Assert(err, gc.ErrorMatches, `cannot add remote service "wordpress": service already exists`)
+	_, err = s.State.AddRemoteService("mysql", "", remoteEnvUUID, mysqlEndpoints)
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.AddService("mysql", "user-admin", s.AddTestingCharm(c, "mysql"), nil)
+	c.Assert(err, gc.ErrorMatches, `cannot add service "mysql": service already exists`)
+}
+
+func (s *RemoteServiceSuite) TestAddRelation(c *gc.C) {
+	rel := s.addRelation(c)
+	c.Assert(rel.String(), gc.Equals, "wordpress:db mysql:server")
+	ep, err := rel.Endpoint("mysql")
+	c.Assert(err, gc.IsNil)
+	c.Assert(ep.Relation, gc.DeepEquals, mysqlEndpoints[0])
+
+	// With no units in scope, the relation is removed immediately,
+	// and the remote service along with it.
+	err = rel.Destroy()
+	c.Assert(err, gc.IsNil)
+	err = rel.Refresh()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.State.RemoteService("mysql")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	s.addRelation(c)
+}
+
+func (s *RemoteServiceSuite) TestRemoteServiceKeptWhileRelated(c *gc.C) {
+	rel := s.addRelation(c)
+	ch, _, err := s.wordpress.Charm()
+	c.Assert(err, gc.IsNil)
+	s.AddTestingService(c, "phpmyadmin", ch)
+	eps, err := s.State.InferEndpoints([]string{"phpmyadmin", "mysql"})
+	c.Assert(err, gc.IsNil)
+	other, err := s.State.AddRelation(eps...)
+	c.Assert(err, gc.IsNil)
+
+	err = rel.Destroy()
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.RemoteService("mysql")
+	c.Assert(err, gc.IsNil)
+	err = other.Destroy()
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.RemoteService("mysql")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *RemoteServiceSuite) TestDestroyRemoteService(c *gc.C) {
+	svc, err := s.State.AddRemoteService("mysql", "sandbox.mysql", remoteEnvUUID, mysqlEndpoints)
+	c.Assert(err, gc.IsNil)
+	// An unrelated remote service is removed immediately.
+	err = svc.Destroy()
+	c.Assert(err, gc.IsNil)
+	err = svc.Refresh()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	// A related one is removed with its last relation.
+	rel := s.addRelation(c)
+	rru, err := rel.RemoteUnit("mysql/0")
+	c.Assert(err, gc.IsNil)
+	err = rru.EnterScope(nil)
+	c.Assert(err, gc.IsNil)
+	svc, err = s.State.RemoteService("mysql")
+	c.Assert(err, gc.IsNil)
+	err = svc.Destroy()
+	c.Assert(err, gc.IsNil)
+	err = svc.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(svc.Life(), gc.Equals, state.Dying)
+	err = rel.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(rel.Life(), gc.Equals, state.Dying)
+
+	err = rru.LeaveScope()
+	c.Assert(err, gc.IsNil)
+	err = rel.Refresh()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	err = svc.Refresh()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *RemoteServiceSuite) TestAddRelationContainerScope(c *gc.C) {
+	s.AddTestingService(c, "logging", s.AddTestingCharm(c, "logging"))
+	jujuInfo := []charm.Relation{{
+		Name:      "juju-info",
+		Role:      charm.RoleProvider,
+		Interface: "juju-info",
+		Scope:     charm.ScopeGlobal,
+	}}
+	_, err := s.State.AddRemoteService("infra", "", remoteEnvUUID, jujuInfo)
+	c.Assert(err, gc.IsNil)
+	eps, err := s.State.InferEndpoints([]string{"logging:info", "infra"})
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.AddRelation(eps...)
+	c.Assert(err, gc.ErrorMatches, `cannot add relation "logging:info infra:juju-info": remote service "infra" cannot take part in a container-scoped relation`)
+}
+
+func (s *RemoteServiceSuite) TestDestroyServiceRelatedToRemoteService(c *gc.C) {
+	rel := s.addRelation(c)
+	err := s.wordpress.Destroy()
+	c.Assert(err, gc.IsNil)
+	err = rel.Refresh()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	err = s.wordpress.Refresh()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *RemoteServiceSuite) TestRemoteUnitErrors(c *gc.C) {
+	rel := s.addRelation(c)
+	_, err := rel.RemoteUnit("mysql")
+	c.Assert(err, gc.ErrorMatches, `"mysql" is not a valid unit name`)
+	_, err = rel.RemoteUnit("wordpress/0")
+	c.Assert(err, gc.ErrorMatches, `service "wordpress" is not a remote service`)
+	_, err = rel.RemoteUnit("riak/0")
+	c.Assert(err, gc.ErrorMatches, `service "riak" is not a member of "wordpress:db mysql:server"`)
+}
+
+func (s *RemoteServiceSuite) TestRemoteUnitScope(c *gc.C) {
+	rel := s.addRelation(c)
+	unit, err := s.wordpress.AddUnit()
+	c.Assert(err, gc.IsNil)
+	ru, err := rel.Unit(unit)
+	c.Assert(err, gc.IsNil)
+	err = ru.EnterScope(nil)
+	c.Assert(err, gc.IsNil)
+	w := ru.WatchScope()
+	defer testing.AssertStop(c, w)
+	s.assertScopeChange(c, w, nil, nil)
+
+	rru, err := rel.RemoteUnit("mysql/0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(rru.UnitName(), gc.Equals, "mysql/0")
+	c.Assert(rru.Endpoint().Name, gc.Equals, "server")
+	err = rru.EnterScope(map[string]interface{}{"host": "db.example.com"})
+	c.Assert(err, gc.IsNil)
+	s.assertScopeChange(c, w, []string{"mysql/0"}, nil)
+	inScope, err := rru.InScope()
+	c.Assert(err, gc.IsNil)
+	c.Assert(inScope, jc.IsTrue)
+	settings, err := ru.ReadSettings("mysql/0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(settings, gc.DeepEquals, map[string]interface{}{"host": "db.example.com"})
+	joined, err := rel.JoinedUnits()
+	c.Assert(err, gc.IsNil)
+	c.Assert(joined, jc.SameContents, []string{"mysql/0", "wordpress/0"})
+
+	// Entering scope again replaces the settings.
+	err = rru.EnterScope(map[string]interface{}{"port": "3306"})
+	c.Assert(err, gc.IsNil)
+	s.assertNoScopeChange(c, w)
+	settings, err = ru.ReadSettings("mysql/0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(settings, gc.DeepEquals, map[string]interface{}{"port": "3306"})
+
+	err = rru.LeaveScope()
+	c.Assert(err, gc.IsNil)
+	s.assertScopeChange(c, w, nil, []string{"mysql/0"})
+	inScope, err = rru.InScope()
+	c.Assert(err, gc.IsNil)
+	c.Assert(inScope, jc.IsFalse)
+	err = rru.LeaveScope()
+	c.Assert(err, gc.IsNil)
+
+	// The settings persist until the relation is removed.
+	settings, err = ru.ReadSettings("mysql/0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(settings, gc.DeepEquals, map[string]interface{}{"port": "3306"})
+}
+
+func (s *RemoteServiceSuite) TestRemoteUnitLeavesDyingRelation(c *gc.C) {
+	rel := s.addRelation(c)
+	rru0, err := rel.RemoteUnit("mysql/0")
+	c.Assert(err, gc.IsNil)
+	err = rru0.EnterScope(nil)
+	c.Assert(err, gc.IsNil)
+	err = rel.Destroy()
+	c.Assert(err, gc.IsNil)
+	err = rel.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(rel.Life(), gc.Equals, state.Dying)
+
+	rru1, err := rel.RemoteUnit("mysql/1")
+	c.Assert(err, gc.IsNil)
+	err = rru1.EnterScope(nil)
+	c.Assert(err, gc.Equals, state.ErrCannotEnterScope)
+
+	err = rru0.LeaveScope()
+	c.Assert(err, gc.IsNil)
+	err = rel.Refresh()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	// The remote service goes along with the relation.
+	_, err = s.State.RemoteService("mysql")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *RemoteServiceSuite) TestWatchRemoteRelations(c *gc.C) {
+	w := s.State.WatchRemoteRelations()
+	defer testing.AssertStop(c, w)
+	wc := testing.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	// Relations between local services are not reported.
+	s.AddTestingService(c, "localdb", s.AddTestingCharm(c, "mysql"))
+	eps, err := s.State.InferEndpoints([]string{"wordpress", "localdb"})
+	c.Assert(err, gc.IsNil)
+	local, err := s.State.AddRelation(eps...)
+	c.Assert(err, gc.IsNil)
+	err = local.Destroy()
+	c.Assert(err, gc.IsNil)
+	wc.AssertNoChange()
+
+	rel := s.addRelation(c)
+	wc.AssertOneChange()
+
+	// Remote units entering scope, and changes to their
+	// settings, are reported.
+	rru, err := rel.RemoteUnit("mysql/0")
+	c.Assert(err, gc.IsNil)
+	err = rru.EnterScope(map[string]interface{}{"port": "3306"})
+	c.Assert(err, gc.IsNil)
+	wc.AssertOneChange()
+	err = rru.EnterScope(map[string]interface{}{"port": "3307"})
+	c.Assert(err, gc.IsNil)
+	wc.AssertOneChange()
+	err = rru.EnterScope(map[string]interface{}{"port": "3307"})
+	c.Assert(err, gc.IsNil)
+	wc.AssertNoChange()
+
+	err = rru.LeaveScope()
+	c.Assert(err, gc.IsNil)
+	wc.AssertOneChange()
+	err = rel.Destroy()
+	c.Assert(err, gc.IsNil)
+	wc.AssertOneChange()
+}
+
+func (s *RemoteServiceSuite) assertScopeChange(c *gc.C, w *state.RelationScopeWatcher, entered, left []string) {
+	s.State.StartSync()
+	select {
+	case ch, ok := <-w.Changes():
+		c.Assert(ok, jc.IsTrue)
+		sort.Strings(ch.Entered)
+		sort.Strings(ch.Left)
+		c.Assert(ch.Entered, gc.DeepEquals, entered)
+		c.Assert(ch.Left, gc.DeepEquals, left)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("no change")
+	}
+}
+
+func (s *RemoteServiceSuite) assertNoScopeChange(c *gc.C, w *state.RelationScopeWatcher) {
+	s.State.StartSync()
+	select {
+	case ch, ok := <-w.Changes():
+		c.Fatalf("got unwanted change: %#v, %t", ch, ok)
+	case <-time.After(coretesting.ShortWait):
+	}
+}
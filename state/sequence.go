@@ -16,7 +16,9 @@ type sequenceDoc struct {
 }
 
 func (s *State) sequence(name string) (int, error) {
-	query := s.db.C("sequence").Find(bson.D{{"_id", name}})
+	// An upserted document takes its _id and environment
+	// UUID from the scoped query.
+	query := s.sequences.FindId(name)
 	inc := mgo.Change{
 		Update: bson.M{"$inc": bson.M{"counter": 1}},
 		Upsert: true,
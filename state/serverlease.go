@@ -0,0 +1,98 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/bson"
+	"labix.org/v2/mgo/txn"
+)
+
+// ErrServerLeaseHeld is returned by ClaimServerLease when another
+// holder has an unexpired lease of the same name.
+var ErrServerLeaseHeld = errors.New("lease held by another state server")
+
+// serverLeaseDoc records which state server holds a lease shared by
+// all the state servers, and until when. Leases let the state
+// servers take turns at work that must not be done by several of
+// them at once; the holder must claim the lease again before it
+// expires to keep it.
+type serverLeaseDoc struct {
+	Holder string    `bson:"holder"`
+	Expiry time.Time `bson:"expiry"`
+}
+
+// serverLeaseKey returns the key, in the state servers
+// collection, of the named lease.
+func serverLeaseKey(name string) string {
+	return "lease#" + name
+}
+
+// ClaimServerLease makes holder the holder of the named lease for the
+// given duration, if no other holder has an unexpired lease of that
+// name. The holder calls it again to extend its lease. If another
+// holder has the lease, it returns ErrServerLeaseHeld.
+func (st *State) ClaimServerLease(name, holder string, duration time.Duration) error {
+	if duration <= 0 {
+		return errors.Errorf("invalid lease duration %v", duration)
+	}
+	key := serverLeaseKey(name)
+	for i := 0; i < 3; i++ {
+		// Mongo only stores times to millisecond precision.
+		now := time.Now().UTC().Round(time.Millisecond)
+		var doc serverLeaseDoc
+		err := st.stateServers.FindId(key).One(&doc)
+		op := txn.Op{
+			C:  st.stateServers.Name,
+			Id: key,
+		}
+		switch {
+		case err == mgo.ErrNotFound:
+			op.Assert = txn.DocMissing
+			op.Insert = &serverLeaseDoc{
+				Holder: holder,
+				Expiry: now.Add(duration),
+			}
+		case err != nil:
+			return fmt.Errorf("cannot claim lease %q: %v", name, err)
+		case doc.Holder != holder && doc.Expiry.After(now):
+			return ErrServerLeaseHeld
+		default:
+			op.Assert = bson.D{{"holder", doc.Holder}, {"expiry", doc.Expiry}}
+			op.Update = bson.D{{"$set", bson.D{
+				{"holder", holder},
+				{"expiry", now.Add(duration)},
+			}}}
+		}
+		err = st.runTransaction([]txn.Op{op})
+		if err == nil {
+			return nil
+		}
+		if err != txn.ErrAborted {
+			return fmt.Errorf("cannot claim lease %q: %v", name, err)
+		}
+	}
+	return ErrExcessiveContention
+}
+
+// ReleaseServerLease gives up the named lease, so that another holder
+// may claim it at once. It does nothing if holder does not hold the
+// lease.
+func (st *State) ReleaseServerLease(name, holder string) error {
+	ops := []txn.Op{{
+		C:      st.stateServers.Name,
+		Id:     serverLeaseKey(name),
+		Assert: bson.D{{"holder", holder}},
+		Remove: true,
+	}}
+	err := st.runTransaction(ops)
+	if err != nil && err != txn.ErrAborted {
+		return fmt.Errorf("cannot release lease %q: %v", name, err)
+	}
+	return nil
+}
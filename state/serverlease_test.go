@@ -0,0 +1,61 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state"
+)
+
+type ServerLeaseSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&ServerLeaseSuite{})
+
+func (s *ServerLeaseSuite) TestClaimServerLease(c *gc.C) {
+	err := s.State.ClaimServerLease("upgrade", "machine-0", time.Minute)
+	c.Assert(err, gc.IsNil)
+	// The holder may extend its lease.
+	err = s.State.ClaimServerLease("upgrade", "machine-0", time.Minute)
+	c.Assert(err, gc.IsNil)
+	// Other holders may not claim it, but may claim other leases.
+	err = s.State.ClaimServerLease("upgrade", "machine-1", time.Minute)
+	c.Assert(err, gc.Equals, state.ErrServerLeaseHeld)
+	err = s.State.ClaimServerLease("other", "machine-1", time.Minute)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *ServerLeaseSuite) TestClaimExpiredServerLease(c *gc.C) {
+	err := s.State.ClaimServerLease("upgrade", "machine-0", time.Millisecond)
+	c.Assert(err, gc.IsNil)
+	time.Sleep(10 * time.Millisecond)
+	err = s.State.ClaimServerLease("upgrade", "machine-1", time.Minute)
+	c.Assert(err, gc.IsNil)
+	err = s.State.ClaimServerLease("upgrade", "machine-0", time.Minute)
+	c.Assert(err, gc.Equals, state.ErrServerLeaseHeld)
+}
+
+func (s *ServerLeaseSuite) TestClaimServerLeaseInvalidDuration(c *gc.C) {
+	err := s.State.ClaimServerLease("upgrade", "machine-0", 0)
+	c.Assert(err, gc.ErrorMatches, "invalid lease duration 0")
+}
+
+func (s *ServerLeaseSuite) TestReleaseServerLease(c *gc.C) {
+	err := s.State.ClaimServerLease("upgrade", "machine-0", time.Minute)
+	c.Assert(err, gc.IsNil)
+	// Releasing a lease held by another holder does nothing.
+	err = s.State.ReleaseServerLease("upgrade", "machine-1")
+	c.Assert(err, gc.IsNil)
+	err = s.State.ClaimServerLease("upgrade", "machine-1", time.Minute)
+	c.Assert(err, gc.Equals, state.ErrServerLeaseHeld)
+
+	err = s.State.ReleaseServerLease("upgrade", "machine-0")
+	c.Assert(err, gc.IsNil)
+	err = s.State.ClaimServerLease("upgrade", "machine-1", time.Minute)
+	c.Assert(err, gc.IsNil)
+}
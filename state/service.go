@@ -6,6 +6,7 @@ package state
 import (
 	stderrors "errors"
 	"fmt"
+	"net"
 	"sort"
 	"strconv"
 	"strings"
@@ -19,6 +20,7 @@ import (
 
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/storage"
 )
 
 // Service represents the state of a service.
@@ -41,9 +43,13 @@ type serviceDoc struct {
 	UnitCount     int
 	RelationCount int
 	Exposed       bool
-	MinUnits      int
-	OwnerTag      string
-	TxnRevno      int64 `bson:"txn-revno"`
+	ExposedCIDRs  []string `bson:",omitempty"`
+	// EndpointBindings maps relation endpoint names to the names
+	// of the spaces they are bound to.
+	EndpointBindings map[string]string `bson:",omitempty"`
+	MinUnits         int
+	OwnerTag         string
+	TxnRevno         int64 `bson:"txn-revno"`
 }
 
 func newService(st *State, doc *serviceDoc) *Service {
@@ -226,6 +232,8 @@ func (s *Service) removeOps(asserts bson.D) []txn.Op {
 	}}
 	ops = append(ops, removeRequestedNetworksOp(s.st, s.globalKey()))
 	ops = append(ops, removeConstraintsOp(s.st, s.globalKey()))
+	ops = append(ops, removeStorageConstraintsOp(s.st, s.globalKey()))
+	ops = append(ops, removeLeadershipOps(s.st, s.doc.Name)...)
 	return append(ops, annotationRemoveOp(s.st, s.globalKey()))
 }
 
@@ -236,29 +244,118 @@ func (s *Service) IsExposed() bool {
 	return s.doc.Exposed
 }
 
-// SetExposed marks the service as exposed.
-// See ClearExposed and IsExposed.
+// ExposedCIDRs returns the source CIDRs from which the ports of an
+// exposed service may be accessed. If it returns no CIDRs, the ports
+// may be accessed from anywhere. See SetExposedTo.
+func (s *Service) ExposedCIDRs() []string {
+	return append([]string(nil), s.doc.ExposedCIDRs...)
+}
+
+// SetExposed marks the service as exposed, with its ports accessible
+// from anywhere. See ClearExposed and IsExposed.
 func (s *Service) SetExposed() error {
-	return s.setExposed(true)
+	return s.setExposed(true, nil)
+}
+
+// SetExposedTo marks the service as exposed, with its ports accessible
+// only from the given source CIDRs. If no CIDRs are given, the ports
+// are accessible from anywhere. It fails if the environment cannot
+// restrict access to source CIDRs. See SetExposed and ExposedCIDRs.
+func (s *Service) SetExposedTo(cidrs []string) error {
+	for _, cidr := range cidrs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("cannot expose service %q: invalid CIDR %q", s, cidr)
+		}
+	}
+	if len(cidrs) > 0 {
+		if err := s.st.supportsSourceCIDRs(); err != nil {
+			return fmt.Errorf("cannot expose service %q: %v", s, err)
+		}
+	}
+	return s.setExposed(true, cidrs)
 }
 
 // ClearExposed removes the exposed flag from the service.
 // See SetExposed and IsExposed.
 func (s *Service) ClearExposed() error {
-	return s.setExposed(false)
+	return s.setExposed(false, nil)
 }
 
-func (s *Service) setExposed(exposed bool) (err error) {
+func (s *Service) setExposed(exposed bool, cidrs []string) (err error) {
+	update := bson.D{{"$set", bson.D{{"exposed", exposed}}}}
+	if len(cidrs) > 0 {
+		update = bson.D{{"$set", bson.D{{"exposed", exposed}, {"exposedcidrs", cidrs}}}}
+	} else {
+		update = append(update, bson.DocElem{"$unset", bson.D{{"exposedcidrs", nil}}})
+	}
 	ops := []txn.Op{{
 		C:      s.st.services.Name,
 		Id:     s.doc.Name,
 		Assert: isAliveDoc,
-		Update: bson.D{{"$set", bson.D{{"exposed", exposed}}}},
+		Update: update,
 	}}
 	if err := s.st.runTransaction(ops); err != nil {
 		return fmt.Errorf("cannot set exposed flag for service %q to %v: %v", s, exposed, onAbort(err, errNotAlive))
 	}
 	s.doc.Exposed = exposed
+	s.doc.ExposedCIDRs = cidrs
+	return nil
+}
+
+// EndpointBindings returns a map from the names of the service's
+// relation endpoints to the names of the spaces they are bound to.
+// Endpoints missing from the map are not bound to any space.
+func (s *Service) EndpointBindings() map[string]string {
+	bindings := make(map[string]string)
+	for endpoint, space := range s.doc.EndpointBindings {
+		bindings[endpoint] = space
+	}
+	return bindings
+}
+
+// SetEndpointBindings binds the service's relation endpoints to
+// spaces, replacing any previous bindings. The units of the service
+// then advertise, on the relations using a bound endpoint, their
+// address in the space that endpoint is bound to.
+func (s *Service) SetEndpointBindings(bindings map[string]string) (err error) {
+	defer errors.Maskf(&err, "cannot set endpoint bindings for service %q", s)
+	ops := []txn.Op{{
+		C:      s.st.services.Name,
+		Id:     s.doc.Name,
+		Assert: isAliveDoc,
+	}}
+	for endpoint, space := range bindings {
+		if _, err := s.Endpoint(endpoint); err != nil {
+			return err
+		}
+		ops = append(ops, txn.Op{
+			C:      s.st.spaces.Name,
+			Id:     space,
+			Assert: txn.DocExists,
+		})
+	}
+	if len(bindings) > 0 {
+		ops[0].Update = bson.D{{"$set", bson.D{{"endpointbindings", bindings}}}}
+	} else {
+		ops[0].Update = bson.D{{"$unset", bson.D{{"endpointbindings", nil}}}}
+	}
+	if err := s.st.runTransaction(ops); err == txn.ErrAborted {
+		if err := s.Refresh(); err != nil {
+			return err
+		}
+		if s.doc.Life != Alive {
+			return errNotAlive
+		}
+		for _, space := range bindings {
+			if _, err := s.st.Space(space); err != nil {
+				return err
+			}
+		}
+		return fmt.Errorf("concurrent change detected")
+	} else if err != nil {
+		return err
+	}
+	s.doc.EndpointBindings = bindings
 	return nil
 }
 
@@ -546,7 +643,7 @@ func (s *Service) newUnitName() (string, error) {
 // and only if s is a subordinate service. Only one subordinate of a given
 // service will be assigned to a given principal. The asserts param can be used
 // to include additional assertions for the service document.
-func (s *Service) addUnitOps(principalName string, asserts bson.D) (string, []txn.Op, error) {
+func (s *Service) addUnitOps(principalName string, storageCons map[string]storage.Directive, asserts bson.D) (string, []txn.Op, error) {
 	if s.doc.Subordinate && principalName == "" {
 		return "", nil, fmt.Errorf("service is a subordinate")
 	} else if !s.doc.Subordinate && principalName != "" {
@@ -575,6 +672,9 @@ func (s *Service) addUnitOps(principalName string, asserts bson.D) (string, []tx
 			Insert: udoc,
 		},
 		createStatusOp(s.st, globalKey, sdoc),
+		createWorkloadStatusOp(s.st, globalKey, workloadStatusDoc{
+			Status: params.WorkloadUnknown,
+		}),
 		{
 			C:      s.st.services.Name,
 			Id:     s.doc.Name,
@@ -600,6 +700,11 @@ func (s *Service) addUnitOps(principalName string, asserts bson.D) (string, []tx
 			return "", nil, err
 		}
 		ops = append(ops, createConstraintsOp(s.st, globalKey, cons))
+		storageOps, err := s.unitStorageOps(name, storageCons)
+		if err != nil {
+			return "", nil, err
+		}
+		ops = append(ops, storageOps...)
 	}
 	return name, ops, nil
 }
@@ -622,8 +727,16 @@ func (s *Service) GetOwnerTag() string {
 
 // AddUnit adds a new principal unit to the service.
 func (s *Service) AddUnit() (unit *Unit, err error) {
+	return s.AddUnitWithStorage(nil)
+}
+
+// AddUnitWithStorage adds a new principal unit to the service, with
+// the storage required by the service's storage constraints. The
+// given storage constraints override those of the service for the
+// new unit only.
+func (s *Service) AddUnitWithStorage(storageCons map[string]storage.Directive) (unit *Unit, err error) {
 	defer errors.Maskf(&err, "cannot add unit to service %q", s)
-	name, ops, err := s.addUnitOps("", nil)
+	name, ops, err := s.addUnitOps("", storageCons, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -673,6 +786,7 @@ func (s *Service) removeUnitOps(u *Unit, asserts bson.D) ([]txn.Op, error) {
 	},
 		removeConstraintsOp(s.st, u.globalKey()),
 		removeStatusOp(s.st, u.globalKey()),
+		removeWorkloadStatusOp(s.st, u.globalKey()),
 		annotationRemoveOp(s.st, u.globalKey()),
 		s.st.newCleanupOp(cleanupRemovedUnit, u.doc.Name),
 	)
@@ -720,6 +720,62 @@ func (s *ServiceSuite) TestServiceRefresh(c *gc.C) {
 	c.Assert(err, jc.Satisfies, errors.IsNotFound)
 }
 
+func (s *ServiceSuite) TestServiceExposedTo(c *gc.C) {
+	c.Assert(s.mysql.ExposedCIDRs(), gc.HasLen, 0)
+
+	err := s.mysql.SetExposedTo([]string{"10.0.0.0/8", "192.168.1.0/24"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mysql.IsExposed(), gc.Equals, true)
+	c.Assert(s.mysql.ExposedCIDRs(), gc.DeepEquals, []string{"10.0.0.0/8", "192.168.1.0/24"})
+	err = s.mysql.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mysql.ExposedCIDRs(), gc.DeepEquals, []string{"10.0.0.0/8", "192.168.1.0/24"})
+
+	// Exposing without CIDRs removes the restriction.
+	err = s.mysql.SetExposed()
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mysql.ExposedCIDRs(), gc.HasLen, 0)
+	err = s.mysql.SetExposedTo([]string{"10.0.0.0/8"})
+	c.Assert(err, gc.IsNil)
+	err = s.mysql.ClearExposed()
+	c.Assert(err, gc.IsNil)
+	err = s.mysql.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mysql.IsExposed(), gc.Equals, false)
+	c.Assert(s.mysql.ExposedCIDRs(), gc.HasLen, 0)
+
+	err = s.mysql.SetExposedTo([]string{"10.0.0.1"})
+	c.Assert(err, gc.ErrorMatches, `cannot expose service "mysql": invalid CIDR "10.0.0.1"`)
+	c.Assert(s.mysql.IsExposed(), gc.Equals, false)
+}
+
+func (s *ServiceSuite) TestEndpointBindings(c *gc.C) {
+	c.Assert(s.mysql.EndpointBindings(), gc.HasLen, 0)
+	_, err := s.State.AddSpace("db", nil)
+	c.Assert(err, gc.IsNil)
+
+	err = s.mysql.SetEndpointBindings(map[string]string{"server": "db"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mysql.EndpointBindings(), gc.DeepEquals, map[string]string{"server": "db"})
+	err = s.mysql.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mysql.EndpointBindings(), gc.DeepEquals, map[string]string{"server": "db"})
+
+	err = s.mysql.SetEndpointBindings(map[string]string{"server": "missing"})
+	c.Assert(err, gc.ErrorMatches, `cannot set endpoint bindings for service "mysql": space "missing" not found`)
+	err = s.mysql.SetEndpointBindings(map[string]string{"foo": "db"})
+	c.Assert(err, gc.ErrorMatches, `cannot set endpoint bindings for service "mysql": service "mysql" has no "foo" relation`)
+	err = s.mysql.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mysql.EndpointBindings(), gc.DeepEquals, map[string]string{"server": "db"})
+
+	err = s.mysql.SetEndpointBindings(nil)
+	c.Assert(err, gc.IsNil)
+	err = s.mysql.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.mysql.EndpointBindings(), gc.HasLen, 0)
+}
+
 func (s *ServiceSuite) TestServiceExposed(c *gc.C) {
 	// Check that querying for the exposed flag works correctly.
 	c.Assert(s.mysql.IsExposed(), gc.Equals, false)
@@ -5,6 +5,7 @@ package state
 
 import (
 	"fmt"
+	"reflect"
 	"sort"
 	"strings"
 
@@ -139,7 +140,9 @@ func (c *Settings) Write() ([]ItemChange, error) {
 	for key := range cacheKeys(c.disk, c.core) {
 		old, ondisk := c.disk[key]
 		new, incore := c.core[key]
-		if new == old {
+		// Values are not necessarily comparable;
+		// environment settings may hold lists.
+		if reflect.DeepEqual(new, old) {
 			continue
 		}
 		var change ItemChange
@@ -191,10 +194,11 @@ func newSettings(st *State, key string) *Settings {
 	}
 }
 
-// cleanSettingsMap cleans the map of version and _id fields and also unescapes
-// keys coming out of MongoDB.
+// cleanSettingsMap cleans the map of version, environment and _id fields and
+// also unescapes keys coming out of MongoDB.
 func cleanSettingsMap(in map[string]interface{}) {
 	delete(in, "_id")
+	delete(in, envUUIDField)
 	delete(in, "txn-revno")
 	delete(in, "txn-queue")
 	replaceKeys(in, unescapeReplacer.Replace)
@@ -118,7 +118,7 @@ func (s *SettingsSuite) TestUpdateWithWrite(c *gc.C) {
 
 	// Check MongoDB state.
 	mgoData := make(map[string]interface{}, 0)
-	err = s.MgoSuite.Session.DB("juju").C("settings").FindId(s.key).One(&mgoData)
+	err = s.state.settings.FindId(s.key).One(&mgoData)
 	c.Assert(err, gc.IsNil)
 	cleanSettingsMap(mgoData)
 	c.Assert(mgoData, gc.DeepEquals, options)
@@ -200,12 +200,37 @@ func (s *SettingsSuite) TestSetItem(c *gc.C) {
 	c.Assert(node.Map(), gc.DeepEquals, options)
 	// Check MongoDB state.
 	mgoData := make(map[string]interface{}, 0)
-	err = s.MgoSuite.Session.DB("juju").C("settings").FindId(s.key).One(&mgoData)
+	err = s.state.settings.FindId(s.key).One(&mgoData)
 	c.Assert(err, gc.IsNil)
 	cleanSettingsMap(mgoData)
 	c.Assert(mgoData, gc.DeepEquals, options)
 }
 
+func (s *SettingsSuite) TestSetListItem(c *gc.C) {
+	// Check that values that are not comparable can be written.
+	node, err := createSettings(s.state, s.key, nil)
+	c.Assert(err, gc.IsNil)
+	node.Set("list", []interface{}{"a", "b"})
+	changes, err := node.Write()
+	c.Assert(err, gc.IsNil)
+	c.Assert(changes, gc.DeepEquals, []ItemChange{
+		{ItemAdded, "list", nil, []interface{}{"a", "b"}},
+	})
+
+	// Writing an equal value again changes nothing.
+	node.Set("list", []interface{}{"a", "b"})
+	changes, err = node.Write()
+	c.Assert(err, gc.IsNil)
+	c.Assert(changes, gc.DeepEquals, []ItemChange{})
+
+	node.Set("list", []interface{}{"a"})
+	changes, err = node.Write()
+	c.Assert(err, gc.IsNil)
+	c.Assert(changes, gc.DeepEquals, []ItemChange{
+		{ItemModified, "list", []interface{}{"a", "b"}, []interface{}{"a"}},
+	})
+}
+
 func (s *SettingsSuite) TestSetItemEscape(c *gc.C) {
 	// Check that Set works as expected.
 	node, err := createSettings(s.state, s.key, nil)
@@ -225,7 +250,7 @@ func (s *SettingsSuite) TestSetItemEscape(c *gc.C) {
 	// Check MongoDB state.
 	mgoOptions := map[string]interface{}{"\uff04bar": 1, "foo\uff0ealpha": "beta"}
 	mgoData := make(map[string]interface{}, 0)
-	err = s.MgoSuite.Session.DB("juju").C("settings").FindId(s.key).One(&mgoData)
+	err = s.state.settings.FindId(s.key).One(&mgoData)
 	c.Assert(err, gc.IsNil)
 	cleanMgoSettings(mgoData)
 	c.Assert(mgoData, gc.DeepEquals, mgoOptions)
@@ -262,7 +287,7 @@ func (s *SettingsSuite) TestReplaceSettingsEscape(c *gc.C) {
 	// Check MongoDB state.
 	mgoOptions := map[string]interface{}{"\uff04baz": 1, "foo\uff0ebar": "beta"}
 	mgoData := make(map[string]interface{}, 0)
-	err = s.MgoSuite.Session.DB("juju").C("settings").FindId(s.key).One(&mgoData)
+	err = s.state.settings.FindId(s.key).One(&mgoData)
 	c.Assert(err, gc.IsNil)
 	cleanMgoSettings(mgoData)
 	c.Assert(mgoData, gc.DeepEquals, mgoOptions)
@@ -280,7 +305,7 @@ func (s *SettingsSuite) TestCreateSettingsEscape(c *gc.C) {
 	// Check MongoDB state.
 	mgoOptions := map[string]interface{}{"\uff04baz": 1, "foo\uff0ebar": "beta"}
 	mgoData := make(map[string]interface{}, 0)
-	err = s.MgoSuite.Session.DB("juju").C("settings").FindId(s.key).One(&mgoData)
+	err = s.state.settings.FindId(s.key).One(&mgoData)
 	c.Assert(err, gc.IsNil)
 	cleanMgoSettings(mgoData)
 	c.Assert(mgoData, gc.DeepEquals, mgoOptions)
@@ -435,7 +460,7 @@ func (s *SettingsSuite) TestMultipleWritesAreStable(c *gc.C) {
 	c.Assert(err, gc.IsNil)
 
 	mgoData := make(map[string]interface{})
-	err = s.MgoSuite.Session.DB("juju").C("settings").FindId(s.key).One(&mgoData)
+	err = s.state.settings.FindId(s.key).One(&mgoData)
 	c.Assert(err, gc.IsNil)
 	version := mgoData["version"]
 	for i := 0; i < 100; i++ {
@@ -447,7 +472,7 @@ func (s *SettingsSuite) TestMultipleWritesAreStable(c *gc.C) {
 		c.Assert(err, gc.IsNil)
 	}
 	mgoData = make(map[string]interface{})
-	err = s.MgoSuite.Session.DB("juju").C("settings").FindId(s.key).One(&mgoData)
+	err = s.state.settings.FindId(s.key).One(&mgoData)
 	c.Assert(err, gc.IsNil)
 	newVersion := mgoData["version"]
 	c.Assert(version, gc.Equals, newVersion)
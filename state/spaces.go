@@ -0,0 +1,156 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/bson"
+	"labix.org/v2/mgo/txn"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/network"
+)
+
+// Space represents a set of subnets which are treated alike for the
+// purposes of connectivity, whatever availability zone they are in.
+type Space struct {
+	st  *State
+	doc spaceDoc
+}
+
+// spaceDoc represents a space known to juju. Subnets refer to the
+// space they belong to by name.
+type spaceDoc struct {
+	Name string `bson:"_id"`
+}
+
+// Name returns the name of the space.
+func (s *Space) Name() string {
+	return s.doc.Name
+}
+
+// Subnets returns the subnets in the space.
+func (s *Space) Subnets() ([]*Subnet, error) {
+	return s.st.findSubnets(bson.D{{"spacename", s.doc.Name}})
+}
+
+// AddSpace creates a new space made of the subnets with the given
+// CIDRs. The subnets must already be known, and not be part of any
+// other space.
+func (st *State) AddSpace(name string, subnetCIDRs []string) (_ *Space, err error) {
+	defer errors.Contextf(&err, "cannot add space %q", name)
+	if !network.IsValidSpaceName(name) {
+		return nil, fmt.Errorf("invalid name")
+	}
+	doc := spaceDoc{Name: name}
+	ops := []txn.Op{{
+		C:      st.spaces.Name,
+		Id:     name,
+		Assert: txn.DocMissing,
+		Insert: &doc,
+	}}
+	for _, cidr := range subnetCIDRs {
+		ops = append(ops, txn.Op{
+			C:      st.subnets.Name,
+			Id:     cidr,
+			Assert: bson.D{{"spacename", ""}},
+			Update: bson.D{{"$set", bson.D{{"spacename", name}}}},
+		})
+	}
+	err = st.runTransaction(ops)
+	if err == txn.ErrAborted {
+		if _, err := st.Space(name); err == nil {
+			return nil, errors.AlreadyExistsf("space %q", name)
+		} else if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		for _, cidr := range subnetCIDRs {
+			subnet, err := st.Subnet(cidr)
+			if err != nil {
+				return nil, err
+			}
+			if subnet.SpaceName() != "" {
+				return nil, fmt.Errorf("subnet %q already in space %q", cidr, subnet.SpaceName())
+			}
+		}
+		return nil, fmt.Errorf("concurrent change detected")
+	} else if err != nil {
+		return nil, err
+	}
+	return &Space{st, doc}, nil
+}
+
+// Space returns the space with the given name.
+func (st *State) Space(name string) (*Space, error) {
+	s := &Space{st: st}
+	err := st.spaces.FindId(name).One(&s.doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("space %q", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot get space %q: %v", name, err)
+	}
+	return s, nil
+}
+
+// AllSpaces returns all known spaces in the environment.
+func (st *State) AllSpaces() ([]*Space, error) {
+	var docs []spaceDoc
+	if err := st.spaces.Find(nil).Sort("_id").All(&docs); err != nil {
+		return nil, fmt.Errorf("cannot get spaces: %v", err)
+	}
+	spaces := make([]*Space, len(docs))
+	for i, doc := range docs {
+		spaces[i] = &Space{st, doc}
+	}
+	return spaces, nil
+}
+
+// spaceAddresses returns those of the given addresses which lie
+// within the named space.
+func (st *State) spaceAddresses(spaceName string, addrs []network.Address) ([]network.Address, error) {
+	subnets, err := st.findSubnets(bson.D{{"spacename", spaceName}})
+	if err != nil {
+		return nil, err
+	}
+	var result []network.Address
+	for _, addr := range addrs {
+		for _, subnet := range subnets {
+			if subnet.Contains(addr.Value) {
+				result = append(result, addr)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// machineMatchesSpaces reports whether the machine has an address in
+// each of the spaces included by the given constraints, and none in
+// the spaces they exclude.
+func (st *State) machineMatchesSpaces(m *Machine, cons *constraints.Value) (bool, error) {
+	addrs := m.Addresses()
+	for _, name := range cons.IncludeSpaces() {
+		inSpace, err := st.spaceAddresses(name, addrs)
+		if err != nil {
+			return false, err
+		}
+		if len(inSpace) == 0 {
+			return false, nil
+		}
+	}
+	for _, name := range cons.ExcludeSpaces() {
+		inSpace, err := st.spaceAddresses(name, addrs)
+		if err != nil {
+			return false, err
+		}
+		if len(inSpace) > 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
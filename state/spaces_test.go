@@ -0,0 +1,90 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state"
+)
+
+type SpacesSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&SpacesSuite{})
+
+func (s *SpacesSuite) addSubnets(c *gc.C, cidrs ...string) {
+	for _, cidr := range cidrs {
+		_, err := s.State.AddSubnet(state.SubnetInfo{CIDR: cidr})
+		c.Assert(err, gc.IsNil)
+	}
+}
+
+func subnetCIDRs(subnets []*state.Subnet) []string {
+	var cidrs []string
+	for _, subnet := range subnets {
+		cidrs = append(cidrs, subnet.CIDR())
+	}
+	return cidrs
+}
+
+func (s *SpacesSuite) TestAddSpace(c *gc.C) {
+	s.addSubnets(c, "10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/24")
+	space, err := s.State.AddSpace("db", []string{"10.0.1.0/24", "10.0.0.0/24"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(space.Name(), gc.Equals, "db")
+
+	subnets, err := space.Subnets()
+	c.Assert(err, gc.IsNil)
+	c.Assert(subnetCIDRs(subnets), gc.DeepEquals, []string{"10.0.0.0/24", "10.0.1.0/24"})
+	subnet, err := s.State.Subnet("10.0.1.0/24")
+	c.Assert(err, gc.IsNil)
+	c.Assert(subnet.SpaceName(), gc.Equals, "db")
+
+	got, err := s.State.Space("db")
+	c.Assert(err, gc.IsNil)
+	c.Assert(got.Name(), gc.Equals, "db")
+}
+
+func (s *SpacesSuite) TestAddSpaceErrors(c *gc.C) {
+	s.addSubnets(c, "10.0.0.0/24", "10.0.1.0/24")
+	_, err := s.State.AddSpace("db", []string{"10.0.0.0/24"})
+	c.Assert(err, gc.IsNil)
+
+	_, err = s.State.AddSpace("Db", nil)
+	c.Check(err, gc.ErrorMatches, `cannot add space "Db": invalid name`)
+	_, err = s.State.AddSpace("db", nil)
+	c.Check(err, gc.ErrorMatches, `cannot add space "db": space "db" already exists`)
+	c.Check(err, jc.Satisfies, errors.IsAlreadyExists)
+	_, err = s.State.AddSpace("dmz", []string{"10.0.1.0/24", "10.0.9.0/24"})
+	c.Check(err, gc.ErrorMatches, `cannot add space "dmz": subnet "10.0.9.0/24" not found`)
+	_, err = s.State.AddSpace("dmz", []string{"10.0.1.0/24", "10.0.0.0/24"})
+	c.Check(err, gc.ErrorMatches, `cannot add space "dmz": subnet "10.0.0.0/24" already in space "db"`)
+
+	// Nothing was changed by the failed attempts.
+	_, err = s.State.Space("dmz")
+	c.Check(err, jc.Satisfies, errors.IsNotFound)
+	subnet, err := s.State.Subnet("10.0.1.0/24")
+	c.Assert(err, gc.IsNil)
+	c.Check(subnet.SpaceName(), gc.Equals, "")
+}
+
+func (s *SpacesSuite) TestAllSpaces(c *gc.C) {
+	spaces, err := s.State.AllSpaces()
+	c.Assert(err, gc.IsNil)
+	c.Assert(spaces, gc.HasLen, 0)
+
+	for _, name := range []string{"dmz", "db"} {
+		_, err := s.State.AddSpace(name, nil)
+		c.Assert(err, gc.IsNil)
+	}
+	spaces, err = s.State.AllSpaces()
+	c.Assert(err, gc.IsNil)
+	c.Assert(spaces, gc.HasLen, 2)
+	c.Assert(spaces[0].Name(), gc.Equals, "db")
+	c.Assert(spaces[1].Name(), gc.Equals, "dmz")
+}
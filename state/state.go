@@ -49,34 +49,57 @@ type State struct {
 	info              *Info
 	policy            Policy
 	db                *mgo.Database
-	environments      *mgo.Collection
-	charms            *mgo.Collection
-	machines          *mgo.Collection
-	instanceData      *mgo.Collection
-	containerRefs     *mgo.Collection
-	relations         *mgo.Collection
-	relationScopes    *mgo.Collection
-	services          *mgo.Collection
-	requestedNetworks *mgo.Collection
-	networks          *mgo.Collection
-	networkInterfaces *mgo.Collection
-	minUnits          *mgo.Collection
-	settings          *mgo.Collection
-	settingsrefs      *mgo.Collection
-	constraints       *mgo.Collection
-	units             *mgo.Collection
-	actions           *mgo.Collection
-	actionresults     *mgo.Collection
-	users             *mgo.Collection
+	environments      *stateCollection
+	hostedEnvs        *stateCollection
+	charms            *stateCollection
+	machines          *stateCollection
+	instanceData      *stateCollection
+	containerRefs     *stateCollection
+	relations         *stateCollection
+	relationScopes    *stateCollection
+	services          *stateCollection
+	remoteServices    *stateCollection
+	offers            *stateCollection
+	requestedNetworks *stateCollection
+	networks          *stateCollection
+	networkInterfaces *stateCollection
+	subnets           *stateCollection
+	spaces            *stateCollection
+	minUnits          *stateCollection
+	settings          *stateCollection
+	settingsrefs      *stateCollection
+	constraints       *stateCollection
+	units             *stateCollection
+	actions           *stateCollection
+	actionresults     *stateCollection
+	users             *stateCollection
+	envUsers          *stateCollection
 	presence          *mgo.Collection
-	cleanups          *mgo.Collection
-	annotations       *mgo.Collection
-	statuses          *mgo.Collection
-	stateServers      *mgo.Collection
+	cleanups          *stateCollection
+	annotations       *stateCollection
+	statuses          *stateCollection
+	workloadStatuses  *stateCollection
+	stateServers      *stateCollection
+	backups           *stateCollection
+	audit             *stateCollection
+	logs              *stateCollection
+	leases            *stateCollection
+	storageInstances  *stateCollection
+	volumes           *stateCollection
+	volumeAttachments *stateCollection
+	storageCons       *stateCollection
+	debugRecordings   *stateCollection
+	metrics           *stateCollection
+	sequences         *stateCollection
 	runner            *txn.Runner
 	transactionHooks  chan ([]transactionHook)
 	watcher           *watcher.Watcher
 	pwatcher          *presence.Watcher
+
+	// envUUID holds the UUID of the State's environment, and
+	// serverUUID that of the state server's own environment.
+	envUUID    string
+	serverUUID string
 	// mu guards allManager.
 	mu         sync.Mutex
 	allManager *multiwatcher.StoreManager
@@ -116,6 +139,10 @@ func (st *State) runTransaction(ops []txn.Op) error {
 			logger.Infof("transaction 'before' hook end")
 		}
 	}
+	ops, err := st.scopeOps(ops)
+	if err != nil {
+		return errors.Trace(err)
+	}
 	return st.runner.Run(ops, "", nil)
 }
 
@@ -125,6 +152,13 @@ func (st *State) Ping() error {
 	return st.db.Session.Ping()
 }
 
+// MongoConnectionInfo returns information for connecting to mongo
+// as the state does. It is exposed so that state server tools such
+// as backups can reach the database and should not otherwise be used.
+func (st *State) MongoConnectionInfo() *Info {
+	return st.info
+}
+
 // MongoSession returns the underlying mongodb session
 // used by the state. It is exposed so that external code
 // can maintain the mongo replica set and should not
@@ -200,7 +234,7 @@ func (st *State) checkCanUpgrade(currentVersion, newVersion string) error {
 		}}},
 	}}}
 	var agentTags []string
-	for _, collection := range []*mgo.Collection{st.machines, st.units} {
+	for _, collection := range []*stateCollection{st.machines, st.units} {
 		var doc struct {
 			Id string `bson:"_id"`
 		}
@@ -982,22 +1016,26 @@ func (st *State) AddService(name, ownerTag string, ch *Charm, networks []string)
 		// and known before setting them.
 		createRequestedNetworksOp(st, svc.globalKey(), networks),
 		createSettingsOp(st, svc.settingsKey(), nil),
-		{
-			C:      st.users.Name,
-			Id:     ownerId,
-			Assert: txn.DocExists,
-		},
 		{
 			C:      st.settingsrefs.Name,
 			Id:     svc.settingsKey(),
 			Assert: txn.DocMissing,
 			Insert: settingsRefsDoc{1},
 		},
+		{
+			C:      st.remoteServices.Name,
+			Id:     name,
+			Assert: txn.DocMissing,
+		},
 		{
 			C:      st.services.Name,
 			Id:     name,
 			Assert: txn.DocMissing,
 			Insert: svcDoc,
+		}, {
+			C:      st.users.Name,
+			Id:     ownerId,
+			Assert: txn.DocExists,
 		}}
 	// Collect peer relation addition operations.
 	peerOps, err := st.addPeerRelationsOps(name, peers)
@@ -1226,9 +1264,21 @@ func (st *State) endpoints(name string, filter func(ep Endpoint) bool) ([]Endpoi
 	} else {
 		return nil, fmt.Errorf("invalid endpoint %q", name)
 	}
-	svc, err := st.Service(svcName)
-	if err != nil {
+	// Remote services may be related to just like local ones.
+	var svc interface {
+		Endpoint(relationName string) (Endpoint, error)
+		Endpoints() ([]Endpoint, error)
+	}
+	if local, err := st.Service(svcName); err == nil {
+		svc = local
+	} else if !errors.IsNotFound(err) {
+		return nil, err
+	} else if remote, remoteErr := st.RemoteService(svcName); remoteErr == nil {
+		svc = remote
+	} else if errors.IsNotFound(remoteErr) {
 		return nil, err
+	} else {
+		return nil, remoteErr
 	}
 	eps := []Endpoint{}
 	if relName != "" {
@@ -1238,10 +1288,11 @@ func (st *State) endpoints(name string, filter func(ep Endpoint) bool) ([]Endpoi
 		}
 		eps = append(eps, ep)
 	} else {
-		eps, err = svc.Endpoints()
+		all, err := svc.Endpoints()
 		if err != nil {
 			return nil, err
 		}
+		eps = all
 	}
 	final := []Endpoint{}
 	for _, ep := range eps {
@@ -1293,6 +1344,26 @@ func (st *State) AddRelation(eps ...Endpoint) (r *Relation, err error) {
 		var ops []txn.Op
 		series := map[string]bool{}
 		for _, ep := range eps {
+			if remoteSvc, err := st.RemoteService(ep.ServiceName); err == nil {
+				if remoteSvc.doc.Life != Alive {
+					return nil, fmt.Errorf("remote service %q is not alive", ep.ServiceName)
+				}
+				if ep.Scope == charm.ScopeContainer {
+					return nil, fmt.Errorf("remote service %q cannot take part in a container-scoped relation", ep.ServiceName)
+				}
+				if remoteEp, err := remoteSvc.Endpoint(ep.Name); err != nil || remoteEp != ep {
+					return nil, fmt.Errorf("%q does not implement %q", ep.ServiceName, ep)
+				}
+				ops = append(ops, txn.Op{
+					C:      st.remoteServices.Name,
+					Id:     ep.ServiceName,
+					Assert: isAliveDoc,
+					Update: bson.D{{"$inc", bson.D{{"relationcount", 1}}}},
+				})
+				continue
+			} else if !errors.IsNotFound(err) {
+				return nil, err
+			}
 			svc, err := st.Service(ep.ServiceName)
 			if errors.IsNotFound(err) {
 				return nil, fmt.Errorf("service %q does not exist", ep.ServiceName)
@@ -1554,6 +1625,8 @@ type stateServersDoc struct {
 	Id               string `bson:"_id"`
 	MachineIds       []string
 	VotingMachineIds []string
+	// EnvUUID holds the UUID of the state server's own environment.
+	EnvUUID string `bson:"env-uuid"`
 }
 
 // StateServerInfo holds information about currently
@@ -22,6 +22,7 @@ import (
 	"labix.org/v2/mgo/bson"
 	gc "launchpad.net/gocheck"
 
+	"github.com/juju/juju/cert"
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/instance"
@@ -1972,7 +1973,7 @@ func (s *StateSuite) TestWatchEnvironConfigCorruptConfig(c *gc.C) {
 	c.Assert(err, gc.IsNil)
 
 	// Corrupt the environment configuration.
-	settings := s.Session.DB("juju").C("settings")
+	settings := state.GetCollection(s.State, "settings")
 	err = settings.UpdateId("e", bson.D{{"$unset", bson.D{{"name", 1}}}})
 	c.Assert(err, gc.IsNil)
 
@@ -3450,6 +3451,54 @@ func (s *StateSuite) TestWatchAPIHostPorts(c *gc.C) {
 	wc.AssertClosed()
 }
 
+func (s *StateSuite) TestSetCACert(c *gc.C) {
+	c.Assert(s.State.CACert(), gc.Equals, testing.CACert)
+
+	otherCACert, _, err := cert.NewCA("other", time.Now().AddDate(1, 0, 0))
+	c.Assert(err, gc.IsNil)
+	err = s.State.SetCACert(otherCACert + testing.CACert)
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.State.CACert(), gc.Equals, otherCACert+testing.CACert)
+
+	err = s.State.SetCACert("no certificates here")
+	c.Assert(err, gc.ErrorMatches, "cannot set CA certificates: no certificates found")
+	c.Assert(s.State.CACert(), gc.Equals, otherCACert+testing.CACert)
+}
+
+func (s *StateSuite) TestWatchAPIHostPortsNotifiesCACertChanges(c *gc.C) {
+	w := s.State.WatchAPIHostPorts()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	err := s.State.SetCACert(testing.CACert)
+	c.Assert(err, gc.IsNil)
+	wc.AssertOneChange()
+}
+
+func (s *StateSuite) TestWatchStateServingInfo(c *gc.C) {
+	w := s.State.WatchStateServingInfo()
+	defer statetesting.AssertStop(c, w)
+
+	// Initial event.
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	info := params.StateServingInfo{
+		APIPort:    69,
+		StatePort:  80,
+		Cert:       testing.ServerCert,
+		PrivateKey: testing.ServerKey,
+	}
+	err := s.State.SetStateServingInfo(info)
+	c.Assert(err, gc.IsNil)
+	wc.AssertOneChange()
+
+	// Stop, check closed.
+	statetesting.AssertStop(c, w)
+	wc.AssertClosed()
+}
+
 func (s *StateSuite) TestUnitActionsFindsRightActions(c *gc.C) {
 	// Add simple service and two units
 	mysql := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
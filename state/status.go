@@ -22,6 +22,13 @@ type statusDoc struct {
 	Status     params.Status
 	StatusInfo string
 	StatusData params.StatusData
+
+	// AgentActivity and ActivityHook record what a unit's agent
+	// is doing. They are set independently of the other fields,
+	// so they are omitted when empty to ensure that setting the
+	// status does not overwrite them.
+	AgentActivity params.AgentActivity `bson:",omitempty"`
+	ActivityHook  string               `bson:",omitempty"`
 }
 
 // validateSet returns an error if the statusDoc does not represent a sane
@@ -85,6 +92,21 @@ func updateStatusOp(st *State, globalKey string, doc statusDoc) txn.Op {
 	}
 }
 
+// updateAgentActivityOp returns the operation needed to update the
+// agent activity held in the status document associated with the
+// given globalKey.
+func updateAgentActivityOp(st *State, globalKey string, activity params.AgentActivity, hook string) txn.Op {
+	return txn.Op{
+		C:      st.statuses.Name,
+		Id:     globalKey,
+		Assert: txn.DocExists,
+		Update: bson.D{{"$set", bson.D{
+			{"agentactivity", activity},
+			{"activityhook", hook},
+		}}},
+	}
+}
+
 // removeStatusOp returns the operation needed to remove the status
 // document associated with the given globalKey.
 func removeStatusOp(st *State, globalKey string) txn.Op {
@@ -94,3 +116,73 @@ func removeStatusOp(st *State, globalKey string) txn.Op {
 		Remove: true,
 	}
 }
+
+// workloadStatusDoc represents the status of a unit's workload, as
+// reported by its charm, in MongoDB. It is kept separately from the
+// unit's agent status so that charms and the agent may each set
+// their own status without overwriting the other's. As with
+// statusDoc, the _id field holds the global key of the unit.
+type workloadStatusDoc struct {
+	Status     params.WorkloadStatus
+	StatusInfo string
+}
+
+// validateSet returns an error if the workloadStatusDoc does not
+// represent a sane SetWorkloadStatus operation.
+func (doc workloadStatusDoc) validateSet() error {
+	if !doc.Status.Valid() {
+		return fmt.Errorf("cannot set invalid workload status %q", doc.Status)
+	}
+	if doc.Status == params.WorkloadUnknown {
+		return fmt.Errorf("cannot set workload status %q", doc.Status)
+	}
+	return nil
+}
+
+// getWorkloadStatus returns the workload status document associated
+// with the given globalKey. Units created before workload status was
+// introduced have no such document, and are reported as having an
+// unknown workload status.
+func getWorkloadStatus(st *State, globalKey string) (workloadStatusDoc, error) {
+	var doc workloadStatusDoc
+	err := st.workloadStatuses.FindId(globalKey).One(&doc)
+	if err == mgo.ErrNotFound {
+		return workloadStatusDoc{Status: params.WorkloadUnknown}, nil
+	}
+	if err != nil {
+		return workloadStatusDoc{}, fmt.Errorf("cannot get workload status %q: %v", globalKey, err)
+	}
+	return doc, nil
+}
+
+// createWorkloadStatusOp returns the operation needed to create the
+// given workload status document associated with the given globalKey.
+func createWorkloadStatusOp(st *State, globalKey string, doc workloadStatusDoc) txn.Op {
+	return txn.Op{
+		C:      st.workloadStatuses.Name,
+		Id:     globalKey,
+		Assert: txn.DocMissing,
+		Insert: doc,
+	}
+}
+
+// updateWorkloadStatusOp returns the operation needed to update the
+// given workload status document associated with the given globalKey.
+func updateWorkloadStatusOp(st *State, globalKey string, doc workloadStatusDoc) txn.Op {
+	return txn.Op{
+		C:      st.workloadStatuses.Name,
+		Id:     globalKey,
+		Assert: txn.DocExists,
+		Update: bson.D{{"$set", doc}},
+	}
+}
+
+// removeWorkloadStatusOp returns the operation needed to remove the
+// workload status document associated with the given globalKey.
+func removeWorkloadStatusOp(st *State, globalKey string) txn.Op {
+	return txn.Op{
+		C:      st.workloadStatuses.Name,
+		Id:     globalKey,
+		Remove: true,
+	}
+}
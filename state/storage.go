@@ -0,0 +1,666 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/bson"
+	"labix.org/v2/mgo/txn"
+
+	"github.com/juju/juju/storage"
+)
+
+// StorageInstance represents one instance of a named store
+// required by a unit, such as "data/0". Each storage instance
+// is backed by a volume, on which a filesystem is created if
+// the instance is a filesystem.
+type StorageInstance struct {
+	st  *State
+	doc storageInstanceDoc
+}
+
+// storageInstanceDoc records a storage instance owned by a unit.
+type storageInstanceDoc struct {
+	Id          string       `bson:"_id"`
+	StorageName string       `bson:"storagename"`
+	Owner       string       `bson:"owner"`
+	Volume      string       `bson:"volume"`
+	Kind        storage.Kind `bson:"kind,omitempty"`
+}
+
+// Id returns the unique id of the storage instance, such as "data/0".
+func (s *StorageInstance) Id() string {
+	return s.doc.Id
+}
+
+// StorageName returns the name of the store the instance belongs to.
+func (s *StorageInstance) StorageName() string {
+	return s.doc.StorageName
+}
+
+// Owner returns the name of the unit that owns the storage instance.
+func (s *StorageInstance) Owner() string {
+	return s.doc.Owner
+}
+
+// Kind returns the kind of the storage instance.
+func (s *StorageInstance) Kind() storage.Kind {
+	if s.doc.Kind == "" {
+		return storage.KindBlock
+	}
+	return s.doc.Kind
+}
+
+// VolumeName returns the name of the volume backing the storage instance.
+func (s *StorageInstance) VolumeName() string {
+	return s.doc.Volume
+}
+
+// Volume represents a block device that backs a storage instance.
+type Volume struct {
+	st  *State
+	doc volumeDoc
+}
+
+// volumeDoc records a volume, and the details of its
+// creation once the storage provider has created it.
+type volumeDoc struct {
+	Name            string      `bson:"_id"`
+	Life            Life        `bson:"life"`
+	StorageInstance string      `bson:"storageinstanceid"`
+	Pool            string      `bson:"pool"`
+	Size            uint64      `bson:"size"`
+	Info            *VolumeInfo `bson:"info,omitempty"`
+}
+
+// VolumeInfo describes a volume created by a storage provider.
+type VolumeInfo struct {
+	VolumeId string `bson:"volumeid"`
+	Size     uint64 `bson:"size"`
+}
+
+// Name returns the unique name of the volume.
+func (v *Volume) Name() string {
+	return v.doc.Name
+}
+
+// Life returns the life state of the volume.
+func (v *Volume) Life() Life {
+	return v.doc.Life
+}
+
+// StorageInstance returns the id of the storage instance the volume backs.
+func (v *Volume) StorageInstance() string {
+	return v.doc.StorageInstance
+}
+
+// Params returns the parameters for creating the volume.
+func (v *Volume) Params() storage.VolumeParams {
+	return storage.VolumeParams{
+		Name:     v.doc.Name,
+		Size:     v.doc.Size,
+		Provider: storage.ProviderType(v.doc.Pool),
+	}
+}
+
+// Info returns the details of the volume, and whether
+// the volume has been created.
+func (v *Volume) Info() (VolumeInfo, bool) {
+	if v.doc.Info == nil {
+		return VolumeInfo{}, false
+	}
+	return *v.doc.Info, true
+}
+
+// VolumeAttachment represents the attachment of a volume to a machine.
+type VolumeAttachment struct {
+	st  *State
+	doc volumeAttachmentDoc
+}
+
+// volumeAttachmentDoc records the attachment of a volume to a
+// machine, and the details of the attachment once it is made.
+type volumeAttachmentDoc struct {
+	Id      string                `bson:"_id"`
+	Volume  string                `bson:"volumeid"`
+	Machine string                `bson:"machineid"`
+	Life    Life                  `bson:"life"`
+	Info    *VolumeAttachmentInfo `bson:"info,omitempty"`
+}
+
+// VolumeAttachmentInfo describes the attachment of a volume to a
+// machine. MountPoint is only set for volumes backing filesystems,
+// once the filesystem has been created and mounted.
+type VolumeAttachmentInfo struct {
+	DeviceName string `bson:"devicename"`
+	MountPoint string `bson:"mountpoint,omitempty"`
+}
+
+// volumeAttachmentId returns the id of the attachment
+// of the named volume to the machine with the given id.
+func volumeAttachmentId(machineId, volumeName string) string {
+	return machineId + ":" + volumeName
+}
+
+// Id returns the unique id of the volume attachment.
+func (a *VolumeAttachment) Id() string {
+	return a.doc.Id
+}
+
+// Volume returns the name of the attached volume.
+func (a *VolumeAttachment) Volume() string {
+	return a.doc.Volume
+}
+
+// Machine returns the id of the machine the volume is attached to.
+func (a *VolumeAttachment) Machine() string {
+	return a.doc.Machine
+}
+
+// Life returns the life state of the volume attachment.
+func (a *VolumeAttachment) Life() Life {
+	return a.doc.Life
+}
+
+// Info returns the details of the volume attachment,
+// and whether the volume has been attached.
+func (a *VolumeAttachment) Info() (VolumeAttachmentInfo, bool) {
+	if a.doc.Info == nil {
+		return VolumeAttachmentInfo{}, false
+	}
+	return *a.doc.Info, true
+}
+
+// StorageInstance returns the storage instance with the given id.
+func (st *State) StorageInstance(id string) (*StorageInstance, error) {
+	var doc storageInstanceDoc
+	err := st.storageInstances.FindId(id).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("storage instance %q", id)
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot get storage instance %q: %v", id, err)
+	}
+	return &StorageInstance{st, doc}, nil
+}
+
+// StorageInstances returns the storage instances owned by the unit.
+func (u *Unit) StorageInstances() ([]*StorageInstance, error) {
+	var docs []storageInstanceDoc
+	err := u.st.storageInstances.Find(bson.D{{"owner", u.doc.Name}}).All(&docs)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get storage instances of unit %q: %v", u, err)
+	}
+	instances := make([]*StorageInstance, len(docs))
+	for i, doc := range docs {
+		instances[i] = &StorageInstance{u.st, doc}
+	}
+	return instances, nil
+}
+
+// Volume returns the volume with the given name.
+func (st *State) Volume(name string) (*Volume, error) {
+	var doc volumeDoc
+	err := st.volumes.FindId(name).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("volume %q", name)
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot get volume %q: %v", name, err)
+	}
+	return &Volume{st, doc}, nil
+}
+
+// VolumeAttachment returns the attachment of the
+// named volume to the machine with the given id.
+func (st *State) VolumeAttachment(machineId, volumeName string) (*VolumeAttachment, error) {
+	var doc volumeAttachmentDoc
+	id := volumeAttachmentId(machineId, volumeName)
+	err := st.volumeAttachments.FindId(id).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("volume %q on machine %q", volumeName, machineId)
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot get volume %q on machine %q: %v", volumeName, machineId, err)
+	}
+	return &VolumeAttachment{st, doc}, nil
+}
+
+// Attachments returns the attachments of the volume to machines.
+func (v *Volume) Attachments() ([]*VolumeAttachment, error) {
+	var docs []volumeAttachmentDoc
+	err := v.st.volumeAttachments.Find(bson.D{{"volumeid", v.doc.Name}}).All(&docs)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get attachments of volume %q: %v", v.doc.Name, err)
+	}
+	attachments := make([]*VolumeAttachment, len(docs))
+	for i, doc := range docs {
+		attachments[i] = &VolumeAttachment{v.st, doc}
+	}
+	return attachments, nil
+}
+
+// VolumeAttachments returns the attachments of volumes to the machine.
+func (m *Machine) VolumeAttachments() ([]*VolumeAttachment, error) {
+	var docs []volumeAttachmentDoc
+	err := m.st.volumeAttachments.Find(bson.D{{"machineid", m.doc.Id}}).All(&docs)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get volume attachments of machine %q: %v", m, err)
+	}
+	attachments := make([]*VolumeAttachment, len(docs))
+	for i, doc := range docs {
+		attachments[i] = &VolumeAttachment{m.st, doc}
+	}
+	return attachments, nil
+}
+
+// SetVolumeInfo records the details of the named volume,
+// once it has been created by its storage provider.
+func (st *State) SetVolumeInfo(name string, info VolumeInfo) (err error) {
+	defer errors.Maskf(&err, "cannot set info for volume %q", name)
+	ops := []txn.Op{{
+		C:      st.volumes.Name,
+		Id:     name,
+		Assert: isAliveDoc,
+		Update: bson.D{{"$set", bson.D{{"info", &info}}}},
+	}}
+	return onAbort(st.runTransaction(ops), errNotAlive)
+}
+
+// SetVolumeAttachmentInfo records the details of the attachment of
+// the named volume to the machine with the given id, once the
+// volume has been attached.
+func (st *State) SetVolumeAttachmentInfo(machineId, volumeName string, info VolumeAttachmentInfo) (err error) {
+	defer errors.Maskf(&err, "cannot set info for volume %q on machine %q", volumeName, machineId)
+	ops := []txn.Op{{
+		C:      st.volumeAttachments.Name,
+		Id:     volumeAttachmentId(machineId, volumeName),
+		Assert: isAliveDoc,
+		Update: bson.D{{"$set", bson.D{{"info", &info}}}},
+	}}
+	return onAbort(st.runTransaction(ops), errNotAlive)
+}
+
+// SetVolumeAttachmentMountPoint records the mount point of the
+// filesystem created on the named volume, which must already be
+// attached to the machine with the given id.
+func (st *State) SetVolumeAttachmentMountPoint(machineId, volumeName, mountPoint string) (err error) {
+	defer errors.Maskf(&err, "cannot set mount point for volume %q on machine %q", volumeName, machineId)
+	ops := []txn.Op{{
+		C:      st.volumeAttachments.Name,
+		Id:     volumeAttachmentId(machineId, volumeName),
+		Assert: bson.D{{"life", Alive}, {"info", bson.D{{"$ne", nil}}}},
+		Update: bson.D{{"$set", bson.D{{"info.mountpoint", mountPoint}}}},
+	}}
+	if err := st.runTransaction(ops); err != txn.ErrAborted {
+		return err
+	}
+	attachment, err := st.VolumeAttachment(machineId, volumeName)
+	if err != nil {
+		return err
+	}
+	if attachment.Life() != Alive {
+		return errNotAlive
+	}
+	return fmt.Errorf("volume not attached")
+}
+
+// RemoveVolume removes the named volume and its attachments, once
+// its storage provider has destroyed it. The volume must not be
+// alive. Removing a volume that has already been removed is not
+// an error.
+func (st *State) RemoveVolume(name string) (err error) {
+	defer errors.Maskf(&err, "cannot remove volume %q", name)
+	attachmentOps, err := removeVolumeAttachmentsOps(st, bson.D{{"volumeid", name}})
+	if err != nil {
+		return err
+	}
+	ops := append([]txn.Op{{
+		C:      st.volumes.Name,
+		Id:     name,
+		Assert: bson.D{{"life", bson.D{{"$ne", Alive}}}},
+		Remove: true,
+	}}, attachmentOps...)
+	if err := st.runTransaction(ops); err != txn.ErrAborted {
+		return err
+	}
+	volume, err := st.Volume(name)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if volume.Life() == Alive {
+		return fmt.Errorf("volume is alive")
+	}
+	return ErrExcessiveContention
+}
+
+// removeVolumeAttachmentsOps returns the operations that remove
+// the volume attachments matching the given query.
+func removeVolumeAttachmentsOps(st *State, query bson.D) ([]txn.Op, error) {
+	var docs []volumeAttachmentDoc
+	if err := st.volumeAttachments.Find(query).All(&docs); err != nil {
+		return nil, err
+	}
+	var ops []txn.Op
+	for _, doc := range docs {
+		ops = append(ops, txn.Op{
+			C:      st.volumeAttachments.Name,
+			Id:     doc.Id,
+			Remove: true,
+		})
+	}
+	return ops, nil
+}
+
+// removeMachineVolumesOps returns the operations that remove the
+// attachments of volumes to the machine. Volumes cannot outlive the
+// machines they are attached to: those that were never provisioned
+// are removed, and the others are left dead, to be destroyed and
+// removed by the environment provisioner.
+func removeMachineVolumesOps(st *State, machineId string) ([]txn.Op, error) {
+	var docs []volumeAttachmentDoc
+	if err := st.volumeAttachments.Find(bson.D{{"machineid", machineId}}).All(&docs); err != nil {
+		return nil, err
+	}
+	var ops []txn.Op
+	for _, doc := range docs {
+		ops = append(ops, txn.Op{
+			C:      st.volumeAttachments.Name,
+			Id:     doc.Id,
+			Remove: true,
+		})
+		volume, err := st.Volume(doc.Volume)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if _, ok := volume.Info(); !ok {
+			ops = append(ops, txn.Op{
+				C:      st.volumes.Name,
+				Id:     doc.Volume,
+				Remove: true,
+			})
+			continue
+		}
+		ops = append(ops, txn.Op{
+			C:      st.volumes.Name,
+			Id:     doc.Volume,
+			Assert: txn.DocExists,
+			Update: bson.D{{"$set", bson.D{{"life", Dead}}}},
+		})
+	}
+	return ops, nil
+}
+
+// storageConstraintsDoc is the mongodb representation
+// of a service's storage constraints.
+type storageConstraintsDoc struct {
+	Constraints map[string]storageDirectiveDoc `bson:"constraints"`
+}
+
+// storageDirectiveDoc is the mongodb representation of a storage.Directive.
+type storageDirectiveDoc struct {
+	Pool  string       `bson:"pool"`
+	Size  uint64       `bson:"size"`
+	Count int          `bson:"count"`
+	Kind  storage.Kind `bson:"kind,omitempty"`
+}
+
+func removeStorageConstraintsOp(st *State, id string) txn.Op {
+	return txn.Op{
+		C:      st.storageCons.Name,
+		Id:     id,
+		Remove: true,
+	}
+}
+
+// StorageConstraints returns the storage required by each unit of
+// the service, keyed by store name.
+func (s *Service) StorageConstraints() (map[string]storage.Directive, error) {
+	var doc storageConstraintsDoc
+	err := s.st.storageCons.FindId(s.globalKey()).One(&doc)
+	if err != nil && err != mgo.ErrNotFound {
+		return nil, fmt.Errorf("cannot get storage constraints of service %q: %v", s, err)
+	}
+	cons := make(map[string]storage.Directive)
+	for name, d := range doc.Constraints {
+		cons[name] = storage.Directive{Pool: d.Pool, Size: d.Size, Count: d.Count, Kind: d.Kind}
+	}
+	return cons, nil
+}
+
+// SetStorageConstraints sets the storage required by each unit of the
+// service that is added later, keyed by store name. Directives that
+// do not name a pool use the environment's default storage provider.
+func (s *Service) SetStorageConstraints(cons map[string]storage.Directive) (err error) {
+	defer errors.Maskf(&err, "cannot set storage constraints")
+	if s.doc.Subordinate {
+		return fmt.Errorf("service is a subordinate")
+	}
+	if s.doc.Life != Alive {
+		return errNotAlive
+	}
+	cons, err = s.st.resolveStorageConstraints(cons)
+	if err != nil {
+		return err
+	}
+	doc := storageConstraintsDoc{Constraints: make(map[string]storageDirectiveDoc)}
+	for name, d := range cons {
+		doc.Constraints[name] = storageDirectiveDoc{Pool: d.Pool, Size: d.Size, Count: d.Count, Kind: d.Kind}
+	}
+	for i := 0; i < 3; i++ {
+		consOp := txn.Op{
+			C:  s.st.storageCons.Name,
+			Id: s.globalKey(),
+		}
+		n, err := s.st.storageCons.FindId(s.globalKey()).Count()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			consOp.Assert = txn.DocMissing
+			consOp.Insert = &doc
+		} else {
+			consOp.Assert = txn.DocExists
+			consOp.Update = bson.D{{"$set", bson.D{{"constraints", doc.Constraints}}}}
+		}
+		ops := []txn.Op{{
+			C:      s.st.services.Name,
+			Id:     s.doc.Name,
+			Assert: isAliveDoc,
+		}, consOp}
+		if err := s.st.runTransaction(ops); err != txn.ErrAborted {
+			return err
+		}
+		if alive, err := isAlive(s.st.services, s.doc.Name); err != nil {
+			return err
+		} else if !alive {
+			return errNotAlive
+		}
+	}
+	return ErrExcessiveContention
+}
+
+// resolveStorageConstraints checks the given storage constraints,
+// and returns them with the environment's default storage provider
+// in place of any pool that is not specified.
+func (st *State) resolveStorageConstraints(cons map[string]storage.Directive) (map[string]storage.Directive, error) {
+	resolved := make(map[string]storage.Directive)
+	for name, d := range cons {
+		if name == "" || strings.ContainsAny(name, "/:") {
+			return nil, fmt.Errorf("invalid storage name %q", name)
+		}
+		if d.Size == 0 || d.Count < 1 {
+			return nil, fmt.Errorf("invalid storage directive for %q", name)
+		}
+		switch d.Kind {
+		case "", storage.KindBlock, storage.KindFilesystem:
+		default:
+			return nil, fmt.Errorf("invalid storage kind %q for %q", d.Kind, name)
+		}
+		if d.Pool == "" {
+			cfg, err := st.EnvironConfig()
+			if err != nil {
+				return nil, err
+			}
+			pool, ok := storage.DefaultProvider(cfg.Type())
+			if !ok {
+				return nil, fmt.Errorf("no storage pool specified for %q, and environment has no default", name)
+			}
+			d.Pool = string(pool)
+		}
+		if _, err := storage.StorageProvider(storage.ProviderType(d.Pool)); err != nil {
+			return nil, fmt.Errorf("invalid storage pool for %q: %v", name, err)
+		}
+		resolved[name] = d
+	}
+	return resolved, nil
+}
+
+// unitStorageOps returns the operations that create the storage
+// instances, and the volumes backing them, required by a new unit
+// of the service. The given constraints override those of the
+// service.
+func (s *Service) unitStorageOps(unitName string, cons map[string]storage.Directive) ([]txn.Op, error) {
+	all, err := s.StorageConstraints()
+	if err != nil {
+		return nil, err
+	}
+	cons, err = s.st.resolveStorageConstraints(cons)
+	if err != nil {
+		return nil, err
+	}
+	for name, d := range cons {
+		all[name] = d
+	}
+	var names []string
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var ops []txn.Op
+	for _, name := range names {
+		d := all[name]
+		for i := 0; i < d.Count; i++ {
+			seq, err := s.st.sequence("storage-" + name)
+			if err != nil {
+				return nil, err
+			}
+			id := fmt.Sprintf("%s/%d", name, seq)
+			seq, err = s.st.sequence("volume")
+			if err != nil {
+				return nil, err
+			}
+			volumeName := strconv.Itoa(seq)
+			ops = append(ops, txn.Op{
+				C:      s.st.storageInstances.Name,
+				Id:     id,
+				Assert: txn.DocMissing,
+				Insert: &storageInstanceDoc{
+					Id:          id,
+					StorageName: name,
+					Owner:       unitName,
+					Volume:      volumeName,
+					Kind:        d.Kind,
+				},
+			}, txn.Op{
+				C:      s.st.volumes.Name,
+				Id:     volumeName,
+				Assert: txn.DocMissing,
+				Insert: &volumeDoc{
+					Name:            volumeName,
+					Life:            Alive,
+					StorageInstance: id,
+					Pool:            d.Pool,
+					Size:            d.Size,
+				},
+			})
+		}
+	}
+	return ops, nil
+}
+
+// volumeAttachmentOps returns the operations that attach the
+// volumes of the unit's storage instances to the machine with
+// the given id.
+func (u *Unit) volumeAttachmentOps(machineId string) ([]txn.Op, error) {
+	instances, err := u.StorageInstances()
+	if err != nil {
+		return nil, err
+	}
+	var ops []txn.Op
+	for _, instance := range instances {
+		volumeName := instance.VolumeName()
+		id := volumeAttachmentId(machineId, volumeName)
+		ops = append(ops, txn.Op{
+			C:      u.st.volumes.Name,
+			Id:     volumeName,
+			Assert: isAliveDoc,
+		}, txn.Op{
+			C:      u.st.volumeAttachments.Name,
+			Id:     id,
+			Assert: txn.DocMissing,
+			Insert: &volumeAttachmentDoc{
+				Id:      id,
+				Volume:  volumeName,
+				Machine: machineId,
+				Life:    Alive,
+			},
+		})
+	}
+	return ops, nil
+}
+
+// destroyUnitStorage removes the storage instances of the named unit,
+// once the unit has been removed, and marks their volumes and volume
+// attachments as dying, so that the volumes are destroyed.
+func (st *State) destroyUnitStorage(unitName string) error {
+	var docs []storageInstanceDoc
+	if err := st.storageInstances.Find(bson.D{{"owner", unitName}}).All(&docs); err != nil {
+		return fmt.Errorf("cannot get storage instances of unit %q: %v", unitName, err)
+	}
+	for _, doc := range docs {
+		ops := []txn.Op{{
+			C:      st.storageInstances.Name,
+			Id:     doc.Id,
+			Remove: true,
+		}}
+		var attachments []volumeAttachmentDoc
+		err := st.volumeAttachments.Find(bson.D{{"volumeid", doc.Volume}}).All(&attachments)
+		if err != nil {
+			return err
+		}
+		for _, attachment := range attachments {
+			ops = append(ops, txn.Op{
+				C:      st.volumeAttachments.Name,
+				Id:     attachment.Id,
+				Assert: txn.DocExists,
+				Update: bson.D{{"$set", bson.D{{"life", Dying}}}},
+			})
+		}
+		// The volume may already have been left dead,
+		// or removed, along with its machine.
+		volume, err := st.Volume(doc.Volume)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		} else if err == nil && volume.Life() == Alive {
+			ops = append(ops, txn.Op{
+				C:      st.volumes.Name,
+				Id:     doc.Volume,
+				Assert: isAliveDoc,
+				Update: bson.D{{"$set", bson.D{{"life", Dying}}}},
+			})
+		}
+		if err := st.runTransaction(ops); err != nil {
+			return fmt.Errorf("cannot destroy storage instance %q: %v", doc.Id, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,389 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/testing"
+	"github.com/juju/juju/storage"
+	_ "github.com/juju/juju/storage/provider"
+)
+
+type StorageSuite struct {
+	ConnSuite
+	mysql *state.Service
+}
+
+var _ = gc.Suite(&StorageSuite{})
+
+func (s *StorageSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.mysql = s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+}
+
+func (s *StorageSuite) assertStorageInstances(c *gc.C, u *state.Unit, expect map[string]storage.VolumeParams) {
+	instances, err := u.StorageInstances()
+	c.Assert(err, gc.IsNil)
+	actual := make(map[string]storage.VolumeParams)
+	for _, si := range instances {
+		c.Assert(si.Owner(), gc.Equals, u.Name())
+		volume, err := s.State.Volume(si.VolumeName())
+		c.Assert(err, gc.IsNil)
+		c.Assert(volume.StorageInstance(), gc.Equals, si.Id())
+		c.Assert(volume.Life(), gc.Equals, state.Alive)
+		params := volume.Params()
+		params.Name = ""
+		actual[si.Id()] = params
+	}
+	c.Assert(actual, gc.DeepEquals, expect)
+}
+
+func (s *StorageSuite) TestStorageConstraints(c *gc.C) {
+	cons, err := s.mysql.StorageConstraints()
+	c.Assert(err, gc.IsNil)
+	c.Assert(cons, gc.HasLen, 0)
+
+	expect := map[string]storage.Directive{
+		"data": {Pool: "loop", Size: 1024, Count: 2},
+		"logs": {Pool: "loop", Size: 512, Count: 1, Kind: storage.KindFilesystem},
+	}
+	err = s.mysql.SetStorageConstraints(expect)
+	c.Assert(err, gc.IsNil)
+	cons, err = s.mysql.StorageConstraints()
+	c.Assert(err, gc.IsNil)
+	c.Assert(cons, gc.DeepEquals, expect)
+
+	// Setting the constraints again replaces them.
+	expect = map[string]storage.Directive{
+		"logs": {Pool: "loop", Size: 512, Count: 1},
+	}
+	err = s.mysql.SetStorageConstraints(expect)
+	c.Assert(err, gc.IsNil)
+	cons, err = s.mysql.StorageConstraints()
+	c.Assert(err, gc.IsNil)
+	c.Assert(cons, gc.DeepEquals, expect)
+}
+
+var invalidStorageConstraintsTests = []struct {
+	cons map[string]storage.Directive
+	err  string
+}{{
+	cons: map[string]storage.Directive{"data/0": {Pool: "loop", Size: 1024, Count: 1}},
+	err:  `invalid storage name "data/0"`,
+}, {
+	cons: map[string]storage.Directive{"data": {Pool: "loop", Size: 1024}},
+	err:  `invalid storage directive for "data"`,
+}, {
+	cons: map[string]storage.Directive{"data": {Pool: "bogus", Size: 1024, Count: 1}},
+	err:  `invalid storage pool for "data": storage provider "bogus" not found`,
+}, {
+	cons: map[string]storage.Directive{"data": {Size: 1024, Count: 1}},
+	err:  `no storage pool specified for "data", and environment has no default`,
+}, {
+	cons: map[string]storage.Directive{"data": {Pool: "loop", Size: 1024, Count: 1, Kind: "tape"}},
+	err:  `invalid storage kind "tape" for "data"`,
+}}
+
+func (s *StorageSuite) TestSetInvalidStorageConstraints(c *gc.C) {
+	for i, test := range invalidStorageConstraintsTests {
+		c.Logf("test %d", i)
+		err := s.mysql.SetStorageConstraints(test.cons)
+		c.Check(err, gc.ErrorMatches, "cannot set storage constraints: "+test.err)
+	}
+}
+
+func (s *StorageSuite) TestSetStorageConstraintsSubordinate(c *gc.C) {
+	logging := s.AddTestingService(c, "logging", s.AddTestingCharm(c, "logging"))
+	err := logging.SetStorageConstraints(map[string]storage.Directive{
+		"data": {Pool: "loop", Size: 1024, Count: 1},
+	})
+	c.Assert(err, gc.ErrorMatches, "cannot set storage constraints: service is a subordinate")
+}
+
+func (s *StorageSuite) TestAddUnitCreatesStorage(c *gc.C) {
+	err := s.mysql.SetStorageConstraints(map[string]storage.Directive{
+		"data": {Pool: "loop", Size: 1024, Count: 2},
+	})
+	c.Assert(err, gc.IsNil)
+	u0, err := s.mysql.AddUnit()
+	c.Assert(err, gc.IsNil)
+	s.assertStorageInstances(c, u0, map[string]storage.VolumeParams{
+		"data/0": {Size: 1024, Provider: "loop"},
+		"data/1": {Size: 1024, Provider: "loop"},
+	})
+
+	u1, err := s.mysql.AddUnitWithStorage(map[string]storage.Directive{
+		"data": {Pool: "loop", Size: 2048, Count: 1},
+		"logs": {Pool: "loop", Size: 100, Count: 1},
+	})
+	c.Assert(err, gc.IsNil)
+	s.assertStorageInstances(c, u1, map[string]storage.VolumeParams{
+		"data/2": {Size: 2048, Provider: "loop"},
+		"logs/0": {Size: 100, Provider: "loop"},
+	})
+
+	si, err := s.State.StorageInstance("logs/0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(si.StorageName(), gc.Equals, "logs")
+	c.Assert(si.Owner(), gc.Equals, u1.Name())
+	c.Assert(si.Kind(), gc.Equals, storage.KindBlock)
+	_, err = s.State.StorageInstance("logs/1")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *StorageSuite) TestAssignUnitAttachesVolumes(c *gc.C) {
+	u, err := s.mysql.AddUnitWithStorage(map[string]storage.Directive{
+		"data": {Pool: "loop", Size: 1024, Count: 1},
+	})
+	c.Assert(err, gc.IsNil)
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+
+	w := machine.WatchVolumeAttachments()
+	defer testing.AssertStop(c, w)
+	wc := testing.NewStringsWatcherC(c, s.State, w)
+	wc.AssertChange()
+	wc.AssertNoChange()
+	sw := u.WatchStorage()
+	defer testing.AssertStop(c, sw)
+	swc := testing.NewNotifyWatcherC(c, s.State, sw)
+	swc.AssertOneChange()
+
+	err = u.AssignToMachine(machine)
+	c.Assert(err, gc.IsNil)
+	wc.AssertChange(machine.Id() + ":0")
+	wc.AssertNoChange()
+	swc.AssertOneChange()
+
+	attachments, err := machine.VolumeAttachments()
+	c.Assert(err, gc.IsNil)
+	c.Assert(attachments, gc.HasLen, 1)
+	attachment := attachments[0]
+	c.Assert(attachment.Volume(), gc.Equals, "0")
+	c.Assert(attachment.Machine(), gc.Equals, machine.Id())
+	c.Assert(attachment.Life(), gc.Equals, state.Alive)
+	_, ok := attachment.Info()
+	c.Assert(ok, jc.IsFalse)
+
+	err = s.State.SetVolumeInfo("0", state.VolumeInfo{VolumeId: "volume-0", Size: 1024})
+	c.Assert(err, gc.IsNil)
+	err = s.State.SetVolumeAttachmentInfo(machine.Id(), "0", state.VolumeAttachmentInfo{DeviceName: "loop0"})
+	c.Assert(err, gc.IsNil)
+	swc.AssertOneChange()
+	wc.AssertNoChange()
+
+	volume, err := s.State.Volume("0")
+	c.Assert(err, gc.IsNil)
+	info, ok := volume.Info()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(info, gc.Equals, state.VolumeInfo{VolumeId: "volume-0", Size: 1024})
+	attachment, err = s.State.VolumeAttachment(machine.Id(), "0")
+	c.Assert(err, gc.IsNil)
+	attachmentInfo, ok := attachment.Info()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(attachmentInfo, gc.Equals, state.VolumeAttachmentInfo{DeviceName: "loop0"})
+
+	// Unassigning the unit removes the attachment.
+	err = u.UnassignFromMachine()
+	c.Assert(err, gc.IsNil)
+	wc.AssertChange(machine.Id() + ":0")
+	_, err = s.State.VolumeAttachment(machine.Id(), "0")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *StorageSuite) TestFilesystemMountPoint(c *gc.C) {
+	u, err := s.mysql.AddUnitWithStorage(map[string]storage.Directive{
+		"data": {Pool: "loop", Size: 1024, Count: 1, Kind: storage.KindFilesystem},
+	})
+	c.Assert(err, gc.IsNil)
+	si, err := s.State.StorageInstance("data/0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(si.Kind(), gc.Equals, storage.KindFilesystem)
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	err = u.AssignToMachine(machine)
+	c.Assert(err, gc.IsNil)
+
+	// The volume must be attached before its filesystem is mounted.
+	err = s.State.SetVolumeAttachmentMountPoint(machine.Id(), "0", "/srv/data")
+	c.Assert(err, gc.ErrorMatches, `cannot set mount point for volume "0" on machine "[0-9]+": volume not attached`)
+
+	sw := u.WatchStorage()
+	defer testing.AssertStop(c, sw)
+	swc := testing.NewNotifyWatcherC(c, s.State, sw)
+	swc.AssertOneChange()
+	err = s.State.SetVolumeAttachmentInfo(machine.Id(), "0", state.VolumeAttachmentInfo{DeviceName: "loop0"})
+	c.Assert(err, gc.IsNil)
+	swc.AssertOneChange()
+	err = s.State.SetVolumeAttachmentMountPoint(machine.Id(), "0", "/srv/data")
+	c.Assert(err, gc.IsNil)
+	swc.AssertOneChange()
+
+	attachment, err := s.State.VolumeAttachment(machine.Id(), "0")
+	c.Assert(err, gc.IsNil)
+	info, ok := attachment.Info()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(info, gc.Equals, state.VolumeAttachmentInfo{DeviceName: "loop0", MountPoint: "/srv/data"})
+}
+
+func (s *StorageSuite) TestAssignUnitToNewMachineAttachesVolumes(c *gc.C) {
+	u, err := s.mysql.AddUnitWithStorage(map[string]storage.Directive{
+		"data": {Pool: "loop", Size: 1024, Count: 2},
+	})
+	c.Assert(err, gc.IsNil)
+	err = u.AssignToNewMachine()
+	c.Assert(err, gc.IsNil)
+	machineId, err := u.AssignedMachineId()
+	c.Assert(err, gc.IsNil)
+	machine, err := s.State.Machine(machineId)
+	c.Assert(err, gc.IsNil)
+	attachments, err := machine.VolumeAttachments()
+	c.Assert(err, gc.IsNil)
+	c.Assert(attachments, gc.HasLen, 2)
+}
+
+func (s *StorageSuite) TestRemoveUnitDestroysStorage(c *gc.C) {
+	u, err := s.mysql.AddUnitWithStorage(map[string]storage.Directive{
+		"data": {Pool: "loop", Size: 1024, Count: 1},
+	})
+	c.Assert(err, gc.IsNil)
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	err = u.AssignToMachine(machine)
+	c.Assert(err, gc.IsNil)
+
+	err = s.State.RemoveVolume("0")
+	c.Assert(err, gc.ErrorMatches, `cannot remove volume "0": volume is alive`)
+
+	err = u.EnsureDead()
+	c.Assert(err, gc.IsNil)
+	err = u.Remove()
+	c.Assert(err, gc.IsNil)
+	err = s.State.Cleanup()
+	c.Assert(err, gc.IsNil)
+
+	_, err = s.State.StorageInstance("data/0")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	volume, err := s.State.Volume("0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(volume.Life(), gc.Equals, state.Dying)
+	attachment, err := s.State.VolumeAttachment(machine.Id(), "0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(attachment.Life(), gc.Equals, state.Dying)
+
+	err = s.State.RemoveVolume("0")
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.Volume("0")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.State.VolumeAttachment(machine.Id(), "0")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	// Removing the volume again is not an error.
+	err = s.State.RemoveVolume("0")
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *StorageSuite) TestRemoveMachineRemovesVolumes(c *gc.C) {
+	u, err := s.mysql.AddUnitWithStorage(map[string]storage.Directive{
+		"data": {Pool: "loop", Size: 1024, Count: 1},
+	})
+	c.Assert(err, gc.IsNil)
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	err = u.AssignToMachine(machine)
+	c.Assert(err, gc.IsNil)
+	err = u.EnsureDead()
+	c.Assert(err, gc.IsNil)
+	err = u.Remove()
+	c.Assert(err, gc.IsNil)
+
+	err = machine.EnsureDead()
+	c.Assert(err, gc.IsNil)
+	err = machine.Remove()
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.Volume("0")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.State.VolumeAttachment(machine.Id(), "0")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	// Cleaning up after the removed unit copes with the volume
+	// having been removed.
+	err = s.State.Cleanup()
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.StorageInstance("data/0")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *StorageSuite) TestRemoveMachineLeavesProvisionedVolumesDead(c *gc.C) {
+	u, err := s.mysql.AddUnitWithStorage(map[string]storage.Directive{
+		"data": {Pool: "loop", Size: 1024, Count: 1},
+	})
+	c.Assert(err, gc.IsNil)
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	err = u.AssignToMachine(machine)
+	c.Assert(err, gc.IsNil)
+	err = s.State.SetVolumeInfo("0", state.VolumeInfo{VolumeId: "volume-0", Size: 1024})
+	c.Assert(err, gc.IsNil)
+	err = u.EnsureDead()
+	c.Assert(err, gc.IsNil)
+	err = u.Remove()
+	c.Assert(err, gc.IsNil)
+
+	err = machine.EnsureDead()
+	c.Assert(err, gc.IsNil)
+	err = machine.Remove()
+	c.Assert(err, gc.IsNil)
+	volume, err := s.State.Volume("0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(volume.Life(), gc.Equals, state.Dead)
+	attachments, err := volume.Attachments()
+	c.Assert(err, gc.IsNil)
+	c.Assert(attachments, gc.HasLen, 0)
+
+	// Cleaning up after the removed unit leaves the volume dead.
+	err = s.State.Cleanup()
+	c.Assert(err, gc.IsNil)
+	volume, err = s.State.Volume("0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(volume.Life(), gc.Equals, state.Dead)
+
+	err = s.State.RemoveVolume("0")
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.Volume("0")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *StorageSuite) TestWatchVolumes(c *gc.C) {
+	w := s.State.WatchVolumes()
+	defer testing.AssertStop(c, w)
+	wc := testing.NewStringsWatcherC(c, s.State, w)
+	wc.AssertChange()
+	wc.AssertNoChange()
+
+	u, err := s.mysql.AddUnitWithStorage(map[string]storage.Directive{
+		"data": {Pool: "loop", Size: 1024, Count: 1},
+	})
+	c.Assert(err, gc.IsNil)
+	wc.AssertChange("0")
+	wc.AssertNoChange()
+
+	err = u.EnsureDead()
+	c.Assert(err, gc.IsNil)
+	err = u.Remove()
+	c.Assert(err, gc.IsNil)
+	err = s.State.Cleanup()
+	c.Assert(err, gc.IsNil)
+	wc.AssertChange("0")
+	wc.AssertNoChange()
+
+	err = s.State.RemoveVolume("0")
+	c.Assert(err, gc.IsNil)
+	wc.AssertChange("0")
+	wc.AssertNoChange()
+}
@@ -0,0 +1,209 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/juju/errors"
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/bson"
+	"labix.org/v2/mgo/txn"
+
+	"github.com/juju/juju/network"
+)
+
+// Subnet represents the state of a subnet.
+type Subnet struct {
+	st  *State
+	doc subnetDoc
+}
+
+// SubnetInfo describes a single subnet.
+type SubnetInfo struct {
+	// CIDR of the subnet, in 123.45.67.89/24 format.
+	CIDR string
+
+	// ProviderId is a provider-specific subnet id. It may be empty.
+	ProviderId network.Id
+
+	// VLANTag needs to be between 1 and 4094 for VLANs and 0 for
+	// normal subnets. It's defined by IEEE 802.1Q standard.
+	VLANTag int
+
+	// AvailabilityZones lists the availability zones in which the
+	// subnet is usable.
+	AvailabilityZones []string
+
+	// SpaceName is the name of the space the subnet belongs to. It
+	// is empty if the subnet is not part of any space.
+	SpaceName string
+}
+
+// subnetDoc represents a subnet known to juju. Subnets are keyed by
+// their CIDR, which is unique within an environment.
+type subnetDoc struct {
+	CIDR              string     `bson:"_id"`
+	ProviderId        network.Id `bson:"providerid"`
+	VLANTag           int        `bson:"vlantag"`
+	AvailabilityZones []string   `bson:"availabilityzones,omitempty"`
+	SpaceName         string     `bson:"spacename"`
+}
+
+// GoString implements fmt.GoStringer.
+func (s *Subnet) GoString() string {
+	return fmt.Sprintf(
+		"&state.Subnet{cidr: %q, providerId: %q, space: %q}",
+		s.CIDR(), s.ProviderId(), s.SpaceName())
+}
+
+// CIDR returns the subnet CIDR (e.g. 192.168.50.0/24).
+func (s *Subnet) CIDR() string {
+	return s.doc.CIDR
+}
+
+// ProviderId returns the provider-specific id of the subnet, or the
+// empty string if it has none.
+func (s *Subnet) ProviderId() network.Id {
+	return s.doc.ProviderId
+}
+
+// VLANTag returns the subnet VLAN tag. It's a number between 1 and
+// 4094 for VLANs and 0 if the subnet is not a VLAN.
+func (s *Subnet) VLANTag() int {
+	return s.doc.VLANTag
+}
+
+// AvailabilityZones returns the availability zones in which the
+// subnet is usable.
+func (s *Subnet) AvailabilityZones() []string {
+	return s.doc.AvailabilityZones
+}
+
+// SpaceName returns the name of the space the subnet belongs to, or
+// the empty string if it is not part of any space.
+func (s *Subnet) SpaceName() string {
+	return s.doc.SpaceName
+}
+
+// Contains reports whether the given address value lies within the
+// subnet.
+func (s *Subnet) Contains(value string) bool {
+	return network.SubnetContains(s.doc.CIDR, value)
+}
+
+// AddSubnet records a new subnet, optionally as part of an existing
+// space. The CIDR must be given in its canonical form, with no bits
+// set beyond the prefix.
+func (st *State) AddSubnet(args SubnetInfo) (_ *Subnet, err error) {
+	defer errors.Contextf(&err, "cannot add subnet %q", args.CIDR)
+	if args.CIDR == "" {
+		return nil, fmt.Errorf("CIDR must be not empty")
+	}
+	ip, ipNet, err := net.ParseCIDR(args.CIDR)
+	if err != nil {
+		return nil, err
+	}
+	if !ip.Equal(ipNet.IP) {
+		return nil, fmt.Errorf("CIDR not canonical: expected %q", ipNet.String())
+	}
+	if args.VLANTag < 0 || args.VLANTag > 4094 {
+		return nil, fmt.Errorf("invalid VLAN tag %d: must be between 0 and 4094", args.VLANTag)
+	}
+	if args.SpaceName != "" && !network.IsValidSpaceName(args.SpaceName) {
+		return nil, fmt.Errorf("invalid space name %q", args.SpaceName)
+	}
+	if args.ProviderId != "" {
+		// Subnets without a provider id are common, so the
+		// uniqueness of provider ids cannot be left to an index.
+		n, err := st.subnets.Find(bson.D{{"providerid", args.ProviderId}}).Count()
+		if err != nil {
+			return nil, err
+		}
+		if n > 0 {
+			return nil, errors.AlreadyExistsf("subnet with provider id %q", args.ProviderId)
+		}
+	}
+	doc := subnetDoc{
+		CIDR:              args.CIDR,
+		ProviderId:        args.ProviderId,
+		VLANTag:           args.VLANTag,
+		AvailabilityZones: args.AvailabilityZones,
+		SpaceName:         args.SpaceName,
+	}
+	ops := []txn.Op{{
+		C:      st.subnets.Name,
+		Id:     args.CIDR,
+		Assert: txn.DocMissing,
+		Insert: &doc,
+	}}
+	if args.SpaceName != "" {
+		ops = append(ops, txn.Op{
+			C:      st.spaces.Name,
+			Id:     args.SpaceName,
+			Assert: txn.DocExists,
+		})
+	}
+	err = st.runTransaction(ops)
+	if err == txn.ErrAborted {
+		if _, err := st.Subnet(args.CIDR); err == nil {
+			return nil, errors.AlreadyExistsf("subnet %q", args.CIDR)
+		} else if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		if args.SpaceName != "" {
+			if _, err := st.Space(args.SpaceName); err != nil {
+				return nil, err
+			}
+		}
+		return nil, fmt.Errorf("concurrent change detected")
+	} else if err != nil {
+		return nil, err
+	}
+	return &Subnet{st, doc}, nil
+}
+
+// Subnet returns the subnet with the given CIDR.
+func (st *State) Subnet(cidr string) (*Subnet, error) {
+	s := &Subnet{st: st}
+	err := st.subnets.FindId(cidr).One(&s.doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("subnet %q", cidr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot get subnet %q: %v", cidr, err)
+	}
+	return s, nil
+}
+
+// SubnetByProviderId returns the subnet with the given provider id.
+func (st *State) SubnetByProviderId(id network.Id) (*Subnet, error) {
+	s := &Subnet{st: st}
+	err := st.subnets.Find(bson.D{{"providerid", id}}).One(&s.doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("subnet with provider id %q", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot get subnet with provider id %q: %v", id, err)
+	}
+	return s, nil
+}
+
+// AllSubnets returns all known subnets in the environment.
+func (st *State) AllSubnets() ([]*Subnet, error) {
+	return st.findSubnets(nil)
+}
+
+func (st *State) findSubnets(sel interface{}) ([]*Subnet, error) {
+	var docs []subnetDoc
+	if err := st.subnets.Find(sel).Sort("_id").All(&docs); err != nil {
+		return nil, fmt.Errorf("cannot get subnets: %v", err)
+	}
+	subnets := make([]*Subnet, len(docs))
+	for i, doc := range docs {
+		subnets[i] = &Subnet{st, doc}
+	}
+	return subnets, nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/state"
+)
+
+type SubnetsSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&SubnetsSuite{})
+
+func (s *SubnetsSuite) TestAddSubnet(c *gc.C) {
+	subnet, err := s.State.AddSubnet(state.SubnetInfo{
+		CIDR:              "10.0.0.0/24",
+		ProviderId:        "subnet-1",
+		VLANTag:           42,
+		AvailabilityZones: []string{"zone1", "zone2"},
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(subnet.CIDR(), gc.Equals, "10.0.0.0/24")
+	c.Assert(subnet.ProviderId(), gc.Equals, network.Id("subnet-1"))
+	c.Assert(subnet.VLANTag(), gc.Equals, 42)
+	c.Assert(subnet.AvailabilityZones(), gc.DeepEquals, []string{"zone1", "zone2"})
+	c.Assert(subnet.SpaceName(), gc.Equals, "")
+	c.Assert(subnet.Contains("10.0.0.42"), jc.IsTrue)
+	c.Assert(subnet.Contains("10.0.1.42"), jc.IsFalse)
+
+	got, err := s.State.Subnet("10.0.0.0/24")
+	c.Assert(err, gc.IsNil)
+	c.Assert(got, jc.DeepEquals, subnet)
+	got, err = s.State.SubnetByProviderId("subnet-1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(got, jc.DeepEquals, subnet)
+}
+
+func (s *SubnetsSuite) TestAddSubnetInSpace(c *gc.C) {
+	_, err := s.State.AddSpace("db", nil)
+	c.Assert(err, gc.IsNil)
+	subnet, err := s.State.AddSubnet(state.SubnetInfo{CIDR: "10.0.0.0/24", SpaceName: "db"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(subnet.SpaceName(), gc.Equals, "db")
+}
+
+var addSubnetErrorsTests = []struct {
+	args      state.SubnetInfo
+	expectErr string
+}{{
+	state.SubnetInfo{},
+	`cannot add subnet "": CIDR must be not empty`,
+}, {
+	state.SubnetInfo{CIDR: "invalid"},
+	`cannot add subnet "invalid": invalid CIDR address: invalid`,
+}, {
+	state.SubnetInfo{CIDR: "10.0.0.1/24"},
+	`cannot add subnet "10.0.0.1/24": CIDR not canonical: expected "10.0.0.0/24"`,
+}, {
+	state.SubnetInfo{CIDR: "10.0.1.0/24", VLANTag: 4095},
+	`cannot add subnet "10.0.1.0/24": invalid VLAN tag 4095: must be between 0 and 4094`,
+}, {
+	state.SubnetInfo{CIDR: "10.0.1.0/24", SpaceName: "-db"},
+	`cannot add subnet "10.0.1.0/24": invalid space name "-db"`,
+}, {
+	state.SubnetInfo{CIDR: "10.0.1.0/24", SpaceName: "missing"},
+	`cannot add subnet "10.0.1.0/24": space "missing" not found`,
+}, {
+	state.SubnetInfo{CIDR: "10.0.0.0/24"},
+	`cannot add subnet "10.0.0.0/24": subnet "10.0.0.0/24" already exists`,
+}, {
+	state.SubnetInfo{CIDR: "10.0.1.0/24", ProviderId: "subnet-0"},
+	`cannot add subnet "10.0.1.0/24": subnet with provider id "subnet-0" already exists`,
+}}
+
+func (s *SubnetsSuite) TestAddSubnetErrors(c *gc.C) {
+	_, err := s.State.AddSubnet(state.SubnetInfo{CIDR: "10.0.0.0/24", ProviderId: "subnet-0"})
+	c.Assert(err, gc.IsNil)
+
+	for i, test := range addSubnetErrorsTests {
+		c.Logf("test %d: %#v", i, test.args)
+		_, err := s.State.AddSubnet(test.args)
+		c.Check(err, gc.ErrorMatches, test.expectErr)
+	}
+	_, err = s.State.AddSubnet(state.SubnetInfo{CIDR: "10.0.0.0/24"})
+	c.Check(err, jc.Satisfies, errors.IsAlreadyExists)
+}
+
+func (s *SubnetsSuite) TestSubnetNotFound(c *gc.C) {
+	_, err := s.State.Subnet("10.0.0.0/24")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `subnet "10.0.0.0/24" not found`)
+	_, err = s.State.SubnetByProviderId("subnet-0")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *SubnetsSuite) TestAllSubnets(c *gc.C) {
+	subnets, err := s.State.AllSubnets()
+	c.Assert(err, gc.IsNil)
+	c.Assert(subnets, gc.HasLen, 0)
+
+	for _, cidr := range []string{"10.0.1.0/24", "10.0.0.0/24"} {
+		_, err := s.State.AddSubnet(state.SubnetInfo{CIDR: cidr})
+		c.Assert(err, gc.IsNil)
+	}
+	subnets, err = s.State.AllSubnets()
+	c.Assert(err, gc.IsNil)
+	c.Assert(subnets, gc.HasLen, 2)
+	c.Assert(subnets[0].CIDR(), gc.Equals, "10.0.0.0/24")
+	c.Assert(subnets[1].CIDR(), gc.Equals, "10.0.1.0/24")
+}
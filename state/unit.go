@@ -6,6 +6,7 @@ package state
 import (
 	stderrors "errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/juju/charm"
@@ -74,6 +75,7 @@ type unitDoc struct {
 	Resolved     ResolvedMode
 	Tools        *tools.Tools `bson:",omitempty"`
 	Ports        []network.Port
+	PortRanges   []network.PortRange `bson:",omitempty"`
 	Life         Life
 	TxnRevno     int64 `bson:"txn-revno"`
 	PasswordHash string
@@ -156,6 +158,16 @@ func unitGlobalKey(name string) string {
 	return "u#" + name
 }
 
+// unitNameFromGlobalKey returns the name of the unit identified by the
+// given global key, or the empty string if the key does not identify
+// a unit.
+func unitNameFromGlobalKey(key string) string {
+	if !strings.HasPrefix(key, "u#") {
+		return ""
+	}
+	return key[len("u#"):]
+}
+
 // globalKey returns the global database key for the unit.
 func (u *Unit) globalKey() string {
 	return unitGlobalKey(u.doc.Name)
@@ -626,59 +638,255 @@ func (u *Unit) SetStatus(status params.Status, info string, data params.StatusDa
 	return nil
 }
 
-// OpenPort sets the policy of the port with protocol and number to be opened.
-func (u *Unit) OpenPort(protocol string, number int) (err error) {
-	port := network.Port{Protocol: protocol, Number: number}
-	defer errors.Maskf(&err, "cannot open port %v for unit %q", port, u)
+// AgentActivity returns what the unit's agent is doing and, if it is
+// running a hook, the name of the hook. The activity is empty if the
+// agent has never reported it.
+func (u *Unit) AgentActivity() (activity params.AgentActivity, hook string, err error) {
+	doc, err := getStatus(u.st, u.globalKey())
+	if err != nil {
+		return "", "", err
+	}
+	return doc.AgentActivity, doc.ActivityHook, nil
+}
+
+// SetAgentActivity records what the unit's agent is doing. The hook
+// must be given when, and only when, the agent is executing a hook.
+func (u *Unit) SetAgentActivity(activity params.AgentActivity, hook string) error {
+	switch activity {
+	case params.ActivityIdle:
+		if hook != "" {
+			return fmt.Errorf("cannot set hook when agent activity is %q", activity)
+		}
+	case params.ActivityExecuting:
+		if hook == "" {
+			return fmt.Errorf("cannot set agent activity %q without hook", activity)
+		}
+	default:
+		return fmt.Errorf("cannot set invalid agent activity %q", activity)
+	}
 	ops := []txn.Op{{
 		C:      u.st.units.Name,
 		Id:     u.doc.Name,
 		Assert: notDeadDoc,
-		Update: bson.D{{"$addToSet", bson.D{{"ports", port}}}},
-	}}
-	err = u.st.runTransaction(ops)
+	},
+		updateAgentActivityOp(u.st, u.globalKey(), activity, hook),
+	}
+	err := u.st.runTransaction(ops)
 	if err != nil {
-		return onAbort(err, errDead)
+		return fmt.Errorf("cannot set agent activity of unit %q: %v", u, onAbort(err, errDead))
 	}
-	found := false
-	for _, p := range u.doc.Ports {
-		if p == port {
-			break
-		}
+	return nil
+}
+
+// WorkloadStatus returns the status of the unit's workload, as
+// last set by its charm.
+func (u *Unit) WorkloadStatus() (status params.WorkloadStatus, info string, err error) {
+	doc, err := getWorkloadStatus(u.st, u.globalKey())
+	if err != nil {
+		return "", "", err
 	}
-	if !found {
-		u.doc.Ports = append(u.doc.Ports, port)
+	return doc.Status, doc.StatusInfo, nil
+}
+
+// SetWorkloadStatus sets the status of the unit's workload. It does
+// not affect the status of the unit's agent.
+func (u *Unit) SetWorkloadStatus(status params.WorkloadStatus, info string) error {
+	doc := workloadStatusDoc{
+		Status:     status,
+		StatusInfo: info,
 	}
-	return nil
+	if err := doc.validateSet(); err != nil {
+		return err
+	}
+	// Units created before workload status was introduced
+	// have no workload status document, so we may need
+	// to create it.
+	for i := 0; i < 2; i++ {
+		statusOp := updateWorkloadStatusOp(u.st, u.globalKey(), doc)
+		if count, err := u.st.workloadStatuses.FindId(u.globalKey()).Count(); err != nil {
+			return fmt.Errorf("cannot set workload status of unit %q: %v", u, err)
+		} else if count == 0 {
+			statusOp = createWorkloadStatusOp(u.st, u.globalKey(), doc)
+		}
+		ops := []txn.Op{{
+			C:      u.st.units.Name,
+			Id:     u.doc.Name,
+			Assert: notDeadDoc,
+		},
+			statusOp,
+		}
+		err := u.st.runTransaction(ops)
+		if err == nil {
+			return nil
+		} else if err != txn.ErrAborted {
+			return fmt.Errorf("cannot set workload status of unit %q: %v", u, err)
+		}
+		if notDead, err := isNotDead(u.st.units, u.doc.Name); err != nil {
+			return err
+		} else if !notDead {
+			return fmt.Errorf("cannot set workload status of unit %q: %v", u, errDead)
+		}
+	}
+	return fmt.Errorf("cannot set workload status of unit %q: %v", u, ErrExcessiveContention)
+}
+
+// OpenPort sets the policy of the port with protocol and number to be opened.
+func (u *Unit) OpenPort(protocol string, number int) error {
+	return u.OpenPorts(protocol, number, number)
 }
 
 // ClosePort sets the policy of the port with protocol and number to be closed.
-func (u *Unit) ClosePort(protocol string, number int) (err error) {
-	port := network.Port{Protocol: protocol, Number: number}
-	defer errors.Maskf(&err, "cannot close port %v for unit %q", port, u)
-	ops := []txn.Op{{
-		C:      u.st.units.Name,
-		Id:     u.doc.Name,
-		Assert: notDeadDoc,
-		Update: bson.D{{"$pull", bson.D{{"ports", port}}}},
-	}}
-	err = u.st.runTransaction(ops)
-	if err != nil {
-		return onAbort(err, errDead)
+func (u *Unit) ClosePort(protocol string, number int) error {
+	return u.ClosePorts(protocol, number, number)
+}
+
+// OpenPorts sets the policy of the range of ports from fromPort to
+// toPort with the given protocol to be opened. It is an error to
+// open a range that overlaps, without being identical to, a range
+// already opened by the unit.
+func (u *Unit) OpenPorts(protocol string, fromPort, toPort int) (err error) {
+	ports := network.PortRange{Protocol: protocol, FromPort: fromPort, ToPort: toPort}
+	defer errors.Maskf(&err, "cannot open ports %v for unit %q", ports, u)
+	if err := ports.Validate(); err != nil {
+		return err
 	}
-	newPorts := make([]network.Port, 0, len(u.doc.Ports))
-	for _, p := range u.doc.Ports {
-		if p != port {
+	for i := 0; i < 5; i++ {
+		if i > 0 {
+			if err := u.Refresh(); err != nil {
+				return err
+			}
+		}
+		for _, p := range u.OpenedPortRanges() {
+			if p == ports {
+				return nil
+			}
+			if p.ConflictsWith(ports) {
+				return fmt.Errorf("ports conflict with already opened ports %v", p)
+			}
+		}
+		ops := []txn.Op{{
+			C:      u.st.units.Name,
+			Id:     u.doc.Name,
+			Assert: append(bson.D{{"txn-revno", u.doc.TxnRevno}}, notDeadDoc...),
+			Update: bson.D{{"$addToSet", bson.D{{"portranges", ports}}}},
+		}}
+		err := u.st.runTransaction(ops)
+		if err == nil {
+			u.doc.PortRanges = append(u.doc.PortRanges, ports)
+			return nil
+		} else if err != txn.ErrAborted {
+			return err
+		}
+		if notDead, err := isNotDead(u.st.units, u.doc.Name); err != nil {
+			return err
+		} else if !notDead {
+			return errDead
+		}
+	}
+	return ErrExcessiveContention
+}
+
+// ClosePorts sets the policy of the range of ports from fromPort to
+// toPort with the given protocol to be closed. It is an error to
+// close a range that overlaps, without being identical to, a range
+// opened by the unit.
+func (u *Unit) ClosePorts(protocol string, fromPort, toPort int) (err error) {
+	ports := network.PortRange{Protocol: protocol, FromPort: fromPort, ToPort: toPort}
+	defer errors.Maskf(&err, "cannot close ports %v for unit %q", ports, u)
+	if err := ports.Validate(); err != nil {
+		return err
+	}
+	for i := 0; i < 5; i++ {
+		if i > 0 {
+			if err := u.Refresh(); err != nil {
+				return err
+			}
+		}
+		for _, p := range u.OpenedPortRanges() {
+			if p != ports && p.ConflictsWith(ports) {
+				return fmt.Errorf("ports conflict with opened ports %v", p)
+			}
+		}
+		// Ports opened before port ranges were introduced are
+		// held separately, so remove the port from both.
+		update := bson.D{{"$pull", bson.D{{"portranges", ports}}}}
+		if fromPort == toPort {
+			port := network.Port{Protocol: protocol, Number: fromPort}
+			update = bson.D{{"$pull", bson.D{
+				{"portranges", ports},
+				{"ports", port},
+			}}}
+		}
+		ops := []txn.Op{{
+			C:      u.st.units.Name,
+			Id:     u.doc.Name,
+			Assert: append(bson.D{{"txn-revno", u.doc.TxnRevno}}, notDeadDoc...),
+			Update: update,
+		}}
+		err := u.st.runTransaction(ops)
+		if err == nil {
+			u.doc.PortRanges = removePortRange(u.doc.PortRanges, ports)
+			newPorts := make([]network.Port, 0, len(u.doc.Ports))
+			for _, p := range u.doc.Ports {
+				if network.NewPortRange(p) != ports {
+					newPorts = append(newPorts, p)
+				}
+			}
+			u.doc.Ports = newPorts
+			return nil
+		} else if err != txn.ErrAborted {
+			return err
+		}
+		if notDead, err := isNotDead(u.st.units, u.doc.Name); err != nil {
+			return err
+		} else if !notDead {
+			return errDead
+		}
+	}
+	return ErrExcessiveContention
+}
+
+func removePortRange(ports []network.PortRange, remove network.PortRange) []network.PortRange {
+	newPorts := make([]network.PortRange, 0, len(ports))
+	for _, p := range ports {
+		if p != remove {
 			newPorts = append(newPorts, p)
 		}
 	}
-	u.doc.Ports = newPorts
-	return nil
+	return newPorts
+}
+
+// OpenedPortRanges returns a slice containing the port ranges
+// opened by the unit, sorted by network.SortPortRanges.
+func (u *Unit) OpenedPortRanges() []network.PortRange {
+	return openedPortRanges(&u.doc)
 }
 
-// OpenedPorts returns a slice containing the open ports of the unit.
+// OpenedPorts returns a slice containing the open ports of the unit,
+// with any opened port ranges expanded into their individual ports.
 func (u *Unit) OpenedPorts() []network.Port {
-	ports := append([]network.Port{}, u.doc.Ports...)
+	return openedPorts(&u.doc)
+}
+
+// openedPortRanges returns the port ranges opened by the unit with
+// the given document, including any ports opened before port ranges
+// were introduced.
+func openedPortRanges(doc *unitDoc) []network.PortRange {
+	ports := append([]network.PortRange{}, doc.PortRanges...)
+	for _, p := range doc.Ports {
+		ports = append(ports, network.NewPortRange(p))
+	}
+	network.SortPortRanges(ports)
+	return ports
+}
+
+// openedPorts returns the individual ports opened by the
+// unit with the given document.
+func openedPorts(doc *unitDoc) []network.Port {
+	ports := []network.Port{}
+	for _, p := range openedPortRanges(doc) {
+		ports = append(ports, p.Ports()...)
+	}
 	network.SortPorts(ports)
 	return ports
 }
@@ -754,7 +962,7 @@ func (u *Unit) SetCharmURL(curl *charm.URL) (err error) {
 
 // AgentAlive returns whether the respective remote agent is alive.
 func (u *Unit) AgentAlive() (bool, error) {
-	return u.st.pwatcher.Alive(u.globalKey())
+	return u.st.pwatcher.Alive(u.st.docID(u.globalKey()))
 }
 
 // Tag returns a name identifying the unit that is safe to use
@@ -768,8 +976,8 @@ func (u *Unit) Tag() string {
 func (u *Unit) WaitAgentAlive(timeout time.Duration) (err error) {
 	defer errors.Maskf(&err, "waiting for agent of unit %q", u)
 	ch := make(chan presence.Change)
-	u.st.pwatcher.Watch(u.globalKey(), ch)
-	defer u.st.pwatcher.Unwatch(u.globalKey(), ch)
+	u.st.pwatcher.Watch(u.st.docID(u.globalKey()), ch)
+	defer u.st.pwatcher.Unwatch(u.st.docID(u.globalKey()), ch)
 	for i := 0; i < 2; i++ {
 		select {
 		case change := <-ch:
@@ -788,7 +996,7 @@ func (u *Unit) WaitAgentAlive(timeout time.Duration) (err error) {
 // SetAgentAlive signals that the agent for unit u is alive.
 // It returns the started pinger.
 func (u *Unit) SetAgentAlive() (*presence.Pinger, error) {
-	p := presence.NewPinger(u.st.presence, u.globalKey())
+	p := presence.NewPinger(u.st.presence, u.st.docID(u.globalKey()))
 	err := p.Start()
 	if err != nil {
 		return nil, err
@@ -895,6 +1103,11 @@ func (u *Unit) assignToMachine(m *Machine, unused bool) (err error) {
 		Assert: massert,
 		Update: bson.D{{"$addToSet", bson.D{{"principals", u.doc.Name}}}, {"$set", bson.D{{"clean", false}}}},
 	}}
+	attachmentOps, err := u.volumeAttachmentOps(m.doc.Id)
+	if err != nil {
+		return err
+	}
+	ops = append(ops, attachmentOps...)
 	err = u.st.runTransaction(ops)
 	if err == nil {
 		u.doc.MachineId = m.doc.Id
@@ -985,6 +1198,11 @@ func (u *Unit) assignToNewMachine(template MachineTemplate, parentId string, con
 		Assert: asserts,
 		Update: bson.D{{"$set", bson.D{{"machineid", mdoc.Id}}}},
 	})
+	attachmentOps, err := u.volumeAttachmentOps(mdoc.Id)
+	if err != nil {
+		return err
+	}
+	ops = append(ops, attachmentOps...)
 
 	err = u.st.runTransaction(ops)
 	if err == nil {
@@ -1175,7 +1393,7 @@ var hasNoContainersTerm = bson.DocElem{
 
 // findCleanMachineQuery returns a Mongo query to find clean (and possibly empty) machines with
 // characteristics matching the specified constraints.
-func (u *Unit) findCleanMachineQuery(requireEmpty bool, cons *constraints.Value) (*mgo.Query, error) {
+func (u *Unit) findCleanMachineQuery(requireEmpty bool, cons *constraints.Value) (*stateQuery, error) {
 	// Select all machines that can accept principal units and are clean.
 	var containerRefs []machineContainers
 	// If we need empty machines, first build up a list of machine ids which have containers
@@ -1302,6 +1520,25 @@ func (u *Unit) assignToCleanMaybeEmptyMachine(requireEmpty bool) (m *Machine, er
 		}
 	}
 
+	// Whether a machine has a network interface in a space can only
+	// be told from its addresses, so a spaces constraint rules out
+	// the machines that have yet to be provisioned.
+	if cons.HaveSpaces() {
+		unprovisioned = nil
+		var matching []instance.Id
+		for _, instance := range instances {
+			ok, err := u.st.machineMatchesSpaces(instanceMachines[instance], cons)
+			if err != nil {
+				assignContextf(&err, u, context)
+				return nil, err
+			}
+			if ok {
+				matching = append(matching, instance)
+			}
+		}
+		instances = matching
+	}
+
 	// Filter the list of instances that are suitable for
 	// distribution, and then map them back to machines.
 	//
@@ -1363,6 +1600,17 @@ func (u *Unit) UnassignFromMachine() (err error) {
 			Assert: txn.DocExists,
 			Update: bson.D{{"$pull", bson.D{{"principals", u.doc.Name}}}},
 		})
+		instances, err := u.StorageInstances()
+		if err != nil {
+			return err
+		}
+		for _, si := range instances {
+			ops = append(ops, txn.Op{
+				C:      u.st.volumeAttachments.Name,
+				Id:     volumeAttachmentId(u.doc.MachineId, si.VolumeName()),
+				Remove: true,
+			})
+		}
 	}
 	err = u.st.runTransaction(ops)
 	if err != nil {
@@ -352,6 +352,108 @@ func (s *UnitSuite) TestGetSetStatusWhileNotAlive(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "status not found")
 }
 
+func (s *UnitSuite) TestGetSetWorkloadStatus(c *gc.C) {
+	status, info, err := s.unit.WorkloadStatus()
+	c.Assert(err, gc.IsNil)
+	c.Assert(status, gc.Equals, params.WorkloadUnknown)
+	c.Assert(info, gc.Equals, "")
+
+	err = s.unit.SetWorkloadStatus(params.WorkloadUnknown, "")
+	c.Assert(err, gc.ErrorMatches, `cannot set workload status "unknown"`)
+	err = s.unit.SetWorkloadStatus(params.WorkloadStatus("vliegkat"), "orville")
+	c.Assert(err, gc.ErrorMatches, `cannot set invalid workload status "vliegkat"`)
+
+	err = s.unit.SetWorkloadStatus(params.WorkloadWaiting, "waiting for database")
+	c.Assert(err, gc.IsNil)
+	status, info, err = s.unit.WorkloadStatus()
+	c.Assert(err, gc.IsNil)
+	c.Assert(status, gc.Equals, params.WorkloadWaiting)
+	c.Assert(info, gc.Equals, "waiting for database")
+
+	// The agent status is independent of the workload status.
+	err = s.unit.SetStatus(params.StatusStarted, "", nil)
+	c.Assert(err, gc.IsNil)
+	status, info, err = s.unit.WorkloadStatus()
+	c.Assert(err, gc.IsNil)
+	c.Assert(status, gc.Equals, params.WorkloadWaiting)
+	c.Assert(info, gc.Equals, "waiting for database")
+	agentStatus, _, _, err := s.unit.Status()
+	c.Assert(err, gc.IsNil)
+	c.Assert(agentStatus, gc.Equals, params.StatusStarted)
+}
+
+func (s *UnitSuite) TestSetWorkloadStatusWithoutDocument(c *gc.C) {
+	// Units created by earlier versions have no
+	// workload status document.
+	err := state.RemoveWorkloadStatus(s.State, s.unit)
+	c.Assert(err, gc.IsNil)
+	status, _, err := s.unit.WorkloadStatus()
+	c.Assert(err, gc.IsNil)
+	c.Assert(status, gc.Equals, params.WorkloadUnknown)
+
+	err = s.unit.SetWorkloadStatus(params.WorkloadActive, "ready")
+	c.Assert(err, gc.IsNil)
+	status, info, err := s.unit.WorkloadStatus()
+	c.Assert(err, gc.IsNil)
+	c.Assert(status, gc.Equals, params.WorkloadActive)
+	c.Assert(info, gc.Equals, "ready")
+}
+
+func (s *UnitSuite) TestSetWorkloadStatusWhenDead(c *gc.C) {
+	err := s.unit.EnsureDead()
+	c.Assert(err, gc.IsNil)
+	err = s.unit.SetWorkloadStatus(params.WorkloadActive, "ready")
+	c.Assert(err, gc.ErrorMatches, `cannot set workload status of unit "wordpress/0": not found or dead`)
+}
+
+func (s *UnitSuite) TestGetSetAgentActivity(c *gc.C) {
+	activity, hook, err := s.unit.AgentActivity()
+	c.Assert(err, gc.IsNil)
+	c.Assert(activity, gc.Equals, params.AgentActivity(""))
+	c.Assert(hook, gc.Equals, "")
+
+	err = s.unit.SetAgentActivity(params.ActivityExecuting, "config-changed")
+	c.Assert(err, gc.IsNil)
+	activity, hook, err = s.unit.AgentActivity()
+	c.Assert(err, gc.IsNil)
+	c.Assert(activity, gc.Equals, params.ActivityExecuting)
+	c.Assert(hook, gc.Equals, "config-changed")
+
+	// Setting the status leaves the activity alone.
+	err = s.unit.SetStatus(params.StatusStarted, "", nil)
+	c.Assert(err, gc.IsNil)
+	activity, hook, err = s.unit.AgentActivity()
+	c.Assert(err, gc.IsNil)
+	c.Assert(activity, gc.Equals, params.ActivityExecuting)
+	c.Assert(hook, gc.Equals, "config-changed")
+	status, _, _, err := s.unit.Status()
+	c.Assert(err, gc.IsNil)
+	c.Assert(status, gc.Equals, params.StatusStarted)
+
+	err = s.unit.SetAgentActivity(params.ActivityIdle, "")
+	c.Assert(err, gc.IsNil)
+	activity, hook, err = s.unit.AgentActivity()
+	c.Assert(err, gc.IsNil)
+	c.Assert(activity, gc.Equals, params.ActivityIdle)
+	c.Assert(hook, gc.Equals, "")
+}
+
+func (s *UnitSuite) TestSetAgentActivityInvalid(c *gc.C) {
+	err := s.unit.SetAgentActivity("sleeping", "")
+	c.Assert(err, gc.ErrorMatches, `cannot set invalid agent activity "sleeping"`)
+	err = s.unit.SetAgentActivity(params.ActivityExecuting, "")
+	c.Assert(err, gc.ErrorMatches, `cannot set agent activity "executing" without hook`)
+	err = s.unit.SetAgentActivity(params.ActivityIdle, "install")
+	c.Assert(err, gc.ErrorMatches, `cannot set hook when agent activity is "idle"`)
+}
+
+func (s *UnitSuite) TestSetAgentActivityWhenDead(c *gc.C) {
+	err := s.unit.EnsureDead()
+	c.Assert(err, gc.IsNil)
+	err = s.unit.SetAgentActivity(params.ActivityIdle, "")
+	c.Assert(err, gc.ErrorMatches, `cannot set agent activity of unit "wordpress/0": not found or dead`)
+}
+
 func (s *UnitSuite) TestGetSetStatusDataStandard(c *gc.C) {
 	err := s.unit.SetStatus(params.StatusStarted, "", nil)
 	c.Assert(err, gc.IsNil)
@@ -879,6 +981,63 @@ func (s *UnitSuite) TestOpenedPorts(c *gc.C) {
 	})
 }
 
+func (s *UnitSuite) TestOpenedPortRanges(c *gc.C) {
+	c.Assert(s.unit.OpenedPortRanges(), gc.HasLen, 0)
+
+	err := s.unit.OpenPorts("tcp", 8000, 8010)
+	c.Assert(err, gc.IsNil)
+	err = s.unit.OpenPort("udp", 53)
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.unit.OpenedPortRanges(), gc.DeepEquals, []network.PortRange{
+		{Protocol: "tcp", FromPort: 8000, ToPort: 8010},
+		{Protocol: "udp", FromPort: 53, ToPort: 53},
+	})
+	c.Assert(s.unit.OpenedPorts(), gc.HasLen, 12)
+
+	// Opening the same range again is a no-op.
+	err = s.unit.OpenPorts("tcp", 8000, 8010)
+	c.Assert(err, gc.IsNil)
+
+	// The changes are visible after refreshing.
+	err = s.unit.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.unit.OpenedPortRanges(), gc.HasLen, 2)
+
+	err = s.unit.ClosePorts("tcp", 8000, 8010)
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.unit.OpenedPortRanges(), gc.DeepEquals, []network.PortRange{
+		{Protocol: "udp", FromPort: 53, ToPort: 53},
+	})
+	err = s.unit.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.unit.OpenedPortRanges(), gc.DeepEquals, []network.PortRange{
+		{Protocol: "udp", FromPort: 53, ToPort: 53},
+	})
+}
+
+func (s *UnitSuite) TestOpenClosePortRangeConflicts(c *gc.C) {
+	err := s.unit.OpenPorts("tcp", 8000, 8010)
+	c.Assert(err, gc.IsNil)
+
+	err = s.unit.OpenPort("tcp", 8005)
+	c.Assert(err, gc.ErrorMatches, `cannot open ports 8005/tcp for unit "wordpress/0": ports conflict with already opened ports 8000-8010/tcp`)
+	err = s.unit.OpenPorts("tcp", 7000, 8000)
+	c.Assert(err, gc.ErrorMatches, `cannot open ports 7000-8000/tcp for unit "wordpress/0": ports conflict with already opened ports 8000-8010/tcp`)
+	err = s.unit.ClosePort("tcp", 8005)
+	c.Assert(err, gc.ErrorMatches, `cannot close ports 8005/tcp for unit "wordpress/0": ports conflict with opened ports 8000-8010/tcp`)
+
+	// Ranges for other protocols do not conflict.
+	err = s.unit.OpenPorts("udp", 8000, 8010)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *UnitSuite) TestOpenPortsInvalid(c *gc.C) {
+	err := s.unit.OpenPorts("tcp", 9000, 8000)
+	c.Assert(err, gc.ErrorMatches, `cannot open ports 9000-8000/tcp for unit "wordpress/0": invalid port range 9000-8000/tcp: start port is greater than end port`)
+	err = s.unit.OpenPorts("tcp", 0, 80)
+	c.Assert(err, gc.ErrorMatches, `cannot open ports 0-80/tcp for unit "wordpress/0": invalid port range 0-80/tcp: ports must be between 1 and 65535`)
+}
+
 func (s *UnitSuite) TestOpenClosePortWhenDying(c *gc.C) {
 	preventUnitDestroyRemove(c, s.unit)
 	testWhenDying(c, s.unit, noErr, deadErr, func() error {
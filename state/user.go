@@ -25,7 +25,10 @@ func (st *State) AddAdminUser(password string) (*User, error) {
 	return st.AddUser("admin", "", password, "")
 }
 
-// AddUser adds a user to the state.
+// AddUser adds a user to the state. Users are shared by all the
+// environments hosted by the state server, but the new user is only
+// given read access to the State's own environment; access to other
+// environments must be granted with SetUserAccess.
 func (st *State) AddUser(username, displayName, password, creator string) (*User, error) {
 	if !names.IsUser(username) {
 		return nil, errors.Errorf("invalid user name %q", username)
@@ -53,6 +56,14 @@ func (st *State) AddUser(username, displayName, password, creator string) (*User
 		Assert: txn.DocMissing,
 		Insert: &u.doc,
 	}}
+	if username != AdminUser {
+		ops = append(ops, txn.Op{
+			C:      st.envUsers.Name,
+			Id:     username,
+			Assert: txn.DocMissing,
+			Insert: &envUserDoc{Name: username, Access: ReadAccess},
+		})
+	}
 	err = st.runTransaction(ops)
 	if err == txn.ErrAborted {
 		err = errors.New("user already exists")
@@ -238,3 +249,190 @@ func (u *User) Deactivate() error {
 func (u *User) IsDeactivated() bool {
 	return u.doc.Deactivated
 }
+
+// Access describes the access a user has to an environment.
+type Access string
+
+const (
+	// ReadAccess allows a user to inspect the environment,
+	// but not to change it.
+	ReadAccess Access = "read"
+
+	// WriteAccess additionally allows a user to change the
+	// services and machines in the environment.
+	WriteAccess Access = "write"
+
+	// AdminAccess additionally allows a user to manage other
+	// users and to destroy the environment.
+	AdminAccess Access = "admin"
+)
+
+// accessLevels holds all the access levels, in increasing order.
+var accessLevels = []Access{ReadAccess, WriteAccess, AdminAccess}
+
+func (a Access) level() int {
+	for i, access := range accessLevels {
+		if a == access {
+			return i
+		}
+	}
+	return -1
+}
+
+// Validate returns an error if the access level is not known.
+func (a Access) Validate() error {
+	if a.level() < 0 {
+		return errors.NotValidf("access level %q", string(a))
+	}
+	return nil
+}
+
+// Allows reports whether a user with this access level
+// also has the given access.
+func (a Access) Allows(access Access) bool {
+	return access.level() >= 0 && a.level() >= access.level()
+}
+
+// envUserDoc records the access level of a user to the
+// environment. Users are shared by all the environments hosted
+// by a state server, but each environment records its own
+// access levels.
+type envUserDoc struct {
+	Name    string `bson:"_id"`
+	EnvUUID string `bson:"env-uuid"`
+	Access  Access `bson:"access"`
+}
+
+// UserAccess returns the access level of the named user to the
+// environment. The admin user and the owner of a hosted environment
+// always have admin access; other users have none, and a NotFound
+// error is returned, unless access has been granted to them.
+func (st *State) UserAccess(name string) (Access, error) {
+	isAdmin, err := st.isEnvironAdmin(name)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if isAdmin {
+		return AdminAccess, nil
+	}
+	var doc envUserDoc
+	err = st.envUsers.FindId(name).One(&doc)
+	if err == mgo.ErrNotFound {
+		if exists, err := st.checkUserExists(name); err != nil {
+			return "", errors.Trace(err)
+		} else if !exists {
+			return "", errors.NotFoundf("user %q", name)
+		}
+		return "", errors.NotFoundf("access of user %q to the environment", name)
+	} else if err != nil {
+		return "", errors.Annotatef(err, "cannot get access of user %q", name)
+	}
+	return doc.Access, nil
+}
+
+// isEnvironAdmin reports whether the named user always
+// has admin access to the environment.
+func (st *State) isEnvironAdmin(name string) (bool, error) {
+	if name == AdminUser {
+		return true, nil
+	}
+	if !st.IsHosted() {
+		return false, nil
+	}
+	env, err := st.HostedEnvironment(st.envUUID)
+	if err != nil {
+		return false, err
+	}
+	return env.Owner() == name, nil
+}
+
+// SetUserAccess sets the access level of the named user to
+// the environment. The change applies to API connections made
+// after it.
+func (st *State) SetUserAccess(name string, access Access) error {
+	if err := access.Validate(); err != nil {
+		return errors.Annotatef(err, "cannot set access of user %q", name)
+	}
+	isAdmin, err := st.isEnvironAdmin(name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if isAdmin && access != AdminAccess {
+		return errors.Unauthorizedf("cannot change access of environment administrator %q", name)
+	}
+	if exists, err := st.checkUserExists(name); err != nil {
+		return errors.Trace(err)
+	} else if !exists {
+		return errors.NotFoundf("user %q", name)
+	}
+	for i := 0; i < 3; i++ {
+		op := txn.Op{
+			C:  st.envUsers.Name,
+			Id: name,
+		}
+		var doc envUserDoc
+		err := st.envUsers.FindId(name).One(&doc)
+		if err == mgo.ErrNotFound {
+			op.Assert = txn.DocMissing
+			op.Insert = &envUserDoc{Name: name, Access: access}
+		} else if err != nil {
+			return fmt.Errorf("cannot set access of user %q: %v", name, err)
+		} else {
+			op.Assert = txn.DocExists
+			op.Update = bson.D{{"$set", bson.D{{"access", access}}}}
+		}
+		err = st.runTransaction([]txn.Op{op})
+		if err == txn.ErrAborted {
+			// The access was set or removed concurrently; try again.
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("cannot set access of user %q: %v", name, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("cannot set access of user %q: %v", name, ErrExcessiveContention)
+}
+
+// RevokeUserAccess takes the given access to the environment away
+// from the named user, leaving the user with the next lower access
+// level. Revoking read access leaves the user with no access to the
+// environment at all.
+func (st *State) RevokeUserAccess(name string, access Access) error {
+	if err := access.Validate(); err != nil {
+		return errors.Annotatef(err, "cannot revoke access of user %q", name)
+	}
+	current, err := st.UserAccess(name)
+	if errors.IsNotFound(err) {
+		if exists, err := st.checkUserExists(name); err != nil {
+			return errors.Trace(err)
+		} else if !exists {
+			return errors.NotFoundf("user %q", name)
+		}
+		// The user has no access to revoke.
+		return nil
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	if !current.Allows(access) {
+		return nil
+	}
+	if access != ReadAccess {
+		return st.SetUserAccess(name, accessLevels[access.level()-1])
+	}
+	if isAdmin, err := st.isEnvironAdmin(name); err != nil {
+		return errors.Trace(err)
+	} else if isAdmin {
+		return errors.Unauthorizedf("cannot change access of environment administrator %q", name)
+	}
+	ops := []txn.Op{{
+		C:      st.envUsers.Name,
+		Id:     name,
+		Assert: txn.DocExists,
+		Remove: true,
+	}}
+	if err := st.runTransaction(ops); err != nil && err != txn.ErrAborted {
+		return fmt.Errorf("cannot revoke access of user %q: %v", name, err)
+	}
+	return nil
+}
@@ -7,11 +7,13 @@ import (
 	"regexp"
 	"time"
 
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/utils"
 	gc "launchpad.net/gocheck"
 
 	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing"
 	"github.com/juju/juju/testing/factory"
 )
 
@@ -190,3 +192,110 @@ func (s *UserSuite) TestCantDeactivateAdminUser(c *gc.C) {
 	err = user.Deactivate()
 	c.Assert(err, gc.ErrorMatches, "Can't deactivate admin user")
 }
+
+func (s *UserSuite) assertAccess(c *gc.C, st *state.State, name string, expect state.Access) {
+	access, err := st.UserAccess(name)
+	c.Assert(err, gc.IsNil)
+	c.Assert(access, gc.Equals, expect)
+}
+
+func (s *UserSuite) TestNewUserHasReadAccess(c *gc.C) {
+	user := s.factory.MakeAnyUser()
+	s.assertAccess(c, s.State, user.Name(), state.ReadAccess)
+	s.assertAccess(c, s.State, state.AdminUser, state.AdminAccess)
+}
+
+func (s *UserSuite) TestAccessUnknownUser(c *gc.C) {
+	_, err := s.State.UserAccess("nobody")
+	c.Assert(err, gc.ErrorMatches, `user "nobody" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	err = s.State.SetUserAccess("nobody", state.WriteAccess)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *UserSuite) TestSetUserAccess(c *gc.C) {
+	user := s.factory.MakeAnyUser()
+	err := s.State.SetUserAccess(user.Name(), state.WriteAccess)
+	c.Assert(err, gc.IsNil)
+	s.assertAccess(c, s.State, user.Name(), state.WriteAccess)
+
+	err = s.State.SetUserAccess(user.Name(), state.AdminAccess)
+	c.Assert(err, gc.IsNil)
+	s.assertAccess(c, s.State, user.Name(), state.AdminAccess)
+
+	err = s.State.SetUserAccess(user.Name(), "superuser")
+	c.Assert(err, gc.ErrorMatches, `cannot set access of user ".*": access level "superuser" not valid`)
+}
+
+func (s *UserSuite) TestCantSetAccessOfAdminUser(c *gc.C) {
+	err := s.State.SetUserAccess(state.AdminUser, state.WriteAccess)
+	c.Assert(err, gc.ErrorMatches, `cannot change access of environment administrator "admin"`)
+	s.assertAccess(c, s.State, state.AdminUser, state.AdminAccess)
+}
+
+func (s *UserSuite) TestAccessIsPerEnvironment(c *gc.C) {
+	user := s.factory.MakeAnyUser()
+	owner := s.factory.MakeAnyUser()
+	cfg := testing.CustomEnvironConfig(c, testing.Attrs{"name": "sandbox"})
+	_, st, err := s.State.NewEnvironment(cfg, owner.Name())
+	c.Assert(err, gc.IsNil)
+	defer st.Close()
+
+	// Users only have access to the environments
+	// they have been granted access to.
+	_, err = st.UserAccess(user.Name())
+	c.Assert(err, gc.ErrorMatches, `access of user ".*" to the environment not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	err = st.SetUserAccess(user.Name(), state.WriteAccess)
+	c.Assert(err, gc.IsNil)
+	s.assertAccess(c, st, user.Name(), state.WriteAccess)
+	s.assertAccess(c, s.State, user.Name(), state.ReadAccess)
+
+	// The owner of a hosted environment administers it,
+	// but no other environment.
+	s.assertAccess(c, st, owner.Name(), state.AdminAccess)
+	s.assertAccess(c, s.State, owner.Name(), state.ReadAccess)
+	err = st.SetUserAccess(owner.Name(), state.ReadAccess)
+	c.Assert(err, gc.ErrorMatches, `cannot change access of environment administrator ".*"`)
+	err = st.RevokeUserAccess(owner.Name(), state.ReadAccess)
+	c.Assert(err, gc.ErrorMatches, `cannot change access of environment administrator ".*"`)
+}
+
+func (s *UserSuite) TestRevokeUserAccess(c *gc.C) {
+	user := s.factory.MakeAnyUser()
+	err := s.State.SetUserAccess(user.Name(), state.AdminAccess)
+	c.Assert(err, gc.IsNil)
+
+	err = s.State.RevokeUserAccess(user.Name(), state.AdminAccess)
+	c.Assert(err, gc.IsNil)
+	s.assertAccess(c, s.State, user.Name(), state.WriteAccess)
+
+	// Revoking access the user does not have changes nothing.
+	err = s.State.RevokeUserAccess(user.Name(), state.AdminAccess)
+	c.Assert(err, gc.IsNil)
+	s.assertAccess(c, s.State, user.Name(), state.WriteAccess)
+
+	err = s.State.RevokeUserAccess(user.Name(), state.WriteAccess)
+	c.Assert(err, gc.IsNil)
+	s.assertAccess(c, s.State, user.Name(), state.ReadAccess)
+
+	// Revoking read access leaves the user with no access.
+	err = s.State.RevokeUserAccess(user.Name(), state.ReadAccess)
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.UserAccess(user.Name())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	err = s.State.RevokeUserAccess(user.Name(), state.ReadAccess)
+	c.Assert(err, gc.IsNil)
+
+	err = s.State.RevokeUserAccess("nobody", state.ReadAccess)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *UserSuite) TestAccessAllows(c *gc.C) {
+	c.Assert(state.AdminAccess.Allows(state.WriteAccess), jc.IsTrue)
+	c.Assert(state.WriteAccess.Allows(state.WriteAccess), jc.IsTrue)
+	c.Assert(state.WriteAccess.Allows(state.AdminAccess), jc.IsFalse)
+	c.Assert(state.ReadAccess.Allows(state.WriteAccess), jc.IsFalse)
+	c.Assert(state.AdminAccess.Allows("bogus"), jc.IsFalse)
+}
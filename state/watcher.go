@@ -140,7 +140,7 @@ type lifecycleWatcher struct {
 	commonWatcher
 	out chan []string
 	// coll is the collection holding all interesting entities.
-	coll *mgo.Collection
+	coll *stateCollection
 	// members is used to select the initial set of interesting entities.
 	members bson.D
 	// filter is used to exclude events not affecting interesting entities.
@@ -179,6 +179,12 @@ func (s *Service) WatchRelations() StringsWatcher {
 	return newLifecycleWatcher(s.st, s.st.relations, members, filter)
 }
 
+// WatchVolumes returns a StringsWatcher that notifies of changes to
+// the lifecycles of the volumes in the environment.
+func (st *State) WatchVolumes() StringsWatcher {
+	return newLifecycleWatcher(st, st.volumes, nil, nil)
+}
+
 // WatchEnvironMachines returns a StringsWatcher that notifies of changes to
 // the lifecycles of the machines (but not containers) in the environment.
 func (st *State) WatchEnvironMachines() StringsWatcher {
@@ -215,7 +221,18 @@ func (m *Machine) containersWatcher(isChildRegexp string) StringsWatcher {
 	return newLifecycleWatcher(m.st, m.st.machines, members, filter)
 }
 
-func newLifecycleWatcher(st *State, coll *mgo.Collection, members bson.D, filter func(key interface{}) bool) StringsWatcher {
+// WatchVolumeAttachments returns a StringsWatcher that notifies of
+// changes to the lifecycles of the volume attachments of the machine.
+func (m *Machine) WatchVolumeAttachments() StringsWatcher {
+	members := bson.D{{"machineid", m.doc.Id}}
+	prefix := m.doc.Id + ":"
+	filter := func(id interface{}) bool {
+		return strings.HasPrefix(id.(string), prefix)
+	}
+	return newLifecycleWatcher(m.st, m.st.volumeAttachments, members, filter)
+}
+
+func newLifecycleWatcher(st *State, coll *stateCollection, members bson.D, filter func(key interface{}) bool) StringsWatcher {
 	w := &lifecycleWatcher{
 		commonWatcher: commonWatcher{st: st},
 		coll:          coll,
@@ -265,7 +282,7 @@ func (w *lifecycleWatcher) merge(ids *set.Strings, updates map[interface{}]bool)
 	changed := []string{}
 	latest := map[string]Life{}
 	for id, exists := range updates {
-		id := id.(string)
+		id := w.st.localID(id.(string))
 		if exists {
 			changed = append(changed, id)
 		} else {
@@ -323,7 +340,7 @@ func stateWatcherDeadError(err error) error {
 
 func (w *lifecycleWatcher) loop() error {
 	in := make(chan watcher.Change)
-	w.st.watcher.WatchCollectionWithFilter(w.coll.Name, in, w.filter)
+	w.st.watchCollection(w.coll.Name, in, w.filter)
 	defer w.st.watcher.UnwatchCollection(w.coll.Name, in)
 	ids, err := w.initial()
 	if err != nil {
@@ -397,7 +414,7 @@ func (w *minUnitsWatcher) initial() (*set.Strings, error) {
 }
 
 func (w *minUnitsWatcher) merge(serviceNames *set.Strings, change watcher.Change) error {
-	serviceName := change.Id.(string)
+	serviceName := w.st.localID(change.Id.(string))
 	if change.Revno == -1 {
 		delete(w.known, serviceName)
 		serviceNames.Remove(serviceName)
@@ -417,7 +434,7 @@ func (w *minUnitsWatcher) merge(serviceNames *set.Strings, change watcher.Change
 
 func (w *minUnitsWatcher) loop() (err error) {
 	ch := make(chan watcher.Change)
-	w.st.watcher.WatchCollection(w.st.minUnits.Name, ch)
+	w.st.watchCollection(w.st.minUnits.Name, ch, nil)
 	defer w.st.watcher.UnwatchCollection(w.st.minUnits.Name, ch)
 	serviceNames, err := w.initial()
 	if err != nil {
@@ -572,6 +589,7 @@ func (w *RelationScopeWatcher) mergeChanges(info *scopeInfo, ids map[interface{}
 			logger.Warningf("ignoring bad relation scope id: %#v", id_)
 			continue
 		}
+		id = w.st.localID(id)
 		if exists {
 			existIds = append(existIds, id)
 		} else {
@@ -600,7 +618,7 @@ func (w *RelationScopeWatcher) loop() error {
 	filter := func(key interface{}) bool {
 		return strings.HasPrefix(key.(string), w.prefix)
 	}
-	w.st.watcher.WatchCollectionWithFilter(w.st.relationScopes.Name, in, filter)
+	w.st.watchCollection(w.st.relationScopes.Name, in, filter)
 	defer w.st.watcher.UnwatchCollection(w.st.relationScopes.Name, in)
 	info, err := w.initialInfo()
 	if err != nil {
@@ -712,7 +730,7 @@ func (w *relationUnitsWatcher) mergeScope(changes *params.RelationUnitsChange, c
 			return err
 		}
 		changes.Departed = remove(changes.Departed, name)
-		w.st.watcher.Watch(w.st.settings.Name, key, revno, w.updates)
+		w.st.watchDoc(w.st.settings.Name, key, revno, w.updates)
 		w.watching.Add(key)
 	}
 	for _, name := range c.Left {
@@ -721,7 +739,7 @@ func (w *relationUnitsWatcher) mergeScope(changes *params.RelationUnitsChange, c
 		if changes.Changed != nil {
 			delete(changes.Changed, name)
 		}
-		w.st.watcher.Unwatch(w.st.settings.Name, key, w.updates)
+		w.st.unwatchDoc(w.st.settings.Name, key, w.updates)
 		w.watching.Remove(key)
 	}
 	return nil
@@ -741,7 +759,7 @@ func remove(strs []string, s string) []string {
 func (w *relationUnitsWatcher) finish() {
 	watcher.Stop(w.sw, &w.tomb)
 	for _, watchedValue := range w.watching.Values() {
-		w.st.watcher.Unwatch(w.st.settings.Name, watchedValue, w.updates)
+		w.st.unwatchDoc(w.st.settings.Name, watchedValue, w.updates)
 	}
 	close(w.updates)
 	close(w.out)
@@ -776,6 +794,7 @@ func (w *relationUnitsWatcher) loop() (err error) {
 			if !ok {
 				logger.Warningf("ignoring bad relation scope id: %#v", c.Id)
 			}
+			id = w.st.localID(id)
 			setRelationUnitChangeVersion(&changes, id, c.Revno)
 			out = w.out
 		case out <- changes:
@@ -805,7 +824,7 @@ var _ Watcher = (*unitsWatcher)(nil)
 // WatchSubordinateUnits returns a StringsWatcher tracking the unit's subordinate units.
 func (u *Unit) WatchSubordinateUnits() StringsWatcher {
 	u = &Unit{st: u.st, doc: u.doc}
-	coll := u.st.units.Name
+	coll := u.st.units
 	getUnits := func() ([]string, error) {
 		if err := u.Refresh(); err != nil {
 			return nil, err
@@ -819,7 +838,7 @@ func (u *Unit) WatchSubordinateUnits() StringsWatcher {
 // units.
 func (m *Machine) WatchPrincipalUnits() StringsWatcher {
 	m = &Machine{st: m.st, doc: m.doc}
-	coll := m.st.machines.Name
+	coll := m.st.machines
 	getUnits := func() ([]string, error) {
 		if err := m.Refresh(); err != nil {
 			return nil, err
@@ -829,7 +848,7 @@ func (m *Machine) WatchPrincipalUnits() StringsWatcher {
 	return newUnitsWatcher(m.st, m.Tag(), getUnits, coll, m.doc.Id)
 }
 
-func newUnitsWatcher(st *State, tag string, getUnits func() ([]string, error), coll, id string) StringsWatcher {
+func newUnitsWatcher(st *State, tag string, getUnits func() ([]string, error), coll *stateCollection, id string) StringsWatcher {
 	w := &unitsWatcher{
 		commonWatcher: commonWatcher{st: st},
 		tag:           tag,
@@ -883,7 +902,7 @@ func (w *unitsWatcher) initial() ([]string, error) {
 		changes = append(changes, doc.Id)
 		if doc.Life != Dead {
 			w.life[doc.Id] = doc.Life
-			w.st.watcher.Watch(w.st.units.Name, doc.Id, doc.TxnRevno, w.in)
+			w.st.watchDoc(w.st.units.Name, doc.Id, doc.TxnRevno, w.in)
 		}
 	}
 	return changes, nil
@@ -912,7 +931,7 @@ func (w *unitsWatcher) update(changes []string) ([]string, error) {
 			changes = append(changes, name)
 		}
 		delete(w.life, name)
-		w.st.watcher.Unwatch(w.st.units.Name, name, w.in)
+		w.st.unwatchDoc(w.st.units.Name, name, w.in)
 	}
 	return changes, nil
 }
@@ -934,9 +953,9 @@ func (w *unitsWatcher) merge(changes []string, name string) ([]string, error) {
 	switch {
 	case known && gone:
 		delete(w.life, name)
-		w.st.watcher.Unwatch(w.st.units.Name, name, w.in)
+		w.st.unwatchDoc(w.st.units.Name, name, w.in)
 	case !known && !gone:
-		w.st.watcher.Watch(w.st.units.Name, name, doc.TxnRevno, w.in)
+		w.st.watchDoc(w.st.units.Name, name, doc.TxnRevno, w.in)
 		w.life[name] = doc.Life
 	case known && life != doc.Life:
 		w.life[name] = doc.Life
@@ -949,16 +968,16 @@ func (w *unitsWatcher) merge(changes []string, name string) ([]string, error) {
 	return changes, nil
 }
 
-func (w *unitsWatcher) loop(coll, id string) error {
-	revno, err := getTxnRevno(w.st.db.C(coll), id)
+func (w *unitsWatcher) loop(coll *stateCollection, id string) error {
+	revno, err := getTxnRevno(coll, id)
 	if err != nil {
 		return err
 	}
-	w.st.watcher.Watch(coll, id, revno, w.in)
+	w.st.watchDoc(coll.Name, id, revno, w.in)
 	defer func() {
-		w.st.watcher.Unwatch(coll, id, w.in)
+		w.st.unwatchDoc(coll.Name, id, w.in)
 		for name := range w.life {
-			w.st.watcher.Unwatch(w.st.units.Name, name, w.in)
+			w.st.unwatchDoc(w.st.units.Name, name, w.in)
 		}
 	}()
 	changes, err := w.initial()
@@ -973,7 +992,7 @@ func (w *unitsWatcher) loop(coll, id string) error {
 		case <-w.tomb.Dying():
 			return tomb.ErrDying
 		case c := <-w.in:
-			name := c.Id.(string)
+			name := w.st.localID(c.Id.(string))
 			if name == id {
 				changes, err = w.update(changes)
 			} else {
@@ -1095,8 +1114,8 @@ func (w *settingsWatcher) loop(key string) (err error) {
 	} else if !errors.IsNotFound(err) {
 		return err
 	}
-	w.st.watcher.Watch(w.st.settings.Name, key, revno, ch)
-	defer w.st.watcher.Unwatch(w.st.settings.Name, key, ch)
+	w.st.watchDoc(w.st.settings.Name, key, revno, ch)
+	defer w.st.unwatchDoc(w.st.settings.Name, key, ch)
 	out := w.out
 	if revno == -1 {
 		out = nil
@@ -1164,11 +1183,19 @@ func (st *State) WatchForEnvironConfigChanges() NotifyWatcher {
 }
 
 // WatchAPIHostPorts returns a NotifyWatcher that notifies
-// when the set of API addresses changes.
+// when the set of API addresses, or the trusted CA certificates,
+// change.
 func (st *State) WatchAPIHostPorts() NotifyWatcher {
 	return newEntityWatcher(st, st.stateServers, apiHostPortsKey)
 }
 
+// WatchStateServingInfo returns a NotifyWatcher that notifies
+// when the state serving information, including the state server
+// certificate, changes.
+func (st *State) WatchStateServingInfo() NotifyWatcher {
+	return newEntityWatcher(st, st.stateServers, stateServingInfoKey)
+}
+
 // WatchConfigSettings returns a watcher for observing changes to the
 // unit's service configuration settings. The unit must have a charm URL
 // set before this method is called, and the returned watcher will be
@@ -1183,7 +1210,7 @@ func (u *Unit) WatchConfigSettings() (NotifyWatcher, error) {
 	return newEntityWatcher(u.st, u.st.settings, settingsKey), nil
 }
 
-func newEntityWatcher(st *State, coll *mgo.Collection, key string) NotifyWatcher {
+func newEntityWatcher(st *State, coll *stateCollection, key string) NotifyWatcher {
 	w := &entityWatcher{
 		commonWatcher: commonWatcher{st: st},
 		out:           make(chan struct{}),
@@ -1205,7 +1232,7 @@ func (w *entityWatcher) Changes() <-chan struct{} {
 // given key in the given collection. It is useful to enable
 // a watcher.Watcher to be primed with the correct revision
 // id.
-func getTxnRevno(coll *mgo.Collection, key string) (int64, error) {
+func getTxnRevno(coll *stateCollection, key string) (int64, error) {
 	doc := &struct {
 		TxnRevno int64 `bson:"txn-revno"`
 	}{}
@@ -1218,14 +1245,14 @@ func getTxnRevno(coll *mgo.Collection, key string) (int64, error) {
 	return doc.TxnRevno, nil
 }
 
-func (w *entityWatcher) loop(coll *mgo.Collection, key string) error {
+func (w *entityWatcher) loop(coll *stateCollection, key string) error {
 	txnRevno, err := getTxnRevno(coll, key)
 	if err != nil {
 		return err
 	}
 	in := make(chan watcher.Change)
-	w.st.watcher.Watch(coll.Name, key, txnRevno, in)
-	defer w.st.watcher.Unwatch(coll.Name, key, in)
+	w.st.watchDoc(coll.Name, key, txnRevno, in)
+	defer w.st.unwatchDoc(coll.Name, key, in)
 	out := w.out
 	for {
 		select {
@@ -1317,14 +1344,14 @@ func (w *machineUnitsWatcher) merge(pending []string, unit string) (new []string
 		// Unit was removed or unassigned from w.machine.
 		if known {
 			delete(w.known, unit)
-			w.st.watcher.Unwatch(w.st.units.Name, unit, w.in)
+			w.st.unwatchDoc(w.st.units.Name, unit, w.in)
 			if life != Dead && !hasString(pending, unit) {
 				pending = append(pending, unit)
 			}
 			for _, subunit := range doc.Subordinates {
 				if sublife, subknown := w.known[subunit]; subknown {
 					delete(w.known, subunit)
-					w.st.watcher.Unwatch(w.st.units.Name, subunit, w.in)
+					w.st.unwatchDoc(w.st.units.Name, subunit, w.in)
 					if sublife != Dead && !hasString(pending, subunit) {
 						pending = append(pending, subunit)
 					}
@@ -1334,7 +1361,7 @@ func (w *machineUnitsWatcher) merge(pending []string, unit string) (new []string
 		return pending, nil
 	}
 	if !known {
-		w.st.watcher.Watch(w.st.units.Name, unit, doc.TxnRevno, w.in)
+		w.st.watchDoc(w.st.units.Name, unit, doc.TxnRevno, w.in)
 		pending = append(pending, unit)
 	} else if life != doc.Life && !hasString(pending, unit) {
 		pending = append(pending, unit)
@@ -1354,7 +1381,7 @@ func (w *machineUnitsWatcher) merge(pending []string, unit string) (new []string
 func (w *machineUnitsWatcher) loop() error {
 	defer func() {
 		for unit := range w.known {
-			w.st.watcher.Unwatch(w.st.units.Name, unit, w.in)
+			w.st.unwatchDoc(w.st.units.Name, unit, w.in)
 		}
 	}()
 	revno, err := getTxnRevno(w.st.machines, w.machine.doc.Id)
@@ -1362,8 +1389,8 @@ func (w *machineUnitsWatcher) loop() error {
 		return err
 	}
 	machineCh := make(chan watcher.Change)
-	w.st.watcher.Watch(w.st.machines.Name, w.machine.doc.Id, revno, machineCh)
-	defer w.st.watcher.Unwatch(w.st.machines.Name, w.machine.doc.Id, machineCh)
+	w.st.watchDoc(w.st.machines.Name, w.machine.doc.Id, revno, machineCh)
+	defer w.st.unwatchDoc(w.st.machines.Name, w.machine.doc.Id, machineCh)
 	changes, err := w.updateMachine([]string(nil))
 	if err != nil {
 		return err
@@ -1384,7 +1411,7 @@ func (w *machineUnitsWatcher) loop() error {
 				out = w.out
 			}
 		case c := <-w.in:
-			changes, err = w.merge(changes, c.Id.(string))
+			changes, err = w.merge(changes, w.st.localID(c.Id.(string)))
 			if err != nil {
 				return err
 			}
@@ -1432,7 +1459,7 @@ func (w *cleanupWatcher) Changes() <-chan struct{} {
 func (w *cleanupWatcher) loop() (err error) {
 	in := make(chan watcher.Change)
 
-	w.st.watcher.WatchCollection(w.st.cleanups.Name, in)
+	w.st.watchCollection(w.st.cleanups.Name, in, nil)
 	defer w.st.watcher.UnwatchCollection(w.st.cleanups.Name, in)
 
 	out := w.out
@@ -1452,3 +1479,285 @@ func (w *cleanupWatcher) loop() (err error) {
 		}
 	}
 }
+
+// actionWatcher notifies of pending actions queued for a unit.
+type actionWatcher struct {
+	commonWatcher
+	prefix string
+	out    chan []string
+}
+
+var _ StringsWatcher = (*actionWatcher)(nil)
+
+// WatchActions starts and returns a StringsWatcher that notifies of
+// actions queued for the unit. The first event holds the ids of all
+// pending actions; subsequent events report the ids of newly queued
+// actions. Removal of an action from the queue is not reported.
+func (u *Unit) WatchActions() StringsWatcher {
+	return newActionWatcher(u.st, actionPrefix(u.globalKey()))
+}
+
+func newActionWatcher(st *State, prefix string) StringsWatcher {
+	w := &actionWatcher{
+		commonWatcher: commonWatcher{st: st},
+		prefix:        prefix,
+		out:           make(chan []string),
+	}
+	go func() {
+		defer w.tomb.Done()
+		defer close(w.out)
+		w.tomb.Kill(w.loop())
+	}()
+	return w
+}
+
+// Changes returns the event channel for w.
+func (w *actionWatcher) Changes() <-chan []string {
+	return w.out
+}
+
+func (w *actionWatcher) initial() (*set.Strings, error) {
+	ids := new(set.Strings)
+	sel := bson.D{{"_id", bson.D{{"$regex", "^" + regexp.QuoteMeta(w.prefix)}}}}
+	iter := w.st.actions.Find(sel).Select(bson.D{{"_id", 1}}).Iter()
+	var doc struct {
+		Id string `bson:"_id"`
+	}
+	for iter.Next(&doc) {
+		ids.Add(doc.Id)
+	}
+	return ids, iter.Err()
+}
+
+func (w *actionWatcher) loop() error {
+	in := make(chan watcher.Change)
+	filter := func(key interface{}) bool {
+		k, ok := key.(string)
+		return ok && strings.HasPrefix(k, w.prefix)
+	}
+	w.st.watchCollection(w.st.actions.Name, in, filter)
+	defer w.st.watcher.UnwatchCollection(w.st.actions.Name, in)
+	ids, err := w.initial()
+	if err != nil {
+		return err
+	}
+	out := w.out
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case <-w.st.watcher.Dead():
+			return stateWatcherDeadError(w.st.watcher.Err())
+		case ch := <-in:
+			updates, ok := collect(ch, in, w.tomb.Dying())
+			if !ok {
+				return tomb.ErrDying
+			}
+			for key, exists := range updates {
+				id := w.st.localID(key.(string))
+				if exists {
+					ids.Add(id)
+				} else {
+					ids.Remove(id)
+				}
+			}
+			if !ids.IsEmpty() {
+				out = w.out
+			}
+		case out <- ids.Values():
+			out = nil
+			ids = new(set.Strings)
+		}
+	}
+}
+
+// unitStorageWatcher notifies of changes to the attachments of the
+// volumes backing a unit's storage instances.
+type unitStorageWatcher struct {
+	commonWatcher
+	unit *Unit
+	out  chan struct{}
+}
+
+var _ Watcher = (*unitStorageWatcher)(nil)
+
+// WatchStorage returns a NotifyWatcher that notifies when the volumes
+// backing the unit's storage instances are attached to or detached
+// from a machine, or their attachments change.
+func (u *Unit) WatchStorage() NotifyWatcher {
+	w := &unitStorageWatcher{
+		commonWatcher: commonWatcher{st: u.st},
+		unit:          u,
+		out:           make(chan struct{}),
+	}
+	go func() {
+		defer w.tomb.Done()
+		defer close(w.out)
+		w.tomb.Kill(w.loop())
+	}()
+	return w
+}
+
+// Changes returns the event channel for the unitStorageWatcher.
+func (w *unitStorageWatcher) Changes() <-chan struct{} {
+	return w.out
+}
+
+func (w *unitStorageWatcher) loop() error {
+	// A unit's storage instances are created along with
+	// the unit, so the set of volumes to watch is fixed.
+	instances, err := w.unit.StorageInstances()
+	if err != nil {
+		return err
+	}
+	volumes := make(map[string]bool)
+	for _, si := range instances {
+		volumes[si.VolumeName()] = true
+	}
+	filter := func(id interface{}) bool {
+		k := id.(string)
+		i := strings.LastIndex(k, ":")
+		return i >= 0 && volumes[k[i+1:]]
+	}
+	in := make(chan watcher.Change)
+	w.st.watchCollection(w.st.volumeAttachments.Name, in, filter)
+	defer w.st.watcher.UnwatchCollection(w.st.volumeAttachments.Name, in)
+	out := w.out
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case <-w.st.watcher.Dead():
+			return stateWatcherDeadError(w.st.watcher.Err())
+		case ch := <-in:
+			if _, ok := collect(ch, in, w.tomb.Dying()); !ok {
+				return tomb.ErrDying
+			}
+			out = w.out
+		case out <- struct{}{}:
+			out = nil
+		}
+	}
+}
+
+// remoteRelationsWatcher notifies of changes to the relations with
+// remote services in an environment, to the units in their scopes,
+// and to the settings of those units.
+type remoteRelationsWatcher struct {
+	commonWatcher
+	out chan struct{}
+}
+
+var _ Watcher = (*remoteRelationsWatcher)(nil)
+
+// WatchRemoteRelations returns a NotifyWatcher that notifies of
+// changes to the relations with remote services in the environment,
+// to the units, local or remote, that enter and leave their scopes,
+// and to the settings of those units.
+func (st *State) WatchRemoteRelations() NotifyWatcher {
+	w := &remoteRelationsWatcher{
+		commonWatcher: commonWatcher{st: st},
+		out:           make(chan struct{}),
+	}
+	go func() {
+		defer w.tomb.Done()
+		defer close(w.out)
+		w.tomb.Kill(w.loop())
+	}()
+	return w
+}
+
+// Changes returns the event channel for w.
+func (w *remoteRelationsWatcher) Changes() <-chan struct{} {
+	return w.out
+}
+
+// remoteRelations returns the ids of the relations with remote
+// services, keyed by relation key.
+func (w *remoteRelationsWatcher) remoteRelations() (map[string]int, error) {
+	remotes, err := w.st.AllRemoteServices()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(remotes))
+	for i, svc := range remotes {
+		names[i] = svc.Name()
+	}
+	var docs []relationDoc
+	sel := bson.D{{"endpoints.servicename", bson.D{{"$in", names}}}}
+	if err := w.st.relations.Find(sel).Select(bson.D{{"_id", 1}, {"id", 1}}).All(&docs); err != nil {
+		return nil, fmt.Errorf("cannot get relations with remote services: %v", err)
+	}
+	ids := make(map[string]int)
+	for _, doc := range docs {
+		ids[doc.Key] = doc.Id
+	}
+	return ids, nil
+}
+
+func (w *remoteRelationsWatcher) loop() error {
+	// Relation scopes and the settings of units in relations have
+	// keys of the form "r#<relation id>#...", while relations are
+	// keyed by their endpoints. Changes to all three are gathered
+	// on the one channel, so that the changes made by a single
+	// transaction are reported together.
+	isRelationUnitKey := func(key interface{}) bool {
+		return strings.HasPrefix(key.(string), "r#")
+	}
+	in := make(chan watcher.Change)
+	w.st.watchCollection(w.st.relations.Name, in, nil)
+	defer w.st.watcher.UnwatchCollection(w.st.relations.Name, in)
+	w.st.watchCollection(w.st.relationScopes.Name, in, isRelationUnitKey)
+	defer w.st.watcher.UnwatchCollection(w.st.relationScopes.Name, in)
+	w.st.watchCollection(w.st.settings.Name, in, isRelationUnitKey)
+	defer w.st.watcher.UnwatchCollection(w.st.settings.Name, in)
+
+	relations, err := w.remoteRelations()
+	if err != nil {
+		return err
+	}
+	// isRemote returns whether the key of a relation unit
+	// belongs to a relation with a remote service.
+	isRemote := func(key string) bool {
+		for _, id := range relations {
+			if strings.HasPrefix(key, fmt.Sprintf("r#%d#", id)) {
+				return true
+			}
+		}
+		return false
+	}
+	out := w.out
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case <-w.st.watcher.Dead():
+			return stateWatcherDeadError(w.st.watcher.Err())
+		case ch := <-in:
+			latest, ok := collect(ch, in, w.tomb.Dying())
+			if !ok {
+				return tomb.ErrDying
+			}
+			previous := relations
+			if relations, err = w.remoteRelations(); err != nil {
+				return err
+			}
+			for id := range latest {
+				key := w.st.localID(id.(string))
+				if isRelationUnitKey(key) {
+					if isRemote(key) {
+						out = w.out
+					}
+					continue
+				}
+				_, was := previous[key]
+				_, is := relations[key]
+				if was || is {
+					out = w.out
+				}
+			}
+		case out <- struct{}{}:
+			out = nil
+		}
+	}
+}
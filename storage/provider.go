@@ -0,0 +1,111 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/environs/config"
+)
+
+// ProviderType uniquely identifies a storage provider, such as "ebs"
+// or "loop". Storage directives name the provider in their pool.
+type ProviderType string
+
+// Scope defines where a storage provider's volumes are managed.
+type Scope int
+
+const (
+	// ScopeEnviron indicates that volumes are managed through the
+	// environment's provider, by the environment provisioner.
+	ScopeEnviron Scope = iota
+
+	// ScopeMachine indicates that volumes are managed by the machine
+	// agent of the machine to which they are attached.
+	ScopeMachine
+)
+
+// Provider is an interface for creating volume sources.
+type Provider interface {
+	// VolumeSource returns a VolumeSource for the given environment
+	// configuration. Machine-scoped providers keep any local state
+	// in storageDir.
+	VolumeSource(environConfig *config.Config, storageDir string) (VolumeSource, error)
+
+	// Scope returns the scope at which the provider's volumes
+	// are managed.
+	Scope() Scope
+
+	// Dynamic reports whether the provider can create volumes for
+	// a running machine. Volumes from providers that are not dynamic
+	// can only be created along with the instance they attach to;
+	// once the instance has started, CreateVolumes describes the
+	// volumes that were created with it.
+	Dynamic() bool
+}
+
+// VolumeSource creates and destroys volumes.
+type VolumeSource interface {
+	// CreateVolumes creates volumes with the specified parameters,
+	// attaching them as requested, and returns the details of the
+	// new volumes and attachments.
+	CreateVolumes(params []VolumeParams) ([]Volume, []VolumeAttachment, error)
+
+	// DestroyVolumes detaches and destroys the volumes
+	// with the specified provider volume ids. Sources whose
+	// volumes are only destroyed along with the instances they
+	// are attached to return an error satisfying
+	// errors.IsNotSupported.
+	DestroyVolumes(volumeIds []string) error
+}
+
+var (
+	providersMutex   sync.Mutex
+	providers        = make(map[ProviderType]Provider)
+	defaultProviders = make(map[string]ProviderType)
+)
+
+// RegisterProvider registers a storage provider with the given type.
+// It panics if a provider is already registered with that type.
+func RegisterProvider(providerType ProviderType, p Provider) {
+	providersMutex.Lock()
+	defer providersMutex.Unlock()
+	if _, ok := providers[providerType]; ok {
+		panic(fmt.Errorf("juju: duplicate storage provider type %q", providerType))
+	}
+	providers[providerType] = p
+}
+
+// StorageProvider returns the storage provider registered
+// with the given type.
+func StorageProvider(providerType ProviderType) (Provider, error) {
+	providersMutex.Lock()
+	defer providersMutex.Unlock()
+	p, ok := providers[providerType]
+	if !ok {
+		return nil, errors.NotFoundf("storage provider %q", providerType)
+	}
+	return p, nil
+}
+
+// RegisterDefaultProvider records the storage provider used by
+// environments of the given type when storage directives do not
+// name a pool.
+func RegisterDefaultProvider(envType string, providerType ProviderType) {
+	providersMutex.Lock()
+	defer providersMutex.Unlock()
+	defaultProviders[envType] = providerType
+}
+
+// DefaultProvider returns the storage provider used by environments
+// of the given type when storage directives do not name a pool.
+func DefaultProvider(envType string) (ProviderType, bool) {
+	providersMutex.Lock()
+	defer providersMutex.Unlock()
+	providerType, ok := defaultProviders[envType]
+	return providerType, ok
+}
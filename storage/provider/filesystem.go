@@ -0,0 +1,69 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// CreateFilesystem creates an ext4 filesystem on the block device
+// with the given name, such as "loop0" or "xvdf", and mounts it at
+// mountPoint. If a filesystem is already mounted at mountPoint,
+// nothing is done, so that the filesystem on a device is never
+// recreated once it may be in use.
+func CreateFilesystem(deviceName, mountPoint string) error {
+	mounted, err := isMounted(mountPoint)
+	if err != nil {
+		return err
+	}
+	if mounted {
+		logger.Debugf("filesystem already mounted at %q", mountPoint)
+		return nil
+	}
+	devicePath := "/dev/" + deviceName
+	if _, err := runCommand("mkfs.ext4", "-q", devicePath); err != nil {
+		return fmt.Errorf("cannot create filesystem on %q: %v", devicePath, err)
+	}
+	if err := os.MkdirAll(mountPoint, 0755); err != nil {
+		return err
+	}
+	if _, err := runCommand("mount", devicePath, mountPoint); err != nil {
+		return fmt.Errorf("cannot mount %q at %q: %v", devicePath, mountPoint, err)
+	}
+	return nil
+}
+
+// RemoveFilesystem unmounts the filesystem mounted at mountPoint,
+// if any, and removes the mount point directory.
+func RemoveFilesystem(mountPoint string) error {
+	mounted, err := isMounted(mountPoint)
+	if err != nil {
+		return err
+	}
+	if mounted {
+		if _, err := runCommand("umount", mountPoint); err != nil {
+			return fmt.Errorf("cannot unmount %q: %v", mountPoint, err)
+		}
+	}
+	if err := os.Remove(mountPoint); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// isMounted reports whether a filesystem is mounted at mountPoint.
+func isMounted(mountPoint string) (bool, error) {
+	output, err := runCommand("findmnt", "-l", "-n", "-o", "TARGET")
+	if err != nil {
+		return false, fmt.Errorf("cannot list mounted filesystems: %v", err)
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == mountPoint {
+			return true, nil
+		}
+	}
+	return false, nil
+}
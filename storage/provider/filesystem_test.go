@@ -0,0 +1,80 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package provider_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/storage/provider"
+	"github.com/juju/juju/testing"
+)
+
+type filesystemSuite struct {
+	testing.BaseSuite
+	mountPoint string
+	mounted    string
+	commands   []string
+}
+
+var _ = gc.Suite(&filesystemSuite{})
+
+func (s *filesystemSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.mountPoint = filepath.Join(c.MkDir(), "mount", "0")
+	s.mounted = ""
+	s.commands = nil
+	s.PatchValue(provider.RunCommand, func(command string, args ...string) (string, error) {
+		cmd := strings.Join(append([]string{command}, args...), " ")
+		s.commands = append(s.commands, cmd)
+		if command == "findmnt" {
+			return "/\n" + s.mounted + "\n", nil
+		}
+		return "", nil
+	})
+}
+
+func (s *filesystemSuite) TestCreateFilesystem(c *gc.C) {
+	err := provider.CreateFilesystem("loop0", s.mountPoint)
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.commands, gc.DeepEquals, []string{
+		"findmnt -l -n -o TARGET",
+		"mkfs.ext4 -q /dev/loop0",
+		"mount /dev/loop0 " + s.mountPoint,
+	})
+	info, err := os.Stat(s.mountPoint)
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.IsDir(), jc.IsTrue)
+}
+
+func (s *filesystemSuite) TestCreateFilesystemAlreadyMounted(c *gc.C) {
+	s.mounted = s.mountPoint
+	err := provider.CreateFilesystem("loop0", s.mountPoint)
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.commands, gc.DeepEquals, []string{"findmnt -l -n -o TARGET"})
+}
+
+func (s *filesystemSuite) TestRemoveFilesystem(c *gc.C) {
+	err := os.MkdirAll(s.mountPoint, 0755)
+	c.Assert(err, gc.IsNil)
+	s.mounted = s.mountPoint
+	err = provider.RemoveFilesystem(s.mountPoint)
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.commands, gc.DeepEquals, []string{
+		"findmnt -l -n -o TARGET",
+		"umount " + s.mountPoint,
+	})
+	_, err = os.Stat(s.mountPoint)
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
+
+func (s *filesystemSuite) TestRemoveFilesystemNotMounted(c *gc.C) {
+	err := provider.RemoveFilesystem(s.mountPoint)
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.commands, gc.DeepEquals, []string{"findmnt -l -n -o TARGET"})
+}
@@ -0,0 +1,151 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The provider package holds storage providers that
+// are not specific to any environment provider.
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/loggo"
+	"github.com/juju/utils"
+
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/storage"
+)
+
+var logger = loggo.GetLogger("juju.storage.provider")
+
+// LoopProviderType is the type of the storage provider
+// that backs volumes with files attached to loop devices.
+const LoopProviderType storage.ProviderType = "loop"
+
+func init() {
+	storage.RegisterProvider(LoopProviderType, loopProvider{})
+	storage.RegisterDefaultProvider("local", LoopProviderType)
+}
+
+// runCommand runs the command and returns its combined output.
+var runCommand = func(command string, args ...string) (string, error) {
+	logger.Tracef("%s %v", command, args)
+	return utils.RunCommand(command, args...)
+}
+
+// loopProvider creates volumes on the machine that uses them, by
+// attaching sparse files in the machine agent's storage directory
+// to loop devices. It works on any machine, including the local
+// provider's containers, but the volumes do not outlive the machine.
+type loopProvider struct{}
+
+// VolumeSource is defined on the storage.Provider interface.
+func (loopProvider) VolumeSource(environConfig *config.Config, storageDir string) (storage.VolumeSource, error) {
+	if storageDir == "" {
+		return nil, fmt.Errorf("storage directory not specified")
+	}
+	return &loopVolumeSource{storageDir}, nil
+}
+
+// Scope is defined on the storage.Provider interface.
+func (loopProvider) Scope() storage.Scope {
+	return storage.ScopeMachine
+}
+
+// Dynamic is defined on the storage.Provider interface.
+func (loopProvider) Dynamic() bool {
+	return true
+}
+
+type loopVolumeSource struct {
+	storageDir string
+}
+
+var _ storage.VolumeSource = (*loopVolumeSource)(nil)
+
+// CreateVolumes is defined on the storage.VolumeSource interface.
+func (s *loopVolumeSource) CreateVolumes(params []storage.VolumeParams) ([]storage.Volume, []storage.VolumeAttachment, error) {
+	if err := os.MkdirAll(s.storageDir, 0755); err != nil {
+		return nil, nil, err
+	}
+	var volumes []storage.Volume
+	var attachments []storage.VolumeAttachment
+	for _, p := range params {
+		if p.Attachment == nil {
+			return nil, nil, fmt.Errorf("loop volume %q must be attached to a machine", p.Name)
+		}
+		volumeId := "volume-" + p.Name
+		deviceName, err := s.createVolume(volumeId, p.Size)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot create loop volume %q: %v", p.Name, err)
+		}
+		volumes = append(volumes, storage.Volume{
+			Name:     p.Name,
+			VolumeId: volumeId,
+			Size:     p.Size,
+		})
+		attachments = append(attachments, storage.VolumeAttachment{
+			Volume:     p.Name,
+			Machine:    p.Attachment.Machine,
+			DeviceName: deviceName,
+		})
+	}
+	return volumes, attachments, nil
+}
+
+// createVolume creates a sparse file of the given size in MiB,
+// attaches it to a free loop device and returns the device's name.
+func (s *loopVolumeSource) createVolume(volumeId string, size uint64) (string, error) {
+	path := filepath.Join(s.storageDir, volumeId)
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	err = f.Truncate(int64(size) * 1024 * 1024)
+	f.Close()
+	if err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	output, err := runCommand("losetup", "-f", "--show", path)
+	if err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("cannot attach loop device: %v", err)
+	}
+	return strings.TrimPrefix(strings.TrimSpace(output), "/dev/"), nil
+}
+
+// DestroyVolumes is defined on the storage.VolumeSource interface.
+func (s *loopVolumeSource) DestroyVolumes(volumeIds []string) error {
+	for _, volumeId := range volumeIds {
+		if err := s.destroyVolume(volumeId); err != nil {
+			return fmt.Errorf("cannot destroy loop volume %q: %v", volumeId, err)
+		}
+	}
+	return nil
+}
+
+// destroyVolume detaches the volume's file from any
+// loop devices, and removes the file.
+func (s *loopVolumeSource) destroyVolume(volumeId string) error {
+	path := filepath.Join(s.storageDir, volumeId)
+	output, err := runCommand("losetup", "-j", path)
+	if err != nil {
+		return err
+	}
+	// Each line of output has the form
+	//     /dev/loop0: [0801]:1234 (/path/to/file)
+	for _, line := range strings.Split(output, "\n") {
+		if i := strings.Index(line, ":"); i > 0 {
+			if _, err := runCommand("losetup", "-d", line[:i]); err != nil {
+				return fmt.Errorf("cannot detach loop device: %v", err)
+			}
+		}
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
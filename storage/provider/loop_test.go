@@ -0,0 +1,114 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package provider_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/storage"
+	"github.com/juju/juju/storage/provider"
+	"github.com/juju/juju/testing"
+)
+
+type loopSuite struct {
+	testing.BaseSuite
+	storageDir string
+	commands   []string
+}
+
+var _ = gc.Suite(&loopSuite{})
+
+func (s *loopSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.storageDir = c.MkDir()
+	s.commands = nil
+	s.PatchValue(provider.RunCommand, func(command string, args ...string) (string, error) {
+		cmd := strings.Join(append([]string{command}, args...), " ")
+		s.commands = append(s.commands, cmd)
+		switch {
+		case args[0] == "-f":
+			return "/dev/loop0\n", nil
+		case args[0] == "-j":
+			return fmt.Sprintf("/dev/loop0: [0801]:1234 (%s)\n", args[1]), nil
+		}
+		return "", nil
+	})
+}
+
+func (s *loopSuite) volumeSource(c *gc.C) storage.VolumeSource {
+	p, err := storage.StorageProvider(provider.LoopProviderType)
+	c.Assert(err, gc.IsNil)
+	c.Assert(p.Scope(), gc.Equals, storage.ScopeMachine)
+	c.Assert(p.Dynamic(), jc.IsTrue)
+	source, err := p.VolumeSource(testing.EnvironConfig(c), s.storageDir)
+	c.Assert(err, gc.IsNil)
+	return source
+}
+
+func (s *loopSuite) TestDefaultProvider(c *gc.C) {
+	providerType, ok := storage.DefaultProvider("local")
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(providerType, gc.Equals, provider.LoopProviderType)
+}
+
+func (s *loopSuite) TestCreateVolumes(c *gc.C) {
+	source := s.volumeSource(c)
+	volumes, attachments, err := source.CreateVolumes([]storage.VolumeParams{{
+		Name:       "0",
+		Size:       2,
+		Provider:   provider.LoopProviderType,
+		Attachment: &storage.AttachmentParams{Machine: "1"},
+	}})
+	c.Assert(err, gc.IsNil)
+	c.Assert(volumes, gc.DeepEquals, []storage.Volume{{
+		Name:     "0",
+		VolumeId: "volume-0",
+		Size:     2,
+	}})
+	c.Assert(attachments, gc.DeepEquals, []storage.VolumeAttachment{{
+		Volume:     "0",
+		Machine:    "1",
+		DeviceName: "loop0",
+	}})
+	path := filepath.Join(s.storageDir, "volume-0")
+	info, err := os.Stat(path)
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.Size(), gc.Equals, int64(2*1024*1024))
+	c.Assert(s.commands, gc.DeepEquals, []string{"losetup -f --show " + path})
+}
+
+func (s *loopSuite) TestCreateVolumesRequiresAttachment(c *gc.C) {
+	source := s.volumeSource(c)
+	_, _, err := source.CreateVolumes([]storage.VolumeParams{{Name: "0", Size: 2}})
+	c.Assert(err, gc.ErrorMatches, `loop volume "0" must be attached to a machine`)
+}
+
+func (s *loopSuite) TestDestroyVolumes(c *gc.C) {
+	source := s.volumeSource(c)
+	path := filepath.Join(s.storageDir, "volume-0")
+	err := ioutil.WriteFile(path, nil, 0644)
+	c.Assert(err, gc.IsNil)
+	err = source.DestroyVolumes([]string{"volume-0"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.commands, gc.DeepEquals, []string{
+		"losetup -j " + path,
+		"losetup -d /dev/loop0",
+	})
+	_, err = os.Stat(path)
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
+
+func (s *loopSuite) TestVolumeSourceRequiresStorageDir(c *gc.C) {
+	p, err := storage.StorageProvider(provider.LoopProviderType)
+	c.Assert(err, gc.IsNil)
+	_, err = p.VolumeSource(testing.EnvironConfig(c), "")
+	c.Assert(err, gc.ErrorMatches, "storage directory not specified")
+}
@@ -0,0 +1,127 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The storage package defines the storage that units of a service may
+// request, and the interfaces through which storage providers create
+// and destroy the volumes that back it.
+package storage
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Kind is the kind of storage that a storage instance provides.
+type Kind string
+
+const (
+	// KindBlock indicates that a storage instance is a block
+	// device, which the charm is responsible for formatting.
+	KindBlock Kind = "block"
+
+	// KindFilesystem indicates that a storage instance is a
+	// filesystem, created on a block device and mounted by Juju.
+	KindFilesystem Kind = "filesystem"
+)
+
+// Directive describes the storage required by each unit of a service
+// for a single named store, as given by "juju deploy --storage".
+type Directive struct {
+	// Pool is the name of the storage provider that provides the
+	// storage. If empty, the environment's default provider is used.
+	Pool string `json:"pool,omitempty" yaml:"pool,omitempty"`
+
+	// Size is the size of each storage instance, in MiB.
+	Size uint64 `json:"size" yaml:"size"`
+
+	// Count is the number of storage instances each unit requires.
+	Count int `json:"count" yaml:"count"`
+
+	// Kind is the kind of each storage instance.
+	// If empty, KindBlock is used.
+	Kind Kind `json:"kind,omitempty" yaml:"kind,omitempty"`
+}
+
+// String returns the directive in the form accepted by ParseDirective.
+func (d Directive) String() string {
+	s := fmt.Sprintf("%dM", d.Size)
+	if d.Pool != "" {
+		s = d.Pool + "," + s
+	}
+	if d.Count != 1 {
+		s += "," + strconv.Itoa(d.Count)
+	}
+	if d.Kind != "" {
+		s += "," + string(d.Kind)
+	}
+	return s
+}
+
+// ParseDirective parses a storage directive of the form
+// "[pool,]size[,count][,kind]", where size is a number with an
+// optional M/G/T/P suffix, as for the root-disk constraint, and kind
+// is "block" or "filesystem". If count is omitted, it defaults to 1.
+// If kind is omitted, the directive's Kind is left empty.
+func ParseDirective(s string) (Directive, error) {
+	var d Directive
+	fields := strings.Split(s, ",")
+	if n := len(fields); n > 1 && isKind(fields[n-1]) {
+		d.Kind, fields = Kind(fields[n-1]), fields[:n-1]
+	}
+	if len(fields) > 3 || s == "" {
+		return Directive{}, fmt.Errorf("invalid storage directive %q: expected [pool,]size[,count][,kind]", s)
+	}
+	if _, err := parseSize(fields[0]); err != nil {
+		d.Pool, fields = fields[0], fields[1:]
+		if d.Pool == "" || len(fields) == 0 {
+			return Directive{}, fmt.Errorf("invalid storage directive %q: expected [pool,]size[,count][,kind]", s)
+		}
+	}
+	size, err := parseSize(fields[0])
+	if err != nil {
+		return Directive{}, fmt.Errorf("invalid storage directive %q: %v", s, err)
+	}
+	d.Size = size
+	d.Count = 1
+	switch len(fields) {
+	case 1:
+	case 2:
+		d.Count, err = strconv.Atoi(fields[1])
+		if err != nil || d.Count < 1 {
+			return Directive{}, fmt.Errorf("invalid storage directive %q: count must be a positive integer", s)
+		}
+	default:
+		return Directive{}, fmt.Errorf("invalid storage directive %q: expected [pool,]size[,count][,kind]", s)
+	}
+	return d, nil
+}
+
+// isKind reports whether s names a kind of storage.
+func isKind(s string) bool {
+	return s == string(KindBlock) || s == string(KindFilesystem)
+}
+
+var mbSuffixes = map[string]float64{
+	"M": 1,
+	"G": 1024,
+	"T": 1024 * 1024,
+	"P": 1024 * 1024 * 1024,
+}
+
+// parseSize returns the size in MiB described by str.
+func parseSize(str string) (uint64, error) {
+	mult := 1.0
+	if str != "" {
+		if m, ok := mbSuffixes[str[len(str)-1:]]; ok {
+			str = str[:len(str)-1]
+			mult = m
+		}
+	}
+	val, err := strconv.ParseFloat(str, 64)
+	if err != nil || !(val > 0) || math.IsInf(val, 1) {
+		return 0, fmt.Errorf("size must be a positive number with optional M/G/T/P suffix")
+	}
+	return uint64(math.Ceil(val * mult)), nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/storage"
+	"github.com/juju/juju/testing"
+)
+
+type DirectiveSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&DirectiveSuite{})
+
+var parseDirectiveTests = []struct {
+	directive string
+	expect    storage.Directive
+	err       string
+}{{
+	directive: "100G",
+	expect:    storage.Directive{Size: 102400, Count: 1},
+}, {
+	directive: "ebs,100G",
+	expect:    storage.Directive{Pool: "ebs", Size: 102400, Count: 1},
+}, {
+	directive: "loop,512,3",
+	expect:    storage.Directive{Pool: "loop", Size: 512, Count: 3},
+}, {
+	directive: "1.5G,2",
+	expect:    storage.Directive{Size: 1536, Count: 2},
+}, {
+	directive: "ebs,100G,filesystem",
+	expect:    storage.Directive{Pool: "ebs", Size: 102400, Count: 1, Kind: storage.KindFilesystem},
+}, {
+	directive: "loop,1G,2,block",
+	expect:    storage.Directive{Pool: "loop", Size: 1024, Count: 2, Kind: storage.KindBlock},
+}, {
+	directive: "10G,filesystem",
+	expect:    storage.Directive{Size: 10240, Count: 1, Kind: storage.KindFilesystem},
+}, {
+	directive: "",
+	err:       `invalid storage directive "": expected \[pool,\]size\[,count\]\[,kind\]`,
+}, {
+	directive: "ebs",
+	err:       `invalid storage directive "ebs": expected \[pool,\]size\[,count\]\[,kind\]`,
+}, {
+	directive: "ebs,lots",
+	err:       `invalid storage directive "ebs,lots": size must be a positive number with optional M/G/T/P suffix`,
+}, {
+	directive: "ebs,0",
+	err:       `invalid storage directive "ebs,0": size must be a positive number with optional M/G/T/P suffix`,
+}, {
+	directive: "ebs,10G,0",
+	err:       `invalid storage directive "ebs,10G,0": count must be a positive integer`,
+}, {
+	directive: "10G,2,3",
+	err:       `invalid storage directive "10G,2,3": expected \[pool,\]size\[,count\]\[,kind\]`,
+}, {
+	directive: "filesystem",
+	err:       `invalid storage directive "filesystem": expected \[pool,\]size\[,count\]\[,kind\]`,
+}, {
+	directive: "ebs,10G,1,2",
+	err:       `invalid storage directive "ebs,10G,1,2": expected \[pool,\]size\[,count\]\[,kind\]`,
+}}
+
+func (s *DirectiveSuite) TestParseDirective(c *gc.C) {
+	for i, test := range parseDirectiveTests {
+		c.Logf("test %d: %q", i, test.directive)
+		d, err := storage.ParseDirective(test.directive)
+		if test.err != "" {
+			c.Check(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Assert(err, gc.IsNil)
+		c.Check(d, gc.Equals, test.expect)
+
+		// The string form parses to the same directive.
+		d, err = storage.ParseDirective(d.String())
+		c.Assert(err, gc.IsNil)
+		c.Check(d, gc.Equals, test.expect)
+	}
+}
+
+type ProviderSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&ProviderSuite{})
+
+type mockProvider struct {
+	storage.Provider
+}
+
+func (s *ProviderSuite) TestRegisterProvider(c *gc.C) {
+	p := &mockProvider{}
+	storage.RegisterProvider("mock", p)
+	registered, err := storage.StorageProvider("mock")
+	c.Assert(err, gc.IsNil)
+	c.Assert(registered, gc.Equals, p)
+
+	_, err = storage.StorageProvider("unknown")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `storage provider "unknown" not found`)
+
+	c.Assert(func() {
+		storage.RegisterProvider("mock", p)
+	}, gc.PanicMatches, `juju: duplicate storage provider type "mock"`)
+}
+
+func (s *ProviderSuite) TestDefaultProvider(c *gc.C) {
+	_, ok := storage.DefaultProvider("mockenv")
+	c.Assert(ok, jc.IsFalse)
+	storage.RegisterDefaultProvider("mockenv", "mock")
+	providerType, ok := storage.DefaultProvider("mockenv")
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(providerType, gc.Equals, storage.ProviderType("mock"))
+}
@@ -0,0 +1,62 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage
+
+import (
+	"github.com/juju/juju/instance"
+)
+
+// VolumeParams holds the parameters for creating a volume.
+type VolumeParams struct {
+	// Name is the unique name assigned to the volume by Juju.
+	Name string
+
+	// Size is the minimum size of the volume, in MiB.
+	Size uint64
+
+	// Provider is the type of the storage provider
+	// that is to create the volume.
+	Provider ProviderType
+
+	// Attachment holds the parameters for attaching the volume
+	// once it is created.
+	Attachment *AttachmentParams
+}
+
+// AttachmentParams holds the parameters for attaching
+// a volume to a machine.
+type AttachmentParams struct {
+	// Machine is the id of the machine that the volume
+	// is to be attached to.
+	Machine string
+
+	// InstanceId is the id of the machine's instance. It may be
+	// empty for machine-scoped providers.
+	InstanceId instance.Id
+}
+
+// Volume describes a volume created by a storage provider.
+type Volume struct {
+	// Name is the unique name assigned to the volume by Juju.
+	Name string
+
+	// VolumeId is the provider's identifier for the volume.
+	VolumeId string
+
+	// Size is the actual size of the volume, in MiB.
+	Size uint64
+}
+
+// VolumeAttachment describes the attachment of a volume to a machine.
+type VolumeAttachment struct {
+	// Volume is the unique name assigned to the volume by Juju.
+	Volume string
+
+	// Machine is the id of the machine the volume is attached to.
+	Machine string
+
+	// DeviceName is the name of the block device on the machine,
+	// such as "xvdf" or "loop0".
+	DeviceName string
+}
@@ -12,6 +12,9 @@ var (
 	ChownPath      = &chownPath
 	IsLocalEnviron = &isLocalEnviron
 
+	UpgradeLockAttempt = &upgradeLockAttempt
+	StateUpgradeSteps  = stateUpgradeSteps
+
 	// 118 upgrade functions
 	StepsFor118                            = stepsFor118
 	EnsureLockDirExistsAndUbuntuWritable   = ensureLockDirExistsAndUbuntuWritable
@@ -0,0 +1,92 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package upgrades
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/utils"
+
+	"github.com/juju/juju/state"
+)
+
+// upgradeLockName is the name of the state server lease that is
+// held while the database is upgraded.
+const upgradeLockName = "upgrade"
+
+var (
+	// upgradeLockDuration is how long the upgrade lock is held
+	// before another state server may take it over, should its
+	// holder fail to release it.
+	upgradeLockDuration = 30 * time.Minute
+
+	// upgradeLockAttempt is used to wait for another state server
+	// to release the upgrade lock.
+	upgradeLockAttempt = utils.AttemptStrategy{
+		Total: time.Hour,
+		Delay: 5 * time.Second,
+	}
+)
+
+// stateUpgradeSteps returns the upgrade steps that change the
+// database of the state servers. Unlike other steps, they must run
+// before anything else uses the database, so they are run when the
+// state worker starts rather than once the API is available. They
+// must do nothing when another state server has already run them.
+func stateUpgradeSteps() []Step {
+	return []Step{
+		&upgradeStep{
+			description: "scope documents by environment",
+			targets:     []Target{StateServer},
+			run:         scopeDocumentsByEnvironment,
+		},
+	}
+}
+
+// scopeDocumentsByEnvironment records the UUID of the state server's
+// environment in the documents of a state server that predates the
+// hosting of several environments.
+func scopeDocumentsByEnvironment(context Context) error {
+	return context.State().ScopeLegacyDocuments()
+}
+
+// PerformStateUpgrade runs the upgrade steps that change the database
+// of the state servers. Only one state server runs them at a time,
+// while it holds the upgrade lock; holder identifies the state server.
+func PerformStateUpgrade(st *state.State, holder string) error {
+	if err := claimUpgradeLock(st, holder); err != nil {
+		return err
+	}
+	defer func() {
+		if err := st.ReleaseServerLease(upgradeLockName, holder); err != nil {
+			logger.Errorf("cannot release upgrade lock: %v", err)
+		}
+	}()
+	context := &upgradeContext{st: st}
+	for _, step := range stateUpgradeSteps() {
+		logger.Infof("running state upgrade step: %v", step.Description())
+		if err := step.Run(context); err != nil {
+			logger.Errorf("state upgrade step %q failed: %v", step.Description(), err)
+			return &upgradeError{
+				description: step.Description(),
+				err:         err,
+			}
+		}
+	}
+	return nil
+}
+
+// claimUpgradeLock waits until the upgrade lock can be claimed for
+// holder, and claims it.
+func claimUpgradeLock(st *state.State, holder string) error {
+	for a := upgradeLockAttempt.Start(); a.Next(); {
+		err := st.ClaimServerLease(upgradeLockName, holder, upgradeLockDuration)
+		if err != state.ErrServerLeaseHeld {
+			return err
+		}
+		logger.Infof("waiting for another state server to finish upgrading the database")
+	}
+	return fmt.Errorf("timed out waiting for the upgrade lock")
+}
@@ -0,0 +1,57 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package upgrades_test
+
+import (
+	"time"
+
+	"github.com/juju/utils"
+	gc "launchpad.net/gocheck"
+
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/upgrades"
+)
+
+type stateUpgradeSuite struct {
+	jujutesting.JujuConnSuite
+}
+
+var _ = gc.Suite(&stateUpgradeSuite{})
+
+func (s *stateUpgradeSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.PatchValue(upgrades.UpgradeLockAttempt, utils.AttemptStrategy{
+		Total: 100 * time.Millisecond,
+		Delay: 10 * time.Millisecond,
+	})
+}
+
+func (s *stateUpgradeSuite) TestStateUpgradeSteps(c *gc.C) {
+	expectedSteps := []string{
+		"scope documents by environment",
+	}
+	assertExpectedSteps(c, upgrades.StateUpgradeSteps(), expectedSteps)
+}
+
+func (s *stateUpgradeSuite) TestPerformStateUpgrade(c *gc.C) {
+	err := upgrades.PerformStateUpgrade(s.State, "machine-0")
+	c.Assert(err, gc.IsNil)
+
+	// The lock is released once the steps have run.
+	err = s.State.ClaimServerLease("upgrade", "machine-1", time.Minute)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *stateUpgradeSuite) TestPerformStateUpgradeWaitsForLock(c *gc.C) {
+	err := s.State.ClaimServerLease("upgrade", "machine-1", time.Minute)
+	c.Assert(err, gc.IsNil)
+
+	err = upgrades.PerformStateUpgrade(s.State, "machine-0")
+	c.Assert(err, gc.ErrorMatches, "timed out waiting for the upgrade lock")
+
+	err = s.State.ReleaseServerLease("upgrade", "machine-1")
+	c.Assert(err, gc.IsNil)
+	err = upgrades.PerformStateUpgrade(s.State, "machine-0")
+	c.Assert(err, gc.IsNil)
+}
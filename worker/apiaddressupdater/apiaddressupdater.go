@@ -25,13 +25,17 @@ type APIAddressUpdater struct {
 // which can be used to watch for API address changes.
 type APIAddresser interface {
 	APIHostPorts() ([][]network.HostPort, error)
+	CACert() (string, error)
 	WatchAPIHostPorts() (watcher.NotifyWatcher, error)
 }
 
 // APIAddressSetter is an interface that is provided to NewAPIAddressUpdater
-// whose SetAPIHostPorts method will be invoked whenever address changes occur.
+// whose SetAPIHostPorts and SetCACert methods will be invoked whenever
+// address changes occur. The CA certificates are recorded alongside
+// the addresses, so that agents learn about rotated certificates.
 type APIAddressSetter interface {
 	SetAPIHostPorts(servers [][]network.HostPort) error
+	SetCACert(caCert string) error
 }
 
 // NewAPIAddressUpdater returns a worker.Worker that runs state.Cleanup()
@@ -56,6 +60,13 @@ func (c *APIAddressUpdater) Handle() error {
 		return fmt.Errorf("error setting addresses: %v", err)
 	}
 	logger.Infof("API addresses updated to %q", addresses)
+	caCert, err := c.addresser.CACert()
+	if err != nil {
+		return fmt.Errorf("error getting CA certificates: %v", err)
+	}
+	if err := c.setter.SetCACert(caCert); err != nil {
+		return fmt.Errorf("error setting CA certificates: %v", err)
+	}
 	return nil
 }
 
@@ -9,6 +9,7 @@ import (
 
 	gc "launchpad.net/gocheck"
 
+	"github.com/juju/juju/cert"
 	jujutesting "github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state"
@@ -28,6 +29,7 @@ var _ = gc.Suite(&APIAddressUpdaterSuite{})
 
 type apiAddressSetter struct {
 	servers chan [][]network.HostPort
+	caCerts chan string
 	err     error
 }
 
@@ -36,6 +38,13 @@ func (s *apiAddressSetter) SetAPIHostPorts(servers [][]network.HostPort) error {
 	return s.err
 }
 
+func (s *apiAddressSetter) SetCACert(caCert string) error {
+	if s.caCerts != nil {
+		s.caCerts <- caCert
+	}
+	return nil
+}
+
 func (s *APIAddressUpdaterSuite) TestStartStop(c *gc.C) {
 	st, _ := s.OpenAPIAsNewMachine(c, state.JobHostUnits)
 	worker := apiaddressupdater.NewAPIAddressUpdater(st.Machiner(), &apiAddressSetter{})
@@ -95,3 +104,37 @@ func (s *APIAddressUpdaterSuite) TestAddressChange(c *gc.C) {
 		c.Assert(servers, gc.DeepEquals, updatedServers)
 	}
 }
+
+func (s *APIAddressUpdaterSuite) TestCACertChange(c *gc.C) {
+	setter := &apiAddressSetter{
+		servers: make(chan [][]network.HostPort, 1),
+		caCerts: make(chan string, 1),
+	}
+	st, _ := s.OpenAPIAsNewMachine(c, state.JobHostUnits)
+	worker := apiaddressupdater.NewAPIAddressUpdater(st.Machiner(), setter)
+	defer func() { c.Assert(worker.Wait(), gc.IsNil) }()
+	defer worker.Kill()
+	s.BackingState.StartSync()
+
+	// SetCACert should be called with the initial value,
+	// and then with the new CA certificates.
+	<-setter.servers
+	select {
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for SetCACert to be called first")
+	case caCert := <-setter.caCerts:
+		c.Assert(caCert, gc.Equals, coretesting.CACert)
+	}
+	newCACert, _, err := cert.NewCA("new", time.Now().AddDate(1, 0, 0))
+	c.Assert(err, gc.IsNil)
+	err = s.State.SetCACert(newCACert + coretesting.CACert)
+	c.Assert(err, gc.IsNil)
+	s.BackingState.StartSync()
+	<-setter.servers
+	select {
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for SetCACert to be called second")
+	case caCert := <-setter.caCerts:
+		c.Assert(caCert, gc.Equals, newCACert+coretesting.CACert)
+	}
+}
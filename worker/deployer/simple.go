@@ -88,6 +88,7 @@ func (ctx *SimpleContext) DeployUnit(unitName, initialPassword string) (err erro
 	logger.Debugf("API addresses: %q", result.APIAddresses)
 	containerType := ctx.agentConfig.Value(agent.ContainerType)
 	namespace := ctx.agentConfig.Value(agent.Namespace)
+	environUUID := ctx.agentConfig.Value(agent.EnvironUUID)
 	conf, err := agent.NewAgentConfig(
 		agent.AgentConfigParams{
 			DataDir:           dataDir,
@@ -103,6 +104,7 @@ func (ctx *SimpleContext) DeployUnit(unitName, initialPassword string) (err erro
 			Values: map[string]string{
 				agent.ContainerType: containerType,
 				agent.Namespace:     namespace,
+				agent.EnvironUUID:   environUUID,
 			},
 		})
 	if err != nil {
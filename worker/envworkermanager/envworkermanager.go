@@ -0,0 +1,112 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package envworkermanager
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/watcher"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.envworkermanager")
+
+// StartFunc starts the workers of a hosted environment, given a
+// State for the environment. The State is closed once the returned
+// worker has stopped.
+type StartFunc func(st *state.State) (worker.Worker, error)
+
+// EnvWorkerManager runs the workers of the environments hosted by
+// a state server, starting them when an environment is created and
+// stopping them once it has been removed.
+type EnvWorkerManager struct {
+	st     *state.State
+	start  StartFunc
+	runner worker.Runner
+}
+
+// NewEnvWorkerManager returns a worker that runs the workers of each
+// environment hosted by the state server of st, as started by start.
+// The workers of an environment are restarted if they fail.
+func NewEnvWorkerManager(st *state.State, start StartFunc) worker.Worker {
+	return worker.NewStringsWorker(&EnvWorkerManager{
+		st:    st,
+		start: start,
+	})
+}
+
+func (m *EnvWorkerManager) SetUp() (watcher.StringsWatcher, error) {
+	// The failure of one environment's workers
+	// must not affect the other environments.
+	isFatal := func(error) bool { return false }
+	moreImportant := func(err0, err1 error) bool { return true }
+	m.runner = worker.NewRunner(isFatal, moreImportant)
+	return m.st.WatchHostedEnvironments(), nil
+}
+
+func (m *EnvWorkerManager) Handle(uuids []string) error {
+	for _, uuid := range uuids {
+		_, err := m.st.HostedEnvironment(uuid)
+		if errors.IsNotFound(err) {
+			logger.Infof("stopping workers of removed environment %q", uuid)
+			if err := m.runner.StopWorker(uuid); err != nil {
+				return err
+			}
+			continue
+		} else if err != nil {
+			return err
+		}
+		// Nothing is done if the workers are already running;
+		// they remain running while the environment is dying,
+		// as it is they that clean it up.
+		uuid := uuid
+		if err := m.runner.StartWorker(uuid, func() (worker.Worker, error) {
+			return m.startEnvWorkers(uuid)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *EnvWorkerManager) TearDown() error {
+	if m.runner == nil {
+		return nil
+	}
+	return worker.Stop(m.runner)
+}
+
+// startEnvWorkers starts the workers of the
+// environment with the given UUID.
+func (m *EnvWorkerManager) startEnvWorkers(uuid string) (worker.Worker, error) {
+	logger.Infof("starting workers of environment %q", uuid)
+	st, err := m.st.ForEnviron(uuid)
+	if err != nil {
+		return nil, err
+	}
+	w, err := m.start(st)
+	if err != nil {
+		st.Close()
+		return nil, err
+	}
+	return &envWorker{Worker: w, st: st}, nil
+}
+
+// envWorker runs the workers of a hosted environment,
+// closing its State once they have stopped.
+type envWorker struct {
+	worker.Worker
+	st *state.State
+}
+
+// Wait implements worker.Worker.Wait.
+func (w *envWorker) Wait() error {
+	err := w.Worker.Wait()
+	if closeErr := w.st.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
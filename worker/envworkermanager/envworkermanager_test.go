@@ -0,0 +1,125 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package envworkermanager_test
+
+import (
+	stdtesting "testing"
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/envworkermanager"
+)
+
+func TestPackage(t *stdtesting.T) {
+	coretesting.MgoTestPackage(t)
+}
+
+type EnvWorkerManagerSuite struct {
+	testing.JujuConnSuite
+	started chan string
+	stopped chan string
+}
+
+var _ = gc.Suite(&EnvWorkerManagerSuite{})
+
+var _ worker.StringsWatchHandler = (*envworkermanager.EnvWorkerManager)(nil)
+
+func (s *EnvWorkerManagerSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.started = make(chan string, 10)
+	s.stopped = make(chan string, 10)
+}
+
+// startEnvWorkers starts a worker that records
+// when it starts and stops for an environment.
+func (s *EnvWorkerManagerSuite) startEnvWorkers(st *state.State) (worker.Worker, error) {
+	env, err := st.Environment()
+	if err != nil {
+		return nil, err
+	}
+	uuid := env.UUID()
+	s.started <- uuid
+	return worker.NewSimpleWorker(func(stopCh <-chan struct{}) error {
+		<-stopCh
+		s.stopped <- uuid
+		return nil
+	}), nil
+}
+
+func (s *EnvWorkerManagerSuite) assertEvent(c *gc.C, events chan string, uuid string) {
+	timeout := time.After(coretesting.LongWait)
+	for {
+		s.State.StartSync()
+		select {
+		case got := <-events:
+			c.Assert(got, gc.Equals, uuid)
+			return
+		case <-time.After(coretesting.ShortWait):
+		case <-timeout:
+			c.Fatalf("timed out waiting for environment %q", uuid)
+		}
+	}
+}
+
+func (s *EnvWorkerManagerSuite) assertNoEvent(c *gc.C, events chan string) {
+	s.State.StartSync()
+	select {
+	case got := <-events:
+		c.Fatalf("unexpected event for environment %q", got)
+	case <-time.After(coretesting.ShortWait):
+	}
+}
+
+func (s *EnvWorkerManagerSuite) newEnvironment(c *gc.C, name string) *state.Environment {
+	cfg, err := s.State.EnvironConfig()
+	c.Assert(err, gc.IsNil)
+	cfg, err = cfg.Apply(map[string]interface{}{"name": name})
+	c.Assert(err, gc.IsNil)
+	env, st, err := s.State.NewEnvironment(cfg, "admin")
+	c.Assert(err, gc.IsNil)
+	s.AddCleanup(func(*gc.C) { st.Close() })
+	return env
+}
+
+func (s *EnvWorkerManagerSuite) TestStartsWorkersOfExistingEnvironments(c *gc.C) {
+	env := s.newEnvironment(c, "hosted")
+
+	m := envworkermanager.NewEnvWorkerManager(s.State, s.startEnvWorkers)
+	defer func() { c.Assert(worker.Stop(m), gc.IsNil) }()
+	s.assertEvent(c, s.started, env.UUID())
+	s.assertNoEvent(c, s.started)
+}
+
+func (s *EnvWorkerManagerSuite) TestStartsAndStopsWorkers(c *gc.C) {
+	m := envworkermanager.NewEnvWorkerManager(s.State, s.startEnvWorkers)
+	defer func() { c.Assert(worker.Stop(m), gc.IsNil) }()
+	s.assertNoEvent(c, s.started)
+
+	env := s.newEnvironment(c, "hosted")
+	s.assertEvent(c, s.started, env.UUID())
+
+	// The workers keep running while the environment is dying.
+	err := env.Destroy()
+	c.Assert(err, gc.IsNil)
+	s.assertNoEvent(c, s.stopped)
+
+	err = env.Remove()
+	c.Assert(err, gc.IsNil)
+	s.assertEvent(c, s.stopped, env.UUID())
+	s.assertNoEvent(c, s.started)
+}
+
+func (s *EnvWorkerManagerSuite) TestStopsWorkers(c *gc.C) {
+	env := s.newEnvironment(c, "hosted")
+	m := envworkermanager.NewEnvWorkerManager(s.State, s.startEnvWorkers)
+	s.assertEvent(c, s.started, env.UUID())
+
+	c.Assert(worker.Stop(m), gc.IsNil)
+	s.assertEvent(c, s.stopped, env.UUID())
+}
@@ -37,7 +37,7 @@ type Firewaller struct {
 	serviceds       map[string]*serviceData
 	exposedChange   chan *exposedChange
 	globalMode      bool
-	globalPortRef   map[network.Port]int
+	globalPortRef   map[network.PortRange]int
 }
 
 // NewFirewaller returns a new Firewaller.
@@ -80,7 +80,7 @@ func (fw *Firewaller) loop() error {
 	}
 	if fw.environ.Config().FirewallMode() == config.FwGlobal {
 		fw.globalMode = true
-		fw.globalPortRef = make(map[network.Port]int)
+		fw.globalPortRef = make(map[network.PortRange]int)
 	}
 	for {
 		select {
@@ -127,6 +127,7 @@ func (fw *Firewaller) loop() error {
 			}
 		case change := <-fw.exposedChange:
 			change.serviced.exposed = change.exposed
+			change.serviced.exposedCIDRs = change.cidrs
 			unitds := []*unitData{}
 			for _, unitd := range change.serviced.unitds {
 				unitds = append(unitds, unitd)
@@ -152,7 +153,7 @@ func (fw *Firewaller) startMachine(tag string) error {
 		fw:     fw,
 		tag:    tag,
 		unitds: make(map[string]*unitData),
-		ports:  make([]network.Port, 0),
+		ports:  make([]network.PortRange, 0),
 	}
 	m, err := machined.machine()
 	if params.IsCodeNotFound(err) {
@@ -196,7 +197,7 @@ func (fw *Firewaller) startUnit(unit *apifirewaller.Unit, machineTag string) err
 	}
 	serviceName := service.Name()
 	unitName := unit.Name()
-	openedPorts, err := unit.OpenedPorts()
+	openedPorts, err := unit.OpenedPortRanges()
 	if err != nil {
 		return err
 	}
@@ -219,7 +220,7 @@ func (fw *Firewaller) startUnit(unit *apifirewaller.Unit, machineTag string) err
 	unitd.serviced = fw.serviceds[serviceName]
 	unitd.serviced.unitds[unitName] = unitd
 
-	ports := make([]network.Port, len(unitd.ports))
+	ports := make([]network.PortRange, len(unitd.ports))
 	copy(ports, unitd.ports)
 
 	go unitd.watchLoop(ports)
@@ -233,14 +234,19 @@ func (fw *Firewaller) startService(service *apifirewaller.Service) error {
 	if err != nil {
 		return err
 	}
+	cidrs, err := service.ExposedCIDRs()
+	if err != nil {
+		return err
+	}
 	serviced := &serviceData{
-		fw:      fw,
-		service: service,
-		exposed: exposed,
-		unitds:  make(map[string]*unitData),
+		fw:           fw,
+		service:      service,
+		exposed:      exposed,
+		exposedCIDRs: cidrs,
+		unitds:       make(map[string]*unitData),
 	}
 	fw.serviceds[service.Name()] = serviced
-	go serviced.watchLoop(serviced.exposed)
+	go serviced.watchLoop(serviced.exposed, serviced.exposedCIDRs)
 	return nil
 }
 
@@ -252,34 +258,32 @@ func (fw *Firewaller) reconcileGlobal() error {
 	if err != nil {
 		return err
 	}
-	collector := make(map[network.Port]bool)
+	collector := make(map[network.PortRange]bool)
 	for _, unitd := range fw.unitds {
-		if unitd.serviced.exposed {
-			for _, port := range unitd.ports {
-				collector[port] = true
-			}
+		for _, port := range unitd.exposedPorts() {
+			collector[port] = true
 		}
 	}
-	wantedPorts := []network.Port{}
+	wantedPorts := []network.PortRange{}
 	for port := range collector {
 		wantedPorts = append(wantedPorts, port)
 	}
 	// Check which ports to open or to close.
-	toOpen := Diff(wantedPorts, initialPorts)
-	toClose := Diff(initialPorts, wantedPorts)
+	toOpen := diffRanges(wantedPorts, initialPorts)
+	toClose := diffRanges(initialPorts, wantedPorts)
 	if len(toOpen) > 0 {
 		logger.Infof("opening global ports %v", toOpen)
 		if err := fw.environ.OpenPorts(toOpen); err != nil {
 			return err
 		}
-		network.SortPorts(toOpen)
+		network.SortPortRanges(toOpen)
 	}
 	if len(toClose) > 0 {
 		logger.Infof("closing global ports %v", toClose)
 		if err := fw.environ.ClosePorts(toClose); err != nil {
 			return err
 		}
-		network.SortPorts(toClose)
+		network.SortPortRanges(toClose)
 	}
 	return nil
 }
@@ -318,8 +322,8 @@ func (fw *Firewaller) reconcileInstances() error {
 			return err
 		}
 		// Check which ports to open or to close.
-		toOpen := Diff(machined.ports, initialPorts)
-		toClose := Diff(initialPorts, machined.ports)
+		toOpen := diffRanges(machined.ports, initialPorts)
+		toClose := diffRanges(initialPorts, machined.ports)
 		if len(toOpen) > 0 {
 			logger.Infof("opening instance ports %v for %q",
 				toOpen, machined.tag)
@@ -327,7 +331,7 @@ func (fw *Firewaller) reconcileInstances() error {
 				// TODO(mue) Add local retry logic.
 				return err
 			}
-			network.SortPorts(toOpen)
+			network.SortPortRanges(toOpen)
 		}
 		if len(toClose) > 0 {
 			logger.Infof("closing instance ports %v for %q",
@@ -336,7 +340,7 @@ func (fw *Firewaller) reconcileInstances() error {
 				// TODO(mue) Add local retry logic.
 				return err
 			}
-			network.SortPorts(toClose)
+			network.SortPortRanges(toClose)
 		}
 	}
 	return nil
@@ -398,20 +402,18 @@ func (fw *Firewaller) flushUnits(unitds []*unitData) error {
 // flushMachine opens and closes ports for the passed machine.
 func (fw *Firewaller) flushMachine(machined *machineData) error {
 	// Gather ports to open and close.
-	ports := map[network.Port]bool{}
+	ports := map[network.PortRange]bool{}
 	for _, unitd := range machined.unitds {
-		if unitd.serviced.exposed {
-			for _, port := range unitd.ports {
-				ports[port] = true
-			}
+		for _, port := range unitd.exposedPorts() {
+			ports[port] = true
 		}
 	}
-	want := []network.Port{}
+	want := []network.PortRange{}
 	for port := range ports {
 		want = append(want, port)
 	}
-	toOpen := Diff(want, machined.ports)
-	toClose := Diff(machined.ports, want)
+	toOpen := diffRanges(want, machined.ports)
+	toClose := diffRanges(machined.ports, want)
 	machined.ports = want
 	if fw.globalMode {
 		return fw.flushGlobalPorts(toOpen, toClose)
@@ -422,9 +424,9 @@ func (fw *Firewaller) flushMachine(machined *machineData) error {
 // flushGlobalPorts opens and closes global ports in the environment.
 // It keeps a reference count for ports so that only 0-to-1 and 1-to-0 events
 // modify the environment.
-func (fw *Firewaller) flushGlobalPorts(rawOpen, rawClose []network.Port) error {
+func (fw *Firewaller) flushGlobalPorts(rawOpen, rawClose []network.PortRange) error {
 	// Filter which ports are really to open or close.
-	var toOpen, toClose []network.Port
+	var toOpen, toClose []network.PortRange
 	for _, port := range rawOpen {
 		if fw.globalPortRef[port] == 0 {
 			toOpen = append(toOpen, port)
@@ -440,26 +442,32 @@ func (fw *Firewaller) flushGlobalPorts(rawOpen, rawClose []network.Port) error {
 	}
 	// Open and close the ports.
 	if len(toOpen) > 0 {
-		if err := fw.environ.OpenPorts(toOpen); err != nil {
+		opened, err := applyPorts(fw.environ.OpenPorts, toOpen)
+		if err != nil {
 			// TODO(mue) Add local retry logic.
 			return err
 		}
-		network.SortPorts(toOpen)
-		logger.Infof("opened ports %v in environment", toOpen)
+		if len(opened) > 0 {
+			network.SortPortRanges(opened)
+			logger.Infof("opened ports %v in environment", opened)
+		}
 	}
 	if len(toClose) > 0 {
-		if err := fw.environ.ClosePorts(toClose); err != nil {
+		closed, err := applyPorts(fw.environ.ClosePorts, toClose)
+		if err != nil {
 			// TODO(mue) Add local retry logic.
 			return err
 		}
-		network.SortPorts(toClose)
-		logger.Infof("closed ports %v in environment", toClose)
+		if len(closed) > 0 {
+			network.SortPortRanges(closed)
+			logger.Infof("closed ports %v in environment", closed)
+		}
 	}
 	return nil
 }
 
 // flushInstancePorts opens and closes ports global on the machine.
-func (fw *Firewaller) flushInstancePorts(machined *machineData, toOpen, toClose []network.Port) error {
+func (fw *Firewaller) flushInstancePorts(machined *machineData, toOpen, toClose []network.PortRange) error {
 	// If there's nothing to do, do nothing.
 	// This is important because when a machine is first created,
 	// it will have no instance id but also no open ports -
@@ -489,24 +497,74 @@ func (fw *Firewaller) flushInstancePorts(machined *machineData, toOpen, toClose
 	}
 	// Open and close the ports.
 	if len(toOpen) > 0 {
-		if err := instances[0].OpenPorts(machineId, toOpen); err != nil {
+		openPorts := func(ports []network.PortRange) error {
+			return instances[0].OpenPorts(machineId, ports)
+		}
+		opened, err := applyPorts(openPorts, toOpen)
+		if err != nil {
 			// TODO(mue) Add local retry logic.
 			return err
 		}
-		network.SortPorts(toOpen)
-		logger.Infof("opened ports %v on %q", toOpen, machined.tag)
+		if len(opened) > 0 {
+			network.SortPortRanges(opened)
+			logger.Infof("opened ports %v on %q", opened, machined.tag)
+		}
 	}
 	if len(toClose) > 0 {
-		if err := instances[0].ClosePorts(machineId, toClose); err != nil {
+		closePorts := func(ports []network.PortRange) error {
+			return instances[0].ClosePorts(machineId, ports)
+		}
+		closed, err := applyPorts(closePorts, toClose)
+		if err != nil {
 			// TODO(mue) Add local retry logic.
 			return err
 		}
-		network.SortPorts(toClose)
-		logger.Infof("closed ports %v on %q", toClose, machined.tag)
+		if len(closed) > 0 {
+			network.SortPortRanges(closed)
+			logger.Infof("closed ports %v on %q", closed, machined.tag)
+		}
 	}
 	return nil
 }
 
+// applyPorts calls apply with the given port ranges, and returns the
+// port ranges it succeeded with. Services cannot be exposed to source
+// CIDRs in environments that cannot restrict access to them, but a
+// service may have been exposed before that was checked. Providers
+// return a NotSupported error for such port ranges; retrying cannot
+// help, and failing would stop the firewaller for every service, so
+// the restricted port ranges are logged and skipped and the rest
+// applied.
+func applyPorts(apply func([]network.PortRange) error, ports []network.PortRange) ([]network.PortRange, error) {
+	err := apply(ports)
+	if err == nil {
+		return ports, nil
+	}
+	if !errors.IsNotSupported(err) {
+		return nil, err
+	}
+	var unrestricted, restricted []network.PortRange
+	for _, port := range ports {
+		if port.SourceCIDR == "" {
+			unrestricted = append(unrestricted, port)
+		} else {
+			restricted = append(restricted, port)
+		}
+	}
+	if len(restricted) == 0 {
+		return nil, err
+	}
+	network.SortPortRanges(restricted)
+	logger.Errorf("skipping ports %v: %v", restricted, err)
+	if len(unrestricted) == 0 {
+		return nil, nil
+	}
+	if err := apply(unrestricted); err != nil {
+		return nil, err
+	}
+	return unrestricted, nil
+}
+
 // machineLifeChanged starts watching new machines when the firewaller
 // is starting, or when new machines come to life, and stops watching
 // machines that are dying.
@@ -617,7 +675,7 @@ type machineData struct {
 	fw     *Firewaller
 	tag    string
 	unitds map[string]*unitData
-	ports  []network.Port
+	ports  []network.PortRange
 }
 
 func (md *machineData) machine() (*apifirewaller.Machine, error) {
@@ -658,7 +716,7 @@ func (md *machineData) Stop() error {
 // portsChange contains the changed ports for one specific unit.
 type portsChange struct {
 	unitd *unitData
-	ports []network.Port
+	ports []network.PortRange
 }
 
 // unitData holds unit details and watches port changes.
@@ -668,11 +726,32 @@ type unitData struct {
 	unit     *apifirewaller.Unit
 	serviced *serviceData
 	machined *machineData
-	ports    []network.Port
+	ports    []network.PortRange
+}
+
+// exposedPorts returns the port ranges the firewall should open for
+// the unit. It is empty unless the unit's service is exposed; when the
+// service is exposed only to some CIDRs, each of the unit's port ranges
+// is returned once for every such CIDR.
+func (ud *unitData) exposedPorts() []network.PortRange {
+	if !ud.serviced.exposed {
+		return nil
+	}
+	if len(ud.serviced.exposedCIDRs) == 0 {
+		return ud.ports
+	}
+	var ports []network.PortRange
+	for _, port := range ud.ports {
+		for _, cidr := range ud.serviced.exposedCIDRs {
+			port.SourceCIDR = cidr
+			ports = append(ports, port)
+		}
+	}
+	return ports
 }
 
 // watchLoop watches the unit for port changes.
-func (ud *unitData) watchLoop(latestPorts []network.Port) {
+func (ud *unitData) watchLoop(latestPorts []network.PortRange) {
 	defer ud.tomb.Done()
 	w, err := ud.unit.Watch()
 	if err != nil {
@@ -695,7 +774,7 @@ func (ud *unitData) watchLoop(latestPorts []network.Port) {
 				}
 				return
 			}
-			change, err := ud.unit.OpenedPorts()
+			change, err := ud.unit.OpenedPortRanges()
 			if err != nil {
 				ud.fw.tomb.Kill(err)
 				return
@@ -715,7 +794,7 @@ func (ud *unitData) watchLoop(latestPorts []network.Port) {
 
 // samePorts returns whether old and new contain the same set of ports.
 // Both old and new must be sorted.
-func samePorts(old, new []network.Port) bool {
+func samePorts(old, new []network.PortRange) bool {
 	if len(old) != len(new) {
 		return false
 	}
@@ -733,23 +812,27 @@ func (ud *unitData) Stop() error {
 	return ud.tomb.Wait()
 }
 
-// exposedChange contains the changed exposed flag for one specific service.
+// exposedChange contains the changed exposed flag and exposed CIDRs
+// for one specific service.
 type exposedChange struct {
 	serviced *serviceData
 	exposed  bool
+	cidrs    []string
 }
 
 // serviceData holds service details and watches exposure changes.
 type serviceData struct {
-	tomb    tomb.Tomb
-	fw      *Firewaller
-	service *apifirewaller.Service
-	exposed bool
-	unitds  map[string]*unitData
+	tomb         tomb.Tomb
+	fw           *Firewaller
+	service      *apifirewaller.Service
+	exposed      bool
+	exposedCIDRs []string
+	unitds       map[string]*unitData
 }
 
-// watchLoop watches the service's exposed flag for changes.
-func (sd *serviceData) watchLoop(exposed bool) {
+// watchLoop watches the service's exposed flag and exposed CIDRs for
+// changes.
+func (sd *serviceData) watchLoop(exposed bool, cidrs []string) {
 	defer sd.tomb.Done()
 	w, err := sd.service.Watch()
 	if err != nil {
@@ -777,12 +860,18 @@ func (sd *serviceData) watchLoop(exposed bool) {
 				sd.fw.tomb.Kill(err)
 				return
 			}
-			if change == exposed {
+			cidrsChange, err := sd.service.ExposedCIDRs()
+			if err != nil {
+				sd.fw.tomb.Kill(err)
+				return
+			}
+			if change == exposed && sameStrings(cidrsChange, cidrs) {
 				continue
 			}
 			exposed = change
+			cidrs = cidrsChange
 			select {
-			case sd.fw.exposedChange <- &exposedChange{sd, change}:
+			case sd.fw.exposedChange <- &exposedChange{sd, change, cidrsChange}:
 			case <-sd.tomb.Dying():
 				return
 			}
@@ -790,14 +879,28 @@ func (sd *serviceData) watchLoop(exposed bool) {
 	}
 }
 
+// sameStrings returns whether old and new contain the same strings
+// in the same order.
+func sameStrings(old, new []string) bool {
+	if len(old) != len(new) {
+		return false
+	}
+	for i, s := range old {
+		if new[i] != s {
+			return false
+		}
+	}
+	return true
+}
+
 // Stop stops the service watching.
 func (sd *serviceData) Stop() error {
 	sd.tomb.Kill(nil)
 	return sd.tomb.Wait()
 }
 
-// Diff returns all the ports that exist in A but not B.
-func Diff(A, B []network.Port) (missing []network.Port) {
+// diffRanges returns all the port ranges that exist in A but not B.
+func diffRanges(A, B []network.PortRange) (missing []network.PortRange) {
 next:
 	for _, a := range A {
 		for _, b := range B {
@@ -8,6 +8,7 @@ import (
 	stdtesting "testing"
 	"time"
 
+	"github.com/juju/errors"
 	"github.com/juju/utils"
 	gc "launchpad.net/gocheck"
 
@@ -46,7 +47,7 @@ var _ worker.Worker = (*firewaller.Firewaller)(nil)
 
 // assertPorts retrieves the open ports of the instance and compares them
 // to the expected.
-func (s *FirewallerSuite) assertPorts(c *gc.C, inst instance.Instance, machineId string, expected []network.Port) {
+func (s *FirewallerSuite) assertPorts(c *gc.C, inst instance.Instance, machineId string, expected []network.PortRange) {
 	s.BackingState.StartSync()
 	start := time.Now()
 	for {
@@ -55,8 +56,8 @@ func (s *FirewallerSuite) assertPorts(c *gc.C, inst instance.Instance, machineId
 			c.Fatal(err)
 			return
 		}
-		network.SortPorts(got)
-		network.SortPorts(expected)
+		network.SortPortRanges(got)
+		network.SortPortRanges(expected)
 		if reflect.DeepEqual(got, expected) {
 			c.Succeed()
 			return
@@ -71,7 +72,7 @@ func (s *FirewallerSuite) assertPorts(c *gc.C, inst instance.Instance, machineId
 
 // assertEnvironPorts retrieves the open ports of environment and compares them
 // to the expected.
-func (s *FirewallerSuite) assertEnvironPorts(c *gc.C, expected []network.Port) {
+func (s *FirewallerSuite) assertEnvironPorts(c *gc.C, expected []network.PortRange) {
 	s.BackingState.StartSync()
 	start := time.Now()
 	for {
@@ -80,8 +81,8 @@ func (s *FirewallerSuite) assertEnvironPorts(c *gc.C, expected []network.Port) {
 			c.Fatal(err)
 			return
 		}
-		network.SortPorts(got)
-		network.SortPorts(expected)
+		network.SortPortRanges(got)
+		network.SortPortRanges(expected)
 		if reflect.DeepEqual(got, expected) {
 			c.Succeed()
 			return
@@ -188,12 +189,12 @@ func (s *FirewallerSuite) TestExposedService(c *gc.C) {
 	err = u.OpenPort("tcp", 8080)
 	c.Assert(err, gc.IsNil)
 
-	s.assertPorts(c, inst, m.Id(), []network.Port{{"tcp", 80}, {"tcp", 8080}})
+	s.assertPorts(c, inst, m.Id(), []network.PortRange{{"tcp", 80, 80, ""}, {"tcp", 8080, 8080, ""}})
 
 	err = u.ClosePort("tcp", 80)
 	c.Assert(err, gc.IsNil)
 
-	s.assertPorts(c, inst, m.Id(), []network.Port{{"tcp", 8080}})
+	s.assertPorts(c, inst, m.Id(), []network.PortRange{{"tcp", 8080, 8080, ""}})
 }
 
 func (s *FirewallerSuite) TestMultipleExposedServices(c *gc.C) {
@@ -222,15 +223,15 @@ func (s *FirewallerSuite) TestMultipleExposedServices(c *gc.C) {
 	err = u2.OpenPort("tcp", 3306)
 	c.Assert(err, gc.IsNil)
 
-	s.assertPorts(c, inst1, m1.Id(), []network.Port{{"tcp", 80}, {"tcp", 8080}})
-	s.assertPorts(c, inst2, m2.Id(), []network.Port{{"tcp", 3306}})
+	s.assertPorts(c, inst1, m1.Id(), []network.PortRange{{"tcp", 80, 80, ""}, {"tcp", 8080, 8080, ""}})
+	s.assertPorts(c, inst2, m2.Id(), []network.PortRange{{"tcp", 3306, 3306, ""}})
 
 	err = u1.ClosePort("tcp", 80)
 	c.Assert(err, gc.IsNil)
 	err = u2.ClosePort("tcp", 3306)
 	c.Assert(err, gc.IsNil)
 
-	s.assertPorts(c, inst1, m1.Id(), []network.Port{{"tcp", 8080}})
+	s.assertPorts(c, inst1, m1.Id(), []network.PortRange{{"tcp", 8080, 8080, ""}})
 	s.assertPorts(c, inst2, m2.Id(), nil)
 }
 
@@ -251,12 +252,12 @@ func (s *FirewallerSuite) TestMachineWithoutInstanceId(c *gc.C) {
 	inst2 := s.startInstance(c, m2)
 	err = u2.OpenPort("tcp", 80)
 	c.Assert(err, gc.IsNil)
-	s.assertPorts(c, inst2, m2.Id(), []network.Port{{"tcp", 80}})
+	s.assertPorts(c, inst2, m2.Id(), []network.PortRange{{"tcp", 80, 80, ""}})
 
 	inst1 := s.startInstance(c, m1)
 	err = u1.OpenPort("tcp", 8080)
 	c.Assert(err, gc.IsNil)
-	s.assertPorts(c, inst1, m1.Id(), []network.Port{{"tcp", 8080}})
+	s.assertPorts(c, inst1, m1.Id(), []network.PortRange{{"tcp", 8080, 8080, ""}})
 }
 
 func (s *FirewallerSuite) TestMultipleUnits(c *gc.C) {
@@ -278,8 +279,8 @@ func (s *FirewallerSuite) TestMultipleUnits(c *gc.C) {
 	err = u2.OpenPort("tcp", 80)
 	c.Assert(err, gc.IsNil)
 
-	s.assertPorts(c, inst1, m1.Id(), []network.Port{{"tcp", 80}})
-	s.assertPorts(c, inst2, m2.Id(), []network.Port{{"tcp", 80}})
+	s.assertPorts(c, inst1, m1.Id(), []network.PortRange{{"tcp", 80, 80, ""}})
+	s.assertPorts(c, inst2, m2.Id(), []network.PortRange{{"tcp", 80, 80, ""}})
 
 	err = u1.ClosePort("tcp", 80)
 	c.Assert(err, gc.IsNil)
@@ -310,7 +311,7 @@ func (s *FirewallerSuite) TestStartWithState(c *gc.C) {
 	c.Assert(err, gc.IsNil)
 	defer func() { c.Assert(fw.Stop(), gc.IsNil) }()
 
-	s.assertPorts(c, inst, m.Id(), []network.Port{{"tcp", 80}, {"tcp", 8080}})
+	s.assertPorts(c, inst, m.Id(), []network.PortRange{{"tcp", 80, 80, ""}, {"tcp", 8080, 8080, ""}})
 
 	err = svc.SetExposed()
 	c.Assert(err, gc.IsNil)
@@ -340,7 +341,7 @@ func (s *FirewallerSuite) TestStartWithPartialState(c *gc.C) {
 	err = u.OpenPort("tcp", 80)
 	c.Assert(err, gc.IsNil)
 
-	s.assertPorts(c, inst, m.Id(), []network.Port{{"tcp", 80}})
+	s.assertPorts(c, inst, m.Id(), []network.PortRange{{"tcp", 80, 80, ""}})
 }
 
 func (s *FirewallerSuite) TestStartWithUnexposedService(c *gc.C) {
@@ -366,7 +367,7 @@ func (s *FirewallerSuite) TestStartWithUnexposedService(c *gc.C) {
 	// Expose service.
 	err = svc.SetExposed()
 	c.Assert(err, gc.IsNil)
-	s.assertPorts(c, inst, m.Id(), []network.Port{{"tcp", 80}})
+	s.assertPorts(c, inst, m.Id(), []network.PortRange{{"tcp", 80, 80, ""}})
 }
 
 func (s *FirewallerSuite) TestSetClearExposedService(c *gc.C) {
@@ -390,7 +391,7 @@ func (s *FirewallerSuite) TestSetClearExposedService(c *gc.C) {
 	err = svc.SetExposed()
 	c.Assert(err, gc.IsNil)
 
-	s.assertPorts(c, inst, m.Id(), []network.Port{{"tcp", 80}, {"tcp", 8080}})
+	s.assertPorts(c, inst, m.Id(), []network.PortRange{{"tcp", 80, 80, ""}, {"tcp", 8080, 8080, ""}})
 
 	// ClearExposed closes the ports again.
 	err = svc.ClearExposed()
@@ -399,6 +400,40 @@ func (s *FirewallerSuite) TestSetClearExposedService(c *gc.C) {
 	s.assertPorts(c, inst, m.Id(), nil)
 }
 
+func (s *FirewallerSuite) TestExposedToCIDRsService(c *gc.C) {
+	fw, err := firewaller.NewFirewaller(s.firewaller)
+	c.Assert(err, gc.IsNil)
+	defer func() { c.Assert(fw.Stop(), gc.IsNil) }()
+
+	svc := s.AddTestingService(c, "wordpress", s.charm)
+
+	u, m := s.addUnit(c, svc)
+	inst := s.startInstance(c, m)
+	err = u.OpenPorts("tcp", 8000, 8080)
+	c.Assert(err, gc.IsNil)
+
+	// Exposing to CIDRs opens the range once for each source.
+	err = svc.SetExposedTo([]string{"10.0.0.0/8", "192.168.1.0/24"})
+	c.Assert(err, gc.IsNil)
+
+	s.assertPorts(c, inst, m.Id(), []network.PortRange{
+		{"tcp", 8000, 8080, "10.0.0.0/8"},
+		{"tcp", 8000, 8080, "192.168.1.0/24"},
+	})
+
+	// Exposing to everyone replaces the CIDR-scoped rules.
+	err = svc.SetExposed()
+	c.Assert(err, gc.IsNil)
+
+	s.assertPorts(c, inst, m.Id(), []network.PortRange{{"tcp", 8000, 8080, ""}})
+
+	// Closing the range closes the ports.
+	err = u.ClosePorts("tcp", 8000, 8080)
+	c.Assert(err, gc.IsNil)
+
+	s.assertPorts(c, inst, m.Id(), nil)
+}
+
 func (s *FirewallerSuite) TestRemoveUnit(c *gc.C) {
 	fw, err := firewaller.NewFirewaller(s.firewaller)
 	c.Assert(err, gc.IsNil)
@@ -418,8 +453,8 @@ func (s *FirewallerSuite) TestRemoveUnit(c *gc.C) {
 	err = u2.OpenPort("tcp", 80)
 	c.Assert(err, gc.IsNil)
 
-	s.assertPorts(c, inst1, m1.Id(), []network.Port{{"tcp", 80}})
-	s.assertPorts(c, inst2, m2.Id(), []network.Port{{"tcp", 80}})
+	s.assertPorts(c, inst1, m1.Id(), []network.PortRange{{"tcp", 80, 80, ""}})
+	s.assertPorts(c, inst2, m2.Id(), []network.PortRange{{"tcp", 80, 80, ""}})
 
 	// Remove unit.
 	err = u1.EnsureDead()
@@ -428,7 +463,7 @@ func (s *FirewallerSuite) TestRemoveUnit(c *gc.C) {
 	c.Assert(err, gc.IsNil)
 
 	s.assertPorts(c, inst1, m1.Id(), nil)
-	s.assertPorts(c, inst2, m2.Id(), []network.Port{{"tcp", 80}})
+	s.assertPorts(c, inst2, m2.Id(), []network.PortRange{{"tcp", 80, 80, ""}})
 }
 
 func (s *FirewallerSuite) TestRemoveService(c *gc.C) {
@@ -445,7 +480,7 @@ func (s *FirewallerSuite) TestRemoveService(c *gc.C) {
 	err = u.OpenPort("tcp", 80)
 	c.Assert(err, gc.IsNil)
 
-	s.assertPorts(c, inst, m.Id(), []network.Port{{"tcp", 80}})
+	s.assertPorts(c, inst, m.Id(), []network.PortRange{{"tcp", 80, 80, ""}})
 
 	// Remove service.
 	err = u.EnsureDead()
@@ -480,8 +515,8 @@ func (s *FirewallerSuite) TestRemoveMultipleServices(c *gc.C) {
 	err = u2.OpenPort("tcp", 3306)
 	c.Assert(err, gc.IsNil)
 
-	s.assertPorts(c, inst1, m1.Id(), []network.Port{{"tcp", 80}})
-	s.assertPorts(c, inst2, m2.Id(), []network.Port{{"tcp", 3306}})
+	s.assertPorts(c, inst1, m1.Id(), []network.PortRange{{"tcp", 80, 80, ""}})
+	s.assertPorts(c, inst2, m2.Id(), []network.PortRange{{"tcp", 3306, 3306, ""}})
 
 	// Remove services.
 	err = u2.EnsureDead()
@@ -516,7 +551,7 @@ func (s *FirewallerSuite) TestDeadMachine(c *gc.C) {
 	err = u.OpenPort("tcp", 80)
 	c.Assert(err, gc.IsNil)
 
-	s.assertPorts(c, inst, m.Id(), []network.Port{{"tcp", 80}})
+	s.assertPorts(c, inst, m.Id(), []network.PortRange{{"tcp", 80, 80, ""}})
 
 	// Remove unit and service, also tested without. Has no effect.
 	err = u.EnsureDead()
@@ -549,7 +584,7 @@ func (s *FirewallerSuite) TestRemoveMachine(c *gc.C) {
 	err = u.OpenPort("tcp", 80)
 	c.Assert(err, gc.IsNil)
 
-	s.assertPorts(c, inst, m.Id(), []network.Port{{"tcp", 80}})
+	s.assertPorts(c, inst, m.Id(), []network.PortRange{{"tcp", 80, 80, ""}})
 
 	// Remove unit.
 	err = u.EnsureDead()
@@ -595,17 +630,17 @@ func (s *FirewallerGlobalModeSuite) TestGlobalMode(c *gc.C) {
 	err = u2.OpenPort("tcp", 80)
 	c.Assert(err, gc.IsNil)
 
-	s.assertEnvironPorts(c, []network.Port{{"tcp", 80}, {"tcp", 8080}})
+	s.assertEnvironPorts(c, []network.PortRange{{"tcp", 80, 80, ""}, {"tcp", 8080, 8080, ""}})
 
 	// Closing a port opened by a different unit won't touch the environment.
 	err = u1.ClosePort("tcp", 80)
 	c.Assert(err, gc.IsNil)
-	s.assertEnvironPorts(c, []network.Port{{"tcp", 80}, {"tcp", 8080}})
+	s.assertEnvironPorts(c, []network.PortRange{{"tcp", 80, 80, ""}, {"tcp", 8080, 8080, ""}})
 
 	// Closing a port used just once changes the environment.
 	err = u1.ClosePort("tcp", 8080)
 	c.Assert(err, gc.IsNil)
-	s.assertEnvironPorts(c, []network.Port{{"tcp", 80}})
+	s.assertEnvironPorts(c, []network.PortRange{{"tcp", 80, 80, ""}})
 
 	// Closing the last port also modifies the environment.
 	err = u2.ClosePort("tcp", 80)
@@ -636,7 +671,7 @@ func (s *FirewallerGlobalModeSuite) TestGlobalModeStartWithUnexposedService(c *g
 	// Expose service.
 	err = svc.SetExposed()
 	c.Assert(err, gc.IsNil)
-	s.assertEnvironPorts(c, []network.Port{{"tcp", 80}})
+	s.assertEnvironPorts(c, []network.PortRange{{"tcp", 80, 80, ""}})
 }
 
 func (s *FirewallerGlobalModeSuite) TestGlobalModeRestart(c *gc.C) {
@@ -655,7 +690,7 @@ func (s *FirewallerGlobalModeSuite) TestGlobalModeRestart(c *gc.C) {
 	err = u.OpenPort("tcp", 8080)
 	c.Assert(err, gc.IsNil)
 
-	s.assertEnvironPorts(c, []network.Port{{"tcp", 80}, {"tcp", 8080}})
+	s.assertEnvironPorts(c, []network.PortRange{{"tcp", 80, 80, ""}, {"tcp", 8080, 8080, ""}})
 
 	// Stop firewaller and close one and open a different port.
 	err = fw.Stop()
@@ -671,7 +706,7 @@ func (s *FirewallerGlobalModeSuite) TestGlobalModeRestart(c *gc.C) {
 	c.Assert(err, gc.IsNil)
 	defer func() { c.Assert(fw.Stop(), gc.IsNil) }()
 
-	s.assertEnvironPorts(c, []network.Port{{"tcp", 80}, {"tcp", 8888}})
+	s.assertEnvironPorts(c, []network.PortRange{{"tcp", 80, 80, ""}, {"tcp", 8888, 8888, ""}})
 }
 
 func (s *FirewallerGlobalModeSuite) TestGlobalModeRestartUnexposedService(c *gc.C) {
@@ -690,7 +725,7 @@ func (s *FirewallerGlobalModeSuite) TestGlobalModeRestartUnexposedService(c *gc.
 	err = u.OpenPort("tcp", 8080)
 	c.Assert(err, gc.IsNil)
 
-	s.assertEnvironPorts(c, []network.Port{{"tcp", 80}, {"tcp", 8080}})
+	s.assertEnvironPorts(c, []network.PortRange{{"tcp", 80, 80, ""}, {"tcp", 8080, 8080, ""}})
 
 	// Stop firewaller and clear exposed flag on service.
 	err = fw.Stop()
@@ -723,7 +758,7 @@ func (s *FirewallerGlobalModeSuite) TestGlobalModeRestartPortCount(c *gc.C) {
 	err = u1.OpenPort("tcp", 8080)
 	c.Assert(err, gc.IsNil)
 
-	s.assertEnvironPorts(c, []network.Port{{"tcp", 80}, {"tcp", 8080}})
+	s.assertEnvironPorts(c, []network.PortRange{{"tcp", 80, 80, ""}, {"tcp", 8080, 8080, ""}})
 
 	// Stop firewaller and add another service using the port.
 	err = fw.Stop()
@@ -743,20 +778,74 @@ func (s *FirewallerGlobalModeSuite) TestGlobalModeRestartPortCount(c *gc.C) {
 	c.Assert(err, gc.IsNil)
 	defer func() { c.Assert(fw.Stop(), gc.IsNil) }()
 
-	s.assertEnvironPorts(c, []network.Port{{"tcp", 80}, {"tcp", 8080}})
+	s.assertEnvironPorts(c, []network.PortRange{{"tcp", 80, 80, ""}, {"tcp", 8080, 8080, ""}})
 
 	// Closing a port opened by a different unit won't touch the environment.
 	err = u1.ClosePort("tcp", 80)
 	c.Assert(err, gc.IsNil)
-	s.assertEnvironPorts(c, []network.Port{{"tcp", 80}, {"tcp", 8080}})
+	s.assertEnvironPorts(c, []network.PortRange{{"tcp", 80, 80, ""}, {"tcp", 8080, 8080, ""}})
 
 	// Closing a port used just once changes the environment.
 	err = u1.ClosePort("tcp", 8080)
 	c.Assert(err, gc.IsNil)
-	s.assertEnvironPorts(c, []network.Port{{"tcp", 80}})
+	s.assertEnvironPorts(c, []network.PortRange{{"tcp", 80, 80, ""}})
 
 	// Closing the last port also modifies the environment.
 	err = u2.ClosePort("tcp", 80)
 	c.Assert(err, gc.IsNil)
 	s.assertEnvironPorts(c, nil)
 }
+
+type applyPortsSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&applyPortsSuite{})
+
+// noCIDRPorts mimics a provider that cannot restrict
+// access to port ranges by source CIDR.
+type noCIDRPorts struct {
+	calls [][]network.PortRange
+}
+
+func (p *noCIDRPorts) apply(ports []network.PortRange) error {
+	p.calls = append(p.calls, ports)
+	for _, port := range ports {
+		if port.SourceCIDR != "" {
+			return errors.NotSupportedf("opening ports to source CIDR %q", port.SourceCIDR)
+		}
+	}
+	return nil
+}
+
+func (s *applyPortsSuite) TestApplyPortsSkipsUnsupportedCIDRs(c *gc.C) {
+	var p noCIDRPorts
+	ports := []network.PortRange{
+		{"tcp", 80, 80, "10.0.0.0/8"},
+		{"tcp", 443, 443, ""},
+	}
+	applied, err := firewaller.ApplyPorts(p.apply, ports)
+	c.Assert(err, gc.IsNil)
+	c.Assert(applied, gc.DeepEquals, []network.PortRange{{"tcp", 443, 443, ""}})
+	c.Assert(p.calls, gc.DeepEquals, [][]network.PortRange{ports, applied})
+
+	p.calls = nil
+	applied, err = firewaller.ApplyPorts(p.apply, ports[:1])
+	c.Assert(err, gc.IsNil)
+	c.Assert(applied, gc.HasLen, 0)
+	c.Assert(p.calls, gc.HasLen, 1)
+}
+
+func (s *applyPortsSuite) TestApplyPortsOtherErrors(c *gc.C) {
+	apply := func([]network.PortRange) error {
+		return errors.NotSupportedf("opening ports")
+	}
+	_, err := firewaller.ApplyPorts(apply, []network.PortRange{{"tcp", 80, 80, ""}})
+	c.Assert(err, gc.ErrorMatches, "opening ports not supported")
+
+	apply = func([]network.PortRange) error {
+		return errors.New("boom")
+	}
+	_, err = firewaller.ApplyPorts(apply, []network.PortRange{{"tcp", 80, 80, "10.0.0.0/8"}})
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
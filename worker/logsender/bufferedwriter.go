@@ -0,0 +1,86 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logsender
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/state/api/params"
+)
+
+// BufferedLogWriter is a loggo.Writer that holds log records until
+// they are sent to the API server by the logsender worker. When the
+// buffer is full, further records are discarded rather than blocking
+// the code doing the logging.
+type BufferedLogWriter struct {
+	records chan *params.LogRecord
+	dropped uint64
+}
+
+var _ loggo.Writer = (*BufferedLogWriter)(nil)
+
+// NewBufferedLogWriter returns a BufferedLogWriter that holds
+// at most maxLen records.
+func NewBufferedLogWriter(maxLen int) *BufferedLogWriter {
+	return &BufferedLogWriter{
+		records: make(chan *params.LogRecord, maxLen),
+	}
+}
+
+// quietModules holds the logging modules whose records below INFO are
+// not sent. The API server and RPC layer log every request at DEBUG,
+// including the requests carrying log records, so sending them would
+// make a state server feed its own log ingestion back into itself.
+var quietModules = []string{
+	"juju.rpc",
+	"juju.state.apiserver",
+	"juju.worker.logsender",
+}
+
+// isQuiet reports whether records below INFO
+// from the given module should not be sent.
+func isQuiet(module string) bool {
+	for _, quiet := range quietModules {
+		if module == quiet || strings.HasPrefix(module, quiet+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Write implements loggo.Writer.
+func (w *BufferedLogWriter) Write(level loggo.Level, module, filename string, line int, timestamp time.Time, message string) {
+	if level < loggo.INFO && isQuiet(module) {
+		return
+	}
+	record := &params.LogRecord{
+		Time:     timestamp,
+		Module:   module,
+		Location: fmt.Sprintf("%s:%d", filename, line),
+		Level:    level,
+		Message:  message,
+	}
+	select {
+	case w.records <- record:
+	default:
+		atomic.AddUint64(&w.dropped, 1)
+	}
+}
+
+// Logs returns the channel on which buffered records are received.
+// The records' Entity field is not set.
+func (w *BufferedLogWriter) Logs() <-chan *params.LogRecord {
+	return w.records
+}
+
+// Dropped returns the number of records discarded
+// because the buffer was full.
+func (w *BufferedLogWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
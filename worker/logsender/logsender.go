@@ -0,0 +1,67 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logsender
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.logsender")
+
+// maxBatchSize holds the maximum number of records
+// sent to the API server in a single call.
+const maxBatchSize = 512
+
+// LogWriter is the part of the logger API used by the worker.
+type LogWriter interface {
+	WriteLogs(records []params.LogRecord) error
+}
+
+// New returns a worker that sends the log records received on logs to
+// the API server, so that they are stored in state and can be viewed
+// with debug-log. The records are sent as having been logged by the
+// entity with the given tag.
+func New(logs <-chan *params.LogRecord, api LogWriter, entity string) worker.Worker {
+	return worker.NewSimpleWorker(func(stop <-chan struct{}) error {
+		batch := make([]params.LogRecord, 0, maxBatchSize)
+		for {
+			select {
+			case <-stop:
+				return nil
+			case record := <-logs:
+				batch = append(batch[:0], *record)
+			}
+			// Gather whatever else is already waiting,
+			// so that we send fewer, larger batches.
+		gather:
+			for len(batch) < maxBatchSize {
+				select {
+				case record := <-logs:
+					batch = append(batch, *record)
+				default:
+					break gather
+				}
+			}
+			for i := range batch {
+				batch[i].Entity = entity
+			}
+			err := api.WriteLogs(batch)
+			if params.IsCodeNotImplemented(err) {
+				// The state server is too old to store log
+				// records; the logs are still written to the
+				// local log file, so just wait to be stopped.
+				logger.Warningf("log records not supported by state server: %v", err)
+				<-stop
+				return nil
+			}
+			if err != nil {
+				return errors.Annotate(err, "cannot send log records")
+			}
+		}
+	})
+}
@@ -0,0 +1,143 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logsender_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state/api/params"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/logsender"
+)
+
+type logSenderSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&logSenderSuite{})
+
+type fakeLogWriter struct {
+	batches chan []params.LogRecord
+	err     error
+}
+
+func (w *fakeLogWriter) WriteLogs(records []params.LogRecord) error {
+	batch := make([]params.LogRecord, len(records))
+	copy(batch, records)
+	w.batches <- batch
+	return w.err
+}
+
+func newFakeLogWriter(err error) *fakeLogWriter {
+	return &fakeLogWriter{
+		batches: make(chan []params.LogRecord, 10),
+		err:     err,
+	}
+}
+
+func (w *fakeLogWriter) nextBatch(c *gc.C) []params.LogRecord {
+	select {
+	case batch := <-w.batches:
+		return batch
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for log records to be sent")
+	}
+	panic("unreachable")
+}
+
+func (s *logSenderSuite) TestBufferedLogWriter(c *gc.C) {
+	w := logsender.NewBufferedLogWriter(2)
+	now := time.Now()
+	w.Write(loggo.INFO, "juju.worker", "worker.go", 42, now, "first")
+	w.Write(loggo.DEBUG, "juju.worker", "worker.go", 43, now, "second")
+	w.Write(loggo.ERROR, "juju.worker", "worker.go", 44, now, "dropped")
+
+	c.Assert(w.Dropped(), gc.Equals, uint64(1))
+	c.Assert(<-w.Logs(), jc.DeepEquals, &params.LogRecord{
+		Time:     now,
+		Module:   "juju.worker",
+		Location: "worker.go:42",
+		Level:    loggo.INFO,
+		Message:  "first",
+	})
+	c.Assert((<-w.Logs()).Message, gc.Equals, "second")
+}
+
+func (s *logSenderSuite) TestBufferedLogWriterSkipsAPIServerDebug(c *gc.C) {
+	w := logsender.NewBufferedLogWriter(10)
+	now := time.Now()
+	w.Write(loggo.DEBUG, "juju.state.apiserver", "apiserver.go", 1, now, "request")
+	w.Write(loggo.TRACE, "juju.rpc.jsoncodec", "codec.go", 2, now, "body")
+	w.Write(loggo.DEBUG, "juju.worker.logsender", "logsender.go", 3, now, "sent")
+	w.Write(loggo.WARNING, "juju.state.apiserver", "apiserver.go", 4, now, "warning")
+	w.Write(loggo.DEBUG, "juju.state.apiserverx", "other.go", 5, now, "other")
+
+	c.Assert((<-w.Logs()).Message, gc.Equals, "warning")
+	c.Assert((<-w.Logs()).Message, gc.Equals, "other")
+	select {
+	case r := <-w.Logs():
+		c.Fatalf("unexpected record %#v", r)
+	default:
+	}
+}
+
+func (s *logSenderSuite) TestSendsRecords(c *gc.C) {
+	logs := make(chan *params.LogRecord, 10)
+	for _, message := range []string{"one", "two", "three"} {
+		logs <- &params.LogRecord{Module: "juju", Level: loggo.INFO, Message: message}
+	}
+	api := newFakeLogWriter(nil)
+	w := logsender.New(logs, api, "machine-1")
+	defer func() {
+		w.Kill()
+		c.Assert(w.Wait(), gc.IsNil)
+	}()
+
+	batch := api.nextBatch(c)
+	c.Assert(batch, gc.HasLen, 3)
+	for i, message := range []string{"one", "two", "three"} {
+		c.Check(batch[i].Entity, gc.Equals, "machine-1")
+		c.Check(batch[i].Message, gc.Equals, message)
+	}
+
+	logs <- &params.LogRecord{Module: "juju", Level: loggo.INFO, Message: "four"}
+	batch = api.nextBatch(c)
+	c.Assert(batch, gc.HasLen, 1)
+	c.Assert(batch[0].Message, gc.Equals, "four")
+}
+
+func (s *logSenderSuite) TestSendError(c *gc.C) {
+	logs := make(chan *params.LogRecord, 1)
+	logs <- &params.LogRecord{Module: "juju", Level: loggo.INFO, Message: "one"}
+	api := newFakeLogWriter(errors.New("boom"))
+	w := logsender.New(logs, api, "machine-1")
+	api.nextBatch(c)
+	c.Assert(w.Wait(), gc.ErrorMatches, "cannot send log records: boom")
+}
+
+func (s *logSenderSuite) TestNotImplemented(c *gc.C) {
+	logs := make(chan *params.LogRecord, 1)
+	logs <- &params.LogRecord{Module: "juju", Level: loggo.INFO, Message: "one"}
+	api := newFakeLogWriter(&params.Error{
+		Message: "no such request",
+		Code:    params.CodeNotImplemented,
+	})
+	w := logsender.New(logs, api, "machine-1")
+	api.nextBatch(c)
+
+	// The worker keeps running without sending anything else.
+	logs <- &params.LogRecord{Module: "juju", Level: loggo.INFO, Message: "two"}
+	select {
+	case batch := <-api.batches:
+		c.Fatalf("unexpected batch %#v", batch)
+	case <-time.After(coretesting.ShortWait):
+	}
+	w.Kill()
+	c.Assert(w.Wait(), gc.IsNil)
+}
@@ -0,0 +1,44 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package metricworker defines a worker that periodically removes
+// the metrics reported by units once they have been exported and
+// are old enough to be expired.
+package metricworker
+
+import (
+	"time"
+
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.metricworker")
+
+// cleanupInterval sets how often old metrics are cleaned up.
+var cleanupInterval = time.Hour
+
+// MetricsCleanupState holds the methods of the MetricsManager API
+// facade used by the worker.
+type MetricsCleanupState interface {
+	CleanupOldMetrics() error
+}
+
+// NewCleanupWorker returns a worker that periodically removes old
+// metrics that have been exported. Failures are logged, and the
+// cleanup is tried again at the next interval.
+func NewCleanupWorker(st MetricsCleanupState) worker.Worker {
+	return worker.NewSimpleWorker(func(stop <-chan struct{}) error {
+		for {
+			if err := st.CleanupOldMetrics(); err != nil {
+				logger.Errorf("cannot clean up old metrics: %v", err)
+			}
+			select {
+			case <-stop:
+				return nil
+			case <-time.After(cleanupInterval):
+			}
+		}
+	})
+}
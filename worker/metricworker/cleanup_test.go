@@ -0,0 +1,75 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package metricworker_test
+
+import (
+	"fmt"
+	stdtesting "testing"
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/metricworker"
+)
+
+func TestPackage(t *stdtesting.T) {
+	gc.TestingT(t)
+}
+
+type cleanupSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&cleanupSuite{})
+
+// fakeState implements metricworker.MetricsCleanupState, reporting
+// cleanups on a channel.
+type fakeState struct {
+	cleanups chan struct{}
+	err      error
+}
+
+func newFakeState(err error) *fakeState {
+	return &fakeState{
+		cleanups: make(chan struct{}, 1),
+		err:      err,
+	}
+}
+
+func (st *fakeState) CleanupOldMetrics() error {
+	select {
+	case st.cleanups <- struct{}{}:
+	default:
+	}
+	return st.err
+}
+
+func (s *cleanupSuite) waitCleanup(c *gc.C, st *fakeState) {
+	select {
+	case <-st.cleanups:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("metrics not cleaned up")
+	}
+}
+
+func (s *cleanupSuite) TestCleanupPeriodically(c *gc.C) {
+	s.PatchValue(metricworker.CleanupInterval, 10*time.Millisecond)
+	st := newFakeState(nil)
+	w := metricworker.NewCleanupWorker(st)
+	defer func() { c.Assert(worker.Stop(w), gc.IsNil) }()
+	for i := 0; i < 3; i++ {
+		s.waitCleanup(c, st)
+	}
+}
+
+func (s *cleanupSuite) TestCleanupErrorDoesNotStopWorker(c *gc.C) {
+	s.PatchValue(metricworker.CleanupInterval, 10*time.Millisecond)
+	st := newFakeState(fmt.Errorf("boom"))
+	w := metricworker.NewCleanupWorker(st)
+	defer func() { c.Assert(worker.Stop(w), gc.IsNil) }()
+	s.waitCleanup(c, st)
+	s.waitCleanup(c, st)
+}
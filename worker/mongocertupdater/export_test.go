@@ -0,0 +1,11 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package mongocertupdater
+
+var (
+	RestartLeaseName    = restartLeaseName
+	RestartLeaseAttempt = &restartLeaseAttempt
+	HealthyAttempt      = &healthyAttempt
+	ReplicaSetStatus    = &replicaSetStatus
+)
@@ -0,0 +1,185 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package mongocertupdater
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+	"github.com/juju/utils"
+
+	"github.com/juju/juju/replicaset"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/api/watcher"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.mongocertupdater")
+
+// restartLeaseName is the name of the state server lease that is held
+// while a state server restarts its mongo server, so that the replica
+// set never loses more than one member at a time.
+const restartLeaseName = "mongo-restart"
+
+var (
+	// restartLeaseDuration is how long the restart lease is held
+	// before another state server may take it over, should its
+	// holder fail to release it.
+	restartLeaseDuration = 10 * time.Minute
+
+	// restartLeaseAttempt is used to wait for another state server
+	// to release the restart lease.
+	restartLeaseAttempt = utils.AttemptStrategy{
+		Total: 30 * time.Minute,
+		Delay: 5 * time.Second,
+	}
+
+	// healthyAttempt is used to wait for the replica set to
+	// recover once mongo has been restarted.
+	healthyAttempt = utils.AttemptStrategy{
+		Total: 5 * time.Minute,
+		Delay: 5 * time.Second,
+	}
+
+	// replicaSetStatus returns the status of the replica set
+	// that st is connected to.
+	replicaSetStatus = func(st *state.State) (*replicaset.Status, error) {
+		session := st.MongoSession().Copy()
+		defer session.Close()
+		return replicaset.CurrentStatus(session)
+	}
+)
+
+// RestartMongoFunc restarts the local mongo server
+// so that it serves the certificate in the given info.
+type RestartMongoFunc func(info params.StateServingInfo) error
+
+// MongoCertUpdater restarts the mongo server on a state server
+// machine when the state server certificate changes, and records
+// the certificate mongo serves so that CAs which no longer sign it
+// can stop being trusted.
+type MongoCertUpdater struct {
+	st        *state.State
+	machineId string
+	cert      string
+	restart   RestartMongoFunc
+}
+
+// NewMongoCertUpdater returns a worker.Worker that watches the state
+// server certificate on behalf of the mongo server on the machine
+// with the given id, which was started with the given certificate.
+func NewMongoCertUpdater(st *state.State, machineId, cert string, restart RestartMongoFunc) worker.Worker {
+	return worker.NewNotifyWorker(&MongoCertUpdater{
+		st:        st,
+		machineId: machineId,
+		cert:      cert,
+		restart:   restart,
+	})
+}
+
+func (u *MongoCertUpdater) SetUp() (watcher.NotifyWatcher, error) {
+	return u.st.WatchStateServingInfo(), nil
+}
+
+func (u *MongoCertUpdater) Handle() error {
+	info, err := u.st.StateServingInfo()
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cannot get state serving info: %v", err)
+	}
+	if info.Cert != u.cert {
+		if err := u.restartMongo(info); err != nil {
+			return err
+		}
+		u.cert = info.Cert
+	}
+	// The certificate is recorded on every change, not just
+	// after a restart, so that it is recorded even if the
+	// state connection was lost when mongo last restarted.
+	if err := u.st.SetMongoCert(u.machineId, u.cert); err != nil {
+		return fmt.Errorf("cannot record mongo certificate: %v", err)
+	}
+	return nil
+}
+
+func (u *MongoCertUpdater) TearDown() error {
+	return nil
+}
+
+// restartMongo restarts mongo so that it serves the certificate in
+// the given info. Only one state server restarts its mongo at a time,
+// while it holds the restart lease, and the lease is not released
+// until the replica set is healthy again.
+func (u *MongoCertUpdater) restartMongo(info params.StateServingInfo) error {
+	holder := names.NewMachineTag(u.machineId).String()
+	if err := u.claimRestartLease(holder); err != nil {
+		return err
+	}
+	defer func() {
+		if err := u.st.ReleaseServerLease(restartLeaseName, holder); err != nil {
+			logger.Errorf("cannot release mongo restart lease: %v", err)
+		}
+	}()
+	logger.Infof("state server certificate changed; restarting mongo")
+	if err := u.restart(info); err != nil {
+		return fmt.Errorf("cannot restart mongo: %v", err)
+	}
+	return u.waitReplicaSetHealthy()
+}
+
+// claimRestartLease waits until the restart lease can be claimed
+// for holder, and claims it.
+func (u *MongoCertUpdater) claimRestartLease(holder string) error {
+	for a := restartLeaseAttempt.Start(); a.Next(); {
+		err := u.st.ClaimServerLease(restartLeaseName, holder, restartLeaseDuration)
+		if err != state.ErrServerLeaseHeld {
+			return err
+		}
+		logger.Infof("waiting for another state server to restart mongo")
+	}
+	return fmt.Errorf("timed out waiting for the mongo restart lease")
+}
+
+// waitReplicaSetHealthy waits until every member of the replica set
+// is up and either primary or secondary.
+func (u *MongoCertUpdater) waitReplicaSetHealthy() error {
+	var err error
+	for a := healthyAttempt.Start(); a.Next(); {
+		var status *replicaset.Status
+		status, err = replicaSetStatus(u.st)
+		if err == nil {
+			err = checkReplicaSetHealthy(status)
+		}
+		if err == nil {
+			return nil
+		}
+		logger.Debugf("waiting for replica set to recover: %v", err)
+	}
+	return fmt.Errorf("replica set not healthy after restarting mongo: %v", err)
+}
+
+func checkReplicaSetHealthy(status *replicaset.Status) error {
+	hasPrimary := false
+	for _, member := range status.Members {
+		if !member.Healthy {
+			return fmt.Errorf("member %q is down", member.Address)
+		}
+		switch member.State {
+		case replicaset.PrimaryState:
+			hasPrimary = true
+		case replicaset.SecondaryState, replicaset.ArbiterState:
+		default:
+			return fmt.Errorf("member %q is %v", member.Address, member.State)
+		}
+	}
+	if !hasPrimary {
+		return fmt.Errorf("no primary")
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package mongocertupdater_test
+
+import (
+	"fmt"
+	stdtesting "testing"
+	"time"
+
+	"github.com/juju/utils"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/cert"
+	"github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/replicaset"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api/params"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/mongocertupdater"
+)
+
+func TestPackage(t *stdtesting.T) {
+	coretesting.MgoTestPackage(t)
+}
+
+type MongoCertUpdaterSuite struct {
+	testing.JujuConnSuite
+	status *replicaset.Status
+}
+
+var _ = gc.Suite(&MongoCertUpdaterSuite{})
+
+func (s *MongoCertUpdaterSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	// The test mongo server is not a replica set.
+	s.status = &replicaset.Status{
+		Members: []replicaset.MemberStatus{{
+			Address: "0.1.2.3:37017",
+			Healthy: true,
+			State:   replicaset.PrimaryState,
+		}},
+	}
+	s.PatchValue(mongocertupdater.ReplicaSetStatus, func(*state.State) (*replicaset.Status, error) {
+		return s.status, nil
+	})
+	s.PatchValue(mongocertupdater.RestartLeaseAttempt, utils.AttemptStrategy{
+		Total: coretesting.LongWait,
+		Delay: coretesting.ShortWait,
+	})
+	s.PatchValue(mongocertupdater.HealthyAttempt, utils.AttemptStrategy{
+		Total: coretesting.ShortWait,
+		Delay: time.Millisecond,
+	})
+}
+
+// changeCert sets up the state serving info with the test server
+// certificate, then starts an updater that sends the info it restarts
+// mongo with on the returned channel, and changes the certificate.
+func (s *MongoCertUpdaterSuite) changeCert(c *gc.C) (worker.Worker, chan params.StateServingInfo) {
+	info := params.StateServingInfo{
+		APIPort:    1234,
+		StatePort:  4321,
+		Cert:       coretesting.ServerCert,
+		PrivateKey: coretesting.ServerKey,
+	}
+	err := s.State.SetStateServingInfo(info)
+	c.Assert(err, gc.IsNil)
+	m, err := s.State.AddMachine("quantal", state.JobManageEnviron)
+	c.Assert(err, gc.IsNil)
+	restarted := make(chan params.StateServingInfo, 1)
+	u := mongocertupdater.NewMongoCertUpdater(s.State, m.Id(), coretesting.ServerCert, func(info params.StateServingInfo) error {
+		restarted <- info
+		return nil
+	})
+	info.Cert, info.PrivateKey, err = cert.NewServer(coretesting.CACert, coretesting.CAKey, time.Now().AddDate(1, 0, 0), nil)
+	c.Assert(err, gc.IsNil)
+	err = s.State.SetStateServingInfo(info)
+	c.Assert(err, gc.IsNil)
+	s.State.StartSync()
+	return u, restarted
+}
+
+var _ worker.NotifyWatchHandler = (*mongocertupdater.MongoCertUpdater)(nil)
+
+func (s *MongoCertUpdaterSuite) TestRestartsMongoWhenCertChanges(c *gc.C) {
+	info := params.StateServingInfo{
+		APIPort:    1234,
+		StatePort:  4321,
+		Cert:       coretesting.ServerCert,
+		PrivateKey: coretesting.ServerKey,
+	}
+	err := s.State.SetStateServingInfo(info)
+	c.Assert(err, gc.IsNil)
+	caCert, caKey, err := cert.NewCA("new", time.Now().AddDate(1, 0, 0))
+	c.Assert(err, gc.IsNil)
+	err = s.State.SetCACert(caCert + coretesting.CACert)
+	c.Assert(err, gc.IsNil)
+	m, err := s.State.AddMachine("quantal", state.JobManageEnviron)
+	c.Assert(err, gc.IsNil)
+
+	restarted := make(chan params.StateServingInfo, 1)
+	u := mongocertupdater.NewMongoCertUpdater(s.State, m.Id(), coretesting.ServerCert, func(info params.StateServingInfo) error {
+		restarted <- info
+		return nil
+	})
+	defer func() { c.Assert(worker.Stop(u), gc.IsNil) }()
+
+	info.Cert, info.PrivateKey, err = cert.NewServer(caCert, caKey, time.Now().AddDate(1, 0, 0), nil)
+	c.Assert(err, gc.IsNil)
+	err = s.State.SetStateServingInfo(info)
+	c.Assert(err, gc.IsNil)
+	s.State.StartSync()
+	select {
+	case got := <-restarted:
+		c.Assert(got.Cert, gc.Equals, info.Cert)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for mongo to restart")
+	}
+
+	// Once the only state server's mongo serves the
+	// new certificate, the old CA is no longer trusted.
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		if s.State.CACert() == caCert {
+			break
+		}
+		if !a.HasNext() {
+			c.Fatalf("old CA still trusted")
+		}
+	}
+}
+
+func (s *MongoCertUpdaterSuite) TestWaitsForRestartLease(c *gc.C) {
+	err := s.State.ClaimServerLease(mongocertupdater.RestartLeaseName, "machine-42", time.Hour)
+	c.Assert(err, gc.IsNil)
+	u, restarted := s.changeCert(c)
+	defer func() { c.Assert(worker.Stop(u), gc.IsNil) }()
+
+	select {
+	case <-restarted:
+		c.Fatalf("mongo restarted while another state server holds the lease")
+	case <-time.After(coretesting.ShortWait):
+	}
+	err = s.State.ReleaseServerLease(mongocertupdater.RestartLeaseName, "machine-42")
+	c.Assert(err, gc.IsNil)
+	select {
+	case <-restarted:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for mongo to restart")
+	}
+
+	// The lease is released once mongo has restarted.
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		err := s.State.ClaimServerLease(mongocertupdater.RestartLeaseName, "machine-42", time.Hour)
+		if err == nil {
+			return
+		}
+		c.Assert(err, gc.Equals, state.ErrServerLeaseHeld)
+	}
+	c.Fatalf("restart lease not released")
+}
+
+func (s *MongoCertUpdaterSuite) TestFailsWhenReplicaSetUnhealthy(c *gc.C) {
+	s.status.Members = append(s.status.Members, replicaset.MemberStatus{
+		Address: "0.1.2.4:37017",
+		Healthy: false,
+		State:   replicaset.DownState,
+	})
+	u, restarted := s.changeCert(c)
+	select {
+	case <-restarted:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for mongo to restart")
+	}
+	err := u.Wait()
+	c.Assert(err, gc.ErrorMatches, `replica set not healthy after restarting mongo: member "0.1.2.4:37017" is down`)
+
+	// The lease is released even though the restart failed.
+	err = s.State.ClaimServerLease(mongocertupdater.RestartLeaseName, "machine-42", time.Hour)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *MongoCertUpdaterSuite) TestFailsWhenReplicaSetStatusUnavailable(c *gc.C) {
+	s.PatchValue(mongocertupdater.ReplicaSetStatus, func(*state.State) (*replicaset.Status, error) {
+		return nil, fmt.Errorf("no reachable servers")
+	})
+	u, _ := s.changeCert(c)
+	err := u.Wait()
+	c.Assert(err, gc.ErrorMatches, `replica set not healthy after restarting mongo: no reachable servers`)
+}
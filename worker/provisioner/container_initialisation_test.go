@@ -15,6 +15,8 @@ import (
 
 	"github.com/juju/juju/agent"
 	"github.com/juju/juju/container"
+	"github.com/juju/juju/container/lxd"
+	"github.com/juju/juju/container/lxd/lxdtest"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/state"
@@ -33,6 +35,7 @@ type ContainerSetupSuite struct {
 	aptCmdChan  <-chan *exec.Cmd
 	initLockDir string
 	initLock    *fslock.Lock
+	lxdServer   *lxdtest.Server
 }
 
 var _ = gc.Suite(&ContainerSetupSuite{})
@@ -68,10 +71,16 @@ func (s *ContainerSetupSuite) SetUpTest(c *gc.C) {
 	initLock, err := fslock.NewLock(s.initLockDir, "container-init")
 	c.Assert(err, gc.IsNil)
 	s.initLock = initLock
+
+	// Initialising LXD talks to the LXD server.
+	s.lxdServer, err = lxdtest.NewServer()
+	c.Assert(err, gc.IsNil)
+	s.PatchValue(&lxd.SocketPath, s.lxdServer.SocketPath())
 }
 
 func (s *ContainerSetupSuite) TearDownTest(c *gc.C) {
 	stop(c, s.p)
+	s.lxdServer.Close()
 	s.CommonProvisionerSuite.TearDownTest(c)
 }
 
@@ -146,7 +155,7 @@ func (s *ContainerSetupSuite) TestContainerProvisionerStarted(c *gc.C) {
 			Constraints: s.defaultConstraints,
 		})
 		c.Assert(err, gc.IsNil)
-		err = m.SetSupportedContainers([]instance.ContainerType{instance.LXC, instance.KVM})
+		err = m.SetSupportedContainers(instance.ContainerTypes)
 		c.Assert(err, gc.IsNil)
 		err = m.SetAgentVersion(version.Current)
 		c.Assert(err, gc.IsNil)
@@ -181,7 +190,7 @@ func (s *ContainerSetupSuite) assertContainerInitialised(c *gc.C, ctype instance
 		Constraints: s.defaultConstraints,
 	})
 	c.Assert(err, gc.IsNil)
-	err = m.SetSupportedContainers([]instance.ContainerType{instance.LXC, instance.KVM})
+	err = m.SetSupportedContainers(instance.ContainerTypes)
 	c.Assert(err, gc.IsNil)
 	err = m.SetAgentVersion(version.Current)
 	c.Assert(err, gc.IsNil)
@@ -204,6 +213,7 @@ func (s *ContainerSetupSuite) TestContainerInitialised(c *gc.C) {
 	}{
 		{instance.LXC, []string{"--target-release", "precise-updates/cloud-tools", "lxc", "cloud-image-utils"}},
 		{instance.KVM, []string{"uvtool-libvirt", "uvtool"}},
+		{instance.LXD, []string{"lxd"}},
 	} {
 		s.assertContainerInitialised(c, test.ctype, test.packages)
 	}
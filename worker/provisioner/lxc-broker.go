@@ -87,10 +87,17 @@ func (broker *lxcBroker) StartInstance(args environs.StartInstanceParams) (insta
 		config.SSLHostnameVerification,
 		config.Proxy,
 		config.AptProxy,
+		config.AptMirror,
+		config.PackageSources,
+		!config.DisableOSUpgrade,
 	); err != nil {
 		lxcLogger.Errorf("failed to populate machine config: %v", err)
 		return nil, nil, nil, err
 	}
+	// Containers belong to the same environment as their host.
+	if uuid := broker.agentConfig.Value(agent.EnvironUUID); uuid != "" {
+		args.MachineConfig.AgentEnvironment[agent.EnvironUUID] = uuid
+	}
 
 	inst, hardware, err := broker.manager.CreateContainer(args.MachineConfig, series, network)
 	if err != nil {
@@ -0,0 +1,130 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package provisioner
+
+import (
+	"fmt"
+
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/container"
+	"github.com/juju/juju/container/lxd"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/tools"
+)
+
+var lxdLogger = loggo.GetLogger("juju.provisioner.lxd")
+
+var _ environs.InstanceBroker = (*lxdBroker)(nil)
+var _ tools.HasTools = (*lxdBroker)(nil)
+
+func NewLxdBroker(
+	api APICalls,
+	tools *tools.Tools,
+	agentConfig agent.Config,
+	managerConfig container.ManagerConfig,
+) (environs.InstanceBroker, error) {
+	manager, err := lxd.NewContainerManager(managerConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &lxdBroker{
+		manager:     manager,
+		api:         api,
+		tools:       tools,
+		agentConfig: agentConfig,
+	}, nil
+}
+
+type lxdBroker struct {
+	manager     container.Manager
+	api         APICalls
+	tools       *tools.Tools
+	agentConfig agent.Config
+}
+
+func (broker *lxdBroker) Tools(series string) tools.List {
+	// TODO: thumper 2014-04-08 bug 1304151
+	// should use the api get get tools for the series.
+	seriesTools := *broker.tools
+	seriesTools.Version.Series = series
+	return tools.List{&seriesTools}
+}
+
+// StartInstance is specified in the Broker interface.
+func (broker *lxdBroker) StartInstance(args environs.StartInstanceParams) (instance.Instance, *instance.HardwareCharacteristics, []network.Info, error) {
+	if args.MachineConfig.HasNetworks() {
+		return nil, nil, nil, fmt.Errorf("starting lxd containers with networks is not supported yet.")
+	}
+	// TODO: refactor common code out of the container brokers.
+	machineId := args.MachineConfig.MachineId
+	lxdLogger.Infof("starting lxd container for machineId: %s", machineId)
+
+	// TODO: Default to using the host network until we can configure.  Yes,
+	// this is using the LxcBridge value, we should put it in the api call for
+	// container config.
+	bridgeDevice := broker.agentConfig.Value(agent.LxcBridge)
+	if bridgeDevice == "" {
+		bridgeDevice = lxd.DefaultLxdBridge
+	}
+	network := container.BridgeNetworkConfig(bridgeDevice)
+
+	// TODO: series doesn't necessarily need to be the same as the host.
+	series := args.Tools.OneSeries()
+	args.MachineConfig.MachineContainerType = instance.LXD
+	args.MachineConfig.Tools = args.Tools[0]
+
+	config, err := broker.api.ContainerConfig()
+	if err != nil {
+		lxdLogger.Errorf("failed to get container config: %v", err)
+		return nil, nil, nil, err
+	}
+	if err := environs.PopulateMachineConfig(
+		args.MachineConfig,
+		config.ProviderType,
+		config.AuthorizedKeys,
+		config.SSLHostnameVerification,
+		config.Proxy,
+		config.AptProxy,
+		config.AptMirror,
+		config.PackageSources,
+		!config.DisableOSUpgrade,
+	); err != nil {
+		lxdLogger.Errorf("failed to populate machine config: %v", err)
+		return nil, nil, nil, err
+	}
+	// Containers belong to the same environment as their host.
+	if uuid := broker.agentConfig.Value(agent.EnvironUUID); uuid != "" {
+		args.MachineConfig.AgentEnvironment[agent.EnvironUUID] = uuid
+	}
+
+	inst, hardware, err := broker.manager.CreateContainer(args.MachineConfig, series, network)
+	if err != nil {
+		lxdLogger.Errorf("failed to start container: %v", err)
+		return nil, nil, nil, err
+	}
+	lxdLogger.Infof("started lxd container for machineId: %s, %s, %s", machineId, inst.Id(), hardware.String())
+	return inst, hardware, nil, nil
+}
+
+// StopInstances shuts down the given instances.
+func (broker *lxdBroker) StopInstances(ids ...instance.Id) error {
+	// TODO: potentially parallelise.
+	for _, id := range ids {
+		lxdLogger.Infof("stopping lxd container for instance: %s", id)
+		if err := broker.manager.DestroyContainer(id); err != nil {
+			lxdLogger.Errorf("container did not stop: %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// AllInstances only returns running containers.
+func (broker *lxdBroker) AllInstances() (result []instance.Instance, err error) {
+	return broker.manager.ListContainers()
+}
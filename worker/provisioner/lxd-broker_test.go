@@ -0,0 +1,236 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package provisioner_test
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/container"
+	"github.com/juju/juju/container/lxd"
+	lxdtesting "github.com/juju/juju/container/lxd/testing"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+	instancetest "github.com/juju/juju/instance/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+	coretools "github.com/juju/juju/tools"
+	"github.com/juju/juju/version"
+	"github.com/juju/juju/worker/provisioner"
+)
+
+type lxdBrokerSuite struct {
+	lxdtesting.TestSuite
+	broker      environs.InstanceBroker
+	agentConfig agent.Config
+}
+
+var _ = gc.Suite(&lxdBrokerSuite{})
+
+func (s *lxdBrokerSuite) SetUpTest(c *gc.C) {
+	s.TestSuite.SetUpTest(c)
+	tools := &coretools.Tools{
+		Version: version.MustParseBinary("2.3.4-foo-bar"),
+		URL:     "http://tools.testing.invalid/2.3.4-foo-bar.tgz",
+	}
+	var err error
+	s.agentConfig, err = agent.NewAgentConfig(
+		agent.AgentConfigParams{
+			DataDir:           "/not/used/here",
+			Tag:               "tag",
+			UpgradedToVersion: version.Current.Number,
+			Password:          "dummy-secret",
+			Nonce:             "nonce",
+			APIAddresses:      []string{"10.0.0.1:1234"},
+			CACert:            coretesting.CACert,
+		})
+	c.Assert(err, gc.IsNil)
+	managerConfig := container.ManagerConfig{container.ConfigName: "juju"}
+	s.broker, err = provisioner.NewLxdBroker(&fakeAPI{}, tools, s.agentConfig, managerConfig)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *lxdBrokerSuite) startInstance(c *gc.C, machineId string) instance.Instance {
+	machineNonce := "fake-nonce"
+	stateInfo := jujutesting.FakeStateInfo(machineId)
+	apiInfo := jujutesting.FakeAPIInfo(machineId)
+	machineConfig := environs.NewMachineConfig(machineId, machineNonce, nil, stateInfo, apiInfo)
+	cons := constraints.Value{}
+	possibleTools := s.broker.(coretools.HasTools).Tools("precise")
+	lxd, _, _, err := s.broker.StartInstance(environs.StartInstanceParams{
+		Constraints:   cons,
+		Tools:         possibleTools,
+		MachineConfig: machineConfig,
+	})
+	c.Assert(err, gc.IsNil)
+	return lxd
+}
+
+func (s *lxdBrokerSuite) TestStartInstance(c *gc.C) {
+	inst := s.startInstance(c, "1/lxd/0")
+	c.Assert(inst.Id(), gc.Equals, instance.Id("juju-machine-1-lxd-0"))
+	created, source, ok := s.Server.Container("juju-machine-1-lxd-0")
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(created.Status, gc.Equals, lxd.StatusRunning)
+	c.Assert(created.Profiles, gc.DeepEquals, []string{"default", "juju-bridged-" + lxd.DefaultLxdBridge})
+	c.Assert(source, gc.Equals, lxd.ContainerSource{Type: "copy", Source: "juju-precise-lxd-template"})
+	c.Assert(created.Config["user.user-data"], jc.HasPrefix, "#cloud-config\n")
+}
+
+func (s *lxdBrokerSuite) TestStopInstance(c *gc.C) {
+	lxd0 := s.startInstance(c, "1/lxd/0")
+	lxd1 := s.startInstance(c, "1/lxd/1")
+	lxd2 := s.startInstance(c, "1/lxd/2")
+
+	err := s.broker.StopInstances(lxd0.Id())
+	c.Assert(err, gc.IsNil)
+	s.assertInstances(c, lxd1, lxd2)
+	c.Assert(s.lxdContainerDir(lxd0), jc.DoesNotExist)
+	c.Assert(s.lxdRemovedContainerDir(lxd0), jc.IsDirectory)
+
+	err = s.broker.StopInstances(lxd1.Id(), lxd2.Id())
+	c.Assert(err, gc.IsNil)
+	s.assertInstances(c)
+}
+
+func (s *lxdBrokerSuite) TestAllInstances(c *gc.C) {
+	lxd0 := s.startInstance(c, "1/lxd/0")
+	lxd1 := s.startInstance(c, "1/lxd/1")
+	s.assertInstances(c, lxd0, lxd1)
+
+	err := s.broker.StopInstances(lxd1.Id())
+	c.Assert(err, gc.IsNil)
+	lxd2 := s.startInstance(c, "1/lxd/2")
+	s.assertInstances(c, lxd0, lxd2)
+}
+
+func (s *lxdBrokerSuite) assertInstances(c *gc.C, inst ...instance.Instance) {
+	results, err := s.broker.AllInstances()
+	c.Assert(err, gc.IsNil)
+	instancetest.MatchInstances(c, results, inst...)
+}
+
+func (s *lxdBrokerSuite) lxdContainerDir(inst instance.Instance) string {
+	return filepath.Join(s.ContainerDir, string(inst.Id()))
+}
+
+func (s *lxdBrokerSuite) lxdRemovedContainerDir(inst instance.Instance) string {
+	return filepath.Join(s.RemovedDir, string(inst.Id()))
+}
+
+type lxdProvisionerSuite struct {
+	CommonProvisionerSuite
+	lxdtesting.TestSuite
+	machineId string
+}
+
+var _ = gc.Suite(&lxdProvisionerSuite{})
+
+func (s *lxdProvisionerSuite) SetUpSuite(c *gc.C) {
+	s.CommonProvisionerSuite.SetUpSuite(c)
+	s.TestSuite.SetUpSuite(c)
+}
+
+func (s *lxdProvisionerSuite) TearDownSuite(c *gc.C) {
+	s.TestSuite.TearDownSuite(c)
+	s.CommonProvisionerSuite.TearDownSuite(c)
+}
+
+func (s *lxdProvisionerSuite) SetUpTest(c *gc.C) {
+	s.CommonProvisionerSuite.SetUpTest(c)
+	s.TestSuite.SetUpTest(c)
+
+	// The lxd provisioner actually needs the machine it is being created on
+	// to be in state, in order to get the watcher.
+	m, err := s.State.AddMachine(coretesting.FakeDefaultSeries, state.JobHostUnits, state.JobManageEnviron)
+	c.Assert(err, gc.IsNil)
+	err = m.SetAddresses(network.NewAddress("0.1.2.3", network.ScopeUnknown))
+	c.Assert(err, gc.IsNil)
+
+	hostPorts := [][]network.HostPort{{{
+		Address: network.NewAddress("0.1.2.3", network.ScopeUnknown),
+		Port:    1234,
+	}}}
+	err = s.State.SetAPIHostPorts(hostPorts)
+	c.Assert(err, gc.IsNil)
+
+	s.machineId = m.Id()
+	s.APILogin(c, m)
+	err = m.SetAgentVersion(version.Current)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *lxdProvisionerSuite) TearDownTest(c *gc.C) {
+	s.TestSuite.TearDownTest(c)
+	s.CommonProvisionerSuite.TearDownTest(c)
+}
+
+func (s *lxdProvisionerSuite) newLxdProvisioner(c *gc.C) provisioner.Provisioner {
+	machineTag := names.NewMachineTag(s.machineId).String()
+	agentConfig := s.AgentConfigForTag(c, machineTag)
+	tools, err := s.provisioner.Tools(agentConfig.Tag())
+	c.Assert(err, gc.IsNil)
+	managerConfig := container.ManagerConfig{container.ConfigName: "juju"}
+	broker, err := provisioner.NewLxdBroker(s.provisioner, tools, agentConfig, managerConfig)
+	c.Assert(err, gc.IsNil)
+	return provisioner.NewContainerProvisioner(instance.LXD, s.provisioner, agentConfig, broker)
+}
+
+// waitContainers waits for the fake LXD server to hold
+// exactly the given running juju containers.
+func (s *lxdProvisionerSuite) waitContainers(c *gc.C, expect ...string) {
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		s.State.StartSync()
+		var running []string
+		for _, name := range s.Server.Containers() {
+			created, _, _ := s.Server.Container(name)
+			if created.Status == lxd.StatusRunning {
+				running = append(running, name)
+			}
+		}
+		sort.Strings(running)
+		sort.Strings(expect)
+		if strings.Join(running, " ") == strings.Join(expect, " ") {
+			return
+		}
+		if !a.HasNext() {
+			c.Fatalf("timed out waiting for containers %v; got %v", expect, running)
+		}
+	}
+}
+
+func (s *lxdProvisionerSuite) TestProvisionerStartStop(c *gc.C) {
+	p := s.newLxdProvisioner(c)
+	c.Assert(p.Stop(), gc.IsNil)
+}
+
+func (s *lxdProvisionerSuite) TestContainerStartedAndStopped(c *gc.C) {
+	p := s.newLxdProvisioner(c)
+	defer stop(c, p)
+
+	template := state.MachineTemplate{
+		Series: coretesting.FakeDefaultSeries,
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+	}
+	container, err := s.State.AddMachineInsideMachine(template, s.machineId, instance.LXD)
+	c.Assert(err, gc.IsNil)
+
+	instId := "juju-" + names.NewMachineTag(container.Id()).String()
+	s.waitContainers(c, instId)
+	s.waitInstanceId(c, container, instance.Id(instId))
+
+	// ...and removed, along with the machine, when the machine is Dead.
+	c.Assert(container.EnsureDead(), gc.IsNil)
+	s.waitContainers(c)
+	s.waitRemoved(c, container)
+}
@@ -31,6 +31,7 @@ type Provisioner interface {
 	worker.Worker
 	Stop() error
 	getMachineWatcher() (apiwatcher.StringsWatcher, error)
+	getVolumeWatcher() (apiwatcher.StringsWatcher, error)
 	getRetryWatcher() (apiwatcher.NotifyWatcher, error)
 }
 
@@ -110,13 +111,17 @@ func (p *provisioner) getStartTask(safeMode bool) (ProvisionerTask, error) {
 	if err != nil {
 		return nil, err
 	}
+	volumeWatcher, err := p.getVolumeWatcher()
+	if err != nil && !errors.IsNotImplemented(err) {
+		return nil, err
+	}
 	retryWatcher, err := p.getRetryWatcher()
 	if err != nil && !errors.IsNotImplemented(err) {
 		return nil, err
 	}
 	task := NewProvisionerTask(
-		p.agentConfig.Tag(), safeMode, p.st,
-		machineWatcher, retryWatcher, p.broker, auth)
+		p.agentConfig.Tag(), safeMode, p.st, p.st,
+		machineWatcher, volumeWatcher, retryWatcher, p.broker, auth)
 	return task, nil
 }
 
@@ -190,6 +195,10 @@ func (p *environProvisioner) getMachineWatcher() (apiwatcher.StringsWatcher, err
 	return p.st.WatchEnvironMachines()
 }
 
+func (p *environProvisioner) getVolumeWatcher() (apiwatcher.StringsWatcher, error) {
+	return p.st.WatchVolumes()
+}
+
 func (p *environProvisioner) getRetryWatcher() (apiwatcher.NotifyWatcher, error) {
 	return p.st.WatchMachineErrorRetry()
 }
@@ -265,6 +274,10 @@ func (p *containerProvisioner) getMachineWatcher() (apiwatcher.StringsWatcher, e
 	return machine.WatchContainers(p.containerType)
 }
 
+func (p *containerProvisioner) getVolumeWatcher() (apiwatcher.StringsWatcher, error) {
+	return nil, errors.NotImplementedf("getVolumeWatcher")
+}
+
 func (p *containerProvisioner) getRetryWatcher() (apiwatcher.NotifyWatcher, error) {
 	return nil, errors.NotImplementedf("getRetryWatcher")
 }
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/juju/errors"
 	"github.com/juju/names"
 	"github.com/juju/utils"
 	"github.com/juju/utils/set"
@@ -22,6 +23,7 @@ import (
 	apiprovisioner "github.com/juju/juju/state/api/provisioner"
 	apiwatcher "github.com/juju/juju/state/api/watcher"
 	"github.com/juju/juju/state/watcher"
+	"github.com/juju/juju/storage"
 	coretools "github.com/juju/juju/tools"
 	"github.com/juju/juju/worker"
 )
@@ -46,11 +48,25 @@ type MachineGetter interface {
 
 var _ MachineGetter = (*apiprovisioner.State)(nil)
 
+// VolumeGetter provides access to the volumes that the environment
+// provisioner destroys once they are no longer required.
+type VolumeGetter interface {
+	Volumes(names []string) ([]params.VolumeResult, error)
+	RemoveVolumes(names []string) ([]params.ErrorResult, error)
+}
+
+var _ VolumeGetter = (*apiprovisioner.State)(nil)
+
+// NewProvisionerTask returns a new ProvisionerTask. Only environment
+// provisioners, which manage the environment's volumes, are given a
+// volumeWatcher; container provisioners pass nil.
 func NewProvisionerTask(
 	machineTag string,
 	safeMode bool,
 	machineGetter MachineGetter,
+	volumeGetter VolumeGetter,
 	machineWatcher apiwatcher.StringsWatcher,
+	volumeWatcher apiwatcher.StringsWatcher,
 	retryWatcher apiwatcher.NotifyWatcher,
 	broker environs.InstanceBroker,
 	auth environs.AuthenticationProvider,
@@ -58,7 +74,9 @@ func NewProvisionerTask(
 	task := &provisionerTask{
 		machineTag:     machineTag,
 		machineGetter:  machineGetter,
+		volumeGetter:   volumeGetter,
 		machineWatcher: machineWatcher,
+		volumeWatcher:  volumeWatcher,
 		retryWatcher:   retryWatcher,
 		broker:         broker,
 		auth:           auth,
@@ -76,7 +94,9 @@ func NewProvisionerTask(
 type provisionerTask struct {
 	machineTag     string
 	machineGetter  MachineGetter
+	volumeGetter   VolumeGetter
 	machineWatcher apiwatcher.StringsWatcher
+	volumeWatcher  apiwatcher.StringsWatcher
 	retryWatcher   apiwatcher.NotifyWatcher
 	broker         environs.InstanceBroker
 	tomb           tomb.Tomb
@@ -130,6 +150,13 @@ func (task *provisionerTask) loop() error {
 		retryChan = task.retryWatcher.Changes()
 	}
 
+	// Only the environment provisioner watches volumes.
+	var volumeChanges <-chan []string
+	if task.volumeWatcher != nil {
+		defer watcher.Stop(task.volumeWatcher, &task.tomb)
+		volumeChanges = task.volumeWatcher.Changes()
+	}
+
 	// When the watcher is started, it will have the initial changes be all
 	// the machines that are relevant. Also, since this is available straight
 	// away, we know there will be some changes right off the bat.
@@ -164,6 +191,13 @@ func (task *provisionerTask) loop() error {
 			if err := task.processMachinesWithTransientErrors(); err != nil {
 				return fmt.Errorf("failed to process machines with transient errors: %v", err)
 			}
+		case volumeNames, ok := <-volumeChanges:
+			if !ok {
+				return watcher.MustErr(task.volumeWatcher)
+			}
+			if err := task.processVolumes(volumeNames); err != nil {
+				return fmt.Errorf("failed to process updated volumes: %v", err)
+			}
 		}
 	}
 }
@@ -469,6 +503,8 @@ func (task *provisionerTask) startMachine(machine *apiprovisioner.Machine) error
 		MachineConfig:     provisioningInfo.MachineConfig,
 		Placement:         provisioningInfo.Placement,
 		DistributionGroup: machine.DistributionGroup,
+		Volumes:           staticVolumes(provisioningInfo.Volumes),
+		SubnetsToZones:    provisioningInfo.SubnetsToZones,
 	})
 	if err != nil {
 		// Set the state to error, so the machine will be skipped next
@@ -484,6 +520,9 @@ func (task *provisionerTask) startMachine(machine *apiprovisioner.Machine) error
 		return fmt.Errorf("cannot provision instance %v for machine %q with networks: not implemented", inst.Id(), machine)
 	} else if err == nil {
 		logger.Infof("started machine %s as instance %s with hardware %q, networks %v, interfaces %v", machine, inst.Id(), metadata, networks, ifaces)
+		if err := task.createVolumes(machine, inst.Id(), provisioningInfo.Volumes); err != nil {
+			return task.setErrorStatus("cannot create volumes for machine %q: %v", machine, err)
+		}
 		return nil
 	}
 	// We need to stop the instance right away here, set error status and go on.
@@ -496,6 +535,173 @@ func (task *provisionerTask) startMachine(machine *apiprovisioner.Machine) error
 	return nil
 }
 
+// staticVolumes returns the parameters of the volumes
+// that must be created along with the instance.
+func staticVolumes(allParams []storage.VolumeParams) []storage.VolumeParams {
+	var static []storage.VolumeParams
+	for _, p := range allParams {
+		provider, err := storage.StorageProvider(p.Provider)
+		if err == nil && !provider.Dynamic() {
+			static = append(static, p)
+		}
+	}
+	return static
+}
+
+// createVolumes creates the environ-scoped volumes attached to the
+// machine once its instance has started, and records their details.
+func (task *provisionerTask) createVolumes(machine *apiprovisioner.Machine, instId instance.Id, allParams []storage.VolumeParams) error {
+	if len(allParams) == 0 {
+		return nil
+	}
+	env, ok := task.broker.(environs.Environ)
+	if !ok {
+		return fmt.Errorf("broker of type %T cannot create volumes", task.broker)
+	}
+	var providerTypes []storage.ProviderType
+	byProvider := make(map[storage.ProviderType][]storage.VolumeParams)
+	for _, p := range allParams {
+		if _, ok := byProvider[p.Provider]; !ok {
+			providerTypes = append(providerTypes, p.Provider)
+		}
+		if p.Attachment != nil {
+			attachment := *p.Attachment
+			attachment.InstanceId = instId
+			p.Attachment = &attachment
+		}
+		byProvider[p.Provider] = append(byProvider[p.Provider], p)
+	}
+	for _, providerType := range providerTypes {
+		provider, err := storage.StorageProvider(providerType)
+		if err != nil {
+			return err
+		}
+		source, err := provider.VolumeSource(env.Config(), "")
+		if err != nil {
+			return err
+		}
+		volumes, attachments, err := source.CreateVolumes(byProvider[providerType])
+		if err != nil {
+			return err
+		}
+		if err := machine.SetVolumeInfo(volumes, attachments); err != nil {
+			return err
+		}
+		logger.Infof("created volumes %v for machine %s", volumes, machine)
+	}
+	return nil
+}
+
+// processVolumes destroys the named volumes that are no longer alive,
+// and removes them from state. Volumes whose storage provider cannot
+// destroy them are destroyed along with the instances they are
+// attached to, so they are only removed once they are detached.
+func (task *provisionerTask) processVolumes(volumeNames []string) error {
+	logger.Tracef("processVolumes(%v)", volumeNames)
+	if len(volumeNames) == 0 {
+		return nil
+	}
+	results, err := task.volumeGetter.Volumes(volumeNames)
+	if err != nil {
+		return err
+	}
+	var providerTypes []storage.ProviderType
+	destroy := make(map[storage.ProviderType][]params.Volume)
+	var remove []string
+	for i, result := range results {
+		if result.Error != nil {
+			if params.IsCodeNotFound(result.Error) {
+				// The volume has already been removed.
+				continue
+			}
+			return fmt.Errorf("cannot get volume %q: %v", volumeNames[i], result.Error)
+		}
+		volume := result.Result
+		if volume.Life == params.Alive {
+			continue
+		}
+		providerType := volume.Params.Provider
+		provider, err := storage.StorageProvider(providerType)
+		if err != nil {
+			return err
+		}
+		switch {
+		case provider.Scope() == storage.ScopeMachine:
+			// Machine-scoped volumes are destroyed by the storage
+			// provisioner of the machine they are attached to, or
+			// along with the machine.
+			if !volume.Attached {
+				remove = append(remove, volume.Params.Name)
+			}
+		case !volume.Provisioned:
+			remove = append(remove, volume.Params.Name)
+		default:
+			if _, ok := destroy[providerType]; !ok {
+				providerTypes = append(providerTypes, providerType)
+			}
+			destroy[providerType] = append(destroy[providerType], volume)
+		}
+	}
+	for _, providerType := range providerTypes {
+		destroyed, err := task.destroyVolumes(providerType, destroy[providerType])
+		if err != nil {
+			return err
+		}
+		remove = append(remove, destroyed...)
+	}
+	if len(remove) == 0 {
+		return nil
+	}
+	errResults, err := task.volumeGetter.RemoveVolumes(remove)
+	if err != nil {
+		return err
+	}
+	for i, result := range errResults {
+		if result.Error != nil {
+			return fmt.Errorf("cannot remove volume %q: %v", remove[i], result.Error)
+		}
+	}
+	return nil
+}
+
+// destroyVolumes destroys the given provisioned volumes of the
+// storage provider with the given type, and returns the names
+// of those that may be removed from state.
+func (task *provisionerTask) destroyVolumes(providerType storage.ProviderType, volumes []params.Volume) ([]string, error) {
+	env, ok := task.broker.(environs.Environ)
+	if !ok {
+		return nil, fmt.Errorf("broker of type %T cannot destroy volumes", task.broker)
+	}
+	provider, err := storage.StorageProvider(providerType)
+	if err != nil {
+		return nil, err
+	}
+	source, err := provider.VolumeSource(env.Config(), "")
+	if err != nil {
+		return nil, err
+	}
+	volumeNames := make([]string, len(volumes))
+	volumeIds := make([]string, len(volumes))
+	for i, volume := range volumes {
+		volumeNames[i] = volume.Params.Name
+		volumeIds[i] = volume.VolumeId
+	}
+	err = source.DestroyVolumes(volumeIds)
+	if errors.IsNotSupported(err) {
+		var detached []string
+		for _, volume := range volumes {
+			if !volume.Attached {
+				detached = append(detached, volume.Params.Name)
+			}
+		}
+		return detached, nil
+	} else if err != nil {
+		return nil, err
+	}
+	logger.Infof("destroyed volumes %v", volumeIds)
+	return volumeNames, nil
+}
+
 func (task *provisionerTask) possibleTools(series string, cons constraints.Value) (coretools.List, error) {
 	if env, ok := task.broker.(environs.Environ); ok {
 		agentVersion, ok := env.Config().AgentVersion()
@@ -511,10 +717,12 @@ func (task *provisionerTask) possibleTools(series string, cons constraints.Value
 }
 
 type provisioningInfo struct {
-	Constraints   constraints.Value
-	Series        string
-	Placement     string
-	MachineConfig *cloudinit.MachineConfig
+	Constraints    constraints.Value
+	Series         string
+	Placement      string
+	MachineConfig  *cloudinit.MachineConfig
+	Volumes        []storage.VolumeParams
+	SubnetsToZones map[network.Id][]string
 }
 
 func (task *provisionerTask) provisioningInfo(machine *apiprovisioner.Machine) (*provisioningInfo, error) {
@@ -550,10 +758,19 @@ func (task *provisionerTask) provisioningInfo(machine *apiprovisioner.Machine) (
 	}
 	nonce := fmt.Sprintf("%s:%s", task.machineTag, uuid.String())
 	machineConfig := environs.NewMachineConfig(machine.Id(), nonce, pInfo.Networks, stateInfo, apiInfo)
+	var subnetsToZones map[network.Id][]string
+	if len(pInfo.SubnetsToZones) > 0 {
+		subnetsToZones = make(map[network.Id][]string)
+		for subnetId, zones := range pInfo.SubnetsToZones {
+			subnetsToZones[network.Id(subnetId)] = zones
+		}
+	}
 	return &provisioningInfo{
-		Constraints:   pInfo.Constraints,
-		Series:        pInfo.Series,
-		Placement:     pInfo.Placement,
-		MachineConfig: machineConfig,
+		Constraints:    pInfo.Constraints,
+		Series:         pInfo.Series,
+		Placement:      pInfo.Placement,
+		MachineConfig:  machineConfig,
+		Volumes:        pInfo.Volumes,
+		SubnetsToZones: subnetsToZones,
 	}, nil
 }
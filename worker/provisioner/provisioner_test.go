@@ -30,6 +30,7 @@ import (
 	"github.com/juju/juju/state/api/params"
 	apiprovisioner "github.com/juju/juju/state/api/provisioner"
 	apiserverprovisioner "github.com/juju/juju/state/apiserver/provisioner"
+	"github.com/juju/juju/storage"
 	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/worker/provisioner"
 )
@@ -389,6 +390,218 @@ func (s *ProvisionerSuite) TestSimple(c *gc.C) {
 	s.waitRemoved(c, m)
 }
 
+// staticStorageProvider is an environ-scoped storage provider
+// whose volumes are created along with the instances they attach
+// to, much like EBS volumes.
+type staticStorageProvider struct{}
+
+func (staticStorageProvider) VolumeSource(*config.Config, string) (storage.VolumeSource, error) {
+	return staticVolumeSource{}, nil
+}
+
+func (staticStorageProvider) Scope() storage.Scope {
+	return storage.ScopeEnviron
+}
+
+func (staticStorageProvider) Dynamic() bool {
+	return false
+}
+
+type staticVolumeSource struct{}
+
+func (staticVolumeSource) CreateVolumes(params []storage.VolumeParams) ([]storage.Volume, []storage.VolumeAttachment, error) {
+	var volumes []storage.Volume
+	var attachments []storage.VolumeAttachment
+	for _, p := range params {
+		if p.Attachment == nil || p.Attachment.InstanceId == "" {
+			return nil, nil, fmt.Errorf("volume %q created without an instance", p.Name)
+		}
+		volumes = append(volumes, storage.Volume{
+			Name:     p.Name,
+			VolumeId: "vol-" + p.Name,
+			Size:     p.Size,
+		})
+		attachments = append(attachments, storage.VolumeAttachment{
+			Volume:     p.Name,
+			Machine:    p.Attachment.Machine,
+			DeviceName: "xvd" + p.Name,
+		})
+	}
+	return volumes, attachments, nil
+}
+
+func (staticVolumeSource) DestroyVolumes([]string) error {
+	return errors.NotSupportedf("destroying static volumes")
+}
+
+// dynamicStorageProvider is an environ-scoped storage provider
+// whose volumes are created and destroyed independently of the
+// instances they attach to. The ids of destroyed volumes are
+// sent on destroyedVolumes.
+type dynamicStorageProvider struct {
+	staticStorageProvider
+}
+
+var destroyedVolumes = make(chan []string, 10)
+
+func (dynamicStorageProvider) VolumeSource(*config.Config, string) (storage.VolumeSource, error) {
+	return dynamicVolumeSource{}, nil
+}
+
+func (dynamicStorageProvider) Dynamic() bool {
+	return true
+}
+
+type dynamicVolumeSource struct {
+	staticVolumeSource
+}
+
+func (dynamicVolumeSource) DestroyVolumes(volumeIds []string) error {
+	destroyedVolumes <- volumeIds
+	return nil
+}
+
+func init() {
+	storage.RegisterProvider("static", staticStorageProvider{})
+	storage.RegisterProvider("dynamic", dynamicStorageProvider{})
+}
+
+// addUnitWithVolume adds a unit of a new mysql service with a volume
+// from the given pool, assigned to a new machine, and waits for the
+// volume to be provisioned.
+func (s *ProvisionerSuite) addUnitWithVolume(c *gc.C, pool string) (*state.Unit, *state.Machine) {
+	service := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	unit, err := service.AddUnitWithStorage(map[string]storage.Directive{
+		"data": {Pool: pool, Size: 1024, Count: 1},
+	})
+	c.Assert(err, gc.IsNil)
+	err = unit.AssignToNewMachine()
+	c.Assert(err, gc.IsNil)
+	machineId, err := unit.AssignedMachineId()
+	c.Assert(err, gc.IsNil)
+	machine, err := s.BackingState.Machine(machineId)
+	c.Assert(err, gc.IsNil)
+	s.checkStartInstanceCustom(c, machine, "pork", constraints.Value{}, nil, nil, true)
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		volume, err := s.BackingState.Volume("0")
+		c.Assert(err, gc.IsNil)
+		if _, ok := volume.Info(); ok {
+			break
+		}
+		if !a.HasNext() {
+			c.Fatalf("volume info was not set")
+		}
+	}
+	return unit, machine
+}
+
+// removeUnit removes the unit, and cleans up after it.
+func (s *ProvisionerSuite) removeUnit(c *gc.C, unit *state.Unit) {
+	err := unit.EnsureDead()
+	c.Assert(err, gc.IsNil)
+	err = unit.Remove()
+	c.Assert(err, gc.IsNil)
+	err = s.BackingState.Cleanup()
+	c.Assert(err, gc.IsNil)
+	s.BackingState.StartSync()
+}
+
+// waitVolumeRemoved waits for the named volume to be removed from state.
+func (s *ProvisionerSuite) waitVolumeRemoved(c *gc.C, name string) {
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		_, err := s.BackingState.Volume(name)
+		if errors.IsNotFound(err) {
+			return
+		}
+		c.Assert(err, gc.IsNil)
+	}
+	c.Fatalf("volume %q was not removed", name)
+}
+
+func (s *ProvisionerSuite) TestDestroysDynamicVolumes(c *gc.C) {
+	p := s.newEnvironProvisioner(c)
+	defer stop(c, p)
+
+	unit, _ := s.addUnitWithVolume(c, "dynamic")
+	s.removeUnit(c, unit)
+	select {
+	case volumeIds := <-destroyedVolumes:
+		c.Assert(volumeIds, gc.DeepEquals, []string{"vol-0"})
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("provisioner did not destroy the volume")
+	}
+	s.waitVolumeRemoved(c, "0")
+}
+
+func (s *ProvisionerSuite) TestRemovesStaticVolumesWithMachine(c *gc.C) {
+	p := s.newEnvironProvisioner(c)
+	defer stop(c, p)
+
+	unit, machine := s.addUnitWithVolume(c, "static")
+	s.removeUnit(c, unit)
+
+	// The volume cannot be destroyed while it is attached
+	// to the machine's instance, so it is left dying.
+	time.Sleep(coretesting.ShortWait)
+	volume, err := s.BackingState.Volume("0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(volume.Life(), gc.Equals, state.Dying)
+
+	// Once the machine is removed, the volume is
+	// destroyed along with its instance, and removed.
+	c.Assert(machine.EnsureDead(), gc.IsNil)
+	s.waitRemoved(c, machine)
+	s.waitVolumeRemoved(c, "0")
+}
+
+func (s *ProvisionerSuite) TestProvisioningMachinesWithVolumes(c *gc.C) {
+	p := s.newEnvironProvisioner(c)
+	defer stop(c, p)
+
+	service := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	unit, err := service.AddUnitWithStorage(map[string]storage.Directive{
+		"data": {Pool: "static", Size: 1024, Count: 1},
+	})
+	c.Assert(err, gc.IsNil)
+	err = unit.AssignToNewMachine()
+	c.Assert(err, gc.IsNil)
+	machineId, err := unit.AssignedMachineId()
+	c.Assert(err, gc.IsNil)
+
+	s.BackingState.StartSync()
+	select {
+	case o := <-s.op:
+		start, ok := o.(dummy.OpStartInstance)
+		c.Assert(ok, jc.IsTrue)
+		c.Assert(start.MachineId, gc.Equals, machineId)
+		c.Assert(start.Volumes, gc.DeepEquals, []storage.VolumeParams{{
+			Name:       "0",
+			Size:       1024,
+			Provider:   "static",
+			Attachment: &storage.AttachmentParams{Machine: machineId},
+		}})
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("provisioner did not start an instance")
+	}
+
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		volume, err := s.BackingState.Volume("0")
+		c.Assert(err, gc.IsNil)
+		if info, ok := volume.Info(); ok {
+			c.Assert(info, gc.Equals, state.VolumeInfo{VolumeId: "vol-0", Size: 1024})
+			break
+		}
+		if !a.HasNext() {
+			c.Fatalf("volume info was not set")
+		}
+	}
+	attachment, err := s.BackingState.VolumeAttachment(machineId, "0")
+	c.Assert(err, gc.IsNil)
+	info, ok := attachment.Info()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(info, gc.Equals, state.VolumeAttachmentInfo{DeviceName: "xvd0"})
+}
+
 func (s *ProvisionerSuite) TestConstraints(c *gc.C) {
 	// Create a machine with non-standard constraints.
 	m, err := s.addMachine()
@@ -889,8 +1102,8 @@ func (s *ProvisionerSuite) newProvisionerTask(c *gc.C, safeMode bool, broker env
 	auth, err := environs.NewAPIAuthenticator(s.provisioner)
 	c.Assert(err, gc.IsNil)
 	return provisioner.NewProvisionerTask(
-		"machine-0", safeMode, s.provisioner,
-		machineWatcher, retryWatcher, broker, auth)
+		"machine-0", safeMode, s.provisioner, s.provisioner,
+		machineWatcher, nil, retryWatcher, broker, auth)
 }
 
 func (s *ProvisionerSuite) TestTurningOffSafeModeReapsUnknownInstances(c *gc.C) {
@@ -0,0 +1,10 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package remoterelations
+
+// Update brings the relations known to st into step once.
+func Update(st RemoteRelationsState) error {
+	h := &remoteRelationsHandler{st: st}
+	return h.update()
+}
@@ -0,0 +1,176 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package remoterelations defines a worker that keeps the relations
+// between local and remote services in step across the environments
+// hosted by a state server.
+//
+// A relation between a service and an offer is represented in both
+// environments concerned: in the consuming environment, the offered
+// service appears as a remote service; in the offering environment, a
+// matching relation is made with a remote service standing for the
+// consuming service. The worker copies the units of the local service
+// of each relation, along with their settings, into the matching
+// relation in the other environment as remote units, where they are
+// seen by the unit agents like any other related units. This is done
+// whenever the relations with remote services, or their units, change
+// in any of the environments.
+package remoterelations
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/api/watcher"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.remoterelations")
+
+// RemoteRelationsState holds the methods of the RemoteRelations API
+// facade used by the worker.
+type RemoteRelationsState interface {
+	WatchRemoteRelations() (watcher.NotifyWatcher, error)
+	RemoteRelations() ([]params.RemoteRelation, error)
+	RelationUnits(id params.RemoteRelationId) (params.RemoteRelationUnitsResult, error)
+	MirrorRelation(id params.RemoteRelationId) error
+	DestroyRelation(id params.RemoteRelationId) error
+	EnterScope(change params.RemoteRelationUnitChange) error
+	LeaveScope(change params.RemoteRelationUnitChange) error
+}
+
+var _ worker.NotifyWatchHandler = (*remoteRelationsHandler)(nil)
+
+// remoteRelationsHandler brings the relations with remote services in
+// all environments hosted by the state server into step whenever any
+// of them change.
+type remoteRelationsHandler struct {
+	st RemoteRelationsState
+}
+
+// NewRemoteRelationsWorker returns a worker that keeps relations with
+// remote services in step.
+func NewRemoteRelationsWorker(st RemoteRelationsState) worker.Worker {
+	return worker.NewNotifyWorker(&remoteRelationsHandler{st: st})
+}
+
+// SetUp is defined on the worker.NotifyWatchHandler interface.
+func (h *remoteRelationsHandler) SetUp() (watcher.NotifyWatcher, error) {
+	return h.st.WatchRemoteRelations()
+}
+
+// Handle is defined on the worker.NotifyWatchHandler interface.
+func (h *remoteRelationsHandler) Handle() error {
+	return h.update()
+}
+
+// TearDown is defined on the worker.NotifyWatchHandler interface.
+func (h *remoteRelationsHandler) TearDown() error {
+	return nil
+}
+
+// update brings every relation with a remote service into step with
+// its counterpart in the remote service's environment. A relation that
+// cannot be updated does not hold up the others, but an error is
+// returned once they have all been tried, so that the worker is
+// restarted and the failed relations are tried again.
+func (h *remoteRelationsHandler) update() error {
+	relations, err := h.st.RemoteRelations()
+	if err != nil {
+		return err
+	}
+	var failed []string
+	for _, rel := range relations {
+		if err := h.updateRelation(rel); err != nil {
+			logger.Errorf("cannot update relation %q in environment %s: %v", rel.Key, rel.EnvUUID, err)
+			failed = append(failed, fmt.Sprintf("%q in environment %s", rel.Key, rel.EnvUUID))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("cannot update relations %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// updateRelation copies the units of the local service of the given
+// relation into its counterpart in the remote environment. The units
+// of the remote service are copied the other way when the counterpart
+// itself is updated.
+func (h *remoteRelationsHandler) updateRelation(rel params.RemoteRelation) error {
+	localId := params.RemoteRelationId{EnvUUID: rel.EnvUUID, Key: rel.Key}
+	remoteId := params.RemoteRelationId{EnvUUID: rel.SourceEnvUUID, Key: rel.Key}
+	local, err := h.st.RelationUnits(localId)
+	if params.IsCodeNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	remote, err := h.st.RelationUnits(remoteId)
+	if params.IsCodeNotFound(err) && rel.Consumer && rel.Life == params.Alive {
+		// The relation has only just been made; the offering
+		// environment needs a relation to match it.
+		if err := h.st.MirrorRelation(localId); err != nil {
+			return fmt.Errorf("cannot mirror relation: %v", err)
+		}
+		remote, err = h.st.RelationUnits(remoteId)
+	}
+	if params.IsCodeNotFound(err) {
+		// The counterpart has gone, so the relation
+		// and its remote units must go too.
+		if rel.Life == params.Alive {
+			if err := h.st.DestroyRelation(localId); err != nil && !params.IsCodeNotFound(err) {
+				return err
+			}
+		}
+		for _, unit := range local.RemoteUnits {
+			change := params.RemoteRelationUnitChange{EnvUUID: rel.EnvUUID, Key: rel.Key, Unit: unit.Unit}
+			if err := h.st.LeaveScope(change); err != nil && !params.IsCodeNotFound(err) {
+				return err
+			}
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if remote.Life != params.Alive && rel.Life == params.Alive {
+		logger.Infof("destroying relation %q in environment %s to match environment %s", rel.Key, rel.EnvUUID, rel.SourceEnvUUID)
+		if err := h.st.DestroyRelation(localId); err != nil && !params.IsCodeNotFound(err) {
+			return err
+		}
+	}
+	existing := make(map[string]params.RelationSettings)
+	for _, unit := range remote.RemoteUnits {
+		existing[unit.Unit] = unit.Settings
+	}
+	for _, unit := range local.LocalUnits {
+		settings, ok := existing[unit.Unit]
+		delete(existing, unit.Unit)
+		if ok && reflect.DeepEqual(settings, unit.Settings) {
+			continue
+		}
+		if !ok && remote.Life != params.Alive {
+			// No units can enter the scope of a dying relation.
+			continue
+		}
+		change := params.RemoteRelationUnitChange{
+			EnvUUID:  rel.SourceEnvUUID,
+			Key:      rel.Key,
+			Unit:     unit.Unit,
+			Settings: unit.Settings,
+		}
+		if err := h.st.EnterScope(change); err != nil && !params.IsCodeCannotEnterScope(err) {
+			return err
+		}
+	}
+	for unitName := range existing {
+		change := params.RemoteRelationUnitChange{EnvUUID: rel.SourceEnvUUID, Key: rel.Key, Unit: unitName}
+		if err := h.st.LeaveScope(change); err != nil && !params.IsCodeNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,308 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package remoterelations_test
+
+import (
+	"fmt"
+	"sync"
+	stdtesting "testing"
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/state/api/watcher"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/remoterelations"
+)
+
+func TestPackage(t *stdtesting.T) {
+	gc.TestingT(t)
+}
+
+type remoteRelationsSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&remoteRelationsSuite{})
+
+const (
+	consumerUUID = "consumer-uuid"
+	offererUUID  = "offerer-uuid"
+	relationKey  = "wordpress:db mysql:server"
+)
+
+// fakeState implements remoterelations.RemoteRelationsState, keeping
+// the units of each relation in memory and recording the calls made.
+type fakeState struct {
+	mu        sync.Mutex
+	changes   chan struct{}
+	relations []params.RemoteRelation
+	units     map[params.RemoteRelationId]*params.RemoteRelationUnitsResult
+	calls     []string
+	// enterScopeErrs holds the errors returned by EnterScope,
+	// keyed by environment.
+	enterScopeErrs map[string]error
+}
+
+func newFakeState() *fakeState {
+	return &fakeState{
+		changes: make(chan struct{}),
+		units:   make(map[params.RemoteRelationId]*params.RemoteRelationUnitsResult),
+	}
+}
+
+func (st *fakeState) WatchRemoteRelations() (watcher.NotifyWatcher, error) {
+	return &fakeWatcher{changes: st.changes}, nil
+}
+
+func (st *fakeState) record(format string, args ...interface{}) {
+	st.calls = append(st.calls, fmt.Sprintf(format, args...))
+}
+
+func (st *fakeState) RemoteRelations() ([]params.RemoteRelation, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.relations, nil
+}
+
+func (st *fakeState) RelationUnits(id params.RemoteRelationId) (params.RemoteRelationUnitsResult, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	result, ok := st.units[id]
+	if !ok {
+		return params.RemoteRelationUnitsResult{}, &params.Error{
+			Message: "relation not found",
+			Code:    params.CodeNotFound,
+		}
+	}
+	return *result, nil
+}
+
+func (st *fakeState) MirrorRelation(id params.RemoteRelationId) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.record("MirrorRelation %s", id.EnvUUID)
+	st.units[params.RemoteRelationId{EnvUUID: offererUUID, Key: id.Key}] = &params.RemoteRelationUnitsResult{
+		Life: params.Alive,
+	}
+	return nil
+}
+
+func (st *fakeState) DestroyRelation(id params.RemoteRelationId) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.record("DestroyRelation %s", id.EnvUUID)
+	if result, ok := st.units[id]; ok {
+		result.Life = params.Dying
+	}
+	return nil
+}
+
+func (st *fakeState) EnterScope(change params.RemoteRelationUnitChange) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.record("EnterScope %s %s %v", change.EnvUUID, change.Unit, change.Settings)
+	return st.enterScopeErrs[change.EnvUUID]
+}
+
+func (st *fakeState) LeaveScope(change params.RemoteRelationUnitChange) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.record("LeaveScope %s %s", change.EnvUUID, change.Unit)
+	return nil
+}
+
+func (st *fakeState) Calls() []string {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return append([]string(nil), st.calls...)
+}
+
+// fakeWatcher implements watcher.NotifyWatcher, passing on the
+// changes sent to its fakeState.
+type fakeWatcher struct {
+	changes chan struct{}
+}
+
+func (w *fakeWatcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+func (w *fakeWatcher) Stop() error {
+	return nil
+}
+
+func (w *fakeWatcher) Err() error {
+	return nil
+}
+
+func consumerRelation(life params.Life) params.RemoteRelation {
+	return params.RemoteRelation{
+		EnvUUID:       consumerUUID,
+		Key:           relationKey,
+		Life:          life,
+		RemoteService: "mysql",
+		SourceEnvUUID: offererUUID,
+		Consumer:      true,
+	}
+}
+
+func (s *remoteRelationsSuite) TestMirrorsNewRelation(c *gc.C) {
+	st := newFakeState()
+	st.relations = []params.RemoteRelation{consumerRelation(params.Alive)}
+	st.units[params.RemoteRelationId{EnvUUID: consumerUUID, Key: relationKey}] = &params.RemoteRelationUnitsResult{
+		Life: params.Alive,
+		LocalUnits: []params.RemoteRelationUnit{{
+			Unit:     "wordpress/0",
+			Settings: params.RelationSettings{"foo": "bar"},
+		}},
+	}
+	err := remoterelations.Update(st)
+	c.Assert(err, gc.IsNil)
+	c.Assert(st.Calls(), gc.DeepEquals, []string{
+		"MirrorRelation consumer-uuid",
+		"EnterScope offerer-uuid wordpress/0 map[foo:bar]",
+	})
+}
+
+func (s *remoteRelationsSuite) TestUpdatesChangedUnits(c *gc.C) {
+	st := newFakeState()
+	st.relations = []params.RemoteRelation{consumerRelation(params.Alive)}
+	st.units[params.RemoteRelationId{EnvUUID: consumerUUID, Key: relationKey}] = &params.RemoteRelationUnitsResult{
+		Life: params.Alive,
+		LocalUnits: []params.RemoteRelationUnit{{
+			Unit:     "wordpress/0",
+			Settings: params.RelationSettings{"foo": "bar"},
+		}, {
+			Unit:     "wordpress/1",
+			Settings: params.RelationSettings{"foo": "baz"},
+		}},
+	}
+	st.units[params.RemoteRelationId{EnvUUID: offererUUID, Key: relationKey}] = &params.RemoteRelationUnitsResult{
+		Life: params.Alive,
+		RemoteUnits: []params.RemoteRelationUnit{{
+			Unit:     "wordpress/0",
+			Settings: params.RelationSettings{"foo": "bar"},
+		}, {
+			Unit:     "wordpress/1",
+			Settings: params.RelationSettings{"foo": "old"},
+		}, {
+			Unit: "wordpress/2",
+		}},
+	}
+	err := remoterelations.Update(st)
+	c.Assert(err, gc.IsNil)
+	c.Assert(st.Calls(), gc.DeepEquals, []string{
+		"EnterScope offerer-uuid wordpress/1 map[foo:baz]",
+		"LeaveScope offerer-uuid wordpress/2",
+	})
+}
+
+func (s *remoteRelationsSuite) TestDestroysRelationWhenCounterpartDying(c *gc.C) {
+	st := newFakeState()
+	st.relations = []params.RemoteRelation{consumerRelation(params.Alive)}
+	st.units[params.RemoteRelationId{EnvUUID: consumerUUID, Key: relationKey}] = &params.RemoteRelationUnitsResult{
+		Life: params.Alive,
+		LocalUnits: []params.RemoteRelationUnit{{
+			Unit: "wordpress/0",
+		}},
+	}
+	st.units[params.RemoteRelationId{EnvUUID: offererUUID, Key: relationKey}] = &params.RemoteRelationUnitsResult{
+		Life: params.Dying,
+	}
+	err := remoterelations.Update(st)
+	c.Assert(err, gc.IsNil)
+	// No units enter the scope of a dying relation.
+	c.Assert(st.Calls(), gc.DeepEquals, []string{
+		"DestroyRelation consumer-uuid",
+	})
+}
+
+func (s *remoteRelationsSuite) TestCleansUpWhenCounterpartGone(c *gc.C) {
+	st := newFakeState()
+	st.relations = []params.RemoteRelation{{
+		EnvUUID:       offererUUID,
+		Key:           relationKey,
+		Life:          params.Alive,
+		RemoteService: "wordpress",
+		SourceEnvUUID: consumerUUID,
+	}}
+	st.units[params.RemoteRelationId{EnvUUID: offererUUID, Key: relationKey}] = &params.RemoteRelationUnitsResult{
+		Life: params.Alive,
+		RemoteUnits: []params.RemoteRelationUnit{{
+			Unit: "wordpress/0",
+		}},
+	}
+	err := remoterelations.Update(st)
+	c.Assert(err, gc.IsNil)
+	c.Assert(st.Calls(), gc.DeepEquals, []string{
+		"DestroyRelation offerer-uuid",
+		"LeaveScope offerer-uuid wordpress/0",
+	})
+}
+
+func (s *remoteRelationsSuite) TestUpdateReturnsRelationErrors(c *gc.C) {
+	st := newFakeState()
+	st.relations = []params.RemoteRelation{{
+		EnvUUID:       offererUUID,
+		Key:           relationKey,
+		Life:          params.Alive,
+		RemoteService: "wordpress",
+		SourceEnvUUID: consumerUUID,
+	}, consumerRelation(params.Alive)}
+	for _, uuid := range []string{consumerUUID, offererUUID} {
+		st.units[params.RemoteRelationId{EnvUUID: uuid, Key: relationKey}] = &params.RemoteRelationUnitsResult{
+			Life:       params.Alive,
+			LocalUnits: []params.RemoteRelationUnit{{Unit: uuid + "/0"}},
+		}
+	}
+	st.enterScopeErrs = map[string]error{consumerUUID: fmt.Errorf("boom")}
+	err := remoterelations.Update(st)
+	c.Assert(err, gc.ErrorMatches, `cannot update relations "wordpress:db mysql:server" in environment offerer-uuid`)
+	// The relation that failed does not stop the other being updated.
+	c.Assert(st.Calls(), gc.DeepEquals, []string{
+		"EnterScope consumer-uuid offerer-uuid/0 map[]",
+		"EnterScope offerer-uuid consumer-uuid/0 map[]",
+	})
+}
+
+func (s *remoteRelationsSuite) TestWorkerUpdatesOnChange(c *gc.C) {
+	st := newFakeState()
+	st.relations = []params.RemoteRelation{consumerRelation(params.Alive)}
+	w := remoterelations.NewRemoteRelationsWorker(st)
+	defer func() { c.Assert(worker.Stop(w), gc.IsNil) }()
+
+	// The relation is not yet known to the consuming environment,
+	// so nothing is done. The worker only accepts the second
+	// event once it has handled the first.
+	sendChange(c, st)
+	sendChange(c, st)
+	c.Assert(st.Calls(), gc.HasLen, 0)
+
+	// Once the relation appears, the next change brings it
+	// into step.
+	st.mu.Lock()
+	st.units[params.RemoteRelationId{EnvUUID: consumerUUID, Key: relationKey}] = &params.RemoteRelationUnitsResult{
+		Life: params.Alive,
+	}
+	st.mu.Unlock()
+	sendChange(c, st)
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		if calls := st.Calls(); len(calls) > 0 {
+			c.Assert(calls, gc.DeepEquals, []string{"MirrorRelation consumer-uuid"})
+			return
+		}
+	}
+	c.Fatalf("relation not mirrored")
+}
+
+func sendChange(c *gc.C, st *fakeState) {
+	select {
+	case st.changes <- struct{}{}:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("worker did not accept change")
+	}
+}
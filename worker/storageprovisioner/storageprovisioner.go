@@ -0,0 +1,258 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storageprovisioner
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/state/api/params"
+	apistorageprovisioner "github.com/juju/juju/state/api/storageprovisioner"
+	"github.com/juju/juju/state/api/watcher"
+	"github.com/juju/juju/storage"
+	"github.com/juju/juju/storage/provider"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.storageprovisioner")
+
+var (
+	createFilesystem = provider.CreateFilesystem
+	removeFilesystem = provider.RemoveFilesystem
+)
+
+// StorageProvisioner creates and destroys the machine-scoped
+// volumes attached to the machine the agent is running on, and
+// creates and mounts filesystems on any volume attached to the
+// machine that backs filesystem storage.
+type StorageProvisioner struct {
+	st         *apistorageprovisioner.State
+	machineTag string
+	storageDir string
+	config     *config.Config
+}
+
+// NewStorageProvisioner returns a Worker that creates volumes attached
+// to the machine with the given tag as they are added to the state,
+// and destroys them once they are no longer required. Volume sources
+// keep their local state in storageDir, and filesystems are mounted
+// in its "mount" subdirectory.
+func NewStorageProvisioner(st *apistorageprovisioner.State, machineTag, storageDir string) worker.Worker {
+	sp := &StorageProvisioner{
+		st:         st,
+		machineTag: machineTag,
+		storageDir: storageDir,
+	}
+	return worker.NewStringsWorker(sp)
+}
+
+func (sp *StorageProvisioner) SetUp() (watcher.StringsWatcher, error) {
+	return sp.st.WatchVolumeAttachments(sp.machineTag)
+}
+
+// volumeSource returns the volume source of the
+// storage provider with the given type.
+func (sp *StorageProvisioner) volumeSource(provider storage.Provider) (storage.VolumeSource, error) {
+	if sp.config == nil {
+		cfg, err := sp.st.EnvironConfig()
+		if err != nil {
+			return nil, err
+		}
+		sp.config = cfg
+	}
+	return provider.VolumeSource(sp.config, sp.storageDir)
+}
+
+func (sp *StorageProvisioner) Handle(ids []string) error {
+	volumeNames := make([]string, len(ids))
+	for i, id := range ids {
+		// Volume attachment ids have the form "<machine id>:<volume name>".
+		volumeNames[i] = id[strings.LastIndex(id, ":")+1:]
+	}
+	results, err := sp.st.VolumeAttachments(sp.machineTag, volumeNames)
+	if err != nil {
+		return err
+	}
+	var providerTypes []storage.ProviderType
+	providers := make(map[storage.ProviderType]storage.Provider)
+	create := make(map[storage.ProviderType][]storage.VolumeParams)
+	destroy := make(map[storage.ProviderType][]string)
+	var remove []string
+	// filesystems holds the names of the volumes being
+	// created that are to have filesystems created on them.
+	filesystems := make(map[string]bool)
+	var mount []storage.VolumeAttachment
+	var unmount []string
+	for i, result := range results {
+		if result.Error != nil {
+			if params.IsCodeNotFound(result.Error) {
+				// The volume has already been removed.
+				continue
+			}
+			return fmt.Errorf("cannot get volume attachment %q: %v", ids[i], result.Error)
+		}
+		attachment := result.Result
+		// Filesystems are created on volumes of any scope,
+		// once they are attached, and removed before the
+		// volumes are destroyed.
+		switch {
+		case attachment.Life == params.Alive && attachment.Provisioned:
+			if attachment.Kind == storage.KindFilesystem && attachment.MountPoint == "" {
+				mount = append(mount, storage.VolumeAttachment{
+					Volume:     attachment.Params.Name,
+					DeviceName: attachment.DeviceName,
+				})
+			}
+		case attachment.Life != params.Alive && attachment.MountPoint != "":
+			unmount = append(unmount, attachment.MountPoint)
+		}
+		providerType := attachment.Params.Provider
+		provider, ok := providers[providerType]
+		if !ok {
+			provider, err = storage.StorageProvider(providerType)
+			if err != nil {
+				return err
+			}
+			providers[providerType] = provider
+			providerTypes = append(providerTypes, providerType)
+		}
+		if provider.Scope() != storage.ScopeMachine {
+			// Environ-scoped volumes are managed by the
+			// environment provisioner.
+			continue
+		}
+		switch {
+		case attachment.Life == params.Alive && !attachment.Provisioned:
+			create[providerType] = append(create[providerType], attachment.Params)
+			if attachment.Kind == storage.KindFilesystem {
+				filesystems[attachment.Params.Name] = true
+			}
+		case attachment.Life != params.Alive:
+			if attachment.Provisioned {
+				destroy[providerType] = append(destroy[providerType], attachment.VolumeId)
+			}
+			remove = append(remove, attachment.Params.Name)
+		}
+	}
+	for _, mountPoint := range unmount {
+		logger.Infof("removing filesystem mounted at %q", mountPoint)
+		if err := removeFilesystem(mountPoint); err != nil {
+			return err
+		}
+	}
+	for _, providerType := range providerTypes {
+		if len(create[providerType]) == 0 && len(destroy[providerType]) == 0 {
+			continue
+		}
+		source, err := sp.volumeSource(providers[providerType])
+		if err != nil {
+			return err
+		}
+		attachments, err := sp.createVolumes(source, create[providerType])
+		if err != nil {
+			return err
+		}
+		for _, attachment := range attachments {
+			if filesystems[attachment.Volume] {
+				mount = append(mount, attachment)
+			}
+		}
+		if volumeIds := destroy[providerType]; len(volumeIds) > 0 {
+			logger.Infof("destroying volumes %v", volumeIds)
+			if err := source.DestroyVolumes(volumeIds); err != nil {
+				return err
+			}
+		}
+	}
+	if err := sp.createFilesystems(mount); err != nil {
+		return err
+	}
+	if len(remove) == 0 {
+		return nil
+	}
+	errResults, err := sp.st.RemoveVolumes(sp.machineTag, remove)
+	if err != nil {
+		return err
+	}
+	for i, result := range errResults {
+		if result.Error != nil {
+			return fmt.Errorf("cannot remove volume %q: %v", remove[i], result.Error)
+		}
+	}
+	return nil
+}
+
+// createVolumes creates volumes with the given parameters,
+// records their details, and returns their attachments.
+func (sp *StorageProvisioner) createVolumes(source storage.VolumeSource, volumeParams []storage.VolumeParams) ([]storage.VolumeAttachment, error) {
+	if len(volumeParams) == 0 {
+		return nil, nil
+	}
+	volumes, attachments, err := source.CreateVolumes(volumeParams)
+	if err != nil {
+		return nil, err
+	}
+	if len(volumes) != len(attachments) {
+		return nil, fmt.Errorf("created %d volumes but %d attachments", len(volumes), len(attachments))
+	}
+	info := make([]params.VolumeInfo, len(volumes))
+	for i, volume := range volumes {
+		logger.Infof("created volume %q as %q", volume.Name, volume.VolumeId)
+		info[i] = params.VolumeInfo{
+			MachineTag: sp.machineTag,
+			Volume:     volume,
+			Attachment: attachments[i],
+		}
+	}
+	errResults, err := sp.st.SetVolumeInfo(info)
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range errResults {
+		if result.Error != nil {
+			return nil, fmt.Errorf("cannot set info for volume %q: %v", volumes[i].Name, result.Error)
+		}
+	}
+	return attachments, nil
+}
+
+// createFilesystems creates and mounts filesystems on the
+// given attached volumes, and records their mount points.
+func (sp *StorageProvisioner) createFilesystems(attachments []storage.VolumeAttachment) error {
+	if len(attachments) == 0 {
+		return nil
+	}
+	info := make([]params.FilesystemInfo, len(attachments))
+	for i, attachment := range attachments {
+		mountPoint := filepath.Join(sp.storageDir, "mount", attachment.Volume)
+		if err := createFilesystem(attachment.DeviceName, mountPoint); err != nil {
+			return fmt.Errorf("cannot create filesystem on volume %q: %v", attachment.Volume, err)
+		}
+		logger.Infof("mounted filesystem on volume %q at %q", attachment.Volume, mountPoint)
+		info[i] = params.FilesystemInfo{
+			MachineTag: sp.machineTag,
+			Volume:     attachment.Volume,
+			MountPoint: mountPoint,
+		}
+	}
+	errResults, err := sp.st.SetFilesystemInfo(info)
+	if err != nil {
+		return err
+	}
+	for i, result := range errResults {
+		if result.Error != nil {
+			return fmt.Errorf("cannot set mount point for volume %q: %v", info[i].Volume, result.Error)
+		}
+	}
+	return nil
+}
+
+func (sp *StorageProvisioner) TearDown() error {
+	// Nothing to do here.
+	return nil
+}
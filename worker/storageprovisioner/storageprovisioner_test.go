@@ -0,0 +1,242 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storageprovisioner_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	stdtesting "testing"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/api"
+	"github.com/juju/juju/storage"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/storageprovisioner"
+)
+
+func TestPackage(t *stdtesting.T) {
+	coretesting.MgoTestPackage(t)
+}
+
+// fakeProvider is a machine-scoped storage provider
+// that records the volumes it creates and destroys.
+type fakeProvider struct {
+	mu         sync.Mutex
+	storageDir string
+	destroyed  []string
+}
+
+var provider = &fakeProvider{}
+
+func init() {
+	storage.RegisterProvider("fake", provider)
+}
+
+func (p *fakeProvider) VolumeSource(environConfig *config.Config, storageDir string) (storage.VolumeSource, error) {
+	if environConfig == nil {
+		return nil, fmt.Errorf("no environment configuration")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.storageDir = storageDir
+	return p, nil
+}
+
+func (p *fakeProvider) Scope() storage.Scope {
+	return storage.ScopeMachine
+}
+
+func (p *fakeProvider) Dynamic() bool {
+	return true
+}
+
+func (p *fakeProvider) CreateVolumes(params []storage.VolumeParams) ([]storage.Volume, []storage.VolumeAttachment, error) {
+	var volumes []storage.Volume
+	var attachments []storage.VolumeAttachment
+	for _, param := range params {
+		volumes = append(volumes, storage.Volume{
+			Name:     param.Name,
+			VolumeId: "fake-" + param.Name,
+			Size:     param.Size,
+		})
+		attachments = append(attachments, storage.VolumeAttachment{
+			Volume:     param.Name,
+			Machine:    param.Attachment.Machine,
+			DeviceName: "fake" + param.Name,
+		})
+	}
+	return volumes, attachments, nil
+}
+
+func (p *fakeProvider) DestroyVolumes(volumeIds []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.destroyed = append(p.destroyed, volumeIds...)
+	return nil
+}
+
+type storageProvisionerSuite struct {
+	testing.JujuConnSuite
+
+	st      *api.State
+	machine *state.Machine
+
+	mu          sync.Mutex
+	filesystems []string
+}
+
+var _ = gc.Suite(&storageProvisionerSuite{})
+
+var _ worker.StringsWatchHandler = (*storageprovisioner.StorageProvisioner)(nil)
+
+func (s *storageProvisionerSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.st, s.machine = s.OpenAPIAsNewMachine(c, state.JobHostUnits)
+	provider.mu.Lock()
+	provider.storageDir = ""
+	provider.destroyed = nil
+	provider.mu.Unlock()
+	s.filesystems = nil
+	s.PatchValue(storageprovisioner.CreateFilesystem, func(deviceName, mountPoint string) error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.filesystems = append(s.filesystems, "create "+deviceName+" "+mountPoint)
+		return nil
+	})
+	s.PatchValue(storageprovisioner.RemoveFilesystem, func(mountPoint string) error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.filesystems = append(s.filesystems, "remove "+mountPoint)
+		return nil
+	})
+}
+
+func (s *storageProvisionerSuite) filesystemCalls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.filesystems...)
+}
+
+func (s *storageProvisionerSuite) waitVolumeInfo(c *gc.C, name string) state.VolumeInfo {
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		s.State.StartSync()
+		volume, err := s.State.Volume(name)
+		c.Assert(err, gc.IsNil)
+		if info, ok := volume.Info(); ok {
+			return info
+		}
+	}
+	c.Fatalf("volume %q was not provisioned", name)
+	panic("unreachable")
+}
+
+func (s *storageProvisionerSuite) waitVolumeRemoved(c *gc.C, name string) {
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		s.State.StartSync()
+		_, err := s.State.Volume(name)
+		if errors.IsNotFound(err) {
+			return
+		}
+		c.Assert(err, gc.IsNil)
+	}
+	c.Fatalf("volume %q was not removed", name)
+}
+
+func (s *storageProvisionerSuite) waitMountPoint(c *gc.C, name string) string {
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		s.State.StartSync()
+		attachment, err := s.State.VolumeAttachment(s.machine.Id(), name)
+		c.Assert(err, gc.IsNil)
+		if info, ok := attachment.Info(); ok && info.MountPoint != "" {
+			return info.MountPoint
+		}
+	}
+	c.Fatalf("filesystem on volume %q was not mounted", name)
+	panic("unreachable")
+}
+
+func (s *storageProvisionerSuite) TestStorageProvisioner(c *gc.C) {
+	storageDir := c.MkDir()
+	sp := storageprovisioner.NewStorageProvisioner(s.st.StorageProvisioner(), s.machine.Tag(), storageDir)
+	defer func() { c.Assert(worker.Stop(sp), gc.IsNil) }()
+
+	mysql := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	unit, err := mysql.AddUnitWithStorage(map[string]storage.Directive{
+		"data": {Pool: "fake", Size: 1024, Count: 2},
+	})
+	c.Assert(err, gc.IsNil)
+	err = unit.AssignToMachine(s.machine)
+	c.Assert(err, gc.IsNil)
+
+	for _, name := range []string{"0", "1"} {
+		info := s.waitVolumeInfo(c, name)
+		c.Assert(info, gc.Equals, state.VolumeInfo{VolumeId: "fake-" + name, Size: 1024})
+		attachment, err := s.State.VolumeAttachment(s.machine.Id(), name)
+		c.Assert(err, gc.IsNil)
+		attachmentInfo, ok := attachment.Info()
+		c.Assert(ok, jc.IsTrue)
+		c.Assert(attachmentInfo, gc.Equals, state.VolumeAttachmentInfo{DeviceName: "fake" + name})
+	}
+	provider.mu.Lock()
+	c.Assert(provider.storageDir, gc.Equals, storageDir)
+	provider.mu.Unlock()
+
+	// Once the unit is removed, its volumes are destroyed.
+	err = unit.EnsureDead()
+	c.Assert(err, gc.IsNil)
+	err = unit.Remove()
+	c.Assert(err, gc.IsNil)
+	err = s.State.Cleanup()
+	c.Assert(err, gc.IsNil)
+	s.waitVolumeRemoved(c, "0")
+	s.waitVolumeRemoved(c, "1")
+	provider.mu.Lock()
+	destroyed := append([]string(nil), provider.destroyed...)
+	provider.mu.Unlock()
+	sort.Strings(destroyed)
+	c.Assert(destroyed, gc.DeepEquals, []string{"fake-0", "fake-1"})
+}
+
+func (s *storageProvisionerSuite) TestStorageProvisionerFilesystem(c *gc.C) {
+	storageDir := c.MkDir()
+	sp := storageprovisioner.NewStorageProvisioner(s.st.StorageProvisioner(), s.machine.Tag(), storageDir)
+	defer func() { c.Assert(worker.Stop(sp), gc.IsNil) }()
+
+	mysql := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	unit, err := mysql.AddUnitWithStorage(map[string]storage.Directive{
+		"data": {Pool: "fake", Size: 1024, Count: 1, Kind: storage.KindFilesystem},
+	})
+	c.Assert(err, gc.IsNil)
+	err = unit.AssignToMachine(s.machine)
+	c.Assert(err, gc.IsNil)
+
+	mountPoint := s.waitMountPoint(c, "0")
+	c.Assert(mountPoint, gc.Equals, filepath.Join(storageDir, "mount", "0"))
+	c.Assert(s.filesystemCalls(), gc.DeepEquals, []string{"create fake0 " + mountPoint})
+
+	// The filesystem is removed before its volume is destroyed.
+	err = unit.EnsureDead()
+	c.Assert(err, gc.IsNil)
+	err = unit.Remove()
+	c.Assert(err, gc.IsNil)
+	err = s.State.Cleanup()
+	c.Assert(err, gc.IsNil)
+	s.waitVolumeRemoved(c, "0")
+	c.Assert(s.filesystemCalls(), gc.DeepEquals, []string{
+		"create fake0 " + mountPoint,
+		"remove " + mountPoint,
+	})
+	provider.mu.Lock()
+	c.Assert(provider.destroyed, gc.DeepEquals, []string{"fake-0"})
+	provider.mu.Unlock()
+}
@@ -0,0 +1,87 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"launchpad.net/goyaml"
+)
+
+// metricsPath is the path within a charm directory of the file
+// declaring the metrics the charm may report.
+const metricsPath = "metrics.yaml"
+
+// MetricType is the type of a metric declared by a charm.
+type MetricType string
+
+const (
+	// MetricTypeGauge is the type of metrics whose values
+	// may go up and down between reports.
+	MetricTypeGauge MetricType = "gauge"
+
+	// MetricTypeAbsolute is the type of metrics whose values
+	// may never be negative.
+	MetricTypeAbsolute MetricType = "absolute"
+)
+
+// Metric describes a metric declared by a charm.
+type Metric struct {
+	Type        MetricType `yaml:"type"`
+	Description string     `yaml:"description"`
+}
+
+// Metrics holds the metrics declared by a charm, keyed by name.
+type Metrics struct {
+	Metrics map[string]Metric `yaml:"metrics"`
+}
+
+// ParseMetrics parses the contents of a charm's metrics.yaml.
+func ParseMetrics(data []byte) (*Metrics, error) {
+	var metrics Metrics
+	if err := goyaml.Unmarshal(data, &metrics); err != nil {
+		return nil, fmt.Errorf("cannot parse metrics: %v", err)
+	}
+	for name, metric := range metrics.Metrics {
+		switch metric.Type {
+		case MetricTypeGauge, MetricTypeAbsolute:
+		default:
+			return nil, fmt.Errorf("metric %q has unknown type %q", name, metric.Type)
+		}
+	}
+	return &metrics, nil
+}
+
+// ReadMetrics returns the metrics declared by the charm in the given
+// directory, or nil if the charm declares none.
+func ReadMetrics(charmDir string) (*Metrics, error) {
+	data, err := ioutil.ReadFile(filepath.Join(charmDir, metricsPath))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return ParseMetrics(data)
+}
+
+// ValidateMetric checks that the metric with the given name is
+// declared and that the value is valid for it.
+func (m *Metrics) ValidateMetric(name, value string) error {
+	metric, ok := m.Metrics[name]
+	if !ok {
+		return fmt.Errorf("metric %q not declared", name)
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("invalid value %q for metric %q: not a number", value, name)
+	}
+	if metric.Type == MetricTypeAbsolute && f < 0 {
+		return fmt.Errorf("invalid value %q for metric %q: must not be negative", value, name)
+	}
+	return nil
+}
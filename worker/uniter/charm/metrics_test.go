@@ -0,0 +1,76 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/worker/uniter/charm"
+)
+
+type MetricsSuite struct{}
+
+var _ = gc.Suite(&MetricsSuite{})
+
+const metricsYaml = `
+metrics:
+  pings:
+    type: gauge
+    description: Pings received.
+  users:
+    type: absolute
+    description: Registered users.
+`
+
+func (s *MetricsSuite) TestReadMetrics(c *gc.C) {
+	dir := c.MkDir()
+	metrics, err := charm.ReadMetrics(dir)
+	c.Assert(err, gc.IsNil)
+	c.Assert(metrics, gc.IsNil)
+
+	err = ioutil.WriteFile(filepath.Join(dir, "metrics.yaml"), []byte(metricsYaml), 0644)
+	c.Assert(err, gc.IsNil)
+	metrics, err = charm.ReadMetrics(dir)
+	c.Assert(err, gc.IsNil)
+	c.Assert(metrics, gc.DeepEquals, &charm.Metrics{
+		Metrics: map[string]charm.Metric{
+			"pings": {Type: charm.MetricTypeGauge, Description: "Pings received."},
+			"users": {Type: charm.MetricTypeAbsolute, Description: "Registered users."},
+		},
+	})
+}
+
+func (s *MetricsSuite) TestParseMetricsInvalid(c *gc.C) {
+	_, err := charm.ParseMetrics([]byte("metrics: [1, 2]"))
+	c.Assert(err, gc.ErrorMatches, "cannot parse metrics: .*")
+	_, err = charm.ParseMetrics([]byte("metrics:\n  pings:\n    type: counter\n"))
+	c.Assert(err, gc.ErrorMatches, `metric "pings" has unknown type "counter"`)
+}
+
+func (s *MetricsSuite) TestValidateMetric(c *gc.C) {
+	metrics, err := charm.ParseMetrics([]byte(metricsYaml))
+	c.Assert(err, gc.IsNil)
+	for i, test := range []struct {
+		name, value string
+		err         string
+	}{
+		{"pings", "5", ""},
+		{"pings", "-2.5", ""},
+		{"users", "12", ""},
+		{"users", "-1", `invalid value "-1" for metric "users": must not be negative`},
+		{"pings", "many", `invalid value "many" for metric "pings": not a number`},
+		{"pongs", "1", `metric "pongs" not declared`},
+	} {
+		c.Logf("test %d: %s=%s", i, test.name, test.value)
+		err := metrics.ValidateMetric(test.name, test.value)
+		if test.err == "" {
+			c.Check(err, gc.IsNil)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.err)
+		}
+	}
+}
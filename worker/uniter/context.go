@@ -22,6 +22,7 @@ import (
 
 	"github.com/juju/juju/state/api/params"
 	"github.com/juju/juju/state/api/uniter"
+	ucharm "github.com/juju/juju/worker/uniter/charm"
 	unitdebug "github.com/juju/juju/worker/uniter/debug"
 	"github.com/juju/juju/worker/uniter/jujuc"
 )
@@ -85,6 +86,46 @@ type HookContext struct {
 
 	// proxySettings are the current proxy settings that the uniter knows about
 	proxySettings proxy.Settings
+
+	// actionData holds the state of the action being run. It is nil
+	// if the context is not running an action.
+	actionData *actionData
+
+	// storageId identifies the storage instance for which a storage
+	// hook is executing. It is empty if the context is not running a
+	// storage hook.
+	storageId string
+
+	// metricsDefs holds the metrics declared by the charm. It is nil
+	// unless the context is running the collect-metrics hook, the
+	// only hook in which metrics may be recorded.
+	metricsDefs *ucharm.Metrics
+
+	// metrics holds the metrics recorded by the collect-metrics
+	// hook, which are reported when the hook completes.
+	metrics []params.Metric
+}
+
+// actionData holds the parameters of a running action and the
+// results accumulated by the action-set and action-fail commands.
+type actionData struct {
+	ActionId       string
+	ActionName     string
+	ActionParams   map[string]interface{}
+	ActionFailed   bool
+	ResultsMessage string
+	ResultsMap     map[string]interface{}
+}
+
+// newActionData returns an actionData for the given action, ready to
+// accumulate results.
+func newActionData(id, name string, params map[string]interface{}) *actionData {
+	return &actionData{
+		ActionId:     id,
+		ActionName:   name,
+		ActionParams: params,
+		ResultsMap:   map[string]interface{}{},
+	}
 }
 
 func NewHookContext(unit *uniter.Unit, id, uuid, envName string,
@@ -112,6 +153,18 @@ func NewHookContext(unit *uniter.Unit, id, uuid, envName string,
 	if err != nil && !params.IsCodeNoAddressSet(err) {
 		return nil, err
 	}
+	// In a relation hook, the unit's private address is the one it
+	// advertises in the relation, which depends on the space (if
+	// any) the relation endpoint is bound to.
+	if relation, ok := relations[relationId]; ok && relationId != -1 {
+		address, err := relation.ru.PrivateAddress()
+		if err != nil && !params.IsCodeNoAddressSet(err) {
+			return nil, err
+		}
+		if address != "" {
+			ctx.privateAddress = address
+		}
+	}
 	return ctx, nil
 }
 
@@ -127,12 +180,20 @@ func (ctx *HookContext) PrivateAddress() (string, bool) {
 	return ctx.privateAddress, ctx.privateAddress != ""
 }
 
-func (ctx *HookContext) OpenPort(protocol string, port int) error {
-	return ctx.unit.OpenPort(protocol, port)
+func (ctx *HookContext) OpenPorts(protocol string, fromPort, toPort int) error {
+	// Single ports are opened with the older API call, so
+	// that charms keep working against older state servers.
+	if fromPort == toPort {
+		return ctx.unit.OpenPort(protocol, fromPort)
+	}
+	return ctx.unit.OpenPorts(protocol, fromPort, toPort)
 }
 
-func (ctx *HookContext) ClosePort(protocol string, port int) error {
-	return ctx.unit.ClosePort(protocol, port)
+func (ctx *HookContext) ClosePorts(protocol string, fromPort, toPort int) error {
+	if fromPort == toPort {
+		return ctx.unit.ClosePort(protocol, fromPort)
+	}
+	return ctx.unit.ClosePorts(protocol, fromPort, toPort)
 }
 
 func (ctx *HookContext) OwnerTag() string {
@@ -154,6 +215,123 @@ func (ctx *HookContext) ConfigSettings() (charm.Settings, error) {
 	return result, nil
 }
 
+// ActionParams returns the parameters of the running action.
+func (ctx *HookContext) ActionParams() (map[string]interface{}, error) {
+	if ctx.actionData == nil {
+		return nil, fmt.Errorf("not running an action")
+	}
+	return ctx.actionData.ActionParams, nil
+}
+
+// UpdateActionResults sets the value at the given dotted path of keys
+// in the results of the running action, creating intermediate maps
+// as necessary.
+func (ctx *HookContext) UpdateActionResults(keys []string, value string) error {
+	if ctx.actionData == nil {
+		return fmt.Errorf("not running an action")
+	}
+	addValueToMap(keys, value, ctx.actionData.ResultsMap)
+	return nil
+}
+
+// SetActionMessage sets the message reported with the results of the
+// running action.
+func (ctx *HookContext) SetActionMessage(message string) error {
+	if ctx.actionData == nil {
+		return fmt.Errorf("not running an action")
+	}
+	ctx.actionData.ResultsMessage = message
+	return nil
+}
+
+// SetActionFailed marks the running action as failed.
+func (ctx *HookContext) SetActionFailed() error {
+	if ctx.actionData == nil {
+		return fmt.Errorf("not running an action")
+	}
+	ctx.actionData.ActionFailed = true
+	return nil
+}
+
+// WorkloadStatus returns the status of the unit's workload,
+// and the message describing it.
+func (ctx *HookContext) WorkloadStatus() (params.WorkloadStatus, string, error) {
+	return ctx.unit.WorkloadStatus()
+}
+
+// SetWorkloadStatus sets the status of the unit's workload, and
+// the message describing it. Unlike relation settings, the status
+// is recorded immediately rather than when the hook completes, so
+// that a long-running hook can report its progress.
+func (ctx *HookContext) SetWorkloadStatus(status params.WorkloadStatus, message string) error {
+	return ctx.unit.SetWorkloadStatus(status, message)
+}
+
+// IsLeader returns whether the unit is the leader of its service.
+// It only asks; the uniter's filter claims and renews the leadership
+// on the unit's behalf, so a unit that is told it is leader remains
+// so for a while after.
+func (ctx *HookContext) IsLeader() (bool, error) {
+	return ctx.unit.IsLeader()
+}
+
+// LeaderSettings returns the settings written by the
+// leader of the unit's service.
+func (ctx *HookContext) LeaderSettings() (map[string]string, error) {
+	return ctx.unit.LeaderSettings()
+}
+
+// WriteLeaderSettings merges the given settings into the leader
+// settings of the unit's service. Like the workload status, they
+// are written immediately rather than when the hook completes.
+func (ctx *HookContext) WriteLeaderSettings(settings map[string]string) error {
+	return ctx.unit.MergeLeaderSettings(settings)
+}
+
+// HookStorageId returns the id of the storage instance for which
+// the executing storage hook is running, if any.
+func (ctx *HookContext) HookStorageId() (string, bool) {
+	return ctx.storageId, ctx.storageId != ""
+}
+
+// StorageInstances returns the storage instances owned by the unit.
+func (ctx *HookContext) StorageInstances() ([]params.StorageInstance, error) {
+	return ctx.unit.StorageInstances()
+}
+
+// AddMetric records a metric, collected at the given time, after
+// checking that it is declared by the charm. The metrics recorded by
+// a hook are reported together, as a single batch, if it succeeds.
+func (ctx *HookContext) AddMetric(key, value string, created time.Time) error {
+	if ctx.metricsDefs == nil {
+		return fmt.Errorf("metrics disabled")
+	}
+	if err := ctx.metricsDefs.ValidateMetric(key, value); err != nil {
+		return err
+	}
+	ctx.metrics = append(ctx.metrics, params.Metric{Key: key, Value: value, Time: created})
+	return nil
+}
+
+// addValueToMap adds value to target at the path described by keys,
+// replacing any non-map values found along the way.
+func addValueToMap(keys []string, value string, target map[string]interface{}) {
+	next := target
+	for i, key := range keys {
+		if i == len(keys)-1 {
+			next[key] = value
+			return
+		}
+		if m, ok := next[key].(map[string]interface{}); ok {
+			next = m
+			continue
+		}
+		m := map[string]interface{}{}
+		next[key] = m
+		next = m
+	}
+}
+
 func (ctx *HookContext) HookRelation() (jujuc.ContextRelation, bool) {
 	return ctx.Relation(ctx.relationId)
 }
@@ -197,6 +375,13 @@ func (ctx *HookContext) hookVars(charmDir, toolsDir, socketPath string) []string
 		name, _ := ctx.RemoteUnitName()
 		vars = append(vars, "JUJU_REMOTE_UNIT="+name)
 	}
+	if ctx.actionData != nil {
+		vars = append(vars, "JUJU_ACTION_NAME="+ctx.actionData.ActionName)
+		vars = append(vars, "JUJU_ACTION_ID="+ctx.actionData.ActionId)
+	}
+	if ctx.storageId != "" {
+		vars = append(vars, "JUJU_STORAGE_ID="+ctx.storageId)
+	}
 	vars = append(vars, ctx.proxySettings.AsEnvironmentValues()...)
 	return vars
 }
@@ -218,6 +403,16 @@ func (ctx *HookContext) finalizeContext(process string, err error) error {
 		}
 		rctx.ClearCache()
 	}
+	if writeChanges && len(ctx.metrics) > 0 {
+		if e := ctx.unit.AddMetrics(time.Now(), ctx.metrics); e != nil {
+			e = fmt.Errorf("could not report metrics from %q: %v", process, e)
+			logger.Errorf("%v", e)
+			if err == nil {
+				err = e
+			}
+		}
+	}
+	ctx.metrics = nil
 	return err
 }
 
@@ -245,15 +440,59 @@ func (ctx *HookContext) RunHook(hookName, charmDir, toolsDir, socketPath string)
 	debugctx := unitdebug.NewHooksContext(ctx.unit.Name())
 	if session, _ := debugctx.FindSession(); session != nil && session.MatchHook(hookName) {
 		logger.Infof("executing %s via debug-hooks", hookName)
-		err = session.RunHook(hookName, charmDir, env)
+		if session.Record() {
+			err = ctx.runRecordedDebugHook(session, hookName, charmDir, env)
+		} else {
+			err = session.RunHook(hookName, charmDir, env)
+		}
 	} else {
 		err = ctx.runCharmHook(hookName, charmDir, env)
 	}
 	return ctx.finalizeContext(hookName, err)
 }
 
+// runRecordedDebugHook runs the hook in the given debug-hooks session,
+// and stores the session's recording in the state server. Failure to
+// record the session is logged rather than treated as a hook failure.
+func (ctx *HookContext) runRecordedDebugHook(session *unitdebug.ServerSession, hookName, charmDir string, env []string) error {
+	recording, err := session.RecordHook(hookName, charmDir, env)
+	if recording == nil {
+		logger.Errorf("debug-hooks session for %s was not recorded", hookName)
+		return err
+	}
+	if len(recording.Transcript) > params.MaxDebugRecordingSize || len(recording.Timing) > params.MaxDebugRecordingSize {
+		logger.Errorf("recording of debug-hooks session for %s is larger than %d bytes; not storing it", hookName, params.MaxDebugRecordingSize)
+		return err
+	}
+	if e := ctx.unit.AddDebugRecording(
+		hookName, recording.Started, recording.Finished, recording.Transcript, recording.Timing,
+	); e != nil {
+		logger.Errorf("cannot store recording of debug-hooks session for %s: %v", hookName, e)
+	}
+	return err
+}
+
+// RunAction executes the named action from the charm's actions
+// directory in an environment which allows it to call back into the
+// hook context to execute jujuc tools. The context must have been
+// prepared for the action.
+func (ctx *HookContext) RunAction(actionName, charmDir, toolsDir, socketPath string) error {
+	if ctx.actionData == nil {
+		return fmt.Errorf("not running an action")
+	}
+	env := ctx.hookVars(charmDir, toolsDir, socketPath)
+	err := ctx.runCharmProcess("actions", actionName, charmDir, env)
+	return ctx.finalizeContext(actionName, err)
+}
+
 func (ctx *HookContext) runCharmHook(hookName, charmDir string, env []string) error {
-	hook, err := exec.LookPath(filepath.Join(charmDir, "hooks", hookName))
+	return ctx.runCharmProcess("hooks", hookName, charmDir, env)
+}
+
+// runCharmProcess runs the named executable from the given
+// subdirectory of charmDir, logging its output.
+func (ctx *HookContext) runCharmProcess(subdir, hookName, charmDir string, env []string) error {
+	hook, err := exec.LookPath(filepath.Join(charmDir, subdir, hookName))
 	if err != nil {
 		if ee, ok := err.(*exec.Error); ok && os.IsNotExist(ee.Err) {
 			// Missing hook is perfectly valid, but worth mentioning.
@@ -23,7 +23,9 @@ import (
 	"github.com/juju/juju/state/api"
 	"github.com/juju/juju/state/api/params"
 	apiuniter "github.com/juju/juju/state/api/uniter"
+	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/worker/uniter"
+	ucharm "github.com/juju/juju/worker/uniter/charm"
 	"github.com/juju/juju/worker/uniter/jujuc"
 )
 
@@ -626,6 +628,33 @@ func (s *InterfaceSuite) TestUnitCaching(c *gc.C) {
 	c.Assert(pr, gc.Equals, pa)
 }
 
+func (s *InterfaceSuite) TestPrivateAddressWithEndpointBinding(c *gc.C) {
+	err := s.machine.SetAddresses(
+		network.NewAddress("u-0.testing.invalid", network.ScopeCloudLocal),
+		network.NewAddress("10.0.1.4", network.ScopeCloudLocal),
+	)
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.AddSubnet(state.SubnetInfo{CIDR: "10.0.1.0/24"})
+	c.Assert(err, gc.IsNil)
+	_, err = s.State.AddSpace("db", []string{"10.0.1.0/24"})
+	c.Assert(err, gc.IsNil)
+	err = s.service.SetEndpointBindings(map[string]string{"db": "db"})
+	c.Assert(err, gc.IsNil)
+
+	// Outside relation hooks, the unit's private address is used.
+	ctx := s.GetContext(c, -1, "")
+	pr, ok := ctx.PrivateAddress()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(pr, gc.Equals, "u-0.testing.invalid")
+
+	// In a hook for a relation using the bound endpoint, the address
+	// in the space is used.
+	ctx = s.GetContext(c, 1, "")
+	pr, ok = ctx.PrivateAddress()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(pr, gc.Equals, "10.0.1.4")
+}
+
 func (s *InterfaceSuite) TestConfigCaching(c *gc.C) {
 	ctx := s.GetContext(c, -1, "")
 	settings, err := ctx.ConfigSettings()
@@ -644,6 +673,249 @@ func (s *InterfaceSuite) TestConfigCaching(c *gc.C) {
 	c.Assert(settings, gc.DeepEquals, charm.Settings{"blog-title": "My Title"})
 }
 
+func (s *InterfaceSuite) TestActionMethodsOutsideAction(c *gc.C) {
+	ctx := s.GetContext(c, -1, "")
+	_, err := ctx.ActionParams()
+	c.Assert(err, gc.ErrorMatches, "not running an action")
+	err = ctx.UpdateActionResults([]string{"foo"}, "bar")
+	c.Assert(err, gc.ErrorMatches, "not running an action")
+	err = ctx.SetActionMessage("message")
+	c.Assert(err, gc.ErrorMatches, "not running an action")
+	err = ctx.SetActionFailed()
+	c.Assert(err, gc.ErrorMatches, "not running an action")
+}
+
+func (s *InterfaceSuite) TestActionMethods(c *gc.C) {
+	uuid, err := utils.NewUUID()
+	c.Assert(err, gc.IsNil)
+	ctx := s.HookContextSuite.getHookContext(c, uuid.String(), -1, "", noProxies)
+	actionParams := map[string]interface{}{"outfile": "foo.bz2"}
+	results := uniter.SetActionData(ctx, "u#u/0#a#0", "snapshot", actionParams)
+
+	p, err := ctx.ActionParams()
+	c.Assert(err, gc.IsNil)
+	c.Assert(p, gc.DeepEquals, actionParams)
+
+	err = ctx.UpdateActionResults([]string{"outfile", "size"}, "10")
+	c.Assert(err, gc.IsNil)
+	err = ctx.UpdateActionResults([]string{"outfile", "name"}, "foo.bz2")
+	c.Assert(err, gc.IsNil)
+	err = ctx.UpdateActionResults([]string{"done"}, "yes")
+	c.Assert(err, gc.IsNil)
+	err = ctx.SetActionMessage("it broke")
+	c.Assert(err, gc.IsNil)
+	err = ctx.SetActionFailed()
+	c.Assert(err, gc.IsNil)
+
+	resultsMap, message, failed := results()
+	c.Assert(resultsMap, gc.DeepEquals, map[string]interface{}{
+		"outfile": map[string]interface{}{
+			"size": "10",
+			"name": "foo.bz2",
+		},
+		"done": "yes",
+	})
+	c.Assert(message, gc.Equals, "it broke")
+	c.Assert(failed, jc.IsTrue)
+}
+
+func (s *InterfaceSuite) TestWorkloadStatus(c *gc.C) {
+	ctx := s.GetContext(c, -1, "")
+	status, message, err := ctx.WorkloadStatus()
+	c.Assert(err, gc.IsNil)
+	c.Assert(status, gc.Equals, params.WorkloadUnknown)
+	c.Assert(message, gc.Equals, "")
+
+	// The status is recorded in state straight away.
+	err = ctx.SetWorkloadStatus(params.WorkloadMaintenance, "installing packages")
+	c.Assert(err, gc.IsNil)
+	status, message, err = s.unit.WorkloadStatus()
+	c.Assert(err, gc.IsNil)
+	c.Assert(status, gc.Equals, params.WorkloadMaintenance)
+	c.Assert(message, gc.Equals, "installing packages")
+
+	status, message, err = ctx.WorkloadStatus()
+	c.Assert(err, gc.IsNil)
+	c.Assert(status, gc.Equals, params.WorkloadMaintenance)
+	c.Assert(message, gc.Equals, "installing packages")
+}
+
+func (s *InterfaceSuite) TestLeadership(c *gc.C) {
+	ctx := s.GetContext(c, -1, "")
+	settings, err := ctx.LeaderSettings()
+	c.Assert(err, gc.IsNil)
+	c.Assert(settings, gc.HasLen, 0)
+
+	// Another unit holds the lease, so this one is not leader.
+	service, err := s.unit.Service()
+	c.Assert(err, gc.IsNil)
+	err = service.ClaimLeadership("u/1", time.Minute)
+	c.Assert(err, gc.IsNil)
+	isLeader, err := ctx.IsLeader()
+	c.Assert(err, gc.IsNil)
+	c.Assert(isLeader, jc.IsFalse)
+	err = ctx.WriteLeaderSettings(map[string]string{"foo": "bar"})
+	c.Assert(err, jc.Satisfies, params.IsCodeNotLeader)
+
+	// Once the lease is released, asking does not claim
+	// the leadership.
+	err = service.ClaimLeadership("u/1", time.Millisecond)
+	c.Assert(err, gc.IsNil)
+	time.Sleep(coretesting.ShortWait)
+	isLeader, err = ctx.IsLeader()
+	c.Assert(err, gc.IsNil)
+	c.Assert(isLeader, jc.IsFalse)
+	leader, err := service.Leader()
+	c.Assert(err, gc.IsNil)
+	c.Assert(leader, gc.Equals, "")
+
+	// When the unit has claimed the leadership, the
+	// settings are written straight away.
+	err = service.ClaimLeadership(s.unit.Name(), time.Minute)
+	c.Assert(err, gc.IsNil)
+	isLeader, err = ctx.IsLeader()
+	c.Assert(err, gc.IsNil)
+	c.Assert(isLeader, jc.IsTrue)
+	err = ctx.WriteLeaderSettings(map[string]string{"foo": "bar"})
+	c.Assert(err, gc.IsNil)
+	settings, err = service.LeaderSettings()
+	c.Assert(err, gc.IsNil)
+	c.Assert(settings, jc.DeepEquals, map[string]string{"foo": "bar"})
+
+	settings, err = ctx.LeaderSettings()
+	c.Assert(err, gc.IsNil)
+	c.Assert(settings, jc.DeepEquals, map[string]string{"foo": "bar"})
+}
+
+func (s *InterfaceSuite) TestOpenClosePorts(c *gc.C) {
+	ctx := s.GetContext(c, -1, "")
+	err := ctx.OpenPorts("tcp", 8000, 8080)
+	c.Assert(err, gc.IsNil)
+	err = ctx.OpenPorts("udp", 53, 53)
+	c.Assert(err, gc.IsNil)
+	err = s.unit.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.unit.OpenedPortRanges(), gc.DeepEquals, []network.PortRange{
+		{Protocol: "tcp", FromPort: 8000, ToPort: 8080},
+		{Protocol: "udp", FromPort: 53, ToPort: 53},
+	})
+
+	err = ctx.ClosePorts("tcp", 8000, 8080)
+	c.Assert(err, gc.IsNil)
+	err = ctx.ClosePorts("udp", 53, 53)
+	c.Assert(err, gc.IsNil)
+	err = s.unit.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.unit.OpenedPortRanges(), gc.HasLen, 0)
+}
+
+func (s *InterfaceSuite) TestRunAction(c *gc.C) {
+	uuid, err := utils.NewUUID()
+	c.Assert(err, gc.IsNil)
+	ctx := s.HookContextSuite.getHookContext(c, uuid.String(), -1, "", noProxies)
+
+	// An action can only be run by a context prepared for it.
+	charmDir := c.MkDir()
+	err = ctx.RunAction("snapshot", charmDir, "/path/to/tools", "/path/to/socket")
+	c.Assert(err, gc.ErrorMatches, "not running an action")
+
+	uniter.SetActionData(ctx, "u#u/0#a#0", "snapshot", nil)
+	err = ctx.RunAction("snapshot", charmDir, "/path/to/tools", "/path/to/socket")
+	c.Assert(err, jc.Satisfies, uniter.IsMissingHookError)
+
+	outPath := filepath.Join(c.MkDir(), "env")
+	err = os.Mkdir(filepath.Join(charmDir, "actions"), 0755)
+	c.Assert(err, gc.IsNil)
+	script := fmt.Sprintf("#!/bin/bash --norc\nenv > %s\n", outPath)
+	err = ioutil.WriteFile(filepath.Join(charmDir, "actions", "snapshot"), []byte(script), 0755)
+	c.Assert(err, gc.IsNil)
+	err = ctx.RunAction("snapshot", charmDir, "/path/to/tools", "/path/to/socket")
+	c.Assert(err, gc.IsNil)
+
+	out, err := ioutil.ReadFile(outPath)
+	c.Assert(err, gc.IsNil)
+	AssertEnvContains(c, strings.Split(string(out), "\n"), map[string]string{
+		"JUJU_ACTION_NAME": "snapshot",
+		"JUJU_ACTION_ID":   "u#u/0#a#0",
+		"CHARM_DIR":        charmDir,
+	})
+}
+
+func (s *InterfaceSuite) TestStorageHook(c *gc.C) {
+	uuid, err := utils.NewUUID()
+	c.Assert(err, gc.IsNil)
+	ctx := s.HookContextSuite.getHookContext(c, uuid.String(), -1, "", noProxies)
+	_, found := ctx.HookStorageId()
+	c.Assert(found, jc.IsFalse)
+	instances, err := ctx.StorageInstances()
+	c.Assert(err, gc.IsNil)
+	c.Assert(instances, gc.HasLen, 0)
+
+	uniter.SetStorageId(ctx, "data/0")
+	storageId, found := ctx.HookStorageId()
+	c.Assert(found, jc.IsTrue)
+	c.Assert(storageId, gc.Equals, "data/0")
+
+	charmDir := c.MkDir()
+	outPath := filepath.Join(c.MkDir(), "env")
+	err = os.Mkdir(filepath.Join(charmDir, "hooks"), 0755)
+	c.Assert(err, gc.IsNil)
+	script := fmt.Sprintf("#!/bin/bash --norc\nenv > %s\n", outPath)
+	err = ioutil.WriteFile(filepath.Join(charmDir, "hooks", "storage-attached"), []byte(script), 0755)
+	c.Assert(err, gc.IsNil)
+	err = ctx.RunHook("storage-attached", charmDir, "/path/to/tools", "/path/to/socket")
+	c.Assert(err, gc.IsNil)
+
+	out, err := ioutil.ReadFile(outPath)
+	c.Assert(err, gc.IsNil)
+	AssertEnvContains(c, strings.Split(string(out), "\n"), map[string]string{
+		"JUJU_STORAGE_ID": "data/0",
+		"CHARM_DIR":       charmDir,
+	})
+}
+
+func (s *InterfaceSuite) TestMetrics(c *gc.C) {
+	uuid, err := utils.NewUUID()
+	c.Assert(err, gc.IsNil)
+	ctx := s.HookContextSuite.getHookContext(c, uuid.String(), -1, "", noProxies)
+	now := time.Now()
+	err = ctx.AddMetric("pings", "5", now)
+	c.Assert(err, gc.ErrorMatches, "metrics disabled")
+
+	metrics, err := ucharm.ParseMetrics([]byte("metrics:\n  pings:\n    type: absolute\n"))
+	c.Assert(err, gc.IsNil)
+	uniter.SetMetricsDefs(ctx, metrics)
+	err = ctx.AddMetric("pongs", "5", now)
+	c.Assert(err, gc.ErrorMatches, `metric "pongs" not declared`)
+	err = ctx.AddMetric("pings", "-5", now)
+	c.Assert(err, gc.ErrorMatches, `invalid value "-5" for metric "pings": must not be negative`)
+
+	// Metrics recorded by a failing hook are discarded.
+	err = ctx.AddMetric("pings", "5", now)
+	c.Assert(err, gc.IsNil)
+	charmDir, _ := makeCharm(c, hookSpec{name: "collect-metrics", perm: 0700, code: 1})
+	err = ctx.RunHook("collect-metrics", charmDir, c.MkDir(), "/path/to/socket")
+	c.Assert(err, gc.ErrorMatches, "exit status 1")
+	batches, err := s.State.MetricBatches()
+	c.Assert(err, gc.IsNil)
+	c.Assert(batches, gc.HasLen, 0)
+
+	// Those recorded by a successful one are reported as a batch.
+	err = ctx.AddMetric("pings", "7", now)
+	c.Assert(err, gc.IsNil)
+	charmDir, _ = makeCharm(c, hookSpec{name: "collect-metrics", perm: 0700})
+	err = ctx.RunHook("collect-metrics", charmDir, c.MkDir(), "/path/to/socket")
+	c.Assert(err, gc.IsNil)
+	batches, err = s.State.MetricBatches()
+	c.Assert(err, gc.IsNil)
+	c.Assert(batches, gc.HasLen, 1)
+	c.Assert(batches[0].Unit(), gc.Equals, "u/0")
+	reported := batches[0].Metrics()
+	c.Assert(reported, gc.HasLen, 1)
+	c.Assert(reported[0].Key, gc.Equals, "pings")
+	c.Assert(reported[0].Value, gc.Equals, "7")
+}
+
 type HookContextSuite struct {
 	testing.JujuConnSuite
 	service  *state.Service
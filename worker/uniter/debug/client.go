@@ -11,12 +11,15 @@ import (
 )
 
 type hookArgs struct {
-	Hooks []string `yaml:"hooks,omitempty"`
+	Hooks  []string `yaml:"hooks,omitempty"`
+	Record bool     `yaml:"record,omitempty"`
 }
 
 // ClientScript returns a bash script suitable for executing
-// on the unit system to intercept hooks via tmux shell.
-func ClientScript(c *HooksContext, hooks []string) string {
+// on the unit system to intercept hooks via tmux shell. If
+// record is true, the shell sessions in which the hooks are
+// run will be recorded and stored by the state server.
+func ClientScript(c *HooksContext, hooks []string, record bool) string {
 	// If any hook is "*", then the client is interested in all.
 	for _, hook := range hooks {
 		if hook == "*" {
@@ -30,15 +33,15 @@ func ClientScript(c *HooksContext, hooks []string) string {
 	s = strings.Replace(s, "{entry_flock}", c.ClientFileLock(), -1)
 	s = strings.Replace(s, "{exit_flock}", c.ClientExitFileLock(), -1)
 
-	yamlArgs := encodeArgs(hooks)
+	yamlArgs := encodeArgs(hookArgs{Hooks: hooks, Record: record})
 	base64Args := base64.StdEncoding.EncodeToString(yamlArgs)
 	s = strings.Replace(s, "{hook_args}", base64Args, 1)
 	return s
 }
 
-func encodeArgs(hooks []string) []byte {
+func encodeArgs(args hookArgs) []byte {
 	// Marshal to YAML, then encode in base64 to avoid shell escapes.
-	yamlArgs, err := goyaml.Marshal(hookArgs{Hooks: hooks})
+	yamlArgs, err := goyaml.Marshal(args)
 	if err != nil {
 		// This should not happen: we're in full control.
 		panic(err)
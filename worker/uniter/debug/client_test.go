@@ -20,7 +20,7 @@ func (*DebugHooksClientSuite) TestClientScript(c *gc.C) {
 	ctx := debug.NewHooksContext("foo/8")
 
 	// Test the variable substitutions.
-	result := debug.ClientScript(ctx, nil)
+	result := debug.ClientScript(ctx, nil, false)
 	// No variables left behind.
 	c.Assert(result, gc.Matches, "[^{}]*")
 	// tmux new-session -d -s {unit_name}
@@ -33,9 +33,9 @@ func (*DebugHooksClientSuite) TestClientScript(c *gc.C) {
 	// nil is the same as empty slice is the same as "*".
 	// Also, if "*" is present as well as a named hook,
 	// it is equivalent to "*".
-	c.Assert(debug.ClientScript(ctx, nil), gc.Equals, debug.ClientScript(ctx, []string{}))
-	c.Assert(debug.ClientScript(ctx, []string{"*"}), gc.Equals, debug.ClientScript(ctx, nil))
-	c.Assert(debug.ClientScript(ctx, []string{"*", "something"}), gc.Equals, debug.ClientScript(ctx, []string{"*"}))
+	c.Assert(debug.ClientScript(ctx, nil, false), gc.Equals, debug.ClientScript(ctx, []string{}, false))
+	c.Assert(debug.ClientScript(ctx, []string{"*"}, false), gc.Equals, debug.ClientScript(ctx, nil, false))
+	c.Assert(debug.ClientScript(ctx, []string{"*", "something"}, false), gc.Equals, debug.ClientScript(ctx, []string{"*"}, false))
 
 	// debug.ClientScript does not validate hook names, as it doesn't have
 	// a full state API connection to determine valid relation hooks.
@@ -43,5 +43,12 @@ func (*DebugHooksClientSuite) TestClientScript(c *gc.C) {
 		`(.|\n)*echo "aG9va3M6Ci0gc29tZXRoaW5nIHNvbWV0aGluZ2Vsc2UK" | base64 -d > %s(.|\n)*`,
 		regexp.QuoteMeta(ctx.ClientFileLock()),
 	)
-	c.Assert(debug.ClientScript(ctx, []string{"something somethingelse"}), gc.Matches, expected)
+	c.Assert(debug.ClientScript(ctx, []string{"something somethingelse"}, false), gc.Matches, expected)
+
+	// Recording is requested through the same args.
+	expected = fmt.Sprintf(
+		`(.|\n)*echo "aG9va3M6Ci0gaW5zdGFsbApyZWNvcmQ6IHRydWUK" | base64 -d > %s(.|\n)*`,
+		regexp.QuoteMeta(ctx.ClientFileLock()),
+	)
+	c.Assert(debug.ClientScript(ctx, []string{"install"}, true), gc.Matches, expected)
 }
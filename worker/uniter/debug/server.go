@@ -9,6 +9,8 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"time"
 
 	"github.com/juju/utils/set"
 	"launchpad.net/goyaml"
@@ -17,7 +19,18 @@ import (
 // ServerSession represents a "juju debug-hooks" session.
 type ServerSession struct {
 	*HooksContext
-	hooks set.Strings
+	hooks  set.Strings
+	record bool
+}
+
+// Recording holds the transcript of a recorded debug-hooks session,
+// and the transcript's timings in the format written by script(1),
+// so that the session can be replayed with scriptreplay(1).
+type Recording struct {
+	Started    time.Time
+	Finished   time.Time
+	Transcript []byte
+	Timing     []byte
 }
 
 // MatchHook returns true if the specified hook name matches
@@ -33,9 +46,46 @@ var waitClientExit = func(s *ServerSession) {
 	exec.Command("flock", path, "-c", "true").Run()
 }
 
+// Record returns true if the debug-hooks client asked for
+// the sessions in which hooks are run to be recorded.
+func (s *ServerSession) Record() bool {
+	return s.record
+}
+
 // RunHook "runs" the hook with the specified name via debug-hooks.
 func (s *ServerSession) RunHook(hookName, charmDir string, env []string) error {
 	env = append(env, "JUJU_HOOK_NAME="+hookName)
+	return s.runHook(charmDir, env)
+}
+
+// RecordHook runs the hook like RunHook, recording the shell session in
+// which it is run. The recording is returned even if the hook fails; it
+// is nil only if the session's transcript could not be read.
+func (s *ServerSession) RecordHook(hookName, charmDir string, env []string) (*Recording, error) {
+	debugDir, err := ioutil.TempDir("", "juju-debug-hooks")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(debugDir)
+	env = append(env,
+		"JUJU_HOOK_NAME="+hookName,
+		"JUJU_DEBUG="+debugDir,
+		"JUJU_DEBUG_RECORD=1",
+	)
+	recording := &Recording{Started: time.Now()}
+	err = s.runHook(charmDir, env)
+	recording.Finished = time.Now()
+	var readErr error
+	if recording.Transcript, readErr = ioutil.ReadFile(filepath.Join(debugDir, "transcript")); readErr != nil {
+		return nil, err
+	}
+	if recording.Timing, readErr = ioutil.ReadFile(filepath.Join(debugDir, "timing")); readErr != nil {
+		return nil, err
+	}
+	return recording, err
+}
+
+func (s *ServerSession) runHook(charmDir string, env []string) error {
 	cmd := exec.Command("/bin/bash", "-s")
 	cmd.Env = env
 	cmd.Dir = charmDir
@@ -76,12 +126,12 @@ func (c *HooksContext) FindSession() (*ServerSession, error) {
 		return nil, err
 	}
 	hooks := set.NewStrings(args.Hooks...)
-	session := &ServerSession{c, hooks}
+	session := &ServerSession{c, hooks, args.Record}
 	return session, nil
 }
 
 const debugHooksServerScript = `set -e
-export JUJU_DEBUG=$(mktemp -d)
+export JUJU_DEBUG=${JUJU_DEBUG:-$(mktemp -d)}
 exec > $JUJU_DEBUG/debug.log >&1
 
 # Set a useful prompt.
@@ -91,12 +141,19 @@ export PS1="$JUJU_UNIT_NAME:$JUJU_HOOK_NAME % "
 FILTER='^\(LS_COLORS\|LESSOPEN\|LESSCLOSE\|PWD\)='
 export | grep -v $FILTER > $JUJU_DEBUG/env.sh
 
+# When recording, run the shell under script(1), which writes its
+# transcript and, to stderr, the timings needed to replay it.
+HOOK_SHELL="/bin/bash --noprofile --norc"
+if [ -n "$JUJU_DEBUG_RECORD" ]; then
+    HOOK_SHELL="script -q -f -t -c '$HOOK_SHELL' $JUJU_DEBUG/transcript 2> $JUJU_DEBUG/timing"
+fi
+
 # Create an internal script which will load the hook environment.
 cat > $JUJU_DEBUG/hook.sh <<END
 #!/bin/bash
 . $JUJU_DEBUG/env.sh
 echo \$\$ > $JUJU_DEBUG/hook.pid
-exec /bin/bash --noprofile --norc
+exec $HOOK_SHELL
 END
 chmod +x $JUJU_DEBUG/hook.sh
 
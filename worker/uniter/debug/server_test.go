@@ -89,6 +89,16 @@ func (s *DebugHooksServerSuite) TestFindSession(c *gc.C) {
 	c.Assert(session.MatchHook("bar"), jc.IsTrue)
 	c.Assert(session.MatchHook("baz"), jc.IsTrue)
 	c.Assert(session.MatchHook("foo bar baz"), jc.IsFalse)
+	c.Assert(session.Record(), jc.IsFalse)
+
+	// Hooks file asks for recording.
+	err = ioutil.WriteFile(s.ctx.ClientFileLock(), []byte(`record: true`), 0777)
+	c.Assert(err, gc.IsNil)
+	session, err = s.ctx.FindSession()
+	c.Assert(session, gc.NotNil)
+	c.Assert(err, gc.IsNil)
+	c.Assert(session.MatchHook("something"), jc.IsTrue)
+	c.Assert(session.Record(), jc.IsTrue)
 }
 
 func (s *DebugHooksServerSuite) TestRunHookExceptional(c *gc.C) {
@@ -197,3 +207,63 @@ func (s *DebugHooksServerSuite) verifyEnvshFile(c *gc.C, envshPath string, hookN
 	c.Assert(contents, jc.Contains, fmt.Sprintf("JUJU_HOOK_NAME=%q", hookName))
 	c.Assert(contents, jc.Contains, fmt.Sprintf(`PS1="%s:%s %% "`, s.ctx.Unit, hookName))
 }
+
+func (s *DebugHooksServerSuite) TestRecordHook(c *gc.C) {
+	err := ioutil.WriteFile(s.ctx.ClientFileLock(), []byte(`record: true`), 0777)
+	c.Assert(err, gc.IsNil)
+	session, err := s.ctx.FindSession()
+	c.Assert(session, gc.NotNil)
+	c.Assert(err, gc.IsNil)
+
+	const hookName = "myhook"
+
+	// As in TestRunHook, hold the exit flock, and write an invalid
+	// PID to the .pid file once the hook's debug dir appears, so
+	// that the server process exits cleanly.
+	cmd := exec.Command("flock", s.ctx.ClientExitFileLock(), "-c", "sleep 5s")
+	c.Assert(cmd.Start(), gc.IsNil)
+	defer cmd.Process.Kill()
+	type result struct {
+		recording *Recording
+		err       error
+	}
+	ch := make(chan result)
+	go func() {
+		recording, err := session.RecordHook(hookName, s.tmpdir, os.Environ())
+		ch <- result{recording, err}
+	}()
+
+	var debugdir string
+	for a := testing.LongAttempt.Start(); debugdir == "" && a.Next(); {
+		matches, err := filepath.Glob(filepath.Join(s.tmpdir, "juju-debug-hooks*", "hook.sh"))
+		c.Assert(err, gc.IsNil)
+		if len(matches) > 0 {
+			debugdir = filepath.Dir(matches[0])
+		}
+	}
+	c.Assert(debugdir, gc.Not(gc.Equals), "")
+
+	// The hook shell is run under script(1).
+	hooksh, err := ioutil.ReadFile(filepath.Join(debugdir, "hook.sh"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(hooksh), jc.Contains, "exec script -q -f -t")
+
+	// Simulate the session's transcript.
+	err = ioutil.WriteFile(filepath.Join(debugdir, "transcript"), []byte("$ ls\n"), 0644)
+	c.Assert(err, gc.IsNil)
+	err = ioutil.WriteFile(filepath.Join(debugdir, "timing"), []byte("0.1 5\n"), 0644)
+	c.Assert(err, gc.IsNil)
+	err = ioutil.WriteFile(filepath.Join(debugdir, "hook.pid"), []byte("not a pid"), 0777)
+	c.Assert(err, gc.IsNil)
+
+	r := <-ch
+	c.Assert(r.err, gc.IsNil)
+	c.Assert(r.recording, gc.NotNil)
+	c.Assert(string(r.recording.Transcript), gc.Equals, "$ ls\n")
+	c.Assert(string(r.recording.Timing), gc.Equals, "0.1 5\n")
+	c.Assert(r.recording.Finished.Before(r.recording.Started), jc.IsFalse)
+
+	// The debug dir is removed once the recording is read.
+	_, err = os.Stat(debugdir)
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
@@ -5,6 +5,8 @@ package uniter
 
 import (
 	"github.com/juju/utils/proxy"
+
+	"github.com/juju/juju/worker/uniter/charm"
 )
 
 func SetUniterObserver(u *Uniter, observer UniterExecutionObserver) {
@@ -16,3 +18,26 @@ func (u *Uniter) GetProxyValues() proxy.Settings {
 	defer u.proxyMutex.Unlock()
 	return u.proxy
 }
+
+// SetActionData prepares ctx to run the given action, and returns a
+// function that reports the accumulated results, message and failure
+// flag.
+func SetActionData(ctx *HookContext, id, name string, params map[string]interface{}) func() (map[string]interface{}, string, bool) {
+	ctx.actionData = newActionData(id, name, params)
+	return func() (map[string]interface{}, string, bool) {
+		data := ctx.actionData
+		return data.ResultsMap, data.ResultsMessage, data.ActionFailed
+	}
+}
+
+// SetStorageId prepares ctx to run a storage hook for
+// the given storage instance.
+func SetStorageId(ctx *HookContext, storageId string) {
+	ctx.storageId = storageId
+}
+
+// SetMetricsDefs prepares ctx to run the collect-metrics hook
+// for a charm declaring the given metrics.
+func SetMetricsDefs(ctx *HookContext, metrics *charm.Metrics) {
+	ctx.metricsDefs = metrics
+}
@@ -5,6 +5,7 @@ package uniter
 
 import (
 	"sort"
+	"time"
 
 	"github.com/juju/charm"
 	"github.com/juju/loggo"
@@ -20,6 +21,18 @@ import (
 
 var filterLogger = loggo.GetLogger("juju.worker.uniter.filter")
 
+// leaseDuration holds the length of the leadership lease claimed
+// on the unit's behalf, and leaseRenewal how often the claim is
+// repeated so that a leader keeps its lease.
+var (
+	leaseDuration = 30 * time.Second
+	leaseRenewal  = 15 * time.Second
+)
+
+// collectMetricsInterval holds how often the collect-metrics hook is
+// run, for charms that declare metrics.
+var collectMetricsInterval = 5 * time.Minute
+
 // filter collects unit, service, and service config information from separate
 // state watchers, and presents it as events on channels designed specifically
 // for the convenience of the uniter.
@@ -42,6 +55,23 @@ type filter struct {
 	outResolvedOn  chan params.ResolvedMode
 	outRelations   chan []int
 	outRelationsOn chan []int
+	outAction      chan string
+	outActionOn    chan string
+	// The update-status events are generated by a timer rather
+	// than by a watcher.
+	outUpdateStatus   chan struct{}
+	outUpdateStatusOn chan struct{}
+	// So are the collect-metrics events.
+	outCollectMetrics   chan struct{}
+	outCollectMetricsOn chan struct{}
+	// The leader-elected events are generated when a periodic
+	// leadership claim succeeds for a unit that was not leader.
+	outLeaderElected    chan struct{}
+	outLeaderElectedOn  chan struct{}
+	outLeaderSettings   chan struct{}
+	outLeaderSettingsOn chan struct{}
+	outStorage          chan struct{}
+	outStorageOn        chan struct{}
 
 	// The want* chans are used to indicate that the filter should send
 	// events if it has them available.
@@ -86,29 +116,43 @@ type filter struct {
 	upgradeAvailable serviceCharm
 	upgrade          *charm.URL
 	relations        []int
+	actionsPending   []string
+	isLeader         bool
 }
 
 // newFilter returns a filter that handles state changes pertaining to the
 // supplied unit.
 func newFilter(st *uniter.State, unitTag string) (*filter, error) {
 	f := &filter{
-		st:                st,
-		outUnitDying:      make(chan struct{}),
-		outConfig:         make(chan struct{}),
-		outConfigOn:       make(chan struct{}),
-		outUpgrade:        make(chan *charm.URL),
-		outUpgradeOn:      make(chan *charm.URL),
-		outResolved:       make(chan params.ResolvedMode),
-		outResolvedOn:     make(chan params.ResolvedMode),
-		outRelations:      make(chan []int),
-		outRelationsOn:    make(chan []int),
-		wantForcedUpgrade: make(chan bool),
-		wantResolved:      make(chan struct{}),
-		discardConfig:     make(chan struct{}),
-		setCharm:          make(chan *charm.URL),
-		didSetCharm:       make(chan struct{}),
-		clearResolved:     make(chan struct{}),
-		didClearResolved:  make(chan struct{}),
+		st:                  st,
+		outUnitDying:        make(chan struct{}),
+		outConfig:           make(chan struct{}),
+		outConfigOn:         make(chan struct{}),
+		outUpgrade:          make(chan *charm.URL),
+		outUpgradeOn:        make(chan *charm.URL),
+		outResolved:         make(chan params.ResolvedMode),
+		outResolvedOn:       make(chan params.ResolvedMode),
+		outRelations:        make(chan []int),
+		outRelationsOn:      make(chan []int),
+		outAction:           make(chan string),
+		outActionOn:         make(chan string),
+		outUpdateStatus:     make(chan struct{}),
+		outUpdateStatusOn:   make(chan struct{}),
+		outCollectMetrics:   make(chan struct{}),
+		outCollectMetricsOn: make(chan struct{}),
+		outLeaderElected:    make(chan struct{}),
+		outLeaderElectedOn:  make(chan struct{}),
+		outLeaderSettings:   make(chan struct{}),
+		outLeaderSettingsOn: make(chan struct{}),
+		outStorage:          make(chan struct{}),
+		outStorageOn:        make(chan struct{}),
+		wantForcedUpgrade:   make(chan bool),
+		wantResolved:        make(chan struct{}),
+		discardConfig:       make(chan struct{}),
+		setCharm:            make(chan *charm.URL),
+		didSetCharm:         make(chan struct{}),
+		clearResolved:       make(chan struct{}),
+		didClearResolved:    make(chan struct{}),
 	}
 	go func() {
 		defer f.tomb.Done()
@@ -164,6 +208,44 @@ func (f *filter) RelationsEvents() <-chan []int {
 	return f.outRelationsOn
 }
 
+// ActionEvents returns a channel that will receive the id of each action
+// queued for the unit, in the order they were queued.
+func (f *filter) ActionEvents() <-chan string {
+	return f.outActionOn
+}
+
+// UpdateStatusEvents returns a channel that will receive a signal
+// whenever the update-status hook is due to be run, as determined by
+// the environment's update-status-interval setting.
+func (f *filter) UpdateStatusEvents() <-chan struct{} {
+	return f.outUpdateStatusOn
+}
+
+// CollectMetricsEvents returns a channel that will receive a signal
+// whenever the collect-metrics hook is due to be run.
+func (f *filter) CollectMetricsEvents() <-chan struct{} {
+	return f.outCollectMetricsOn
+}
+
+// LeaderElectedEvents returns a channel that will receive a signal
+// whenever the unit becomes the leader of its service.
+func (f *filter) LeaderElectedEvents() <-chan struct{} {
+	return f.outLeaderElectedOn
+}
+
+// LeaderSettingsEvents returns a channel that will receive a signal
+// whenever the settings written by the service's leader change, while
+// the unit is not itself leader.
+func (f *filter) LeaderSettingsEvents() <-chan struct{} {
+	return f.outLeaderSettingsOn
+}
+
+// StorageEvents returns a channel that will receive a signal
+// whenever the storage instances owned by the unit change.
+func (f *filter) StorageEvents() <-chan struct{} {
+	return f.outStorageOn
+}
+
 // WantUpgradeEvent controls whether the filter will generate upgrade
 // events for unforced service charm changes.
 func (f *filter) WantUpgradeEvent(mustForce bool) {
@@ -301,6 +383,59 @@ func (f *filter) loop(unitTag string) (err error) {
 			watcher.Stop(relationsw, &f.tomb)
 		}
 	}()
+	var actionsChanges <-chan []string
+	actionsw, err := f.unit.WatchActions()
+	if params.IsCodeNotImplemented(err) {
+		// The state server is too old to run actions; carry on
+		// without them.
+		filterLogger.Warningf("actions not supported by state server: %v", err)
+	} else if err != nil {
+		return err
+	} else {
+		defer f.maybeStopWatcher(actionsw)
+		actionsChanges = actionsw.Changes()
+	}
+	// The environment watcher's initial event starts the
+	// update-status timer.
+	environw, err := f.st.WatchForEnvironConfigChanges()
+	if err != nil {
+		return err
+	}
+	defer f.maybeStopWatcher(environw)
+	var updateStatusInterval time.Duration
+	var updateStatusTimer <-chan time.Time
+	collectMetricsTimer := time.After(collectMetricsInterval)
+	// The unit's leadership is claimed before the leader settings
+	// watcher's initial event is handled, so that the leader is not
+	// told about its own settings.
+	var leaderSettingsChanges <-chan struct{}
+	var leaseTimer <-chan time.Time
+	leaderSettingsw, err := f.unit.WatchLeaderSettings()
+	if params.IsCodeNotImplemented(err) {
+		// The state server is too old to elect leaders; carry
+		// on without them.
+		filterLogger.Warningf("leadership not supported by state server: %v", err)
+	} else if err != nil {
+		return err
+	} else {
+		defer f.maybeStopWatcher(leaderSettingsw)
+		if err := f.claimLeadership(); err != nil {
+			return err
+		}
+		leaderSettingsChanges = leaderSettingsw.Changes()
+		leaseTimer = time.After(leaseRenewal)
+	}
+
+	var storageChanges <-chan struct{}
+	storagew, err := f.unit.WatchStorage()
+	if params.IsCodeNotImplemented(err) {
+		filterLogger.Warningf("storage not supported by state server: %v", err)
+	} else if err != nil {
+		return err
+	} else {
+		defer f.maybeStopWatcher(storagew)
+		storageChanges = storagew.Changes()
+	}
 
 	// Config events cannot be meaningfully discarded until one is available;
 	// once we receive the initial change, we unblock discard requests by
@@ -356,6 +491,55 @@ func (f *filter) loop(unitTag string) (err error) {
 				}
 			}
 			f.relationsChanged(ids)
+		case ids, ok := <-actionsChanges:
+			filterLogger.Debugf("got actions change")
+			if !ok {
+				return watcher.MustErr(actionsw)
+			}
+			f.actionsChanged(ids)
+		case _, ok = <-environw.Changes():
+			filterLogger.Debugf("got environment change")
+			if !ok {
+				return watcher.MustErr(environw)
+			}
+			environConfig, err := f.st.EnvironConfig()
+			if err != nil {
+				return err
+			}
+			if interval := environConfig.UpdateStatusInterval(); interval != updateStatusInterval {
+				filterLogger.Debugf("running update-status hook every %v", interval)
+				updateStatusInterval = interval
+				updateStatusTimer = time.After(interval)
+			}
+		case <-updateStatusTimer:
+			filterLogger.Debugf("preparing new update-status event")
+			f.outUpdateStatus = f.outUpdateStatusOn
+			updateStatusTimer = time.After(updateStatusInterval)
+		case <-collectMetricsTimer:
+			filterLogger.Debugf("preparing new collect-metrics event")
+			f.outCollectMetrics = f.outCollectMetricsOn
+			collectMetricsTimer = time.After(collectMetricsInterval)
+		case <-leaseTimer:
+			if err := f.claimLeadership(); err != nil {
+				return err
+			}
+			leaseTimer = time.After(leaseRenewal)
+		case _, ok = <-leaderSettingsChanges:
+			filterLogger.Debugf("got leader settings change")
+			if !ok {
+				return watcher.MustErr(leaderSettingsw)
+			}
+			if !f.isLeader {
+				filterLogger.Debugf("preparing new leader-settings-changed event")
+				f.outLeaderSettings = f.outLeaderSettingsOn
+			}
+		case _, ok = <-storageChanges:
+			filterLogger.Debugf("got storage change")
+			if !ok {
+				return watcher.MustErr(storagew)
+			}
+			filterLogger.Debugf("preparing new storage event")
+			f.outStorage = f.outStorageOn
 
 		// Send events on active out chans.
 		case f.outUpgrade <- f.upgrade:
@@ -371,6 +555,27 @@ func (f *filter) loop(unitTag string) (err error) {
 			filterLogger.Debugf("sent relations event")
 			f.outRelations = nil
 			f.relations = nil
+		case f.outAction <- f.nextAction():
+			filterLogger.Debugf("sent action event")
+			f.actionsPending = f.actionsPending[1:]
+			if len(f.actionsPending) == 0 {
+				f.outAction = nil
+			}
+		case f.outUpdateStatus <- nothing:
+			filterLogger.Debugf("sent update-status event")
+			f.outUpdateStatus = nil
+		case f.outCollectMetrics <- nothing:
+			filterLogger.Debugf("sent collect-metrics event")
+			f.outCollectMetrics = nil
+		case f.outLeaderElected <- nothing:
+			filterLogger.Debugf("sent leader-elected event")
+			f.outLeaderElected = nil
+		case f.outLeaderSettings <- nothing:
+			filterLogger.Debugf("sent leader-settings-changed event")
+			f.outLeaderSettings = nil
+		case f.outStorage <- nothing:
+			filterLogger.Debugf("sent storage event")
+			f.outStorage = nil
 
 		// Handle explicit requests.
 		case curl := <-f.setCharm:
@@ -523,6 +728,31 @@ func (f *filter) upgradeChanged() (err error) {
 	return nil
 }
 
+// claimLeadership claims the leadership of the unit's service, or
+// extends the unit's lease if it is already leader. It prepares a
+// leader-elected event when the unit becomes leader.
+func (f *filter) claimLeadership() error {
+	err := f.unit.ClaimLeadership(leaseDuration)
+	if params.IsCodeLeadershipClaimDenied(err) {
+		if f.isLeader {
+			filterLogger.Infof("unit is no longer service leader")
+		}
+		f.isLeader = false
+		f.outLeaderElected = nil
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !f.isLeader {
+		filterLogger.Infof("unit is now service leader")
+		f.isLeader = true
+		f.outLeaderElected = f.outLeaderElectedOn
+		// The leader wrote any settings changes itself.
+		f.outLeaderSettings = nil
+	}
+	return nil
+}
+
 // relationsChanged responds to service relation changes.
 func (f *filter) relationsChanged(ids []int) {
 outer:
@@ -540,6 +770,31 @@ outer:
 	}
 }
 
+// actionsChanged responds to newly queued actions.
+func (f *filter) actionsChanged(ids []string) {
+outer:
+	for _, id := range ids {
+		for _, existing := range f.actionsPending {
+			if id == existing {
+				continue outer
+			}
+		}
+		f.actionsPending = append(f.actionsPending, id)
+	}
+	if len(f.actionsPending) != 0 {
+		f.outAction = f.outActionOn
+	}
+}
+
+// nextAction returns the id of the next action to be sent, or the
+// empty string if no actions are pending.
+func (f *filter) nextAction() string {
+	if len(f.actionsPending) == 0 {
+		return ""
+	}
+	return f.actionsPending[0]
+}
+
 // serviceCharm holds information about a charm.
 type serviceCharm struct {
 	url   *charm.URL
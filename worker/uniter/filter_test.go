@@ -353,6 +353,135 @@ func (s *FilterSuite) TestConfigEvents(c *gc.C) {
 	assertChange()
 }
 
+func (s *FilterSuite) TestUpdateStatusEvents(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"update-status-interval": 1,
+	}, nil, nil)
+	c.Assert(err, gc.IsNil)
+	f, err := newFilter(s.uniter, s.unit.Tag())
+	c.Assert(err, gc.IsNil)
+	defer statetesting.AssertStop(c, f)
+
+	assertChange := func() {
+		s.BackingState.StartSync()
+		select {
+		case _, ok := <-f.UpdateStatusEvents():
+			c.Assert(ok, gc.Equals, true)
+		case <-time.After(coretesting.LongWait):
+			c.Fatalf("timed out")
+		}
+	}
+	// Events keep coming at the configured interval.
+	assertChange()
+	assertChange()
+
+	// Events stop while the interval is lengthened.
+	err = s.State.UpdateEnvironConfig(map[string]interface{}{
+		"update-status-interval": 3600,
+	}, nil, nil)
+	c.Assert(err, gc.IsNil)
+	s.BackingState.StartSync()
+	// Allow any event already pending to be delivered.
+	select {
+	case <-f.UpdateStatusEvents():
+	case <-time.After(2 * time.Second):
+	}
+	select {
+	case <-f.UpdateStatusEvents():
+		c.Fatalf("unexpected update-status event")
+	case <-time.After(2 * time.Second):
+	}
+}
+
+func (s *FilterSuite) TestLeaderEvents(c *gc.C) {
+	f, err := newFilter(s.uniter, s.unit.Tag())
+	c.Assert(err, gc.IsNil)
+	defer statetesting.AssertStop(c, f)
+
+	assertChange := func(ch <-chan struct{}) {
+		s.BackingState.StartSync()
+		select {
+		case _, ok := <-ch:
+			c.Assert(ok, gc.Equals, true)
+		case <-time.After(coretesting.LongWait):
+			c.Fatalf("timed out")
+		}
+	}
+	assertNoChange := func(ch <-chan struct{}) {
+		s.BackingState.StartSync()
+		select {
+		case <-ch:
+			c.Fatalf("unexpected event")
+		case <-time.After(coretesting.ShortWait):
+		}
+	}
+
+	// The service's only unit is elected leader, once.
+	assertChange(f.LeaderElectedEvents())
+	assertNoChange(f.LeaderElectedEvents())
+
+	// The leader is not told about its own settings.
+	err = s.wordpress.MergeLeaderSettings(s.unit.Name(), map[string]string{"foo": "bar"})
+	c.Assert(err, gc.IsNil)
+	assertNoChange(f.LeaderSettingsEvents())
+
+	// Another unit is not elected, but is told about the settings,
+	// initially and as they change.
+	unit1, err := s.wordpress.AddUnit()
+	c.Assert(err, gc.IsNil)
+	s.APILogin(c, unit1)
+	f1, err := newFilter(s.uniter, unit1.Tag())
+	c.Assert(err, gc.IsNil)
+	defer statetesting.AssertStop(c, f1)
+	assertChange(f1.LeaderSettingsEvents())
+	assertNoChange(f1.LeaderSettingsEvents())
+	assertNoChange(f1.LeaderElectedEvents())
+
+	err = s.wordpress.MergeLeaderSettings(s.unit.Name(), map[string]string{"foo": "baz"})
+	c.Assert(err, gc.IsNil)
+	assertChange(f1.LeaderSettingsEvents())
+	assertNoChange(f1.LeaderElectedEvents())
+}
+
+func (s *FilterSuite) TestStorageEvents(c *gc.C) {
+	f, err := newFilter(s.uniter, s.unit.Tag())
+	c.Assert(err, gc.IsNil)
+	defer statetesting.AssertStop(c, f)
+
+	// The initial event is sent, so that the uniter
+	// checks for storage attached while it was down.
+	s.BackingState.StartSync()
+	select {
+	case _, ok := <-f.StorageEvents():
+		c.Assert(ok, gc.Equals, true)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out")
+	}
+	s.BackingState.StartSync()
+	select {
+	case <-f.StorageEvents():
+		c.Fatalf("unexpected event")
+	case <-time.After(coretesting.ShortWait):
+	}
+}
+
+func (s *FilterSuite) TestCollectMetricsEvents(c *gc.C) {
+	s.PatchValue(&collectMetricsInterval, 10*time.Millisecond)
+	f, err := newFilter(s.uniter, s.unit.Tag())
+	c.Assert(err, gc.IsNil)
+	defer statetesting.AssertStop(c, f)
+
+	// Events keep coming at the set interval.
+	for i := 0; i < 2; i++ {
+		select {
+		case _, ok := <-f.CollectMetricsEvents():
+			c.Assert(ok, gc.Equals, true)
+		case <-time.After(coretesting.LongWait):
+			c.Fatalf("timed out")
+		}
+	}
+}
+
 func (s *FilterSuite) TestCharmErrorEvents(c *gc.C) {
 	f, err := newFilter(s.uniter, s.unit.Tag())
 	c.Assert(err, gc.IsNil)
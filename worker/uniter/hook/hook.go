@@ -10,6 +10,37 @@ import (
 	"github.com/juju/charm/hooks"
 )
 
+// UpdateStatus is the kind of the hook that the uniter runs
+// periodically, to let the charm check the health of its workload
+// and report it with status-set.
+const UpdateStatus hooks.Kind = "update-status"
+
+// CollectMetrics is the kind of the hook that the uniter runs
+// periodically, for charms that declare metrics, to let the charm
+// report them with add-metric.
+const CollectMetrics hooks.Kind = "collect-metrics"
+
+const (
+	// LeaderElected is the kind of the hook that the uniter runs
+	// when its unit becomes the leader of the unit's service.
+	LeaderElected hooks.Kind = "leader-elected"
+
+	// LeaderSettingsChanged is the kind of the hook that the uniter
+	// runs when the settings written by the service's leader change.
+	// It is not run on the leader itself.
+	LeaderSettingsChanged hooks.Kind = "leader-settings-changed"
+)
+
+const (
+	// StorageAttached is the kind of the hook that the uniter runs
+	// when a storage instance owned by its unit is first attached.
+	StorageAttached hooks.Kind = "storage-attached"
+
+	// StorageDetaching is the kind of the hook that the uniter runs
+	// for each attached storage instance before its unit is stopped.
+	StorageDetaching hooks.Kind = "storage-detaching"
+)
+
 // Info holds details required to execute a hook. Not all fields are
 // relevant to all Kind values.
 type Info struct {
@@ -26,6 +57,10 @@ type Info struct {
 	// ChangeVersion identifies the most recent unit settings change
 	// associated with RemoteUnit. It is only set when RemoteUnit is set.
 	ChangeVersion int64 `yaml:"change-version,omitempty"`
+
+	// StorageId identifies the storage instance associated with the
+	// hook. It is only set when Kind indicates a storage hook.
+	StorageId string `yaml:"storage-id,omitempty"`
 }
 
 // Validate returns an error if the info is not valid.
@@ -36,7 +71,13 @@ func (hi Info) Validate() error {
 			return fmt.Errorf("%q hook requires a remote unit", hi.Kind)
 		}
 		fallthrough
-	case hooks.Install, hooks.Start, hooks.ConfigChanged, hooks.UpgradeCharm, hooks.Stop, hooks.RelationBroken:
+	case hooks.Install, hooks.Start, hooks.ConfigChanged, hooks.UpgradeCharm, hooks.Stop, hooks.RelationBroken,
+		UpdateStatus, CollectMetrics, LeaderElected, LeaderSettingsChanged:
+		return nil
+	case StorageAttached, StorageDetaching:
+		if hi.StorageId == "" {
+			return fmt.Errorf("%q hook requires a storage id", hi.Kind)
+		}
 		return nil
 	}
 	return fmt.Errorf("unknown hook kind %q", hi.Kind)
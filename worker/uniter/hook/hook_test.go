@@ -36,6 +36,12 @@ var validateTests = []struct {
 	}, {
 		hook.Info{Kind: hooks.Kind("grok")},
 		`unknown hook kind "grok"`,
+	}, {
+		hook.Info{Kind: hook.StorageAttached},
+		`"storage-attached" hook requires a storage id`,
+	}, {
+		hook.Info{Kind: hook.StorageDetaching},
+		`"storage-detaching" hook requires a storage id`,
 	},
 	{hook.Info{Kind: hooks.Install}, ""},
 	{hook.Info{Kind: hooks.Start}, ""},
@@ -46,6 +52,12 @@ var validateTests = []struct {
 	{hook.Info{Kind: hooks.RelationChanged, RemoteUnit: "x"}, ""},
 	{hook.Info{Kind: hooks.RelationDeparted, RemoteUnit: "x"}, ""},
 	{hook.Info{Kind: hooks.RelationBroken}, ""},
+	{hook.Info{Kind: hook.UpdateStatus}, ""},
+	{hook.Info{Kind: hook.CollectMetrics}, ""},
+	{hook.Info{Kind: hook.LeaderElected}, ""},
+	{hook.Info{Kind: hook.LeaderSettingsChanged}, ""},
+	{hook.Info{Kind: hook.StorageAttached, StorageId: "data/0"}, ""},
+	{hook.Info{Kind: hook.StorageDetaching, StorageId: "data/0"}, ""},
 }
 
 func (s *InfoSuite) TestValidate(c *gc.C) {
@@ -0,0 +1,49 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"github.com/juju/cmd"
+)
+
+// ActionFailCommand implements the action-fail command.
+type ActionFailCommand struct {
+	cmd.CommandBase
+	ctx         Context
+	failMessage string
+}
+
+func NewActionFailCommand(ctx Context) cmd.Command {
+	return &ActionFailCommand{ctx: ctx}
+}
+
+func (c *ActionFailCommand) Info() *cmd.Info {
+	doc := `
+action-fail sets the action's fail state with a given error message.  Using
+action-fail without a failure message will set a default message indicating a
+problem with the action.
+`
+	return &cmd.Info{
+		Name:    "action-fail",
+		Args:    "[\"<failure message>\"]",
+		Purpose: "set action fail status with message",
+		Doc:     doc,
+	}
+}
+
+func (c *ActionFailCommand) Init(args []string) error {
+	c.failMessage = "action failed without reason given, check action for errors"
+	if len(args) > 0 {
+		c.failMessage = args[0]
+		args = args[1:]
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *ActionFailCommand) Run(ctx *cmd.Context) error {
+	if err := c.ctx.SetActionFailed(); err != nil {
+		return err
+	}
+	return c.ctx.SetActionMessage(c.failMessage)
+}
@@ -0,0 +1,54 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	"github.com/juju/cmd"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/jujuc"
+)
+
+type ActionFailSuite struct {
+	ContextSuite
+}
+
+var _ = gc.Suite(&ActionFailSuite{})
+
+var actionFailTests = []struct {
+	summary string
+	args    []string
+	code    int
+	err     string
+	message string
+}{{
+	summary: "no message gives a default",
+	message: "action failed without reason given, check action for errors",
+}, {
+	summary: "explicit message",
+	args:    []string{"disk full"},
+	message: "disk full",
+}, {
+	summary: "too many args",
+	args:    []string{"disk", "full"},
+	code:    2,
+	err:     "error: unrecognized args: [\"full\"]\n",
+}}
+
+func (s *ActionFailSuite) TestActionFail(c *gc.C) {
+	for i, t := range actionFailTests {
+		c.Logf("test %d: %s", i, t.summary)
+		hctx := s.GetHookContext(c, -1, "")
+		hctx.actionParams = map[string]interface{}{}
+		com, err := jujuc.NewCommand(hctx, "action-fail")
+		c.Assert(err, gc.IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Check(code, gc.Equals, t.code)
+		c.Check(bufferString(ctx.Stderr), gc.Equals, t.err)
+		c.Check(hctx.actionFailed, gc.Equals, t.code == 0)
+		c.Check(hctx.actionMessage, gc.Equals, t.message)
+	}
+}
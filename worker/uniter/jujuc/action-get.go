@@ -0,0 +1,97 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/cmd"
+	"launchpad.net/gnuflag"
+)
+
+// ActionGetCommand implements the action-get command.
+type ActionGetCommand struct {
+	cmd.CommandBase
+	ctx  Context
+	keys []string
+	out  cmd.Output
+}
+
+func NewActionGetCommand(ctx Context) cmd.Command {
+	return &ActionGetCommand{ctx: ctx}
+}
+
+func (c *ActionGetCommand) Info() *cmd.Info {
+	doc := `
+action-get will print the value of the parameter at the given key, serialized
+as YAML.  If multiple keys are passed, action-get will recurse into the param
+map as needed, using "." to separate the keys, e.g. "outfile.format".
+If no key is given, all parameters are printed.
+`
+	return &cmd.Info{
+		Name:    "action-get",
+		Args:    "[<key>[.<key>.<key>...]]",
+		Purpose: "get action parameters",
+		Doc:     doc,
+	}
+}
+
+func (c *ActionGetCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "smart", cmd.DefaultFormatters)
+}
+
+func (c *ActionGetCommand) Init(args []string) error {
+	if len(args) > 0 {
+		if args[0] == "" {
+			return fmt.Errorf("key must not be empty")
+		}
+		c.keys = strings.Split(args[0], ".")
+		for _, key := range c.keys {
+			if key == "" {
+				return fmt.Errorf("invalid key %q", args[0])
+			}
+		}
+		args = args[1:]
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *ActionGetCommand) Run(ctx *cmd.Context) error {
+	params, err := c.ctx.ActionParams()
+	if err != nil {
+		return err
+	}
+	value, _ := recurseMapOnKeys(c.keys, params)
+	return c.out.Write(ctx, value)
+}
+
+// recurseMapOnKeys returns the value found at the nested position
+// given by keys, and whether it was found. An empty keys slice
+// returns the whole map.
+func recurseMapOnKeys(keys []string, params map[string]interface{}) (interface{}, bool) {
+	if len(keys) == 0 {
+		return params, true
+	}
+	value, ok := params[keys[0]]
+	if !ok {
+		return nil, false
+	}
+	if len(keys) == 1 {
+		return value, true
+	}
+	switch next := value.(type) {
+	case map[string]interface{}:
+		return recurseMapOnKeys(keys[1:], next)
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{})
+		for k, v := range next {
+			if ks, ok := k.(string); ok {
+				converted[ks] = v
+			}
+		}
+		return recurseMapOnKeys(keys[1:], converted)
+	}
+	return nil, false
+}
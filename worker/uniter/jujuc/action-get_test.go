@@ -0,0 +1,83 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	"github.com/juju/cmd"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/jujuc"
+)
+
+type ActionGetSuite struct {
+	ContextSuite
+}
+
+var _ = gc.Suite(&ActionGetSuite{})
+
+var actionGetTests = []struct {
+	summary string
+	args    []string
+	code    int
+	out     string
+	err     string
+}{{
+	summary: "no key gives all params",
+	args:    []string{"--format", "yaml"},
+	out:     "outfile:\n  format: bz2\n  name: foo\nsnapshot: \"yes\"\n",
+}, {
+	summary: "top-level key",
+	args:    []string{"snapshot"},
+	out:     "yes\n",
+}, {
+	summary: "nested key",
+	args:    []string{"outfile.name"},
+	out:     "foo\n",
+}, {
+	summary: "missing key prints nothing",
+	args:    []string{"outfile.missing"},
+	out:     "",
+}, {
+	summary: "too many args",
+	args:    []string{"outfile", "snapshot"},
+	code:    2,
+	err:     "error: unrecognized args: [\"snapshot\"]\n",
+}, {
+	summary: "empty nested key",
+	args:    []string{"outfile..name"},
+	code:    2,
+	err:     "error: invalid key \"outfile..name\"\n",
+}}
+
+func (s *ActionGetSuite) TestActionGet(c *gc.C) {
+	for i, t := range actionGetTests {
+		c.Logf("test %d: %s", i, t.summary)
+		hctx := s.GetHookContext(c, -1, "")
+		hctx.actionParams = map[string]interface{}{
+			"snapshot": "yes",
+			"outfile": map[string]interface{}{
+				"name":   "foo",
+				"format": "bz2",
+			},
+		}
+		com, err := jujuc.NewCommand(hctx, "action-get")
+		c.Assert(err, gc.IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Check(code, gc.Equals, t.code)
+		c.Check(bufferString(ctx.Stdout), gc.Equals, t.out)
+		c.Check(bufferString(ctx.Stderr), gc.Equals, t.err)
+	}
+}
+
+func (s *ActionGetSuite) TestNotInAction(c *gc.C) {
+	hctx := s.GetHookContext(c, -1, "")
+	com, err := jujuc.NewCommand(hctx, "action-get")
+	c.Assert(err, gc.IsNil)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, nil)
+	c.Check(code, gc.Equals, 1)
+	c.Check(bufferString(ctx.Stderr), gc.Equals, "error: not running an action\n")
+}
@@ -0,0 +1,72 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/cmd"
+)
+
+// actionResult holds a single nested key and its value.
+type actionResult struct {
+	keys  []string
+	value string
+}
+
+// ActionSetCommand implements the action-set command.
+type ActionSetCommand struct {
+	cmd.CommandBase
+	ctx  Context
+	args []actionResult
+}
+
+func NewActionSetCommand(ctx Context) cmd.Command {
+	return &ActionSetCommand{ctx: ctx}
+}
+
+func (c *ActionSetCommand) Info() *cmd.Info {
+	doc := `
+action-set adds the given values to the results map of the Action.  This map
+is returned to the user after the completion of the Action.  Keys may be
+nested by separating them with ".", e.g. "outfile.size=10".
+`
+	return &cmd.Info{
+		Name:    "action-set",
+		Args:    "<key>=<value> [<key>=<value> ...]",
+		Purpose: "set action results",
+		Doc:     doc,
+	}
+}
+
+func (c *ActionSetCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no key=value pairs specified")
+	}
+	c.args = nil
+	for _, kv := range args {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return fmt.Errorf(`expected "key=value", got %q`, kv)
+		}
+		keys := strings.Split(parts[0], ".")
+		for _, key := range keys {
+			if key == "" {
+				return fmt.Errorf("invalid key %q", parts[0])
+			}
+		}
+		c.args = append(c.args, actionResult{keys, parts[1]})
+	}
+	return nil
+}
+
+func (c *ActionSetCommand) Run(ctx *cmd.Context) error {
+	for _, arg := range c.args {
+		if err := c.ctx.UpdateActionResults(arg.keys, arg.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	"github.com/juju/cmd"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/jujuc"
+)
+
+type ActionSetSuite struct {
+	ContextSuite
+}
+
+var _ = gc.Suite(&ActionSetSuite{})
+
+var actionSetTests = []struct {
+	summary string
+	args    []string
+	code    int
+	err     string
+	expect  map[string]interface{}
+}{{
+	summary: "no args",
+	code:    2,
+	err:     "error: no key=value pairs specified\n",
+}, {
+	summary: "bad pair",
+	args:    []string{"foo"},
+	code:    2,
+	err:     "error: expected \"key=value\", got \"foo\"\n",
+}, {
+	summary: "bad nested key",
+	args:    []string{"foo..bar=baz"},
+	code:    2,
+	err:     "error: invalid key \"foo..bar\"\n",
+}, {
+	summary: "simple and nested values",
+	args:    []string{"result=ok", "outfile.size=10", "outfile.name=foo.bz2"},
+	expect: map[string]interface{}{
+		"result": "ok",
+		"outfile": map[string]interface{}{
+			"size": "10",
+			"name": "foo.bz2",
+		},
+	},
+}}
+
+func (s *ActionSetSuite) TestActionSet(c *gc.C) {
+	for i, t := range actionSetTests {
+		c.Logf("test %d: %s", i, t.summary)
+		hctx := s.GetHookContext(c, -1, "")
+		hctx.actionParams = map[string]interface{}{}
+		com, err := jujuc.NewCommand(hctx, "action-set")
+		c.Assert(err, gc.IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Check(code, gc.Equals, t.code)
+		c.Check(bufferString(ctx.Stderr), gc.Equals, t.err)
+		c.Check(hctx.actionResults, jc.DeepEquals, t.expect)
+	}
+}
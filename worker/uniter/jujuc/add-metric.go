@@ -0,0 +1,74 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/juju/cmd"
+)
+
+// metric holds a single metric given to add-metric.
+type metric struct {
+	key   string
+	value string
+}
+
+// AddMetricCommand implements the add-metric command.
+type AddMetricCommand struct {
+	cmd.CommandBase
+	ctx     Context
+	metrics []metric
+}
+
+func NewAddMetricCommand(ctx Context) cmd.Command {
+	return &AddMetricCommand{ctx: ctx}
+}
+
+func (c *AddMetricCommand) Info() *cmd.Info {
+	doc := `
+add-metric records metrics to be reported to the state server once the hook
+completes successfully. It may only be used in the collect-metrics hook, and
+each metric must be declared in the charm's metrics.yaml. Values must be
+numbers; those of metrics of type absolute must not be negative.
+`
+	return &cmd.Info{
+		Name:    "add-metric",
+		Args:    "<key>=<value> [<key>=<value> ...]",
+		Purpose: "record metrics",
+		Doc:     doc,
+	}
+}
+
+func (c *AddMetricCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no metrics specified")
+	}
+	seen := make(map[string]bool)
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return fmt.Errorf(`expected "key=value", got %q`, arg)
+		}
+		if seen[parts[0]] {
+			return fmt.Errorf("metric %q specified more than once", parts[0])
+		}
+		seen[parts[0]] = true
+		c.metrics = append(c.metrics, metric{parts[0], parts[1]})
+	}
+	return nil
+}
+
+func (c *AddMetricCommand) Run(ctx *cmd.Context) error {
+	now := time.Now()
+	for _, m := range c.metrics {
+		if err := c.ctx.AddMetric(m.key, m.value, now); err != nil {
+			return fmt.Errorf("cannot record metric: %v", err)
+		}
+	}
+	return nil
+}
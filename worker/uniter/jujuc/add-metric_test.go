@@ -0,0 +1,76 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	"github.com/juju/cmd"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/jujuc"
+)
+
+type AddMetricSuite struct {
+	ContextSuite
+}
+
+var _ = gc.Suite(&AddMetricSuite{})
+
+var addMetricTests = []struct {
+	summary  string
+	disabled bool
+	args     []string
+	code     int
+	err      string
+	expected map[string]string
+}{{
+	summary:  "single metric",
+	args:     []string{"pings=5"},
+	expected: map[string]string{"pings": "5"},
+}, {
+	summary:  "several metrics",
+	args:     []string{"pings=5", "users=0.5"},
+	expected: map[string]string{"pings": "5", "users": "0.5"},
+}, {
+	summary: "no metrics",
+	code:    2,
+	err:     "error: no metrics specified\n",
+}, {
+	summary: "bad argument",
+	args:    []string{"pings"},
+	code:    2,
+	err:     "error: expected \"key=value\", got \"pings\"\n",
+}, {
+	summary: "repeated metric",
+	args:    []string{"pings=5", "pings=6"},
+	code:    2,
+	err:     "error: metric \"pings\" specified more than once\n",
+}, {
+	summary: "undeclared metric",
+	args:    []string{"undeclared=1"},
+	code:    1,
+	err:     "error: cannot record metric: metric \"undeclared\" not declared\n",
+}, {
+	summary:  "outside collect-metrics",
+	disabled: true,
+	args:     []string{"pings=5"},
+	code:     1,
+	err:      "error: cannot record metric: metrics disabled\n",
+}}
+
+func (s *AddMetricSuite) TestAddMetric(c *gc.C) {
+	for i, t := range addMetricTests {
+		c.Logf("test %d: %s", i, t.summary)
+		hctx := s.GetHookContext(c, -1, "")
+		hctx.metricsEnabled = !t.disabled
+		com, err := jujuc.NewCommand(hctx, "add-metric")
+		c.Assert(err, gc.IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Check(code, gc.Equals, t.code)
+		c.Check(bufferString(ctx.Stderr), gc.Equals, t.err)
+		c.Check(hctx.metrics, jc.DeepEquals, t.expected)
+	}
+}
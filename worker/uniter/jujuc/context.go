@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/juju/charm"
 
@@ -26,14 +27,14 @@ type Context interface {
 	// PrivateAddress returns the executing unit's private address.
 	PrivateAddress() (string, bool)
 
-	// OpenPort marks the supplied port for opening when the executing unit's
-	// service is exposed.
-	OpenPort(protocol string, port int) error
+	// OpenPorts marks the supplied port range for opening when the
+	// executing unit's service is exposed.
+	OpenPorts(protocol string, fromPort, toPort int) error
 
-	// ClosePort ensures the supplied port is closed even when the executing
-	// unit's service is exposed (unless it is opened separately by a co-
-	// located unit).
-	ClosePort(protocol string, port int) error
+	// ClosePorts ensures the supplied port range is closed even when
+	// the executing unit's service is exposed (unless it is opened
+	// separately by a co-located unit).
+	ClosePorts(protocol string, fromPort, toPort int) error
 
 	// Config returns the current service configuration of the executing unit.
 	ConfigSettings() (charm.Settings, error)
@@ -56,6 +57,58 @@ type Context interface {
 
 	// OwnerTag returns the owner of the service the executing units belongs to
 	OwnerTag() string
+
+	// ActionParams returns the parameters of the action being run, or
+	// an error if the context is not running an action.
+	ActionParams() (map[string]interface{}, error)
+
+	// UpdateActionResults sets the value at the nested position given
+	// by keys in the results of the action being run. The results are
+	// reported to the state server when the action finishes.
+	UpdateActionResults(keys []string, value string) error
+
+	// SetActionMessage sets the message reported when the action being
+	// run finishes.
+	SetActionMessage(message string) error
+
+	// SetActionFailed marks the action being run as failed.
+	SetActionFailed() error
+
+	// WorkloadStatus returns the status of the executing unit's
+	// workload, and the message describing it.
+	WorkloadStatus() (params.WorkloadStatus, string, error)
+
+	// SetWorkloadStatus sets the status of the executing unit's
+	// workload, along with a message describing it.
+	SetWorkloadStatus(status params.WorkloadStatus, message string) error
+
+	// IsLeader returns whether the executing unit is the leader
+	// of its service.
+	IsLeader() (bool, error)
+
+	// LeaderSettings returns the settings written by the leader
+	// of the executing unit's service.
+	LeaderSettings() (map[string]string, error)
+
+	// WriteLeaderSettings merges the given settings into the leader
+	// settings of the executing unit's service; settings with empty
+	// values are removed. It fails unless the unit is leader.
+	WriteLeaderSettings(settings map[string]string) error
+
+	// HookStorageId returns the id of the storage instance the
+	// executing hook is associated with if it was found, and
+	// whether it was found.
+	HookStorageId() (string, bool)
+
+	// StorageInstances returns the storage instances
+	// owned by the executing unit.
+	StorageInstances() ([]params.StorageInstance, error)
+
+	// AddMetric records a metric, collected at the given time, to be
+	// reported when the hook completes. It fails unless the context
+	// is running the collect-metrics hook, or if the metric is not
+	// declared by the charm.
+	AddMetric(key, value string, created time.Time) error
 }
 
 // ContextRelation expresses the capabilities of a hook with respect to a relation.
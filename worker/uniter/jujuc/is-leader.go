@@ -0,0 +1,49 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"github.com/juju/cmd"
+	"launchpad.net/gnuflag"
+)
+
+// IsLeaderCommand implements the is-leader command.
+type IsLeaderCommand struct {
+	cmd.CommandBase
+	ctx Context
+	out cmd.Output
+}
+
+func NewIsLeaderCommand(ctx Context) cmd.Command {
+	return &IsLeaderCommand{ctx: ctx}
+}
+
+func (c *IsLeaderCommand) Info() *cmd.Info {
+	doc := `
+is-leader prints a boolean indicating whether the executing unit is
+the leader of its service. Leadership is only guaranteed for a short
+time after is-leader returns true.
+`
+	return &cmd.Info{
+		Name:    "is-leader",
+		Purpose: "print service leadership status",
+		Doc:     doc,
+	}
+}
+
+func (c *IsLeaderCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "smart", cmd.DefaultFormatters)
+}
+
+func (c *IsLeaderCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+func (c *IsLeaderCommand) Run(ctx *cmd.Context) error {
+	isLeader, err := c.ctx.IsLeader()
+	if err != nil {
+		return err
+	}
+	return c.out.Write(ctx, isLeader)
+}
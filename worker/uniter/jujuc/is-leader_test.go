@@ -0,0 +1,54 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	"github.com/juju/cmd"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/jujuc"
+)
+
+type IsLeaderSuite struct {
+	ContextSuite
+}
+
+var _ = gc.Suite(&IsLeaderSuite{})
+
+var isLeaderTests = []struct {
+	isLeader bool
+	args     []string
+	out      string
+}{
+	{true, nil, "True\n"},
+	{false, nil, "False\n"},
+	{true, []string{"--format", "json"}, "true\n"},
+	{false, []string{"--format", "yaml"}, "false\n"},
+}
+
+func (s *IsLeaderSuite) TestIsLeader(c *gc.C) {
+	for i, t := range isLeaderTests {
+		c.Logf("test %d: %v %v", i, t.isLeader, t.args)
+		hctx := s.GetHookContext(c, -1, "")
+		hctx.isLeader = t.isLeader
+		com, err := jujuc.NewCommand(hctx, "is-leader")
+		c.Assert(err, gc.IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Check(code, gc.Equals, 0)
+		c.Check(bufferString(ctx.Stderr), gc.Equals, "")
+		c.Check(bufferString(ctx.Stdout), gc.Equals, t.out)
+	}
+}
+
+func (s *IsLeaderSuite) TestUnexpectedArgs(c *gc.C) {
+	hctx := s.GetHookContext(c, -1, "")
+	com, err := jujuc.NewCommand(hctx, "is-leader")
+	c.Assert(err, gc.IsNil)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"blah"})
+	c.Assert(code, gc.Equals, 2)
+	c.Assert(bufferString(ctx.Stderr), gc.Equals, "error: unrecognized args: [\"blah\"]\n")
+}
@@ -0,0 +1,60 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"github.com/juju/cmd"
+	"launchpad.net/gnuflag"
+)
+
+// LeaderGetCommand implements the leader-get command.
+type LeaderGetCommand struct {
+	cmd.CommandBase
+	ctx Context
+	Key string // The key to show. If empty, show all.
+	out cmd.Output
+}
+
+func NewLeaderGetCommand(ctx Context) cmd.Command {
+	return &LeaderGetCommand{ctx: ctx}
+}
+
+func (c *LeaderGetCommand) Info() *cmd.Info {
+	doc := `
+leader-get prints the value of a leader setting specified by key. If no key
+is given, all settings written by the service's leader are printed.
+`
+	return &cmd.Info{
+		Name:    "leader-get",
+		Args:    "[<key>]",
+		Purpose: "print service leader settings",
+		Doc:     doc,
+	}
+}
+
+func (c *LeaderGetCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "smart", cmd.DefaultFormatters)
+}
+
+func (c *LeaderGetCommand) Init(args []string) error {
+	if args == nil {
+		return nil
+	}
+	c.Key = args[0]
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *LeaderGetCommand) Run(ctx *cmd.Context) error {
+	settings, err := c.ctx.LeaderSettings()
+	if err != nil {
+		return err
+	}
+	var value interface{}
+	if c.Key == "" {
+		value = settings
+	} else if v, ok := settings[c.Key]; ok {
+		value = v
+	}
+	return c.out.Write(ctx, value)
+}
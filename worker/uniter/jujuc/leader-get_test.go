@@ -0,0 +1,58 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	"github.com/juju/cmd"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/jujuc"
+)
+
+type LeaderGetSuite struct {
+	ContextSuite
+}
+
+var _ = gc.Suite(&LeaderGetSuite{})
+
+var leaderGetTests = []struct {
+	args []string
+	out  string
+}{
+	{nil, "master: 10.0.0.1\ntoken: secret\n"},
+	{[]string{"--format", "json"}, `{"master":"10.0.0.1","token":"secret"}` + "\n"},
+	{[]string{"master"}, "10.0.0.1\n"},
+	{[]string{"master", "--format", "json"}, `"10.0.0.1"` + "\n"},
+	{[]string{"missing"}, ""},
+	{[]string{"missing", "--format", "json"}, "null\n"},
+}
+
+func (s *LeaderGetSuite) TestOutputFormat(c *gc.C) {
+	for i, t := range leaderGetTests {
+		c.Logf("test %d: %v", i, t.args)
+		hctx := s.GetHookContext(c, -1, "")
+		hctx.leaderSettings = map[string]string{
+			"master": "10.0.0.1",
+			"token":  "secret",
+		}
+		com, err := jujuc.NewCommand(hctx, "leader-get")
+		c.Assert(err, gc.IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Check(code, gc.Equals, 0)
+		c.Check(bufferString(ctx.Stderr), gc.Equals, "")
+		c.Check(bufferString(ctx.Stdout), gc.Equals, t.out)
+	}
+}
+
+func (s *LeaderGetSuite) TestUnexpectedArgs(c *gc.C) {
+	hctx := s.GetHookContext(c, -1, "")
+	com, err := jujuc.NewCommand(hctx, "leader-get")
+	c.Assert(err, gc.IsNil)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"master", "token"})
+	c.Assert(code, gc.Equals, 2)
+	c.Assert(bufferString(ctx.Stderr), gc.Equals, "error: unrecognized args: [\"token\"]\n")
+}
@@ -0,0 +1,51 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/cmd"
+)
+
+// LeaderSetCommand implements the leader-set command.
+type LeaderSetCommand struct {
+	cmd.CommandBase
+	ctx      Context
+	Settings map[string]string
+}
+
+func NewLeaderSetCommand(ctx Context) cmd.Command {
+	return &LeaderSetCommand{ctx: ctx, Settings: map[string]string{}}
+}
+
+func (c *LeaderSetCommand) Info() *cmd.Info {
+	doc := `
+leader-set writes settings that every unit of the service can read with
+leader-get. Settings with empty values are removed. Only the service's
+leader may write its settings.
+`
+	return &cmd.Info{
+		Name:    "leader-set",
+		Args:    "key=value [key=value ...]",
+		Purpose: "write service leader settings",
+		Doc:     doc,
+	}
+}
+
+func (c *LeaderSetCommand) Init(args []string) error {
+	for _, kv := range args {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return fmt.Errorf(`expected "key=value", got %q`, kv)
+		}
+		c.Settings[parts[0]] = parts[1]
+	}
+	return nil
+}
+
+func (c *LeaderSetCommand) Run(ctx *cmd.Context) error {
+	return c.ctx.WriteLeaderSettings(c.Settings)
+}
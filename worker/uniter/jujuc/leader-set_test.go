@@ -0,0 +1,70 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	"github.com/juju/cmd"
+	jc "github.com/juju/testing/checkers"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/jujuc"
+)
+
+type LeaderSetSuite struct {
+	ContextSuite
+}
+
+var _ = gc.Suite(&LeaderSetSuite{})
+
+var leaderSetTests = []struct {
+	summary  string
+	isLeader bool
+	args     []string
+	code     int
+	err      string
+	expected map[string]string
+}{{
+	summary:  "set and delete settings",
+	isLeader: true,
+	args:     []string{"master=10.0.0.2", "token="},
+	expected: map[string]string{"master": "10.0.0.2"},
+}, {
+	summary:  "value containing =",
+	isLeader: true,
+	args:     []string{"query=a=b"},
+	expected: map[string]string{"master": "10.0.0.1", "token": "secret", "query": "a=b"},
+}, {
+	summary:  "bad argument",
+	isLeader: true,
+	args:     []string{"master"},
+	code:     2,
+	err:      "error: expected \"key=value\", got \"master\"\n",
+	expected: map[string]string{"master": "10.0.0.1", "token": "secret"},
+}, {
+	summary:  "not leader",
+	args:     []string{"master=10.0.0.2"},
+	code:     1,
+	err:      "error: cannot write leader settings: unit is not the service leader\n",
+	expected: map[string]string{"master": "10.0.0.1", "token": "secret"},
+}}
+
+func (s *LeaderSetSuite) TestLeaderSet(c *gc.C) {
+	for i, t := range leaderSetTests {
+		c.Logf("test %d: %s", i, t.summary)
+		hctx := s.GetHookContext(c, -1, "")
+		hctx.isLeader = t.isLeader
+		hctx.leaderSettings = map[string]string{
+			"master": "10.0.0.1",
+			"token":  "secret",
+		}
+		com, err := jujuc.NewCommand(hctx, "leader-set")
+		c.Assert(err, gc.IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Check(code, gc.Equals, t.code)
+		c.Check(bufferString(ctx.Stderr), gc.Equals, t.err)
+		c.Check(hctx.leaderSettings, jc.DeepEquals, t.expected)
+	}
+}
@@ -13,7 +13,7 @@ import (
 	"launchpad.net/gnuflag"
 )
 
-const portFormat = "<port>[/<protocol>]"
+const portFormat = "<port>[-<port>][/<protocol>]"
 
 // portCommand implements the open-port and close-port commands.
 type portCommand struct {
@@ -21,7 +21,8 @@ type portCommand struct {
 	info       *cmd.Info
 	action     func(*portCommand) error
 	Protocol   string
-	Port       int
+	FromPort   int
+	ToPort     int
 	formatFlag string // deprecated
 }
 
@@ -33,6 +34,17 @@ func badPort(value interface{}) error {
 	return fmt.Errorf(`port must be in the range [1, 65535]; got "%v"`, value)
 }
 
+func parsePort(s string) (int, error) {
+	port, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, badPort(s)
+	}
+	if port < 1 || port > 65535 {
+		return 0, badPort(port)
+	}
+	return port, nil
+}
+
 func (c *portCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.StringVar(&c.formatFlag, "format", "", "deprecated format flag")
 }
@@ -45,12 +57,22 @@ func (c *portCommand) Init(args []string) error {
 	if len(parts) > 2 {
 		return fmt.Errorf("expected %s; got %q", portFormat, args[0])
 	}
-	port, err := strconv.Atoi(parts[0])
+	ports := strings.Split(parts[0], "-")
+	if len(ports) > 2 {
+		return fmt.Errorf("expected %s; got %q", portFormat, args[0])
+	}
+	fromPort, err := parsePort(ports[0])
 	if err != nil {
-		return badPort(parts[0])
+		return err
 	}
-	if port < 1 || port > 65535 {
-		return badPort(port)
+	toPort := fromPort
+	if len(ports) == 2 {
+		if toPort, err = parsePort(ports[1]); err != nil {
+			return err
+		}
+		if fromPort > toPort {
+			return fmt.Errorf("port range must not end before it starts; got %q", parts[0])
+		}
 	}
 	protocol := "tcp"
 	if len(parts) == 2 {
@@ -59,7 +81,8 @@ func (c *portCommand) Init(args []string) error {
 			return fmt.Errorf(`protocol must be "tcp" or "udp"; got %q`, protocol)
 		}
 	}
-	c.Port = port
+	c.FromPort = fromPort
+	c.ToPort = toPort
 	c.Protocol = protocol
 	return cmd.CheckEmpty(args[1:])
 }
@@ -74,15 +97,18 @@ func (c *portCommand) Run(ctx *cmd.Context) error {
 var openPortInfo = &cmd.Info{
 	Name:    "open-port",
 	Args:    portFormat,
-	Purpose: "register a port to open",
-	Doc:     "The port will only be open while the service is exposed.",
+	Purpose: "register a port or range of ports to open",
+	Doc: `
+The ports will only be open while the service is exposed. A range of
+ports is given as, for example, 8000-8080/tcp.
+`,
 }
 
 func NewOpenPortCommand(ctx Context) cmd.Command {
 	return &portCommand{
 		info: openPortInfo,
 		action: func(c *portCommand) error {
-			return ctx.OpenPort(c.Protocol, c.Port)
+			return ctx.OpenPorts(c.Protocol, c.FromPort, c.ToPort)
 		},
 	}
 }
@@ -90,14 +116,14 @@ func NewOpenPortCommand(ctx Context) cmd.Command {
 var closePortInfo = &cmd.Info{
 	Name:    "close-port",
 	Args:    portFormat,
-	Purpose: "ensure a port is always closed",
+	Purpose: "ensure a port or range of ports is always closed",
 }
 
 func NewClosePortCommand(ctx Context) cmd.Command {
 	return &portCommand{
 		info: closePortInfo,
 		action: func(c *portCommand) error {
-			return ctx.ClosePort(c.Protocol, c.Port)
+			return ctx.ClosePorts(c.Protocol, c.FromPort, c.ToPort)
 		},
 	}
 }
@@ -27,6 +27,8 @@ var portsTests = []struct {
 	{[]string{"close-port", "80/TCP"}, set.NewStrings("99/tcp")},
 	{[]string{"open-port", "123/udp"}, set.NewStrings("99/tcp", "123/udp")},
 	{[]string{"close-port", "9999/UDP"}, set.NewStrings("99/tcp", "123/udp")},
+	{[]string{"open-port", "8000-8080/tcp"}, set.NewStrings("99/tcp", "123/udp", "8000-8080/tcp")},
+	{[]string{"close-port", "8000-8080"}, set.NewStrings("99/tcp", "123/udp")},
 }
 
 func (s *PortsSuite) TestOpenClose(c *gc.C) {
@@ -52,7 +54,10 @@ var badPortsTests = []struct {
 	{[]string{"65536"}, `port must be in the range \[1, 65535\]; got "65536"`},
 	{[]string{"two"}, `port must be in the range \[1, 65535\]; got "two"`},
 	{[]string{"80/http"}, `protocol must be "tcp" or "udp"; got "http"`},
-	{[]string{"blah/blah/blah"}, `expected <port>\[/<protocol>\]; got "blah/blah/blah"`},
+	{[]string{"blah/blah/blah"}, `expected <port>\[-<port>\]\[/<protocol>\]; got "blah/blah/blah"`},
+	{[]string{"1-2-3"}, `expected <port>\[-<port>\]\[/<protocol>\]; got "1-2-3"`},
+	{[]string{"80-0"}, `port must be in the range \[1, 65535\]; got "0"`},
+	{[]string{"90-80/tcp"}, `port range must not end before it starts; got "90-80"`},
 	{[]string{"123", "haha"}, `unrecognized args: \["haha"\]`},
 }
 
@@ -74,17 +79,18 @@ func (s *PortsSuite) TestHelp(c *gc.C) {
 	c.Assert(err, gc.IsNil)
 	flags := testing.NewFlagSet()
 	c.Assert(string(open.Info().Help(flags)), gc.Equals, `
-usage: open-port <port>[/<protocol>]
-purpose: register a port to open
+usage: open-port <port>[-<port>][/<protocol>]
+purpose: register a port or range of ports to open
 
-The port will only be open while the service is exposed.
+The ports will only be open while the service is exposed. A range of
+ports is given as, for example, 8000-8080/tcp.
 `[1:])
 
 	close, err := jujuc.NewCommand(hctx, "close-port")
 	c.Assert(err, gc.IsNil)
 	c.Assert(string(close.Info().Help(flags)), gc.Equals, `
-usage: close-port <port>[/<protocol>]
-purpose: ensure a port is always closed
+usage: close-port <port>[-<port>][/<protocol>]
+purpose: ensure a port or range of ports is always closed
 `[1:])
 }
 
@@ -24,14 +24,25 @@ var logger = loggo.GetLogger("worker.uniter.jujuc")
 
 // newCommands maps Command names to initializers.
 var newCommands = map[string]func(Context) cmd.Command{
+	"action-fail":   NewActionFailCommand,
+	"action-get":    NewActionGetCommand,
+	"action-set":    NewActionSetCommand,
+	"add-metric":    NewAddMetricCommand,
 	"close-port":    NewClosePortCommand,
 	"config-get":    NewConfigGetCommand,
+	"is-leader":     NewIsLeaderCommand,
 	"juju-log":      NewJujuLogCommand,
+	"leader-get":    NewLeaderGetCommand,
+	"leader-set":    NewLeaderSetCommand,
 	"open-port":     NewOpenPortCommand,
 	"relation-get":  NewRelationGetCommand,
 	"relation-ids":  NewRelationIdsCommand,
 	"relation-list": NewRelationListCommand,
 	"relation-set":  NewRelationSetCommand,
+	"status-get":    NewStatusGetCommand,
+	"status-set":    NewStatusSetCommand,
+	"storage-get":   NewStorageGetCommand,
+	"storage-list":  NewStorageListCommand,
 	"unit-get":      NewUnitGetCommand,
 	"owner-get":     NewOwnerGetCommand,
 }
@@ -191,14 +191,25 @@ var newCommandTests = []struct {
 	name string
 	err  string
 }{
+	{"action-fail", ""},
+	{"action-get", ""},
+	{"action-set", ""},
+	{"add-metric", ""},
 	{"close-port", ""},
 	{"config-get", ""},
+	{"is-leader", ""},
 	{"juju-log", ""},
+	{"leader-get", ""},
+	{"leader-set", ""},
 	{"open-port", ""},
 	{"relation-get", ""},
 	{"relation-ids", ""},
 	{"relation-list", ""},
 	{"relation-set", ""},
+	{"status-get", ""},
+	{"status-set", ""},
+	{"storage-get", ""},
+	{"storage-list", ""},
 	{"unit-get", ""},
 	{"random", "unknown command: random"},
 }
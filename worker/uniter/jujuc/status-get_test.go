@@ -0,0 +1,55 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	"github.com/juju/cmd"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/jujuc"
+)
+
+type StatusGetSuite struct {
+	ContextSuite
+}
+
+var _ = gc.Suite(&StatusGetSuite{})
+
+var statusGetTests = []struct {
+	args []string
+	out  string
+}{
+	{nil, "blocked\n"},
+	{[]string{"--format", "json"}, `"blocked"` + "\n"},
+	{[]string{"--include-message"}, "message: need a database\nstatus: blocked\n"},
+	{[]string{"--include-message", "--format", "json"}, `{"message":"need a database","status":"blocked"}` + "\n"},
+}
+
+func (s *StatusGetSuite) TestOutputFormat(c *gc.C) {
+	for i, t := range statusGetTests {
+		c.Logf("test %d: %v", i, t.args)
+		hctx := s.GetHookContext(c, -1, "")
+		hctx.workloadStatus = params.WorkloadBlocked
+		hctx.workloadMessage = "need a database"
+		com, err := jujuc.NewCommand(hctx, "status-get")
+		c.Assert(err, gc.IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Check(code, gc.Equals, 0)
+		c.Check(bufferString(ctx.Stderr), gc.Equals, "")
+		c.Check(bufferString(ctx.Stdout), gc.Equals, t.out)
+	}
+}
+
+func (s *StatusGetSuite) TestUnknownStatus(c *gc.C) {
+	hctx := s.GetHookContext(c, -1, "")
+	com, err := jujuc.NewCommand(hctx, "status-get")
+	c.Assert(err, gc.IsNil)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, nil)
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(bufferString(ctx.Stdout), gc.Equals, "unknown\n")
+}
@@ -0,0 +1,65 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/juju/cmd"
+
+	"github.com/juju/juju/state/api/params"
+)
+
+// StatusSetCommand implements the status-set command.
+type StatusSetCommand struct {
+	cmd.CommandBase
+	ctx     Context
+	status  params.WorkloadStatus
+	message string
+}
+
+func NewStatusSetCommand(ctx Context) cmd.Command {
+	return &StatusSetCommand{ctx: ctx}
+}
+
+func (c *StatusSetCommand) Info() *cmd.Info {
+	doc := `
+status-set sets the status of the unit's workload, as shown by juju status,
+along with an optional message describing it. The status is one of:
+
+    maintenance  the unit is not yet providing its service, but is
+                 actively working towards it
+    blocked      the unit needs manual intervention to get going
+    waiting      the unit is waiting on another unit or service
+    active       the unit is ready and providing its service
+`
+	return &cmd.Info{
+		Name:    "status-set",
+		Args:    "<maintenance | blocked | waiting | active> [\"<message>\"]",
+		Purpose: "set status of the unit's workload",
+		Doc:     doc,
+	}
+}
+
+func (c *StatusSetCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no status specified")
+	}
+	status := params.WorkloadStatus(args[0])
+	if !status.Valid() || status == params.WorkloadUnknown {
+		return fmt.Errorf("invalid status %q", args[0])
+	}
+	c.status = status
+	args = args[1:]
+	if len(args) > 0 {
+		c.message = args[0]
+		args = args[1:]
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *StatusSetCommand) Run(ctx *cmd.Context) error {
+	return c.ctx.SetWorkloadStatus(c.status, c.message)
+}
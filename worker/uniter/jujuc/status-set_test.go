@@ -0,0 +1,71 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	"github.com/juju/cmd"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/jujuc"
+)
+
+type StatusSetSuite struct {
+	ContextSuite
+}
+
+var _ = gc.Suite(&StatusSetSuite{})
+
+var statusSetTests = []struct {
+	summary string
+	args    []string
+	code    int
+	err     string
+	status  params.WorkloadStatus
+	message string
+}{{
+	summary: "status only",
+	args:    []string{"active"},
+	status:  params.WorkloadActive,
+}, {
+	summary: "status and message",
+	args:    []string{"blocked", "need a database"},
+	status:  params.WorkloadBlocked,
+	message: "need a database",
+}, {
+	summary: "no status",
+	code:    2,
+	err:     "error: no status specified\n",
+}, {
+	summary: "invalid status",
+	args:    []string{"sleepy"},
+	code:    2,
+	err:     "error: invalid status \"sleepy\"\n",
+}, {
+	summary: "unknown cannot be set",
+	args:    []string{"unknown"},
+	code:    2,
+	err:     "error: invalid status \"unknown\"\n",
+}, {
+	summary: "too many args",
+	args:    []string{"waiting", "for", "mysql"},
+	code:    2,
+	err:     "error: unrecognized args: [\"mysql\"]\n",
+}}
+
+func (s *StatusSetSuite) TestStatusSet(c *gc.C) {
+	for i, t := range statusSetTests {
+		c.Logf("test %d: %s", i, t.summary)
+		hctx := s.GetHookContext(c, -1, "")
+		com, err := jujuc.NewCommand(hctx, "status-set")
+		c.Assert(err, gc.IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Check(code, gc.Equals, t.code)
+		c.Check(bufferString(ctx.Stderr), gc.Equals, t.err)
+		c.Check(hctx.workloadStatus, gc.Equals, t.status)
+		c.Check(hctx.workloadMessage, gc.Equals, t.message)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+	"launchpad.net/gnuflag"
+)
+
+// StorageGetCommand implements the storage-get command.
+type StorageGetCommand struct {
+	cmd.CommandBase
+	ctx       Context
+	StorageId string
+	Key       string // The key to show. If empty, show all.
+	out       cmd.Output
+}
+
+func NewStorageGetCommand(ctx Context) cmd.Command {
+	return &StorageGetCommand{ctx: ctx}
+}
+
+func (c *StorageGetCommand) Info() *cmd.Info {
+	doc := `
+storage-get prints information about a storage instance owned by the unit.
+The key may be "id", "name", "kind" or "location"; if no key is given, all
+of them are printed. The kind is "block" or "filesystem". The location of a
+block storage instance is the path of the device backing it, and is empty
+until the device has been attached; that of a filesystem is the directory it
+is mounted at, and is empty until it has been mounted.
+In storage hooks, the storage instance defaults to the one the hook is
+about.
+`
+	return &cmd.Info{
+		Name:    "storage-get",
+		Args:    "[<key>]",
+		Purpose: "print information about a storage instance",
+		Doc:     doc,
+	}
+}
+
+func (c *StorageGetCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "smart", cmd.DefaultFormatters)
+	storageId, _ := c.ctx.HookStorageId()
+	f.StringVar(&c.StorageId, "s", storageId, "specify a storage instance by id")
+}
+
+func (c *StorageGetCommand) Init(args []string) error {
+	if c.StorageId == "" {
+		return fmt.Errorf("no storage instance specified")
+	}
+	if args == nil {
+		return nil
+	}
+	c.Key = args[0]
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *StorageGetCommand) Run(ctx *cmd.Context) error {
+	instances, err := c.ctx.StorageInstances()
+	if err != nil {
+		return err
+	}
+	for _, instance := range instances {
+		if instance.Id != c.StorageId {
+			continue
+		}
+		values := map[string]interface{}{
+			"id":       instance.Id,
+			"name":     instance.Name,
+			"kind":     instance.Kind,
+			"location": instance.Location,
+		}
+		if c.Key == "" {
+			return c.out.Write(ctx, values)
+		}
+		value, ok := values[c.Key]
+		if !ok {
+			return fmt.Errorf("unknown key %q", c.Key)
+		}
+		return c.out.Write(ctx, value)
+	}
+	return fmt.Errorf("storage instance %q not found", c.StorageId)
+}
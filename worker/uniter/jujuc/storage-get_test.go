@@ -0,0 +1,89 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	"github.com/juju/cmd"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/jujuc"
+)
+
+type storageSuite struct {
+	ContextSuite
+}
+
+// GetStorageHookContext returns a hook context for the storage
+// hook of the given storage instance, owning two instances of
+// the "data" store and one of the "logs" store.
+func (s *storageSuite) GetStorageHookContext(c *gc.C, storageId string) *Context {
+	hctx := s.GetHookContext(c, -1, "")
+	hctx.storageId = storageId
+	hctx.storage = []params.StorageInstance{
+		{Id: "data/0", Name: "data", Kind: "block", Location: "/dev/xvdf"},
+		{Id: "data/1", Name: "data", Kind: "block"},
+		{Id: "logs/2", Name: "logs", Kind: "block", Location: "/dev/loop0"},
+	}
+	return hctx
+}
+
+type StorageGetSuite struct {
+	storageSuite
+}
+
+var _ = gc.Suite(&StorageGetSuite{})
+
+var storageGetTests = []struct {
+	storageId string
+	args      []string
+	out       string
+}{
+	{"data/0", nil, "id: data/0\nkind: block\nlocation: /dev/xvdf\nname: data\n"},
+	{"data/0", []string{"location"}, "/dev/xvdf\n"},
+	{"data/0", []string{"location", "--format", "json"}, `"/dev/xvdf"` + "\n"},
+	{"data/1", []string{"location"}, ""},
+	{"", []string{"-s", "logs/2", "name"}, "logs\n"},
+	{"data/0", []string{"-s", "logs/2", "location"}, "/dev/loop0\n"},
+}
+
+func (s *StorageGetSuite) TestOutputFormat(c *gc.C) {
+	for i, t := range storageGetTests {
+		c.Logf("test %d: %v", i, t.args)
+		hctx := s.GetStorageHookContext(c, t.storageId)
+		com, err := jujuc.NewCommand(hctx, "storage-get")
+		c.Assert(err, gc.IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Check(code, gc.Equals, 0)
+		c.Check(bufferString(ctx.Stderr), gc.Equals, "")
+		c.Check(bufferString(ctx.Stdout), gc.Equals, t.out)
+	}
+}
+
+var storageGetErrorTests = []struct {
+	storageId string
+	args      []string
+	code      int
+	err       string
+}{
+	{"", nil, 2, "error: no storage instance specified\n"},
+	{"data/0", []string{"location", "name"}, 2, "error: unrecognized args: [\"name\"]\n"},
+	{"data/0", []string{"size"}, 1, "error: unknown key \"size\"\n"},
+	{"data/9", nil, 1, "error: storage instance \"data/9\" not found\n"},
+}
+
+func (s *StorageGetSuite) TestErrors(c *gc.C) {
+	for i, t := range storageGetErrorTests {
+		c.Logf("test %d: %v", i, t.args)
+		hctx := s.GetStorageHookContext(c, t.storageId)
+		com, err := jujuc.NewCommand(hctx, "storage-get")
+		c.Assert(err, gc.IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Check(code, gc.Equals, t.code)
+		c.Check(bufferString(ctx.Stderr), gc.Equals, t.err)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"github.com/juju/cmd"
+	"launchpad.net/gnuflag"
+)
+
+// StorageListCommand implements the storage-list command.
+type StorageListCommand struct {
+	cmd.CommandBase
+	ctx  Context
+	Name string // The store to list. If empty, list all.
+	out  cmd.Output
+}
+
+func NewStorageListCommand(ctx Context) cmd.Command {
+	return &StorageListCommand{ctx: ctx}
+}
+
+func (c *StorageListCommand) Info() *cmd.Info {
+	doc := `
+storage-list lists the ids of the storage instances owned by the unit. If a
+store name is given, only the instances created for that store are listed.
+`
+	return &cmd.Info{
+		Name:    "storage-list",
+		Args:    "[<store>]",
+		Purpose: "list storage instances",
+		Doc:     doc,
+	}
+}
+
+func (c *StorageListCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "smart", cmd.DefaultFormatters)
+}
+
+func (c *StorageListCommand) Init(args []string) error {
+	if args == nil {
+		return nil
+	}
+	c.Name = args[0]
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *StorageListCommand) Run(ctx *cmd.Context) error {
+	instances, err := c.ctx.StorageInstances()
+	if err != nil {
+		return err
+	}
+	ids := []string{}
+	for _, instance := range instances {
+		if c.Name == "" || instance.Name == c.Name {
+			ids = append(ids, instance.Id)
+		}
+	}
+	return c.out.Write(ctx, ids)
+}
@@ -0,0 +1,53 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	"github.com/juju/cmd"
+	gc "launchpad.net/gocheck"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/jujuc"
+)
+
+type StorageListSuite struct {
+	storageSuite
+}
+
+var _ = gc.Suite(&StorageListSuite{})
+
+var storageListTests = []struct {
+	args []string
+	out  string
+}{
+	{nil, "data/0\ndata/1\nlogs/2\n"},
+	{[]string{"--format", "json"}, `["data/0","data/1","logs/2"]` + "\n"},
+	{[]string{"data"}, "data/0\ndata/1\n"},
+	{[]string{"cache"}, ""},
+	{[]string{"cache", "--format", "json"}, "[]\n"},
+}
+
+func (s *StorageListSuite) TestOutputFormat(c *gc.C) {
+	for i, t := range storageListTests {
+		c.Logf("test %d: %v", i, t.args)
+		hctx := s.GetStorageHookContext(c, "")
+		com, err := jujuc.NewCommand(hctx, "storage-list")
+		c.Assert(err, gc.IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Check(code, gc.Equals, 0)
+		c.Check(bufferString(ctx.Stderr), gc.Equals, "")
+		c.Check(bufferString(ctx.Stdout), gc.Equals, t.out)
+	}
+}
+
+func (s *StorageListSuite) TestUnexpectedArgs(c *gc.C) {
+	hctx := s.GetStorageHookContext(c, "")
+	com, err := jujuc.NewCommand(hctx, "storage-list")
+	c.Assert(err, gc.IsNil)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"data", "logs"})
+	c.Assert(code, gc.Equals, 2)
+	c.Assert(bufferString(ctx.Stderr), gc.Equals, "error: unrecognized args: [\"logs\"]\n")
+}
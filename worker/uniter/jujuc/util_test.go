@@ -9,11 +9,13 @@ import (
 	"io"
 	"sort"
 	stdtesting "testing"
+	"time"
 
 	"github.com/juju/charm"
 	"github.com/juju/utils/set"
 	gc "launchpad.net/gocheck"
 
+	"github.com/juju/juju/network"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/api/params"
 	"github.com/juju/juju/testing"
@@ -83,6 +85,23 @@ type Context struct {
 	relid  int
 	remote string
 	rels   map[int]*ContextRelation
+
+	actionParams  map[string]interface{}
+	actionResults map[string]interface{}
+	actionMessage string
+	actionFailed  bool
+
+	workloadStatus  params.WorkloadStatus
+	workloadMessage string
+
+	isLeader       bool
+	leaderSettings map[string]string
+
+	storageId string
+	storage   []params.StorageInstance
+
+	metricsEnabled bool
+	metrics        map[string]string
 }
 
 func (c *Context) UnitName() string {
@@ -97,13 +116,13 @@ func (c *Context) PrivateAddress() (string, bool) {
 	return "192.168.0.99", true
 }
 
-func (c *Context) OpenPort(protocol string, port int) error {
-	c.ports.Add(fmt.Sprintf("%d/%s", port, protocol))
+func (c *Context) OpenPorts(protocol string, fromPort, toPort int) error {
+	c.ports.Add(network.PortRange{Protocol: protocol, FromPort: fromPort, ToPort: toPort}.String())
 	return nil
 }
 
-func (c *Context) ClosePort(protocol string, port int) error {
-	c.ports.Remove(fmt.Sprintf("%d/%s", port, protocol))
+func (c *Context) ClosePorts(protocol string, fromPort, toPort int) error {
+	c.ports.Remove(network.PortRange{Protocol: protocol, FromPort: fromPort, ToPort: toPort}.String())
 	return nil
 }
 
@@ -142,6 +161,113 @@ func (c *Context) OwnerTag() string {
 	return "test-owner"
 }
 
+func (c *Context) ActionParams() (map[string]interface{}, error) {
+	if c.actionParams == nil {
+		return nil, fmt.Errorf("not running an action")
+	}
+	return c.actionParams, nil
+}
+
+func (c *Context) UpdateActionResults(keys []string, value string) error {
+	if c.actionParams == nil {
+		return fmt.Errorf("not running an action")
+	}
+	if c.actionResults == nil {
+		c.actionResults = map[string]interface{}{}
+	}
+	m := c.actionResults
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := m[key].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			m[key] = next
+		}
+		m = next
+	}
+	m[keys[len(keys)-1]] = value
+	return nil
+}
+
+func (c *Context) SetActionMessage(message string) error {
+	if c.actionParams == nil {
+		return fmt.Errorf("not running an action")
+	}
+	c.actionMessage = message
+	return nil
+}
+
+func (c *Context) SetActionFailed() error {
+	if c.actionParams == nil {
+		return fmt.Errorf("not running an action")
+	}
+	c.actionFailed = true
+	return nil
+}
+
+func (c *Context) WorkloadStatus() (params.WorkloadStatus, string, error) {
+	if c.workloadStatus == "" {
+		return params.WorkloadUnknown, "", nil
+	}
+	return c.workloadStatus, c.workloadMessage, nil
+}
+
+func (c *Context) SetWorkloadStatus(status params.WorkloadStatus, message string) error {
+	c.workloadStatus = status
+	c.workloadMessage = message
+	return nil
+}
+
+func (c *Context) IsLeader() (bool, error) {
+	return c.isLeader, nil
+}
+
+func (c *Context) LeaderSettings() (map[string]string, error) {
+	result := make(map[string]string)
+	for k, v := range c.leaderSettings {
+		result[k] = v
+	}
+	return result, nil
+}
+
+func (c *Context) WriteLeaderSettings(settings map[string]string) error {
+	if !c.isLeader {
+		return fmt.Errorf("cannot write leader settings: unit is not the service leader")
+	}
+	if c.leaderSettings == nil {
+		c.leaderSettings = make(map[string]string)
+	}
+	for k, v := range settings {
+		if v == "" {
+			delete(c.leaderSettings, k)
+		} else {
+			c.leaderSettings[k] = v
+		}
+	}
+	return nil
+}
+
+func (c *Context) HookStorageId() (string, bool) {
+	return c.storageId, c.storageId != ""
+}
+
+func (c *Context) StorageInstances() ([]params.StorageInstance, error) {
+	return c.storage, nil
+}
+
+func (c *Context) AddMetric(key, value string, created time.Time) error {
+	if !c.metricsEnabled {
+		return fmt.Errorf("metrics disabled")
+	}
+	if key == "undeclared" {
+		return fmt.Errorf("metric %q not declared", key)
+	}
+	if c.metrics == nil {
+		c.metrics = make(map[string]string)
+	}
+	c.metrics[key] = value
+	return nil
+}
+
 type ContextRelation struct {
 	id    int
 	name  string
@@ -117,6 +117,20 @@ func ModeUpgrading(curl *charm.URL) Mode {
 	}
 }
 
+// ModeRunAction is responsible for running the action with the given
+// id. A failing action is reported as such, but does not put the unit
+// into an error state.
+func ModeRunAction(actionId string) Mode {
+	name := fmt.Sprintf("ModeRunAction %s", actionId)
+	return func(u *Uniter) (next Mode, err error) {
+		defer modeContext(name, &err)()
+		if err = u.runAction(actionId); err != nil {
+			return nil, err
+		}
+		return ModeContinue, nil
+	}
+}
+
 // ModeConfigChanged runs the "config-changed" hook.
 func ModeConfigChanged(u *Uniter) (next Mode, err error) {
 	defer modeContext("ModeConfigChanged", &err)()
@@ -198,6 +212,8 @@ func ModeTerminating(u *Uniter) (next Mode, err error) {
 // * charm upgrade requests
 // * relation changes
 // * unit death
+// * update-status and collect-metrics hook timer events
+// * storage attachment
 func ModeAbide(u *Uniter) (next Mode, err error) {
 	defer modeContext("ModeAbide", &err)()
 	if u.s.Op != Continue {
@@ -209,6 +225,7 @@ func ModeAbide(u *Uniter) (next Mode, err error) {
 	if err = u.unit.SetStatus(params.StatusStarted, "", nil); err != nil {
 		return nil, err
 	}
+	u.setAgentActivity(params.ActivityIdle, "")
 	u.f.WantUpgradeEvent(false)
 	for _, r := range u.relationers {
 		r.StartHooks()
@@ -231,6 +248,9 @@ func ModeAbide(u *Uniter) (next Mode, err error) {
 // modeAbideAliveLoop handles all state changes for ModeAbide when the unit
 // is in an Alive state.
 func modeAbideAliveLoop(u *Uniter) (Mode, error) {
+	if err := u.refreshStorage(); err != nil {
+		return nil, err
+	}
 	for {
 		hi := hook.Info{}
 		select {
@@ -240,6 +260,27 @@ func modeAbideAliveLoop(u *Uniter) (Mode, error) {
 			return modeAbideDyingLoop(u)
 		case <-u.f.ConfigEvents():
 			hi = hook.Info{Kind: hooks.ConfigChanged}
+		case <-u.f.UpdateStatusEvents():
+			hi = hook.Info{Kind: hook.UpdateStatus}
+		case <-u.f.CollectMetricsEvents():
+			// Only charms that declare metrics collect them.
+			metrics, err := ucharm.ReadMetrics(u.charmPath)
+			if err != nil {
+				return nil, err
+			} else if metrics == nil {
+				continue
+			}
+			hi = hook.Info{Kind: hook.CollectMetrics}
+		case <-u.f.LeaderElectedEvents():
+			hi = hook.Info{Kind: hook.LeaderElected}
+		case <-u.f.LeaderSettingsEvents():
+			hi = hook.Info{Kind: hook.LeaderSettingsChanged}
+		case hi = <-u.storageHooks(hook.StorageAttached):
+		case <-u.f.StorageEvents():
+			if err := u.refreshStorage(); err != nil {
+				return nil, err
+			}
+			continue
 		case hi = <-u.relationHooks:
 		case ids := <-u.f.RelationsEvents():
 			added, err := u.updateRelations(ids)
@@ -252,6 +293,8 @@ func modeAbideAliveLoop(u *Uniter) (Mode, error) {
 			continue
 		case curl := <-u.f.UpgradeEvents():
 			return ModeUpgrading(curl), nil
+		case actionId := <-u.f.ActionEvents():
+			return ModeRunAction(actionId), nil
 		}
 		if err := u.runHook(hi); err == errHookFailed {
 			return ModeHookError, nil
@@ -261,8 +304,8 @@ func modeAbideAliveLoop(u *Uniter) (Mode, error) {
 	}
 }
 
-// modeAbideDyingLoop handles the proper termination of all relations in
-// response to a Dying unit.
+// modeAbideDyingLoop handles the proper termination of all relations,
+// and the detachment of all storage, in response to a Dying unit.
 func modeAbideDyingLoop(u *Uniter) (next Mode, err error) {
 	if err := u.unit.Refresh(); err != nil {
 		return nil, err
@@ -278,7 +321,7 @@ func modeAbideDyingLoop(u *Uniter) (next Mode, err error) {
 		}
 	}
 	for {
-		if len(u.relationers) == 0 {
+		if len(u.relationers) == 0 && u.attachedStorage.IsEmpty() {
 			return ModeStopping, nil
 		}
 		hi := hook.Info{}
@@ -287,6 +330,7 @@ func modeAbideDyingLoop(u *Uniter) (next Mode, err error) {
 			return nil, tomb.ErrDying
 		case <-u.f.ConfigEvents():
 			hi = hook.Info{Kind: hooks.ConfigChanged}
+		case hi = <-u.storageHooks(hook.StorageDetaching):
 		case hi = <-u.relationHooks:
 		}
 		if err = u.runHook(hi); err == errHookFailed {
@@ -0,0 +1,104 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter
+
+import (
+	"os"
+	"sort"
+
+	"github.com/juju/charm/hooks"
+	"github.com/juju/utils"
+	"github.com/juju/utils/set"
+
+	"github.com/juju/juju/state/api/params"
+	"github.com/juju/juju/worker/uniter/hook"
+)
+
+// StorageStateFile records the ids of the storage instances for
+// which the uniter has run the storage-attached hook, so that the
+// hook is run exactly once for each instance, and so that the
+// storage-detaching hook can be run for each of them before the
+// unit stops.
+type StorageStateFile struct {
+	path string
+}
+
+// NewStorageStateFile returns a new StorageStateFile using path.
+func NewStorageStateFile(path string) *StorageStateFile {
+	return &StorageStateFile{path}
+}
+
+// Read returns the ids of the attached storage instances recorded
+// in the file. It returns an empty set if the file does not exist.
+func (f *StorageStateFile) Read() (set.Strings, error) {
+	var ids []string
+	if err := utils.ReadYaml(f.path, &ids); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return set.NewStrings(ids...), nil
+}
+
+// Write records the ids of the attached storage instances.
+func (f *StorageStateFile) Write(ids set.Strings) error {
+	return utils.WriteYaml(f.path, ids.SortedValues())
+}
+
+// refreshStorage fetches the storage instances owned by the unit. A
+// state server that does not support storage is treated as one that
+// has no storage instances for the unit.
+func (u *Uniter) refreshStorage() error {
+	instances, err := u.unit.StorageInstances()
+	if params.IsCodeNotImplemented(err) {
+		instances = nil
+	} else if err != nil {
+		return err
+	}
+	u.storage = instances
+	return nil
+}
+
+// storageHooks returns a channel that delivers the next storage hook
+// of the given kind the uniter should run, or nil if there is none.
+// A storage-attached hook is due for each storage instance that has
+// a location but has not yet been recorded as attached, while a
+// storage-detaching hook is due for each recorded instance.
+func (u *Uniter) storageHooks(kind hooks.Kind) <-chan hook.Info {
+	var ids []string
+	switch kind {
+	case hook.StorageAttached:
+		for _, instance := range u.storage {
+			if instance.Location != "" && !u.attachedStorage.Contains(instance.Id) {
+				ids = append(ids, instance.Id)
+			}
+		}
+	case hook.StorageDetaching:
+		ids = u.attachedStorage.Values()
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	sort.Strings(ids)
+	next := make(chan hook.Info, 1)
+	next <- hook.Info{Kind: kind, StorageId: ids[0]}
+	return next
+}
+
+// commitStorageHook records the effect of the given storage
+// hook on the set of attached storage instances.
+func (u *Uniter) commitStorageHook(hi hook.Info) error {
+	attached := set.NewStrings(u.attachedStorage.Values()...)
+	switch hi.Kind {
+	case hook.StorageAttached:
+		attached.Add(hi.StorageId)
+	case hook.StorageDetaching:
+		attached.Remove(hi.StorageId)
+	default:
+		return nil
+	}
+	if err := u.storageStateFile.Write(attached); err != nil {
+		return err
+	}
+	u.attachedStorage = attached
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/juju/utils/exec"
 	"github.com/juju/utils/fslock"
 	proxyutils "github.com/juju/utils/proxy"
+	"github.com/juju/utils/set"
 	"launchpad.net/tomb"
 
 	"github.com/juju/juju/agent/tools"
@@ -82,6 +83,13 @@ type Uniter struct {
 	proxy      proxyutils.Settings
 	proxyMutex sync.Mutex
 
+	// storage holds the storage instances owned by the unit, as last
+	// fetched; attachedStorage holds the ids of those for which the
+	// storage-attached hook has been committed.
+	storage          []params.StorageInstance
+	attachedStorage  set.Strings
+	storageStateFile *StorageStateFile
+
 	ranConfigChanged bool
 	// The execution observer is only used in tests at this stage. Should this
 	// need to be extended, perhaps a list of observers would be needed.
@@ -206,6 +214,10 @@ func (u *Uniter) init(unitTag string) (err error) {
 		return fmt.Errorf("cannot create deployer: %v", err)
 	}
 	u.sf = NewStateFile(filepath.Join(u.baseDir, "state", "uniter"))
+	u.storageStateFile = NewStorageStateFile(filepath.Join(u.baseDir, "state", "storage"))
+	if u.attachedStorage, err = u.storageStateFile.Read(); err != nil {
+		return err
+	}
 	u.rand = rand.New(rand.NewSource(time.Now().Unix()))
 
 	// If we start trying to listen for juju-run commands before we have valid
@@ -416,6 +428,53 @@ func (u *Uniter) RunCommands(commands string) (results *exec.ExecResponse, err e
 	return result, err
 }
 
+// runAction runs the queued action with the given id and reports its
+// outcome. An action that is no longer queued is skipped. Failure of
+// the action itself is recorded against the action, and is not
+// returned as an error.
+func (u *Uniter) runAction(actionId string) (err error) {
+	action, err := u.st.Action(actionId)
+	if params.IsCodeNotFoundOrCodeUnauthorized(err) {
+		logger.Infof("skipped action %q (no longer queued)", actionId)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	actionName := action.Name()
+	hctxId := fmt.Sprintf("%s:%s:%d", u.unit.Name(), actionName, u.rand.Int63())
+	lockMessage := fmt.Sprintf("%s: running action %q", u.unit.Name(), actionName)
+	if err = u.acquireHookLock(lockMessage); err != nil {
+		return err
+	}
+	defer u.hookLock.Unlock()
+
+	hctx, err := u.getHookContext(hctxId, -1, "")
+	if err != nil {
+		return err
+	}
+	hctx.actionData = newActionData(actionId, actionName, action.Params())
+	srv, socketPath, err := u.startJujucServer(hctx)
+	if err != nil {
+		return err
+	}
+	defer srv.Close()
+
+	logger.Infof("running action %q", actionName)
+	data := hctx.actionData
+	if err := hctx.RunAction(actionName, u.charmPath, u.toolsDir, socketPath); err != nil {
+		logger.Errorf("action %q failed: %s", actionName, err)
+		data.ActionFailed = true
+		if data.ResultsMessage == "" {
+			data.ResultsMessage = err.Error()
+		}
+	}
+	if data.ActionFailed {
+		return action.Fail(data.ResultsMap, data.ResultsMessage)
+	}
+	logger.Infof("ran action %q", actionName)
+	return action.Complete(data.ResultsMap, data.ResultsMessage)
+}
+
 func (u *Uniter) notifyHookInternal(hook string, hctx *HookContext, method func(string)) {
 	if r, ok := hctx.HookRelation(); ok {
 		remote, _ := hctx.RemoteUnitName()
@@ -467,12 +526,21 @@ func (u *Uniter) runHook(hi hook.Info) (err error) {
 	if err != nil {
 		return err
 	}
+	hctx.storageId = hi.StorageId
+	if hi.Kind == hook.CollectMetrics {
+		if hctx.metricsDefs, err = charm.ReadMetrics(u.charmPath); err != nil {
+			return err
+		}
+	}
 	srv, socketPath, err := u.startJujucServer(hctx)
 	if err != nil {
 		return err
 	}
 	defer srv.Close()
 
+	u.setAgentActivity(params.ActivityExecuting, hookName)
+	defer u.setAgentActivity(params.ActivityIdle, "")
+
 	// Run the hook.
 	if err := u.writeState(RunHook, Pending, &hi, nil); err != nil {
 		return err
@@ -499,6 +567,20 @@ func (u *Uniter) runHook(hi hook.Info) (err error) {
 	return u.commitHook(hi)
 }
 
+// setAgentActivity records what the unit's agent is doing, so that
+// users can see which hook, if any, is running. The activity is only
+// informative, so failure to record it is logged rather than treated
+// as an error; in particular, state servers that predate agent
+// activity cannot record it.
+func (u *Uniter) setAgentActivity(activity params.AgentActivity, hookName string) {
+	err := u.unit.SetAgentActivity(activity, hookName)
+	if params.IsCodeNotImplemented(err) {
+		logger.Debugf("agent activity not supported by state server: %v", err)
+	} else if err != nil {
+		logger.Warningf("cannot set agent activity: %v", err)
+	}
+}
+
 // commitHook ensures that state is consistent with the supplied hook, and
 // that the fact of the hook's completion is persisted.
 func (u *Uniter) commitHook(hi hook.Info) error {
@@ -514,6 +596,9 @@ func (u *Uniter) commitHook(hi hook.Info) error {
 	if hi.Kind == hooks.ConfigChanged {
 		u.ranConfigChanged = true
 	}
+	if err := u.commitStorageHook(hi); err != nil {
+		return err
+	}
 	if err := u.writeState(Continue, Pending, &hi, nil); err != nil {
 		return err
 	}
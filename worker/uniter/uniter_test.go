@@ -206,6 +206,14 @@ func (ctx *context) writeHook(c *gc.C, path string, good bool) {
 	c.Assert(err, gc.IsNil)
 }
 
+func (ctx *context) writeAction(c *gc.C, charmPath, name, content string) {
+	dir := filepath.Join(charmPath, "actions")
+	err := os.MkdirAll(dir, 0755)
+	c.Assert(err, gc.IsNil)
+	err = ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0755)
+	c.Assert(err, gc.IsNil)
+}
+
 func (ctx *context) matchHooks(c *gc.C) (match bool, overshoot bool) {
 	c.Logf("ctx.hooksCompleted: %#v", ctx.hooksCompleted)
 	if len(ctx.hooksCompleted) < len(ctx.hooks) {
@@ -1249,6 +1257,105 @@ func (s *UniterSuite) TestUniterSubordinates(c *gc.C) {
 	s.runUniterTests(c, subordinatesTests)
 }
 
+var actionsTests = []uniterTest{
+	ut(
+		"successful action records its results",
+		createCharm{customize: func(c *gc.C, ctx *context, path string) {
+			ctx.writeAction(c, path, "snapshot", `
+#!/bin/bash --norc
+action-set name=$(action-get outfile) size=10
+`[1:])
+		}},
+		serveCharm{},
+		createUniter{},
+		waitHooks{"install", "config-changed", "start"},
+		addAction{"snapshot", map[string]interface{}{"outfile": "foo.bz2"}},
+		waitActionResults{state.ActionCompleted, map[string]interface{}{
+			"name": "foo.bz2",
+			"size": "10",
+		}, ""},
+		waitUnit{status: params.StatusStarted},
+		verifyRunning{},
+	), ut(
+		"action-fail marks the action failed",
+		createCharm{customize: func(c *gc.C, ctx *context, path string) {
+			ctx.writeAction(c, path, "snapshot", `
+#!/bin/bash --norc
+action-set partial=true
+action-fail "disk full"
+`[1:])
+		}},
+		serveCharm{},
+		createUniter{},
+		waitHooks{"install", "config-changed", "start"},
+		addAction{"snapshot", nil},
+		waitActionResults{state.ActionFailed, map[string]interface{}{
+			"partial": "true",
+		}, "disk full"},
+		waitUnit{status: params.StatusStarted},
+	), ut(
+		"erroring action is failed without putting the unit in error",
+		createCharm{customize: func(c *gc.C, ctx *context, path string) {
+			ctx.writeAction(c, path, "snapshot", "#!/bin/bash --norc\nexit 1\n")
+		}},
+		serveCharm{},
+		createUniter{},
+		waitHooks{"install", "config-changed", "start"},
+		addAction{"snapshot", nil},
+		waitActionResults{state.ActionFailed, map[string]interface{}{}, "exit status 1"},
+		waitUnit{status: params.StatusStarted},
+		verifyRunning{},
+	), ut(
+		"missing action is failed",
+		quickStart{},
+		addAction{"snapshot", nil},
+		waitActionResults{state.ActionFailed, map[string]interface{}{}, "snapshot does not exist"},
+		waitUnit{status: params.StatusStarted},
+	),
+}
+
+func (s *UniterSuite) TestUniterActions(c *gc.C) {
+	s.runUniterTests(c, actionsTests)
+}
+
+var updateStatusHookTests = []uniterTest{
+	ut(
+		"update-status hook runs periodically in steady state",
+		quickStart{},
+		waitAgentActivity{params.ActivityIdle, ""},
+		setUpdateStatusInterval(1),
+		waitHooks{"update-status"},
+		setUpdateStatusInterval(3600),
+		waitAgentActivity{params.ActivityIdle, ""},
+		verifyRunning{},
+	),
+}
+
+func (s *UniterSuite) TestUniterUpdateStatusHook(c *gc.C) {
+	s.runUniterTests(c, updateStatusHookTests)
+}
+
+var leaderElectedHookTests = []uniterTest{
+	ut(
+		"leader-elected hook runs once the sole unit has started",
+		createCharm{
+			customize: func(c *gc.C, ctx *context, path string) {
+				ctx.writeHook(c, filepath.Join(path, "hooks", "leader-elected"), true)
+			},
+		},
+		serveCharm{},
+		createUniter{},
+		waitUnit{status: params.StatusStarted},
+		waitHooks{"install", "config-changed", "start", "leader-elected"},
+		waitHooks{},
+		verifyRunning{},
+	),
+}
+
+func (s *UniterSuite) TestUniterLeaderElectedHook(c *gc.C) {
+	s.runUniterTests(c, leaderElectedHookTests)
+}
+
 func (s *UniterSuite) runUniterTests(c *gc.C, uniterTests []uniterTest) {
 	for i, t := range uniterTests {
 		c.Logf("\ntest %d: %s\n", i, t.summary)
@@ -1369,7 +1476,7 @@ type createCharm struct {
 var charmHooks = []string{
 	"install", "start", "config-changed", "upgrade-charm", "stop",
 	"db-relation-joined", "db-relation-changed", "db-relation-departed",
-	"db-relation-broken",
+	"db-relation-broken", "update-status",
 }
 
 func (s createCharm) step(c *gc.C, ctx *context) {
@@ -1718,6 +1825,45 @@ func (s waitUnit) step(c *gc.C, ctx *context) {
 	}
 }
 
+type addAction struct {
+	name   string
+	params map[string]interface{}
+}
+
+func (s addAction) step(c *gc.C, ctx *context) {
+	_, err := ctx.unit.AddAction(s.name, s.params)
+	c.Assert(err, gc.IsNil)
+}
+
+type waitActionResults struct {
+	status  state.ActionStatus
+	results map[string]interface{}
+	message string
+}
+
+func (s waitActionResults) step(c *gc.C, ctx *context) {
+	timeout := time.After(worstCase)
+	for {
+		ctx.s.BackingState.StartSync()
+		select {
+		case <-time.After(coretesting.ShortWait):
+			results, err := ctx.st.ActionResultsForUnit(ctx.unit.Name())
+			c.Assert(err, gc.IsNil)
+			if len(results) == 0 {
+				c.Logf("no action results yet")
+				continue
+			}
+			c.Assert(results, gc.HasLen, 1)
+			c.Assert(results[0].Status(), gc.Equals, s.status)
+			c.Assert(results[0].Results(), gc.DeepEquals, s.results)
+			c.Assert(results[0].Output(), gc.Equals, s.message)
+			return
+		case <-timeout:
+			c.Fatalf("never got action results")
+		}
+	}
+}
+
 type waitHooks []string
 
 func (s waitHooks) step(c *gc.C, ctx *context) {
@@ -2197,6 +2343,33 @@ var verifyHookSyncLockLocked = custom{func(c *gc.C, ctx *context) {
 	c.Assert(lock.IsLocked(), jc.IsTrue)
 }}
 
+type setUpdateStatusInterval int
+
+func (s setUpdateStatusInterval) step(c *gc.C, ctx *context) {
+	attrs := map[string]interface{}{
+		"update-status-interval": int(s),
+	}
+	err := ctx.st.UpdateEnvironConfig(attrs, nil, nil)
+	c.Assert(err, gc.IsNil)
+}
+
+type waitAgentActivity struct {
+	activity params.AgentActivity
+	hook     string
+}
+
+func (s waitAgentActivity) step(c *gc.C, ctx *context) {
+	for attempt := coretesting.LongAttempt.Start(); attempt.Next(); {
+		activity, hook, err := ctx.unit.AgentActivity()
+		c.Assert(err, gc.IsNil)
+		if activity == s.activity && hook == s.hook {
+			return
+		}
+		c.Logf("want agent activity %q %q, got %q %q; still waiting", s.activity, s.hook, activity, hook)
+	}
+	c.Fatalf("never reached desired agent activity")
+}
+
 type setProxySettings proxy.Settings
 
 func (s setProxySettings) step(c *gc.C, ctx *context) {